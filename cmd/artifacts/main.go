@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/artifacts"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/junit"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/version"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// buildLogName is the object name of the build log, relative to the job path
+	buildLogName = "build-log.txt"
+	// artifactsDir is the directory artifacts and junit results are uploaded into, relative to the job path
+	artifactsDir = "artifacts"
+	// maxResultSize is the maximum size of a Tekton task result
+	maxResultSize = 4096
+)
+
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+type options struct {
+	bucket    string
+	workspace string
+	artifacts stringSlice
+	junit     stringSlice
+	logFile   string
+
+	junitResultFile string
+
+	credentialsFile string
+	endpoint        string
+	region          string
+}
+
+func (o *options) Validate() error {
+	if o.bucket == "" {
+		return fmt.Errorf("--bucket or the %s environment variable must be set", v1alpha1.ArtifactsBucketEnv)
+	}
+	if _, err := artifacts.ParseDestination(o.bucket); err != nil {
+		return errors.Wrap(err, "invalid --bucket")
+	}
+	if os.Getenv(v1alpha1.JobNameEnv) == "" || os.Getenv(v1alpha1.BuildIDEnv) == "" {
+		return fmt.Errorf("the %s and %s environment variables must be set", v1alpha1.JobNameEnv, v1alpha1.BuildIDEnv)
+	}
+	return nil
+}
+
+func gatherOptions(fs *flag.FlagSet, args ...string) options {
+	var o options
+	fs.StringVar(&o.bucket, "bucket", os.Getenv(v1alpha1.ArtifactsBucketEnv), "The bucket URL to upload to, e.g. gs://my-bucket/prefix or s3://my-bucket/prefix.")
+	fs.StringVar(&o.workspace, "workspace", ".", "The directory the artifacts and junit glob patterns are relative to.")
+	fs.Var(&o.artifacts, "artifacts", fmt.Sprintf("Glob pattern of the artifacts to upload, may be repeated. Defaults to the comma separated patterns of the %s environment variable.", v1alpha1.ArtifactsPathsEnv))
+	fs.Var(&o.junit, "junit", "Glob pattern of the junit results to upload, may be repeated. Defaults to **/junit*.xml.")
+	fs.StringVar(&o.logFile, "log-file", "", "Path to the build log to upload.")
+	fs.StringVar(&o.junitResultFile, "junit-result-file", "", "Path to write a summary of the junit failures to, such as $(results.junit.path) in a Tekton task, so they can be reported on the pull request.")
+	fs.StringVar(&o.credentialsFile, "credentials-file", "", "Path to the GCS service account key file. Application default credentials are used if empty.")
+	fs.StringVar(&o.endpoint, "endpoint", "", "Override of the storage API endpoint, e.g. for S3 compatible storages.")
+	fs.StringVar(&o.region, "region", "", "The S3 region, defaults to the AWS_REGION environment variable.")
+	err := fs.Parse(args)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+	if len(o.junit) == 0 {
+		o.junit = stringSlice{"**/junit*.xml"}
+	}
+	if len(o.artifacts) == 0 {
+		for _, pattern := range strings.Split(os.Getenv(v1alpha1.ArtifactsPathsEnv), ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				o.artifacts = append(o.artifacts, pattern)
+			}
+		}
+	}
+	return o
+}
+
+func main() {
+	logrusutil.ComponentInit("lighthouse-artifacts")
+	logrus.WithField("version", fmt.Sprintf("%v", version.Version)).Info("Lighthouse Artifacts Uploader")
+
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
+	if err := o.Validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+
+	if err := run(context.Background(), &o); err != nil {
+		logrus.WithError(err).Fatal("Error uploading artifacts.")
+	}
+}
+
+func run(ctx context.Context, o *options) error {
+	dest, err := artifacts.ParseDestination(o.bucket)
+	if err != nil {
+		return err
+	}
+	uploader, err := artifacts.NewUploader(ctx, dest, artifacts.Options{
+		CredentialsFile: o.credentialsFile,
+		Endpoint:        o.endpoint,
+		Region:          o.region,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create uploader")
+	}
+
+	jobPath := artifacts.JobPath(
+		job.PipelineKind(os.Getenv(v1alpha1.JobTypeEnv)),
+		os.Getenv(v1alpha1.JobNameEnv),
+		os.Getenv(v1alpha1.RepoOwnerEnv),
+		os.Getenv(v1alpha1.RepoNameEnv),
+		os.Getenv(v1alpha1.PullNumberEnv),
+		os.Getenv(v1alpha1.BuildIDEnv),
+	)
+	logger := logrus.WithField("destination", dest.String()).WithField("path", jobPath)
+
+	junitFiles, err := artifacts.Gather(o.workspace, o.junit)
+	if err != nil {
+		return err
+	}
+	files, err := artifacts.Gather(o.workspace, o.artifacts)
+	if err != nil {
+		return err
+	}
+	for k, v := range junitFiles {
+		files[k] = v
+	}
+	var errs []string
+	failures, err := junitFailures(junitFiles)
+	if err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		// the report lets lighthouse fill the failed tests of the job whatever the engine running it
+		if len(junitFiles) > 0 {
+			if err := uploadJUnitReport(ctx, uploader, dest.Join(jobPath, artifacts.JUnitReportName), failures); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if o.junitResultFile != "" {
+			if err := writeJUnitResult(o.junitResultFile, failures); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if o.logFile != "" {
+		if err := artifacts.UploadFiles(ctx, uploader, dest, jobPath, map[string]string{buildLogName: o.logFile}, logger); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if err := artifacts.UploadFiles(ctx, uploader, dest, jobPath+"/"+artifactsDir, files, logger); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	logger.Infof("Uploaded %d artifact(s) to %s", len(files), dest.BrowseURL(jobPath))
+	return nil
+}
+
+// junitFailures returns the failures of the given junit files, ignoring the invalid ones.
+func junitFailures(junitFiles map[string]string) ([]junit.Failure, error) {
+	names := make([]string, 0, len(junitFiles))
+	for name := range junitFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failures []junit.Failure
+	for _, name := range names {
+		file := junitFiles[name]
+		data, err := ioutil.ReadFile(file) // #nosec
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", file)
+		}
+		fileFailures, err := junit.ParseFailures(data)
+		if err != nil {
+			logrus.WithError(err).Warnf("ignoring invalid junit file %s", file)
+			continue
+		}
+		failures = append(failures, fileFailures...)
+	}
+	return failures, nil
+}
+
+// uploadJUnitReport uploads the failures as a single, size limited, junit report.
+func uploadJUnitReport(ctx context.Context, uploader artifacts.Uploader, object string, failures []junit.Failure) error {
+	data, err := junit.MarshalFailures(failures, artifacts.MaxJUnitReportSize)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(uploader.Upload(ctx, object, bytes.NewReader(data), int64(len(data)), "application/xml"), "failed to upload %s", object)
+}
+
+// writeJUnitResult writes the failures as a single junit report small enough for a Tekton task result.
+func writeJUnitResult(path string, failures []junit.Failure) error {
+	data, err := junit.MarshalFailures(failures, maxResultSize)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(ioutil.WriteFile(path, data, 0644), "failed to write %s", path) // #nosec
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/clients"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/jenkins-x/lighthouse/pkg/engines/buildkite"
+	"github.com/jenkins-x/lighthouse/pkg/health"
+	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	lhmetrics "github.com/jenkins-x/lighthouse/pkg/metrics"
+	"github.com/jenkins-x/lighthouse/pkg/version"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type options struct {
+	selector  string
+	namespace string
+
+	buildkiteURL       string
+	buildkiteTokenFile string
+	organization       string
+	resyncInterval     time.Duration
+
+	dryRun bool
+}
+
+func (o *options) Validate() error {
+	if _, err := url.ParseRequestURI(o.buildkiteURL); err != nil {
+		return fmt.Errorf("invalid --buildkite-url URI: %q", o.buildkiteURL)
+	}
+	if o.buildkiteTokenFile == "" {
+		return errors.New("--buildkite-token-file must be set")
+	}
+	if _, err := labels.Parse(o.selector); err != nil {
+		return errors.Wrap(err, "invalid --label-selector")
+	}
+	return nil
+}
+
+func gatherOptions(fs *flag.FlagSet, args ...string) options {
+	var o options
+	fs.StringVar(&o.selector, "label-selector", labels.Everything().String(), "Label selector to be applied on LighthouseJobs. See https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#label-selectors for constructing a label selector.")
+	fs.StringVar(&o.namespace, "namespace", "lighthouse", "The namespace in which Lighthouse is installed. Defaults to 'lighthouse'.")
+	fs.StringVar(&o.buildkiteURL, "buildkite-url", buildkite.DefaultURL, "Buildkite REST API URL")
+	fs.StringVar(&o.buildkiteTokenFile, "buildkite-token-file", "", "Path to the file containing the Buildkite API access token used to create builds.")
+	fs.StringVar(&o.organization, "organization", "", "Slug of the Buildkite organization of the pipelines of the jobs which do not specify one.")
+	fs.DurationVar(&o.resyncInterval, "resync-interval", 30*time.Second, "How often to poll the Buildkite API for build updates.")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Whether or not to make mutating API calls to Buildkite.")
+	err := fs.Parse(args)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+	return o
+}
+
+func main() {
+	logrusutil.ComponentInit("lighthouse-buildkite-controller")
+	logrus.WithField("version", fmt.Sprintf("%v", version.Version)).Info("Lighthouse Buildkite Controller")
+
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
+	if err := o.Validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+
+	defer audit.Init("lighthouse-buildkite-controller")()
+
+	defer interrupts.WaitForGracefulShutdown()
+
+	_, kubeClient, lighthouseClientSet, _, err := clients.GetAPIClients()
+	if err != nil {
+		logrus.WithError(err).Fatal("Error creating kubernetes resource clients.")
+	}
+
+	secretAgent := &secret.Agent{}
+	if err := secretAgent.Start([]string{o.buildkiteTokenFile}); err != nil {
+		logrus.WithError(err).Fatal("Error starting secrets agent.")
+	}
+
+	metrics := buildkite.NewMetrics()
+	bc := buildkite.NewClient(o.buildkiteURL, o.organization, o.dryRun, secretAgent.GetTokenGenerator(o.buildkiteTokenFile), nil, metrics.ClientMetrics)
+
+	checker := health.NewChecker()
+	checker.AddReadinessCheck("kubernetes", health.KubernetesCheck(kubeClient.Discovery()))
+	checker.AddReadinessCheck("buildkite", func(ctx context.Context) error {
+		return bc.Ping()
+	})
+	lhmetrics.Serve(lhmetrics.Port, checker.Register)
+	c := buildkite.NewController(lighthouseClientSet.LighthouseV1alpha1().LighthouseJobs(o.namespace), bc, nil, o.selector)
+
+	interrupts.TickLiteral(func() {
+		start := time.Now()
+		if err := c.Sync(); err != nil {
+			logrus.WithError(err).Error("Error syncing.")
+		}
+		duration := time.Since(start)
+		logrus.WithField("duration", fmt.Sprintf("%v", duration)).Info("Synced")
+		metrics.ResyncPeriod.Observe(duration.Seconds())
+	}, o.resyncInterval)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/clients"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/jenkins-x/lighthouse/pkg/engines/drone"
+	"github.com/jenkins-x/lighthouse/pkg/health"
+	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	lhmetrics "github.com/jenkins-x/lighthouse/pkg/metrics"
+	"github.com/jenkins-x/lighthouse/pkg/version"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type options struct {
+	selector  string
+	namespace string
+
+	droneURL       string
+	droneTokenFile string
+	resyncInterval time.Duration
+
+	dryRun bool
+}
+
+func (o *options) Validate() error {
+	if _, err := url.ParseRequestURI(o.droneURL); err != nil {
+		return fmt.Errorf("invalid --drone-url URI: %q", o.droneURL)
+	}
+	if o.droneTokenFile == "" {
+		return errors.New("--drone-token-file must be set")
+	}
+	if _, err := labels.Parse(o.selector); err != nil {
+		return errors.Wrap(err, "invalid --label-selector")
+	}
+	return nil
+}
+
+func gatherOptions(fs *flag.FlagSet, args ...string) options {
+	var o options
+	fs.StringVar(&o.selector, "label-selector", labels.Everything().String(), "Label selector to be applied on LighthouseJobs. See https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#label-selectors for constructing a label selector.")
+	fs.StringVar(&o.namespace, "namespace", "lighthouse", "The namespace in which Lighthouse is installed. Defaults to 'lighthouse'.")
+	fs.StringVar(&o.droneURL, "drone-url", "", "Drone server URL, e.g. https://drone.example.com")
+	fs.StringVar(&o.droneTokenFile, "drone-token-file", "", "Path to the file containing the Drone token used to create builds.")
+	fs.DurationVar(&o.resyncInterval, "resync-interval", 30*time.Second, "How often to poll the Drone API for build updates.")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Whether or not to make mutating API calls to Drone.")
+	err := fs.Parse(args)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+	return o
+}
+
+func main() {
+	logrusutil.ComponentInit("lighthouse-drone-controller")
+	logrus.WithField("version", fmt.Sprintf("%v", version.Version)).Info("Lighthouse Drone Controller")
+
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
+	if err := o.Validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+
+	defer audit.Init("lighthouse-drone-controller")()
+
+	defer interrupts.WaitForGracefulShutdown()
+
+	_, kubeClient, lighthouseClientSet, _, err := clients.GetAPIClients()
+	if err != nil {
+		logrus.WithError(err).Fatal("Error creating kubernetes resource clients.")
+	}
+
+	secretAgent := &secret.Agent{}
+	if err := secretAgent.Start([]string{o.droneTokenFile}); err != nil {
+		logrus.WithError(err).Fatal("Error starting secrets agent.")
+	}
+
+	metrics := drone.NewMetrics()
+	dc := drone.NewClient(o.droneURL, o.dryRun, secretAgent.GetTokenGenerator(o.droneTokenFile), nil, metrics.ClientMetrics)
+
+	checker := health.NewChecker()
+	checker.AddReadinessCheck("kubernetes", health.KubernetesCheck(kubeClient.Discovery()))
+	checker.AddReadinessCheck("drone", func(ctx context.Context) error {
+		return dc.Ping()
+	})
+	lhmetrics.Serve(lhmetrics.Port, checker.Register)
+	c := drone.NewController(lighthouseClientSet.LighthouseV1alpha1().LighthouseJobs(o.namespace), dc, nil, o.selector)
+
+	interrupts.TickLiteral(func() {
+		start := time.Now()
+		if err := c.Sync(); err != nil {
+			logrus.WithError(err).Error("Error syncing.")
+		}
+		duration := time.Since(start)
+		logrus.WithField("duration", fmt.Sprintf("%v", duration)).Info("Synced")
+		metrics.ResyncPeriod.Observe(duration.Seconds())
+	}, o.resyncInterval)
+}
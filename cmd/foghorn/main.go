@@ -1,20 +1,43 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"time"
 
 	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/artifacts"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
 	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/foghorn"
+	"github.com/jenkins-x/lighthouse/pkg/health"
+	"github.com/jenkins-x/lighthouse/pkg/leaderelection"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/metrics"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/tracing"
+	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 type options struct {
-	namespace string
+	namespace   string
+	metricsPort int
+	leaderElect bool
+
+	shutdownTimeout time.Duration
+	etagCacheSize   int
+
+	statusReconcilePeriod time.Duration
+	statusReconcileMinAge time.Duration
+
+	artifactsCredentialsFile string
+	artifactsEndpoint        string
+	artifactsRegion          string
 }
 
 func (o *options) Validate() error {
@@ -24,7 +47,16 @@ func (o *options) Validate() error {
 func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	var o options
 	fs.StringVar(&o.namespace, "namespace", "", "The namespace to listen in")
+	fs.IntVar(&o.metricsPort, "metrics-port", 8080, "The port to serve the metrics on")
+	fs.BoolVar(&o.leaderElect, "leader-elect", false, "Elect a leader among the replicas with a Lease so that only one of them reports the jobs, required to run more than one replica.")
+	fs.DurationVar(&o.shutdownTimeout, "shutdown-timeout", time.Minute, "How long to wait on shutdown for the external plugins, notifications and status webhooks being called")
+	fs.IntVar(&o.etagCacheSize, "etag-cache-size", scmprovider.DefaultETagCacheSize, "The number of responses of the SCM provider cached to be revalidated with their ETag, the cache is disabled if 0")
+	fs.DurationVar(&o.statusReconcilePeriod, "status-reconcile-period", 0, "How often to repair the commit statuses of the open pull requests which do not match the state of their jobs, disabled if zero")
+	fs.DurationVar(&o.statusReconcileMinAge, "status-reconcile-min-age", foghorn.DefaultStatusReconcileMinAge, "How long after the last transition of a job its commit status can be repaired")
 
+	fs.StringVar(&o.artifactsCredentialsFile, "artifacts-credentials-file", "", "Path to the GCS service account key file used to read the junit reports uploaded along with the artifacts of the jobs. Application default credentials are used if empty.")
+	fs.StringVar(&o.artifactsEndpoint, "artifacts-endpoint", "", "Override of the storage API endpoint the junit reports of the jobs are read from, e.g. for S3 compatible storages.")
+	fs.StringVar(&o.artifactsRegion, "artifacts-region", "", "The S3 region the junit reports of the jobs are read from, defaults to the AWS_REGION environment variable.")
 	err := fs.Parse(args)
 	if err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
@@ -45,13 +77,17 @@ func main() {
 	if err := o.Validate(); err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
+	defer tracing.Init("lighthouse-foghorn")()
+	defer audit.Init("lighthouse-foghorn")()
+	scmprovider.SetETagCacheSize(o.etagCacheSize)
 
 	cfg, err := clients.GetConfig("", "")
 	if err != nil {
 		logrus.WithError(err).Fatal("Could not create kubeconfig")
 	}
 
-	mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme, Namespace: o.namespace})
+	// the metrics of the manager are served along with the lighthouse ones
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme, Namespace: o.namespace, MetricsBindAddress: "0"})
 	if err != nil {
 		logrus.WithError(err).Fatal("Unable to start manager")
 	}
@@ -60,13 +96,63 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Unable to instantiate reconciler")
 	}
+	reconciler.SetArtifactsOptions(artifacts.Options{
+		CredentialsFile: o.artifactsCredentialsFile,
+		Endpoint:        o.artifactsEndpoint,
+		Region:          o.artifactsRegion,
+	})
 	if err = reconciler.SetupWithManager(mgr); err != nil {
 		logrus.WithError(err).Fatal("Unable to create controller")
 	}
 
 	defer reconciler.ConfigMapWatcher.Stop()
 
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
-		logrus.WithError(err).Fatal("Problem running manager")
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatal("Unable to create the kubernetes client")
+	}
+	var elector *leaderelection.Elector
+	if o.leaderElect {
+		elector, err = leaderelection.NewElector(kubeClient, o.namespace, "lighthouse-foghorn")
+		if err != nil {
+			logrus.WithError(err).Fatal("Unable to create the leader elector")
+		}
+	}
+	checker := health.NewChecker()
+	checker.AddLivenessCheck("leader-election", elector.Check())
+	checker.AddReadinessCheck("kubernetes", health.KubernetesCheck(kubeClient.Discovery()))
+	checker.AddReadinessCheck("config", health.ConfigCheck(reconciler.ConfigMapWatcher))
+	// the tokens of a GitHub App are per owner so there is no single token to check
+	if util.GetGitHubAppSecretDir() == "" {
+		_, scmClient, _, _, err := util.GetSCMClient("", reconciler.Config())
+		if err != nil {
+			logrus.WithError(err).Fatal("Unable to create the SCM client of the health checks")
+		}
+		checker.AddReadinessCheck("scm", health.SCMCheck(scmClient))
+	}
+
+	metrics.Serve(o.metricsPort, checker.Register)
+
+	stop := ctrl.SetupSignalHandler()
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stop
+		cancel()
+	}()
+	// only the leader reconciles the jobs
+	err = elector.Run(ctx, func(ctx context.Context) {
+		if o.statusReconcilePeriod > 0 {
+			go reconciler.RunStatusReconciler(ctx, o.statusReconcilePeriod, o.statusReconcileMinAge)
+		}
+		if err := mgr.Start(ctx.Done()); err != nil {
+			logrus.WithError(err).Fatal("Problem running manager")
+		}
+	})
+	if err != nil {
+		logrus.WithError(err).Fatal("Problem running the leader election")
+	}
+	// the states reported are recorded on the jobs, only the calls made in the background are waited for
+	if !reconciler.Drain(o.shutdownTimeout) {
+		logrus.Warn("Timed out waiting for the external plugins, notifications and status webhooks to be called")
 	}
 }
@@ -1,29 +1,44 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	clientset "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned"
-	lhclient "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/typed/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/clients"
+	"github.com/jenkins-x/lighthouse/pkg/gc"
+	"github.com/jenkins-x/lighthouse/pkg/leaderelection"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
 	"github.com/sirupsen/logrus"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	tektonclient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 )
 
 type options struct {
 	namespace string
 	maxAge    time.Duration
+
+	successTTL time.Duration
+	failureTTL time.Duration
+	keepRecent int
+
+	keeperURL         string
+	cleanPipelineRuns bool
+	dryRun            bool
+	leaderElect       bool
 }
 
 func (o *options) Validate() error {
 	if o.namespace == "" {
 		return fmt.Errorf("no --namespace given")
 	}
+	if o.keepRecent < 0 {
+		return fmt.Errorf("--keep-recent must not be negative")
+	}
 	return nil
 }
 
@@ -31,13 +46,26 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	logrusutil.ComponentInit("lighthouse-gc-jobs")
 
 	var o options
-	fs.DurationVar(&o.maxAge, "max-age", 7*24*time.Hour, "Maximum age to keep LighthouseJobs.")
+	fs.DurationVar(&o.maxAge, "max-age", 7*24*time.Hour, "Maximum age to keep LighthouseJobs, used for jobs which never completed and as the default of the other TTLs.")
+	fs.DurationVar(&o.successTTL, "success-ttl", 0, "How long to keep successful LighthouseJobs after they completed. Defaults to --max-age.")
+	fs.DurationVar(&o.failureTTL, "failure-ttl", 0, "How long to keep failed, errored or aborted LighthouseJobs after they completed. Defaults to --max-age.")
+	fs.IntVar(&o.keepRecent, "keep-recent", 0, "Number of most recent completed LighthouseJobs to keep per repository and job regardless of their TTL.")
+	fs.StringVar(&o.keeperURL, "keeper-url", "", "URL of the keeper pools endpoint, jobs of pull requests still in a keeper pool are never deleted.")
+	fs.BoolVar(&o.cleanPipelineRuns, "clean-pipelineruns", true, "Whether to delete Tekton PipelineRuns left behind by deleted LighthouseJobs.")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Only log what would be deleted.")
+	fs.BoolVar(&o.leaderElect, "leader-elect", false, "Hold a Lease while collecting so that overlapping runs do not collect concurrently.")
 	fs.StringVar(&o.namespace, "namespace", "", "The namespace to listen in")
 
 	err := fs.Parse(args)
 	if err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
+	if o.successTTL == 0 {
+		o.successTTL = o.maxAge
+	}
+	if o.failureTTL == 0 {
+		o.failureTTL = o.maxAge
+	}
 
 	return o
 }
@@ -48,44 +76,52 @@ func main() {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
 
-	cfg, err := clients.GetConfig("", "")
+	tektonClient, kubeClient, lhClient, _, err := clients.GetAPIClients()
 	if err != nil {
-		logrus.WithError(err).Fatal("Could not create kubeconfig")
+		logrus.WithError(err).Fatal("Could not create API clients")
 	}
-	lhClient, err := clientset.NewForConfig(cfg)
-	if err != nil {
-		logrus.WithError(err).Fatal("Could not create Lighthouse API client")
-	}
-
-	lhInterface := lhClient.LighthouseV1alpha1().LighthouseJobs(o.namespace)
 
-	jobList, err := lhInterface.List(metav1.ListOptions{})
+	var elector *leaderelection.Elector
+	if o.leaderElect {
+		elector, err = leaderelection.NewElector(kubeClient, o.namespace, "lighthouse-gc-jobs")
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not create the leader elector")
+		}
+	}
+	// the pools are fetched once the lease is held so that they are up to date
+	err = elector.Run(context.Background(), func(ctx context.Context) {
+		collect(o, tektonClient, lhClient)
+	})
 	if err != nil {
-		logrus.WithError(err).Fatalf("Could not list LighthouseJobs in namespace %s", o.namespace)
+		logrus.WithError(err).Fatal("Failed to run the leader election")
 	}
+}
 
-	now := time.Now()
-
-	for _, job := range jobList.Items {
-		j := job
-		completionTime := j.Status.CompletionTime
-		if completionTime != nil && completionTime.Add(o.maxAge).Before(now) {
-			// The job completed at least maxAge ago, so delete it.
-			err = deleteLighthouseJob(lhInterface, &j)
-			if err != nil {
-				logrus.WithError(err).Fatalf("Failed to delete LighthouseJob %s", j.Name)
-			}
-		} else if completionTime == nil && j.Status.StartTime.Add(o.maxAge).Before(now) {
-			// The job never completed, but was created at least maxAge ago, so delete it.
-			err = deleteLighthouseJob(lhInterface, &j)
-			if err != nil {
-				logrus.WithError(err).Fatalf("Failed to delete LighthouseJob %s", j.Name)
-			}
+func collect(o options, tektonClient tektonclient.Interface, lhClient clientset.Interface) {
+	var protected func(*v1alpha1.LighthouseJob) bool
+	if o.keeperURL != "" {
+		pools, err := gc.FetchPools(&http.Client{Timeout: time.Minute}, o.keeperURL)
+		if err != nil {
+			// without the pools we cannot tell which jobs are still needed by keeper
+			logrus.WithError(err).Fatal("Could not get keeper pools")
 		}
+		protected = gc.InPool(gc.PoolPullRequests(pools))
 	}
-}
 
-func deleteLighthouseJob(lhInterface lhclient.LighthouseJobInterface, lhJob *v1alpha1.LighthouseJob) error {
-	logrus.Infof("Deleting LighthouseJob %s", lhJob.Name)
-	return lhInterface.Delete(lhJob.Name, metav1.NewDeleteOptions(0))
+	policy := gc.Policy{
+		SuccessTTL: o.successTTL,
+		FailureTTL: o.failureTTL,
+		PendingTTL: o.maxAge,
+		KeepRecent: o.keepRecent,
+	}
+	var collector *gc.Collector
+	lhInterface := lhClient.LighthouseV1alpha1().LighthouseJobs(o.namespace)
+	if o.cleanPipelineRuns {
+		collector = gc.NewCollector(lhInterface, tektonClient.TektonV1beta1().PipelineRuns(o.namespace), policy, protected, o.dryRun, nil)
+	} else {
+		collector = gc.NewCollector(lhInterface, nil, policy, protected, o.dryRun, nil)
+	}
+	if err := collector.Run(time.Now()); err != nil {
+		logrus.WithError(err).Fatalf("Failed to garbage collect LighthouseJobs in namespace %s", o.namespace)
+	}
 }
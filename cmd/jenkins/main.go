@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
@@ -29,6 +30,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jenkins-x/lighthouse/pkg/audit"
 	"github.com/jenkins-x/lighthouse/pkg/version"
 
 	"github.com/NYTimes/gziphandler"
@@ -36,8 +38,11 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/secret"
 	"github.com/jenkins-x/lighthouse/pkg/engines/jenkins"
+	"github.com/jenkins-x/lighthouse/pkg/health"
 	"github.com/jenkins-x/lighthouse/pkg/interrupts"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	lhmetrics "github.com/jenkins-x/lighthouse/pkg/metrics"
+	"github.com/jenkins-x/lighthouse/pkg/tracing"
 	"github.com/jenkins-x/lighthouse/pkg/watcher"
 
 	"github.com/sirupsen/logrus"
@@ -100,7 +105,7 @@ func gatherOptions() (options, error) {
 	fs.StringVar(&o.certFile, "cert-file", "", "Path to a PEM-encoded certificate file.")
 	fs.StringVar(&o.keyFile, "key-file", "", "Path to a PEM-encoded key file.")
 	fs.StringVar(&o.caCertFile, "ca-cert-file", "", "Path to a PEM-encoded CA certificate file.")
-	fs.BoolVar(&o.csrfProtect, "csrf-protect", false, "Request a CSRF protection token from Jenkins that will be used in all subsequent requests to Jenkins.")
+	fs.BoolVar(&o.csrfProtect, "csrf-protect", false, "Request a CSRF protection token from Jenkins that will be used in all subsequent requests to Jenkins. The token is renewed when Jenkins rejects it, and none is used if Jenkins does not issue any.")
 
 	fs.BoolVar(&o.dryRun, "dry-run", false, "Whether or not to make mutating API calls to GitHub/Kubernetes/Jenkins.")
 	err := fs.Parse(os.Args[1:])
@@ -122,6 +127,8 @@ func main() {
 	if err := o.Validate(); err != nil {
 		logrus.Fatalf("Invalid options: %v", err)
 	}
+	defer tracing.Init("lighthouse-jenkins-controller")()
+	defer audit.Init("lighthouse-jenkins-controller")()
 
 	defer interrupts.WaitForGracefulShutdown()
 
@@ -138,7 +145,7 @@ func main() {
 
 	cfg := configAgent.Config
 
-	_, _, lighthouseClientSet, _, err := clients.GetAPIClients()
+	_, kubeClient, lighthouseClientSet, _, err := clients.GetAPIClients()
 	if err != nil {
 		logrus.WithError(err).Fatal(err, "Error creating kubernetes resource clients.")
 	}
@@ -195,6 +202,15 @@ func main() {
 	// instead of baking agent-specific logic in deck
 	logMux := http.NewServeMux()
 	logMux.Handle("/", gziphandler.GzipHandler(handleLog(jc)))
+	logMux.Handle(lhmetrics.Path, lhmetrics.Handler())
+	checker := health.NewChecker()
+	checker.AddReadinessCheck("kubernetes", health.KubernetesCheck(kubeClient.Discovery()))
+	checker.AddReadinessCheck("config", health.ConfigCheck(watcher))
+	checker.AddReadinessCheck("jenkins", func(ctx context.Context) error {
+		_, err := jc.GetSkipMetrics("/api/json?tree=mode")
+		return err
+	})
+	checker.Register(logMux)
 	server := &http.Server{Addr: ":8080", Handler: logMux}
 	interrupts.ListenAndServe(server, 5*time.Second)
 
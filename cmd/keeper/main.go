@@ -17,20 +17,29 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/badge"
+	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	configutil "github.com/jenkins-x/lighthouse/pkg/config/util"
+	"github.com/jenkins-x/lighthouse/pkg/health"
 	"github.com/jenkins-x/lighthouse/pkg/interrupts"
 	"github.com/jenkins-x/lighthouse/pkg/jobutil"
 	"github.com/jenkins-x/lighthouse/pkg/keeper"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/githubapp"
+	"github.com/jenkins-x/lighthouse/pkg/leaderelection"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
 	"github.com/jenkins-x/lighthouse/pkg/metrics"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/jenkins-x/lighthouse/pkg/watcher"
 	"github.com/sirupsen/logrus"
@@ -46,7 +55,17 @@ type options struct {
 	gitKind       string
 	namespace     string
 
-	runOnce bool
+	runOnce     bool
+	leaderElect bool
+
+	etagCacheSize int
+
+	// shard, shardIndex and shardCount select the orgs and repos synced by this instance, shardPeers being the URLs
+	// of the instances of the other shards whose pools are merged with the pools of this one
+	shard      string
+	shardIndex int
+	shardCount int
+	shardPeers string
 
 	maxRecordsPerPool int
 	// historyURI where Keeper should store its action history.
@@ -65,7 +84,26 @@ type options struct {
 }
 
 func (o *options) Validate() error {
-	return nil
+	return o.Shard().Validate()
+}
+
+// Shard returns the shard of the instance, nil if it syncs all the orgs and repos
+func (o *options) Shard() *keeper.Shard {
+	if o.shard == "" && o.shardCount == 0 {
+		return nil
+	}
+	return &keeper.Shard{Name: o.shard, Index: o.shardIndex, Count: o.shardCount}
+}
+
+// ShardPeers returns the URLs of the instances of the other shards
+func (o *options) ShardPeers() []string {
+	var peers []string
+	for _, peer := range strings.Split(o.shardPeers, ",") {
+		if peer = strings.TrimSpace(peer); peer != "" {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
 }
 
 func gatherOptions(fs *flag.FlagSet, args ...string) options {
@@ -77,7 +115,14 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.gitServerURL, "git-url", "", "The git provider URL")
 	fs.StringVar(&o.gitKind, "git-kind", "", "The git provider kind (e.g. github, gitlab, bitbucketserver")
 	fs.BoolVar(&o.runOnce, "run-once", false, "If true, run only once then quit.")
+	fs.BoolVar(&o.leaderElect, "leader-elect", false, "Elect a leader among the replicas with a Lease so that only one of them syncs the pools, required to run more than one replica.")
+
+	fs.StringVar(&o.shard, "shard", "", "The name of the shard in the keeper config whose orgs and repos are synced by this instance.")
+	fs.IntVar(&o.shardIndex, "shard-index", 0, "The index of this instance among the --shard-count instances the orgs not assigned to a shard are split among by hash.")
+	fs.IntVar(&o.shardCount, "shard-count", 0, "The number of instances the orgs not assigned to a shard are split among by hash, 0 to not split them.")
+	fs.StringVar(&o.shardPeers, "shard-peers", "", "The comma separated URLs of the instances of the other shards whose pools are merged with the pools of this instance on "+keeper.MergedPoolsPath+".")
 
+	fs.IntVar(&o.etagCacheSize, "etag-cache-size", scmprovider.DefaultETagCacheSize, "The number of responses of the SCM provider cached to be revalidated with their ETag, the cache is disabled if 0")
 	fs.IntVar(&o.maxRecordsPerPool, "max-records-per-pool", 1000, "The maximum number of history records stored for an individual Keeper pool.")
 	fs.StringVar(&o.historyURI, "history-uri", "", "The /local/path or gs://path/to/object to store keeper action history. GCS writes will use the default object ACL for the bucket")
 	fs.StringVar(&o.statusURI, "status-path", "", "The /local/path or gs://path/to/object to store status controller state. GCS writes will use the default object ACL for the bucket.")
@@ -102,6 +147,8 @@ func main() {
 	if err := o.Validate(); err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
+	defer audit.Init("keeper")()
+	scmprovider.SetETagCacheSize(o.etagCacheSize)
 
 	configAgent := &config.Agent{}
 	cfgMapWatcher, err := watcher.SetupConfigMapWatchers(o.namespace, configAgent, nil)
@@ -137,27 +184,67 @@ func main() {
 	}
 
 	cfg := configAgent.Config
-	c, err := githubapp.NewKeeperController(configAgent, botName, gitKind, gitToken, serverURL, o.maxRecordsPerPool, o.historyURI, o.statusURI, o.namespace)
+	c, err := githubapp.NewKeeperController(configAgent, botName, gitKind, gitToken, serverURL, o.maxRecordsPerPool, o.historyURI, o.statusURI, o.namespace, o.Shard())
 	if err != nil {
 		logrus.WithError(err).Fatal("Error creating Keeper controller.")
 	}
 	defer c.Shutdown()
-	http.Handle("/", c)
-	http.Handle("/history", c.GetHistory())
+
+	_, kubeClient, lhClient, _, err := clients.GetAPIClients()
+	if err != nil {
+		logrus.WithError(err).Fatal("Error creating kubernetes resource clients.")
+	}
+	var elector *leaderelection.Elector
+	if o.leaderElect && !o.runOnce {
+		lease := "lighthouse-keeper"
+		if shard := o.Shard(); shard != nil {
+			// the instances of each shard elect their own leader
+			lease += "-" + shard.ID()
+		}
+		elector, err = leaderelection.NewElector(kubeClient, o.namespace, lease)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating the leader elector.")
+		}
+	}
+
+	// only the leader syncs the pools
+	http.Handle("/", elector.LeaderOnly(c))
+	http.Handle("/history", elector.LeaderOnly(c.GetHistory()))
+	http.Handle(watcher.StatusPath, cfgMapWatcher.StatusHandler())
+	http.Handle(metrics.Path, metrics.Handler())
+	// the pools of all the shards are merged so that any of them serves the status of all the pools
+	pools := keeper.MergePools(c.GetPools, o.ShardPeers(), nil)
+	http.Handle(keeper.MergedPoolsPath, elector.LeaderOnly(keeper.PoolsHandler(pools)))
+	http.Handle(badge.Path, elector.LeaderOnly(badge.NewHandler(lhClient, o.namespace, pools)))
+
+	scmClient, err := factory.NewClient(gitKind, serverURL, "")
+	if err != nil {
+		logrus.WithError(err).Fatal("Error creating the SCM client of the health checks.")
+	}
+	util.AddAuthToSCMClient(scmClient, gitToken, false)
+	checker := health.NewChecker()
+	checker.AddReadinessCheck("kubernetes", health.KubernetesCheck(kubeClient.Discovery()))
+	checker.AddReadinessCheck("config", health.ConfigCheck(cfgMapWatcher))
+	checker.AddReadinessCheck("scm", health.SCMCheck(scmClient))
+	checker.AddLivenessCheck("leader-election", elector.Check())
+	checker.Register(http.DefaultServeMux)
 	server := &http.Server{Addr: ":" + strconv.Itoa(o.port)}
 
-	start := time.Now()
-	sync(c)
 	if o.runOnce {
+		sync(c)
 		return
 	}
 
-	// run the controller, but only after one sync period expires after our first run
-	time.Sleep(time.Until(start.Add(cfg().Keeper.SyncPeriod)))
-	interrupts.Tick(func() {
-		sync(c)
-	}, func() time.Duration {
-		return cfg().Keeper.SyncPeriod
+	interrupts.Run(func(ctx context.Context) {
+		err := elector.Run(ctx, func(ctx context.Context) {
+			syncPeriodically(ctx, c, func() time.Duration {
+				return cfg().Keeper.SyncPeriod
+			})
+		})
+		if err != nil {
+			// the pools are synced by another replica from now on
+			logrus.WithError(err).Fatal("Error running the leader election.")
+		}
 	})
 
 	// Push metrics to the configured prometheus pushgateway endpoint or serve them
@@ -165,15 +252,27 @@ func main() {
 	if gateway.Endpoint != "" {
 		logrus.WithField("gateway", gateway.Endpoint).Infof("using push gateway")
 		go metrics.ExposeMetrics("keeper", gateway)
-
-		// serve data
-		interrupts.ListenAndServe(server, 10*time.Second)
 	} else {
 		logrus.Warn("not pushing metrics as there is no push_gateway defined in the config.yaml")
+	}
+
+	// serve data
+	interrupts.ListenAndServe(server, 10*time.Second)
 
-		// serve data
-		err := server.ListenAndServe()
-		logrus.WithError(err).Errorf("failed to server HTTP")
+	// wait for the sync in progress before the history is flushed by the deferred shutdown of the controller
+	interrupts.WaitForGracefulShutdown()
+}
+
+// syncPeriodically syncs the pools every sync period until ctx is done
+func syncPeriodically(ctx context.Context, c keeper.Controller, period func() time.Duration) {
+	for {
+		start := time.Now()
+		sync(c)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(start.Add(period()))):
+		}
 	}
 }
 
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/labelsync"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+type options struct {
+	labelsPath   string
+	configPath   string
+	pluginConfig string
+	repos        string
+	dryRun       bool
+}
+
+func (o *options) Validate() error {
+	if o.labelsPath == "" {
+		return fmt.Errorf("no --labels-path given")
+	}
+	if o.pluginConfig == "" && o.repos == "" {
+		return fmt.Errorf("neither --plugin-config nor --repos given")
+	}
+	return nil
+}
+
+func gatherOptions(fs *flag.FlagSet, args ...string) options {
+	logrusutil.ComponentInit("lighthouse-label-sync")
+
+	var o options
+	fs.StringVar(&o.labelsPath, "labels-path", "", "Path to the labels.yaml declaring the labels of the repositories.")
+	fs.StringVar(&o.configPath, "config-path", "", "Path to config.yaml, used for the kind and the URL of the git provider.")
+	fs.StringVar(&o.pluginConfig, "plugin-config", "", "Path to plugins.yaml, the labels of the orgs and repos the plugins are enabled for are synced.")
+	fs.StringVar(&o.repos, "repos", "", "Comma separated orgs or org/repos whose labels are synced in addition to the ones of --plugin-config.")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Only log the labels which would be created, updated or renamed.")
+
+	err := fs.Parse(args)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+	return o
+}
+
+func main() {
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
+	if err := o.Validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+
+	labels, err := labelsync.Load(o.labelsPath)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not load the labels")
+	}
+	var cfg *config.Config
+	if o.configPath != "" {
+		cfg, err = config.Load(o.configPath, "")
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not load the config")
+		}
+	}
+	targets, err := gatherTargets(o)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not gather the repositories")
+	}
+
+	failed := false
+	for _, org := range sortedOrgs(targets) {
+		// the tokens of a GitHub App are per owner
+		scmClient, _, _, _, err := util.GetSCMClient(org, func() *config.Config { return cfg })
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not create the SCM client")
+		}
+		syncer := labelsync.NewSyncer(scmClient, labels, o.dryRun, nil)
+		repos, err := syncer.Repos(targets[org])
+		if err != nil {
+			logrus.WithError(err).Errorf("Could not list the repositories of %s", org)
+			failed = true
+			continue
+		}
+		changed, err := syncer.Sync(repos)
+		logrus.WithField("org", org).Infof("changed %d labels of %d repositories", changed, len(repos))
+		if err != nil {
+			failed = true
+		}
+	}
+	if failed {
+		logrus.Fatal("Failed to sync the labels of some repositories")
+	}
+}
+
+// gatherTargets returns the orgs and org/repos whose labels are synced, by org
+func gatherTargets(o options) (map[string][]string, error) {
+	var targets []string
+	if o.pluginConfig != "" {
+		data, err := ioutil.ReadFile(o.pluginConfig)
+		if err != nil {
+			return nil, err
+		}
+		pluginCfg := &plugins.Configuration{}
+		if err := yaml.Unmarshal(data, pluginCfg); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", o.pluginConfig, err)
+		}
+		for target := range pluginCfg.Plugins {
+			targets = append(targets, target)
+		}
+	}
+	for _, target := range strings.Split(o.repos, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	answer := map[string][]string{}
+	for _, target := range targets {
+		org := strings.SplitN(target, "/", 2)[0]
+		answer[org] = append(answer[org], target)
+	}
+	return answer, nil
+}
+
+func sortedOrgs(targets map[string][]string) []string {
+	var answer []string
+	for org := range targets {
+		answer = append(answer, org)
+	}
+	sort.Strings(answer)
+	return answer
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/contextmigrate"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
+)
+
+type contextsOptions struct {
+	configPath    string
+	jobConfigPath string
+	repos         string
+	dryRun        bool
+}
+
+func (o *contextsOptions) Validate() error {
+	if o.configPath == "" {
+		return fmt.Errorf("no --config-path given")
+	}
+	return nil
+}
+
+func gatherContextsOptions(fs *flag.FlagSet, args ...string) (contextsOptions, error) {
+	var o contextsOptions
+	fs.StringVar(&o.configPath, "config-path", "", "Path to the Lighthouse config.yaml, used for the kind and the URL of the git provider.")
+	fs.StringVar(&o.jobConfigPath, "job-config-path", "", "Path to the job config file or directory declaring the previous_contexts of the presubmits.")
+	fs.StringVar(&o.repos, "repos", "", "Comma separated org/repos to migrate, all the repositories with renamed contexts if not given.")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Only print the contexts which would be migrated.")
+	if err := fs.Parse(args); err != nil {
+		return o, err
+	}
+	return o, o.Validate()
+}
+
+// migrateContexts migrates the statuses of the renamed contexts of the presubmits on the open pull requests
+func migrateContexts(o *contextsOptions, out io.Writer) error {
+	cfg, err := config.Load(o.configPath, o.jobConfigPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to load the config")
+	}
+	var repos []string
+	for _, repo := range strings.Split(o.repos, ",") {
+		if repo = strings.TrimSpace(repo); repo != "" {
+			repos = append(repos, repo)
+		}
+	}
+	if len(repos) == 0 {
+		repos = renamedRepos(cfg)
+	}
+	if len(repos) == 0 {
+		fmt.Fprintln(out, "No presubmit has previous_contexts")
+		return nil
+	}
+
+	failed := 0
+	for _, fullName := range repos {
+		parts := strings.Split(fullName, "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid repository %q, expected org/repo", fullName)
+		}
+		// the tokens of a GitHub App are per owner
+		scmClient, _, _, _, err := util.GetSCMClient(parts[0], func() *config.Config { return cfg })
+		if err != nil {
+			return errors.Wrap(err, "failed to create the SCM client")
+		}
+		migrated, err := contextmigrate.NewMigrator(scmClient, o.dryRun, nil).Migrate(parts[0], parts[1], cfg.RepoPresubmits(fullName))
+		if err != nil {
+			fmt.Fprintf(out, "ERROR: %v\n", err)
+			failed++
+		}
+		verb := "Migrated"
+		if o.dryRun {
+			verb = "Would migrate"
+		}
+		fmt.Fprintf(out, "%s %d contexts of the open pull requests of %s\n", verb, migrated, fullName)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to migrate the contexts of %d repositories", failed)
+	}
+	return nil
+}
+
+// renamedRepos returns the repositories declaring presubmits with previous contexts
+func renamedRepos(cfg *config.Config) []string {
+	var repos []string
+	for fullName := range cfg.Presubmits {
+		if !strings.Contains(fullName, "/") {
+			// the repositories inheriting the presubmits of an org must be given with --repos
+			continue
+		}
+		for _, p := range cfg.RepoPresubmits(fullName) {
+			if len(p.PreviousContexts) > 0 {
+				repos = append(repos, fullName)
+				break
+			}
+		}
+	}
+	sort.Strings(repos)
+	return repos
+}
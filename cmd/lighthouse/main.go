@@ -0,0 +1,171 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jenkins-x/lighthouse/pkg/configcheck"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const usage = `usage: lighthouse config check --config-path=config.yaml [--job-config-path=jobs] [--plugin-config=plugins.yaml]
+       [--base-config-path=old/config.yaml [--base-job-config-path=old/jobs] [--base-plugin-config=old/plugins.yaml]]
+   or: lighthouse config migrate --config-path=prow/config.yaml [--job-config-path=prow/jobs]
+       [--plugin-config=prow/plugins.yaml] --output-dir=lighthouse
+   or: lighthouse simulate --config-path=config.yaml [--job-config-path=jobs] --plugin-config=plugins.yaml
+       --payload=webhook.json (--event=issue_comment | --header='Name: value'...) [--fake-scm] [--output=text|json]
+   or: lighthouse trigger --url=https://lighthouse.example.com --job=name [--repo=org/repo [--branch=main | --pr=1]]
+       [--hmac-token-path=hmac] [--requested-by=user]
+   or: lighthouse plugin new [--dir=.] name
+   or: lighthouse contexts migrate --config-path=config.yaml [--job-config-path=jobs] [--repos=org/repo,...] [--dry-run]`
+
+type checkOptions struct {
+	configPath    string
+	jobConfigPath string
+	pluginConfig  string
+
+	baseConfigPath    string
+	baseJobConfigPath string
+	basePluginConfig  string
+}
+
+func (o *checkOptions) Validate() error {
+	if o.configPath == "" {
+		return fmt.Errorf("no --config-path given")
+	}
+	if o.baseConfigPath == "" && (o.baseJobConfigPath != "" || o.basePluginConfig != "") {
+		return fmt.Errorf("--base-job-config-path and --base-plugin-config require --base-config-path")
+	}
+	return nil
+}
+
+func gatherCheckOptions(fs *flag.FlagSet, args ...string) (checkOptions, error) {
+	var o checkOptions
+	fs.StringVar(&o.configPath, "config-path", "", "Path to the Lighthouse config.yaml to check.")
+	fs.StringVar(&o.jobConfigPath, "job-config-path", "", "Path to the job config file or directory to check.")
+	fs.StringVar(&o.pluginConfig, "plugin-config", "", "Path to the plugins.yaml to check.")
+	fs.StringVar(&o.baseConfigPath, "base-config-path", "", "Path to the previous config.yaml to print the changes in effective behavior against.")
+	fs.StringVar(&o.baseJobConfigPath, "base-job-config-path", "", "Path to the previous job config file or directory.")
+	fs.StringVar(&o.basePluginConfig, "base-plugin-config", "", "Path to the previous plugins.yaml.")
+	if err := fs.Parse(args); err != nil {
+		return o, err
+	}
+	return o, o.Validate()
+}
+
+func main() {
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "simulate" {
+		o, err := gatherSimulateOptions(flag.NewFlagSet("lighthouse simulate", flag.ExitOnError), args[1:]...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n%s\n", err, usage)
+			os.Exit(2)
+		}
+		if err := simulate(&o, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "trigger" {
+		o, err := gatherTriggerOptions(flag.NewFlagSet("lighthouse trigger", flag.ExitOnError), args[1:]...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n%s\n", err, usage)
+			os.Exit(2)
+		}
+		if err := trigger(&o, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) > 1 && args[0] == "plugin" && args[1] == "new" {
+		o, err := gatherPluginOptions(flag.NewFlagSet("lighthouse plugin new", flag.ExitOnError), args[2:]...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n%s\n", err, usage)
+			os.Exit(2)
+		}
+		if err := newPlugin(&o, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) > 1 && args[0] == "contexts" && args[1] == "migrate" {
+		o, err := gatherContextsOptions(flag.NewFlagSet("lighthouse contexts migrate", flag.ExitOnError), args[2:]...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n%s\n", err, usage)
+			os.Exit(2)
+		}
+		if err := migrateContexts(&o, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) > 1 && args[0] == "config" && args[1] == "migrate" {
+		o, err := gatherMigrateOptions(flag.NewFlagSet("lighthouse config migrate", flag.ExitOnError), args[2:]...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n%s\n", err, usage)
+			os.Exit(2)
+		}
+		if !migrate(&o, os.Stdout) {
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) < 2 || args[0] != "config" || args[1] != "check" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	o, err := gatherCheckOptions(flag.NewFlagSet("lighthouse config check", flag.ExitOnError), args[2:]...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n%s\n", err, usage)
+		os.Exit(2)
+	}
+	if !check(&o, os.Stdout) {
+		os.Exit(1)
+	}
+}
+
+// check validates the configuration and prints the changes in effective behavior, returning false if it is invalid
+func check(o *checkOptions, out io.Writer) bool {
+	cfg, pluginCfg, err := configcheck.Load(o.configPath, o.jobConfigPath, o.pluginConfig)
+	if err != nil {
+		fmt.Fprintf(out, "ERROR: %v\n", err)
+		return false
+	}
+
+	knownPlugins := sets.StringKeySet(plugins.HelpProviders()).List()
+	errs := configcheck.Validate(cfg, pluginCfg, knownPlugins)
+	for _, err := range errs {
+		fmt.Fprintf(out, "ERROR: %v\n", err)
+	}
+
+	if o.baseConfigPath != "" {
+		baseCfg, basePluginCfg, err := configcheck.Load(o.baseConfigPath, o.baseJobConfigPath, o.basePluginConfig)
+		if err != nil {
+			fmt.Fprintf(out, "ERROR: cannot load the base configuration to diff against: %v\n", err)
+			return false
+		}
+		lines := configcheck.Diff(configcheck.Describe(baseCfg, basePluginCfg), configcheck.Describe(cfg, pluginCfg))
+		if len(lines) == 0 {
+			fmt.Fprintln(out, "No changes in effective behavior")
+		} else {
+			fmt.Fprintln(out, "Changes in effective behavior:")
+			for _, line := range lines {
+				fmt.Fprintln(out, line)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		fmt.Fprintf(out, "%d problems found\n", len(errs))
+		return false
+	}
+	fmt.Fprintln(out, "Configuration is valid")
+	return true
+}
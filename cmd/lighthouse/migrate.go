@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/lighthouse/pkg/configcheck"
+	"github.com/jenkins-x/lighthouse/pkg/configmigrate"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type migrateOptions struct {
+	configPath    string
+	jobConfigPath string
+	pluginConfig  string
+	outputDir     string
+}
+
+func (o *migrateOptions) Validate() error {
+	if o.configPath == "" {
+		return fmt.Errorf("no --config-path given")
+	}
+	if o.outputDir == "" {
+		return fmt.Errorf("no --output-dir given")
+	}
+	return nil
+}
+
+func gatherMigrateOptions(fs *flag.FlagSet, args ...string) (migrateOptions, error) {
+	var o migrateOptions
+	fs.StringVar(&o.configPath, "config-path", "", "Path to the Prow config.yaml to migrate.")
+	fs.StringVar(&o.jobConfigPath, "job-config-path", "", "Path to the Prow job config file or directory to migrate.")
+	fs.StringVar(&o.pluginConfig, "plugin-config", "", "Path to the Prow plugins.yaml to migrate.")
+	fs.StringVar(&o.outputDir, "output-dir", "", "Directory the Lighthouse config.yaml, plugins.yaml and job config are written to.")
+	if err := fs.Parse(args); err != nil {
+		return o, err
+	}
+	return o, o.Validate()
+}
+
+// migrate converts the Prow configuration into the output directory, printing what could not be migrated, then checks
+// the Lighthouse configuration, returning false if it could not be converted or is invalid
+func migrate(o *migrateOptions, out io.Writer) bool {
+	if err := os.MkdirAll(o.outputDir, 0755); err != nil {
+		fmt.Fprintf(out, "ERROR: failed to create %s: %v\n", o.outputDir, err)
+		return false
+	}
+	configPath := filepath.Join(o.outputDir, "config.yaml")
+	if err := migrateFile(o.configPath, configPath, configmigrate.Config, out); err != nil {
+		fmt.Fprintf(out, "ERROR: %v\n", err)
+		return false
+	}
+
+	jobConfigPath := ""
+	if o.jobConfigPath != "" {
+		jobConfigPath = filepath.Join(o.outputDir, "jobs")
+		if err := migrateJobConfig(o.jobConfigPath, jobConfigPath, out); err != nil {
+			fmt.Fprintf(out, "ERROR: %v\n", err)
+			return false
+		}
+	}
+
+	knownPlugins := sets.StringKeySet(plugins.HelpProviders()).List()
+	pluginConfig := ""
+	if o.pluginConfig != "" {
+		pluginConfig = filepath.Join(o.outputDir, "plugins.yaml")
+		convert := func(data []byte) ([]byte, []string, error) {
+			return configmigrate.Plugins(data, knownPlugins)
+		}
+		if err := migrateFile(o.pluginConfig, pluginConfig, convert, out); err != nil {
+			fmt.Fprintf(out, "ERROR: %v\n", err)
+			return false
+		}
+	}
+
+	cfg, pluginCfg, err := configcheck.Load(configPath, jobConfigPath, pluginConfig)
+	if err != nil {
+		fmt.Fprintf(out, "ERROR: the migrated configuration does not load: %v\n", err)
+		return false
+	}
+	errs := configcheck.Validate(cfg, pluginCfg, knownPlugins)
+	for _, err := range errs {
+		fmt.Fprintf(out, "ERROR: %v\n", err)
+	}
+	if len(errs) > 0 {
+		fmt.Fprintf(out, "%d problems found in the migrated configuration\n", len(errs))
+		return false
+	}
+	fmt.Fprintf(out, "Migrated the configuration to %s\n", o.outputDir)
+	return true
+}
+
+// migrateJobConfig converts the job config file or each YAML file of the job config directory
+func migrateJobConfig(from, to string, out io.Writer) error {
+	stat, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	if !stat.IsDir() {
+		return migrateFile(from, filepath.Join(to, filepath.Base(from)), configmigrate.JobConfig, out)
+	}
+	return filepath.Walk(from, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || (filepath.Ext(path) != ".yaml" && filepath.Ext(path) != ".yml") {
+			return nil
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		return migrateFile(path, filepath.Join(to, rel), configmigrate.JobConfig, out)
+	})
+}
+
+// migrateFile converts the file, printing the warnings prefixed with its path
+func migrateFile(from, to string, convert func([]byte) ([]byte, []string, error), out io.Writer) error {
+	data, err := ioutil.ReadFile(from) // #nosec
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", from)
+	}
+	converted, warnings, err := convert(data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to migrate %s", from)
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(out, "WARNING: %s: %s\n", from, w)
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create the directory of %s", to)
+	}
+	if err := ioutil.WriteFile(to, converted, 0644); err != nil { //nolint:gosec
+		return errors.Wrapf(err, "failed to write %s", to)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/jenkins-x/lighthouse/pkg/pluginscaffold"
+)
+
+type pluginOptions struct {
+	dir  string
+	name string
+}
+
+func (o *pluginOptions) Validate() error {
+	if o.name == "" {
+		return fmt.Errorf("no plugin name given")
+	}
+	return nil
+}
+
+func gatherPluginOptions(fs *flag.FlagSet, args ...string) (pluginOptions, error) {
+	var o pluginOptions
+	fs.StringVar(&o.dir, "dir", ".", "Path to the root of the Lighthouse repository.")
+	if err := fs.Parse(args); err != nil {
+		return o, err
+	}
+	if fs.NArg() > 1 {
+		return o, fmt.Errorf("unexpected arguments %v", fs.Args()[1:])
+	}
+	o.name = fs.Arg(0)
+	return o, o.Validate()
+}
+
+// newPlugin generates the skeleton of the plugin and prints the files created or changed
+func newPlugin(o *pluginOptions, out io.Writer) error {
+	files, err := pluginscaffold.Generate(o.dir, o.name)
+	for _, f := range files {
+		fmt.Fprintf(out, "Wrote %s\n", f)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Generated the %s plugin, run make plugins-docs to document its configuration\n", o.name)
+	return nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	lhfake "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/configcheck"
+	"github.com/jenkins-x/lighthouse/pkg/git"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/jenkins-x/lighthouse/pkg/webhook"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// eventHeaders are the headers of the webhooks of the git providers holding the kind of event
+var eventHeaders = map[string]string{
+	"bitbucketcloud":  "X-Event-Key",
+	"bitbucketserver": "X-Event-Key",
+	"gitea":           "X-Gitea-Event",
+	"github":          "X-GitHub-Event",
+	"gitlab":          "X-Gitlab-Event",
+}
+
+// headers are the headers given with repeated flags
+type headers []string
+
+func (h *headers) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headers) Set(value string) error {
+	if !strings.Contains(value, ":") {
+		return fmt.Errorf("the header %q is not of the form Name: value", value)
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+type simulateOptions struct {
+	configPath    string
+	jobConfigPath string
+	pluginConfig  string
+	payload       string
+	event         string
+	headers       headers
+	namespace     string
+	fakeSCM       bool
+	output        string
+}
+
+func (o *simulateOptions) Validate() error {
+	if o.configPath == "" {
+		return fmt.Errorf("no --config-path given")
+	}
+	if o.pluginConfig == "" {
+		return fmt.Errorf("no --plugin-config given")
+	}
+	if o.payload == "" {
+		return fmt.Errorf("no --payload given")
+	}
+	if o.event == "" && len(o.headers) == 0 {
+		return fmt.Errorf("neither --event nor --header given")
+	}
+	if o.output != "text" && o.output != "json" {
+		return fmt.Errorf("invalid --output %q, expected text or json", o.output)
+	}
+	return nil
+}
+
+func gatherSimulateOptions(fs *flag.FlagSet, args ...string) (simulateOptions, error) {
+	var o simulateOptions
+	fs.StringVar(&o.configPath, "config-path", "", "Path to the Lighthouse config.yaml.")
+	fs.StringVar(&o.jobConfigPath, "job-config-path", "", "Path to the job config file or directory.")
+	fs.StringVar(&o.pluginConfig, "plugin-config", "", "Path to the plugins.yaml.")
+	fs.StringVar(&o.payload, "payload", "", "Path to the saved body of the webhook.")
+	fs.StringVar(&o.event, "event", "", "Kind of event of the webhook, e.g. issue_comment for GitHub or Note Hook for GitLab.")
+	fs.Var(&o.headers, "header", "Header of the webhook of the form 'Name: value', may be repeated.")
+	fs.StringVar(&o.namespace, "namespace", "jx", "Namespace of the LighthouseJobs the plugins would create.")
+	fs.BoolVar(&o.fakeSCM, "fake-scm", false, "Use an in-memory fake git provider seeded with the pull request of the webhook rather than reading from the git provider.")
+	fs.StringVar(&o.output, "output", "text", "Output format of the actions, text or json.")
+	if err := fs.Parse(args); err != nil {
+		return o, err
+	}
+	return o, o.Validate()
+}
+
+// simulate handles the saved webhook with the plugins and prints the actions they would have taken
+func simulate(o *simulateOptions, out io.Writer) error {
+	cfg, pluginCfg, err := configcheck.Load(o.configPath, o.jobConfigPath, o.pluginConfig)
+	if err != nil {
+		return err
+	}
+	configAgent := &config.Agent{}
+	configAgent.Set(cfg)
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(pluginCfg)
+
+	hook, err := parseWebhook(o, configAgent.Config)
+	if err != nil {
+		return err
+	}
+	clientAgent, err := simulationClients(o, configAgent.Config, hook)
+	if err != nil {
+		return err
+	}
+	if clientAgent.GitClient != nil {
+		defer clientAgent.GitClient.Clean() //nolint:errcheck
+	}
+	serverURL, err := url.Parse(util.GetGitServer(configAgent.Config))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse the git server URL")
+	}
+	server := &webhook.Server{
+		ConfigAgent: configAgent,
+		Plugins:     pluginAgent,
+		ClientAgent: clientAgent,
+		ServerURL:   serverURL,
+	}
+
+	actions, err := server.Simulate(logrus.WithField("Webhook", hook.Kind()), hook)
+	if err != nil {
+		return errors.Wrap(err, "failed to handle the webhook")
+	}
+	if o.output == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(actions)
+	}
+	if len(actions) == 0 {
+		fmt.Fprintln(out, "No actions")
+		return nil
+	}
+	for _, a := range actions {
+		plugin := a.Plugin
+		if plugin == "" {
+			plugin = "-"
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", plugin, a.Action, a.Target, a.Details)
+	}
+	return nil
+}
+
+// parseWebhook parses the saved webhook with the driver of the git provider of the configuration
+func parseWebhook(o *simulateOptions, cfg config.Getter) (scm.Webhook, error) {
+	body, err := ioutil.ReadFile(o.payload)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the payload %s", o.payload)
+	}
+	kind := util.GitKind(cfg)
+	parser, err := factory.NewClient(kind, util.GetGitServer(cfg), "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the %s client", kind)
+	}
+	req, err := http.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if o.event != "" {
+		name, ok := eventHeaders[kind]
+		if !ok {
+			return nil, fmt.Errorf("unknown event header of %s, use --header instead of --event", kind)
+		}
+		req.Header.Set(name, o.event)
+		req.Header.Set("X-GitHub-Delivery", "simulated")
+	}
+	for _, h := range o.headers {
+		parts := strings.SplitN(h, ":", 2)
+		req.Header.Set(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	// the payload is trusted so its signature is not validated
+	hook, err := parser.Webhooks.Parse(req, func(scm.Webhook) (string, error) {
+		return "", nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the webhook")
+	}
+	if hook == nil {
+		return nil, errors.New("no webhook could be parsed")
+	}
+	return hook, nil
+}
+
+// simulationClients creates the clients of the plugins, the Kubernetes clients being in-memory fakes
+func simulationClients(o *simulateOptions, cfg config.Getter, hook scm.Webhook) (*plugins.ClientAgent, error) {
+	answer := &plugins.ClientAgent{
+		BotName:          util.GetBotName(cfg),
+		KubernetesClient: kubefake.NewSimpleClientset(),
+		LighthouseClient: lhfake.NewSimpleClientset().LighthouseV1alpha1().LighthouseJobs(o.namespace),
+	}
+	if o.fakeSCM {
+		scmClient, data := fakescm.NewDefault()
+		if pr := webhookPullRequest(hook); pr != nil {
+			data.PullRequests[pr.Number] = pr
+		}
+		answer.SCMProviderClient = scmClient
+		return answer, nil
+	}
+
+	_, scmClient, serverURL, token, err := util.GetSCMClient(hook.Repository().Namespace, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the SCM client")
+	}
+	answer.SCMProviderClient = scmClient
+	answer.GitClient, err = git.NewClient(serverURL, util.GitKind(cfg))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the git client")
+	}
+	answer.GitClient.SetCredentials(answer.BotName, func() []byte {
+		return []byte(token)
+	})
+	return answer, nil
+}
+
+// webhookPullRequest returns the pull request of the webhook, if any
+func webhookPullRequest(hook scm.Webhook) *scm.PullRequest {
+	switch h := hook.(type) {
+	case *scm.PullRequestHook:
+		return &h.PullRequest
+	case *scm.PullRequestCommentHook:
+		return &h.PullRequest
+	case *scm.ReviewHook:
+		return &h.PullRequest
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/webhook"
+	"github.com/pkg/errors"
+)
+
+type triggerOptions struct {
+	url           string
+	hmacTokenPath string
+	trigger       webhook.ManualTrigger
+}
+
+func (o *triggerOptions) Validate() error {
+	if o.url == "" {
+		return fmt.Errorf("no --url given")
+	}
+	if err := o.trigger.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func gatherTriggerOptions(fs *flag.FlagSet, args ...string) (triggerOptions, error) {
+	var o triggerOptions
+	fs.StringVar(&o.url, "url", "", "URL of the Lighthouse webhooks server, e.g. https://lighthouse.example.com.")
+	fs.StringVar(&o.hmacTokenPath, "hmac-token-path", "", "Path to the file holding the HMAC token, the HMAC_TOKEN environment variable being used if not given.")
+	fs.StringVar(&o.trigger.Job, "job", "", "Name of the presubmit, postsubmit or periodic to trigger.")
+	fs.StringVar(&o.trigger.Repo, "repo", "", "Full name of the repository of the presubmit or postsubmit, e.g. myorg/myrepo.")
+	fs.StringVar(&o.trigger.Branch, "branch", "", "Branch the postsubmit runs against, the default branch of the repository if not given.")
+	fs.IntVar(&o.trigger.PR, "pr", 0, "Number of the pull request the presubmit runs against.")
+	fs.StringVar(&o.trigger.RequestedBy, "requested-by", os.Getenv("USER"), "User triggering the job, recorded in the audit log.")
+	if err := fs.Parse(args); err != nil {
+		return o, err
+	}
+	return o, o.Validate()
+}
+
+// trigger asks the webhooks server to create the LighthouseJob and prints it
+func trigger(o *triggerOptions, out io.Writer) error {
+	token := os.Getenv("HMAC_TOKEN")
+	if o.hmacTokenPath != "" {
+		data, err := ioutil.ReadFile(o.hmacTokenPath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the HMAC token %s", o.hmacTokenPath)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token == "" {
+		return errors.New("no HMAC token given, use --hmac-token-path or the HMAC_TOKEN environment variable")
+	}
+
+	body, err := json.Marshal(&o.trigger)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the request")
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(o.url, "/")+webhook.ManualTriggerPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, []byte(token))
+	_, _ = mac.Write(body)
+	req.Header.Set(webhook.ManualTriggerSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to request the job")
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("the job was not triggered: %s", strings.TrimSpace(string(data)))
+	}
+	job := &v1alpha1.LighthouseJob{}
+	if err := json.Unmarshal(data, job); err != nil {
+		return errors.Wrap(err, "failed to parse the LighthouseJob")
+	}
+	fmt.Fprintf(out, "Triggered %s %s as LighthouseJob %s\n", job.Spec.Type, job.Spec.Job, job.Name)
+	return nil
+}
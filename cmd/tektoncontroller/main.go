@@ -7,11 +7,16 @@ import (
 	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/clients"
 	tektonengine "github.com/jenkins-x/lighthouse/pkg/engines/tekton"
+	"github.com/jenkins-x/lighthouse/pkg/engines/tekton/tektonv1"
+	"github.com/jenkins-x/lighthouse/pkg/health"
 	"github.com/jenkins-x/lighthouse/pkg/interrupts"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/metrics"
+	"github.com/jenkins-x/lighthouse/pkg/tracing"
 	"github.com/sirupsen/logrus"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -19,6 +24,8 @@ type options struct {
 	namespace         string
 	dashboardURL      string
 	dashboardTemplate string
+	apiVersion        string
+	metricsPort       int
 }
 
 func (o *options) Validate() error {
@@ -28,8 +35,10 @@ func (o *options) Validate() error {
 func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	var o options
 	fs.StringVar(&o.namespace, "namespace", "", "The namespace to listen in")
+	fs.IntVar(&o.metricsPort, "metrics-port", 8080, "The port to serve the metrics on")
 	fs.StringVar(&o.dashboardURL, "dashboard-url", "", "The base URL for the Tekton Dashboard to link to for build reports")
 	fs.StringVar(&o.dashboardTemplate, "dashboard-template", "", "The template expression for generating the URL to the build report based on the PipelineRun parameters. If not specified defaults to $LIGHTHOUSE_DASHBOARD_TEMPLATE")
+	fs.StringVar(&o.apiVersion, "pipeline-run-api-version", tektonengine.V1APIVersion, "The API version of the PipelineRuns, v1 or v1beta1 for the Tekton releases which do not serve the tekton.dev/v1 API")
 	err := fs.Parse(args)
 	if err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
@@ -48,28 +57,44 @@ func main() {
 	if err := pipelinev1beta1.AddToScheme(scheme); err != nil {
 		logrus.WithError(err).Fatal("Failed to register scheme")
 	}
+	if err := tektonv1.AddToScheme(scheme); err != nil {
+		logrus.WithError(err).Fatal("Failed to register scheme")
+	}
 
 	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
 	if err := o.Validate(); err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
+	defer tracing.Init("lighthouse-tekton-controller")()
 
 	cfg, err := clients.GetConfig("", "")
 	if err != nil {
 		logrus.WithError(err).Fatal("Could not create kubeconfig")
 	}
 
-	mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme, Namespace: o.namespace})
+	// the metrics of the manager are served along with the lighthouse ones
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme, Namespace: o.namespace, MetricsBindAddress: "0"})
 	if err != nil {
 		logrus.WithError(err).Fatal("Unable to start manager")
 	}
 
 	reconciler := tektonengine.NewLighthouseJobReconciler(mgr.GetClient(), mgr.GetAPIReader(), mgr.GetScheme(), o.dashboardURL, o.dashboardTemplate, o.namespace)
+	if err := reconciler.SetPipelineRunAPIVersion(o.apiVersion); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
 	if err = reconciler.SetupWithManager(mgr); err != nil {
 		logrus.WithError(err).Fatal("Unable to create controller")
 	}
 
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatal("Unable to create the kubernetes client of the health checks")
+	}
+	checker := health.NewChecker()
+	checker.AddReadinessCheck("kubernetes", health.KubernetesCheck(kubeClient.Discovery()))
+
 	defer interrupts.WaitForGracefulShutdown()
+	metrics.Serve(o.metricsPort, checker.Register)
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		logrus.WithError(err).Fatal("Problem running manager")
 	}
@@ -2,12 +2,27 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/jenkins-x/lighthouse/pkg/dashboard"
+	"github.com/jenkins-x/lighthouse/pkg/engines/jenkins"
+	"github.com/jenkins-x/lighthouse/pkg/envelope"
 	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	"github.com/jenkins-x/lighthouse/pkg/joblogs"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/metrics"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/tracing"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/crdconfig"
+	"github.com/jenkins-x/lighthouse/pkg/watcher"
 	"github.com/jenkins-x/lighthouse/pkg/webhook"
 	"github.com/sirupsen/logrus"
 )
@@ -29,12 +44,104 @@ type options struct {
 	pluginFilename string
 	configFilename string
 	botName        string
+
+	configResources   bool
+	admissionPort     int
+	admissionCertFile string
+	admissionKeyFile  string
+
+	debugPort int
+
+	shutdownDelay   time.Duration
+	shutdownTimeout time.Duration
+
+	permissionCacheTTL time.Duration
+	etagCacheSize      int
+
+	actionQueueDir     string
+	actionQueueWorkers int
+
+	webhookArchiveDir    string
+	webhookArchiveMaxAge time.Duration
+
+	encryptionKeyFile string
+	vaultAddress      string
+	vaultTransitMount string
+	vaultTransitKey   string
+	vaultTokenFile    string
+
+	codeFreezeSyncPeriod time.Duration
+
+	jenkinsUserName        string
+	jenkinsTokenFile       string
+	jenkinsBearerTokenFile string
 }
 
 func (o *options) Validate() error {
+	if o.admissionPort != 0 && (o.admissionCertFile == "" || o.admissionKeyFile == "") {
+		return fmt.Errorf("--admission-cert-file and --admission-key-file are required with --admission-port")
+	}
+	if o.encryptionKeyFile != "" && o.vaultTransitKey != "" {
+		return fmt.Errorf("--encryption-key-file and --vault-transit-key are mutually exclusive")
+	}
+	if o.vaultTransitKey != "" && (o.vaultAddress == "" || o.vaultTokenFile == "") {
+		return fmt.Errorf("--vault-address and --vault-token-file are required with --vault-transit-key")
+	}
+	if o.jenkinsTokenFile != "" && o.jenkinsBearerTokenFile != "" {
+		return fmt.Errorf("only one of --jenkins-token-file or --jenkins-bearer-token-file can be set")
+	}
 	return nil
 }
 
+// jenkinsAuth returns the credentials the live logs of the Jenkins builds are read with, nil if none is configured
+func (o *options) jenkinsAuth() (*jenkins.AuthConfig, error) {
+	var tokenFile string
+	switch {
+	case o.jenkinsTokenFile != "":
+		tokenFile = o.jenkinsTokenFile
+	case o.jenkinsBearerTokenFile != "":
+		tokenFile = o.jenkinsBearerTokenFile
+	default:
+		return nil, nil
+	}
+	secretAgent := &secret.Agent{}
+	if err := secretAgent.Start([]string{tokenFile}); err != nil {
+		return nil, err
+	}
+	if o.jenkinsTokenFile != "" {
+		return &jenkins.AuthConfig{
+			Basic: &jenkins.BasicAuthConfig{
+				User:     o.jenkinsUserName,
+				GetToken: secretAgent.GetTokenGenerator(tokenFile),
+			},
+		}, nil
+	}
+	return &jenkins.AuthConfig{
+		BearerToken: &jenkins.BearerTokenAuthConfig{
+			GetToken: secretAgent.GetTokenGenerator(tokenFile),
+		},
+	}, nil
+}
+
+// sealer returns the sealer encrypting the persisted webhooks and actions with the configured key, nil if none is
+func (o *options) sealer() (*envelope.Sealer, error) {
+	switch {
+	case o.encryptionKeyFile != "":
+		keys, err := envelope.NewLocalKey(o.encryptionKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return envelope.NewSealer(keys), nil
+	case o.vaultTransitKey != "":
+		token := func() (string, error) {
+			data, err := ioutil.ReadFile(o.vaultTokenFile)
+			return strings.TrimSpace(string(data)), err
+		}
+		return envelope.NewSealer(envelope.NewVaultTransit(o.vaultAddress, o.vaultTransitMount, o.vaultTransitKey, token)), nil
+	}
+	return nil, nil
+}
+
 func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	var o options
 	fs.BoolVar(&o.jsonLog, "json", true, "Enable JSON logging")
@@ -47,6 +154,28 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.configFilename, "config-file", "", "7Path to the config.yaml file. If not specified it is loaded from the 'config' ConfigMap")
 	fs.StringVar(&o.botName, "bot-name", "", "The name of the bot user to run as. Defaults to $GIT_USER if not specified.")
 	fs.StringVar(&o.namespace, "namespace", "", "The namespace to listen in")
+	fs.BoolVar(&o.configResources, "config-resources", false, "Merge the configuration declared by the LighthouseConfig and LighthouseTrigger resources of the namespace")
+	fs.IntVar(&o.admissionPort, "admission-port", 0, "The TCP port of the validating admission webhook of the LighthouseConfig and LighthouseTrigger resources, disabled if 0")
+	fs.StringVar(&o.admissionCertFile, "admission-cert-file", "", "Path to the TLS certificate of the validating admission webhook")
+	fs.StringVar(&o.admissionKeyFile, "admission-key-file", "", "Path to the TLS private key of the validating admission webhook")
+	fs.IntVar(&o.debugPort, "debug-port", 8081, "The TCP port the debug endpoints, e.g. the effective configuration of the repositories and the explanation of the jobs triggered for the pull requests, the reload status of the configuration, the lookup of the webhooks by ID and the jobs dashboard, are served on. It only listens on localhost, to be reached with kubectl port-forward, as the endpoints expose the private repositories, pull requests, jobs and logs read with the bot token. They are disabled if 0")
+	fs.DurationVar(&o.permissionCacheTTL, "permission-cache-ttl", scmprovider.DefaultPermissionCacheTTL, "How long the permissions, the memberships and the teams looked up by the plugins are cached for, they are invalidated by the membership webhooks of GitHub. The cache is disabled if 0")
+	fs.IntVar(&o.etagCacheSize, "etag-cache-size", scmprovider.DefaultETagCacheSize, "The number of responses of the SCM provider cached to be revalidated with their ETag, the cache is disabled if 0")
+	fs.StringVar(&o.actionQueueDir, "action-queue-dir", "", "The directory the comments, labels and statuses of the plugins are durably queued in before being applied, so that a crash while handling a webhook does not leave a pull request half updated. They are applied right away if empty")
+	fs.IntVar(&o.actionQueueWorkers, "action-queue-workers", 10, "The number of workers applying the actions of the plugins queued")
+	fs.StringVar(&o.webhookArchiveDir, "webhook-archive-dir", "", "The directory the webhooks received are archived in to be replayed, with their tokens and signatures redacted. They are not archived if empty")
+	fs.DurationVar(&o.webhookArchiveMaxAge, "webhook-archive-max-age", 7*24*time.Hour, "How long the webhooks are archived for")
+	fs.StringVar(&o.encryptionKeyFile, "encryption-key-file", "", "Path to the 32 bytes key, raw or base64 encoded, encrypting the data keys of the archived webhooks and the queued actions. They are persisted in clear if neither this nor --vault-transit-key is given")
+	fs.StringVar(&o.vaultAddress, "vault-address", "", "The address of the Vault server whose transit secrets engine encrypts the data keys of the archived webhooks and the queued actions")
+	fs.StringVar(&o.vaultTransitMount, "vault-transit-mount", "transit", "The path the transit secrets engine of Vault is mounted at")
+	fs.StringVar(&o.vaultTransitKey, "vault-transit-key", "", "The name of the key of the transit secrets engine of Vault encrypting the data keys")
+	fs.StringVar(&o.vaultTokenFile, "vault-token-file", "", "Path to the Vault token, read again for each call so that it can be renewed")
+	fs.DurationVar(&o.codeFreezeSyncPeriod, "code-freeze-sync-period", 5*time.Minute, "How often the code freeze label of the open pull requests of the repositories with a code freeze is synced, so that it is added and removed when the code freezes start and are lifted. The sync is disabled if 0")
+	fs.StringVar(&o.jenkinsUserName, "jenkins-user", "jenkins-trigger", "The Jenkins username the live logs of the Jenkins builds are read with")
+	fs.StringVar(&o.jenkinsTokenFile, "jenkins-token-file", "", "Path to the file containing the Jenkins API token the live logs of the Jenkins builds are read with")
+	fs.StringVar(&o.jenkinsBearerTokenFile, "jenkins-bearer-token-file", "", "Path to the file containing the Jenkins API bearer token the live logs of the Jenkins builds are read with")
+	fs.DurationVar(&o.shutdownDelay, "shutdown-delay", 5*time.Second, "How long the webhooks are still received for once the readiness fails on shutdown, so that the pod is removed from the endpoints of the service")
+	fs.DurationVar(&o.shutdownTimeout, "shutdown-timeout", 2*time.Minute, "How long to wait on shutdown for the webhooks being handled, it must be less than the termination grace period of the pod")
 
 	err := fs.Parse(args)
 	if err != nil {
@@ -64,11 +193,16 @@ func main() {
 	if err := o.Validate(); err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
+	defer tracing.Init("lighthouse-webhooks")()
+	defer audit.Init("lighthouse-webhooks")()
 
 	if o.jsonLog {
 		logrus.SetFormatter(logrusutil.CreateDefaultFormatter())
 	}
 
+	scmprovider.SetPermissionCacheTTL(o.permissionCacheTTL)
+	scmprovider.SetETagCacheSize(o.etagCacheSize)
+
 	controller, err := webhook.NewWebhooksController(o.path, o.namespace, o.botName, o.pluginFilename, o.configFilename)
 	if err != nil {
 		logrus.WithError(err).Fatal("failed to set up controller")
@@ -78,14 +212,72 @@ func main() {
 		controller.ConfigMapWatcher.Stop()
 	}()
 
+	if o.configResources {
+		if err := controller.WatchConfigResources(interrupts.Context().Done()); err != nil {
+			logrus.WithError(err).Fatal("failed to watch the LighthouseConfig and LighthouseTrigger resources")
+		}
+	}
+	sealer, err := o.sealer()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to load the encryption key")
+	}
+	if o.actionQueueDir != "" {
+		if err := controller.StartActionQueue(o.actionQueueDir, o.actionQueueWorkers, sealer); err != nil {
+			logrus.WithError(err).Fatal("failed to start the action queue")
+		}
+	}
+	if o.webhookArchiveDir != "" {
+		if err := controller.StartWebhookArchive(o.webhookArchiveDir, o.webhookArchiveMaxAge, sealer); err != nil {
+			logrus.WithError(err).Fatal("failed to start the webhook archive")
+		}
+	}
+	jenkinsAuth, err := o.jenkinsAuth()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to load the Jenkins token")
+	}
+	controller.SetJenkinsAuth(jenkinsAuth)
+	if o.codeFreezeSyncPeriod > 0 {
+		controller.StartCodeFreezeSync(interrupts.Context(), o.codeFreezeSyncPeriod)
+	}
+	if o.admissionPort != 0 {
+		admissionMux := http.NewServeMux()
+		admissionMux.Handle(crdconfig.AdmissionPath, controller.AdmissionHandler())
+		server := &http.Server{Addr: ":" + strconv.Itoa(o.admissionPort), Handler: admissionMux}
+		interrupts.ListenAndServeTLS(server, o.admissionCertFile, o.admissionKeyFile, 5*time.Second)
+	}
+
+	if o.debugPort != 0 {
+		debugMux := http.NewServeMux()
+		debugMux.Handle(webhook.EffectiveConfigPath, http.HandlerFunc(controller.EffectiveConfigHandler))
+		debugMux.Handle(webhook.TriggerDebugPath, http.HandlerFunc(controller.TriggerDebugHandler))
+		debugMux.Handle(watcher.StatusPath, controller.ConfigMapWatcher.StatusHandler())
+		debugMux.Handle(webhook.EventDebugPath, http.HandlerFunc(controller.EventDebugHandler))
+		debugMux.Handle(dashboard.Path, controller.DashboardHandler())
+		debugMux.Handle(dashboard.Path+"/", controller.DashboardHandler())
+		server := &http.Server{Addr: "127.0.0.1:" + strconv.Itoa(o.debugPort), Handler: debugMux}
+		interrupts.ListenAndServe(server, 5*time.Second)
+	}
+
 	mux := http.NewServeMux()
 	mux.Handle(HealthPath, http.HandlerFunc(controller.Health))
 	mux.Handle(ReadyPath, http.HandlerFunc(controller.Ready))
+	controller.HealthChecker().Register(mux)
+	mux.Handle(webhook.ManualTriggerPath, http.HandlerFunc(controller.ManualTriggerHandler))
+	mux.Handle(joblogs.Path, controller.LogsHandler())
+	mux.Handle(metrics.Path, metrics.Handler())
 
 	mux.Handle("/", http.HandlerFunc(controller.DefaultHandler))
 	mux.Handle(o.path, http.HandlerFunc(controller.HandleWebhookRequests))
 
+	server := &http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			logrus.WithError(err).Fatal("failed to serve HTTP")
+		}
+	}()
 	logrus.Infof("Lighthouse is now listening on path %s and port %d for WebHooks", o.path, o.port)
-	err = http.ListenAndServe(":"+strconv.Itoa(o.port), mux)
-	logrus.WithError(err).Errorf("failed to server HTTP")
+
+	// stop receiving the webhooks and wait for the ones being handled before exiting
+	<-interrupts.Context().Done()
+	controller.Shutdown(server, o.shutdownDelay, o.shutdownTimeout)
 }
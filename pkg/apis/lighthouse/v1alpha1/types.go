@@ -10,6 +10,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -21,6 +22,9 @@ const (
 	// TriggeredState for pipelines that have been triggered
 	TriggeredState PipelineState = "triggered"
 
+	// QueuedState for pipelines that have been triggered during a maintenance window and are waiting for it to end
+	QueuedState PipelineState = "queued"
+
 	// PendingState pipeline is pending
 	PendingState PipelineState = "pending"
 
@@ -68,6 +72,10 @@ const (
 	PullNumberEnv = "PULL_NUMBER"
 	// PullPullShaEnv is the pull request's sha
 	PullPullShaEnv = "PULL_PULL_SHA"
+	// ArtifactsBucketEnv is the bucket URL artifacts should be uploaded to, if any
+	ArtifactsBucketEnv = "ARTIFACTS_BUCKET"
+	// ArtifactsPathsEnv is the comma separated glob patterns of the artifacts to upload, if any
+	ArtifactsPathsEnv = "ARTIFACTS_PATHS"
 )
 
 // +genclient
@@ -101,10 +109,66 @@ type LighthouseJobStatus struct {
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 	// LastReportState is the state from the last time we reported commit status for this job.
 	LastReportState string `json:"lastReportState,omitempty"`
+	// ReportAttempts is the number of consecutive failed attempts to report the current state of the job to the
+	// SCM provider. The failed reports are retried with an exponential backoff.
+	ReportAttempts int `json:"reportAttempts,omitempty"`
+	// NextReportTime is when the failed report of the job state is retried.
+	NextReportTime *metav1.Time `json:"nextReportTime,omitempty"`
+	// CheckRunID is the ID of the GitHub check run the job is reported to, if it is reported as a check run.
+	CheckRunID int64 `json:"checkRunID,omitempty"`
+	// QueueItemID is the ID of the Jenkins queue item of the job until its build starts, if it runs on Jenkins.
+	QueueItemID int `json:"queueItemID,omitempty"`
+	// WebhookStates are the events last delivered to the status webhooks, by URL.
+	WebhookStates map[string]string `json:"webhookStates,omitempty"`
+	// LastNotificationState is the event from the last time we sent the job state to the chat notifications and
+	// the cloud events sink.
+	LastNotificationState string `json:"lastNotificationState,omitempty"`
+	// LastEmailState is the final state of the job the last time we decided whether to email it.
+	LastEmailState string `json:"lastEmailState,omitempty"`
+	// LastJobOwnersState is the final state of the presubmit the last time we reported its failure to its owners.
+	LastJobOwnersState string `json:"lastJobOwnersState,omitempty"`
+	// LastFlakeState is the final state of the job the last time we tracked the failure rates of its failed tests.
+	LastFlakeState string `json:"lastFlakeState,omitempty"`
+	// LastTestReportState is the final state of the job the last time we read its failed tests from the junit report
+	// uploaded along with its artifacts.
+	LastTestReportState string `json:"lastTestReportState,omitempty"`
 	// LastCommitSHA is the commit that will be/has been reported to on the SCM provider
 	LastCommitSHA string `json:"lastCommitSHA,omitempty"`
 	// Activity is the most recent activity recorded for the pipeline associated with this job.
 	Activity *ActivityRecord `json:"activity,omitempty"`
+	// BuildID is the ID of the build the logs and artifacts of the job are uploaded under, if any.
+	BuildID string `json:"buildID,omitempty"`
+	// ArtifactsURL is the link to the uploaded logs and artifacts of the job, if any.
+	ArtifactsURL string `json:"artifactsURL,omitempty"`
+	// TestFailures are the failed tests reported by the junit results of the job, if any.
+	TestFailures []TestFailure `json:"testFailures,omitempty"`
+	// Resources are the CPU and memory requested and consumed by the pods of the job once it completed, if known.
+	Resources *ResourceUsage `json:"resources,omitempty"`
+}
+
+// TestFailure is a failed test reported by a job
+type TestFailure struct {
+	// Suite is the name of the test suite
+	Suite string `json:"suite,omitempty"`
+	// Name is the name of the test
+	Name string `json:"name"`
+	// Message is the (possibly truncated) failure message
+	Message string `json:"message,omitempty"`
+	// QuarantineIssue is the number of the issue tracking the test, if it is quarantined as flaky
+	QuarantineIssue int `json:"quarantineIssue,omitempty"`
+}
+
+// ResourceUsage is the CPU and memory requested by the containers of the pods of a job, and consumed by them over
+// their run, i.e. their requests times how long they ran, so that the CI spend can be attributed to the jobs
+type ResourceUsage struct {
+	// CPURequests is the total CPU requested by the containers
+	CPURequests resource.Quantity `json:"cpuRequests,omitempty"`
+	// MemoryRequests is the total memory requested by the containers
+	MemoryRequests resource.Quantity `json:"memoryRequests,omitempty"`
+	// CPUMilliSeconds is the CPU consumed by the containers, in millicore-seconds
+	CPUMilliSeconds int64 `json:"cpuMilliSeconds,omitempty"`
+	// MemoryByteSeconds is the memory consumed by the containers, in byte-seconds
+	MemoryByteSeconds int64 `json:"memoryByteSeconds,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -144,15 +208,42 @@ type LighthouseJobSpec struct {
 	// MaxConcurrency restricts the total number of instances
 	// of this job that can run in parallel at once
 	MaxConcurrency int `json:"max_concurrency,omitempty"`
+	// Priority orders the jobs waiting to be started by the jenkins, drone, buildkite and github-actions engines,
+	// higher priority jobs are started first. The tekton jobs are started right away.
+	Priority int `json:"priority,omitempty"`
+	// PriorityClassName is the Kubernetes PriorityClass of the pods running the job
+	PriorityClassName string `json:"priority_class_name,omitempty"`
 	// PipelineRunSpec provides the basis for running the test as a Tekton Pipeline
 	// https://github.com/tektoncd/pipeline
 	PipelineRunSpec *tektonv1beta1.PipelineRunSpec `json:"pipeline_run_spec,omitempty"`
 	// PipelineRunParams are the params used by the pipeline run
 	PipelineRunParams []job.PipelineRunParam `json:"pipeline_run_params,omitempty"`
+	// Parameters are the validated parameters given to the job by the command triggering it,
+	// e.g. `/test e2e --provider=gke`, which are passed to the pipeline run
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// PipelineRunOverrides are merged into the PipelineRun created for the job
+	PipelineRunOverrides *PipelineRunOverrides `json:"pipeline_run_overrides,omitempty"`
+	// PipelineRef references the pipeline resolved by a Tekton resolver, instead of the pipeline of the
+	// PipelineRunSpec
+	PipelineRef *PipelineRef `json:"pipeline_ref,omitempty"`
 	// PodSpec provides the basis for running the test under a Kubernetes agent
 	PodSpec *corev1.PodSpec `json:"pod_spec,omitempty"`
 	// JenkinsSpec holds configuration specific to Jenkins jobs
 	JenkinsSpec *JenkinsSpec `json:"jenkins_spec,omitempty"`
+	// GitHubActionsSpec holds configuration specific to GitHub Actions jobs
+	GitHubActionsSpec *GitHubActionsSpec `json:"github_actions_spec,omitempty"`
+	// DroneSpec holds configuration specific to Drone jobs
+	DroneSpec *DroneSpec `json:"drone_spec,omitempty"`
+	// BuildkiteSpec holds configuration specific to Buildkite jobs
+	BuildkiteSpec *BuildkiteSpec `json:"buildkite_spec,omitempty"`
+	// Artifacts configures where the logs and artifacts of the job are uploaded
+	Artifacts *ArtifactsSpec `json:"artifacts,omitempty"`
+	// EmailReport configures the emails sent when the job fails
+	EmailReport *EmailReportSpec `json:"email_report,omitempty"`
+	// Timeout is how long the job can run once started before it is aborted
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// PendingTimeout is how long the job can wait to start before it is aborted
+	PendingTimeout *metav1.Duration `json:"pending_timeout,omitempty"`
 }
 
 // Complete returns true if the prow job has finished
@@ -191,6 +282,13 @@ func (s *LighthouseJobSpec) GetEnvVars() map[string]string {
 
 	env[JobSpecEnv] = fmt.Sprintf("type:%s", s.Type)
 
+	if s.Artifacts != nil && s.Artifacts.Bucket != "" {
+		env[ArtifactsBucketEnv] = s.Artifacts.Bucket
+		if len(s.Artifacts.Paths) > 0 {
+			env[ArtifactsPathsEnv] = strings.Join(s.Artifacts.Paths, ",")
+		}
+	}
+
 	if s.Type == job.PeriodicJob {
 		return env
 	}
@@ -203,7 +301,7 @@ func (s *LighthouseJobSpec) GetEnvVars() map[string]string {
 		env[PullRefsEnv] = s.Refs.String()
 	}
 
-	if s.Type == job.PostsubmitJob || s.Type == job.BatchJob {
+	if s.Type == job.PostsubmitJob || s.Type == job.BatchJob || s.Type == job.OpsJob {
 		return env
 	}
 
@@ -383,6 +481,7 @@ type ActivityRecord struct {
 	LogURL          string                 `json:"logURL,omitempty"`
 	LinkURL         string                 `json:"linkURL,omitempty"`
 	Status          PipelineState          `json:"status,omitempty"`
+	Description     string                 `json:"description,omitempty"`
 	BaseSHA         string                 `json:"baseSHA,omitempty"`
 	LastCommitSHA   string                 `json:"lastCommitSHA,omitempty"`
 	StartTime       *metav1.Time           `json:"startTime,omitempty"`
@@ -414,8 +513,85 @@ func (a *ActivityRecord) RunningStages() []string {
 }
 
 // JenkinsSpec is optional parameters for Jenkins jobs.
-// Currently, the only parameter supported is for telling
-// jenkins-operator that the job is generated by the https://go.cloudbees.com/docs/plugins/github-branch-source/#github-branch-source plugin
+// It tells the Jenkins controller where the job lives, either
+// generated by the https://go.cloudbees.com/docs/plugins/github-branch-source/#github-branch-source plugin,
+// inside folders or as a multibranch pipeline.
 type JenkinsSpec struct {
 	BranchSourceJob bool `json:"branch_source_job,omitempty"`
+	// Folder is the slash separated path of the folder(s) containing the job
+	Folder string `json:"folder,omitempty"`
+	// MultiBranch indicates the job is a multibranch pipeline
+	MultiBranch bool `json:"multi_branch,omitempty"`
+}
+
+// ArtifactsSpec is the object storage configuration used to upload
+// the logs, junit results and artifacts of a job.
+type ArtifactsSpec struct {
+	// Bucket is the bucket URL, e.g. gs://my-bucket/prefix or s3://my-bucket/prefix
+	Bucket string `json:"bucket"`
+	// Paths are the glob patterns of the artifacts to upload, passed to the build as the ARTIFACTS_PATHS environment
+	// variable
+	Paths []string `json:"paths,omitempty"`
+}
+
+// EmailReportSpec configures the emails sent to the owners of a
+// postsubmit or periodic job when it fails.
+type EmailReportSpec struct {
+	// To are the email addresses the reports are sent to
+	To []string `json:"to"`
+	// When is on_failure or on_state_change
+	When string `json:"when,omitempty"`
+}
+
+// PipelineRunOverrides are merged into the PipelineRun created for a job
+// to control where and how it runs.
+type PipelineRunOverrides struct {
+	// ServiceAccountName replaces the service account of the PipelineRun
+	ServiceAccountName string `json:"service_account_name,omitempty"`
+	// Timeout replaces the timeout of the PipelineRun
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// NodeSelector is merged into the node selector of the pod template
+	NodeSelector map[string]string `json:"node_selector,omitempty"`
+	// Tolerations are added to the tolerations of the pod template
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Workspaces replace the workspace bindings of the same name, or are added
+	Workspaces []tektonv1beta1.WorkspaceBinding `json:"workspaces,omitempty"`
+}
+
+// PipelineRef references the pipeline of a job resolved by a Tekton remote resolver.
+type PipelineRef struct {
+	// Resolver is the name of the Tekton resolver, e.g. git, bundles or cluster
+	Resolver string `json:"resolver"`
+	// Params are the params of the resolver, whose values are templates given the Refs and the Parameters
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// GitHubActionsSpec is optional parameters for GitHub Actions jobs.
+// It describes which workflow is dispatched and how.
+type GitHubActionsSpec struct {
+	// Workflow is the workflow file name or ID to dispatch
+	Workflow string `json:"workflow,omitempty"`
+	// Event is either workflow_dispatch or repository_dispatch
+	Event string `json:"event,omitempty"`
+	// EventType is the event_type sent with a repository_dispatch event
+	EventType string `json:"event_type,omitempty"`
+	// Inputs are additional static inputs passed to the workflow
+	Inputs map[string]string `json:"inputs,omitempty"`
+}
+
+// DroneSpec is optional parameters for Drone jobs.
+type DroneSpec struct {
+	// Params are additional static parameters passed to the build
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// BuildkiteSpec is optional parameters for Buildkite jobs.
+// It describes which pipeline builds the job.
+type BuildkiteSpec struct {
+	// Organization is the slug of the Buildkite organization of the pipeline
+	Organization string `json:"organization,omitempty"`
+	// Pipeline is the slug of the Buildkite pipeline to build
+	Pipeline string `json:"pipeline"`
+	// Env are additional static environment variables passed to the build
+	Env map[string]string `json:"env,omitempty"`
 }
@@ -28,6 +28,25 @@ func TestPipelineOptionsSpec_GetEnvVars(t *testing.T) {
 				v1alpha1.JobSpecEnv: fmt.Sprintf("type:%s", job.PeriodicJob),
 			},
 		},
+		{
+			name: "periodic with artifacts",
+			spec: &v1alpha1.LighthouseJobSpec{
+				Type:      job.PeriodicJob,
+				Namespace: "jx",
+				Job:       "some-job",
+				Artifacts: &v1alpha1.ArtifactsSpec{
+					Bucket: "gs://my-bucket",
+					Paths:  []string{"dist/*.tgz", "reports/**/*.html"},
+				},
+			},
+			env: map[string]string{
+				v1alpha1.JobNameEnv:         "some-job",
+				v1alpha1.JobTypeEnv:         string(job.PeriodicJob),
+				v1alpha1.JobSpecEnv:         fmt.Sprintf("type:%s", job.PeriodicJob),
+				v1alpha1.ArtifactsBucketEnv: "gs://my-bucket",
+				v1alpha1.ArtifactsPathsEnv:  "dist/*.tgz,reports/**/*.html",
+			},
+		},
 		{
 			name: "postsubmit",
 			spec: &v1alpha1.LighthouseJobSpec{
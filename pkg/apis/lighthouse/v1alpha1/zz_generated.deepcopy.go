@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 // Code generated by deepcopy-gen. DO NOT EDIT.
@@ -8,6 +9,7 @@ import (
 	job "github.com/jenkins-x/lighthouse/pkg/config/job"
 	v1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -123,6 +125,50 @@ func (in ByNum) DeepCopy() ByNum {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactsSpec) DeepCopyInto(out *ArtifactsSpec) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactsSpec.
+func (in *ArtifactsSpec) DeepCopy() *ArtifactsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildkiteSpec) DeepCopyInto(out *BuildkiteSpec) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildkiteSpec.
+func (in *BuildkiteSpec) DeepCopy() *BuildkiteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildkiteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DecorationConfig) DeepCopyInto(out *DecorationConfig) {
 	*out = *in
@@ -164,6 +210,29 @@ func (in *DecorationConfig) DeepCopy() *DecorationConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DroneSpec) DeepCopyInto(out *DroneSpec) {
+	*out = *in
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DroneSpec.
+func (in *DroneSpec) DeepCopy() *DroneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DroneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Duration) DeepCopyInto(out *Duration) {
 	*out = *in
@@ -180,6 +249,50 @@ func (in *Duration) DeepCopy() *Duration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailReportSpec) DeepCopyInto(out *EmailReportSpec) {
+	*out = *in
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailReportSpec.
+func (in *EmailReportSpec) DeepCopy() *EmailReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitHubActionsSpec) DeepCopyInto(out *GitHubActionsSpec) {
+	*out = *in
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubActionsSpec.
+func (in *GitHubActionsSpec) DeepCopy() *GitHubActionsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitHubActionsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JenkinsSpec) DeepCopyInto(out *JenkinsSpec) {
 	*out = *in
@@ -282,6 +395,23 @@ func (in *LighthouseJobSpec) DeepCopyInto(out *LighthouseJobSpec) {
 		*out = make([]job.PipelineRunParam, len(*in))
 		copy(*out, *in)
 	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PipelineRunOverrides != nil {
+		in, out := &in.PipelineRunOverrides, &out.PipelineRunOverrides
+		*out = new(PipelineRunOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PipelineRef != nil {
+		in, out := &in.PipelineRef, &out.PipelineRef
+		*out = new(PipelineRef)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PodSpec != nil {
 		in, out := &in.PodSpec, &out.PodSpec
 		*out = new(v1.PodSpec)
@@ -292,6 +422,41 @@ func (in *LighthouseJobSpec) DeepCopyInto(out *LighthouseJobSpec) {
 		*out = new(JenkinsSpec)
 		**out = **in
 	}
+	if in.GitHubActionsSpec != nil {
+		in, out := &in.GitHubActionsSpec, &out.GitHubActionsSpec
+		*out = new(GitHubActionsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DroneSpec != nil {
+		in, out := &in.DroneSpec, &out.DroneSpec
+		*out = new(DroneSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BuildkiteSpec != nil {
+		in, out := &in.BuildkiteSpec, &out.BuildkiteSpec
+		*out = new(BuildkiteSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = new(ArtifactsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EmailReport != nil {
+		in, out := &in.EmailReport, &out.EmailReport
+		*out = new(EmailReportSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PendingTimeout != nil {
+		in, out := &in.PendingTimeout, &out.PendingTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -313,11 +478,32 @@ func (in *LighthouseJobStatus) DeepCopyInto(out *LighthouseJobStatus) {
 		in, out := &in.CompletionTime, &out.CompletionTime
 		*out = (*in).DeepCopy()
 	}
+	if in.NextReportTime != nil {
+		in, out := &in.NextReportTime, &out.NextReportTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Activity != nil {
 		in, out := &in.Activity, &out.Activity
 		*out = new(ActivityRecord)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.WebhookStates != nil {
+		in, out := &in.WebhookStates, &out.WebhookStates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TestFailures != nil {
+		in, out := &in.TestFailures, &out.TestFailures
+		*out = make([]TestFailure, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(ResourceUsage)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -331,6 +517,71 @@ func (in *LighthouseJobStatus) DeepCopy() *LighthouseJobStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineRef) DeepCopyInto(out *PipelineRef) {
+	*out = *in
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRef.
+func (in *PipelineRef) DeepCopy() *PipelineRef {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineRunOverrides) DeepCopyInto(out *PipelineRunOverrides) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Workspaces != nil {
+		in, out := &in.Workspaces, &out.Workspaces
+		*out = make([]v1beta1.WorkspaceBinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRunOverrides.
+func (in *PipelineRunOverrides) DeepCopy() *PipelineRunOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineRunOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Pull) DeepCopyInto(out *Pull) {
 	*out = *in
@@ -367,3 +618,37 @@ func (in *Refs) DeepCopy() *Refs {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceUsage) DeepCopyInto(out *ResourceUsage) {
+	*out = *in
+	out.CPURequests = in.CPURequests.DeepCopy()
+	out.MemoryRequests = in.MemoryRequests.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceUsage.
+func (in *ResourceUsage) DeepCopy() *ResourceUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestFailure) DeepCopyInto(out *TestFailure) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestFailure.
+func (in *TestFailure) DeepCopy() *TestFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(TestFailure)
+	in.DeepCopyInto(out)
+	return out
+}
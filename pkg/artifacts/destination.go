@@ -0,0 +1,106 @@
+package artifacts
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/pkg/errors"
+)
+
+const (
+	// GCSScheme is the URL scheme of Google Cloud Storage destinations
+	GCSScheme = "gs"
+	// S3Scheme is the URL scheme of Amazon S3 (or compatible) destinations
+	S3Scheme = "s3"
+)
+
+// Destination is a bucket and an optional path prefix in an object storage.
+type Destination struct {
+	Scheme string
+	Bucket string
+	Prefix string
+}
+
+// ParseDestination parses a bucket URL such as `gs://bucket/prefix` or `s3://bucket/prefix`.
+func ParseDestination(bucket string) (*Destination, error) {
+	u, err := url.Parse(bucket)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid artifacts bucket %q", bucket)
+	}
+	if u.Scheme != GCSScheme && u.Scheme != S3Scheme {
+		return nil, fmt.Errorf("artifacts bucket %q must start with %s:// or %s://", bucket, GCSScheme, S3Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("artifacts bucket %q has no bucket name", bucket)
+	}
+	return &Destination{
+		Scheme: u.Scheme,
+		Bucket: u.Host,
+		Prefix: strings.Trim(u.Path, "/"),
+	}, nil
+}
+
+// Join returns the object name for the given path, relative to the destination prefix.
+func (d *Destination) Join(elem ...string) string {
+	return path.Join(append([]string{d.Prefix}, elem...)...)
+}
+
+// String returns the destination as a bucket URL.
+func (d *Destination) String() string {
+	return fmt.Sprintf("%s://%s", d.Scheme, path.Join(d.Bucket, d.Prefix))
+}
+
+// BrowseURL returns a link to browse the objects stored under the given directory.
+func (d *Destination) BrowseURL(dir string) string {
+	switch d.Scheme {
+	case S3Scheme:
+		return fmt.Sprintf("https://s3.console.aws.amazon.com/s3/buckets/%s?prefix=%s/", d.Bucket, url.QueryEscape(d.Join(dir)))
+	default:
+		return fmt.Sprintf("https://console.cloud.google.com/storage/browser/%s/%s", d.Bucket, d.Join(dir))
+	}
+}
+
+// JobPath returns the Prow style directory used to store the artifacts of a build:
+//
+// presubmits: pr-logs/pull/<org>_<repo>/<pull number>/<job>/<build id>
+// batches: pr-logs/pull/batch/<job>/<build id>
+// others: logs/<job>/<build id>
+func JobPath(jobType job.PipelineKind, jobName, org, repo, pullNumber, buildID string) string {
+	switch jobType {
+	case job.PresubmitJob:
+		return path.Join("pr-logs", "pull", fmt.Sprintf("%s_%s", org, repo), pullNumber, jobName, buildID)
+	case job.BatchJob:
+		return path.Join("pr-logs", "pull", "batch", jobName, buildID)
+	default:
+		return path.Join("logs", jobName, buildID)
+	}
+}
+
+// JobPathForSpec returns the directory used to store the artifacts of a build of the given job.
+func JobPathForSpec(spec *v1alpha1.LighthouseJobSpec, buildID string) string {
+	var org, repo, pull string
+	if spec.Refs != nil {
+		org, repo = spec.Refs.Org, spec.Refs.Repo
+		if len(spec.Refs.Pulls) > 0 {
+			pull = fmt.Sprintf("%d", spec.Refs.Pulls[0].Number)
+		}
+	}
+	return JobPath(spec.Type, spec.Job, org, repo, pull, buildID)
+}
+
+// URLForJob returns the link to the artifacts of the given build, or an empty
+// string if the job does not upload artifacts.
+func URLForJob(spec *v1alpha1.LighthouseJobSpec, buildID string) string {
+	if spec.Artifacts == nil || spec.Artifacts.Bucket == "" || buildID == "" {
+		return ""
+	}
+	dest, err := ParseDestination(spec.Artifacts.Bucket)
+	if err != nil {
+		return ""
+	}
+	return dest.BrowseURL(JobPathForSpec(spec, buildID))
+}
@@ -0,0 +1,78 @@
+package artifacts
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		name     string
+		bucket   string
+		expected *Destination
+		err      bool
+	}{
+		{
+			name:     "gcs bucket",
+			bucket:   "gs://my-bucket",
+			expected: &Destination{Scheme: GCSScheme, Bucket: "my-bucket"},
+		},
+		{
+			name:     "s3 bucket with prefix",
+			bucket:   "s3://my-bucket/some/prefix/",
+			expected: &Destination{Scheme: S3Scheme, Bucket: "my-bucket", Prefix: "some/prefix"},
+		},
+		{
+			name:   "unsupported scheme",
+			bucket: "https://my-bucket",
+			err:    true,
+		},
+		{
+			name:   "no bucket name",
+			bucket: "gs:///prefix",
+			err:    true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dest, err := ParseDestination(tc.bucket)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, dest)
+		})
+	}
+}
+
+func TestJobPath(t *testing.T) {
+	assert.Equal(t, "pr-logs/pull/org_repo/12/unit/42", JobPath(job.PresubmitJob, "unit", "org", "repo", "12", "42"))
+	assert.Equal(t, "pr-logs/pull/batch/unit/42", JobPath(job.BatchJob, "unit", "org", "repo", "", "42"))
+	assert.Equal(t, "logs/release/42", JobPath(job.PostsubmitJob, "release", "org", "repo", "", "42"))
+	assert.Equal(t, "logs/nightly/42", JobPath(job.PeriodicJob, "nightly", "", "", "", "42"))
+}
+
+func TestURLForJob(t *testing.T) {
+	spec := &v1alpha1.LighthouseJobSpec{
+		Type: job.PresubmitJob,
+		Job:  "unit",
+		Refs: &v1alpha1.Refs{
+			Org:   "org",
+			Repo:  "repo",
+			Pulls: []v1alpha1.Pull{{Number: 12}},
+		},
+	}
+	assert.Empty(t, URLForJob(spec, "42"), "no artifacts configured")
+
+	spec.Artifacts = &v1alpha1.ArtifactsSpec{Bucket: "gs://my-bucket/ci"}
+	assert.Empty(t, URLForJob(spec, ""), "no build ID")
+	assert.Equal(t, "https://console.cloud.google.com/storage/browser/my-bucket/ci/pr-logs/pull/org_repo/12/unit/42", URLForJob(spec, "42"))
+
+	spec.Artifacts.Bucket = "s3://my-bucket"
+	assert.Equal(t, "https://s3.console.aws.amazon.com/s3/buckets/my-bucket?prefix=pr-logs%2Fpull%2Forg_repo%2F12%2Funit%2F42/", URLForJob(spec, "42"))
+}
@@ -0,0 +1,59 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxDownloadSize caps the size of the downloaded objects, which are read in memory.
+const maxDownloadSize = 10 << 20
+
+// ErrNotFound is returned when downloading an object which does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// Downloader downloads objects from an object storage bucket.
+type Downloader interface {
+	// Download returns the content of the named object, or ErrNotFound if it does not exist.
+	Download(ctx context.Context, name string) ([]byte, error)
+}
+
+// NewDownloader creates the downloader for the given destination.
+func NewDownloader(ctx context.Context, dest *Destination, opts Options) (Downloader, error) {
+	switch dest.Scheme {
+	case GCSScheme:
+		return newGCSUploader(ctx, dest.Bucket, opts)
+	case S3Scheme:
+		return newS3Uploader(dest.Bucket, opts)
+	default:
+		return nil, fmt.Errorf("unsupported artifacts destination %s", dest)
+	}
+}
+
+func doDownload(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("download of %s failed: %s: %s", req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", req.URL.Path)
+	}
+	if len(data) > maxDownloadSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", req.URL.Path, maxDownloadSize)
+	}
+	return data, nil
+}
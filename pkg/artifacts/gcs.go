@@ -0,0 +1,95 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	gcsEndpoint = "https://storage.googleapis.com"
+	gcsScope    = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+type gcsUploader struct {
+	client   *http.Client
+	endpoint string
+	bucket   string
+}
+
+func newGCSUploader(ctx context.Context, bucket string, opts Options) (*gcsUploader, error) {
+	var ts oauth2.TokenSource
+	if opts.CredentialsFile != "" {
+		data, err := ioutil.ReadFile(opts.CredentialsFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read GCS credentials %s", opts.CredentialsFile)
+		}
+		creds, err := google.CredentialsFromJSON(ctx, data, gcsScope)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse GCS credentials %s", opts.CredentialsFile)
+		}
+		ts = creds.TokenSource
+	} else {
+		var err error
+		ts, err = google.DefaultTokenSource(ctx, gcsScope)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to find default GCS credentials")
+		}
+	}
+	endpoint := gcsEndpoint
+	if opts.Endpoint != "" {
+		endpoint = strings.TrimSuffix(opts.Endpoint, "/")
+	}
+	return &gcsUploader{
+		client:   oauth2.NewClient(ctx, ts),
+		endpoint: endpoint,
+		bucket:   bucket,
+	}, nil
+}
+
+// Upload uses the simple media upload of the GCS JSON API.
+func (u *gcsUploader) Upload(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	query := url.Values{}
+	query.Set("uploadType", "media")
+	query.Set("name", name)
+	uploadURL := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", u.endpoint, url.PathEscape(u.bucket), query.Encode())
+	req, err := http.NewRequest(http.MethodPost, uploadURL, r)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	return doUpload(u.client, req)
+}
+
+// Download uses the media download of the GCS JSON API.
+func (u *gcsUploader) Download(ctx context.Context, name string) ([]byte, error) {
+	downloadURL := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", u.endpoint, url.PathEscape(u.bucket), url.PathEscape(name))
+	req, err := http.NewRequest(http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return doDownload(u.client, req.WithContext(ctx))
+}
+
+func doUpload(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload of %s failed: %s: %s", req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package artifacts
+
+import (
+	"context"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/junit"
+	"github.com/pkg/errors"
+)
+
+const (
+	// JUnitReportName is the object name of the report of the junit failures of a build, relative to the job path
+	JUnitReportName = "junit-report.xml"
+	// MaxJUnitReportSize is the maximum size of the report of the junit failures of a build
+	MaxJUnitReportSize = 1 << 20
+
+	// maxTestFailures is the maximum number of test failures recorded in a LighthouseJob status
+	maxTestFailures = 50
+	// maxTestFailureMessage is the maximum size of a failure message recorded in a LighthouseJob status
+	maxTestFailureMessage = 512
+)
+
+// TestFailures converts the junit failures to the test failures recorded in a LighthouseJob status, keeping the
+// first ones and truncating their messages so that the status stays small.
+func TestFailures(failures []junit.Failure) []v1alpha1.TestFailure {
+	var answer []v1alpha1.TestFailure
+	for _, f := range failures {
+		if len(answer) == maxTestFailures {
+			break
+		}
+		answer = append(answer, v1alpha1.TestFailure{
+			Suite:   f.Suite,
+			Name:    f.Name,
+			Message: junit.Truncate(f.Message, maxTestFailureMessage),
+		})
+	}
+	return answer
+}
+
+// ReadTestFailures downloads the junit report uploaded for the given build of a job and returns its test failures.
+func ReadTestFailures(ctx context.Context, downloader Downloader, dest *Destination, spec *v1alpha1.LighthouseJobSpec, buildID string) ([]v1alpha1.TestFailure, error) {
+	name := dest.Join(JobPathForSpec(spec, buildID), JUnitReportName)
+	data, err := downloader.Download(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	failures, err := junit.ParseFailures(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the junit report %s", name)
+	}
+	return TestFailures(failures), nil
+}
@@ -0,0 +1,47 @@
+package artifacts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/junit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTestFailures(t *testing.T) {
+	report, err := junit.MarshalFailures([]junit.Failure{{Suite: "foo", Name: "TestFoo", Message: "boom"}}, MaxJUnitReportSize)
+	require.NoError(t, err)
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		assert.Equal(t, "media", r.URL.Query().Get("alt"))
+		_, _ = w.Write(report)
+	}))
+	defer server.Close()
+
+	dest, err := ParseDestination("gs://my-bucket/prefix")
+	require.NoError(t, err)
+	downloader := &gcsUploader{client: server.Client(), endpoint: server.URL, bucket: dest.Bucket}
+	spec := &v1alpha1.LighthouseJobSpec{Type: job.PostsubmitJob, Job: "unit"}
+
+	failures, err := ReadTestFailures(context.Background(), downloader, dest, spec, "42")
+	require.NoError(t, err)
+	assert.Equal(t, "/storage/v1/b/my-bucket/o/prefix%2Flogs%2Funit%2F42%2Fjunit-report.xml", gotPath)
+	assert.Equal(t, []v1alpha1.TestFailure{{Suite: "foo", Name: "TestFoo", Message: "boom"}}, failures)
+}
+
+func TestTestFailures(t *testing.T) {
+	var failures []junit.Failure
+	for i := 0; i < maxTestFailures+10; i++ {
+		failures = append(failures, junit.Failure{Name: "TestFoo", Message: string(make([]byte, 2*maxTestFailureMessage))})
+	}
+	converted := TestFailures(failures)
+	assert.Len(t, converted, maxTestFailures)
+	assert.Len(t, converted[0].Message, maxTestFailureMessage)
+	assert.Nil(t, TestFailures(nil))
+}
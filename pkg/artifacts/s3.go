@@ -0,0 +1,94 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+)
+
+const s3DefaultRegion = "us-east-1"
+
+type s3Uploader struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+}
+
+// newS3Uploader creates the uploader with the credentials of the default AWS credential chain, e.g. the
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables, the shared credentials file or the IAM role
+// of the pod
+func newS3Uploader(bucket string, opts Options) (*s3Uploader, error) {
+	region := opts.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = s3DefaultRegion
+	}
+	cfg := aws.NewConfig().WithRegion(region)
+	if opts.Endpoint != "" {
+		// S3 compatible storages, e.g. minio, are addressed path-style
+		cfg = cfg.WithEndpoint(opts.Endpoint).WithS3ForcePathStyle(true)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the AWS session")
+	}
+	return newS3UploaderWithSession(bucket, sess), nil
+}
+
+func newS3UploaderWithSession(bucket string, sess *session.Session) *s3Uploader {
+	client := s3.New(sess)
+	return &s3Uploader{
+		client:   client,
+		uploader: s3manager.NewUploaderWithClient(client),
+		bucket:   bucket,
+	}
+}
+
+// Upload puts the object, in parts if it is large.
+func (u *s3Uploader) Upload(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	_, err := u.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(u.bucket),
+		Key:         aws.String(name),
+		Body:        r,
+		ContentType: aws.String(contentType),
+	})
+	return errors.Wrapf(err, "upload of %s failed", name)
+}
+
+// Download gets the object.
+func (u *s3Uploader) Download(ctx context.Context, name string) ([]byte, error) {
+	out, err := u.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		if failure, ok := err.(awserr.RequestFailure); ok && failure.StatusCode() == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, errors.Wrapf(err, "download of %s failed", name)
+	}
+	defer out.Body.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(out.Body, maxDownloadSize+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", name)
+	}
+	if len(data) > maxDownloadSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", name, maxDownloadSize)
+	}
+	return data, nil
+}
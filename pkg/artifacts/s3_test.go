@@ -0,0 +1,71 @@
+package artifacts
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestS3Uploader(t *testing.T, endpoint string) *s3Uploader {
+	sess, err := session.NewSession(aws.NewConfig().
+		WithRegion("eu-west-1").
+		WithEndpoint(endpoint).
+		WithS3ForcePathStyle(true).
+		WithCredentials(credentials.NewStaticCredentials("AKID", "secret", "")))
+	require.NoError(t, err)
+	return newS3UploaderWithSession("my-bucket", sess)
+}
+
+func TestS3Upload(t *testing.T) {
+	var got *http.Request
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		got, body = r, string(data)
+	}))
+	defer server.Close()
+
+	u := newTestS3Uploader(t, server.URL)
+	err := u.Upload(context.Background(), "logs/job/1/build-log.txt", strings.NewReader("hello"), 5, "text/plain")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	assert.Equal(t, http.MethodPut, got.Method)
+	assert.Equal(t, "/my-bucket/logs/job/1/build-log.txt", got.URL.Path)
+	assert.Equal(t, "hello", body)
+	assert.Equal(t, "text/plain", got.Header.Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(got.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+	assert.Contains(t, got.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request")
+}
+
+func TestS3Download(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		if r.URL.Path != "/my-bucket/logs/job/1/junit-report.xml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("<testsuites/>"))
+	}))
+	defer server.Close()
+
+	u := newTestS3Uploader(t, server.URL)
+	data, err := u.Download(context.Background(), "logs/job/1/junit-report.xml")
+	require.NoError(t, err)
+	assert.Equal(t, "<testsuites/>", string(data))
+	assert.Equal(t, http.MethodGet, got.Method)
+	assert.True(t, strings.HasPrefix(got.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+
+	_, err = u.Download(context.Background(), "logs/job/2/junit-report.xml")
+	assert.Equal(t, ErrNotFound, err)
+}
@@ -0,0 +1,120 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/mattn/go-zglob"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Uploader uploads objects to an object storage bucket.
+type Uploader interface {
+	// Upload stores the content of r, of the given size, as the named object.
+	Upload(ctx context.Context, name string, r io.Reader, size int64, contentType string) error
+}
+
+// NewUploader creates the uploader for the given destination.
+func NewUploader(ctx context.Context, dest *Destination, opts Options) (Uploader, error) {
+	switch dest.Scheme {
+	case GCSScheme:
+		return newGCSUploader(ctx, dest.Bucket, opts)
+	case S3Scheme:
+		return newS3Uploader(dest.Bucket, opts)
+	default:
+		return nil, fmt.Errorf("unsupported artifacts destination %s", dest)
+	}
+}
+
+// Options configures how uploaders connect to the object storage.
+type Options struct {
+	// CredentialsFile is the path to the GCS service account key file.
+	// Application default credentials are used if empty.
+	CredentialsFile string
+	// Endpoint overrides the storage API endpoint, e.g. for S3 compatible storages.
+	Endpoint string
+	// Region is the S3 region, defaults to the AWS_REGION environment variable or us-east-1.
+	Region string
+}
+
+// Gather returns the files in root matching any of the given glob patterns, keyed
+// by their slash separated path relative to root.
+func Gather(root string, patterns []string) (map[string]string, error) {
+	files := map[string]string{}
+	for _, pattern := range patterns {
+		matches, err := zglob.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to match %s", pattern)
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			rel, err := filepath.Rel(root, match)
+			if err != nil {
+				return nil, err
+			}
+			files[filepath.ToSlash(rel)] = match
+		}
+	}
+	return files, nil
+}
+
+// UploadFiles uploads the given files, keyed by their relative names, under the dir
+// of the destination. All files are attempted even if some fail.
+func UploadFiles(ctx context.Context, uploader Uploader, dest *Destination, dir string, files map[string]string, logger *logrus.Entry) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failed []string
+	for _, name := range names {
+		object := dest.Join(dir, name)
+		if err := uploadFile(ctx, uploader, object, files[name]); err != nil {
+			logger.WithError(err).Warnf("failed to upload %s", files[name])
+			failed = append(failed, name)
+			continue
+		}
+		logger.Debugf("uploaded %s to %s", files[name], object)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to upload %d file(s): %v", len(failed), failed)
+	}
+	return nil
+}
+
+func uploadFile(ctx context.Context, uploader Uploader, object, file string) error {
+	f, err := os.Open(file) // #nosec
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return uploader.Upload(ctx, object, f, info.Size(), contentType(file))
+}
+
+func contentType(file string) string {
+	switch filepath.Ext(file) {
+	case ".log", ".txt":
+		return "text/plain; charset=utf-8"
+	}
+	if t := mime.TypeByExtension(filepath.Ext(file)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,72 @@
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUploader struct {
+	objects map[string]string
+	fail    map[string]bool
+}
+
+func (f *fakeUploader) Upload(_ context.Context, name string, r io.Reader, _ int64, _ string) error {
+	if f.fail[name] {
+		return errors.New("injected failure")
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	f.objects[name] = string(data)
+	return nil
+}
+
+func writeFiles(t *testing.T, root string, files ...string) {
+	for _, f := range files {
+		path := filepath.Join(root, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(f), 0600))
+	}
+}
+
+func TestGather(t *testing.T) {
+	root, err := ioutil.TempDir("", "artifacts")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	writeFiles(t, root, "junit.xml", "pkg/foo/junit_foo.xml", "dist/app.tar.gz", "README.md")
+
+	files, err := Gather(root, []string{"**/junit*.xml", "dist/*", "missing/**/*"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"junit.xml":             filepath.Join(root, "junit.xml"),
+		"pkg/foo/junit_foo.xml": filepath.Join(root, "pkg/foo/junit_foo.xml"),
+		"dist/app.tar.gz":       filepath.Join(root, "dist/app.tar.gz"),
+	}, files)
+}
+
+func TestUploadFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "artifacts")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	writeFiles(t, root, "a.txt", "b.txt")
+
+	dest := &Destination{Scheme: GCSScheme, Bucket: "bucket", Prefix: "ci"}
+	uploader := &fakeUploader{objects: map[string]string{}, fail: map[string]bool{"ci/logs/job/1/b.txt": true}}
+	files := map[string]string{
+		"a.txt": filepath.Join(root, "a.txt"),
+		"b.txt": filepath.Join(root, "b.txt"),
+	}
+	err = UploadFiles(context.Background(), uploader, dest, "logs/job/1", files, logrus.NewEntry(logrus.StandardLogger()))
+	assert.Error(t, err)
+	assert.Equal(t, map[string]string{"ci/logs/job/1/a.txt": "a.txt"}, uploader.objects)
+}
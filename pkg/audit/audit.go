@@ -0,0 +1,164 @@
+// Package audit records the actions of lighthouse changing the state of the repositories and of the jobs, such as
+// the comments, labels, statuses, merges and the jobs created or aborted, with the user and the webhook which caused
+// them. The events are written as JSON lines on the standard output and optionally sent to an external sink.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/sirupsen/logrus"
+)
+
+// Action is a kind of state-changing action
+type Action string
+
+const (
+	// CommentCreated is the action of posting a comment
+	CommentCreated Action = "comment.create"
+	// CommentEdited is the action of editing a comment
+	CommentEdited Action = "comment.edit"
+	// CommentDeleted is the action of deleting a comment
+	CommentDeleted Action = "comment.delete"
+	// LabelAdded is the action of adding a label
+	LabelAdded Action = "label.add"
+	// LabelRemoved is the action of removing a label
+	LabelRemoved Action = "label.remove"
+	// StatusSet is the action of setting a commit status or a check run
+	StatusSet Action = "status.set"
+	// PullRequestCreated is the action of creating a pull request
+	PullRequestCreated Action = "pr.create"
+	// Merged is the action of merging a pull request
+	Merged Action = "pr.merge"
+	// Closed is the action of closing an issue or a pull request
+	Closed Action = "issue.close"
+	// Reopened is the action of reopening an issue or a pull request
+	Reopened Action = "issue.reopen"
+	// IssueCreated is the action of creating an issue
+	IssueCreated Action = "issue.create"
+	// Transferred is the action of transferring an issue to another repository
+	Transferred Action = "issue.transfer"
+	// ReviewDismissed is the action of dismissing a review of a pull request
+	ReviewDismissed Action = "review.dismiss"
+	// ReactionCreated is the action of adding a reaction to an issue or a pull request
+	ReactionCreated Action = "reaction.create"
+	// MemberInvited is the action of inviting a user to join an org
+	MemberInvited Action = "org.invite"
+	// JobCreated is the action of creating a LighthouseJob
+	JobCreated Action = "job.create"
+	// JobAborted is the action of aborting a LighthouseJob
+	JobAborted Action = "job.abort"
+)
+
+// Event is a state-changing action
+type Event struct {
+	Type      string            `json:"type"`
+	Time      time.Time         `json:"time"`
+	Component string            `json:"component,omitempty"`
+	Action    Action            `json:"action"`
+	Actor     string            `json:"actor,omitempty"`
+	EventID   string            `json:"eventID,omitempty"`
+	Org       string            `json:"org,omitempty"`
+	Repo      string            `json:"repo,omitempty"`
+	Number    int               `json:"number,omitempty"`
+	Target    string            `json:"target,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// eventType is the type of the audit events, distinguishing them from the other JSON lines of the components
+const eventType = "audit"
+
+// Source identifies what caused the actions: the user and the webhook delivery
+type Source struct {
+	Actor   string
+	EventID string
+}
+
+type sourceKey struct{}
+
+// ContextWithSource returns a context whose actions are caused by the given source
+func ContextWithSource(ctx context.Context, source Source) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// SourceFromContext returns the source of the actions of the context, if any. The context may be nil.
+func SourceFromContext(ctx context.Context) Source {
+	if ctx == nil {
+		return Source{}
+	}
+	source, _ := ctx.Value(sourceKey{}).(Source)
+	return source
+}
+
+var (
+	lock      sync.Mutex
+	out       io.Writer = os.Stdout
+	component string
+	sink      *Sink
+)
+
+// Record records an action caused by the source of the context, if any, and which failed with the given error if
+// not nil. The context may be nil.
+func Record(ctx context.Context, event Event, err error) {
+	source := SourceFromContext(ctx)
+	if event.Actor == "" {
+		event.Actor = source.Actor
+	}
+	if event.EventID == "" {
+		event.EventID = source.EventID
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	event.Type = eventType
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if event.Component == "" {
+		event.Component = component
+	}
+	data, err := json.Marshal(&event)
+	if err != nil {
+		logrus.WithError(err).WithField("action", event.Action).Warn("failed to marshal the audit event")
+		return
+	}
+	if _, err := out.Write(append(data, '\n')); err != nil {
+		logrus.WithError(err).WithField("action", event.Action).Warn("failed to write the audit event")
+	}
+	if sink != nil {
+		sink.add(data)
+	}
+}
+
+// JobEvent returns the event of an action on a LighthouseJob
+func JobEvent(action Action, j *v1alpha1.LighthouseJob) Event {
+	event := Event{
+		Action: action,
+		Target: j.Name,
+		Details: map[string]string{
+			"job":   j.Spec.Job,
+			"type":  string(j.Spec.Type),
+			"agent": j.Spec.Agent,
+		},
+	}
+	if refs := j.Spec.Refs; refs != nil {
+		event.Org = refs.Org
+		event.Repo = refs.Repo
+		if len(refs.Pulls) > 0 {
+			event.Number = refs.Pulls[0].Number
+		}
+	}
+	return event
+}
@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func capture() *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	lock.Lock()
+	out, component = buf, "lighthouse-webhooks"
+	lock.Unlock()
+	return buf
+}
+
+func decode(t *testing.T, data []byte) []Event {
+	var answer []Event
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		event := Event{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		answer = append(answer, event)
+	}
+	return answer
+}
+
+func TestRecord(t *testing.T) {
+	buf := capture()
+
+	ctx := ContextWithSource(nil, Source{Actor: "alice", EventID: "72d3162e"})
+	Record(ctx, Event{Action: LabelAdded, Org: "myorg", Repo: "myrepo", Number: 12, Target: "lgtm"}, nil)
+	Record(nil, Event{Action: Merged, Org: "myorg", Repo: "myrepo", Number: 12}, errors.New("base branch was modified"))
+
+	events := decode(t, buf.Bytes())
+	require.Len(t, events, 2)
+	assert.Equal(t, "audit", events[0].Type)
+	assert.Equal(t, "lighthouse-webhooks", events[0].Component)
+	assert.Equal(t, LabelAdded, events[0].Action)
+	assert.Equal(t, "alice", events[0].Actor)
+	assert.Equal(t, "72d3162e", events[0].EventID)
+	assert.Equal(t, "lgtm", events[0].Target)
+	assert.False(t, events[0].Time.IsZero())
+	assert.Empty(t, events[0].Error)
+
+	assert.Empty(t, events[1].Actor)
+	assert.Equal(t, "base branch was modified", events[1].Error)
+}
+
+func TestJobEvent(t *testing.T) {
+	j := &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myrepo-pr-12-lint-1"},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type:  "presubmit",
+			Agent: "tekton",
+			Job:   "lint",
+			Refs:  &v1alpha1.Refs{Org: "myorg", Repo: "myrepo", Pulls: []v1alpha1.Pull{{Number: 12}}},
+		},
+	}
+	event := JobEvent(JobCreated, j)
+	assert.Equal(t, Event{
+		Action:  JobCreated,
+		Org:     "myorg",
+		Repo:    "myrepo",
+		Number:  12,
+		Target:  "myorg-myrepo-pr-12-lint-1",
+		Details: map[string]string{"job": "lint", "type": "presubmit", "agent": "tekton"},
+	}, event)
+}
+
+func TestSink(t *testing.T) {
+	received := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := &bytes.Buffer{}
+		_, err := buf.ReadFrom(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		received <- buf.Bytes()
+	}))
+	defer server.Close()
+
+	capture()
+	s := NewSink(server.URL)
+	lock.Lock()
+	sink = s
+	lock.Unlock()
+
+	Record(nil, Event{Action: CommentCreated, Org: "myorg", Repo: "myrepo", Number: 1}, nil)
+	Record(nil, Event{Action: StatusSet, Org: "myorg", Repo: "myrepo", Target: "lint"}, nil)
+	s.Shutdown()
+
+	events := decode(t, <-received)
+	require.Len(t, events, 2)
+	assert.Equal(t, CommentCreated, events[0].Action)
+	assert.Equal(t, StatusSet, events[1].Action)
+
+	// the events recorded after the shutdown are only written on the output
+	Record(nil, Event{Action: CommentCreated}, nil)
+}
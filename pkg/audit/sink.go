@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SinkURLEnv is the environment variable of the URL the audit events are also sent to, as JSON lines
+	SinkURLEnv = "LIGHTHOUSE_AUDIT_SINK_URL"
+
+	// sinkBatchSize is the maximum number of events sent at once
+	sinkBatchSize = 100
+	// sinkFlushInterval is the interval between the sends of the events
+	sinkFlushInterval = 2 * time.Second
+	// sinkBufferSize caps the number of events waiting to be sent, the extra ones being only written on the
+	// standard output
+	sinkBufferSize = 10 * sinkBatchSize
+)
+
+// Init sets the component of the audit events and sends them to the sink configured in the environment, if any. It
+// returns the function sending the remaining events on shutdown.
+func Init(name string) func() {
+	lock.Lock()
+	defer lock.Unlock()
+	component = name
+	url := os.Getenv(SinkURLEnv)
+	if url == "" {
+		return func() {}
+	}
+	logrus.WithField("url", url).Info("sending the audit events")
+	sink = NewSink(url)
+	s := sink
+	return s.Shutdown
+}
+
+// Sink sends the audit events in batches to an HTTP endpoint, as JSON lines
+type Sink struct {
+	url    string
+	client *http.Client
+	events chan []byte
+	done   chan struct{}
+}
+
+// NewSink creates a sink sending the events to the given URL
+func NewSink(url string) *Sink {
+	s := &Sink{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		events: make(chan []byte, sinkBufferSize),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *Sink) add(event []byte) {
+	select {
+	case s.events <- event:
+	default:
+		logrus.Warn("dropping an audit event for the sink as it is lagging")
+	}
+}
+
+func (s *Sink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(sinkFlushInterval)
+	defer ticker.Stop()
+	var batch [][]byte
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.send(batch); err != nil {
+			logrus.WithError(err).Warn("failed to send the audit events")
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case event, ok := <-s.events:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= sinkBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Shutdown stops the sink once the remaining events are sent
+func (s *Sink) Shutdown() {
+	lock.Lock()
+	if sink == s {
+		sink = nil
+	}
+	lock.Unlock()
+	close(s.events)
+	<-s.done
+}
+
+func (s *Sink) send(batch [][]byte) error {
+	body := bytes.Join(batch, []byte{'\n'})
+	resp, err := s.client.Post(s.url, "application/x-ndjson", bytes.NewReader(append(body, '\n')))
+	if err != nil {
+		return errors.Wrapf(err, "failed to send %d audit events to %s", len(batch), s.url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send %d audit events to %s: status %d", len(batch), s.url, resp.StatusCode)
+	}
+	return nil
+}
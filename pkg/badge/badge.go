@@ -0,0 +1,72 @@
+// Package badge renders the health of the postsubmit jobs and of the keeper queue of the repositories as
+// SVG or JSON badges which can be embedded in README files.
+package badge
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+)
+
+// Colors of the badges
+const (
+	ColorGreen  = "#4c1"
+	ColorYellow = "#dfb317"
+	ColorRed    = "#e05d44"
+	ColorGrey   = "#9f9f9f"
+)
+
+// charWidth is the approximate width in pixels of a character of the 11px Verdana font used by the badges
+const charWidth = 7
+
+// Badge is a badge made of a label and a colored message, e.g. "build | passing"
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// endpointBadge is the JSON badge, in the schema of the shields.io endpoint badges
+type endpointBadge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// JSON returns the badge in the schema of the shields.io endpoint badges
+func (b *Badge) JSON() ([]byte, error) {
+	return json.Marshal(&endpointBadge{SchemaVersion: 1, Label: b.Label, Message: b.Message, Color: b.Color})
+}
+
+// SVG returns the badge as a flat SVG image
+func (b *Badge) SVG() []byte {
+	labelWidth := textWidth(b.Label)
+	messageWidth := textWidth(b.Message)
+	width := labelWidth + messageWidth
+	label, message := escape(b.Label), escape(b.Message)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, width, label, message)
+	fmt.Fprintf(buf, `<title>%s: %s</title>`, label, message)
+	buf.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(buf, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, width)
+	fmt.Fprintf(buf, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`,
+		labelWidth, labelWidth, messageWidth, escape(b.Color), width)
+	buf.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	fmt.Fprintf(buf, `<text x="%d" y="14">%s</text>`, labelWidth/2, label)
+	fmt.Fprintf(buf, `<text x="%d" y="14">%s</text>`, labelWidth+messageWidth/2, message)
+	buf.WriteString(`</g></svg>`)
+	return buf.Bytes()
+}
+
+func textWidth(text string) int {
+	return len([]rune(text))*charWidth + 10
+}
+
+func escape(text string) string {
+	buf := &bytes.Buffer{}
+	_ = xml.EscapeText(buf, []byte(text))
+	return buf.String()
+}
@@ -0,0 +1,156 @@
+package badge
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	clientset "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/keeper"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// Path is the path prefix the badges are served on, e.g. /badge/myorg/myrepo/release
+	Path = "/badge/"
+
+	// keeperBadge is the name of the badge of the keeper queue, taking precedence over a job of the same name
+	keeperBadge = "keeper"
+)
+
+// Handler serves the badges of the latest postsubmit result of a job on /badge/<org>/<repo>/<job> and of the
+// health of the keeper queue of a repository on /badge/<org>/<repo>/keeper. The optional branch query parameter
+// restricts the badge to a branch and format=json returns a shields.io endpoint badge rather than an SVG image.
+type Handler struct {
+	lhClient  clientset.Interface
+	namespace string
+	pools     func() []keeper.Pool
+	logger    *logrus.Entry
+}
+
+// NewHandler creates a handler serving the badges of the jobs of the namespace and of the given keeper pools
+func NewHandler(lhClient clientset.Interface, namespace string, pools func() []keeper.Pool) *Handler {
+	return &Handler{
+		lhClient:  lhClient,
+		namespace: namespace,
+		pools:     pools,
+		logger:    logrus.WithField("handler", "badge"),
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "405 Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, Path), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		http.Error(w, "400 Bad Request: the path must be /badge/<org>/<repo>/<job> or /badge/<org>/<repo>/keeper", http.StatusBadRequest)
+		return
+	}
+	org, repo, name := parts[0], parts[1], parts[2]
+	branch := r.URL.Query().Get("branch")
+
+	var b *Badge
+	if name == keeperBadge {
+		b = KeeperBadge(h.pools(), org, repo, branch)
+	} else {
+		list, err := h.lhClient.LighthouseV1alpha1().LighthouseJobs(h.namespace).List(metav1.ListOptions{
+			LabelSelector: labels.Set{
+				job.LighthouseJobTypeLabel: string(job.PostsubmitJob),
+				util.OrgLabel:              strings.ToLower(org),
+				util.RepoLabel:             repo,
+			}.String(),
+		})
+		if err != nil {
+			h.logger.WithError(err).Warn("failed to list the jobs")
+			http.Error(w, "500 Internal Server Error: failed to list the jobs", http.StatusInternalServerError)
+			return
+		}
+		b = JobBadge(list.Items, name, branch)
+	}
+
+	// badges are embedded in pages cached by the git providers, ask them to revalidate
+	w.Header().Set("Cache-Control", "no-cache, max-age=0")
+	if r.URL.Query().Get("format") == "json" {
+		data, err := b.JSON()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("500 Internal Server Error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	_, _ = w.Write(b.SVG())
+}
+
+// JobBadge returns the badge of the latest completed run of the postsubmit job, on the given branch if not empty
+func JobBadge(jobs []v1alpha1.LighthouseJob, name, branch string) *Badge {
+	var latest *v1alpha1.LighthouseJob
+	for i := range jobs {
+		j := &jobs[i]
+		if j.Spec.Type != job.PostsubmitJob || j.Spec.Job != name || j.Status.CompletionTime == nil {
+			continue
+		}
+		if branch != "" && (j.Spec.Refs == nil || j.Spec.Refs.BaseRef != branch) {
+			continue
+		}
+		if latest == nil || latest.Status.StartTime.Before(&j.Status.StartTime) {
+			latest = j
+		}
+	}
+	b := &Badge{Label: name, Message: "unknown", Color: ColorGrey}
+	if latest == nil {
+		return b
+	}
+	switch latest.Status.State {
+	case v1alpha1.SuccessState:
+		b.Message, b.Color = "passing", ColorGreen
+	case v1alpha1.FailureState:
+		b.Message, b.Color = "failing", ColorRed
+	case v1alpha1.ErrorState:
+		b.Message, b.Color = "error", ColorRed
+	case v1alpha1.AbortedState:
+		b.Message, b.Color = "aborted", ColorYellow
+	}
+	return b
+}
+
+// KeeperBadge returns the badge of the health of the keeper pools of the repository, on the given branch if
+// not empty
+func KeeperBadge(pools []keeper.Pool, org, repo, branch string) *Badge {
+	b := &Badge{Label: "merge queue", Message: "unknown", Color: ColorGrey}
+	found := false
+	queued := 0
+	for _, p := range pools {
+		if !strings.EqualFold(p.Org, org) || p.Repo != repo || (branch != "" && p.Branch != branch) {
+			continue
+		}
+		found = true
+		switch {
+		case p.Error != "":
+			b.Message, b.Color = "error", ColorRed
+			return b
+		case p.Action == keeper.PoolBlocked || len(p.Blockers) > 0:
+			b.Message, b.Color = "blocked", ColorRed
+			return b
+		}
+		queued += len(p.SuccessPRs) + len(p.PendingPRs) + len(p.MissingPRs)
+	}
+	if !found {
+		return b
+	}
+	b.Color = ColorGreen
+	b.Message = "idle"
+	if queued > 0 {
+		b.Message = fmt.Sprintf("%d queued", queued)
+	}
+	return b
+}
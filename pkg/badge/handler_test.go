@@ -0,0 +1,113 @@
+package badge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/keeper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const ns = "jx"
+
+var now = time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newJob(name, jobName, branch string, state v1alpha1.PipelineState, started time.Duration) *v1alpha1.LighthouseJob {
+	spec := v1alpha1.LighthouseJobSpec{
+		Type:  job.PostsubmitJob,
+		Job:   jobName,
+		Agent: job.TektonPipelineAgent,
+		Refs:  &v1alpha1.Refs{Org: "MyOrg", Repo: "myrepo", BaseRef: branch},
+	}
+	labels, annotations := jobutil.LabelsAndAnnotationsForSpec(spec, nil, nil)
+	lhjob := &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels, Annotations: annotations},
+		Spec:       spec,
+		Status: v1alpha1.LighthouseJobStatus{
+			State:     state,
+			StartTime: metav1.NewTime(now.Add(-started)),
+		},
+	}
+	if state != v1alpha1.PendingState {
+		completion := metav1.NewTime(now.Add(-started + time.Minute))
+		lhjob.Status.CompletionTime = &completion
+	}
+	return lhjob
+}
+
+func get(t *testing.T, h http.Handler, target string) *Badge {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	answer := &endpointBadge{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), answer))
+	assert.Equal(t, 1, answer.SchemaVersion)
+	return &Badge{Label: answer.Label, Message: answer.Message, Color: answer.Color}
+}
+
+func TestJobBadge(t *testing.T) {
+	objects := []runtime.Object{
+		newJob("release-1", "release", "master", v1alpha1.FailureState, 3*time.Hour),
+		newJob("release-2", "release", "master", v1alpha1.SuccessState, 2*time.Hour),
+		newJob("release-3", "release", "master", v1alpha1.PendingState, time.Hour),
+		newJob("release-4", "release", "v1", v1alpha1.FailureState, time.Hour),
+		newJob("docs-1", "docs", "master", v1alpha1.ErrorState, time.Hour),
+	}
+	h := NewHandler(fake.NewSimpleClientset(objects...), ns, func() []keeper.Pool { return nil })
+
+	assert.Equal(t, &Badge{Label: "release", Message: "failing", Color: ColorRed}, get(t, h, "/badge/myorg/myrepo/release?format=json"))
+	assert.Equal(t, &Badge{Label: "release", Message: "passing", Color: ColorGreen}, get(t, h, "/badge/MyOrg/myrepo/release?format=json&branch=master"))
+	assert.Equal(t, &Badge{Label: "docs", Message: "error", Color: ColorRed}, get(t, h, "/badge/myorg/myrepo/docs?format=json"))
+	assert.Equal(t, &Badge{Label: "lint", Message: "unknown", Color: ColorGrey}, get(t, h, "/badge/myorg/myrepo/lint?format=json"))
+	assert.Equal(t, &Badge{Label: "release", Message: "unknown", Color: ColorGrey}, get(t, h, "/badge/myorg/other/release?format=json"))
+}
+
+func TestKeeperBadge(t *testing.T) {
+	pools := []keeper.Pool{
+		{Org: "MyOrg", Repo: "myrepo", Branch: "master", Action: keeper.Merge, SuccessPRs: make([]keeper.PullRequest, 2), PendingPRs: make([]keeper.PullRequest, 1)},
+		{Org: "MyOrg", Repo: "myrepo", Branch: "v1", Action: keeper.Wait},
+		{Org: "MyOrg", Repo: "blocked", Branch: "master", Action: keeper.PoolBlocked},
+		{Org: "MyOrg", Repo: "broken", Branch: "master", Error: "failed to merge"},
+	}
+	h := NewHandler(fake.NewSimpleClientset(), ns, func() []keeper.Pool { return pools })
+
+	assert.Equal(t, &Badge{Label: "merge queue", Message: "3 queued", Color: ColorGreen}, get(t, h, "/badge/myorg/myrepo/keeper?format=json"))
+	assert.Equal(t, &Badge{Label: "merge queue", Message: "idle", Color: ColorGreen}, get(t, h, "/badge/myorg/myrepo/keeper?format=json&branch=v1"))
+	assert.Equal(t, &Badge{Label: "merge queue", Message: "blocked", Color: ColorRed}, get(t, h, "/badge/myorg/blocked/keeper?format=json"))
+	assert.Equal(t, &Badge{Label: "merge queue", Message: "error", Color: ColorRed}, get(t, h, "/badge/myorg/broken/keeper?format=json"))
+	assert.Equal(t, &Badge{Label: "merge queue", Message: "unknown", Color: ColorGrey}, get(t, h, "/badge/myorg/missing/keeper?format=json"))
+}
+
+func TestSVG(t *testing.T) {
+	h := NewHandler(fake.NewSimpleClientset(newJob("release-1", "release", "master", v1alpha1.SuccessState, time.Hour)), ns, nil)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/badge/myorg/myrepo/release", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/svg+xml", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<title>release: passing</title>")
+	assert.Contains(t, w.Body.String(), ColorGreen)
+
+	svg := string((&Badge{Label: "a<b", Message: "c&d", Color: ColorGrey}).SVG())
+	assert.Contains(t, svg, "<title>a&lt;b: c&amp;d</title>")
+}
+
+func TestInvalidPath(t *testing.T) {
+	h := NewHandler(fake.NewSimpleClientset(), ns, nil)
+	for _, target := range []string{"/badge/", "/badge/myorg/myrepo", "/badge/myorg/myrepo/release/extra", "/badge/myorg//release"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+}
@@ -0,0 +1,100 @@
+// Package botcomment maintains a single sticky bot comment per issue or pull request, identified by a hidden
+// marker, which is updated in place instead of appending a new comment on every change.
+package botcomment
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// Client is the part of the SCM client used to update the sticky comments of an issue or pull request
+type Client interface {
+	CreateComment(org, repo string, number int, pr bool, comment string) error
+	EditComment(org, repo string, number, id int, comment string, pr bool) error
+	DeleteComment(org, repo string, number, id int, pr bool) error
+}
+
+// ListingClient is a Client which can also list the comments of an issue or pull request
+type ListingClient interface {
+	Client
+	BotName() (string, error)
+	ListIssueComments(org, repo string, number int) ([]*scm.Comment, error)
+	ListPullRequestComments(org, repo string, number int) ([]*scm.Comment, error)
+}
+
+// Marker returns the hidden marker identifying the sticky comment with the given id
+func Marker(id string) string {
+	return fmt.Sprintf("<!-- lighthouse:%s -->", id)
+}
+
+// Find returns the comments of the bot containing the marker, in the order they were listed
+func Find(comments []*scm.Comment, botName, marker string) []*scm.Comment {
+	var found []*scm.Comment
+	for _, c := range comments {
+		if c.Author.Login == botName && strings.Contains(c.Body, marker) {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// Sync makes the latest bot comment with the marker among the given comments of the issue or pull request
+// the only one, updating its body in place, and deletes the obsolete ones. The comment is created if there is
+// none yet, and all of them are deleted if the body is empty. The marker is appended to the body if missing.
+func Sync(c Client, org, repo string, number int, pr bool, comments []*scm.Comment, botName, marker, body string) error {
+	existing := Find(comments, botName, marker)
+	var latest *scm.Comment
+	if body != "" && len(existing) > 0 {
+		latest = existing[len(existing)-1]
+		existing = existing[:len(existing)-1]
+	}
+	for _, obsolete := range existing {
+		if err := c.DeleteComment(org, repo, number, obsolete.ID, pr); err != nil {
+			return fmt.Errorf("failed to delete obsolete comment %d: %v", obsolete.ID, err)
+		}
+	}
+	if body == "" {
+		return nil
+	}
+	if !strings.Contains(body, marker) {
+		body = body + "\n\n" + marker
+	}
+	if latest == nil {
+		if err := c.CreateComment(org, repo, number, pr, body); err != nil {
+			return fmt.Errorf("failed to create comment: %v", err)
+		}
+		return nil
+	}
+	if latest.Body == body {
+		return nil
+	}
+	if err := c.EditComment(org, repo, number, latest.ID, body, pr); err != nil {
+		return fmt.Errorf("failed to update comment %d: %v", latest.ID, err)
+	}
+	return nil
+}
+
+// Upsert lists the comments of the issue or pull request and syncs its sticky comment with the marker
+func Upsert(c ListingClient, org, repo string, number int, pr bool, marker, body string) error {
+	botName, err := c.BotName()
+	if err != nil {
+		return fmt.Errorf("failed to get the bot name: %v", err)
+	}
+	var comments []*scm.Comment
+	if pr {
+		comments, err = c.ListPullRequestComments(org, repo, number)
+	} else {
+		comments, err = c.ListIssueComments(org, repo, number)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list the comments of %s/%s#%d: %v", org, repo, number, err)
+	}
+	return Sync(c, org, repo, number, pr, comments, botName, marker, body)
+}
+
+// Remove deletes the sticky comments with the marker of the issue or pull request
+func Remove(c ListingClient, org, repo string, number int, pr bool, marker string) error {
+	return Upsert(c, org, repo, number, pr, marker, "")
+}
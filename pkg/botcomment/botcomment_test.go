@@ -0,0 +1,99 @@
+package botcomment
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	comments []*scm.Comment
+	listedPR bool
+	created  []string
+	edited   map[int]string
+	deleted  []int
+}
+
+func (f *fakeClient) BotName() (string, error) {
+	return "bot", nil
+}
+
+func (f *fakeClient) ListIssueComments(string, string, int) ([]*scm.Comment, error) {
+	return f.comments, nil
+}
+
+func (f *fakeClient) ListPullRequestComments(string, string, int) ([]*scm.Comment, error) {
+	f.listedPR = true
+	return f.comments, nil
+}
+
+func (f *fakeClient) CreateComment(_, _ string, _ int, _ bool, comment string) error {
+	f.created = append(f.created, comment)
+	return nil
+}
+
+func (f *fakeClient) EditComment(_, _ string, _, id int, comment string, _ bool) error {
+	if f.edited == nil {
+		f.edited = map[int]string{}
+	}
+	f.edited[id] = comment
+	return nil
+}
+
+func (f *fakeClient) DeleteComment(_, _ string, _, id int, _ bool) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func TestUpsertCreates(t *testing.T) {
+	marker := Marker("test")
+	c := &fakeClient{comments: []*scm.Comment{
+		{ID: 1, Author: scm.User{Login: "someone"}, Body: "hello " + marker},
+		{ID: 2, Author: scm.User{Login: "bot"}, Body: "unrelated"},
+	}}
+	require.NoError(t, Upsert(c, "org", "repo", 1, true, marker, "body"))
+	assert.True(t, c.listedPR)
+	assert.Equal(t, []string{"body\n\n<!-- lighthouse:test -->"}, c.created)
+	assert.Empty(t, c.edited)
+	assert.Empty(t, c.deleted)
+}
+
+func TestUpsertUpdatesLatestAndPrunesObsolete(t *testing.T) {
+	marker := Marker("test")
+	c := &fakeClient{comments: []*scm.Comment{
+		{ID: 1, Author: scm.User{Login: "bot"}, Body: "first\n\n" + marker},
+		{ID: 2, Author: scm.User{Login: "bot"}, Body: "second\n\n" + marker},
+		{ID: 3, Author: scm.User{Login: "bot"}, Body: "third\n\n" + marker},
+	}}
+	require.NoError(t, Upsert(c, "org", "repo", 1, false, marker, "fourth"))
+	assert.False(t, c.listedPR)
+	assert.Equal(t, []int{1, 2}, c.deleted)
+	assert.Equal(t, map[int]string{3: "fourth\n\n" + marker}, c.edited)
+	assert.Empty(t, c.created)
+}
+
+func TestUpsertUnchanged(t *testing.T) {
+	marker := Marker("test")
+	c := &fakeClient{comments: []*scm.Comment{
+		{ID: 1, Author: scm.User{Login: "bot"}, Body: "same " + marker},
+	}}
+	require.NoError(t, Upsert(c, "org", "repo", 1, true, marker, "same "+marker))
+	assert.Empty(t, c.edited)
+	assert.Empty(t, c.created)
+	assert.Empty(t, c.deleted)
+}
+
+func TestRemove(t *testing.T) {
+	marker := Marker("test")
+	c := &fakeClient{comments: []*scm.Comment{
+		{ID: 1, Author: scm.User{Login: "bot"}, Body: "first " + marker},
+		{ID: 2, Author: scm.User{Login: "bot"}, Body: "other " + Marker("other")},
+		{ID: 3, Author: scm.User{Login: "bot"}, Body: "second " + marker},
+	}}
+	require.NoError(t, Remove(c, "org", "repo", 1, true, marker))
+	assert.Equal(t, []int{1, 3}, c.deleted)
+	assert.Empty(t, c.edited)
+	assert.Empty(t, c.created)
+}
@@ -0,0 +1,186 @@
+// Package cloudevents emits the CloudEvents of the lifecycle of the LighthouseJobs and of the merges of keeper to
+// the configured sink, using the binary content mode of the CloudEvents HTTP protocol binding so that the events
+// can be consumed by Knative eventing, Argo Events or any HTTP endpoint.
+package cloudevents
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/statuswebhook"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SpecVersion is the version of the CloudEvents specification of the events
+	SpecVersion = "1.0"
+
+	// JobCreatedType is the type of the events emitted when a job has been triggered
+	JobCreatedType = "dev.lighthouse.job.created.v1"
+	// JobStartedType is the type of the events emitted when a job starts running
+	JobStartedType = "dev.lighthouse.job.started.v1"
+	// JobCompletedType is the type of the events emitted when a job succeeded, failed or was aborted
+	JobCompletedType = "dev.lighthouse.job.completed.v1"
+	// MergedType is the type of the events emitted when keeper merged a pull request
+	MergedType = "dev.lighthouse.keeper.merged.v1"
+
+	maxRetries = 3
+)
+
+// Event is a CloudEvent, its data being encoded as JSON.
+type Event struct {
+	// ID identifies the event, the same event being emitted again with the same ID
+	ID string
+	// Type is the type of the event, e.g. JobCompletedType
+	Type string
+	// Subject is the subject of the event in the context of the source, e.g. the name of the LighthouseJob
+	Subject string
+	// Time is when the event occurred
+	Time time.Time
+	// Data is the payload of the event
+	Data interface{}
+}
+
+// MergePayload is the data of the merge events.
+type MergePayload struct {
+	// Org is the org of the repository of the pull request
+	Org string `json:"org"`
+	// Repo is the name of the repository of the pull request
+	Repo string `json:"repo"`
+	// Number is the number of the pull request
+	Number int `json:"number"`
+	// Title is the title of the pull request
+	Title string `json:"title,omitempty"`
+	// Author is the login of the author of the pull request
+	Author string `json:"author,omitempty"`
+	// SHA is the head commit of the merged pull request
+	SHA string `json:"sha"`
+	// BaseRef is the branch the pull request was merged into
+	BaseRef string `json:"base_ref"`
+	// MergeMethod is the method the pull request was merged with
+	MergeMethod string `json:"merge_method,omitempty"`
+}
+
+// JobEvent returns the event of the given status webhook event of the LighthouseJob, the data of the event being
+// the status webhook payload, or nil if the status webhook event is unknown.
+func JobEvent(event string, lhjob *v1alpha1.LighthouseJob, now time.Time) *Event {
+	var eventType string
+	switch event {
+	case lighthouse.StatusWebhookTriggered:
+		eventType = JobCreatedType
+	case lighthouse.StatusWebhookRunning:
+		eventType = JobStartedType
+	case lighthouse.StatusWebhookSucceeded, lighthouse.StatusWebhookFailed, lighthouse.StatusWebhookAborted:
+		eventType = JobCompletedType
+	default:
+		return nil
+	}
+	return &Event{
+		ID:      fmt.Sprintf("%s-%s", lhjob.Name, event),
+		Type:    eventType,
+		Subject: lhjob.Name,
+		Time:    now,
+		Data:    statuswebhook.NewPayload(event, lhjob),
+	}
+}
+
+// MergedEvent returns the event of the merge of a pull request.
+func MergedEvent(payload *MergePayload, now time.Time) *Event {
+	return &Event{
+		ID:      fmt.Sprintf("%s/%s#%d-%s", payload.Org, payload.Repo, payload.Number, payload.SHA),
+		Type:    MergedType,
+		Subject: fmt.Sprintf("%s/%s#%d", payload.Org, payload.Repo, payload.Number),
+		Time:    now,
+		Data:    payload,
+	}
+}
+
+// Emitter posts the events to the sink configured in the lighthouse config.
+type Emitter struct {
+	client  *http.Client
+	logger  *logrus.Entry
+	backoff time.Duration
+}
+
+// NewEmitter creates a new emitter
+func NewEmitter(logger *logrus.Entry) *Emitter {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	return &Emitter{
+		client:  &http.Client{Timeout: 30 * time.Second},
+		logger:  logger.WithField("emitter", "cloudevents"),
+		backoff: time.Second,
+	}
+}
+
+// Emit posts the event to the sink of the config, retrying on server errors. Nothing is emitted if no sink is
+// configured.
+func (e *Emitter) Emit(cfg *lighthouse.CloudEvents, event *Event) error {
+	if cfg.Sink == "" || event == nil {
+		return nil
+	}
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the data of the %s event: %v", event.Type, err)
+	}
+	source := cfg.Source
+	if source == "" {
+		source = lighthouse.DefaultCloudEventsSource
+	}
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	headers.Set("User-Agent", util.LighthouseUserAgent)
+	headers.Set("Ce-Specversion", SpecVersion)
+	headers.Set("Ce-Id", event.ID)
+	headers.Set("Ce-Source", source)
+	headers.Set("Ce-Type", event.Type)
+	headers.Set("Ce-Subject", event.Subject)
+	headers.Set("Ce-Time", event.Time.UTC().Format(time.RFC3339Nano))
+
+	logger := e.logger.WithField("type", event.Type).WithField("subject", event.Subject)
+	backoff := e.backoff
+	for retries := 0; retries < maxRetries; retries++ {
+		if retries > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		var retry bool
+		retry, err = e.post(cfg.Sink, data, headers)
+		if err == nil || !retry {
+			break
+		}
+	}
+	if err != nil {
+		logger.WithError(err).Warn("failed to emit the event")
+		return fmt.Errorf("failed to emit the %s event of %s: %v", event.Type, event.Subject, err)
+	}
+	logger.Debug("emitted the event")
+	return nil
+}
+
+// post posts the data to the url, returning whether the request can be retried on error
+func (e *Emitter) post(url string, data []byte, headers http.Header) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	req.Header = headers
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, fmt.Errorf("response has status %q and body %q", resp.Status, string(body))
+	}
+	return false, nil
+}
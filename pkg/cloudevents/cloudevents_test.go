@@ -0,0 +1,95 @@
+package cloudevents
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestJobEvent(t *testing.T) {
+	lhjob := &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc123"},
+		Spec:       v1alpha1.LighthouseJobSpec{Job: "unit"},
+		Status:     v1alpha1.LighthouseJobStatus{State: v1alpha1.FailureState},
+	}
+	tests := map[string]string{
+		lighthouse.StatusWebhookTriggered: JobCreatedType,
+		lighthouse.StatusWebhookRunning:   JobStartedType,
+		lighthouse.StatusWebhookSucceeded: JobCompletedType,
+		lighthouse.StatusWebhookFailed:    JobCompletedType,
+		lighthouse.StatusWebhookAborted:   JobCompletedType,
+	}
+	for event, eventType := range tests {
+		ce := JobEvent(event, lhjob, time.Now())
+		require.NotNil(t, ce, event)
+		assert.Equal(t, eventType, ce.Type, event)
+		assert.Equal(t, "abc123-"+event, ce.ID, event)
+		assert.Equal(t, "abc123", ce.Subject, event)
+	}
+	assert.Nil(t, JobEvent("unknown", lhjob, time.Now()))
+}
+
+func TestEmit(t *testing.T) {
+	var headers http.Header
+	var body map[string]interface{}
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			http.Error(w, "broker unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		headers = r.Header
+		data, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &body))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	e := NewEmitter(nil)
+	e.backoff = time.Millisecond
+	now := time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+	event := MergedEvent(&MergePayload{Org: "org", Repo: "repo", Number: 7, SHA: "abc", BaseRef: "master"}, now)
+	require.NoError(t, e.Emit(&lighthouse.CloudEvents{Sink: server.URL, Source: "https://lighthouse.example.com"}, event))
+
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, "1.0", headers.Get("Ce-Specversion"))
+	assert.Equal(t, "org/repo#7-abc", headers.Get("Ce-Id"))
+	assert.Equal(t, "https://lighthouse.example.com", headers.Get("Ce-Source"))
+	assert.Equal(t, MergedType, headers.Get("Ce-Type"))
+	assert.Equal(t, "org/repo#7", headers.Get("Ce-Subject"))
+	assert.Equal(t, "2020-07-01T10:00:00Z", headers.Get("Ce-Time"))
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+	assert.Equal(t, float64(7), body["number"])
+	assert.Equal(t, "master", body["base_ref"])
+}
+
+func TestEmitClientError(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "bad event", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	e := NewEmitter(nil)
+	e.backoff = time.Millisecond
+	err := e.Emit(&lighthouse.CloudEvents{Sink: server.URL}, MergedEvent(&MergePayload{Org: "org", Repo: "repo", Number: 7}, time.Now()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad event")
+	assert.Equal(t, 1, attempts)
+
+	// nothing is emitted without a sink
+	require.NoError(t, e.Emit(&lighthouse.CloudEvents{}, MergedEvent(&MergePayload{}, time.Now())))
+	assert.Equal(t, 1, attempts)
+}
@@ -169,7 +169,7 @@ func yamlToConfig(path string, nc interface{}) error {
 	if err != nil {
 		return fmt.Errorf("error reading %s: %v", path, err)
 	}
-	if err := yaml.Unmarshal(b, nc); err != nil {
+	if err := unmarshalJobConfig(b, nc); err != nil {
 		return fmt.Errorf("error unmarshaling %s: %v", path, err)
 	}
 	var jc *job.Config
@@ -206,28 +206,66 @@ func yamlToConfig(path string, nc interface{}) error {
 }
 
 // LoadYAMLConfig loads the configuration from the given data
-func LoadYAMLConfig(data []byte) (*Config, error) {
-	c := &Config{}
-	if err := yaml.Unmarshal(data, c); err != nil {
+func LoadYAMLConfig(data []byte) (c *Config, err error) {
+	// we never want reloading an invalid config to take down the lighthouse components
+	defer func() {
+		if r := recover(); r != nil {
+			c, err = nil, fmt.Errorf("panic loading config: %v", r)
+		}
+	}()
+	c = &Config{}
+	j, err := jobConfigJSON(data)
+	if err != nil {
+		return c, err
+	}
+	if err := yaml.Unmarshal(j, &c.ProwConfig); err != nil {
+		// report the error against the whole configuration, as when its jobs are not loaded lazily
+		if err := yaml.Unmarshal(j, c); err != nil {
+			return c, err
+		}
 		return c, err
 	}
 	if err := parseProwConfig(c); err != nil {
 		return c, err
 	}
+	if c.LazyJobConfig != nil && c.LazyJobConfig.Enabled {
+		err = c.JobConfig.LoadLazily(j, c.ProwConfig, c.LazyJobConfig.CacheSize)
+	} else {
+		err = yaml.Unmarshal(j, &c.JobConfig)
+	}
+	if err != nil {
+		return c, err
+	}
 
 	return c.finalizeAndValidate()
 }
 
+// unmarshalJobConfig unmarshals the YAML document after merging its repository jobs with the jobs of their org
+func unmarshalJobConfig(data []byte, nc interface{}) error {
+	j, err := jobConfigJSON(data)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(j, nc)
+}
+
+// jobConfigJSON converts the YAML document to JSON, merging its repository jobs with the jobs of their org
+func jobConfigJSON(data []byte) ([]byte, error) {
+	j, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		// same error as when unmarshalling the YAML document directly
+		return nil, fmt.Errorf("error converting YAML to JSON: %v", err)
+	}
+	return job.MergeOrgDefaults(j)
+}
+
 func parseProwConfig(c *Config) error {
 	if err := c.ProwConfig.Parse(); err != nil {
 		return err
 	}
-	lvl, err := logrus.ParseLevel(c.LogLevel)
-	if err != nil {
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
 		return err
 	}
-	logrus.WithField("level", lvl.String()).Infof("setting the log level")
-	logrus.SetLevel(lvl)
 	return nil
 }
 
@@ -239,6 +277,11 @@ func (c *Config) finalizeAndValidate() (*Config, error) {
 	if err := c.JobConfig.Validate(c.ProwConfig); err != nil {
 		return nil, err
 	}
+	// only apply the log level once the whole configuration is valid
+	if lvl, err := logrus.ParseLevel(c.LogLevel); err == nil {
+		logrus.WithField("level", lvl.String()).Infof("setting the log level")
+		logrus.SetLevel(lvl)
+	}
 	return c, nil
 }
 
@@ -247,7 +290,17 @@ func (c *Config) GetPostsubmits(repository scm.Repository) []job.Postsubmit {
 	fullNames := util.FullNames(repository)
 	var answer []job.Postsubmit
 	for _, fn := range fullNames {
-		answer = append(answer, c.Postsubmits[fn]...)
+		answer = append(answer, c.RepoPostsubmits(fn)...)
+	}
+	return answer
+}
+
+// GetOps returns the ops jobs for the given repo
+func (c *Config) GetOps(repository scm.Repository) []job.Ops {
+	fullNames := util.FullNames(repository)
+	var answer []job.Ops
+	for _, fn := range fullNames {
+		answer = append(answer, c.RepoOps(fn)...)
 	}
 	return answer
 }
@@ -257,17 +310,27 @@ func (c *Config) GetPresubmits(repository scm.Repository) []job.Presubmit {
 	fullNames := util.FullNames(repository)
 	var answer []job.Presubmit
 	for _, fn := range fullNames {
-		answer = append(answer, c.Presubmits[fn]...)
+		answer = append(answer, c.RepoPresubmits(fn)...)
 	}
 	return answer
 }
 
+// branchPresubmits returns the presubmits of the repository keyed by its full name, as expected by BranchRequirements,
+// so that the presubmits of a job configuration loaded lazily are only parsed for the repository
+func (c *Config) branchPresubmits(org, repo string) map[string][]job.Presubmit {
+	return map[string][]job.Presubmit{org + "/" + repo: c.RepoPresubmits(org + "/" + repo)}
+}
+
 // BranchRequirements partitions status contexts for a given org, repo branch into three buckets:
 //  - contexts that are always required to be present
 //  - contexts that are required, _if_ present
 //  - contexts that are always optional
 func BranchRequirements(org, repo, branch string, presubmits map[string][]job.Presubmit) ([]string, []string, []string) {
 	jobs, ok := presubmits[org+"/"+repo]
+	if !ok {
+		// repositories without jobs of their own inherit the jobs of their org
+		jobs, ok = presubmits[org]
+	}
 	if !ok {
 		return nil, nil, nil
 	}
@@ -294,6 +357,25 @@ func BranchRequirements(org, repo, branch string, presubmits map[string][]job.Pr
 	return required, requiredIfPresent, optional
 }
 
+// PreviousContexts returns the contexts the presubmits which could run on the given org, repo and branch reported
+// before their contexts were renamed, which are no longer reported by any of them.
+func PreviousContexts(org, repo, branch string, presubmits map[string][]job.Presubmit) []string {
+	jobs, ok := presubmits[org+"/"+repo]
+	if !ok {
+		jobs = presubmits[org]
+	}
+	current := sets.NewString()
+	previous := sets.NewString()
+	for _, j := range jobs {
+		if !j.CouldRun(branch) {
+			continue
+		}
+		current.Insert(j.Context)
+		previous.Insert(j.PreviousContexts...)
+	}
+	return previous.Difference(current).List()
+}
+
 // GetBranchProtection returns the policy for a given branch.
 //
 // Handles merging any policies defined at repo/org/global levels into the branch policy.
@@ -314,7 +396,7 @@ func (c *Config) GetPolicy(org, repo, branch string, b branchprotection.Branch)
 	policy := b.Policy
 
 	// Automatically require contexts from prow which must always be present
-	if prowContexts, _, _ := BranchRequirements(org, repo, branch, c.Presubmits); len(prowContexts) > 0 {
+	if prowContexts, _, _ := BranchRequirements(org, repo, branch, c.branchPresubmits(org, repo)); len(prowContexts) > 0 {
 		// Error if protection is disabled
 		if policy.Protect != nil && !*policy.Protect {
 			if c.BranchProtection.AllowDisabledJobPolicies {
@@ -370,7 +452,7 @@ func (c Config) GetKeeperContextPolicy(org, repo, branch string) (*keeper.Contex
 	optional := sets.NewString(options.OptionalContexts...)
 
 	// automatically generate required and optional entries for Prow Pipelines
-	prowRequired, prowRequiredIfPresent, prowOptional := BranchRequirements(org, repo, branch, c.Presubmits)
+	prowRequired, prowRequiredIfPresent, prowOptional := BranchRequirements(org, repo, branch, c.branchPresubmits(org, repo))
 	required.Insert(prowRequired...)
 	requiredIfPresent.Insert(prowRequiredIfPresent...)
 	optional.Insert(prowOptional...)
@@ -389,6 +471,12 @@ func (c Config) GetKeeperContextPolicy(org, repo, branch string) (*keeper.Contex
 	// duplicated by branch protection.
 	required.Delete(requiredIfPresent.List()...)
 
+	// The renamed contexts are not reported anymore, they must not block the pull requests which still have them.
+	previous := PreviousContexts(org, repo, branch, c.branchPresubmits(org, repo))
+	required.Delete(previous...)
+	requiredIfPresent.Delete(previous...)
+	optional.Insert(previous...)
+
 	t := &keeper.ContextPolicy{
 		RequiredContexts:          required.List(),
 		RequiredIfPresentContexts: requiredIfPresent.List(),
@@ -25,9 +25,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	"github.com/jenkins-x/lighthouse/pkg/config/secret"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -227,6 +229,50 @@ func TestValidateJobBase(t *testing.T) {
 				Namespace:      &ns,
 			},
 		},
+		{
+			name: "valid pipeline reference",
+			base: job.Base{
+				Name:  "name",
+				Agent: job.TektonPipelineAgent,
+				PipelineRef: &job.PipelineRef{
+					Resolver: job.GitResolver,
+					Params: map[string]string{
+						"url":        "https://github.com/org/pipelines.git",
+						"revision":   "{{ .Refs.BaseSHA }}",
+						"pathInRepo": "pipelines/build.yaml",
+					},
+				},
+				Namespace: &ns,
+			},
+			pass: true,
+		},
+		{
+			name: "pipeline reference without the params of its resolver",
+			base: job.Base{
+				Name:        "name",
+				Agent:       job.TektonPipelineAgent,
+				PipelineRef: &job.PipelineRef{Resolver: job.BundlesResolver, Params: map[string]string{"name": "build"}},
+				Namespace:   &ns,
+			},
+		},
+		{
+			name: "pipeline reference of an unknown resolver",
+			base: job.Base{
+				Name:        "name",
+				Agent:       job.TektonPipelineAgent,
+				PipelineRef: &job.PipelineRef{Resolver: "hub", Params: map[string]string{"name": "build"}},
+				Namespace:   &ns,
+			},
+		},
+		{
+			name: "pipeline reference of a jenkins job",
+			base: job.Base{
+				Name:        "name",
+				Agent:       job.JenkinsAgent,
+				PipelineRef: &job.PipelineRef{Resolver: job.ClusterResolver, Params: map[string]string{"name": "build"}},
+				Namespace:   &ns,
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -824,6 +870,105 @@ periodics:
 				},
 			},
 		},
+		{
+			name: "test named presets referenced by jobs",
+			prowConfig: `
+presets:
+- name: go-env
+  env:
+  - name: GOPROXY
+    value: https://proxy.golang.org
+- name: docker
+  labels:
+    preset-docker: "true"
+  env:
+  - name: DOCKER_HOST
+    value: tcp://localhost:2375`,
+			jobConfigs: []string{
+				`
+presubmits:
+  foo/bar:
+  - agent: tekton
+    name: build
+    presets:
+    - go-env
+    - docker
+    spec:
+      containers:
+      - image: alpine
+  - agent: tekton
+    name: lint
+    labels:
+      preset-docker: "true"
+    spec:
+      containers:
+      - image: alpine
+  - agent: tekton
+    name: docs
+    spec:
+      containers:
+      - image: alpine`,
+			},
+			expectEnv: map[string][]v1.EnvVar{
+				"build": {
+					{
+						Name:  "GOPROXY",
+						Value: "https://proxy.golang.org",
+					},
+					{
+						Name:  "DOCKER_HOST",
+						Value: "tcp://localhost:2375",
+					},
+				},
+				"lint": {
+					{
+						Name:  "DOCKER_HOST",
+						Value: "tcp://localhost:2375",
+					},
+				},
+				"docs": nil,
+			},
+		},
+		{
+			name: "test unknown preset referenced by a job",
+			prowConfig: `
+presets:
+- name: go-env
+  env:
+  - name: GOPROXY
+    value: https://proxy.golang.org`,
+			jobConfigs: []string{
+				`
+postsubmits:
+  foo/bar:
+  - agent: tekton
+    name: release
+    presets:
+    - node-env
+    spec:
+      containers:
+      - image: alpine`,
+			},
+			expectError: true,
+		},
+		{
+			name: "test duplicated preset names",
+			prowConfig: `
+presets:
+- name: go-env
+  env:
+  - name: GOPROXY
+    value: https://proxy.golang.org`,
+			jobConfigs: []string{
+				`
+presets:
+- name: go-env
+  env:
+  - name: GOFLAGS
+    value: -mod=mod`,
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -970,6 +1115,118 @@ presubmits:
 	}
 }
 
+func TestLoadYAMLConfig_OrgDefaults(t *testing.T) {
+	configYaml := `
+presubmits:
+  myorg:
+    - agent: tekton
+      always_run: true
+      name: lint
+      labels:
+        team: platform
+    - agent: tekton
+      always_run: true
+      name: unit
+  myorg/custom:
+    - name: lint
+      always_run: false
+      optional: true
+      labels:
+        owner: custom
+    - name: unit
+      disable: true
+    - agent: tekton
+      name: e2e
+postsubmits:
+  myorg:
+    - agent: tekton
+      name: release
+      branches:
+        - master
+`
+	cfg, err := LoadYAMLConfig([]byte(configYaml))
+	require.NoError(t, err)
+
+	custom := cfg.GetPresubmits(scm.Repository{Namespace: "myorg", Name: "custom"})
+	require.Len(t, custom, 2, "custom should have lint and e2e")
+	lint := custom[0]
+	assert.Equal(t, "lint", lint.Name)
+	assert.Equal(t, "tekton", lint.Agent, "agent should be inherited from the org")
+	assert.False(t, lint.AlwaysRun, "always_run should be overridden")
+	assert.True(t, lint.Optional, "optional should be overridden")
+	assert.Equal(t, map[string]string{"team": "platform", "owner": "custom"}, lint.Labels, "labels should be deep merged")
+	assert.Equal(t, "e2e", custom[1].Name)
+
+	other := cfg.GetPresubmits(scm.Repository{Namespace: "myorg", Name: "other"})
+	require.Len(t, other, 2, "other should inherit the org jobs")
+	assert.True(t, other[0].AlwaysRun)
+	assert.Equal(t, map[string]string{"team": "platform"}, other[0].Labels)
+
+	assert.Len(t, cfg.GetPostsubmits(scm.Repository{Namespace: "myorg", Name: "custom"}), 1, "postsubmits of custom")
+	assert.Empty(t, cfg.GetPresubmits(scm.Repository{Namespace: "otherorg", Name: "custom"}), "other orgs should not inherit")
+}
+
+func TestLoadYAMLConfig_Lazy(t *testing.T) {
+	configYaml := `
+lazy_job_config:
+  enabled: true
+  cache_size: 1
+presubmits:
+  myorg:
+    - agent: tekton
+      always_run: true
+      name: lint
+  myorg/custom:
+    - name: lint
+      always_run: false
+      optional: true
+    - agent: tekton
+      name: e2e
+      trigger: (?m)^/test e2e
+      rerun_command: /test e2e
+postsubmits:
+  myorg/custom:
+    - agent: tekton
+      name: release
+      branches:
+        - master
+`
+	cfg, err := LoadYAMLConfig([]byte(configYaml))
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Presubmits, "the presubmits are kept serialized")
+
+	for i := 0; i < 2; i++ {
+		custom := cfg.GetPresubmits(scm.Repository{Namespace: "myorg", Name: "custom"})
+		require.Len(t, custom, 2)
+		assert.Equal(t, "lint", custom[0].Name)
+		assert.Equal(t, "tekton", custom[0].Agent, "agent should be inherited from the org")
+		assert.True(t, custom[0].Optional)
+		assert.Equal(t, "lint", custom[0].Context, "the defaults are set")
+		assert.True(t, custom[1].TriggerMatches("/test e2e"), "the regexes are set")
+
+		other := cfg.GetPresubmits(scm.Repository{Namespace: "myorg", Name: "other"})
+		require.Len(t, other, 1, "other should inherit the org jobs")
+		assert.True(t, other[0].AlwaysRun)
+	}
+	assert.Len(t, cfg.GetPostsubmits(scm.Repository{Namespace: "myorg", Name: "custom"}), 1)
+	assert.Empty(t, cfg.GetPostsubmits(scm.Repository{Namespace: "myorg", Name: "other"}))
+	assert.Len(t, cfg.AllPresubmits(nil), 3)
+
+	required, requiredIfPresent, optional := BranchRequirements("myorg", "custom", "master", cfg.branchPresubmits("myorg", "custom"))
+	assert.Empty(t, required)
+	assert.Equal(t, []string{"e2e"}, requiredIfPresent)
+	assert.Equal(t, []string{"lint"}, optional)
+
+	_, err = LoadYAMLConfig([]byte(configYaml + `
+  myorg/broken:
+    - agent: tekton
+      name: release
+    - agent: tekton
+      name: release
+`))
+	assert.Error(t, err, "the jobs kept serialized are validated")
+}
+
 func TestBrancher_Intersects(t *testing.T) {
 	testCases := []struct {
 		name   string
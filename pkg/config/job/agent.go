@@ -37,9 +37,27 @@ const (
 
 	// JenkinsAgent is the agent type for running Jenkins pipelines
 	JenkinsAgent = "jenkins"
+
+	// GitHubActionsAgent is the agent type for running GitHub Actions workflows
+	GitHubActionsAgent = "github-actions"
+
+	// DroneAgent is the agent type for running Drone builds
+	DroneAgent = "drone"
+
+	// BuildkiteAgent is the agent type for running Buildkite builds
+	BuildkiteAgent = "buildkite"
 )
 
 // AvailablePipelineAgentTypes returns a slice of all available pipeline agent types
 func AvailablePipelineAgentTypes() []string {
-	return []string{JenkinsXAgent, LegacyDefaultAgent, TektonPipelineAgent, JenkinsAgent}
+	return []string{JenkinsXAgent, LegacyDefaultAgent, TektonPipelineAgent, JenkinsAgent, GitHubActionsAgent, DroneAgent, BuildkiteAgent}
 }
+
+// Events used to dispatch GitHub Actions workflows.
+const (
+	// WorkflowDispatchEvent triggers a specific workflow using the workflow_dispatch event
+	WorkflowDispatchEvent = "workflow_dispatch"
+
+	// RepositoryDispatchEvent triggers all workflows listening for a repository_dispatch event type
+	RepositoryDispatchEvent = "repository_dispatch"
+)
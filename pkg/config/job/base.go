@@ -24,7 +24,9 @@ import (
 
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -44,8 +46,18 @@ type Base struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// Annotations are unused by prow itself, but provide a space to configure other automation.
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// Presets are the names of the presets applied to the pod spec of this job, in addition
+	// to the presets selected by its labels.
+	Presets []string `json:"presets,omitempty"`
 	// MaximumConcurrency of this job, 0 implies no limit.
 	MaxConcurrency int `json:"max_concurrency,omitempty"`
+	// Priority of this job, the jenkins, drone, buildkite and github-actions engines
+	// start the jobs with a higher priority first. The tekton engine starts the
+	// jobs right away, use PriorityClassName to prioritize their pods. Defaults to 0.
+	Priority int `json:"priority,omitempty"`
+	// PriorityClassName is the Kubernetes PriorityClass given to the pods of this job so
+	// they can preempt pods of lower priority jobs.
+	PriorityClassName string `json:"priority_class_name,omitempty"`
 	// Agent that will take care of running this job.
 	Agent string `json:"agent"`
 	// Cluster is the alias of the cluster to run this job in.
@@ -68,6 +80,48 @@ type Base struct {
 	PipelineRunSpec *tektonv1beta1.PipelineRunSpec `json:"pipeline_run_spec,omitempty"`
 	// PipelineRunParams are the params used by the pipeline run
 	PipelineRunParams []PipelineRunParam `json:"pipeline_run_params,omitempty"`
+	// PipelineRunOverrides are merged into the PipelineRun of the job if agent is tekton-pipeline
+	PipelineRunOverrides *PipelineRunOverrides `json:"pipeline_run_overrides,omitempty"`
+	// PipelineRef references the pipeline resolved by a Tekton resolver if agent is tekton-pipeline,
+	// instead of the pipeline of the pipeline_run_spec
+	PipelineRef *PipelineRef `json:"pipeline_ref,omitempty"`
+	// Artifacts configures where the build logs, junit results and artifacts of the job are uploaded
+	Artifacts *ArtifactsSpec `json:"artifacts,omitempty"`
+	// EmailReport emails the failures of the postsubmit or periodic job to its owners
+	EmailReport *EmailReport `json:"email_report,omitempty"`
+	// Timeout is how long the job can run once started before it is aborted, e.g. `2h`. No limit if unset.
+	// Only enforced for the tekton-pipeline agent.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// PendingTimeout is how long the job can wait to start, e.g. for its pods to be scheduled, before it is
+	// aborted, e.g. `30m`. No limit if unset. Only enforced for the tekton-pipeline agent.
+	PendingTimeout *metav1.Duration `json:"pending_timeout,omitempty"`
+}
+
+// ArtifactsSpec holds the object storage configuration of a job
+type ArtifactsSpec struct {
+	// Bucket is the bucket URL artifacts are uploaded to, e.g. `gs://my-bucket/prefix` or `s3://my-bucket/prefix`.
+	// Artifacts are stored under a Prow style path within the bucket.
+	Bucket string `json:"bucket"`
+	// Paths are the glob patterns, relative to the workspace, of the artifacts to upload. They are passed to the
+	// build as the comma separated ARTIFACTS_PATHS environment variable read by the artifacts uploader.
+	Paths []string `json:"paths,omitempty"`
+}
+
+const (
+	// EmailOnFailure emails every failed run of the job
+	EmailOnFailure = "on_failure"
+	// EmailOnStateChange emails when the job starts failing and when it is fixed
+	EmailOnStateChange = "on_state_change"
+)
+
+// EmailReport configures the emails sent when a postsubmit or periodic job breaks, as their failures have no
+// pull request to be commented on
+type EmailReport struct {
+	// To are the email addresses of the owners of the job
+	To []string `json:"to"`
+	// When is "on_failure" to email every failed run, or "on_state_change" to only email when the job starts
+	// failing and when it is fixed. Defaults to "on_failure".
+	When string `json:"when,omitempty"`
 }
 
 // SetDefaults initializes default values
@@ -94,6 +148,11 @@ func (b *Base) Validate(jobType PipelineKind, podNamespace string) error {
 	if b.MaxConcurrency < 0 {
 		return fmt.Errorf("max_concurrency: %d must be a non-negative number", b.MaxConcurrency)
 	}
+	if b.PriorityClassName != "" {
+		if errs := validation.IsDNS1123Subdomain(b.PriorityClassName); len(errs) > 0 {
+			return fmt.Errorf("priority_class_name: %q is not a valid PriorityClass name: %s", b.PriorityClassName, strings.Join(errs, ", "))
+		}
+	}
 	if err := b.ValidateAgent(podNamespace); err != nil {
 		return err
 	}
@@ -103,6 +162,49 @@ func (b *Base) Validate(jobType PipelineKind, podNamespace string) error {
 	if err := ValidateLabels(b.Labels); err != nil {
 		return err
 	}
+	if b.Artifacts != nil && !strings.HasPrefix(b.Artifacts.Bucket, "gs://") && !strings.HasPrefix(b.Artifacts.Bucket, "s3://") {
+		return fmt.Errorf("artifacts.bucket: %q must start with gs:// or s3://", b.Artifacts.Bucket)
+	}
+	if b.Artifacts != nil {
+		for _, path := range b.Artifacts.Paths {
+			if strings.Contains(path, ",") {
+				return fmt.Errorf("artifacts.paths: %q cannot contain a comma", path)
+			}
+		}
+	}
+	if b.PipelineRunOverrides != nil {
+		if err := b.PipelineRunOverrides.Validate(); err != nil {
+			return fmt.Errorf("pipeline_run_overrides: %v", err)
+		}
+	}
+	if b.PipelineRef != nil {
+		if b.Agent != TektonPipelineAgent {
+			return fmt.Errorf("pipeline_ref: only %s jobs can reference a pipeline (found agent %q)", TektonPipelineAgent, b.Agent)
+		}
+		if b.PipelineRunSpec != nil && (b.PipelineRunSpec.PipelineRef != nil || b.PipelineRunSpec.PipelineSpec != nil) {
+			return fmt.Errorf("pipeline_ref: the pipeline_run_spec must not have a pipelineRef or a pipelineSpec")
+		}
+		if err := b.PipelineRef.Validate(); err != nil {
+			return fmt.Errorf("pipeline_ref: %v", err)
+		}
+	}
+	if b.Timeout != nil && b.Timeout.Duration < 0 {
+		return fmt.Errorf("timeout: %s must not be negative", b.Timeout.Duration)
+	}
+	if b.PendingTimeout != nil && b.PendingTimeout.Duration < 0 {
+		return fmt.Errorf("pending_timeout: %s must not be negative", b.PendingTimeout.Duration)
+	}
+	if b.EmailReport != nil {
+		if jobType != PostsubmitJob && jobType != PeriodicJob {
+			return fmt.Errorf("email_report: only postsubmit and periodic jobs can be reported by email")
+		}
+		if len(b.EmailReport.To) == 0 {
+			return fmt.Errorf("email_report.to: at least one email address is required")
+		}
+		if b.EmailReport.When != "" && b.EmailReport.When != EmailOnFailure && b.EmailReport.When != EmailOnStateChange {
+			return fmt.Errorf("email_report.when: %q must be %s or %s", b.EmailReport.When, EmailOnFailure, EmailOnStateChange)
+		}
+	}
 	if b.Spec == nil || len(b.Spec.Containers) == 0 {
 		return nil // knative-build and jenkins jobs have no spec
 	}
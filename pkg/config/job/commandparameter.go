@@ -0,0 +1,56 @@
+package job
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var commandParameterNameRe = regexp.MustCompile(`^[A-Za-z_][-\w]*$`)
+
+// CommandParameter is a parameter which can be given to a presubmit or an ops job by the command triggering it,
+// e.g. `/test e2e --provider=gke`, and is passed to the pipeline run as a param of the same name
+type CommandParameter struct {
+	// Name of the parameter
+	Name string `json:"name"`
+	// Pattern is the regular expression the whole value must match.
+	// Defaults to any value.
+	Pattern string         `json:"pattern,omitempty"`
+	re      *regexp.Regexp // from Pattern
+}
+
+// SetRegexes compiles the regular expression of the parameter
+func (cp CommandParameter) SetRegexes() (CommandParameter, error) {
+	if cp.Pattern != "" {
+		re, err := regexp.Compile(`^(?:` + cp.Pattern + `)$`)
+		if err != nil {
+			return cp, fmt.Errorf("could not compile pattern of parameter %s: %v", cp.Name, err)
+		}
+		cp.re = re
+	}
+	return cp, nil
+}
+
+// Matches returns true if the given value is valid for the parameter
+func (cp CommandParameter) Matches(value string) bool {
+	if cp.Pattern == "" {
+		return true
+	}
+	re := cp.re
+	if re == nil {
+		var err error
+		if cp, err = cp.SetRegexes(); err != nil {
+			return false
+		}
+		re = cp.re
+	}
+	return re.MatchString(value)
+}
+
+// Validate validates the parameter
+func (cp CommandParameter) Validate() error {
+	if !commandParameterNameRe.MatchString(cp.Name) {
+		return fmt.Errorf("invalid parameter name %q, it must match %s", cp.Name, commandParameterNameRe.String())
+	}
+	_, err := cp.SetRegexes()
+	return err
+}
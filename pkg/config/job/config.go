@@ -21,7 +21,6 @@ import (
 
 	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
 	"gopkg.in/robfig/cron.v2"
-	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -30,16 +29,48 @@ type Config struct {
 	// Presets apply to all job types.
 	Presets []Preset `json:"presets,omitempty"`
 	// Full repo name (such as "kubernetes/kubernetes") -> list of jobs.
+	// The jobs of an org (such as "kubernetes") are inherited by all its repositories,
+	// a repository job of the same name only declares the settings it overrides.
 	Presubmits  map[string][]Presubmit  `json:"presubmits,omitempty"`
 	Postsubmits map[string][]Postsubmit `json:"postsubmits,omitempty"`
+	// Full repo name or org -> list of jobs triggered by commands on the issues and pull requests.
+	// The jobs of an org are inherited by all its repositories not declaring a job of the same name.
+	Ops map[string][]Ops `json:"ops,omitempty"`
 	// Periodics are not associated with any repo.
 	Periodics []Periodic `json:"periodics,omitempty"`
+
+	// lazy keeps the presubmits and postsubmits of the repositories serialized when loaded lazily
+	lazy *lazyJobs
 }
 
-func resolvePresets(name string, labels map[string]string, spec *v1.PodSpec, presets []Preset) error {
+func resolvePresets(base *Base, presets []Preset) error {
+	named := map[string]Preset{}
 	for _, preset := range presets {
-		if err := MergePreset(preset, labels, spec); err != nil {
-			return fmt.Errorf("job %s failed to merge presets: %v", name, err)
+		if preset.Name != "" {
+			named[preset.Name] = preset
+		}
+	}
+	referenced := sets.NewString()
+	for _, name := range base.Presets {
+		preset, ok := named[name]
+		if !ok {
+			return fmt.Errorf("job %s references unknown preset %s", base.Name, name)
+		}
+		if referenced.Has(name) {
+			continue
+		}
+		referenced.Insert(name)
+		if err := applyPreset(preset, base.Spec); err != nil {
+			return fmt.Errorf("job %s failed to merge preset %s: %v", base.Name, name, err)
+		}
+	}
+	for _, preset := range presets {
+		// named presets without labels are only applied to the jobs referencing them
+		if referenced.Has(preset.Name) || (preset.Name != "" && len(preset.Labels) == 0) {
+			continue
+		}
+		if err := MergePreset(preset, base.Labels, base.Spec); err != nil {
+			return fmt.Errorf("job %s failed to merge presets: %v", base.Name, err)
 		}
 	}
 
@@ -49,9 +80,16 @@ func resolvePresets(name string, labels map[string]string, spec *v1.PodSpec, pre
 // Merge merges one Config with another one
 func (c *Config) Merge(other Config) error {
 	c.Presets = append(c.Presets, other.Presets...)
-	// validate no duplicated preset key-value pairs
+	// validate no duplicated preset key-value pairs or names
 	validLabels := map[string]bool{}
+	names := sets.NewString()
 	for _, preset := range c.Presets {
+		if preset.Name != "" {
+			if names.Has(preset.Name) {
+				return fmt.Errorf("duplicated preset name : %s", preset.Name)
+			}
+			names.Insert(preset.Name)
+		}
 		for label, val := range preset.Labels {
 			pair := label + ":" + val
 			if _, ok := validLabels[pair]; ok {
@@ -73,6 +111,12 @@ func (c *Config) Merge(other Config) error {
 	for repo, jobs := range other.Postsubmits {
 		c.Postsubmits[repo] = append(c.Postsubmits[repo], jobs...)
 	}
+	if c.Ops == nil {
+		c.Ops = make(map[string][]Ops)
+	}
+	for repo, jobs := range other.Ops {
+		c.Ops[repo] = append(c.Ops[repo], jobs...)
+	}
 	return nil
 }
 
@@ -112,13 +156,16 @@ func (c *Config) Init(lh lighthouse.Config) error {
 		// 	// }
 		// }
 	}
+	if err := c.inheritOrgJobs(); err != nil {
+		return err
+	}
 	for _, ps := range c.Presubmits {
 		for i := range ps {
 			ps[i].SetDefaults(lh.PodNamespace)
 			if err := ps[i].SetRegexes(); err != nil {
 				return fmt.Errorf("could not set regex: %v", err)
 			}
-			if err := resolvePresets(ps[i].Name, ps[i].Labels, ps[i].Spec, c.Presets); err != nil {
+			if err := resolvePresets(&ps[i].Base, c.Presets); err != nil {
 				return err
 			}
 		}
@@ -129,17 +176,28 @@ func (c *Config) Init(lh lighthouse.Config) error {
 			if err := ps[i].SetRegexes(); err != nil {
 				return fmt.Errorf("could not set regex: %v", err)
 			}
-			if err := resolvePresets(ps[i].Name, ps[i].Labels, ps[i].Spec, c.Presets); err != nil {
+			if err := resolvePresets(&ps[i].Base, c.Presets); err != nil {
 				return err
 			}
 		}
 	}
 	for i := range c.Periodics {
 		c.Periodics[i].SetDefaults(lh.PodNamespace)
-		if err := resolvePresets(c.Periodics[i].Name, c.Periodics[i].Labels, c.Periodics[i].Spec, c.Presets); err != nil {
+		if err := resolvePresets(&c.Periodics[i].Base, c.Presets); err != nil {
 			return err
 		}
 	}
+	for _, jobs := range c.Ops {
+		for i := range jobs {
+			jobs[i].SetDefaults(lh.PodNamespace)
+			if err := jobs[i].SetRegexes(); err != nil {
+				return err
+			}
+			if err := resolvePresets(&jobs[i].Base, c.Presets); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -188,6 +246,22 @@ func (c *Config) Validate(lh lighthouse.Config) error {
 			if err := j.Base.Validate(PostsubmitJob, lh.PodNamespace); err != nil {
 				return fmt.Errorf("invalid postsubmit job %s: %v", j.Name, err)
 			}
+			if j.Agent == GitHubActionsAgent {
+				if j.GitHubActionsSpec == nil {
+					return fmt.Errorf("postsubmit job %s uses the %s agent but has no github_actions_spec", j.Name, GitHubActionsAgent)
+				}
+				if err := j.GitHubActionsSpec.Validate(); err != nil {
+					return fmt.Errorf("invalid postsubmit job %s: %v", j.Name, err)
+				}
+			}
+			if j.Agent == BuildkiteAgent {
+				if j.BuildkiteSpec == nil {
+					return fmt.Errorf("postsubmit job %s uses the %s agent but has no buildkite_spec", j.Name, BuildkiteAgent)
+				}
+				if err := j.BuildkiteSpec.Validate(); err != nil {
+					return fmt.Errorf("invalid postsubmit job %s: %v", j.Name, err)
+				}
+			}
 		}
 	}
 	// validate no duplicated periodics
@@ -213,6 +287,25 @@ func (c *Config) Validate(lh lighthouse.Config) error {
 			return fmt.Errorf("cron cannot be empty in periodic %s", p.Name)
 		}
 	}
+	// Validate ops jobs.
+	// Checking that no job or command is declared twice for the same org / repo.
+	for repo, jobs := range c.Ops {
+		names := sets.NewString()
+		commands := sets.NewString()
+		for _, j := range jobs {
+			if names.Has(j.Name) {
+				return fmt.Errorf("duplicated ops job: %s", j.Name)
+			}
+			names.Insert(j.Name)
+			if commands.Has(j.Command) {
+				return fmt.Errorf("ops job %s of %s uses the command %s of another job", j.Name, repo, j.Command)
+			}
+			commands.Insert(j.Command)
+			if err := j.Validate(lh.PodNamespace); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -245,17 +338,14 @@ func (c *Config) DecorationRequested() bool {
 func (c *Config) AllPresubmits(repos []string) []Presubmit {
 	var res []Presubmit
 
-	for repo, v := range c.Presubmits {
-		if len(repos) == 0 {
+	if len(repos) == 0 {
+		for _, v := range c.Presubmits {
 			res = append(res, v...)
-		} else {
-			for _, r := range repos {
-				if r == repo {
-					res = append(res, v...)
-					break
-				}
-			}
 		}
+		return append(res, c.lazyPresubmits(c.Presubmits)...)
+	}
+	for _, r := range repos {
+		res = append(res, c.RepoPresubmits(r)...)
 	}
 
 	return res
@@ -266,22 +356,42 @@ func (c *Config) AllPresubmits(repos []string) []Presubmit {
 func (c *Config) AllPostsubmits(repos []string) []Postsubmit {
 	var res []Postsubmit
 
-	for repo, v := range c.Postsubmits {
-		if len(repos) == 0 {
+	if len(repos) == 0 {
+		for _, v := range c.Postsubmits {
 			res = append(res, v...)
-		} else {
-			for _, r := range repos {
-				if r == repo {
-					res = append(res, v...)
-					break
-				}
-			}
 		}
+		return append(res, c.lazyPostsubmits(c.Postsubmits)...)
+	}
+	for _, r := range repos {
+		res = append(res, c.RepoPostsubmits(r)...)
 	}
 
 	return res
 }
 
+// RepoOps returns the ops jobs of the repository, including the ones of its org the repository does not declare a
+// job of the same name for.
+func (c *Config) RepoOps(fullName string) []Ops {
+	jobs := append([]Ops(nil), c.Ops[fullName]...)
+	if org := orgOf(fullName); org != fullName {
+		for _, j := range c.Ops[org] {
+			if !hasOps(c.Ops[fullName], j.Name) {
+				jobs = append(jobs, j)
+			}
+		}
+	}
+	return jobs
+}
+
+func hasOps(jobs []Ops, name string) bool {
+	for i := range jobs {
+		if jobs[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // AllPeriodics returns all prow periodic jobs.
 func (c *Config) AllPeriodics() []Periodic {
 	return c.Periodics
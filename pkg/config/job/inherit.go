@@ -0,0 +1,193 @@
+package job
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jobSections are the sections of a job configuration document whose jobs can be declared for an org
+var jobSections = []string{"presubmits", "postsubmits"}
+
+// MergeOrgDefaults deep merges, within the given JSON job configuration document, each job of a repository
+// with the job of the same name of its org so that a repository only has to declare the settings it overrides.
+// Maps are merged recursively while any other value of the repository job replaces the one of the org job.
+//
+// Jobs of an org which are not overridden are inherited by all the repositories of the org when the
+// configuration is initialized, a repository job with `disable: true` removes the inherited job instead.
+func MergeOrgDefaults(data []byte) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := unmarshalUsingNumber(data, &doc); err != nil || doc == nil {
+		// not a document with sections, let the caller report the decoding error
+		return data, nil
+	}
+	changed := false
+	for _, section := range jobSections {
+		raw, ok := doc[section]
+		if !ok {
+			continue
+		}
+		var jobs map[string][]map[string]interface{}
+		if err := unmarshalUsingNumber(raw, &jobs); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", section, err)
+		}
+		if !mergeOrgJobs(jobs) {
+			continue
+		}
+		merged, err := json.Marshal(jobs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %v", section, err)
+		}
+		doc[section] = merged
+		changed = true
+	}
+	if !changed {
+		return data, nil
+	}
+	return json.Marshal(doc)
+}
+
+// mergeOrgJobs merges the repository jobs with the org jobs of the same name, returning true if any was merged
+func mergeOrgJobs(jobs map[string][]map[string]interface{}) bool {
+	merged := false
+	for repo, repoJobs := range jobs {
+		orgJobs := jobs[orgOf(repo)]
+		if !strings.Contains(repo, "/") || len(orgJobs) == 0 {
+			continue
+		}
+		for i, repoJob := range repoJobs {
+			for _, orgJob := range orgJobs {
+				if orgJob["name"] != nil && orgJob["name"] == repoJob["name"] {
+					repoJobs[i] = deepMerge(orgJob, repoJob)
+					merged = true
+					break
+				}
+			}
+		}
+	}
+	return merged
+}
+
+// deepMerge returns a copy of base with the values of override, merging the nested maps
+func deepMerge(base, override map[string]interface{}) map[string]interface{} {
+	answer := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		answer[k] = v
+	}
+	for k, v := range override {
+		overrideMap, ok := v.(map[string]interface{})
+		baseMap, baseOk := answer[k].(map[string]interface{})
+		if ok && baseOk {
+			answer[k] = deepMerge(baseMap, overrideMap)
+			continue
+		}
+		answer[k] = v
+	}
+	return answer
+}
+
+func unmarshalUsingNumber(data []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	// keep large integers such as timeouts in nanoseconds intact
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+func orgOf(fullName string) string {
+	return strings.Split(fullName, "/")[0]
+}
+
+// inheritOrgJobs adds the jobs of each org to its repositories which do not declare a job of the same name,
+// then removes the disabled jobs.
+func (c *Config) inheritOrgJobs() error {
+	for repo, jobs := range c.Presubmits {
+		if strings.Contains(repo, "/") {
+			for _, orgJob := range c.Presubmits[orgOf(repo)] {
+				if !hasPresubmit(jobs, orgJob.Name) {
+					var copied Presubmit
+					if err := copyJob(&orgJob, &copied); err != nil {
+						return err
+					}
+					jobs = append(jobs, copied)
+				}
+			}
+		}
+		var enabled []Presubmit
+		for _, j := range jobs {
+			if !j.Disable {
+				enabled = append(enabled, j)
+			}
+		}
+		c.Presubmits[repo] = enabled
+	}
+	for repo, jobs := range c.Postsubmits {
+		if strings.Contains(repo, "/") {
+			for _, orgJob := range c.Postsubmits[orgOf(repo)] {
+				if !hasPostsubmit(jobs, orgJob.Name) {
+					var copied Postsubmit
+					if err := copyJob(&orgJob, &copied); err != nil {
+						return err
+					}
+					jobs = append(jobs, copied)
+				}
+			}
+		}
+		var enabled []Postsubmit
+		for _, j := range jobs {
+			if !j.Disable {
+				enabled = append(enabled, j)
+			}
+		}
+		c.Postsubmits[repo] = enabled
+	}
+	return nil
+}
+
+// copyJob deep copies the job so that the inherited jobs do not share their pod specs with the org job
+func copyJob(from, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return fmt.Errorf("failed to copy job: %v", err)
+	}
+	if err := json.Unmarshal(data, to); err != nil {
+		return fmt.Errorf("failed to copy job: %v", err)
+	}
+	return nil
+}
+
+func hasPresubmit(jobs []Presubmit, name string) bool {
+	for i := range jobs {
+		if jobs[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPostsubmit(jobs []Postsubmit, name string) bool {
+	for i := range jobs {
+		if jobs[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RepoPresubmits returns the presubmits of the repository, the ones of its org if the repository has none declared
+func (c *Config) RepoPresubmits(fullName string) []Presubmit {
+	if jobs, ok := c.repoPresubmits(fullName); ok {
+		return jobs
+	}
+	jobs, _ := c.repoPresubmits(orgOf(fullName))
+	return jobs
+}
+
+// RepoPostsubmits returns the postsubmits of the repository, the ones of its org if the repository has none declared
+func (c *Config) RepoPostsubmits(fullName string) []Postsubmit {
+	if jobs, ok := c.repoPostsubmits(fullName); ok {
+		return jobs
+	}
+	jobs, _ := c.repoPostsubmits(orgOf(fullName))
+	return jobs
+}
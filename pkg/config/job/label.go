@@ -46,6 +46,14 @@ const (
 	// the k8s garbage collector would immediately delete these
 	// resources
 	CreatedByLighthouseLabel = "created-by-lighthouse"
+	// TreeHashLabel is added on the presubmits which cache their results and
+	// carries the hash of the tree of the head commit of the pull request, so
+	// that the jobs which already succeeded for the same tree can be found.
+	TreeHashLabel = "lighthouse.jenkins-x.io/treeHash"
+	// JobSpecHashLabel is added along with TreeHashLabel and carries the hash
+	// of the configuration of the job, so that the results cached before the
+	// job changed, e.g. its image, command or environment, are not reused.
+	JobSpecHashLabel = "lighthouse.jenkins-x.io/jobSpecHash"
 )
 
 // Labels returns a string slice with label consts from kube.
@@ -0,0 +1,237 @@
+package job
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// lazyJobs keeps the initialized presubmits and postsubmits of each repository serialized, parsing the ones of a
+// repository when they are looked up and caching the ones of the most recently looked up repositories
+type lazyJobs struct {
+	presubmits  map[string][]byte
+	postsubmits map[string][]byte
+
+	lock    sync.Mutex
+	maxSize int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lazyJobsEntry struct {
+	key         string
+	presubmits  []Presubmit
+	postsubmits []Postsubmit
+}
+
+// LoadLazily unmarshals the JSON job configuration document, the presubmits and postsubmits of each repository
+// being initialized and validated then kept serialized instead of parsed. The jobs of a repository are parsed again
+// when they are looked up, the ones of at most cacheSize repositories being cached.
+//
+// The presets and periodics are unmarshalled as usual, the config still needs to be initialized and validated.
+func (c *Config) LoadLazily(data []byte, lh lighthouse.Config, cacheSize int) error {
+	doc := struct {
+		*Config
+		Presubmits  map[string]json.RawMessage `json:"presubmits,omitempty"`
+		Postsubmits map[string]json.RawMessage `json:"postsubmits,omitempty"`
+	}{Config: c}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	lazy := &lazyJobs{
+		presubmits:  map[string][]byte{},
+		postsubmits: map[string][]byte{},
+		maxSize:     cacheSize,
+		order:       list.New(),
+		entries:     map[string]*list.Element{},
+	}
+	repos := sets.NewString()
+	for repo := range doc.Presubmits {
+		repos.Insert(repo)
+	}
+	for repo := range doc.Postsubmits {
+		repos.Insert(repo)
+	}
+	for _, repo := range repos.List() {
+		// the jobs of the repository are initialized along with the jobs of its org they inherit
+		jobs := &Config{
+			Presets:     c.Presets,
+			Presubmits:  map[string][]Presubmit{},
+			Postsubmits: map[string][]Postsubmit{},
+		}
+		for _, name := range sets.NewString(repo, orgOf(repo)).List() {
+			if raw, ok := doc.Presubmits[name]; ok {
+				var ps []Presubmit
+				if err := json.Unmarshal(raw, &ps); err != nil {
+					return fmt.Errorf("invalid presubmits of %s: %v", name, err)
+				}
+				jobs.Presubmits[name] = ps
+			}
+			if raw, ok := doc.Postsubmits[name]; ok {
+				var ps []Postsubmit
+				if err := json.Unmarshal(raw, &ps); err != nil {
+					return fmt.Errorf("invalid postsubmits of %s: %v", name, err)
+				}
+				jobs.Postsubmits[name] = ps
+			}
+		}
+		if err := jobs.Init(lh); err != nil {
+			return err
+		}
+		if err := jobs.Validate(lh); err != nil {
+			return err
+		}
+		if _, ok := doc.Presubmits[repo]; ok {
+			b, err := json.Marshal(jobs.Presubmits[repo])
+			if err != nil {
+				return fmt.Errorf("failed to marshal the presubmits of %s: %v", repo, err)
+			}
+			lazy.presubmits[repo] = b
+		}
+		if _, ok := doc.Postsubmits[repo]; ok {
+			b, err := json.Marshal(jobs.Postsubmits[repo])
+			if err != nil {
+				return fmt.Errorf("failed to marshal the postsubmits of %s: %v", repo, err)
+			}
+			lazy.postsubmits[repo] = b
+		}
+	}
+	c.Presubmits = nil
+	c.Postsubmits = nil
+	c.lazy = lazy
+	return nil
+}
+
+// repoPresubmits returns the presubmits declared for the repository or org, and whether any are declared
+func (c *Config) repoPresubmits(name string) ([]Presubmit, bool) {
+	if jobs, ok := c.Presubmits[name]; ok {
+		return jobs, true
+	}
+	if c.lazy == nil {
+		return nil, false
+	}
+	data, ok := c.lazy.presubmits[name]
+	if !ok {
+		return nil, false
+	}
+	key := "presubmits/" + name
+	if e, ok := c.lazy.get(key); ok {
+		return e.presubmits, true
+	}
+	jobs := decodePresubmits(name, data)
+	c.lazy.put(&lazyJobsEntry{key: key, presubmits: jobs})
+	return jobs, true
+}
+
+// repoPostsubmits returns the postsubmits declared for the repository or org, and whether any are declared
+func (c *Config) repoPostsubmits(name string) ([]Postsubmit, bool) {
+	if jobs, ok := c.Postsubmits[name]; ok {
+		return jobs, true
+	}
+	if c.lazy == nil {
+		return nil, false
+	}
+	data, ok := c.lazy.postsubmits[name]
+	if !ok {
+		return nil, false
+	}
+	key := "postsubmits/" + name
+	if e, ok := c.lazy.get(key); ok {
+		return e.postsubmits, true
+	}
+	jobs := decodePostsubmits(name, data)
+	c.lazy.put(&lazyJobsEntry{key: key, postsubmits: jobs})
+	return jobs, true
+}
+
+// lazyPresubmits returns the presubmits kept serialized of the repositories and orgs not in the given map, without
+// caching them
+func (c *Config) lazyPresubmits(skip map[string][]Presubmit) []Presubmit {
+	if c.lazy == nil {
+		return nil
+	}
+	var answer []Presubmit
+	for name, data := range c.lazy.presubmits {
+		if _, ok := skip[name]; !ok {
+			answer = append(answer, decodePresubmits(name, data)...)
+		}
+	}
+	return answer
+}
+
+// lazyPostsubmits returns the postsubmits kept serialized of the repositories and orgs not in the given map, without
+// caching them
+func (c *Config) lazyPostsubmits(skip map[string][]Postsubmit) []Postsubmit {
+	if c.lazy == nil {
+		return nil
+	}
+	var answer []Postsubmit
+	for name, data := range c.lazy.postsubmits {
+		if _, ok := skip[name]; !ok {
+			answer = append(answer, decodePostsubmits(name, data)...)
+		}
+	}
+	return answer
+}
+
+// decodePresubmits parses the presubmits serialized once validated, so that they are not expected to be invalid
+func decodePresubmits(name string, data []byte) []Presubmit {
+	var jobs []Presubmit
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		logrus.WithError(err).WithField("repo", name).Error("failed to parse the presubmits kept serialized")
+		return nil
+	}
+	for i := range jobs {
+		if err := jobs[i].SetRegexes(); err != nil {
+			logrus.WithError(err).WithField("repo", name).Error("failed to set the regexes of the presubmits kept serialized")
+		}
+	}
+	return jobs
+}
+
+// decodePostsubmits parses the postsubmits serialized once validated, so that they are not expected to be invalid
+func decodePostsubmits(name string, data []byte) []Postsubmit {
+	var jobs []Postsubmit
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		logrus.WithError(err).WithField("repo", name).Error("failed to parse the postsubmits kept serialized")
+		return nil
+	}
+	for i := range jobs {
+		if err := jobs[i].SetRegexes(); err != nil {
+			logrus.WithError(err).WithField("repo", name).Error("failed to set the regexes of the postsubmits kept serialized")
+		}
+	}
+	return jobs
+}
+
+func (l *lazyJobs) get(key string) (*lazyJobsEntry, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	e, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(e)
+	return e.Value.(*lazyJobsEntry), true
+}
+
+func (l *lazyJobs) put(entry *lazyJobsEntry) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if e, ok := l.entries[entry.key]; ok {
+		e.Value = entry
+		l.order.MoveToFront(e)
+		return
+	}
+	l.entries[entry.key] = l.order.PushFront(entry)
+	for l.order.Len() > l.maxSize {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lazyJobsEntry).key)
+	}
+}
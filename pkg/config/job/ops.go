@@ -0,0 +1,99 @@
+package job
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var opsCommandRe = regexp.MustCompile(`^[\w-]+$`)
+
+// Ops runs repository operations, e.g. release cuts or environment promotions, when a user with write access to
+// the repository comments the command of the job on an issue or pull request, e.g. `/release --version=1.2.0`. The
+// job runs on the head of a branch rather than on the commits of a pull request.
+type Ops struct {
+	Base
+	Reporter
+	// Command is the name of the command triggering the job, e.g. `release` for `/release`.
+	// Defaults to the job name.
+	Command string `json:"command,omitempty"`
+	// Branch is the branch the job runs on.
+	// Defaults to the default branch of the repository.
+	Branch            string             `json:"branch,omitempty"`
+	JenkinsSpec       *JenkinsSpec       `json:"jenkins_spec,omitempty"`
+	GitHubActionsSpec *GitHubActionsSpec `json:"github_actions_spec,omitempty"`
+	DroneSpec         *DroneSpec         `json:"drone_spec,omitempty"`
+	BuildkiteSpec     *BuildkiteSpec     `json:"buildkite_spec,omitempty"`
+	// CommandParameters are the parameters which can be given to the job by its command,
+	// e.g. `/promote --environment=production`. Any other parameter is rejected.
+	CommandParameters []CommandParameter `json:"command_parameters,omitempty"`
+}
+
+// SetDefaults initializes default values
+func (o *Ops) SetDefaults(namespace string) {
+	o.Base.SetDefaults(namespace)
+	if o.Command == "" {
+		o.Command = o.Name
+	}
+	if o.Context == "" {
+		o.Context = o.Name
+	}
+}
+
+// SetRegexes compiles the regular expressions of the command parameters
+func (o *Ops) SetRegexes() error {
+	for i := range o.CommandParameters {
+		cp, err := o.CommandParameters[i].SetRegexes()
+		if err != nil {
+			return fmt.Errorf("could not set command parameter regexes for %s: %v", o.Name, err)
+		}
+		o.CommandParameters[i] = cp
+	}
+	return nil
+}
+
+// CommandParameter returns the command parameter of the given name, or nil if the job doesn't accept it.
+func (o Ops) CommandParameter(name string) *CommandParameter {
+	for i := range o.CommandParameters {
+		if o.CommandParameters[i].Name == name {
+			return &o.CommandParameters[i]
+		}
+	}
+	return nil
+}
+
+// Validate validates the ops job
+func (o *Ops) Validate(podNamespace string) error {
+	if err := o.Base.Validate(OpsJob, podNamespace); err != nil {
+		return fmt.Errorf("invalid ops job %s: %v", o.Name, err)
+	}
+	if !opsCommandRe.MatchString(o.Command) {
+		return fmt.Errorf("ops job %s has an invalid command %q, it must match %s", o.Name, o.Command, opsCommandRe.String())
+	}
+	names := map[string]bool{}
+	for _, cp := range o.CommandParameters {
+		if err := cp.Validate(); err != nil {
+			return fmt.Errorf("invalid ops job %s: %v", o.Name, err)
+		}
+		if names[cp.Name] {
+			return fmt.Errorf("ops job %s declares the command parameter %s more than once", o.Name, cp.Name)
+		}
+		names[cp.Name] = true
+	}
+	if o.Agent == GitHubActionsAgent {
+		if o.GitHubActionsSpec == nil {
+			return fmt.Errorf("ops job %s uses the %s agent but has no github_actions_spec", o.Name, GitHubActionsAgent)
+		}
+		if err := o.GitHubActionsSpec.Validate(); err != nil {
+			return fmt.Errorf("invalid ops job %s: %v", o.Name, err)
+		}
+	}
+	if o.Agent == BuildkiteAgent {
+		if o.BuildkiteSpec == nil {
+			return fmt.Errorf("ops job %s uses the %s agent but has no buildkite_spec", o.Name, BuildkiteAgent)
+		}
+		if err := o.BuildkiteSpec.Validate(); err != nil {
+			return fmt.Errorf("invalid ops job %s: %v", o.Name, err)
+		}
+	}
+	return nil
+}
@@ -37,4 +37,6 @@ const (
 	PeriodicJob PipelineKind = "periodic"
 	// BatchJob tests multiple unmerged PRs at the same time.
 	BatchJob PipelineKind = "batch"
+	// OpsJob means it runs on a branch when its command is commented, e.g. to cut a release.
+	OpsJob PipelineKind = "ops"
 )
@@ -0,0 +1,61 @@
+package job
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// GitResolver resolves the pipeline from a file of a git repository
+	GitResolver = "git"
+	// BundlesResolver resolves the pipeline from a Tekton bundle, i.e. an OCI image
+	BundlesResolver = "bundles"
+	// ClusterResolver resolves the pipeline from a Pipeline of a namespace of the cluster
+	ClusterResolver = "cluster"
+)
+
+// resolverParams are the params each resolver requires, any of the params of an entry being enough
+var resolverParams = map[string][][]string{
+	GitResolver:     {{"url", "repo"}, {"pathInRepo"}},
+	BundlesResolver: {{"bundle"}, {"name"}},
+	ClusterResolver: {{"name"}},
+}
+
+// PipelineRef references the pipeline of a tekton-pipeline job resolved by a Tekton remote resolver, rather than
+// embedded in its pipeline_run_spec. The PipelineRuns of such jobs are created with the tekton.dev/v1 API.
+type PipelineRef struct {
+	// Resolver is the name of the Tekton resolver, one of git, bundles or cluster
+	Resolver string `json:"resolver"`
+	// Params are the params of the resolver, e.g. the url, revision and pathInRepo of the git resolver.
+	// The values are Go templates given the Refs and the Parameters of the job, like pipeline_run_params.
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Validate validates the pipeline reference
+func (r *PipelineRef) Validate() error {
+	required, ok := resolverParams[r.Resolver]
+	if !ok {
+		return fmt.Errorf("resolver: %q must be one of %s", r.Resolver, strings.Join(sets.StringKeySet(resolverParams).List(), ", "))
+	}
+	for _, names := range required {
+		found := false
+		for _, name := range names {
+			if r.Params[name] != "" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("params: the %s resolver requires the %s param", r.Resolver, strings.Join(names, " or "))
+		}
+	}
+	for name, value := range r.Params {
+		if _, err := template.New(name).Parse(value); err != nil {
+			return fmt.Errorf("params: %s: %v", name, err)
+		}
+	}
+	return nil
+}
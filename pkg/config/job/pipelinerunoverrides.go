@@ -0,0 +1,42 @@
+package job
+
+import (
+	"fmt"
+
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PipelineRunOverrides are merged into the PipelineRun of a tekton-pipeline job, whether it comes from the
+// pipeline_run_spec or the source of the job, so that where and how it runs can be controlled per job
+type PipelineRunOverrides struct {
+	// ServiceAccountName replaces the service account the PipelineRun runs as
+	ServiceAccountName string `json:"service_account_name,omitempty"`
+	// Timeout replaces the timeout of the PipelineRun
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// NodeSelector is merged into the node selector of the pod template
+	NodeSelector map[string]string `json:"node_selector,omitempty"`
+	// Tolerations are added to the tolerations of the pod template
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// Workspaces replace the workspace bindings of the same name, or are added to the PipelineRun
+	Workspaces []tektonv1beta1.WorkspaceBinding `json:"workspaces,omitempty"`
+}
+
+// Validate validates the overrides
+func (o *PipelineRunOverrides) Validate() error {
+	if o.Timeout != nil && o.Timeout.Duration < 0 {
+		return fmt.Errorf("timeout: %s must not be negative", o.Timeout.Duration)
+	}
+	names := map[string]bool{}
+	for _, w := range o.Workspaces {
+		if w.Name == "" {
+			return fmt.Errorf("workspaces: the name of a workspace must be set")
+		}
+		if names[w.Name] {
+			return fmt.Errorf("workspaces: workspace %s is declared more than once", w.Name)
+		}
+		names[w.Name] = true
+	}
+	return nil
+}
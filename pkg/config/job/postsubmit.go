@@ -25,7 +25,12 @@ type Postsubmit struct {
 	Brancher
 	// TODO(krzyzacy): Move existing `Report` into `Skip_Report` once this is deployed
 	Reporter
-	JenkinsSpec *JenkinsSpec `json:"jenkins_spec,omitempty"`
+	JenkinsSpec       *JenkinsSpec       `json:"jenkins_spec,omitempty"`
+	GitHubActionsSpec *GitHubActionsSpec `json:"github_actions_spec,omitempty"`
+	DroneSpec         *DroneSpec         `json:"drone_spec,omitempty"`
+	BuildkiteSpec     *BuildkiteSpec     `json:"buildkite_spec,omitempty"`
+	// Disable removes the job of the same name inherited from the org of the repository.
+	Disable bool `json:"disable,omitempty"`
 }
 
 // JenkinsSpec holds optional Jenkins job config
@@ -33,6 +38,67 @@ type JenkinsSpec struct {
 	// Job is managed by the GH branch source plugin
 	// and requires a specific path
 	BranchSourceJob bool `json:"branch_source_job,omitempty"`
+	// Folder is the slash separated path of the folder(s)
+	// containing the job, e.g. `team/backend`
+	Folder string `json:"folder,omitempty"`
+	// MultiBranch indicates the job is a multibranch pipeline,
+	// the branch or PR job inside it will be triggered
+	MultiBranch bool `json:"multi_branch,omitempty"`
+}
+
+// GitHubActionsSpec holds optional GitHub Actions job config
+type GitHubActionsSpec struct {
+	// Workflow is the workflow file name (e.g. `ci.yaml`) or ID to dispatch.
+	// Required when using the workflow_dispatch event.
+	Workflow string `json:"workflow,omitempty"`
+	// Event is the dispatch event to use, either `workflow_dispatch` (the default)
+	// or `repository_dispatch`
+	Event string `json:"event,omitempty"`
+	// EventType is the event_type sent with a repository_dispatch event.
+	// Defaults to the job name.
+	EventType string `json:"event_type,omitempty"`
+	// Inputs are additional static inputs passed to the workflow
+	Inputs map[string]string `json:"inputs,omitempty"`
+}
+
+// Validate validates the GitHub Actions spec
+func (s *GitHubActionsSpec) Validate() error {
+	switch s.Event {
+	case "", WorkflowDispatchEvent:
+		if s.Workflow == "" {
+			return fmt.Errorf("github_actions_spec.workflow is required for the %s event", WorkflowDispatchEvent)
+		}
+	case RepositoryDispatchEvent:
+	default:
+		return fmt.Errorf("github_actions_spec.event must be one of %s or %s (found %q)", WorkflowDispatchEvent, RepositoryDispatchEvent, s.Event)
+	}
+	return nil
+}
+
+// DroneSpec holds optional Drone job config
+type DroneSpec struct {
+	// Params are additional static parameters passed to the build,
+	// which Drone exposes to the pipeline as environment variables
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// BuildkiteSpec holds optional Buildkite job config
+type BuildkiteSpec struct {
+	// Organization is the slug of the Buildkite organization of the pipeline.
+	// Defaults to the organization the Buildkite controller is configured with.
+	Organization string `json:"organization,omitempty"`
+	// Pipeline is the slug of the Buildkite pipeline to build
+	Pipeline string `json:"pipeline"`
+	// Env are additional static environment variables passed to the build
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// Validate validates the Buildkite spec
+func (s *BuildkiteSpec) Validate() error {
+	if s.Pipeline == "" {
+		return fmt.Errorf("buildkite_spec.pipeline is required")
+	}
+	return nil
 }
 
 // SetDefaults initializes default values
@@ -25,6 +25,9 @@ import (
 // Preset is intended to match the k8s' PodPreset feature, and may be removed
 // if that feature goes beta.
 type Preset struct {
+	// Name of the preset, jobs can reference a named preset in their `presets` to have it applied
+	// regardless of their labels. A named preset without labels is only applied to the jobs referencing it.
+	Name         string            `json:"name,omitempty"`
 	Labels       map[string]string `json:"labels"`
 	Env          []v1.EnvVar       `json:"env"`
 	Volumes      []v1.Volume       `json:"volumes"`
@@ -41,6 +44,14 @@ func MergePreset(preset Preset, labels map[string]string, pod *v1.PodSpec) error
 			return nil
 		}
 	}
+	return applyPreset(preset, pod)
+}
+
+// applyPreset merges a preset with a pod spec
+func applyPreset(preset Preset, pod *v1.PodSpec) error {
+	if pod == nil {
+		return nil
+	}
 	for _, e1 := range preset.Env {
 		for i := range pod.Containers {
 			for _, e2 := range pod.Containers[i].Env {
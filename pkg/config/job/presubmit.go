@@ -41,8 +41,24 @@ type Presubmit struct {
 	// The RerunCommand to give users. Must match Trigger.
 	// Trigger must also be specified if this field is specified.
 	// (Default: `/test <job name>`)
-	RerunCommand string       `json:"rerun_command,omitempty"`
-	JenkinsSpec  *JenkinsSpec `json:"jenkins_spec,omitempty"`
+	RerunCommand      string             `json:"rerun_command,omitempty"`
+	JenkinsSpec       *JenkinsSpec       `json:"jenkins_spec,omitempty"`
+	GitHubActionsSpec *GitHubActionsSpec `json:"github_actions_spec,omitempty"`
+	DroneSpec         *DroneSpec         `json:"drone_spec,omitempty"`
+	BuildkiteSpec     *BuildkiteSpec     `json:"buildkite_spec,omitempty"`
+	// CommandParameters are the parameters which can be given to the job when triggering
+	// it with a command, e.g. `/test e2e --provider=gke`. Any other parameter is rejected.
+	CommandParameters []CommandParameter `json:"command_parameters,omitempty"`
+	// Disable removes the job of the same name inherited from the org of the repository.
+	Disable bool `json:"disable,omitempty"`
+	// PreviousContexts are the contexts the job reported before its context was renamed. Keeper considers them
+	// optional, and `lighthouse contexts migrate` moves their statuses on the open pull requests to the new context.
+	PreviousContexts []string `json:"previous_contexts,omitempty"`
+	// CacheResults reports the job as successful without running it when it already succeeded for the same tree
+	// of the head commit of the pull request and the same base commit, e.g. after a rebase with no content change
+	// or for the same commit in another pull request. The results are only reused while the configuration of the job
+	// is unchanged, and the job is always run when it is requested by a comment, e.g. `/test` or `/retest`.
+	CacheResults bool `json:"cache_results,omitempty"`
 
 	// We'll set these when we load it.
 	//re *regexp.Regexp // from Trigger.
@@ -83,6 +99,13 @@ func (p *Presubmit) SetRegexes() error {
 		return fmt.Errorf("could not set change regexes for %s: %v", p.Name, err)
 	}
 	p.RegexpChangeMatcher = c
+	for i := range p.CommandParameters {
+		cp, err := p.CommandParameters[i].SetRegexes()
+		if err != nil {
+			return fmt.Errorf("could not set command parameter regexes for %s: %v", p.Name, err)
+		}
+		p.CommandParameters[i] = cp
+	}
 	return nil
 }
 
@@ -91,6 +114,9 @@ func (p *Presubmit) ClearCompiledRegexes() {
 	p.Brancher.re = nil
 	p.Brancher.reSkip = nil
 	p.RegexpChangeMatcher.reChanges = nil
+	for i := range p.CommandParameters {
+		p.CommandParameters[i].re = nil
+	}
 }
 
 // CouldRun determines if the presubmit could run against a specific
@@ -130,6 +156,16 @@ func (p Presubmit) NeedsExplicitTrigger() bool {
 	return !p.AlwaysRun && !p.RegexpChangeMatcher.CouldRun()
 }
 
+// CommandParameter returns the command parameter of the given name, or nil if the job doesn't accept it.
+func (p Presubmit) CommandParameter(name string) *CommandParameter {
+	for i := range p.CommandParameters {
+		if p.CommandParameters[i].Name == name {
+			return &p.CommandParameters[i]
+		}
+	}
+	return nil
+}
+
 // TriggerMatches returns true if the comment body should trigger this presubmit.
 //
 // This is usually a /test foo string.
@@ -161,8 +197,45 @@ func (p *Presubmit) Validate(podNamespace string) error {
 	if p.AlwaysRun && p.RunIfChanged != "" {
 		return fmt.Errorf("job %s is set to always run but also declares run_if_changed targets, which are mutually exclusive", p.Name)
 	}
+	if p.AlwaysRun && p.SkipIfOnlyChanged != "" {
+		return fmt.Errorf("job %s is set to always run but also declares skip_if_only_changed targets, which are mutually exclusive", p.Name)
+	}
+	if p.RunIfChanged != "" && p.SkipIfOnlyChanged != "" {
+		return fmt.Errorf("job %s declares run_if_changed and skip_if_only_changed targets, which are mutually exclusive", p.Name)
+	}
+	names := map[string]bool{}
+	for _, cp := range p.CommandParameters {
+		if err := cp.Validate(); err != nil {
+			return fmt.Errorf("invalid presubmit job %s: %v", p.Name, err)
+		}
+		if names[cp.Name] {
+			return fmt.Errorf("job %s declares the command parameter %s more than once", p.Name, cp.Name)
+		}
+		names[cp.Name] = true
+	}
 	if !p.SkipReport && p.Context == "" {
 		return fmt.Errorf("job %s is set to report but has no context configured", p.Name)
 	}
+	for _, c := range p.PreviousContexts {
+		if c == "" || c == p.Context {
+			return fmt.Errorf("job %s has an invalid previous context %q", p.Name, c)
+		}
+	}
+	if p.Agent == GitHubActionsAgent {
+		if p.GitHubActionsSpec == nil {
+			return fmt.Errorf("job %s uses the %s agent but has no github_actions_spec", p.Name, GitHubActionsAgent)
+		}
+		if err := p.GitHubActionsSpec.Validate(); err != nil {
+			return fmt.Errorf("invalid presubmit job %s: %v", p.Name, err)
+		}
+	}
+	if p.Agent == BuildkiteAgent {
+		if p.BuildkiteSpec == nil {
+			return fmt.Errorf("job %s uses the %s agent but has no buildkite_spec", p.Name, BuildkiteAgent)
+		}
+		if err := p.BuildkiteSpec.Validate(); err != nil {
+			return fmt.Errorf("invalid presubmit job %s: %v", p.Name, err)
+		}
+	}
 	return nil
 }
@@ -25,13 +25,17 @@ import (
 type RegexpChangeMatcher struct {
 	// RunIfChanged defines a regex used to select which subset of file changes should trigger this job.
 	// If any file in the changeset matches this regex, the job will be triggered
-	RunIfChanged string         `json:"run_if_changed,omitempty"`
-	reChanges    *regexp.Regexp // from RunIfChanged
+	RunIfChanged string `json:"run_if_changed,omitempty"`
+	// SkipIfOnlyChanged defines a regex used to select which subset of file changes should not trigger this job.
+	// If all files in the changeset match this regex, the job will not be triggered.
+	// It is mutually exclusive with RunIfChanged.
+	SkipIfOnlyChanged string         `json:"skip_if_only_changed,omitempty"`
+	reChanges         *regexp.Regexp // from RunIfChanged or SkipIfOnlyChanged
 }
 
 // CouldRun determines if its possible for a set of changes to trigger this condition
 func (cm RegexpChangeMatcher) CouldRun() bool {
-	return cm.RunIfChanged != ""
+	return cm.RunIfChanged != "" || cm.SkipIfOnlyChanged != ""
 }
 
 // ShouldRun determines if we can know for certain that the job should run. We can either
@@ -48,10 +52,14 @@ func (cm RegexpChangeMatcher) ShouldRun(changes ChangedFilesProvider) (determine
 	return false, false, nil
 }
 
-// RunsAgainstChanges returns true if any of the changed input paths match the run_if_changed regex.
+// RunsAgainstChanges returns true if any of the changed input paths match the run_if_changed regex,
+// or if any of the changed input paths don't match the skip_if_only_changed regex.
 func (cm RegexpChangeMatcher) RunsAgainstChanges(changes []string) bool {
 	for _, change := range changes {
-		if cm.reChanges.MatchString(change) {
+		if cm.RunIfChanged != "" && cm.reChanges.MatchString(change) {
+			return true
+		}
+		if cm.SkipIfOnlyChanged != "" && !cm.reChanges.MatchString(change) {
 			return true
 		}
 	}
@@ -67,5 +75,15 @@ func (cm RegexpChangeMatcher) SetChangeRegexes() (RegexpChangeMatcher, error) {
 		}
 		cm.reChanges = re
 	}
+	if cm.SkipIfOnlyChanged != "" {
+		if cm.RunIfChanged != "" {
+			return cm, fmt.Errorf("run_if_changed and skip_if_only_changed are mutually exclusive")
+		}
+		re, err := regexp.Compile(cm.SkipIfOnlyChanged)
+		if err != nil {
+			return cm, fmt.Errorf("could not compile skip_if_only_changed regex: %v", err)
+		}
+		cm.reChanges = re
+	}
 	return cm, nil
 }
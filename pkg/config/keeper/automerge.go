@@ -0,0 +1,96 @@
+package keeper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+)
+
+// DefaultAutoMergeLabel is the default label requesting the merge of a pull request
+const DefaultAutoMergeLabel = "auto-merge"
+
+// AutoMerge is a lightweight alternative to the keeper queries for small repositories: their pull requests given
+// the auto merge label are merged as soon as they are approved and their required contexts pass.
+type AutoMerge struct {
+	// Repos are the orgs or org/repos whose pull requests can be auto merged.
+	Repos []string `json:"repos,omitempty"`
+	// Label is the label requesting the merge of a pull request. Defaults to `auto-merge`.
+	Label string `json:"label,omitempty"`
+	// ApprovedLabel is the label of the approved pull requests, added by the approve plugin. Defaults to `approved`.
+	ApprovedLabel string `json:"approved_label,omitempty"`
+	// MissingLabels are the labels preventing the merge of the pull requests.
+	// Defaults to the hold, work in progress and needs-rebase labels.
+	MissingLabels []string `json:"missing_labels,omitempty"`
+}
+
+// Parse sets the defaults of the auto merge and validates it
+func (a *AutoMerge) Parse() error {
+	if a.Label == "" {
+		a.Label = DefaultAutoMergeLabel
+	}
+	if a.ApprovedLabel == "" {
+		a.ApprovedLabel = labels.Approved
+	}
+	if a.MissingLabels == nil {
+		a.MissingLabels = []string{labels.Hold, labels.WorkInProgress, labels.NeedsRebase}
+	}
+	for _, r := range a.Repos {
+		if parts := strings.Split(r, "/"); len(parts) > 2 || parts[0] == "" || (len(parts) == 2 && parts[1] == "") {
+			return fmt.Errorf("keeper auto_merge has an invalid org or org/repo %q", r)
+		}
+	}
+	return nil
+}
+
+// Queries returns the keeper queries matching the pull requests to auto merge
+func (a *AutoMerge) Queries() Queries {
+	var orgs, repos []string
+	for _, r := range a.Repos {
+		if strings.Contains(r, "/") {
+			repos = append(repos, r)
+		} else {
+			orgs = append(orgs, r)
+		}
+	}
+	var answer Queries
+	query := Query{
+		Labels:        []string{a.Label, a.ApprovedLabel},
+		MissingLabels: a.MissingLabels,
+	}
+	if len(orgs) > 0 {
+		q := query
+		q.Orgs = orgs
+		answer = append(answer, q)
+	}
+	if len(repos) > 0 {
+		q := query
+		q.Repos = repos
+		answer = append(answer, q)
+	}
+	return answer
+}
+
+// addAutoMergeQueries adds the queries of the auto merge to the keeper queries, unless they were already added
+func (c *Config) addAutoMergeQueries() error {
+	if c.AutoMerge == nil {
+		return nil
+	}
+	if err := c.AutoMerge.Parse(); err != nil {
+		return err
+	}
+	for _, q := range c.AutoMerge.Queries() {
+		found := false
+		for _, existing := range c.Queries {
+			if reflect.DeepEqual(existing, q) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.Queries = append(c.Queries, q)
+		}
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ package keeper
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -35,6 +36,9 @@ type Config struct {
 	// Queries represents a list of GitHub search queries that collectively
 	// specify the set of PRs that meet merge requirements.
 	Queries Queries `json:"queries,omitempty"`
+	// AutoMerge merges the pull requests of some repositories given a label as soon as they are approved and
+	// their required contexts pass, without configuring the queries. Its queries are added to the Queries.
+	AutoMerge *AutoMerge `json:"auto_merge,omitempty"`
 	// A key/value pair of an org/repo as the key and merge method to override
 	// the default method of merge. Valid options are squash, rebase, and merge.
 	MergeType map[string]PullRequestMergeType `json:"merge_method,omitempty"`
@@ -82,6 +86,21 @@ type Config struct {
 	//  0 => unlimited batch size
 	// -1 => batch merging disabled :(
 	BatchSizeLimitMap map[string]int `json:"batch_size_limit,omitempty"`
+	// Priority is the minimum priority of the jobs triggered by keeper to retest or
+	// batch test pull requests before merging them, so they are started ahead of
+	// ordinary presubmits by the engines starting the jobs by priority. The tekton
+	// jobs are started right away, use PriorityClassName to prioritize their pods.
+	Priority int `json:"priority,omitempty"`
+	// PriorityClassName, if set, is the Kubernetes PriorityClass given to the pods
+	// of the jobs triggered by keeper.
+	PriorityClassName string `json:"priority_class_name,omitempty"`
+	// Shards assigns orgs or org/repos to named shards, the keeper instances started with `--shard=<name>` syncing
+	// only the orgs and repos of their shard. The orgs and repos not assigned to any shard are split by the hash of
+	// their org among the instances started with `--shard-count`.
+	Shards map[string][]string `json:"shards,omitempty"`
+
+	// shardOf maps the lower cased orgs and org/repos assigned to a shard to the name of the shard
+	shardOf map[string]string
 }
 
 // MergeMethod returns the merge method to use for a repo. The default of merge is
@@ -126,6 +145,18 @@ func (c *Config) MergeCommitTemplate(org, repo string) MergeCommitTemplate {
 	return v
 }
 
+// AssignedShard returns the name of the shard the repo, or the whole org if repo is empty, is assigned to, if any
+func (c *Config) AssignedShard(org, repo string) (string, bool) {
+	org = strings.ToLower(org)
+	if repo != "" {
+		if shard, ok := c.shardOf[org+"/"+strings.ToLower(repo)]; ok {
+			return shard, true
+		}
+	}
+	shard, ok := c.shardOf[org]
+	return shard, ok
+}
+
 // Parse initializes and validates the Config
 func (c *Config) Parse() error {
 	if c.SyncPeriodString == "" {
@@ -157,6 +188,22 @@ func (c *Config) Parse() error {
 			return fmt.Errorf("merge type %q for %s is not a valid type", method, name)
 		}
 	}
+	c.shardOf = map[string]string{}
+	for shard, entries := range c.Shards {
+		for _, entry := range entries {
+			key := strings.ToLower(entry)
+			if parts := strings.Split(key, "/"); len(parts) > 2 || parts[0] == "" || (len(parts) == 2 && parts[1] == "") {
+				return fmt.Errorf("keeper shard %s has an invalid org or org/repo %q", shard, entry)
+			}
+			if other, ok := c.shardOf[key]; ok && other != shard {
+				return fmt.Errorf("%s is assigned to both the keeper shards %s and %s", entry, other, shard)
+			}
+			c.shardOf[key] = shard
+		}
+	}
+	if err := c.addAutoMergeQueries(); err != nil {
+		return err
+	}
 	for i, tq := range c.Queries {
 		if err := tq.Validate(); err != nil {
 			return fmt.Errorf("keeper query (index %d) is invalid: %v", i, err)
@@ -278,6 +278,41 @@ func TestConfigGetKeeperContextPolicy(t *testing.T) {
 			expectedIfPresent:   []string{"run-if-changed", "not-always"},
 			expectedOptional:    []string{"optional"},
 		},
+		{
+			name: "renamed contexts",
+			presubmits: []job.Presubmit{
+				{
+					AlwaysRun: true,
+					Reporter: job.Reporter{
+						Context: "ci/unit",
+					},
+					PreviousContexts: []string{"unit", "always-run"},
+				},
+				{
+					AlwaysRun: true,
+					Reporter: job.Reporter{
+						Context: "always-run",
+					},
+				},
+			},
+			fromBranchProtection: true,
+			bpOrgs: map[string]branchprotection.Org{
+				"o": {
+					Policy: branchprotection.Policy{},
+					Repos: map[string]branchprotection.Repo{
+						"r": {
+							Policy: branchprotection.Policy{
+								RequiredStatusChecks: &branchprotection.ContextPolicy{
+									Contexts: []string{"unit"},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedRequired: []string{"always-run", "ci/unit"},
+			expectedOptional: []string{"unit"},
+		},
 	}
 
 	for _, tc := range cases {
@@ -321,3 +356,39 @@ func TestConfigGetKeeperContextPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestKeeperAutoMerge(t *testing.T) {
+	cfg, err := LoadYAMLConfig([]byte(`
+tide:
+  queries:
+  - repos:
+    - myorg/big
+    labels:
+    - lgtm
+  auto_merge:
+    repos:
+    - smallorg
+    - myorg/small
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	missing := []string{"do-not-merge/hold", "do-not-merge/work-in-progress", "needs-rebase"}
+	assert.Equal(t, keeper.Queries{
+		{Repos: []string{"myorg/big"}, Labels: []string{"lgtm"}},
+		{Orgs: []string{"smallorg"}, Labels: []string{"auto-merge", "approved"}, MissingLabels: missing},
+		{Repos: []string{"myorg/small"}, Labels: []string{"auto-merge", "approved"}, MissingLabels: missing},
+	}, cfg.Keeper.Queries)
+
+	// parsing again must not add the queries twice
+	assert.NoError(t, cfg.Keeper.Parse())
+	assert.Len(t, cfg.Keeper.Queries, 3)
+
+	_, err = LoadYAMLConfig([]byte(`
+tide:
+  auto_merge:
+    repos:
+    - myorg/small/repo
+`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+package lighthouse
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// ChatSlack is the kind of the notifications posted to a Slack channel
+	ChatSlack = "slack"
+	// ChatTeams is the kind of the notifications posted to a Microsoft Teams channel
+	ChatTeams = "teams"
+
+	// ChatEventJobFailed is the event sent when a job failed or errored
+	ChatEventJobFailed = "job_failed"
+	// ChatEventMerged is the event sent when keeper merged a pull request
+	ChatEventMerged = "merged"
+	// ChatEventOverride is the event sent when a failed context was overridden
+	ChatEventOverride = "override"
+)
+
+var chatEvents = sets.NewString(ChatEventJobFailed, ChatEventMerged, ChatEventOverride)
+
+// ChatNotification posts job failures, keeper merges and overrides to a Slack or Microsoft Teams channel.
+type ChatNotification struct {
+	// Name is the name of the notification, used in logs.
+	Name string `json:"name"`
+	// Kind is the kind of chat, "slack" or "teams".
+	Kind string `json:"kind"`
+	// Channel is the ID or name of the Slack channel the messages are posted to.
+	Channel string `json:"channel,omitempty"`
+	// TokenSecret references the Secret key holding the token of the Slack bot posting the messages.
+	TokenSecret *secret.Reference `json:"token_secret,omitempty"`
+	// WebhookURLSecret references the Secret key holding the URL of the Teams incoming webhook
+	// the messages are posted to.
+	WebhookURLSecret *secret.Reference `json:"webhook_url_secret,omitempty"`
+	// Repos restricts the notification to the events of the given orgs or org/repos.
+	// The events of all repositories are posted if empty.
+	Repos []string `json:"repos,omitempty"`
+	// Jobs restricts the job failures posted to the given jobs.
+	// The failures of all jobs are posted if empty.
+	Jobs []string `json:"jobs,omitempty"`
+	// Events restricts the notification to the given events, any of
+	// "job_failed", "merged" and "override". All events are posted if empty.
+	Events []string `json:"events,omitempty"`
+	// Templates are the Go templates of the messages keyed by event, replacing the default messages.
+	// They are executed with the event, e.g. "{{.Job}} failed on {{.Org}}/{{.Repo}}#{{.Number}}: {{.URL}}".
+	Templates map[string]string `json:"templates,omitempty"`
+}
+
+// Parse validates the notification
+func (n *ChatNotification) Parse() error {
+	if n.Name == "" {
+		return fmt.Errorf("chat notification of kind %q has no name", n.Kind)
+	}
+	switch n.Kind {
+	case ChatSlack:
+		if n.Channel == "" {
+			return fmt.Errorf("slack chat notification %s has no channel", n.Name)
+		}
+		if n.TokenSecret == nil {
+			return fmt.Errorf("slack chat notification %s has no token_secret", n.Name)
+		}
+		if err := n.TokenSecret.Validate(); err != nil {
+			return fmt.Errorf("invalid token_secret for chat notification %s: %v", n.Name, err)
+		}
+	case ChatTeams:
+		if n.WebhookURLSecret == nil {
+			return fmt.Errorf("teams chat notification %s has no webhook_url_secret", n.Name)
+		}
+		if err := n.WebhookURLSecret.Validate(); err != nil {
+			return fmt.Errorf("invalid webhook_url_secret for chat notification %s: %v", n.Name, err)
+		}
+	default:
+		return fmt.Errorf("invalid kind %q for chat notification %s, must be %s or %s", n.Kind, n.Name, ChatSlack, ChatTeams)
+	}
+	for _, event := range n.Events {
+		if !chatEvents.Has(event) {
+			return fmt.Errorf("invalid event %q for chat notification %s, valid events are %s", event, n.Name, strings.Join(chatEvents.List(), ", "))
+		}
+	}
+	for event, text := range n.Templates {
+		if !chatEvents.Has(event) {
+			return fmt.Errorf("template for invalid event %q in chat notification %s, valid events are %s", event, n.Name, strings.Join(chatEvents.List(), ", "))
+		}
+		if _, err := template.New(event).Funcs(template.FuncMap{"join": strings.Join}).Parse(text); err != nil {
+			return fmt.Errorf("invalid %s template for chat notification %s: %v", event, n.Name, err)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the notification wants the given event of the repository.
+// The job is empty for the events which are not about a job.
+func (n *ChatNotification) Matches(event, org, repo, job string) bool {
+	if len(n.Events) > 0 && !sets.NewString(n.Events...).Has(event) {
+		return false
+	}
+	if job != "" && len(n.Jobs) > 0 && !sets.NewString(n.Jobs...).Has(job) {
+		return false
+	}
+	return matchesRepos(n.Repos, org, repo)
+}
@@ -0,0 +1,89 @@
+package lighthouse
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigParseChatNotifications(t *testing.T) {
+	token := &secret.Reference{Name: "slack", Key: "token"}
+	webhook := &secret.Reference{Name: "teams", Key: "url"}
+	tests := []struct {
+		name          string
+		notifications []ChatNotification
+		wantErr       bool
+	}{
+		{
+			name: "valid",
+			notifications: []ChatNotification{
+				{Name: "ci", Kind: ChatSlack, Channel: "#ci", TokenSecret: token, Events: []string{ChatEventJobFailed}},
+				{Name: "releases", Kind: ChatTeams, WebhookURLSecret: webhook, Templates: map[string]string{ChatEventMerged: "{{.Org}}/{{.Repo}}#{{.Number}} merged"}},
+			},
+		},
+		{
+			name:          "missing name",
+			notifications: []ChatNotification{{Kind: ChatSlack, Channel: "#ci", TokenSecret: token}},
+			wantErr:       true,
+		},
+		{
+			name:          "unknown kind",
+			notifications: []ChatNotification{{Name: "ci", Kind: "irc", Channel: "#ci"}},
+			wantErr:       true,
+		},
+		{
+			name:          "slack without channel",
+			notifications: []ChatNotification{{Name: "ci", Kind: ChatSlack, TokenSecret: token}},
+			wantErr:       true,
+		},
+		{
+			name:          "slack without token",
+			notifications: []ChatNotification{{Name: "ci", Kind: ChatSlack, Channel: "#ci"}},
+			wantErr:       true,
+		},
+		{
+			name:          "teams without webhook",
+			notifications: []ChatNotification{{Name: "ci", Kind: ChatTeams}},
+			wantErr:       true,
+		},
+		{
+			name:          "unknown event",
+			notifications: []ChatNotification{{Name: "ci", Kind: ChatTeams, WebhookURLSecret: webhook, Events: []string{"succeeded"}}},
+			wantErr:       true,
+		},
+		{
+			name:          "invalid template",
+			notifications: []ChatNotification{{Name: "ci", Kind: ChatTeams, WebhookURLSecret: webhook, Templates: map[string]string{ChatEventOverride: "{{.Author"}}},
+			wantErr:       true,
+		},
+		{
+			name: "duplicate name",
+			notifications: []ChatNotification{
+				{Name: "ci", Kind: ChatTeams, WebhookURLSecret: webhook},
+				{Name: "ci", Kind: ChatSlack, Channel: "#ci", TokenSecret: token},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Config{ChatNotifications: tc.notifications}
+			err := c.Parse()
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestChatNotificationMatches(t *testing.T) {
+	n := ChatNotification{Repos: []string{"org", "other/repo"}, Jobs: []string{"unit"}, Events: []string{ChatEventJobFailed, ChatEventMerged}}
+	assert.True(t, n.Matches(ChatEventJobFailed, "org", "anything", "unit"))
+	assert.True(t, n.Matches(ChatEventMerged, "other", "repo", ""))
+	assert.False(t, n.Matches(ChatEventJobFailed, "org", "anything", "lint"))
+	assert.False(t, n.Matches(ChatEventOverride, "org", "anything", ""))
+	assert.False(t, n.Matches(ChatEventMerged, "other", "different", ""))
+}
@@ -0,0 +1,17 @@
+package lighthouse
+
+// CheckRuns configures the reporting of the jobs as GitHub check runs, with a markdown summary of the
+// pipeline and a re-run button, rather than as commit statuses.
+type CheckRuns struct {
+	// Enabled reports the jobs as check runs when the SCM provider is GitHub.
+	// The other providers keep on using commit statuses.
+	Enabled bool `json:"enabled,omitempty"`
+	// Repos restricts the check runs to the jobs of the given orgs or org/repos.
+	// The jobs of all repositories are reported as check runs if empty.
+	Repos []string `json:"repos,omitempty"`
+}
+
+// EnabledFor returns true if the jobs of the given repository are reported as check runs
+func (c CheckRuns) EnabledFor(org, repo string) bool {
+	return c.Enabled && matchesRepos(c.Repos, org, repo)
+}
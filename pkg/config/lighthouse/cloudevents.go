@@ -0,0 +1,32 @@
+package lighthouse
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// DefaultCloudEventsSource is the source of the CloudEvents emitted by lighthouse by default
+const DefaultCloudEventsSource = "lighthouse"
+
+// CloudEvents configures the CloudEvents emitted for the lifecycle of the LighthouseJobs and the merges of keeper
+type CloudEvents struct {
+	// Sink is the URL the events are posted to, e.g. the URL of a Knative broker or of an Argo Events webhook
+	// event source. No events are emitted if empty.
+	Sink string `json:"sink,omitempty"`
+	// Source is the source attribute of the events, e.g. the URL of the lighthouse installation.
+	// Defaults to `lighthouse`.
+	Source string `json:"source,omitempty"`
+}
+
+// Parse validates the sink of the events and sets their default source
+func (c *CloudEvents) Parse() error {
+	if c.Sink != "" {
+		if u, err := url.Parse(c.Sink); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid cloud_events sink %q, it must be an absolute URL", c.Sink)
+		}
+	}
+	if c.Source == "" {
+		c.Source = DefaultCloudEventsSource
+	}
+	return nil
+}
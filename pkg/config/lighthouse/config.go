@@ -18,6 +18,7 @@ package lighthouse
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strings"
 
@@ -64,8 +65,35 @@ type Config struct {
 	PubSubSubscriptions PubsubSubscriptions `json:"pubsub_subscriptions,omitempty"`
 	// GitHubOptions allows users to control how prow applications display GitHub website links.
 	GitHubOptions GitHubOptions `json:"github,omitempty"`
+	// GitLabOptions configures how the jobs are reported on GitLab.
+	GitLabOptions GitLabOptions `json:"gitlab,omitempty"`
 	// ProviderConfig contains optional SCM provider information
 	ProviderConfig *ProviderConfig `json:"providerConfig,omitempty"`
+	// StatusWebhooks are the HTTP endpoints LighthouseJob state transitions are posted to
+	StatusWebhooks []StatusWebhook `json:"status_webhooks,omitempty"`
+	// MaintenanceWindows are the periods during which newly triggered jobs are queued instead of launched
+	MaintenanceWindows []MaintenanceWindow `json:"maintenance_windows,omitempty"`
+	// ChatNotifications are the Slack and Microsoft Teams channels job failures, merges and overrides are posted to
+	ChatNotifications []ChatNotification `json:"chat_notifications,omitempty"`
+	// CheckRuns configures the reporting of the jobs as GitHub check runs
+	CheckRuns CheckRuns `json:"check_runs,omitempty"`
+	// SummaryStatus configures a commit status summarizing the state of the required jobs of each pull request
+	SummaryStatus SummaryStatus `json:"summary_status,omitempty"`
+	// SMTP configures the server the email reports of the postsubmit and periodic jobs are sent with
+	SMTP *SMTP `json:"smtp,omitempty"`
+	// DetailsURLs are the templates of the URLs the commit statuses and check runs of the jobs link to, the first
+	// template matching a job being used instead of the report URL provided by its engine
+	DetailsURLs []DetailsURL `json:"details_urls,omitempty"`
+	// LogStreaming configures the endpoint streaming the live logs of the jobs
+	LogStreaming LogStreaming `json:"log_streaming,omitempty"`
+	// LazyJobConfig configures the loading of the presubmits and postsubmits of each repository on demand
+	LazyJobConfig *LazyJobConfig `json:"lazy_job_config,omitempty"`
+	// Policy configures the policy engine authorizing the slash commands and the merges
+	Policy *Policy `json:"policy,omitempty"`
+	// Quarantine configures the tracking and quarantine of the flaky tests reported by the junit results of the jobs
+	Quarantine *Quarantine `json:"quarantine,omitempty"`
+	// CloudEvents configures the CloudEvents emitted for the lifecycle of the jobs and the merges
+	CloudEvents CloudEvents `json:"cloud_events,omitempty"`
 }
 
 // Parse initializes and validates the Config
@@ -100,6 +128,63 @@ func (c *Config) Parse() error {
 	if err := c.GitHubOptions.Parse(); err != nil {
 		return err
 	}
+	names := map[string]bool{}
+	for i := range c.StatusWebhooks {
+		if err := c.StatusWebhooks[i].Parse(); err != nil {
+			return err
+		}
+		if names[c.StatusWebhooks[i].Name] {
+			return fmt.Errorf("duplicate status webhook name %s", c.StatusWebhooks[i].Name)
+		}
+		names[c.StatusWebhooks[i].Name] = true
+	}
+	names = map[string]bool{}
+	for i := range c.ChatNotifications {
+		if err := c.ChatNotifications[i].Parse(); err != nil {
+			return err
+		}
+		if names[c.ChatNotifications[i].Name] {
+			return fmt.Errorf("duplicate chat notification name %s", c.ChatNotifications[i].Name)
+		}
+		names[c.ChatNotifications[i].Name] = true
+	}
+	for i := range c.MaintenanceWindows {
+		if err := c.MaintenanceWindows[i].Parse(); err != nil {
+			return err
+		}
+	}
+	c.SummaryStatus.Parse()
+	if c.SMTP != nil {
+		if err := c.SMTP.Parse(); err != nil {
+			return err
+		}
+	}
+	for i := range c.DetailsURLs {
+		if err := c.DetailsURLs[i].Parse(); err != nil {
+			return err
+		}
+	}
+	if err := c.LogStreaming.Parse(); err != nil {
+		return err
+	}
+	if err := c.CloudEvents.Parse(); err != nil {
+		return err
+	}
+	if c.LazyJobConfig != nil {
+		if err := c.LazyJobConfig.Parse(); err != nil {
+			return err
+		}
+	}
+	if c.Policy != nil {
+		if err := c.Policy.Parse(); err != nil {
+			return err
+		}
+	}
+	if c.Quarantine != nil {
+		if err := c.Quarantine.Parse(); err != nil {
+			return err
+		}
+	}
 	if c.LogLevel == "" {
 		c.LogLevel = os.Getenv("LOG_LEVEL")
 		if c.LogLevel == "" {
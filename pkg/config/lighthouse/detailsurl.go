@@ -0,0 +1,78 @@
+package lighthouse
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// DetailsURL is a template of the URL the commit statuses and check runs of some jobs link to, such as a log viewer,
+// a Grafana dashboard, the Tekton dashboard or Jenkins, so that the links match the layout of the installation.
+type DetailsURL struct {
+	// TemplateString compiles into Template at load time, it is a Go template executed with the LighthouseJob, e.g.
+	// `https://dashboard.example.com/#/namespaces/{{ .Namespace }}/pipelineruns/{{ .Status.ActivityName }}`.
+	TemplateString string `json:"template"`
+	// Template is compiled at load time from TemplateString.
+	Template *template.Template `json:"-"`
+	// Repos restricts the template to the jobs of the given orgs or org/repos.
+	// The jobs of all repositories use it if empty.
+	Repos []string `json:"repos,omitempty"`
+	// Jobs restricts the template to the jobs with the given names.
+	// All the jobs use it if empty.
+	Jobs []string `json:"jobs,omitempty"`
+	// Agents restricts the template to the jobs run by the given agents, e.g. `tekton-pipeline` or `jenkins`.
+	// The jobs of all agents use it if empty.
+	Agents []string `json:"agents,omitempty"`
+}
+
+// Parse compiles the template
+func (d *DetailsURL) Parse() error {
+	if d.TemplateString == "" {
+		return fmt.Errorf("details url for repos %v and jobs %v has no template", d.Repos, d.Jobs)
+	}
+	tmpl, err := template.New("DetailsURL").Option("missingkey=error").Parse(d.TemplateString)
+	if err != nil {
+		return fmt.Errorf("parsing details url template %q: %v", d.TemplateString, err)
+	}
+	d.Template = tmpl
+	return nil
+}
+
+// Matches returns true if the template applies to the given job of the given repository run by the given agent.
+// The org and repo are empty for jobs without refs, which only match templates without repos.
+func (d *DetailsURL) Matches(org, repo, job, agent string) bool {
+	if len(d.Jobs) > 0 && !sets.NewString(d.Jobs...).Has(job) {
+		return false
+	}
+	if len(d.Agents) > 0 && !sets.NewString(d.Agents...).Has(agent) {
+		return false
+	}
+	return matchesRepos(d.Repos, org, repo)
+}
+
+// Execute resolves the URL from the given LighthouseJob
+func (d *DetailsURL) Execute(lighthouseJob interface{}) (string, error) {
+	if d.Template == nil {
+		if err := d.Parse(); err != nil {
+			return "", err
+		}
+	}
+	var b bytes.Buffer
+	if err := d.Template.Execute(&b, lighthouseJob); err != nil {
+		return "", fmt.Errorf("executing details url template %q: %v", d.TemplateString, err)
+	}
+	return b.String(), nil
+}
+
+// DetailsURLFor returns the first details URL template applying to the given job of the given repository run by
+// the given agent, if any.
+func (c *Config) DetailsURLFor(org, repo, job, agent string) *DetailsURL {
+	for i := range c.DetailsURLs {
+		if c.DetailsURLs[i].Matches(org, repo, job, agent) {
+			return &c.DetailsURLs[i]
+		}
+	}
+	return nil
+}
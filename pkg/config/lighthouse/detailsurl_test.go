@@ -0,0 +1,44 @@
+package lighthouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigParseDetailsURLs(t *testing.T) {
+	c := Config{DetailsURLs: []DetailsURL{{TemplateString: "https://logs.example.com/{{ .Name }}"}}}
+	require.NoError(t, c.Parse())
+	assert.NotNil(t, c.DetailsURLs[0].Template)
+
+	c = Config{DetailsURLs: []DetailsURL{{Repos: []string{"org"}}}}
+	assert.Error(t, c.Parse(), "missing template")
+
+	c = Config{DetailsURLs: []DetailsURL{{TemplateString: "https://logs.example.com/{{ .Name"}}}
+	assert.Error(t, c.Parse(), "invalid template")
+}
+
+func TestDetailsURLFor(t *testing.T) {
+	c := Config{DetailsURLs: []DetailsURL{
+		{TemplateString: "jenkins", Agents: []string{"jenkins"}},
+		{TemplateString: "grafana", Repos: []string{"org/repo"}, Jobs: []string{"perf"}},
+		{TemplateString: "dashboard", Repos: []string{"org"}},
+	}}
+	require.NoError(t, c.Parse())
+
+	resolve := func(org, repo, job, agent string) string {
+		d := c.DetailsURLFor(org, repo, job, agent)
+		if d == nil {
+			return ""
+		}
+		u, err := d.Execute(nil)
+		require.NoError(t, err)
+		return u
+	}
+	assert.Equal(t, "jenkins", resolve("other", "repo", "unit", "jenkins"))
+	assert.Equal(t, "grafana", resolve("org", "repo", "perf", "tekton-pipeline"))
+	assert.Equal(t, "dashboard", resolve("org", "repo", "unit", "tekton-pipeline"))
+	assert.Equal(t, "", resolve("other", "repo", "unit", "tekton-pipeline"))
+	assert.Equal(t, "", resolve("", "", "periodic", "tekton-pipeline"))
+}
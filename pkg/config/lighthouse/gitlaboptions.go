@@ -0,0 +1,12 @@
+package lighthouse
+
+// GitLabOptions configures how the jobs are reported on GitLab.
+type GitLabOptions struct {
+	// PipelineName is the name of the pipeline the commit statuses of the jobs are grouped under.
+	// The statuses are added to the latest pipeline of the commit with this name if there is one,
+	// otherwise GitLab groups them under the external pipeline of the branch or merge request.
+	PipelineName string `json:"pipeline_name,omitempty"`
+	// IgnorePipelinesMustSucceed stops keeper from requiring all the statuses of a merge request to
+	// succeed when its project only allows merging once the pipeline succeeded.
+	IgnorePipelinesMustSucceed bool `json:"ignore_pipelines_must_succeed,omitempty"`
+}
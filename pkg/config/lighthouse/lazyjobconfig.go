@@ -0,0 +1,29 @@
+package lighthouse
+
+import "fmt"
+
+// DefaultLazyJobConfigCacheSize is the default number of repositories whose jobs are kept parsed when the job
+// configuration is loaded lazily
+const DefaultLazyJobConfigCacheSize = 500
+
+// LazyJobConfig configures the loading of the presubmits and postsubmits of each repository on demand, for the
+// installations whose generated job configuration is too large to be kept parsed in memory by every component
+type LazyJobConfig struct {
+	// Enabled keeps the presubmits and postsubmits of the repositories serialized once validated, the jobs of a
+	// repository being parsed again when they are looked up
+	Enabled bool `json:"enabled,omitempty"`
+	// CacheSize is the number of repositories whose parsed jobs are cached, the least recently used ones being
+	// evicted. Defaults to 500.
+	CacheSize int `json:"cache_size,omitempty"`
+}
+
+// Parse validates the lazy job configuration and sets its defaults
+func (l *LazyJobConfig) Parse() error {
+	if l.CacheSize < 0 {
+		return fmt.Errorf("invalid lazy_job_config cache_size %d, it must not be negative", l.CacheSize)
+	}
+	if l.CacheSize == 0 {
+		l.CacheSize = DefaultLazyJobConfigCacheSize
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package lighthouse
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// LogStreaming configures the endpoint of the webhooks service streaming the live logs of the jobs
+type LogStreaming struct {
+	// URL is the external URL of the log streaming endpoint, e.g. https://lighthouse.example.com/logs.
+	// The commit statuses of the jobs which have no report URL link to their live logs when set.
+	URL string `json:"url,omitempty"`
+	// JenkinsURL is the URL of the log server of the Jenkins controller the console output of the Jenkins
+	// jobs is proxied from, e.g. http://jenkins-x-controller:8080. It is read with the Jenkins credentials
+	// given to the webhooks service with --jenkins-token-file or --jenkins-bearer-token-file, if any.
+	JenkinsURL string `json:"jenkins_url,omitempty"`
+}
+
+// Parse validates the URLs of the log streaming endpoint
+func (l *LogStreaming) Parse() error {
+	for name, value := range map[string]string{"url": l.URL, "jenkins_url": l.JenkinsURL} {
+		if value == "" {
+			continue
+		}
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid log_streaming %s %q, it must be an absolute URL", name, value)
+		}
+	}
+	return nil
+}
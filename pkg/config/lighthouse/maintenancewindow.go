@@ -0,0 +1,82 @@
+package lighthouse
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaintenanceWindow is a period of time during which newly triggered jobs are queued instead of
+// being launched, such as a cluster upgrade. The queued jobs are launched when the window ends.
+type MaintenanceWindow struct {
+	// Name is the name of the window, used in the commit statuses of the queued jobs.
+	Name string `json:"name"`
+	// StartString compiles into Start at load time, it is an RFC 3339 time such as "2020-07-20T20:00:00Z".
+	StartString string `json:"start"`
+	// Start is when the window starts.
+	Start time.Time `json:"-"`
+	// EndString compiles into End at load time, it is an RFC 3339 time such as "2020-07-20T22:00:00Z".
+	EndString string `json:"end"`
+	// End is when the window ends.
+	End time.Time `json:"-"`
+	// Repos restricts the window to the jobs of the given orgs or org/repos.
+	// The jobs of all repositories are queued if empty.
+	Repos []string `json:"repos,omitempty"`
+}
+
+// Parse initializes and validates the window
+func (w *MaintenanceWindow) Parse() error {
+	if w.Name == "" {
+		return fmt.Errorf("maintenance window starting at %q has no name", w.StartString)
+	}
+	var err error
+	w.Start, err = time.Parse(time.RFC3339, w.StartString)
+	if err != nil {
+		return fmt.Errorf("cannot parse start for maintenance window %s: %v", w.Name, err)
+	}
+	w.End, err = time.Parse(time.RFC3339, w.EndString)
+	if err != nil {
+		return fmt.Errorf("cannot parse end for maintenance window %s: %v", w.Name, err)
+	}
+	if !w.End.After(w.Start) {
+		return fmt.Errorf("maintenance window %s must end after it starts", w.Name)
+	}
+	return nil
+}
+
+// Active returns true if the window applies to the jobs of the given repository at the given time.
+// The org and repo are empty for jobs without refs, which only match windows without repos.
+func (w *MaintenanceWindow) Active(org, repo string, now time.Time) bool {
+	if now.Before(w.Start) || !now.Before(w.End) {
+		return false
+	}
+	return matchesRepos(w.Repos, org, repo)
+}
+
+// ActiveMaintenanceWindow returns the window applying to the jobs of the given repository at the given time
+// which ends last, if any.
+func (c *Config) ActiveMaintenanceWindow(org, repo string, now time.Time) *MaintenanceWindow {
+	var active *MaintenanceWindow
+	for i := range c.MaintenanceWindows {
+		w := &c.MaintenanceWindows[i]
+		if w.Active(org, repo, now) && (active == nil || w.End.After(active.End)) {
+			active = w
+		}
+	}
+	return active
+}
+
+// matchesRepos returns true if the given repository is one of the orgs or org/repos, or if there are none.
+func matchesRepos(repos []string, org, repo string) bool {
+	if len(repos) == 0 {
+		return true
+	}
+	if org == "" {
+		return false
+	}
+	for _, r := range repos {
+		if r == org || r == org+"/"+repo {
+			return true
+		}
+	}
+	return false
+}
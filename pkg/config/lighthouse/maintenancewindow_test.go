@@ -0,0 +1,39 @@
+package lighthouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindowParse(t *testing.T) {
+	w := MaintenanceWindow{Name: "upgrade", StartString: "2020-07-20T20:00:00Z", EndString: "2020-07-20T22:00:00+01:00"}
+	require.NoError(t, w.Parse())
+	assert.Equal(t, time.Date(2020, 7, 20, 20, 0, 0, 0, time.UTC), w.Start.UTC())
+	assert.Equal(t, time.Date(2020, 7, 20, 21, 0, 0, 0, time.UTC), w.End.UTC())
+
+	for _, invalid := range []MaintenanceWindow{
+		{StartString: "2020-07-20T20:00:00Z", EndString: "2020-07-20T22:00:00Z"},
+		{Name: "upgrade", StartString: "tonight", EndString: "2020-07-20T22:00:00Z"},
+		{Name: "upgrade", StartString: "2020-07-20T20:00:00Z"},
+		{Name: "upgrade", StartString: "2020-07-20T20:00:00Z", EndString: "2020-07-20T19:00:00Z"},
+	} {
+		assert.Error(t, invalid.Parse(), "window %+v", invalid)
+	}
+}
+
+func TestActiveMaintenanceWindow(t *testing.T) {
+	start := time.Date(2020, 7, 20, 20, 0, 0, 0, time.UTC)
+	c := Config{MaintenanceWindows: []MaintenanceWindow{
+		{Name: "cluster", Start: start, End: start.Add(time.Hour)},
+		{Name: "repo", Start: start, End: start.Add(2 * time.Hour), Repos: []string{"org/repo"}},
+	}}
+	assert.Nil(t, c.ActiveMaintenanceWindow("org", "repo", start.Add(-time.Minute)))
+	assert.Equal(t, "repo", c.ActiveMaintenanceWindow("org", "repo", start).Name)
+	assert.Equal(t, "cluster", c.ActiveMaintenanceWindow("org", "other", start).Name)
+	assert.Equal(t, "cluster", c.ActiveMaintenanceWindow("", "", start).Name)
+	assert.Equal(t, "repo", c.ActiveMaintenanceWindow("org", "repo", start.Add(time.Hour)).Name)
+	assert.Nil(t, c.ActiveMaintenanceWindow("org", "other", start.Add(time.Hour)))
+}
@@ -0,0 +1,55 @@
+package lighthouse
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// DefaultPolicyTimeout is the default time the policy engine has to evaluate a decision
+const DefaultPolicyTimeout = 5 * time.Second
+
+// Policy configures an Open Policy Agent the slash commands and the merges are authorized by, the policies being
+// written in Rego and evaluated with an input holding the actor, their teams, the repository, the command and the
+// labels of the pull request or issue.
+type Policy struct {
+	// URL is the endpoint of the OPA data API of the decision, e.g. http://opa:8181/v1/data/lighthouse/authz.
+	// The decision is either a boolean or an object with an "allow" boolean and an optional "reason" string.
+	URL string `json:"url"`
+	// TimeoutString compiles into Timeout at load time.
+	TimeoutString string `json:"timeout,omitempty"`
+	// Timeout is the time the policy engine has to evaluate a decision. Defaults to 5s.
+	Timeout time.Duration `json:"-"`
+	// FailOpen allows the commands and the merges when the policy engine cannot be reached or has no decision.
+	// They are denied by default.
+	FailOpen bool `json:"fail_open,omitempty"`
+	// Repos restricts the policies to the given orgs or org/repos.
+	// The commands and the merges of all repositories are authorized by the policies if empty.
+	Repos []string `json:"repos,omitempty"`
+}
+
+// Parse validates the policy configuration and sets its defaults
+func (p *Policy) Parse() error {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url for policy: %v", err)
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("url %q for policy must be absolute", p.URL)
+	}
+	if p.TimeoutString == "" {
+		p.Timeout = DefaultPolicyTimeout
+	} else {
+		timeout, err := time.ParseDuration(p.TimeoutString)
+		if err != nil {
+			return fmt.Errorf("cannot parse duration for policy.timeout: %v", err)
+		}
+		p.Timeout = timeout
+	}
+	return nil
+}
+
+// EnabledFor returns true if the commands and the merges of the given repository are authorized by the policies
+func (p *Policy) EnabledFor(org, repo string) bool {
+	return p != nil && matchesRepos(p.Repos, org, repo)
+}
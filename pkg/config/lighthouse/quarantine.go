@@ -0,0 +1,74 @@
+package lighthouse
+
+import (
+	"fmt"
+)
+
+const (
+	// DefaultQuarantineConfigMap is the default name of the ConfigMap holding the quarantined tests
+	DefaultQuarantineConfigMap = "lighthouse-quarantine"
+	// DefaultQuarantineWindow is the default number of latest runs of a job the failure rates of its tests are
+	// computed over
+	DefaultQuarantineWindow = 20
+	// DefaultQuarantineMinRuns is the default number of runs of a job required before its tests are quarantined
+	DefaultQuarantineMinRuns = 5
+	// DefaultQuarantineFlakeThreshold is the default failure rate from which a test is considered flaky
+	DefaultQuarantineFlakeThreshold = 0.1
+	// DefaultQuarantineIssueLabel is the default label of the quarantine issues
+	DefaultQuarantineIssueLabel = "kind/flake"
+)
+
+// Quarantine configures the tracking of the flaky tests from the junit results of the jobs. The tests which fail
+// too often, but not always, are quarantined: an issue is opened for each of them and they are added to the
+// quarantine list of the repository, which is shown in the test failures comments of the pull requests.
+type Quarantine struct {
+	// Repos restricts the tracking to the jobs of the given orgs or org/repos.
+	// The jobs of all repositories are tracked if empty.
+	Repos []string `json:"repos,omitempty"`
+	// ConfigMap is the name of the ConfigMap holding the quarantine lists of the repositories, defaults to
+	// "lighthouse-quarantine".
+	ConfigMap string `json:"config_map,omitempty"`
+	// Window is the number of latest completed runs of a job the failure rates of its tests are computed over,
+	// defaults to 20.
+	Window int `json:"window,omitempty"`
+	// MinRuns is the minimum number of completed runs of a job before its tests are quarantined, defaults to 5.
+	MinRuns int `json:"min_runs,omitempty"`
+	// FlakeThreshold is the failure rate, between 0 and 1, from which a test is quarantined, defaults to 0.1.
+	FlakeThreshold float64 `json:"flake_threshold,omitempty"`
+	// IssueLabels are the labels of the quarantine issues, defaults to "kind/flake".
+	IssueLabels []string `json:"issue_labels,omitempty"`
+}
+
+// Parse validates the quarantine configuration and sets its defaults
+func (q *Quarantine) Parse() error {
+	if q.ConfigMap == "" {
+		q.ConfigMap = DefaultQuarantineConfigMap
+	}
+	if q.Window == 0 {
+		q.Window = DefaultQuarantineWindow
+	}
+	if q.MinRuns == 0 {
+		q.MinRuns = DefaultQuarantineMinRuns
+	}
+	if q.FlakeThreshold == 0 {
+		q.FlakeThreshold = DefaultQuarantineFlakeThreshold
+	}
+	if len(q.IssueLabels) == 0 {
+		q.IssueLabels = []string{DefaultQuarantineIssueLabel}
+	}
+	if q.Window < 0 || q.MinRuns < 0 {
+		return fmt.Errorf("quarantine window and min_runs must be positive")
+	}
+	if q.MinRuns > q.Window {
+		return fmt.Errorf("quarantine min_runs %d exceeds the window of %d runs", q.MinRuns, q.Window)
+	}
+	if q.FlakeThreshold < 0 || q.FlakeThreshold > 1 {
+		return fmt.Errorf("quarantine flake_threshold %v is not between 0 and 1", q.FlakeThreshold)
+	}
+	return nil
+}
+
+// EnabledFor returns true if the flaky tests of the given repository are tracked
+func (q *Quarantine) EnabledFor(org, repo string) bool {
+	return q != nil && matchesRepos(q.Repos, org, repo)
+}
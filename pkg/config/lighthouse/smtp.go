@@ -0,0 +1,54 @@
+package lighthouse
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"strconv"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+)
+
+// DefaultSMTPPort is the default port of the SMTP server, the mail submission port
+const DefaultSMTPPort = 587
+
+// SMTP configures the server the email reports of the jobs are sent with.
+type SMTP struct {
+	// Host is the host name of the SMTP server.
+	Host string `json:"host"`
+	// Port is the port of the SMTP server, defaults to 587.
+	Port int `json:"port,omitempty"`
+	// From is the sender address of the emails.
+	From string `json:"from"`
+	// Username is the user authenticating to the SMTP server, if it requires authentication.
+	Username string `json:"username,omitempty"`
+	// PasswordSecret references the Secret key holding the password of the user.
+	PasswordSecret *secret.Reference `json:"password_secret,omitempty"`
+}
+
+// Parse validates the SMTP configuration and sets its defaults
+func (s *SMTP) Parse() error {
+	if s.Host == "" {
+		return fmt.Errorf("smtp has no host")
+	}
+	if s.Port == 0 {
+		s.Port = DefaultSMTPPort
+	}
+	if _, err := mail.ParseAddress(s.From); err != nil {
+		return fmt.Errorf("invalid smtp from address %q: %v", s.From, err)
+	}
+	if s.Username != "" {
+		if s.PasswordSecret == nil {
+			return fmt.Errorf("smtp username %s has no password_secret", s.Username)
+		}
+		if err := s.PasswordSecret.Validate(); err != nil {
+			return fmt.Errorf("invalid smtp password_secret: %v", err)
+		}
+	}
+	return nil
+}
+
+// Address returns the host:port address of the SMTP server
+func (s *SMTP) Address() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
@@ -0,0 +1,86 @@
+package lighthouse
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// StatusWebhookTriggered is the event sent when a job has been triggered
+	StatusWebhookTriggered = "triggered"
+	// StatusWebhookRunning is the event sent when a job starts running
+	StatusWebhookRunning = "running"
+	// StatusWebhookSucceeded is the event sent when a job succeeded
+	StatusWebhookSucceeded = "succeeded"
+	// StatusWebhookFailed is the event sent when a job failed or errored
+	StatusWebhookFailed = "failed"
+	// StatusWebhookAborted is the event sent when a job was aborted
+	StatusWebhookAborted = "aborted"
+
+	// DefaultStatusWebhookHMACTokenEnv is the environment variable holding the token used to sign the status webhooks by default
+	DefaultStatusWebhookHMACTokenEnv = "HMAC_TOKEN"
+)
+
+var statusWebhookEvents = sets.NewString(StatusWebhookTriggered, StatusWebhookRunning, StatusWebhookSucceeded, StatusWebhookFailed, StatusWebhookAborted)
+
+// StatusWebhook is an HTTP endpoint LighthouseJob state transitions are posted to.
+type StatusWebhook struct {
+	// Name is the name of the webhook, used in logs.
+	Name string `json:"name"`
+	// URL is the endpoint the JSON payloads are posted to.
+	URL string `json:"url"`
+	// HMACTokenEnv is the environment variable holding the token used to sign the payloads.
+	// Defaults to HMAC_TOKEN.
+	HMACTokenEnv string `json:"hmac_token_env,omitempty"`
+	// HMACTokenSecret references the Secret key holding the token used to sign the payloads,
+	// it takes precedence over HMACTokenEnv.
+	HMACTokenSecret *secret.Reference `json:"hmac_token_secret,omitempty"`
+	// Repos restricts the webhook to the jobs of the given orgs or org/repos.
+	// The jobs of all repositories are sent if empty.
+	Repos []string `json:"repos,omitempty"`
+	// Events restricts the webhook to the given events, any of
+	// "triggered", "running", "succeeded", "failed" and "aborted".
+	// All events are sent if empty.
+	Events []string `json:"events,omitempty"`
+}
+
+// Parse validates the webhook and sets its defaults
+func (w *StatusWebhook) Parse() error {
+	if w.Name == "" {
+		return fmt.Errorf("status webhook with url %q has no name", w.URL)
+	}
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url for status webhook %s: %v", w.Name, err)
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("url %q for status webhook %s must be absolute", w.URL, w.Name)
+	}
+	for _, event := range w.Events {
+		if !statusWebhookEvents.Has(event) {
+			return fmt.Errorf("invalid event %q for status webhook %s, valid events are %s", event, w.Name, strings.Join(statusWebhookEvents.List(), ", "))
+		}
+	}
+	if w.HMACTokenSecret != nil {
+		if err := w.HMACTokenSecret.Validate(); err != nil {
+			return fmt.Errorf("invalid hmac_token_secret for status webhook %s: %v", w.Name, err)
+		}
+	}
+	if w.HMACTokenEnv == "" {
+		w.HMACTokenEnv = DefaultStatusWebhookHMACTokenEnv
+	}
+	return nil
+}
+
+// Matches returns true if the webhook wants the given event for a job of the given repository.
+// The org and repo are empty for jobs without refs, which only match webhooks without repos.
+func (w *StatusWebhook) Matches(org, repo, event string) bool {
+	if len(w.Events) > 0 && !sets.NewString(w.Events...).Has(event) {
+		return false
+	}
+	return matchesRepos(w.Repos, org, repo)
+}
@@ -0,0 +1,64 @@
+package lighthouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigParseStatusWebhooks(t *testing.T) {
+	tests := []struct {
+		name     string
+		webhooks []StatusWebhook
+		wantErr  bool
+	}{
+		{
+			name:     "valid",
+			webhooks: []StatusWebhook{{Name: "dashboard", URL: "https://dashboard.example.com/hook", Events: []string{"succeeded", "failed"}}},
+		},
+		{
+			name:     "missing name",
+			webhooks: []StatusWebhook{{URL: "https://dashboard.example.com/hook"}},
+			wantErr:  true,
+		},
+		{
+			name:     "relative url",
+			webhooks: []StatusWebhook{{Name: "dashboard", URL: "/hook"}},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown event",
+			webhooks: []StatusWebhook{{Name: "dashboard", URL: "https://dashboard.example.com/hook", Events: []string{"pending"}}},
+			wantErr:  true,
+		},
+		{
+			name: "duplicate name",
+			webhooks: []StatusWebhook{
+				{Name: "dashboard", URL: "https://dashboard.example.com/hook"},
+				{Name: "dashboard", URL: "https://other.example.com/hook"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Config{StatusWebhooks: tc.webhooks}
+			err := c.Parse()
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, DefaultStatusWebhookHMACTokenEnv, c.StatusWebhooks[0].HMACTokenEnv)
+		})
+	}
+}
+
+func TestStatusWebhookMatches(t *testing.T) {
+	w := StatusWebhook{Repos: []string{"org", "other/repo"}, Events: []string{StatusWebhookFailed}}
+	assert.True(t, w.Matches("org", "anything", StatusWebhookFailed))
+	assert.True(t, w.Matches("other", "repo", StatusWebhookFailed))
+	assert.False(t, w.Matches("other", "different", StatusWebhookFailed))
+	assert.False(t, w.Matches("org", "anything", StatusWebhookSucceeded))
+	assert.False(t, w.Matches("", "", StatusWebhookFailed))
+}
@@ -0,0 +1,28 @@
+package lighthouse
+
+// DefaultSummaryContext is the default context of the summary status
+const DefaultSummaryContext = "lighthouse/summary"
+
+// SummaryStatus configures a commit status summarizing the state of the required jobs of each pull request,
+// e.g. "3/5 required checks passing; blocked on e2e, lint", updated as the individual jobs report.
+type SummaryStatus struct {
+	// Enabled reports the summary status on the pull requests.
+	Enabled bool `json:"enabled,omitempty"`
+	// Context is the context of the summary status, defaults to "lighthouse/summary".
+	Context string `json:"context,omitempty"`
+	// Repos restricts the summary status to the pull requests of the given orgs or org/repos.
+	// The pull requests of all repositories are summarized if empty.
+	Repos []string `json:"repos,omitempty"`
+}
+
+// Parse sets the default context of the summary status
+func (s *SummaryStatus) Parse() {
+	if s.Context == "" {
+		s.Context = DefaultSummaryContext
+	}
+}
+
+// EnabledFor returns true if the pull requests of the given repository get a summary status
+func (s SummaryStatus) EnabledFor(org, repo string) bool {
+	return s.Enabled && matchesRepos(s.Repos, org, repo)
+}
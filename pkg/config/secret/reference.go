@@ -0,0 +1,108 @@
+package secret
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultResolverTTL is how long the resolved values of the secret references are cached by default
+const DefaultResolverTTL = 5 * time.Minute
+
+// Reference points to a key of a Kubernetes Secret, in the namespace of lighthouse, holding a credential.
+// It lets the configuration refer to credentials without containing any secret material.
+type Reference struct {
+	// Name is the name of the Secret.
+	Name string `json:"name"`
+	// Key is the key of the Secret data holding the credential.
+	Key string `json:"key"`
+}
+
+// Validate validates the secret reference
+func (r *Reference) Validate() error {
+	if r.Name == "" {
+		return errors.New("secret reference has no name")
+	}
+	if r.Key == "" {
+		return fmt.Errorf("reference to secret %s has no key", r.Name)
+	}
+	return nil
+}
+
+// String returns the reference as name/key
+func (r Reference) String() string {
+	return r.Name + "/" + r.Key
+}
+
+// Getter gets a Secret by name
+type Getter func(name string) (*v1.Secret, error)
+
+// KubeGetter returns a Getter reading the Secrets of the namespace with the given client
+func KubeGetter(client kubernetes.Interface, namespace string) Getter {
+	return func(name string) (*v1.Secret, error) {
+		return client.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	}
+}
+
+type resolvedValue struct {
+	value   string
+	expires time.Time
+}
+
+// Resolver resolves secret references when their value is first needed, caching the values for a TTL so
+// that rotated secrets are eventually picked up.
+type Resolver struct {
+	getter Getter
+	ttl    time.Duration
+	now    func() time.Time
+
+	lock  sync.Mutex
+	cache map[Reference]resolvedValue
+}
+
+// NewResolver creates a resolver getting the Secrets with the given getter
+func NewResolver(getter Getter, ttl time.Duration) *Resolver {
+	if ttl <= 0 {
+		ttl = DefaultResolverTTL
+	}
+	return &Resolver{
+		getter: getter,
+		ttl:    ttl,
+		now:    time.Now,
+		cache:  map[Reference]resolvedValue{},
+	}
+}
+
+// Resolve returns the value of the referenced secret key
+func (r *Resolver) Resolve(ref Reference) (string, error) {
+	if r == nil {
+		return "", fmt.Errorf("cannot resolve secret %s without a secret resolver", ref)
+	}
+	if err := ref.Validate(); err != nil {
+		return "", err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now()
+	if cached, ok := r.cache[ref]; ok && now.Before(cached.expires) {
+		return cached.value, nil
+	}
+	s, err := r.getter(ref.Name)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get secret %s", ref.Name)
+	}
+	data, ok := s.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %s", ref.Name, ref.Key)
+	}
+	value := strings.TrimSpace(string(data))
+	r.cache[ref] = resolvedValue{value: value, expires: now.Add(r.ttl)}
+	return value, nil
+}
@@ -0,0 +1,145 @@
+package configcheck
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Load loads the Lighthouse configuration, the optional job configuration file or directory and the
+// optional plugins configuration, returning the first error which prevents Lighthouse from loading them.
+func Load(configPath, jobConfigPath, pluginsPath string) (*config.Config, *plugins.Configuration, error) {
+	cfg, err := config.Load(configPath, jobConfigPath)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to load config %s", configPath)
+	}
+	pluginCfg := &plugins.Configuration{}
+	if pluginsPath != "" {
+		data, err := ioutil.ReadFile(pluginsPath) // #nosec
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to read plugins config %s", pluginsPath)
+		}
+		pluginCfg, err = (&plugins.ConfigAgent{}).LoadYAMLConfig(data)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to load plugins config %s", pluginsPath)
+		}
+	}
+	return cfg, pluginCfg, nil
+}
+
+// Validate runs the semantic checks which loading the configuration does not enforce, returning all the problems found.
+// The known plugins are the names of the plugins linked into Lighthouse, usually the keys of plugins.HelpProviders().
+func Validate(cfg *config.Config, pluginCfg *plugins.Configuration, knownPlugins []string) []error {
+	var errs []error
+	errs = append(errs, validateContexts(cfg)...)
+	if pluginCfg != nil {
+		errs = append(errs, validatePlugins(pluginCfg, knownPlugins)...)
+	}
+	errs = append(errs, validateKeeperQueries(cfg, pluginCfg)...)
+	return errs
+}
+
+// validateContexts checks that no two presubmits of a repository report the same context on the same branch
+func validateContexts(cfg *config.Config) []error {
+	var errs []error
+	for _, repo := range sets.StringKeySet(cfg.Presubmits).List() {
+		presubmits := cfg.Presubmits[repo]
+		for i := range presubmits {
+			a := &presubmits[i]
+			if a.Context == "" || a.SkipReport {
+				continue
+			}
+			for j := i + 1; j < len(presubmits); j++ {
+				b := &presubmits[j]
+				if b.Context != a.Context || b.SkipReport || !a.Brancher.Intersects(b.Brancher) {
+					continue
+				}
+				errs = append(errs, fmt.Errorf("presubmits %s and %s of %s both report the context %q", a.Name, b.Name, repo, a.Context))
+			}
+		}
+	}
+	return errs
+}
+
+// validatePlugins checks that all the enabled plugins are known
+func validatePlugins(pluginCfg *plugins.Configuration, knownPlugins []string) []error {
+	known := map[string]bool{}
+	for _, name := range knownPlugins {
+		known[name] = true
+	}
+	var errs []error
+	for _, repo := range sets.StringKeySet(pluginCfg.Plugins).List() {
+		for _, name := range pluginCfg.Plugins[repo] {
+			if !known[strings.TrimPrefix(name, plugins.DisabledPluginPrefix)] {
+				errs = append(errs, fmt.Errorf("unknown plugin %s enabled for %s", name, repo))
+			}
+		}
+	}
+	return errs
+}
+
+// validateKeeperQueries checks that each keeper query matches at least one org or repository Lighthouse is configured for
+func validateKeeperQueries(cfg *config.Config, pluginCfg *plugins.Configuration) []error {
+	configured := map[string]bool{}
+	for repo := range cfg.Presubmits {
+		configured[repo] = true
+	}
+	for repo := range cfg.Postsubmits {
+		configured[repo] = true
+	}
+	if pluginCfg != nil {
+		for repo := range pluginCfg.Plugins {
+			configured[repo] = true
+		}
+		for repo := range pluginCfg.ExternalPlugins {
+			configured[repo] = true
+		}
+	}
+	isConfigured := func(orgOrRepo string) bool {
+		if configured[orgOrRepo] {
+			return true
+		}
+		org := strings.Split(orgOrRepo, "/")[0]
+		if configured[org] {
+			return true
+		}
+		if !strings.Contains(orgOrRepo, "/") {
+			for key := range configured {
+				if strings.HasPrefix(key, org+"/") {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	var errs []error
+	for i := range cfg.Keeper.Queries {
+		q := &cfg.Keeper.Queries[i]
+		if !orphanQuery(q, isConfigured) {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("keeper query %d (%s) does not match any org or repository with jobs or plugins", i, q.Query()))
+	}
+	return errs
+}
+
+func orphanQuery(q *keeper.Query, isConfigured func(string) bool) bool {
+	for _, org := range q.Orgs {
+		if isConfigured(org) {
+			return false
+		}
+	}
+	for _, repo := range q.Repos {
+		if isConfigured(repo) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package configcheck_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/configcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var knownPlugins = []string{"approve", "trigger"}
+
+func load(t *testing.T, dir string) configcheck.Behavior {
+	cfg, pluginCfg, err := configcheck.Load(filepath.Join("test_data", dir, "config.yaml"), "", filepath.Join("test_data", dir, "plugins.yaml"))
+	require.NoError(t, err, "failed to load %s", dir)
+	return configcheck.Describe(cfg, pluginCfg)
+}
+
+func TestValidate(t *testing.T) {
+	cfg, pluginCfg, err := configcheck.Load(filepath.Join("test_data", "base", "config.yaml"), "", filepath.Join("test_data", "base", "plugins.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, configcheck.Validate(cfg, pluginCfg, knownPlugins), "base config should be valid")
+
+	cfg, pluginCfg, err = configcheck.Load(filepath.Join("test_data", "changed", "config.yaml"), "", filepath.Join("test_data", "changed", "plugins.yaml"))
+	require.NoError(t, err)
+	var messages []string
+	for _, err := range configcheck.Validate(cfg, pluginCfg, knownPlugins) {
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(t, []string{
+		`presubmits lint and lint-again of myorg/myrepo both report the context "lint"`,
+		"unknown plugin not-a-plugin enabled for myorg/myrepo",
+		`keeper query 1 (is:pr state:open repo:"otherorg/unknown" label:"approved") does not match any org or repository with jobs or plugins`,
+	}, messages)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, _, err := configcheck.Load(filepath.Join("test_data", "missing", "config.yaml"), "", "")
+	assert.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	lines := configcheck.Diff(load(t, "base"), load(t, "changed"))
+	assert.Equal(t, []string{
+		`+ keeper query is:pr state:open repo:"otherorg/unknown" label:"approved": enabled`,
+		`~ merge method myorg/myrepo: merge => squash`,
+		`~ plugins myorg/myrepo: approve, trigger => approve, not-a-plugin, trigger`,
+		`~ presubmit myorg/myrepo lint: context="lint" always_run trigger="(?m)^/test( | .* )lint,?($|\\s.*)" rerun_command="/test lint" agent="tekton" => context="lint" always_run optional trigger="(?m)^/test( | .* )lint,?($|\\s.*)" rerun_command="/test lint" agent="tekton"`,
+		`+ presubmit myorg/myrepo lint-again: context="lint" trigger="(?m)^/lint-again" rerun_command="/lint-again" agent="tekton"`,
+		`- presubmit myorg/myrepo unit: context="unit" always_run trigger="(?m)^/test( | .* )unit,?($|\\s.*)" rerun_command="/test unit" agent="tekton"`,
+	}, lines)
+
+	assert.Empty(t, configcheck.Diff(load(t, "base"), load(t, "base")), "no changes expected")
+}
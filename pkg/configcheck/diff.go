@@ -0,0 +1,134 @@
+package configcheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Behavior is the effective behavior of a configuration, a description of each job, enabled plugins,
+// keeper query and merge method keyed by what it applies to such as "presubmit myorg/myrepo lint".
+type Behavior map[string]string
+
+// Describe returns the effective behavior of the configuration
+func Describe(cfg *config.Config, pluginCfg *plugins.Configuration) Behavior {
+	b := Behavior{}
+	for repo, presubmits := range cfg.Presubmits {
+		for i := range presubmits {
+			b["presubmit "+repo+" "+presubmits[i].Name] = describePresubmit(&presubmits[i])
+		}
+	}
+	for repo, postsubmits := range cfg.Postsubmits {
+		for i := range postsubmits {
+			b["postsubmit "+repo+" "+postsubmits[i].Name] = describePostsubmit(&postsubmits[i])
+		}
+	}
+	for i := range cfg.Periodics {
+		p := &cfg.Periodics[i]
+		b["periodic "+p.Name] = joinFields(field("cron", p.Cron), field("agent", p.Agent))
+	}
+	for i := range cfg.Keeper.Queries {
+		b["keeper query "+cfg.Keeper.Queries[i].Query()] = "enabled"
+	}
+	for orgOrRepo, method := range cfg.Keeper.MergeType {
+		b["merge method "+orgOrRepo] = string(method)
+	}
+	if pluginCfg != nil {
+		for orgOrRepo, names := range pluginCfg.Plugins {
+			b["plugins "+orgOrRepo] = strings.Join(sets.NewString(names...).List(), ", ")
+		}
+		for orgOrRepo, externals := range pluginCfg.ExternalPlugins {
+			names := sets.NewString()
+			for _, e := range externals {
+				names.Insert(e.Name)
+			}
+			b["external plugins "+orgOrRepo] = strings.Join(names.List(), ", ")
+		}
+	}
+	return b
+}
+
+// Diff returns a human readable line for each behavior added (+), removed (-) or changed (~) between the
+// two behaviors, sorted by what they apply to.
+func Diff(before, after Behavior) []string {
+	keys := sets.StringKeySet(before).Union(sets.StringKeySet(after)).List()
+	var lines []string
+	for _, key := range keys {
+		was, existed := before[key]
+		is, exists := after[key]
+		switch {
+		case !existed:
+			lines = append(lines, fmt.Sprintf("+ %s: %s", key, is))
+		case !exists:
+			lines = append(lines, fmt.Sprintf("- %s: %s", key, was))
+		case was != is:
+			lines = append(lines, fmt.Sprintf("~ %s: %s => %s", key, was, is))
+		}
+	}
+	return lines
+}
+
+func describePresubmit(p *job.Presubmit) string {
+	return joinFields(
+		field("context", p.Context),
+		boolField("always_run", p.AlwaysRun),
+		boolField("optional", p.Optional),
+		boolField("skip_report", p.SkipReport),
+		field("trigger", p.Trigger),
+		field("rerun_command", p.RerunCommand),
+		field("run_if_changed", p.RunIfChanged),
+		field("skip_if_only_changed", p.SkipIfOnlyChanged),
+		listField("branches", p.Branches),
+		listField("skip_branches", p.SkipBranches),
+		field("agent", p.Agent),
+	)
+}
+
+func describePostsubmit(p *job.Postsubmit) string {
+	return joinFields(
+		field("context", p.Context),
+		boolField("skip_report", p.SkipReport),
+		field("run_if_changed", p.RunIfChanged),
+		listField("branches", p.Branches),
+		listField("skip_branches", p.SkipBranches),
+		field("agent", p.Agent),
+	)
+}
+
+func field(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s=%q", name, value)
+}
+
+func boolField(name string, value bool) string {
+	if !value {
+		return ""
+	}
+	return name
+}
+
+func listField(name string, values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return fmt.Sprintf("%s=[%s]", name, strings.Join(sorted, ","))
+}
+
+func joinFields(fields ...string) string {
+	var nonEmpty []string
+	for _, f := range fields {
+		if f != "" {
+			nonEmpty = append(nonEmpty, f)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
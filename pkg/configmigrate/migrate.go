@@ -0,0 +1,189 @@
+// Package configmigrate converts the configuration of Prow, its config.yaml, job configuration files and plugins.yaml,
+// into the equivalent Lighthouse configuration, flagging what Lighthouse does not support.
+package configmigrate
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/pkg/errors"
+	tektonpod "github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// kubernetesAgent is the agent of the Prow jobs running a pod
+	kubernetesAgent = "kubernetes"
+
+	// taskName is the name of the task of the pipelines the pods of the Prow jobs are converted into
+	taskName = "run"
+)
+
+// Config converts a Prow config.yaml, which may hold jobs, into a Lighthouse config.yaml, returning the warnings
+// about the fields which could not be migrated
+func Config(data []byte) ([]byte, []string, error) {
+	return convert(data, reflect.TypeOf(config.Config{}), convertJobs)
+}
+
+// JobConfig converts a Prow job configuration file, returning the warnings about the fields which could not be migrated
+func JobConfig(data []byte) ([]byte, []string, error) {
+	return convert(data, reflect.TypeOf(job.Config{}), convertJobs)
+}
+
+// Plugins converts a Prow plugins.yaml, returning the warnings about the fields and the plugins which could not be
+// migrated. The known plugins are the names of the plugins linked into Lighthouse, the others being dropped.
+func Plugins(data []byte, knownPlugins []string) ([]byte, []string, error) {
+	known := sets.NewString(knownPlugins...)
+	return convert(data, reflect.TypeOf(plugins.Configuration{}), func(m map[string]interface{}, w *warnings) {
+		convertPlugins(m, known, w)
+	})
+}
+
+// convert decodes the YAML, applies the conversion then drops the fields the Lighthouse type has not
+func convert(data []byte, t reflect.Type, conversion func(map[string]interface{}, *warnings)) ([]byte, []string, error) {
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse the Prow configuration")
+	}
+	w := &warnings{}
+	conversion(m, w)
+	answer, err := yaml.Marshal(prune(m, t, "", w))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal the Lighthouse configuration")
+	}
+	sort.Strings(*w)
+	return answer, *w, nil
+}
+
+// convertJobs converts the pods of the Prow jobs into Tekton pipelines
+func convertJobs(m map[string]interface{}, w *warnings) {
+	for _, kind := range []string{"presubmits", "postsubmits"} {
+		repos, _ := m[kind].(map[string]interface{})
+		for _, repo := range sortedKeys(repos) {
+			jobs, _ := repos[repo].([]interface{})
+			for i, j := range jobs {
+				if jm, ok := j.(map[string]interface{}); ok {
+					convertJob(jm, fmt.Sprintf("%s.%s[%d]", kind, repo, i), w)
+				}
+			}
+		}
+	}
+	periodics, _ := m["periodics"].([]interface{})
+	for i, j := range periodics {
+		if jm, ok := j.(map[string]interface{}); ok {
+			path := fmt.Sprintf("periodics[%d]", i)
+			convertInterval(jm, path, w)
+			convertJob(jm, path, w)
+		}
+	}
+}
+
+// convertJob converts the pod of a job of the kubernetes agent, Prow's default, into a Tekton pipeline running a step
+// per container. The fields of the pod which are not supported by Tekton are dropped, the volumes being those of the
+// task and the other fields those of its pod template.
+func convertJob(m map[string]interface{}, path string, w *warnings) {
+	agent, _ := m["agent"].(string)
+	spec, ok := m["spec"].(map[string]interface{})
+	if !ok || (agent != "" && agent != kubernetesAgent) {
+		return
+	}
+	delete(m, "spec")
+	m["agent"] = job.TektonPipelineAgent
+	w.add(path, "the pod of the %s agent is converted into a Tekton pipeline running a step per container", kubernetesAgent)
+
+	taskSpec := map[string]interface{}{}
+	runSpec := map[string]interface{}{
+		"pipelineSpec": map[string]interface{}{
+			"tasks": []interface{}{
+				map[string]interface{}{"name": taskName, "taskSpec": taskSpec},
+			},
+		},
+	}
+	podTemplate := map[string]interface{}{}
+	podTemplateFields := jsonFields(reflect.TypeOf(tektonpod.Template{}))
+	for _, k := range sortedKeys(spec) {
+		switch k {
+		case "containers":
+			taskSpec["steps"] = spec[k]
+		case "volumes":
+			taskSpec["volumes"] = spec[k]
+		case "serviceAccountName":
+			runSpec["serviceAccountName"] = spec[k]
+		default:
+			if _, ok := podTemplateFields[k]; !ok {
+				w.unsupported(path+".spec."+k, k)
+				continue
+			}
+			podTemplate[k] = spec[k]
+		}
+	}
+	if len(podTemplate) > 0 {
+		runSpec["podTemplate"] = podTemplate
+	}
+	m["pipeline_run_spec"] = runSpec
+}
+
+// convertInterval converts the interval of a periodic into the equivalent cron schedule, if any
+func convertInterval(m map[string]interface{}, path string, w *warnings) {
+	interval, _ := m["interval"].(string)
+	if interval == "" || m["cron"] != nil {
+		return
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil || d <= 0 || d%time.Minute != 0 {
+		return
+	}
+	minutes := int(d / time.Minute)
+	hours := minutes / 60
+	var cron string
+	switch {
+	case minutes < 60 && 60%minutes == 0:
+		cron = fmt.Sprintf("*/%d * * * *", minutes)
+	case minutes%60 == 0 && hours == 24:
+		cron = "0 0 * * *"
+	case minutes%60 == 0 && hours < 24 && 24%hours == 0:
+		cron = fmt.Sprintf("0 */%d * * *", hours)
+	default:
+		return
+	}
+	delete(m, "interval")
+	m["cron"] = cron
+	w.add(path, "the interval %s is converted into the cron schedule %q", interval, cron)
+}
+
+// convertPlugins converts the plugins enabled for an org or a repository, which Prow also accepts as an object listing
+// them, dropping the plugins Lighthouse does not know
+func convertPlugins(m map[string]interface{}, known sets.String, w *warnings) {
+	enabled, _ := m["plugins"].(map[string]interface{})
+	for _, repo := range sortedKeys(enabled) {
+		path := "plugins." + repo
+		names, ok := enabled[repo].([]interface{})
+		if o, isObject := enabled[repo].(map[string]interface{}); isObject {
+			names, ok = o["plugins"].([]interface{})
+			for _, k := range sortedKeys(o) {
+				if k != "plugins" {
+					w.unsupported(path+"."+k, k)
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		answer := []interface{}{}
+		for _, n := range names {
+			name, _ := n.(string)
+			if !known.Has(name) {
+				w.add(path, "the plugin %v is not supported by Lighthouse", n)
+				continue
+			}
+			answer = append(answer, name)
+		}
+		enabled[repo] = answer
+	}
+}
@@ -0,0 +1,107 @@
+package configmigrate_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/configmigrate"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestConfig(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "config.yaml"))
+	require.NoError(t, err)
+
+	converted, warnings, err := configmigrate.Config(data)
+	require.NoError(t, err)
+	cfg, err := config.LoadYAMLConfig(converted)
+	require.NoError(t, err)
+
+	assert.Equal(t, "default", cfg.LighthouseJobNamespace)
+	require.Len(t, cfg.Keeper.Queries, 1)
+	assert.Equal(t, []string{"lgtm", "approved"}, cfg.Keeper.Queries[0].Labels)
+
+	presubmits := cfg.Presubmits["myorg/myrepo"]
+	require.Len(t, presubmits, 2)
+	unit := presubmits[0]
+	assert.Equal(t, job.TektonPipelineAgent, unit.Agent)
+	assert.Nil(t, unit.Spec)
+	assert.False(t, unit.Decorate)
+	require.NotNil(t, unit.PipelineRunSpec)
+	assert.Equal(t, "tests", unit.PipelineRunSpec.ServiceAccountName)
+	require.NotNil(t, unit.PipelineRunSpec.PodTemplate)
+	assert.Equal(t, map[string]string{"pool": "ci"}, unit.PipelineRunSpec.PodTemplate.NodeSelector)
+	require.NotNil(t, unit.PipelineRunSpec.PipelineSpec)
+	require.Len(t, unit.PipelineRunSpec.PipelineSpec.Tasks, 1)
+	steps := unit.PipelineRunSpec.PipelineSpec.Tasks[0].TaskSpec.Steps
+	require.Len(t, steps, 1)
+	assert.Equal(t, "golang:1.15", steps[0].Image)
+	assert.Equal(t, []string{"make", "test"}, steps[0].Command)
+	assert.Equal(t, job.JenkinsAgent, presubmits[1].Agent, "the jobs of the other agents should be kept")
+
+	require.Len(t, cfg.Periodics, 1)
+	assert.Equal(t, "0 0 * * *", cfg.Periodics[0].Cron)
+
+	assert.Equal(t, []string{
+		"deck: not supported, Lighthouse has no Deck, see the dashboard of the pipeline engine instead",
+		`periodics[0]: the interval 24h is converted into the cron schedule "0 0 * * *"`,
+		"periodics[0]: the pod of the kubernetes agent is converted into a Tekton pipeline running a step per container",
+		"plank.default_decoration_configs: not supported, the pod utilities are not supported, the steps must clone the repository themselves",
+		"plank.job_url_template: not supported by Lighthouse",
+		"presubmits.myorg/myrepo[0].decorate: not supported, the pod utilities are not supported, the steps must clone the repository themselves",
+		"presubmits.myorg/myrepo[0].spec.hostPID: not supported by Lighthouse",
+		"presubmits.myorg/myrepo[0]: the pod of the kubernetes agent is converted into a Tekton pipeline running a step per container",
+		"sinker: not supported, the LighthouseJobs are garbage collected by the gc-jobs cron job",
+	}, warnings)
+	assert.NotContains(t, string(converted), "plank", "the objects left empty should be dropped")
+}
+
+func TestJobConfig(t *testing.T) {
+	converted, warnings, err := configmigrate.JobConfig([]byte(`
+periodics:
+- name: hourly
+  interval: 1h
+  agent: jenkins
+- name: odd
+  interval: 7h
+  agent: jenkins
+`))
+	require.NoError(t, err)
+	jc := job.Config{}
+	require.NoError(t, yaml.Unmarshal(converted, &jc))
+	require.Len(t, jc.Periodics, 2)
+	assert.Equal(t, "0 */1 * * *", jc.Periodics[0].Cron)
+	assert.Empty(t, jc.Periodics[1].Cron, "an interval without an equivalent cron schedule cannot be converted")
+	assert.Equal(t, []string{
+		`periodics[0]: the interval 1h is converted into the cron schedule "0 */1 * * *"`,
+		"periodics[1].interval: not supported, periodics only run on a cron schedule",
+	}, warnings)
+}
+
+func TestPlugins(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "plugins.yaml"))
+	require.NoError(t, err)
+
+	converted, warnings, err := configmigrate.Plugins(data, []string{"approve", "hold", "lgtm"})
+	require.NoError(t, err)
+	pluginCfg := &plugins.Configuration{}
+	require.NoError(t, yaml.UnmarshalStrict(converted, pluginCfg))
+
+	assert.Equal(t, map[string][]string{
+		"myorg":        {"approve", "lgtm"},
+		"myorg/myrepo": {"hold"},
+	}, pluginCfg.Plugins)
+	require.Len(t, pluginCfg.Approve, 1)
+	assert.True(t, pluginCfg.Approve[0].LgtmActsAsApprove)
+	assert.Equal(t, []string{
+		"plugins.myorg.excluded_repos: not supported by Lighthouse",
+		"plugins.myorg: the plugin buildifier is not supported by Lighthouse",
+		"slack: not supported by Lighthouse",
+	}, warnings)
+}
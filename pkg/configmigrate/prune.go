@@ -0,0 +1,141 @@
+package configmigrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// hints explain why the Prow fields of the given name are not supported
+var hints = map[string]string{
+	"decorate":                   "the pod utilities are not supported, the steps must clone the repository themselves",
+	"decoration_config":          "the pod utilities are not supported, the steps must clone the repository themselves",
+	"default_decoration_configs": "the pod utilities are not supported, the steps must clone the repository themselves",
+	"extra_refs":                 "only the repository of the job is cloned",
+	"interval":                   "periodics only run on a cron schedule",
+	"reporter_config":            "the jobs report to the git provider and the chat notifications only",
+	"deck":                       "Lighthouse has no Deck, see the dashboard of the pipeline engine instead",
+	"sinker":                     "the LighthouseJobs are garbage collected by the gc-jobs cron job",
+}
+
+// ignored are the fields Lighthouse accepts for compatibility with Prow but ignores
+var ignored = map[string]bool{
+	"clone_depth":     true,
+	"decorate":        true,
+	"skip_submodules": true,
+}
+
+// warnings collects the fields of the Prow configuration which cannot be migrated
+type warnings []string
+
+func (w *warnings) add(path, format string, args ...interface{}) {
+	*w = append(*w, fmt.Sprintf("%s: %s", strings.TrimPrefix(path, "."), fmt.Sprintf(format, args...)))
+}
+
+func (w *warnings) unsupported(path, name string) {
+	if hint, ok := hints[name]; ok {
+		w.add(path, "not supported, %s", hint)
+		return
+	}
+	w.add(path, "not supported by Lighthouse")
+}
+
+// prune returns the decoded YAML value without the fields the given type has not, recording a warning for each. The
+// objects left empty once pruned are dropped, nil being returned for them.
+func prune(value interface{}, t reflect.Type, path string, w *warnings) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return value
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		fields := jsonFields(t)
+		answer := map[string]interface{}{}
+		for _, k := range sortedKeys(m) {
+			ft, ok := fields[k]
+			if !ok || ignored[k] {
+				w.unsupported(path+"."+k, k)
+				continue
+			}
+			if v := prune(m[k], ft, path+"."+k, w); v != nil || m[k] == nil {
+				answer[k] = v
+			}
+		}
+		if len(answer) == 0 && len(m) > 0 {
+			return nil
+		}
+		return answer
+	case reflect.Slice, reflect.Array:
+		l, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+		answer := make([]interface{}, 0, len(l))
+		for i, v := range l {
+			answer = append(answer, prune(v, t.Elem(), fmt.Sprintf("%s[%d]", path, i), w))
+		}
+		return answer
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		answer := map[string]interface{}{}
+		for _, k := range sortedKeys(m) {
+			answer[k] = prune(m[k], t.Elem(), path+"."+k, w)
+		}
+		return answer
+	}
+	return value
+}
+
+// jsonFields returns the types of the fields of the struct keyed by their JSON name, the fields of the inlined
+// structs included
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	answer := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for k, v := range jsonFields(ft) {
+				answer[k] = v
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		answer[name] = f.Type
+	}
+	return answer
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var answer []string
+	for k := range m {
+		answer = append(answer, k)
+	}
+	sort.Strings(answer)
+	return answer
+}
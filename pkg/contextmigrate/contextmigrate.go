@@ -0,0 +1,141 @@
+// Package contextmigrate migrates the commit statuses of the open pull requests when the contexts of presubmits are
+// renamed. The latest status of each previous context is copied to the new context, unless it was already reported,
+// and the previous context is marked as superseded with a success status, so that the pull requests are not blocked
+// on a context which will never be reported again.
+package contextmigrate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// pageSize is the number of open pull requests listed per page
+const pageSize = 100
+
+type scmProviderClient interface {
+	ListAllPullRequestsForFullNameRepo(string, scm.PullRequestListOptions) ([]*scm.PullRequest, error)
+	GetCombinedStatus(string, string, string) (*scm.CombinedStatus, error)
+	CreateStatus(string, string, string, *scm.StatusInput) (*scm.Status, error)
+}
+
+// Rename is a renamed context of a presubmit
+type Rename struct {
+	From         string
+	To           string
+	RerunCommand string
+}
+
+// Renames returns the renamed contexts of the presubmits which could run on the given branch
+func Renames(presubmits []job.Presubmit, branch string) []Rename {
+	var renames []Rename
+	for _, p := range presubmits {
+		if p.SkipReport || !p.CouldRun(branch) {
+			continue
+		}
+		for _, c := range p.PreviousContexts {
+			renames = append(renames, Rename{From: c, To: p.Context, RerunCommand: p.RerunCommand})
+		}
+	}
+	return renames
+}
+
+// SupersededDescription returns the description of the success status marking a previous context as superseded
+func SupersededDescription(to string) string {
+	return fmt.Sprintf("Superseded by %s", to)
+}
+
+// Migrator migrates the statuses of the renamed contexts of the open pull requests of the repositories
+type Migrator struct {
+	spc    scmProviderClient
+	dryRun bool
+	log    *logrus.Entry
+}
+
+// NewMigrator creates a migrator, which only logs the statuses it would create in dry run mode
+func NewMigrator(spc scmProviderClient, dryRun bool, log *logrus.Entry) *Migrator {
+	if log == nil {
+		log = logrus.WithField("client", "contextmigrate")
+	}
+	return &Migrator{spc: spc, dryRun: dryRun, log: log}
+}
+
+// Migrate migrates the statuses of the renamed contexts of the presubmits of the repository on its open pull
+// requests, returning the number of migrated contexts. It carries on with the next pull requests on errors.
+func (m *Migrator) Migrate(org, repo string, presubmits []job.Presubmit) (int, error) {
+	prs, err := m.spc.ListAllPullRequestsForFullNameRepo(org+"/"+repo, scm.PullRequestListOptions{Page: 1, Size: pageSize, Open: true})
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to list the open pull requests of %s/%s", org, repo)
+	}
+	migrated := 0
+	var errs []string
+	for _, pr := range prs {
+		if pr.Closed || pr.Merged {
+			continue
+		}
+		renames := Renames(presubmits, pr.Base.Ref)
+		if len(renames) == 0 {
+			continue
+		}
+		n, err := m.migratePullRequest(org, repo, pr, renames)
+		migrated += n
+		if err != nil {
+			m.log.WithError(err).Errorf("failed to migrate the contexts of %s/%s#%d", org, repo, pr.Number)
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return migrated, fmt.Errorf("failed to migrate the contexts of %d pull requests of %s/%s: %s", len(errs), org, repo, strings.Join(errs, "; "))
+	}
+	return migrated, nil
+}
+
+func (m *Migrator) migratePullRequest(org, repo string, pr *scm.PullRequest, renames []Rename) (int, error) {
+	sha := pr.Head.Sha
+	combined, err := m.spc.GetCombinedStatus(org, repo, sha)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get the statuses of %s", sha)
+	}
+	latest := map[string]*scm.Status{}
+	for _, s := range combined.Statuses {
+		if _, ok := latest[s.Label]; !ok {
+			latest[s.Label] = s
+		}
+	}
+
+	migrated := 0
+	for _, r := range renames {
+		old := latest[r.From]
+		superseded := SupersededDescription(r.To)
+		if old == nil || (old.State == scm.StateSuccess && old.Desc == superseded) {
+			continue
+		}
+		log := m.log.WithFields(logrus.Fields{"org": org, "repo": repo, "pr": pr.Number, "from": r.From, "to": r.To})
+		if latest[r.To] == nil {
+			status := &scm.StatusInput{Label: r.To, State: old.State, Desc: old.Desc, Target: old.Target}
+			if old.State != scm.StateSuccess && old.State != scm.StateFailure && old.State != scm.StateError {
+				// a running job still reports its previous context, it must be rerun to report the new one
+				status.State = scm.StatePending
+				status.Desc = fmt.Sprintf("Context renamed from %s, comment %s to run it", r.From, r.RerunCommand)
+			}
+			log.Infof("Reporting the %s state of %s to %s", status.State, r.From, r.To)
+			if !m.dryRun {
+				if _, err := m.spc.CreateStatus(org, repo, sha, status); err != nil {
+					return migrated, errors.Wrapf(err, "failed to report the %s context", r.To)
+				}
+			}
+		}
+		log.Infof("Marking %s as superseded", r.From)
+		if !m.dryRun {
+			if _, err := m.spc.CreateStatus(org, repo, sha, &scm.StatusInput{Label: r.From, State: scm.StateSuccess, Desc: superseded}); err != nil {
+				return migrated, errors.Wrapf(err, "failed to mark the %s context as superseded", r.From)
+			}
+		}
+		migrated++
+	}
+	return migrated, nil
+}
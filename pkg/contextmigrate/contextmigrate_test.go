@@ -0,0 +1,110 @@
+package contextmigrate
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	prs      []*scm.PullRequest
+	statuses map[string][]*scm.Status
+	created  []string
+}
+
+func (f *fakeClient) ListAllPullRequestsForFullNameRepo(string, scm.PullRequestListOptions) ([]*scm.PullRequest, error) {
+	return f.prs, nil
+}
+
+func (f *fakeClient) GetCombinedStatus(_, _, ref string) (*scm.CombinedStatus, error) {
+	return &scm.CombinedStatus{Sha: ref, Statuses: f.statuses[ref]}, nil
+}
+
+func (f *fakeClient) CreateStatus(_, _, ref string, s *scm.StatusInput) (*scm.Status, error) {
+	f.created = append(f.created, fmt.Sprintf("%s %s %s: %s", ref, s.Label, s.State, s.Desc))
+	return &scm.Status{}, nil
+}
+
+func pr(number int, branch, sha string) *scm.PullRequest {
+	return &scm.PullRequest{Number: number, Base: scm.PullRequestBranch{Ref: branch}, Head: scm.PullRequestBranch{Sha: sha}}
+}
+
+func TestMigrate(t *testing.T) {
+	presubmits := []job.Presubmit{
+		{
+			Reporter:         job.Reporter{Context: "ci/unit"},
+			RerunCommand:     "/test unit",
+			PreviousContexts: []string{"unit"},
+		},
+		{
+			Brancher:         job.Brancher{Branches: []string{"release"}},
+			Reporter:         job.Reporter{Context: "ci/e2e"},
+			RerunCommand:     "/test e2e",
+			PreviousContexts: []string{"e2e"},
+		},
+		{
+			Reporter: job.Reporter{Context: "lint"},
+		},
+	}
+	spc := &fakeClient{
+		prs: []*scm.PullRequest{
+			pr(1, "master", "failed"),
+			pr(2, "master", "running"),
+			pr(3, "master", "both"),
+			pr(4, "master", "migrated"),
+			pr(5, "master", "unrelated"),
+		},
+		statuses: map[string][]*scm.Status{
+			"failed": {
+				{Label: "unit", State: scm.StateFailure, Desc: "Tests failed", Target: "https://dashboard/1"},
+				{Label: "unit", State: scm.StatePending, Desc: "Running"},
+				{Label: "e2e", State: scm.StateFailure, Desc: "Not on master"},
+			},
+			"running": {
+				{Label: "unit", State: scm.StatePending, Desc: "Running"},
+			},
+			"both": {
+				{Label: "ci/unit", State: scm.StateSuccess},
+				{Label: "unit", State: scm.StateFailure},
+			},
+			"migrated": {
+				{Label: "unit", State: scm.StateSuccess, Desc: "Superseded by ci/unit"},
+			},
+			"unrelated": {
+				{Label: "lint", State: scm.StateFailure},
+			},
+		},
+	}
+
+	migrated, err := NewMigrator(spc, false, nil).Migrate("org", "repo", presubmits)
+	require.NoError(t, err)
+	assert.Equal(t, 3, migrated)
+	assert.Equal(t, []string{
+		"failed ci/unit failure: Tests failed",
+		"failed unit success: Superseded by ci/unit",
+		"running ci/unit pending: Context renamed from unit, comment /test unit to run it",
+		"running unit success: Superseded by ci/unit",
+		"both unit success: Superseded by ci/unit",
+	}, spc.created)
+
+	spc.created = nil
+	migrated, err = NewMigrator(spc, true, nil).Migrate("org", "repo", presubmits)
+	require.NoError(t, err)
+	assert.Equal(t, 3, migrated)
+	assert.Empty(t, spc.created)
+}
+
+func TestRenames(t *testing.T) {
+	presubmits := []job.Presubmit{
+		{Reporter: job.Reporter{Context: "ci/unit"}, RerunCommand: "/test unit", PreviousContexts: []string{"unit", "test"}},
+		{Reporter: job.Reporter{Context: "ci/lint", SkipReport: true}, PreviousContexts: []string{"lint"}},
+	}
+	assert.Equal(t, []Rename{
+		{From: "unit", To: "ci/unit", RerunCommand: "/test unit"},
+		{From: "test", To: "ci/unit", RerunCommand: "/test unit"},
+	}, Renames(presubmits, "master"))
+}
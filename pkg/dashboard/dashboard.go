@@ -0,0 +1,318 @@
+// Package dashboard serves a read-only JSON API of the recent LighthouseJobs, intended as the backend of a
+// web dashboard in the spirit of Prow's Deck.
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	clientset "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// Path is the path the jobs are listed on, the detail of a job being served on Path/<name>
+	Path = "/api/jobs"
+
+	// DefaultPageSize is the number of jobs per page when the per_page query parameter is not set
+	DefaultPageSize = 50
+	// MaxPageSize is the maximum number of jobs per page
+	MaxPageSize = 500
+)
+
+// Job is the summary of a LighthouseJob in the list of jobs
+type Job struct {
+	// Name is the name of the LighthouseJob resource
+	Name string `json:"name"`
+	// Job is the name of the job in the configuration
+	Job string `json:"job"`
+	// Type is the type of the job, presubmit, postsubmit, periodic or batch
+	Type job.PipelineKind `json:"type"`
+	// Agent is the engine running the job
+	Agent string `json:"agent,omitempty"`
+	// Context is the status context of the job
+	Context string `json:"context,omitempty"`
+	// Repo is the full name of the repository of the job, empty for periodic jobs without refs
+	Repo string `json:"repo,omitempty"`
+	// BaseRef is the branch the job runs against
+	BaseRef string `json:"base_ref,omitempty"`
+	// Pull is the number of the pull request of a presubmit, 0 otherwise
+	Pull int `json:"pull,omitempty"`
+	// Author is the author of the pull request of a presubmit
+	Author string `json:"author,omitempty"`
+	// SHA is the head commit of the pull request, or the base commit otherwise
+	SHA string `json:"sha,omitempty"`
+	// State is the state of the job
+	State v1alpha1.PipelineState `json:"state"`
+	// Description is the description of the state of the job
+	Description string `json:"description,omitempty"`
+	// ReportURL is the link to the pipeline of the job
+	ReportURL string `json:"report_url,omitempty"`
+	// ArtifactsURL is the link to the artifacts of the job
+	ArtifactsURL string `json:"artifacts_url,omitempty"`
+	// StartTime is when the job started
+	StartTime *time.Time `json:"start_time,omitempty"`
+	// CompletionTime is when the job completed
+	CompletionTime *time.Time `json:"completion_time,omitempty"`
+	// Duration is the duration of the job in seconds, up to now if it is still running
+	Duration float64 `json:"duration_seconds,omitempty"`
+}
+
+// JobDetail is the detail of a single LighthouseJob
+type JobDetail struct {
+	Job
+	// CreationTime is when the LighthouseJob was created
+	CreationTime time.Time `json:"creation_time"`
+	// QueuedDuration is the time in seconds the job waited before starting
+	QueuedDuration float64 `json:"queued_duration_seconds,omitempty"`
+	// Refs is the code under test
+	Refs *v1alpha1.Refs `json:"refs,omitempty"`
+	// ExtraRefs are the auxiliary repositories cloned by the job
+	ExtraRefs []v1alpha1.Refs `json:"extra_refs,omitempty"`
+	// RerunCommand is the command re-running the job on its pull request
+	RerunCommand string `json:"rerun_command,omitempty"`
+	// Activity is the pipeline activity of the job, with its stages and steps
+	Activity *v1alpha1.ActivityRecord `json:"activity,omitempty"`
+	// TestFailures are the failed tests reported by the job
+	TestFailures []v1alpha1.TestFailure `json:"test_failures,omitempty"`
+}
+
+// JobList is a page of the jobs matching the filters, the most recent first
+type JobList struct {
+	// Items are the jobs of the page
+	Items []Job `json:"items"`
+	// Total is the number of jobs matching the filters
+	Total int `json:"total"`
+	// Page is the page number, starting at 1
+	Page int `json:"page"`
+	// PerPage is the number of jobs per page
+	PerPage int `json:"per_page"`
+}
+
+// Filter restricts the jobs listed
+type Filter struct {
+	// Repo is the full name of the repository of the jobs
+	Repo string
+	// Type is the type of the jobs
+	Type string
+	// State is the state of the jobs
+	State string
+	// Author is the author of the pull requests of the jobs
+	Author string
+	// Job is the name of the jobs in the configuration
+	Job string
+}
+
+// Matches returns true if the job passes the filter
+func (f *Filter) Matches(j *v1alpha1.LighthouseJob) bool {
+	if f.Type != "" && string(j.Spec.Type) != f.Type {
+		return false
+	}
+	if f.State != "" && string(j.Status.State) != f.State {
+		return false
+	}
+	if f.Job != "" && j.Spec.Job != f.Job {
+		return false
+	}
+	refs := j.Spec.Refs
+	if f.Repo != "" && (refs == nil || !strings.EqualFold(refs.Org+"/"+refs.Repo, f.Repo)) {
+		return false
+	}
+	if f.Author != "" && (refs == nil || len(refs.Pulls) == 0 || !strings.EqualFold(refs.Pulls[0].Author, f.Author)) {
+		return false
+	}
+	return true
+}
+
+// selector returns the label selector pre-filtering the jobs on the API server
+func (f *Filter) selector() string {
+	set := labels.Set{}
+	if f.Type != "" {
+		set[job.LighthouseJobTypeLabel] = f.Type
+	}
+	// the org label is lowercased when the jobs are created but the repo label is not, the repository is
+	// therefore matched case-insensitively by Matches only
+	if parts := strings.Split(f.Repo, "/"); len(parts) == 2 {
+		set[util.OrgLabel] = strings.ToLower(parts[0])
+	}
+	return set.String()
+}
+
+// Handler serves the jobs of a namespace
+type Handler struct {
+	lhClient  clientset.Interface
+	namespace string
+	now       func() time.Time
+	logger    *logrus.Entry
+}
+
+// NewHandler creates a handler serving the jobs of the namespace
+func NewHandler(lhClient clientset.Interface, namespace string) *Handler {
+	return &Handler{
+		lhClient:  lhClient,
+		namespace: namespace,
+		now:       time.Now,
+		logger:    logrus.WithField("handler", "dashboard"),
+	}
+}
+
+// ServeHTTP serves the list of jobs on Path, filtered by the repo, type, state, author and job query
+// parameters and paginated by the page and per_page ones, and the detail of a job on Path/<name>
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "405 Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, Path), "/")
+	if name == "" {
+		h.serveList(w, r)
+		return
+	}
+	if strings.Contains(name, "/") {
+		http.Error(w, fmt.Sprintf("404 Not Found: %s", r.URL.Path), http.StatusNotFound)
+		return
+	}
+	h.serveDetail(w, name)
+}
+
+func (h *Handler) serveList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, err := intParam(query.Get("page"), 1)
+	if err != nil || page < 1 {
+		http.Error(w, "400 Bad Request: page must be a positive integer", http.StatusBadRequest)
+		return
+	}
+	perPage, err := intParam(query.Get("per_page"), DefaultPageSize)
+	if err != nil || perPage < 1 || perPage > MaxPageSize {
+		http.Error(w, fmt.Sprintf("400 Bad Request: per_page must be an integer between 1 and %d", MaxPageSize), http.StatusBadRequest)
+		return
+	}
+	filter := &Filter{
+		Repo:   query.Get("repo"),
+		Type:   query.Get("type"),
+		State:  query.Get("state"),
+		Author: query.Get("author"),
+		Job:    query.Get("job"),
+	}
+
+	list, err := h.lhClient.LighthouseV1alpha1().LighthouseJobs(h.namespace).List(metav1.ListOptions{LabelSelector: filter.selector()})
+	if err != nil {
+		h.logger.WithError(err).Warn("failed to list the jobs")
+		http.Error(w, "500 Internal Server Error: failed to list the jobs", http.StatusInternalServerError)
+		return
+	}
+	var matching []*v1alpha1.LighthouseJob
+	for i := range list.Items {
+		if filter.Matches(&list.Items[i]) {
+			matching = append(matching, &list.Items[i])
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		return startTime(matching[j]).Before(startTime(matching[i]))
+	})
+
+	answer := &JobList{Items: []Job{}, Total: len(matching), Page: page, PerPage: perPage}
+	for i := (page - 1) * perPage; i < len(matching) && i < page*perPage; i++ {
+		answer.Items = append(answer.Items, h.summary(matching[i]))
+	}
+	writeJSON(w, answer)
+}
+
+func (h *Handler) serveDetail(w http.ResponseWriter, name string) {
+	lhjob, err := h.lhClient.LighthouseV1alpha1().LighthouseJobs(h.namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, fmt.Sprintf("404 Not Found: job %s", name), http.StatusNotFound)
+			return
+		}
+		h.logger.WithError(err).WithField("job", name).Warn("failed to get the job")
+		http.Error(w, "500 Internal Server Error: failed to get the job", http.StatusInternalServerError)
+		return
+	}
+	detail := &JobDetail{
+		Job:          h.summary(lhjob),
+		CreationTime: lhjob.CreationTimestamp.Time,
+		Refs:         lhjob.Spec.Refs,
+		ExtraRefs:    lhjob.Spec.ExtraRefs,
+		RerunCommand: lhjob.Spec.RerunCommand,
+		Activity:     lhjob.Status.Activity,
+		TestFailures: lhjob.Status.TestFailures,
+	}
+	if !lhjob.Status.StartTime.IsZero() && !lhjob.CreationTimestamp.IsZero() {
+		detail.QueuedDuration = lhjob.Status.StartTime.Sub(lhjob.CreationTimestamp.Time).Seconds()
+	}
+	writeJSON(w, detail)
+}
+
+// summary converts the LighthouseJob to its summary
+func (h *Handler) summary(lhjob *v1alpha1.LighthouseJob) Job {
+	answer := Job{
+		Name:         lhjob.Name,
+		Job:          lhjob.Spec.Job,
+		Type:         lhjob.Spec.Type,
+		Agent:        lhjob.Spec.Agent,
+		Context:      lhjob.Spec.Context,
+		State:        lhjob.Status.State,
+		Description:  lhjob.Status.Description,
+		ReportURL:    lhjob.Status.ReportURL,
+		ArtifactsURL: lhjob.Status.ArtifactsURL,
+	}
+	if refs := lhjob.Spec.Refs; refs != nil {
+		answer.Repo = refs.Org + "/" + refs.Repo
+		answer.BaseRef = refs.BaseRef
+		answer.SHA = refs.BaseSHA
+		if len(refs.Pulls) > 0 {
+			answer.Pull = refs.Pulls[0].Number
+			answer.Author = refs.Pulls[0].Author
+			answer.SHA = refs.Pulls[0].SHA
+		}
+	}
+	if !lhjob.Status.StartTime.IsZero() {
+		start := lhjob.Status.StartTime.Time
+		answer.StartTime = &start
+		end := h.now()
+		if lhjob.Status.CompletionTime != nil {
+			completion := lhjob.Status.CompletionTime.Time
+			answer.CompletionTime = &completion
+			end = completion
+		}
+		answer.Duration = end.Sub(start).Seconds()
+	}
+	return answer
+}
+
+// startTime returns when the job started, or when it was created if it has not started yet
+func startTime(lhjob *v1alpha1.LighthouseJob) time.Time {
+	if lhjob.Status.StartTime.IsZero() {
+		return lhjob.CreationTimestamp.Time
+	}
+	return lhjob.Status.StartTime.Time
+}
+
+func intParam(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("500 Internal Server Error: failed to marshal the response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,151 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const ns = "jx"
+
+var now = time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newJob(name, jobName string, kind job.PipelineKind, state v1alpha1.PipelineState, started time.Duration, refs *v1alpha1.Refs) *v1alpha1.LighthouseJob {
+	spec := v1alpha1.LighthouseJobSpec{Type: kind, Job: jobName, Agent: job.TektonPipelineAgent, Refs: refs}
+	labels, annotations := jobutil.LabelsAndAnnotationsForSpec(spec, nil, nil)
+	return &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         ns,
+			Labels:            labels,
+			Annotations:       annotations,
+			CreationTimestamp: metav1.NewTime(now.Add(-started - time.Minute)),
+		},
+		Spec: spec,
+		Status: v1alpha1.LighthouseJobStatus{
+			State:     state,
+			StartTime: metav1.NewTime(now.Add(-started)),
+		},
+	}
+}
+
+func pullRefs(org, repo string, number int, author string) *v1alpha1.Refs {
+	return &v1alpha1.Refs{
+		Org:     org,
+		Repo:    repo,
+		BaseRef: "master",
+		BaseSHA: "base",
+		Pulls:   []v1alpha1.Pull{{Number: number, Author: author, SHA: "head"}},
+	}
+}
+
+func newHandler(objects ...runtime.Object) *Handler {
+	h := NewHandler(fake.NewSimpleClientset(objects...), ns)
+	h.now = func() time.Time { return now }
+	return h
+}
+
+func list(t *testing.T, h http.Handler, query string) *JobList {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, Path+query, nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	answer := &JobList{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), answer))
+	return answer
+}
+
+func names(l *JobList) []string {
+	var answer []string
+	for _, item := range l.Items {
+		answer = append(answer, item.Name)
+	}
+	return answer
+}
+
+func TestList(t *testing.T) {
+	h := newHandler(
+		newJob("unit-1", "unit", job.PresubmitJob, v1alpha1.FailureState, 3*time.Hour, pullRefs("MyOrg", "myrepo", 1, "alice")),
+		newJob("unit-2", "unit", job.PresubmitJob, v1alpha1.SuccessState, time.Hour, pullRefs("MyOrg", "myrepo", 2, "bob")),
+		newJob("lint-1", "lint", job.PresubmitJob, v1alpha1.PendingState, 2*time.Hour, pullRefs("MyOrg", "other", 3, "alice")),
+		newJob("release-1", "release", job.PostsubmitJob, v1alpha1.SuccessState, 4*time.Hour, &v1alpha1.Refs{Org: "MyOrg", Repo: "myrepo", BaseRef: "master"}),
+		newJob("nightly-1", "nightly", job.PeriodicJob, v1alpha1.FailureState, 5*time.Hour, nil),
+	)
+
+	all := list(t, h, "")
+	assert.Equal(t, []string{"unit-2", "lint-1", "unit-1", "release-1", "nightly-1"}, names(all))
+	assert.Equal(t, 5, all.Total)
+	assert.Equal(t, DefaultPageSize, all.PerPage)
+
+	assert.Equal(t, []string{"unit-2", "unit-1", "release-1"}, names(list(t, h, "?repo=myorg/myrepo")))
+	assert.Equal(t, []string{"unit-2", "unit-1", "release-1"}, names(list(t, h, "?repo=MyOrg/MyRepo")), "the repository is matched case-insensitively")
+	assert.Equal(t, []string{"release-1"}, names(list(t, h, "?type=postsubmit")))
+	assert.Equal(t, []string{"unit-1", "nightly-1"}, names(list(t, h, "?state=failure")))
+	assert.Equal(t, []string{"lint-1", "unit-1"}, names(list(t, h, "?author=alice")))
+	assert.Equal(t, []string{"unit-2", "unit-1"}, names(list(t, h, "?job=unit")))
+
+	page := list(t, h, "?page=2&per_page=2")
+	assert.Equal(t, []string{"unit-1", "release-1"}, names(page))
+	assert.Equal(t, 5, page.Total)
+	assert.Empty(t, list(t, h, "?page=4&per_page=2").Items)
+}
+
+func TestListSummary(t *testing.T) {
+	lhjob := newJob("unit-1", "unit", job.PresubmitJob, v1alpha1.SuccessState, time.Hour, pullRefs("myorg", "myrepo", 1, "alice"))
+	completion := metav1.NewTime(now.Add(-30 * time.Minute))
+	lhjob.Status.CompletionTime = &completion
+	h := newHandler(lhjob)
+
+	items := list(t, h, "").Items
+	require.Len(t, items, 1)
+	item := items[0]
+	assert.Equal(t, "myorg/myrepo", item.Repo)
+	assert.Equal(t, 1, item.Pull)
+	assert.Equal(t, "alice", item.Author)
+	assert.Equal(t, "head", item.SHA)
+	assert.Equal(t, (30 * time.Minute).Seconds(), item.Duration)
+}
+
+func TestInvalidPagination(t *testing.T) {
+	h := newHandler()
+	for _, query := range []string{"?page=0", "?page=x", "?per_page=0", "?per_page=501"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, Path+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestDetail(t *testing.T) {
+	lhjob := newJob("unit-1", "unit", job.PresubmitJob, v1alpha1.PendingState, time.Hour, pullRefs("myorg", "myrepo", 1, "alice"))
+	lhjob.Spec.RerunCommand = "/test unit"
+	lhjob.Status.Activity = &v1alpha1.ActivityRecord{Name: "unit-1-pipeline"}
+	h := newHandler(lhjob)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, Path+"/unit-1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	detail := &JobDetail{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), detail))
+	assert.Equal(t, "unit-1", detail.Name)
+	assert.Equal(t, "/test unit", detail.RerunCommand)
+	assert.Equal(t, "unit-1-pipeline", detail.Activity.Name)
+	require.NotNil(t, detail.Refs)
+	assert.Equal(t, "head", detail.Refs.Pulls[0].SHA)
+	assert.Equal(t, time.Minute.Seconds(), detail.QueuedDuration)
+	assert.Equal(t, time.Hour.Seconds(), detail.Duration)
+	assert.Nil(t, detail.CompletionTime)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, Path+"/missing", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
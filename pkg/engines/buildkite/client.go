@@ -0,0 +1,297 @@
+package buildkite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultURL is the default Buildkite REST API URL
+	DefaultURL = "https://api.buildkite.com"
+
+	// Build states as returned by the Buildkite API.
+	stateCreating      = "creating"
+	stateScheduled     = "scheduled"
+	stateRunning       = "running"
+	stateBlocked       = "blocked"
+	stateWaiting       = "waiting"
+	stateFailing       = "failing"
+	stateCanceling     = "canceling"
+	statePassed        = "passed"
+	stateSkipped       = "skipped"
+	stateFailed        = "failed"
+	stateWaitingFailed = "waiting_failed"
+	stateCanceled      = "canceled"
+
+	// lighthouseJobIDEnv is the build environment variable holding the name of the LighthouseJob
+	lighthouseJobIDEnv = "LIGHTHOUSE_JOB_ID"
+
+	// Meta-data recorded on every build.
+	lighthouseJobIDMetaData   = "lighthouse-job-id"
+	lighthouseJobNameMetaData = "lighthouse-job-name"
+	lighthouseJobTypeMetaData = "lighthouse-job-type"
+	lighthouseRefsMetaData    = "lighthouse-refs"
+)
+
+// Build is a Buildkite build.
+type Build struct {
+	ID     string `json:"id"`
+	Number int64  `json:"number"`
+	State  string `json:"state"`
+	WebURL string `json:"web_url"`
+	Commit string `json:"commit"`
+	Branch string `json:"branch"`
+}
+
+// IsRunning means the build has not finished yet (it is either scheduled, blocked or running).
+func (b *Build) IsRunning() bool {
+	switch b.State {
+	case stateCreating, stateScheduled, stateRunning, stateBlocked, stateWaiting, stateFailing, stateCanceling:
+		return true
+	}
+	return false
+}
+
+// IsSuccess means the build finished successfully.
+func (b *Build) IsSuccess() bool {
+	return b.State == statePassed || b.State == stateSkipped
+}
+
+// IsFailure means the build finished and failed.
+func (b *Build) IsFailure() bool {
+	return b.State == stateFailed || b.State == stateWaitingFailed
+}
+
+// IsAborted means the build was canceled.
+func (b *Build) IsAborted() bool {
+	return b.State == stateCanceled
+}
+
+// Client can interact with the Buildkite REST API.
+type Client struct {
+	client       *http.Client
+	baseURL      string
+	organization string
+	getToken     func() []byte
+	dryRun       bool
+	logger       *logrus.Entry
+	metrics      *ClientMetrics
+}
+
+// NewClient instantiates a client with provided values.
+//
+// url: the Buildkite REST API URL
+// organization: the slug of the organization of the pipelines which do not specify one
+// getToken: function returning the API access token
+// logger: the logger to use, a default one is created if nil
+// metrics: the metrics to collect, may be nil
+func NewClient(url, organization string, dryRun bool, getToken func() []byte, logger *logrus.Entry, metrics *ClientMetrics) *Client {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	return &Client{
+		client:       &http.Client{Timeout: 30 * time.Second},
+		baseURL:      strings.TrimSuffix(url, "/"),
+		organization: organization,
+		getToken:     getToken,
+		dryRun:       dryRun,
+		logger:       logger.WithField("client", "buildkite"),
+		metrics:      metrics,
+	}
+}
+
+// CreateBuild creates the Buildkite build for the given LighthouseJob.
+func (c *Client) CreateBuild(lighthouseJob *v1alpha1.LighthouseJob) (*Build, error) {
+	spec := &lighthouseJob.Spec
+	if spec.Refs == nil {
+		return nil, errors.New("cannot create a Buildkite build without refs")
+	}
+	path, err := c.pipelinePath(lighthouseJob)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.WithFields(jobutil.LighthouseJobFields(lighthouseJob)).Info("CreateBuild")
+	if c.dryRun {
+		return &Build{State: stateScheduled}, nil
+	}
+
+	body := struct {
+		Commit                string            `json:"commit"`
+		Branch                string            `json:"branch"`
+		Message               string            `json:"message,omitempty"`
+		Env                   map[string]string `json:"env"`
+		MetaData              map[string]string `json:"meta_data"`
+		PullRequestID         int               `json:"pull_request_id,omitempty"`
+		PullRequestBaseBranch string            `json:"pull_request_base_branch,omitempty"`
+	}{
+		Commit:   spec.Refs.BaseSHA,
+		Branch:   spec.Refs.BaseRef,
+		Message:  spec.Job,
+		Env:      BuildEnv(lighthouseJob),
+		MetaData: BuildMetaData(lighthouseJob),
+	}
+	if body.Commit == "" {
+		body.Commit = "HEAD"
+	}
+	if len(spec.Refs.Pulls) > 0 {
+		pull := spec.Refs.Pulls[0]
+		body.Commit = pull.SHA
+		body.PullRequestID = pull.Number
+		body.PullRequestBaseBranch = spec.Refs.BaseRef
+		if pull.Title != "" {
+			body.Message = pull.Title
+		}
+	}
+	data, err := c.request(http.MethodPost, path+"/builds", body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create build of %s", path)
+	}
+	var build Build
+	if err := json.Unmarshal(data, &build); err != nil {
+		return nil, errors.Wrapf(err, "cannot unmarshal build of %s", path)
+	}
+	return &build, nil
+}
+
+// BuildEnv returns the environment variables passed to the build for the given LighthouseJob.
+func BuildEnv(lighthouseJob *v1alpha1.LighthouseJob) map[string]string {
+	spec := &lighthouseJob.Spec
+	env := map[string]string{}
+	if spec.BuildkiteSpec != nil {
+		for k, v := range spec.BuildkiteSpec.Env {
+			env[k] = v
+		}
+	}
+	for k, v := range spec.GetEnvVars() {
+		env[k] = v
+	}
+	// the builds upload their logs and artifacts under the name of the job
+	env[v1alpha1.BuildIDEnv] = lighthouseJob.Name
+	env[lighthouseJobIDEnv] = lighthouseJob.Name
+	return env
+}
+
+// BuildMetaData returns the meta-data recorded on the build for the given LighthouseJob, so that the builds can be
+// traced back to the job and the refs they tested from Buildkite.
+func BuildMetaData(lighthouseJob *v1alpha1.LighthouseJob) map[string]string {
+	metaData := map[string]string{
+		lighthouseJobIDMetaData:   lighthouseJob.Name,
+		lighthouseJobNameMetaData: lighthouseJob.Spec.Job,
+		lighthouseJobTypeMetaData: string(lighthouseJob.Spec.Type),
+	}
+	if refs := lighthouseJob.Spec.GetEnvVars()[v1alpha1.PullRefsEnv]; refs != "" {
+		metaData[lighthouseRefsMetaData] = refs
+	}
+	return metaData
+}
+
+// GetBuild returns the build of the given LighthouseJob with the given number.
+func (c *Client) GetBuild(lighthouseJob *v1alpha1.LighthouseJob, number int64) (*Build, error) {
+	path, err := c.pipelinePath(lighthouseJob)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.request(http.MethodGet, fmt.Sprintf("%s/builds/%d", path, number), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get build %d of %s", number, path)
+	}
+	var build Build
+	if err := json.Unmarshal(data, &build); err != nil {
+		return nil, errors.Wrapf(err, "cannot unmarshal build %d of %s", number, path)
+	}
+	return &build, nil
+}
+
+// CancelBuild cancels the build of the given LighthouseJob with the given number.
+func (c *Client) CancelBuild(lighthouseJob *v1alpha1.LighthouseJob, number int64) error {
+	path, err := c.pipelinePath(lighthouseJob)
+	if err != nil {
+		return err
+	}
+	c.logger.Debugf("CancelBuild(%s %d)", path, number)
+	if c.dryRun {
+		return nil
+	}
+	_, err = c.request(http.MethodPut, fmt.Sprintf("%s/builds/%d/cancel", path, number), nil)
+	return err
+}
+
+// Ping verifies the Buildkite API is reachable and accepts the token.
+func (c *Client) Ping() error {
+	_, err := c.request(http.MethodGet, "/v2/access-token", nil)
+	return err
+}
+
+// pipelinePath returns the API path of the pipeline building the given LighthouseJob.
+func (c *Client) pipelinePath(lighthouseJob *v1alpha1.LighthouseJob) (string, error) {
+	spec := lighthouseJob.Spec.BuildkiteSpec
+	if spec == nil || spec.Pipeline == "" {
+		return "", errors.New("missing buildkite_spec.pipeline")
+	}
+	organization := spec.Organization
+	if organization == "" {
+		organization = c.organization
+	}
+	if organization == "" {
+		return "", errors.New("missing buildkite_spec.organization and no default organization configured")
+	}
+	return fmt.Sprintf("/v2/organizations/%s/pipelines/%s", organization, spec.Pipeline), nil
+}
+
+func (c *Client) request(method, path string, body interface{}) ([]byte, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.getToken != nil {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(c.getToken())))
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if c.metrics != nil {
+		c.metrics.RequestLatency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if c.metrics != nil {
+		c.metrics.Requests.WithLabelValues(method, strconv.Itoa(resp.StatusCode)).Inc()
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("response not 2XX: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
@@ -0,0 +1,151 @@
+package buildkite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testJob(organization string) *v1alpha1.LighthouseJob {
+	return &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc123"},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type:  job.PresubmitJob,
+			Agent: job.BuildkiteAgent,
+			Job:   "unit",
+			Refs: &v1alpha1.Refs{
+				Org:     "org",
+				Repo:    "repo",
+				BaseRef: "master",
+				BaseSHA: "base-sha",
+				Pulls:   []v1alpha1.Pull{{Number: 7, SHA: "pull-sha", Title: "Fix the build"}},
+			},
+			BuildkiteSpec: &v1alpha1.BuildkiteSpec{
+				Organization: organization,
+				Pipeline:     "repo-ci",
+				Env:          map[string]string{"EXTRA": "value"},
+			},
+		},
+	}
+}
+
+func TestCreateBuild(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": "f62a1b4d", "number": 42, "state": "scheduled", "web_url": "https://buildkite.com/acme/repo-ci/builds/42"}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "acme", false, func() []byte { return []byte("secret\n") }, nil, nil)
+	build, err := c.CreateBuild(testJob(""))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), build.Number)
+	assert.Equal(t, "https://buildkite.com/acme/repo-ci/builds/42", build.WebURL)
+	assert.True(t, build.IsRunning())
+
+	assert.Equal(t, "/v2/organizations/acme/pipelines/repo-ci/builds", gotPath)
+	assert.Equal(t, "Bearer secret", gotAuth)
+	assert.Equal(t, "pull-sha", gotBody["commit"])
+	assert.Equal(t, "master", gotBody["branch"])
+	assert.Equal(t, "Fix the build", gotBody["message"])
+	assert.Equal(t, float64(7), gotBody["pull_request_id"])
+	assert.Equal(t, "master", gotBody["pull_request_base_branch"])
+	env := gotBody["env"].(map[string]interface{})
+	assert.Equal(t, "abc123", env["LIGHTHOUSE_JOB_ID"])
+	assert.Equal(t, "7", env[v1alpha1.PullNumberEnv])
+	assert.Equal(t, "value", env["EXTRA"])
+	metaData := gotBody["meta_data"].(map[string]interface{})
+	assert.Equal(t, "abc123", metaData["lighthouse-job-id"])
+	assert.Equal(t, "unit", metaData["lighthouse-job-name"])
+	assert.Equal(t, "presubmit", metaData["lighthouse-job-type"])
+	assert.Equal(t, "master:base-sha,7:pull-sha", metaData["lighthouse-refs"])
+}
+
+func TestCreateBuildOrganization(t *testing.T) {
+	var gotPath string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_, _ = w.Write([]byte(`{"number": 1, "state": "scheduled"}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "acme", false, nil, nil, nil)
+	_, err := c.CreateBuild(testJob("other"))
+	require.NoError(t, err)
+	assert.Equal(t, "/v2/organizations/other/pipelines/repo-ci/builds", gotPath)
+
+	c = NewClient(ts.URL, "", false, nil, nil, nil)
+	_, err = c.CreateBuild(testJob(""))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no default organization")
+}
+
+func TestCreateBuildError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"No pipeline found"}`, http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "acme", false, nil, nil, nil)
+	_, err := c.CreateBuild(testJob(""))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "No pipeline found")
+}
+
+func TestGetAndCancelBuild(t *testing.T) {
+	var cancelled string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			cancelled = r.URL.Path
+			_, _ = w.Write([]byte(`{"number": 42, "state": "canceling"}`))
+			return
+		}
+		assert.Equal(t, "/v2/organizations/acme/pipelines/repo-ci/builds/42", r.URL.Path)
+		_, _ = w.Write([]byte(`{"number": 42, "state": "failed"}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "acme", false, nil, nil, nil)
+	build, err := c.GetBuild(testJob(""), 42)
+	require.NoError(t, err)
+	assert.True(t, build.IsFailure())
+
+	require.NoError(t, c.CancelBuild(testJob(""), 42))
+	assert.Equal(t, "/v2/organizations/acme/pipelines/repo-ci/builds/42/cancel", cancelled)
+}
+
+func TestBuildStates(t *testing.T) {
+	tests := []struct {
+		build                              Build
+		running, success, failure, aborted bool
+	}{
+		{build: Build{State: "scheduled"}, running: true},
+		{build: Build{State: "running"}, running: true},
+		{build: Build{State: "failing"}, running: true},
+		{build: Build{State: "blocked"}, running: true},
+		{build: Build{State: "canceling"}, running: true},
+		{build: Build{State: "passed"}, success: true},
+		{build: Build{State: "skipped"}, success: true},
+		{build: Build{State: "failed"}, failure: true},
+		{build: Build{State: "canceled"}, aborted: true},
+		{build: Build{State: "not_run"}},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.running, tc.build.IsRunning(), "running %+v", tc.build)
+		assert.Equal(t, tc.success, tc.build.IsSuccess(), "success %+v", tc.build)
+		assert.Equal(t, tc.failure, tc.build.IsFailure(), "failure %+v", tc.build)
+		assert.Equal(t, tc.aborted, tc.build.IsAborted(), "aborted %+v", tc.build)
+	}
+}
@@ -0,0 +1,212 @@
+package buildkite
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/artifacts"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	client "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/typed/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+type lighthouseJobClient interface {
+	List(metav1.ListOptions) (*v1alpha1.LighthouseJobList, error)
+	UpdateStatus(*v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error)
+}
+
+type buildkiteClient interface {
+	CreateBuild(*v1alpha1.LighthouseJob) (*Build, error)
+	GetBuild(lighthouseJob *v1alpha1.LighthouseJob, number int64) (*Build, error)
+	CancelBuild(lighthouseJob *v1alpha1.LighthouseJob, number int64) error
+}
+
+// Controller manages LighthouseJobs running as Buildkite builds.
+type Controller struct {
+	lighthouseClient lighthouseJobClient
+	buildkiteClient  buildkiteClient
+	log              *logrus.Entry
+	// selector that will be applied on Lighthouse jobs.
+	selector string
+	clock    clock.Clock
+}
+
+// NewController creates a new Controller from the provided clients.
+func NewController(lighthouseClient client.LighthouseJobInterface, buildkiteClient *Client, logger *logrus.Entry, selector string) *Controller {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	return &Controller{
+		lighthouseClient: lighthouseClient,
+		buildkiteClient:  buildkiteClient,
+		log:              logger,
+		selector:         selector,
+		clock:            clock.RealClock{},
+	}
+}
+
+// Sync does one sync iteration.
+func (c *Controller) Sync() error {
+	jobList, err := c.lighthouseClient.List(metav1.ListOptions{LabelSelector: c.selector})
+	if err != nil {
+		return fmt.Errorf("error listing Lighthouse jobs: %v", err)
+	}
+	// the triggered jobs are started by decreasing priority
+	jobutil.SortByPriority(jobList.Items)
+
+	var syncErrs []error
+	for i := range jobList.Items {
+		lighthouseJob := jobList.Items[i]
+		if lighthouseJob.Spec.Agent != job.BuildkiteAgent || lighthouseJob.Complete() {
+			continue
+		}
+		var err error
+		switch lighthouseJob.Status.State {
+		case v1alpha1.TriggeredState, "":
+			err = c.syncTriggeredJob(lighthouseJob)
+		case v1alpha1.PendingState, v1alpha1.RunningState:
+			err = c.syncPendingJob(lighthouseJob)
+		case v1alpha1.AbortedState:
+			err = c.syncAbortedJob(lighthouseJob)
+		}
+		if err != nil {
+			syncErrs = append(syncErrs, err)
+		}
+	}
+
+	if len(syncErrs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("errors syncing: %v", syncErrs)
+}
+
+func (c *Controller) syncTriggeredJob(lighthouseJob v1alpha1.LighthouseJob) error {
+	originalState := lighthouseJob.Status.State
+
+	build, err := c.buildkiteClient.CreateBuild(&lighthouseJob)
+	if err != nil {
+		c.log.WithError(err).WithFields(jobutil.LighthouseJobFields(&lighthouseJob)).Warn("Cannot create Buildkite build")
+		lighthouseJob.Status.State = v1alpha1.ErrorState
+		lighthouseJob.Status.Description = "Error creating Buildkite build."
+		lighthouseJob.SetComplete()
+	} else {
+		lighthouseJob.Status.State = v1alpha1.PendingState
+		lighthouseJob.Status.Description = "Buildkite build created."
+		lighthouseJob.Status.StartTime = metav1.NewTime(c.clock.Now())
+		lighthouseJob.Status.BuildID = lighthouseJob.Name
+		lighthouseJob.Status.ArtifactsURL = artifacts.URLForJob(&lighthouseJob.Spec, lighthouseJob.Name)
+		if build.Number > 0 {
+			lighthouseJob.Status.ActivityName = strconv.FormatInt(build.Number, 10)
+			lighthouseJob.Status.ReportURL = build.WebURL
+		}
+	}
+	return c.updateStatus(&lighthouseJob, originalState)
+}
+
+func (c *Controller) syncPendingJob(lighthouseJob v1alpha1.LighthouseJob) error {
+	// builds created in dry-run mode have no number to poll
+	if lighthouseJob.Status.ActivityName == "" {
+		return nil
+	}
+	originalState := lighthouseJob.Status.State
+	number, err := c.buildNumber(&lighthouseJob)
+	if err != nil {
+		return err
+	}
+	build, err := c.buildkiteClient.GetBuild(&lighthouseJob, number)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case build.IsRunning():
+		if build.State != stateScheduled && build.State != stateCreating {
+			lighthouseJob.Status.State = v1alpha1.RunningState
+			lighthouseJob.Status.Description = "Buildkite build running."
+		}
+	case build.IsSuccess():
+		lighthouseJob.Status.State = v1alpha1.SuccessState
+		lighthouseJob.Status.Description = "Buildkite build succeeded."
+		lighthouseJob.SetComplete()
+	case build.IsFailure():
+		lighthouseJob.Status.State = v1alpha1.FailureState
+		lighthouseJob.Status.Description = "Buildkite build failed."
+		lighthouseJob.SetComplete()
+	case build.IsAborted():
+		lighthouseJob.Status.State = v1alpha1.AbortedState
+		lighthouseJob.Status.Description = "Buildkite build canceled."
+		lighthouseJob.SetComplete()
+	default:
+		lighthouseJob.Status.State = v1alpha1.ErrorState
+		lighthouseJob.Status.Description = fmt.Sprintf("Buildkite build completed with state %q.", build.State)
+		lighthouseJob.SetComplete()
+	}
+
+	if originalState == lighthouseJob.Status.State {
+		return nil
+	}
+	return c.updateStatus(&lighthouseJob, originalState)
+}
+
+func (c *Controller) syncAbortedJob(lighthouseJob v1alpha1.LighthouseJob) error {
+	if lighthouseJob.Status.ActivityName != "" {
+		number, err := c.buildNumber(&lighthouseJob)
+		if err != nil {
+			return err
+		}
+		err = c.buildkiteClient.CancelBuild(&lighthouseJob, number)
+		audit.Record(nil, audit.JobEvent(audit.JobAborted, &lighthouseJob), err)
+		if err != nil {
+			return fmt.Errorf("failed to cancel Buildkite build: %v", err)
+		}
+	}
+
+	lighthouseJob.SetComplete()
+	c.addActivity(&lighthouseJob)
+
+	_, err := c.lighthouseClient.UpdateStatus(&lighthouseJob)
+	return err
+}
+
+// buildNumber returns the number of the Buildkite build of the job, stored as its activity name.
+func (c *Controller) buildNumber(lighthouseJob *v1alpha1.LighthouseJob) (int64, error) {
+	number, err := strconv.ParseInt(lighthouseJob.Status.ActivityName, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Buildkite build number %q for job %s: %v", lighthouseJob.Status.ActivityName, lighthouseJob.Name, err)
+	}
+	return number, nil
+}
+
+func (c *Controller) updateStatus(lighthouseJob *v1alpha1.LighthouseJob, previousState v1alpha1.PipelineState) error {
+	c.log.WithFields(jobutil.LighthouseJobFields(lighthouseJob)).
+		WithField("from", previousState).
+		WithField("to", lighthouseJob.Status.State).Info("Transitioning states.")
+	// make sure to set an activity record this job state update
+	c.addActivity(lighthouseJob)
+	_, err := c.lighthouseClient.UpdateStatus(lighthouseJob)
+	return err
+}
+
+func (c *Controller) addActivity(lighthouseJob *v1alpha1.LighthouseJob) {
+	activity := v1alpha1.ActivityRecord{
+		Name:            lighthouseJob.Name,
+		Status:          lighthouseJob.Status.State,
+		StartTime:       &lighthouseJob.Status.StartTime,
+		CompletionTime:  lighthouseJob.Status.CompletionTime,
+		Owner:           lighthouseJob.Labels[util.OrgLabel],
+		Repo:            lighthouseJob.Labels[util.RepoLabel],
+		GitURL:          lighthouseJob.Annotations[util.CloneURIAnnotation],
+		LastCommitSHA:   lighthouseJob.Labels[util.LastCommitSHALabel],
+		Branch:          lighthouseJob.Labels[util.BranchLabel],
+		BuildIdentifier: lighthouseJob.Status.ActivityName,
+		Context:         lighthouseJob.Labels[util.ContextLabel],
+	}
+
+	lighthouseJob.Status.Activity = &activity
+}
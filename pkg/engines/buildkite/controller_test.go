@@ -0,0 +1,135 @@
+package buildkite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+type fakeBuildkiteClient struct {
+	created   []string
+	cancelled []int64
+	build     Build
+	createErr error
+}
+
+func (f *fakeBuildkiteClient) CreateBuild(j *v1alpha1.LighthouseJob) (*Build, error) {
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	f.created = append(f.created, j.Name)
+	build := f.build
+	return &build, nil
+}
+
+func (f *fakeBuildkiteClient) GetBuild(j *v1alpha1.LighthouseJob, number int64) (*Build, error) {
+	if f.build.Number != number {
+		return nil, fmt.Errorf("build %d not found", number)
+	}
+	build := f.build
+	return &build, nil
+}
+
+func (f *fakeBuildkiteClient) CancelBuild(j *v1alpha1.LighthouseJob, number int64) error {
+	f.cancelled = append(f.cancelled, number)
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	lhJob := testJob("")
+	lhJob.Namespace = "jx"
+	lhJob.Status.State = v1alpha1.TriggeredState
+
+	lhClient := fake.NewSimpleClientset(lhJob).LighthouseV1alpha1().LighthouseJobs("jx")
+	bc := &fakeBuildkiteClient{build: Build{Number: 42, State: "scheduled", WebURL: "https://buildkite.com/acme/repo-ci/builds/42"}}
+	c := &Controller{
+		lighthouseClient: lhClient,
+		buildkiteClient:  bc,
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		clock:            clock.NewFakeClock(time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)),
+	}
+
+	getJob := func() *v1alpha1.LighthouseJob {
+		j, err := lhClient.Get(lhJob.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		return j
+	}
+
+	// triggered jobs get a build
+	require.NoError(t, c.Sync())
+	assert.Equal(t, []string{"abc123"}, bc.created)
+	j := getJob()
+	assert.Equal(t, v1alpha1.PendingState, j.Status.State)
+	assert.Equal(t, "42", j.Status.ActivityName)
+	assert.Equal(t, "https://buildkite.com/acme/repo-ci/builds/42", j.Status.ReportURL)
+
+	// scheduled builds stay pending
+	require.NoError(t, c.Sync())
+	assert.Equal(t, v1alpha1.PendingState, getJob().Status.State)
+
+	// the build starts
+	bc.build.State = "running"
+	require.NoError(t, c.Sync())
+	j = getJob()
+	assert.Equal(t, v1alpha1.RunningState, j.Status.State)
+	require.NotNil(t, j.Status.Activity)
+	assert.Equal(t, v1alpha1.RunningState, j.Status.Activity.Status)
+
+	// the build completes
+	bc.build.State = "passed"
+	require.NoError(t, c.Sync())
+	j = getJob()
+	assert.Equal(t, v1alpha1.SuccessState, j.Status.State)
+	assert.True(t, j.Complete())
+	assert.Len(t, bc.created, 1)
+}
+
+func TestSyncCreateError(t *testing.T) {
+	lhJob := testJob("")
+	lhJob.Namespace = "jx"
+	lhJob.Status.State = v1alpha1.TriggeredState
+
+	lhClient := fake.NewSimpleClientset(lhJob).LighthouseV1alpha1().LighthouseJobs("jx")
+	c := &Controller{
+		lighthouseClient: lhClient,
+		buildkiteClient:  &fakeBuildkiteClient{createErr: fmt.Errorf("no pipeline found")},
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		clock:            clock.RealClock{},
+	}
+	require.NoError(t, c.Sync())
+
+	j, err := lhClient.Get(lhJob.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.ErrorState, j.Status.State)
+	assert.True(t, j.Complete())
+}
+
+func TestSyncAborted(t *testing.T) {
+	lhJob := testJob("")
+	lhJob.Namespace = "jx"
+	lhJob.Status.State = v1alpha1.AbortedState
+	lhJob.Status.ActivityName = "42"
+
+	lhClient := fake.NewSimpleClientset(lhJob).LighthouseV1alpha1().LighthouseJobs("jx")
+	bc := &fakeBuildkiteClient{}
+	c := &Controller{
+		lighthouseClient: lhClient,
+		buildkiteClient:  bc,
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		clock:            clock.RealClock{},
+	}
+	require.NoError(t, c.Sync())
+	assert.Equal(t, []int64{42}, bc.cancelled)
+
+	j, err := lhClient.Get(lhJob.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, j.Complete())
+}
@@ -0,0 +1,9 @@
+// Package buildkite includes a client and the controller logic for
+// running LighthouseJobs as Buildkite builds.
+//
+// Jobs are started by creating a build of the configured pipeline through
+// the Buildkite REST API, for the commit under test, with the usual job
+// environment variables (e.g. `PULL_NUMBER`) passed as build environment
+// and the job and refs recorded as build meta-data. The controller then
+// polls the build and maps its state back to the LighthouseJob status.
+package buildkite
@@ -0,0 +1,57 @@
+package buildkite
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "buildkite_requests",
+		Help: "Number of Buildkite API requests made by lighthouse.",
+	}, []string{
+		// http verb of the request
+		"verb",
+		// http status code of the request
+		"code",
+	})
+	requestLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "buildkite_request_latency",
+		Help:    "Time for a request to roundtrip between lighthouse and the Buildkite API.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{
+		// http verb of the request
+		"verb",
+	})
+	resyncPeriod = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "buildkite_resync_period_seconds",
+		Help:    "Time the Buildkite controller takes to complete one reconciliation loop.",
+		Buckets: prometheus.ExponentialBuckets(1, 3, 5),
+	})
+)
+
+func init() {
+	prometheus.MustRegister(requests)
+	prometheus.MustRegister(requestLatency)
+	prometheus.MustRegister(resyncPeriod)
+}
+
+// ClientMetrics is a set of metrics gathered by the Buildkite client.
+type ClientMetrics struct {
+	Requests       *prometheus.CounterVec
+	RequestLatency *prometheus.HistogramVec
+}
+
+// Metrics is a set of metrics gathered by the Buildkite controller.
+type Metrics struct {
+	ClientMetrics *ClientMetrics
+	ResyncPeriod  prometheus.Histogram
+}
+
+// NewMetrics creates a new set of metrics for the Buildkite controller.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		ClientMetrics: &ClientMetrics{
+			Requests:       requests,
+			RequestLatency: requestLatency,
+		},
+		ResyncPeriod: resyncPeriod,
+	}
+}
@@ -0,0 +1,222 @@
+package drone
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Build statuses as returned by the Drone API.
+	statusPending  = "pending"
+	statusRunning  = "running"
+	statusBlocked  = "blocked"
+	statusWaiting  = "waiting_on_dependencies"
+	statusSuccess  = "success"
+	statusSkipped  = "skipped"
+	statusFailure  = "failure"
+	statusKilled   = "killed"
+	statusError    = "error"
+	statusDeclined = "declined"
+
+	// lighthouseJobIDParam is the build parameter holding the name of the LighthouseJob
+	lighthouseJobIDParam = "LIGHTHOUSE_JOB_ID"
+)
+
+// Build is a Drone build.
+type Build struct {
+	ID       int64  `json:"id"`
+	Number   int64  `json:"number"`
+	Status   string `json:"status"`
+	Event    string `json:"event"`
+	Ref      string `json:"ref"`
+	After    string `json:"after"`
+	Error    string `json:"error"`
+	Started  int64  `json:"started"`
+	Finished int64  `json:"finished"`
+}
+
+// IsRunning means the build has not finished yet (it is either pending, blocked or running).
+func (b *Build) IsRunning() bool {
+	switch b.Status {
+	case statusPending, statusRunning, statusBlocked, statusWaiting:
+		return true
+	}
+	return false
+}
+
+// IsSuccess means the build finished successfully.
+func (b *Build) IsSuccess() bool {
+	return b.Status == statusSuccess || b.Status == statusSkipped
+}
+
+// IsFailure means the build finished and failed.
+func (b *Build) IsFailure() bool {
+	return b.Status == statusFailure
+}
+
+// IsAborted means the build was killed.
+func (b *Build) IsAborted() bool {
+	return b.Status == statusKilled
+}
+
+// Client can interact with the Drone API.
+type Client struct {
+	client   *http.Client
+	baseURL  string
+	getToken func() []byte
+	dryRun   bool
+	logger   *logrus.Entry
+	metrics  *ClientMetrics
+}
+
+// NewClient instantiates a client with provided values.
+//
+// url: the Drone server URL
+// getToken: function returning the token used to authenticate against the API
+// logger: the logger to use, a default one is created if nil
+// metrics: the metrics to collect, may be nil
+func NewClient(url string, dryRun bool, getToken func() []byte, logger *logrus.Entry, metrics *ClientMetrics) *Client {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	return &Client{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		baseURL:  strings.TrimSuffix(url, "/"),
+		getToken: getToken,
+		dryRun:   dryRun,
+		logger:   logger.WithField("client", "drone"),
+		metrics:  metrics,
+	}
+}
+
+// CreateBuild creates the Drone build for the given LighthouseJob.
+func (c *Client) CreateBuild(lighthouseJob *v1alpha1.LighthouseJob) (*Build, error) {
+	spec := &lighthouseJob.Spec
+	if spec.Refs == nil {
+		return nil, errors.New("cannot create a Drone build without refs")
+	}
+	c.logger.WithFields(jobutil.LighthouseJobFields(lighthouseJob)).Info("CreateBuild")
+	if c.dryRun {
+		return &Build{Status: statusPending}, nil
+	}
+
+	query := url.Values{}
+	for k, v := range BuildParams(lighthouseJob) {
+		query.Set(k, v)
+	}
+	query.Set("branch", spec.Refs.BaseRef)
+	commit := spec.Refs.BaseSHA
+	if len(spec.Refs.Pulls) > 0 {
+		commit = spec.Refs.Pulls[0].SHA
+	}
+	if commit != "" {
+		query.Set("commit", commit)
+	}
+	data, err := c.request(http.MethodPost, fmt.Sprintf("/api/repos/%s/%s/builds?%s", spec.Refs.Org, spec.Refs.Repo, query.Encode()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create build for %s/%s", spec.Refs.Org, spec.Refs.Repo)
+	}
+	var build Build
+	if err := json.Unmarshal(data, &build); err != nil {
+		return nil, errors.Wrapf(err, "cannot unmarshal build for %s/%s", spec.Refs.Org, spec.Refs.Repo)
+	}
+	return &build, nil
+}
+
+// BuildParams returns the parameters passed to the build for the given LighthouseJob, which Drone exposes to the
+// pipeline as environment variables.
+func BuildParams(lighthouseJob *v1alpha1.LighthouseJob) map[string]string {
+	spec := &lighthouseJob.Spec
+	params := map[string]string{}
+	if spec.DroneSpec != nil {
+		for k, v := range spec.DroneSpec.Params {
+			params[k] = v
+		}
+	}
+	for k, v := range spec.GetEnvVars() {
+		params[k] = v
+	}
+	// the builds upload their logs and artifacts under the name of the job
+	params[v1alpha1.BuildIDEnv] = lighthouseJob.Name
+	params[lighthouseJobIDParam] = lighthouseJob.Name
+	return params
+}
+
+// GetBuild returns the build of a repository with the given number.
+func (c *Client) GetBuild(owner, repo string, number int64) (*Build, error) {
+	data, err := c.request(http.MethodGet, fmt.Sprintf("/api/repos/%s/%s/builds/%d", owner, repo, number))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get build %d for %s/%s", number, owner, repo)
+	}
+	var build Build
+	if err := json.Unmarshal(data, &build); err != nil {
+		return nil, errors.Wrapf(err, "cannot unmarshal build %d for %s/%s", number, owner, repo)
+	}
+	return &build, nil
+}
+
+// CancelBuild cancels the build of a repository with the given number.
+func (c *Client) CancelBuild(owner, repo string, number int64) error {
+	c.logger.Debugf("CancelBuild(%s/%s %d)", owner, repo, number)
+	if c.dryRun {
+		return nil
+	}
+	_, err := c.request(http.MethodDelete, fmt.Sprintf("/api/repos/%s/%s/builds/%d", owner, repo, number))
+	return err
+}
+
+// BuildURL returns the URL of the build in the Drone UI.
+func (c *Client) BuildURL(owner, repo string, number int64) string {
+	return fmt.Sprintf("%s/%s/%s/%d", c.baseURL, owner, repo, number)
+}
+
+// Ping verifies the Drone API is reachable and accepts the token.
+func (c *Client) Ping() error {
+	_, err := c.request(http.MethodGet, "/api/user")
+	return err
+}
+
+func (c *Client) request(method, path string) ([]byte, error) {
+	req, err := http.NewRequest(method, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.getToken != nil {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(c.getToken())))
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if c.metrics != nil {
+		c.metrics.RequestLatency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if c.metrics != nil {
+		c.metrics.Requests.WithLabelValues(method, strconv.Itoa(resp.StatusCode)).Inc()
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("response not 2XX: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
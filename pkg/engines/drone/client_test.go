@@ -0,0 +1,120 @@
+package drone
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testJob() *v1alpha1.LighthouseJob {
+	return &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc123"},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type:  job.PresubmitJob,
+			Agent: job.DroneAgent,
+			Job:   "unit",
+			Refs: &v1alpha1.Refs{
+				Org:     "org",
+				Repo:    "repo",
+				BaseRef: "master",
+				BaseSHA: "base-sha",
+				Pulls:   []v1alpha1.Pull{{Number: 7, SHA: "pull-sha"}},
+			},
+			DroneSpec: &v1alpha1.DroneSpec{
+				Params: map[string]string{"EXTRA": "value"},
+			},
+		},
+	}
+}
+
+func TestCreateBuild(t *testing.T) {
+	var gotMethod, gotPath, gotAuth string
+	var gotQuery map[string][]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotQuery = r.URL.Query()
+		_, _ = w.Write([]byte(`{"id": 100, "number": 42, "status": "pending", "event": "custom"}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, false, func() []byte { return []byte("secret\n") }, nil, nil)
+	build, err := c.CreateBuild(testJob())
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), build.Number)
+	assert.True(t, build.IsRunning())
+
+	assert.Equal(t, http.MethodPost, gotMethod)
+	assert.Equal(t, "/api/repos/org/repo/builds", gotPath)
+	assert.Equal(t, "Bearer secret", gotAuth)
+	assert.Equal(t, []string{"master"}, gotQuery["branch"])
+	assert.Equal(t, []string{"pull-sha"}, gotQuery["commit"])
+	assert.Equal(t, []string{"abc123"}, gotQuery["LIGHTHOUSE_JOB_ID"])
+	assert.Equal(t, []string{"7"}, gotQuery[v1alpha1.PullNumberEnv])
+	assert.Equal(t, []string{"value"}, gotQuery["EXTRA"])
+	assert.Equal(t, ts.URL+"/org/repo/42", c.BuildURL("org", "repo", 42))
+}
+
+func TestCreateBuildError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, false, nil, nil, nil)
+	_, err := c.CreateBuild(testJob())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Not Found")
+}
+
+func TestGetAndCancelBuild(t *testing.T) {
+	var cancelled string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/repos/org/repo/builds/42", r.URL.Path)
+		if r.Method == http.MethodDelete {
+			cancelled = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 100, "number": 42, "status": "failure"}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, false, nil, nil, nil)
+	build, err := c.GetBuild("org", "repo", 42)
+	require.NoError(t, err)
+	assert.True(t, build.IsFailure())
+
+	require.NoError(t, c.CancelBuild("org", "repo", 42))
+	assert.Equal(t, "/api/repos/org/repo/builds/42", cancelled)
+}
+
+func TestBuildStates(t *testing.T) {
+	tests := []struct {
+		build                              Build
+		running, success, failure, aborted bool
+	}{
+		{build: Build{Status: "pending"}, running: true},
+		{build: Build{Status: "running"}, running: true},
+		{build: Build{Status: "blocked"}, running: true},
+		{build: Build{Status: "success"}, success: true},
+		{build: Build{Status: "skipped"}, success: true},
+		{build: Build{Status: "failure"}, failure: true},
+		{build: Build{Status: "killed"}, aborted: true},
+		{build: Build{Status: "error"}},
+		{build: Build{Status: "declined"}},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.running, tc.build.IsRunning(), "running %+v", tc.build)
+		assert.Equal(t, tc.success, tc.build.IsSuccess(), "success %+v", tc.build)
+		assert.Equal(t, tc.failure, tc.build.IsFailure(), "failure %+v", tc.build)
+		assert.Equal(t, tc.aborted, tc.build.IsAborted(), "aborted %+v", tc.build)
+	}
+}
@@ -0,0 +1,159 @@
+package drone
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+type fakeDroneClient struct {
+	created   []string
+	cancelled []int64
+	build     Build
+	createErr error
+}
+
+func (f *fakeDroneClient) CreateBuild(j *v1alpha1.LighthouseJob) (*Build, error) {
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	f.created = append(f.created, j.Name)
+	build := f.build
+	return &build, nil
+}
+
+func (f *fakeDroneClient) GetBuild(owner, repo string, number int64) (*Build, error) {
+	if f.build.Number != number {
+		return nil, fmt.Errorf("build %d not found", number)
+	}
+	build := f.build
+	return &build, nil
+}
+
+func (f *fakeDroneClient) CancelBuild(owner, repo string, number int64) error {
+	f.cancelled = append(f.cancelled, number)
+	return nil
+}
+
+func (f *fakeDroneClient) BuildURL(owner, repo string, number int64) string {
+	return fmt.Sprintf("https://drone.example.com/%s/%s/%d", owner, repo, number)
+}
+
+func TestSync(t *testing.T) {
+	lhJob := testJob()
+	lhJob.Namespace = "jx"
+	lhJob.Status.State = v1alpha1.TriggeredState
+
+	lhClient := fake.NewSimpleClientset(lhJob).LighthouseV1alpha1().LighthouseJobs("jx")
+	dc := &fakeDroneClient{build: Build{Number: 42, Status: "pending"}}
+	c := &Controller{
+		lighthouseClient: lhClient,
+		droneClient:      dc,
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		clock:            clock.NewFakeClock(time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)),
+	}
+
+	getJob := func() *v1alpha1.LighthouseJob {
+		j, err := lhClient.Get(lhJob.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		return j
+	}
+
+	// triggered jobs get a build
+	require.NoError(t, c.Sync())
+	assert.Equal(t, []string{"abc123"}, dc.created)
+	j := getJob()
+	assert.Equal(t, v1alpha1.PendingState, j.Status.State)
+	assert.Equal(t, "42", j.Status.ActivityName)
+	assert.Equal(t, "https://drone.example.com/org/repo/42", j.Status.ReportURL)
+
+	// the build starts
+	dc.build.Status = "running"
+	require.NoError(t, c.Sync())
+	j = getJob()
+	assert.Equal(t, v1alpha1.RunningState, j.Status.State)
+	require.NotNil(t, j.Status.Activity)
+	assert.Equal(t, v1alpha1.RunningState, j.Status.Activity.Status)
+
+	// the build completes
+	dc.build.Status = "failure"
+	require.NoError(t, c.Sync())
+	j = getJob()
+	assert.Equal(t, v1alpha1.FailureState, j.Status.State)
+	assert.True(t, j.Complete())
+	assert.Len(t, dc.created, 1)
+}
+
+func TestSyncByPriority(t *testing.T) {
+	var objects []runtime.Object
+	for i, priority := range []int{0, 10, 5} {
+		lhJob := testJob()
+		lhJob.Name = fmt.Sprintf("job-%d", i)
+		lhJob.Namespace = "jx"
+		lhJob.CreationTimestamp = metav1.NewTime(time.Date(2020, 7, 1, 9, i, 0, 0, time.UTC))
+		lhJob.Spec.Priority = priority
+		lhJob.Status.State = v1alpha1.TriggeredState
+		objects = append(objects, lhJob)
+	}
+
+	dc := &fakeDroneClient{build: Build{Number: 42, Status: "pending"}}
+	c := &Controller{
+		lighthouseClient: fake.NewSimpleClientset(objects...).LighthouseV1alpha1().LighthouseJobs("jx"),
+		droneClient:      dc,
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		clock:            clock.NewFakeClock(time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)),
+	}
+	require.NoError(t, c.Sync())
+	assert.Equal(t, []string{"job-1", "job-2", "job-0"}, dc.created)
+}
+
+func TestSyncCreateError(t *testing.T) {
+	lhJob := testJob()
+	lhJob.Namespace = "jx"
+	lhJob.Status.State = v1alpha1.TriggeredState
+
+	lhClient := fake.NewSimpleClientset(lhJob).LighthouseV1alpha1().LighthouseJobs("jx")
+	c := &Controller{
+		lighthouseClient: lhClient,
+		droneClient:      &fakeDroneClient{createErr: fmt.Errorf("repository not activated")},
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		clock:            clock.RealClock{},
+	}
+	require.NoError(t, c.Sync())
+
+	j, err := lhClient.Get(lhJob.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.ErrorState, j.Status.State)
+	assert.True(t, j.Complete())
+}
+
+func TestSyncAborted(t *testing.T) {
+	lhJob := testJob()
+	lhJob.Namespace = "jx"
+	lhJob.Status.State = v1alpha1.AbortedState
+	lhJob.Status.ActivityName = "42"
+
+	lhClient := fake.NewSimpleClientset(lhJob).LighthouseV1alpha1().LighthouseJobs("jx")
+	dc := &fakeDroneClient{}
+	c := &Controller{
+		lighthouseClient: lhClient,
+		droneClient:      dc,
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		clock:            clock.RealClock{},
+	}
+	require.NoError(t, c.Sync())
+	assert.Equal(t, []int64{42}, dc.cancelled)
+
+	j, err := lhClient.Get(lhJob.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, j.Complete())
+}
@@ -0,0 +1,12 @@
+// Package drone includes a client and the controller logic for
+// running LighthouseJobs as Drone builds.
+//
+// Jobs are started by creating a build of the repository through the Drone
+// API, for the base branch and the commit under test, with the usual job
+// environment variables (e.g. `PULL_NUMBER`) passed as build parameters.
+// The controller then polls the build and maps its status back to the
+// LighthouseJob status.
+//
+// The repository must already be activated in Drone, and its pipeline must
+// accept builds triggered by the `custom` event.
+package drone
@@ -0,0 +1,57 @@
+package drone
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "drone_requests",
+		Help: "Number of Drone API requests made by lighthouse.",
+	}, []string{
+		// http verb of the request
+		"verb",
+		// http status code of the request
+		"code",
+	})
+	requestLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "drone_request_latency",
+		Help:    "Time for a request to roundtrip between lighthouse and the Drone API.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{
+		// http verb of the request
+		"verb",
+	})
+	resyncPeriod = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "drone_resync_period_seconds",
+		Help:    "Time the Drone controller takes to complete one reconciliation loop.",
+		Buckets: prometheus.ExponentialBuckets(1, 3, 5),
+	})
+)
+
+func init() {
+	prometheus.MustRegister(requests)
+	prometheus.MustRegister(requestLatency)
+	prometheus.MustRegister(resyncPeriod)
+}
+
+// ClientMetrics is a set of metrics gathered by the Drone client.
+type ClientMetrics struct {
+	Requests       *prometheus.CounterVec
+	RequestLatency *prometheus.HistogramVec
+}
+
+// Metrics is a set of metrics gathered by the Drone controller.
+type Metrics struct {
+	ClientMetrics *ClientMetrics
+	ResyncPeriod  prometheus.Histogram
+}
+
+// NewMetrics creates a new set of metrics for the Drone controller.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		ClientMetrics: &ClientMetrics{
+			Requests:       requests,
+			RequestLatency: requestLatency,
+		},
+		ResyncPeriod: resyncPeriod,
+	}
+}
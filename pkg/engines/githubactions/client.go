@@ -0,0 +1,279 @@
+package githubactions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultURL is the default GitHub API URL
+	DefaultURL = "https://api.github.com"
+
+	// Workflow run statuses and conclusions as returned by the GitHub API.
+	statusCompleted          = "completed"
+	conclusionSuccess        = "success"
+	conclusionNeutral        = "neutral"
+	conclusionSkipped        = "skipped"
+	conclusionFailure        = "failure"
+	conclusionTimedOut       = "timed_out"
+	conclusionStartupFailure = "startup_failure"
+	conclusionCancelled      = "cancelled"
+
+	// Inputs passed to every dispatched workflow.
+	lighthouseJobIDInput = "lighthouse_job_id"
+	jobNameInput         = "job_name"
+	jobTypeInput         = "job_type"
+	baseRefInput         = "base_ref"
+	baseSHAInput         = "base_sha"
+	pullNumberInput      = "pull_number"
+	pullSHAInput         = "pull_sha"
+)
+
+// WorkflowRun is a GitHub Actions workflow run.
+type WorkflowRun struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	DisplayTitle string    `json:"display_title"`
+	Event        string    `json:"event"`
+	HeadBranch   string    `json:"head_branch"`
+	HeadSHA      string    `json:"head_sha"`
+	Status       string    `json:"status"`
+	Conclusion   string    `json:"conclusion"`
+	HTMLURL      string    `json:"html_url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// IsRunning means the workflow run has not completed yet (it is either queued or in progress).
+func (r *WorkflowRun) IsRunning() bool {
+	return r.Status != statusCompleted
+}
+
+// IsSuccess means the workflow run completed successfully.
+func (r *WorkflowRun) IsSuccess() bool {
+	switch r.Conclusion {
+	case conclusionSuccess, conclusionNeutral, conclusionSkipped:
+		return !r.IsRunning()
+	}
+	return false
+}
+
+// IsFailure means the workflow run completed and failed.
+func (r *WorkflowRun) IsFailure() bool {
+	switch r.Conclusion {
+	case conclusionFailure, conclusionTimedOut, conclusionStartupFailure:
+		return !r.IsRunning()
+	}
+	return false
+}
+
+// IsAborted means the workflow run was cancelled.
+func (r *WorkflowRun) IsAborted() bool {
+	return !r.IsRunning() && r.Conclusion == conclusionCancelled
+}
+
+// MatchesJob returns true if the run was started for the given LighthouseJob.
+func (r *WorkflowRun) MatchesJob(lighthouseJobID string) bool {
+	return strings.Contains(r.DisplayTitle, lighthouseJobID) || strings.Contains(r.Name, lighthouseJobID)
+}
+
+// Client can interact with the GitHub Actions API.
+type Client struct {
+	client   *http.Client
+	baseURL  string
+	getToken func() []byte
+	dryRun   bool
+	logger   *logrus.Entry
+	metrics  *ClientMetrics
+}
+
+// NewClient instantiates a client with provided values.
+//
+// url: the GitHub API URL
+// getToken: function returning the token used to authenticate against the API
+// logger: the logger to use, a default one is created if nil
+// metrics: the metrics to collect, may be nil
+func NewClient(url string, dryRun bool, getToken func() []byte, logger *logrus.Entry, metrics *ClientMetrics) *Client {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	return &Client{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		baseURL:  strings.TrimSuffix(url, "/"),
+		getToken: getToken,
+		dryRun:   dryRun,
+		logger:   logger.WithField("client", "github-actions"),
+		metrics:  metrics,
+	}
+}
+
+// Dispatch sends the dispatch event that starts the workflow for the given LighthouseJob.
+func (c *Client) Dispatch(lighthouseJob *v1alpha1.LighthouseJob) error {
+	spec := &lighthouseJob.Spec
+	if spec.Refs == nil {
+		return errors.New("cannot dispatch a GitHub Actions workflow without refs")
+	}
+	if spec.GitHubActionsSpec == nil {
+		return errors.New("missing github_actions_spec")
+	}
+	c.logger.WithFields(jobutil.LighthouseJobFields(lighthouseJob)).Info("Dispatch")
+	if c.dryRun {
+		return nil
+	}
+
+	inputs := DispatchInputs(lighthouseJob)
+	actionsSpec := spec.GitHubActionsSpec
+	var path string
+	var body interface{}
+	switch actionsSpec.Event {
+	case job.RepositoryDispatchEvent:
+		eventType := actionsSpec.EventType
+		if eventType == "" {
+			eventType = spec.Job
+		}
+		path = fmt.Sprintf("/repos/%s/%s/dispatches", spec.Refs.Org, spec.Refs.Repo)
+		body = struct {
+			EventType     string            `json:"event_type"`
+			ClientPayload map[string]string `json:"client_payload"`
+		}{eventType, inputs}
+	default:
+		path = fmt.Sprintf("/repos/%s/%s/actions/workflows/%s/dispatches", spec.Refs.Org, spec.Refs.Repo, url.PathEscape(actionsSpec.Workflow))
+		body = struct {
+			Ref    string            `json:"ref"`
+			Inputs map[string]string `json:"inputs"`
+		}{spec.Refs.BaseRef, inputs}
+	}
+	_, err := c.request(http.MethodPost, path, body)
+	return err
+}
+
+// DispatchInputs returns the inputs sent to the workflow for the given LighthouseJob.
+func DispatchInputs(lighthouseJob *v1alpha1.LighthouseJob) map[string]string {
+	spec := &lighthouseJob.Spec
+	inputs := map[string]string{}
+	if spec.GitHubActionsSpec != nil {
+		for k, v := range spec.GitHubActionsSpec.Inputs {
+			inputs[k] = v
+		}
+	}
+	inputs[lighthouseJobIDInput] = lighthouseJob.Name
+	inputs[jobNameInput] = spec.Job
+	inputs[jobTypeInput] = string(spec.Type)
+	if spec.Refs != nil {
+		inputs[baseRefInput] = spec.Refs.BaseRef
+		inputs[baseSHAInput] = spec.Refs.BaseSHA
+		if len(spec.Refs.Pulls) > 0 {
+			inputs[pullNumberInput] = strconv.Itoa(spec.Refs.Pulls[0].Number)
+			inputs[pullSHAInput] = spec.Refs.Pulls[0].SHA
+		}
+	}
+	return inputs
+}
+
+// ListRuns lists the workflow runs of a repository triggered by the given event since the given time.
+func (c *Client) ListRuns(owner, repo, event string, since time.Time) ([]WorkflowRun, error) {
+	query := url.Values{}
+	query.Set("event", event)
+	query.Set("created", ">="+since.UTC().Format(time.RFC3339))
+	query.Set("per_page", "100")
+	data, err := c.request(http.MethodGet, fmt.Sprintf("/repos/%s/%s/actions/runs?%s", owner, repo, query.Encode()), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot list workflow runs for %s/%s", owner, repo)
+	}
+	page := struct {
+		WorkflowRuns []WorkflowRun `json:"workflow_runs"`
+	}{}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, errors.Wrapf(err, "cannot unmarshal workflow runs for %s/%s", owner, repo)
+	}
+	return page.WorkflowRuns, nil
+}
+
+// GetRun returns the workflow run with the given ID.
+func (c *Client) GetRun(owner, repo string, id int64) (*WorkflowRun, error) {
+	data, err := c.request(http.MethodGet, fmt.Sprintf("/repos/%s/%s/actions/runs/%d", owner, repo, id), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get workflow run %d for %s/%s", id, owner, repo)
+	}
+	var run WorkflowRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, errors.Wrapf(err, "cannot unmarshal workflow run %d for %s/%s", id, owner, repo)
+	}
+	return &run, nil
+}
+
+// CancelRun cancels the workflow run with the given ID.
+func (c *Client) CancelRun(owner, repo string, id int64) error {
+	c.logger.Debugf("CancelRun(%s/%s %d)", owner, repo, id)
+	if c.dryRun {
+		return nil
+	}
+	_, err := c.request(http.MethodPost, fmt.Sprintf("/repos/%s/%s/actions/runs/%d/cancel", owner, repo, id), nil)
+	return err
+}
+
+// Ping verifies the GitHub API is reachable and accepts the token, using the rate limit endpoint which does not count
+// against the rate limit.
+func (c *Client) Ping() error {
+	_, err := c.request(http.MethodGet, "/rate_limit", nil)
+	return err
+}
+
+func (c *Client) request(method, path string, body interface{}) ([]byte, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.getToken != nil {
+		req.Header.Set("Authorization", "token "+strings.TrimSpace(string(c.getToken())))
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if c.metrics != nil {
+		c.metrics.RequestLatency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if c.metrics != nil {
+		c.metrics.Requests.WithLabelValues(method, strconv.Itoa(resp.StatusCode)).Inc()
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("response not 2XX: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
@@ -0,0 +1,141 @@
+package githubactions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testJob(event string) *v1alpha1.LighthouseJob {
+	return &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc123"},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type:  job.PresubmitJob,
+			Agent: job.GitHubActionsAgent,
+			Job:   "unit",
+			Refs: &v1alpha1.Refs{
+				Org:     "org",
+				Repo:    "repo",
+				BaseRef: "master",
+				BaseSHA: "base-sha",
+				Pulls:   []v1alpha1.Pull{{Number: 7, SHA: "pull-sha"}},
+			},
+			GitHubActionsSpec: &v1alpha1.GitHubActionsSpec{
+				Workflow: "ci.yaml",
+				Event:    event,
+				Inputs:   map[string]string{"extra": "value"},
+			},
+		},
+	}
+}
+
+func TestDispatchWorkflow(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, false, func() []byte { return []byte("secret\n") }, nil, nil)
+	require.NoError(t, c.Dispatch(testJob("")))
+
+	assert.Equal(t, "/repos/org/repo/actions/workflows/ci.yaml/dispatches", gotPath)
+	assert.Equal(t, "token secret", gotAuth)
+	assert.Equal(t, "master", gotBody["ref"])
+	assert.Equal(t, map[string]interface{}{
+		"extra":             "value",
+		"lighthouse_job_id": "abc123",
+		"job_name":          "unit",
+		"job_type":          "presubmit",
+		"base_ref":          "master",
+		"base_sha":          "base-sha",
+		"pull_number":       "7",
+		"pull_sha":          "pull-sha",
+	}, gotBody["inputs"])
+}
+
+func TestDispatchRepository(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, false, nil, nil, nil)
+	require.NoError(t, c.Dispatch(testJob(job.RepositoryDispatchEvent)))
+
+	assert.Equal(t, "/repos/org/repo/dispatches", gotPath)
+	assert.Equal(t, "unit", gotBody["event_type"])
+	assert.Equal(t, "abc123", gotBody["client_payload"].(map[string]interface{})["lighthouse_job_id"])
+}
+
+func TestDispatchError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Unexpected inputs provided"}`, http.StatusUnprocessableEntity)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, false, nil, nil, nil)
+	err := c.Dispatch(testJob(""))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Unexpected inputs provided")
+}
+
+func TestListRuns(t *testing.T) {
+	since := time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/org/repo/actions/runs", r.URL.Path)
+		assert.Equal(t, "workflow_dispatch", r.URL.Query().Get("event"))
+		assert.Equal(t, ">=2020-07-01T10:00:00Z", r.URL.Query().Get("created"))
+		_, _ = w.Write([]byte(`{"total_count": 2, "workflow_runs": [
+			{"id": 1, "display_title": "unit other", "status": "in_progress"},
+			{"id": 2, "display_title": "unit abc123", "status": "completed", "conclusion": "success", "html_url": "https://github.com/org/repo/actions/runs/2"}
+		]}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, false, nil, nil, nil)
+	runs, err := c.ListRuns("org", "repo", job.WorkflowDispatchEvent, since)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.True(t, runs[0].IsRunning())
+	assert.False(t, runs[0].MatchesJob("abc123"))
+	assert.True(t, runs[1].MatchesJob("abc123"))
+	assert.True(t, runs[1].IsSuccess())
+}
+
+func TestWorkflowRunStates(t *testing.T) {
+	tests := []struct {
+		run                                WorkflowRun
+		running, success, failure, aborted bool
+	}{
+		{run: WorkflowRun{Status: "queued"}, running: true},
+		{run: WorkflowRun{Status: "completed", Conclusion: "success"}, success: true},
+		{run: WorkflowRun{Status: "completed", Conclusion: "skipped"}, success: true},
+		{run: WorkflowRun{Status: "completed", Conclusion: "failure"}, failure: true},
+		{run: WorkflowRun{Status: "completed", Conclusion: "timed_out"}, failure: true},
+		{run: WorkflowRun{Status: "completed", Conclusion: "cancelled"}, aborted: true},
+		{run: WorkflowRun{Status: "completed", Conclusion: "action_required"}},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.running, tc.run.IsRunning(), "running %+v", tc.run)
+		assert.Equal(t, tc.success, tc.run.IsSuccess(), "success %+v", tc.run)
+		assert.Equal(t, tc.failure, tc.run.IsFailure(), "failure %+v", tc.run)
+		assert.Equal(t, tc.aborted, tc.run.IsAborted(), "aborted %+v", tc.run)
+	}
+}
@@ -0,0 +1,239 @@
+package githubactions
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/artifacts"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	client "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/typed/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// runLookupTimeout is how long we wait for the workflow run to show up after dispatching it.
+const runLookupTimeout = 5 * time.Minute
+
+type lighthouseJobClient interface {
+	List(metav1.ListOptions) (*v1alpha1.LighthouseJobList, error)
+	UpdateStatus(*v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error)
+}
+
+type actionsClient interface {
+	Dispatch(*v1alpha1.LighthouseJob) error
+	ListRuns(owner, repo, event string, since time.Time) ([]WorkflowRun, error)
+	GetRun(owner, repo string, id int64) (*WorkflowRun, error)
+	CancelRun(owner, repo string, id int64) error
+}
+
+// Controller manages LighthouseJobs running as GitHub Actions workflows.
+type Controller struct {
+	lighthouseClient lighthouseJobClient
+	actionsClient    actionsClient
+	log              *logrus.Entry
+	// selector that will be applied on Lighthouse jobs.
+	selector string
+	clock    clock.Clock
+}
+
+// NewController creates a new Controller from the provided clients.
+func NewController(lighthouseClient client.LighthouseJobInterface, actionsClient *Client, logger *logrus.Entry, selector string) *Controller {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	return &Controller{
+		lighthouseClient: lighthouseClient,
+		actionsClient:    actionsClient,
+		log:              logger,
+		selector:         selector,
+		clock:            clock.RealClock{},
+	}
+}
+
+// Sync does one sync iteration.
+func (c *Controller) Sync() error {
+	jobList, err := c.lighthouseClient.List(metav1.ListOptions{LabelSelector: c.selector})
+	if err != nil {
+		return fmt.Errorf("error listing Lighthouse jobs: %v", err)
+	}
+	// the triggered jobs are started by decreasing priority
+	jobutil.SortByPriority(jobList.Items)
+
+	var syncErrs []error
+	for i := range jobList.Items {
+		lighthouseJob := jobList.Items[i]
+		if lighthouseJob.Spec.Agent != job.GitHubActionsAgent || lighthouseJob.Complete() {
+			continue
+		}
+		var err error
+		switch lighthouseJob.Status.State {
+		case v1alpha1.TriggeredState, "":
+			err = c.syncTriggeredJob(lighthouseJob)
+		case v1alpha1.PendingState, v1alpha1.RunningState:
+			err = c.syncPendingJob(lighthouseJob)
+		case v1alpha1.AbortedState:
+			err = c.syncAbortedJob(lighthouseJob)
+		}
+		if err != nil {
+			syncErrs = append(syncErrs, err)
+		}
+	}
+
+	if len(syncErrs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("errors syncing: %v", syncErrs)
+}
+
+func (c *Controller) syncTriggeredJob(lighthouseJob v1alpha1.LighthouseJob) error {
+	originalState := lighthouseJob.Status.State
+
+	if err := c.actionsClient.Dispatch(&lighthouseJob); err != nil {
+		c.log.WithError(err).WithFields(jobutil.LighthouseJobFields(&lighthouseJob)).Warn("Cannot dispatch GitHub Actions workflow")
+		lighthouseJob.Status.State = v1alpha1.ErrorState
+		lighthouseJob.Status.Description = "Error dispatching GitHub Actions workflow."
+		lighthouseJob.SetComplete()
+	} else {
+		lighthouseJob.Status.State = v1alpha1.PendingState
+		lighthouseJob.Status.Description = "GitHub Actions workflow dispatched."
+		lighthouseJob.Status.StartTime = metav1.NewTime(c.clock.Now())
+		lighthouseJob.Status.BuildID = lighthouseJob.Name
+		lighthouseJob.Status.ArtifactsURL = artifacts.URLForJob(&lighthouseJob.Spec, lighthouseJob.Name)
+	}
+	return c.updateStatus(&lighthouseJob, originalState)
+}
+
+func (c *Controller) syncPendingJob(lighthouseJob v1alpha1.LighthouseJob) error {
+	original := lighthouseJob.DeepCopy()
+	owner, repo := lighthouseJob.Spec.Refs.Org, lighthouseJob.Spec.Refs.Repo
+
+	var run *WorkflowRun
+	if lighthouseJob.Status.ActivityName == "" {
+		var err error
+		run, err = c.findRun(&lighthouseJob)
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			if c.clock.Since(lighthouseJob.Status.StartTime.Time) > runLookupTimeout {
+				lighthouseJob.Status.State = v1alpha1.ErrorState
+				lighthouseJob.Status.Description = "Error finding GitHub Actions workflow run."
+				lighthouseJob.SetComplete()
+				return c.updateStatus(&lighthouseJob, original.Status.State)
+			}
+			return nil
+		}
+		lighthouseJob.Status.ActivityName = strconv.FormatInt(run.ID, 10)
+		lighthouseJob.Status.ReportURL = run.HTMLURL
+	} else {
+		id, err := strconv.ParseInt(lighthouseJob.Status.ActivityName, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid workflow run ID %q for job %s: %v", lighthouseJob.Status.ActivityName, lighthouseJob.Name, err)
+		}
+		run, err = c.actionsClient.GetRun(owner, repo, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case run.IsRunning():
+		lighthouseJob.Status.State = v1alpha1.RunningState
+		lighthouseJob.Status.Description = "GitHub Actions workflow running."
+	case run.IsSuccess():
+		lighthouseJob.Status.State = v1alpha1.SuccessState
+		lighthouseJob.Status.Description = "GitHub Actions workflow succeeded."
+		lighthouseJob.SetComplete()
+	case run.IsFailure():
+		lighthouseJob.Status.State = v1alpha1.FailureState
+		lighthouseJob.Status.Description = "GitHub Actions workflow failed."
+		lighthouseJob.SetComplete()
+	case run.IsAborted():
+		lighthouseJob.Status.State = v1alpha1.AbortedState
+		lighthouseJob.Status.Description = "GitHub Actions workflow cancelled."
+		lighthouseJob.SetComplete()
+	default:
+		lighthouseJob.Status.State = v1alpha1.ErrorState
+		lighthouseJob.Status.Description = fmt.Sprintf("GitHub Actions workflow completed with conclusion %q.", run.Conclusion)
+		lighthouseJob.SetComplete()
+	}
+
+	if original.Status.State == lighthouseJob.Status.State && original.Status.ReportURL == lighthouseJob.Status.ReportURL {
+		return nil
+	}
+	return c.updateStatus(&lighthouseJob, original.Status.State)
+}
+
+func (c *Controller) syncAbortedJob(lighthouseJob v1alpha1.LighthouseJob) error {
+	if lighthouseJob.Status.ActivityName != "" {
+		id, err := strconv.ParseInt(lighthouseJob.Status.ActivityName, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid workflow run ID %q for job %s: %v", lighthouseJob.Status.ActivityName, lighthouseJob.Name, err)
+		}
+		err = c.actionsClient.CancelRun(lighthouseJob.Spec.Refs.Org, lighthouseJob.Spec.Refs.Repo, id)
+		audit.Record(nil, audit.JobEvent(audit.JobAborted, &lighthouseJob), err)
+		if err != nil {
+			return fmt.Errorf("failed to cancel GitHub Actions workflow run: %v", err)
+		}
+	}
+
+	lighthouseJob.SetComplete()
+	c.addActivity(&lighthouseJob)
+
+	_, err := c.lighthouseClient.UpdateStatus(&lighthouseJob)
+	return err
+}
+
+// findRun looks for the workflow run that was started by dispatching the given job.
+func (c *Controller) findRun(lighthouseJob *v1alpha1.LighthouseJob) (*WorkflowRun, error) {
+	event := job.WorkflowDispatchEvent
+	if lighthouseJob.Spec.GitHubActionsSpec != nil && lighthouseJob.Spec.GitHubActionsSpec.Event != "" {
+		event = lighthouseJob.Spec.GitHubActionsSpec.Event
+	}
+	// allow for some clock skew between the cluster and GitHub
+	since := lighthouseJob.Status.StartTime.Add(-time.Minute)
+	runs, err := c.actionsClient.ListRuns(lighthouseJob.Spec.Refs.Org, lighthouseJob.Spec.Refs.Repo, event, since)
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		if runs[i].MatchesJob(lighthouseJob.Name) {
+			return &runs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *Controller) updateStatus(lighthouseJob *v1alpha1.LighthouseJob, previousState v1alpha1.PipelineState) error {
+	c.log.WithFields(jobutil.LighthouseJobFields(lighthouseJob)).
+		WithField("from", previousState).
+		WithField("to", lighthouseJob.Status.State).Info("Transitioning states.")
+	// make sure to set an activity record this job state update
+	c.addActivity(lighthouseJob)
+	_, err := c.lighthouseClient.UpdateStatus(lighthouseJob)
+	return err
+}
+
+func (c *Controller) addActivity(lighthouseJob *v1alpha1.LighthouseJob) {
+	activity := v1alpha1.ActivityRecord{
+		Name:            lighthouseJob.Name,
+		Status:          lighthouseJob.Status.State,
+		StartTime:       &lighthouseJob.Status.StartTime,
+		CompletionTime:  lighthouseJob.Status.CompletionTime,
+		Owner:           lighthouseJob.Labels[util.OrgLabel],
+		Repo:            lighthouseJob.Labels[util.RepoLabel],
+		GitURL:          lighthouseJob.Annotations[util.CloneURIAnnotation],
+		LastCommitSHA:   lighthouseJob.Labels[util.LastCommitSHALabel],
+		Branch:          lighthouseJob.Labels[util.BranchLabel],
+		BuildIdentifier: lighthouseJob.Status.ActivityName,
+		Context:         lighthouseJob.Labels[util.ContextLabel],
+	}
+
+	lighthouseJob.Status.Activity = &activity
+}
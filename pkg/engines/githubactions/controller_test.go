@@ -0,0 +1,138 @@
+package githubactions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+type fakeActionsClient struct {
+	dispatched []string
+	cancelled  []int64
+	runs       []WorkflowRun
+}
+
+func (f *fakeActionsClient) Dispatch(j *v1alpha1.LighthouseJob) error {
+	f.dispatched = append(f.dispatched, j.Name)
+	return nil
+}
+
+func (f *fakeActionsClient) ListRuns(owner, repo, event string, since time.Time) ([]WorkflowRun, error) {
+	return f.runs, nil
+}
+
+func (f *fakeActionsClient) GetRun(owner, repo string, id int64) (*WorkflowRun, error) {
+	for i := range f.runs {
+		if f.runs[i].ID == id {
+			return &f.runs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeActionsClient) CancelRun(owner, repo string, id int64) error {
+	f.cancelled = append(f.cancelled, id)
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	now := time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+	lhJob := testJob("")
+	lhJob.Namespace = "jx"
+	lhJob.Status.State = v1alpha1.TriggeredState
+
+	lhClient := fake.NewSimpleClientset(lhJob).LighthouseV1alpha1().LighthouseJobs("jx")
+	actions := &fakeActionsClient{}
+	fakeClock := clock.NewFakeClock(now)
+	c := &Controller{
+		lighthouseClient: lhClient,
+		actionsClient:    actions,
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		clock:            fakeClock,
+	}
+
+	getJob := func() *v1alpha1.LighthouseJob {
+		j, err := lhClient.Get(lhJob.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		return j
+	}
+
+	// triggered jobs get dispatched
+	require.NoError(t, c.Sync())
+	assert.Equal(t, []string{"abc123"}, actions.dispatched)
+	assert.Equal(t, v1alpha1.PendingState, getJob().Status.State)
+
+	// no matching run yet, nothing changes
+	require.NoError(t, c.Sync())
+	assert.Equal(t, v1alpha1.PendingState, getJob().Status.State)
+	assert.Empty(t, getJob().Status.ActivityName)
+
+	// the run shows up
+	actions.runs = []WorkflowRun{{ID: 42, DisplayTitle: "unit abc123", Status: "in_progress", HTMLURL: "https://github.com/org/repo/actions/runs/42"}}
+	require.NoError(t, c.Sync())
+	j := getJob()
+	assert.Equal(t, v1alpha1.RunningState, j.Status.State)
+	assert.Equal(t, "42", j.Status.ActivityName)
+	assert.Equal(t, "https://github.com/org/repo/actions/runs/42", j.Status.ReportURL)
+	require.NotNil(t, j.Status.Activity)
+	assert.Equal(t, v1alpha1.RunningState, j.Status.Activity.Status)
+
+	// the run completes
+	actions.runs[0].Status = "completed"
+	actions.runs[0].Conclusion = "failure"
+	require.NoError(t, c.Sync())
+	j = getJob()
+	assert.Equal(t, v1alpha1.FailureState, j.Status.State)
+	assert.True(t, j.Complete())
+}
+
+func TestSyncRunNotFound(t *testing.T) {
+	now := time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+	lhJob := testJob("")
+	lhJob.Namespace = "jx"
+	lhJob.Status.State = v1alpha1.PendingState
+	lhJob.Status.StartTime = metav1.NewTime(now.Add(-10 * time.Minute))
+
+	lhClient := fake.NewSimpleClientset(lhJob).LighthouseV1alpha1().LighthouseJobs("jx")
+	c := &Controller{
+		lighthouseClient: lhClient,
+		actionsClient:    &fakeActionsClient{},
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		clock:            clock.NewFakeClock(now),
+	}
+	require.NoError(t, c.Sync())
+
+	j, err := lhClient.Get(lhJob.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.ErrorState, j.Status.State)
+	assert.True(t, j.Complete())
+}
+
+func TestSyncAborted(t *testing.T) {
+	lhJob := testJob("")
+	lhJob.Namespace = "jx"
+	lhJob.Status.State = v1alpha1.AbortedState
+	lhJob.Status.ActivityName = "42"
+
+	lhClient := fake.NewSimpleClientset(lhJob).LighthouseV1alpha1().LighthouseJobs("jx")
+	actions := &fakeActionsClient{}
+	c := &Controller{
+		lighthouseClient: lhClient,
+		actionsClient:    actions,
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		clock:            clock.RealClock{},
+	}
+	require.NoError(t, c.Sync())
+	assert.Equal(t, []int64{42}, actions.cancelled)
+
+	j, err := lhClient.Get(lhJob.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, j.Complete())
+}
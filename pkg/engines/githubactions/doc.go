@@ -0,0 +1,13 @@
+// Package githubactions includes a client and the controller logic for
+// running LighthouseJobs as GitHub Actions workflows.
+//
+// Jobs are started by sending a `workflow_dispatch` event to the configured
+// workflow, or a `repository_dispatch` event to the repository, with the
+// refs under test passed as inputs. The controller then polls the workflow
+// runs API and maps the run conclusion back to the LighthouseJob status.
+//
+// Since the dispatch APIs do not return the run they create, the workflow
+// must set its `run-name` to include the `lighthouse_job_id` input (or
+// `client_payload.lighthouse_job_id` for repository_dispatch) so that the
+// controller can find the run it started.
+package githubactions
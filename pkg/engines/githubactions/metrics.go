@@ -0,0 +1,57 @@
+package githubactions
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "github_actions_requests",
+		Help: "Number of GitHub Actions API requests made by lighthouse.",
+	}, []string{
+		// http verb of the request
+		"verb",
+		// http status code of the request
+		"code",
+	})
+	requestLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "github_actions_request_latency",
+		Help:    "Time for a request to roundtrip between lighthouse and the GitHub Actions API.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{
+		// http verb of the request
+		"verb",
+	})
+	resyncPeriod = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "github_actions_resync_period_seconds",
+		Help:    "Time the GitHub Actions controller takes to complete one reconciliation loop.",
+		Buckets: prometheus.ExponentialBuckets(1, 3, 5),
+	})
+)
+
+func init() {
+	prometheus.MustRegister(requests)
+	prometheus.MustRegister(requestLatency)
+	prometheus.MustRegister(resyncPeriod)
+}
+
+// ClientMetrics is a set of metrics gathered by the GitHub Actions client.
+type ClientMetrics struct {
+	Requests       *prometheus.CounterVec
+	RequestLatency *prometheus.HistogramVec
+}
+
+// Metrics is a set of metrics gathered by the GitHub Actions controller.
+type Metrics struct {
+	ClientMetrics *ClientMetrics
+	ResyncPeriod  prometheus.Histogram
+}
+
+// NewMetrics creates a new set of metrics for the GitHub Actions controller.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		ClientMetrics: &ClientMetrics{
+			Requests:       requests,
+			RequestLatency: requestLatency,
+		},
+		ResyncPeriod: resyncPeriod,
+	}
+}
@@ -23,7 +23,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -37,17 +40,24 @@ import (
 
 const (
 	// Maximum retries for a request to Jenkins.
-	// Retries on transport failures and 500s.
+	// Retries on transport failures, on 502s, 503s and 504s returned
+	// while Jenkins restarts, and on 500s for the read-only requests.
 	maxRetries = 5
 	// Backoff delay used after a request retry.
 	// Doubles on every retry.
 	retryDelay = 100 * time.Millisecond
+	// Maximum delay honoured from the Retry-After header of a response.
+	maxRetryAfter = 30 * time.Second
 	// Name of environment variable to add to Jenkins jobs to identify matching Lighthouse job
 	lighthouseJobIDEnv = "LIGHTHOUSE_JOB_ID"
 	// Name of environment variable to identify a specific build for a given job
 	buildIDEnv = "BUILD_ID"
 )
 
+// queueItemLocation matches the Location header returned by Jenkins when
+// a build is enqueued, e.g. https://jenkins/queue/item/123/
+var queueItemLocation = regexp.MustCompile(`/queue/item/(\d+)/?$`)
+
 // NotFoundError is returned by the Jenkins client when
 // a job does not exist in Jenkins.
 type NotFoundError struct {
@@ -72,6 +82,8 @@ type Client struct {
 	client     *http.Client
 	baseURL    string
 	authConfig *AuthConfig
+	// crumbLock guards the CSRF protection token of authConfig.
+	crumbLock sync.RWMutex
 
 	metrics *ClientMetrics
 }
@@ -87,6 +99,10 @@ type AuthConfig struct {
 	// CSRFProtect ensures the client will acquire a CSRF protection
 	// token from Jenkins to use it in mutating requests. Required
 	// for masters that prevent cross site request forgery exploits.
+	// The token is negotiated before the first mutating request and
+	// renewed when Jenkins rejects it, e.g. after a restart. No token
+	// is used if the crumb issuer of Jenkins is disabled, e.g. because
+	// the API tokens are exempted from the CSRF protection.
 	CSRFProtect bool
 	// csrfToken is the token acquired from Jenkins for CSRF protection.
 	// Needs to be used as the header value in subsequent mutating requests.
@@ -94,6 +110,22 @@ type AuthConfig struct {
 	// csrfRequestField is a key acquired from Jenkins for CSRF protection.
 	// Needs to be used as the header key in subsequent mutating requests.
 	csrfRequestField string
+	// csrfNegotiated is set once the token was acquired, or Jenkins
+	// answered that it does not issue any.
+	csrfNegotiated bool
+}
+
+// Authenticate sets the basic or bearer token credentials of the configuration on the request
+func (a *AuthConfig) Authenticate(req *http.Request) {
+	if a == nil {
+		return
+	}
+	if a.Basic != nil {
+		req.SetBasicAuth(a.Basic.User, string(a.Basic.GetToken()))
+	}
+	if a.BearerToken != nil {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.BearerToken.GetToken()))
+	}
 }
 
 // BasicAuthConfig authenticates with jenkins using user/pass.
@@ -133,6 +165,11 @@ func NewClient(
 	if logger == nil {
 		logger = logrus.NewEntry(logrus.StandardLogger())
 	}
+	// the crumbs are bound to the web session they were issued for
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
 	c := &Client{
 		logger:     logger.WithField("client", "jenkins"),
 		dryRun:     dryRun,
@@ -140,15 +177,17 @@ func NewClient(
 		authConfig: authConfig,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
+			Jar:     jar,
 		},
 		metrics: metrics,
 	}
 	if tlsConfig != nil {
 		c.client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
 	}
-	if c.authConfig.CSRFProtect {
+	if c.usesCrumbs() {
+		// Jenkins may be restarting, the crumb is negotiated again before the first mutating request
 		if err := c.CrumbRequest(); err != nil {
-			return nil, fmt.Errorf("cannot get Jenkins crumb: %v", err)
+			c.logger.WithError(err).Warn("Cannot get Jenkins crumb yet")
 		}
 	}
 	return c, nil
@@ -156,13 +195,22 @@ func NewClient(
 
 // CrumbRequest requests a CSRF protection token from Jenkins to
 // use it in subsequent requests. Required for Jenkins masters that
-// prevent cross site request forgery exploits.
+// prevent cross site request forgery exploits. No token is used if
+// Jenkins does not issue any.
 func (c *Client) CrumbRequest() error {
-	if c.authConfig.csrfToken != "" && c.authConfig.csrfRequestField != "" {
+	c.crumbLock.RLock()
+	negotiated := c.authConfig.csrfNegotiated
+	c.crumbLock.RUnlock()
+	if negotiated {
 		return nil
 	}
 	c.logger.Debug("CrumbRequest")
 	data, err := c.GetSkipMetrics("/crumbIssuer/api/json")
+	if _, isNotFound := err.(NotFoundError); isNotFound {
+		c.logger.Info("Jenkins does not issue crumbs, sending the requests without CSRF protection token")
+		c.setCrumb("", "")
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -173,11 +221,40 @@ func (c *Client) CrumbRequest() error {
 	if err := json.Unmarshal(data, &crumbResp); err != nil {
 		return fmt.Errorf("cannot unmarshal crumb response: %v", err)
 	}
-	c.authConfig.csrfToken = crumbResp.Crumb
-	c.authConfig.csrfRequestField = crumbResp.CrumbRequestField
+	c.setCrumb(crumbResp.CrumbRequestField, crumbResp.Crumb)
 	return nil
 }
 
+// usesCrumbs returns true if the client protects its mutating requests with a CSRF protection token.
+func (c *Client) usesCrumbs() bool {
+	return c.authConfig != nil && c.authConfig.CSRFProtect
+}
+
+// crumb returns the header key and value of the CSRF protection token, empty if none is used.
+func (c *Client) crumb() (string, string) {
+	c.crumbLock.RLock()
+	defer c.crumbLock.RUnlock()
+	return c.authConfig.csrfRequestField, c.authConfig.csrfToken
+}
+
+// setCrumb records the negotiated CSRF protection token.
+func (c *Client) setCrumb(field, token string) {
+	c.crumbLock.Lock()
+	defer c.crumbLock.Unlock()
+	c.authConfig.csrfRequestField = field
+	c.authConfig.csrfToken = token
+	c.authConfig.csrfNegotiated = true
+}
+
+// invalidateCrumb forgets the CSRF protection token rejected by Jenkins, so that a new one is negotiated.
+func (c *Client) invalidateCrumb() {
+	c.crumbLock.Lock()
+	defer c.crumbLock.Unlock()
+	c.authConfig.csrfRequestField = ""
+	c.authConfig.csrfToken = ""
+	c.authConfig.csrfNegotiated = false
+}
+
 // measure records metrics about the provided method, path, and code.
 // start needs to be recorded before doing the request.
 func (c *Client) measure(method, path string, code int, start time.Time) {
@@ -229,7 +306,9 @@ func readResp(resp *http.Response) ([]byte, error) {
 }
 
 // request executes a request with the provided method and path.
-// It retries on transport failures and 500s. measure is provided
+// It retries on transport failures and on the 5xx returned while
+// Jenkins restarts, and renews the CSRF protection token of the
+// mutating requests once if Jenkins rejects it. measure is provided
 // to enable or disable gathering metrics for specific requests
 // to avoid high-cardinality metrics.
 func (c *Client) request(method, path string, params url.Values, measure bool) (*http.Response, error) {
@@ -241,28 +320,96 @@ func (c *Client) request(method, path string, params url.Values, measure bool) (
 	if params != nil {
 		urlPath = fmt.Sprintf("%s?%s", urlPath, params.Encode())
 	}
+	mutating := method != http.MethodGet && method != http.MethodHead
+	if mutating && c.usesCrumbs() {
+		if err := c.CrumbRequest(); err != nil {
+			c.countFailure(method, "crumb")
+			return nil, fmt.Errorf("cannot get Jenkins crumb: %v", err)
+		}
+	}
 
 	start := time.Now()
+	crumbRenewed := false
 	for retries := 0; retries < maxRetries; retries++ {
 		resp, err = c.doRequest(method, urlPath)
-		if err == nil && resp.StatusCode < 500 {
+		if err == nil && resp.StatusCode == http.StatusForbidden && mutating && c.usesCrumbs() && !crumbRenewed {
+			// the crumb expired, e.g. because Jenkins restarted, so negotiate a new one
+			crumbRenewed = true
+			_ = resp.Body.Close()
+			c.invalidateCrumb()
+			if err := c.CrumbRequest(); err != nil {
+				c.countFailure(method, "crumb")
+				return nil, fmt.Errorf("cannot renew Jenkins crumb: %v", err)
+			}
+			continue
+		}
+		if !retryable(method, resp, err) || retries+1 == maxRetries {
 			break
-		} else if err == nil && retries+1 < maxRetries {
+		}
+		delay := retryAfter(resp, backoff)
+		if resp != nil {
 			_ = resp.Body.Close()
 		}
 		// Capture the retry in a metric.
 		if measure && c.metrics != nil {
 			c.metrics.RequestRetries.Inc()
 		}
-		time.Sleep(backoff)
+		time.Sleep(delay)
 		backoff *= 2
 	}
 	if measure && resp != nil {
 		c.measure(method, path, resp.StatusCode, start)
 	}
+	if err != nil {
+		c.countFailure(method, "error")
+	} else if resp.StatusCode >= 500 || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		c.countFailure(method, strconv.Itoa(resp.StatusCode))
+	}
 	return resp, err
 }
 
+// retryable returns true if the request can be retried given its response: on transport failures and the
+// gateway errors returned while Jenkins restarts, and on internal errors only if the request is read-only as
+// Jenkins may have processed it.
+func retryable(method string, resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusInternalServerError:
+		return method == http.MethodGet || method == http.MethodHead
+	}
+	return false
+}
+
+// retryAfter returns the delay before retrying the request: the one asked by the Retry-After header of the
+// response in seconds if any, capped by maxRetryAfter, or the backoff otherwise.
+func retryAfter(resp *http.Response, backoff time.Duration) time.Duration {
+	if resp == nil {
+		return backoff
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return backoff
+	}
+	delay := time.Duration(seconds) * time.Second
+	if delay > maxRetryAfter {
+		return maxRetryAfter
+	}
+	return delay
+}
+
+// countFailure records a request which failed once retried, with the status code of its response or the reason
+// it failed.
+func (c *Client) countFailure(method, code string) {
+	if c.metrics == nil || c.metrics.RequestFailures == nil {
+		return
+	}
+	c.metrics.RequestFailures.WithLabelValues(method, code).Inc()
+}
+
 // doRequest executes a request with the provided method and path
 // exactly once. It sets up authentication if the jenkins client
 // is configured accordingly. It's up to callers of this function
@@ -273,14 +420,11 @@ func (c *Client) doRequest(method, path string) (*http.Response, error) {
 		return nil, err
 	}
 	if c.authConfig != nil {
-		if c.authConfig.Basic != nil {
-			req.SetBasicAuth(c.authConfig.Basic.User, string(c.authConfig.Basic.GetToken()))
-		}
-		if c.authConfig.BearerToken != nil {
-			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.authConfig.BearerToken.GetToken()))
-		}
-		if c.authConfig.CSRFProtect && c.authConfig.csrfRequestField != "" && c.authConfig.csrfToken != "" {
-			req.Header.Set(c.authConfig.csrfRequestField, c.authConfig.csrfToken)
+		c.authConfig.Authenticate(req)
+		if c.authConfig.CSRFProtect {
+			if field, token := c.crumb(); field != "" && token != "" {
+				req.Header.Set(field, token)
+			}
 		}
 	}
 	return c.client.Do(req)
@@ -288,15 +432,42 @@ func (c *Client) doRequest(method, path string) (*http.Response, error) {
 
 // getJobName generates the correct job name for this job type
 func getJobName(spec *v1alpha1.LighthouseJobSpec) string {
-	if spec.JenkinsSpec != nil && spec.JenkinsSpec.BranchSourceJob && spec.Refs != nil {
+	jenkinsSpec := spec.JenkinsSpec
+	if jenkinsSpec == nil {
+		return spec.Job
+	}
+	prefix := getFolderPath(jenkinsSpec.Folder)
+
+	if jenkinsSpec.BranchSourceJob && spec.Refs != nil {
+		if len(spec.Refs.Pulls) > 0 {
+			return fmt.Sprintf("%s%s/job/%s/view/change-requests/job/PR-%d", prefix, spec.Refs.Org, spec.Job, spec.Refs.Pulls[0].Number)
+		}
+
+		return fmt.Sprintf("%s%s/job/%s/job/%s", prefix, spec.Refs.Org, spec.Job, spec.Refs.BaseRef)
+	}
+
+	if jenkinsSpec.MultiBranch && spec.Refs != nil {
 		if len(spec.Refs.Pulls) > 0 {
-			return fmt.Sprintf("%s/job/%s/view/change-requests/job/PR-%d", spec.Refs.Org, spec.Job, spec.Refs.Pulls[0].Number)
+			return fmt.Sprintf("%s%s/job/PR-%d", prefix, spec.Job, spec.Refs.Pulls[0].Number)
 		}
 
-		return fmt.Sprintf("%s/job/%s/job/%s", spec.Refs.Org, spec.Job, spec.Refs.BaseRef)
+		// multibranch pipelines encode slashes in branch names, which need to be escaped again in the URL
+		return fmt.Sprintf("%s%s/job/%s", prefix, spec.Job, url.PathEscape(url.PathEscape(spec.Refs.BaseRef)))
 	}
 
-	return spec.Job
+	return prefix + spec.Job
+}
+
+// getFolderPath returns the job path prefix for a slash separated folder path.
+func getFolderPath(folder string) string {
+	var prefix string
+	for _, part := range strings.Split(folder, "/") {
+		if part == "" {
+			continue
+		}
+		prefix += url.PathEscape(part) + "/job/"
+	}
+	return prefix
 }
 
 // getJobInfoPath builds an appropriate path to use for this Jenkins Job to get the job information
@@ -394,7 +565,7 @@ func (c *Client) EnsureBuildableJob(spec *v1alpha1.LighthouseJobSpec) error {
 		return nil
 	}
 
-	buildErr := c.LaunchBuild(spec, nil)
+	_, buildErr := c.LaunchBuild(spec, nil)
 
 	if buildErr != nil {
 		return buildErr
@@ -432,8 +603,9 @@ func (c *Client) EnsureBuildableJob(spec *v1alpha1.LighthouseJobSpec) error {
 }
 
 // LaunchBuild launches a regular or parameterized Jenkins build, depending on
-// whether or not we have `params` to POST
-func (c *Client) LaunchBuild(spec *v1alpha1.LighthouseJobSpec, params url.Values) error {
+// whether or not we have `params` to POST. It returns the ID of the queue item
+// created by Jenkins, or 0 if it could not be determined.
+func (c *Client) LaunchBuild(spec *v1alpha1.LighthouseJobSpec, params url.Values) (int, error) {
 	var path string
 
 	if params != nil {
@@ -447,7 +619,7 @@ func (c *Client) LaunchBuild(spec *v1alpha1.LighthouseJobSpec, params url.Values
 	resp, err := c.request(http.MethodPost, path, params, true)
 
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	defer func() {
@@ -455,25 +627,40 @@ func (c *Client) LaunchBuild(spec *v1alpha1.LighthouseJobSpec, params url.Values
 	}()
 
 	if resp.StatusCode != 201 {
-		return fmt.Errorf("response not 201: %s", resp.Status)
+		return 0, fmt.Errorf("response not 201: %s", resp.Status)
 	}
 
-	return nil
+	return getQueueItemID(resp.Header.Get("Location")), nil
+}
+
+// getQueueItemID extracts the queue item ID from the Location header
+// of the build response.
+func getQueueItemID(location string) int {
+	m := queueItemLocation.FindStringSubmatch(location)
+	if m == nil {
+		return 0
+	}
+	id, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0
+	}
+	return id
 }
 
 // Build triggers a Jenkins build for the provided LighthouseJob. The name of
 // the LighthouseJob is going to be used as the Lighthouse Job ID parameter that will
-// help us track the build before it's scheduled by Jenkins.
-func (c *Client) Build(job *v1alpha1.LighthouseJob, buildID string) error {
+// help us track the build before it's scheduled by Jenkins. The ID of the queue
+// item is returned so the build can be tracked while it waits in the queue.
+func (c *Client) Build(job *v1alpha1.LighthouseJob, buildID string) (int, error) {
 	c.logger.WithFields(jobutil.LighthouseJobFields(job)).Info("Build")
 	return c.BuildFromSpec(&job.Spec, buildID, job.ObjectMeta.Name)
 }
 
 // BuildFromSpec triggers a Jenkins build for the provided LighthouseJobSpec.
 // lighthouseJobID helps us track the build before it's scheduled by Jenkins.
-func (c *Client) BuildFromSpec(spec *v1alpha1.LighthouseJobSpec, buildID, lighthouseJobID string) error {
+func (c *Client) BuildFromSpec(spec *v1alpha1.LighthouseJobSpec, buildID, lighthouseJobID string) (int, error) {
 	if c.dryRun {
-		return nil
+		return 0, nil
 	}
 
 	var env = spec.GetEnvVars()
@@ -486,7 +673,7 @@ func (c *Client) BuildFromSpec(spec *v1alpha1.LighthouseJobSpec, buildID, lighth
 	}
 
 	if err := c.EnsureBuildableJob(spec); err != nil {
-		return fmt.Errorf("job %v cannot be build: %v", spec.Job, err)
+		return 0, fmt.Errorf("job %v cannot be build: %v", spec.Job, err)
 	}
 
 	return c.LaunchBuild(spec, params)
@@ -554,6 +741,9 @@ func (c *Client) GetEnqueuedBuilds(jobs []BuildQueryParams) (map[string]Build, e
 	if err := json.Unmarshal(data, &page); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal builds from the queue: %v", err)
 	}
+	if c.metrics != nil && c.metrics.QueueDepth != nil {
+		c.metrics.QueueDepth.Set(float64(len(page.QueuedBuilds)))
+	}
 	jenkinsBuilds := make(map[string]Build)
 	for _, jb := range page.QueuedBuilds {
 		lighthouseJobID := jb.LighthouseJobID()
@@ -611,6 +801,43 @@ func (c *Client) GetBuilds(job string) (map[string]Build, error) {
 	return jenkinsBuilds, nil
 }
 
+// GetQueueItem returns the queue item with the given ID. Jenkins only keeps
+// queue items for a few minutes after their build started, a NotFoundError
+// is returned after that.
+func (c *Client) GetQueueItem(id int) (*QueueItem, error) {
+	c.logger.Debugf("GetQueueItem(%d)", id)
+
+	data, err := c.GetSkipMetrics(fmt.Sprintf("/queue/item/%d/api/json", id))
+	if err != nil {
+		return nil, err
+	}
+	var item QueueItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal queue item %d: %v", id, err)
+	}
+	return &item, nil
+}
+
+// CancelQueueItem removes the queue item with the given ID from the Jenkins queue.
+func (c *Client) CancelQueueItem(id int) error {
+	c.logger.Debugf("CancelQueueItem(%d)", id)
+	if c.dryRun {
+		return nil
+	}
+	resp, err := c.request(http.MethodPost, "/queue/cancelItem", url.Values{"id": []string{strconv.Itoa(id)}}, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	// older Jenkins versions answer with a redirect or a 404 even on success
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("response not 2XX: %s", resp.Status)
+	}
+	return nil
+}
+
 // Abort aborts the provided Jenkins build for job.
 func (c *Client) Abort(job string, build *Build) error {
 	c.logger.Debugf("Abort(%v %v)", job, build.Number)
@@ -629,3 +856,8 @@ func (c *Client) Abort(job string, build *Build) error {
 	}
 	return nil
 }
+
+// ConsoleTextPath returns the path of the console output of the given build of the job
+func ConsoleTextPath(spec *v1alpha1.LighthouseJobSpec, buildID string) string {
+	return fmt.Sprintf("/job/%s/%s/consoleText", getJobName(spec), buildID)
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/sirupsen/logrus"
 )
 
@@ -355,7 +356,7 @@ func TestBuildCreate(t *testing.T) {
 				baseURL: ts.URL,
 			}
 
-			buildErr := jc.BuildFromSpec(testCase.input, "buildID", "prowJobID")
+			_, buildErr := jc.BuildFromSpec(testCase.input, "buildID", "prowJobID")
 
 			if buildErr != nil && !testCase.expectError {
 				t.Errorf("%s: unexpected build error: %v", testCase.name, buildErr)
@@ -431,6 +432,59 @@ func TestGetJobName(t *testing.T) {
 			},
 			output: "my-k8s-job-name",
 		},
+		{
+			name: "Job in folders",
+			input: &v1alpha1.LighthouseJobSpec{
+				Agent: "jenkins",
+				Job:   "my-jenkins-job-name",
+				JenkinsSpec: &v1alpha1.JenkinsSpec{
+					Folder: "team/backend/",
+				},
+				Refs: &v1alpha1.Refs{
+					BaseRef: "master",
+					BaseSHA: "deadbeef",
+				},
+			},
+			output: "team/job/backend/job/my-jenkins-job-name",
+		},
+		{
+			name: "Multibranch PR job in a folder",
+			input: &v1alpha1.LighthouseJobSpec{
+				Agent: "jenkins",
+				Job:   "my-jenkins-job-name",
+				JenkinsSpec: &v1alpha1.JenkinsSpec{
+					Folder:      "team",
+					MultiBranch: true,
+				},
+				Refs: &v1alpha1.Refs{
+					BaseRef: "master",
+					BaseSHA: "deadbeef",
+					Pulls: []v1alpha1.Pull{
+						{
+							Number: 123,
+							SHA:    "abcd1234",
+						},
+					},
+				},
+			},
+			output: "team/job/my-jenkins-job-name/job/PR-123",
+		},
+		{
+			name: "Multibranch branch job",
+			input: &v1alpha1.LighthouseJobSpec{
+				Agent: "jenkins",
+				Type:  job.PostsubmitJob,
+				Job:   "my-jenkins-job-name",
+				JenkinsSpec: &v1alpha1.JenkinsSpec{
+					MultiBranch: true,
+				},
+				Refs: &v1alpha1.Refs{
+					BaseRef: "release/1.0",
+					BaseSHA: "deadbeef",
+				},
+			},
+			output: "my-jenkins-job-name/job/release%252F1.0",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -677,3 +731,172 @@ func TestGetBuildWithParametersPath(t *testing.T) {
 		})
 	}
 }
+
+func TestGetQueueItemID(t *testing.T) {
+	testCases := map[string]int{
+		"https://jenkins.example.com/queue/item/123/": 123,
+		"/queue/item/42":                              42,
+		"":                                            0,
+		"https://jenkins.example.com/job/foo/":        0,
+	}
+	for location, expected := range testCases {
+		if actual := getQueueItemID(location); actual != expected {
+			t.Errorf("%q: expected queue item %d, got %d", location, expected, actual)
+		}
+	}
+}
+
+func TestGetQueueItem(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/queue/item/1/api/json":
+			_, _ = fmt.Fprint(w, `{"id": 1, "why": "Waiting for next available executor", "executable": null}`)
+		case "/queue/item/2/api/json":
+			_, _ = fmt.Fprint(w, `{"id": 2, "executable": {"number": 7, "url": "https://jenkins/job/foo/7/"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	jc := Client{
+		logger:  logrus.WithField("client", "jenkins"),
+		client:  ts.Client(),
+		baseURL: ts.URL,
+	}
+
+	item, err := jc.GetQueueItem(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.IsStarted() || item.Why == "" {
+		t.Errorf("expected queue item 1 to be waiting, got %+v", item)
+	}
+	item, err = jc.GetQueueItem(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !item.IsStarted() || item.Executable.Number != 7 {
+		t.Errorf("expected queue item 2 to be started as build 7, got %+v", item)
+	}
+	if _, err = jc.GetQueueItem(3); err == nil {
+		t.Error("expected an error for a missing queue item")
+	} else if _, isNotFound := err.(NotFoundError); !isNotFound {
+		t.Errorf("expected a NotFoundError, got %v", err)
+	}
+}
+
+func TestCrumbNegotiation(t *testing.T) {
+	crumb := "first"
+	var builds []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/crumbIssuer/api/json":
+			_, _ = fmt.Fprintf(w, `{"crumb": %q, "crumbRequestField": "Jenkins-Crumb"}`, crumb)
+		case "/job/foo/build":
+			if r.Header.Get("Jenkins-Crumb") != crumb {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			builds = append(builds, crumb)
+			w.Header().Set("Location", "/queue/item/1/")
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	jc, err := NewClient(ts.URL, false, nil, &AuthConfig{CSRFProtect: true}, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec := &v1alpha1.LighthouseJobSpec{Job: "foo"}
+	if _, err := jc.LaunchBuild(spec, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Jenkins restarted and issues new crumbs
+	crumb = "second"
+	if _, err := jc.LaunchBuild(spec, nil); err != nil {
+		t.Fatalf("unexpected error after the crumb expired: %v", err)
+	}
+	if expected := []string{"first", "second"}; !reflect.DeepEqual(expected, builds) {
+		t.Errorf("expected builds with crumbs %v, got %v", expected, builds)
+	}
+}
+
+func TestCrumbLessJenkins(t *testing.T) {
+	var builds int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/job/foo/build" {
+			builds++
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	jc, err := NewClient(ts.URL, false, nil, &AuthConfig{CSRFProtect: true}, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := jc.LaunchBuild(&v1alpha1.LighthouseJobSpec{Job: "foo"}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if builds != 1 {
+		t.Errorf("expected 1 build, got %d", builds)
+	}
+}
+
+func TestRequestRetriesWhileRestarting(t *testing.T) {
+	var attempts int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"items": []}`)
+	}))
+	defer ts.Close()
+
+	jc := Client{
+		logger:  logrus.WithField("client", "jenkins"),
+		client:  ts.Client(),
+		baseURL: ts.URL,
+	}
+	if _, err := jc.GetEnqueuedBuilds(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestMutatingRequestNotRetriedOnInternalError(t *testing.T) {
+	var attempts int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	metrics := NewMetrics().ClientMetrics
+	failures := testutil.ToFloat64(metrics.RequestFailures.WithLabelValues(http.MethodPost, "500"))
+	jc := Client{
+		logger:  logrus.WithField("client", "jenkins"),
+		client:  ts.Client(),
+		baseURL: ts.URL,
+		metrics: metrics,
+	}
+	if _, err := jc.LaunchBuild(&v1alpha1.LighthouseJobSpec{Job: "foo"}, nil); err == nil {
+		t.Error("expected an error")
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+	if count := testutil.ToFloat64(metrics.RequestFailures.WithLabelValues(http.MethodPost, "500")) - failures; count != 1 {
+		t.Errorf("expected 1 failure, got %v", count)
+	}
+}
@@ -28,10 +28,13 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/artifacts"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
 	client "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/typed/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
 	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/tracing"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/bwmarrin/snowflake"
@@ -48,9 +51,11 @@ type lighthouseJobClient interface {
 }
 
 type jenkinsClient interface {
-	Build(*v1alpha1.LighthouseJob, string) error
+	Build(*v1alpha1.LighthouseJob, string) (int, error)
 	ListBuilds(jobs []BuildQueryParams) (map[string]Build, error)
 	Abort(job string, build *Build) error
+	GetQueueItem(id int) (*QueueItem, error)
+	CancelQueueItem(id int) error
 }
 
 type syncFn func(v1alpha1.LighthouseJob, map[string]Build) error
@@ -280,6 +285,9 @@ func (c *Controller) terminateDupes(lighthouseJobs []v1alpha1.LighthouseJob, jen
 			WithField("to", toCancel.Status.State).Info("Transitioning states.")
 
 		updatedJob, err := c.lighthouseClient.UpdateStatus(&toCancel)
+		event := audit.JobEvent(audit.JobAborted, &toCancel)
+		event.Details["reason"] = "superseded"
+		audit.Record(nil, event, err)
 		if err != nil {
 			return errors.Wrapf(err, "unable to update LighthouseJob status")
 		}
@@ -315,10 +323,23 @@ func (c *Controller) syncPendingJob(lighthouseJob v1alpha1.LighthouseJob, jenkin
 
 	jenkinsJob, jbExists := jenkinsBuilds[lighthouseJob.ObjectMeta.Name]
 	if !jbExists {
-		lighthouseJob.SetComplete()
-		lighthouseJob.Status.State = v1alpha1.ErrorState
-		lighthouseJob.Status.Description = "Error finding Jenkins job."
+		queued, err := c.syncQueueItem(&lighthouseJob)
+		if err != nil {
+			return err
+		}
+		if queued {
+			c.incrementNumPendingJobs(lighthouseJob.Spec.Job)
+			return nil
+		}
+		if lighthouseJob.Status.State == originalLighthouseJob.Status.State {
+			lighthouseJob.SetComplete()
+			lighthouseJob.Status.State = v1alpha1.ErrorState
+			lighthouseJob.Status.Description = "Error finding Jenkins job."
+		}
 	} else {
+		if !jenkinsJob.IsEnqueued() {
+			lighthouseJob.Status.QueueItemID = 0
+		}
 		switch {
 		case jenkinsJob.IsEnqueued():
 			// Still in queue.
@@ -328,10 +349,12 @@ func (c *Controller) syncPendingJob(lighthouseJob v1alpha1.LighthouseJob, jenkin
 		case jenkinsJob.IsRunning():
 			// Build still going.
 			c.incrementNumPendingJobs(lighthouseJob.Spec.Job)
-			if lighthouseJob.Status.Description == "Jenkins job running." {
+			if lighthouseJob.Status.Description == "Jenkins job running." && lighthouseJob.Status.ActivityName != "" {
 				return nil
 			}
 			lighthouseJob.Status.Description = "Jenkins job running."
+			// the build ID locates the console output streamed while the build is running
+			lighthouseJob.Status.ActivityName = jenkinsJob.BuildID()
 
 		case jenkinsJob.IsSuccess():
 			// Build is complete.
@@ -344,10 +367,25 @@ func (c *Controller) syncPendingJob(lighthouseJob v1alpha1.LighthouseJob, jenkin
 			lighthouseJob.Status.Description = "Jenkins job failed."
 			lighthouseJob.SetComplete()
 
+		case jenkinsJob.IsUnstable():
+			lighthouseJob.Status.State = v1alpha1.FailureState
+			lighthouseJob.Status.Description = "Jenkins job unstable."
+			lighthouseJob.SetComplete()
+
 		case jenkinsJob.IsAborted():
 			lighthouseJob.Status.State = v1alpha1.AbortedState
 			lighthouseJob.Status.Description = "Jenkins job aborted."
 			lighthouseJob.SetComplete()
+
+		case jenkinsJob.IsNotBuilt():
+			lighthouseJob.Status.State = v1alpha1.AbortedState
+			lighthouseJob.Status.Description = "Jenkins job not built."
+			lighthouseJob.SetComplete()
+
+		default:
+			lighthouseJob.Status.State = v1alpha1.ErrorState
+			lighthouseJob.Status.Description = fmt.Sprintf("Jenkins job finished with unknown result %q.", *jenkinsJob.Result)
+			lighthouseJob.SetComplete()
 		}
 		// Construct the status URL that will be used in reports.
 		lighthouseJob.Status.ActivityName = jenkinsJob.BuildID()
@@ -360,7 +398,8 @@ func (c *Controller) syncPendingJob(lighthouseJob v1alpha1.LighthouseJob, jenkin
 	}
 
 	var err error
-	if originalLighthouseJob.Status.State != lighthouseJob.Status.State || originalLighthouseJob.Status.ReportURL != lighthouseJob.Status.ReportURL {
+	if originalLighthouseJob.Status.State != lighthouseJob.Status.State || originalLighthouseJob.Status.ReportURL != lighthouseJob.Status.ReportURL ||
+		originalLighthouseJob.Status.ActivityName != lighthouseJob.Status.ActivityName || originalLighthouseJob.Status.QueueItemID != lighthouseJob.Status.QueueItemID {
 		c.log.WithFields(jobutil.LighthouseJobFields(&lighthouseJob)).
 			WithField("from", originalLighthouseJob.Status.State).
 			WithField("to", lighthouseJob.Status.State).Info("Transitioning states.")
@@ -371,16 +410,53 @@ func (c *Controller) syncPendingJob(lighthouseJob v1alpha1.LighthouseJob, jenkin
 	return err
 }
 
+// syncQueueItem checks the queue item recorded in the status of a LighthouseJob
+// whose build can't be found in Jenkins, so that it is still found once the
+// controller restarted. It returns true if the job is still waiting in the
+// queue and updates the job status if the queue item got cancelled.
+func (c *Controller) syncQueueItem(lighthouseJob *v1alpha1.LighthouseJob) (bool, error) {
+	id := lighthouseJob.Status.QueueItemID
+	if id == 0 {
+		return false, nil
+	}
+	item, err := c.jenkinsClient.GetQueueItem(id)
+	if err != nil {
+		if _, isNotFound := err.(NotFoundError); isNotFound {
+			lighthouseJob.Status.QueueItemID = 0
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get Jenkins queue item %d: %v", id, err)
+	}
+	if item.Cancelled {
+		lighthouseJob.Status.QueueItemID = 0
+		lighthouseJob.Status.State = v1alpha1.AbortedState
+		lighthouseJob.Status.Description = "Jenkins queue item cancelled."
+		lighthouseJob.SetComplete()
+		return false, nil
+	}
+	// the build may have started without being listed yet, wait for the next sync
+	return true, nil
+}
+
 func (c *Controller) syncAbortedJob(lighthouseJob v1alpha1.LighthouseJob, jenkinsBuilds map[string]Build) error {
 	if lighthouseJob.Status.State != v1alpha1.AbortedState || lighthouseJob.Complete() {
 		return nil
 	}
 
-	if build, exists := jenkinsBuilds[lighthouseJob.Name]; exists {
-		if err := c.jenkinsClient.Abort(getJobName(&lighthouseJob.Spec), &build); err != nil {
+	if build, exists := jenkinsBuilds[lighthouseJob.Name]; exists && !build.IsEnqueued() {
+		err := c.jenkinsClient.Abort(getJobName(&lighthouseJob.Spec), &build)
+		audit.Record(nil, audit.JobEvent(audit.JobAborted, &lighthouseJob), err)
+		if err != nil {
 			return fmt.Errorf("failed to abort Jenkins build: %v", err)
 		}
+	} else if id := lighthouseJob.Status.QueueItemID; id != 0 {
+		err := c.jenkinsClient.CancelQueueItem(id)
+		audit.Record(nil, audit.JobEvent(audit.JobAborted, &lighthouseJob), err)
+		if err != nil {
+			return fmt.Errorf("failed to cancel Jenkins queue item %d: %v", id, err)
+		}
 	}
+	lighthouseJob.Status.QueueItemID = 0
 
 	lighthouseJob.SetComplete()
 	c.addActivity(&lighthouseJob)
@@ -403,15 +479,23 @@ func (c *Controller) syncTriggeredJob(lighthouseJob v1alpha1.LighthouseJob, jenk
 			return fmt.Errorf("error getting build ID: %v", err)
 		}
 		// Start the Jenkins job.
-		if err := c.jenkinsClient.Build(&lighthouseJob, buildID); err != nil {
+		_, span := tracing.Start(tracing.ContextWithAnnotations(nil, lighthouseJob.Annotations), "launch jenkins build", tracing.KindClient)
+		span.SetAttribute("lighthouse_job", lighthouseJob.Name)
+		queueItemID, err := c.jenkinsClient.Build(&lighthouseJob, buildID)
+		span.RecordError(err)
+		span.End()
+		if err != nil {
 			c.log.WithError(err).WithFields(jobutil.LighthouseJobFields(&lighthouseJob)).Warn("Cannot start Jenkins build")
 			lighthouseJob.Status.State = v1alpha1.ErrorState
 			lighthouseJob.Status.Description = "Error starting Jenkins job."
 			lighthouseJob.SetComplete()
 		} else {
+			lighthouseJob.Status.QueueItemID = queueItemID
 			lighthouseJob.Status.State = v1alpha1.PendingState
 			lighthouseJob.Status.Description = "Jenkins job enqueued."
 			lighthouseJob.Status.StartTime = metav1.Now()
+			lighthouseJob.Status.BuildID = buildID
+			lighthouseJob.Status.ArtifactsURL = artifacts.URLForJob(&lighthouseJob.Spec, buildID)
 		}
 	} else {
 		// If a Jenkins build already exists for this job, advance the LighthouseJob to Pending and
@@ -0,0 +1,182 @@
+package jenkins
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+type fakeJenkinsClient struct {
+	queueItems      map[int]*QueueItem
+	cancelledQueued []int
+}
+
+func (f *fakeJenkinsClient) Build(*v1alpha1.LighthouseJob, string) (int, error) {
+	return 1, nil
+}
+
+func (f *fakeJenkinsClient) ListBuilds([]BuildQueryParams) (map[string]Build, error) {
+	return nil, nil
+}
+
+func (f *fakeJenkinsClient) Abort(string, *Build) error {
+	return nil
+}
+
+func (f *fakeJenkinsClient) GetQueueItem(id int) (*QueueItem, error) {
+	if item, ok := f.queueItems[id]; ok {
+		return item, nil
+	}
+	return nil, NewNotFoundError(nil)
+}
+
+func (f *fakeJenkinsClient) CancelQueueItem(id int) error {
+	f.cancelledQueued = append(f.cancelledQueued, id)
+	return nil
+}
+
+func newTestController(t *testing.T, jc jenkinsClient, jobs ...*v1alpha1.LighthouseJob) (*Controller, func(string) *v1alpha1.LighthouseJob) {
+	var objs []v1alpha1.LighthouseJob
+	for _, j := range jobs {
+		objs = append(objs, *j)
+	}
+	cs := fake.NewSimpleClientset()
+	lhClient := cs.LighthouseV1alpha1().LighthouseJobs("jx")
+	for i := range objs {
+		_, err := lhClient.Create(&objs[i])
+		require.NoError(t, err)
+	}
+	cfg := &config.Config{
+		ProwConfig: lighthouse.Config{
+			Jenkinses: []lighthouse.JenkinsConfig{{
+				Controller: lighthouse.Controller{
+					JobURLTemplate: template.Must(template.New("test").Parse("https://jenkins/{{.Status.ActivityName}}")),
+				},
+			}},
+		},
+	}
+	c := &Controller{
+		lighthouseClient: lhClient,
+		jenkinsClient:    jc,
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		cfg:              func() *config.Config { return cfg },
+		pendingJobs:      make(map[string]int),
+		clock:            clock.RealClock{},
+	}
+	return c, func(name string) *v1alpha1.LighthouseJob {
+		j, err := lhClient.Get(name, metav1.GetOptions{})
+		require.NoError(t, err)
+		return j
+	}
+}
+
+func pendingJob(name string) *v1alpha1.LighthouseJob {
+	return &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "jx"},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Agent: job.JenkinsAgent,
+			Job:   "my-job",
+		},
+		Status: v1alpha1.LighthouseJobStatus{State: v1alpha1.PendingState},
+	}
+}
+
+func queuedJob(name string, queueItemID int) *v1alpha1.LighthouseJob {
+	j := pendingJob(name)
+	j.Status.QueueItemID = queueItemID
+	return j
+}
+
+func TestSyncPendingJobQueueTracking(t *testing.T) {
+	jc := &fakeJenkinsClient{queueItems: map[int]*QueueItem{
+		1: {ID: 1, Why: "Waiting for next available executor"},
+		2: {ID: 2, Cancelled: true},
+	}}
+	// the queue items are read from the job statuses, e.g. once the controller restarted
+	c, getJob := newTestController(t, jc, queuedJob("queued", 1), queuedJob("cancelled", 2), pendingJob("lost"), queuedJob("expired", 3))
+
+	for _, name := range []string{"queued", "cancelled", "lost", "expired"} {
+		require.NoError(t, c.syncPendingJob(*getJob(name), map[string]Build{}))
+	}
+
+	assert.Equal(t, v1alpha1.PendingState, getJob("queued").Status.State)
+	assert.Equal(t, 1, getJob("queued").Status.QueueItemID)
+	assert.Equal(t, 1, c.pendingJobs["my-job"])
+	assert.Equal(t, v1alpha1.AbortedState, getJob("cancelled").Status.State)
+	assert.True(t, getJob("cancelled").Complete())
+	assert.Equal(t, v1alpha1.ErrorState, getJob("lost").Status.State)
+	assert.Equal(t, v1alpha1.ErrorState, getJob("expired").Status.State)
+
+	assert.Zero(t, getJob("expired").Status.QueueItemID)
+	assert.Zero(t, getJob("cancelled").Status.QueueItemID)
+}
+
+func TestSyncTriggeredJobRecordsQueueItem(t *testing.T) {
+	triggered := pendingJob("job")
+	triggered.Status.State = v1alpha1.TriggeredState
+	c, getJob := newTestController(t, &fakeJenkinsClient{}, triggered)
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	c.node = node
+
+	require.NoError(t, c.syncTriggeredJob(*getJob("job"), map[string]Build{}))
+
+	j := getJob("job")
+	assert.Equal(t, v1alpha1.PendingState, j.Status.State)
+	assert.Equal(t, 1, j.Status.QueueItemID, "the queue item is recorded in the status to be found once the controller restarted")
+}
+
+func TestSyncPendingJobResults(t *testing.T) {
+	results := map[string]v1alpha1.PipelineState{
+		success:  v1alpha1.SuccessState,
+		failure:  v1alpha1.FailureState,
+		unstable: v1alpha1.FailureState,
+		aborted:  v1alpha1.AbortedState,
+		notBuilt: v1alpha1.AbortedState,
+		"WEIRD":  v1alpha1.ErrorState,
+	}
+	for result, expected := range results {
+		t.Run(result, func(t *testing.T) {
+			c, getJob := newTestController(t, &fakeJenkinsClient{}, queuedJob("job", 1))
+			r := result
+			builds := map[string]Build{
+				"job": {
+					Number:  5,
+					Result:  &r,
+					Actions: []Action{{Parameters: []Parameter{{Name: lighthouseJobID, Value: "job"}}}},
+				},
+			}
+			require.NoError(t, c.syncPendingJob(*getJob("job"), builds))
+
+			j := getJob("job")
+			assert.Equal(t, expected, j.Status.State)
+			assert.True(t, j.Complete())
+			assert.Equal(t, "https://jenkins/5", j.Status.ReportURL)
+			assert.Zero(t, j.Status.QueueItemID)
+		})
+	}
+}
+
+func TestSyncAbortedJobCancelsQueueItem(t *testing.T) {
+	aborted := queuedJob("job", 4)
+	aborted.Status.State = v1alpha1.AbortedState
+	jc := &fakeJenkinsClient{}
+	c, getJob := newTestController(t, jc, aborted)
+
+	require.NoError(t, c.syncAbortedJob(*getJob("job"), map[string]Build{}))
+
+	assert.Equal(t, []int{4}, jc.cancelledQueued)
+	assert.True(t, getJob("job").Complete())
+	assert.Zero(t, getJob("job").Status.QueueItemID)
+}
@@ -36,6 +36,7 @@ const (
 	failure  = "FAILURE"
 	unstable = "UNSTABLE"
 	aborted  = "ABORTED"
+	notBuilt = "NOT_BUILT"
 )
 
 // Action holds a list of parameters
@@ -64,6 +65,23 @@ type Build struct {
 	enqueued bool
 }
 
+// QueueItem holds information about an item in the Jenkins queue.
+type QueueItem struct {
+	ID        int    `json:"id"`
+	Cancelled bool   `json:"cancelled"`
+	Why       string `json:"why"`
+	// Executable is set once the queue item has left the queue and a build started.
+	Executable *struct {
+		Number int    `json:"number"`
+		URL    string `json:"url"`
+	} `json:"executable"`
+}
+
+// IsStarted means the queue item left the queue and its build started.
+func (qi *QueueItem) IsStarted() bool {
+	return qi.Executable != nil
+}
+
 // ParameterDefinition holds information about a build parameter
 type ParameterDefinition struct {
 	DefaultParameterValue Parameter `json:"defaultParameterValue,omitempty"`
@@ -98,7 +116,19 @@ func (jb *Build) IsSuccess() bool {
 
 // IsFailure means the job completed with problems.
 func (jb *Build) IsFailure() bool {
-	return jb.Result != nil && (*jb.Result == failure || *jb.Result == unstable)
+	return jb.Result != nil && *jb.Result == failure
+}
+
+// IsUnstable means the job completed but some of its tests failed or
+// it has been marked unstable by a step.
+func (jb *Build) IsUnstable() bool {
+	return jb.Result != nil && *jb.Result == unstable
+}
+
+// IsNotBuilt means the job completed without building anything,
+// e.g. because an earlier stage was skipped.
+func (jb *Build) IsNotBuilt() bool {
+	return jb.Result != nil && *jb.Result == notBuilt
 }
 
 // IsAborted means something stopped the job before it could finish.
@@ -34,6 +34,19 @@ var (
 		Name: "jenkins_request_retries",
 		Help: "Number of Jenkins request retries made from prow.",
 	})
+	requestFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jenkins_request_failures",
+		Help: "Number of Jenkins requests which failed once retried, by status code or reason.",
+	}, []string{
+		// http verb of the request
+		"verb",
+		// http status code of the response, or the reason the request failed
+		"code",
+	})
+	queueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "jenkins_queue_depth",
+		Help: "Number of items waiting in the Jenkins queue.",
+	})
 	requestLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Name:    "jenkins_request_latency",
 		Help:    "Time for a request to roundtrip between prow and Jenkins.",
@@ -54,15 +67,19 @@ var (
 func init() {
 	prometheus.MustRegister(requests)
 	prometheus.MustRegister(requestRetries)
+	prometheus.MustRegister(requestFailures)
+	prometheus.MustRegister(queueDepth)
 	prometheus.MustRegister(requestLatency)
 	prometheus.MustRegister(resyncPeriod)
 }
 
 // ClientMetrics is a set of metrics gathered by the Jenkins client.
 type ClientMetrics struct {
-	Requests       *prometheus.CounterVec
-	RequestRetries prometheus.Counter
-	RequestLatency *prometheus.HistogramVec
+	Requests        *prometheus.CounterVec
+	RequestRetries  prometheus.Counter
+	RequestFailures *prometheus.CounterVec
+	RequestLatency  *prometheus.HistogramVec
+	QueueDepth      prometheus.Gauge
 }
 
 // Metrics is a set of metrics gathered by the Jenkins operator.
@@ -76,9 +93,11 @@ type Metrics struct {
 func NewMetrics() *Metrics {
 	return &Metrics{
 		ClientMetrics: &ClientMetrics{
-			Requests:       requests,
-			RequestRetries: requestRetries,
-			RequestLatency: requestLatency,
+			Requests:        requests,
+			RequestRetries:  requestRetries,
+			RequestFailures: requestFailures,
+			RequestLatency:  requestLatency,
+			QueueDepth:      queueDepth,
 		},
 		ResyncPeriod: resyncPeriod,
 	}
@@ -42,7 +42,10 @@ func ConvertPipelineRun(pr *v1beta1.PipelineRun) *v1alpha1.ActivityRecord {
 
 	for _, taskName := range sets.StringKeySet(pr.Status.TaskRuns).List() {
 		task := pr.Status.TaskRuns[taskName]
-		cleanedUpTaskName := strings.TrimPrefix(taskName[:len(taskName)-6], pr.Name+"-")
+		cleanedUpTaskName := task.PipelineTaskName
+		if cleanedUpTaskName == "" {
+			cleanedUpTaskName = strings.TrimPrefix(taskName[:len(taskName)-6], pr.Name+"-")
+		}
 		t := &v1alpha1.ActivityStageOrStep{
 			Name:           cleanedUpTaskName,
 			Status:         convertTektonStatus(task.Status.GetCondition(apis.ConditionSucceeded), task.Status.StartTime, task.Status.CompletionTime),
@@ -6,12 +6,18 @@ import (
 	"fmt"
 	"os"
 	"text/template"
+	"time"
 
 	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/artifacts"
 	configjob "github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/engines/tekton/tektonv1"
+	"github.com/jenkins-x/lighthouse/pkg/tracing"
 	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -33,6 +39,7 @@ type LighthouseJobReconciler struct {
 	dashboardURL      string
 	dashboardTemplate string
 	namespace         string
+	apiVersion        string
 }
 
 // NewLighthouseJobReconciler creates a LighthouseJob reconciler
@@ -49,13 +56,32 @@ func NewLighthouseJobReconciler(client client.Client, apiReader client.Reader, s
 		dashboardTemplate: dashboardTemplate,
 		namespace:         namespace,
 		idGenerator:       &epochBuildIDGenerator{},
+		apiVersion:        V1APIVersion,
+	}
+}
+
+// SetPipelineRunAPIVersion sets the API version of the PipelineRuns created and watched, v1 or v1beta1
+func (r *LighthouseJobReconciler) SetPipelineRunAPIVersion(apiVersion string) error {
+	switch apiVersion {
+	case V1APIVersion, V1beta1APIVersion:
+		r.apiVersion = apiVersion
+		return nil
+	default:
+		return fmt.Errorf("unsupported PipelineRun API version %q, must be %s or %s", apiVersion, V1APIVersion, V1beta1APIVersion)
 	}
 }
 
 // SetupWithManager sets up the reconcilier with it's manager
 func (r *LighthouseJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	if err := mgr.GetFieldIndexer().IndexField(&pipelinev1beta1.PipelineRun{}, jobOwnerKey, func(rawObj runtime.Object) []string {
-		obj := rawObj.(*pipelinev1beta1.PipelineRun)
+	var pipelineRun runtime.Object = &pipelinev1beta1.PipelineRun{}
+	if r.apiVersion == V1APIVersion {
+		pipelineRun = &tektonv1.PipelineRun{}
+	}
+	if err := mgr.GetFieldIndexer().IndexField(pipelineRun, jobOwnerKey, func(rawObj runtime.Object) []string {
+		obj, err := meta.Accessor(rawObj)
+		if err != nil {
+			return nil
+		}
 		owner := metav1.GetControllerOf(obj)
 		// TODO: would be nice to get kind from the type rather than a hard coded string
 		if owner == nil || owner.APIVersion != apiGVStr || owner.Kind != "LighthouseJob" {
@@ -69,7 +95,7 @@ func (r *LighthouseJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&lighthousev1alpha1.LighthouseJob{}).
 		WithEventFilter(predicate.ResourceVersionChangedPredicate{}).
-		Owns(&pipelinev1beta1.PipelineRun{}).
+		Owns(pipelineRun).
 		Complete(r)
 }
 
@@ -95,24 +121,35 @@ func (r *LighthouseJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 	}
 
 	// get job's pipeline runs
-	var pipelineRunList pipelinev1beta1.PipelineRunList
-	if err := r.client.List(ctx, &pipelineRunList, client.InNamespace(req.Namespace), client.MatchingFields{jobOwnerKey: req.Name}); err != nil {
+	pipelineRuns, err := r.listPipelineRuns(ctx, req.Namespace, req.Name)
+	if err != nil {
 		r.logger.Errorf("Failed list pipeline runs: %s", err)
 		return ctrl.Result{}, err
 	}
 
 	// if pipeline run does not exist, create it
-	if len(pipelineRunList.Items) == 0 {
+	if len(pipelineRuns) == 0 {
 		if job.Status.State == lighthousev1alpha1.TriggeredState {
+			_, span := tracing.Start(tracing.ContextWithAnnotations(ctx, job.Annotations), "launch pipeline run", tracing.KindClient)
+			span.SetAttribute("lighthouse_job", job.Name)
+			defer span.End()
+			if job.Spec.PipelineRef != nil && r.apiVersion != V1APIVersion {
+				err := fmt.Errorf("the pipeline of LighthouseJob %s is resolved by the %s resolver, which requires the %s PipelineRun API version", job.Name, job.Spec.PipelineRef.Resolver, V1APIVersion)
+				r.logger.Errorf("Failed to make pipeline run: %s", err)
+				span.RecordError(err)
+				return ctrl.Result{}, err
+			}
 			// construct a pipeline run
 			pipelineRun, err := makePipelineRun(ctx, job, r.namespace, r.logger, r.idGenerator, r.apiReader)
 			if err != nil {
 				r.logger.Errorf("Failed to make pipeline run: %s", err)
+				span.RecordError(err)
 				return ctrl.Result{}, err
 			}
 			// link it to the current lighthouse job
 			if err := ctrl.SetControllerReference(&job, pipelineRun, r.scheme); err != nil {
 				r.logger.Errorf("Failed to set owner reference: %s", err)
+				span.RecordError(err)
 				return ctrl.Result{}, err
 			}
 			// TODO: changing the status should be a consequence of a pipeline run being created
@@ -123,17 +160,19 @@ func (r *LighthouseJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 			}
 			if err := r.client.Status().Update(ctx, &job); err != nil {
 				r.logger.Errorf("Failed to update LighthouseJob status: %s", err)
+				span.RecordError(err)
 				return ctrl.Result{}, err
 			}
 			// create pipeline run
-			if err := r.client.Create(ctx, pipelineRun); err != nil {
+			if err := r.createPipelineRun(ctx, &job, pipelineRun); err != nil {
 				r.logger.Errorf("Failed to create pipeline run: %s", err)
+				span.RecordError(err)
 				return ctrl.Result{}, err
 			}
 		}
-	} else if len(pipelineRunList.Items) == 1 {
+	} else if len(pipelineRuns) == 1 {
 		// if pipeline run exists, create it and update status
-		pipelineRun := pipelineRunList.Items[0]
+		pipelineRun := pipelineRuns[0]
 		r.logger.Infof("Reconcile PipelineRun %+v", pipelineRun)
 		// update build id
 		job.Labels[util.BuildNumLabel] = pipelineRun.Labels[util.BuildNumLabel]
@@ -144,11 +183,56 @@ func (r *LighthouseJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 		if r.dashboardURL != "" {
 			job.Status.ReportURL = r.getPipelingetPipelineTargetURLeTargetURL(pipelineRun)
 		}
-		job.Status.Activity = ConvertPipelineRun(&pipelineRun)
+		activity := ConvertPipelineRun(&pipelineRun)
+		description, left := exceededTimeout(&job, activity, time.Now())
+		if description != "" {
+			r.logger.Infof("Cancelling PipelineRun %s: %s", pipelineRun.Name, description)
+			if err := r.cancelPipelineRun(ctx, &pipelineRun, description); err != nil {
+				r.logger.Errorf("Failed to cancel PipelineRun: %s", err)
+				return ctrl.Result{}, err
+			}
+		}
+		// the jobs of the PipelineRuns cancelled on timeout are reported as aborted rather than failed
+		if timeout := pipelineRun.Annotations[TimeoutAnnotation]; timeout != "" {
+			activity.Status = lighthousev1alpha1.AbortedState
+			activity.Description = timeout
+		}
+		// the jobs which failed because their pods were evicted or their node was preempted are re-run once
+		if activity.Status == lighthousev1alpha1.FailureState && job.Annotations[DisruptionRetryAnnotation] == "" {
+			disruption, err := r.getPipelineRunDisruption(ctx, &pipelineRun)
+			if err != nil {
+				r.logger.Warningf("Failed to get the disruption of PipelineRun %s: %s", pipelineRun.Name, err)
+			} else if disruption != "" {
+				if err := r.retryDisruptedJob(ctx, &job, &pipelineRun, disruption); err != nil {
+					r.logger.Errorf("Failed to re-run LighthouseJob: %s", err)
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{}, nil
+			}
+		}
+		job.Status.Activity = activity
+		if activity.CompletionTime != nil && job.Status.Resources == nil {
+			resources, err := r.getPipelineRunResources(ctx, &pipelineRun)
+			if err != nil {
+				r.logger.Warningf("Failed to get the resources of PipelineRun %s: %s", pipelineRun.Name, err)
+			} else {
+				job.Status.Resources = resources
+			}
+		}
+		// the failures read by foghorn from the uploaded junit report are kept if the task results have none
+		if failures := ConvertTestFailures(&pipelineRun); len(failures) > 0 {
+			job.Status.TestFailures = failures
+		}
+		job.Status.BuildID = job.Labels[util.BuildNumLabel]
+		job.Status.ArtifactsURL = artifacts.URLForJob(&job.Spec, job.Status.BuildID)
 		if err := r.client.Status().Update(ctx, &job); err != nil {
 			r.logger.Errorf("Failed to update LighthouseJob status: %s", err)
 			return ctrl.Result{}, err
 		}
+		if left > 0 {
+			// check the timeouts again once they could be exceeded, even if the PipelineRun does not change
+			return ctrl.Result{RequeueAfter: left}, nil
+		}
 	} else {
 		r.logger.Errorf("A lighthouse job should never have more than 1 pipeline run")
 	}
@@ -156,6 +240,76 @@ func (r *LighthouseJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 	return ctrl.Result{}, nil
 }
 
+// listPipelineRuns lists the PipelineRuns of the job, the tekton.dev/v1 ones being converted to v1beta1 along with
+// their TaskRuns
+func (r *LighthouseJobReconciler) listPipelineRuns(ctx context.Context, namespace, job string) ([]pipelinev1beta1.PipelineRun, error) {
+	if r.apiVersion != V1APIVersion {
+		var pipelineRunList pipelinev1beta1.PipelineRunList
+		if err := r.client.List(ctx, &pipelineRunList, client.InNamespace(namespace), client.MatchingFields{jobOwnerKey: job}); err != nil {
+			return nil, err
+		}
+		return pipelineRunList.Items, nil
+	}
+	var pipelineRunList tektonv1.PipelineRunList
+	if err := r.client.List(ctx, &pipelineRunList, client.InNamespace(namespace), client.MatchingFields{jobOwnerKey: job}); err != nil {
+		return nil, err
+	}
+	var answer []pipelinev1beta1.PipelineRun
+	for i := range pipelineRunList.Items {
+		pr := &pipelineRunList.Items[i]
+		// the v1 PipelineRuns only reference their TaskRuns, which are read from the API server like their pods
+		var taskRunList tektonv1.TaskRunList
+		if err := r.apiReader.List(ctx, &taskRunList, client.InNamespace(pr.Namespace), client.MatchingLabels{pipelineRunLabel: pr.Name}); err != nil {
+			return nil, errors.Wrapf(err, "failed to list the TaskRuns of PipelineRun %s", pr.Name)
+		}
+		answer = append(answer, *fromV1PipelineRun(pr, taskRunList.Items))
+	}
+	return answer, nil
+}
+
+// createPipelineRun creates the PipelineRun of the job with the configured API version
+func (r *LighthouseJobReconciler) createPipelineRun(ctx context.Context, job *lighthousev1alpha1.LighthouseJob, pipelineRun *pipelinev1beta1.PipelineRun) error {
+	if r.apiVersion != V1APIVersion {
+		return r.client.Create(ctx, pipelineRun)
+	}
+	pr, err := toV1PipelineRun(pipelineRun, job)
+	if err != nil {
+		return err
+	}
+	return r.client.Create(ctx, pr)
+}
+
+// cancelPipelineRun cancels the PipelineRun, annotated with the reason of the cancellation
+func (r *LighthouseJobReconciler) cancelPipelineRun(ctx context.Context, pipelineRun *pipelinev1beta1.PipelineRun, description string) error {
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[TimeoutAnnotation] = description
+	if r.apiVersion != V1APIVersion {
+		pipelineRun.Spec.Status = pipelinev1beta1.PipelineRunSpecStatusCancelled
+		return r.client.Update(ctx, pipelineRun)
+	}
+	pipelineRun.Spec.Status = tektonv1.PipelineRunSpecStatusCancelled
+	pr := &tektonv1.PipelineRun{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: pipelineRun.Namespace, Name: pipelineRun.Name}, pr); err != nil {
+		return err
+	}
+	if pr.Annotations == nil {
+		pr.Annotations = map[string]string{}
+	}
+	pr.Annotations[TimeoutAnnotation] = description
+	pr.Spec.Status = tektonv1.PipelineRunSpecStatusCancelled
+	return r.client.Update(ctx, pr)
+}
+
+// pipelineRunObject returns the object to delete the PipelineRun with the configured API version
+func (r *LighthouseJobReconciler) pipelineRunObject(pipelineRun *pipelinev1beta1.PipelineRun) runtime.Object {
+	if r.apiVersion != V1APIVersion {
+		return pipelineRun
+	}
+	return &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: pipelineRun.Namespace, Name: pipelineRun.Name}}
+}
+
 func (r *LighthouseJobReconciler) getPipelingetPipelineTargetURLeTargetURL(pipelineRun pipelinev1beta1.PipelineRun) string {
 	if r.dashboardTemplate == "" {
 		return fmt.Sprintf("%s/#/namespaces/%s/pipelineruns/%s", trimDashboardURL(r.dashboardURL), r.namespace, pipelineRun.Name)
@@ -11,6 +11,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/engines/tekton/tektonv1"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/stretchr/testify/assert"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -38,6 +39,9 @@ func (s *seededRandIDGenerator) GenerateBuildID() string {
 func TestReconcile(t *testing.T) {
 	testCases := []string{
 		"start-pullrequest",
+		"start-pullrequest-priority",
+		"start-pullrequest-parameters",
+		"start-pullrequest-overrides",
 		"update-job",
 		"start-batch-pullrequest",
 		"start-push",
@@ -85,6 +89,8 @@ func TestReconcile(t *testing.T) {
 			c := fake.NewFakeClientWithScheme(scheme, state...)
 			reconciler := NewLighthouseJobReconciler(c, c, scheme, dashboardBaseURL, dashboardTemplate, ns)
 			reconciler.idGenerator = &seededRandIDGenerator{}
+			err = reconciler.SetPipelineRunAPIVersion(V1beta1APIVersion)
+			assert.NoError(t, err)
 
 			// invoke reconcile
 			_, err = reconciler.Reconcile(ctrl.Request{
@@ -124,6 +130,130 @@ func TestReconcile(t *testing.T) {
 	}
 }
 
+func TestReconcileV1(t *testing.T) {
+	testCases := []string{
+		"start-pullrequest-resolver",
+		"start-pullrequest-spec",
+		"update-job",
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc, func(t *testing.T) {
+			utilrand.Seed(12345)
+
+			testData := path.Join("test_data", "controller-v1", tc)
+			_, err := os.Stat(testData)
+			assert.NoError(t, err)
+
+			// load observed state
+			ns := "jx"
+			observedJob, err := loadLighthouseJob(true, testData)
+			assert.NoError(t, err)
+			state := []runtime.Object{observedJob}
+			observedPR := &tektonv1.PipelineRun{}
+			if loadV1Object(t, filepath.Join(testData, "observed-pr.yml"), observedPR) {
+				state = append(state, observedPR)
+			}
+			observedTaskRun := &tektonv1.TaskRun{}
+			if loadV1Object(t, filepath.Join(testData, "observed-taskrun.yml"), observedTaskRun) {
+				state = append(state, observedTaskRun)
+			}
+
+			// load expected state
+			expectedPR := &tektonv1.PipelineRun{}
+			hasExpectedPR := loadV1Object(t, filepath.Join(testData, "expected-pr.yml"), expectedPR)
+			expectedJob, err := loadLighthouseJob(false, testData)
+			assert.NoError(t, err)
+
+			// create fake controller, the v1beta1 API not being registered
+			scheme := runtime.NewScheme()
+			err = lighthousev1alpha1.AddToScheme(scheme)
+			assert.NoError(t, err)
+			err = tektonv1.AddToScheme(scheme)
+			assert.NoError(t, err)
+			c := fake.NewFakeClientWithScheme(scheme, state...)
+			reconciler := NewLighthouseJobReconciler(c, c, scheme, dashboardBaseURL, dashboardTemplate, ns)
+			reconciler.idGenerator = &seededRandIDGenerator{}
+
+			// invoke reconcile
+			_, err = reconciler.Reconcile(ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: ns,
+					Name:      observedJob.GetName(),
+				},
+			})
+			assert.NoError(t, err)
+
+			// assert observed state matches expected state
+			if hasExpectedPR {
+				var pipelineRunList tektonv1.PipelineRunList
+				err := c.List(nil, &pipelineRunList, client.InNamespace(ns))
+				assert.NoError(t, err)
+				assert.Len(t, pipelineRunList.Items, 1)
+				updatedPR := pipelineRunList.Items[0].DeepCopy()
+				if d := cmp.Diff(expectedPR, updatedPR); d != "" {
+					t.Errorf("PipelineRun did not match expected: %s", d)
+					py, _ := yaml.Marshal(updatedPR)
+					t.Logf("pr:\n%s", string(py))
+				}
+			}
+			if expectedJob != nil {
+				var jobList lighthousev1alpha1.LighthouseJobList
+				err := c.List(nil, &jobList, client.InNamespace(ns))
+				assert.NoError(t, err)
+				assert.Len(t, jobList.Items, 1)
+				// Ignore status.starttime since that's always going to be different
+				updatedJob := jobList.Items[0].DeepCopy()
+				updatedJob.Status.StartTime = metav1.Time{}
+				if d := cmp.Diff(expectedJob, updatedJob); d != "" {
+					t.Errorf("LighthouseJob did not match expected: %s", d)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileV1beta1RejectsResolver(t *testing.T) {
+	ns := "jx"
+	j := &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: ns},
+		Spec: lighthousev1alpha1.LighthouseJobSpec{
+			Agent: "tekton-pipeline",
+			Job:   "github",
+			PipelineRef: &lighthousev1alpha1.PipelineRef{
+				Resolver: "cluster",
+				Params:   map[string]string{"name": "build"},
+			},
+		},
+		Status: lighthousev1alpha1.LighthouseJobStatus{State: lighthousev1alpha1.TriggeredState},
+	}
+	scheme := runtime.NewScheme()
+	assert.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	assert.NoError(t, pipelinev1beta1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, j)
+	reconciler := NewLighthouseJobReconciler(c, c, scheme, "", "", ns)
+	assert.NoError(t, reconciler.SetPipelineRunAPIVersion(V1beta1APIVersion))
+
+	_, err := reconciler.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: "job"}})
+	assert.EqualError(t, err, "the pipeline of LighthouseJob job is resolved by the cluster resolver, which requires the v1 PipelineRun API version")
+
+	var pipelineRunList pipelinev1beta1.PipelineRunList
+	assert.NoError(t, c.List(nil, &pipelineRunList, client.InNamespace(ns)))
+	assert.Empty(t, pipelineRunList.Items)
+}
+
+func loadV1Object(t *testing.T, fileName string, obj runtime.Object) bool {
+	exists, err := util.FileExists(fileName)
+	assert.NoError(t, err)
+	if !exists {
+		return false
+	}
+	data, err := ioutil.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.NoError(t, yaml.Unmarshal(data, obj))
+	return true
+}
+
 func loadLighthouseJob(isObserved bool, dir string) (*v1alpha1.LighthouseJob, error) {
 	var baseFn string
 	if isObserved {
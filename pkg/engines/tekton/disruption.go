@@ -0,0 +1,90 @@
+package tekton
+
+import (
+	"context"
+	"fmt"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DisruptionRetryAnnotation is the annotation of the jobs re-run because their pods were evicted or their node was
+// preempted, holding the description of the disruption. The jobs are re-run only once.
+const DisruptionRetryAnnotation = "lighthouse.jenkins-x.io/disruption-retry"
+
+// disruptionTargetCondition is the pod condition set by kubernetes when a pod is about to be deleted because of a
+// disruption, e.g. a preemption, an eviction or a node shutdown
+const disruptionTargetCondition corev1.PodConditionType = "DisruptionTarget"
+
+// disruptionReasons are the reasons of the pods which failed because of their node rather than of the job
+var disruptionReasons = map[string]bool{
+	"Evicted":      true,
+	"Preempting":   true,
+	"NodeLost":     true,
+	"NodeShutdown": true,
+	"Shutdown":     true,
+	"Terminated":   true,
+}
+
+// getPipelineRunDisruption returns the description of the disruption of the pods of the PipelineRun, if any
+func (r *LighthouseJobReconciler) getPipelineRunDisruption(ctx context.Context, pipelineRun *pipelinev1beta1.PipelineRun) (string, error) {
+	var pods corev1.PodList
+	if err := r.apiReader.List(ctx, &pods, client.InNamespace(pipelineRun.Namespace), client.MatchingLabels{pipelineRunLabel: pipelineRun.Name}); err != nil {
+		return "", err
+	}
+	return PodDisruption(pods.Items), nil
+}
+
+// PodDisruption returns the description of the first pod which failed because it was evicted or its node was
+// preempted or shut down, if any
+func PodDisruption(pods []corev1.Pod) string {
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodFailed {
+			continue
+		}
+		reason, message := pod.Status.Reason, pod.Status.Message
+		if !disruptionReasons[reason] {
+			reason = ""
+			for _, c := range pod.Status.Conditions {
+				if c.Type == disruptionTargetCondition && c.Status == corev1.ConditionTrue {
+					reason, message = c.Reason, c.Message
+					break
+				}
+			}
+		}
+		if reason == "" {
+			continue
+		}
+		if message == "" {
+			return fmt.Sprintf("pod %s failed: %s", pod.Name, reason)
+		}
+		return fmt.Sprintf("pod %s failed: %s: %s", pod.Name, reason, message)
+	}
+	return ""
+}
+
+// retryDisruptedJob annotates the job with the disruption and deletes its PipelineRun, resetting the job to the
+// triggered state so that a new PipelineRun is created rather than reporting the failure
+func (r *LighthouseJobReconciler) retryDisruptedJob(ctx context.Context, job *lighthousev1alpha1.LighthouseJob, pipelineRun *pipelinev1beta1.PipelineRun, disruption string) error {
+	r.logger.Infof("Re-running LighthouseJob %s as its PipelineRun %s was disrupted: %s", job.Name, pipelineRun.Name, disruption)
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[DisruptionRetryAnnotation] = disruption
+	if err := r.client.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to annotate LighthouseJob: %v", err)
+	}
+	job.Status = lighthousev1alpha1.LighthouseJobStatus{
+		State: lighthousev1alpha1.TriggeredState,
+	}
+	if err := r.client.Status().Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to update LighthouseJob status: %v", err)
+	}
+	if err := r.client.Delete(ctx, r.pipelineRunObject(pipelineRun), client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		return fmt.Errorf("failed to delete PipelineRun %s: %v", pipelineRun.Name, err)
+	}
+	return nil
+}
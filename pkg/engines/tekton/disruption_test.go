@@ -0,0 +1,138 @@
+package tekton
+
+import (
+	"testing"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodDisruption(t *testing.T) {
+	tests := []struct {
+		name       string
+		pod        corev1.Pod
+		disruption string
+	}{
+		{
+			name: "succeeded",
+			pod:  corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+		},
+		{
+			name: "failed step",
+			pod:  corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed}},
+		},
+		{
+			name: "evicted",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "job-1-build-pod"},
+				Status: corev1.PodStatus{
+					Phase:   corev1.PodFailed,
+					Reason:  "Evicted",
+					Message: "The node was low on resource: memory.",
+				},
+			},
+			disruption: "pod job-1-build-pod failed: Evicted: The node was low on resource: memory.",
+		},
+		{
+			name: "node shutdown",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "job-1-build-pod"},
+				Status:     corev1.PodStatus{Phase: corev1.PodFailed, Reason: "NodeShutdown"},
+			},
+			disruption: "pod job-1-build-pod failed: NodeShutdown",
+		},
+		{
+			name: "preempted",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "job-1-build-pod"},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodFailed,
+					Conditions: []corev1.PodCondition{{
+						Type:    disruptionTargetCondition,
+						Status:  corev1.ConditionTrue,
+						Reason:  "PreemptionByScheduler",
+						Message: "default-scheduler: preempting to accommodate a higher priority pod",
+					}},
+				},
+			},
+			disruption: "pod job-1-build-pod failed: PreemptionByScheduler: default-scheduler: preempting to accommodate a higher priority pod",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.disruption, PodDisruption([]corev1.Pod{tc.pod}))
+		})
+	}
+}
+
+func TestReconcileRetriesDisruptedPipelineRun(t *testing.T) {
+	ns := "jx"
+	j := &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: ns, Labels: map[string]string{util.BuildNumLabel: "1"}},
+		Spec:       lighthousev1alpha1.LighthouseJobSpec{Agent: job.TektonPipelineAgent},
+		Status: lighthousev1alpha1.LighthouseJobStatus{
+			State:     lighthousev1alpha1.RunningState,
+			StartTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+	}
+	controller := true
+	pr := &pipelinev1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job-1",
+			Namespace: ns,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: apiGVStr,
+				Kind:       "LighthouseJob",
+				Name:       "job",
+				Controller: &controller,
+			}},
+		},
+	}
+	pr.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse})
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "job-1-build-pod", Namespace: ns, Labels: map[string]string{pipelineRunLabel: "job-1"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	require.NoError(t, pipelinev1beta1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, j, pr, pod)
+	reconciler := NewLighthouseJobReconciler(c, c, scheme, "", "", ns)
+	require.NoError(t, reconciler.SetPipelineRunAPIVersion(V1beta1APIVersion))
+
+	_, err := reconciler.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: "job"}})
+	require.NoError(t, err)
+
+	err = c.Get(nil, types.NamespacedName{Namespace: ns, Name: "job-1"}, pr)
+	assert.True(t, apierrors.IsNotFound(err), "the disrupted PipelineRun should be deleted")
+
+	require.NoError(t, c.Get(nil, types.NamespacedName{Namespace: ns, Name: "job"}, j))
+	assert.Equal(t, "pod job-1-build-pod failed: Evicted", j.Annotations[DisruptionRetryAnnotation])
+	assert.Equal(t, lighthousev1alpha1.TriggeredState, j.Status.State)
+	assert.Nil(t, j.Status.Activity)
+
+	// the job is re-run only once
+	pr.ResourceVersion = ""
+	require.NoError(t, c.Create(nil, pr))
+	_, err = reconciler.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: "job"}})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Get(nil, types.NamespacedName{Namespace: ns, Name: "job-1"}, pr))
+	require.NoError(t, c.Get(nil, types.NamespacedName{Namespace: ns, Name: "job"}, j))
+	require.NotNil(t, j.Status.Activity)
+	assert.Equal(t, lighthousev1alpha1.FailureState, j.Status.Activity.Status)
+}
@@ -0,0 +1,39 @@
+package tekton
+
+import (
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/artifacts"
+	"github.com/jenkins-x/lighthouse/pkg/junit"
+	"github.com/sirupsen/logrus"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// JUnitResultName is the name of the task result containing junit XML test results
+const JUnitResultName = "junit"
+
+// ConvertTestFailures returns the failed tests reported in the junit task results of a PipelineRun
+func ConvertTestFailures(pr *v1beta1.PipelineRun) []v1alpha1.TestFailure {
+	if pr == nil {
+		return nil
+	}
+	var failures []junit.Failure
+	for _, taskName := range sets.StringKeySet(pr.Status.TaskRuns).List() {
+		task := pr.Status.TaskRuns[taskName]
+		if task.Status == nil {
+			continue
+		}
+		for _, result := range task.Status.TaskRunResults {
+			if result.Name != JUnitResultName {
+				continue
+			}
+			parsed, err := junit.ParseFailures([]byte(result.Value))
+			if err != nil {
+				logrus.WithError(err).WithField("PipelineRun", pr.Name).WithField("TaskRun", taskName).Warn("failed to parse junit task result")
+				continue
+			}
+			failures = append(failures, parsed...)
+		}
+	}
+	return artifacts.TestFailures(failures)
+}
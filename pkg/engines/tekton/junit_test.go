@@ -0,0 +1,37 @@
+package tekton
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+func TestConvertTestFailures(t *testing.T) {
+	pr := &v1beta1.PipelineRun{}
+	pr.Status.TaskRuns = map[string]*v1beta1.PipelineRunTaskRunStatus{
+		"pr-unit-abcde": {
+			Status: &v1beta1.TaskRunStatus{
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					TaskRunResults: []v1beta1.TaskRunResult{
+						{Name: "other", Value: "ignored"},
+						{Name: JUnitResultName, Value: `<testsuite name="foo"><testcase name="TestFoo"><failure message="boom"/></testcase></testsuite>`},
+					},
+				},
+			},
+		},
+		"pr-lint-abcde": {
+			Status: &v1beta1.TaskRunStatus{
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					TaskRunResults: []v1beta1.TaskRunResult{
+						{Name: JUnitResultName, Value: "invalid"},
+					},
+				},
+			},
+		},
+		"pr-pending-abcde": {},
+	}
+	assert.Equal(t, []v1alpha1.TestFailure{{Suite: "foo", Name: "TestFoo", Message: "boom"}}, ConvertTestFailures(pr))
+	assert.Nil(t, ConvertTestFailures(nil))
+}
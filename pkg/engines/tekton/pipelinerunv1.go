@@ -0,0 +1,208 @@
+package tekton
+
+import (
+	"encoding/json"
+	"fmt"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/engines/tekton/tektonv1"
+	"github.com/pkg/errors"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// V1beta1APIVersion creates the PipelineRuns with the tekton.dev/v1beta1 API, for the Tekton releases which
+	// do not serve the tekton.dev/v1 API. The pipelines of the jobs cannot be resolved by Tekton resolvers.
+	V1beta1APIVersion = "v1beta1"
+	// V1APIVersion creates the PipelineRuns with the tekton.dev/v1 API
+	V1APIVersion = "v1"
+
+	// pipelineTaskLabel is the label Tekton sets on the TaskRuns of a PipelineRun
+	pipelineTaskLabel = "tekton.dev/pipelineTask"
+)
+
+// toV1PipelineRun converts the PipelineRun made for the job to the tekton.dev/v1 API, its pipeline being resolved by
+// the resolver the job references, if any
+func toV1PipelineRun(pr *pipelinev1beta1.PipelineRun, lj *lighthousev1alpha1.LighthouseJob) (*tektonv1.PipelineRun, error) {
+	spec := &pr.Spec
+	if len(spec.Resources) > 0 {
+		return nil, errors.New("the PipelineResources of the PipelineRun are not supported by the tekton.dev/v1 API")
+	}
+	answer := &tektonv1.PipelineRun{
+		TypeMeta:   metav1.TypeMeta{APIVersion: tektonv1.SchemeGroupVersion.String(), Kind: "PipelineRun"},
+		ObjectMeta: *pr.ObjectMeta.DeepCopy(),
+		Spec: tektonv1.PipelineRunSpec{
+			Params:     spec.Params,
+			Status:     spec.Status,
+			Workspaces: spec.Workspaces,
+			TaskRunTemplate: tektonv1.PipelineTaskRunTemplate{
+				PodTemplate:        spec.PodTemplate,
+				ServiceAccountName: spec.ServiceAccountName,
+			},
+		},
+	}
+	if spec.Timeout != nil {
+		answer.Spec.Timeouts = &tektonv1.TimeoutFields{Pipeline: spec.Timeout}
+	}
+	tasks := sets.NewString()
+	for _, s := range spec.TaskRunSpecs {
+		answer.Spec.TaskRunSpecs = append(answer.Spec.TaskRunSpecs, tektonv1.PipelineTaskRunSpec{
+			PipelineTaskName:   s.PipelineTaskName,
+			ServiceAccountName: s.TaskServiceAccountName,
+			PodTemplate:        s.TaskPodTemplate,
+		})
+		tasks.Insert(s.PipelineTaskName)
+	}
+	for _, s := range spec.ServiceAccountNames {
+		if !tasks.Has(s.TaskName) {
+			answer.Spec.TaskRunSpecs = append(answer.Spec.TaskRunSpecs, tektonv1.PipelineTaskRunSpec{
+				PipelineTaskName:   s.TaskName,
+				ServiceAccountName: s.ServiceAccountName,
+			})
+		}
+	}
+
+	switch {
+	case lj.Spec.PipelineRef != nil:
+		ref, err := resolverPipelineRef(lj)
+		if err != nil {
+			return nil, err
+		}
+		answer.Spec.PipelineRef = ref
+	case spec.PipelineRef != nil:
+		answer.Spec.PipelineRef = &tektonv1.PipelineRef{Name: spec.PipelineRef.Name}
+	case spec.PipelineSpec != nil:
+		data, err := toV1PipelineSpec(spec.PipelineSpec)
+		if err != nil {
+			return nil, err
+		}
+		answer.Spec.PipelineSpec = &runtime.RawExtension{Raw: data}
+	}
+	return answer.DeepCopy(), nil
+}
+
+// resolverPipelineRef returns the reference to the pipeline of the job resolved by its resolver, the params of the
+// resolver being templates given the refs and the parameters of the job
+func resolverPipelineRef(lj *lighthousev1alpha1.LighthouseJob) (*tektonv1.PipelineRef, error) {
+	ref := &tektonv1.PipelineRef{Resolver: lj.Spec.PipelineRef.Resolver}
+	payload := templatePayload(lj)
+	for _, name := range sets.StringKeySet(lj.Spec.PipelineRef.Params).List() {
+		value, err := executeTemplate(name, lj.Spec.PipelineRef.Params[name], payload)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to evaluate the %s param of the %s resolver", name, ref.Resolver)
+		}
+		ref.Params = append(ref.Params, pipelinev1beta1.Param{
+			Name:  name,
+			Value: pipelinev1beta1.ArrayOrString{Type: pipelinev1beta1.ParamTypeString, StringVal: value},
+		})
+	}
+	return ref, nil
+}
+
+// toV1PipelineSpec converts the embedded pipeline to the tekton.dev/v1 API, in which the resources of the steps and
+// the sidecars are renamed computeResources and the PipelineResources and the conditions no longer exist
+func toV1PipelineSpec(spec *pipelinev1beta1.PipelineSpec) ([]byte, error) {
+	if len(spec.Resources) > 0 {
+		return nil, errors.New("the PipelineResources of the pipeline are not supported by the tekton.dev/v1 API")
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the pipeline")
+	}
+	pipeline := map[string]interface{}{}
+	if err := json.Unmarshal(data, &pipeline); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the pipeline")
+	}
+	for _, key := range []string{"tasks", "finally"} {
+		tasks, _ := pipeline[key].([]interface{})
+		for _, t := range tasks {
+			task, _ := t.(map[string]interface{})
+			if task == nil {
+				continue
+			}
+			if task["resources"] != nil {
+				return nil, fmt.Errorf("the resources of the task %v are not supported by the tekton.dev/v1 API", task["name"])
+			}
+			if task["conditions"] != nil {
+				return nil, fmt.Errorf("the conditions of the task %v are not supported by the tekton.dev/v1 API, use when expressions", task["name"])
+			}
+			taskSpec, _ := task["taskSpec"].(map[string]interface{})
+			if taskSpec == nil {
+				continue
+			}
+			if taskSpec["resources"] != nil {
+				return nil, fmt.Errorf("the resources of the task %v are not supported by the tekton.dev/v1 API", task["name"])
+			}
+			if stepTemplate, ok := taskSpec["stepTemplate"].(map[string]interface{}); ok {
+				// the step templates are no longer containers
+				delete(stepTemplate, "name")
+				renameComputeResources(stepTemplate)
+			}
+			for _, key := range []string{"steps", "sidecars"} {
+				containers, _ := taskSpec[key].([]interface{})
+				for _, c := range containers {
+					renameComputeResources(c)
+				}
+			}
+		}
+	}
+	return json.Marshal(pipeline)
+}
+
+func renameComputeResources(c interface{}) {
+	container, _ := c.(map[string]interface{})
+	if container == nil {
+		return
+	}
+	if resources, ok := container["resources"]; ok {
+		delete(container, "resources")
+		container["computeResources"] = resources
+	}
+}
+
+// fromV1PipelineRun converts the tekton.dev/v1 PipelineRun and its TaskRuns to the v1beta1 API, so that its state
+// is reported as the state of the v1beta1 PipelineRuns
+func fromV1PipelineRun(pr *tektonv1.PipelineRun, taskRuns []tektonv1.TaskRun) *pipelinev1beta1.PipelineRun {
+	answer := &pipelinev1beta1.PipelineRun{ObjectMeta: *pr.ObjectMeta.DeepCopy()}
+	answer.Spec.Status = pr.Spec.Status
+	pr.Status.Status.DeepCopyInto(&answer.Status.Status)
+	answer.Status.StartTime = pr.Status.StartTime.DeepCopy()
+	answer.Status.CompletionTime = pr.Status.CompletionTime.DeepCopy()
+
+	pipelineTasks := map[string]string{}
+	for _, child := range pr.Status.ChildReferences {
+		pipelineTasks[child.Name] = child.PipelineTaskName
+	}
+	for i := range taskRuns {
+		tr := &taskRuns[i]
+		pipelineTask := pipelineTasks[tr.Name]
+		if pipelineTask == "" {
+			pipelineTask = tr.Labels[pipelineTaskLabel]
+		}
+		status := &pipelinev1beta1.TaskRunStatus{}
+		tr.Status.Status.DeepCopyInto(&status.Status)
+		status.PodName = tr.Status.PodName
+		status.StartTime = tr.Status.StartTime.DeepCopy()
+		status.CompletionTime = tr.Status.CompletionTime.DeepCopy()
+		for j := range tr.Status.Steps {
+			status.Steps = append(status.Steps, *tr.Status.Steps[j].DeepCopy())
+		}
+		for j := range tr.Status.Results {
+			// only the string results, e.g. the junit ones, are reported
+			if value, ok := tr.Status.Results[j].StringValue(); ok {
+				status.TaskRunResults = append(status.TaskRunResults, pipelinev1beta1.TaskRunResult{Name: tr.Status.Results[j].Name, Value: value})
+			}
+		}
+		if answer.Status.TaskRuns == nil {
+			answer.Status.TaskRuns = map[string]*pipelinev1beta1.PipelineRunTaskRunStatus{}
+		}
+		answer.Status.TaskRuns[tr.Name] = &pipelinev1beta1.PipelineRunTaskRunStatus{
+			PipelineTaskName: pipelineTask,
+			Status:           status,
+		}
+	}
+	return answer
+}
@@ -0,0 +1,53 @@
+package tekton
+
+import (
+	"context"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pipelineRunLabel is the label Tekton sets on the pods of a PipelineRun
+const pipelineRunLabel = "tekton.dev/pipelineRun"
+
+// getPipelineRunResources returns the resources requested and consumed by the pods of the PipelineRun
+func (r *LighthouseJobReconciler) getPipelineRunResources(ctx context.Context, pipelineRun *pipelinev1beta1.PipelineRun) (*lighthousev1alpha1.ResourceUsage, error) {
+	var pods corev1.PodList
+	if err := r.apiReader.List(ctx, &pods, client.InNamespace(pipelineRun.Namespace), client.MatchingLabels{pipelineRunLabel: pipelineRun.Name}); err != nil {
+		return nil, err
+	}
+	return ConvertPodResources(pods.Items), nil
+}
+
+// ConvertPodResources sums the CPU and memory requested by the containers of the pods, and consumed by the
+// containers which terminated, i.e. their requests times how long they ran
+func ConvertPodResources(pods []corev1.Pod) *lighthousev1alpha1.ResourceUsage {
+	usage := &lighthousev1alpha1.ResourceUsage{
+		CPURequests:    *resource.NewMilliQuantity(0, resource.DecimalSI),
+		MemoryRequests: *resource.NewQuantity(0, resource.BinarySI),
+	}
+	for _, pod := range pods {
+		statuses := map[string]corev1.ContainerStatus{}
+		for _, s := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			statuses[s.Name] = s
+		}
+		for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			cpu := c.Resources.Requests.Cpu()
+			memory := c.Resources.Requests.Memory()
+			usage.CPURequests.Add(*cpu)
+			usage.MemoryRequests.Add(*memory)
+
+			terminated := statuses[c.Name].State.Terminated
+			if terminated == nil || terminated.StartedAt.IsZero() || terminated.FinishedAt.Before(&terminated.StartedAt) {
+				continue
+			}
+			seconds := terminated.FinishedAt.Sub(terminated.StartedAt.Time).Seconds()
+			usage.CPUMilliSeconds += int64(float64(cpu.MilliValue()) * seconds)
+			usage.MemoryByteSeconds += int64(float64(memory.Value()) * seconds)
+		}
+	}
+	return usage
+}
@@ -0,0 +1,52 @@
+package tekton
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConvertPodResources(t *testing.T) {
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	container := func(name, cpu, memory string) corev1.Container {
+		return corev1.Container{
+			Name: name,
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}},
+		}
+	}
+	terminated := func(name string, seconds int) corev1.ContainerStatus {
+		return corev1.ContainerStatus{Name: name, State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			StartedAt:  metav1.NewTime(start),
+			FinishedAt: metav1.NewTime(start.Add(time.Duration(seconds) * time.Second)),
+		}}}
+	}
+	pods := []corev1.Pod{
+		{
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{container("place-tools", "100m", "64Mi")},
+				Containers:     []corev1.Container{container("step-build", "1", "1Gi"), container("step-test", "500m", "512Mi")},
+			},
+			Status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{terminated("place-tools", 10)},
+				ContainerStatuses:     []corev1.ContainerStatus{terminated("step-build", 60), terminated("step-test", 120)},
+			},
+		},
+		{
+			// a pod which never ran
+			Spec: corev1.PodSpec{Containers: []corev1.Container{container("step-lint", "250m", "256Mi")}},
+		},
+	}
+
+	usage := ConvertPodResources(pods)
+	assert.Equal(t, "1850m", usage.CPURequests.String())
+	assert.Equal(t, int64(1856)<<20, usage.MemoryRequests.Value())
+	assert.Equal(t, int64(100*10+1000*60+500*120), usage.CPUMilliSeconds)
+	assert.Equal(t, int64(64<<20)*10+int64(1<<30)*60+int64(512<<20)*120, usage.MemoryByteSeconds)
+}
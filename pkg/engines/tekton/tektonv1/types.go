@@ -0,0 +1,180 @@
+// Package tektonv1 declares the subset of the tekton.dev/v1 API the Tekton engine creates and watches, as the
+// vendored Tekton pipeline module only provides the v1alpha1 and v1beta1 APIs. The pipeline embedded in a
+// PipelineRun is kept as raw JSON so that it is passed to Tekton as is.
+package tektonv1
+
+import (
+	"encoding/json"
+
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+// SchemeGroupVersion is the group version of the Tekton v1 API
+var SchemeGroupVersion = schema.GroupVersion{Group: "tekton.dev", Version: "v1"}
+
+var (
+	// SchemeBuilder registers the PipelineRuns and TaskRuns of the Tekton v1 API
+	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+	// AddToScheme adds the PipelineRuns and TaskRuns of the Tekton v1 API to a scheme
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(SchemeGroupVersion,
+		&PipelineRun{},
+		&PipelineRunList{},
+		&TaskRun{},
+		&TaskRunList{},
+	)
+	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
+	return nil
+}
+
+// PipelineRunSpecStatusCancelled cancels a PipelineRun, its finally tasks not being run
+const PipelineRunSpecStatusCancelled = "Cancelled"
+
+// PipelineRun is a tekton.dev/v1 PipelineRun
+type PipelineRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PipelineRunSpec   `json:"spec,omitempty"`
+	Status PipelineRunStatus `json:"status,omitempty"`
+}
+
+// PipelineRunList is a list of PipelineRuns
+type PipelineRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PipelineRun `json:"items"`
+}
+
+// PipelineRunSpec is the spec of a PipelineRun
+type PipelineRunSpec struct {
+	// PipelineRef references the pipeline, by name or through a resolver
+	PipelineRef *PipelineRef `json:"pipelineRef,omitempty"`
+	// PipelineSpec is the embedded pipeline, as raw JSON
+	PipelineSpec *runtime.RawExtension `json:"pipelineSpec,omitempty"`
+	// Params are the params of the pipeline
+	Params []tektonv1beta1.Param `json:"params,omitempty"`
+	// Status is used to cancel the PipelineRun
+	Status tektonv1beta1.PipelineRunSpecStatus `json:"status,omitempty"`
+	// Timeouts are the timeouts of the PipelineRun
+	Timeouts *TimeoutFields `json:"timeouts,omitempty"`
+	// TaskRunTemplate is the template of the TaskRuns of the PipelineRun
+	TaskRunTemplate PipelineTaskRunTemplate `json:"taskRunTemplate,omitempty"`
+	// Workspaces are the workspaces bound to the pipeline
+	Workspaces []tektonv1beta1.WorkspaceBinding `json:"workspaces,omitempty"`
+	// TaskRunSpecs are the specs of the TaskRuns of given pipeline tasks
+	TaskRunSpecs []PipelineTaskRunSpec `json:"taskRunSpecs,omitempty"`
+}
+
+// PipelineRef references a pipeline by name or through a resolver
+type PipelineRef struct {
+	// Name is the name of the Pipeline in the namespace of the PipelineRun
+	Name string `json:"name,omitempty"`
+	// Resolver is the name of the resolver resolving the pipeline
+	Resolver string `json:"resolver,omitempty"`
+	// Params are the params of the resolver
+	Params []tektonv1beta1.Param `json:"params,omitempty"`
+}
+
+// TimeoutFields are the timeouts of a PipelineRun
+type TimeoutFields struct {
+	// Pipeline is the timeout of the whole PipelineRun
+	Pipeline *metav1.Duration `json:"pipeline,omitempty"`
+}
+
+// PipelineTaskRunTemplate is the template of the TaskRuns of a PipelineRun
+type PipelineTaskRunTemplate struct {
+	// PodTemplate is the template of the pods of the TaskRuns
+	PodTemplate *tektonv1beta1.PodTemplate `json:"podTemplate,omitempty"`
+	// ServiceAccountName is the service account the TaskRuns run as
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// PipelineTaskRunSpec is the spec of the TaskRun of a pipeline task
+type PipelineTaskRunSpec struct {
+	// PipelineTaskName is the name of the pipeline task
+	PipelineTaskName string `json:"pipelineTaskName,omitempty"`
+	// ServiceAccountName is the service account the TaskRun runs as
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// PodTemplate is the template of the pod of the TaskRun
+	PodTemplate *tektonv1beta1.PodTemplate `json:"podTemplate,omitempty"`
+}
+
+// PipelineRunStatus is the status of a PipelineRun
+type PipelineRunStatus struct {
+	duckv1beta1.Status `json:",inline"`
+
+	// StartTime is when the PipelineRun started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is when the PipelineRun completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// ChildReferences reference the TaskRuns of the PipelineRun
+	ChildReferences []ChildStatusReference `json:"childReferences,omitempty"`
+}
+
+// ChildStatusReference references a TaskRun of a PipelineRun
+type ChildStatusReference struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Name is the name of the TaskRun
+	Name string `json:"name,omitempty"`
+	// PipelineTaskName is the name of the pipeline task of the TaskRun
+	PipelineTaskName string `json:"pipelineTaskName,omitempty"`
+}
+
+// TaskRun is a tekton.dev/v1 TaskRun, of which only the status is read
+type TaskRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status TaskRunStatus `json:"status,omitempty"`
+}
+
+// TaskRunList is a list of TaskRuns
+type TaskRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TaskRun `json:"items"`
+}
+
+// TaskRunStatus is the status of a TaskRun
+type TaskRunStatus struct {
+	duckv1beta1.Status `json:",inline"`
+
+	// PodName is the name of the pod of the TaskRun
+	PodName string `json:"podName,omitempty"`
+	// StartTime is when the TaskRun started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is when the TaskRun completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Steps are the states of the steps of the TaskRun
+	Steps []tektonv1beta1.StepState `json:"steps,omitempty"`
+	// Results are the results of the TaskRun
+	Results []TaskRunResult `json:"results,omitempty"`
+}
+
+// TaskRunResult is a result of a TaskRun
+type TaskRunResult struct {
+	// Name is the name of the result
+	Name string `json:"name"`
+	// Value is the value of the result, a string, an array or an object
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// StringValue returns the value of the result if it is a string
+func (r *TaskRunResult) StringValue() (string, bool) {
+	var s string
+	if err := json.Unmarshal(r.Value, &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
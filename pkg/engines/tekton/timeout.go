@@ -0,0 +1,45 @@
+package tekton
+
+import (
+	"fmt"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+)
+
+// TimeoutAnnotation is the annotation of the PipelineRuns cancelled because their job exceeded its timeout or
+// pending timeout, holding the description of the exceeded timeout
+const TimeoutAnnotation = "lighthouse.jenkins-x.io/timeout"
+
+// exceededTimeout returns the description of the timeout or pending timeout the job exceeded at the given time given
+// the activity of its PipelineRun, if any, otherwise how long until the next timeout is exceeded, zero if none applies
+func exceededTimeout(job *lighthousev1alpha1.LighthouseJob, activity *lighthousev1alpha1.ActivityRecord, now time.Time) (string, time.Duration) {
+	switch activity.Status {
+	case lighthousev1alpha1.TriggeredState, lighthousev1alpha1.PendingState:
+		t := job.Spec.PendingTimeout
+		if t == nil || t.Duration <= 0 || job.Status.StartTime.IsZero() {
+			return "", 0
+		}
+		if left := job.Status.StartTime.Add(t.Duration).Sub(now); left > 0 {
+			return "", left
+		}
+		return fmt.Sprintf("Job aborted: pending for more than %s", t.Duration), 0
+	case lighthousev1alpha1.RunningState:
+		t := job.Spec.Timeout
+		if t == nil || t.Duration <= 0 {
+			return "", 0
+		}
+		started := job.Status.StartTime
+		if activity.StartTime != nil {
+			started = *activity.StartTime
+		}
+		if started.IsZero() {
+			return "", 0
+		}
+		if left := started.Add(t.Duration).Sub(now); left > 0 {
+			return "", left
+		}
+		return fmt.Sprintf("Job aborted: running for more than %s", t.Duration), 0
+	}
+	return "", 0
+}
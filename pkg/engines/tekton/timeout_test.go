@@ -0,0 +1,173 @@
+package tekton
+
+import (
+	"testing"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/engines/tekton/tektonv1"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestExceededTimeout(t *testing.T) {
+	now := time.Date(2020, 7, 20, 22, 0, 0, 0, time.UTC)
+	created := metav1.NewTime(now.Add(-time.Hour))
+	started := metav1.NewTime(now.Add(-10 * time.Minute))
+	tests := []struct {
+		name           string
+		timeout        time.Duration
+		pendingTimeout time.Duration
+		activity       lighthousev1alpha1.ActivityRecord
+		description    string
+		left           time.Duration
+	}{
+		{
+			name:     "no timeout",
+			activity: lighthousev1alpha1.ActivityRecord{Status: lighthousev1alpha1.RunningState, StartTime: &started},
+		},
+		{
+			name:           "pending for too long",
+			pendingTimeout: 30 * time.Minute,
+			activity:       lighthousev1alpha1.ActivityRecord{Status: lighthousev1alpha1.PendingState},
+			description:    "Job aborted: pending for more than 30m0s",
+		},
+		{
+			name:           "pending",
+			pendingTimeout: 2 * time.Hour,
+			activity:       lighthousev1alpha1.ActivityRecord{Status: lighthousev1alpha1.TriggeredState},
+			left:           time.Hour,
+		},
+		{
+			name:           "running once pending for long",
+			pendingTimeout: 30 * time.Minute,
+			timeout:        time.Hour,
+			activity:       lighthousev1alpha1.ActivityRecord{Status: lighthousev1alpha1.RunningState, StartTime: &started},
+			left:           50 * time.Minute,
+		},
+		{
+			name:        "running for too long",
+			timeout:     5 * time.Minute,
+			activity:    lighthousev1alpha1.ActivityRecord{Status: lighthousev1alpha1.RunningState, StartTime: &started},
+			description: "Job aborted: running for more than 5m0s",
+		},
+		{
+			name:     "completed",
+			timeout:  5 * time.Minute,
+			activity: lighthousev1alpha1.ActivityRecord{Status: lighthousev1alpha1.FailureState, StartTime: &started},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			j := &lighthousev1alpha1.LighthouseJob{Status: lighthousev1alpha1.LighthouseJobStatus{StartTime: created}}
+			if tc.timeout > 0 {
+				j.Spec.Timeout = &metav1.Duration{Duration: tc.timeout}
+			}
+			if tc.pendingTimeout > 0 {
+				j.Spec.PendingTimeout = &metav1.Duration{Duration: tc.pendingTimeout}
+			}
+			description, left := exceededTimeout(j, &tc.activity, now)
+			assert.Equal(t, tc.description, description)
+			assert.Equal(t, tc.left, left)
+		})
+	}
+}
+
+func TestReconcileCancelsPendingPipelineRun(t *testing.T) {
+	ns := "jx"
+	j := &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: ns, Labels: map[string]string{util.BuildNumLabel: "1"}},
+		Spec: lighthousev1alpha1.LighthouseJobSpec{
+			Agent:          job.TektonPipelineAgent,
+			PendingTimeout: &metav1.Duration{Duration: time.Minute},
+		},
+		Status: lighthousev1alpha1.LighthouseJobStatus{
+			State:     lighthousev1alpha1.PendingState,
+			StartTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+	}
+	controller := true
+	pr := &pipelinev1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job-1",
+			Namespace: ns,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: apiGVStr,
+				Kind:       "LighthouseJob",
+				Name:       "job",
+				Controller: &controller,
+			}},
+		},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	require.NoError(t, pipelinev1beta1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, j, pr)
+	reconciler := NewLighthouseJobReconciler(c, c, scheme, "", "", ns)
+	require.NoError(t, reconciler.SetPipelineRunAPIVersion(V1beta1APIVersion))
+
+	_, err := reconciler.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: "job"}})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Get(nil, types.NamespacedName{Namespace: ns, Name: "job-1"}, pr))
+	assert.Equal(t, pipelinev1beta1.PipelineRunSpecStatus(pipelinev1beta1.PipelineRunSpecStatusCancelled), pr.Spec.Status)
+	assert.Equal(t, "Job aborted: pending for more than 1m0s", pr.Annotations[TimeoutAnnotation])
+
+	require.NoError(t, c.Get(nil, types.NamespacedName{Namespace: ns, Name: "job"}, j))
+	require.NotNil(t, j.Status.Activity)
+	assert.Equal(t, lighthousev1alpha1.AbortedState, j.Status.Activity.Status)
+	assert.Equal(t, "Job aborted: pending for more than 1m0s", j.Status.Activity.Description)
+}
+
+func TestReconcileCancelsPendingV1PipelineRun(t *testing.T) {
+	ns := "jx"
+	j := &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: ns, Labels: map[string]string{util.BuildNumLabel: "1"}},
+		Spec: lighthousev1alpha1.LighthouseJobSpec{
+			Agent:          job.TektonPipelineAgent,
+			PendingTimeout: &metav1.Duration{Duration: time.Minute},
+		},
+		Status: lighthousev1alpha1.LighthouseJobStatus{
+			State:     lighthousev1alpha1.PendingState,
+			StartTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+	}
+	controller := true
+	pr := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job-1",
+			Namespace: ns,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: apiGVStr,
+				Kind:       "LighthouseJob",
+				Name:       "job",
+				Controller: &controller,
+			}},
+		},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	require.NoError(t, tektonv1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, j, pr)
+	reconciler := NewLighthouseJobReconciler(c, c, scheme, "", "", ns)
+
+	_, err := reconciler.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: "job"}})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Get(nil, types.NamespacedName{Namespace: ns, Name: "job-1"}, pr))
+	assert.Equal(t, pipelinev1beta1.PipelineRunSpecStatus(tektonv1.PipelineRunSpecStatusCancelled), pr.Spec.Status)
+	assert.Equal(t, "Job aborted: pending for more than 1m0s", pr.Annotations[TimeoutAnnotation])
+
+	require.NoError(t, c.Get(nil, types.NamespacedName{Namespace: ns, Name: "job"}, j))
+	require.NotNil(t, j.Status.Activity)
+	assert.Equal(t, lighthousev1alpha1.AbortedState, j.Status.Activity.Status)
+	assert.Equal(t, "Job aborted: pending for more than 1m0s", j.Status.Activity.Description)
+}
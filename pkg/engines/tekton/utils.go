@@ -50,7 +50,7 @@ func trimDashboardURL(base string) string {
 // so that we don't have to take care of potentially dangling created pipeline resources.
 func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace string, logger *logrus.Entry, idGen buildIDGenerator, c client.Reader) (*tektonv1beta1.PipelineRun, error) {
 	// First validate.
-	if lj.Spec.PipelineRunSpec == nil {
+	if lj.Spec.PipelineRunSpec == nil && lj.Spec.PipelineRef == nil {
 		return nil, errors.New("no PipelineSpec defined")
 	}
 
@@ -60,7 +60,11 @@ func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace s
 	}
 
 	prLabels, annotations := jobutil.LabelsAndAnnotationsForJob(lj, buildID)
-	specCopy := lj.Spec.PipelineRunSpec.DeepCopy()
+	// the pipeline of a job referencing a resolver is resolved by Tekton, see toV1PipelineRun
+	specCopy := &tektonv1beta1.PipelineRunSpec{}
+	if lj.Spec.PipelineRunSpec != nil {
+		specCopy = lj.Spec.PipelineRunSpec.DeepCopy()
+	}
 	p := tektonv1beta1.PipelineRun{
 		ObjectMeta: metav1.ObjectMeta{
 			Annotations:  annotations,
@@ -70,10 +74,18 @@ func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace s
 		},
 		Spec: *specCopy,
 	}
+	applyPipelineRunOverrides(&p.Spec, lj.Spec.PipelineRunOverrides)
 	// Set a default timeout of 1 day if no timeout is specified
 	if p.Spec.Timeout == nil {
 		p.Spec.Timeout = &metav1.Duration{Duration: 24 * time.Hour}
 	}
+	if lj.Spec.PriorityClassName != "" {
+		if p.Spec.PodTemplate == nil {
+			p.Spec.PodTemplate = &tektonv1beta1.PodTemplate{}
+		}
+		priorityClassName := lj.Spec.PriorityClassName
+		p.Spec.PodTemplate.PriorityClassName = &priorityClassName
+	}
 
 	// Add parameters instead of env vars.
 	env := lj.Spec.GetEnvVars()
@@ -89,22 +101,15 @@ func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace s
 		env[v1alpha1.PullPullRefEnv] = strings.Join(batchedRefsVals, " ")
 	}
 	if len(lj.Spec.PipelineRunParams) > 0 {
-		payload := map[string]interface{}{
-			"Refs": lj.Spec.Refs,
-		}
+		payload := templatePayload(&lj)
 		for _, param := range lj.Spec.PipelineRunParams {
-			parsedTemplate, err := template.New(param.Name).Parse(param.ValueTemplate)
+			value, err := executeTemplate(param.Name, param.ValueTemplate, payload)
 			if err != nil {
 				return nil, err
 			}
-			var msgBuffer bytes.Buffer
-			err = parsedTemplate.Execute(&msgBuffer, payload)
-			if err != nil {
-				return nil, err
-			}
-			env[param.Name] = msgBuffer.String()
+			env[param.Name] = value
 		}
-	} else {
+	} else if lj.Spec.PipelineRef == nil {
 		paramNames, err := determineGitCloneOrMergeTaskParams(ctx, &p, c)
 		if err != nil {
 			return nil, err
@@ -128,6 +133,12 @@ func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace s
 			}
 		}
 	}
+	// Add the parameters given by the triggering command, without overriding the well known ones.
+	for name, value := range lj.Spec.Parameters {
+		if _, ok := env[name]; !ok {
+			env[name] = value
+		}
+	}
 	for _, key := range sets.StringKeySet(env).List() {
 		val := env[key]
 		// TODO: make this handle existing values/substitutions.
@@ -143,6 +154,77 @@ func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace s
 	return &p, nil
 }
 
+// applyPipelineRunOverrides merges the overrides of a job into the spec of its PipelineRun
+func applyPipelineRunOverrides(spec *tektonv1beta1.PipelineRunSpec, overrides *v1alpha1.PipelineRunOverrides) {
+	if overrides == nil {
+		return
+	}
+	o := overrides.DeepCopy()
+	if o.ServiceAccountName != "" {
+		spec.ServiceAccountName = o.ServiceAccountName
+	}
+	if o.Timeout != nil {
+		spec.Timeout = o.Timeout
+	}
+	if len(o.NodeSelector) > 0 || len(o.Tolerations) > 0 {
+		if spec.PodTemplate == nil {
+			spec.PodTemplate = &tektonv1beta1.PodTemplate{}
+		}
+		if len(o.NodeSelector) > 0 && spec.PodTemplate.NodeSelector == nil {
+			spec.PodTemplate.NodeSelector = map[string]string{}
+		}
+		for k, v := range o.NodeSelector {
+			spec.PodTemplate.NodeSelector[k] = v
+		}
+		for _, toleration := range o.Tolerations {
+			found := false
+			for i := range spec.PodTemplate.Tolerations {
+				if spec.PodTemplate.Tolerations[i].MatchToleration(&toleration) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				spec.PodTemplate.Tolerations = append(spec.PodTemplate.Tolerations, toleration)
+			}
+		}
+	}
+	for _, workspace := range o.Workspaces {
+		found := false
+		for i := range spec.Workspaces {
+			if spec.Workspaces[i].Name == workspace.Name {
+				spec.Workspaces[i] = workspace
+				found = true
+				break
+			}
+		}
+		if !found {
+			spec.Workspaces = append(spec.Workspaces, workspace)
+		}
+	}
+}
+
+// templatePayload returns the data given to the templates of the pipeline_run_params and of the resolver params
+func templatePayload(lj *v1alpha1.LighthouseJob) map[string]interface{} {
+	return map[string]interface{}{
+		"Refs":       lj.Spec.Refs,
+		"Parameters": lj.Spec.Parameters,
+	}
+}
+
+func executeTemplate(name, text string, payload map[string]interface{}) (string, error) {
+	parsedTemplate, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var msgBuffer bytes.Buffer
+	err = parsedTemplate.Execute(&msgBuffer, payload)
+	if err != nil {
+		return "", err
+	}
+	return msgBuffer.String(), nil
+}
+
 type gitTaskParamNames struct {
 	urlParam          string
 	revParam          string
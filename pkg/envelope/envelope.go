@@ -0,0 +1,189 @@
+// Package envelope encrypts the documents lighthouse persists, e.g. the webhooks archived for replay or the queued
+// actions of the plugins, with envelope encryption: each document is encrypted with its own random data key, which is
+// itself encrypted by a key service so that the master key can stay in a key management service.
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// version is the version of the format of the sealed documents
+	version = 1
+
+	// dataKeySize is the size of the AES-256 data keys
+	dataKeySize = 32
+
+	// Redacted replaces the values of the redacted headers
+	Redacted = "REDACTED"
+)
+
+// KeyService encrypts and decrypts the data keys with a master key
+type KeyService interface {
+	// KeyID identifies the master key the data keys are encrypted with
+	KeyID() string
+	// Encrypt returns the data key encrypted with the master key
+	Encrypt(ctx context.Context, dataKey []byte) ([]byte, error)
+	// Decrypt returns the data key encrypted with the master key
+	Decrypt(ctx context.Context, encryptedKey []byte) ([]byte, error)
+}
+
+// sealed is a document encrypted with a data key
+type sealed struct {
+	Version int    `json:"lighthouseEnvelope"`
+	KeyID   string `json:"keyID"`
+	Key     []byte `json:"key"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Sealer encrypts the documents with envelope encryption. A nil Sealer leaves the documents in clear.
+type Sealer struct {
+	keys KeyService
+}
+
+// NewSealer creates a Sealer encrypting the data keys with the key service
+func NewSealer(keys KeyService) *Sealer {
+	return &Sealer{keys: keys}
+}
+
+// Seal encrypts the document with a new data key, returned encrypted along with the document
+func (s *Sealer) Seal(plaintext []byte) ([]byte, error) {
+	if s == nil {
+		return plaintext, nil
+	}
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, errors.Wrap(err, "failed to generate a data key")
+	}
+	nonce, data, err := encrypt(dataKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.keys.Encrypt(context.Background(), dataKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encrypt the data key with %s", s.keys.KeyID())
+	}
+	return json.Marshal(&sealed{
+		Version: version,
+		KeyID:   s.keys.KeyID(),
+		Key:     key,
+		Nonce:   nonce,
+		Data:    data,
+	})
+}
+
+// Open decrypts a document sealed by Seal. The documents in clear are returned as is, so that the documents persisted
+// before the encryption was enabled can still be read.
+func (s *Sealer) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	doc := &sealed{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the sealed document")
+	}
+	if doc.Version != version {
+		return nil, errors.Errorf("unsupported version %d of the sealed document", doc.Version)
+	}
+	if s == nil {
+		return nil, errors.Errorf("the document is encrypted with %s but no key is configured", doc.KeyID)
+	}
+	if doc.KeyID != s.keys.KeyID() {
+		return nil, errors.Errorf("the document is encrypted with %s rather than %s", doc.KeyID, s.keys.KeyID())
+	}
+	dataKey, err := s.keys.Decrypt(context.Background(), doc.Key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt the data key with %s", doc.KeyID)
+	}
+	return decrypt(dataKey, doc.Nonce, doc.Data)
+}
+
+// IsSealed returns true if the document was sealed by a Sealer
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(`{"lighthouseEnvelope":`))
+}
+
+func encrypt(key, plaintext []byte) ([]byte, []byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate a nonce")
+	}
+	return nonce, gcm.Seal(nil, nonce, plaintext, nil), nil
+}
+
+func decrypt(key, nonce, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, errors.New("invalid nonce")
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	return plaintext, errors.Wrap(err, "failed to decrypt the document")
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid key")
+	}
+	return cipher.NewGCM(block)
+}
+
+// sensitiveHeaders are the headers holding credentials, whatever their name
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// sensitiveHeaderWords are the words of the names of the headers holding tokens or signatures, e.g. X-Gitlab-Token
+// or X-Hub-Signature-256
+var sensitiveHeaderWords = []string{"token", "secret", "signature", "password", "auth", "key"}
+
+// RedactHeaders returns a copy of the headers whose values holding credentials, tokens or signatures are redacted,
+// to be persisted along with a payload
+func RedactHeaders(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for name, values := range header {
+		if !isSensitiveHeader(name) {
+			redacted[name] = append([]string(nil), values...)
+			continue
+		}
+		for range values {
+			redacted[name] = append(redacted[name], Redacted)
+		}
+	}
+	return redacted
+}
+
+func isSensitiveHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	if sensitiveHeaders[name] {
+		return true
+	}
+	lower := strings.ToLower(name)
+	for _, word := range sensitiveHeaderWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
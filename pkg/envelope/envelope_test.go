@@ -0,0 +1,106 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLocalKey(t *testing.T, dir, name, content string) KeyService {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	keys, err := NewLocalKey(path)
+	require.NoError(t, err)
+	return keys
+}
+
+func TestSealer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envelope")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	keys := newLocalKey(t, dir, "key", "0123456789abcdef0123456789abcdef")
+	other := newLocalKey(t, dir, "other", base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210")))
+	s := NewSealer(keys)
+
+	sealed, err := s.Seal([]byte(`{"body":"secret"}`))
+	require.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, string(sealed), "secret")
+
+	opened, err := s.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, `{"body":"secret"}`, string(opened))
+
+	opened, err = s.Open([]byte(`{"body":"clear"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"body":"clear"}`, string(opened), "the documents in clear are read as is")
+
+	_, err = NewSealer(other).Open(sealed)
+	assert.Error(t, err, "another key cannot open the document")
+
+	var nilSealer *Sealer
+	clear, err := nilSealer.Seal([]byte("data"))
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(clear))
+	_, err = nilSealer.Open(sealed)
+	assert.Error(t, err, "the sealed documents cannot be read without a key")
+}
+
+func TestVaultTransit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/lighthouse":
+			_, _ = w.Write([]byte(`{"data":{"ciphertext":"vault:v1:` + body["plaintext"] + `"}}`))
+		case "/v1/transit/decrypt/lighthouse":
+			_, _ = w.Write([]byte(`{"data":{"plaintext":"` + strings.TrimPrefix(body["ciphertext"], "vault:v1:") + `"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	keys := NewVaultTransit(server.URL, "", "lighthouse", func() (string, error) { return "s.token", nil })
+	assert.Equal(t, "vault:transit/lighthouse", keys.KeyID())
+	s := NewSealer(keys)
+	sealed, err := s.Seal([]byte("payload"))
+	require.NoError(t, err)
+	opened, err := s.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(opened))
+
+	denied := NewSealer(NewVaultTransit(server.URL, "transit", "lighthouse", func() (string, error) { return "wrong", nil }))
+	_, err = denied.Seal([]byte("payload"))
+	assert.Error(t, err)
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("X-GitHub-Event", "push")
+	header.Set("X-Hub-Signature-256", "sha256=abc")
+	header.Set("X-Gitlab-Token", "secret")
+	header.Set("Authorization", "Bearer token")
+
+	redacted := RedactHeaders(header)
+	assert.Equal(t, "application/json", redacted.Get("Content-Type"))
+	assert.Equal(t, "push", redacted.Get("X-GitHub-Event"))
+	assert.Equal(t, Redacted, redacted.Get("X-Hub-Signature-256"))
+	assert.Equal(t, Redacted, redacted.Get("X-Gitlab-Token"))
+	assert.Equal(t, Redacted, redacted.Get("Authorization"))
+	assert.Equal(t, "secret", header.Get("X-Gitlab-Token"), "the headers are copied")
+}
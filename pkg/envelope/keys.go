@@ -0,0 +1,151 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// localKey encrypts the data keys with a master key read from a file, e.g. mounted from a Kubernetes secret
+type localKey struct {
+	id  string
+	key []byte
+}
+
+// NewLocalKey returns the key service encrypting the data keys with the 32 bytes master key of the file, given raw
+// or base64 encoded
+func NewLocalKey(path string) (KeyService, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the key file %s", path)
+	}
+	key := data
+	if len(key) != dataKeySize {
+		if key, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(data))); err != nil || len(key) != dataKeySize {
+			return nil, errors.Errorf("the key file %s must hold a %d bytes key, raw or base64 encoded", path, dataKeySize)
+		}
+	}
+	sum := sha256.Sum256(key)
+	return &localKey{id: "local:" + hex.EncodeToString(sum[:4]), key: key}, nil
+}
+
+func (k *localKey) KeyID() string {
+	return k.id
+}
+
+func (k *localKey) Encrypt(_ context.Context, dataKey []byte) ([]byte, error) {
+	nonce, data, err := encrypt(k.key, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, data...), nil
+}
+
+func (k *localKey) Decrypt(_ context.Context, encryptedKey []byte) ([]byte, error) {
+	gcm, err := newGCM(k.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(encryptedKey) < gcm.NonceSize() {
+		return nil, errors.New("invalid encrypted key")
+	}
+	return decrypt(k.key, encryptedKey[:gcm.NonceSize()], encryptedKey[gcm.NonceSize():])
+}
+
+// vaultTransit encrypts the data keys with a key of the transit secrets engine of HashiCorp Vault, the master key
+// never leaving Vault
+type vaultTransit struct {
+	address string
+	mount   string
+	key     string
+	token   func() (string, error)
+	client  *http.Client
+}
+
+// NewVaultTransit returns the key service encrypting the data keys with the named key of the transit secrets engine
+// mounted at the path of the Vault server, authenticating with the token returned by the function
+func NewVaultTransit(address, mount, key string, token func() (string, error)) KeyService {
+	if mount == "" {
+		mount = "transit"
+	}
+	return &vaultTransit{
+		address: strings.TrimSuffix(address, "/"),
+		mount:   strings.Trim(mount, "/"),
+		key:     key,
+		token:   token,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (v *vaultTransit) KeyID() string {
+	return fmt.Sprintf("vault:%s/%s", v.mount, v.key)
+}
+
+func (v *vaultTransit) Encrypt(ctx context.Context, dataKey []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	if err := v.do(ctx, "encrypt", map[string]string{"plaintext": base64.StdEncoding.EncodeToString(dataKey)}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.Ciphertext == "" {
+		return nil, errors.New("vault returned no ciphertext")
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+func (v *vaultTransit) Decrypt(ctx context.Context, encryptedKey []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := v.do(ctx, "decrypt", map[string]string{"ciphertext": string(encryptedKey)}, &resp); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+}
+
+func (v *vaultTransit) do(ctx context.Context, operation string, body, into interface{}) error {
+	token, err := v.token()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the vault token")
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", v.address, v.mount, operation, v.key)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to %s with vault", operation)
+	}
+	defer resp.Body.Close()
+	respData, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the %s response of vault", operation)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to %s with vault: %s: %s", operation, resp.Status, strings.TrimSpace(string(respData)))
+	}
+	return errors.Wrapf(json.Unmarshal(respData, into), "failed to unmarshal the %s response of vault", operation)
+}
@@ -0,0 +1,91 @@
+// Package eventid identifies the webhooks with a short ID which is surfaced in the comments and commit statuses the
+// bot creates in response to them and recorded on the LighthouseJobs they trigger, so that a comment or a status can
+// be correlated to the logs and the jobs of the webhook which caused it.
+package eventid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Label is the label of the LighthouseJobs carrying the ID of the webhook which triggered them
+	Label = "lighthouse.jenkins-x.io/eventID"
+
+	// MaxDescriptionLength is the maximum length of the commit status descriptions
+	MaxDescriptionLength = 140
+)
+
+var commentRegex = regexp.MustCompile(`<!-- lighthouse-event: ([0-9a-f]+) -->`)
+
+// New returns a new random ID
+func New() string {
+	b := make([]byte, 5)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type idKey struct{}
+
+// ContextWithID returns a context carrying the ID of the webhook it processes. The context may be nil.
+func ContextWithID(ctx context.Context, id string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// FromContext returns the ID of the webhook processed by the context, if any. The context may be nil.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(idKey{}).(string)
+	return id
+}
+
+// FromLabels returns the ID of the webhook which triggered a LighthouseJob given its labels, if any
+func FromLabels(labels map[string]string) string {
+	return labels[Label]
+}
+
+// CommentMarker returns the hidden HTML comment carrying the ID in a markdown comment
+func CommentMarker(id string) string {
+	return fmt.Sprintf("<!-- lighthouse-event: %s -->", id)
+}
+
+// FromComment returns the ID of the webhook which caused a comment of the bot, if any
+func FromComment(body string) string {
+	m := commentRegex.FindAllStringSubmatch(body, -1)
+	if len(m) == 0 {
+		return ""
+	}
+	return m[len(m)-1][1]
+}
+
+// AppendToComment appends the marker of the ID to a markdown comment, replacing the marker of a previous webhook if
+// the comment is edited. The comment is returned as is if the ID is empty.
+func AppendToComment(body, id string) string {
+	if id == "" {
+		return body
+	}
+	body = strings.TrimRight(commentRegex.ReplaceAllString(body, ""), "\n")
+	return body + "\n\n" + CommentMarker(id)
+}
+
+// AppendToDescription appends the ID to a commit status description, truncating the description so that it fits in
+// MaxDescriptionLength. The description is returned as is if the ID is empty.
+func AppendToDescription(description, id string) string {
+	if id == "" {
+		return description
+	}
+	suffix := fmt.Sprintf(" (event %s)", id)
+	if max := MaxDescriptionLength - len(suffix); len(description) > max {
+		description = strings.TrimSpace(description[:max-3]) + "..."
+	}
+	return description + suffix
+}
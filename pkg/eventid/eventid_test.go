@@ -0,0 +1,69 @@
+package eventid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	id := New()
+	assert.Len(t, id, 10)
+	assert.NotEqual(t, id, New())
+	assert.Equal(t, id, FromContext(ContextWithID(nil, id)))
+	assert.Equal(t, "", FromContext(nil))
+}
+
+func TestAppendToComment(t *testing.T) {
+	assert.Equal(t, "/lgtm", AppendToComment("/lgtm", ""))
+
+	body := AppendToComment("Jobs failed\n", "0a1b2c3d4e")
+	assert.Equal(t, "Jobs failed\n\n<!-- lighthouse-event: 0a1b2c3d4e -->", body)
+	assert.Equal(t, "0a1b2c3d4e", FromComment(body))
+
+	// the marker of the previous webhook is replaced when the comment is edited
+	body = AppendToComment(body, "5f6a7b8c9d")
+	assert.Equal(t, "Jobs failed\n\n<!-- lighthouse-event: 5f6a7b8c9d -->", body)
+	assert.Equal(t, "", FromComment("Jobs failed"))
+}
+
+func TestAppendToDescription(t *testing.T) {
+	assert.Equal(t, "Pipeline pending", AppendToDescription("Pipeline pending", ""))
+	assert.Equal(t, "Pipeline pending (event 0a1b2c3d4e)", AppendToDescription("Pipeline pending", "0a1b2c3d4e"))
+
+	description := AppendToDescription(strings.Repeat("failed ", 30), "0a1b2c3d4e")
+	assert.Len(t, description, MaxDescriptionLength)
+	assert.True(t, strings.HasSuffix(description, "... (event 0a1b2c3d4e)"), description)
+}
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder()
+	r.MaxEvents = 2
+	r.MaxLines = 2
+	logger := logrus.New()
+	logger.AddHook(r)
+
+	r.Add(Event{ID: "a", Kind: "pull_request", Repository: "myorg/myrepo"})
+	logger.WithField(Field, "a").WithField("plugin", "trigger").Info("launched job")
+	logger.WithField(Field, "a").Warn("failed to add label")
+	logger.WithField(Field, "a").Warn("dropped")
+	logger.WithField(Field, "unknown").Info("ignored")
+	logger.Info("ignored")
+
+	event, ok := r.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, "myorg/myrepo", event.Repository)
+	require.Len(t, event.Logs, 2)
+	assert.Contains(t, event.Logs[0], "info launched job plugin=trigger")
+	assert.Contains(t, event.Logs[1], "warning failed to add label")
+
+	r.Add(Event{ID: "b"})
+	r.Add(Event{ID: "c"})
+	_, ok = r.Get("a")
+	assert.False(t, ok, "the oldest webhook is forgotten")
+	_, ok = r.Get("c")
+	assert.True(t, ok)
+}
@@ -0,0 +1,127 @@
+package eventid
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Field is the field of the log entries carrying the ID of the webhook they were logged for
+	Field = "event_id"
+
+	// DefaultMaxEvents is the default number of recent webhooks a Recorder remembers
+	DefaultMaxEvents = 1000
+
+	// DefaultMaxLines is the default number of log lines a Recorder keeps per webhook
+	DefaultMaxLines = 200
+)
+
+// Event is a webhook received recently and the lines logged while processing it
+type Event struct {
+	// ID is the short ID of the webhook
+	ID string `json:"id"`
+	// Kind is the kind of the webhook, e.g. pull_request
+	Kind string `json:"kind"`
+	// Repository is the full name of the repository of the webhook
+	Repository string `json:"repository,omitempty"`
+	// Delivery is the ID the SCM provider gave to the delivery of the webhook, if any
+	Delivery string `json:"delivery,omitempty"`
+	// TraceID is the ID of the trace of the processing of the webhook
+	TraceID string `json:"traceID,omitempty"`
+	// Received is the time the webhook was received at
+	Received time.Time `json:"received"`
+	// Logs are the lines logged while processing the webhook
+	Logs []string `json:"logs,omitempty"`
+}
+
+// Recorder remembers the recent webhooks and, as a logrus hook, the lines logged with their ID in the Field field
+type Recorder struct {
+	// MaxEvents is the number of recent webhooks remembered
+	MaxEvents int
+	// MaxLines is the number of log lines kept per webhook, the first lines being kept
+	MaxLines int
+
+	lock   sync.Mutex
+	events map[string]*Event
+	order  []string
+}
+
+// NewRecorder creates a recorder with the default limits
+func NewRecorder() *Recorder {
+	return &Recorder{
+		MaxEvents: DefaultMaxEvents,
+		MaxLines:  DefaultMaxLines,
+		events:    map[string]*Event{},
+	}
+}
+
+// Add remembers a webhook, forgetting the oldest one if MaxEvents are already remembered
+func (r *Recorder) Add(event Event) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.events[event.ID]; ok {
+		return
+	}
+	for len(r.order) >= r.MaxEvents && len(r.order) > 0 {
+		delete(r.events, r.order[0])
+		r.order = r.order[1:]
+	}
+	r.events[event.ID] = &event
+	r.order = append(r.order, event.ID)
+}
+
+// Get returns a copy of a remembered webhook
+func (r *Recorder) Get(id string) (Event, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	event, ok := r.events[id]
+	if !ok {
+		return Event{}, false
+	}
+	answer := *event
+	answer.Logs = append([]string(nil), event.Logs...)
+	return answer, true
+}
+
+// Levels returns the levels of the log entries recorded
+func (r *Recorder) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire records a log entry carrying the ID of a remembered webhook
+func (r *Recorder) Fire(entry *logrus.Entry) error {
+	id, _ := entry.Data[Field].(string)
+	if id == "" {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	event, ok := r.events[id]
+	if !ok || len(event.Logs) >= r.MaxLines {
+		return nil
+	}
+	event.Logs = append(event.Logs, formatEntry(entry))
+	return nil
+}
+
+// formatEntry formats a log entry on one line, with its fields other than the ID sorted by name
+func formatEntry(entry *logrus.Entry) string {
+	var keys []string
+	for k := range entry.Data {
+		if k != Field {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%s %s %s", entry.Time.UTC().Format(time.RFC3339), entry.Level.String(), entry.Message)
+	for _, k := range keys {
+		fmt.Fprintf(b, " %s=%v", k, entry.Data[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,607 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// apiPrefix is the prefix of the API of GitHub Enterprise, which the server accepts too
+const apiPrefix = "/api/v3"
+
+// route serves the requests of a method whose path matches the pattern, the submatches being the full name of the
+// repository then the other parameters
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	handle  func(s *Server, repo *Repository, params []string, body []byte) (int, interface{})
+}
+
+func newRoute(method, pattern string, handle func(s *Server, repo *Repository, params []string, body []byte) (int, interface{})) route {
+	return route{method: method, pattern: regexp.MustCompile("^/repos/([^/]+/[^/]+)" + pattern + "$"), handle: handle}
+}
+
+var routes = []route{
+	newRoute(http.MethodGet, "", getRepository),
+	newRoute(http.MethodGet, "/git/refs/heads/(.+)", getRef),
+	newRoute(http.MethodGet, "/collaborators/([^/]+)/permission", getPermission),
+	newRoute(http.MethodGet, "/pulls", listPullRequests),
+	newRoute(http.MethodGet, "/pulls/(\\d+)", getPullRequest),
+	newRoute(http.MethodPatch, "/pulls/(\\d+)", editIssue),
+	newRoute(http.MethodGet, "/pulls/(\\d+)/files", listFiles),
+	newRoute(http.MethodPut, "/pulls/(\\d+)/merge", mergePullRequest),
+	newRoute(http.MethodGet, "/pulls/(\\d+)/reviews", listReviews),
+	newRoute(http.MethodPost, "/pulls/(\\d+)/reviews", createReview),
+	newRoute(http.MethodGet, "/issues/(\\d+)", getIssue),
+	newRoute(http.MethodPatch, "/issues/(\\d+)", editIssue),
+	newRoute(http.MethodGet, "/issues/(\\d+)/comments", listComments),
+	newRoute(http.MethodPost, "/issues/(\\d+)/comments", createComment),
+	newRoute(http.MethodPatch, "/issues/comments/(\\d+)", editComment),
+	newRoute(http.MethodDelete, "/issues/comments/(\\d+)", deleteComment),
+	newRoute(http.MethodGet, "/issues/(\\d+)/labels", listLabels),
+	newRoute(http.MethodPost, "/issues/(\\d+)/labels", addLabels),
+	newRoute(http.MethodDelete, "/issues/(\\d+)/labels/(.+)", removeLabel),
+	newRoute(http.MethodPost, "/issues/(\\d+)/assignees", addAssignees),
+	newRoute(http.MethodDelete, "/issues/(\\d+)/assignees", removeAssignees),
+	newRoute(http.MethodGet, "/statuses/([^/]+)", listStatuses),
+	newRoute(http.MethodPost, "/statuses/([^/]+)", createStatus),
+	newRoute(http.MethodGet, "/commits/([^/]+)/status", getCombinedStatus),
+}
+
+// serveAPI serves the REST API of GitHub, the changes made through it being those of the bot which delivers no webhook
+func (s *Server) serveAPI(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.lock.Lock()
+	status, out := s.route(r.Method, path, body)
+	s.lock.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if out == nil && status >= 300 {
+		out = map[string]string{"message": http.StatusText(status)}
+	}
+	if out != nil {
+		_ = json.NewEncoder(w).Encode(out)
+	}
+}
+
+func (s *Server) route(method, path string, body []byte) (int, interface{}) {
+	switch {
+	case method == http.MethodGet && path == "/user":
+		return http.StatusOK, userJSON(s.BotName)
+	case method == http.MethodGet && strings.HasPrefix(path, "/users/"):
+		return http.StatusOK, userJSON(strings.TrimPrefix(path, "/users/"))
+	}
+	for _, rt := range routes {
+		if rt.method != method {
+			continue
+		}
+		params := rt.pattern.FindStringSubmatch(path)
+		if params == nil {
+			continue
+		}
+		repo := s.repos[params[1]]
+		if repo == nil {
+			return http.StatusNotFound, nil
+		}
+		return rt.handle(s, repo, params[2:], body)
+	}
+	return http.StatusNotFound, nil
+}
+
+func getRepository(s *Server, repo *Repository, _ []string, _ []byte) (int, interface{}) {
+	return http.StatusOK, s.repositoryJSON(repo)
+}
+
+func getRef(_ *Server, repo *Repository, params []string, _ []byte) (int, interface{}) {
+	sha, ok := repo.Branches[params[0]]
+	if !ok {
+		return http.StatusNotFound, nil
+	}
+	return http.StatusOK, map[string]interface{}{
+		"ref":    "refs/heads/" + params[0],
+		"object": map[string]string{"type": "commit", "sha": sha},
+	}
+}
+
+func getPermission(_ *Server, repo *Repository, params []string, _ []byte) (int, interface{}) {
+	permission, ok := repo.Permissions[params[0]]
+	if !ok {
+		permission = "none"
+	}
+	return http.StatusOK, map[string]interface{}{"permission": permission, "user": userJSON(params[0])}
+}
+
+func listPullRequests(s *Server, repo *Repository, _ []string, _ []byte) (int, interface{}) {
+	answer := []interface{}{}
+	for _, number := range sortedNumbers(repo) {
+		issue := repo.Issues[number]
+		if issue.PullRequest != nil && !issue.Closed {
+			answer = append(answer, s.pullRequestJSON(repo, issue))
+		}
+	}
+	return http.StatusOK, answer
+}
+
+func getPullRequest(s *Server, repo *Repository, params []string, _ []byte) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil || issue.PullRequest == nil {
+		return http.StatusNotFound, nil
+	}
+	return http.StatusOK, s.pullRequestJSON(repo, issue)
+}
+
+func listFiles(_ *Server, repo *Repository, params []string, _ []byte) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil || issue.PullRequest == nil {
+		return http.StatusNotFound, nil
+	}
+	answer := []interface{}{}
+	for _, f := range issue.PullRequest.Files {
+		answer = append(answer, map[string]interface{}{"filename": f, "status": "modified"})
+	}
+	return http.StatusOK, answer
+}
+
+func mergePullRequest(_ *Server, repo *Repository, params []string, body []byte) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil || issue.PullRequest == nil {
+		return http.StatusNotFound, nil
+	}
+	pr := issue.PullRequest
+	if issue.Closed || pr.Merged {
+		return http.StatusMethodNotAllowed, map[string]string{"message": "Pull Request is not mergeable"}
+	}
+	in := struct {
+		SHA string `json:"sha"`
+	}{}
+	_ = json.Unmarshal(body, &in)
+	if in.SHA != "" && in.SHA != pr.HeadSHA {
+		return http.StatusConflict, map[string]string{"message": "Head branch was modified. Review and try the merge again."}
+	}
+	pr.Merged = true
+	pr.MergeSHA = pr.HeadSHA
+	issue.Closed = true
+	repo.Branches[pr.Base] = pr.HeadSHA
+	return http.StatusOK, map[string]interface{}{"sha": pr.MergeSHA, "merged": true, "message": "Pull Request successfully merged"}
+}
+
+func listReviews(s *Server, repo *Repository, params []string, _ []byte) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil || issue.PullRequest == nil {
+		return http.StatusNotFound, nil
+	}
+	answer := []interface{}{}
+	for _, review := range issue.PullRequest.Reviews {
+		answer = append(answer, s.reviewJSON(repo, issue, review))
+	}
+	return http.StatusOK, answer
+}
+
+// reviewStates are the states of the reviews keyed by the event creating them
+var reviewStates = map[string]string{
+	"APPROVE":         "APPROVED",
+	"REQUEST_CHANGES": "CHANGES_REQUESTED",
+	"COMMENT":         "COMMENTED",
+}
+
+func createReview(s *Server, repo *Repository, params []string, body []byte) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil || issue.PullRequest == nil {
+		return http.StatusNotFound, nil
+	}
+	in := struct {
+		Body  string `json:"body"`
+		Event string `json:"event"`
+	}{}
+	if err := json.Unmarshal(body, &in); err != nil {
+		return http.StatusBadRequest, nil
+	}
+	state, ok := reviewStates[in.Event]
+	if !ok {
+		state = "PENDING"
+	}
+	review := s.addReview(issue, s.BotName, state, in.Body)
+	return http.StatusOK, s.reviewJSON(repo, issue, review)
+}
+
+func getIssue(s *Server, repo *Repository, params []string, _ []byte) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil {
+		return http.StatusNotFound, nil
+	}
+	return http.StatusOK, s.issueJSON(repo, issue)
+}
+
+func editIssue(s *Server, repo *Repository, params []string, body []byte) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil {
+		return http.StatusNotFound, nil
+	}
+	in := struct {
+		State *string `json:"state"`
+		Title *string `json:"title"`
+		Body  *string `json:"body"`
+	}{}
+	if err := json.Unmarshal(body, &in); err != nil {
+		return http.StatusBadRequest, nil
+	}
+	if in.State != nil {
+		issue.Closed = *in.State == "closed"
+	}
+	if in.Title != nil {
+		issue.Title = *in.Title
+	}
+	if in.Body != nil {
+		issue.Body = *in.Body
+	}
+	return http.StatusOK, s.issueJSON(repo, issue)
+}
+
+func listComments(s *Server, repo *Repository, params []string, _ []byte) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil {
+		return http.StatusNotFound, nil
+	}
+	answer := []interface{}{}
+	for _, comment := range issue.Comments {
+		answer = append(answer, s.commentJSON(repo, issue, comment))
+	}
+	return http.StatusOK, answer
+}
+
+func createComment(s *Server, repo *Repository, params []string, body []byte) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil {
+		return http.StatusNotFound, nil
+	}
+	in := struct {
+		Body string `json:"body"`
+	}{}
+	if err := json.Unmarshal(body, &in); err != nil {
+		return http.StatusBadRequest, nil
+	}
+	comment := s.addComment(issue, s.BotName, in.Body)
+	return http.StatusCreated, s.commentJSON(repo, issue, comment)
+}
+
+func editComment(s *Server, repo *Repository, params []string, body []byte) (int, interface{}) {
+	issue, i := findComment(repo, params[0])
+	if issue == nil {
+		return http.StatusNotFound, nil
+	}
+	in := struct {
+		Body string `json:"body"`
+	}{}
+	if err := json.Unmarshal(body, &in); err != nil {
+		return http.StatusBadRequest, nil
+	}
+	comment := issue.Comments[i]
+	comment.Body = in.Body
+	comment.Updated = s.now()
+	return http.StatusOK, s.commentJSON(repo, issue, comment)
+}
+
+func deleteComment(_ *Server, repo *Repository, params []string, _ []byte) (int, interface{}) {
+	issue, i := findComment(repo, params[0])
+	if issue == nil {
+		return http.StatusNotFound, nil
+	}
+	issue.Comments = append(issue.Comments[:i], issue.Comments[i+1:]...)
+	return http.StatusNoContent, nil
+}
+
+func listLabels(_ *Server, repo *Repository, params []string, _ []byte) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil {
+		return http.StatusNotFound, nil
+	}
+	return http.StatusOK, labelsJSON(issue.Labels)
+}
+
+func addLabels(_ *Server, repo *Repository, params []string, body []byte) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil {
+		return http.StatusNotFound, nil
+	}
+	var labels []string
+	if err := json.Unmarshal(body, &labels); err != nil {
+		return http.StatusBadRequest, nil
+	}
+	issue.Labels = union(issue.Labels, labels)
+	return http.StatusOK, labelsJSON(issue.Labels)
+}
+
+func removeLabel(_ *Server, repo *Repository, params []string, _ []byte) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil {
+		return http.StatusNotFound, nil
+	}
+	labels := difference(issue.Labels, params[1:2])
+	if len(labels) == len(issue.Labels) {
+		return http.StatusNotFound, map[string]string{"message": "Label does not exist"}
+	}
+	issue.Labels = labels
+	return http.StatusOK, labelsJSON(issue.Labels)
+}
+
+func addAssignees(s *Server, repo *Repository, params []string, body []byte) (int, interface{}) {
+	return editAssignees(s, repo, params, body, union)
+}
+
+func removeAssignees(s *Server, repo *Repository, params []string, body []byte) (int, interface{}) {
+	return editAssignees(s, repo, params, body, difference)
+}
+
+func editAssignees(s *Server, repo *Repository, params []string, body []byte, edit func(a, b []string) []string) (int, interface{}) {
+	issue := findIssue(repo, params[0])
+	if issue == nil {
+		return http.StatusNotFound, nil
+	}
+	in := struct {
+		Assignees []string `json:"assignees"`
+	}{}
+	if err := json.Unmarshal(body, &in); err != nil {
+		return http.StatusBadRequest, nil
+	}
+	issue.Assignees = edit(issue.Assignees, in.Assignees)
+	return http.StatusCreated, s.issueJSON(repo, issue)
+}
+
+func listStatuses(_ *Server, repo *Repository, params []string, _ []byte) (int, interface{}) {
+	statuses := repo.Statuses[resolve(repo, params[0])]
+	answer := []interface{}{}
+	// the latest statuses come first
+	for i := len(statuses) - 1; i >= 0; i-- {
+		answer = append(answer, statusJSON(statuses[i]))
+	}
+	return http.StatusOK, answer
+}
+
+func createStatus(s *Server, repo *Repository, params []string, body []byte) (int, interface{}) {
+	in := struct {
+		State       string `json:"state"`
+		Context     string `json:"context"`
+		Description string `json:"description"`
+		TargetURL   string `json:"target_url"`
+	}{}
+	if err := json.Unmarshal(body, &in); err != nil {
+		return http.StatusBadRequest, nil
+	}
+	if in.Context == "" {
+		in.Context = "default"
+	}
+	status := &Status{
+		Context:     in.Context,
+		State:       in.State,
+		Description: in.Description,
+		TargetURL:   in.TargetURL,
+		Created:     s.now(),
+	}
+	sha := params[0]
+	repo.Statuses[sha] = append(repo.Statuses[sha], status)
+	return http.StatusCreated, statusJSON(status)
+}
+
+func getCombinedStatus(_ *Server, repo *Repository, params []string, _ []byte) (int, interface{}) {
+	sha := resolve(repo, params[0])
+	latest := map[string]*Status{}
+	var contexts []string
+	for _, status := range repo.Statuses[sha] {
+		if latest[status.Context] == nil {
+			contexts = append(contexts, status.Context)
+		}
+		latest[status.Context] = status
+	}
+	sort.Strings(contexts)
+	state := "success"
+	statuses := []interface{}{}
+	for _, c := range contexts {
+		status := latest[c]
+		statuses = append(statuses, statusJSON(status))
+		switch {
+		case status.State == "error" || status.State == "failure":
+			state = "failure"
+		case status.State == "pending" && state == "success":
+			state = "pending"
+		}
+	}
+	if len(contexts) == 0 {
+		state = "pending"
+	}
+	return http.StatusOK, map[string]interface{}{"sha": sha, "state": state, "statuses": statuses}
+}
+
+// resolve returns the SHA of the ref, which is either a SHA or a branch
+func resolve(repo *Repository, ref string) string {
+	if sha, ok := repo.Branches[ref]; ok {
+		return sha
+	}
+	return ref
+}
+
+func findIssue(repo *Repository, number string) *Issue {
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil
+	}
+	return repo.Issues[n]
+}
+
+func findComment(repo *Repository, id string) (*Issue, int) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, 0
+	}
+	for _, issue := range repo.Issues {
+		for i, comment := range issue.Comments {
+			if comment.ID == n {
+				return issue, i
+			}
+		}
+	}
+	return nil, 0
+}
+
+func union(a, b []string) []string {
+	answer := append([]string{}, a...)
+	for _, s := range b {
+		if !contains(answer, s) {
+			answer = append(answer, s)
+		}
+	}
+	return answer
+}
+
+func difference(a, b []string) []string {
+	answer := []string{}
+	for _, s := range a {
+		if !contains(b, s) {
+			answer = append(answer, s)
+		}
+	}
+	return answer
+}
+
+func contains(l []string, s string) bool {
+	for _, v := range l {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) repositoryJSON(repo *Repository) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             len(repo.FullName()),
+		"owner":          userJSON(repo.Owner),
+		"name":           repo.Name,
+		"full_name":      repo.FullName(),
+		"html_url":       fmt.Sprintf("%s/%s", s.URL, repo.FullName()),
+		"clone_url":      fmt.Sprintf("%s/%s.git", s.URL, repo.FullName()),
+		"default_branch": repo.DefaultBranch,
+	}
+}
+
+func (s *Server) issueJSON(repo *Repository, issue *Issue) map[string]interface{} {
+	answer := map[string]interface{}{
+		"id":         issue.Number,
+		"number":     issue.Number,
+		"state":      state(issue),
+		"title":      issue.Title,
+		"body":       issue.Body,
+		"user":       userJSON(issue.Author),
+		"labels":     labelsJSON(issue.Labels),
+		"assignees":  usersJSON(issue.Assignees),
+		"html_url":   fmt.Sprintf("%s/%s/issues/%d", s.URL, repo.FullName(), issue.Number),
+		"created_at": issue.Created,
+		"updated_at": issue.Created,
+	}
+	if issue.PullRequest != nil {
+		answer["html_url"] = fmt.Sprintf("%s/%s/pull/%d", s.URL, repo.FullName(), issue.Number)
+		answer["pull_request"] = map[string]interface{}{"url": answer["html_url"]}
+	}
+	return answer
+}
+
+func (s *Server) pullRequestJSON(repo *Repository, issue *Issue) map[string]interface{} {
+	pr := issue.PullRequest
+	branch := func(ref, sha string) map[string]interface{} {
+		return map[string]interface{}{
+			"ref":  ref,
+			"sha":  sha,
+			"user": userJSON(repo.Owner),
+			"repo": s.repositoryJSON(repo),
+		}
+	}
+	return map[string]interface{}{
+		"number":           issue.Number,
+		"state":            state(issue),
+		"title":            issue.Title,
+		"body":             issue.Body,
+		"labels":           labelsJSON(issue.Labels),
+		"html_url":         fmt.Sprintf("%s/%s/pull/%d", s.URL, repo.FullName(), issue.Number),
+		"user":             userJSON(issue.Author),
+		"assignees":        usersJSON(issue.Assignees),
+		"head":             branch(pr.Head, pr.HeadSHA),
+		"base":             branch(pr.Base, repo.Branches[pr.Base]),
+		"merged":           pr.Merged,
+		"mergeable":        !issue.Closed,
+		"merge_commit_sha": pr.MergeSHA,
+		"created_at":       issue.Created,
+		"updated_at":       issue.Created,
+	}
+}
+
+func (s *Server) commentJSON(repo *Repository, issue *Issue, comment *Comment) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         comment.ID,
+		"html_url":   fmt.Sprintf("%s/%s/issues/%d#issuecomment-%d", s.URL, repo.FullName(), issue.Number, comment.ID),
+		"user":       userJSON(comment.Author),
+		"body":       comment.Body,
+		"created_at": comment.Created,
+		"updated_at": comment.Updated,
+	}
+}
+
+func (s *Server) reviewJSON(repo *Repository, issue *Issue, review *Review) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           review.ID,
+		"body":         review.Body,
+		"user":         userJSON(review.Author),
+		"submitted_at": review.Created,
+		"commit_id":    review.SHA,
+		"state":        review.State,
+		"html_url":     fmt.Sprintf("%s/%s/pull/%d#pullrequestreview-%d", s.URL, repo.FullName(), issue.Number, review.ID),
+	}
+}
+
+func statusJSON(status *Status) map[string]interface{} {
+	return map[string]interface{}{
+		"state":       status.State,
+		"context":     status.Context,
+		"description": status.Description,
+		"target_url":  status.TargetURL,
+		"created_at":  status.Created,
+		"updated_at":  status.Created,
+	}
+}
+
+func userJSON(login string) map[string]interface{} {
+	return map[string]interface{}{"id": len(login), "login": login}
+}
+
+func usersJSON(logins []string) []interface{} {
+	answer := []interface{}{}
+	for _, login := range logins {
+		answer = append(answer, userJSON(login))
+	}
+	return answer
+}
+
+func labelsJSON(labels []string) []interface{} {
+	answer := []interface{}{}
+	for _, label := range labels {
+		answer = append(answer, map[string]string{"name": label})
+	}
+	return answer
+}
+
+func state(issue *Issue) string {
+	if issue.Closed {
+		return "closed"
+	}
+	return "open"
+}
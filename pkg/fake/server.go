@@ -0,0 +1,437 @@
+// Package fake provides an in-memory git provider served over HTTP with the REST API of GitHub, holding repositories,
+// pull requests, issues, comments, labels, reviews and statuses, which delivers webhooks for the changes made by the
+// users it simulates. Tests and local development run Lighthouse and the real go-scm clients against it.
+package fake
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1" // #nosec
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+	"github.com/pkg/errors"
+)
+
+// Repository is a repository of the fake git provider
+type Repository struct {
+	Owner string
+	Name  string
+	// DefaultBranch is the default branch, master if empty
+	DefaultBranch string
+	// Branches are the SHAs of the heads of the branches keyed by branch
+	Branches map[string]string
+	// Permissions are the permissions of the collaborators keyed by login, e.g. admin, write or read
+	Permissions map[string]string
+	// Issues are the issues and pull requests keyed by number
+	Issues map[int]*Issue
+	// Statuses are the statuses of the commits keyed by SHA, the latest status of a context being last
+	Statuses map[string][]*Status
+}
+
+// FullName returns the full name of the repository
+func (r *Repository) FullName() string {
+	return scm.Join(r.Owner, r.Name)
+}
+
+// Issue is an issue, or a pull request if PullRequest is not nil
+type Issue struct {
+	Number    int
+	Title     string
+	Body      string
+	Author    string
+	Closed    bool
+	Labels    []string
+	Assignees []string
+	Comments  []*Comment
+	// PullRequest holds the fields of the pull requests
+	PullRequest *PullRequest
+	Created     time.Time
+}
+
+// PullRequest holds the fields of an issue which is a pull request
+type PullRequest struct {
+	Base    string
+	Head    string
+	HeadSHA string
+	// Files are the names of the files changed
+	Files    []string
+	Reviews  []*Review
+	Merged   bool
+	MergeSHA string
+}
+
+// Comment is a comment of an issue or a pull request
+type Comment struct {
+	ID      int
+	Author  string
+	Body    string
+	Created time.Time
+	Updated time.Time
+}
+
+// Review is a review of a pull request
+type Review struct {
+	ID     int
+	Author string
+	// State is the state of the review, APPROVED, CHANGES_REQUESTED or COMMENTED
+	State   string
+	Body    string
+	SHA     string
+	Created time.Time
+}
+
+// Status is a status of a commit
+type Status struct {
+	Context     string
+	State       string
+	Description string
+	TargetURL   string
+	Created     time.Time
+}
+
+// hook is a webhook the changes are delivered to
+type hook struct {
+	url    string
+	secret string
+}
+
+// Server is the fake git provider
+type Server struct {
+	// URL is the base URL of the server, the GitHub API being served under it and under URL/api/v3
+	URL string
+	// BotName is the login of the user the API is used as
+	BotName string
+
+	server *httptest.Server
+	lock   sync.Mutex
+	repos  map[string]*Repository
+	hooks  []hook
+	lastID int
+	// now returns the current time, it is replaced in the tests
+	now func() time.Time
+}
+
+// NewServer starts a fake git provider whose API is used as the given bot
+func NewServer(botName string) *Server {
+	s := &Server{
+		BotName: botName,
+		repos:   map[string]*Repository{},
+		now:     time.Now,
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveAPI))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a go-scm client of the API of the server
+func (s *Server) Client() *scm.Client {
+	client, _ := github.New(s.URL)
+	client.Client = s.server.Client()
+	return client
+}
+
+// AddHook delivers the webhooks of the changes made by the users to the given URL, signed with the given secret if not
+// empty
+func (s *Server) AddHook(url, secret string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.hooks = append(s.hooks, hook{url: url, secret: secret})
+}
+
+// AddRepository adds a repository whose default branch points at the given SHA
+func (s *Server) AddRepository(owner, name, defaultBranch, sha string) *Repository {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if defaultBranch == "" {
+		defaultBranch = "master"
+	}
+	repo := &Repository{
+		Owner:         owner,
+		Name:          name,
+		DefaultBranch: defaultBranch,
+		Branches:      map[string]string{defaultBranch: sha},
+		Permissions:   map[string]string{},
+		Issues:        map[int]*Issue{},
+		Statuses:      map[string][]*Status{},
+	}
+	s.repos[repo.FullName()] = repo
+	return repo
+}
+
+// Repository returns a copy of the repository, nil if there is none with that full name
+func (s *Server) Repository(fullName string) *Repository {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	repo := s.repos[fullName]
+	if repo == nil {
+		return nil
+	}
+	data, _ := json.Marshal(repo)
+	answer := &Repository{}
+	_ = json.Unmarshal(data, answer)
+	return answer
+}
+
+// SetPermission sets the permission of the user on the repository, e.g. admin, write or read
+func (s *Server) SetPermission(fullName, user, permission string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	repo, err := s.repository(fullName)
+	if err != nil {
+		return err
+	}
+	repo.Permissions[user] = permission
+	return nil
+}
+
+// OpenPullRequest opens a pull request of the user from the head branch at the given SHA to the base branch, delivering
+// the pull_request webhook, and returns its number
+func (s *Server) OpenPullRequest(fullName, author, title, base, head, sha string, files ...string) (int, error) {
+	s.lock.Lock()
+	repo, err := s.repository(fullName)
+	if err != nil {
+		s.lock.Unlock()
+		return 0, err
+	}
+	issue := s.addIssue(repo, author, title)
+	issue.PullRequest = &PullRequest{Base: base, Head: head, HeadSHA: sha, Files: files}
+	repo.Branches[head] = sha
+	payload := s.pullRequestHook("opened", repo, issue, author)
+	s.lock.Unlock()
+	return issue.Number, s.deliver("pull_request", payload)
+}
+
+// OpenIssue opens an issue of the user, delivering the issues webhook, and returns its number
+func (s *Server) OpenIssue(fullName, author, title string) (int, error) {
+	s.lock.Lock()
+	repo, err := s.repository(fullName)
+	if err != nil {
+		s.lock.Unlock()
+		return 0, err
+	}
+	issue := s.addIssue(repo, author, title)
+	payload := map[string]interface{}{
+		"action":     "opened",
+		"issue":      s.issueJSON(repo, issue),
+		"repository": s.repositoryJSON(repo),
+		"sender":     userJSON(author),
+	}
+	s.lock.Unlock()
+	return issue.Number, s.deliver("issues", payload)
+}
+
+// Comment adds a comment of the user to the issue or pull request, delivering the issue_comment webhook
+func (s *Server) Comment(fullName string, number int, author, body string) error {
+	s.lock.Lock()
+	repo, issue, err := s.issue(fullName, number)
+	if err != nil {
+		s.lock.Unlock()
+		return err
+	}
+	comment := s.addComment(issue, author, body)
+	payload := map[string]interface{}{
+		"action":     "created",
+		"issue":      s.issueJSON(repo, issue),
+		"comment":    s.commentJSON(repo, issue, comment),
+		"repository": s.repositoryJSON(repo),
+		"sender":     userJSON(author),
+	}
+	s.lock.Unlock()
+	return s.deliver("issue_comment", payload)
+}
+
+// Review adds a review of the user to the pull request, delivering the pull_request_review webhook. The state is
+// APPROVED, CHANGES_REQUESTED or COMMENTED.
+func (s *Server) Review(fullName string, number int, author, state, body string) error {
+	s.lock.Lock()
+	repo, issue, err := s.pullRequest(fullName, number)
+	if err != nil {
+		s.lock.Unlock()
+		return err
+	}
+	review := s.addReview(issue, author, state, body)
+	payload := map[string]interface{}{
+		"action":       "submitted",
+		"review":       s.reviewJSON(repo, issue, review),
+		"pull_request": s.pullRequestJSON(repo, issue),
+		"repository":   s.repositoryJSON(repo),
+		"sender":       userJSON(author),
+	}
+	s.lock.Unlock()
+	return s.deliver("pull_request_review", payload)
+}
+
+// Push moves the branch to the given SHA as pushed by the user, delivering the push webhook. The head of the pull
+// requests from the branch is moved too, delivering their synchronize webhook.
+func (s *Server) Push(fullName, branch, author, sha string) error {
+	s.lock.Lock()
+	repo, err := s.repository(fullName)
+	if err != nil {
+		s.lock.Unlock()
+		return err
+	}
+	before := repo.Branches[branch]
+	repo.Branches[branch] = sha
+	payloads := []map[string]interface{}{{
+		"ref":     "refs/heads/" + branch,
+		"before":  before,
+		"after":   sha,
+		"created": before == "",
+		"compare": fmt.Sprintf("%s/%s/compare/%s...%s", s.URL, repo.FullName(), before, sha),
+		"head_commit": map[string]interface{}{
+			"id":     sha,
+			"author": map[string]interface{}{"name": author, "username": author},
+		},
+		"repository": s.repositoryJSON(repo),
+		"pusher":     map[string]interface{}{"name": author},
+		"sender":     userJSON(author),
+	}}
+	events := []string{"push"}
+	for _, number := range sortedNumbers(repo) {
+		issue := repo.Issues[number]
+		if pr := issue.PullRequest; pr != nil && !issue.Closed && pr.Head == branch {
+			pr.HeadSHA = sha
+			payloads = append(payloads, s.pullRequestHook("synchronize", repo, issue, author))
+			events = append(events, "pull_request")
+		}
+	}
+	s.lock.Unlock()
+	for i, payload := range payloads {
+		if err := s.deliver(events[i], payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver posts the webhook to the hooks
+func (s *Server) deliver(event string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the %s webhook", event)
+	}
+	s.lock.Lock()
+	hooks := append([]hook{}, s.hooks...)
+	s.lastID++
+	delivery := fmt.Sprintf("fake-%d", s.lastID)
+	s.lock.Unlock()
+
+	for _, h := range hooks {
+		req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", event)
+		req.Header.Set("X-GitHub-Delivery", delivery)
+		if h.secret != "" {
+			mac := hmac.New(sha1.New, []byte(h.secret))
+			_, _ = mac.Write(body)
+			req.Header.Set("X-Hub-Signature", "sha1="+hex.EncodeToString(mac.Sum(nil)))
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return errors.Wrapf(err, "failed to deliver the %s webhook to %s", event, h.url)
+		}
+		data, _ := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return errors.Errorf("the %s webhook was refused by %s with status %d: %s", event, h.url, resp.StatusCode, string(data))
+		}
+	}
+	return nil
+}
+
+func (s *Server) repository(fullName string) (*Repository, error) {
+	repo := s.repos[fullName]
+	if repo == nil {
+		return nil, errors.Errorf("no repository %s", fullName)
+	}
+	return repo, nil
+}
+
+func (s *Server) issue(fullName string, number int) (*Repository, *Issue, error) {
+	repo, err := s.repository(fullName)
+	if err != nil {
+		return nil, nil, err
+	}
+	issue := repo.Issues[number]
+	if issue == nil {
+		return nil, nil, errors.Errorf("no issue %s#%d", fullName, number)
+	}
+	return repo, issue, nil
+}
+
+func (s *Server) pullRequest(fullName string, number int) (*Repository, *Issue, error) {
+	repo, issue, err := s.issue(fullName, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	if issue.PullRequest == nil {
+		return nil, nil, errors.Errorf("%s#%d is not a pull request", fullName, number)
+	}
+	return repo, issue, nil
+}
+
+func (s *Server) nextID() int {
+	s.lastID++
+	return s.lastID
+}
+
+func (s *Server) addIssue(repo *Repository, author, title string) *Issue {
+	issue := &Issue{
+		Number:  len(repo.Issues) + 1,
+		Title:   title,
+		Author:  author,
+		Created: s.now(),
+	}
+	repo.Issues[issue.Number] = issue
+	return issue
+}
+
+func (s *Server) addComment(issue *Issue, author, body string) *Comment {
+	now := s.now()
+	comment := &Comment{ID: s.nextID(), Author: author, Body: body, Created: now, Updated: now}
+	issue.Comments = append(issue.Comments, comment)
+	return comment
+}
+
+func (s *Server) addReview(issue *Issue, author, state, body string) *Review {
+	review := &Review{ID: s.nextID(), Author: author, State: state, Body: body, SHA: issue.PullRequest.HeadSHA, Created: s.now()}
+	issue.PullRequest.Reviews = append(issue.PullRequest.Reviews, review)
+	return review
+}
+
+func (s *Server) pullRequestHook(action string, repo *Repository, issue *Issue, sender string) map[string]interface{} {
+	return map[string]interface{}{
+		"action":       action,
+		"number":       issue.Number,
+		"pull_request": s.pullRequestJSON(repo, issue),
+		"repository":   s.repositoryJSON(repo),
+		"sender":       userJSON(sender),
+	}
+}
+
+func sortedNumbers(repo *Repository) []int {
+	var answer []int
+	for number := range repo.Issues {
+		answer = append(answer, number)
+	}
+	sort.Ints(answer)
+	return answer
+}
@@ -0,0 +1,188 @@
+package fake_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+	"github.com/jenkins-x/lighthouse/pkg/fake"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	secret   = "secret"
+	fullName = "org/repo"
+	baseSHA  = "base-sha"
+	headSHA  = "head-sha"
+)
+
+// receiver parses the webhooks delivered by the server
+type receiver struct {
+	lock     sync.Mutex
+	webhooks []scm.Webhook
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	webhook, err := github.NewWebHookService().Parse(req, func(scm.Webhook) (string, error) {
+		return secret, nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.webhooks = append(r.webhooks, webhook)
+}
+
+// newServer returns a server with a repository delivering its webhooks to a receiver, and the function closing them
+func newServer() (*fake.Server, *scmprovider.Client, *receiver, func()) {
+	server := fake.NewServer("bot")
+	server.AddRepository("org", "repo", "master", baseSHA)
+	r := &receiver{}
+	hooks := httptest.NewServer(r)
+	server.AddHook(hooks.URL, secret)
+	return server, scmprovider.ToClient(server.Client(), "bot"), r, func() {
+		hooks.Close()
+		server.Close()
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	server, _, r, closer := newServer()
+	defer closer()
+
+	number, err := server.OpenPullRequest(fullName, "author", "a change", "master", "feature", headSHA, "README.md")
+	require.NoError(t, err)
+	assert.Equal(t, 1, number)
+	require.NoError(t, server.Comment(fullName, number, "reviewer", "/lgtm"))
+	require.NoError(t, server.Review(fullName, number, "reviewer", "APPROVED", "looks good"))
+	require.NoError(t, server.Push(fullName, "feature", "author", "new-sha"))
+
+	require.Len(t, r.webhooks, 5)
+	pr, ok := r.webhooks[0].(*scm.PullRequestHook)
+	require.True(t, ok, "expected a pull request hook but got %#v", r.webhooks[0])
+	assert.Equal(t, scm.ActionOpen, pr.Action)
+	assert.Equal(t, fullName, pr.Repo.FullName)
+	assert.Equal(t, "feature", pr.PullRequest.Source)
+	assert.Equal(t, "master", pr.PullRequest.Target)
+	assert.Equal(t, headSHA, pr.PullRequest.Sha)
+	assert.Equal(t, "author", pr.PullRequest.Author.Login)
+
+	comment, ok := r.webhooks[1].(*scm.IssueCommentHook)
+	require.True(t, ok, "expected an issue comment hook but got %#v", r.webhooks[1])
+	assert.Equal(t, scm.ActionCreate, comment.Action)
+	assert.Equal(t, "/lgtm", comment.Comment.Body)
+	assert.Equal(t, "reviewer", comment.Comment.Author.Login)
+	assert.True(t, comment.Issue.PullRequest)
+
+	review, ok := r.webhooks[2].(*scm.ReviewHook)
+	require.True(t, ok, "expected a review hook but got %#v", r.webhooks[2])
+	assert.Equal(t, "APPROVED", review.Review.State)
+	assert.Equal(t, "reviewer", review.Review.Author.Login)
+
+	push, ok := r.webhooks[3].(*scm.PushHook)
+	require.True(t, ok, "expected a push hook but got %#v", r.webhooks[3])
+	assert.Equal(t, "refs/heads/feature", push.Ref)
+	assert.Equal(t, "new-sha", push.After)
+
+	synchronize, ok := r.webhooks[4].(*scm.PullRequestHook)
+	require.True(t, ok, "expected a pull request hook but got %#v", r.webhooks[4])
+	assert.Equal(t, scm.ActionSync, synchronize.Action)
+	assert.Equal(t, "new-sha", synchronize.PullRequest.Sha)
+}
+
+func TestWebhookRefused(t *testing.T) {
+	server, _, _, closer := newServer()
+	defer closer()
+	server.AddHook(server.URL+"/no-hook", "")
+
+	_, err := server.OpenIssue(fullName, "author", "a bug")
+	require.Error(t, err)
+}
+
+func TestAPI(t *testing.T) {
+	server, spc, _, closer := newServer()
+	defer closer()
+	number, err := server.OpenPullRequest(fullName, "author", "a change", "master", "feature", headSHA, "README.md")
+	require.NoError(t, err)
+	require.NoError(t, server.Comment(fullName, number, "reviewer", "/lgtm"))
+
+	pr, err := spc.GetPullRequest("org", "repo", number)
+	require.NoError(t, err)
+	assert.Equal(t, "a change", pr.Title)
+	assert.Equal(t, headSHA, pr.Sha)
+	assert.Equal(t, baseSHA, pr.Base.Sha)
+
+	sha, err := spc.GetRef("org", "repo", "heads/master")
+	require.NoError(t, err)
+	assert.Equal(t, baseSHA, sha)
+
+	changes, err := spc.GetPullRequestChanges("org", "repo", number)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "README.md", changes[0].Path)
+
+	require.NoError(t, spc.CreateComment("org", "repo", number, true, "thanks"))
+	comments, err := spc.ListPullRequestComments("org", "repo", number)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Equal(t, "reviewer", comments[0].Author.Login)
+	assert.Equal(t, "bot", comments[1].Author.Login)
+	assert.Equal(t, "thanks", comments[1].Body)
+
+	require.NoError(t, spc.EditComment("org", "repo", number, comments[1].ID, "thanks!", true))
+	require.NoError(t, spc.DeleteComment("org", "repo", number, comments[0].ID, true))
+	comments, err = spc.ListPullRequestComments("org", "repo", number)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "thanks!", comments[0].Body)
+
+	require.NoError(t, spc.AddLabel("org", "repo", number, "do-not-merge/hold", true))
+	require.NoError(t, spc.AddLabel("org", "repo", number, "approved", true))
+	require.NoError(t, spc.RemoveLabel("org", "repo", number, "do-not-merge/hold", true))
+	labels, err := spc.GetIssueLabels("org", "repo", number, true)
+	require.NoError(t, err)
+	require.Len(t, labels, 1)
+	assert.Equal(t, "approved", labels[0].Name)
+
+	require.NoError(t, spc.AssignIssue("org", "repo", number, []string{"reviewer"}))
+
+	_, err = spc.CreateStatus("org", "repo", headSHA, &scm.StatusInput{Label: "unit", State: scm.StatePending})
+	require.NoError(t, err)
+	_, err = spc.CreateStatus("org", "repo", headSHA, &scm.StatusInput{Label: "unit", State: scm.StateSuccess, Desc: "passed"})
+	require.NoError(t, err)
+	combined, err := spc.GetCombinedStatus("org", "repo", headSHA)
+	require.NoError(t, err)
+	assert.Equal(t, scm.StateSuccess, combined.State)
+	require.Len(t, combined.Statuses, 1)
+	assert.Equal(t, "passed", combined.Statuses[0].Desc)
+
+	ok, err := spc.HasPermission("org", "repo", "reviewer", scmprovider.RoleAdmin)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	require.NoError(t, server.SetPermission(fullName, "reviewer", "admin"))
+	ok, err = spc.HasPermission("org", "repo", "reviewer", scmprovider.RoleAdmin)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	err = spc.Merge("org", "repo", number, scmprovider.MergeDetails{SHA: "other-sha"})
+	require.Error(t, err)
+	require.NoError(t, spc.Merge("org", "repo", number, scmprovider.MergeDetails{SHA: headSHA}))
+
+	repo := server.Repository(fullName)
+	require.NotNil(t, repo)
+	issue := repo.Issues[number]
+	assert.True(t, issue.PullRequest.Merged)
+	assert.True(t, issue.Closed)
+	assert.Equal(t, []string{"reviewer"}, issue.Assignees)
+	assert.Equal(t, headSHA, repo.Branches["master"])
+
+	_, err = spc.GetPullRequest("org", "missing", number)
+	require.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package foghorn
+
+import (
+	"fmt"
+	"strings"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+)
+
+// maxCheckRunTestFailures is the maximum number of failed tests listed in the summary of a check run
+const maxCheckRunTestFailures = 20
+
+// checkRunClient is the part of the SCM client used to report the check runs
+type checkRunClient interface {
+	CreateCheckRun(string, string, *scmprovider.CheckRunInput) (*scmprovider.CheckRun, error)
+	UpdateCheckRun(string, string, int64, *scmprovider.CheckRunInput) (*scmprovider.CheckRun, error)
+}
+
+// reportCheckRun creates the check run of the job the first time it is reported, then updates it
+func reportCheckRun(scmClient checkRunClient, activity *lighthousev1alpha1.ActivityRecord, j *lighthousev1alpha1.LighthouseJob, name string, info reportStatusInfo) error {
+	input := checkRunInput(activity, j, name, info)
+	if j.Status.CheckRunID == 0 {
+		checkRun, err := scmClient.CreateCheckRun(activity.Owner, activity.Repo, input)
+		if err != nil {
+			return err
+		}
+		j.Status.CheckRunID = checkRun.ID
+		return nil
+	}
+	_, err := scmClient.UpdateCheckRun(activity.Owner, activity.Repo, j.Status.CheckRunID, input)
+	return err
+}
+
+// checkRunInput converts the activity of the job to a check run. The external ID of the check run is the name of
+// the job so that the job can be triggered again when the check run is re-requested.
+func checkRunInput(activity *lighthousev1alpha1.ActivityRecord, j *lighthousev1alpha1.LighthouseJob, name string, info reportStatusInfo) *scmprovider.CheckRunInput {
+	input := &scmprovider.CheckRunInput{
+		Name:       name,
+		HeadSHA:    activity.LastCommitSHA,
+		DetailsURL: j.Status.ReportURL,
+		ExternalID: j.Name,
+		Output: &scmprovider.CheckRunOutput{
+			Title:   info.description,
+			Summary: checkRunSummary(activity, j, info),
+		},
+	}
+	if activity.StartTime != nil {
+		input.StartedAt = &activity.StartTime.Time
+	}
+	switch activity.Status {
+	case lighthousev1alpha1.PendingState:
+		input.Status = scmprovider.CheckRunQueued
+	case lighthousev1alpha1.RunningState:
+		input.Status = scmprovider.CheckRunInProgress
+	default:
+		input.Status = scmprovider.CheckRunCompleted
+		switch activity.Status {
+		case lighthousev1alpha1.SuccessState:
+			input.Conclusion = scmprovider.CheckRunSuccess
+		case lighthousev1alpha1.AbortedState:
+			input.Conclusion = scmprovider.CheckRunCancelled
+		default:
+			input.Conclusion = scmprovider.CheckRunFailure
+		}
+		if activity.CompletionTime != nil {
+			input.CompletedAt = &activity.CompletionTime.Time
+		}
+	}
+	return input
+}
+
+// checkRunSummary returns the markdown summary of the pipeline: its duration, stages, failed steps,
+// failed tests and a link to its logs and artifacts
+func checkRunSummary(activity *lighthousev1alpha1.ActivityRecord, j *lighthousev1alpha1.LighthouseJob, info reportStatusInfo) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "**%s**", info.description)
+	if duration := durationString(activity.StartTime, activity.CompletionTime); duration != "" {
+		fmt.Fprintf(sb, " in %s", duration)
+	}
+	sb.WriteString("\n")
+
+	if len(activity.Stages) > 0 {
+		sb.WriteString("\n| Stage | Status | Duration |\n|---|---|---|\n")
+		for _, stage := range activity.Stages {
+			fmt.Fprintf(sb, "| %s | %s | %s |\n", stage.Name, stage.Status, durationString(stage.StartTime, stage.CompletionTime))
+		}
+	}
+
+	if failed := failedSteps(activity); len(failed) > 0 {
+		sb.WriteString("\n### Failed steps\n\n")
+		for _, step := range failed {
+			fmt.Fprintf(sb, "- `%s`\n", step)
+		}
+	}
+
+	if len(j.Status.TestFailures) > 0 {
+		sb.WriteString("\n### Failed tests\n\n")
+		for i, f := range j.Status.TestFailures {
+			if i == maxCheckRunTestFailures {
+				fmt.Fprintf(sb, "- and %d more\n", len(j.Status.TestFailures)-maxCheckRunTestFailures)
+				break
+			}
+			name := f.Name
+			if f.Suite != "" {
+				name = f.Suite + "." + f.Name
+			}
+			fmt.Fprintf(sb, "- `%s`", name)
+			if f.Message != "" {
+				fmt.Fprintf(sb, ": %s", strings.ReplaceAll(f.Message, "\n", " "))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	var links []string
+	if j.Status.ReportURL != "" {
+		links = append(links, fmt.Sprintf("[Pipeline](%s)", j.Status.ReportURL))
+	}
+	if activity.LogURL != "" {
+		links = append(links, fmt.Sprintf("[Logs](%s)", activity.LogURL))
+	}
+	if j.Status.ArtifactsURL != "" {
+		links = append(links, fmt.Sprintf("[Artifacts](%s)", j.Status.ArtifactsURL))
+	}
+	if len(links) > 0 {
+		fmt.Fprintf(sb, "\n%s\n", strings.Join(links, " | "))
+	}
+	return sb.String()
+}
+
+// failedSteps returns the failed steps of the activity, prefixed with the names of their stages
+func failedSteps(activity *lighthousev1alpha1.ActivityRecord) []string {
+	var answer []string
+	var walk func(prefix string, items []*lighthousev1alpha1.ActivityStageOrStep)
+	walk = func(prefix string, items []*lighthousev1alpha1.ActivityStageOrStep) {
+		for _, item := range items {
+			if item == nil {
+				continue
+			}
+			name := item.Name
+			if prefix != "" {
+				name = prefix + " / " + item.Name
+			}
+			if len(item.Stages) == 0 && len(item.Steps) == 0 {
+				if item.Status == lighthousev1alpha1.FailureState {
+					answer = append(answer, name)
+				}
+				continue
+			}
+			walk(name, item.Stages)
+			walk(name, item.Steps)
+		}
+	}
+	walk("", activity.Stages)
+	walk("", activity.Steps)
+	return answer
+}
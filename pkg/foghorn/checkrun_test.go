@@ -0,0 +1,88 @@
+package foghorn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReportCheckRun(t *testing.T) {
+	start := metav1.NewTime(time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(start.Add(3*time.Minute + 20*time.Second))
+	activity := &v1alpha1.ActivityRecord{
+		Owner:         "myorg",
+		Repo:          "myrepo",
+		LastCommitSHA: "abcdef",
+		LogURL:        "https://logs.example.com/unit",
+		Status:        v1alpha1.RunningState,
+		StartTime:     &start,
+		Stages: []*v1alpha1.ActivityStageOrStep{
+			{
+				Name:   "build",
+				Status: v1alpha1.RunningState,
+				Steps: []*v1alpha1.ActivityStageOrStep{
+					{Name: "compile", Status: v1alpha1.SuccessState},
+					{Name: "test", Status: v1alpha1.RunningState},
+				},
+			},
+		},
+	}
+	j := &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-job"},
+		Status: v1alpha1.LighthouseJobStatus{
+			ReportURL: "https://dashboard.example.com/unit",
+		},
+	}
+	scmClient := &fake.SCMClient{}
+
+	err := reportCheckRun(scmClient, activity, j, "unit", toScmStatusDescriptionRunningStages(activity, "github"))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), j.Status.CheckRunID)
+	created := scmClient.CheckRuns[1]
+	assert.Equal(t, "unit", created.Name)
+	assert.Equal(t, "abcdef", created.HeadSHA)
+	assert.Equal(t, "my-job", created.ExternalID)
+	assert.Equal(t, scmprovider.CheckRunInProgress, created.Status)
+	assert.Empty(t, created.Conclusion)
+	assert.Equal(t, "Pipeline running stage(s): build", created.Output.Title)
+
+	activity.Status = v1alpha1.FailureState
+	activity.CompletionTime = &end
+	activity.Stages[0].Status = v1alpha1.FailureState
+	activity.Stages[0].StartTime = &start
+	activity.Stages[0].CompletionTime = &end
+	activity.Stages[0].Steps[1].Status = v1alpha1.FailureState
+	j.Status.ArtifactsURL = "https://artifacts.example.com/unit"
+	j.Status.TestFailures = []v1alpha1.TestFailure{{Suite: "pkg", Name: "TestSomething", Message: "expected 1\ngot 2"}}
+
+	err = reportCheckRun(scmClient, activity, j, "unit", toScmStatusDescriptionRunningStages(activity, "github"))
+	require.NoError(t, err)
+	require.Len(t, scmClient.CheckRuns, 1, "the check run should be updated")
+	updated := scmClient.CheckRuns[1]
+	assert.Equal(t, scmprovider.CheckRunCompleted, updated.Status)
+	assert.Equal(t, scmprovider.CheckRunFailure, updated.Conclusion)
+	require.NotNil(t, updated.CompletedAt)
+	assert.Equal(t, end.Time, *updated.CompletedAt)
+	assert.Equal(t, `**Pipeline failed** in 3m20s
+
+| Stage | Status | Duration |
+|---|---|---|
+| build | failure | 3m20s |
+
+### Failed steps
+
+- `+"`build / test`"+`
+
+### Failed tests
+
+- `+"`pkg.TestSomething`"+`: expected 1 got 2
+
+[Pipeline](https://dashboard.example.com/unit) | [Logs](https://logs.example.com/unit) | [Artifacts](https://artifacts.example.com/unit)
+`, updated.Output.Summary)
+}
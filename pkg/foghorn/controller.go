@@ -10,16 +10,31 @@ import (
 
 	"github.com/jenkins-x/go-scm/scm"
 	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/cloudevents"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+	"github.com/jenkins-x/lighthouse/pkg/joblogs"
+	"github.com/jenkins-x/lighthouse/pkg/launcher"
+	"github.com/jenkins-x/lighthouse/pkg/notification"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider/reporter"
+	"github.com/jenkins-x/lighthouse/pkg/statuswebhook"
+	"github.com/jenkins-x/lighthouse/pkg/tracing"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/inrepo"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/jenkins-x/lighthouse/pkg/watcher"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -27,6 +42,14 @@ import (
 
 const (
 	controllerName = "foghorn"
+
+	// reportRetryBaseDelay is the delay before the first retry of a failed report
+	reportRetryBaseDelay = 5 * time.Second
+	// reportRetryMaxDelay caps the exponential backoff between the retries of a failed report
+	reportRetryMaxDelay = 10 * time.Minute
+	// maxReportAttempts is the number of consecutive failed attempts after which a report is no longer retried,
+	// which tolerates provider outages of several hours
+	maxReportAttempts = 30
 )
 
 // LighthouseJobReconciler listens for changes to LighthouseJobs and updates the corresponding LighthouseJob status and provider commit statuses.
@@ -41,6 +64,19 @@ type LighthouseJobReconciler struct {
 	jobConfig    *config.Agent
 	pluginConfig *plugins.ConfigAgent
 
+	webhookReporter   *statuswebhook.Reporter
+	eventEmitter      *cloudevents.Emitter
+	notifier          *notification.Notifier
+	emailReporter     jobEmailer
+	jobOwnersReporter jobOwnersReporter
+	quarantiner       testQuarantiner
+	testReports       testReportReader
+	inRepoCache       *inrepo.ContentCache
+	secrets           *secret.Resolver
+	clock             clock.Clock
+	states            *stateTracker
+	webhookDeliveries *webhookDeliveries
+
 	wg *sync.WaitGroup
 	ns string
 }
@@ -69,18 +105,57 @@ func NewLighthouseJobReconcilerWithConfig(client client.Client, scheme *runtime.
 		}
 	}
 
+	secrets := secret.NewResolver(func(name string) (*corev1.Secret, error) {
+		s := &corev1.Secret{}
+		err := client.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: name}, s)
+		return s, err
+	}, secret.DefaultResolverTTL)
+
 	return &LighthouseJobReconciler{
-		client:           client,
-		scheme:           scheme,
-		logger:           logger,
-		ns:               ns,
-		jobConfig:        jobConfig,
-		pluginConfig:     pluginConfig,
-		ConfigMapWatcher: configMapWatcher,
-		wg:               &sync.WaitGroup{},
+		client:            client,
+		scheme:            scheme,
+		logger:            logger,
+		ns:                ns,
+		jobConfig:         jobConfig,
+		pluginConfig:      pluginConfig,
+		ConfigMapWatcher:  configMapWatcher,
+		webhookReporter:   statuswebhook.NewReporter(logger, secrets),
+		eventEmitter:      cloudevents.NewEmitter(logger),
+		notifier:          notification.NewNotifier(logger, secrets),
+		emailReporter:     notification.NewEmailReporter(logger, secrets),
+		jobOwnersReporter: &scmJobOwnersReporter{logger: logger, jobConfig: jobConfig},
+		quarantiner:       &scmQuarantiner{client: client, ns: ns, jobConfig: jobConfig, logger: logger},
+		testReports:       &artifactsTestReports{},
+		inRepoCache:       inrepo.NewContentCache(inrepo.DefaultContentCacheSize),
+		secrets:           secrets,
+		clock:             clock.RealClock{},
+		states:            newStateTracker(),
+		webhookDeliveries: newWebhookDeliveries(),
+		wg:                &sync.WaitGroup{},
 	}, nil
 }
 
+// Config returns the Lighthouse configuration of the reconciler
+func (r *LighthouseJobReconciler) Config() config.Getter {
+	return r.jobConfig.Config
+}
+
+// Drain waits up to timeout for the external plugins, the status webhooks, the chat notifications and the cloud events being sent
+// once the manager stopped, returning false if it timed out
+func (r *LighthouseJobReconciler) Drain(timeout time.Duration) bool {
+	finished := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
 // SetupWithManager sets up the reconciler with its manager
 func (r *LighthouseJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -102,29 +177,134 @@ func (r *LighthouseJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 		// we'll ignore not-found errors, since they can't be fixed by an immediate
 		// requeue (we'll need to wait for a new notification), and we can get them
 		// on deleted requests.
+		if apierrors.IsNotFound(err) {
+			r.states.forget(req.Name)
+			r.webhookDeliveries.forget(req.Name)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
-
-	activityRecord := job.Status.Activity
-
-	if activityRecord == nil {
-		// There's no activity on the job, so there's nothing for us to do.
-		return ctrl.Result{}, nil
+	if r.states.observe(&job) {
+		traceCompletedJob(&job)
+		observeCompletedJob(&job)
 	}
 
-	// Update the job's status for the activity.
 	jobCopy := job.DeepCopy()
-	r.updateJobStatusForActivity(activityRecord, jobCopy)
-	r.reportStatus(activityRecord, jobCopy)
+	var result ctrl.Result
+
+	// the failed tests are read before the job state is reported so that they are part of the reports
+	r.readTestFailures(jobCopy)
+
+	if job.Status.State == lighthousev1alpha1.QueuedState {
+		result = r.syncQueuedJob(jobCopy)
+	} else if activityRecord := job.Status.Activity; activityRecord != nil {
+		// Update the job's status for the activity.
+		r.updateJobStatusForActivity(activityRecord, jobCopy)
+		result = r.reportStatusWithRetry(activityRecord, jobCopy)
+	}
+	if !r.reportWebhooks(jobCopy) && (result.RequeueAfter == 0 || result.RequeueAfter > statusWebhookRetryPeriod) {
+		// the job is reconciled again to record the state delivered to the status webhooks and retry the failed ones
+		result.RequeueAfter = statusWebhookRetryPeriod
+	}
+	r.reportEmail(jobCopy)
+	r.reportJobOwners(jobCopy)
+	r.reportFlakes(jobCopy)
 
 	if !reflect.DeepEqual(job.Status, jobCopy.Status) {
 		if err := r.client.Status().Update(ctx, jobCopy); err != nil {
 			r.logger.Errorf("Failed to update LighthouseJob status: %s", err)
 			return ctrl.Result{}, err
 		}
+		if r.states.observe(jobCopy) {
+			traceCompletedJob(jobCopy)
+			observeCompletedJob(jobCopy)
+		}
 	}
 
-	return ctrl.Result{}, nil
+	return result, nil
+}
+
+// traceCompletedJob records the span of the whole run of a job which just completed, as a child of the span which
+// launched it, if any
+func traceCompletedJob(j *lighthousev1alpha1.LighthouseJob) {
+	if !j.Complete() {
+		return
+	}
+	parent, ok := tracing.FromAnnotations(j.Annotations)
+	if !ok {
+		return
+	}
+	_, span := tracing.Start(tracing.ContextWithSpanContext(nil, parent), "job "+j.Spec.Job, tracing.KindInternal)
+	span.SetStartTime(j.CreationTimestamp.Time)
+	span.SetAttribute("lighthouse_job", j.Name)
+	span.SetAttribute("agent", j.Spec.Agent)
+	span.SetAttribute("state", string(j.Status.State))
+	if j.Status.State == lighthousev1alpha1.FailureState || j.Status.State == lighthousev1alpha1.ErrorState {
+		span.RecordError(errors.Errorf("job %s: %s", j.Status.State, j.Status.Description))
+	}
+	end := time.Now()
+	if j.Status.CompletionTime != nil {
+		end = j.Status.CompletionTime.Time
+	}
+	span.EndAt(end)
+}
+
+// syncQueuedJob triggers the job once the maintenance window it was queued for has ended, otherwise it reports
+// the job as pending and requeues it to check the window again later.
+func (r *LighthouseJobReconciler) syncQueuedJob(j *lighthousev1alpha1.LighthouseJob) ctrl.Result {
+	var org, repo string
+	if j.Spec.Refs != nil {
+		org, repo = j.Spec.Refs.Org, j.Spec.Refs.Repo
+	}
+	now := r.clock.Now()
+	var window *lighthouse.MaintenanceWindow
+	if cfg := r.jobConfig.Config(); cfg != nil {
+		window = cfg.ActiveMaintenanceWindow(org, repo, now)
+	}
+	if window == nil {
+		r.logger.WithField("job", j.Name).Info("Maintenance window ended, triggering queued LighthouseJob")
+		j.Status.State = lighthousev1alpha1.TriggeredState
+		j.Status.Description = ""
+		return ctrl.Result{}
+	}
+
+	description := launcher.QueuedDescription(window)
+	if j.Status.Description != description || j.Status.LastReportState != scm.StatePending.String() {
+		j.Status.Description = description
+		if err := r.reportQueuedStatus(j, description); err != nil {
+			r.logger.WithField("job", j.Name).WithError(err).Warn("failed to report queued status")
+		} else {
+			j.Status.LastReportState = scm.StatePending.String()
+		}
+	}
+
+	// check again at least every minute in case the window is changed or removed
+	requeueAfter := window.End.Sub(now)
+	if requeueAfter > time.Minute {
+		requeueAfter = time.Minute
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}
+}
+
+// reportQueuedStatus sets the pending commit status of a queued job.
+func (r *LighthouseJobReconciler) reportQueuedStatus(j *lighthousev1alpha1.LighthouseJob, description string) error {
+	refs := j.Spec.Refs
+	if refs == nil || j.Spec.Context == "" {
+		return nil
+	}
+	sha := refs.BaseSHA
+	if len(refs.Pulls) > 0 {
+		sha = refs.Pulls[0].SHA
+	}
+	scmClient, _, _, _, err := util.GetSCMClient(refs.Org, r.jobConfig.Config)
+	if err != nil {
+		return errors.Wrap(err, "failed to create SCM client")
+	}
+	_, err = scmClient.CreateStatus(refs.Org, refs.Repo, sha, &scm.StatusInput{
+		State: scm.StatePending,
+		Label: j.Spec.Context,
+		Desc:  description,
+	})
+	return err
 }
 
 func (r *LighthouseJobReconciler) updateJobStatusForActivity(activity *lighthousev1alpha1.ActivityRecord, job *lighthousev1alpha1.LighthouseJob) {
@@ -139,7 +319,49 @@ func (r *LighthouseJobReconciler) updateJobStatusForActivity(activity *lighthous
 	}
 }
 
-func (r *LighthouseJobReconciler) reportStatus(activity *lighthousev1alpha1.ActivityRecord, j *lighthousev1alpha1.LighthouseJob) {
+// reportStatusWithRetry reports the job state to the SCM provider. The failed reports, e.g. because the provider is
+// down or rate limits us, are recorded in the job status and retried with an exponential backoff, so that the
+// commit statuses do not stay out of sync with the jobs after an outage, even if foghorn is restarted meanwhile.
+func (r *LighthouseJobReconciler) reportStatusWithRetry(activity *lighthousev1alpha1.ActivityRecord, j *lighthousev1alpha1.LighthouseJob) ctrl.Result {
+	now := r.clock.Now()
+	if next := j.Status.NextReportTime; next != nil && now.Before(next.Time) {
+		return ctrl.Result{RequeueAfter: next.Sub(now)}
+	}
+	err := r.reportStatus(activity, j)
+	if err == nil {
+		j.Status.ReportAttempts = 0
+		j.Status.NextReportTime = nil
+		return ctrl.Result{}
+	}
+
+	j.Status.ReportAttempts++
+	logger := r.logger.WithFields(map[string]interface{}{"job": j.Name, "attempts": j.Status.ReportAttempts}).WithError(err)
+	if j.Status.ReportAttempts >= maxReportAttempts {
+		logger.Error("giving up reporting the job state to the SCM provider")
+		j.Status.NextReportTime = nil
+		return ctrl.Result{}
+	}
+	delay := reportRetryDelay(j.Status.ReportAttempts)
+	j.Status.NextReportTime = &metav1.Time{Time: now.Add(delay)}
+	logger.Warnf("failed to report the job state to the SCM provider, retrying in %s", delay)
+	return ctrl.Result{RequeueAfter: delay}
+}
+
+// reportRetryDelay returns the delay before retrying a report after the given number of failed attempts
+func reportRetryDelay(attempts int) time.Duration {
+	delay := reportRetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= reportRetryMaxDelay {
+			return reportRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// reportStatus reports the job state as a commit status or check run, and updates the PR comment listing the
+// failed jobs. It returns an error if the SCM provider could not be updated, so that the report is retried.
+func (r *LighthouseJobReconciler) reportStatus(activity *lighthousev1alpha1.ActivityRecord, j *lighthousev1alpha1.LighthouseJob) (err error) {
 	sha := activity.LastCommitSHA
 
 	owner := activity.Owner
@@ -160,32 +382,35 @@ func (r *LighthouseJobReconciler) reportStatus(activity *lighthousev1alpha1.Acti
 		"buildNumber": activity.BuildIdentifier,
 		"duration":    durationString(activity.StartTime, activity.CompletionTime),
 	}
+	eventID := eventid.FromLabels(j.Labels)
+	if eventID != "" {
+		fields[eventid.Field] = eventID
+	}
 	if gitURL == "" {
 		r.logger.WithFields(fields).Debugf("Cannot report pipeline %s as we have no git SHA", activity.Name)
-		return
-
+		return nil
 	}
 	if sha == "" {
 		r.logger.WithFields(fields).Debugf("Cannot report pipeline %s as we have no git SHA", activity.Name)
-		return
+		return nil
 	}
 	if owner == "" {
 		r.logger.WithFields(fields).Debugf("Cannot report pipeline %s as we have no git Owner", activity.Name)
-		return
+		return nil
 	}
 	if repo == "" {
 		r.logger.WithFields(fields).Debugf("Cannot report pipeline %s as we have no git repository name", activity.Name)
-		return
+		return nil
 	}
 
 	if statusInfo.scmStatus == scm.StateUnknown {
-		return
+		return nil
 	}
 
 	switch scm.ToState(j.Status.LastReportState) {
 	// already completed - avoid reporting again if a promotion happens after a PR has merged and the pipeline updates status
 	case scm.StateFailure, scm.StateError, scm.StateSuccess, scm.StateCanceled:
-		return
+		return nil
 	}
 
 	r.logger.WithFields(fields).Warnf("last report: %s, current: %s, last desc: %s, current: %s", j.Status.LastReportState, statusInfo.scmStatus.String(),
@@ -194,19 +419,37 @@ func (r *LighthouseJobReconciler) reportStatus(activity *lighthousev1alpha1.Acti
 	// Check if state and running stages haven't changed and return if they haven't
 	if scm.ToState(j.Status.LastReportState) == statusInfo.scmStatus &&
 		j.Status.Description == statusInfo.description {
-		return
+		return nil
 	}
 
-	// Trigger external plugins if appropriate
-	if external := util.ExternalPluginsForEvent(r.pluginConfig, util.LighthousePayloadTypeActivity, fmt.Sprintf("%s/%s", owner, repo)); len(external) > 0 {
-		go util.CallExternalPluginsWithActivityRecord(r.logger, external, activity, util.HMACToken(), r.wg)
+	// Trigger external plugins if appropriate, only once when a failed report is retried
+	if external := util.ExternalPluginsForEvent(r.pluginConfig, util.LighthousePayloadTypeActivity, fmt.Sprintf("%s/%s", owner, repo)); len(external) > 0 && j.Status.ReportAttempts == 0 {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			util.CallExternalPluginsWithActivityRecord(r.logger, external, activity, util.HMACToken(), r.secrets, r.wg)
+		}()
 	}
 
+	_, span := tracing.Start(tracing.ContextWithAnnotations(nil, j.Annotations), "report "+statusInfo.scmStatus.String(), tracing.KindClient)
+	span.SetAttribute("lighthouse_job", j.Name)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	pipelineContext := activity.Context
 	if pipelineContext == "" {
 		pipelineContext = "jenkins-x"
 	}
 
+	cfg := r.jobConfig.Config()
+	j.Status.ReportURL = r.detailsURL(cfg, j)
+	if j.Status.ReportURL == "" {
+		// link to the live logs until the engine provides a report URL
+		j.Status.ReportURL = joblogs.URL(cfg.LogStreaming.URL, j.Name, []byte(util.HMACToken()))
+	}
+
 	gitRepoStatus := &scm.StatusInput{
 		State:  statusInfo.scmStatus,
 		Label:  pipelineContext,
@@ -216,24 +459,126 @@ func (r *LighthouseJobReconciler) reportStatus(activity *lighthousev1alpha1.Acti
 	scmClient, _, _, _, err := util.GetSCMClient(owner, r.jobConfig.Config)
 	if err != nil {
 		r.logger.WithFields(fields).WithError(err).Warnf("failed to create SCM client")
-		return
+		return errors.Wrap(err, "failed to create SCM client")
+	}
+	// the statuses and comments carry the ID of the webhook which triggered the job
+	if c, ok := scmClient.(*scmprovider.Client); ok && eventID != "" {
+		scmClient = c.WithContext(eventid.ContextWithID(nil, eventID))
 	}
 
-	_, err = scmClient.CreateStatus(owner, repo, sha, gitRepoStatus)
-	if err != nil {
-		r.logger.WithFields(fields).WithError(err).Warnf("failed to report git status with target URL '%s'", gitRepoStatus.Target)
-		// TODO: Need something here to prevent infinite attempts to create status from just bombing us. (apb)
-		return
+	switch {
+	case cfg.CheckRuns.EnabledFor(owner, repo) && scmClient.SupportsChecks():
+		err = reportCheckRun(scmClient, activity, j, pipelineContext, statusInfo)
+		if err != nil {
+			r.logger.WithFields(fields).WithError(err).Warnf("failed to report check run with details URL '%s'", gitRepoStatus.Target)
+			return errors.Wrap(err, "failed to report check run")
+		}
+	case scmClient.ProviderType() == "gitlab":
+		err = reportGitLabStatus(scmClient, cfg.GitLabOptions, activity, j, pipelineContext, statusInfo)
+		if err != nil {
+			r.logger.WithFields(fields).WithError(err).Warnf("failed to report GitLab status with target URL '%s'", gitRepoStatus.Target)
+			return errors.Wrap(err, "failed to report GitLab status")
+		}
+	default:
+		_, err = scmClient.CreateStatus(owner, repo, sha, gitRepoStatus)
+		if err != nil {
+			r.logger.WithFields(fields).WithError(err).Warnf("failed to report git status with target URL '%s'", gitRepoStatus.Target)
+			return errors.Wrap(err, "failed to report git status")
+		}
 	}
 
-	err = reporter.Report(scmClient, r.jobConfig.Config().Plank.ReportTemplate, j, []job.PipelineKind{job.PresubmitJob})
+	r.markQuarantined(cfg, j)
+	err = reporter.Report(scmClient, cfg.Plank.ReportTemplate, j, []job.PipelineKind{job.PresubmitJob})
 	if err != nil {
-		// For now, we're just going to ignore failures here.
 		r.logger.WithFields(fields).WithError(err).Warnf("failed to update comments on the PR")
+		return errors.Wrap(err, "failed to update comments on the PR")
+	}
+	if err := r.reportSummary(scmClient, cfg, j); err != nil {
+		r.logger.WithFields(fields).WithError(err).Warnf("failed to report the summary status of the PR")
+		return errors.Wrap(err, "failed to report the summary status of the PR")
 	}
 	r.logger.WithFields(fields).Info("reported git status")
+	if j.Status.LastReportState == "" {
+		observeFirstStatus(j, r.clock.Now())
+	}
 	j.Status.Description = statusInfo.description
 	j.Status.LastReportState = statusInfo.scmStatus.String()
+	return nil
+}
+
+// reportWebhooks posts the job state in the background to the matching status webhooks it was not delivered to yet,
+// and sends it as a CloudEvent and its failure to the chat notifications if it changed since the last time it was
+// sent. The state delivered to each webhook is recorded in the job status the next time the job is reconciled, so
+// that the webhooks which failed are posted to again without the others. It returns false if the state remains to be
+// delivered to some webhooks.
+func (r *LighthouseJobReconciler) reportWebhooks(j *lighthousev1alpha1.LighthouseJob) bool {
+	cfg := r.jobConfig.Config()
+	if cfg == nil {
+		return true
+	}
+	event := statuswebhook.EventForState(j.Status.State)
+	if event == "" {
+		return true
+	}
+	r.reportNotifications(cfg, event, j)
+
+	r.webhookDeliveries.record(j)
+	if len(cfg.StatusWebhooks) == 0 {
+		return true
+	}
+	delivered := true
+	var payload *statuswebhook.Payload
+	for _, w := range statuswebhook.Matching(cfg.StatusWebhooks, event, j) {
+		if j.Status.WebhookStates[w.URL] == event {
+			continue
+		}
+		delivered = false
+		if !r.webhookDeliveries.start(j.Name, w.URL) {
+			// the previous state of the job is still being posted to the webhook
+			continue
+		}
+		if payload == nil {
+			payload = statuswebhook.NewPayload(event, j)
+		}
+		name, webhook := j.Name, w
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			err := r.webhookReporter.Report([]lighthouse.StatusWebhook{webhook}, payload)
+			r.webhookDeliveries.finish(name, webhook.URL, event, err == nil)
+		}()
+	}
+	return delivered
+}
+
+// reportNotifications sends the job state as a CloudEvent, and its failure to the chat notifications, if it changed
+// since the last time it was sent.
+func (r *LighthouseJobReconciler) reportNotifications(cfg *config.Config, event string, j *lighthousev1alpha1.LighthouseJob) {
+	if len(cfg.ChatNotifications) == 0 && cfg.CloudEvents.Sink == "" {
+		return
+	}
+	if event == j.Status.LastNotificationState {
+		return
+	}
+	j.Status.LastNotificationState = event
+	if event == lighthouse.StatusWebhookFailed && len(cfg.ChatNotifications) > 0 {
+		notifications := cfg.ChatNotifications
+		failure := notification.JobFailed(j)
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			_ = r.notifier.Notify(notifications, failure)
+		}()
+	}
+	if cfg.CloudEvents.Sink != "" {
+		cloudEvents := cfg.CloudEvents
+		ce := cloudevents.JobEvent(event, j, r.clock.Now())
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			_ = r.eventEmitter.Emit(&cloudEvents, ce)
+		}()
+	}
 }
 
 type reportStatusInfo struct {
@@ -265,6 +610,10 @@ func toScmStatusDescriptionRunningStages(activity *lighthousev1alpha1.ActivityRe
 		info.scmStatus = scm.StateUnknown
 		info.description = "Pipeline in unknown state"
 	}
+	// the engines can explain the state, e.g. why the pipeline was aborted
+	if activity.Description != "" {
+		info.description = activity.Description
+	}
 
 	runningStages := activity.RunningStages()
 	// GitLab does not currently support updating description without changing state, so we need simple descriptions there.
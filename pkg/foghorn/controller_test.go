@@ -2,25 +2,33 @@ package foghorn
 
 import (
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/cloudevents"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/branchprotection"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
 	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/statuswebhook"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/jenkins-x/lighthouse/pkg/watcher"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -137,6 +145,327 @@ func TestReconcile(t *testing.T) {
 	}
 }
 
+func TestReconcileStatusWebhooks(t *testing.T) {
+	var events []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events = append(events, r.Header.Get(statuswebhook.EventHeader))
+	}))
+	defer server.Close()
+
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{
+		ProwConfig: config.ProwConfig{
+			ProviderConfig: &lighthouse.ProviderConfig{
+				Kind:    "fake",
+				Server:  "https://github.com",
+				BotUser: "jenkins-x-bot",
+			},
+			StatusWebhooks: []lighthouse.StatusWebhook{{Name: "dashboard", URL: server.URL}},
+		},
+	})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{})
+
+	ns := "jx"
+	observedJob, err := loadLighthouseJob(path.Join("test_data", "status-change"), "observed-lhjob.yml")
+	require.NoError(t, err)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, observedJob)
+	reconciler, err := NewLighthouseJobReconcilerWithConfig(c, scheme, ns, &watcher.ConfigMapWatcher{}, configAgent, pluginAgent)
+	require.NoError(t, err)
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: observedJob.GetName()}}
+	result, err := reconciler.Reconcile(req)
+	require.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter, "the job is reconciled again to record the delivered state")
+	// reconciling again while the state is being posted must not post it again
+	_, err = reconciler.Reconcile(req)
+	require.NoError(t, err)
+	reconciler.wg.Wait()
+
+	_, err = reconciler.Reconcile(req)
+	require.NoError(t, err)
+	var updatedJob lighthousev1alpha1.LighthouseJob
+	require.NoError(t, c.Get(nil, req.NamespacedName, &updatedJob))
+	assert.Equal(t, map[string]string{server.URL: lighthouse.StatusWebhookRunning}, updatedJob.Status.WebhookStates)
+
+	// reconciling again without a state change must not post the job state again
+	_, err = reconciler.Reconcile(req)
+	require.NoError(t, err)
+	reconciler.wg.Wait()
+	assert.Equal(t, []string{lighthouse.StatusWebhookRunning}, events)
+}
+
+func TestReconcileStatusWebhooksFailure(t *testing.T) {
+	var delivered, failed []string
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered = append(delivered, r.Header.Get(statuswebhook.EventHeader))
+	}))
+	defer ok.Close()
+	fail := true
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failed = append(failed, r.Header.Get(statuswebhook.EventHeader))
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer flaky.Close()
+
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{
+		ProwConfig: config.ProwConfig{
+			ProviderConfig: &lighthouse.ProviderConfig{
+				Kind:    "fake",
+				Server:  "https://github.com",
+				BotUser: "jenkins-x-bot",
+			},
+			StatusWebhooks: []lighthouse.StatusWebhook{
+				{Name: "dashboard", URL: ok.URL},
+				{Name: "flaky", URL: flaky.URL},
+			},
+		},
+	})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{})
+
+	ns := "jx"
+	observedJob, err := loadLighthouseJob(path.Join("test_data", "status-change"), "observed-lhjob.yml")
+	require.NoError(t, err)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, observedJob)
+	reconciler, err := NewLighthouseJobReconcilerWithConfig(c, scheme, ns, &watcher.ConfigMapWatcher{}, configAgent, pluginAgent)
+	require.NoError(t, err)
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: observedJob.GetName()}}
+	_, err = reconciler.Reconcile(req)
+	require.NoError(t, err)
+	reconciler.wg.Wait()
+
+	fail = false
+	result, err := reconciler.Reconcile(req)
+	require.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter, "the failed webhook is retried")
+	var updatedJob lighthousev1alpha1.LighthouseJob
+	require.NoError(t, c.Get(nil, req.NamespacedName, &updatedJob))
+	assert.Equal(t, map[string]string{ok.URL: lighthouse.StatusWebhookRunning}, updatedJob.Status.WebhookStates, "the state is recorded for the webhooks it was delivered to")
+	reconciler.wg.Wait()
+
+	_, err = reconciler.Reconcile(req)
+	require.NoError(t, err)
+	require.NoError(t, c.Get(nil, req.NamespacedName, &updatedJob))
+	assert.Equal(t, map[string]string{ok.URL: lighthouse.StatusWebhookRunning, flaky.URL: lighthouse.StatusWebhookRunning}, updatedJob.Status.WebhookStates)
+	assert.Equal(t, []string{lighthouse.StatusWebhookRunning}, delivered, "the state is not posted again to the webhooks it was delivered to")
+	assert.Equal(t, []string{lighthouse.StatusWebhookRunning, lighthouse.StatusWebhookRunning}, failed)
+}
+
+func TestReconcileCloudEvents(t *testing.T) {
+	var eventTypes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eventTypes = append(eventTypes, r.Header.Get("Ce-Type"))
+	}))
+	defer server.Close()
+
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{
+		ProwConfig: config.ProwConfig{
+			ProviderConfig: &lighthouse.ProviderConfig{
+				Kind:    "fake",
+				Server:  "https://github.com",
+				BotUser: "jenkins-x-bot",
+			},
+			CloudEvents: lighthouse.CloudEvents{Sink: server.URL},
+		},
+	})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{})
+
+	ns := "jx"
+	observedJob, err := loadLighthouseJob(path.Join("test_data", "status-change"), "observed-lhjob.yml")
+	require.NoError(t, err)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, observedJob)
+	reconciler, err := NewLighthouseJobReconcilerWithConfig(c, scheme, ns, &watcher.ConfigMapWatcher{}, configAgent, pluginAgent)
+	require.NoError(t, err)
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: observedJob.GetName()}}
+	_, err = reconciler.Reconcile(req)
+	require.NoError(t, err)
+	// reconciling again without a state change must not emit the event again
+	_, err = reconciler.Reconcile(req)
+	require.NoError(t, err)
+	reconciler.wg.Wait()
+
+	assert.Equal(t, []string{cloudevents.JobStartedType}, eventTypes)
+}
+
+func TestReconcileQueuedJob(t *testing.T) {
+	oldToken, hadToken := os.LookupEnv("GIT_TOKEN")
+	require.NoError(t, os.Setenv("GIT_TOKEN", "abcd"))
+	defer func() {
+		if hadToken {
+			os.Setenv("GIT_TOKEN", oldToken)
+		} else {
+			os.Unsetenv("GIT_TOKEN")
+		}
+	}()
+
+	now := time.Date(2020, 7, 20, 21, 0, 0, 0, time.UTC)
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{
+		ProwConfig: config.ProwConfig{
+			ProviderConfig: &lighthouse.ProviderConfig{
+				Kind:    "fake",
+				Server:  "https://github.com",
+				BotUser: "jenkins-x-bot",
+			},
+			MaintenanceWindows: []lighthouse.MaintenanceWindow{{
+				Name:  "upgrade",
+				Start: now.Add(-time.Hour),
+				End:   now.Add(30 * time.Second),
+			}},
+		},
+	})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{})
+
+	ns := "jx"
+	queuedJob, err := loadLighthouseJob(path.Join("test_data", "status-change"), "observed-lhjob.yml")
+	require.NoError(t, err)
+	queuedJob.Status = lighthousev1alpha1.LighthouseJobStatus{State: lighthousev1alpha1.QueuedState}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, queuedJob)
+	reconciler, err := NewLighthouseJobReconcilerWithConfig(c, scheme, ns, &watcher.ConfigMapWatcher{}, configAgent, pluginAgent)
+	require.NoError(t, err)
+	fakeClock := clock.NewFakeClock(now)
+	reconciler.clock = fakeClock
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: queuedJob.GetName()}}
+	result, err := reconciler.Reconcile(req)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, result.RequeueAfter)
+
+	var updatedJob lighthousev1alpha1.LighthouseJob
+	require.NoError(t, c.Get(nil, req.NamespacedName, &updatedJob))
+	assert.Equal(t, lighthousev1alpha1.QueuedState, updatedJob.Status.State)
+	assert.Equal(t, "Queued until maintenance window upgrade ends at 21:00 UTC Jul 20", updatedJob.Status.Description)
+	assert.Equal(t, "pending", updatedJob.Status.LastReportState)
+
+	fakeClock.Step(30 * time.Second)
+	result, err = reconciler.Reconcile(req)
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+
+	var releasedJob lighthousev1alpha1.LighthouseJob
+	require.NoError(t, c.Get(nil, req.NamespacedName, &releasedJob))
+	assert.Equal(t, lighthousev1alpha1.TriggeredState, releasedJob.Status.State)
+	assert.Empty(t, releasedJob.Status.Description)
+}
+
+func TestReconcileRetriesFailedReports(t *testing.T) {
+	oldToken, hadToken := os.LookupEnv("GIT_TOKEN")
+	require.NoError(t, os.Setenv("GIT_TOKEN", "abcd"))
+	defer func() {
+		if hadToken {
+			os.Setenv("GIT_TOKEN", oldToken)
+		} else {
+			os.Unsetenv("GIT_TOKEN")
+		}
+	}()
+
+	outage := true
+	var statuses int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if outage {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte("[]"))
+			return
+		}
+		if strings.Contains(r.URL.Path, "/statuses/") {
+			statuses++
+		}
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{
+		ProwConfig: config.ProwConfig{
+			ProviderConfig: &lighthouse.ProviderConfig{
+				Kind:    "github",
+				Server:  server.URL,
+				BotUser: "jenkins-x-bot",
+			},
+		},
+	})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{})
+
+	ns := "jx"
+	observedJob, err := loadLighthouseJob(path.Join("test_data", "status-change"), "observed-lhjob.yml")
+	require.NoError(t, err)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, observedJob)
+	reconciler, err := NewLighthouseJobReconcilerWithConfig(c, scheme, ns, &watcher.ConfigMapWatcher{}, configAgent, pluginAgent)
+	require.NoError(t, err)
+	now := time.Date(2020, 7, 20, 21, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(now)
+	reconciler.clock = fakeClock
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: observedJob.GetName()}}
+	for attempt := 1; attempt <= 2; attempt++ {
+		result, err := reconciler.Reconcile(req)
+		require.NoError(t, err)
+		assert.Equal(t, reportRetryDelay(attempt), result.RequeueAfter)
+
+		var updatedJob lighthousev1alpha1.LighthouseJob
+		require.NoError(t, c.Get(nil, req.NamespacedName, &updatedJob))
+		assert.Equal(t, attempt, updatedJob.Status.ReportAttempts)
+		assert.Empty(t, updatedJob.Status.LastReportState, "the failed report should not be recorded as reported")
+		require.NotNil(t, updatedJob.Status.NextReportTime)
+		assert.True(t, fakeClock.Now().Add(result.RequeueAfter).Equal(updatedJob.Status.NextReportTime.Time))
+
+		// reconciling before the retry is due does not call the provider again
+		fakeClock.Step(time.Second)
+		result, err = reconciler.Reconcile(req)
+		require.NoError(t, err)
+		assert.Equal(t, reportRetryDelay(attempt)-time.Second, result.RequeueAfter)
+		fakeClock.Step(result.RequeueAfter)
+	}
+
+	outage = false
+	result, err := reconciler.Reconcile(req)
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, 1, statuses)
+
+	var reportedJob lighthousev1alpha1.LighthouseJob
+	require.NoError(t, c.Get(nil, req.NamespacedName, &reportedJob))
+	assert.Zero(t, reportedJob.Status.ReportAttempts)
+	assert.Nil(t, reportedJob.Status.NextReportTime)
+	assert.Equal(t, "running", reportedJob.Status.LastReportState)
+}
+
+func TestReportRetryDelay(t *testing.T) {
+	assert.Equal(t, 5*time.Second, reportRetryDelay(1))
+	assert.Equal(t, 10*time.Second, reportRetryDelay(2))
+	assert.Equal(t, 80*time.Second, reportRetryDelay(5))
+	assert.Equal(t, reportRetryMaxDelay, reportRetryDelay(8))
+	assert.Equal(t, reportRetryMaxDelay, reportRetryDelay(maxReportAttempts))
+}
+
 func loadLighthouseJob(dir string, baseFn string) (*lighthousev1alpha1.LighthouseJob, error) {
 	fileName := filepath.Join(dir, baseFn)
 	exists, err := util.FileExists(fileName)
@@ -0,0 +1,25 @@
+package foghorn
+
+import (
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+)
+
+// detailsURL returns the URL the status of the job links to: the URL resolved from the first details URL template
+// of the config matching the job, or the report URL of the job if none matches or the template fails
+func (r *LighthouseJobReconciler) detailsURL(cfg *config.Config, j *lighthousev1alpha1.LighthouseJob) string {
+	var org, repo string
+	if j.Spec.Refs != nil {
+		org, repo = j.Spec.Refs.Org, j.Spec.Refs.Repo
+	}
+	d := cfg.DetailsURLFor(org, repo, j.Spec.Job, j.Spec.Agent)
+	if d == nil {
+		return j.Status.ReportURL
+	}
+	u, err := d.Execute(j)
+	if err != nil {
+		r.logger.WithField("lighthouseJob", j.Name).WithError(err).Warn("failed to resolve the details URL")
+		return j.Status.ReportURL
+	}
+	return u
+}
@@ -0,0 +1,34 @@
+package foghorn
+
+import (
+	"testing"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetailsURL(t *testing.T) {
+	r := &LighthouseJobReconciler{logger: logrus.NewEntry(logrus.StandardLogger())}
+	cfg := &config.Config{ProwConfig: lighthouse.Config{DetailsURLs: []lighthouse.DetailsURL{
+		{TemplateString: "https://dashboard.example.com/#/namespaces/{{ .Namespace }}/pipelineruns/{{ .Status.ActivityName }}", Agents: []string{"tekton-pipeline"}},
+		{TemplateString: "https://logs.example.com/{{ .Spec.Refs.Org }}/{{ .Spec.Refs.Repo }}/{{ .Spec.Job }}/{{ .Missing }}", Repos: []string{"broken"}},
+	}}}
+	require.NoError(t, cfg.ProwConfig.Parse())
+
+	j := summaryJob("a", "unit", lighthousev1alpha1.RunningState, 0, "sha")
+	j.Spec.Job = "unit"
+	j.Status.ReportURL = "https://engine.example.com/a"
+	assert.Equal(t, "https://engine.example.com/a", r.detailsURL(cfg, j), "no matching template")
+
+	j.Spec.Agent = "tekton-pipeline"
+	j.Status.ActivityName = "a-run"
+	assert.Equal(t, "https://dashboard.example.com/#/namespaces/jx/pipelineruns/a-run", r.detailsURL(cfg, j))
+
+	j.Spec.Agent = "jenkins"
+	j.Spec.Refs.Org = "broken"
+	assert.Equal(t, "https://engine.example.com/a", r.detailsURL(cfg, j), "failing template")
+}
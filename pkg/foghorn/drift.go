@@ -0,0 +1,233 @@
+package foghorn
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultStatusReconcileMinAge is the default age of the last transition of a job before its commit status is
+// repaired, so that the reports still being made or retried by the reconciler are not raced with
+const DefaultStatusReconcileMinAge = 15 * time.Minute
+
+var statusesRepaired = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lighthouse_foghorn_statuses_repaired_total",
+	Help: "A counter of the commit statuses of the open pull requests reported again as they did not match the state of their job, by reason.",
+}, []string{"reason"})
+
+func init() {
+	prometheus.MustRegister(statusesRepaired)
+}
+
+// statusRepairClient is the part of the SCM client used to repair the commit statuses of the pull requests
+type statusRepairClient interface {
+	statusClient
+	GetPullRequest(string, string, int) (*scm.PullRequest, error)
+	ListStatuses(string, string, string) ([]*scm.Status, error)
+}
+
+// pullKey identifies a pull request
+type pullKey struct {
+	org    string
+	repo   string
+	number int
+}
+
+// RunStatusReconciler repairs the commit statuses of the open pull requests every period until the context is done
+func (r *LighthouseJobReconciler) RunStatusReconciler(ctx context.Context, period, minAge time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			repaired, err := r.ReconcileStatuses(minAge)
+			if err != nil {
+				r.logger.WithError(err).Warn("failed to reconcile the commit statuses of the open pull requests")
+			}
+			if repaired > 0 {
+				r.logger.Infof("repaired %d commit statuses of the open pull requests", repaired)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ReconcileStatuses compares the state of the latest presubmit job of each context of the open pull requests with
+// the commit statuses of their head commit, reporting again the statuses which are missing or stale, e.g. as the SCM
+// provider could not be reached when the jobs completed. The jobs whose last transition is more recent than minAge
+// are left to the reconciler. It returns the number of statuses repaired.
+func (r *LighthouseJobReconciler) ReconcileStatuses(minAge time.Duration) (int, error) {
+	var list lighthousev1alpha1.LighthouseJobList
+	if err := r.client.List(context.TODO(), &list, client.InNamespace(r.ns)); err != nil {
+		return 0, errors.Wrap(err, "failed to list the LighthouseJobs")
+	}
+	pulls := map[pullKey][]lighthousev1alpha1.LighthouseJob{}
+	for _, j := range list.Items {
+		refs := j.Spec.Refs
+		if j.Spec.Type != job.PresubmitJob || refs == nil || len(refs.Pulls) == 0 {
+			continue
+		}
+		key := pullKey{org: refs.Org, repo: refs.Repo, number: refs.Pulls[0].Number}
+		pulls[key] = append(pulls[key], j)
+	}
+	keys := make([]pullKey, 0, len(pulls))
+	for key := range pulls {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].org != keys[j].org {
+			return keys[i].org < keys[j].org
+		}
+		if keys[i].repo != keys[j].repo {
+			return keys[i].repo < keys[j].repo
+		}
+		return keys[i].number < keys[j].number
+	})
+
+	cfg := r.jobConfig.Config()
+	repaired := 0
+	var lastErr error
+	for _, key := range keys {
+		log := r.logger.WithFields(logrus.Fields{"gitOwner": key.org, "gitRepo": key.repo, "pr": key.number})
+		scmClient, _, _, _, err := util.GetSCMClient(key.org, r.jobConfig.Config)
+		if err != nil {
+			return repaired, errors.Wrap(err, "failed to create SCM client")
+		}
+		// the jobs reported as check runs have no commit status
+		if cfg.CheckRuns.EnabledFor(key.org, key.repo) && scmClient.SupportsChecks() {
+			continue
+		}
+		n, err := r.repairPullStatuses(scmClient, cfg, key, pulls[key], minAge)
+		repaired += n
+		if err != nil {
+			log.WithError(err).Warn("failed to repair the commit statuses of the pull request")
+			lastErr = err
+		}
+	}
+	return repaired, lastErr
+}
+
+// repairPullStatuses reports again the missing or stale commit statuses of the head commit of the pull request if it
+// is open, returning the number of statuses repaired
+func (r *LighthouseJobReconciler) repairPullStatuses(scmClient statusRepairClient, cfg *config.Config, key pullKey, jobs []lighthousev1alpha1.LighthouseJob, minAge time.Duration) (int, error) {
+	pr, err := scmClient.GetPullRequest(key.org, key.repo, key.number)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get the pull request")
+	}
+	if pr.Closed || pr.Merged {
+		return 0, nil
+	}
+	sha := pr.Head.Sha
+
+	// the latest job of each context reported on the head commit
+	latest := map[string]*lighthousev1alpha1.LighthouseJob{}
+	for i := range jobs {
+		j := &jobs[i]
+		if j.Spec.Refs.Pulls[0].SHA != sha || j.Status.Activity == nil || j.Status.State == lighthousev1alpha1.QueuedState {
+			continue
+		}
+		context := reportedContext(j)
+		if previous, ok := latest[context]; !ok || previous.Status.StartTime.Before(&j.Status.StartTime) {
+			latest[context] = j
+		}
+	}
+	if len(latest) == 0 {
+		return 0, nil
+	}
+
+	statuses, err := scmClient.ListStatuses(key.org, key.repo, sha)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list the commit statuses")
+	}
+	// the statuses are listed the most recent first
+	current := map[string]*scm.Status{}
+	for _, s := range statuses {
+		if _, ok := current[s.Label]; !ok {
+			current[s.Label] = s
+		}
+	}
+
+	contexts := make([]string, 0, len(latest))
+	for context := range latest {
+		contexts = append(contexts, context)
+	}
+	sort.Strings(contexts)
+	repaired := 0
+	now := r.clock.Now()
+	for _, context := range contexts {
+		j := latest[context]
+		if now.Sub(lastTransition(j)) < minAge {
+			continue
+		}
+		info := toScmStatusDescriptionRunningStages(j.Status.Activity, util.GitKind(r.jobConfig.Config))
+		if info.scmStatus == scm.StateUnknown {
+			continue
+		}
+		reason := "missing"
+		if s, ok := current[context]; ok {
+			if sameState(s.State, info.scmStatus) {
+				continue
+			}
+			reason = "stale"
+		}
+		_, err := scmClient.CreateStatus(key.org, key.repo, sha, &scm.StatusInput{
+			State:  info.scmStatus,
+			Label:  context,
+			Desc:   info.description,
+			Target: r.detailsURL(cfg, j),
+		})
+		if err != nil {
+			return repaired, errors.Wrapf(err, "failed to report the %s status", context)
+		}
+		statusesRepaired.WithLabelValues(reason).Inc()
+		r.logger.WithFields(logrus.Fields{"gitOwner": key.org, "gitRepo": key.repo, "gitSHA": sha, "context": context, "reason": reason}).
+			Infof("repaired the %s commit status", info.scmStatus.String())
+		repaired++
+	}
+	// any job of the head commit updates the summary status of the pull request
+	if repaired > 0 {
+		if err := r.reportSummary(scmClient, cfg, latest[contexts[0]]); err != nil {
+			return repaired, errors.Wrap(err, "failed to report the summary status")
+		}
+	}
+	return repaired, nil
+}
+
+// reportedContext returns the context the status of the job is reported with
+func reportedContext(j *lighthousev1alpha1.LighthouseJob) string {
+	if j.Status.Activity.Context != "" {
+		return j.Status.Activity.Context
+	}
+	return "jenkins-x"
+}
+
+// lastTransition returns the time of the last known transition of the job
+func lastTransition(j *lighthousev1alpha1.LighthouseJob) time.Time {
+	if j.Status.CompletionTime != nil {
+		return j.Status.CompletionTime.Time
+	}
+	return j.Status.StartTime.Time
+}
+
+// sameState returns true if the states are the same once the running state, reported as pending by most SCM
+// providers, is normalized
+func sameState(a, b scm.State) bool {
+	if a == scm.StateRunning {
+		a = scm.StatePending
+	}
+	if b == scm.StateRunning {
+		b = scm.StatePending
+	}
+	return a == b
+}
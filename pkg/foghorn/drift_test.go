@@ -0,0 +1,77 @@
+package foghorn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func driftJob(name, context string, state lighthousev1alpha1.PipelineState, started int, sha string) lighthousev1alpha1.LighthouseJob {
+	j := summaryJob(name, context, state, started, sha)
+	j.Status.Activity = &lighthousev1alpha1.ActivityRecord{Name: name, Context: context, Status: state}
+	j.Status.ReportURL = "https://logs/" + name
+	return *j
+}
+
+func TestRepairPullStatuses(t *testing.T) {
+	now := time.Date(2020, 7, 20, 22, 0, 0, 0, time.UTC)
+	r := &LighthouseJobReconciler{
+		logger:    logrus.NewEntry(logrus.StandardLogger()),
+		jobConfig: &config.Agent{},
+		clock:     clock.NewFakeClock(now),
+	}
+	client, data := fakescm.NewDefault()
+	data.PullRequests[7] = &scm.PullRequest{Number: 7, Head: scm.PullRequestBranch{Sha: "sha"}}
+	scmClient := scmprovider.ToClient(client, "bot")
+	_, err := scmClient.CreateStatus("org", "repo", "sha", &scm.StatusInput{Label: "unit", State: scm.StatePending})
+	require.NoError(t, err)
+	_, err = scmClient.CreateStatus("org", "repo", "sha", &scm.StatusInput{Label: "lint", State: scm.StateFailure})
+	require.NoError(t, err)
+
+	jobs := []lighthousev1alpha1.LighthouseJob{
+		// stuck pending after the report of its completion failed
+		driftJob("unit-1", "unit", lighthousev1alpha1.SuccessState, 0, "sha"),
+		// never reported
+		driftJob("e2e-1", "e2e", lighthousev1alpha1.FailureState, 0, "sha"),
+		// up to date
+		driftJob("lint-1", "lint", lighthousev1alpha1.FailureState, 0, "sha"),
+		// still being reported by the reconciler
+		driftJob("docs-1", "docs", lighthousev1alpha1.SuccessState, 55, "sha"),
+		// of a previous commit
+		driftJob("build-1", "build", lighthousev1alpha1.SuccessState, 0, "old-sha"),
+	}
+	key := pullKey{org: "org", repo: "repo", number: 7}
+	repaired, err := r.repairPullStatuses(scmClient, &config.Config{}, key, jobs, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repaired)
+
+	states := map[string]scm.State{}
+	for _, s := range data.Statuses["sha"] {
+		states[s.Label] = s.State
+	}
+	assert.Equal(t, map[string]scm.State{
+		"unit": scm.StateSuccess,
+		"lint": scm.StateFailure,
+		"e2e":  scm.StateFailure,
+	}, states)
+	assert.Empty(t, data.Statuses["old-sha"])
+
+	repaired, err = r.repairPullStatuses(scmClient, &config.Config{}, key, jobs, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0, repaired, "the statuses are repaired once")
+
+	data.PullRequests[7].Closed = true
+	data.Statuses["sha"] = nil
+	repaired, err = r.repairPullStatuses(scmClient, &config.Config{}, key, jobs, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0, repaired, "the statuses of the closed pull requests are not repaired")
+}
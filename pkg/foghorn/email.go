@@ -0,0 +1,96 @@
+package foghorn
+
+import (
+	"context"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// jobEmailer emails the final state of a job
+type jobEmailer interface {
+	Report(*lighthouse.SMTP, *lighthousev1alpha1.LighthouseJob) error
+}
+
+// reportEmail emails the owners of a postsubmit or periodic job once it succeeded or failed, according to its
+// email report configuration. Aborted jobs are not reported.
+func (r *LighthouseJobReconciler) reportEmail(j *lighthousev1alpha1.LighthouseJob) {
+	report := j.Spec.EmailReport
+	if report == nil || (j.Spec.Type != job.PostsubmitJob && j.Spec.Type != job.PeriodicJob) {
+		return
+	}
+	state := j.Status.State
+	if state != lighthousev1alpha1.SuccessState && !failed(state) {
+		return
+	}
+	if j.Status.LastEmailState == string(state) {
+		return
+	}
+	j.Status.LastEmailState = string(state)
+
+	logger := r.logger.WithField("job", j.Name)
+	send := failed(state)
+	if report.When == job.EmailOnStateChange {
+		previous, err := r.previousRun(j)
+		if err != nil {
+			logger.WithError(err).Warn("failed to find the previous run of the job to email its state change")
+			return
+		}
+		send = failed(state) != (previous != nil && failed(previous.Status.State))
+	}
+	if !send {
+		return
+	}
+	cfg := r.jobConfig.Config()
+	if cfg == nil || cfg.SMTP == nil {
+		logger.Warn("cannot email the job state as there is no smtp configuration")
+		return
+	}
+	smtp := *cfg.SMTP
+	lhjob := j.DeepCopy()
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if err := r.emailReporter.Report(&smtp, lhjob); err != nil {
+			logger.WithError(err).Warn("failed to email the job state")
+		}
+	}()
+}
+
+// previousRun returns the latest completed run of the same job which started before the given one, if any.
+// The runs of a postsubmit must be on the same branch.
+func (r *LighthouseJobReconciler) previousRun(j *lighthousev1alpha1.LighthouseJob) (*lighthousev1alpha1.LighthouseJob, error) {
+	var list lighthousev1alpha1.LighthouseJobList
+	err := r.client.List(context.TODO(), &list, client.InNamespace(j.Namespace), client.MatchingLabels{
+		util.LighthouseJobAnnotation: j.Labels[util.LighthouseJobAnnotation],
+		job.LighthouseJobTypeLabel:   string(j.Spec.Type),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var previous *lighthousev1alpha1.LighthouseJob
+	for i := range list.Items {
+		item := &list.Items[i]
+		if item.Name == j.Name || item.Spec.Job != j.Spec.Job || !item.Status.StartTime.Before(&j.Status.StartTime) {
+			continue
+		}
+		if item.Status.State != lighthousev1alpha1.SuccessState && !failed(item.Status.State) {
+			continue
+		}
+		if j.Spec.Refs != nil && (item.Spec.Refs == nil || item.Spec.Refs.Org != j.Spec.Refs.Org ||
+			item.Spec.Refs.Repo != j.Spec.Refs.Repo || item.Spec.Refs.BaseRef != j.Spec.Refs.BaseRef) {
+			continue
+		}
+		if previous == nil || previous.Status.StartTime.Before(&item.Status.StartTime) {
+			previous = item
+		}
+	}
+	return previous, nil
+}
+
+func failed(state lighthousev1alpha1.PipelineState) bool {
+	return state == lighthousev1alpha1.FailureState || state == lighthousev1alpha1.ErrorState
+}
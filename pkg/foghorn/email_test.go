@@ -0,0 +1,148 @@
+package foghorn
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeEmailer struct {
+	lock sync.Mutex
+	sent []string
+}
+
+func (f *fakeEmailer) Report(cfg *lighthouse.SMTP, lhjob *lighthousev1alpha1.LighthouseJob) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.sent = append(f.sent, lhjob.Name)
+	return nil
+}
+
+func periodicRun(name string, state lighthousev1alpha1.PipelineState, started int, when string) *lighthousev1alpha1.LighthouseJob {
+	return &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "jx",
+			Labels: map[string]string{
+				util.LighthouseJobAnnotation: "nightly",
+				job.LighthouseJobTypeLabel:   string(job.PeriodicJob),
+			},
+		},
+		Spec: lighthousev1alpha1.LighthouseJobSpec{
+			Type:        job.PeriodicJob,
+			Job:         "nightly",
+			EmailReport: &lighthousev1alpha1.EmailReportSpec{To: []string{"team@example.com"}, When: when},
+		},
+		Status: lighthousev1alpha1.LighthouseJobStatus{
+			State:     state,
+			StartTime: metav1.NewTime(time.Date(2020, 7, 20, started, 0, 0, 0, time.UTC)),
+		},
+	}
+}
+
+func TestReportEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		when     string
+		previous []*lighthousev1alpha1.LighthouseJob
+		state    lighthousev1alpha1.PipelineState
+		wantSent bool
+	}{
+		{
+			name:     "failure",
+			state:    lighthousev1alpha1.FailureState,
+			wantSent: true,
+		},
+		{
+			name:  "success",
+			state: lighthousev1alpha1.SuccessState,
+		},
+		{
+			name:  "aborted",
+			state: lighthousev1alpha1.AbortedState,
+		},
+		{
+			name:     "failure after failure",
+			when:     job.EmailOnFailure,
+			previous: []*lighthousev1alpha1.LighthouseJob{periodicRun("previous", lighthousev1alpha1.FailureState, 1, "")},
+			state:    lighthousev1alpha1.ErrorState,
+			wantSent: true,
+		},
+		{
+			name:     "first failure on state change",
+			when:     job.EmailOnStateChange,
+			state:    lighthousev1alpha1.FailureState,
+			wantSent: true,
+		},
+		{
+			name: "still failing on state change",
+			when: job.EmailOnStateChange,
+			previous: []*lighthousev1alpha1.LighthouseJob{
+				periodicRun("older", lighthousev1alpha1.SuccessState, 1, ""),
+				periodicRun("previous", lighthousev1alpha1.FailureState, 2, ""),
+				periodicRun("aborted", lighthousev1alpha1.AbortedState, 3, ""),
+			},
+			state: lighthousev1alpha1.FailureState,
+		},
+		{
+			name: "fixed on state change",
+			when: job.EmailOnStateChange,
+			previous: []*lighthousev1alpha1.LighthouseJob{
+				periodicRun("previous", lighthousev1alpha1.FailureState, 2, ""),
+				periodicRun("later", lighthousev1alpha1.SuccessState, 5, ""),
+			},
+			state:    lighthousev1alpha1.SuccessState,
+			wantSent: true,
+		},
+		{
+			name:     "still passing on state change",
+			when:     job.EmailOnStateChange,
+			previous: []*lighthousev1alpha1.LighthouseJob{periodicRun("previous", lighthousev1alpha1.SuccessState, 2, "")},
+			state:    lighthousev1alpha1.SuccessState,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+			var objects []runtime.Object
+			for _, p := range tc.previous {
+				objects = append(objects, p)
+			}
+			configAgent := &config.Agent{}
+			configAgent.Set(&config.Config{ProwConfig: config.ProwConfig{SMTP: &lighthouse.SMTP{Host: "smtp.example.com", From: "lighthouse@example.com"}}})
+			emailer := &fakeEmailer{}
+			r := &LighthouseJobReconciler{
+				client:        fake.NewFakeClientWithScheme(scheme, objects...),
+				logger:        logrus.NewEntry(logrus.StandardLogger()),
+				jobConfig:     configAgent,
+				emailReporter: emailer,
+				wg:            &sync.WaitGroup{},
+			}
+
+			j := periodicRun("current", tc.state, 4, tc.when)
+			r.reportEmail(j)
+			// reconciling again must not email the same state twice
+			r.reportEmail(j)
+			r.wg.Wait()
+
+			if tc.wantSent {
+				assert.Equal(t, []string{"current"}, emailer.sent)
+			} else {
+				assert.Empty(t, emailer.sent)
+			}
+		})
+	}
+}
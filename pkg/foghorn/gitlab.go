@@ -0,0 +1,51 @@
+package foghorn
+
+import (
+	"github.com/jenkins-x/go-scm/scm"
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/pkg/errors"
+)
+
+// gitlabStatusClient is the part of the SCM client used to report the GitLab statuses
+type gitlabStatusClient interface {
+	CreateGitLabStatus(string, string, string, *scmprovider.GitLabStatusInput) (*scm.Status, error)
+	FindGitLabPipeline(string, string, string, string) (int, error)
+}
+
+// reportGitLabStatus reports the job as a commit status of the pipeline of its merge request or branch, or of the
+// configured named pipeline, with a target URL pointing to the logs of the job
+func reportGitLabStatus(scmClient gitlabStatusClient, opts lighthouse.GitLabOptions, activity *lighthousev1alpha1.ActivityRecord, j *lighthousev1alpha1.LighthouseJob, name string, info reportStatusInfo) error {
+	input := &scmprovider.GitLabStatusInput{
+		State:       info.scmStatus,
+		Name:        name,
+		TargetURL:   activity.LogURL,
+		Description: info.description,
+		Ref:         gitlabRef(j),
+	}
+	if input.TargetURL == "" {
+		input.TargetURL = j.Status.ReportURL
+	}
+	if opts.PipelineName != "" {
+		id, err := scmClient.FindGitLabPipeline(activity.Owner, activity.Repo, activity.LastCommitSHA, opts.PipelineName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to find pipeline %s", opts.PipelineName)
+		}
+		input.PipelineID = id
+	}
+	_, err := scmClient.CreateGitLabStatus(activity.Owner, activity.Repo, activity.LastCommitSHA, input)
+	return err
+}
+
+// gitlabRef returns the ref of the merge request the job was triggered for, or the branch of the job otherwise
+func gitlabRef(j *lighthousev1alpha1.LighthouseJob) string {
+	refs := j.Spec.Refs
+	if refs == nil {
+		return ""
+	}
+	if len(refs.Pulls) > 0 && refs.Pulls[0].Ref != "" {
+		return refs.Pulls[0].Ref
+	}
+	return refs.BaseRef
+}
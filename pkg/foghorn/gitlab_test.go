@@ -0,0 +1,63 @@
+package foghorn
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportGitLabStatus(t *testing.T) {
+	activity := &v1alpha1.ActivityRecord{
+		Owner:         "myorg",
+		Repo:          "myrepo",
+		LastCommitSHA: "abcdef",
+		LogURL:        "https://logs.example.com/unit",
+		Status:        v1alpha1.SuccessState,
+	}
+	j := &v1alpha1.LighthouseJob{
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type: job.PresubmitJob,
+			Refs: &v1alpha1.Refs{
+				Org:     "myorg",
+				Repo:    "myrepo",
+				BaseRef: "master",
+				Pulls:   []v1alpha1.Pull{{Number: 1, SHA: "abcdef", Ref: "refs/merge-requests/1/head"}},
+			},
+		},
+		Status: v1alpha1.LighthouseJobStatus{ReportURL: "https://dashboard.example.com/unit"},
+	}
+	scmClient := &fake.SCMClient{
+		GitLabPipelines: map[string]int{"abcdef:lighthouse": 42},
+	}
+	info := toScmStatusDescriptionRunningStages(activity, "gitlab")
+
+	err := reportGitLabStatus(scmClient, lighthouse.GitLabOptions{}, activity, j, "unit", info)
+	require.NoError(t, err)
+	err = reportGitLabStatus(scmClient, lighthouse.GitLabOptions{PipelineName: "lighthouse"}, activity, j, "unit", info)
+	require.NoError(t, err)
+
+	statuses := scmClient.GitLabStatuses["abcdef"]
+	require.Len(t, statuses, 2)
+	assert.Equal(t, scm.StateSuccess, statuses[0].State)
+	assert.Equal(t, "unit", statuses[0].Name)
+	assert.Equal(t, "https://logs.example.com/unit", statuses[0].TargetURL)
+	assert.Equal(t, "refs/merge-requests/1/head", statuses[0].Ref)
+	assert.Equal(t, 0, statuses[0].PipelineID)
+	assert.Equal(t, 42, statuses[1].PipelineID, "the status should be added to the named pipeline")
+
+	activity.LogURL = ""
+	j.Spec.Type = job.PostsubmitJob
+	j.Spec.Refs.Pulls = nil
+	err = reportGitLabStatus(scmClient, lighthouse.GitLabOptions{}, activity, j, "release", info)
+	require.NoError(t, err)
+	statuses = scmClient.GitLabStatuses["abcdef"]
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "https://dashboard.example.com/unit", statuses[2].TargetURL, "the report URL should be used without logs")
+	assert.Equal(t, "master", statuses[2].Ref)
+}
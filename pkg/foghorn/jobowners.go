@@ -0,0 +1,107 @@
+package foghorn
+
+import (
+	"context"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/plugins/jobowners"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// jobOwnersReporter reports the repeated failures of a presubmit on a pull request
+type jobOwnersReporter interface {
+	Report(*plugins.JobOwners, *lighthousev1alpha1.LighthouseJob, int) error
+}
+
+// scmJobOwnersReporter reports the failures with the job-owners plugin
+type scmJobOwnersReporter struct {
+	logger    *logrus.Entry
+	jobConfig *config.Agent
+}
+
+// Report cc's the owners of the job and records the failure in its flake-tracking issue
+func (s *scmJobOwnersReporter) Report(jo *plugins.JobOwners, j *lighthousev1alpha1.LighthouseJob, failures int) error {
+	scmClient, _, _, _, err := util.GetSCMClient(j.Spec.Refs.Org, s.jobConfig.Config)
+	if err != nil {
+		return errors.Wrap(err, "failed to create SCM client")
+	}
+	return jobowners.ReportFailure(scmClient, s.logger.WithField("job", j.Name), jo, j, failures)
+}
+
+// reportJobOwners reports the failed presubmits of the repositories the job-owners plugin is enabled for, once their
+// failures on the pull request reach the flake threshold. Each failure is only reported once.
+func (r *LighthouseJobReconciler) reportJobOwners(j *lighthousev1alpha1.LighthouseJob) {
+	refs := j.Spec.Refs
+	state := j.Status.State
+	if j.Spec.Type != job.PresubmitJob || refs == nil || len(refs.Pulls) == 0 || !failed(state) {
+		return
+	}
+	if j.Status.LastJobOwnersState == string(state) {
+		return
+	}
+	cfg := r.pluginConfig.Config()
+	if cfg == nil || !jobOwnersEnabled(cfg, refs.Org, refs.Repo) {
+		return
+	}
+	j.Status.LastJobOwnersState = string(state)
+
+	logger := r.logger.WithField("job", j.Name)
+	failures, err := r.failedRuns(j)
+	if err != nil {
+		logger.WithError(err).Warn("failed to count the failed runs of the job on the pull request")
+		return
+	}
+	jo := cfg.JobOwnersFor(refs.Org, refs.Repo)
+	if failures < jo.FlakeThreshold {
+		return
+	}
+	lhjob := j.DeepCopy()
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if err := r.jobOwnersReporter.Report(jo, lhjob, failures); err != nil {
+			logger.WithError(err).Warn("failed to report the failures of the job to its owners")
+		}
+	}()
+}
+
+// failedRuns returns the number of failed runs of a presubmit on its pull request, including the given one
+func (r *LighthouseJobReconciler) failedRuns(j *lighthousev1alpha1.LighthouseJob) (int, error) {
+	var list lighthousev1alpha1.LighthouseJobList
+	err := r.client.List(context.TODO(), &list, client.InNamespace(j.Namespace), client.MatchingLabels{
+		util.LighthouseJobAnnotation: j.Labels[util.LighthouseJobAnnotation],
+		job.LighthouseJobTypeLabel:   string(j.Spec.Type),
+	})
+	if err != nil {
+		return 0, err
+	}
+	refs := j.Spec.Refs
+	failures := 1
+	for i := range list.Items {
+		item := &list.Items[i]
+		if item.Name == j.Name || item.Spec.Job != j.Spec.Job || !failed(item.Status.State) {
+			continue
+		}
+		if item.Spec.Refs == nil || len(item.Spec.Refs.Pulls) == 0 || item.Spec.Refs.Org != refs.Org ||
+			item.Spec.Refs.Repo != refs.Repo || item.Spec.Refs.Pulls[0].Number != refs.Pulls[0].Number {
+			continue
+		}
+		failures++
+	}
+	return failures, nil
+}
+
+func jobOwnersEnabled(cfg *plugins.Configuration, org, repo string) bool {
+	for _, p := range cfg.PluginsFor(org, repo) {
+		if p == jobowners.PluginName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package foghorn
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeJobOwnersReporter struct {
+	lock     sync.Mutex
+	failures []int
+}
+
+func (f *fakeJobOwnersReporter) Report(_ *plugins.JobOwners, _ *lighthousev1alpha1.LighthouseJob, failures int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.failures = append(f.failures, failures)
+	return nil
+}
+
+func presubmitRun(name string, state lighthousev1alpha1.PipelineState, pull int) *lighthousev1alpha1.LighthouseJob {
+	return &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "jx",
+			Labels: map[string]string{
+				util.LighthouseJobAnnotation: "integration",
+				job.LighthouseJobTypeLabel:   string(job.PresubmitJob),
+			},
+		},
+		Spec: lighthousev1alpha1.LighthouseJobSpec{
+			Type: job.PresubmitJob,
+			Job:  "integration",
+			Refs: &lighthousev1alpha1.Refs{
+				Org:   "org",
+				Repo:  "repo",
+				Pulls: []lighthousev1alpha1.Pull{{Number: pull}},
+			},
+		},
+		Status: lighthousev1alpha1.LighthouseJobStatus{
+			State:     state,
+			StartTime: metav1.NewTime(time.Date(2020, 7, 20, 1, 0, 0, 0, time.UTC)),
+		},
+	}
+}
+
+func TestReportJobOwners(t *testing.T) {
+	tests := []struct {
+		name         string
+		plugins      []string
+		previous     []*lighthousev1alpha1.LighthouseJob
+		state        lighthousev1alpha1.PipelineState
+		wantFailures []int
+	}{
+		{
+			name:    "threshold reached",
+			plugins: []string{"job-owners"},
+			previous: []*lighthousev1alpha1.LighthouseJob{
+				presubmitRun("first", lighthousev1alpha1.FailureState, 5),
+				presubmitRun("second", lighthousev1alpha1.ErrorState, 5),
+				presubmitRun("passed", lighthousev1alpha1.SuccessState, 5),
+				presubmitRun("other-pr", lighthousev1alpha1.FailureState, 6),
+			},
+			state:        lighthousev1alpha1.FailureState,
+			wantFailures: []int{3},
+		},
+		{
+			name:    "below threshold",
+			plugins: []string{"job-owners"},
+			previous: []*lighthousev1alpha1.LighthouseJob{
+				presubmitRun("first", lighthousev1alpha1.FailureState, 5),
+				presubmitRun("other-pr", lighthousev1alpha1.FailureState, 6),
+			},
+			state: lighthousev1alpha1.FailureState,
+		},
+		{
+			name: "plugin disabled",
+			previous: []*lighthousev1alpha1.LighthouseJob{
+				presubmitRun("first", lighthousev1alpha1.FailureState, 5),
+				presubmitRun("second", lighthousev1alpha1.FailureState, 5),
+			},
+			state: lighthousev1alpha1.FailureState,
+		},
+		{
+			name:    "success",
+			plugins: []string{"job-owners"},
+			previous: []*lighthousev1alpha1.LighthouseJob{
+				presubmitRun("first", lighthousev1alpha1.FailureState, 5),
+				presubmitRun("second", lighthousev1alpha1.FailureState, 5),
+			},
+			state: lighthousev1alpha1.SuccessState,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+			var objects []runtime.Object
+			for _, p := range tc.previous {
+				objects = append(objects, p)
+			}
+			pluginConfig := &plugins.ConfigAgent{}
+			pluginConfig.Set(&plugins.Configuration{
+				Plugins:   map[string][]string{"org/repo": tc.plugins},
+				JobOwners: []plugins.JobOwners{{Repos: []string{"org"}, FlakeThreshold: 3}},
+			})
+			reporter := &fakeJobOwnersReporter{}
+			r := &LighthouseJobReconciler{
+				client:            fake.NewFakeClientWithScheme(scheme, objects...),
+				logger:            logrus.NewEntry(logrus.StandardLogger()),
+				pluginConfig:      pluginConfig,
+				jobOwnersReporter: reporter,
+				wg:                &sync.WaitGroup{},
+			}
+
+			j := presubmitRun("current", tc.state, 5)
+			r.reportJobOwners(j)
+			// reconciling again must not report the same failure twice
+			r.reportJobOwners(j)
+			r.wg.Wait()
+
+			assert.Equal(t, tc.wantFailures, reporter.failures)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package foghorn
+
+import (
+	"sync"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newJobState is the previous state of the transitions of the jobs which were just created
+const newJobState = "new"
+
+var (
+	jobTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_job_state_transitions_total",
+		Help: "A counter of the transitions of the state of the LighthouseJobs, by job type, agent and states.",
+	}, []string{"type", "agent", "from", "to"})
+
+	// sloBuckets range from a second to about 9 hours
+	sloBuckets = prometheus.ExponentialBuckets(1, 2, 16)
+
+	timeToFirstStatus = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "lighthouse_slo_time_to_first_status_seconds",
+		Help:    "Histogram of the durations between the receipt of the webhooks triggering the jobs and the first status reported for them, by org and repo.",
+		Buckets: sloBuckets,
+	}, []string{"org", "repo"})
+	timeToJobCompletion = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "lighthouse_slo_time_to_job_completion_seconds",
+		Help:    "Histogram of the durations between the trigger of the jobs and their completion, by org, repo and job type.",
+		Buckets: sloBuckets,
+	}, []string{"org", "repo", "type"})
+
+	jobCPUSeconds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_job_cpu_seconds_total",
+		Help: "A counter of the CPU core-seconds consumed by the completed LighthouseJobs, i.e. their CPU requests times how long they ran, by org, repo and job.",
+	}, []string{"org", "repo", "job"})
+	jobMemoryByteSeconds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_job_memory_byte_seconds_total",
+		Help: "A counter of the memory byte-seconds consumed by the completed LighthouseJobs, i.e. their memory requests times how long they ran, by org, repo and job.",
+	}, []string{"org", "repo", "job"})
+
+	testFailureRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lighthouse_test_failure_rate",
+		Help: "The failure rate of the failed tests over the latest runs of their jobs, by org, repo, job and test.",
+	}, []string{"org", "repo", "job", "test"})
+	quarantinedTests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_quarantined_tests_total",
+		Help: "A counter of the tests quarantined as flaky, by org and repo.",
+	}, []string{"org", "repo"})
+)
+
+func init() {
+	prometheus.MustRegister(jobTransitions)
+	prometheus.MustRegister(timeToFirstStatus)
+	prometheus.MustRegister(timeToJobCompletion)
+	prometheus.MustRegister(jobCPUSeconds)
+	prometheus.MustRegister(jobMemoryByteSeconds)
+	prometheus.MustRegister(testFailureRate)
+	prometheus.MustRegister(quarantinedTests)
+}
+
+// observeFirstStatus records the time between the receipt of the webhook which triggered the job, if any, and the
+// first status reported for it
+func observeFirstStatus(j *lighthousev1alpha1.LighthouseJob, reported time.Time) {
+	if j.Spec.Refs == nil {
+		return
+	}
+	received, err := time.Parse(time.RFC3339Nano, j.Annotations[util.WebhookReceivedAnnotation])
+	if err != nil {
+		return
+	}
+	timeToFirstStatus.WithLabelValues(j.Spec.Refs.Org, j.Spec.Refs.Repo).Observe(reported.Sub(received).Seconds())
+}
+
+// observeCompletedJob records the time between the trigger of a job which just completed and its completion, and the
+// resources it consumed
+func observeCompletedJob(j *lighthousev1alpha1.LighthouseJob) {
+	if !j.Complete() {
+		return
+	}
+	observeJobResources(j)
+	if j.Spec.Refs == nil || j.Status.CompletionTime == nil {
+		return
+	}
+	duration := j.Status.CompletionTime.Sub(j.CreationTimestamp.Time)
+	timeToJobCompletion.WithLabelValues(j.Spec.Refs.Org, j.Spec.Refs.Repo, string(j.Spec.Type)).Observe(duration.Seconds())
+}
+
+// observeJobResources records the resources consumed by a job which just completed, if known. The jobs without refs,
+// e.g. the periodic jobs, are recorded with an empty org and repo.
+func observeJobResources(j *lighthousev1alpha1.LighthouseJob) {
+	if j.Status.Resources == nil {
+		return
+	}
+	var org, repo string
+	if j.Spec.Refs != nil {
+		org, repo = j.Spec.Refs.Org, j.Spec.Refs.Repo
+	}
+	jobCPUSeconds.WithLabelValues(org, repo, j.Spec.Job).Add(float64(j.Status.Resources.CPUMilliSeconds) / 1000)
+	jobMemoryByteSeconds.WithLabelValues(org, repo, j.Spec.Job).Add(float64(j.Status.Resources.MemoryByteSeconds))
+}
+
+// stateTracker remembers the last state seen of each job to count the transitions of their states, whichever
+// controller made them
+type stateTracker struct {
+	lock   sync.Mutex
+	states map[string]lighthousev1alpha1.PipelineState
+}
+
+func newStateTracker() *stateTracker {
+	return &stateTracker{states: map[string]lighthousev1alpha1.PipelineState{}}
+}
+
+// observe counts the transition of the job to its current state, if any, and returns true if it was counted. The
+// previous state of the jobs seen for the first time is unknown, e.g. after a restart, unless they are in their
+// initial state.
+func (t *stateTracker) observe(j *lighthousev1alpha1.LighthouseJob) bool {
+	state := j.Status.State
+	if state == "" {
+		return false
+	}
+	t.lock.Lock()
+	previous, seen := t.states[j.Name]
+	t.states[j.Name] = state
+	t.lock.Unlock()
+
+	from := string(previous)
+	switch {
+	case seen && previous == state:
+		return false
+	case !seen && (state == lighthousev1alpha1.TriggeredState || state == lighthousev1alpha1.QueuedState):
+		from = newJobState
+	case !seen:
+		return false
+	}
+	jobTransitions.WithLabelValues(string(j.Spec.Type), j.Spec.Agent, from, string(state)).Inc()
+	return true
+}
+
+// forget removes a deleted job
+func (t *stateTracker) forget(name string) {
+	t.lock.Lock()
+	delete(t.states, name)
+	t.lock.Unlock()
+}
@@ -0,0 +1,116 @@
+package foghorn
+
+import (
+	"testing"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStateTracker(t *testing.T) {
+	jobTransitions.Reset()
+	tracker := newStateTracker()
+	j := &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-job"},
+		Spec:       lighthousev1alpha1.LighthouseJobSpec{Type: job.PresubmitJob, Agent: job.TektonPipelineAgent},
+	}
+	transitions := func(from, to lighthousev1alpha1.PipelineState) float64 {
+		return testutil.ToFloat64(jobTransitions.WithLabelValues(string(job.PresubmitJob), job.TektonPipelineAgent, string(from), string(to)))
+	}
+
+	j.Status.State = lighthousev1alpha1.TriggeredState
+	tracker.observe(j)
+	assert.Equal(t, 1.0, transitions(newJobState, lighthousev1alpha1.TriggeredState))
+
+	tracker.observe(j)
+	j.Status.State = lighthousev1alpha1.PendingState
+	tracker.observe(j)
+	j.Status.State = lighthousev1alpha1.SuccessState
+	tracker.observe(j)
+	assert.Equal(t, 1.0, transitions(newJobState, lighthousev1alpha1.TriggeredState))
+	assert.Equal(t, 1.0, transitions(lighthousev1alpha1.TriggeredState, lighthousev1alpha1.PendingState))
+	assert.Equal(t, 1.0, transitions(lighthousev1alpha1.PendingState, lighthousev1alpha1.SuccessState))
+
+	// the previous state of a running job seen for the first time is unknown
+	tracker.forget("my-job")
+	j.Status.State = lighthousev1alpha1.PendingState
+	tracker.observe(j)
+	assert.Equal(t, 0.0, transitions(newJobState, lighthousev1alpha1.PendingState))
+	j.Status.State = lighthousev1alpha1.FailureState
+	tracker.observe(j)
+	assert.Equal(t, 1.0, transitions(lighthousev1alpha1.PendingState, lighthousev1alpha1.FailureState))
+}
+
+func TestSLOMetrics(t *testing.T) {
+	timeToFirstStatus.Reset()
+	timeToJobCompletion.Reset()
+	created := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	j := &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "my-job",
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations: map[string]string{
+				util.WebhookReceivedAnnotation: created.Add(-2 * time.Second).Format(time.RFC3339Nano),
+			},
+		},
+		Spec: lighthousev1alpha1.LighthouseJobSpec{
+			Type: job.PresubmitJob,
+			Refs: &lighthousev1alpha1.Refs{Org: "myorg", Repo: "myrepo"},
+		},
+	}
+	histogram := func(o prometheus.Observer) *dto.Histogram {
+		m := &dto.Metric{}
+		require.NoError(t, o.(prometheus.Metric).Write(m))
+		return m.GetHistogram()
+	}
+
+	observeFirstStatus(j, created.Add(3*time.Second))
+	h := histogram(timeToFirstStatus.WithLabelValues("myorg", "myrepo"))
+	assert.Equal(t, uint64(1), h.GetSampleCount())
+	assert.Equal(t, 5.0, h.GetSampleSum())
+
+	// the job is not complete yet
+	observeCompletedJob(j)
+	assert.Equal(t, uint64(0), histogram(timeToJobCompletion.WithLabelValues("myorg", "myrepo", string(job.PresubmitJob))).GetSampleCount())
+
+	j.Status.State = lighthousev1alpha1.SuccessState
+	completed := metav1.NewTime(created.Add(10 * time.Minute))
+	j.Status.CompletionTime = &completed
+	observeCompletedJob(j)
+	h = histogram(timeToJobCompletion.WithLabelValues("myorg", "myrepo", string(job.PresubmitJob)))
+	assert.Equal(t, uint64(1), h.GetSampleCount())
+	assert.Equal(t, 600.0, h.GetSampleSum())
+}
+
+func TestJobResourceMetrics(t *testing.T) {
+	jobCPUSeconds.Reset()
+	jobMemoryByteSeconds.Reset()
+	completed := metav1.NewTime(time.Date(2020, 10, 1, 12, 5, 0, 0, time.UTC))
+	j := &lighthousev1alpha1.LighthouseJob{
+		Spec: lighthousev1alpha1.LighthouseJobSpec{
+			Job:  "unit",
+			Refs: &lighthousev1alpha1.Refs{Org: "org", Repo: "repo"},
+		},
+		Status: lighthousev1alpha1.LighthouseJobStatus{
+			CompletionTime: &completed,
+			Resources:      &lighthousev1alpha1.ResourceUsage{CPUMilliSeconds: 90000, MemoryByteSeconds: 1 << 30},
+		},
+	}
+	observeCompletedJob(j)
+	assert.Equal(t, 90.0, testutil.ToFloat64(jobCPUSeconds.WithLabelValues("org", "repo", "unit")))
+	assert.Equal(t, float64(1<<30), testutil.ToFloat64(jobMemoryByteSeconds.WithLabelValues("org", "repo", "unit")))
+
+	// periodic jobs have no refs
+	j.Spec.Job = "nightly"
+	j.Spec.Refs = nil
+	observeCompletedJob(j)
+	assert.Equal(t, 90.0, testutil.ToFloat64(jobCPUSeconds.WithLabelValues("", "", "nightly")))
+}
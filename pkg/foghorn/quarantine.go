@@ -0,0 +1,231 @@
+package foghorn
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/quarantine"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// testQuarantiner quarantines the flaky tests of a job
+type testQuarantiner interface {
+	Quarantine(*lighthouse.Quarantine, *lighthousev1alpha1.LighthouseJob, []quarantine.Rate) error
+}
+
+// scmQuarantiner opens an issue for each flaky test and adds it to the quarantine list of its repository
+type scmQuarantiner struct {
+	client    client.Client
+	ns        string
+	jobConfig *config.Agent
+	logger    *logrus.Entry
+	// lock serializes the updates of the quarantine ConfigMap
+	lock sync.Mutex
+}
+
+// Quarantine quarantines the flaky tests which are not quarantined yet
+func (q *scmQuarantiner) Quarantine(cfg *lighthouse.Quarantine, j *lighthousev1alpha1.LighthouseJob, flaky []quarantine.Rate) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	refs := j.Spec.Refs
+	cm := &corev1.ConfigMap{}
+	err := q.client.Get(context.TODO(), types.NamespacedName{Namespace: q.ns, Name: cfg.ConfigMap}, cm)
+	create := apierrors.IsNotFound(err)
+	if err != nil && !create {
+		return errors.Wrapf(err, "failed to get the quarantine ConfigMap %s", cfg.ConfigMap)
+	}
+	if create {
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: q.ns, Name: cfg.ConfigMap}}
+	}
+	tests, err := quarantine.Load(cm, refs.Org, refs.Repo)
+	if err != nil {
+		return err
+	}
+	scmClient, _, _, _, err := util.GetSCMClient(refs.Org, q.jobConfig.Config)
+	if err != nil {
+		return errors.Wrap(err, "failed to create SCM client")
+	}
+
+	// the tests quarantined before an error are still saved
+	var issueErr error
+	added := 0
+	for _, rate := range flaky {
+		if quarantine.Find(tests, rate.Suite, rate.Name) != nil {
+			continue
+		}
+		name := quarantine.FullName(rate.Suite, rate.Name)
+		issue, err := scmClient.CreateIssue(refs.Org, refs.Repo, fmt.Sprintf("Flaky test: %s", name), quarantineIssueBody(cfg, j, rate))
+		if err != nil {
+			issueErr = errors.Wrapf(err, "failed to create the quarantine issue of %s", name)
+			break
+		}
+		for _, label := range cfg.IssueLabels {
+			if err := scmClient.AddLabel(refs.Org, refs.Repo, issue.Number, label, false); err != nil {
+				q.logger.WithError(err).Warnf("failed to label the quarantine issue %s/%s#%d", refs.Org, refs.Repo, issue.Number)
+			}
+		}
+		tests = append(tests, quarantine.Test{
+			Suite:       rate.Suite,
+			Name:        rate.Name,
+			Job:         j.Spec.Job,
+			Issue:       issue.Number,
+			FailureRate: rate.Value(),
+			Since:       metav1.Now(),
+		})
+		quarantinedTests.WithLabelValues(refs.Org, refs.Repo).Inc()
+		q.logger.WithField("job", j.Name).Infof("quarantined the flaky test %s in %s/%s#%d", name, refs.Org, refs.Repo, issue.Number)
+		added++
+	}
+	if added == 0 {
+		return issueErr
+	}
+	if err := quarantine.Save(cm, refs.Org, refs.Repo, tests); err != nil {
+		return err
+	}
+	if create {
+		err = q.client.Create(context.TODO(), cm)
+	} else {
+		err = q.client.Update(context.TODO(), cm)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to save the quarantine ConfigMap %s", cfg.ConfigMap)
+	}
+	return issueErr
+}
+
+func quarantineIssueBody(cfg *lighthouse.Quarantine, j *lighthousev1alpha1.LighthouseJob, rate quarantine.Rate) string {
+	body := fmt.Sprintf("The `%s` test failed in %d of the latest %d runs of the `%s` job (%.0f%%), it is quarantined as flaky.",
+		quarantine.FullName(rate.Suite, rate.Name), rate.Failures, rate.Runs, j.Spec.Job, rate.Value()*100)
+	if j.Status.ReportURL != "" {
+		body += fmt.Sprintf(" See its [latest failure](%s).", j.Status.ReportURL)
+	}
+	body += fmt.Sprintf("\n\nIts failures are marked as flaky in the test failures comments of the pull requests. Once it is fixed, remove it from the `%s` key of the `%s` ConfigMap and close this issue.",
+		quarantine.Key(j.Spec.Refs.Org, j.Spec.Refs.Repo), cfg.ConfigMap)
+	return body
+}
+
+// reportFlakes computes the failure rates of the failed tests of a job over its latest runs, and quarantines the
+// tests which are flaky. The failures of each run are only tracked once.
+func (r *LighthouseJobReconciler) reportFlakes(j *lighthousev1alpha1.LighthouseJob) {
+	refs := j.Spec.Refs
+	if refs == nil || j.Status.State != lighthousev1alpha1.FailureState || len(j.Status.TestFailures) == 0 {
+		return
+	}
+	if j.Status.LastFlakeState == string(j.Status.State) {
+		return
+	}
+	cfg := r.jobConfig.Config()
+	if cfg == nil || !cfg.Quarantine.EnabledFor(refs.Org, refs.Repo) {
+		return
+	}
+	j.Status.LastFlakeState = string(j.Status.State)
+	q := *cfg.Quarantine
+
+	logger := r.logger.WithField("job", j.Name)
+	runs, err := r.latestRuns(j, q.Window)
+	if err != nil {
+		logger.WithError(err).Warn("failed to list the latest runs of the job to track its flaky tests")
+		return
+	}
+	var flaky []quarantine.Rate
+	for _, rate := range quarantine.FailureRates(runs) {
+		if !failedTest(j, rate.Suite, rate.Name) || rate.Runs < q.MinRuns {
+			continue
+		}
+		testFailureRate.WithLabelValues(refs.Org, refs.Repo, j.Spec.Job, quarantine.FullName(rate.Suite, rate.Name)).Set(rate.Value())
+		if rate.Flaky(q.FlakeThreshold, q.MinRuns) {
+			flaky = append(flaky, rate)
+		}
+	}
+	if len(flaky) == 0 {
+		return
+	}
+	lhjob := j.DeepCopy()
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if err := r.quarantiner.Quarantine(&q, lhjob, flaky); err != nil {
+			logger.WithError(err).Warn("failed to quarantine the flaky tests of the job")
+		}
+	}()
+}
+
+// latestRuns returns the given job and its latest previous runs in the same repository which succeeded or failed,
+// up to the given number of runs, the latest first. The errored runs are ignored as they usually did not run
+// the tests.
+func (r *LighthouseJobReconciler) latestRuns(j *lighthousev1alpha1.LighthouseJob, max int) ([]lighthousev1alpha1.LighthouseJob, error) {
+	var list lighthousev1alpha1.LighthouseJobList
+	err := r.client.List(context.TODO(), &list, client.InNamespace(j.Namespace), client.MatchingLabels{
+		util.LighthouseJobAnnotation: j.Labels[util.LighthouseJobAnnotation],
+	})
+	if err != nil {
+		return nil, err
+	}
+	runs := []lighthousev1alpha1.LighthouseJob{*j}
+	for _, item := range list.Items {
+		if item.Name == j.Name || item.Spec.Job != j.Spec.Job || item.Spec.Refs == nil ||
+			item.Spec.Refs.Org != j.Spec.Refs.Org || item.Spec.Refs.Repo != j.Spec.Refs.Repo {
+			continue
+		}
+		if item.Status.State != lighthousev1alpha1.SuccessState && item.Status.State != lighthousev1alpha1.FailureState {
+			continue
+		}
+		runs = append(runs, item)
+	}
+	sort.SliceStable(runs, func(a, b int) bool {
+		return runs[b].Status.StartTime.Before(&runs[a].Status.StartTime)
+	})
+	if len(runs) > max {
+		runs = runs[:max]
+	}
+	return runs, nil
+}
+
+// markQuarantined records the quarantine issues of the failed tests of the job which are quarantined, so that
+// they are shown as such in the test failures comment
+func (r *LighthouseJobReconciler) markQuarantined(cfg *config.Config, j *lighthousev1alpha1.LighthouseJob) {
+	refs := j.Spec.Refs
+	if refs == nil || len(j.Status.TestFailures) == 0 || !cfg.Quarantine.EnabledFor(refs.Org, refs.Repo) {
+		return
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: r.ns, Name: cfg.Quarantine.ConfigMap}, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			r.logger.WithError(err).Warnf("failed to get the quarantine ConfigMap %s", cfg.Quarantine.ConfigMap)
+		}
+		return
+	}
+	tests, err := quarantine.Load(cm, refs.Org, refs.Repo)
+	if err != nil {
+		r.logger.WithError(err).Warn("failed to load the quarantined tests")
+		return
+	}
+	for i := range j.Status.TestFailures {
+		f := &j.Status.TestFailures[i]
+		if t := quarantine.Find(tests, f.Suite, f.Name); t != nil {
+			f.QuarantineIssue = t.Issue
+		}
+	}
+}
+
+func failedTest(j *lighthousev1alpha1.LighthouseJob, suite, name string) bool {
+	for _, f := range j.Status.TestFailures {
+		if f.Suite == suite && f.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package foghorn
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/quarantine"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeQuarantiner struct {
+	lock  sync.Mutex
+	flaky []quarantine.Rate
+}
+
+func (f *fakeQuarantiner) Quarantine(_ *lighthouse.Quarantine, _ *lighthousev1alpha1.LighthouseJob, flaky []quarantine.Rate) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.flaky = append(f.flaky, flaky...)
+	return nil
+}
+
+func unitRun(name string, state lighthousev1alpha1.PipelineState, started int, failures ...string) *lighthousev1alpha1.LighthouseJob {
+	j := &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "jx",
+			Labels: map[string]string{
+				util.LighthouseJobAnnotation: "unit",
+				job.LighthouseJobTypeLabel:   string(job.PresubmitJob),
+			},
+		},
+		Spec: lighthousev1alpha1.LighthouseJobSpec{
+			Type: job.PresubmitJob,
+			Job:  "unit",
+			Refs: &lighthousev1alpha1.Refs{Org: "org", Repo: "repo"},
+		},
+		Status: lighthousev1alpha1.LighthouseJobStatus{
+			State:     state,
+			StartTime: metav1.NewTime(time.Date(2020, 7, 20, started, 0, 0, 0, time.UTC)),
+		},
+	}
+	for _, f := range failures {
+		j.Status.TestFailures = append(j.Status.TestFailures, lighthousev1alpha1.TestFailure{Name: f})
+	}
+	return j
+}
+
+func TestReportFlakes(t *testing.T) {
+	var history []*lighthousev1alpha1.LighthouseJob
+	for i := 0; i < 8; i++ {
+		history = append(history, unitRun(fmt.Sprintf("run-%d", i), lighthousev1alpha1.SuccessState, i))
+	}
+	history[1] = unitRun("run-1", lighthousev1alpha1.FailureState, 1, "TestFlaky")
+	history[5] = unitRun("run-5", lighthousev1alpha1.FailureState, 5, "TestFlaky", "TestBroken")
+	history[6] = unitRun("run-6", lighthousev1alpha1.ErrorState, 6)
+
+	tests := []struct {
+		name      string
+		repos     []string
+		previous  []*lighthousev1alpha1.LighthouseJob
+		current   *lighthousev1alpha1.LighthouseJob
+		wantFlaky []quarantine.Rate
+	}{
+		{
+			name:      "flaky test",
+			previous:  history,
+			current:   unitRun("current", lighthousev1alpha1.FailureState, 9, "TestFlaky"),
+			wantFlaky: []quarantine.Rate{{Name: "TestFlaky", Failures: 2, Runs: 5}},
+		},
+		{
+			name:     "not enough runs",
+			previous: history[4:],
+			current:  unitRun("current", lighthousev1alpha1.FailureState, 9, "TestBroken"),
+		},
+		{
+			name:     "repository not tracked",
+			repos:    []string{"other-org"},
+			previous: history,
+			current:  unitRun("current", lighthousev1alpha1.FailureState, 9, "TestFlaky"),
+		},
+		{
+			name:     "success",
+			previous: history,
+			current:  unitRun("current", lighthousev1alpha1.SuccessState, 9),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+			var objects []runtime.Object
+			for _, p := range tc.previous {
+				objects = append(objects, p)
+			}
+			q := &lighthouse.Quarantine{Repos: tc.repos, Window: 5}
+			require.NoError(t, q.Parse())
+			configAgent := &config.Agent{}
+			configAgent.Set(&config.Config{ProwConfig: config.ProwConfig{Quarantine: q}})
+			quarantiner := &fakeQuarantiner{}
+			r := &LighthouseJobReconciler{
+				client:      fake.NewFakeClientWithScheme(scheme, objects...),
+				logger:      logrus.NewEntry(logrus.StandardLogger()),
+				jobConfig:   configAgent,
+				quarantiner: quarantiner,
+				wg:          &sync.WaitGroup{},
+			}
+
+			r.reportFlakes(tc.current)
+			// reconciling again must not track the same failures twice
+			r.reportFlakes(tc.current)
+			r.wg.Wait()
+
+			assert.Equal(t, tc.wantFlaky, quarantiner.flaky)
+		})
+	}
+}
+
+func TestMarkQuarantined(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "jx", Name: lighthouse.DefaultQuarantineConfigMap}}
+	require.NoError(t, quarantine.Save(cm, "org", "repo", []quarantine.Test{{Name: "TestFlaky", Job: "unit", Issue: 7}}))
+	q := &lighthouse.Quarantine{}
+	require.NoError(t, q.Parse())
+	r := &LighthouseJobReconciler{
+		client: fake.NewFakeClientWithScheme(scheme, cm),
+		logger: logrus.NewEntry(logrus.StandardLogger()),
+		ns:     "jx",
+	}
+
+	j := unitRun("current", lighthousev1alpha1.FailureState, 9, "TestFlaky", "TestBroken")
+	r.markQuarantined(&config.Config{ProwConfig: config.ProwConfig{Quarantine: q}}, j)
+	assert.Equal(t, []lighthousev1alpha1.TestFailure{{Name: "TestFlaky", QuarantineIssue: 7}, {Name: "TestBroken"}}, j.Status.TestFailures)
+}
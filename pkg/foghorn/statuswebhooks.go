@@ -0,0 +1,89 @@
+package foghorn
+
+import (
+	"sync"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+)
+
+// statusWebhookRetryPeriod is how long the jobs whose state remains to be delivered to some status webhooks are
+// reconciled again after, to record the webhooks it was delivered to and retry the failed ones
+const statusWebhookRetryPeriod = 10 * time.Second
+
+// webhookDeliveries tracks the job states posted to the status webhooks in the background, so that a single state of
+// a job is posted to a webhook at a time and the states delivered are recorded in the job status once it is
+// reconciled again
+type webhookDeliveries struct {
+	lock sync.Mutex
+	// inFlight are the URLs of the webhooks being posted to, by job
+	inFlight map[string]map[string]bool
+	// delivered are the events delivered to the webhooks not yet seen recorded in the job status, by job and URL
+	delivered map[string]map[string]string
+}
+
+func newWebhookDeliveries() *webhookDeliveries {
+	return &webhookDeliveries{
+		inFlight:  map[string]map[string]bool{},
+		delivered: map[string]map[string]string{},
+	}
+}
+
+// start marks the webhook as being posted to for the job, returning false if it already was
+func (d *webhookDeliveries) start(job, url string) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.inFlight[job][url] {
+		return false
+	}
+	if d.inFlight[job] == nil {
+		d.inFlight[job] = map[string]bool{}
+	}
+	d.inFlight[job][url] = true
+	return true
+}
+
+// finish marks the webhook as no longer being posted to for the job, remembering the event if it was delivered
+func (d *webhookDeliveries) finish(job, url, event string, delivered bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.inFlight[job], url)
+	if len(d.inFlight[job]) == 0 {
+		delete(d.inFlight, job)
+	}
+	if !delivered {
+		return
+	}
+	if d.delivered[job] == nil {
+		d.delivered[job] = map[string]string{}
+	}
+	d.delivered[job][url] = event
+}
+
+// record sets the events delivered to the webhooks in the job status. They are forgotten once seen recorded, so that
+// they are recorded again if the status failed to be updated.
+func (d *webhookDeliveries) record(j *lighthousev1alpha1.LighthouseJob) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for url, event := range d.delivered[j.Name] {
+		if j.Status.WebhookStates[url] == event {
+			delete(d.delivered[j.Name], url)
+			continue
+		}
+		if j.Status.WebhookStates == nil {
+			j.Status.WebhookStates = map[string]string{}
+		}
+		j.Status.WebhookStates[url] = event
+	}
+	if len(d.delivered[j.Name]) == 0 {
+		delete(d.delivered, j.Name)
+	}
+}
+
+// forget removes a deleted job
+func (d *webhookDeliveries) forget(job string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.inFlight, job)
+	delete(d.delivered, job)
+}
@@ -0,0 +1,147 @@
+package foghorn
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/junit"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/inrepo"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxStatusDescription is the maximum length of a commit status description accepted by GitHub
+const maxStatusDescription = 140
+
+// statusClient is the part of the SCM client used to report the summary status, loading the in-repo configuration
+// of the pull request to find its required contexts
+type statusClient interface {
+	CreateStatus(string, string, string, *scm.StatusInput) (*scm.Status, error)
+	GetPullRequestChanges(string, string, int) ([]*scm.Change, error)
+	GetRepositoryByFullName(string) (*scm.Repository, error)
+	GetRef(string, string, string) (string, error)
+	GetFile(string, string, string, string) ([]byte, error)
+	ListFiles(string, string, string, string) ([]*scm.FileEntry, error)
+}
+
+// reportSummary updates the summary status of the pull request of the presubmit job from the latest state of
+// the jobs of each required context on its head commit
+func (r *LighthouseJobReconciler) reportSummary(scmClient statusClient, cfg *config.Config, j *lighthousev1alpha1.LighthouseJob) error {
+	refs := j.Spec.Refs
+	if j.Spec.Type != job.PresubmitJob || refs == nil || len(refs.Pulls) == 0 || !cfg.SummaryStatus.EnabledFor(refs.Org, refs.Repo) {
+		return nil
+	}
+	pull := refs.Pulls[0]
+	var list lighthousev1alpha1.LighthouseJobList
+	err := r.client.List(context.TODO(), &list, client.InNamespace(r.ns), client.MatchingLabels{
+		util.OrgLabel:           strings.ToLower(refs.Org),
+		util.RepoLabel:          refs.Repo,
+		util.PullLabel:          strconv.Itoa(pull.Number),
+		util.LastCommitSHALabel: pull.SHA,
+	})
+	if err != nil {
+		return err
+	}
+	// the state of the reported job is not stored yet
+	jobs := []lighthousev1alpha1.LighthouseJob{*j}
+	for _, item := range list.Items {
+		if item.Name != j.Name {
+			jobs = append(jobs, item)
+		}
+	}
+
+	// the in-repo configuration is loaded at the base the presubmits were triggered for
+	repoCfg, _, err := inrepo.Generate(scmClient, r.inRepoCache, cfg, nil, refs.Org, refs.Repo, refs.BaseSHA)
+	if err != nil {
+		return errors.Wrap(err, "failed to calculate the in repo config")
+	}
+	optional := map[string]bool{}
+	var expected []string
+	changes := job.NewGitHubDeferredChangedFilesProvider(scmClient, refs.Org, refs.Repo, pull.Number)
+	for _, p := range repoCfg.AllPresubmits([]string{refs.Org + "/" + refs.Repo}) {
+		if !p.ContextRequired() {
+			optional[p.Context] = true
+			continue
+		}
+		if p.Context == cfg.SummaryStatus.Context {
+			continue
+		}
+		// as keeper, only the required presubmits which run on the pull request are expected
+		shouldRun, err := p.ShouldRun(refs.BaseRef, changes, false, false)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check whether %s runs on the pull request", p.Name)
+		}
+		if shouldRun {
+			expected = append(expected, p.Context)
+		}
+	}
+	state, description := summarize(jobs, expected, func(context string) bool {
+		return !optional[context] && context != cfg.SummaryStatus.Context
+	})
+	_, err = scmClient.CreateStatus(refs.Org, refs.Repo, pull.SHA, &scm.StatusInput{
+		State: state,
+		Label: cfg.SummaryStatus.Context,
+		Desc:  description,
+	})
+	return err
+}
+
+// summarize returns the state and description of the summary status of the jobs, only taking into account
+// the latest job of each required context. The expected contexts without any job are waited for.
+func summarize(jobs []lighthousev1alpha1.LighthouseJob, expected []string, required func(context string) bool) (scm.State, string) {
+	latest := map[string]*lighthousev1alpha1.LighthouseJob{}
+	for i := range jobs {
+		j := &jobs[i]
+		if j.Spec.Context == "" || !required(j.Spec.Context) {
+			continue
+		}
+		if previous, ok := latest[j.Spec.Context]; !ok || previous.Status.StartTime.Before(&j.Status.StartTime) {
+			latest[j.Spec.Context] = j
+		}
+	}
+
+	var passing int
+	var failing, waiting []string
+	contexts := sets.NewString(expected...)
+	for context := range contexts {
+		if _, ok := latest[context]; !ok {
+			waiting = append(waiting, context)
+		}
+	}
+	for context, j := range latest {
+		contexts.Insert(context)
+		switch j.Status.State {
+		case lighthousev1alpha1.SuccessState:
+			passing++
+		case lighthousev1alpha1.FailureState, lighthousev1alpha1.ErrorState, lighthousev1alpha1.AbortedState:
+			failing = append(failing, context)
+		default:
+			waiting = append(waiting, context)
+		}
+	}
+	sort.Strings(failing)
+	sort.Strings(waiting)
+
+	description := fmt.Sprintf("%d/%d required checks passing", passing, contexts.Len())
+	state := scm.StateSuccess
+	if len(failing) > 0 {
+		state = scm.StateFailure
+		description += "; blocked on " + strings.Join(failing, ", ")
+	}
+	if len(waiting) > 0 {
+		if state == scm.StateSuccess {
+			state = scm.StatePending
+		}
+		description += "; waiting for " + strings.Join(waiting, ", ")
+	}
+	return state, junit.Truncate(description, maxStatusDescription)
+}
@@ -0,0 +1,170 @@
+package foghorn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func summaryJob(name, context string, state lighthousev1alpha1.PipelineState, started int, sha string) *lighthousev1alpha1.LighthouseJob {
+	return &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "jx",
+			Labels: map[string]string{
+				util.OrgLabel:           "org",
+				util.RepoLabel:          "repo",
+				util.PullLabel:          "7",
+				util.LastCommitSHALabel: sha,
+			},
+		},
+		Spec: lighthousev1alpha1.LighthouseJobSpec{
+			Type:    job.PresubmitJob,
+			Context: context,
+			Refs: &lighthousev1alpha1.Refs{
+				Org:   "org",
+				Repo:  "repo",
+				Pulls: []lighthousev1alpha1.Pull{{Number: 7, SHA: sha}},
+			},
+		},
+		Status: lighthousev1alpha1.LighthouseJobStatus{
+			State:     state,
+			StartTime: metav1.NewTime(time.Date(2020, 7, 20, 21, started, 0, 0, time.UTC)),
+		},
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	all := func(string) bool { return true }
+	tests := []struct {
+		name      string
+		jobs      []*lighthousev1alpha1.LighthouseJob
+		expected  []string
+		required  func(string) bool
+		wantState scm.State
+		wantDesc  string
+	}{
+		{
+			name: "all passing",
+			jobs: []*lighthousev1alpha1.LighthouseJob{
+				summaryJob("a", "unit", lighthousev1alpha1.SuccessState, 0, "sha"),
+				summaryJob("b", "lint", lighthousev1alpha1.SuccessState, 0, "sha"),
+			},
+			required:  all,
+			wantState: scm.StateSuccess,
+			wantDesc:  "2/2 required checks passing",
+		},
+		{
+			name: "blocked and waiting",
+			jobs: []*lighthousev1alpha1.LighthouseJob{
+				summaryJob("a", "unit", lighthousev1alpha1.SuccessState, 0, "sha"),
+				summaryJob("b", "lint", lighthousev1alpha1.FailureState, 0, "sha"),
+				summaryJob("c", "e2e", lighthousev1alpha1.ErrorState, 0, "sha"),
+				summaryJob("d", "build", lighthousev1alpha1.RunningState, 0, "sha"),
+			},
+			required:  all,
+			wantState: scm.StateFailure,
+			wantDesc:  "1/4 required checks passing; blocked on e2e, lint; waiting for build",
+		},
+		{
+			name: "latest job of a context wins",
+			jobs: []*lighthousev1alpha1.LighthouseJob{
+				summaryJob("a", "unit", lighthousev1alpha1.FailureState, 0, "sha"),
+				summaryJob("b", "unit", lighthousev1alpha1.PendingState, 5, "sha"),
+			},
+			required:  all,
+			wantState: scm.StatePending,
+			wantDesc:  "0/1 required checks passing; waiting for unit",
+		},
+		{
+			name: "optional contexts are ignored",
+			jobs: []*lighthousev1alpha1.LighthouseJob{
+				summaryJob("a", "unit", lighthousev1alpha1.SuccessState, 0, "sha"),
+				summaryJob("b", "lint", lighthousev1alpha1.FailureState, 0, "sha"),
+			},
+			required:  func(context string) bool { return context != "lint" },
+			wantState: scm.StateSuccess,
+			wantDesc:  "1/1 required checks passing",
+		},
+		{
+			name: "expected context without job",
+			jobs: []*lighthousev1alpha1.LighthouseJob{
+				summaryJob("a", "unit", lighthousev1alpha1.SuccessState, 0, "sha"),
+			},
+			expected:  []string{"unit", "integration"},
+			required:  all,
+			wantState: scm.StatePending,
+			wantDesc:  "1/2 required checks passing; waiting for integration",
+		},
+		{
+			name:      "no job yet",
+			expected:  []string{"unit"},
+			required:  all,
+			wantState: scm.StatePending,
+			wantDesc:  "0/1 required checks passing; waiting for unit",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var jobs []lighthousev1alpha1.LighthouseJob
+			for _, j := range tc.jobs {
+				jobs = append(jobs, *j)
+			}
+			state, desc := summarize(jobs, tc.expected, tc.required)
+			assert.Equal(t, tc.wantState, state)
+			assert.Equal(t, tc.wantDesc, desc)
+		})
+	}
+}
+
+func TestReportSummary(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme,
+		summaryJob("unit-1", "unit", lighthousev1alpha1.RunningState, 0, "sha"),
+		summaryJob("lint-1", "lint", lighthousev1alpha1.FailureState, 0, "sha"),
+		summaryJob("docs-1", "docs", lighthousev1alpha1.FailureState, 0, "sha"),
+		summaryJob("e2e-1", "e2e", lighthousev1alpha1.FailureState, 0, "old-sha"),
+	)
+	r := &LighthouseJobReconciler{client: c, ns: "jx", logger: logrus.NewEntry(logrus.StandardLogger())}
+
+	// the in-repo configuration of test_data/org/repo requires an integration context
+	enabled := true
+	cfg := &config.Config{}
+	cfg.InRepoConfig = lighthouse.InRepoConfig{Enabled: map[string]*bool{"org/repo": &enabled}}
+	cfg.SummaryStatus = lighthouse.SummaryStatus{Enabled: true}
+	cfg.SummaryStatus.Parse()
+	require.NoError(t, cfg.SetPresubmits(map[string][]job.Presubmit{
+		"org/repo": {{Base: job.Base{Name: "docs", Agent: job.TektonPipelineAgent}, Reporter: job.Reporter{Context: "docs"}, Optional: true}},
+	}))
+	client, data := fakescm.NewDefault()
+	data.Repositories = []*scm.Repository{{Namespace: "org", Name: "repo", FullName: "org/repo", Branch: "master"}}
+	scmClient := scmprovider.ToClient(client, "bot")
+
+	// the reported job has succeeded but its new state is not stored yet
+	reported := summaryJob("unit-1", "unit", lighthousev1alpha1.SuccessState, 0, "sha")
+	require.NoError(t, r.reportSummary(scmClient, cfg, reported))
+	require.Len(t, data.Statuses["sha"], 1)
+	status := data.Statuses["sha"][0]
+	assert.Equal(t, lighthouse.DefaultSummaryContext, status.Label)
+	assert.Equal(t, scm.StateFailure, status.State)
+	assert.Equal(t, "1/3 required checks passing; blocked on lint; waiting for integration", status.Desc)
+
+	cfg.SummaryStatus.Repos = []string{"other"}
+	require.NoError(t, r.reportSummary(scmClient, cfg, reported))
+	assert.Len(t, data.Statuses["sha"], 1, "the summary should only be reported for the configured repositories")
+}
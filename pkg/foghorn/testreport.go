@@ -0,0 +1,70 @@
+package foghorn
+
+import (
+	"context"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/artifacts"
+	"github.com/pkg/errors"
+)
+
+// testReportTimeout is how long reading the junit report of a job may take
+const testReportTimeout = 30 * time.Second
+
+// testReportReader reads the failed tests of the junit report a job uploaded along with its artifacts
+type testReportReader interface {
+	ReadTestFailures(*lighthousev1alpha1.LighthouseJob) ([]lighthousev1alpha1.TestFailure, error)
+}
+
+// artifactsTestReports downloads the junit reports from the buckets the jobs upload their artifacts to
+type artifactsTestReports struct {
+	opts artifacts.Options
+}
+
+// ReadTestFailures downloads the junit report of the job and returns its failed tests
+func (a *artifactsTestReports) ReadTestFailures(j *lighthousev1alpha1.LighthouseJob) ([]lighthousev1alpha1.TestFailure, error) {
+	dest, err := artifacts.ParseDestination(j.Spec.Artifacts.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), testReportTimeout)
+	defer cancel()
+	downloader, err := artifacts.NewDownloader(ctx, dest, a.opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the downloader of %s", dest)
+	}
+	return artifacts.ReadTestFailures(ctx, downloader, dest, &j.Spec, j.Status.BuildID)
+}
+
+// SetArtifactsOptions configures how the junit reports the jobs upload along with their artifacts are downloaded
+func (r *LighthouseJobReconciler) SetArtifactsOptions(opts artifacts.Options) {
+	r.testReports = &artifactsTestReports{opts: opts}
+}
+
+// readTestFailures fills the failed tests of a failed job from the junit report uploaded along with its artifacts,
+// so that they are reported whatever the engine running the job, unless the engine already filled them
+func (r *LighthouseJobReconciler) readTestFailures(j *lighthousev1alpha1.LighthouseJob) {
+	if r.testReports == nil || j.Status.State != lighthousev1alpha1.FailureState || len(j.Status.TestFailures) > 0 {
+		return
+	}
+	if j.Spec.Artifacts == nil || j.Spec.Artifacts.Bucket == "" || j.Status.BuildID == "" {
+		return
+	}
+	if j.Status.LastTestReportState == string(j.Status.State) {
+		return
+	}
+	j.Status.LastTestReportState = string(j.Status.State)
+
+	failures, err := r.testReports.ReadTestFailures(j)
+	if err != nil {
+		logger := r.logger.WithField("job", j.Name).WithError(err)
+		if errors.Cause(err) == artifacts.ErrNotFound {
+			logger.Debug("the job uploaded no junit report")
+		} else {
+			logger.Warn("failed to read the junit report of the job")
+		}
+		return
+	}
+	j.Status.TestFailures = failures
+}
@@ -0,0 +1,100 @@
+package foghorn
+
+import (
+	"testing"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/artifacts"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTestReports struct {
+	failures []lighthousev1alpha1.TestFailure
+	err      error
+	reads    int
+}
+
+func (f *fakeTestReports) ReadTestFailures(*lighthousev1alpha1.LighthouseJob) ([]lighthousev1alpha1.TestFailure, error) {
+	f.reads++
+	return f.failures, f.err
+}
+
+func TestReadTestFailures(t *testing.T) {
+	reported := []lighthousev1alpha1.TestFailure{{Suite: "foo", Name: "TestFoo", Message: "boom"}}
+	uploaded := []lighthousev1alpha1.TestFailure{{Suite: "bar", Name: "TestBar"}}
+	tests := []struct {
+		name      string
+		state     lighthousev1alpha1.PipelineState
+		buildID   string
+		bucket    string
+		failures  []lighthousev1alpha1.TestFailure
+		err       error
+		want      []lighthousev1alpha1.TestFailure
+		wantReads int
+	}{
+		{
+			name:      "failed job",
+			state:     lighthousev1alpha1.FailureState,
+			buildID:   "42",
+			bucket:    "gs://bucket",
+			want:      uploaded,
+			wantReads: 1,
+		},
+		{
+			name:     "failures reported by the engine",
+			state:    lighthousev1alpha1.FailureState,
+			buildID:  "42",
+			bucket:   "gs://bucket",
+			failures: reported,
+			want:     reported,
+		},
+		{
+			name:    "successful job",
+			state:   lighthousev1alpha1.SuccessState,
+			buildID: "42",
+			bucket:  "gs://bucket",
+		},
+		{
+			name:    "no artifacts",
+			state:   lighthousev1alpha1.FailureState,
+			buildID: "42",
+		},
+		{
+			name:   "unknown build",
+			state:  lighthousev1alpha1.FailureState,
+			bucket: "gs://bucket",
+		},
+		{
+			name:      "no report uploaded",
+			state:     lighthousev1alpha1.FailureState,
+			buildID:   "42",
+			bucket:    "gs://bucket",
+			err:       artifacts.ErrNotFound,
+			wantReads: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reports := &fakeTestReports{failures: uploaded, err: tc.err}
+			r := &LighthouseJobReconciler{
+				logger:      logrus.NewEntry(logrus.StandardLogger()),
+				testReports: reports,
+			}
+			j := &lighthousev1alpha1.LighthouseJob{}
+			j.Status.State = tc.state
+			j.Status.BuildID = tc.buildID
+			j.Status.TestFailures = tc.failures
+			if tc.bucket != "" {
+				j.Spec.Artifacts = &lighthousev1alpha1.ArtifactsSpec{Bucket: tc.bucket}
+			}
+
+			r.readTestFailures(j)
+			// reconciling again must not download the report twice
+			r.readTestFailures(j)
+
+			assert.Equal(t, tc.want, j.Status.TestFailures)
+			assert.Equal(t, tc.wantReads, reports.reads)
+		})
+	}
+}
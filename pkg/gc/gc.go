@@ -0,0 +1,255 @@
+// Package gc garbage collects completed LighthouseJobs and the resources they created
+// according to a retention policy.
+package gc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/keeper"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Policy defines how long LighthouseJobs are retained.
+type Policy struct {
+	// SuccessTTL is how long successful jobs are kept after completion.
+	SuccessTTL time.Duration
+	// FailureTTL is how long failed, errored or aborted jobs are kept after completion.
+	FailureTTL time.Duration
+	// PendingTTL is how long jobs which never completed are kept after they started.
+	PendingTTL time.Duration
+	// KeepRecent is the number of most recent completed jobs kept per repository and job
+	// regardless of their TTL.
+	KeepRecent int
+}
+
+// TTL returns the retention of the given job and the time it is counted from.
+func (p *Policy) TTL(lhjob *v1alpha1.LighthouseJob) (time.Duration, time.Time) {
+	if lhjob.Status.CompletionTime == nil {
+		return p.PendingTTL, lhjob.Status.StartTime.Time
+	}
+	completed := lhjob.Status.CompletionTime.Time
+	if lhjob.Status.State == v1alpha1.SuccessState {
+		return p.SuccessTTL, completed
+	}
+	return p.FailureTTL, completed
+}
+
+// PullRequestKey identifies a pull request.
+type PullRequestKey struct {
+	Org    string
+	Repo   string
+	Number int
+}
+
+// Select returns the jobs which should be deleted at the given time. Jobs for which protected
+// returns true are never deleted.
+func (p *Policy) Select(jobs []v1alpha1.LighthouseJob, now time.Time, protected func(*v1alpha1.LighthouseJob) bool) []v1alpha1.LighthouseJob {
+	kept := map[string]int{}
+	sorted := make([]v1alpha1.LighthouseJob, len(jobs))
+	copy(sorted, jobs)
+	// most recent first so that the first KeepRecent completed jobs of each group are kept
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Status.StartTime.After(sorted[j].Status.StartTime.Time)
+	})
+
+	var answer []v1alpha1.LighthouseJob
+	for i := range sorted {
+		lhjob := &sorted[i]
+		ttl, since := p.TTL(lhjob)
+		if lhjob.Status.CompletionTime != nil {
+			key := groupKey(lhjob)
+			if kept[key] < p.KeepRecent {
+				kept[key]++
+				continue
+			}
+		}
+		if !since.Add(ttl).Before(now) {
+			continue
+		}
+		if protected != nil && protected(lhjob) {
+			continue
+		}
+		answer = append(answer, *lhjob)
+	}
+	return answer
+}
+
+// groupKey returns the repository and job name the retention count of a job applies to.
+func groupKey(lhjob *v1alpha1.LighthouseJob) string {
+	if lhjob.Spec.Refs == nil {
+		return lhjob.Spec.Job
+	}
+	return fmt.Sprintf("%s/%s/%s", strings.ToLower(lhjob.Spec.Refs.Org), strings.ToLower(lhjob.Spec.Refs.Repo), lhjob.Spec.Job)
+}
+
+// PoolPullRequests returns the pull requests currently in the given keeper pools.
+func PoolPullRequests(pools []keeper.Pool) map[PullRequestKey]bool {
+	answer := map[PullRequestKey]bool{}
+	for _, pool := range pools {
+		for _, prs := range [][]keeper.PullRequest{pool.SuccessPRs, pool.PendingPRs, pool.MissingPRs, pool.BatchPending, pool.Target} {
+			for _, pr := range prs {
+				answer[PullRequestKey{Org: strings.ToLower(pool.Org), Repo: strings.ToLower(pool.Repo), Number: int(pr.Number)}] = true
+			}
+		}
+	}
+	return answer
+}
+
+// InPool returns a function protecting jobs building any of the given pull requests.
+func InPool(prs map[PullRequestKey]bool) func(*v1alpha1.LighthouseJob) bool {
+	return func(lhjob *v1alpha1.LighthouseJob) bool {
+		refs := lhjob.Spec.Refs
+		if refs == nil {
+			return false
+		}
+		for _, pull := range refs.Pulls {
+			if prs[PullRequestKey{Org: strings.ToLower(refs.Org), Repo: strings.ToLower(refs.Repo), Number: pull.Number}] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FetchPools reads the current pools from the keeper HTTP endpoint.
+func FetchPools(client *http.Client, keeperURL string) ([]keeper.Pool, error) {
+	resp, err := client.Get(keeperURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get keeper pools from %s", keeperURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get keeper pools from %s: %s", keeperURL, resp.Status)
+	}
+	var pools []keeper.Pool
+	if err := json.NewDecoder(resp.Body).Decode(&pools); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode keeper pools from %s", keeperURL)
+	}
+	return pools, nil
+}
+
+type lighthouseJobClient interface {
+	List(metav1.ListOptions) (*v1alpha1.LighthouseJobList, error)
+	Delete(name string, options *metav1.DeleteOptions) error
+}
+
+type pipelineRunClient interface {
+	List(metav1.ListOptions) (*tektonv1beta1.PipelineRunList, error)
+	Delete(name string, options *metav1.DeleteOptions) error
+}
+
+// Collector deletes LighthouseJobs according to a retention policy.
+type Collector struct {
+	lighthouseClient lighthouseJobClient
+	// pipelineRunClient is optional, it is used to clean up PipelineRuns left behind by deleted jobs
+	pipelineRunClient pipelineRunClient
+	policy            Policy
+	// protected returns true for jobs that must not be deleted, may be nil
+	protected func(*v1alpha1.LighthouseJob) bool
+	dryRun    bool
+	logger    *logrus.Entry
+}
+
+// NewCollector creates a new Collector. The pipeline run client and protected function may be nil.
+func NewCollector(lighthouseClient lighthouseJobClient, pipelineRunClient pipelineRunClient, policy Policy, protected func(*v1alpha1.LighthouseJob) bool, dryRun bool, logger *logrus.Entry) *Collector {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	return &Collector{
+		lighthouseClient:  lighthouseClient,
+		pipelineRunClient: pipelineRunClient,
+		policy:            policy,
+		protected:         protected,
+		dryRun:            dryRun,
+		logger:            logger,
+	}
+}
+
+// Run does one garbage collection pass at the given time.
+func (c *Collector) Run(now time.Time) error {
+	jobList, err := c.lighthouseClient.List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "could not list LighthouseJobs")
+	}
+
+	var errs []string
+	remaining := map[string]bool{}
+	for i := range jobList.Items {
+		remaining[jobList.Items[i].Name] = true
+	}
+	for _, lhjob := range c.policy.Select(jobList.Items, now, c.protected) {
+		if err := c.delete(lhjob.Name); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to delete LighthouseJob %s: %v", lhjob.Name, err))
+			continue
+		}
+		delete(remaining, lhjob.Name)
+	}
+
+	if c.pipelineRunClient != nil {
+		if err := c.cleanPipelineRuns(remaining, now); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (c *Collector) delete(name string) error {
+	c.logger.Infof("Deleting LighthouseJob %s", name)
+	if c.dryRun {
+		return nil
+	}
+	// deleting in the background also deletes the PipelineRuns and pods owned by the job
+	return c.lighthouseClient.Delete(name, deleteOptions())
+}
+
+// cleanPipelineRuns deletes the PipelineRuns created by Lighthouse whose job no longer exists,
+// for example because it was deleted without cascading.
+func (c *Collector) cleanPipelineRuns(jobs map[string]bool, now time.Time) error {
+	list, err := c.pipelineRunClient.List(metav1.ListOptions{LabelSelector: job.CreatedByLighthouseLabel + "=true"})
+	if err != nil {
+		return errors.Wrap(err, "could not list PipelineRuns")
+	}
+	var errs []string
+	for i := range list.Items {
+		pr := &list.Items[i]
+		jobName := pr.Labels[job.LighthouseJobIDLabel]
+		if jobName == "" || jobs[jobName] {
+			continue
+		}
+		// leave PipelineRuns that are still running or were just created alone
+		if pr.Status.CompletionTime == nil && pr.CreationTimestamp.Add(c.policy.PendingTTL).After(now) {
+			continue
+		}
+		c.logger.Infof("Deleting PipelineRun %s of deleted LighthouseJob %s", pr.Name, jobName)
+		if c.dryRun {
+			continue
+		}
+		if err := c.pipelineRunClient.Delete(pr.Name, deleteOptions()); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to delete PipelineRun %s: %v", pr.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func deleteOptions() *metav1.DeleteOptions {
+	options := metav1.NewDeleteOptions(0)
+	propagation := metav1.DeletePropagationBackground
+	options.PropagationPolicy = &propagation
+	return options
+}
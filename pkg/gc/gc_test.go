@@ -0,0 +1,172 @@
+package gc
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	fakelh "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	faketekton "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const ns = "jx"
+
+var now = time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func makeJob(name, jobName string, pull int, state v1alpha1.PipelineState, started time.Duration, completed *time.Duration) *v1alpha1.LighthouseJob {
+	lhjob := &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Job: jobName,
+			Refs: &v1alpha1.Refs{
+				Org:  "org",
+				Repo: "repo",
+			},
+		},
+		Status: v1alpha1.LighthouseJobStatus{
+			State:     state,
+			StartTime: metav1.NewTime(now.Add(-started)),
+		},
+	}
+	if pull > 0 {
+		lhjob.Spec.Refs.Pulls = []v1alpha1.Pull{{Number: pull}}
+	}
+	if completed != nil {
+		t := metav1.NewTime(now.Add(-*completed))
+		lhjob.Status.CompletionTime = &t
+	}
+	return lhjob
+}
+
+func ago(d time.Duration) *time.Duration {
+	return &d
+}
+
+func names(jobs []v1alpha1.LighthouseJob) []string {
+	var answer []string
+	for i := range jobs {
+		answer = append(answer, jobs[i].Name)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+func TestSelect(t *testing.T) {
+	policy := Policy{
+		SuccessTTL: 24 * time.Hour,
+		FailureTTL: 72 * time.Hour,
+		PendingTTL: 7 * 24 * time.Hour,
+	}
+	jobs := []v1alpha1.LighthouseJob{
+		*makeJob("recent-success", "unit", 1, v1alpha1.SuccessState, 2*time.Hour, ago(time.Hour)),
+		*makeJob("old-success", "unit", 1, v1alpha1.SuccessState, 49*time.Hour, ago(48*time.Hour)),
+		*makeJob("old-failure", "unit", 2, v1alpha1.FailureState, 49*time.Hour, ago(48*time.Hour)),
+		*makeJob("older-failure", "unit", 2, v1alpha1.ErrorState, 97*time.Hour, ago(96*time.Hour)),
+		*makeJob("stuck", "unit", 3, v1alpha1.PendingState, 8*24*time.Hour, nil),
+		*makeJob("running", "unit", 3, v1alpha1.RunningState, 49*time.Hour, nil),
+	}
+	assert.Equal(t, []string{"old-success", "older-failure", "stuck"}, names(policy.Select(jobs, now, nil)))
+
+	protected := InPool(map[PullRequestKey]bool{{Org: "org", Repo: "repo", Number: 2}: true})
+	assert.Equal(t, []string{"old-success", "stuck"}, names(policy.Select(jobs, now, protected)))
+
+	policy.KeepRecent = 1
+	// the most recent completed job of each group is kept
+	jobs = append(jobs, *makeJob("other-job", "lint", 1, v1alpha1.SuccessState, 49*time.Hour, ago(48*time.Hour)))
+	assert.Equal(t, []string{"old-success", "older-failure", "stuck"}, names(policy.Select(jobs, now, nil)))
+	policy.KeepRecent = 2
+	assert.Equal(t, []string{"older-failure", "stuck"}, names(policy.Select(jobs, now, nil)))
+}
+
+func TestFetchPools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"Org":"Org","Repo":"repo","Branch":"master","SuccessPRs":[{"Number":1}],"PendingPRs":[{"Number":2}],"MissingPRs":null,"BatchPending":[{"Number":3}],"Action":"Wait","Target":null,"Blockers":null,"Error":""}]`)
+	}))
+	defer server.Close()
+
+	pools, err := FetchPools(server.Client(), server.URL)
+	require.NoError(t, err)
+	prs := PoolPullRequests(pools)
+	assert.Equal(t, map[PullRequestKey]bool{
+		{Org: "org", Repo: "repo", Number: 1}: true,
+		{Org: "org", Repo: "repo", Number: 2}: true,
+		{Org: "org", Repo: "repo", Number: 3}: true,
+	}, prs)
+
+	inPool := InPool(prs)
+	assert.True(t, inPool(makeJob("a", "unit", 2, v1alpha1.SuccessState, 0, nil)))
+	assert.False(t, inPool(makeJob("b", "unit", 4, v1alpha1.SuccessState, 0, nil)))
+	assert.False(t, inPool(makeJob("c", "unit", 0, v1alpha1.SuccessState, 0, nil)))
+}
+
+func makePipelineRun(name, jobName string, created time.Duration, completed bool) *tektonv1beta1.PipelineRun {
+	pr := &tektonv1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         ns,
+			CreationTimestamp: metav1.NewTime(now.Add(-created)),
+			Labels: map[string]string{
+				job.CreatedByLighthouseLabel: "true",
+				job.LighthouseJobIDLabel:     jobName,
+			},
+		},
+	}
+	if completed {
+		t := metav1.NewTime(now.Add(-created / 2))
+		pr.Status.CompletionTime = &t
+	}
+	return pr
+}
+
+func TestCollectorRun(t *testing.T) {
+	lhClient := fakelh.NewSimpleClientset(
+		makeJob("keep", "unit", 1, v1alpha1.SuccessState, 2*time.Hour, ago(time.Hour)),
+		makeJob("delete", "unit", 1, v1alpha1.SuccessState, 49*time.Hour, ago(48*time.Hour)),
+	)
+	tektonClient := faketekton.NewSimpleClientset([]runtime.Object{
+		makePipelineRun("pr-keep", "keep", 2*time.Hour, true),
+		makePipelineRun("pr-delete", "delete", 49*time.Hour, true),
+		makePipelineRun("pr-orphan", "gone", 49*time.Hour, true),
+		makePipelineRun("pr-orphan-running", "gone-too", time.Hour, false),
+	}...)
+
+	policy := Policy{SuccessTTL: 24 * time.Hour, FailureTTL: 24 * time.Hour, PendingTTL: 24 * time.Hour}
+	c := NewCollector(lhClient.LighthouseV1alpha1().LighthouseJobs(ns), tektonClient.TektonV1beta1().PipelineRuns(ns), policy, nil, false, nil)
+	require.NoError(t, c.Run(now))
+
+	jobs, err := lhClient.LighthouseV1alpha1().LighthouseJobs(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"keep"}, names(jobs.Items))
+
+	// the fake client does not cascade deletes, so the PipelineRun of the deleted job is cleaned up as an orphan
+	prs, err := tektonClient.TektonV1beta1().PipelineRuns(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	var prNames []string
+	for _, pr := range prs.Items {
+		prNames = append(prNames, pr.Name)
+	}
+	sort.Strings(prNames)
+	assert.Equal(t, []string{"pr-keep", "pr-orphan-running"}, prNames)
+}
+
+func TestCollectorDryRun(t *testing.T) {
+	lhClient := fakelh.NewSimpleClientset(
+		makeJob("delete", "unit", 1, v1alpha1.SuccessState, 49*time.Hour, ago(48*time.Hour)),
+	)
+	c := NewCollector(lhClient.LighthouseV1alpha1().LighthouseJobs(ns), nil, Policy{}, nil, true, nil)
+	require.NoError(t, c.Run(now))
+
+	jobs, err := lhClient.LighthouseV1alpha1().LighthouseJobs(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, jobs.Items, 1)
+}
@@ -318,6 +318,90 @@ func (r *Repo) Push(repo, branch string) error {
 	return err
 }
 
+// ForcePush force pushes the current HEAD to the provided owner/repo#branch,
+// as long as the remote branch still points to the expected commit, so that
+// the commits pushed in the meantime are never lost. An empty expected commit
+// requires the branch not to exist yet.
+func (r *Repo) ForcePush(repo, branch, expected string) error {
+	r.logger.Infof("Force pushing to '%s (branch: %s)'.", repo, branch)
+	co := r.gitCommand("push", fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", branch, expected), r.base+"/"+repo, "HEAD:refs/heads/"+branch)
+	if b, err := co.CombinedOutput(); err != nil {
+		return fmt.Errorf("error force pushing to %s (branch: %s): %v. output: %s", repo, branch, err, string(b))
+	}
+	return nil
+}
+
+// Fetch fetches the ref of the provided owner/repo, which can then be checked
+// out as FETCH_HEAD.
+func (r *Repo) Fetch(repo, ref string) error {
+	r.logger.Infof("Fetching %s from %s.", ref, repo)
+	if b, err := retryCmd(r.logger, r.Dir, r.git, "fetch", r.base+"/"+repo, ref); err != nil {
+		return fmt.Errorf("git fetch failed for %s of %s: %v. output: %s", ref, repo, err, string(b))
+	}
+	return nil
+}
+
+// Rebase attempts to rebase the current branch onto commitlike. It returns
+// true if the rebase completes, or the files in conflict otherwise. It
+// returns an error if the abort fails.
+func (r *Repo) Rebase(commitlike string) (bool, []string, error) {
+	r.logger.Infof("Rebasing onto %s.", commitlike)
+	b, err := r.gitCommand("rebase", commitlike).CombinedOutput()
+	if err == nil {
+		return true, nil, nil
+	}
+	r.logger.WithError(err).Warningf("Rebase failed with output: %s", string(b))
+
+	conflicts := r.conflicts()
+	if b, err := r.gitCommand("rebase", "--abort").CombinedOutput(); err != nil {
+		return false, conflicts, fmt.Errorf("error aborting rebase onto %s: %v. output: %s", commitlike, err, string(b))
+	}
+	return false, conflicts, nil
+}
+
+// CherryPick attempts to cherry-pick commitlike onto the current branch, the
+// changes of a merge commit being picked relative to its first parent. It
+// returns true if the cherry-pick completes, or the files in conflict
+// otherwise. It returns an error if the abort fails.
+func (r *Repo) CherryPick(commitlike string) (bool, []string, error) {
+	r.logger.Infof("Cherry-picking %s.", commitlike)
+	b, err := r.gitCommand("rev-list", "--parents", "-n", "1", commitlike).CombinedOutput()
+	if err != nil {
+		return false, nil, fmt.Errorf("error listing the parents of %s: %v. output: %s", commitlike, err, string(b))
+	}
+	args := []string{"cherry-pick", "-x"}
+	if len(strings.Fields(string(b))) > 2 {
+		args = append(args, "-m", "1")
+	}
+	b, err = r.gitCommand(append(args, commitlike)...).CombinedOutput()
+	if err == nil {
+		return true, nil, nil
+	}
+	r.logger.WithError(err).Warningf("Cherry-pick failed with output: %s", string(b))
+
+	conflicts := r.conflicts()
+	if b, err := r.gitCommand("cherry-pick", "--abort").CombinedOutput(); err != nil {
+		return false, conflicts, fmt.Errorf("error aborting cherry-pick of %s: %v. output: %s", commitlike, err, string(b))
+	}
+	return false, conflicts, nil
+}
+
+// conflicts returns the unmerged files of a rebase or cherry-pick which
+// stopped on conflicts.
+func (r *Repo) conflicts() []string {
+	b, err := r.gitCommand("diff", "--name-only", "--diff-filter=U").CombinedOutput()
+	if err != nil {
+		return nil
+	}
+	var conflicts []string
+	for _, file := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if file != "" {
+			conflicts = append(conflicts, file)
+		}
+	}
+	return conflicts
+}
+
 // CheckoutPullRequest does exactly that.
 func (r *Repo) CheckoutPullRequest(number int) error {
 	r.logger.Infof("Fetching and checking out %s#%d.", r.repo, number)
@@ -84,7 +84,7 @@ func (g *Group) load(r io.Reader) error {
 		// When the pattern matches the path in question, the attributes listed on the line are given to the path.
 		attributes := sets.NewString(fs[1:]...)
 		if attributes.Has("linguist-generated=true") {
-			p, err := parsePattern(fs[0])
+			p, err := ParsePattern(fs[0])
 			if err != nil {
 				return fmt.Errorf("error parsing pattern: %v", err)
 			}
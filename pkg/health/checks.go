@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/watcher"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/discovery"
+)
+
+// SCMCheckTTL is how long the result of the check of the SCM token is reused for, so that the probes do not use up
+// the rate limit of the token
+const SCMCheckTTL = time.Minute
+
+// KubernetesCheck returns a check failing if the Kubernetes API server is unreachable
+func KubernetesCheck(client discovery.ServerVersionInterface) Check {
+	return func(ctx context.Context) error {
+		_, err := client.ServerVersion()
+		return errors.Wrap(err, "unable to reach the Kubernetes API server")
+	}
+}
+
+// ConfigCheck returns a check failing if the last reload of any configuration watched by w failed, the component
+// still using the last good one
+func ConfigCheck(w *watcher.ConfigMapWatcher) Check {
+	return func(ctx context.Context) error {
+		var failed []string
+		for _, s := range w.ReloadStatuses() {
+			if !s.Healthy {
+				failed = append(failed, s.Name+": "+s.LastError)
+			}
+		}
+		if len(failed) > 0 {
+			return errors.Errorf("invalid configuration, the last good one is still in use: %s", strings.Join(failed, ", "))
+		}
+		return nil
+	}
+}
+
+// SCMCheck returns a check failing if the SCM provider is unreachable or rejects the token of the client. The result
+// is reused for SCMCheckTTL, and the check is shed rather than delayed once the rate limit is nearly exhausted.
+func SCMCheck(client *scm.Client) Check {
+	return Cached(func(ctx context.Context) error {
+		_, res, err := client.Users.Find(scmprovider.ContextWithPriority(ctx, scmprovider.PriorityLow))
+		if res != nil {
+			switch res.Status {
+			case http.StatusUnauthorized:
+				return errors.New("the SCM provider rejected the token")
+			default:
+				// the token is valid even if it is not allowed to find the user, or is rate limited
+				return nil
+			}
+		}
+		if err != nil && !errors.Is(err, scmprovider.ErrRateLimitBudgetExhausted) {
+			return errors.Wrap(err, "unable to reach the SCM provider")
+		}
+		return nil
+	}, SCMCheckTTL)
+}
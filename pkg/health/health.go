@@ -0,0 +1,181 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// LivenessPath is the path the liveness endpoint is served on
+	LivenessPath = "/healthz"
+	// ReadinessPath is the path the readiness endpoint is served on
+	ReadinessPath = "/readyz"
+
+	// DefaultTimeout is how long the checks of an endpoint may take before they fail
+	DefaultTimeout = 4 * time.Second
+)
+
+// Check verifies a dependency of the component, returning an error if it is not usable
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker serves the liveness and readiness endpoints of a component. The liveness checks should only fail if the
+// process has to be restarted, while the readiness checks verify the dependencies the component needs to do its work.
+type Checker struct {
+	// Timeout is how long the checks of an endpoint may take, DefaultTimeout if zero
+	Timeout time.Duration
+
+	lock      sync.RWMutex
+	liveness  []namedCheck
+	readiness []namedCheck
+}
+
+// NewChecker creates a checker without any check, whose endpoints always succeed until checks are added
+func NewChecker() *Checker {
+	return &Checker{Timeout: DefaultTimeout}
+}
+
+// AddLivenessCheck adds a check to the liveness endpoint
+func (c *Checker) AddLivenessCheck(name string, check Check) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.liveness = append(c.liveness, namedCheck{name: name, check: check})
+}
+
+// AddReadinessCheck adds a check to the readiness endpoint
+func (c *Checker) AddReadinessCheck(name string, check Check) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.readiness = append(c.readiness, namedCheck{name: name, check: check})
+}
+
+// Register registers the liveness and readiness endpoints on the given mux
+func (c *Checker) Register(mux *http.ServeMux) {
+	mux.Handle(LivenessPath, c.LivenessHandler())
+	mux.Handle(ReadinessPath, c.ReadinessHandler())
+}
+
+// LivenessHandler returns the handler of the liveness endpoint
+func (c *Checker) LivenessHandler() http.Handler {
+	return c.handler(func() []namedCheck {
+		c.lock.RLock()
+		defer c.lock.RUnlock()
+		return c.liveness
+	})
+}
+
+// ReadinessHandler returns the handler of the readiness endpoint
+func (c *Checker) ReadinessHandler() http.Handler {
+	return c.handler(func() []namedCheck {
+		c.lock.RLock()
+		defer c.lock.RUnlock()
+		return c.readiness
+	})
+}
+
+// Ready runs the readiness checks, returning the error of the first one which failed
+func (c *Checker) Ready(ctx context.Context) error {
+	c.lock.RLock()
+	checks := c.readiness
+	c.lock.RUnlock()
+	for i, err := range c.run(ctx, checks) {
+		if err != nil {
+			return errors.Wrapf(err, "check %s failed", checks[i].name)
+		}
+	}
+	return nil
+}
+
+// handler runs the checks concurrently and responds with 200 OK if they all succeed, 503 Service Unavailable
+// otherwise. The result of each check is listed in the body, in the same format as the Kubernetes API server.
+func (c *Checker) handler(checks func() []namedCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		all := checks()
+		results := c.run(r.Context(), all)
+		buf := &bytes.Buffer{}
+		failed := false
+		for i, err := range results {
+			if err != nil {
+				failed = true
+				fmt.Fprintf(buf, "[-]%s failed: %v\n", all[i].name, err)
+				logrus.WithError(err).WithField("check", all[i].name).WithField("path", r.URL.Path).Warn("health check failed")
+			} else {
+				fmt.Fprintf(buf, "[+]%s ok\n", all[i].name)
+			}
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if failed {
+			fmt.Fprintf(buf, "%s check failed\n", r.URL.Path)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			fmt.Fprintf(buf, "%s check passed\n", r.URL.Path)
+		}
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+// run runs the checks concurrently, failing the ones which do not complete before the timeout
+func (c *Checker) run(ctx context.Context, checks []namedCheck) []error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results := make([]error, len(checks))
+	wg := sync.WaitGroup{}
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			done := make(chan error, 1)
+			go func() {
+				done <- checks[i].check(ctx)
+			}()
+			select {
+			case err := <-done:
+				results[i] = err
+			case <-ctx.Done():
+				results[i] = errors.Errorf("timed out after %s", timeout)
+			}
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// Cached returns a check which only runs the given one once per ttl, returning its last result in between. It is
+// used for the checks calling rate limited APIs.
+func Cached(check Check, ttl time.Duration) Check {
+	var (
+		lock    sync.Mutex
+		lastRun time.Time
+		lastErr error
+	)
+	return func(ctx context.Context) error {
+		lock.Lock()
+		defer lock.Unlock()
+		if !lastRun.IsZero() && time.Since(lastRun) < ttl {
+			return lastErr
+		}
+		lastErr = check(ctx)
+		lastRun = time.Now()
+		return lastErr
+	}
+}
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker(t *testing.T) {
+	c := NewChecker()
+	c.Timeout = 100 * time.Millisecond
+	c.AddLivenessCheck("ping", func(ctx context.Context) error { return nil })
+	c.AddReadinessCheck("kubernetes", func(ctx context.Context) error { return nil })
+	c.AddReadinessCheck("scm", func(ctx context.Context) error { return errors.New("bad credentials") })
+	c.AddReadinessCheck("jenkins", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	mux := http.NewServeMux()
+	c.Register(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, LivenessPath, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[+]ping ok\n/healthz check passed\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "[+]kubernetes ok\n[-]scm failed: bad credentials\n[-]jenkins failed: timed out after 100ms\n/readyz check failed\n", w.Body.String())
+
+	err := c.Ready(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, "check scm failed: bad credentials", err.Error())
+}
+
+func TestCached(t *testing.T) {
+	calls := 0
+	check := Cached(func(ctx context.Context) error {
+		calls++
+		return errors.New("unreachable")
+	}, time.Hour)
+	assert.Error(t, check(context.Background()))
+	assert.Error(t, check(context.Background()))
+	assert.Equal(t, 1, calls)
+}
+
+func TestSCMCheck(t *testing.T) {
+	status := http.StatusOK
+	remaining := 5000
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"login": "bot"}`))
+	}))
+	defer server.Close()
+
+	newCheck := func() Check {
+		client, err := factory.NewClient("github", server.URL, "")
+		require.NoError(t, err)
+		scmprovider.InstrumentClient(client, t.Name())
+		return SCMCheck(client)
+	}
+
+	assert.NoError(t, newCheck()(context.Background()))
+
+	status = http.StatusForbidden
+	assert.NoError(t, newCheck()(context.Background()), "the token is valid even if it is not allowed to find the user")
+
+	status = http.StatusUnauthorized
+	assert.Error(t, newCheck()(context.Background()))
+
+	// the check is shed rather than using up the rest of the rate limit
+	status = http.StatusOK
+	remaining = 10
+	assert.NoError(t, newCheck()(context.Background()))
+	assert.NoError(t, newCheck()(context.Background()))
+
+	server.Close()
+	client, err := factory.NewClient("github", server.URL, "")
+	require.NoError(t, err)
+	assert.Error(t, SCMCheck(client)(context.Background()), "the provider is unreachable")
+}
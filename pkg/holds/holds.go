@@ -0,0 +1,141 @@
+// Package holds records the reason and the expiry of the holds put on the pull requests with the /hold command, in
+// the sticky comment of the hold plugin, so that Keeper can surface the reason in its status and remove the expired
+// holds.
+package holds
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+)
+
+const (
+	// markerID identifies the sticky comment recording the hold of a pull request
+	markerID = "hold"
+
+	// maxReasonChars is the maximum length of the reason shown in a status description, which GitHub limits to
+	// 140 characters
+	maxReasonChars = 80
+)
+
+var (
+	// DurationPattern matches the durations accepted by ParseDuration
+	DurationPattern = `(?:\d+[wdhm])+`
+
+	durationRe = regexp.MustCompile(`^` + DurationPattern + `$`)
+	unitRe     = regexp.MustCompile(`(\d+)([wdhm])`)
+	dataRe     = regexp.MustCompile(`<!-- hold-data: (.*) -->`)
+
+	units = map[string]time.Duration{
+		"w": 7 * 24 * time.Hour,
+		"d": 24 * time.Hour,
+		"h": time.Hour,
+		"m": time.Minute,
+	}
+)
+
+// Hold is the hold of a pull request
+type Hold struct {
+	// By is the login of the user who put the pull request on hold
+	By string `json:"by,omitempty"`
+	// Reason is why the pull request is on hold
+	Reason string `json:"reason,omitempty"`
+	// Expiry is when the hold is removed automatically, never if nil
+	Expiry *time.Time `json:"expiry,omitempty"`
+}
+
+// Marker returns the hidden marker of the sticky comment recording the hold of a pull request
+func Marker() string {
+	return botcomment.Marker(markerID)
+}
+
+// ParseDuration parses a duration made of weeks, days, hours and minutes, e.g. 2d or 1h30m
+func ParseDuration(s string) (time.Duration, error) {
+	if !durationRe.MatchString(s) {
+		return 0, fmt.Errorf("invalid duration %q, it must be made of weeks, days, hours and minutes, e.g. 2d or 1h30m", s)
+	}
+	var d time.Duration
+	for _, m := range unitRe.FindAllStringSubmatch(s, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %v", s, err)
+		}
+		d += time.Duration(n) * units[m[2]]
+	}
+	return d, nil
+}
+
+// Format returns the body of the comment recording the hold
+func (h *Hold) Format() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("This pull request was put on hold by @%s", h.By))
+	if h.Expiry != nil {
+		sb.WriteString(" until " + formatTime(*h.Expiry))
+	}
+	if h.Reason != "" {
+		sb.WriteString(": " + h.Reason)
+	}
+	sb.WriteString(".\n\nUse `/hold cancel` to remove the hold")
+	if h.Expiry != nil {
+		sb.WriteString(", which is otherwise removed once it expires")
+	}
+	data, _ := json.Marshal(h)
+	sb.WriteString(fmt.Sprintf(".\n\n<!-- hold-data: %s -->\n%s", data, Marker()))
+	return sb.String()
+}
+
+// Parse returns the hold recorded in the body of a comment, nil if there is none
+func Parse(body string) *Hold {
+	m := dataRe.FindStringSubmatch(body)
+	if m == nil {
+		return nil
+	}
+	h := &Hold{}
+	if err := json.Unmarshal([]byte(m[1]), h); err != nil {
+		return nil
+	}
+	return h
+}
+
+// Find returns the hold recorded by the bot among the comments of a pull request, nil if there is none
+func Find(comments []*scm.Comment, botName string) *Hold {
+	found := botcomment.Find(comments, botName, Marker())
+	if len(found) == 0 {
+		return nil
+	}
+	return Parse(found[len(found)-1].Body)
+}
+
+// Expired tells whether the hold has expired at the given time
+func (h *Hold) Expired(now time.Time) bool {
+	return h.Expiry != nil && !now.Before(*h.Expiry)
+}
+
+// Description returns the description of the hold for the status of a pull request, e.g. " On hold until
+// 2020-01-02 15:04 UTC: flaky infra.", or an empty string if the hold has neither a reason nor an expiry
+func (h *Hold) Description() string {
+	if h.Reason == "" && h.Expiry == nil {
+		return ""
+	}
+	desc := " On hold"
+	if h.Expiry != nil {
+		desc += " until " + formatTime(*h.Expiry)
+	}
+	if reason := h.Reason; reason != "" {
+		if r := []rune(reason); len(r) > maxReasonChars {
+			reason = strings.TrimSpace(string(r[:maxReasonChars-3])) + "..."
+		}
+		desc += ": " + reason
+	}
+	return desc + "."
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04 MST")
+}
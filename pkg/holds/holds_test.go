@@ -0,0 +1,82 @@
+package holds
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected time.Duration
+		err      bool
+	}{
+		{input: "48h", expected: 48 * time.Hour},
+		{input: "2d", expected: 48 * time.Hour},
+		{input: "1w", expected: 7 * 24 * time.Hour},
+		{input: "1h30m", expected: 90 * time.Minute},
+		{input: "30s", err: true},
+		{input: "soon", err: true},
+		{input: "", err: true},
+	}
+	for _, tc := range cases {
+		actual, err := ParseDuration(tc.input)
+		if tc.err {
+			assert.Error(t, err, tc.input)
+			continue
+		}
+		require.NoError(t, err, tc.input)
+		assert.Equal(t, tc.expected, actual, tc.input)
+	}
+}
+
+func TestFormatAndFind(t *testing.T) {
+	expiry := time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC)
+	hold := &Hold{By: "user", Reason: "flaky infra", Expiry: &expiry}
+	body := hold.Format()
+	assert.Contains(t, body, "put on hold by @user until 2020-01-02 15:04 UTC: flaky infra.")
+	assert.Contains(t, body, Marker())
+
+	comments := []*scm.Comment{
+		{Author: scm.User{Login: "bot"}, Body: (&Hold{By: "other"}).Format()},
+		{Author: scm.User{Login: "user"}, Body: "/hold 48h flaky infra"},
+		{Author: scm.User{Login: "someone"}, Body: body},
+		{Author: scm.User{Login: "bot"}, Body: body},
+	}
+	found := Find(comments, "bot")
+	require.NotNil(t, found)
+	assert.Equal(t, "user", found.By)
+	assert.Equal(t, "flaky infra", found.Reason)
+	require.NotNil(t, found.Expiry)
+	assert.True(t, expiry.Equal(*found.Expiry))
+
+	assert.Nil(t, Find(comments[1:3], "bot"))
+	assert.Nil(t, Parse("no hold"))
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+	assert.True(t, (&Hold{Expiry: &past}).Expired(now))
+	assert.True(t, (&Hold{Expiry: &now}).Expired(now))
+	assert.False(t, (&Hold{Expiry: &future}).Expired(now))
+	assert.False(t, (&Hold{}).Expired(now))
+}
+
+func TestDescription(t *testing.T) {
+	expiry := time.Date(2020, 1, 2, 15, 4, 0, 0, time.FixedZone("CET", 3600))
+	assert.Equal(t, "", (&Hold{By: "user"}).Description())
+	assert.Equal(t, " On hold: flaky infra.", (&Hold{Reason: "flaky infra"}).Description())
+	assert.Equal(t, " On hold until 2020-01-02 14:04 UTC.", (&Hold{Expiry: &expiry}).Description())
+	assert.Equal(t, " On hold until 2020-01-02 14:04 UTC: flaky infra.", (&Hold{Reason: "flaky infra", Expiry: &expiry}).Description())
+
+	long := (&Hold{Reason: strings.Repeat("a", 200), Expiry: &expiry}).Description()
+	assert.True(t, strings.HasSuffix(long, "a...."), long)
+	assert.True(t, len("Not mergeable."+long) <= 140, long)
+}
@@ -0,0 +1,257 @@
+// Package joblogs streams the live logs of the LighthouseJobs over HTTP, from the pods of their Tekton
+// pipeline runs or the console output of their Jenkins builds, so that they can be followed before the
+// artifacts of the jobs are uploaded.
+package joblogs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	clientset "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/engines/jenkins"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Path is the path prefix the logs are served on, followed by the name of the job
+	Path = "/logs/"
+
+	// pipelineRunLabel is the label Tekton sets on the pods of a pipeline run
+	pipelineRunLabel = "tekton.dev/pipelineRun"
+	// linkKeyLabel labels the key signing the links derived from the secret
+	linkKeyLabel = "lighthouse-job-logs"
+)
+
+// linkKey derives the key signing the links from the secret, so that the tokens published in the comments and
+// statuses of the pull requests are not signatures made with the secret authenticating the webhooks
+func linkKey(secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(linkKeyLabel))
+	return mac.Sum(nil)
+}
+
+// Sign returns the token authorizing access to the logs of the job, signed with a key derived from the secret
+func Sign(secret []byte, name string) string {
+	mac := hmac.New(sha256.New, linkKey(secret))
+	_, _ = mac.Write([]byte(name))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// URL returns the signed link to the logs of the job on the log streaming endpoint at baseURL, or an empty
+// string if the endpoint or the secret is not configured
+func URL(baseURL, name string, secret []byte) string {
+	if baseURL == "" || len(secret) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s?token=%s", strings.TrimSuffix(baseURL, "/"), url.PathEscape(name), Sign(secret, name))
+}
+
+// Handler serves the logs of the LighthouseJobs of a namespace on Path, e.g. /logs/my-job?token=..., the token
+// being the signature of the job name returned by Sign.
+type Handler struct {
+	kubeClient  kubernetes.Interface
+	lhClient    clientset.Interface
+	namespace   string
+	config      config.Getter
+	secret      func() []byte
+	client      *http.Client
+	jenkinsAuth *jenkins.AuthConfig
+	logger      *logrus.Entry
+	podLogs     func(pod, container string, follow bool) (io.ReadCloser, error)
+}
+
+// NewHandler creates a handler serving the logs of the jobs of the namespace, the secret signing the links
+func NewHandler(kubeClient kubernetes.Interface, lhClient clientset.Interface, namespace string, cfg config.Getter, secret func() []byte) *Handler {
+	h := &Handler{
+		kubeClient: kubeClient,
+		lhClient:   lhClient,
+		namespace:  namespace,
+		config:     cfg,
+		secret:     secret,
+		client:     &http.Client{},
+		logger:     logrus.WithField("handler", "logs"),
+	}
+	h.podLogs = h.streamPodLogs
+	return h
+}
+
+// SetJenkinsAuth configures the credentials the console output of the Jenkins builds is read with
+func (h *Handler) SetJenkinsAuth(auth *jenkins.AuthConfig) {
+	h.jenkinsAuth = auth
+}
+
+func (h *Handler) streamPodLogs(pod, container string, follow bool) (io.ReadCloser, error) {
+	return h.kubeClient.CoreV1().Pods(h.namespace).GetLogs(pod, &corev1.PodLogOptions{Container: container, Follow: follow}).Stream()
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "405 Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, Path)
+	if name == "" || strings.Contains(name, "/") {
+		http.Error(w, "400 Bad Request: the path must be the name of a job, e.g. /logs/my-job", http.StatusBadRequest)
+		return
+	}
+	secret := h.secret()
+	token := r.URL.Query().Get("token")
+	if len(secret) == 0 || !hmac.Equal([]byte(token), []byte(Sign(secret, name))) {
+		http.Error(w, "403 Forbidden: invalid token", http.StatusForbidden)
+		return
+	}
+
+	lhjob, err := h.lhClient.LighthouseV1alpha1().LighthouseJobs(h.namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, fmt.Sprintf("404 Not Found: job %s", name), http.StatusNotFound)
+			return
+		}
+		h.logger.WithError(err).WithField("job", name).Warn("failed to get the job")
+		http.Error(w, "500 Internal Server Error: failed to get the job", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	switch lhjob.Spec.Agent {
+	case job.TektonPipelineAgent:
+		err = h.streamTekton(w, lhjob)
+	case job.JenkinsAgent:
+		err = h.streamJenkins(w, lhjob)
+	default:
+		http.Error(w, fmt.Sprintf("404 Not Found: the logs of %s jobs cannot be streamed", lhjob.Spec.Agent), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).WithField("job", name).Debug("failed to stream the logs of the job")
+	}
+}
+
+// notFound reports that the logs are not available, linking to the artifacts of the job if any
+func notFound(w http.ResponseWriter, lhjob *v1alpha1.LighthouseJob, reason string) {
+	msg := fmt.Sprintf("404 Not Found: %s", reason)
+	if lhjob.Status.ArtifactsURL != "" {
+		msg += fmt.Sprintf(", see the artifacts at %s", lhjob.Status.ArtifactsURL)
+	}
+	http.Error(w, msg, http.StatusNotFound)
+}
+
+// streamTekton writes the logs of the step containers of the pods of the pipeline run of the job, following them
+// while the job is running
+func (h *Handler) streamTekton(w http.ResponseWriter, lhjob *v1alpha1.LighthouseJob) error {
+	if lhjob.Status.Activity == nil || lhjob.Status.Activity.Name == "" {
+		notFound(w, lhjob, "the pipeline of the job has not started yet")
+		return nil
+	}
+	pods, err := h.kubeClient.CoreV1().Pods(h.namespace).List(metav1.ListOptions{
+		LabelSelector: pipelineRunLabel + "=" + lhjob.Status.Activity.Name,
+	})
+	if err != nil {
+		http.Error(w, "500 Internal Server Error: failed to list the pods of the pipeline", http.StatusInternalServerError)
+		return err
+	}
+	if len(pods.Items) == 0 {
+		notFound(w, lhjob, "the pods of the pipeline do not exist")
+		return nil
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].CreationTimestamp.Before(&pods.Items[j].CreationTimestamp)
+	})
+	follow := lhjob.Status.CompletionTime == nil
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for _, c := range pod.Spec.Containers {
+			if _, err := fmt.Fprintf(w, "==> %s/%s <==\n", pod.Name, c.Name); err != nil {
+				return err
+			}
+			stream, err := h.podLogs(pod.Name, c.Name, follow)
+			if err != nil {
+				if _, err := fmt.Fprintf(w, "failed to get the logs: %v\n", err); err != nil {
+					return err
+				}
+				continue
+			}
+			err = copyFlush(w, stream)
+			stream.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// streamJenkins proxies the console output of the Jenkins build of the job from the log server of the
+// Jenkins controller
+func (h *Handler) streamJenkins(w http.ResponseWriter, lhjob *v1alpha1.LighthouseJob) error {
+	jenkinsURL := ""
+	if cfg := h.config(); cfg != nil {
+		jenkinsURL = cfg.LogStreaming.JenkinsURL
+	}
+	if jenkinsURL == "" {
+		notFound(w, lhjob, "log_streaming.jenkins_url is not configured")
+		return nil
+	}
+	if lhjob.Status.ActivityName == "" {
+		notFound(w, lhjob, "the Jenkins build of the job has not started yet")
+		return nil
+	}
+	u := strings.TrimSuffix(jenkinsURL, "/") + jenkins.ConsoleTextPath(&lhjob.Spec, lhjob.Status.ActivityName)
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		http.Error(w, "500 Internal Server Error: invalid Jenkins URL", http.StatusInternalServerError)
+		return err
+	}
+	h.jenkinsAuth.Authenticate(req)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		http.Error(w, "502 Bad Gateway: failed to get the Jenkins console output", http.StatusBadGateway)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		notFound(w, lhjob, "the Jenkins console output is not available")
+		return fmt.Errorf("GET %s returned status %q", u, resp.Status)
+	}
+	return copyFlush(w, resp.Body)
+}
+
+// copyFlush copies the reader to the response, flushing it after every read so that the client gets the
+// logs as they are written
+func copyFlush(w http.ResponseWriter, r io.Reader) error {
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package joblogs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	fakelh "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/engines/jenkins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	ns     = "jx"
+	secret = "s3cr3t"
+)
+
+func newHandler(cfg *config.Config, objects ...runtime.Object) (*Handler, *fakelh.Clientset) {
+	lhClient := fakelh.NewSimpleClientset()
+	kubeClient := fake.NewSimpleClientset(objects...)
+	h := NewHandler(kubeClient, lhClient, ns, func() *config.Config { return cfg }, func() []byte { return []byte(secret) })
+	return h, lhClient
+}
+
+func get(h http.Handler, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestURL(t *testing.T) {
+	assert.Equal(t, "https://hook.example.com/logs/my-job?token="+Sign([]byte(secret), "my-job"), URL("https://hook.example.com/logs/", "my-job", []byte(secret)))
+	assert.Empty(t, URL("", "my-job", []byte(secret)))
+	assert.Empty(t, URL("https://hook.example.com/logs", "my-job", nil))
+}
+
+func TestAuthentication(t *testing.T) {
+	h, _ := newHandler(&config.Config{})
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte("my-job"))
+	signedWithSecret := hex.EncodeToString(mac.Sum(nil))
+
+	assert.Equal(t, http.StatusForbidden, get(h, "/logs/my-job").Code)
+	assert.Equal(t, http.StatusForbidden, get(h, "/logs/my-job?token="+Sign([]byte(secret), "other-job")).Code)
+	assert.Equal(t, http.StatusForbidden, get(h, "/logs/my-job?token="+signedWithSecret).Code, "the links are not signed with the secret itself")
+	assert.Equal(t, http.StatusBadRequest, get(h, "/logs/").Code)
+	assert.Equal(t, http.StatusNotFound, get(h, "/logs/my-job?token="+Sign([]byte(secret), "my-job")).Code)
+}
+
+func TestStreamTekton(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-pipeline-build-pod",
+			Namespace: ns,
+			Labels:    map[string]string{pipelineRunLabel: "my-pipeline"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "step-build"}, {Name: "step-test"}}},
+	}
+	h, lhClient := newHandler(&config.Config{}, pod)
+	h.podLogs = func(pod, container string, follow bool) (io.ReadCloser, error) {
+		assert.True(t, follow)
+		return ioutil.NopCloser(strings.NewReader(container + " logs\n")), nil
+	}
+	_, err := lhClient.LighthouseV1alpha1().LighthouseJobs(ns).Create(&v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-job", Namespace: ns},
+		Spec:       v1alpha1.LighthouseJobSpec{Agent: job.TektonPipelineAgent},
+		Status: v1alpha1.LighthouseJobStatus{
+			State:    v1alpha1.PendingState,
+			Activity: &v1alpha1.ActivityRecord{Name: "my-pipeline"},
+		},
+	})
+	require.NoError(t, err)
+
+	w := get(h, "/logs/my-job?token="+Sign([]byte(secret), "my-job"))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "==> my-pipeline-build-pod/step-build <==\nstep-build logs\n==> my-pipeline-build-pod/step-test <==\nstep-test logs\n", w.Body.String())
+}
+
+func TestStreamTektonNotStarted(t *testing.T) {
+	h, lhClient := newHandler(&config.Config{})
+	_, err := lhClient.LighthouseV1alpha1().LighthouseJobs(ns).Create(&v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-job", Namespace: ns},
+		Spec:       v1alpha1.LighthouseJobSpec{Agent: job.TektonPipelineAgent},
+	})
+	require.NoError(t, err)
+
+	w := get(h, "/logs/my-job?token="+Sign([]byte(secret), "my-job"))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "has not started yet")
+}
+
+func TestStreamJenkins(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/job/my-folder/job/build/42/consoleText" {
+			http.NotFound(w, r)
+			return
+		}
+		if user, token, ok := r.BasicAuth(); !ok || user != "jenkins-trigger" || token != "api-token" {
+			http.Error(w, "401 Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("Started by lighthouse\n"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.LogStreaming.JenkinsURL = server.URL
+	h, lhClient := newHandler(cfg)
+	h.SetJenkinsAuth(&jenkins.AuthConfig{
+		Basic: &jenkins.BasicAuthConfig{User: "jenkins-trigger", GetToken: func() []byte { return []byte("api-token") }},
+	})
+	_, err := lhClient.LighthouseV1alpha1().LighthouseJobs(ns).Create(&v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-job", Namespace: ns},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Agent:       job.JenkinsAgent,
+			Job:         "build",
+			JenkinsSpec: &v1alpha1.JenkinsSpec{Folder: "my-folder"},
+		},
+		Status: v1alpha1.LighthouseJobStatus{ActivityName: "42"},
+	})
+	require.NoError(t, err)
+
+	w := get(h, "/logs/my-job?token="+Sign([]byte(secret), "my-job"))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := ioutil.ReadAll(w.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "Started by lighthouse\n", string(body))
+}
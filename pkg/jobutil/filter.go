@@ -31,6 +31,9 @@ var TestAllRe = regexp.MustCompile(`(?m)^/(?:lh-)?test all,?($|\s.*)`)
 // RetestRe provides the regex for `/retest`
 var RetestRe = regexp.MustCompile(`(?m)^/(?:lh-)?retest\s*$`)
 
+// RetestRequiredRe provides the regex for `/retest-required`
+var RetestRequiredRe = regexp.MustCompile(`(?m)^/(?:lh-)?retest-required\s*$`)
+
 // OkToTestRe provies the regex for `/ok-to-test`
 var OkToTestRe = regexp.MustCompile(`(?m)^/(?:lh-)?ok-to-test\s*$`)
 
@@ -131,6 +134,13 @@ func RetestFilter(failedContexts, allContexts sets.String) Filter {
 	}
 }
 
+// RetestRequiredFilter builds a filter for `/retest-required`
+func RetestRequiredFilter(failedContexts sets.String) Filter {
+	return func(p job.Presubmit) (bool, bool, bool) {
+		return p.ContextRequired() && failedContexts.Has(p.Context), false, true
+	}
+}
+
 type contextGetter func() (sets.String, sets.String, error)
 
 // PresubmitFilter creates a filter for presubmits
@@ -151,6 +161,14 @@ func PresubmitFilter(honorOkToTest bool, contextGetter contextGetter, body strin
 		}
 		filters = append(filters, RetestFilter(failedContexts, allContexts))
 	}
+	if RetestRequiredRe.MatchString(body) {
+		logger.Debug("Using retest-required filter.")
+		failedContexts, _, err := contextGetter()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, RetestRequiredFilter(failedContexts))
+	}
 	if (honorOkToTest && OkToTestRe.MatchString(body)) || TestAllRe.MatchString(body) {
 		logger.Debug("Using test-all filter.")
 		filters = append(filters, TestAllFilter())
@@ -648,6 +648,50 @@ func TestPresubmitFilter(t *testing.T) {
 			},
 			expected: [][]bool{{false, false, false}, {false, false, false}, {true, false, true}, {true, false, true}, {true, false, true}},
 		},
+		{
+			name: "retest-required command selects for errored or failed required contexts",
+			body: "/retest-required",
+			org:  "org",
+			repo: "repo",
+			ref:  "ref",
+			presubmits: []job.Presubmit{
+				{
+					Base: job.Base{
+						Name: "successful-job",
+					},
+					Reporter: job.Reporter{
+						Context: "existing-successful",
+					},
+				},
+				{
+					Base: job.Base{
+						Name: "failure-job",
+					},
+					Reporter: job.Reporter{
+						Context: "existing-failure",
+					},
+				},
+				{
+					Base: job.Base{
+						Name: "optional-error-job",
+					},
+					Optional: true,
+					Reporter: job.Reporter{
+						Context: "existing-error",
+					},
+				},
+				{
+					Base: job.Base{
+						Name: "missing-always-runs",
+					},
+					Reporter: job.Reporter{
+						Context: "missing-always-runs",
+					},
+					AlwaysRun: true,
+				},
+			},
+			expected: [][]bool{{false, false, false}, {true, false, true}, {false, false, false}, {false, false, false}},
+		},
 		{
 			name: "explicit test command filters for jobs that match",
 			body: "/test trigger",
@@ -0,0 +1,96 @@
+package jobutil
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// TargetNotFoundNote is the note added to the help message when a `/test foo` matches no job
+	TargetNotFoundNote = "The specified target(s) for `/test` were not found."
+)
+
+var (
+	// TestWithAnyNameRe matches a `/test` command with or without a job name
+	TestWithAnyNameRe = regexp.MustCompile(`(?m)^/(?:lh-)?test(?:[ \t]+\S*|[ \t]*$)`)
+	// TestWithoutNameRe matches a `/test` command without a job name
+	TestWithoutNameRe = regexp.MustCompile(`(?m)^/(?:lh-)?test[ \t]*$`)
+	// TestListRe matches a `/test ?` command listing the jobs of a pull request
+	TestListRe = regexp.MustCompile(`(?m)^/(?:lh-)?test[ \t]+\?[ \t]*$`)
+)
+
+// ShouldRespondWithHelp returns true if the comment body requires a help message listing the available jobs,
+// along with an optional note explaining why, given the number of jobs the comment matched.
+func ShouldRespondWithHelp(body string, toRunOrSkip int) (bool, string) {
+	switch {
+	case TestWithoutNameRe.MatchString(body):
+		return true, ""
+	case TestAllRe.MatchString(body):
+		return false, ""
+	case TestWithAnyNameRe.MatchString(body) && toRunOrSkip == 0:
+		return true, TargetNotFoundNote
+	}
+	return false, ""
+}
+
+// AvailablePresubmits returns the names of the presubmits that `/test all` would trigger, along with the
+// commands triggering the optional and the required presubmits which could run against the given branch.
+func AvailablePresubmits(changes job.ChangedFilesProvider, branch string, presubmits []job.Presubmit, logger *logrus.Entry) (testAllNames, optionalCommands, requiredCommands sets.String, err error) {
+	toTrigger, _, err := FilterPresubmits(TestAllFilter(), changes, branch, presubmits, logger)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	testAllNames = sets.NewString()
+	for _, presubmit := range toTrigger {
+		testAllNames.Insert(presubmit.Name)
+	}
+	optionalCommands = sets.NewString()
+	requiredCommands = sets.NewString()
+	for _, presubmit := range presubmits {
+		if !presubmit.CouldRun(branch) {
+			continue
+		}
+		if presubmit.ContextRequired() {
+			requiredCommands.Insert(presubmit.RerunCommand)
+		} else {
+			optionalCommands.Insert(presubmit.RerunCommand)
+		}
+	}
+	return testAllNames, optionalCommands, requiredCommands, nil
+}
+
+// HelpMessage returns the help message listing the commands that can be used to trigger the presubmits of a repository branch.
+func HelpMessage(org, repo, branch, note string, testAllNames, optionalCommands, requiredCommands sets.String) string {
+	if testAllNames.Len()+optionalCommands.Len()+requiredCommands.Len() == 0 {
+		return fmt.Sprintf("No presubmit jobs available for %s/%s@%s", org, repo, branch)
+	}
+	listBuilder := func(names sets.String) string {
+		var list strings.Builder
+		for _, name := range names.List() {
+			list.WriteString(fmt.Sprintf("\n* `%s`", name))
+		}
+		return list.String()
+	}
+
+	var resp strings.Builder
+	if note != "" {
+		resp.WriteString(note + "\n")
+	} else {
+		resp.WriteString("The `/test` command needs one or more targets.\n")
+	}
+	if requiredCommands.Len() > 0 {
+		resp.WriteString(fmt.Sprintf("The following commands are available to trigger required jobs:%s\n\n", listBuilder(requiredCommands)))
+	}
+	if optionalCommands.Len() > 0 {
+		resp.WriteString(fmt.Sprintf("The following commands are available to trigger optional jobs:%s\n\n", listBuilder(optionalCommands)))
+	}
+	if testAllNames.Len() > 0 {
+		resp.WriteString(fmt.Sprintf("Use `/test all` to run the following jobs that were automatically triggered:%s\n\n", listBuilder(testAllNames)))
+	}
+	return strings.TrimSuffix(resp.String(), "\n")
+}
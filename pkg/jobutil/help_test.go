@@ -0,0 +1,69 @@
+package jobutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestShouldRespondWithHelp(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		toRunOrSkip  int
+		expectedHelp bool
+		expectedNote string
+	}{
+		{
+			name:         "test without target",
+			body:         "/test",
+			expectedHelp: true,
+		},
+		{
+			name:         "prefixed test without target",
+			body:         "some text\n/lh-test  ",
+			expectedHelp: true,
+		},
+		{
+			name:         "unknown target",
+			body:         "/test foo",
+			expectedHelp: true,
+			expectedNote: TargetNotFoundNote,
+		},
+		{
+			name:        "known target",
+			body:        "/test foo",
+			toRunOrSkip: 1,
+		},
+		{
+			name: "test all without jobs",
+			body: "/test all",
+		},
+		{
+			name: "retest",
+			body: "/retest",
+		},
+		{
+			name: "not a command",
+			body: "/testing",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			help, note := ShouldRespondWithHelp(tc.body, tc.toRunOrSkip)
+			assert.Equal(t, tc.expectedHelp, help)
+			assert.Equal(t, tc.expectedNote, note)
+		})
+	}
+}
+
+func TestHelpMessage(t *testing.T) {
+	assert.Equal(t, "No presubmit jobs available for org/repo@master", HelpMessage("org", "repo", "master", "", sets.NewString(), sets.NewString(), sets.NewString()))
+
+	expected := "The specified target(s) for `/test` were not found.\n" +
+		"The following commands are available to trigger required jobs:\n* `/test a`\n* `/test b`\n\n" +
+		"The following commands are available to trigger optional jobs:\n* `/test c`\n\n" +
+		"Use `/test all` to run the following jobs that were automatically triggered:\n* `a`\n"
+	assert.Equal(t, expected, HelpMessage("org", "repo", "master", TargetNotFoundNote, sets.NewString("a"), sets.NewString("/test c"), sets.NewString("/test b", "/test a")))
+}
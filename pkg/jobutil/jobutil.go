@@ -20,6 +20,7 @@ package jobutil
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -117,9 +118,23 @@ func PresubmitSpec(p job.Presubmit, refs v1alpha1.Refs) v1alpha1.LighthouseJobSp
 	if p.JenkinsSpec != nil {
 		pjs.JenkinsSpec = &v1alpha1.JenkinsSpec{
 			BranchSourceJob: p.JenkinsSpec.BranchSourceJob,
+			Folder:          p.JenkinsSpec.Folder,
+			MultiBranch:     p.JenkinsSpec.MultiBranch,
 		}
 	}
 
+	if p.GitHubActionsSpec != nil {
+		pjs.GitHubActionsSpec = gitHubActionsSpec(p.GitHubActionsSpec)
+	}
+
+	if p.DroneSpec != nil {
+		pjs.DroneSpec = droneSpec(p.DroneSpec)
+	}
+
+	if p.BuildkiteSpec != nil {
+		pjs.BuildkiteSpec = buildkiteSpec(p.BuildkiteSpec)
+	}
+
 	return pjs
 }
 
@@ -133,9 +148,104 @@ func PostsubmitSpec(p job.Postsubmit, refs v1alpha1.Refs) v1alpha1.LighthouseJob
 	if p.JenkinsSpec != nil {
 		pjs.JenkinsSpec = &v1alpha1.JenkinsSpec{
 			BranchSourceJob: p.JenkinsSpec.BranchSourceJob,
+			Folder:          p.JenkinsSpec.Folder,
+			MultiBranch:     p.JenkinsSpec.MultiBranch,
 		}
 	}
 
+	if p.GitHubActionsSpec != nil {
+		pjs.GitHubActionsSpec = gitHubActionsSpec(p.GitHubActionsSpec)
+	}
+
+	if p.DroneSpec != nil {
+		pjs.DroneSpec = droneSpec(p.DroneSpec)
+	}
+
+	if p.BuildkiteSpec != nil {
+		pjs.BuildkiteSpec = buildkiteSpec(p.BuildkiteSpec)
+	}
+
+	return pjs
+}
+
+func gitHubActionsSpec(s *job.GitHubActionsSpec) *v1alpha1.GitHubActionsSpec {
+	spec := &v1alpha1.GitHubActionsSpec{
+		Workflow:  s.Workflow,
+		Event:     s.Event,
+		EventType: s.EventType,
+	}
+	if len(s.Inputs) > 0 {
+		spec.Inputs = make(map[string]string, len(s.Inputs))
+		for k, v := range s.Inputs {
+			spec.Inputs[k] = v
+		}
+	}
+	return spec
+}
+
+func droneSpec(s *job.DroneSpec) *v1alpha1.DroneSpec {
+	spec := &v1alpha1.DroneSpec{}
+	if len(s.Params) > 0 {
+		spec.Params = make(map[string]string, len(s.Params))
+		for k, v := range s.Params {
+			spec.Params[k] = v
+		}
+	}
+	return spec
+}
+
+func buildkiteSpec(s *job.BuildkiteSpec) *v1alpha1.BuildkiteSpec {
+	spec := &v1alpha1.BuildkiteSpec{
+		Organization: s.Organization,
+		Pipeline:     s.Pipeline,
+	}
+	if len(s.Env) > 0 {
+		spec.Env = make(map[string]string, len(s.Env))
+		for k, v := range s.Env {
+			spec.Env[k] = v
+		}
+	}
+	return spec
+}
+
+func pipelineRunOverrides(o *job.PipelineRunOverrides) *v1alpha1.PipelineRunOverrides {
+	overrides := &v1alpha1.PipelineRunOverrides{
+		ServiceAccountName: o.ServiceAccountName,
+		Timeout:            o.Timeout,
+		NodeSelector:       o.NodeSelector,
+		Tolerations:        o.Tolerations,
+		Workspaces:         o.Workspaces,
+	}
+	return overrides.DeepCopy()
+}
+
+// OpsSpec initializes a PipelineOptionsSpec for a given ops job and the refs of the branch it runs on.
+func OpsSpec(o job.Ops, refs v1alpha1.Refs) v1alpha1.LighthouseJobSpec {
+	pjs := specFromJobBase(o.Base)
+	pjs.Type = job.OpsJob
+	pjs.Context = o.Context
+	pjs.Refs = completePrimaryRefs(refs, o.Base)
+
+	if o.JenkinsSpec != nil {
+		pjs.JenkinsSpec = &v1alpha1.JenkinsSpec{
+			BranchSourceJob: o.JenkinsSpec.BranchSourceJob,
+			Folder:          o.JenkinsSpec.Folder,
+			MultiBranch:     o.JenkinsSpec.MultiBranch,
+		}
+	}
+
+	if o.GitHubActionsSpec != nil {
+		pjs.GitHubActionsSpec = gitHubActionsSpec(o.GitHubActionsSpec)
+	}
+
+	if o.DroneSpec != nil {
+		pjs.DroneSpec = droneSpec(o.DroneSpec)
+	}
+
+	if o.BuildkiteSpec != nil {
+		pjs.BuildkiteSpec = buildkiteSpec(o.BuildkiteSpec)
+	}
+
 	return pjs
 }
 
@@ -162,14 +272,38 @@ func specFromJobBase(jb job.Base) v1alpha1.LighthouseJobSpec {
 	if jb.Namespace != nil {
 		namespace = *jb.Namespace
 	}
-	return v1alpha1.LighthouseJobSpec{
-		Agent:           jb.Agent,
-		Job:             jb.Name,
-		Namespace:       namespace,
-		MaxConcurrency:  jb.MaxConcurrency,
-		PodSpec:         jb.Spec,
-		PipelineRunSpec: jb.PipelineRunSpec,
+	spec := v1alpha1.LighthouseJobSpec{
+		Agent:             jb.Agent,
+		Job:               jb.Name,
+		Namespace:         namespace,
+		MaxConcurrency:    jb.MaxConcurrency,
+		Priority:          jb.Priority,
+		PriorityClassName: jb.PriorityClassName,
+		PodSpec:           jb.Spec,
+		PipelineRunSpec:   jb.PipelineRunSpec,
+		Timeout:           jb.Timeout,
+		PendingTimeout:    jb.PendingTimeout,
+	}
+	if jb.PipelineRunOverrides != nil {
+		spec.PipelineRunOverrides = pipelineRunOverrides(jb.PipelineRunOverrides)
+	}
+	if jb.PipelineRef != nil {
+		ref := &v1alpha1.PipelineRef{Resolver: jb.PipelineRef.Resolver, Params: jb.PipelineRef.Params}
+		spec.PipelineRef = ref.DeepCopy()
+	}
+	if jb.Artifacts != nil {
+		spec.Artifacts = &v1alpha1.ArtifactsSpec{
+			Bucket: jb.Artifacts.Bucket,
+			Paths:  append([]string(nil), jb.Artifacts.Paths...),
+		}
 	}
+	if jb.EmailReport != nil {
+		spec.EmailReport = &v1alpha1.EmailReportSpec{
+			To:   append([]string(nil), jb.EmailReport.To...),
+			When: jb.EmailReport.When,
+		}
+	}
+	return spec
 }
 
 func completePrimaryRefs(refs v1alpha1.Refs, jb job.Base) *v1alpha1.Refs {
@@ -305,8 +439,12 @@ func LabelsAndAnnotationsForJob(lj v1alpha1.LighthouseJob, buildID string) (map[
 // and returns them inside channels so that they can be consumed in parallel
 // by different goroutines. Complete prowjobs are filtered out. Controller
 // loops need to handle pending jobs first so they can conform to maximum
-// concurrency requirements that different jobs may have.
+// concurrency requirements that different jobs may have. Triggered jobs are
+// ordered by priority so higher priority jobs get the available slots first.
 func PartitionActive(pjs []v1alpha1.LighthouseJob) (pending, triggered, aborted chan v1alpha1.LighthouseJob) {
+	pjs = append([]v1alpha1.LighthouseJob(nil), pjs...)
+	SortByPriority(pjs)
+
 	// Size channels correctly.
 	pendingCount, triggeredCount, abortedCount := 0, 0, 0
 	for _, pj := range pjs {
@@ -341,3 +479,14 @@ func PartitionActive(pjs []v1alpha1.LighthouseJob) (pending, triggered, aborted
 	close(aborted)
 	return pending, triggered, aborted
 }
+
+// SortByPriority sorts the jobs by decreasing priority, jobs of the same priority
+// are sorted from the oldest to the newest.
+func SortByPriority(pjs []v1alpha1.LighthouseJob) {
+	sort.SliceStable(pjs, func(i, j int) bool {
+		if pjs[i].Spec.Priority != pjs[j].Spec.Priority {
+			return pjs[i].Spec.Priority > pjs[j].Spec.Priority
+		}
+		return pjs[i].CreationTimestamp.Before(&pjs[j].CreationTimestamp)
+	})
+}
@@ -19,6 +19,7 @@ package jobutil
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -27,6 +28,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/diff"
 )
@@ -656,3 +658,34 @@ func TestPartitionActive(t *testing.T) {
 		}
 	}
 }
+
+func TestPartitionActiveOrdersTriggeredByPriority(t *testing.T) {
+	now := time.Now()
+	newJob := func(name string, priority int, age time.Duration) v1alpha1.LighthouseJob {
+		return v1alpha1.LighthouseJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Spec: v1alpha1.LighthouseJobSpec{
+				Priority: priority,
+			},
+			Status: v1alpha1.LighthouseJobStatus{
+				State: v1alpha1.TriggeredState,
+			},
+		}
+	}
+	jobs := []v1alpha1.LighthouseJob{
+		newJob("presubmit-new", 0, time.Minute),
+		newJob("presubmit-old", 0, time.Hour),
+		newJob("release", 100, time.Second),
+		newJob("batch", 10, time.Minute),
+	}
+	_, triggeredCh, _ := PartitionActive(jobs)
+	var names []string
+	for job := range triggeredCh {
+		names = append(names, job.Name)
+	}
+	assert.Equal(t, []string{"release", "batch", "presubmit-old", "presubmit-new"}, names)
+	assert.Equal(t, "presubmit-new", jobs[0].Name, "the given jobs should not be reordered")
+}
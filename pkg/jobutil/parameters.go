@@ -0,0 +1,74 @@
+package jobutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+)
+
+// commandParameterPrefix is the prefix of the command parameters, e.g. `/test e2e --provider=gke`
+const commandParameterPrefix = "--"
+
+// CommandParameters returns the parameters given to the presubmit by the lines of the comment body triggering it,
+// e.g. `/test e2e --flags=-race --provider=gke`. An error is returned if a parameter isn't allowed by the presubmit
+// or its value is invalid.
+func CommandParameters(body string, p job.Presubmit) (map[string]string, error) {
+	var parameters map[string]string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if !p.TriggerMatches(line) {
+			continue
+		}
+		var err error
+		if parameters, err = parseParameters(parameters, strings.Fields(line), p.Name, p.CommandParameter, p.CommandParameters); err != nil {
+			return nil, err
+		}
+	}
+	return parameters, nil
+}
+
+// OpsParameters returns the parameters given to the ops job by the arguments of its command,
+// e.g. `--version=1.2.0` for `/release --version=1.2.0`. An error is returned if a parameter isn't allowed by the
+// job or its value is invalid.
+func OpsParameters(args string, o job.Ops) (map[string]string, error) {
+	return parseParameters(nil, strings.Fields(args), o.Name, o.CommandParameter, o.CommandParameters)
+}
+
+// parseParameters adds the parameters among the fields of a command to the given ones, checking them against the
+// command parameters of the named job
+func parseParameters(parameters map[string]string, fields []string, jobName string, lookup func(string) *job.CommandParameter, allowed []job.CommandParameter) (map[string]string, error) {
+	for _, field := range fields {
+		if !strings.HasPrefix(field, commandParameterPrefix) {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(field, commandParameterPrefix), "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid parameter `%s` for job %s, parameters must be given as `--name=value`", field, jobName)
+		}
+		name, value := parts[0], parts[1]
+		cp := lookup(name)
+		if cp == nil {
+			return nil, fmt.Errorf("unknown parameter `%s` for job %s%s", name, jobName, allowedParametersMessage(allowed))
+		}
+		if !cp.Matches(value) {
+			return nil, fmt.Errorf("invalid value `%s` for parameter `%s` of job %s, it must match `%s`", value, name, jobName, cp.Pattern)
+		}
+		if parameters == nil {
+			parameters = map[string]string{}
+		}
+		parameters[name] = value
+	}
+	return parameters, nil
+}
+
+func allowedParametersMessage(allowed []job.CommandParameter) string {
+	if len(allowed) == 0 {
+		return ", it doesn't accept any parameter"
+	}
+	var names []string
+	for _, cp := range allowed {
+		names = append(names, "`"+cp.Name+"`")
+	}
+	return ", the allowed parameters are " + strings.Join(names, ", ")
+}
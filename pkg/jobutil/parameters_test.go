@@ -0,0 +1,88 @@
+package jobutil
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandParameters(t *testing.T) {
+	presubmit := job.Presubmit{
+		Base:         job.Base{Name: "e2e"},
+		Trigger:      `(?m)^/test (?:.*? )?e2e(?: .*?)?$`,
+		RerunCommand: "/test e2e",
+		CommandParameters: []job.CommandParameter{
+			{Name: "flags"},
+			{Name: "provider", Pattern: "gke|eks"},
+		},
+	}
+	require.NoError(t, presubmit.SetRegexes())
+
+	tests := []struct {
+		name        string
+		body        string
+		expected    map[string]string
+		expectedErr string
+	}{
+		{
+			name: "no parameters",
+			body: "/test e2e",
+		},
+		{
+			name:     "parameters",
+			body:     "/test e2e --flags=-race --provider=gke",
+			expected: map[string]string{"flags": "-race", "provider": "gke"},
+		},
+		{
+			name:     "parameters of other commands are ignored",
+			body:     "/test lint --provider=aks\n/test e2e --provider=eks",
+			expected: map[string]string{"provider": "eks"},
+		},
+		{
+			name:        "unknown parameter",
+			body:        "/test e2e --cluster=foo",
+			expectedErr: "unknown parameter `cluster` for job e2e, the allowed parameters are `flags`, `provider`",
+		},
+		{
+			name:        "invalid value",
+			body:        "/test e2e --provider=aks",
+			expectedErr: "invalid value `aks` for parameter `provider` of job e2e, it must match `gke|eks`",
+		},
+		{
+			name:        "missing value",
+			body:        "/test e2e --provider",
+			expectedErr: "invalid parameter `--provider` for job e2e, parameters must be given as `--name=value`",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parameters, err := CommandParameters(tc.body, presubmit)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, parameters)
+		})
+	}
+}
+
+func TestOpsParameters(t *testing.T) {
+	ops := job.Ops{
+		Base:              job.Base{Name: "release"},
+		CommandParameters: []job.CommandParameter{{Name: "version", Pattern: `\d+\.\d+\.\d+`}},
+	}
+
+	parameters, err := OpsParameters("--version=1.2.0 now", ops)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"version": "1.2.0"}, parameters)
+
+	parameters, err = OpsParameters("", ops)
+	require.NoError(t, err)
+	assert.Empty(t, parameters)
+
+	_, err = OpsParameters("--branch=main", ops)
+	assert.EqualError(t, err, "unknown parameter `branch` for job release, the allowed parameters are `version`")
+}
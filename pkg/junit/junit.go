@@ -0,0 +1,193 @@
+// Package junit parses junit XML test reports.
+package junit
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Suites is the root element of a junit report with multiple test suites.
+type Suites struct {
+	XMLName xml.Name `xml:"testsuites"`
+	Suites  []Suite  `xml:"testsuite"`
+}
+
+// Suite is a junit test suite.
+type Suite struct {
+	XMLName   xml.Name   `xml:"testsuite"`
+	Name      string     `xml:"name,attr"`
+	Tests     int        `xml:"tests,attr"`
+	Failures  int        `xml:"failures,attr"`
+	Errors    int        `xml:"errors,attr"`
+	Suites    []Suite    `xml:"testsuite"`
+	TestCases []TestCase `xml:"testcase"`
+}
+
+// TestCase is a single junit test case.
+type TestCase struct {
+	Name      string  `xml:"name,attr"`
+	ClassName string  `xml:"classname,attr"`
+	Time      float64 `xml:"time,attr,omitempty"`
+	Failure   *Result `xml:"failure,omitempty"`
+	Error     *Result `xml:"error,omitempty"`
+	Skipped   *Result `xml:"skipped,omitempty"`
+}
+
+// Result is the failure, error or skipped element of a test case.
+type Result struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Value   string `xml:",chardata"`
+}
+
+// Failure is a failed test case.
+type Failure struct {
+	Suite   string
+	Name    string
+	Message string
+}
+
+// Parse parses a junit report, which may either have a testsuites or a testsuite root element.
+func Parse(data []byte) ([]Suite, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, errors.New("no testsuites or testsuite element found")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse junit XML")
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "testsuites":
+			var suites Suites
+			if err := decoder.DecodeElement(&suites, &start); err != nil {
+				return nil, errors.Wrap(err, "failed to parse junit testsuites")
+			}
+			return suites.Suites, nil
+		case "testsuite":
+			var suite Suite
+			if err := decoder.DecodeElement(&suite, &start); err != nil {
+				return nil, errors.Wrap(err, "failed to parse junit testsuite")
+			}
+			return []Suite{suite}, nil
+		default:
+			return nil, errors.Errorf("unexpected junit root element %s", start.Name.Local)
+		}
+	}
+}
+
+// Failures returns the failed and errored test cases of the given suites, including nested suites.
+func Failures(suites []Suite) []Failure {
+	var failures []Failure
+	for i := range suites {
+		failures = append(failures, suiteFailures(&suites[i])...)
+	}
+	return failures
+}
+
+func suiteFailures(suite *Suite) []Failure {
+	var failures []Failure
+	for _, tc := range suite.TestCases {
+		result := tc.Failure
+		if result == nil {
+			result = tc.Error
+		}
+		if result == nil {
+			continue
+		}
+		message := strings.TrimSpace(result.Message)
+		if message == "" {
+			message = strings.TrimSpace(result.Value)
+		}
+		failures = append(failures, Failure{
+			Suite:   suite.Name,
+			Name:    tc.Name,
+			Message: message,
+		})
+	}
+	for i := range suite.Suites {
+		failures = append(failures, suiteFailures(&suite.Suites[i])...)
+	}
+	return failures
+}
+
+// ParseFailures parses a junit report and returns its failed test cases.
+func ParseFailures(data []byte) ([]Failure, error) {
+	suites, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return Failures(suites), nil
+}
+
+// MarshalFailures generates a compact junit report only containing the given failures,
+// truncating messages and dropping failures so the report fits in maxSize bytes when
+// maxSize is positive. This is handy to pass failures around in size limited places
+// such as Tekton task results.
+func MarshalFailures(failures []Failure, maxSize int) ([]byte, error) {
+	for _, messageSize := range []int{1024, 256, 64, 0} {
+		for count := len(failures); count >= 0; count-- {
+			data, err := marshalFailures(failures[:count], messageSize)
+			if err != nil {
+				return nil, err
+			}
+			if maxSize <= 0 || len(data) <= maxSize {
+				return data, nil
+			}
+			if messageSize > 0 {
+				// try shorter messages before dropping failures
+				break
+			}
+		}
+	}
+	return nil, errors.Errorf("cannot fit junit failures report in %d bytes", maxSize)
+}
+
+func marshalFailures(failures []Failure, messageSize int) ([]byte, error) {
+	suites := map[string]*Suite{}
+	var names []string
+	for _, f := range failures {
+		suite, ok := suites[f.Suite]
+		if !ok {
+			suite = &Suite{Name: f.Suite}
+			suites[f.Suite] = suite
+			names = append(names, f.Suite)
+		}
+		suite.Tests++
+		suite.Failures++
+		suite.TestCases = append(suite.TestCases, TestCase{
+			Name:    f.Name,
+			Failure: &Result{Message: Truncate(f.Message, messageSize)},
+		})
+	}
+	root := Suites{}
+	for _, name := range names {
+		root.Suites = append(root.Suites, *suites[name])
+	}
+	return xml.Marshal(root)
+}
+
+// Truncate shortens s to at most size bytes, ending it with an ellipsis if it was truncated.
+func Truncate(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	if size <= 3 {
+		return ""
+	}
+	end := size - 3
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end] + "..."
+}
@@ -0,0 +1,90 @@
+package junit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const goJUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+	<testsuite tests="3" failures="1" errors="1" name="github.com/org/repo/pkg/foo">
+		<testcase classname="foo" name="TestOK" time="0.010"></testcase>
+		<testcase classname="foo" name="TestFail" time="0.020">
+			<failure message="Failed" type="">foo_test.go:12: expected 1, got 2</failure>
+		</testcase>
+		<testcase classname="foo" name="TestPanic" time="0.000">
+			<error message="panic: boom" type=""></error>
+		</testcase>
+		<testcase classname="foo" name="TestSkip" time="0.000">
+			<skipped message="skipped"></skipped>
+		</testcase>
+	</testsuite>
+	<testsuite tests="1" failures="0" name="github.com/org/repo/pkg/bar">
+		<testcase classname="bar" name="TestBar" time="0.010"></testcase>
+	</testsuite>
+</testsuites>`
+
+const singleSuite = `<testsuite name="nested">
+	<testsuite name="inner">
+		<testcase name="test">
+			<failure>   stack trace   </failure>
+		</testcase>
+	</testsuite>
+</testsuite>`
+
+func TestParseFailures(t *testing.T) {
+	failures, err := ParseFailures([]byte(goJUnit))
+	require.NoError(t, err)
+	assert.Equal(t, []Failure{
+		{Suite: "github.com/org/repo/pkg/foo", Name: "TestFail", Message: "Failed"},
+		{Suite: "github.com/org/repo/pkg/foo", Name: "TestPanic", Message: "panic: boom"},
+	}, failures)
+
+	failures, err = ParseFailures([]byte(singleSuite))
+	require.NoError(t, err)
+	assert.Equal(t, []Failure{{Suite: "inner", Name: "test", Message: "stack trace"}}, failures)
+
+	_, err = ParseFailures([]byte("<html></html>"))
+	assert.Error(t, err)
+	_, err = ParseFailures([]byte("not xml"))
+	assert.Error(t, err)
+}
+
+func TestMarshalFailures(t *testing.T) {
+	failures := []Failure{
+		{Suite: "a", Name: "test1", Message: strings.Repeat("x", 2000)},
+		{Suite: "a", Name: "test2", Message: "short"},
+		{Suite: "b", Name: "test3", Message: "other"},
+	}
+	data, err := MarshalFailures(failures, 0)
+	require.NoError(t, err)
+	parsed, err := ParseFailures(data)
+	require.NoError(t, err)
+	require.Len(t, parsed, 3)
+	assert.Equal(t, 1024, len(parsed[0].Message))
+	assert.Equal(t, failures[1:], parsed[1:])
+
+	data, err = MarshalFailures(failures, 512)
+	require.NoError(t, err)
+	assert.True(t, len(data) <= 512)
+	parsed, err = ParseFailures(data)
+	require.NoError(t, err)
+	assert.Len(t, parsed, 3)
+
+	data, err = MarshalFailures(failures, 120)
+	require.NoError(t, err)
+	assert.True(t, len(data) <= 120)
+	parsed, err = ParseFailures(data)
+	require.NoError(t, err)
+	assert.True(t, len(parsed) < 3)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", Truncate("short", 10))
+	assert.Equal(t, "trun...", Truncate("truncated", 7))
+	assert.Equal(t, "é...", Truncate("ééé", 5))
+	assert.Equal(t, "", Truncate("anything", 2))
+}
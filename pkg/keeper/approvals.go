@@ -0,0 +1,54 @@
+package keeper
+
+import (
+	"sync"
+	"time"
+)
+
+// approvalTracker remembers when the PRs joined the pool, i.e. were approved, to measure how long they take to be
+// merged afterwards
+type approvalTracker struct {
+	lock   sync.Mutex
+	synced bool
+	joined map[string]time.Time
+}
+
+func newApprovalTracker() *approvalTracker {
+	return &approvalTracker{joined: map[string]time.Time{}}
+}
+
+// update records the time the PRs of the pool joined it, forgetting the ones which left it. The PRs already in the
+// pool at the first sync are not tracked as the time they joined it is unknown, e.g. after a restart.
+func (t *approvalTracker) update(prs map[string]PullRequest, now time.Time) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for key := range t.joined {
+		if _, ok := prs[key]; !ok {
+			delete(t.joined, key)
+		}
+	}
+	for key := range prs {
+		if _, ok := t.joined[key]; !ok {
+			if t.synced {
+				t.joined[key] = now
+			} else {
+				t.joined[key] = time.Time{}
+			}
+		}
+	}
+	t.synced = true
+}
+
+// approvedAt returns when the PR joined the pool, if known
+func (t *approvalTracker) approvedAt(pr PullRequest) (time.Time, bool) {
+	if t == nil {
+		return time.Time{}, false
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	joined := t.joined[pr.prKey()]
+	return joined, !joined.IsZero()
+}
@@ -0,0 +1,46 @@
+package keeper
+
+import (
+	"testing"
+	"time"
+
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApprovalTracker(t *testing.T) {
+	pr := func(number int) PullRequest {
+		answer := PullRequest{Number: githubql.Int(number)}
+		answer.Repository.NameWithOwner = "myorg/myrepo"
+		return answer
+	}
+	pool := func(prs ...PullRequest) map[string]PullRequest {
+		answer := map[string]PullRequest{}
+		for _, p := range prs {
+			answer[p.prKey()] = p
+		}
+		return answer
+	}
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newApprovalTracker()
+
+	tracker.update(pool(pr(1)), start)
+	_, ok := tracker.approvedAt(pr(1))
+	assert.False(t, ok, "the PRs already in the pool at the first sync joined it at an unknown time")
+
+	tracker.update(pool(pr(1), pr(2)), start.Add(time.Minute))
+	approved, ok := tracker.approvedAt(pr(2))
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(time.Minute), approved)
+
+	// the PRs leaving the pool are forgotten, e.g. when they lose their approval
+	tracker.update(pool(pr(1)), start.Add(2*time.Minute))
+	tracker.update(pool(pr(1), pr(2)), start.Add(3*time.Minute))
+	approved, ok = tracker.approvedAt(pr(2))
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(3*time.Minute), approved)
+
+	var nilTracker *approvalTracker
+	_, ok = nilTracker.approvedAt(pr(2))
+	assert.False(t, ok)
+}
@@ -4,20 +4,22 @@ import (
 	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
 	"github.com/jenkins-x/lighthouse/pkg/git"
 	"github.com/jenkins-x/lighthouse/pkg/keeper"
 	"github.com/jenkins-x/lighthouse/pkg/launcher"
+	"github.com/jenkins-x/lighthouse/pkg/notification"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/pkg/errors"
 )
 
 // NewKeeperController creates a new controller; either regular or a GitHub App flavour
-// depending on the $GITHUB_APP_SECRET_DIR environment variable
-func NewKeeperController(configAgent *config.Agent, botName string, gitKind string, gitToken string, serverURL string, maxRecordsPerPool int, historyURI string, statusURI string, ns string) (keeper.Controller, error) {
+// depending on the $GITHUB_APP_SECRET_DIR environment variable, syncing only the orgs and repos of the shard if any
+func NewKeeperController(configAgent *config.Agent, botName string, gitKind string, gitToken string, serverURL string, maxRecordsPerPool int, historyURI string, statusURI string, ns string, shard *keeper.Shard) (keeper.Controller, error) {
 	githubAppSecretDir := util.GetGitHubAppSecretDir()
 	if githubAppSecretDir != "" {
-		return NewGitHubAppKeeperController(githubAppSecretDir, configAgent, botName, gitKind, maxRecordsPerPool, historyURI, statusURI, ns)
+		return NewGitHubAppKeeperController(githubAppSecretDir, configAgent, botName, gitKind, maxRecordsPerPool, historyURI, statusURI, ns, shard)
 	}
 
 	scmClient, err := factory.NewClient(gitKind, serverURL, "")
@@ -34,11 +36,13 @@ func NewKeeperController(configAgent *config.Agent, botName string, gitKind stri
 		return []byte(gitToken)
 	})
 
-	tektonClient, _, lhClient, _, err := clients.GetAPIClients()
+	tektonClient, kubeClient, lhClient, _, err := clients.GetAPIClients()
 	if err != nil {
 		return nil, errors.Wrap(err, "Error creating kubernetes resource clients.")
 	}
-	launcherClient := launcher.NewLauncher(lhClient, ns)
-	c, err := keeper.NewController(gitproviderClient, gitproviderClient, launcherClient, tektonClient, lhClient, ns, configAgent.Config, gitClient, maxRecordsPerPool, historyURI, statusURI, nil)
+	notifier := notification.NewNotifier(nil, secret.NewResolver(secret.KubeGetter(kubeClient, ns), secret.DefaultResolverTTL))
+	configGetter := shard.Config(configAgent.Config)
+	launcherClient := launcher.NewLauncherWithConfig(lhClient, ns, configGetter)
+	c, err := keeper.NewController(gitproviderClient, gitproviderClient, launcherClient, tektonClient, lhClient, ns, configGetter, gitClient, maxRecordsPerPool, historyURI, statusURI, notifier, nil)
 	return c, err
 }
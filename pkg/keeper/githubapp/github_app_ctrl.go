@@ -11,10 +11,12 @@ import (
 	"github.com/jenkins-x/go-scm/scm/transport"
 	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
 	"github.com/jenkins-x/lighthouse/pkg/git"
 	"github.com/jenkins-x/lighthouse/pkg/keeper"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/history"
 	"github.com/jenkins-x/lighthouse/pkg/launcher"
+	"github.com/jenkins-x/lighthouse/pkg/notification"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/pkg/errors"
@@ -33,13 +35,14 @@ type gitHubAppKeeperController struct {
 	historyURI         string
 	statusURI          string
 	ns                 string
+	shard              *keeper.Shard
 	logger             *logrus.Entry
 	m                  sync.Mutex
 }
 
 // NewGitHubAppKeeperController creates a GitHub App style controller which needs to process each github owner
 // using a separate git provider client due to the way GitHub App tokens work
-func NewGitHubAppKeeperController(githubAppSecretDir string, configAgent *config.Agent, botName string, gitKind string, maxRecordsPerPool int, historyURI string, statusURI string, ns string) (keeper.Controller, error) {
+func NewGitHubAppKeeperController(githubAppSecretDir string, configAgent *config.Agent, botName string, gitKind string, maxRecordsPerPool int, historyURI string, statusURI string, ns string, shard *keeper.Shard) (keeper.Controller, error) {
 
 	gitServer := util.GithubServer
 	return &gitHubAppKeeperController{
@@ -52,6 +55,7 @@ func NewGitHubAppKeeperController(githubAppSecretDir string, configAgent *config
 		historyURI:        historyURI,
 		statusURI:         statusURI,
 		ns:                ns,
+		shard:             shard,
 		logger:            logrus.NewEntry(logrus.StandardLogger()),
 	}, nil
 
@@ -123,7 +127,7 @@ func (g *gitHubAppKeeperController) createOwnerControllers() error {
 
 	var errs *multierror.Error
 
-	cfg := g.configAgent.Config()
+	cfg := g.shard.Config(g.configAgent.Config)()
 	if cfg == nil {
 		return errors.New("no config")
 	}
@@ -169,12 +173,13 @@ func (g *gitHubAppKeeperController) createOwnerController(owner string, configGe
 	gitClient.SetCredentials(util.GitHubAppGitRemoteUsername, func() []byte {
 		return []byte(token)
 	})
-	tektonClient, _, lhClient, _, err := clients.GetAPIClients()
+	tektonClient, kubeClient, lhClient, _, err := clients.GetAPIClients()
 	if err != nil {
 		return nil, errors.Wrap(err, "Error creating kubernetes resource clients.")
 	}
-	launcherClient := launcher.NewLauncher(lhClient, g.ns)
-	c, err := keeper.NewController(gitproviderClient, gitproviderClient, launcherClient, tektonClient, lhClient, g.ns, configGetter, gitClient, g.maxRecordsPerPool, g.historyURI, g.statusURI, nil)
+	notifier := notification.NewNotifier(nil, secret.NewResolver(secret.KubeGetter(kubeClient, g.ns), secret.DefaultResolverTTL))
+	launcherClient := launcher.NewLauncherWithConfig(lhClient, g.ns, configGetter)
+	c, err := keeper.NewController(gitproviderClient, gitproviderClient, launcherClient, tektonClient, lhClient, g.ns, configGetter, gitClient, g.maxRecordsPerPool, g.historyURI, g.statusURI, notifier, nil)
 	return c, err
 }
 
@@ -0,0 +1,62 @@
+package keeper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/holds"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// hasHoldLabel tells whether the pull request is on hold
+func hasHoldLabel(pr *PullRequest) bool {
+	for _, l := range pr.Labels.Nodes {
+		if string(l.Name) == labels.Hold {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHold returns the hold recorded by the hold plugin for the pull request, nil if it is not on hold or the hold
+// has no record. An expired hold is removed along with its record, the removal being announced with a comment, and
+// nil is returned for it.
+func checkHold(log *logrus.Entry, spc scmProviderClient, pr *PullRequest, now time.Time) (*holds.Hold, error) {
+	if !hasHoldLabel(pr) {
+		return nil, nil
+	}
+	org := string(pr.Repository.Owner.Login)
+	repo := string(pr.Repository.Name)
+	number := int(pr.Number)
+	botName, err := spc.BotName()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the bot name")
+	}
+	comments, err := spc.ListPullRequestComments(org, repo, number)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the comments")
+	}
+	hold := holds.Find(comments, botName)
+	if hold == nil || !hold.Expired(now) {
+		return hold, nil
+	}
+
+	log.Infof("Removing the %q label as the hold expired at %s", labels.Hold, hold.Expiry.UTC().Format(time.RFC3339))
+	if err := spc.RemoveLabel(org, repo, number, labels.Hold, true); err != nil {
+		return nil, errors.Wrapf(err, "failed to remove the %s label", labels.Hold)
+	}
+	if err := botcomment.Sync(spc, org, repo, number, true, comments, botName, holds.Marker(), ""); err != nil {
+		return nil, errors.Wrap(err, "failed to remove the record of the hold")
+	}
+	msg := fmt.Sprintf("The hold put by @%s expired, the `%s` label was removed.", hold.By, labels.Hold)
+	if hold.Reason != "" {
+		msg = fmt.Sprintf("The hold put by @%s expired, the `%s` label was removed. It was put on hold because: %s", hold.By, labels.Hold, hold.Reason)
+	}
+	if err := spc.CreateComment(org, repo, number, true, msg); err != nil {
+		return nil, errors.Wrap(err, "failed to comment on the expiry of the hold")
+	}
+	return nil, nil
+}
@@ -0,0 +1,99 @@
+package keeper
+
+import (
+	"testing"
+	"time"
+
+	lhfake "github.com/jenkins-x/lighthouse/pkg/fake"
+	"github.com/jenkins-x/lighthouse/pkg/holds"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHold(t *testing.T) {
+	now := time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	cases := []struct {
+		name            string
+		labeled         bool
+		hold            *holds.Hold
+		expected        *holds.Hold
+		expectedRemoved bool
+	}{
+		{
+			name: "not on hold",
+			hold: &holds.Hold{By: "user", Reason: "stale record", Expiry: &past},
+		},
+		{
+			name:    "on hold without record",
+			labeled: true,
+		},
+		{
+			name:     "on hold",
+			labeled:  true,
+			hold:     &holds.Hold{By: "user", Reason: "flaky infra", Expiry: &future},
+			expected: &holds.Hold{By: "user", Reason: "flaky infra", Expiry: &future},
+		},
+		{
+			name:     "on hold without expiry",
+			labeled:  true,
+			hold:     &holds.Hold{By: "user", Reason: "flaky infra"},
+			expected: &holds.Hold{By: "user", Reason: "flaky infra"},
+		},
+		{
+			name:            "expired hold",
+			labeled:         true,
+			hold:            &holds.Hold{By: "user", Reason: "flaky infra", Expiry: &past},
+			expectedRemoved: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := lhfake.NewServer("bot")
+			defer server.Close()
+			server.AddRepository("org", "repo", "master", "base-sha")
+			number, err := server.OpenPullRequest("org/repo", "author", "a change", "master", "feature", "head-sha")
+			require.NoError(t, err)
+			spc := scmprovider.ToClient(server.Client(), "bot")
+			if tc.labeled {
+				require.NoError(t, spc.AddLabel("org", "repo", number, labels.Hold, true))
+			}
+			if tc.hold != nil {
+				require.NoError(t, spc.CreateComment("org", "repo", number, true, tc.hold.Format()))
+			}
+
+			pr := &PullRequest{Number: githubql.Int(number)}
+			pr.Repository.Name = "repo"
+			pr.Repository.Owner.Login = "org"
+			for _, l := range server.Repository("org/repo").Issues[number].Labels {
+				pr.Labels.Nodes = append(pr.Labels.Nodes, struct{ Name githubql.String }{Name: githubql.String(l)})
+			}
+
+			actual, err := checkHold(logrus.WithField("test", tc.name), spc, pr, now)
+			require.NoError(t, err)
+			if tc.expected == nil {
+				assert.Nil(t, actual)
+			} else {
+				require.NotNil(t, actual)
+				assert.Equal(t, tc.expected.Reason, actual.Reason)
+				assert.Equal(t, tc.expected.Expiry == nil, actual.Expiry == nil)
+			}
+
+			issue := server.Repository("org/repo").Issues[number]
+			assert.Equal(t, tc.labeled && !tc.expectedRemoved, len(issue.Labels) == 1)
+			if tc.expectedRemoved {
+				require.Len(t, issue.Comments, 1)
+				assert.Equal(t, "The hold put by @user expired, the `do-not-merge/hold` label was removed. It was put on hold because: flaky infra", issue.Comments[0].Body)
+			} else if tc.hold != nil {
+				assert.Len(t, issue.Comments, 1)
+			}
+		})
+	}
+}
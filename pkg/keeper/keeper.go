@@ -32,15 +32,20 @@ import (
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
 	clientset "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/lighthouse/pkg/cloudevents"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
 	"github.com/jenkins-x/lighthouse/pkg/errorutil"
 	"github.com/jenkins-x/lighthouse/pkg/git"
 	"github.com/jenkins-x/lighthouse/pkg/jobutil"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/blockers"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/history"
+	"github.com/jenkins-x/lighthouse/pkg/notification"
+	"github.com/jenkins-x/lighthouse/pkg/policy"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/inrepo"
 	"github.com/jenkins-x/lighthouse/pkg/util"
@@ -63,6 +68,8 @@ type launcher interface {
 type scmProviderClient interface {
 	CreateGraphQLStatus(string, string, string, *scmprovider.Status) (*scm.Status, error)
 	GetCombinedStatus(org, repo, ref string) (*scm.CombinedStatus, error)
+	SupportsChecks() bool
+	ListCheckRuns(org, repo, ref string) ([]*scmprovider.CheckRun, error)
 	CreateStatus(org, repo, ref string, s *scm.StatusInput) (*scm.Status, error)
 	GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error)
 	GetRef(string, string, string) (string, error)
@@ -74,8 +81,14 @@ type scmProviderClient interface {
 	GetRepositoryByFullName(string) (*scm.Repository, error)
 	ListAllPullRequestsForFullNameRepo(string, scm.PullRequestListOptions) ([]*scm.PullRequest, error)
 	CreateComment(owner, repo string, number int, isPR bool, comment string) error
+	EditComment(owner, repo string, number, id int, comment string, isPR bool) error
+	DeleteComment(owner, repo string, number, id int, isPR bool) error
+	ListPullRequestComments(owner, repo string, number int) ([]*scm.Comment, error)
+	RemoveLabel(owner, repo string, number int, label string, isPR bool) error
+	BotName() (string, error)
 	GetFile(string, string, string, string) ([]byte, error)
 	ListFiles(string, string, string, string) ([]*scm.FileEntry, error)
+	PipelinesMustSucceed(string, string) (bool, error)
 }
 
 type contextChecker interface {
@@ -85,6 +98,17 @@ type contextChecker interface {
 	MissingRequiredContexts([]string) []string
 }
 
+// pipelinesMustSucceedChecker requires all the contexts of a pull request to succeed, as GitLab does
+// when a project only allows merge requests to be merged once their pipeline succeeded.
+type pipelinesMustSucceedChecker struct {
+	contextChecker
+}
+
+// IsOptional always returns false as a single failed status fails the GitLab pipeline
+func (pipelinesMustSucceedChecker) IsOptional(string) bool {
+	return false
+}
+
 // DefaultController knows how to sync PRs and PJs.
 type DefaultController struct {
 	logger         *logrus.Entry
@@ -94,6 +118,8 @@ type DefaultController struct {
 	gc             git.Client
 	tektonClient   tektonclient.Interface
 	lhClient       clientset.Interface
+	notifier       *notification.Notifier
+	eventEmitter   *cloudevents.Emitter
 	ns             string
 
 	sc *statusController
@@ -104,6 +130,12 @@ type DefaultController struct {
 	// changedFiles caches the names of files changed by PRs.
 	// Cache entries expire if they are not used during a sync loop.
 	changedFiles *changedFilesAgent
+	// inRepoCache caches the in-repo configuration contents at commit SHAs.
+	inRepoCache *inrepo.ContentCache
+	// approvals remembers when the PRs joined the pool to measure the time they take to be merged
+	approvals *approvalTracker
+	// policies evaluates the policies authorizing the merges
+	policies *policy.Evaluator
 
 	History *history.History
 }
@@ -167,12 +199,15 @@ type prWithStatus struct {
 var (
 	keeperMetrics = struct {
 		// Per pool
-		pooledPRs  *prometheus.GaugeVec
-		updateTime *prometheus.GaugeVec
-		merges     *prometheus.HistogramVec
+		pooledPRs   *prometheus.GaugeVec
+		updateTime  *prometheus.GaugeVec
+		merges      *prometheus.HistogramVec
+		poolPRs     *prometheus.GaugeVec
+		timeToMerge *prometheus.HistogramVec
 
 		// Singleton
 		syncDuration         prometheus.Gauge
+		syncDurationSeconds  prometheus.Histogram
 		statusUpdateDuration prometheus.Gauge
 	}{
 		pooledPRs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
@@ -202,11 +237,36 @@ var (
 			"branch",
 		}),
 
+		poolPRs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "lighthouse_keeper_pool_prs",
+			Help: "Number of PRs in each Keeper pool by state, success, pending or missing.",
+		}, []string{
+			"org",
+			"repo",
+			"branch",
+			"state",
+		}),
+
+		timeToMerge: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "lighthouse_slo_time_to_merge_seconds",
+			Help:    "Histogram of the durations between the PRs joining the Keeper pool, i.e. being approved, and their merge, by org and repo.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 16),
+		}, []string{
+			"org",
+			"repo",
+		}),
+
 		syncDuration: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: "syncdur",
 			Help: "The duration of the last loop of the sync controller.",
 		}),
 
+		syncDurationSeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "lighthouse_keeper_sync_duration_seconds",
+			Help:    "Histogram of the durations of the loops of the sync controller.",
+			Buckets: []float64{1, 5, 10, 30, 60, 120, 300, 600},
+		}),
+
 		statusUpdateDuration: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: "statusupdatedur",
 			Help: "The duration of the last loop of the status update controller.",
@@ -218,12 +278,15 @@ func init() {
 	prometheus.MustRegister(keeperMetrics.pooledPRs)
 	prometheus.MustRegister(keeperMetrics.updateTime)
 	prometheus.MustRegister(keeperMetrics.merges)
+	prometheus.MustRegister(keeperMetrics.poolPRs)
+	prometheus.MustRegister(keeperMetrics.timeToMerge)
 	prometheus.MustRegister(keeperMetrics.syncDuration)
+	prometheus.MustRegister(keeperMetrics.syncDurationSeconds)
 	prometheus.MustRegister(keeperMetrics.statusUpdateDuration)
 }
 
 // NewController makes a DefaultController out of the given clients.
-func NewController(spcSync, spcStatus *scmprovider.Client, launcherClient launcher, tektonClient tektonclient.Interface, lighthouseClient clientset.Interface, ns string, cfg config.Getter, gc git.Client, maxRecordsPerPool int, historyURI, statusURI string, logger *logrus.Entry) (*DefaultController, error) {
+func NewController(spcSync, spcStatus *scmprovider.Client, launcherClient launcher, tektonClient tektonclient.Interface, lighthouseClient clientset.Interface, ns string, cfg config.Getter, gc git.Client, maxRecordsPerPool int, historyURI, statusURI string, notifier *notification.Notifier, logger *logrus.Entry) (*DefaultController, error) {
 	if logger == nil {
 		logger = logrus.NewEntry(logrus.StandardLogger())
 	}
@@ -246,6 +309,8 @@ func NewController(spcSync, spcStatus *scmprovider.Client, launcherClient launch
 		launcherClient: launcherClient,
 		tektonClient:   tektonClient,
 		lhClient:       lighthouseClient,
+		notifier:       notifier,
+		eventEmitter:   cloudevents.NewEmitter(logger),
 		ns:             ns,
 		config:         cfg,
 		gc:             gc,
@@ -254,7 +319,10 @@ func NewController(spcSync, spcStatus *scmprovider.Client, launcherClient launch
 			spc:             spcSync,
 			nextChangeCache: make(map[changeCacheKey][]string),
 		},
-		History: hist,
+		inRepoCache: inrepo.NewContentCache(inrepo.DefaultContentCacheSize),
+		approvals:   newApprovalTracker(),
+		policies:    policy.NewEvaluator(logger),
+		History:     hist,
 	}, nil
 }
 
@@ -304,6 +372,7 @@ func (c *DefaultController) Sync() error {
 		duration := time.Since(start)
 		c.logger.WithField("duration", duration.String()).Info("Synced")
 		keeperMetrics.syncDuration.Set(duration.Seconds())
+		keeperMetrics.syncDurationSeconds.Observe(duration.Seconds())
 	}()
 	defer c.changedFiles.prune()
 
@@ -340,6 +409,7 @@ func (c *DefaultController) Sync() error {
 	c.logger.WithField(
 		"duration", time.Since(start).String(),
 	).Debugf("Found %d (unfiltered) pool PRs.", len(prs))
+	c.approvals.update(prs, time.Now())
 
 	var lhjs []v1alpha1.LighthouseJob
 	var blocks blockers.Blockers
@@ -582,10 +652,23 @@ func (c *DefaultController) initSubpoolData(sp *subpool) error {
 	if err != nil {
 		return fmt.Errorf("error determining required presubmit PipelineActivitys: %v", err)
 	}
-	sp.cc, err = c.config().GetKeeperContextPolicy(sp.org, sp.repo, sp.branch)
+	cfg, err := c.subpoolConfig(sp)
+	if err != nil {
+		return err
+	}
+	sp.cc, err = cfg.GetKeeperContextPolicy(sp.org, sp.repo, sp.branch)
 	if err != nil {
 		return fmt.Errorf("error setting up context checker: %v", err)
 	}
+	if c.spc.ProviderType() == "gitlab" && !cfg.GitLabOptions.IgnorePipelinesMustSucceed {
+		mustSucceed, err := c.spc.PipelinesMustSucceed(sp.org, sp.repo)
+		if err != nil {
+			return fmt.Errorf("error checking if the pipelines of %s/%s must succeed: %v", sp.org, sp.repo, err)
+		}
+		if mustSucceed {
+			sp.cc = pipelinesMustSucceedChecker{sp.cc}
+		}
+	}
 	return nil
 }
 
@@ -662,7 +745,7 @@ const (
 )
 
 func toSimpleState(s v1alpha1.PipelineState) simpleState {
-	if s == v1alpha1.TriggeredState || s == v1alpha1.PendingState || s == v1alpha1.RunningState {
+	if s == v1alpha1.TriggeredState || s == v1alpha1.QueuedState || s == v1alpha1.PendingState || s == v1alpha1.RunningState {
 		return pendingState
 	} else if s == v1alpha1.SuccessState {
 		return successState
@@ -1013,15 +1096,19 @@ func (c *DefaultController) mergePRs(sp subpool, prs []PullRequest) error {
 		keeperMetrics.merges.WithLabelValues(sp.org, sp.repo, sp.branch).Observe(float64(len(merged)))
 	}()
 
+	cfg, err := c.subpoolConfig(&sp)
+	if err != nil {
+		return err
+	}
 	var errs []error
 	log := sp.log.WithField("merge-targets", prNumbers(prs))
 	for i, pr := range prs {
 		log := log.WithFields(pr.logFields())
-		mergeMethod := c.config().Keeper.MergeMethod(sp.org, sp.repo)
-		commitTemplates := c.config().Keeper.MergeCommitTemplate(sp.org, sp.repo)
-		squashLabel := c.config().Keeper.SquashLabel
-		rebaseLabel := c.config().Keeper.RebaseLabel
-		mergeLabel := c.config().Keeper.MergeLabel
+		mergeMethod := cfg.Keeper.MergeMethod(sp.org, sp.repo)
+		commitTemplates := cfg.Keeper.MergeCommitTemplate(sp.org, sp.repo)
+		squashLabel := cfg.Keeper.SquashLabel
+		rebaseLabel := cfg.Keeper.RebaseLabel
+		mergeLabel := cfg.Keeper.MergeLabel
 		if squashLabel != "" || rebaseLabel != "" || mergeLabel != "" {
 			var err error
 			mergeMethod, err = checkMergeLabels(pr, squashLabel, rebaseLabel, mergeLabel, mergeMethod)
@@ -1033,6 +1120,13 @@ func (c *DefaultController) mergePRs(sp subpool, prs []PullRequest) error {
 				continue
 			}
 		}
+		if err := c.authorizeMerge(cfg, sp, pr); err != nil {
+			log.WithError(err).Error("Merge failed.")
+			errs = append(errs, err)
+			failed = append(failed, int(pr.Number))
+			failedPRs = append(failedPRs, pr)
+			continue
+		}
 
 		keepTrying, err := tryMerge(func() error {
 			ghMergeDetails := c.prepareMergeDetails(commitTemplates, pr, mergeMethod)
@@ -1046,6 +1140,11 @@ func (c *DefaultController) mergePRs(sp subpool, prs []PullRequest) error {
 		} else {
 			log.Info("Merged.")
 			merged = append(merged, int(pr.Number))
+			if approved, ok := c.approvals.approvedAt(pr); ok {
+				keeperMetrics.timeToMerge.WithLabelValues(sp.org, sp.repo).Observe(time.Since(approved).Seconds())
+			}
+			c.notifyMerged(cfg, sp, pr)
+			c.emitMerged(cfg, sp, pr, mergeMethod)
 		}
 		if !keepTrying {
 			break
@@ -1072,6 +1171,44 @@ func (c *DefaultController) mergePRs(sp subpool, prs []PullRequest) error {
 	return finalErr
 }
 
+// notifyMerged posts the merge of the pull request to the chat notifications
+func (c *DefaultController) notifyMerged(cfg *config.Config, sp subpool, pr PullRequest) {
+	if len(cfg.ChatNotifications) == 0 {
+		return
+	}
+	event := &notification.Event{
+		Kind:   lighthouse.ChatEventMerged,
+		Org:    sp.org,
+		Repo:   sp.repo,
+		Number: int(pr.Number),
+		Title:  string(pr.Title),
+		Author: string(pr.Author.Login),
+	}
+	if err := c.notifier.Notify(cfg.ChatNotifications, event); err != nil {
+		sp.log.WithFields(pr.logFields()).WithError(err).Warn("failed to post the merge to the chat notifications")
+	}
+}
+
+// emitMerged emits the CloudEvent of the merge of the pull request
+func (c *DefaultController) emitMerged(cfg *config.Config, sp subpool, pr PullRequest, mergeMethod keeper.PullRequestMergeType) {
+	if cfg.CloudEvents.Sink == "" || c.eventEmitter == nil {
+		return
+	}
+	event := cloudevents.MergedEvent(&cloudevents.MergePayload{
+		Org:         sp.org,
+		Repo:        sp.repo,
+		Number:      int(pr.Number),
+		Title:       string(pr.Title),
+		Author:      string(pr.Author.Login),
+		SHA:         string(pr.HeadRefOID),
+		BaseRef:     sp.branch,
+		MergeMethod: string(mergeMethod),
+	}, time.Now())
+	if err := c.eventEmitter.Emit(&cfg.CloudEvents, event); err != nil {
+		sp.log.WithFields(pr.logFields()).WithError(err).Warn("failed to emit the merge event")
+	}
+}
+
 func rollupMergeErrors(prs []PullRequest, failed []int, merged []int, errs []error) error {
 	// Construct a more informative error.
 	var batch string
@@ -1164,6 +1301,17 @@ func tryMerge(mergeFunc func() error) (bool, error) {
 	return true, err
 }
 
+// applyPriority raises the priority of a job triggered by keeper to the configured keeper priority.
+func (c *DefaultController) applyPriority(spec *v1alpha1.LighthouseJobSpec) {
+	cfg := c.config().Keeper
+	if cfg.Priority > spec.Priority {
+		spec.Priority = cfg.Priority
+	}
+	if cfg.PriorityClassName != "" {
+		spec.PriorityClassName = cfg.PriorityClassName
+	}
+}
+
 func (c *DefaultController) trigger(sp subpool, presubmits map[int][]job.Presubmit, prs []PullRequest) error {
 	refs := v1alpha1.Refs{
 		Org:      sp.org,
@@ -1200,9 +1348,14 @@ func (c *DefaultController) trigger(sp subpool, presubmits map[int][]job.Presubm
 			} else {
 				spec = jobutil.BatchSpec(ps, refs)
 			}
+			c.applyPriority(&spec)
 			pj := jobutil.NewLighthouseJob(spec, ps.Labels, ps.Annotations)
 			start := time.Now()
-			if _, err := c.launcherClient.Launch(&pj); err != nil {
+			launched, err := c.launcherClient.Launch(&pj)
+			if err != nil || launched == nil || launched.Name == pj.Name {
+				audit.Record(nil, audit.JobEvent(audit.JobCreated, &pj), err)
+			}
+			if err != nil {
 				c.logger.WithField("duration", time.Since(start).String()).Debug("Failed to create pipeline on the cluster.")
 				return fmt.Errorf("failed to create a pipeline for job: %q, PRs: %v: %v", spec.Job, prNumbers(prs), err)
 			}
@@ -1216,6 +1369,9 @@ func (c *DefaultController) trigger(sp subpool, presubmits map[int][]job.Presubm
 				Label: spec.Context,
 				Desc:  util.CommitStatusPendingDescription,
 			}
+			if launched != nil && launched.Status.State == v1alpha1.QueuedState {
+				statusInput.Desc = launched.Status.Description
+			}
 			if _, err := c.spc.CreateStatus(refs.Org, refs.Repo, sha, statusInput); err != nil {
 				c.logger.WithField("duration", time.Since(start).String()).Debug("Failed to set pending status on triggered context.")
 				return errors.Wrapf(err, "Cannot update PR status on org %s repo %s sha %s for context %s", refs.Org, refs.Repo, sha, statusInput.Label)
@@ -1333,6 +1489,19 @@ func (c *changedFilesAgent) prune() {
 	c.nextChangeCache = make(map[changeCacheKey][]string)
 }
 
+// subpoolConfig returns the configuration of the subpool's repository, including any in-repo configuration
+// at the subpool's base SHA.
+func (c *DefaultController) subpoolConfig(sp *subpool) (*config.Config, error) {
+	if sp.config == nil {
+		cfg, _, err := inrepo.Generate(c.spc, c.inRepoCache, c.config(), nil, sp.org, sp.repo, sp.sha)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to calculate in repo config")
+		}
+		sp.config = cfg
+	}
+	return sp.config, nil
+}
+
 func (c *DefaultController) presubmitsByPull(sp *subpool) (map[int][]job.Presubmit, error) {
 	presubmits := make(map[int][]job.Presubmit, len(sp.prs))
 	record := func(num int, j job.Presubmit) {
@@ -1344,15 +1513,12 @@ func (c *DefaultController) presubmitsByPull(sp *subpool) (map[int][]job.Presubm
 	}
 
 	// lets get the in repo config for the repo
-	owner := sp.org
-	repo := sp.repo
-	sharedConfig := c.config()
-	cfg, _, err := inrepo.Generate(c.spc, sharedConfig, nil, owner, repo, "")
+	cfg, err := c.subpoolConfig(sp)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to calculate in repo config")
+		return nil, err
 	}
 
-	for _, ps := range cfg.Presubmits[owner+"/"+repo] {
+	for _, ps := range cfg.RepoPresubmits(sp.org + "/" + sp.repo) {
 		if !ps.ContextRequired() {
 			continue
 		}
@@ -1426,6 +1592,9 @@ func (c *DefaultController) syncSubpool(sp subpool, blocks []blockers.Blocker) (
 		"targets": prNumbers(targets),
 	}).Info("Subpool synced.")
 	keeperMetrics.pooledPRs.WithLabelValues(sp.org, sp.repo, sp.branch).Set(float64(len(sp.prs)))
+	keeperMetrics.poolPRs.WithLabelValues(sp.org, sp.repo, sp.branch, "success").Set(float64(len(successes)))
+	keeperMetrics.poolPRs.WithLabelValues(sp.org, sp.repo, sp.branch, "pending").Set(float64(len(pendings)))
+	keeperMetrics.poolPRs.WithLabelValues(sp.org, sp.repo, sp.branch, "missing").Set(float64(len(missings)))
 	keeperMetrics.updateTime.WithLabelValues(sp.org, sp.repo, sp.branch).Set(float64(time.Now().Unix()))
 	return Pool{
 			Org:    sp.org,
@@ -1496,6 +1665,8 @@ type subpool struct {
 	prs []PullRequest
 
 	cc contextChecker
+	// config is the configuration of the repository, including any in-repo configuration
+	config *config.Config
 	// presubmit contains all required presubmits for each PR
 	// in this subpool
 	presubmits map[int][]job.Presubmit
@@ -1612,9 +1783,23 @@ type Commit struct {
 	Status struct {
 		Contexts []Context
 	}
+	CheckSuites struct {
+		Nodes []struct {
+			CheckRuns struct {
+				Nodes []CheckRun
+			} `graphql:"checkRuns(first: 50, filterBy: {checkType: LATEST})"`
+		}
+	} `graphql:"checkSuites(first: 20)"`
 	OID githubql.String `graphql:"oid"`
 }
 
+// CheckRun holds graphql response data for github check runs.
+type CheckRun struct {
+	Name       githubql.String
+	Status     githubql.String
+	Conclusion githubql.String
+}
+
 // Context holds graphql response data for github contexts.
 type Context struct {
 	Context     githubql.String
@@ -1650,7 +1835,8 @@ func (pr *PullRequest) logFields() logrus.Fields {
 	}
 }
 
-// headContexts gets the status contexts for the commit with OID == pr.HeadRefOID
+// headContexts gets the status contexts for the commit with OID == pr.HeadRefOID, along with its check runs, e.g.
+// reported by foghorn instead of a status when the check runs are enabled for the repository
 //
 // First, we try to get this value from the commits we got with the PR query.
 // Unfortunately the 'last' commit ordering is determined by author date
@@ -1662,7 +1848,11 @@ func (pr *PullRequest) logFields() logrus.Fields {
 func headContexts(log *logrus.Entry, spc scmProviderClient, pr *PullRequest) ([]Context, error) {
 	for _, node := range pr.Commits.Nodes {
 		if node.Commit.OID == pr.HeadRefOID {
-			return node.Commit.Status.Contexts, nil
+			var checkRuns []CheckRun
+			for _, suite := range node.Commit.CheckSuites.Nodes {
+				checkRuns = append(checkRuns, suite.CheckRuns.Nodes...)
+			}
+			return withCheckRuns(node.Commit.Status.Contexts, checkRuns), nil
 		}
 	}
 	// We didn't get the head commit from the query (the commits must not be
@@ -1687,6 +1877,21 @@ func headContexts(log *logrus.Entry, spc scmProviderClient, pr *PullRequest) ([]
 			},
 		)
 	}
+	if spc.SupportsChecks() {
+		checkRuns, err := spc.ListCheckRuns(org, repo, string(pr.HeadRefOID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the check runs: %v", err)
+		}
+		var runs []CheckRun
+		for _, checkRun := range checkRuns {
+			runs = append(runs, CheckRun{
+				Name:       githubql.String(checkRun.Name),
+				Status:     githubql.String(checkRun.Status),
+				Conclusion: githubql.String(checkRun.Conclusion),
+			})
+		}
+		contexts = withCheckRuns(contexts, runs)
+	}
 	// Add a commit with these contexts to pr for future look ups.
 	pr.Commits.Nodes = append(pr.Commits.Nodes,
 		struct{ Commit Commit }{
@@ -1699,6 +1904,43 @@ func headContexts(log *logrus.Entry, spc scmProviderClient, pr *PullRequest) ([]
 	return contexts, nil
 }
 
+// withCheckRuns appends the check runs to the status contexts, as contexts named after the check runs. A status
+// context takes precedence over a check run of the same name.
+func withCheckRuns(contexts []Context, checkRuns []CheckRun) []Context {
+	if len(checkRuns) == 0 {
+		return contexts
+	}
+	answer := append([]Context(nil), contexts...)
+	seen := sets.NewString()
+	for _, ctx := range contexts {
+		seen.Insert(string(ctx.Context))
+	}
+	for _, checkRun := range checkRuns {
+		name := string(checkRun.Name)
+		if seen.Has(name) {
+			continue
+		}
+		seen.Insert(name)
+		answer = append(answer, Context{
+			Context: checkRun.Name,
+			State:   checkRunState(checkRun),
+		})
+	}
+	return answer
+}
+
+// checkRunState converts the status and conclusion of a check run to the state of a status context
+func checkRunState(checkRun CheckRun) githubql.StatusState {
+	if !strings.EqualFold(string(checkRun.Status), scmprovider.CheckRunCompleted) {
+		return githubql.StatusStatePending
+	}
+	switch strings.ToLower(string(checkRun.Conclusion)) {
+	case scmprovider.CheckRunSuccess, "neutral", "skipped":
+		return githubql.StatusStateSuccess
+	}
+	return githubql.StatusStateFailure
+}
+
 func orgRepoQueryString(orgs, repos []string, orgExceptions map[string]sets.String) string {
 	toks := make([]string, 0, len(orgs))
 	for _, o := range orgs {
@@ -510,7 +510,10 @@ type fgc struct {
 	expectedSHA    string
 	ignoreExpected bool
 	combinedStatus map[string]map[string]commitStatus
+	checkRuns      map[string][]*scmprovider.CheckRun
 	fakeClient     *scm.Client
+	providerType   string
+	mustSucceed    bool
 }
 
 type commitStatus struct {
@@ -530,9 +533,16 @@ func (f *fgc) SupportsGraphQL() bool {
 }
 
 func (f *fgc) ProviderType() string {
+	if f.providerType != "" {
+		return f.providerType
+	}
 	return "fake"
 }
 
+func (f *fgc) PipelinesMustSucceed(org, repo string) (bool, error) {
+	return f.mustSucceed, nil
+}
+
 func (f *fgc) PRRefFmt() string {
 	return "refs/pull/%d/head"
 }
@@ -573,6 +583,26 @@ func (f *fgc) CreateComment(org, repo string, number int, isPR bool, commentBody
 	return nil
 }
 
+func (f *fgc) EditComment(org, repo string, number, id int, comment string, isPR bool) error {
+	return scm.ErrNotSupported
+}
+
+func (f *fgc) DeleteComment(org, repo string, number, id int, isPR bool) error {
+	return scm.ErrNotSupported
+}
+
+func (f *fgc) ListPullRequestComments(org, repo string, number int) ([]*scm.Comment, error) {
+	return nil, nil
+}
+
+func (f *fgc) RemoveLabel(org, repo string, number int, label string, isPR bool) error {
+	return scm.ErrNotSupported
+}
+
+func (f *fgc) BotName() (string, error) {
+	return "bot", nil
+}
+
 func (f *fgc) Merge(org, repo string, number int, details scmprovider.MergeDetails) error {
 	if err, ok := f.mergeErrs[number]; ok {
 		return err
@@ -608,6 +638,17 @@ func (f *fgc) GetCombinedStatus(org, repo, ref string) (*scm.CombinedStatus, err
 		nil
 }
 
+func (f *fgc) SupportsChecks() bool {
+	return f.checkRuns != nil
+}
+
+func (f *fgc) ListCheckRuns(org, repo, ref string) ([]*scmprovider.CheckRun, error) {
+	if !f.ignoreExpected && f.expectedSHA != ref {
+		return nil, errors.New("bad check runs request: incorrect sha")
+	}
+	return f.checkRuns[ref], nil
+}
+
 func (f *fgc) CreateStatus(org, repo, ref string, s *scm.StatusInput) (*scm.Status, error) {
 	if s.Label == "fail-create" {
 		return nil, errors.New("injected CreateStatus failure")
@@ -2121,6 +2162,8 @@ func TestIsPassing(t *testing.T) {
 		passing          bool
 		config           keeper.ContextPolicy
 		combinedContexts map[string]commitStatus
+		// mustSucceed is true if the GitLab pipelines must succeed
+		mustSucceed bool
 	}{
 		{
 			name:             "empty policy - success (trust combined status)",
@@ -2208,6 +2251,35 @@ func TestIsPassing(t *testing.T) {
 				SkipUnknownContexts: &yes,
 			},
 		},
+		{
+			name:             "pipelines must succeed - failing because c4 is failing even if optional",
+			passing:          false,
+			combinedContexts: map[string]commitStatus{"c1": toCommitStatus(success, ""), "c4": toCommitStatus(failure, ""), statusContext: toCommitStatus(failure, "")},
+			config: keeper.ContextPolicy{
+				RequiredContexts: []string{"c1"},
+				OptionalContexts: []string{"c4"},
+			},
+			mustSucceed: true,
+		},
+		{
+			name:             "pipelines must succeed - failing because of missing required check c2",
+			passing:          false,
+			combinedContexts: map[string]commitStatus{"c1": toCommitStatus(success, "")},
+			config: keeper.ContextPolicy{
+				RequiredContexts: []string{"c1", "c2"},
+			},
+			mustSucceed: true,
+		},
+		{
+			name:             "pipelines must succeed - passing",
+			passing:          true,
+			combinedContexts: map[string]commitStatus{"c1": toCommitStatus(success, ""), "c4": toCommitStatus(success, ""), statusContext: toCommitStatus(failure, "")},
+			config: keeper.ContextPolicy{
+				RequiredContexts: []string{"c1"},
+				OptionalContexts: []string{"c4"},
+			},
+			mustSucceed: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -2221,13 +2293,87 @@ func TestIsPassing(t *testing.T) {
 			t.FailNow()
 		}
 		pr := PullRequest{HeadRefOID: githubql.String(headSHA)}
-		passing := isPassingTests(log, ghc, pr, &tc.config)
+		var cc contextChecker = &tc.config
+		if tc.mustSucceed {
+			cc = pipelinesMustSucceedChecker{cc}
+		}
+		passing := isPassingTests(log, ghc, pr, cc)
 		if passing != tc.passing {
 			t.Errorf("%s: Expected %t got %t", tc.name, tc.passing, passing)
 		}
 	}
 }
 
+func TestIsPassingCheckRuns(t *testing.T) {
+	headSHA := "head"
+	success := string(githubql.StatusStateSuccess)
+	policy := keeper.ContextPolicy{RequiredContexts: []string{"c1", "c2"}}
+	testCases := []struct {
+		name      string
+		passing   bool
+		checkRuns []*scmprovider.CheckRun
+	}{
+		{
+			name:    "passing because the required context c2 is a successful check run",
+			passing: true,
+			checkRuns: []*scmprovider.CheckRun{
+				{Name: "c2", Status: scmprovider.CheckRunCompleted, Conclusion: scmprovider.CheckRunSuccess},
+			},
+		},
+		{
+			name:    "failing because the check run c2 is still running",
+			passing: false,
+			checkRuns: []*scmprovider.CheckRun{
+				{Name: "c2", Status: scmprovider.CheckRunInProgress},
+			},
+		},
+		{
+			name:    "failing because the check run c2 failed",
+			passing: false,
+			checkRuns: []*scmprovider.CheckRun{
+				{Name: "c2", Status: scmprovider.CheckRunCompleted, Conclusion: scmprovider.CheckRunFailure},
+			},
+		},
+		{
+			name:    "failing because of the missing check run c2",
+			passing: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		// the head commit is not among the commits of the query, so the statuses and check runs are listed
+		ghc := &fgc{
+			combinedStatus: map[string]map[string]commitStatus{headSHA: {"c1": toCommitStatus(success, "")}},
+			checkRuns:      map[string][]*scmprovider.CheckRun{headSHA: tc.checkRuns},
+			expectedSHA:    headSHA,
+		}
+		pr := PullRequest{HeadRefOID: githubql.String(headSHA)}
+		passing := isPassingTests(logrus.WithField("component", "keeper"), ghc, pr, &policy)
+		assert.Equal(t, tc.passing, passing, "%s: listed check runs", tc.name)
+
+		// the head commit and its check suites are returned by the query
+		commit := Commit{OID: githubql.String(headSHA)}
+		commit.Status.Contexts = []Context{{Context: "c1", State: githubql.StatusStateSuccess}}
+		var nodes []CheckRun
+		for _, checkRun := range tc.checkRuns {
+			nodes = append(nodes, CheckRun{
+				Name:       githubql.String(checkRun.Name),
+				Status:     githubql.String(strings.ToUpper(checkRun.Status)),
+				Conclusion: githubql.String(strings.ToUpper(checkRun.Conclusion)),
+			})
+		}
+		commit.CheckSuites.Nodes = make([]struct {
+			CheckRuns struct {
+				Nodes []CheckRun
+			} `graphql:"checkRuns(first: 50, filterBy: {checkType: LATEST})"`
+		}, 1)
+		commit.CheckSuites.Nodes[0].CheckRuns.Nodes = nodes
+		pr.Commits.Nodes = append(pr.Commits.Nodes, struct{ Commit Commit }{commit})
+		passing = isPassingTests(logrus.WithField("component", "keeper"), &fgc{}, pr, &policy)
+		assert.Equal(t, tc.passing, passing, "%s: queried check runs", tc.name)
+	}
+}
+
 func TestPresubmitsByPull(t *testing.T) {
 	samplePR := PullRequest{
 		Number:     githubql.Int(100),
@@ -0,0 +1,47 @@
+package keeper
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/policy"
+)
+
+// authorizeMerge evaluates the policies for the merge of the pull request if they are enabled for its repository,
+// returning an error holding the reason of the denial if the merge is not allowed
+func (c *DefaultController) authorizeMerge(cfg *config.Config, sp subpool, pr PullRequest) error {
+	if c.policies == nil || !cfg.Policy.EnabledFor(sp.org, sp.repo) {
+		return nil
+	}
+	input := &policy.Input{
+		Action: policy.ActionMerge,
+		Actor:  string(pr.Author.Login),
+		Teams:  []string{},
+		Org:    sp.org,
+		Repo:   sp.repo,
+		Number: int(pr.Number),
+		IsPR:   true,
+		Branch: sp.branch,
+		Labels: []string{},
+	}
+	// the teams are only listed by the SCM clients supporting them
+	if tl, ok := c.spc.(policy.TeamLister); ok {
+		teams, err := policy.Teams(tl, sp.org, input.Actor)
+		if err != nil {
+			sp.log.WithFields(pr.logFields()).WithError(err).Warn("failed to list the teams of the author of the pull request")
+		} else {
+			input.Teams = teams
+		}
+	}
+	for _, label := range pr.Labels.Nodes {
+		input.Labels = append(input.Labels, string(label.Name))
+	}
+	decision := c.policies.Evaluate(cfg.Policy, input)
+	if decision.Allow {
+		return nil
+	}
+	if decision.Reason == "" {
+		return fmt.Errorf("the merge is denied by the policies")
+	}
+	return fmt.Errorf("the merge is denied by the policies: %s", decision.Reason)
+}
@@ -0,0 +1,243 @@
+package keeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// MergedPoolsPath is the path the pools of all the shards are served on
+const MergedPoolsPath = "/pools"
+
+// Shard selects the orgs and repos synced by a keeper instance so that the orgs and repos of the very large
+// installations are split among several instances, each one syncing them within the sync period. A nil Shard syncs
+// all the orgs and repos.
+type Shard struct {
+	// Name is the name of the shard the orgs and repos are assigned to in the `shards` of the keeper config. A shard
+	// without a name and count syncs all the orgs and repos which are not assigned to a shard.
+	Name string
+	// Index is the index of the shard among the Count shards the orgs not assigned to a shard are split among by hash
+	Index int
+	// Count is the number of shards the orgs not assigned to a shard are split among by hash, if positive
+	Count int
+}
+
+// ID returns the ID of the shard, unique among the shards of an installation
+func (s *Shard) ID() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return fmt.Sprintf("%d-of-%d", s.Index, s.Count)
+}
+
+// Validate validates the shard
+func (s *Shard) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.Count < 0 || (s.Count > 0 && (s.Index < 0 || s.Index >= s.Count)) {
+		return errors.Errorf("the shard index %d is not in [0, %d)", s.Index, s.Count)
+	}
+	if s.Name == "" && s.Count == 0 {
+		return errors.New("a shard requires a name or a count")
+	}
+	if errs := validation.IsDNS1123Label(s.Name); s.Name != "" && len(errs) > 0 {
+		return errors.Errorf("invalid shard name %s: %s", s.Name, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Owns returns true if the shard syncs the repo, or the whole org if repo is empty
+func (s *Shard) Owns(kc *keeper.Config, org, repo string) bool {
+	if s == nil {
+		return true
+	}
+	if shard, ok := kc.AssignedShard(org, repo); ok {
+		return shard == s.Name
+	}
+	if s.Count > 0 {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(strings.ToLower(org)))
+		return int(h.Sum32()%uint32(s.Count)) == s.Index
+	}
+	return s.Name == ""
+}
+
+// Queries returns the keeper queries restricted to the orgs and repos synced by the shard, the queries left without
+// any of them being dropped
+func (s *Shard) Queries(kc *keeper.Config) keeper.Queries {
+	if s == nil {
+		return kc.Queries
+	}
+	var answer keeper.Queries
+	for _, q := range kc.Queries {
+		var orgs, repos, excluded []string
+		for _, org := range q.Orgs {
+			if s.Owns(kc, org, "") {
+				orgs = append(orgs, org)
+				for _, r := range q.ExcludedRepos {
+					if strings.HasPrefix(strings.ToLower(r), strings.ToLower(org)+"/") {
+						excluded = append(excluded, r)
+					}
+				}
+				// the repos of the org assigned to another shard
+				for _, r := range assignedRepos(kc, org) {
+					if shard, _ := kc.AssignedShard(org, r); shard != s.Name {
+						excluded = append(excluded, org+"/"+r)
+					}
+				}
+				continue
+			}
+			// the repos of the org assigned to this shard
+			for _, r := range assignedRepos(kc, org) {
+				if shard, _ := kc.AssignedShard(org, r); shard == s.Name && !containsFold(q.ExcludedRepos, org+"/"+r) {
+					repos = append(repos, org+"/"+r)
+				}
+			}
+		}
+		for _, fullName := range q.Repos {
+			parts := strings.SplitN(fullName, "/", 2)
+			if len(parts) == 2 && s.Owns(kc, parts[0], parts[1]) {
+				repos = append(repos, fullName)
+			}
+		}
+		if len(orgs) == 0 && len(repos) == 0 {
+			continue
+		}
+		q.Orgs = orgs
+		q.Repos = repos
+		q.ExcludedRepos = excluded
+		answer = append(answer, q)
+	}
+	return answer
+}
+
+// Config returns the config of the getter whose keeper queries are restricted to the orgs and repos synced by the
+// shard, the restricted config being reused until the config is reloaded
+func (s *Shard) Config(getter config.Getter) config.Getter {
+	if s == nil {
+		return getter
+	}
+	var lock sync.Mutex
+	var last, sharded *config.Config
+	return func() *config.Config {
+		cfg := getter()
+		if cfg == nil {
+			return nil
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		if cfg != last {
+			c := *cfg
+			c.Keeper.Queries = s.Queries(&cfg.Keeper)
+			last, sharded = cfg, &c
+		}
+		return sharded
+	}
+}
+
+// assignedRepos returns the names of the repos of an org assigned to a shard
+func assignedRepos(kc *keeper.Config, org string) []string {
+	var answer []string
+	prefix := strings.ToLower(org) + "/"
+	for _, entries := range kc.Shards {
+		for _, entry := range entries {
+			if strings.HasPrefix(strings.ToLower(entry), prefix) {
+				answer = append(answer, entry[len(prefix):])
+			}
+		}
+	}
+	return answer
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// MergePools returns the pools of this shard merged with the pools the keeper instances of the other shards serve on
+// the root of the peer URLs, the peers which cannot be reached being logged and skipped
+func MergePools(local func() []Pool, peers []string, logger *logrus.Entry) func() []Pool {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func() []Pool {
+		results := make([][]Pool, len(peers))
+		var wg sync.WaitGroup
+		for i := range peers {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				pools, err := fetchPools(client, peers[i])
+				if err != nil {
+					logger.WithError(err).WithField("peer", peers[i]).Warn("failed to get the pools of the keeper shard")
+					return
+				}
+				results[i] = pools
+			}(i)
+		}
+		pools := local()
+		wg.Wait()
+		for _, r := range results {
+			pools = append(pools, r...)
+		}
+		sortPools(pools)
+		return pools
+	}
+}
+
+// fetchPools gets the pools served by a keeper instance, retrying if it reached a replica which is not the leader
+func fetchPools(client *http.Client, url string) ([]Pool, error) {
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		var resp *http.Response
+		resp, err = client.Get(url)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			err = errors.Errorf("unexpected status %s", resp.Status)
+			if resp.StatusCode == http.StatusServiceUnavailable {
+				continue
+			}
+			return nil, err
+		}
+		var pools []Pool
+		err = json.NewDecoder(resp.Body).Decode(&pools)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode the pools")
+		}
+		return pools, nil
+	}
+	return nil, err
+}
+
+// PoolsHandler serves the pools as JSON
+func PoolsHandler(getPools func() []Pool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := json.Marshal(getPools())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to encode the pools: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+	})
+}
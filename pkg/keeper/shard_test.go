@@ -0,0 +1,137 @@
+package keeper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardQueries(t *testing.T) {
+	kc := &keeper.Config{
+		Queries: keeper.Queries{
+			{
+				Orgs:          []string{"big", "small"},
+				ExcludedRepos: []string{"small/old", "big/old"},
+				Labels:        []string{"approved"},
+			},
+			{
+				Repos: []string{"other/a", "big/b", "small/x"},
+			},
+		},
+		Shards: map[string][]string{
+			"big":     {"big"},
+			"special": {"small/x", "other/a"},
+		},
+	}
+	require.NoError(t, kc.Parse())
+
+	var nilShard *Shard
+	assert.Equal(t, kc.Queries, nilShard.Queries(kc))
+
+	assert.Equal(t, keeper.Queries{
+		{
+			Orgs:          []string{"big"},
+			ExcludedRepos: []string{"big/old"},
+			Labels:        []string{"approved"},
+		},
+		{
+			Repos: []string{"big/b"},
+		},
+	}, (&Shard{Name: "big"}).Queries(kc))
+
+	assert.Equal(t, keeper.Queries{
+		{
+			Repos:  []string{"small/x"},
+			Labels: []string{"approved"},
+		},
+		{
+			Repos: []string{"other/a", "small/x"},
+		},
+	}, (&Shard{Name: "special"}).Queries(kc))
+
+	// the orgs which are not assigned are split by hash
+	owners := 0
+	for i := 0; i < 3; i++ {
+		shard := &Shard{Index: i, Count: 3}
+		queries := shard.Queries(kc)
+		if !shard.Owns(kc, "small", "") {
+			for _, q := range queries {
+				assert.NotContains(t, q.Orgs, "small")
+			}
+			continue
+		}
+		owners++
+		assert.Equal(t, keeper.Queries{
+			{
+				Orgs:          []string{"small"},
+				ExcludedRepos: []string{"small/old", "small/x"},
+				Labels:        []string{"approved"},
+			},
+		}, queries)
+	}
+	assert.Equal(t, 1, owners, "a single shard owns an org")
+}
+
+func TestShardConfig(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Keeper.Queries = keeper.Queries{{Orgs: []string{"big", "small"}}}
+	cfg.Keeper.Shards = map[string][]string{"big": {"big"}}
+	require.NoError(t, cfg.Keeper.Parse())
+	getter := (&Shard{Name: "big"}).Config(func() *config.Config {
+		return cfg
+	})
+
+	sharded := getter()
+	assert.Equal(t, []string{"big"}, sharded.Keeper.Queries[0].Orgs)
+	assert.Equal(t, []string{"big", "small"}, cfg.Keeper.Queries[0].Orgs, "the config is not modified")
+	assert.True(t, sharded == getter(), "the sharded config is reused")
+}
+
+func TestShardValidate(t *testing.T) {
+	var nilShard *Shard
+	assert.NoError(t, nilShard.Validate())
+	assert.NoError(t, (&Shard{Name: "big"}).Validate())
+	assert.NoError(t, (&Shard{Index: 2, Count: 3}).Validate())
+	assert.Error(t, (&Shard{}).Validate())
+	assert.Error(t, (&Shard{Index: 3, Count: 3}).Validate())
+	assert.Error(t, (&Shard{Name: "Not_Valid"}).Validate())
+
+	kc := &keeper.Config{Shards: map[string][]string{"a": {"org"}, "b": {"org"}}}
+	assert.Error(t, kc.Parse(), "an org is assigned to several shards")
+}
+
+func TestMergePools(t *testing.T) {
+	attempts := 0
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			http.Error(w, "this replica is not the leader", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]Pool{{Org: "a", Repo: "r", Branch: "master"}})
+	}))
+	defer peer.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer down.Close()
+
+	pools := MergePools(func() []Pool {
+		return []Pool{{Org: "b", Repo: "r", Branch: "master"}}
+	}, []string{peer.URL, down.URL}, nil)
+
+	rec := httptest.NewRecorder()
+	PoolsHandler(pools).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, MergedPoolsPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got []Pool
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Len(t, got, 2)
+	assert.Equal(t, "a", got[0].Org)
+	assert.Equal(t, "b", got[1].Org)
+}
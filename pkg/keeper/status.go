@@ -335,6 +335,16 @@ func (sc *statusController) setStatuses(all []PullRequest, pool map[string]prWit
 		}
 
 		wantState, wantDesc := expectedStatus(queryMap, pr, pool, cr, blocks, sc.spc.ProviderType())
+		// the expired holds are removed, and the reason of the others surfaced
+		hold, err := checkHold(log, sc.spc, pr, time.Now())
+		if err != nil {
+			log.WithError(err).Warn("Failed to check the hold of the pull request")
+		}
+		if hold != nil && wantState == scmprovider.StatusPending && sc.spc.ProviderType() != "gitlab" {
+			if desc := hold.Description(); desc != "" {
+				wantDesc = fmt.Sprintf(statusNotInPool, desc)
+			}
+		}
 		var actualState githubql.StatusState
 		var actualDesc string
 		for _, ctx := range contexts {
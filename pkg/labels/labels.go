@@ -19,12 +19,16 @@ package labels
 // labels for github plugins
 const (
 	Approved        = "approved"
+	BackportPrefix  = "backport/"
 	BlockedPaths    = "do-not-merge/blocked-paths"
 	Bug             = "kind/bug"
 	ClaNo           = "cncf-cla: no"
 	ClaYes          = "cncf-cla: yes"
+	CodeFreeze      = "do-not-merge/code-freeze"
+	CodeFreezeOK    = "code-freeze-exception"
 	CpApproved      = "cherry-pick-approved"
 	CpUnapproved    = "do-not-merge/cherry-pick-not-approved"
+	Flake           = "kind/flake"
 	GoodFirstIssue  = "good first issue"
 	Help            = "help wanted"
 	Hold            = "do-not-merge/hold"
@@ -37,7 +41,12 @@ const (
 	NeedsOkToTest   = "needs-ok-to-test"
 	NeedsRebase     = "needs-rebase"
 	NeedsSig        = "needs-sig"
+	NeedsTriage     = "needs-triage"
 	OkToTest        = "ok-to-test"
 	Shrug           = "¯\\_(ツ)_/¯"
+	TriageAccepted  = "triage/accepted"
+	TriageDuplicate = "triage/duplicate"
+	TriageNeedsInfo = "triage/needs-information"
+	UnsignedCommits = "do-not-merge/unsigned-commits"
 	WorkInProgress  = "do-not-merge/work-in-progress"
 )
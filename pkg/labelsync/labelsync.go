@@ -0,0 +1,273 @@
+// Package labelsync reconciles the labels of the repositories with a declarative set of labels loaded from a
+// labels.yaml file, so that the plugins applying labels always find them with the expected color and description.
+// The labels are renamed rather than created when the repositories still have them under one of their previous
+// names, and the labels not declared are left untouched.
+package labelsync
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+// colorRe matches the hexadecimal colors of the labels
+var colorRe = regexp.MustCompile(`^#?[0-9a-fA-F]{6}$`)
+
+// Label is a label of the repositories
+type Label struct {
+	// Name is the name of the label
+	Name string `json:"name"`
+	// Color is the hexadecimal color of the label, e.g. "ee0701"
+	Color string `json:"color"`
+	// Description is the description of the label
+	Description string `json:"description,omitempty"`
+	// Previously are the former names of the label, the labels of the repositories with one of these names are
+	// renamed to Name so that the issues and pull requests keep them
+	Previously []string `json:"previously,omitempty"`
+}
+
+// Configuration is the declarative set of labels of the repositories
+type Configuration struct {
+	// Default are the labels of all the repositories
+	Default []Label `json:"default,omitempty"`
+	// Repos are the labels of the given orgs or org/repos, in addition to the default ones. The labels of an
+	// org/repo override the labels of its org, which override the default ones with the same name.
+	Repos map[string][]Label `json:"repos,omitempty"`
+}
+
+// Load loads and validates the configuration from the given path
+func Load(path string) (*Configuration, error) {
+	data, err := ioutil.ReadFile(path) // #nosec
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+	cfg := &Configuration{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid labels in %s", path)
+	}
+	return cfg, nil
+}
+
+// Validate checks the labels have a name and a valid color, and that their names and previous names are unique
+func (c *Configuration) Validate() error {
+	if err := validateLabels("default", c.Default); err != nil {
+		return err
+	}
+	for target, labels := range c.Repos {
+		if err := validateLabels(target, labels); err != nil {
+			return err
+		}
+		if err := validateLabels(target, c.LabelsFor(target)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateLabels(target string, labels []Label) error {
+	names := map[string]string{}
+	for _, l := range labels {
+		if l.Name == "" {
+			return fmt.Errorf("a label of %s has no name", target)
+		}
+		if !colorRe.MatchString(l.Color) {
+			return fmt.Errorf("the color %q of the label %s of %s is not a hexadecimal color", l.Color, l.Name, target)
+		}
+		for _, name := range append([]string{l.Name}, l.Previously...) {
+			key := strings.ToLower(name)
+			if other, ok := names[key]; ok {
+				return fmt.Errorf("the labels %s and %s of %s are both named %s", other, l.Name, target, name)
+			}
+			names[key] = l.Name
+		}
+	}
+	return nil
+}
+
+// LabelsFor returns the labels of the given org/repo, or org if it has no slash
+func (c *Configuration) LabelsFor(fullName string) []Label {
+	org := strings.SplitN(fullName, "/", 2)[0]
+	var answer []Label
+	index := map[string]int{}
+	sets := [][]Label{c.Default, c.Repos[org]}
+	if fullName != org {
+		sets = append(sets, c.Repos[fullName])
+	}
+	for _, labels := range sets {
+		for _, l := range labels {
+			key := strings.ToLower(l.Name)
+			if i, ok := index[key]; ok {
+				answer[i] = l
+				continue
+			}
+			index[key] = len(answer)
+			answer = append(answer, l)
+		}
+	}
+	return answer
+}
+
+// Action is the kind of change of a label of a repository
+type Action string
+
+const (
+	// ActionCreate creates a missing label
+	ActionCreate Action = "create"
+	// ActionUpdate updates the color or the description of a label
+	ActionUpdate Action = "update"
+	// ActionRename renames a label with one of its previous names
+	ActionRename Action = "rename"
+)
+
+// Change is a change of a label of a repository
+type Change struct {
+	Action Action
+	// Current is the name of the label of the repository updated or renamed
+	Current string
+	Label   *scm.Label
+}
+
+// Plan returns the changes reconciling the current labels of a repository with the given labels
+func Plan(current []*scm.Label, labels []Label) []Change {
+	byName := map[string]*scm.Label{}
+	for _, l := range current {
+		byName[strings.ToLower(l.Name)] = l
+	}
+	var answer []Change
+	for _, l := range labels {
+		desired := &scm.Label{
+			Name:        l.Name,
+			Color:       strings.ToLower(strings.TrimPrefix(l.Color, "#")),
+			Description: l.Description,
+		}
+		if existing, ok := byName[strings.ToLower(l.Name)]; ok {
+			if existing.Name != desired.Name || !sameColor(existing.Color, desired.Color) || existing.Description != desired.Description {
+				answer = append(answer, Change{Action: ActionUpdate, Current: existing.Name, Label: desired})
+			}
+			continue
+		}
+		action := Change{Action: ActionCreate, Label: desired}
+		for _, name := range l.Previously {
+			if previous, ok := byName[strings.ToLower(name)]; ok {
+				action = Change{Action: ActionRename, Current: previous.Name, Label: desired}
+				break
+			}
+		}
+		answer = append(answer, action)
+	}
+	return answer
+}
+
+func sameColor(a, b string) bool {
+	return strings.EqualFold(strings.TrimPrefix(a, "#"), strings.TrimPrefix(b, "#"))
+}
+
+// scmProviderClient is the part of the SCM client used to sync the labels
+type scmProviderClient interface {
+	GetRepoLabels(string, string) ([]*scm.Label, error)
+	CreateRepoLabel(string, string, *scm.Label) error
+	UpdateRepoLabel(string, string, string, *scm.Label) error
+	ListOrgRepos(string) ([]*scm.Repository, error)
+}
+
+// Syncer syncs the labels of the repositories
+type Syncer struct {
+	client scmProviderClient
+	config *Configuration
+	dryRun bool
+	logger *logrus.Entry
+}
+
+// NewSyncer creates a new syncer, which only logs the changes if dryRun is true
+func NewSyncer(client scmProviderClient, config *Configuration, dryRun bool, logger *logrus.Entry) *Syncer {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	return &Syncer{
+		client: client,
+		config: config,
+		dryRun: dryRun,
+		logger: logger.WithField("component", "label-sync"),
+	}
+}
+
+// Repos returns the sorted repositories of the given orgs and org/repos, the orgs being expanded to their
+// repositories
+func (s *Syncer) Repos(targets []string) ([]string, error) {
+	repos := map[string]bool{}
+	for _, target := range targets {
+		if strings.Contains(target, "/") {
+			repos[target] = true
+			continue
+		}
+		orgRepos, err := s.client.ListOrgRepos(target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the repositories of %s", target)
+		}
+		for _, r := range orgRepos {
+			repos[scm.Join(target, r.Name)] = true
+		}
+	}
+	var answer []string
+	for r := range repos {
+		answer = append(answer, r)
+	}
+	sort.Strings(answer)
+	return answer, nil
+}
+
+// Sync reconciles the labels of the given repositories, returning the number of labels changed. The sync carries on
+// with the other repositories when one fails, the last error being returned.
+func (s *Syncer) Sync(repos []string) (int, error) {
+	changed := 0
+	var lastErr error
+	for _, fullName := range repos {
+		n, err := s.syncRepo(fullName)
+		changed += n
+		if err != nil {
+			s.logger.WithError(err).WithField("repo", fullName).Warn("failed to sync the labels")
+			lastErr = err
+		}
+	}
+	return changed, lastErr
+}
+
+func (s *Syncer) syncRepo(fullName string) (int, error) {
+	org, repo := scm.Split(fullName)
+	current, err := s.client.GetRepoLabels(org, repo)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to list the labels of %s", fullName)
+	}
+	changed := 0
+	for _, change := range Plan(current, s.config.LabelsFor(fullName)) {
+		log := s.logger.WithFields(logrus.Fields{"repo": fullName, "label": change.Label.Name, "action": change.Action})
+		if change.Current != "" && change.Current != change.Label.Name {
+			log = log.WithField("from", change.Current)
+		}
+		if s.dryRun {
+			log.Info("would change the label")
+			continue
+		}
+		if change.Action == ActionCreate {
+			err = s.client.CreateRepoLabel(org, repo, change.Label)
+		} else {
+			err = s.client.UpdateRepoLabel(org, repo, change.Current, change.Label)
+		}
+		if err != nil {
+			return changed, errors.Wrapf(err, "failed to %s the label %s of %s", change.Action, change.Label.Name, fullName)
+		}
+		log.Info("changed the label")
+		changed++
+	}
+	return changed, nil
+}
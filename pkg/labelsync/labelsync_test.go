@@ -0,0 +1,133 @@
+package labelsync
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	labels  map[string][]*scm.Label
+	repos   map[string][]*scm.Repository
+	created []string
+	updated []string
+}
+
+func (f *fakeClient) GetRepoLabels(org, repo string) ([]*scm.Label, error) {
+	return f.labels[scm.Join(org, repo)], nil
+}
+
+func (f *fakeClient) CreateRepoLabel(org, repo string, label *scm.Label) error {
+	f.created = append(f.created, scm.Join(org, repo)+":"+label.Name)
+	return nil
+}
+
+func (f *fakeClient) UpdateRepoLabel(org, repo, name string, label *scm.Label) error {
+	f.updated = append(f.updated, scm.Join(org, repo)+":"+name+"->"+label.Name)
+	return nil
+}
+
+func (f *fakeClient) ListOrgRepos(org string) ([]*scm.Repository, error) {
+	return f.repos[org], nil
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Configuration
+		err    string
+	}{
+		{
+			name: "valid",
+			config: Configuration{
+				Default: []Label{{Name: "bug", Color: "ee0701"}},
+				Repos:   map[string][]Label{"org/repo": {{Name: "bug", Color: "#00ff00"}}},
+			},
+		},
+		{
+			name:   "invalid color",
+			config: Configuration{Default: []Label{{Name: "bug", Color: "red"}}},
+			err:    `the color "red" of the label bug of default is not a hexadecimal color`,
+		},
+		{
+			name: "previous name of another label",
+			config: Configuration{Default: []Label{
+				{Name: "kind/bug", Color: "ee0701", Previously: []string{"bug"}},
+				{Name: "Bug", Color: "ee0701"},
+			}},
+			err: "the labels kind/bug and Bug of default are both named Bug",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestLabelsFor(t *testing.T) {
+	cfg := &Configuration{
+		Default: []Label{{Name: "bug", Color: "ee0701"}, {Name: "lgtm", Color: "15dd18"}},
+		Repos: map[string][]Label{
+			"org":      {{Name: "Bug", Color: "000000"}},
+			"org/repo": {{Name: "area/docs", Color: "ffffff"}},
+		},
+	}
+	assert.Equal(t, []Label{{Name: "Bug", Color: "000000"}, {Name: "lgtm", Color: "15dd18"}, {Name: "area/docs", Color: "ffffff"}}, cfg.LabelsFor("org/repo"))
+	assert.Equal(t, []Label{{Name: "bug", Color: "ee0701"}, {Name: "lgtm", Color: "15dd18"}}, cfg.LabelsFor("other/repo"))
+}
+
+func TestPlan(t *testing.T) {
+	current := []*scm.Label{
+		{Name: "lgtm", Color: "15DD18"},
+		{Name: "approved", Color: "0ffa16", Description: "old"},
+		{Name: "bug", Color: "ee0701"},
+		{Name: "unmanaged", Color: "000000"},
+	}
+	labels := []Label{
+		{Name: "lgtm", Color: "#15dd18"},
+		{Name: "approved", Color: "0ffa16", Description: "Indicates a PR has been approved."},
+		{Name: "kind/bug", Color: "ee0701", Previously: []string{"Bug"}},
+		{Name: "hold", Color: "e11d21"},
+	}
+	assert.Equal(t, []Change{
+		{Action: ActionUpdate, Current: "approved", Label: &scm.Label{Name: "approved", Color: "0ffa16", Description: "Indicates a PR has been approved."}},
+		{Action: ActionRename, Current: "bug", Label: &scm.Label{Name: "kind/bug", Color: "ee0701"}},
+		{Action: ActionCreate, Label: &scm.Label{Name: "hold", Color: "e11d21"}},
+	}, Plan(current, labels))
+}
+
+func TestSync(t *testing.T) {
+	client := &fakeClient{
+		labels: map[string][]*scm.Label{
+			"org/a": {{Name: "bug", Color: "ee0701"}},
+		},
+		repos: map[string][]*scm.Repository{
+			"org": {{Name: "a"}, {Name: "b"}},
+		},
+	}
+	cfg := &Configuration{Default: []Label{{Name: "kind/bug", Color: "ee0701", Previously: []string{"bug"}}}}
+
+	repos, err := NewSyncer(client, cfg, false, nil).Repos([]string{"org", "org/a", "other/c"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"org/a", "org/b", "other/c"}, repos)
+
+	changed, err := NewSyncer(client, cfg, true, nil).Sync(repos)
+	require.NoError(t, err)
+	assert.Equal(t, 0, changed)
+	assert.Empty(t, client.created)
+	assert.Empty(t, client.updated)
+
+	changed, err = NewSyncer(client, cfg, false, nil).Sync(repos)
+	require.NoError(t, err)
+	assert.Equal(t, 3, changed)
+	assert.Equal(t, []string{"org/b:kind/bug", "other/c:kind/bug"}, client.created)
+	assert.Equal(t, []string{"org/a:bug->kind/bug"}, client.updated)
+}
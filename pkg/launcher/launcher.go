@@ -1,29 +1,74 @@
 package launcher
 
 import (
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	clientset "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // launcherImpl default launcher
 type launcherImpl struct {
 	lhClient  clientset.Interface
 	namespace string
+	// config is used to queue the jobs triggered during a maintenance window, it may be nil
+	config config.Getter
+	now    func() time.Time
+	// lock serializes launches so concurrent events for the same commit cannot both create a job
+	lock sync.Mutex
 }
 
 // NewLauncher creates a new builder
 func NewLauncher(lhClient clientset.Interface, ns string) PipelineLauncher {
+	return NewLauncherWithConfig(lhClient, ns, nil)
+}
+
+// NewLauncherWithConfig creates a new builder which queues the jobs triggered during the maintenance windows of the given config
+func NewLauncherWithConfig(lhClient clientset.Interface, ns string, configGetter config.Getter) PipelineLauncher {
 	b := &launcherImpl{
 		lhClient:  lhClient,
 		namespace: ns,
+		config:    configGetter,
+		now:       time.Now,
 	}
 	return b
 }
 
-// Launch creates a pipeline
-// TODO: This should be moved somewhere else, probably, and needs some kind of unit testing (apb)
+// Launch creates a pipeline, unless a pending or running job already exists for the same
+// job, head SHA and base SHA in which case that job is returned instead. The jobs which cache
+// their results and already succeeded with the same configuration for the same tree and base SHA
+// are created as successful rather than run.
+// TODO: This should be moved somewhere else, probably (apb)
 func (b *launcherImpl) Launch(request *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	existing, err := b.findDuplicate(request)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		logrus.WithFields(logrus.Fields{
+			"job":      request.Spec.Job,
+			"existing": existing.Name,
+			"state":    existing.Status.State,
+		}).Info("Not launching duplicate LighthouseJob, an identical job is already active")
+		return existing, nil
+	}
+	cached, err := b.findCached(request)
+	if err != nil {
+		return nil, err
+	}
+
 	appliedJob, err := b.lhClient.LighthouseV1alpha1().LighthouseJobs(b.namespace).Create(request)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to apply LighthouseJob")
@@ -32,6 +77,22 @@ func (b *launcherImpl) Launch(request *v1alpha1.LighthouseJob) (*v1alpha1.Lighth
 	appliedJob.Status = v1alpha1.LighthouseJobStatus{
 		State: v1alpha1.TriggeredState,
 	}
+	if cached != nil {
+		logrus.WithFields(logrus.Fields{
+			"job":    request.Spec.Job,
+			"cached": cached.Name,
+		}).Info("Not running LighthouseJob, it already succeeded for the same tree")
+		appliedJob.Status = CachedStatus(cached, metav1.NewTime(b.now()))
+	} else if window := b.activeMaintenanceWindow(request); window != nil {
+		logrus.WithFields(logrus.Fields{
+			"job":    request.Spec.Job,
+			"window": window.Name,
+		}).Info("Queueing LighthouseJob until the maintenance window ends")
+		appliedJob.Status = v1alpha1.LighthouseJobStatus{
+			State:       v1alpha1.QueuedState,
+			Description: QueuedDescription(window),
+		}
+	}
 	fullyCreatedJob, err := b.lhClient.LighthouseV1alpha1().LighthouseJobs(b.namespace).UpdateStatus(appliedJob)
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to set status on LighthouseJob %s", appliedJob.Name)
@@ -39,3 +100,149 @@ func (b *launcherImpl) Launch(request *v1alpha1.LighthouseJob) (*v1alpha1.Lighth
 
 	return fullyCreatedJob, nil
 }
+
+// activeMaintenanceWindow returns the maintenance window the request should be queued for, if any.
+func (b *launcherImpl) activeMaintenanceWindow(request *v1alpha1.LighthouseJob) *lighthouse.MaintenanceWindow {
+	if b.config == nil || b.config() == nil {
+		return nil
+	}
+	var org, repo string
+	if request.Spec.Refs != nil {
+		org, repo = request.Spec.Refs.Org, request.Spec.Refs.Repo
+	}
+	return b.config().ActiveMaintenanceWindow(org, repo, b.now())
+}
+
+// QueuedDescription returns the status description of the jobs queued for the given maintenance window.
+func QueuedDescription(window *lighthouse.MaintenanceWindow) string {
+	return fmt.Sprintf("Queued until maintenance window %s ends at %s", window.Name, window.End.UTC().Format("15:04 MST Jan 2"))
+}
+
+// findDuplicate returns the active job building the same job, head SHA and base SHA as the request, if any.
+func (b *launcherImpl) findDuplicate(request *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
+	if request.Spec.Refs == nil {
+		// periodics have nothing to compare against
+		return nil, nil
+	}
+	selector := labels.Set{
+		job.CreatedByLighthouseLabel: "true",
+		job.LighthouseJobTypeLabel:   string(request.Spec.Type),
+	}
+	list, err := b.lhClient.LighthouseV1alpha1().LighthouseJobs(b.namespace).List(metav1.ListOptions{LabelSelector: selector.AsSelector().String()})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list LighthouseJobs")
+	}
+	for i := range list.Items {
+		if IsDuplicate(&list.Items[i], request) {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// findCached returns a job which succeeded for the same job configuration, tree and base SHA as the request, if the
+// request caches its results.
+func (b *launcherImpl) findCached(request *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
+	tree, spec := request.Labels[job.TreeHashLabel], request.Labels[job.JobSpecHashLabel]
+	if tree == "" || spec == "" || request.Spec.Refs == nil {
+		return nil, nil
+	}
+	selector := labels.Set{
+		job.CreatedByLighthouseLabel: "true",
+		job.LighthouseJobTypeLabel:   string(request.Spec.Type),
+		job.TreeHashLabel:            tree,
+		job.JobSpecHashLabel:         spec,
+	}
+	list, err := b.lhClient.LighthouseV1alpha1().LighthouseJobs(b.namespace).List(metav1.ListOptions{LabelSelector: selector.AsSelector().String()})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list LighthouseJobs")
+	}
+	for i := range list.Items {
+		if IsCached(&list.Items[i], request) {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// IsCached returns true if existing succeeded for the same job configuration, tree, base SHA and parameters as
+// request, whatever the pull request it was run for.
+func IsCached(existing, request *v1alpha1.LighthouseJob) bool {
+	if existing.Status.State != v1alpha1.SuccessState {
+		return false
+	}
+	tree := request.Labels[job.TreeHashLabel]
+	if tree == "" || existing.Labels[job.TreeHashLabel] != tree {
+		return false
+	}
+	spec := request.Labels[job.JobSpecHashLabel]
+	if spec == "" || existing.Labels[job.JobSpecHashLabel] != spec {
+		return false
+	}
+	return sameJob(&existing.Spec, &request.Spec)
+}
+
+// CachedStatus returns the status of a job reported as successful from the cached job rather than run.
+func CachedStatus(cached *v1alpha1.LighthouseJob, now metav1.Time) v1alpha1.LighthouseJobStatus {
+	description := fmt.Sprintf("Cached: succeeded for the same tree in %s", cached.Name)
+	name := cached.Name
+	if cached.Status.Activity != nil && cached.Status.Activity.Name != "" {
+		name = cached.Status.Activity.Name
+	}
+	return v1alpha1.LighthouseJobStatus{
+		State:          v1alpha1.SuccessState,
+		StartTime:      now,
+		CompletionTime: &now,
+		ReportURL:      cached.Status.ReportURL,
+		ArtifactsURL:   cached.Status.ArtifactsURL,
+		// the activity makes the reporter report the success like the one of a pipeline
+		Activity: &v1alpha1.ActivityRecord{
+			Name:           name,
+			Status:         v1alpha1.SuccessState,
+			Description:    description,
+			StartTime:      &now,
+			CompletionTime: &now,
+		},
+	}
+}
+
+// IsDuplicate returns true if existing is an active job for the same job, head SHA, base SHA and parameters as request.
+func IsDuplicate(existing, request *v1alpha1.LighthouseJob) bool {
+	if existing.Complete() {
+		return false
+	}
+	switch existing.Status.State {
+	case "", v1alpha1.TriggeredState, v1alpha1.QueuedState, v1alpha1.PendingState, v1alpha1.RunningState:
+	default:
+		return false
+	}
+	a, b := &existing.Spec, &request.Spec
+	if !sameJob(a, b) || len(a.Refs.Pulls) != len(b.Refs.Pulls) {
+		return false
+	}
+	for i := range a.Refs.Pulls {
+		if a.Refs.Pulls[i].Number != b.Refs.Pulls[i].Number || a.Refs.Pulls[i].SHA != b.Refs.Pulls[i].SHA {
+			return false
+		}
+	}
+	return true
+}
+
+// sameJob returns true if the specs are for the same job, repository, base SHA and parameters.
+func sameJob(a, b *v1alpha1.LighthouseJobSpec) bool {
+	if a.Job != b.Job || a.Type != b.Type || a.Refs == nil || b.Refs == nil {
+		return false
+	}
+	if a.Refs.Org != b.Refs.Org || a.Refs.Repo != b.Refs.Repo || a.Refs.BaseSHA != b.Refs.BaseSHA {
+		return false
+	}
+	if len(a.Parameters) != len(b.Parameters) {
+		return false
+	}
+	for k, v := range a.Parameters {
+		if bv, ok := b.Parameters[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,258 @@
+package launcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const ns = "jx"
+
+func newJob(name, headSHA string) *v1alpha1.LighthouseJob {
+	return &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels: map[string]string{
+				job.CreatedByLighthouseLabel: "true",
+				job.LighthouseJobTypeLabel:   string(job.PresubmitJob),
+			},
+		},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type: job.PresubmitJob,
+			Job:  "unit",
+			Refs: &v1alpha1.Refs{
+				Org:     "org",
+				Repo:    "repo",
+				BaseSHA: "base",
+				Pulls:   []v1alpha1.Pull{{Number: 1, SHA: headSHA}},
+			},
+		},
+	}
+}
+
+func TestLaunchDeduplicates(t *testing.T) {
+	running := newJob("running", "head")
+	running.Status.State = v1alpha1.RunningState
+	lhClient := fake.NewSimpleClientset(running)
+	l := NewLauncher(lhClient, ns)
+
+	launched, err := l.Launch(newJob("duplicate", "head"))
+	require.NoError(t, err)
+	assert.Equal(t, "running", launched.Name)
+
+	launched, err = l.Launch(newJob("new-sha", "other"))
+	require.NoError(t, err)
+	assert.Equal(t, "new-sha", launched.Name)
+	assert.Equal(t, v1alpha1.TriggeredState, launched.Status.State)
+
+	list, err := lhClient.LighthouseV1alpha1().LighthouseJobs(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, list.Items, 2)
+}
+
+func TestLaunchQueuesDuringMaintenanceWindow(t *testing.T) {
+	now := time.Date(2020, 7, 20, 21, 0, 0, 0, time.UTC)
+	cfg := &config.Config{ProwConfig: config.ProwConfig{
+		MaintenanceWindows: []lighthouse.MaintenanceWindow{{
+			Name:  "upgrade",
+			Start: now.Add(-time.Hour),
+			End:   now.Add(time.Hour),
+			Repos: []string{"org/repo"},
+		}},
+	}}
+	lhClient := fake.NewSimpleClientset()
+	l := NewLauncherWithConfig(lhClient, ns, func() *config.Config { return cfg }).(*launcherImpl)
+	l.now = func() time.Time { return now }
+
+	launched, err := l.Launch(newJob("queued", "head"))
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.QueuedState, launched.Status.State)
+	assert.Equal(t, "Queued until maintenance window upgrade ends at 22:00 UTC Jul 20", launched.Status.Description)
+
+	// a queued job is still active so is not launched twice
+	launched, err = l.Launch(newJob("duplicate", "head"))
+	require.NoError(t, err)
+	assert.Equal(t, "queued", launched.Name)
+
+	other := newJob("other-repo", "head")
+	other.Spec.Refs.Repo = "other"
+	launched, err = l.Launch(other)
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.TriggeredState, launched.Status.State)
+
+	l.now = func() time.Time { return now.Add(time.Hour) }
+	launched, err = l.Launch(newJob("after-window", "other"))
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.TriggeredState, launched.Status.State)
+}
+
+func TestIsDuplicate(t *testing.T) {
+	request := newJob("request", "head")
+	tests := []struct {
+		name     string
+		modify   func(*v1alpha1.LighthouseJob)
+		expected bool
+	}{
+		{
+			name:     "pending",
+			modify:   func(j *v1alpha1.LighthouseJob) { j.Status.State = v1alpha1.PendingState },
+			expected: true,
+		},
+		{
+			name:     "triggered",
+			modify:   func(j *v1alpha1.LighthouseJob) { j.Status.State = v1alpha1.TriggeredState },
+			expected: true,
+		},
+		{
+			name: "completed",
+			modify: func(j *v1alpha1.LighthouseJob) {
+				j.Status.State = v1alpha1.FailureState
+				j.SetComplete()
+			},
+		},
+		{
+			name: "other job",
+			modify: func(j *v1alpha1.LighthouseJob) {
+				j.Status.State = v1alpha1.PendingState
+				j.Spec.Job = "lint"
+			},
+		},
+		{
+			name: "other base",
+			modify: func(j *v1alpha1.LighthouseJob) {
+				j.Status.State = v1alpha1.PendingState
+				j.Spec.Refs.BaseSHA = "other"
+			},
+		},
+		{
+			name: "batch",
+			modify: func(j *v1alpha1.LighthouseJob) {
+				j.Status.State = v1alpha1.PendingState
+				j.Spec.Refs.Pulls = append(j.Spec.Refs.Pulls, v1alpha1.Pull{Number: 2, SHA: "two"})
+			},
+		},
+		{
+			name: "other parameters",
+			modify: func(j *v1alpha1.LighthouseJob) {
+				j.Status.State = v1alpha1.PendingState
+				j.Spec.Parameters = map[string]string{"provider": "gke"}
+			},
+		},
+	}
+	for _, tc := range tests {
+		existing := newJob("existing", "head")
+		tc.modify(existing)
+		assert.Equal(t, tc.expected, IsDuplicate(existing, request), tc.name)
+	}
+}
+
+func TestLaunchReportsCachedResults(t *testing.T) {
+	now := time.Date(2020, 7, 20, 21, 0, 0, 0, time.UTC)
+	succeeded := newJob("succeeded", "head")
+	succeeded.Labels[job.TreeHashLabel] = "tree"
+	succeeded.Labels[job.JobSpecHashLabel] = "spec"
+	succeeded.Status.State = v1alpha1.SuccessState
+	succeeded.Status.ReportURL = "https://dashboard/succeeded"
+	succeeded.SetComplete()
+	lhClient := fake.NewSimpleClientset(succeeded)
+	l := NewLauncher(lhClient, ns).(*launcherImpl)
+	l.now = func() time.Time { return now }
+
+	// the same commit in another pull request
+	request := newJob("other-pr", "head")
+	request.Spec.Refs.Pulls[0].Number = 2
+	request.Labels[job.TreeHashLabel] = "tree"
+	request.Labels[job.JobSpecHashLabel] = "spec"
+	launched, err := l.Launch(request)
+	require.NoError(t, err)
+	assert.Equal(t, "other-pr", launched.Name)
+	assert.Equal(t, v1alpha1.SuccessState, launched.Status.State)
+	assert.Equal(t, "https://dashboard/succeeded", launched.Status.ReportURL)
+	require.NotNil(t, launched.Status.Activity)
+	assert.Equal(t, v1alpha1.SuccessState, launched.Status.Activity.Status)
+	assert.Equal(t, "Cached: succeeded for the same tree in succeeded", launched.Status.Activity.Description)
+	assert.True(t, launched.Complete())
+
+	// a rebase onto another base commit
+	request = newJob("other-base", "rebased")
+	request.Spec.Refs.BaseSHA = "other"
+	request.Labels[job.TreeHashLabel] = "tree"
+	request.Labels[job.JobSpecHashLabel] = "spec"
+	launched, err = l.Launch(request)
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.TriggeredState, launched.Status.State)
+
+	// the same tree once the configuration of the job changed
+	request = newJob("changed", "head")
+	request.Labels[job.TreeHashLabel] = "tree"
+	request.Labels[job.JobSpecHashLabel] = "changed"
+	launched, err = l.Launch(request)
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.TriggeredState, launched.Status.State)
+
+	// a job which does not cache its results
+	launched, err = l.Launch(newJob("uncached", "other"))
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.TriggeredState, launched.Status.State)
+}
+
+func TestIsCached(t *testing.T) {
+	request := newJob("request", "head")
+	request.Labels[job.TreeHashLabel] = "tree"
+	request.Labels[job.JobSpecHashLabel] = "spec"
+	tests := []struct {
+		name     string
+		modify   func(*v1alpha1.LighthouseJob)
+		expected bool
+	}{
+		{
+			name:     "succeeded",
+			modify:   func(j *v1alpha1.LighthouseJob) {},
+			expected: true,
+		},
+		{
+			name: "amended commit",
+			modify: func(j *v1alpha1.LighthouseJob) {
+				j.Spec.Refs.Pulls[0].SHA = "amended"
+			},
+			expected: true,
+		},
+		{
+			name:   "failed",
+			modify: func(j *v1alpha1.LighthouseJob) { j.Status.State = v1alpha1.FailureState },
+		},
+		{
+			name:   "other tree",
+			modify: func(j *v1alpha1.LighthouseJob) { j.Labels[job.TreeHashLabel] = "other" },
+		},
+		{
+			name:   "other job configuration",
+			modify: func(j *v1alpha1.LighthouseJob) { j.Labels[job.JobSpecHashLabel] = "other" },
+		},
+		{
+			name:   "other job",
+			modify: func(j *v1alpha1.LighthouseJob) { j.Spec.Job = "lint" },
+		},
+		{
+			name:   "other parameters",
+			modify: func(j *v1alpha1.LighthouseJob) { j.Spec.Parameters = map[string]string{"provider": "gke"} },
+		},
+	}
+	for _, tc := range tests {
+		existing := newJob("existing", "head")
+		existing.Labels[job.TreeHashLabel] = "tree"
+		existing.Labels[job.JobSpecHashLabel] = "spec"
+		existing.Status.State = v1alpha1.SuccessState
+		tc.modify(existing)
+		assert.Equal(t, tc.expected, IsCached(existing, request), tc.name)
+	}
+}
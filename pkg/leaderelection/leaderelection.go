@@ -0,0 +1,208 @@
+// Package leaderelection elects a leader among the replicas of the singleton components, e.g. keeper, foghorn and
+// the garbage collection of the jobs, with a Lease of their namespace so that they can run with several replicas for
+// a fast failover without merging or reporting twice.
+package leaderelection
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/health"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	le "k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	// DefaultLeaseDuration is the default duration the other replicas wait for before taking over the leadership if
+	// the leader stops renewing its lease
+	DefaultLeaseDuration = 15 * time.Second
+
+	// DefaultRenewDeadline is the default duration the leader retries renewing its lease for before giving up the
+	// leadership
+	DefaultRenewDeadline = 10 * time.Second
+
+	// DefaultRetryPeriod is the default duration between the attempts to acquire or renew the lease
+	DefaultRetryPeriod = 2 * time.Second
+)
+
+// ErrLeadershipLost is returned by Run when the leader failed to renew its lease, the process should then exit as
+// another replica may already be acting as the leader
+var ErrLeadershipLost = errors.New("lost the leadership")
+
+// errStopping is returned by the lock once the leader stops, to stop renewing the lease
+var errStopping = errors.New("the leader is stopping")
+
+// Elector elects a leader among the replicas of a component. A nil Elector always leads, so that the election can be
+// disabled.
+type Elector struct {
+	// LeaseDuration is the duration the other replicas wait for before taking over the leadership
+	LeaseDuration time.Duration
+	// RenewDeadline is the duration the leader retries renewing its lease for before giving up the leadership
+	RenewDeadline time.Duration
+	// RetryPeriod is the duration between the attempts to acquire or renew the lease
+	RetryPeriod time.Duration
+
+	lock     resourcelock.Interface
+	watchdog *le.HealthzAdaptor
+	leading  int32
+}
+
+// NewElector creates an elector competing for the Lease of the given name in the namespace, identified by the name
+// of the pod
+func NewElector(kubeClient kubernetes.Interface, namespace, name string) (*Elector, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the hostname")
+	}
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock, namespace, name, kubeClient.CoreV1(), kubeClient.CoordinationV1(), resourcelock.ResourceLockConfig{
+		Identity: hostname + "_" + string(uuid.NewUUID()),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the lock of the lease %s", name)
+	}
+	return &Elector{
+		LeaseDuration: DefaultLeaseDuration,
+		RenewDeadline: DefaultRenewDeadline,
+		RetryPeriod:   DefaultRetryPeriod,
+		lock:          lock,
+		watchdog:      le.NewLeaderHealthzAdaptor(DefaultLeaseDuration),
+	}, nil
+}
+
+// IsLeader returns true if this replica currently leads
+func (e *Elector) IsLeader() bool {
+	return e == nil || atomic.LoadInt32(&e.leading) == 1
+}
+
+// Check returns the liveness check failing if the leader keeps failing to renew its lease
+func (e *Elector) Check() health.Check {
+	return func(ctx context.Context) error {
+		if e == nil {
+			return nil
+		}
+		return e.watchdog.Check(nil)
+	}
+}
+
+// LeaderOnly returns a handler serving the requests only if this replica leads, the other replicas responding with
+// 503 as they do not hold the state of the leader
+func (e *Elector) LeaderOnly(h http.Handler) http.Handler {
+	if e == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !e.IsLeader() {
+			http.Error(w, "this replica is not the leader", http.StatusServiceUnavailable)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Run campaigns for the leadership until ctx is done and calls run once this replica leads. The context of run is
+// done when ctx is, the lease being released only once run returned so that the next leader does not act
+// concurrently. Run returns once run returned, or ErrLeadershipLost as soon as the leadership is lost.
+func (e *Elector) Run(ctx context.Context, run func(ctx context.Context)) error {
+	if e == nil {
+		run(ctx)
+		return nil
+	}
+	electionCtx, stopElection := context.WithCancel(context.Background())
+	defer stopElection()
+
+	stopping := &stoppingLock{Interface: e.lock, stop: stopElection}
+	var lock sync.Mutex
+	leading := false
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-electionCtx.Done():
+			return
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		// stop campaigning, otherwise the lease is released once run returned
+		if !leading {
+			stopElection()
+		}
+	}()
+
+	elector, err := le.NewLeaderElector(le.LeaderElectionConfig{
+		Lock:            stopping,
+		LeaseDuration:   e.LeaseDuration,
+		RenewDeadline:   e.RenewDeadline,
+		RetryPeriod:     e.RetryPeriod,
+		ReleaseOnCancel: true,
+		WatchDog:        e.watchdog,
+		Name:            e.lock.Describe(),
+		Callbacks: le.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				lock.Lock()
+				leading = true
+				lock.Unlock()
+				atomic.StoreInt32(&e.leading, 1)
+				logrus.WithField("lease", e.lock.Describe()).WithField("identity", e.lock.Identity()).Info("started leading")
+
+				runCtx, cancel := context.WithCancel(leaderCtx)
+				defer cancel()
+				go func() {
+					select {
+					case <-ctx.Done():
+						cancel()
+					case <-runCtx.Done():
+					}
+				}()
+				run(runCtx)
+				// release the lease unless it was already lost
+				if leaderCtx.Err() == nil {
+					stopping.Stop()
+				}
+			},
+			OnStoppedLeading: func() {
+				if atomic.SwapInt32(&e.leading, 0) == 1 {
+					logrus.WithField("lease", e.lock.Describe()).WithField("identity", e.lock.Identity()).Info("stopped leading")
+				}
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create the leader elector")
+	}
+	e.watchdog.SetLeaderElection(elector)
+	elector.Run(electionCtx)
+	if electionCtx.Err() == nil {
+		return ErrLeadershipLost
+	}
+	return nil
+}
+
+// stoppingLock stops the election from the goroutine renewing the lease once the leader stops. The elector of
+// client-go does not wait for the renewal in progress when its context is done, which would then race with the release
+// of the lease.
+type stoppingLock struct {
+	resourcelock.Interface
+	stopping int32
+	stop     func()
+}
+
+// Stop stops the election the next time the lease is renewed, the lease being released then
+func (l *stoppingLock) Stop() {
+	atomic.StoreInt32(&l.stopping, 1)
+}
+
+// Get fails once the leader stops, so that the lease is not renewed anymore
+func (l *stoppingLock) Get() (*resourcelock.LeaderElectionRecord, []byte, error) {
+	if atomic.LoadInt32(&l.stopping) == 1 {
+		l.stop()
+		return nil, nil, errStopping
+	}
+	return l.Interface.Get()
+}
@@ -0,0 +1,124 @@
+package leaderelection
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestElector(t *testing.T, kubeClient *fake.Clientset) *Elector {
+	e, err := NewElector(kubeClient, "jx", "lighthouse-keeper")
+	require.NoError(t, err)
+	e.LeaseDuration = time.Second
+	e.RenewDeadline = 500 * time.Millisecond
+	e.RetryPeriod = 100 * time.Millisecond
+	return e
+}
+
+func TestElector(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	first := newTestElector(t, kubeClient)
+	second := newTestElector(t, kubeClient)
+
+	firstCtx, stopFirst := context.WithCancel(context.Background())
+	started := make(chan string, 2)
+	firstDone := make(chan error)
+	go func() {
+		firstDone <- first.Run(firstCtx, func(ctx context.Context) {
+			started <- "first"
+			<-ctx.Done()
+			// the lease is still held while the work stops
+			time.Sleep(200 * time.Millisecond)
+			started <- "first stopped"
+		})
+	}()
+	require.Equal(t, "first", <-started)
+	assert.True(t, first.IsLeader())
+
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+	secondDone := make(chan error)
+	go func() {
+		secondDone <- second.Run(secondCtx, func(ctx context.Context) {
+			started <- "second"
+		})
+	}()
+	select {
+	case s := <-started:
+		t.Fatalf("%s started while the first replica leads", s)
+	case <-time.After(500 * time.Millisecond):
+	}
+	assert.False(t, second.IsLeader())
+
+	// the first replica releases the lease once its work stopped so that the second one takes over
+	stopFirst()
+	assert.Equal(t, "first stopped", <-started)
+	assert.NoError(t, <-firstDone)
+	assert.False(t, first.IsLeader())
+	select {
+	case s := <-started:
+		assert.Equal(t, "second", s)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second replica did not take over")
+	}
+	assert.NoError(t, <-secondDone, "the election stops once the work returned")
+}
+
+func TestElectorStopsCampaigning(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	first := newTestElector(t, kubeClient)
+	second := newTestElector(t, kubeClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = first.Run(ctx, func(ctx context.Context) { <-ctx.Done() })
+	}()
+	require.Eventually(t, first.IsLeader, 5*time.Second, 10*time.Millisecond)
+
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- second.Run(secondCtx, func(ctx context.Context) {
+			t.Error("the second replica must not lead")
+		})
+	}()
+	stopSecond()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second replica did not stop campaigning")
+	}
+}
+
+func TestNilElector(t *testing.T) {
+	var e *Elector
+	ran := false
+	require.NoError(t, e.Run(context.Background(), func(ctx context.Context) { ran = true }))
+	assert.True(t, ran)
+	assert.True(t, e.IsLeader())
+	assert.NoError(t, e.Check()(context.Background()))
+}
+
+func TestLeaderOnly(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	var disabled *Elector
+	w := httptest.NewRecorder()
+	disabled.LeaderOnly(h).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	e := newTestElector(t, fake.NewSimpleClientset())
+	w = httptest.NewRecorder()
+	e.LeaderOnly(h).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
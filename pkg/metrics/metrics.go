@@ -20,40 +20,88 @@ package metrics
 import (
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/sirupsen/logrus"
 
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
 	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
 	"github.com/jenkins-x/lighthouse/pkg/interrupts"
 )
 
-const metricsPort = 9090
+const (
+	// Path is the path the metrics are served on by every component
+	Path = "/metrics"
+
+	// Port is the port the metrics are served on by the components without an HTTP server of their own
+	Port = 9090
+)
+
+// Handler returns the handler serving the metrics of the default prometheus registry, along with the ones of
+// the controller-runtime registry for the components built on a controller manager
+func Handler() http.Handler {
+	return promhttp.HandlerFor(gatherers{prometheus.DefaultGatherer, ctrlmetrics.Registry}, promhttp.HandlerOpts{})
+}
+
+// gatherers gathers the metrics of several registries, skipping the families already gathered from a previous
+// one, e.g. the go runtime metrics which some dependencies register in the controller-runtime registry too
+type gatherers []prometheus.Gatherer
+
+// Gather implements prometheus.Gatherer
+func (g gatherers) Gather() ([]*dto.MetricFamily, error) {
+	var answer []*dto.MetricFamily
+	var errs prometheus.MultiError
+	seen := map[string]bool{}
+	for _, gatherer := range g {
+		families, err := gatherer.Gather()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, family := range families {
+			if seen[family.GetName()] {
+				continue
+			}
+			seen[family.GetName()] = true
+			answer = append(answer, family)
+		}
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].GetName() < answer[j].GetName()
+	})
+	return answer, errs.MaybeUnwrap()
+}
+
+// Serve serves the metrics on Path on the given port until the process is interrupted, along with the handlers
+// registered by the given functions, e.g. the health endpoints
+func Serve(port int, register ...func(*http.ServeMux)) {
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle(Path, Handler())
+	for _, r := range register {
+		r(metricsMux)
+	}
+	server := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: metricsMux}
+	interrupts.ListenAndServe(server, 5*time.Second)
+}
 
 // ExposeMetrics chooses whether to serve or push metrics for the service
 func ExposeMetrics(component string, pushGateway lighthouse.PushGateway) {
 	if pushGateway.Endpoint != "" {
 		pushMetrics(component, pushGateway.Endpoint, pushGateway.Interval)
 		if pushGateway.ServeMetrics {
-			serveMetrics()
+			Serve(Port)
 		}
 	} else {
-		serveMetrics()
+		Serve(Port)
 	}
 }
 
-// serveMetrics serves prometheus metrics for the service
-func serveMetrics() {
-	metricsMux := http.NewServeMux()
-	metricsMux.Handle("/metrics", promhttp.Handler())
-	server := &http.Server{Addr: ":" + strconv.Itoa(metricsPort), Handler: metricsMux}
-	interrupts.ListenAndServe(server, 5*time.Second)
-}
-
 // pushMetrics is meant to run in a goroutine and continuously push
 // metrics to the provided endpoint.
 func pushMetrics(component, endpoint string, interval time.Duration) {
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func TestHandler(t *testing.T) {
+	lighthouseCounter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_lighthouse_total", Help: "test"})
+	managerCounter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_manager_total", Help: "test"})
+	prometheus.MustRegister(lighthouseCounter)
+	defer prometheus.Unregister(lighthouseCounter)
+	ctrlmetrics.Registry.MustRegister(managerCounter)
+	defer ctrlmetrics.Registry.Unregister(managerCounter)
+	lighthouseCounter.Inc()
+	managerCounter.Inc()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, Path, nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "test_lighthouse_total 1")
+	assert.Contains(t, w.Body.String(), "test_manager_total 1")
+}
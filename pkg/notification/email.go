@@ -0,0 +1,125 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/sirupsen/logrus"
+)
+
+// maxEmailTestFailures is the maximum number of failed tests listed in an email report
+const maxEmailTestFailures = 20
+
+// EmailReporter emails the failures of postsubmit and periodic jobs to their owners.
+type EmailReporter struct {
+	logger   *logrus.Entry
+	secrets  *secret.Resolver
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	now      func() time.Time
+}
+
+// NewEmailReporter creates an email reporter, the secrets resolver resolving the password of the SMTP server
+func NewEmailReporter(logger *logrus.Entry, secrets *secret.Resolver) *EmailReporter {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	return &EmailReporter{
+		logger:   logger.WithField("reporter", "email"),
+		secrets:  secrets,
+		sendMail: smtp.SendMail,
+		now:      time.Now,
+	}
+}
+
+// Report emails the final state of the job to the recipients of its email report
+func (r *EmailReporter) Report(cfg *lighthouse.SMTP, lhjob *v1alpha1.LighthouseJob) error {
+	if lhjob.Spec.EmailReport == nil || len(lhjob.Spec.EmailReport.To) == 0 {
+		return nil
+	}
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		password, err := r.secrets.Resolve(*cfg.PasswordSecret)
+		if err != nil {
+			return fmt.Errorf("failed to resolve the SMTP password: %v", err)
+		}
+		auth = smtp.PlainAuth("", cfg.Username, password, cfg.Host)
+	}
+	to := lhjob.Spec.EmailReport.To
+	msg := EmailMessage(cfg.From, to, lhjob, r.now())
+	if err := r.sendMail(cfg.Address(), auth, cfg.From, to, msg); err != nil {
+		return fmt.Errorf("failed to send the email report of job %s: %v", lhjob.Name, err)
+	}
+	r.logger.WithFields(logrus.Fields{"job": lhjob.Name, "to": strings.Join(to, ",")}).Info("sent email report")
+	return nil
+}
+
+// EmailSubject returns the subject of the email reporting the final state of the job
+func EmailSubject(lhjob *v1alpha1.LighthouseJob) string {
+	verb := "failed"
+	if lhjob.Status.State == v1alpha1.SuccessState {
+		verb = "is fixed"
+	}
+	subject := fmt.Sprintf("[lighthouse] %s job %s %s", lhjob.Spec.Type, lhjob.Spec.Job, verb)
+	if refs := lhjob.Spec.Refs; refs != nil {
+		subject += fmt.Sprintf(" on %s/%s", refs.Org, refs.Repo)
+		if refs.BaseRef != "" {
+			subject += " " + refs.BaseRef
+		}
+	}
+	return subject
+}
+
+// EmailMessage returns the RFC 5322 message reporting the final state of the job
+func EmailMessage(from string, to []string, lhjob *v1alpha1.LighthouseJob, date time.Time) []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(buf, "Subject: %s\r\n", EmailSubject(lhjob))
+	fmt.Fprintf(buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+
+	fmt.Fprintf(buf, "Job:    %s\r\n", lhjob.Spec.Job)
+	fmt.Fprintf(buf, "Type:   %s\r\n", lhjob.Spec.Type)
+	if refs := lhjob.Spec.Refs; refs != nil {
+		fmt.Fprintf(buf, "Repo:   %s/%s\r\n", refs.Org, refs.Repo)
+		if refs.BaseRef != "" {
+			fmt.Fprintf(buf, "Branch: %s\r\n", refs.BaseRef)
+		}
+		if refs.BaseSHA != "" {
+			fmt.Fprintf(buf, "Commit: %s\r\n", refs.BaseSHA)
+		}
+	}
+	fmt.Fprintf(buf, "State:  %s\r\n", lhjob.Status.State)
+	if lhjob.Status.Description != "" {
+		fmt.Fprintf(buf, "\r\n%s\r\n", lhjob.Status.Description)
+	}
+	if lhjob.Status.ReportURL != "" {
+		fmt.Fprintf(buf, "\r\nPipeline:  %s\r\n", lhjob.Status.ReportURL)
+	}
+	if lhjob.Status.ArtifactsURL != "" {
+		fmt.Fprintf(buf, "Artifacts: %s\r\n", lhjob.Status.ArtifactsURL)
+	}
+	if failures := lhjob.Status.TestFailures; len(failures) > 0 {
+		buf.WriteString("\r\nFailed tests:\r\n")
+		for i, f := range failures {
+			if i == maxEmailTestFailures {
+				fmt.Fprintf(buf, "  and %d more\r\n", len(failures)-maxEmailTestFailures)
+				break
+			}
+			name := f.Name
+			if f.Suite != "" {
+				name = f.Suite + "." + f.Name
+			}
+			fmt.Fprintf(buf, "  - %s\r\n", name)
+		}
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailReport(t *testing.T) {
+	lhjob := &v1alpha1.LighthouseJob{
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type:        job.PostsubmitJob,
+			Job:         "release",
+			Refs:        &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: "abc123"},
+			EmailReport: &v1alpha1.EmailReportSpec{To: []string{"team@example.com", "lead@example.com"}},
+		},
+		Status: v1alpha1.LighthouseJobStatus{
+			State:        v1alpha1.FailureState,
+			Description:  "Pipeline failed",
+			ReportURL:    "https://dashboard/release",
+			TestFailures: []v1alpha1.TestFailure{{Suite: "api", Name: "TestCreate"}},
+		},
+	}
+	cfg := &lighthouse.SMTP{Host: "smtp.example.com", From: "lighthouse@example.com", Username: "bot", PasswordSecret: &secret.Reference{Name: "smtp", Key: "password"}}
+	require.NoError(t, cfg.Parse())
+
+	var addr, from string
+	var to []string
+	var msg []byte
+	r := NewEmailReporter(nil, testResolver(map[string]string{"password": "s3cr3t"}))
+	r.now = func() time.Time { return time.Date(2020, 7, 20, 21, 0, 0, 0, time.UTC) }
+	r.sendMail = func(a string, auth smtp.Auth, f string, t []string, m []byte) error {
+		addr, from, to, msg = a, f, t, m
+		return nil
+	}
+	require.NoError(t, r.Report(cfg, lhjob))
+
+	assert.Equal(t, "smtp.example.com:587", addr)
+	assert.Equal(t, "lighthouse@example.com", from)
+	assert.Equal(t, []string{"team@example.com", "lead@example.com"}, to)
+	text := string(msg)
+	assert.Contains(t, text, "Subject: [lighthouse] postsubmit job release failed on org/repo main\r\n")
+	assert.Contains(t, text, "To: team@example.com, lead@example.com\r\n")
+	assert.Contains(t, text, "Date: Mon, 20 Jul 2020 21:00:00 +0000\r\n")
+	assert.Contains(t, text, "Pipeline:  https://dashboard/release\r\n")
+	assert.Contains(t, text, "  - api.TestCreate\r\n")
+	assert.True(t, strings.Contains(text, "\r\n\r\nJob:    release\r\n"), "the body should follow the headers")
+
+	lhjob.Status.State = v1alpha1.SuccessState
+	assert.Equal(t, "[lighthouse] postsubmit job release is fixed on org/repo main", EmailSubject(lhjob))
+}
+
+func TestEmailReportMissingPassword(t *testing.T) {
+	lhjob := &v1alpha1.LighthouseJob{Spec: v1alpha1.LighthouseJobSpec{EmailReport: &v1alpha1.EmailReportSpec{To: []string{"team@example.com"}}}}
+	cfg := &lighthouse.SMTP{Host: "smtp.example.com", Port: 25, From: "lighthouse@example.com", Username: "bot", PasswordSecret: &secret.Reference{Name: "smtp", Key: "password"}}
+	r := NewEmailReporter(nil, testResolver(nil))
+	r.sendMail = func(string, smtp.Auth, string, []string, []byte) error {
+		t.Error("the email should not be sent without the password")
+		return nil
+	}
+	assert.Error(t, r.Report(cfg, lhjob))
+}
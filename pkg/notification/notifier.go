@@ -0,0 +1,323 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSlackURL = "https://slack.com/api/"
+
+	// threadEventType is the type of the metadata of the Slack messages starting the thread of a pull request
+	threadEventType = "lighthouse_pull_request"
+	// threadHistoryLimit is the number of recent messages of a Slack channel searched for the thread of a pull request
+	threadHistoryLimit = 200
+)
+
+var defaultTemplates = map[string]string{
+	lighthouse.ChatEventJobFailed: `Job {{.Job}} failed on {{.Org}}/{{.Repo}}{{if .Number}}#{{.Number}}{{else if .Branch}} {{.Branch}}{{end}}{{if .Description}}: {{.Description}}{{end}}{{if .URL}} {{.URL}}{{end}}`,
+	lighthouse.ChatEventMerged:    `{{.Org}}/{{.Repo}}#{{.Number}} {{.Title}} was merged{{if .Author}} (author {{.Author}}){{end}}`,
+	lighthouse.ChatEventOverride:  `{{.Author}} overrode {{join .Contexts ", "}} on {{.Org}}/{{.Repo}}#{{.Number}}`,
+}
+
+var templateFuncs = template.FuncMap{"join": strings.Join}
+
+// Event is an event posted to the chat channels
+type Event struct {
+	// Kind is the kind of event, one of "job_failed", "merged" and "override"
+	Kind string
+	// Org is the org of the repository
+	Org string
+	// Repo is the name of the repository
+	Repo string
+	// Number is the number of the pull request, 0 for a job failure on a branch
+	Number int
+	// Branch is the branch of a job failure
+	Branch string
+	// Title is the title of the pull request, if known
+	Title string
+	// Author is the author of the pull request for a merge, or the user overriding the contexts for an override
+	Author string
+	// Job is the name of the failed job
+	Job string
+	// Description is the description of the state of the failed job
+	Description string
+	// URL is the link to the failed job
+	URL string
+	// Contexts are the overridden contexts
+	Contexts []string
+}
+
+// JobFailed returns the event of the failure of the LighthouseJob
+func JobFailed(lhjob *v1alpha1.LighthouseJob) *Event {
+	event := &Event{
+		Kind:        lighthouse.ChatEventJobFailed,
+		Job:         lhjob.Spec.Job,
+		Description: lhjob.Status.Description,
+		URL:         lhjob.Status.ReportURL,
+	}
+	if refs := lhjob.Spec.Refs; refs != nil {
+		event.Org, event.Repo, event.Branch = refs.Org, refs.Repo, refs.BaseRef
+		if len(refs.Pulls) > 0 {
+			event.Number = refs.Pulls[0].Number
+			event.Author = refs.Pulls[0].Author
+		}
+	}
+	return event
+}
+
+// Notifier posts events to Slack and Microsoft Teams channels.
+type Notifier struct {
+	client   *http.Client
+	logger   *logrus.Entry
+	secrets  *secret.Resolver
+	slackURL string
+
+	lock sync.Mutex
+	// threads are the timestamps of the Slack messages starting the thread of each pull request, by channel
+	threads map[string]string
+}
+
+// NewNotifier creates a notifier, the secrets resolver resolving the Slack tokens and Teams webhook URLs
+func NewNotifier(logger *logrus.Entry, secrets *secret.Resolver) *Notifier {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	return &Notifier{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		logger:   logger.WithField("reporter", "chat"),
+		secrets:  secrets,
+		slackURL: defaultSlackURL,
+		threads:  map[string]string{},
+	}
+}
+
+// Notify posts the event to the matching notifications, returning the first error encountered.
+// It does nothing if the notifier is nil.
+func (n *Notifier) Notify(notifications []lighthouse.ChatNotification, event *Event) error {
+	if n == nil {
+		return nil
+	}
+	var firstErr error
+	for i := range notifications {
+		c := &notifications[i]
+		if !c.Matches(event.Kind, event.Org, event.Repo, event.Job) {
+			continue
+		}
+		logger := n.logger.WithFields(logrus.Fields{"notification": c.Name, "event": event.Kind, "repo": event.Org + "/" + event.Repo})
+		if err := n.notify(c, event); err != nil {
+			logger.WithError(err).Warn("failed to post chat notification")
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to post chat notification %s: %v", c.Name, err)
+			}
+			continue
+		}
+		logger.Debug("posted chat notification")
+	}
+	return firstErr
+}
+
+func (n *Notifier) notify(c *lighthouse.ChatNotification, event *Event) error {
+	text, err := Message(c, event)
+	if err != nil {
+		return err
+	}
+	switch c.Kind {
+	case lighthouse.ChatSlack:
+		return n.postSlack(c, event, text)
+	case lighthouse.ChatTeams:
+		return n.postTeams(c, event, text)
+	}
+	return fmt.Errorf("unknown chat kind %q", c.Kind)
+}
+
+// Message renders the message of the event with the template of the notification, or the default one
+func Message(c *lighthouse.ChatNotification, event *Event) (string, error) {
+	text, ok := c.Templates[event.Kind]
+	if !ok {
+		text = defaultTemplates[event.Kind]
+	}
+	t, err := template.New(event.Kind).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %v", event.Kind, err)
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, event); err != nil {
+		return "", fmt.Errorf("failed to execute %s template: %v", event.Kind, err)
+	}
+	return buf.String(), nil
+}
+
+// pullRequestKey identifies the pull request of the event, empty if the event is not about a pull request
+func pullRequestKey(event *Event) string {
+	if event.Number == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s#%d", event.Org, event.Repo, event.Number)
+}
+
+type slackMetadata struct {
+	EventType    string            `json:"event_type"`
+	EventPayload map[string]string `json:"event_payload"`
+}
+
+type slackMessage struct {
+	Channel  string         `json:"channel"`
+	Text     string         `json:"text"`
+	ThreadTS string         `json:"thread_ts,omitempty"`
+	Metadata *slackMetadata `json:"metadata,omitempty"`
+}
+
+type slackResponse struct {
+	OK       bool   `json:"ok"`
+	Error    string `json:"error"`
+	TS       string `json:"ts"`
+	Messages []struct {
+		TS       string         `json:"ts"`
+		Metadata *slackMetadata `json:"metadata"`
+	} `json:"messages"`
+}
+
+// postSlack posts the message to the Slack channel, in the thread of the pull request of the event if any.
+// The first message about a pull request starts its thread, and is tagged with metadata so that the other
+// lighthouse components find the thread in the channel history.
+func (n *Notifier) postSlack(c *lighthouse.ChatNotification, event *Event, text string) error {
+	token, err := n.secrets.Resolve(*c.TokenSecret)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the Slack token: %v", err)
+	}
+	msg := &slackMessage{Channel: c.Channel, Text: text}
+	pr := pullRequestKey(event)
+	if pr != "" {
+		msg.ThreadTS = n.slackThread(c.Channel, pr, token)
+		if msg.ThreadTS == "" {
+			msg.Metadata = &slackMetadata{EventType: threadEventType, EventPayload: map[string]string{"pull_request": pr}}
+		}
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, n.slackURL+"chat.postMessage", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp, err := n.slack(req, token)
+	if err != nil {
+		return err
+	}
+	if pr != "" && msg.ThreadTS == "" {
+		n.lock.Lock()
+		n.threads[c.Channel+"/"+pr] = resp.TS
+		n.lock.Unlock()
+	}
+	return nil
+}
+
+// slackThread returns the timestamp of the message starting the thread of the pull request in the channel,
+// or an empty string if there is none yet
+func (n *Notifier) slackThread(channel, pr, token string) string {
+	key := channel + "/" + pr
+	n.lock.Lock()
+	ts, ok := n.threads[key]
+	n.lock.Unlock()
+	if ok {
+		return ts
+	}
+
+	params := url.Values{}
+	params.Set("channel", channel)
+	params.Set("limit", fmt.Sprintf("%d", threadHistoryLimit))
+	params.Set("include_all_metadata", "true")
+	req, err := http.NewRequest(http.MethodGet, n.slackURL+"conversations.history?"+params.Encode(), nil)
+	if err != nil {
+		return ""
+	}
+	resp, err := n.slack(req, token)
+	if err != nil {
+		n.logger.WithError(err).WithField("channel", channel).Debug("failed to search the thread of the pull request")
+		return ""
+	}
+	for _, m := range resp.Messages {
+		if m.Metadata != nil && m.Metadata.EventType == threadEventType && m.Metadata.EventPayload["pull_request"] == pr {
+			n.lock.Lock()
+			n.threads[key] = m.TS
+			n.lock.Unlock()
+			return m.TS
+		}
+	}
+	return ""
+}
+
+func (n *Notifier) slack(req *http.Request, token string) (*slackResponse, error) {
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("response has status %q and body %q", resp.Status, string(body))
+	}
+	answer := &slackResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(answer); err != nil {
+		return nil, fmt.Errorf("failed to decode the Slack response: %v", err)
+	}
+	if !answer.OK {
+		return nil, fmt.Errorf("slack returned error %q", answer.Error)
+	}
+	return answer, nil
+}
+
+type teamsMessageCard struct {
+	Type    string `json:"@type"`
+	Context string `json:"@context"`
+	Summary string `json:"summary"`
+	Title   string `json:"title,omitempty"`
+	Text    string `json:"text"`
+}
+
+// postTeams posts the message to the Teams incoming webhook. Incoming webhooks cannot reply to a message,
+// so the messages about a pull request are titled with the pull request instead of being threaded.
+func (n *Notifier) postTeams(c *lighthouse.ChatNotification, event *Event, text string) error {
+	webhookURL, err := n.secrets.Resolve(*c.WebhookURLSecret)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the Teams webhook URL: %v", err)
+	}
+	card := &teamsMessageCard{
+		Type:    "MessageCard",
+		Context: "https://schema.org/extensions",
+		Summary: text,
+		Title:   pullRequestKey(event),
+		Text:    text,
+	}
+	data, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(webhookURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("response has status %q and body %q", resp.Status, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package notification
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testResolver(data map[string]string) *secret.Resolver {
+	return secret.NewResolver(func(name string) (*corev1.Secret, error) {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name}, Data: map[string][]byte{}}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return s, nil
+	}, time.Hour)
+}
+
+func TestMessage(t *testing.T) {
+	lhjob := &v1alpha1.LighthouseJob{
+		Spec: v1alpha1.LighthouseJobSpec{
+			Job:  "unit",
+			Refs: &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "master", Pulls: []v1alpha1.Pull{{Number: 5, Author: "bob"}}},
+		},
+		Status: v1alpha1.LighthouseJobStatus{Description: "Pipeline failed", ReportURL: "https://dashboard/unit"},
+	}
+	text, err := Message(&lighthouse.ChatNotification{}, JobFailed(lhjob))
+	require.NoError(t, err)
+	assert.Equal(t, "Job unit failed on org/repo#5: Pipeline failed https://dashboard/unit", text)
+
+	lhjob.Spec.Refs.Pulls = nil
+	text, err = Message(&lighthouse.ChatNotification{}, JobFailed(lhjob))
+	require.NoError(t, err)
+	assert.Equal(t, "Job unit failed on org/repo master: Pipeline failed https://dashboard/unit", text)
+
+	override := &Event{Kind: lighthouse.ChatEventOverride, Org: "org", Repo: "repo", Number: 5, Author: "alice", Contexts: []string{"lint", "unit"}}
+	text, err = Message(&lighthouse.ChatNotification{}, override)
+	require.NoError(t, err)
+	assert.Equal(t, "alice overrode lint, unit on org/repo#5", text)
+
+	custom := &lighthouse.ChatNotification{Templates: map[string]string{lighthouse.ChatEventOverride: ":warning: {{.Author}} forced {{len .Contexts}} contexts"}}
+	text, err = Message(custom, override)
+	require.NoError(t, err)
+	assert.Equal(t, ":warning: alice forced 2 contexts", text)
+}
+
+func TestNotifySlackThreads(t *testing.T) {
+	var posted []slackMessage
+	historyCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xoxb-token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/conversations.history":
+			historyCalls++
+			assert.Equal(t, "#ci", r.URL.Query().Get("channel"))
+			_, _ = w.Write([]byte(`{"ok": true, "messages": [{"ts": "100.1", "metadata": {"event_type": "lighthouse_pull_request", "event_payload": {"pull_request": "org/other#1"}}}]}`))
+		case "/chat.postMessage":
+			msg := slackMessage{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+			posted = append(posted, msg)
+			_, _ = w.Write([]byte(`{"ok": true, "ts": "200.2"}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	n := NewNotifier(nil, testResolver(map[string]string{"token": "xoxb-token"}))
+	n.slackURL = server.URL + "/"
+	notifications := []lighthouse.ChatNotification{
+		{Name: "ci", Kind: lighthouse.ChatSlack, Channel: "#ci", TokenSecret: &secret.Reference{Name: "slack", Key: "token"}, Repos: []string{"org"}},
+	}
+
+	merged := &Event{Kind: lighthouse.ChatEventMerged, Org: "org", Repo: "repo", Number: 3, Title: "Fix"}
+	require.NoError(t, n.Notify(notifications, merged))
+	require.NoError(t, n.Notify(notifications, &Event{Kind: lighthouse.ChatEventJobFailed, Org: "org", Repo: "repo", Number: 3, Job: "unit"}))
+	require.NoError(t, n.Notify(notifications, &Event{Kind: lighthouse.ChatEventJobFailed, Org: "org", Repo: "other", Number: 1, Job: "unit"}))
+	require.NoError(t, n.Notify(notifications, &Event{Kind: lighthouse.ChatEventMerged, Org: "elsewhere", Repo: "repo", Number: 3}))
+
+	require.Len(t, posted, 3)
+	assert.Equal(t, "org/repo#3 Fix was merged", posted[0].Text)
+	assert.Empty(t, posted[0].ThreadTS, "the first message should start the thread")
+	require.NotNil(t, posted[0].Metadata)
+	assert.Equal(t, "org/repo#3", posted[0].Metadata.EventPayload["pull_request"])
+	assert.Equal(t, "200.2", posted[1].ThreadTS, "the following messages should reply in the thread")
+	assert.Nil(t, posted[1].Metadata)
+	assert.Equal(t, "100.1", posted[2].ThreadTS, "the thread should be found in the channel history")
+	assert.Equal(t, 2, historyCalls, "the threads should be cached")
+}
+
+func TestNotifyTeams(t *testing.T) {
+	var cards []teamsMessageCard
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		card := teamsMessageCard{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&card))
+		cards = append(cards, card)
+	}))
+	defer server.Close()
+
+	n := NewNotifier(nil, testResolver(map[string]string{"url": server.URL}))
+	notifications := []lighthouse.ChatNotification{
+		{Name: "releases", Kind: lighthouse.ChatTeams, WebhookURLSecret: &secret.Reference{Name: "teams", Key: "url"}, Events: []string{lighthouse.ChatEventOverride}},
+	}
+	require.NoError(t, n.Notify(notifications, &Event{Kind: lighthouse.ChatEventOverride, Org: "org", Repo: "repo", Number: 2, Author: "alice", Contexts: []string{"unit"}}))
+	require.NoError(t, n.Notify(notifications, &Event{Kind: lighthouse.ChatEventMerged, Org: "org", Repo: "repo", Number: 2}))
+
+	require.Len(t, cards, 1)
+	assert.Equal(t, "MessageCard", cards[0].Type)
+	assert.Equal(t, "org/repo#2", cards[0].Title)
+	assert.Equal(t, "alice overrode unit on org/repo#2", cards[0].Text)
+}
+
+func TestNotifyError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+	}))
+	defer server.Close()
+
+	n := NewNotifier(nil, testResolver(map[string]string{"token": "xoxb-token"}))
+	n.slackURL = server.URL + "/"
+	notifications := []lighthouse.ChatNotification{
+		{Name: "ci", Kind: lighthouse.ChatSlack, Channel: "#missing", TokenSecret: &secret.Reference{Name: "slack", Key: "token"}},
+	}
+	err := n.Notify(notifications, &Event{Kind: lighthouse.ChatEventJobFailed, Org: "org", Repo: "repo", Job: "unit"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "channel_not_found")
+
+	var nilNotifier *Notifier
+	assert.NoError(t, nilNotifier.Notify(notifications, &Event{Kind: lighthouse.ChatEventJobFailed}))
+}
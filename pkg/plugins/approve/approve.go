@@ -31,6 +31,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/plugins/approve/approvers"
 	"github.com/jenkins-x/lighthouse/pkg/repoowners"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/stalereviews"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -147,6 +148,9 @@ func configHelp(config *plugins.Configuration, enabledRepos []string) (map[strin
 			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
 		}
 		approveConfig[repo] = fmt.Sprintf("Pull requests %s require an associated issue.<br>Pull request authors %s implicitly approve their own PRs.<br>The /lgtm [cancel] command(s) %s act as approval.<br>A GitHub approved or changes requested review %s act as approval or cancel respectively.", doNot(opts.IssueRequired), doNot(opts.HasSelfApproval()), willNot(opts.LgtmActsAsApprove), willNot(opts.ConsiderReviewState()))
+		if opts.ForbidSelfApproval {
+			approveConfig[repo] += "<br>Pull request authors may not approve their own PRs."
+		}
 	}
 	return approveConfig, nil
 }
@@ -431,7 +435,7 @@ func handle(log *logrus.Entry, spc scmProviderClient, repo approvers.Repo, baseU
 	// Author implicitly approves their own PR if config allows it
 	if opts.HasSelfApproval() {
 		approversHandler.AddAuthorSelfApprover(pr.author, pr.htmlURL+"#", false)
-	} else {
+	} else if !opts.ForbidSelfApproval {
 		// Treat the author as an assignee, and suggest them if possible
 		approversHandler.AddAssignees(pr.author)
 	}
@@ -443,6 +447,18 @@ func handle(log *logrus.Entry, spc scmProviderClient, repo approvers.Repo, baseU
 		return comments[i].Created.Before(comments[j].Created)
 	})
 	approveComments := filterComments(comments, approvalMatcher(botName, opts.LgtmActsAsApprove, opts.ConsiderReviewState()))
+	// the approvals dismissed by the stale-review plugin are ignored
+	if record := stalereviews.Find(reviewComments, botName); record != nil && record.Dismissed != nil {
+		approveComments = filterComments(approveComments, func(c *comment) bool {
+			return !record.DismissedBefore(c.Created)
+		})
+	}
+	// the authors may not approve their own PRs
+	if opts.ForbidSelfApproval {
+		approveComments = filterComments(approveComments, func(c *comment) bool {
+			return !strings.EqualFold(c.Author, pr.author)
+		})
+	}
 	addApprovers(&approversHandler, approveComments, pr.author, opts.ConsiderReviewState())
 
 	for _, user := range pr.assignees {
@@ -36,6 +36,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/plugins/approve/approvers"
 	"github.com/jenkins-x/lighthouse/pkg/repoowners"
+	"github.com/jenkins-x/lighthouse/pkg/stalereviews"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -166,6 +167,8 @@ func TestHandle(t *testing.T) {
 	// This function does not need to test IsApproved, that is tested in approvers/approvers_test.go.
 
 	testBotName := "k8s-ci-robot"
+	dismissed := time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC)
+	staleRecord := (&stalereviews.Record{SHA: "abcdef", Stale: []string{"a/a.go"}, Dismissed: &dismissed}).Format()
 
 	// includes tests with mixed case usernames
 	// includes tests with stale notifications
@@ -183,6 +186,7 @@ func TestHandle(t *testing.T) {
 		needsIssue          bool
 		lgtmActsAsApprove   bool
 		reviewActsAsApprove bool
+		forbidSelfApproval  bool
 		githubLinkURL       *url.URL
 
 		expectDelete    bool
@@ -489,6 +493,47 @@ Approvers can cancel approval by writing ` + "`/approve cancel`" + ` in a commen
 			expectToggle:  true,
 			expectComment: true,
 		},
+		{
+			name:     "remove approval dismissed by stale-review",
+			prBody:   "Changes the thing.\n fixes #42",
+			hasLabel: true,
+			files:    []string{"a/a.go"},
+			comments: []*scm.Comment{
+				newTestCommentTime(dismissed.Add(-time.Hour), "Alice", "/approve"),
+				newTestCommentTime(dismissed.Add(-time.Hour), "k8s-ci-robot", "[APPROVALNOTIFIER] This PR is **APPROVED**\n\nblah"),
+				newTestCommentTime(dismissed.Add(-time.Hour), "k8s-ci-robot", staleRecord),
+			},
+			reviews:             []*scm.Review{},
+			selfApprove:         true, // no-op test
+			needsIssue:          false,
+			lgtmActsAsApprove:   false,
+			reviewActsAsApprove: false,
+			githubLinkURL:       &url.URL{Scheme: "https", Host: "github.com"},
+
+			expectDelete:  true,
+			expectToggle:  true,
+			expectComment: true,
+		},
+		{
+			name:     "approve after dismissal by stale-review",
+			prBody:   "Changes the thing.\n fixes #42",
+			hasLabel: false,
+			files:    []string{"a/a.go"},
+			comments: []*scm.Comment{
+				newTestCommentTime(dismissed.Add(-time.Hour), "k8s-ci-robot", staleRecord),
+				newTestCommentTime(dismissed.Add(time.Hour), "Alice", "/approve"),
+			},
+			reviews:             []*scm.Review{},
+			selfApprove:         true, // no-op test
+			needsIssue:          false,
+			lgtmActsAsApprove:   false,
+			reviewActsAsApprove: false,
+			githubLinkURL:       &url.URL{Scheme: "https", Host: "github.com"},
+
+			expectDelete:  false,
+			expectToggle:  true,
+			expectComment: true,
+		},
 		{
 			name:     "cancel implicit self approve",
 			prBody:   "Changes the thing.\n fixes #42",
@@ -1087,6 +1132,42 @@ Approvers can indicate their approval by writing ` + "`/approve`" + ` in a comme
 Approvers can cancel approval by writing ` + "`/approve cancel`" + ` in a comment
 </details>
 <!-- META={"approvers":[]} -->`,
+		},
+		{
+			name:     "forbidden self approval",
+			hasLabel: false,
+			files:    []string{"c/c.go"},
+			comments: []*scm.Comment{
+				newTestComment("cjwagner", "/approve"),
+			},
+			reviews:             []*scm.Review{newTestReview("cjwagner", "", scm.ReviewStateApproved)},
+			selfApprove:         true,
+			needsIssue:          false,
+			lgtmActsAsApprove:   false,
+			reviewActsAsApprove: true,
+			forbidSelfApproval:  true,
+			githubLinkURL:       &url.URL{Scheme: "https", Host: "github.com"},
+
+			expectDelete:  false,
+			expectToggle:  false,
+			expectComment: true,
+			expectedComment: `[APPROVALNOTIFIER] This PR is **NOT APPROVED**
+
+This pull-request has been approved by:
+To complete the [pull request process](https://git.k8s.io/community/contributors/guide/owners.md#the-code-review-process), please assign **cblecker**
+You can assign the PR to them by writing ` + "`/assign @cblecker`" + ` in a comment when ready.
+
+The full list of commands accepted by this bot can be found [here](https://go.k8s.io/bot-commands?repo=org%2Frepo).
+
+<details open>
+Needs approval from an approver in each of these files:
+
+- **[c/OWNERS](https://github.com/org/repo/blob/master/c/OWNERS)**
+
+Approvers can indicate their approval by writing ` + "`/approve`" + ` in a comment
+Approvers can cancel approval by writing ` + "`/approve cancel`" + ` in a comment
+</details>
+<!-- META={"approvers":["cblecker"]} -->`,
 		},
 		{
 			name:     "remove approval with /lh-approve cancel with prefix",
@@ -1169,6 +1250,7 @@ Approvers can cancel approval by writing ` + "`/approve cancel`" + ` in a commen
 					IssueRequired:       test.needsIssue,
 					LgtmActsAsApprove:   test.lgtmActsAsApprove,
 					IgnoreReviewState:   &irs,
+					ForbidSelfApproval:  test.forbidSelfApproval,
 				},
 				&state{
 					org:       "org",
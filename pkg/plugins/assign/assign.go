@@ -17,20 +17,24 @@ limitations under the License.
 package assign
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	githubql "github.com/shurcooL/githubv4"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 const pluginName = "assign"
 
 var (
 	plugin = plugins.Plugin{
-		Description: "The assign plugin assigns or requests reviews from users. Specific users can be assigned with the command '/assign @user1' or have reviews requested of them with the command '/cc @user1'. If no user is specified the commands default to targeting the user who created the command. Assignments and requested reviews can be removed in the same way that they are added by prefixing the commands with 'un'.",
+		Description: "The assign plugin assigns or requests reviews from users. Specific users can be assigned with the command '/assign @user1' or have reviews requested of them with the command '/cc @user1'. If no user is specified the commands default to targeting the user who created the command. Assignments and requested reviews can be removed in the same way that they are added by prefixing the commands with 'un'. " +
+			"Teams, e.g. '/cc @org/sig-network', are expanded to their members. The users configured as unavailable, or whose status shows a limited availability if configured, are skipped, and the number of assignees can be capped.",
 		Commands: []plugins.Command{{
 			Prefix: "un",
 			Name:   "cc|assign",
@@ -38,7 +42,7 @@ var (
 				Pattern:  `@?"?[-/\w]+"?(?:[ \t]+@?"?[-/\w]+"?)*`,
 				Optional: true,
 			},
-			Description: "Assigns an assignee to the PR or issue or requests a review from the user(s)",
+			Description: "Assigns an assignee to the PR or issue or requests a review from the user(s) or the members of the team(s)",
 			Featured:    true,
 			WhoCanUse:   "Anyone can use the command, but the target user must be an org member, a repo collaborator, or should have previously commented on the issue or PR.",
 			Action: plugins.
@@ -63,12 +67,28 @@ type scmProviderClient interface {
 
 	CreateComment(owner, repo string, number int, pr bool, comment string) error
 	QuoteAuthorForComment(string) string
+
+	ListTeams(org string) ([]*scm.Team, error)
+	ListTeamMembers(id int, role string) ([]*scm.TeamMember, error)
+
+	Query(context.Context, interface{}, map[string]interface{}) error
+	SupportsGraphQL() bool
+}
+
+// userStatusQuery queries whether the status of a GitHub user indicates a limited availability
+type userStatusQuery struct {
+	User struct {
+		Status *struct {
+			IndicatesLimitedAvailability githubql.Boolean
+		}
+	} `graphql:"user(login: $login)"`
 }
 
 func handleGenericComment(add bool, command string, arg string, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
-	err := handle(add, command, arg, newAssignHandler(e, pc.SCMProviderClient, pc.Logger))
+	config := pc.PluginConfig.AssignFor(e.Repo.Namespace, e.Repo.Name)
+	err := handle(add, command, arg, newAssignHandler(e, pc.SCMProviderClient, config, pc.Logger))
 	if e.IsPR {
-		err = combineErrors(err, handle(add, command, arg, newReviewHandler(e, pc.SCMProviderClient, pc.Logger)))
+		err = combineErrors(err, handle(add, command, arg, newReviewHandler(e, pc.SCMProviderClient, config, pc.Logger)))
 	}
 	return err
 }
@@ -95,10 +115,11 @@ func combineErrors(err1, err2 error) error {
 	}
 }
 
-// handle is the generic handler for the assign plugin. It uses the handler's regexp and affectedLogins
-// functions to identify the users to add and/or remove and then passes the appropriate users to the
-// handler's add and remove functions. If add fails to add some of the users, a response comment is
-// created where the body of the response is generated by the handler's addFailureResponse function.
+// handle is the generic handler for the assign plugin. It identifies the users to add or remove, expanding
+// the teams to their members, skips the unavailable users and those beyond the maximum number of users of the
+// handler, and then passes the users to the handler's add or remove function. If add fails to add some of the
+// users, a response comment is created where the body of the response is generated by the handler's
+// addFailureResponse function, along with the users which were skipped.
 func handle(add bool, command string, arg string, h *handler) error {
 	e := h.event
 	org := e.Repo.Namespace
@@ -106,50 +127,167 @@ func handle(add bool, command string, arg string, h *handler) error {
 	if command != h.command {
 		return nil
 	}
-	users := make(map[string]bool)
-	if arg == "" {
-		users[e.Author.Login] = add
-	} else {
-		for _, login := range parseLogins(arg) {
-			users[login] = add
-		}
+	logins := []string{e.Author.Login}
+	if arg != "" {
+		logins = h.expandTeams(parseLogins(arg))
 	}
 
-	var toAdd, toRemove []string
-	for login, add := range users {
-		if add {
-			toAdd = append(toAdd, login)
-		} else {
-			toRemove = append(toRemove, login)
-		}
+	if !add {
+		h.log.Printf("Removing %s from %s/%s#%d: %v", h.userType, org, repo, e.Number, logins)
+		return h.remove(org, repo, e.Number, logins)
 	}
 
-	if len(toRemove) > 0 {
-		h.log.Printf("Removing %s from %s/%s#%d: %v", h.userType, org, repo, e.Number, toRemove)
-		if err := h.remove(org, repo, e.Number, toRemove); err != nil {
-			return err
+	var notes []string
+	toAdd := logins
+	if arg != "" {
+		var unavailable []string
+		toAdd, unavailable = h.filterUnavailable(toAdd)
+		if len(unavailable) > 0 {
+			notes = append(notes, fmt.Sprintf("The following users are unavailable and were skipped: %s.", strings.Join(unavailable, ", ")))
+		}
+	}
+	if h.maxUsers > 0 {
+		var overflow []string
+		toAdd, overflow = h.capUsers(toAdd)
+		if len(overflow) > 0 {
+			notes = append(notes, fmt.Sprintf("The following users were skipped as there can be at most %d %s: %s.", h.maxUsers, h.userType, strings.Join(overflow, ", ")))
 		}
 	}
+
 	if len(toAdd) > 0 {
 		h.log.Printf("Adding %s to %s/%s#%d: %v", h.userType, org, repo, e.Number, toAdd)
 		if err := h.add(org, repo, e.Number, toAdd); err != nil {
-			if mu, ok := err.(scmprovider.MissingUsers); ok {
-				msg := h.addFailureResponse(mu)
-				if len(msg) == 0 {
-					return nil
-				}
-				if err := h.spc.CreateComment(org, repo, e.Number, e.IsPR,
-					plugins.FormatResponseRaw(e.Body, e.Link, h.spc.QuoteAuthorForComment(e.Author.Login), msg)); err != nil {
-					return fmt.Errorf("comment err: %v", err)
-				}
-				return nil
+			mu, ok := err.(scmprovider.MissingUsers)
+			if !ok {
+				return err
+			}
+			if msg := h.addFailureResponse(mu); len(msg) > 0 {
+				notes = append(notes, msg)
 			}
-			return err
 		}
 	}
+	if len(notes) == 0 {
+		return nil
+	}
+	if err := h.spc.CreateComment(org, repo, e.Number, e.IsPR,
+		plugins.FormatResponseRaw(e.Body, e.Link, h.spc.QuoteAuthorForComment(e.Author.Login), strings.Join(notes, "\n\n"))); err != nil {
+		return fmt.Errorf("comment err: %v", err)
+	}
 	return nil
 }
 
+// expandTeams replaces the teams, e.g. org/sig-network, by their members, removing the duplicates. The teams which
+// cannot be found are kept as they are for the git provider to resolve them, e.g. GitHub requesting a review from
+// the team itself.
+func (h *handler) expandTeams(logins []string) []string {
+	var users []string
+	seen := sets.NewString()
+	addUser := func(login string) {
+		if key := scmprovider.NormLogin(login); !seen.Has(key) {
+			seen.Insert(key)
+			users = append(users, login)
+		}
+	}
+	for _, login := range logins {
+		members, err := h.teamMembers(login)
+		if err != nil {
+			h.log.WithError(err).Warnf("Failed to get the members of the team %s", login)
+		}
+		if len(members) == 0 {
+			addUser(login)
+			continue
+		}
+		for _, member := range members {
+			addUser(member)
+		}
+	}
+	return users
+}
+
+// teamMembers returns the logins of the members of a team of the form org/team, matching the slug or the name of
+// the team, nil if the login is not a team or the team cannot be found
+func (h *handler) teamMembers(login string) ([]string, error) {
+	parts := strings.SplitN(login, "/", 2)
+	if len(parts) != 2 {
+		return nil, nil
+	}
+	teams, err := h.spc.ListTeams(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range teams {
+		if !strings.EqualFold(team.Slug, parts[1]) && !strings.EqualFold(team.Name, parts[1]) {
+			continue
+		}
+		members, err := h.spc.ListTeamMembers(team.ID, scmprovider.RoleAll)
+		if err != nil {
+			return nil, err
+		}
+		var logins []string
+		for _, member := range members {
+			logins = append(logins, member.Login)
+		}
+		return logins, nil
+	}
+	return nil, nil
+}
+
+// filterUnavailable splits the users between the available ones and those configured as unavailable or, if
+// configured, whose status indicates a limited availability
+func (h *handler) filterUnavailable(logins []string) (available, unavailable []string) {
+	configured := sets.NewString()
+	for _, login := range h.config.Unavailable {
+		configured.Insert(scmprovider.NormLogin(login))
+	}
+	for _, login := range logins {
+		if configured.Has(scmprovider.NormLogin(login)) || h.isBusy(login) {
+			unavailable = append(unavailable, login)
+		} else {
+			available = append(available, login)
+		}
+	}
+	return available, unavailable
+}
+
+// isBusy tells whether the status of the user indicates a limited availability, if the busy users are skipped and
+// the git provider supports it
+func (h *handler) isBusy(login string) bool {
+	if !h.config.SkipBusyUsers || strings.Contains(login, "/") || !h.spc.SupportsGraphQL() {
+		return false
+	}
+	q := &userStatusQuery{}
+	vars := map[string]interface{}{
+		"login": githubql.String(login),
+	}
+	if err := h.spc.Query(context.Background(), q, vars); err != nil {
+		h.log.WithError(err).Warnf("Failed to get the status of %s", login)
+		return false
+	}
+	return q.User.Status != nil && bool(q.User.Status.IndicatesLimitedAvailability)
+}
+
+// capUsers splits the users between those which can be added without exceeding the maximum number of users of
+// the handler, given the current ones, and the overflowing ones
+func (h *handler) capUsers(logins []string) (kept, overflow []string) {
+	current := sets.NewString()
+	for _, login := range h.current {
+		current.Insert(scmprovider.NormLogin(login))
+	}
+	capacity := h.maxUsers - current.Len()
+	for _, login := range logins {
+		switch {
+		case current.Has(scmprovider.NormLogin(login)):
+			kept = append(kept, login)
+		case capacity > 0:
+			kept = append(kept, login)
+			capacity--
+		default:
+			overflow = append(overflow, login)
+		}
+	}
+	return kept, overflow
+}
+
 // handler is a struct that contains data about a github event and provides functions to help handle it.
 type handler struct {
 	// addFailureResponse generates the body of a response comment in the event that the add function fails.
@@ -166,17 +304,28 @@ type handler struct {
 	// spc is the scmProviderClient to use for creating response comments in the event of a failure.
 	spc scmProviderClient
 
+	// config is the configuration of the assign plugin for the repository.
+	config *plugins.Assign
+	// maxUsers is the maximum number of users the handler adds, there is no maximum if zero.
+	maxUsers int
+	// current are the users already added, counted against maxUsers.
+	current []string
+
 	// log is a logrus.Entry used to record actions the handler takes.
 	log *logrus.Entry
 	// userType is a string that represents the type of users affected by this handler. (e.g. 'assignees')
 	userType string
 }
 
-func newAssignHandler(e scmprovider.GenericCommentEvent, spc scmProviderClient, log *logrus.Entry) *handler {
+func newAssignHandler(e scmprovider.GenericCommentEvent, spc scmProviderClient, config *plugins.Assign, log *logrus.Entry) *handler {
 	org := e.Repo.Namespace
 	addFailureResponse := func(mu scmprovider.MissingUsers) string {
 		return fmt.Sprintf("GitHub didn't allow me to assign the following users: %s.\n\nNote that only [%s members](https://github.com/orgs/%s/people), repo collaborators and people who have commented on this issue/PR can be assigned. Additionally, issues/PRs can only have 10 assignees at the same time.\nFor more information please see [the contributor guide](https://git.k8s.io/community/contributors/guide/#issue-assignment-in-github)", strings.Join(mu.Users, ", "), org, org)
 	}
+	var current []string
+	for _, assignee := range e.Assignees {
+		current = append(current, assignee.Login)
+	}
 
 	return &handler{
 		addFailureResponse: addFailureResponse,
@@ -185,12 +334,15 @@ func newAssignHandler(e scmprovider.GenericCommentEvent, spc scmProviderClient,
 		event:              &e,
 		command:            "assign",
 		spc:                spc,
+		config:             config,
+		maxUsers:           config.MaxAssignees,
+		current:            current,
 		log:                log,
 		userType:           "assignee(s)",
 	}
 }
 
-func newReviewHandler(e scmprovider.GenericCommentEvent, spc scmProviderClient, log *logrus.Entry) *handler {
+func newReviewHandler(e scmprovider.GenericCommentEvent, spc scmProviderClient, config *plugins.Assign, log *logrus.Entry) *handler {
 	org := e.Repo.Namespace
 	addFailureResponse := func(mu scmprovider.MissingUsers) string {
 		return fmt.Sprintf("GitHub didn't allow me to request PR reviews from the following users: %s.\n\nNote that only [%s members](https://github.com/orgs/%s/people) and repo collaborators can review this PR, and authors cannot review their own PRs.", strings.Join(mu.Users, ", "), org, org)
@@ -203,6 +355,7 @@ func newReviewHandler(e scmprovider.GenericCommentEvent, spc scmProviderClient,
 		event:              &e,
 		command:            "cc",
 		spc:                spc,
+		config:             config,
 		log:                log,
 		userType:           "reviewer(s)",
 	}
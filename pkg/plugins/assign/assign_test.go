@@ -17,12 +17,18 @@ limitations under the License.
 package assign
 
 import (
+	"context"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	githubql "github.com/shurcooL/githubv4"
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 type fakeClient struct {
@@ -33,7 +39,11 @@ type fakeClient struct {
 	unrequested  map[string]int
 	contributors map[string]bool
 
+	teams map[string][]string
+	busy  map[string]bool
+
 	commented bool
+	comment   string
 }
 
 func (c *fakeClient) UnassignIssue(owner, repo string, number int, assignees []string) error {
@@ -94,9 +104,42 @@ func (c *fakeClient) UnrequestReview(org, repo string, number int, logins []stri
 
 func (c *fakeClient) CreateComment(owner, repo string, number int, pr bool, comment string) error {
 	c.commented = comment != ""
+	c.comment = comment
+	return nil
+}
+
+func (c *fakeClient) ListTeams(org string) ([]*scm.Team, error) {
+	var teams []*scm.Team
+	for i, name := range sets.StringKeySet(c.teams).List() {
+		parts := strings.SplitN(name, "/", 2)
+		if parts[0] == org {
+			teams = append(teams, &scm.Team{ID: i, Slug: parts[1], Name: parts[1]})
+		}
+	}
+	return teams, nil
+}
+
+func (c *fakeClient) ListTeamMembers(id int, role string) ([]*scm.TeamMember, error) {
+	var members []*scm.TeamMember
+	for _, login := range c.teams[sets.StringKeySet(c.teams).List()[id]] {
+		members = append(members, &scm.TeamMember{Login: login})
+	}
+	return members, nil
+}
+
+func (c *fakeClient) Query(ctx context.Context, q interface{}, vars map[string]interface{}) error {
+	if q, ok := q.(*userStatusQuery); ok {
+		q.User.Status = &struct {
+			IndicatesLimitedAvailability githubql.Boolean
+		}{githubql.Boolean(c.busy[string(vars["login"].(githubql.String))])}
+	}
 	return nil
 }
 
+func (c *fakeClient) SupportsGraphQL() bool {
+	return true
+}
+
 func (c *fakeClient) QuoteAuthorForComment(author string) string {
 	return author
 }
@@ -108,6 +151,8 @@ func newFakeClient(contribs []string) *fakeClient {
 		unrequested:  make(map[string]int),
 		assigned:     make(map[string]int),
 		unassigned:   make(map[string]int),
+		teams:        make(map[string][]string),
+		busy:         make(map[string]bool),
 	}
 	for _, user := range contribs {
 		c.contributors[user] = true
@@ -425,10 +470,10 @@ func TestAssignAndReview(t *testing.T) {
 				t.Fatalf("(%s): Unexpected error from handle: %v.", tc.name, err)
 			}
 			for _, m := range matches {
-				if err := handle(m.Prefix != "un", m.Name, m.Arg, newAssignHandler(e, fc, logrus.WithField("plugin", pluginName))); err != nil {
+				if err := handle(m.Prefix != "un", m.Name, m.Arg, newAssignHandler(e, fc, &plugins.Assign{}, logrus.WithField("plugin", pluginName))); err != nil {
 					t.Fatalf("For case %s, didn't expect error from handle: %v", tc.name, err)
 				}
-				if err := handle(m.Prefix != "un", m.Name, m.Arg, newReviewHandler(e, fc, logrus.WithField("plugin", pluginName))); err != nil {
+				if err := handle(m.Prefix != "un", m.Name, m.Arg, newReviewHandler(e, fc, &plugins.Assign{}, logrus.WithField("plugin", pluginName))); err != nil {
 					t.Fatalf("For case %s, didn't expect error from handle: %v", tc.name, err)
 				}
 			}
@@ -481,3 +526,111 @@ func TestAssignAndReview(t *testing.T) {
 		})
 	}
 }
+
+func TestTeamsAndAvailability(t *testing.T) {
+	var testcases = []struct {
+		name      string
+		body      string
+		config    plugins.Assign
+		assignees []string
+		assigned  []string
+		requested []string
+		comment   string
+	}{
+		{
+			name:      "request review from the members of a team",
+			body:      "/cc @org/sig-network",
+			requested: []string{"alice", "bob"},
+		},
+		{
+			name:      "request review from a team which cannot be found",
+			body:      "/cc @org/unknown",
+			requested: []string{"org/unknown"},
+		},
+		{
+			name:     "assign the members of a team and a member twice",
+			body:     "/assign @org/sig-network @Alice",
+			assigned: []string{"alice", "bob"},
+		},
+		{
+			name:      "skip the unavailable users",
+			body:      "/cc @org/sig-network @carol",
+			config:    plugins.Assign{Unavailable: []string{"Bob"}},
+			requested: []string{"alice", "carol"},
+			comment:   "The following users are unavailable and were skipped: bob.",
+		},
+		{
+			name:      "skip the busy users",
+			body:      "/cc @org/sig-network @carol",
+			config:    plugins.Assign{SkipBusyUsers: true},
+			requested: []string{"alice", "bob"},
+			comment:   "The following users are unavailable and were skipped: carol.",
+		},
+		{
+			name:      "busy users are not skipped unless configured",
+			body:      "/cc @carol",
+			requested: []string{"carol"},
+		},
+		{
+			name:     "assign yourself when unavailable",
+			body:     "/assign",
+			config:   plugins.Assign{Unavailable: []string{"rando"}},
+			assigned: []string{"rando"},
+		},
+		{
+			name:      "cap the assignees",
+			body:      "/assign @org/sig-network @carol",
+			config:    plugins.Assign{MaxAssignees: 2},
+			assignees: []string{"dave"},
+			assigned:  []string{"alice"},
+			comment:   "The following users were skipped as there can be at most 2 assignee(s): bob, carol.",
+		},
+		{
+			name:      "current assignees do not overflow",
+			body:      "/assign @dave @alice",
+			config:    plugins.Assign{MaxAssignees: 1},
+			assignees: []string{"dave"},
+			assigned:  []string{"dave"},
+			comment:   "The following users were skipped as there can be at most 1 assignee(s): alice.",
+		},
+		{
+			name:      "reviews are not capped",
+			body:      "/cc @org/sig-network @carol",
+			config:    plugins.Assign{MaxAssignees: 1},
+			requested: []string{"alice", "bob", "carol"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := newFakeClient([]string{"alice", "bob", "carol", "org/unknown"})
+			fc.teams["org/sig-network"] = []string{"alice", "bob"}
+			fc.teams["org/sig-storage"] = []string{"erin"}
+			fc.busy["carol"] = true
+			e := scmprovider.GenericCommentEvent{
+				Action: scm.ActionCreate,
+				Body:   tc.body,
+				Author: scm.User{Login: "rando"},
+				Repo:   scm.Repository{Name: "repo", Namespace: "org"},
+				Number: 5,
+				IsPR:   true,
+			}
+			for _, login := range tc.assignees {
+				e.Assignees = append(e.Assignees, scm.User{Login: login})
+			}
+			matches, err := plugin.Commands[0].FilterAndGetMatches(&e)
+			assert.NoError(t, err)
+			for _, m := range matches {
+				assert.NoError(t, handle(m.Prefix != "un", m.Name, m.Arg, newAssignHandler(e, fc, &tc.config, logrus.WithField("plugin", pluginName))))
+				assert.NoError(t, handle(m.Prefix != "un", m.Name, m.Arg, newReviewHandler(e, fc, &tc.config, logrus.WithField("plugin", pluginName))))
+			}
+
+			assert.ElementsMatch(t, tc.assigned, sets.StringKeySet(fc.assigned).List())
+			assert.ElementsMatch(t, tc.requested, sets.StringKeySet(fc.requested).List())
+			if tc.comment == "" {
+				assert.False(t, fc.commented, fc.comment)
+			} else {
+				assert.Contains(t, fc.comment, tc.comment)
+			}
+		})
+	}
+}
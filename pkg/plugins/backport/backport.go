@@ -0,0 +1,209 @@
+// Package backport contains a plugin which backports the pull requests labelled with `backport/<branch>` to the
+// given branches once they are merged, by cherry-picking their merge commit onto a new branch and opening a pull
+// request targeting each branch. The status of each backport is tracked in a single comment on the pull request.
+package backport
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/git"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pluginName = "backport"
+	// queued is the status of the backports of a pull request which is not merged yet
+	queued = "queued, the pull request is cherry-picked once it is merged"
+	// createdPrefix prefixes the status of the backports whose pull request was created
+	createdPrefix = "created "
+)
+
+var (
+	// marker identifies the comment tracking the backports of a pull request
+	marker = botcomment.Marker(pluginName)
+	// statusRe matches the status of the backport to a branch in the tracking comment
+	statusRe = regexp.MustCompile("(?m)^- `([^`]+)`: (.+)$")
+)
+
+type scmProviderClient interface {
+	botcomment.ListingClient
+	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	CreatePullRequest(org, repo string, input *scm.PullRequestInput) (*scm.PullRequest, error)
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		pluginName,
+		plugins.Plugin{
+			Description: "The backport plugin cherry-picks the pull requests labelled with '" + labels.BackportPrefix + "<branch>' onto the given branches once they are merged, " +
+				"and opens a pull request targeting each branch. The status of the backports is tracked in a comment on the pull request.",
+			PullRequestHandler: handlePullRequest,
+		},
+	)
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	switch pre.Action {
+	case scm.ActionLabel, scm.ActionUnlabel:
+		if !strings.HasPrefix(pre.Label.Name, labels.BackportPrefix) {
+			return nil
+		}
+	case scm.ActionClose:
+		if !pre.PullRequest.Merged {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return handle(pc.SCMProviderClient, pc.GitClient, pc.Logger, pre.Repo.Namespace, pre.Repo.Name, pre.PullRequest.Number)
+}
+
+// handle backports the merged pull request to the branches of its backport labels which were not backported yet,
+// or queues the backports until it is merged, and updates the tracking comment
+func handle(spc scmProviderClient, gc git.Client, log *logrus.Entry, org, repo string, number int) error {
+	pr, err := spc.GetPullRequest(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to get %s/%s PR #%d: %v", org, repo, number, err)
+	}
+	issueLabels, err := spc.GetIssueLabels(org, repo, number, true)
+	if err != nil {
+		return fmt.Errorf("failed to get the labels of %s/%s PR #%d: %v", org, repo, number, err)
+	}
+	botName, err := spc.BotName()
+	if err != nil {
+		return fmt.Errorf("failed to get the bot name: %v", err)
+	}
+	comments, err := spc.ListPullRequestComments(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to list the comments of %s/%s PR #%d: %v", org, repo, number, err)
+	}
+	previous := map[string]string{}
+	if existing := botcomment.Find(comments, botName, marker); len(existing) > 0 {
+		previous = parseStatuses(existing[len(existing)-1].Body)
+	}
+
+	statuses := map[string]string{}
+	for branch, status := range previous {
+		// the created backports are kept even if their label is removed
+		if strings.HasPrefix(status, createdPrefix) {
+			statuses[branch] = status
+		}
+	}
+	for _, l := range issueLabels {
+		branch := strings.TrimPrefix(l.Name, labels.BackportPrefix)
+		if branch == l.Name || branch == "" || statuses[branch] != "" {
+			continue
+		}
+		// the failed backports are retried by removing and adding their label again
+		if status := previous[branch]; pr.Merged && status != "" && status != queued {
+			statuses[branch] = status
+			continue
+		}
+		switch {
+		case !pr.Merged:
+			statuses[branch] = queued
+		case pr.MergeSha == "":
+			statuses[branch] = "failed, the merge commit of the pull request is unknown"
+		default:
+			statuses[branch] = backport(spc, gc, log, org, repo, pr, branch, botName)
+		}
+	}
+	return botcomment.Sync(spc, org, repo, number, true, comments, botName, marker, trackingComment(statuses))
+}
+
+// backport cherry-picks the merge commit of the pull request onto a new branch and opens a pull request targeting
+// the given branch, returning the status of the backport
+func backport(spc scmProviderClient, gc git.Client, log *logrus.Entry, org, repo string, pr *scm.PullRequest, branch, botName string) string {
+	log = log.WithFields(logrus.Fields{"org": org, "repo": repo, "pr": pr.Number, "branch": branch})
+	r, err := gc.Clone(org + "/" + repo)
+	if err != nil {
+		log.WithError(err).Warn("failed to clone the repository")
+		return "failed to clone the repository"
+	}
+	defer func() {
+		if err := r.Clean(); err != nil {
+			log.WithError(err).Warn("failed to clean the clone")
+		}
+	}()
+	if err := r.Config("user.name", botName); err != nil {
+		log.WithError(err).Warn("failed to configure the git user")
+		return "failed to configure the git user"
+	}
+	if err := r.Config("user.email", botName+"@localhost"); err != nil {
+		log.WithError(err).Warn("failed to configure the git user")
+		return "failed to configure the git user"
+	}
+	if err := r.Config("commit.gpgsign", "false"); err != nil {
+		log.Warningf("Cannot set gpgsign=false in gitconfig: %v", err)
+	}
+
+	if err := r.Checkout("origin/" + branch); err != nil {
+		log.WithError(err).Warn("failed to check out the target branch")
+		return fmt.Sprintf("failed, the `%s` branch does not exist", branch)
+	}
+	newBranch := fmt.Sprintf("backport-%d-to-%s", pr.Number, branch)
+	if err := r.CheckoutNewBranch(newBranch); err != nil {
+		log.WithError(err).Warn("failed to create the backport branch")
+		return "failed to create the backport branch"
+	}
+	ok, conflicts, err := r.CherryPick(pr.MergeSha)
+	if err != nil {
+		log.WithError(err).Warn("failed to cherry-pick the merge commit")
+		return "failed to cherry-pick the merge commit"
+	}
+	if !ok {
+		if len(conflicts) == 0 {
+			return "failed, the pull request cannot be cherry-picked cleanly"
+		}
+		return fmt.Sprintf("failed, the pull request cannot be cherry-picked cleanly, conflicts in `%s`", strings.Join(conflicts, "`, `"))
+	}
+	if err := r.ForcePush(org+"/"+repo, newBranch, ""); err != nil {
+		log.WithError(err).Warn("failed to push the backport branch")
+		return fmt.Sprintf("failed to push the `%s` branch", newBranch)
+	}
+	created, err := spc.CreatePullRequest(org, repo, &scm.PullRequestInput{
+		Title: fmt.Sprintf("[%s] %s", branch, pr.Title),
+		Head:  newBranch,
+		Base:  branch,
+		Body:  fmt.Sprintf("Backport of #%d to `%s`.", pr.Number, branch),
+	})
+	if err != nil {
+		log.WithError(err).Warn("failed to create the backport pull request")
+		return fmt.Sprintf("failed to create the pull request from the `%s` branch", newBranch)
+	}
+	log.Infof("Created the backport PR #%d", created.Number)
+	return fmt.Sprintf("%s#%d", createdPrefix, created.Number)
+}
+
+// parseStatuses returns the statuses of the backports per target branch listed in the tracking comment
+func parseStatuses(body string) map[string]string {
+	statuses := map[string]string{}
+	for _, m := range statusRe.FindAllStringSubmatch(body, -1) {
+		statuses[m[1]] = m[2]
+	}
+	return statuses
+}
+
+func trackingComment(statuses map[string]string) string {
+	if len(statuses) == 0 {
+		return ""
+	}
+	var branches []string
+	for branch := range statuses {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+	body := "Backports of this pull request:\n\n"
+	for _, branch := range branches {
+		body += fmt.Sprintf("- `%s`: %s\n", branch, statuses[branch])
+	}
+	return body + fmt.Sprintf("\nAdd or remove the `%s<branch>` labels to change the target branches.", labels.BackportPrefix)
+}
@@ -0,0 +1,145 @@
+package backport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/git/localgit"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type fakeClient struct {
+	pr       *scm.PullRequest
+	labels   sets.String
+	comments []*scm.Comment
+	created  []*scm.PullRequestInput
+}
+
+func (f *fakeClient) BotName() (string, error) {
+	return "bot", nil
+}
+
+func (f *fakeClient) CreateComment(_, _ string, _ int, _ bool, comment string) error {
+	f.comments = append(f.comments, &scm.Comment{ID: len(f.comments) + 1, Author: scm.User{Login: "bot"}, Body: comment})
+	return nil
+}
+
+func (f *fakeClient) EditComment(_, _ string, _, id int, comment string, _ bool) error {
+	for _, c := range f.comments {
+		if c.ID == id {
+			c.Body = comment
+			return nil
+		}
+	}
+	return errors.New("no such comment")
+}
+
+func (f *fakeClient) DeleteComment(_, _ string, _, id int, _ bool) error {
+	for i, c := range f.comments {
+		if c.ID == id {
+			f.comments = append(f.comments[:i], f.comments[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("no such comment")
+}
+
+func (f *fakeClient) ListIssueComments(string, string, int) ([]*scm.Comment, error) {
+	return f.comments, nil
+}
+
+func (f *fakeClient) ListPullRequestComments(string, string, int) ([]*scm.Comment, error) {
+	return f.comments, nil
+}
+
+func (f *fakeClient) GetPullRequest(string, string, int) (*scm.PullRequest, error) {
+	return f.pr, nil
+}
+
+func (f *fakeClient) GetIssueLabels(string, string, int, bool) ([]*scm.Label, error) {
+	var issueLabels []*scm.Label
+	for _, l := range f.labels.List() {
+		issueLabels = append(issueLabels, &scm.Label{Name: l})
+	}
+	return issueLabels, nil
+}
+
+func (f *fakeClient) CreatePullRequest(_, _ string, input *scm.PullRequestInput) (*scm.PullRequest, error) {
+	f.created = append(f.created, input)
+	return &scm.PullRequest{Number: 100 + len(f.created), Title: input.Title}, nil
+}
+
+func TestHandle(t *testing.T) {
+	lg, gc, err := localgit.New()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, lg.Clean())
+		require.NoError(t, gc.Clean())
+	}()
+	require.NoError(t, lg.MakeFakeRepo("org", "repo"))
+	require.NoError(t, lg.CheckoutNewBranch("org", "repo", "develop"))
+	require.NoError(t, lg.CheckoutNewBranch("org", "repo", "release-1.2"))
+	require.NoError(t, lg.Checkout("org", "repo", "develop"))
+	require.NoError(t, lg.CheckoutNewBranch("org", "repo", "release-1.1"))
+	require.NoError(t, lg.AddCommit("org", "repo", map[string][]byte{"feature": []byte("old")}))
+	require.NoError(t, lg.Checkout("org", "repo", "develop"))
+	require.NoError(t, lg.AddCommit("org", "repo", map[string][]byte{"feature": []byte("new")}))
+	mergeSha, err := lg.RevParse("org", "repo", "develop")
+	require.NoError(t, err)
+	release, err := lg.RevParse("org", "repo", "release-1.2")
+	require.NoError(t, err)
+
+	spc := &fakeClient{
+		pr:     &scm.PullRequest{Number: 1, Title: "Add the feature", Base: scm.PullRequestBranch{Ref: "develop"}},
+		labels: sets.NewString("backport/release-1.1", "backport/release-1.2", "backport/missing", "kind/bug"),
+	}
+	log := logrus.WithField("plugin", pluginName)
+
+	require.NoError(t, handle(spc, gc, log, "org", "repo", 1))
+	require.Len(t, spc.comments, 1)
+	assert.Equal(t, map[string]string{
+		"missing":     queued,
+		"release-1.1": queued,
+		"release-1.2": queued,
+	}, parseStatuses(spc.comments[0].Body))
+	assert.Empty(t, spc.created)
+
+	spc.pr.Merged = true
+	spc.pr.MergeSha = strings.TrimSpace(mergeSha)
+	require.NoError(t, handle(spc, gc, log, "org", "repo", 1))
+	require.Len(t, spc.comments, 1)
+	assert.Equal(t, map[string]string{
+		"missing":     "failed, the `missing` branch does not exist",
+		"release-1.1": "failed, the pull request cannot be cherry-picked cleanly, conflicts in `feature`",
+		"release-1.2": "created #101",
+	}, parseStatuses(spc.comments[0].Body))
+	require.Len(t, spc.created, 1)
+	assert.Equal(t, &scm.PullRequestInput{
+		Title: "[release-1.2] Add the feature",
+		Head:  "backport-1-to-release-1.2",
+		Base:  "release-1.2",
+		Body:  "Backport of #1 to `release-1.2`.",
+	}, spc.created[0])
+	parent, err := lg.RevParse("org", "repo", "backport-1-to-release-1.2^")
+	require.NoError(t, err)
+	assert.Equal(t, release, parent)
+
+	// the backports are not attempted again
+	body := spc.comments[0].Body
+	require.NoError(t, handle(spc, gc, log, "org", "repo", 1))
+	assert.Equal(t, body, spc.comments[0].Body)
+	assert.Len(t, spc.created, 1)
+
+	// the created backports are kept when their label is removed
+	spc.labels.Delete("backport/release-1.1", "backport/release-1.2")
+	require.NoError(t, handle(spc, gc, log, "org", "repo", 1))
+	assert.Equal(t, map[string]string{
+		"missing":     "failed, the `missing` branch does not exist",
+		"release-1.2": "created #101",
+	}, parseStatuses(spc.comments[0].Body))
+}
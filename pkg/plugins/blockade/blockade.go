@@ -30,6 +30,7 @@ import (
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
 	"github.com/jenkins-x/lighthouse/pkg/labels"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/sirupsen/logrus"
@@ -41,13 +42,16 @@ const (
 
 var blockedPathsBody = fmt.Sprintf("Adding label: `%s` because PR changes a protected file.", labels.BlockedPaths)
 
+// blockedPathsMarker identifies the sticky comment explaining why the PR is blocked
+var blockedPathsMarker = botcomment.Marker(pluginName)
+
 type scmProviderClient interface {
 	GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error)
 	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
 	AddLabel(owner, repo string, number int, label string, pr bool) error
 	RemoveLabel(owner, repo string, number int, label string, pr bool) error
-	CreateComment(org, repo string, number int, pr bool, comment string) error
 	QuoteAuthorForComment(string) string
+	botcomment.ListingClient
 }
 
 type pruneClient interface {
@@ -168,7 +172,7 @@ func handle(spc scmProviderClient, log *logrus.Entry, config []plugins.Blockade,
 			return err
 		}
 		msg := plugins.FormatResponse(spc.QuoteAuthorForComment(pre.PullRequest.Author.Login), blockedPathsBody, sum.String())
-		return spc.CreateComment(org, repo, prNumber, true, msg)
+		return botcomment.Upsert(spc, org, repo, prNumber, true, blockedPathsMarker, msg)
 	} else if !shouldBlock && labelPresent {
 		// Remove the label and delete any comments created by this plugin.
 		if err := spc.RemoveLabel(org, repo, prNumber, labels.BlockedPaths, true); err != nil {
@@ -22,6 +22,7 @@ import (
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/sirupsen/logrus"
 
+	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 )
 
@@ -33,21 +34,38 @@ func init() {
 	plugins.RegisterPlugin(
 		pluginName,
 		plugins.Plugin{
-			Description:        "The branchcleaner plugin automatically deletes source branches for merged PRs between two branches on the same repository. This is helpful to keep repos that don't allow forking clean.",
+			Description:        "The branchcleaner plugin automatically deletes source branches for merged PRs between two branches on the same repository. This is helpful to keep repos that don't allow forking clean. The default branch, the protected branches and the configured preserved branches are never deleted.",
 			PullRequestHandler: handlePullRequest,
 		},
 	)
 }
 
 func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
-	return handle(pc.SCMProviderClient, pc.Logger, pre)
+	repo := pre.PullRequest.Base.Repo
+	return handle(pc.SCMProviderClient, pc.Logger, pre, pc.PluginConfig.BranchCleanerFor(repo.Namespace, repo.Name), func(branch string) bool {
+		return protected(pc.Config, pc.Logger, repo.Namespace, repo.Name, branch)
+	})
+}
+
+// protected returns whether the branch protection config protects the branch
+func protected(cfg *config.Config, log *logrus.Entry, org, repo, branch string) bool {
+	if cfg == nil {
+		return false
+	}
+	policy, err := cfg.GetBranchProtection(org, repo, branch)
+	if err != nil {
+		// better keep a branch than delete a protected one
+		log.WithError(err).Warnf("failed to get the branch protection of %s in %s/%s", branch, org, repo)
+		return true
+	}
+	return policy != nil && policy.Protect != nil && *policy.Protect
 }
 
 type scmProviderClient interface {
 	DeleteRef(owner, repo, ref string) error
 }
 
-func handle(spc scmProviderClient, log *logrus.Entry, pre scm.PullRequestHook) error {
+func handle(spc scmProviderClient, log *logrus.Entry, pre scm.PullRequestHook, bc *plugins.BranchCleaner, protected func(branch string) bool) error {
 	// Only consider closed PRs that got merged
 	if pre.Action != scm.ActionClose || !pre.PullRequest.Merged {
 		return nil
@@ -60,6 +78,12 @@ func handle(spc scmProviderClient, log *logrus.Entry, pre scm.PullRequestHook) e
 		return nil
 	}
 
+	// Never delete the default branch, the preserved branches nor the protected ones
+	if pr.Head.Ref == pr.Base.Repo.Branch || bc.Preserves(pr.Head.Ref) || protected(pr.Head.Ref) {
+		log.Debugf("Preserving the branch %s of %s/%s", pr.Head.Ref, pr.Base.Repo.Namespace, pr.Base.Repo.Name)
+		return nil
+	}
+
 	if err := spc.DeleteRef(pr.Base.Repo.Namespace, pr.Base.Repo.Name, fmt.Sprintf("heads/%s", pr.Head.Ref)); err != nil {
 		return fmt.Errorf("failed to delete branch %s on repo %s/%s after Pull Request #%d got merged: %v",
 			pr.Head.Ref, pr.Base.Repo.Namespace, pr.Base.Repo.Name, pre.PullRequest.Number, err)
@@ -18,10 +18,12 @@ package branchcleaner
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/sirupsen/logrus"
 )
@@ -36,6 +38,8 @@ func TestBranchCleaner(t *testing.T) {
 		prAction             scm.Action
 		merged               bool
 		headRepoFullName     string
+		headRef              string
+		protected            bool
 		branchDeleteExpected bool
 	}{
 		{
@@ -64,6 +68,30 @@ func TestBranchCleaner(t *testing.T) {
 			headRepoFullName:     "my-org/repo",
 			branchDeleteExpected: true,
 		},
+		{
+			name:                 "PR from the default branch nothing to do",
+			prAction:             scm.ActionClose,
+			merged:               true,
+			headRepoFullName:     "my-org/repo",
+			headRef:              "master",
+			branchDeleteExpected: false,
+		},
+		{
+			name:                 "PR from a preserved branch nothing to do",
+			prAction:             scm.ActionClose,
+			merged:               true,
+			headRepoFullName:     "my-org/repo",
+			headRef:              "release-1.0",
+			branchDeleteExpected: false,
+		},
+		{
+			name:                 "PR from a protected branch nothing to do",
+			prAction:             scm.ActionClose,
+			merged:               true,
+			headRepoFullName:     "my-org/repo",
+			protected:            true,
+			branchDeleteExpected: false,
+		},
 	}
 
 	mergeSHA := "abc"
@@ -73,6 +101,10 @@ func TestBranchCleaner(t *testing.T) {
 
 		t.Run(tc.name, func(t *testing.T) {
 			log := logrus.WithField("plugin", pluginName)
+			headRef := tc.headRef
+			if headRef == "" {
+				headRef = "my-feature"
+			}
 			event := scm.PullRequestHook{
 				Action: tc.prAction,
 				PullRequest: scm.PullRequest{
@@ -87,7 +119,7 @@ func TestBranchCleaner(t *testing.T) {
 						},
 					},
 					Head: scm.PullRequestBranch{
-						Ref: "my-feature",
+						Ref: headRef,
 						Repo: scm.Repository{
 							FullName: tc.headRepoFullName,
 						},
@@ -104,7 +136,9 @@ func TestBranchCleaner(t *testing.T) {
 			fgc.PullRequests[prNumber] = &scm.PullRequest{
 				Number: prNumber,
 			}
-			if err := handle(fakeClient, log, event); err != nil {
+			bc := &plugins.BranchCleaner{PreservedBranchRes: []*regexp.Regexp{regexp.MustCompile(`^release-.*$`)}}
+			protected := func(string) bool { return tc.protected }
+			if err := handle(fakeClient, log, event, bc, protected); err != nil {
 				t.Fatalf("error in handle: %v", err)
 			}
 			if tc.branchDeleteExpected != (len(fgc.RefsDeleted) == 1) {
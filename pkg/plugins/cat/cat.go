@@ -50,6 +50,8 @@ const (
 var (
 	plugin = plugins.Plugin{
 		Description:        "The cat plugin adds a cat image to an issue or PR in response to the `/meow` command.",
+		BestEffort:         true,
+		Fun:                true,
 		ConfigHelpProvider: configHelp,
 		Commands: []plugins.Command{{
 			Name: "meow|meowvie",
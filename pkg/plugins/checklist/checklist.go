@@ -0,0 +1,144 @@
+// Package checklist contains a plugin which enforces the task lists of the pull request descriptions: the
+// `checklist` status fails while required items, marked by a configurable prefix, remain unchecked.
+package checklist
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pluginName = "checklist"
+
+	// statusContext is the context of the status reporting whether the required items are checked
+	statusContext = "checklist"
+)
+
+var (
+	// taskRe matches the items of the task lists, e.g. `- [x] Tests added`
+	taskRe = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[([ xX])\]\s+(.*)$`)
+
+	// fenceRe matches the lines opening or closing the fenced code blocks, whose task lists are ignored
+	fenceRe = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// Item is an item of a task list
+type Item struct {
+	Text    string
+	Checked bool
+}
+
+type scmProviderClient interface {
+	CreateStatus(org, repo, ref string, s *scm.StatusInput) (*scm.Status, error)
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		pluginName,
+		plugins.Plugin{
+			Description:        "The checklist plugin enforces the task lists of the pull request descriptions: the '" + statusContext + "' status fails while required items, whose text starts with a configurable prefix, remain unchecked.",
+			ConfigHelpProvider: configHelp,
+			PullRequestHandler: handlePullRequest,
+		},
+	)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	checklistConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		parts := strings.Split(repo, "/")
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
+		}
+		name := ""
+		if len(parts) == 2 {
+			name = parts[1]
+		}
+		cl := config.ChecklistFor(parts[0], name)
+		checklistConfig[repo] = fmt.Sprintf("The items of the task lists of the pull request descriptions starting with %q must be checked.", cl.RequiredPrefix)
+	}
+	return checklistConfig, nil
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	cl := pc.PluginConfig.ChecklistFor(pre.Repo.Namespace, pre.Repo.Name)
+	return handle(pc.SCMProviderClient, pc.Logger, cl, &pre)
+}
+
+func handle(spc scmProviderClient, log *logrus.Entry, cl *plugins.Checklist, pre *scm.PullRequestHook) error {
+	// the description may only change with an edit, but the status is reported for each head commit
+	if pre.Action != scm.ActionSync &&
+		pre.Action != scm.ActionOpen &&
+		pre.Action != scm.ActionReopen &&
+		pre.Action != scm.ActionEdited {
+		return nil
+	}
+
+	org := pre.Repo.Namespace
+	repo := pre.Repo.Name
+	number := pre.PullRequest.Number
+	required, unchecked := 0, 0
+	for _, item := range Parse(pre.PullRequest.Body) {
+		if !IsRequired(item, cl.RequiredPrefix) {
+			continue
+		}
+		required++
+		if !item.Checked {
+			unchecked++
+		}
+	}
+
+	status := &scm.StatusInput{
+		Label: statusContext,
+		State: scm.StateSuccess,
+		Desc:  fmt.Sprintf("All %d required items are checked.", required),
+	}
+	switch {
+	case required == 0:
+		status.Desc = "No required item in the description."
+	case required == 1 && unchecked == 0:
+		status.Desc = "The required item is checked."
+	case unchecked > 0:
+		log.WithField("unchecked", unchecked).Info("Found unchecked required items in the pull request description.")
+		status.State = scm.StateFailure
+		status.Desc = fmt.Sprintf("%d of %d required items are not checked.", unchecked, required)
+	}
+	if _, err := spc.CreateStatus(org, repo, pre.PullRequest.Head.Sha, status); err != nil {
+		return fmt.Errorf("error setting the %s status of %s/%s PR #%d: %v", statusContext, org, repo, number, err)
+	}
+	return nil
+}
+
+// Parse returns the items of the task lists of a markdown text, ignoring those of the fenced code blocks
+func Parse(text string) []Item {
+	var items []Item
+	fence := ""
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			if fence == "" {
+				fence = m[1]
+			} else if fence == m[1] {
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		if m := taskRe.FindStringSubmatch(line); m != nil {
+			items = append(items, Item{Text: strings.TrimSpace(m[2]), Checked: m[1] != " "})
+		}
+	}
+	return items
+}
+
+// IsRequired returns true if the text of the item starts with the prefix of the required items, ignoring the case
+func IsRequired(item Item, prefix string) bool {
+	return len(item.Text) >= len(prefix) && strings.EqualFold(item.Text[:len(prefix)], prefix)
+}
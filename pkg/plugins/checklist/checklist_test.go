@@ -0,0 +1,114 @@
+package checklist
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	statuses map[string]*scm.StatusInput
+}
+
+func (f *fakeClient) CreateStatus(_, _, ref string, s *scm.StatusInput) (*scm.Status, error) {
+	f.statuses[ref] = s
+	return &scm.Status{}, nil
+}
+
+const description = `Fixes the webhook timeouts.
+
+- [x] (required) Tests added
+- [ ] (Required) Docs updated
+* [X] Changelog entry
+1. [ ] optional follow-up
+
+` + "```" + `
+- [ ] (required) not a task
+` + "```"
+
+func TestParse(t *testing.T) {
+	assert.Equal(t, []Item{
+		{Text: "(required) Tests added", Checked: true},
+		{Text: "(Required) Docs updated"},
+		{Text: "Changelog entry", Checked: true},
+		{Text: "optional follow-up"},
+	}, Parse(description))
+	assert.Empty(t, Parse("- [] not a task\n-[ ] nor this one\n[ ] nor that one"))
+}
+
+func TestHandle(t *testing.T) {
+	cases := []struct {
+		name   string
+		action scm.Action
+		body   string
+		prefix string
+		state  scm.State
+		desc   string
+	}{
+		{
+			name:   "unchecked required item",
+			action: scm.ActionOpen,
+			body:   description,
+			prefix: plugins.DefaultChecklistRequiredPrefix,
+			state:  scm.StateFailure,
+			desc:   "1 of 2 required items are not checked.",
+		},
+		{
+			name:   "all required items checked",
+			action: scm.ActionEdited,
+			body:   "- [x] (required) Tests added\n- [x] (required) Docs updated\n- [ ] optional follow-up",
+			prefix: plugins.DefaultChecklistRequiredPrefix,
+			state:  scm.StateSuccess,
+			desc:   "All 2 required items are checked.",
+		},
+		{
+			name:   "custom prefix",
+			action: scm.ActionSync,
+			body:   description,
+			prefix: "Changelog",
+			state:  scm.StateSuccess,
+			desc:   "The required item is checked.",
+		},
+		{
+			name:   "no required item",
+			action: scm.ActionReopen,
+			body:   "No task list.",
+			prefix: plugins.DefaultChecklistRequiredPrefix,
+			state:  scm.StateSuccess,
+			desc:   "No required item in the description.",
+		},
+		{
+			name:   "ignored action",
+			action: scm.ActionLabel,
+			body:   description,
+			prefix: plugins.DefaultChecklistRequiredPrefix,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			spc := &fakeClient{statuses: map[string]*scm.StatusInput{}}
+			pre := &scm.PullRequestHook{
+				Action: tc.action,
+				Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+				PullRequest: scm.PullRequest{
+					Number: 5,
+					Body:   tc.body,
+					Head:   scm.PullRequestBranch{Sha: "abc"},
+				},
+			}
+			require.NoError(t, handle(spc, logrus.WithField("plugin", pluginName), &plugins.Checklist{RequiredPrefix: tc.prefix}, pre))
+			if tc.state == scm.StateUnknown {
+				assert.Empty(t, spc.statuses)
+				return
+			}
+			require.Contains(t, spc.statuses, "abc")
+			assert.Equal(t, statusContext, spc.statuses["abc"].Label)
+			assert.Equal(t, tc.state, spc.statuses["abc"].State)
+			assert.Equal(t, tc.desc, spc.statuses["abc"].Desc)
+		})
+	}
+}
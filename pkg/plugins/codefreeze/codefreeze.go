@@ -0,0 +1,151 @@
+// Package codefreeze contains a plugin which blocks the merge of the pull requests during the code freezes declared
+// in the plugin config, by labelling the pull requests which are neither in the milestone of the release nor
+// granted an exception, and removes the label once the code freeze is lifted. The pull requests are checked when
+// they change, and all the open pull requests are synced periodically by the webhooks controller.
+package codefreeze
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PluginName is the name of the code-freeze plugin
+	PluginName = "code-freeze"
+	// pageSize is the number of open pull requests listed per page
+	pageSize = 100
+)
+
+type scmProviderClient interface {
+	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
+	ListAllPullRequestsForFullNameRepo(fullName string, opts scm.PullRequestListOptions) ([]*scm.PullRequest, error)
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		PluginName,
+		plugins.Plugin{
+			Description: "The code-freeze plugin adds the '" + labels.CodeFreeze + "' label to the pull requests during the code freezes declared in its config, " +
+				"unless they are in the milestone of the release or are granted an exception with a label, and removes it once the code freeze is lifted.",
+			ConfigHelpProvider: configHelp,
+			PullRequestHandler: handlePullRequest,
+		},
+	)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	freezeConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		parts := strings.Split(repo, "/")
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
+		}
+		name := ""
+		if len(parts) == 2 {
+			name = parts[1]
+		}
+		cf := config.CodeFreezeFor(parts[0], name)
+		if len(cf.Repos) == 0 {
+			freezeConfig[repo] = "No code freeze is declared."
+			continue
+		}
+		help := "The pull requests"
+		if len(cf.Branches) > 0 {
+			help += fmt.Sprintf(" targeting %s", strings.Join(cf.Branches, ", "))
+		}
+		help += " are frozen"
+		if cf.Start != "" {
+			help += " from " + cf.Start
+		}
+		if cf.End != "" {
+			help += " until " + cf.End
+		}
+		help += fmt.Sprintf(", unless they have the %s label", cf.ExceptionLabel)
+		if cf.Milestone != "" {
+			help += fmt.Sprintf(" or are in the %s milestone", cf.Milestone)
+		}
+		freezeConfig[repo] = help + "."
+	}
+	return freezeConfig, nil
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	switch pre.Action {
+	case scm.ActionOpen, scm.ActionReopen, scm.ActionSync, scm.ActionEdited, scm.ActionLabel, scm.ActionUnlabel:
+	default:
+		return nil
+	}
+	// the label events of the plugin itself need no check
+	if (pre.Action == scm.ActionLabel || pre.Action == scm.ActionUnlabel) && pre.Label.Name == labels.CodeFreeze {
+		return nil
+	}
+	org := pre.Repo.Namespace
+	repo := pre.Repo.Name
+	cf := pc.PluginConfig.CodeFreezeFor(org, repo)
+	// the milestone of the pull request is not always included in the webhook
+	pr, err := pc.SCMProviderClient.GetPullRequest(org, repo, pre.PullRequest.Number)
+	if err != nil {
+		return fmt.Errorf("failed to get %s/%s PR #%d: %v", org, repo, pre.PullRequest.Number, err)
+	}
+	return check(pc.SCMProviderClient, pc.Logger, cf, org, repo, pr, time.Now())
+}
+
+// Sync checks all the open pull requests of the repository, so that they are labelled when a code freeze starts and
+// unlabelled when it is lifted, carrying on with the next pull requests on errors
+func Sync(spc scmProviderClient, log *logrus.Entry, cf *plugins.CodeFreeze, org, repo string, now time.Time) error {
+	prs, err := spc.ListAllPullRequestsForFullNameRepo(org+"/"+repo, scm.PullRequestListOptions{Page: 1, Size: pageSize, Open: true})
+	if err != nil {
+		return fmt.Errorf("failed to list the open pull requests of %s/%s: %v", org, repo, err)
+	}
+	var errs []string
+	for _, pr := range prs {
+		if err := check(spc, log, cf, org, repo, pr, now); err != nil {
+			log.WithError(err).Warnf("failed to check the code freeze of %s/%s PR #%d", org, repo, pr.Number)
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to check the code freeze of %d pull requests of %s/%s: %s", len(errs), org, repo, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// check labels the pull request if it is frozen at the given time, and removes the label otherwise
+func check(spc scmProviderClient, log *logrus.Entry, cf *plugins.CodeFreeze, org, repo string, pr *scm.PullRequest, now time.Time) error {
+	if pr.Closed || pr.Merged {
+		return nil
+	}
+	issueLabels, err := spc.GetIssueLabels(org, repo, pr.Number, true)
+	if err != nil {
+		return fmt.Errorf("failed to get the labels of %s/%s PR #%d: %v", org, repo, pr.Number, err)
+	}
+	frozen := cf.Frozen(pr.Base.Ref, now) &&
+		!scmprovider.HasLabel(cf.ExceptionLabel, issueLabels) &&
+		(cf.Milestone == "" || pr.Milestone.Title != cf.Milestone)
+	hasLabel := scmprovider.HasLabel(labels.CodeFreeze, issueLabels)
+
+	log = log.WithFields(logrus.Fields{"org": org, "repo": repo, "pr": pr.Number})
+	switch {
+	case frozen && !hasLabel:
+		log.Infof("Adding the %s label", labels.CodeFreeze)
+		if err := spc.AddLabel(org, repo, pr.Number, labels.CodeFreeze, true); err != nil {
+			return fmt.Errorf("failed to add the %s label: %v", labels.CodeFreeze, err)
+		}
+	case !frozen && hasLabel:
+		log.Infof("Removing the %s label", labels.CodeFreeze)
+		if err := spc.RemoveLabel(org, repo, pr.Number, labels.CodeFreeze, true); err != nil {
+			return fmt.Errorf("failed to remove the %s label: %v", labels.CodeFreeze, err)
+		}
+	}
+	return nil
+}
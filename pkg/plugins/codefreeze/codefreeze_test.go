@@ -0,0 +1,104 @@
+package codefreeze
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type fakeClient struct {
+	prs    []*scm.PullRequest
+	labels map[int]sets.String
+}
+
+func (f *fakeClient) GetPullRequest(_, _ string, number int) (*scm.PullRequest, error) {
+	for _, pr := range f.prs {
+		if pr.Number == number {
+			return pr, nil
+		}
+	}
+	return nil, scm.ErrNotFound
+}
+
+func (f *fakeClient) ListAllPullRequestsForFullNameRepo(string, scm.PullRequestListOptions) ([]*scm.PullRequest, error) {
+	return f.prs, nil
+}
+
+func (f *fakeClient) GetIssueLabels(_, _ string, number int, _ bool) ([]*scm.Label, error) {
+	var issueLabels []*scm.Label
+	for _, l := range f.labels[number].List() {
+		issueLabels = append(issueLabels, &scm.Label{Name: l})
+	}
+	return issueLabels, nil
+}
+
+func (f *fakeClient) AddLabel(_, _ string, number int, label string, _ bool) error {
+	f.labels[number].Insert(label)
+	return nil
+}
+
+func (f *fakeClient) RemoveLabel(_, _ string, number int, label string, _ bool) error {
+	f.labels[number].Delete(label)
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC)
+	cf := &plugins.CodeFreeze{
+		Repos:          []string{"org/repo"},
+		Branches:       []string{"master"},
+		StartTime:      start,
+		EndTime:        end,
+		Milestone:      "v1.2",
+		ExceptionLabel: labels.CodeFreezeOK,
+	}
+	spc := &fakeClient{
+		prs: []*scm.PullRequest{
+			{Number: 1, Base: scm.PullRequestBranch{Ref: "master"}},
+			{Number: 2, Base: scm.PullRequestBranch{Ref: "master"}, Milestone: scm.Milestone{Title: "v1.2"}},
+			{Number: 3, Base: scm.PullRequestBranch{Ref: "master"}},
+			{Number: 4, Base: scm.PullRequestBranch{Ref: "release-1.1"}},
+		},
+		labels: map[int]sets.String{
+			1: sets.NewString(),
+			2: sets.NewString(),
+			3: sets.NewString(labels.CodeFreezeOK),
+			4: sets.NewString(),
+		},
+	}
+	log := logrus.WithField("plugin", PluginName)
+	frozen := func() []int {
+		var numbers []int
+		for _, pr := range spc.prs {
+			if spc.labels[pr.Number].Has(labels.CodeFreeze) {
+				numbers = append(numbers, pr.Number)
+			}
+		}
+		return numbers
+	}
+
+	require.NoError(t, Sync(spc, log, cf, "org", "repo", start.Add(-time.Hour)))
+	assert.Empty(t, frozen(), "before the code freeze")
+
+	require.NoError(t, Sync(spc, log, cf, "org", "repo", start))
+	assert.Equal(t, []int{1}, frozen(), "during the code freeze")
+
+	spc.labels[1].Insert(labels.CodeFreezeOK)
+	require.NoError(t, Sync(spc, log, cf, "org", "repo", start.Add(time.Hour)))
+	assert.Empty(t, frozen(), "exception granted")
+
+	spc.labels[1].Delete(labels.CodeFreezeOK)
+	require.NoError(t, Sync(spc, log, cf, "org", "repo", start.Add(time.Hour)))
+	assert.Equal(t, []int{1}, frozen(), "exception revoked")
+
+	require.NoError(t, Sync(spc, log, cf, "org", "repo", end))
+	assert.Empty(t, frozen(), "after the code freeze")
+}
@@ -25,7 +25,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/jenkins-x/lighthouse/pkg/gitattributes"
 	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/repoowners"
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -33,12 +36,17 @@ import (
 
 const (
 	failOnMissingPlugin = false
+
+	// DisabledPluginPrefix disables, when prefixing a plugin name in the plugins of a repository,
+	// the plugin enabled for the org of the repository, e.g. "-lgtm"
+	DisabledPluginPrefix = "-"
 )
 
 // Configuration is the top-level serialization target for plugin Configuration.
 type Configuration struct {
 	// Plugins is a map of repositories (eg "k/k") to lists of
-	// plugin names.
+	// plugin names. The plugins of an org (eg "k") are enabled for all its repositories,
+	// a repository disables one of them by listing its name prefixed with "-".
 	// TODO: Link to the list of supported plugins.
 	// https://github.com/kubernetes/test-infra/issues/3476
 	Plugins map[string][]string `json:"plugins,omitempty"`
@@ -50,20 +58,50 @@ type Configuration struct {
 	// Owners contains configuration related to handling OWNERS files.
 	Owners Owners `json:"owners,omitempty"`
 
+	// BranchPlugins restricts plugins and commands to the branches matching a regular expression,
+	// e.g. to only allow the `hold` and `override` commands on release branches.
+	BranchPlugins []BranchPlugins `json:"branch_plugins,omitempty"`
+
+	// PluginTimeouts bounds how long the handlers of the plugins are waited for, so that a slow plugin does not
+	// hold on to the resources of the webhooks.
+	PluginTimeouts *PluginTimeouts `json:"plugin_timeouts,omitempty"`
+
+	// BotAccounts are the logins of the other bots whose slash commands are ignored, so that their comments
+	// cannot be used to run commands. The commands of the bot itself are always ignored.
+	BotAccounts []string `json:"bot_accounts,omitempty"`
+
+	// CommandRestrictions restrict who can use some commands and how often, e.g. to only allow the author
+	// of a pull request to `/retest` it at most once per 10 minutes.
+	CommandRestrictions []CommandRestriction `json:"command_restrictions,omitempty"`
+
+	// FunPlugins enables or disables the non-functional plugins (cat, dog, pony, shrug and yuks) all at once,
+	// whatever the plugins enabled for the repositories.
+	FunPlugins FunPlugins `json:"fun_plugins,omitempty"`
+
 	// Built-in plugins specific configuration.
 	Approve              []Approve              `json:"approve,omitempty"`
+	Assign               []Assign               `json:"assign,omitempty"`
 	Blockades            []Blockade             `json:"blockades,omitempty"`
+	BranchCleaners       []BranchCleaner        `json:"branch_cleaners,omitempty"`
 	Cat                  Cat                    `json:"cat,omitempty"`
+	Checklists           []Checklist            `json:"checklists,omitempty"`
 	CherryPickUnapproved CherryPickUnapproved   `json:"cherry_pick_unapproved,omitempty"`
+	CodeFreezes          []CodeFreeze           `json:"code_freezes,omitempty"`
 	ConfigUpdater        ConfigUpdater          `json:"config_updater,omitempty"`
+	DuplicateIssues      []DuplicateIssues      `json:"duplicate_issues,omitempty"`
 	Heart                Heart                  `json:"heart,omitempty"`
+	JobOwners            []JobOwners            `json:"job_owners,omitempty"`
 	Label                Label                  `json:"label,omitempty"`
 	Lgtm                 []Lgtm                 `json:"lgtm,omitempty"`
+	PathLabels           []PathLabels           `json:"path_labels,omitempty"`
 	RepoMilestone        map[string]Milestone   `json:"repo_milestone,omitempty"`
 	RequireMatchingLabel []RequireMatchingLabel `json:"require_matching_label,omitempty"`
 	RequireSIG           RequireSIG             `json:"requiresig,omitempty"`
+	SecretScans          []SecretScan           `json:"secret_scans,omitempty"`
 	SigMention           SigMention             `json:"sigmention,omitempty"`
+	SignedCommits        []SignedCommits        `json:"signed_commits,omitempty"`
 	Size                 Size                   `json:"size,omitempty"`
+	StaleReviews         []StaleReviews         `json:"stale_reviews,omitempty"`
 	Triggers             []Trigger              `json:"triggers,omitempty"`
 	Welcome              []Welcome              `json:"welcome,omitempty"`
 }
@@ -80,6 +118,79 @@ type ExternalPlugin struct {
 	// server to the external plugin. If no events are specified,
 	// everything is sent.
 	Events []string `json:"events,omitempty"`
+	// HMACTokenSecret references the Secret key holding the token used to sign the payloads
+	// sent to the plugin. The HMAC token of the hook server is used if not specified.
+	HMACTokenSecret *secret.Reference `json:"hmac_token_secret,omitempty"`
+}
+
+// BranchPlugins restricts plugins and commands of repositories to some branches. The restrictions are
+// enforced by the webhook server before invoking the plugins, for events related to a branch: pushes,
+// pull requests and their reviews and comments. A plugin or command listed by several BranchPlugins
+// is allowed on the branches matching any of them.
+type BranchPlugins struct {
+	// Repos is either of the form org/repo or just org.
+	Repos []string `json:"repos,omitempty"`
+	// BranchRegexp is the regular expression of the branches the plugins and commands are allowed on.
+	// Compiles into BranchRe during config load.
+	BranchRegexp string         `json:"branch_regexp"`
+	BranchRe     *regexp.Regexp `json:"-"`
+	// Plugins are the plugins only invoked for events on the matching branches.
+	Plugins []string `json:"plugins,omitempty"`
+	// Commands are the names of the commands, without the leading "/", only handled for pull requests
+	// targeting the matching branches, e.g. "hold".
+	Commands []string `json:"commands,omitempty"`
+}
+
+// FunPlugins disables the non-functional plugins, such as cat or yuks, globally or for some orgs. The fun plugins
+// enabled for the disabled repositories are ignored by the webhook server.
+type FunPlugins struct {
+	// Disabled disables the fun plugins for all the repositories, except the ones of the EnabledOrgs.
+	Disabled bool `json:"disabled,omitempty"`
+	// DisabledOrgs are the orgs or org/repos opting out of the fun plugins when they are enabled globally.
+	DisabledOrgs []string `json:"disabled_orgs,omitempty"`
+	// EnabledOrgs are the orgs or org/repos opting in the fun plugins when they are disabled globally.
+	EnabledOrgs []string `json:"enabled_orgs,omitempty"`
+}
+
+// DefaultPluginTimeout is the timeout of the handlers of the plugins without a configured timeout
+const DefaultPluginTimeout = 5 * time.Minute
+
+// CommandRestriction restricts the users allowed to use some commands of repositories and how often they can be
+// used on an issue or pull request. The restrictions are enforced by the webhook server before invoking the plugins,
+// the commands not satisfying them being ignored. A command listed by several CommandRestrictions must satisfy
+// all of them.
+type CommandRestriction struct {
+	// Repos is either of the form org/repo or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Commands are the names of the commands, without the leading "/", e.g. "retest".
+	Commands []string `json:"commands,omitempty"`
+	// Author allows the author of the issue or pull request to use the commands.
+	Author bool `json:"author,omitempty"`
+	// Assignees allows the assignees of the issue or pull request to use the commands.
+	Assignees bool `json:"assignees,omitempty"`
+	// Teams are the teams of the org whose members are allowed to use the commands.
+	// Anyone can use the commands if neither Author, Assignees nor Teams are set.
+	Teams []string `json:"teams,omitempty"`
+	// Cooldown is the minimum duration between two uses of each command on an issue or pull request, e.g. '10m'.
+	Cooldown         string        `json:"cooldown,omitempty"`
+	CooldownDuration time.Duration `json:"-"`
+}
+
+// RestrictsUsers returns whether only some users are allowed to use the commands
+func (r *CommandRestriction) RestrictsUsers() bool {
+	return r.Author || r.Assignees || len(r.Teams) > 0
+}
+
+// PluginTimeouts bounds how long the webhook server waits for the handler of a plugin before giving up on it. The
+// calls the plugin makes to the SCM provider are cancelled once it timed out.
+type PluginTimeouts struct {
+	// Default is the timeout of the plugins without a specific timeout.
+	// Defaults to '5m'.
+	Default         string        `json:"default,omitempty"`
+	DefaultDuration time.Duration `json:"-"`
+	// Plugins are the timeouts of some plugins by name, e.g. `trigger: 10m`.
+	Plugins         map[string]string        `json:"plugins,omitempty"`
+	PluginDurations map[string]time.Duration `json:"-"`
 }
 
 // Owners contains configuration related to handling OWNERS files.
@@ -105,6 +216,9 @@ type Owners struct {
 	// OWNERS file, preventing their automatic addition by the owners-label plugin.
 	// This check is performed by the verify-owners plugin.
 	LabelsExcludeList []string `json:"labels_excludes,omitempty"`
+	// Providers configures where the owners of the repositories are loaded from, keyed by org or
+	// org/repo. Repositories without a provider use their OWNERS files.
+	Providers map[string]repoowners.ProviderConfig `json:"providers,omitempty"`
 }
 
 // MDYAMLEnabled returns a boolean denoting if the passed repo supports YAML OWNERS config headers
@@ -132,6 +246,15 @@ func (c *Configuration) SkipCollaborators(org, repo string) bool {
 	return false
 }
 
+// OwnersProvider returns the owners provider of the passed repo, the one of the repo taking
+// precedence over the one of its org.
+func (c *Configuration) OwnersProvider(org, repo string) repoowners.ProviderConfig {
+	if p, ok := c.Owners.Providers[fmt.Sprintf("%s/%s", org, repo)]; ok {
+		return p
+	}
+	return c.Owners.Providers[org]
+}
+
 // RequireSIG specifies configuration for the require-sig plugin.
 type RequireSIG struct {
 	// GroupListURL is the URL where a list of the available SIGs can be found.
@@ -159,6 +282,220 @@ type SigMention struct {
 	Re     *regexp.Regexp `json:"-"`
 }
 
+// DefaultSecretScanEntropyThreshold is the default Shannon entropy, in bits per character, above which the values
+// assigned to keys, tokens, passwords and secrets are reported by the secret-scan plugin
+const DefaultSecretScanEntropyThreshold = 4.0
+
+// SecretScan specifies a configuration for the secret-scan plugin.
+//
+// The configuration for the secret-scan plugin is defined as a list of these structures.
+type SecretScan struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Patterns are regular expressions matching secrets, in addition to the built-in ones matching
+	// the cloud provider keys, the SCM and chat tokens and the private keys.
+	Patterns []string `json:"patterns,omitempty"`
+	// PatternRes are the compiled versions of Patterns. They should not be specified in config.
+	PatternRes []*regexp.Regexp `json:"-"`
+	// ExcludedPaths are regular expressions matching the paths of the files not scanned, e.g. test fixtures.
+	ExcludedPaths []string `json:"excluded_paths,omitempty"`
+	// ExcludedPathRes are the compiled versions of ExcludedPaths. They should not be specified in config.
+	ExcludedPathRes []*regexp.Regexp `json:"-"`
+	// EntropyThreshold is the Shannon entropy, in bits per character, above which a quoted value assigned to
+	// a key, token, password or secret is reported. Defaults to 4.0, a negative value disables the heuristic.
+	EntropyThreshold float64 `json:"entropy_threshold,omitempty"`
+}
+
+// PathLabels specifies a configuration for the path-labels plugin.
+//
+// The configuration for the path-labels plugin is defined as a list of these structures.
+type PathLabels struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Labels map the files changed by the pull requests to the labels applied to them.
+	Labels []PathLabel `json:"labels,omitempty"`
+	// IgnoreRepoFile ignores the labels of the .lighthouse/labels.yaml file of the repositories.
+	IgnoreRepoFile bool `json:"ignore_repo_file,omitempty"`
+}
+
+// PathLabel maps the files whose path matches a regular expression to a label.
+type PathLabel struct {
+	// Path is the regular expression matching the whole path of the changed files, e.g. docs/.*
+	Path string `json:"path"`
+	// Label is the label applied to the pull requests changing a matching file, e.g. area/docs
+	Label string `json:"label"`
+	// PathRe is the compiled version of Path. It should not be specified in config.
+	PathRe *regexp.Regexp `json:"-"`
+}
+
+// Compile compiles the regular expression of the path
+func (l *PathLabel) Compile() error {
+	if l.Label == "" {
+		return fmt.Errorf("no label for the path %q", l.Path)
+	}
+	re, err := regexp.Compile("^(?:" + l.Path + ")$")
+	if err != nil {
+		return fmt.Errorf("failed to compile the path regexp %q: %v", l.Path, err)
+	}
+	l.PathRe = re
+	return nil
+}
+
+// BranchCleaner specifies a configuration for the branchcleaner plugin.
+//
+// The configuration for the branchcleaner plugin is defined as a list of these structures.
+type BranchCleaner struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// PreservedBranches are regular expressions matching the head branches which are never deleted once their
+	// pull requests are merged, e.g. `^release-.*$`. The branches protected by the branch protection config
+	// and the default branch of the repository are always preserved.
+	PreservedBranches []string `json:"preserved_branches,omitempty"`
+	// PreservedBranchRes are the compiled versions of PreservedBranches. They should not be specified in config.
+	PreservedBranchRes []*regexp.Regexp `json:"-"`
+}
+
+// Preserves returns true if the given branch must not be deleted
+func (b *BranchCleaner) Preserves(branch string) bool {
+	for _, re := range b.PreservedBranchRes {
+		if re.MatchString(branch) {
+			return true
+		}
+	}
+	return false
+}
+
+// CodeFreeze specifies a configuration for the code-freeze plugin.
+//
+// The configuration for the code-freeze plugin is defined as a list of these structures.
+type CodeFreeze struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Branches are the base branches of the pull requests which are frozen, all the branches if empty.
+	Branches []string `json:"branches,omitempty"`
+	// Start is the RFC 3339 time the code freeze starts at, e.g. `2021-03-01T00:00:00Z`. The code freeze starts
+	// right away if empty.
+	Start string `json:"start,omitempty"`
+	// StartTime is the parsed version of Start. It should not be specified in config.
+	StartTime time.Time `json:"-"`
+	// End is the RFC 3339 time the code freeze is lifted at. The code freeze lasts until it is removed from the
+	// config if empty.
+	End string `json:"end,omitempty"`
+	// EndTime is the parsed version of End. It should not be specified in config.
+	EndTime time.Time `json:"-"`
+	// Milestone is the title of the milestone whose pull requests can still be merged during the code freeze.
+	Milestone string `json:"milestone,omitempty"`
+	// ExceptionLabel is the label approving the merge of a pull request during the code freeze.
+	// Defaults to `code-freeze-exception`.
+	ExceptionLabel string `json:"exception_label,omitempty"`
+}
+
+// Frozen returns true if the pull requests targeting the branch cannot be merged at the given time
+func (f *CodeFreeze) Frozen(branch string, now time.Time) bool {
+	if len(f.Repos) == 0 || now.Before(f.StartTime) || (!f.EndTime.IsZero() && !now.Before(f.EndTime)) {
+		return false
+	}
+	if len(f.Branches) == 0 {
+		return true
+	}
+	for _, b := range f.Branches {
+		if b == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// SignedCommits specifies a configuration for the signed-commits plugin.
+//
+// The configuration for the signed-commits plugin is defined as a list of these structures.
+type SignedCommits struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Branches are regular expressions matching the base branches of the pull requests whose commits must be
+	// signed. The commits of the pull requests of all branches must be signed if empty.
+	Branches []string `json:"branches,omitempty"`
+	// BranchRes are the compiled versions of Branches. They should not be specified in config.
+	BranchRes []*regexp.Regexp `json:"-"`
+}
+
+// RequiredOn returns true if the commits of the pull requests of the given base branch must be signed
+func (s *SignedCommits) RequiredOn(branch string) bool {
+	if len(s.BranchRes) == 0 {
+		return true
+	}
+	for _, re := range s.BranchRes {
+		if re.MatchString(branch) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultJobOwnersFlakeThreshold is the default number of failures of a presubmit on a pull request after which it is
+// considered flaky by the job-owners plugin
+const DefaultJobOwnersFlakeThreshold = 3
+
+// JobOwners specifies a configuration for the job-owners plugin.
+//
+// The configuration for the job-owners plugin is defined as a list of these structures.
+type JobOwners struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Owners map the presubmits to the teams owning them.
+	Owners []JobOwner `json:"owners,omitempty"`
+	// FlakeThreshold is the number of failures of a presubmit on a pull request after which its owners are cc'ed on
+	// the pull request and its failures are tracked in an issue of the repository. Defaults to 3.
+	FlakeThreshold int `json:"flake_threshold,omitempty"`
+	// FlakeLabel is the label of the flake-tracking issues. Defaults to kind/flake.
+	FlakeLabel string `json:"flake_label,omitempty"`
+}
+
+// JobOwner maps presubmits to the teams owning them.
+type JobOwner struct {
+	// Job is a regular expression matching the whole names of the presubmits.
+	Job string `json:"job"`
+	// JobRe is the compiled version of Job. It should not be specified in config.
+	JobRe *regexp.Regexp `json:"-"`
+	// Teams are the teams, e.g. `org/team`, or the users owning the presubmits.
+	Teams []string `json:"teams"`
+}
+
+// TeamsFor returns the teams owning the given presubmit, those of the first matching owner
+func (j *JobOwners) TeamsFor(job string) []string {
+	for _, o := range j.Owners {
+		if o.JobRe != nil && o.JobRe.MatchString(job) {
+			return o.Teams
+		}
+	}
+	return nil
+}
+
+// StaleReviews specifies a configuration for the stale-review plugin.
+//
+// The configuration for the stale-review plugin is defined as a list of these structures.
+type StaleReviews struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// KeepReviews keeps the approving reviews, only the approved label being removed once they are stale.
+	KeepReviews bool `json:"keep_reviews,omitempty"`
+	// IgnoredPaths are regular expressions matching the whole path of the files whose changes never make the
+	// approvals stale, e.g. docs/.*
+	IgnoredPaths []string `json:"ignored_paths,omitempty"`
+	// IgnoredPathRes are the compiled versions of IgnoredPaths. They should not be specified in config.
+	IgnoredPathRes []*regexp.Regexp `json:"-"`
+}
+
+// Ignored returns true if the changes of the file with the given path never make the approvals stale
+func (s *StaleReviews) Ignored(path string) bool {
+	for _, re := range s.IgnoredPathRes {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
 // Size specifies configuration for the size plugin, defining lower bounds (in # lines changed) for each size label.
 // XS is assumed to be zero.
 type Size struct {
@@ -167,6 +504,13 @@ type Size struct {
 	L   int `json:"l"`
 	Xl  int `json:"xl"`
 	Xxl int `json:"xxl"`
+
+	// Exclude are the patterns of the files whose changes are not counted, e.g. vendor/** or *.pb.go, matched as
+	// the patterns of a .gitattributes file.
+	Exclude []string `json:"exclude,omitempty"`
+	// Repos overrides the configuration for orgs or org/repos, the thresholds which are not set and, if not set, the
+	// excluded files being those of the top-level configuration.
+	Repos map[string]Size `json:"repos,omitempty"`
 }
 
 // Blockade specifies a configuration for a single blockade.
@@ -184,6 +528,23 @@ type Blockade struct {
 	Explanation string `json:"explanation,omitempty"`
 }
 
+// Assign specifies a configuration for the assign plugin.
+//
+// The configuration for the assign plugin is defined as a list of these structures.
+type Assign struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// MaxAssignees is the maximum number of assignees of a pull request or an issue, the users assigned beyond
+	// it are skipped. There is no maximum if zero.
+	MaxAssignees int `json:"max_assignees,omitempty"`
+	// Unavailable are the logins of the users who are skipped when assigned or asked for a review, e.g. while
+	// on leave.
+	Unavailable []string `json:"unavailable,omitempty"`
+	// SkipBusyUsers skips the users whose status on the git provider indicates a limited availability, where
+	// the git provider supports it.
+	SkipBusyUsers bool `json:"skip_busy_users,omitempty"`
+}
+
 // Approve specifies a configuration for a single approve.
 //
 // The configuration for the approve plugin is defined as a list of these structures.
@@ -203,6 +564,9 @@ type Approve struct {
 	// * an APPROVE github review is equivalent to leaving an "/approve" message.
 	// * A REQUEST_CHANGES github review is equivalent to leaving an /approve cancel" message.
 	IgnoreReviewState *bool `json:"ignore_review_state,omitempty"`
+	// ForbidSelfApproval prevents PR authors from approving their own PRs: they neither implicitly approve them
+	// nor can approve them with a command or a review. It takes precedence over RequireSelfApproval.
+	ForbidSelfApproval bool `json:"forbid_self_approval,omitempty"`
 }
 
 var (
@@ -232,6 +596,9 @@ func warnDeprecated(last *time.Time, freq time.Duration, msg string) {
 
 // HasSelfApproval checks if it has self-approval
 func (a Approve) HasSelfApproval() bool {
+	if a.ForbidSelfApproval {
+		return false
+	}
 	if a.RequireSelfApproval != nil {
 		return !*a.RequireSelfApproval
 	}
@@ -300,6 +667,37 @@ type Trigger struct {
 	// ElideSkippedContexts makes trigger not post "Skipped" contexts for jobs
 	// that could run but do not run.
 	ElideSkippedContexts bool `json:"elide_skipped_contexts,omitempty"`
+	// TrustedApps are the exact logins of the bots and apps, e.g. dependabot[bot], whose PRs are
+	// built automatically although they are neither collaborators nor org members.
+	TrustedApps []string `json:"trusted_apps,omitempty"`
+}
+
+const (
+	// DefaultDuplicateIssuesRecent is the default number of the most recent issues the new issues are compared to by
+	// the duplicate-issues plugin
+	DefaultDuplicateIssuesRecent = 100
+	// DefaultDuplicateIssuesThreshold is the default minimal similarity of the issues reported as likely duplicates
+	// by the duplicate-issues plugin
+	DefaultDuplicateIssuesThreshold = 0.5
+	// DefaultDuplicateIssuesSuggestions is the default maximum number of likely duplicates reported by the
+	// duplicate-issues plugin
+	DefaultDuplicateIssuesSuggestions = 3
+)
+
+// DuplicateIssues specifies a configuration for the duplicate-issues plugin.
+//
+// The configuration for the duplicate-issues plugin is defined as a list of these structures.
+type DuplicateIssues struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// RecentIssues is the number of the most recent issues, open or closed, the new issues are compared to.
+	// Defaults to 100.
+	RecentIssues int `json:"recent_issues,omitempty"`
+	// Threshold is the minimal similarity, between 0 and 1, of the issues reported as likely duplicates.
+	// Defaults to 0.5.
+	Threshold float64 `json:"threshold,omitempty"`
+	// MaxSuggestions is the maximum number of likely duplicates reported. Defaults to 3.
+	MaxSuggestions int `json:"max_suggestions,omitempty"`
 }
 
 // Heart contains the configuration for the heart plugin.
@@ -314,6 +712,23 @@ type Heart struct {
 	// Compiles into CommentRe during config load.
 	CommentRegexp string         `json:"commentregexp,omitempty"`
 	CommentRe     *regexp.Regexp `json:"-"`
+	// Owners configures, per org or repo, how the contributors added to the OWNERS and OWNERS_ALIASES files by
+	// the merged pull requests are congratulated.
+	Owners []HeartOwners `json:"owners,omitempty"`
+}
+
+// DefaultHeartOwnersMessage is the default template of the comment congratulating the new owners
+const DefaultHeartOwnersMessage = "Congratulations {{.Mentions}} on becoming an owner of {{.Org}}/{{.Repo}}! ❤️ Thank you for your contributions."
+
+// HeartOwners is the configuration of the heart plugin for the new owners of the repositories of an org or of a repo.
+type HeartOwners struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// MessageTemplate is the template of the comment congratulating the new owners, which is given the Org, Repo,
+	// Logins and Mentions of the new owners. Defaults to DefaultHeartOwnersMessage.
+	MessageTemplate string `json:"message_template,omitempty"`
+	// InviteToOrg invites the new owners who are not members of the org to join it, which is only supported by GitHub.
+	InviteToOrg bool `json:"invite_to_org,omitempty"`
 }
 
 // Milestone contains the configuration options for the milestone and
@@ -346,6 +761,10 @@ type ConfigMapSpec struct {
 	// If set to false and the global GZIP option is enabled, this file will
 	// will not be GZIP'd.
 	GZIP *bool `json:"gzip,omitempty"`
+	// Partitioned spreads the keys of the ConfigMap over several ConfigMaps named `<name>`, `<name>-1`, `<name>-2`...
+	// when they do not fit in a single one, e.g. to mount a large job config directory with a projected volume.
+	// All the files updating the same ConfigMap must agree on it.
+	Partitioned bool `json:"partitioned,omitempty"`
 	// Namespaces is the fully resolved list of Namespaces to deploy the ConfigMap in
 	Namespaces []string `json:"-"`
 }
@@ -360,8 +779,15 @@ type ConfigUpdater struct {
 	// If GZIP is true then files will be gzipped before insertion into
 	// their corresponding configmap
 	GZIP bool `json:"gzip"`
+	// PartitionSize is the maximum size in bytes of the data of each partition of the partitioned ConfigMaps.
+	// Defaults to DefaultConfigMapPartitionSize.
+	PartitionSize int `json:"partition_size,omitempty"`
 }
 
+// DefaultConfigMapPartitionSize is the default maximum size of the data of the partitions of the ConfigMaps,
+// leaving room for their metadata within the 1MiB limit of the ConfigMaps
+const DefaultConfigMapPartitionSize = 900 * 1024
+
 // Welcome is config for the welcome plugin.
 type Welcome struct {
 	// Repos is either of the form org/repos or just org.
@@ -371,6 +797,20 @@ type Welcome struct {
 	MessageTemplate string `json:"message_template,omitempty"`
 }
 
+// DefaultChecklistRequiredPrefix is the default prefix of the required items of the task lists of the pull requests
+const DefaultChecklistRequiredPrefix = "(required)"
+
+// Checklist specifies a configuration for the checklist plugin.
+//
+// The configuration for the checklist plugin is defined as a list of these structures.
+type Checklist struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// RequiredPrefix is the prefix of the text of the required items of the task lists of the pull request
+	// descriptions, e.g. `- [ ] (required) Tests added`. Defaults to DefaultChecklistRequiredPrefix.
+	RequiredPrefix string `json:"required_prefix,omitempty"`
+}
+
 // CherryPickUnapproved is the config for the cherrypick-unapproved plugin.
 type CherryPickUnapproved struct {
 	// BranchRegexp is the regular expression for branch names such that
@@ -503,6 +943,285 @@ func (c *Configuration) TriggerFor(org, repo string) *Trigger {
 	return &Trigger{}
 }
 
+// ChecklistFor finds the Checklist for a repo, if one exists
+// a checklist configuration can be listed for the repo itself or for the
+// owning organization
+func (c *Configuration) ChecklistFor(org, repo string) *Checklist {
+	for i, cl := range c.Checklists {
+		for _, r := range cl.Repos {
+			if r == org || r == fmt.Sprintf("%s/%s", org, repo) {
+				return &c.Checklists[i]
+			}
+		}
+	}
+	return &Checklist{RequiredPrefix: DefaultChecklistRequiredPrefix}
+}
+
+// DuplicateIssuesFor finds the DuplicateIssues for a repo, if one exists
+// a duplicate issues configuration can be listed for the repo itself or for the
+// owning organization
+func (c *Configuration) DuplicateIssuesFor(org, repo string) *DuplicateIssues {
+	for i, di := range c.DuplicateIssues {
+		for _, r := range di.Repos {
+			if r == org || r == fmt.Sprintf("%s/%s", org, repo) {
+				return &c.DuplicateIssues[i]
+			}
+		}
+	}
+	return &DuplicateIssues{
+		RecentIssues:   DefaultDuplicateIssuesRecent,
+		Threshold:      DefaultDuplicateIssuesThreshold,
+		MaxSuggestions: DefaultDuplicateIssuesSuggestions,
+	}
+}
+
+// HeartOwnersFor finds the HeartOwners for a repo, if one exists
+// a heart owners configuration can be listed for the repo itself or for the
+// owning organization
+func (c *Configuration) HeartOwnersFor(org, repo string) *HeartOwners {
+	for i, ho := range c.Heart.Owners {
+		for _, r := range ho.Repos {
+			if r == org || r == fmt.Sprintf("%s/%s", org, repo) {
+				return &c.Heart.Owners[i]
+			}
+		}
+	}
+	return &HeartOwners{MessageTemplate: DefaultHeartOwnersMessage}
+}
+
+// SecretScanFor finds the SecretScan for a repo, if one exists
+// a secret scan can be listed for the repo itself or for the
+// owning organization
+func (c *Configuration) SecretScanFor(org, repo string) *SecretScan {
+	for i, ss := range c.SecretScans {
+		for _, r := range ss.Repos {
+			if r == org || r == fmt.Sprintf("%s/%s", org, repo) {
+				return &c.SecretScans[i]
+			}
+		}
+	}
+	return &SecretScan{EntropyThreshold: DefaultSecretScanEntropyThreshold}
+}
+
+// SizeFor returns the size configuration of a repo, the configuration of the repo or of its org overriding the
+// top-level one
+func (c *Configuration) SizeFor(org, repo string) Size {
+	size := c.Size
+	size.Repos = nil
+	override, ok := c.Size.Repos[fmt.Sprintf("%s/%s", org, repo)]
+	if !ok {
+		override, ok = c.Size.Repos[org]
+	}
+	if !ok {
+		return size
+	}
+	if override.S != 0 {
+		size.S = override.S
+	}
+	if override.M != 0 {
+		size.M = override.M
+	}
+	if override.L != 0 {
+		size.L = override.L
+	}
+	if override.Xl != 0 {
+		size.Xl = override.Xl
+	}
+	if override.Xxl != 0 {
+		size.Xxl = override.Xxl
+	}
+	if len(override.Exclude) > 0 {
+		size.Exclude = override.Exclude
+	}
+	return size
+}
+
+// AssignFor finds the Assign for a repo, if one exists
+// an assign configuration can be listed for the repo itself or for the
+// owning organization
+func (c *Configuration) AssignFor(org, repo string) *Assign {
+	for i, a := range c.Assign {
+		for _, r := range a.Repos {
+			if r == org || r == fmt.Sprintf("%s/%s", org, repo) {
+				return &c.Assign[i]
+			}
+		}
+	}
+	return &Assign{}
+}
+
+// PathLabelsFor finds the PathLabels for a repo, if one exists
+// a path labels configuration can be listed for the repo itself or for the
+// owning organization
+func (c *Configuration) PathLabelsFor(org, repo string) *PathLabels {
+	for i, pl := range c.PathLabels {
+		for _, r := range pl.Repos {
+			if r == org || r == fmt.Sprintf("%s/%s", org, repo) {
+				return &c.PathLabels[i]
+			}
+		}
+	}
+	return &PathLabels{}
+}
+
+// BranchCleanerFor finds the BranchCleaner for a repo, if one exists
+// a branch cleaner configuration can be listed for the repo itself or for the
+// owning organization
+func (c *Configuration) BranchCleanerFor(org, repo string) *BranchCleaner {
+	for i, bc := range c.BranchCleaners {
+		for _, r := range bc.Repos {
+			if r == org || r == fmt.Sprintf("%s/%s", org, repo) {
+				return &c.BranchCleaners[i]
+			}
+		}
+	}
+	return &BranchCleaner{}
+}
+
+// CodeFreezeFor finds the CodeFreeze for a repo, if one exists
+// a code freeze configuration can be listed for the repo itself or for the
+// owning organization
+func (c *Configuration) CodeFreezeFor(org, repo string) *CodeFreeze {
+	for i, cf := range c.CodeFreezes {
+		for _, r := range cf.Repos {
+			if r == org || r == fmt.Sprintf("%s/%s", org, repo) {
+				return &c.CodeFreezes[i]
+			}
+		}
+	}
+	return &CodeFreeze{}
+}
+
+// SignedCommitsFor finds the SignedCommits for a repo, if one exists
+// a signed commits configuration can be listed for the repo itself or for the
+// owning organization
+func (c *Configuration) SignedCommitsFor(org, repo string) *SignedCommits {
+	for i, sc := range c.SignedCommits {
+		for _, r := range sc.Repos {
+			if r == org || r == fmt.Sprintf("%s/%s", org, repo) {
+				return &c.SignedCommits[i]
+			}
+		}
+	}
+	return &SignedCommits{}
+}
+
+// StaleReviewsFor finds the StaleReviews for a repo, if one exists
+// a stale reviews configuration can be listed for the repo itself or for the
+// owning organization
+func (c *Configuration) StaleReviewsFor(org, repo string) *StaleReviews {
+	for i, sr := range c.StaleReviews {
+		for _, r := range sr.Repos {
+			if r == org || r == fmt.Sprintf("%s/%s", org, repo) {
+				return &c.StaleReviews[i]
+			}
+		}
+	}
+	return &StaleReviews{}
+}
+
+// JobOwnersFor finds the JobOwners for a repo, if one exists
+// a job owners configuration can be listed for the repo itself or for the
+// owning organization
+func (c *Configuration) JobOwnersFor(org, repo string) *JobOwners {
+	for i, jo := range c.JobOwners {
+		for _, r := range jo.Repos {
+			if r == org || r == fmt.Sprintf("%s/%s", org, repo) {
+				return &c.JobOwners[i]
+			}
+		}
+	}
+	return &JobOwners{FlakeThreshold: DefaultJobOwnersFlakeThreshold, FlakeLabel: labels.Flake}
+}
+
+// PluginsFor returns the plugins enabled for the repository, the plugins of its org which are not
+// disabled by the repository followed by the plugins of the repository.
+func (c *Configuration) PluginsFor(org, repo string) []string {
+	repoPlugins := c.Plugins[org+"/"+repo]
+	disabled := sets.NewString()
+	var answer []string
+	for _, plugin := range repoPlugins {
+		if name := strings.TrimPrefix(plugin, DisabledPluginPrefix); name != plugin {
+			disabled.Insert(name)
+		}
+	}
+	for _, plugin := range c.Plugins[org] {
+		if !disabled.Has(plugin) {
+			answer = append(answer, plugin)
+		}
+	}
+	for _, plugin := range repoPlugins {
+		if !strings.HasPrefix(plugin, DisabledPluginPrefix) {
+			answer = append(answer, plugin)
+		}
+	}
+	return answer
+}
+
+// FunPluginsEnabled returns whether the non-functional plugins can be invoked for the repository
+func (c *Configuration) FunPluginsEnabled(org, repo string) bool {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	if c.FunPlugins.Disabled {
+		return sets.NewString(c.FunPlugins.EnabledOrgs...).HasAny(org, fullName)
+	}
+	return !sets.NewString(c.FunPlugins.DisabledOrgs...).HasAny(org, fullName)
+}
+
+// HasBranchPlugins returns whether plugins or commands of the repository are restricted to some branches
+func (c *Configuration) HasBranchPlugins(org, repo string) bool {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	for _, bp := range c.BranchPlugins {
+		for _, r := range bp.Repos {
+			if r == org || r == fullName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UnknownBranch is the branch of the events whose branch could not be looked up, e.g. the comments on a pull
+// request which could not be fetched. The plugins and commands restricted to some branches are not allowed on it.
+// Git does not allow spaces in branch names so it cannot be the name of an actual branch.
+const UnknownBranch = "unknown branch"
+
+// PluginAllowedOnBranch returns whether the plugin can be invoked for an event on the branch of the repository
+func (c *Configuration) PluginAllowedOnBranch(org, repo, plugin, branch string) bool {
+	return c.allowedOnBranch(org, repo, branch, func(bp BranchPlugins) []string { return bp.Plugins }, plugin)
+}
+
+// CommandAllowedOnBranch returns whether the command can be handled for a pull request targeting the branch of the repository
+func (c *Configuration) CommandAllowedOnBranch(org, repo, command, branch string) bool {
+	return c.allowedOnBranch(org, repo, branch, func(bp BranchPlugins) []string { return bp.Commands }, command)
+}
+
+// CommandRestrictionsFor returns the restrictions of a command of the repository
+func (c *Configuration) CommandRestrictionsFor(org, repo, command string) []CommandRestriction {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	var answer []CommandRestriction
+	for _, r := range c.CommandRestrictions {
+		if sets.NewString(r.Repos...).HasAny(org, fullName) && sets.NewString(r.Commands...).Has(command) {
+			answer = append(answer, r)
+		}
+	}
+	return answer
+}
+
+func (c *Configuration) allowedOnBranch(org, repo, branch string, names func(BranchPlugins) []string, name string) bool {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	restricted := false
+	for _, bp := range c.BranchPlugins {
+		if !sets.NewString(bp.Repos...).HasAny(org, fullName) || !sets.NewString(names(bp)...).Has(name) {
+			continue
+		}
+		restricted = true
+		if branch != UnknownBranch && bp.BranchRe != nil && bp.BranchRe.MatchString(branch) {
+			return true
+		}
+	}
+	return !restricted
+}
+
 // EnabledReposForPlugin returns the orgs and repos that have enabled the passed plugin.
 func (c *Configuration) EnabledReposForPlugin(plugin string) (orgs, repos []string) {
 	for repo, plugins := range c.Plugins {
@@ -565,6 +1284,9 @@ func (c *ConfigUpdater) SetDefaults() {
 		spec.Namespaces = append([]string{spec.Namespace}, spec.AdditionalNamespaces...)
 		c.Maps[name] = spec
 	}
+	if c.PartitionSize == 0 {
+		c.PartitionSize = DefaultConfigMapPartitionSize
+	}
 }
 
 func (c *Configuration) setDefaults() {
@@ -595,6 +1317,47 @@ func (c *Configuration) setDefaults() {
 			milestone.MaintainersFriendlyName = "SIG Chairs/TLs"
 		}
 	}
+	for i := range c.Checklists {
+		if c.Checklists[i].RequiredPrefix == "" {
+			c.Checklists[i].RequiredPrefix = DefaultChecklistRequiredPrefix
+		}
+	}
+	for i := range c.DuplicateIssues {
+		di := &c.DuplicateIssues[i]
+		if di.RecentIssues == 0 {
+			di.RecentIssues = DefaultDuplicateIssuesRecent
+		}
+		if di.Threshold == 0 {
+			di.Threshold = DefaultDuplicateIssuesThreshold
+		}
+		if di.MaxSuggestions == 0 {
+			di.MaxSuggestions = DefaultDuplicateIssuesSuggestions
+		}
+	}
+	for i := range c.Heart.Owners {
+		if c.Heart.Owners[i].MessageTemplate == "" {
+			c.Heart.Owners[i].MessageTemplate = DefaultHeartOwnersMessage
+		}
+	}
+	for i := range c.JobOwners {
+		jo := &c.JobOwners[i]
+		if jo.FlakeThreshold == 0 {
+			jo.FlakeThreshold = DefaultJobOwnersFlakeThreshold
+		}
+		if jo.FlakeLabel == "" {
+			jo.FlakeLabel = labels.Flake
+		}
+	}
+	for i := range c.SecretScans {
+		if c.SecretScans[i].EntropyThreshold == 0 {
+			c.SecretScans[i].EntropyThreshold = DefaultSecretScanEntropyThreshold
+		}
+	}
+	for i := range c.CodeFreezes {
+		if c.CodeFreezes[i].ExceptionLabel == "" {
+			c.CodeFreezes[i].ExceptionLabel = labels.CodeFreezeOK
+		}
+	}
 	if c.CherryPickUnapproved.BranchRegexp == "" {
 		c.CherryPickUnapproved.BranchRegexp = `^release-.*$`
 	}
@@ -613,12 +1376,27 @@ The list of patch release managers for each release can be found [here](https://
 	}
 }
 
+// PluginTimeout returns the timeout of the handlers of a plugin
+func (c *Configuration) PluginTimeout(plugin string) time.Duration {
+	if c.PluginTimeouts == nil {
+		return DefaultPluginTimeout
+	}
+	if d, ok := c.PluginTimeouts.PluginDurations[plugin]; ok {
+		return d
+	}
+	if c.PluginTimeouts.DefaultDuration > 0 {
+		return c.PluginTimeouts.DefaultDuration
+	}
+	return DefaultPluginTimeout
+}
+
 // ValidatePluginsArePresent takes a map with plugin names as keys and errors or logs for each configured plugin that can't be found.
 func (c *Configuration) ValidatePluginsArePresent(presentPlugins map[string]interface{}) error {
 	var errList []string
 
 	for _, configuration := range c.Plugins {
 		for _, plugin := range configuration {
+			plugin = strings.TrimPrefix(plugin, DisabledPluginPrefix)
 			if _, ok := presentPlugins[plugin]; !ok {
 				if failOnMissingPlugin {
 					errList = append(errList, fmt.Sprintf("unknown plugin: %s", plugin))
@@ -644,6 +1422,17 @@ func validatePlugins(plugins map[string][]string) error {
 			if dupes := findDuplicatedPluginConfig(repoConfig, plugins[org]); len(dupes) > 0 {
 				errList = append(errList, fmt.Sprintf("plugins %v are duplicated for %s and %s", dupes, repo, org))
 			}
+			for _, plugin := range repoConfig {
+				if name := strings.TrimPrefix(plugin, DisabledPluginPrefix); name != plugin && !sets.NewString(plugins[org]...).Has(name) {
+					errList = append(errList, fmt.Sprintf("plugin %s is disabled for %s but not enabled for %s", name, repo, org))
+				}
+			}
+		} else {
+			for _, plugin := range repoConfig {
+				if strings.HasPrefix(plugin, DisabledPluginPrefix) {
+					errList = append(errList, fmt.Sprintf("plugin %s cannot be disabled for org %s as there is nothing to inherit from", plugin, repo))
+				}
+			}
 		}
 	}
 
@@ -654,9 +1443,35 @@ func validatePlugins(plugins map[string][]string) error {
 }
 
 func validateSizes(size Size) error {
+	if err := validateSize(size); err != nil {
+		return err
+	}
+	for key := range size.Repos {
+		if len(size.Repos[key].Repos) > 0 {
+			return fmt.Errorf("invalid size plugin configuration for %s - repos cannot be nested", key)
+		}
+		parts := strings.SplitN(key, "/", 2)
+		org, repo := parts[0], ""
+		if len(parts) == 2 {
+			repo = parts[1]
+		}
+		c := &Configuration{Size: size}
+		if err := validateSize(c.SizeFor(org, repo)); err != nil {
+			return fmt.Errorf("%v for %s", err, key)
+		}
+	}
+	return nil
+}
+
+func validateSize(size Size) error {
 	if size.S > size.M || size.M > size.L || size.L > size.Xl || size.Xl > size.Xxl {
 		return errors.New("invalid size plugin configuration - one of the smaller sizes is bigger than a larger one")
 	}
+	for _, p := range size.Exclude {
+		if _, err := gitattributes.ParsePattern(p); err != nil {
+			return fmt.Errorf("invalid size plugin configuration - invalid exclude pattern: %v", err)
+		}
+	}
 
 	return nil
 }
@@ -678,6 +1493,14 @@ func validateExternalPlugins(pluginMap map[string][]ExternalPlugin) error {
 	var errors []string
 
 	for repo, plugins := range pluginMap {
+		for _, p := range plugins {
+			if p.HMACTokenSecret == nil {
+				continue
+			}
+			if err := p.HMACTokenSecret.Validate(); err != nil {
+				errors = append(errors, fmt.Sprintf("invalid hmac_token_secret of external plugin %s for %s: %v", p.Name, repo, err))
+			}
+		}
 		if !strings.Contains(repo, "/") {
 			continue
 		}
@@ -705,14 +1528,23 @@ func validateExternalPlugins(pluginMap map[string][]ExternalPlugin) error {
 }
 
 func validateConfigUpdater(updater *ConfigUpdater) error {
+	if updater.PartitionSize < 0 {
+		return fmt.Errorf("invalid partition_size %d in config updater config", updater.PartitionSize)
+	}
 	files := sets.NewString()
 	configMapKeys := map[string]sets.String{}
+	partitioned := map[string]bool{}
 	for file, config := range updater.Maps {
 		if files.Has(file) {
 			return fmt.Errorf("file %s listed more than once in config updater config", file)
 		}
 		files.Insert(file)
 
+		if p, ok := partitioned[config.Name]; ok && p != config.Partitioned {
+			return fmt.Errorf("configmap %s is partitioned for some of its files only", config.Name)
+		}
+		partitioned[config.Name] = config.Partitioned
+
 		key := config.Key
 		if key == "" {
 			key = path.Base(file)
@@ -730,6 +1562,69 @@ func validateConfigUpdater(updater *ConfigUpdater) error {
 	return nil
 }
 
+func validateBranchPlugins(bps []BranchPlugins) error {
+	for i, bp := range bps {
+		switch {
+		case len(bp.Repos) == 0:
+			return fmt.Errorf("branch_plugins config #%d has no repos", i)
+		case bp.BranchRegexp == "":
+			return fmt.Errorf("branch_plugins config #%d has no branch_regexp", i)
+		case len(bp.Plugins) == 0 && len(bp.Commands) == 0:
+			return fmt.Errorf("branch_plugins config #%d restricts neither plugins nor commands", i)
+		}
+	}
+	return nil
+}
+
+func validateCommandRestrictions(rs []CommandRestriction) error {
+	for i, r := range rs {
+		switch {
+		case len(r.Repos) == 0:
+			return fmt.Errorf("command_restrictions config #%d has no repos", i)
+		case len(r.Commands) == 0:
+			return fmt.Errorf("command_restrictions config #%d has no commands", i)
+		case !r.RestrictsUsers() && r.Cooldown == "":
+			return fmt.Errorf("command_restrictions config #%d restricts neither the users nor the cooldown", i)
+		}
+	}
+	return nil
+}
+
+func validateAssign(as []Assign) error {
+	for i, a := range as {
+		if a.MaxAssignees < 0 {
+			return fmt.Errorf("assign config #%d has a negative max_assignees", i)
+		}
+	}
+	return nil
+}
+
+func validateJobOwners(js []JobOwners) error {
+	for i, j := range js {
+		if j.FlakeThreshold < 0 {
+			return fmt.Errorf("job_owners config #%d has a negative flake_threshold", i)
+		}
+		for _, o := range j.Owners {
+			if o.Job == "" || len(o.Teams) == 0 {
+				return fmt.Errorf("job_owners config #%d has an owner without job or teams", i)
+			}
+		}
+	}
+	return nil
+}
+
+func validateDuplicateIssues(ds []DuplicateIssues) error {
+	for i, d := range ds {
+		if d.RecentIssues < 0 || d.MaxSuggestions < 0 {
+			return fmt.Errorf("duplicate_issues config #%d has a negative recent_issues or max_suggestions", i)
+		}
+		if d.Threshold < 0 || d.Threshold > 1 {
+			return fmt.Errorf("duplicate_issues config #%d has a threshold %v which is not between 0 and 1", i, d.Threshold)
+		}
+	}
+	return nil
+}
+
 func validateRequireMatchingLabel(rs []RequireMatchingLabel) error {
 	for i, r := range rs {
 		if err := r.validate(); err != nil {
@@ -752,6 +1647,13 @@ func compileRegexpsAndDurations(pc *Configuration) error {
 	}
 	pc.CherryPickUnapproved.BranchRe = branchRe
 
+	for i := range pc.BranchPlugins {
+		re, err := regexp.Compile(pc.BranchPlugins[i].BranchRegexp)
+		if err != nil {
+			return err
+		}
+		pc.BranchPlugins[i].BranchRe = re
+	}
 	commentRe, err := regexp.Compile(pc.Heart.CommentRegexp)
 	if err != nil {
 		return err
@@ -773,6 +1675,134 @@ func compileRegexpsAndDurations(pc *Configuration) error {
 		}
 		rs[i].GracePeriodDuration = dur
 	}
+
+	for i := range pc.SecretScans {
+		ss := &pc.SecretScans[i]
+		ss.PatternRes = nil
+		for _, pattern := range ss.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("failed to compile secret scan pattern: %q, error: %v", pattern, err)
+			}
+			ss.PatternRes = append(ss.PatternRes, re)
+		}
+		ss.ExcludedPathRes = nil
+		for _, path := range ss.ExcludedPaths {
+			re, err := regexp.Compile(path)
+			if err != nil {
+				return fmt.Errorf("failed to compile secret scan excluded path: %q, error: %v", path, err)
+			}
+			ss.ExcludedPathRes = append(ss.ExcludedPathRes, re)
+		}
+	}
+
+	for i := range pc.BranchCleaners {
+		bc := &pc.BranchCleaners[i]
+		bc.PreservedBranchRes = nil
+		for _, branch := range bc.PreservedBranches {
+			re, err := regexp.Compile(branch)
+			if err != nil {
+				return fmt.Errorf("failed to compile branch cleaner preserved branch regexp: %q, error: %v", branch, err)
+			}
+			bc.PreservedBranchRes = append(bc.PreservedBranchRes, re)
+		}
+	}
+
+	for i := range pc.SignedCommits {
+		sc := &pc.SignedCommits[i]
+		sc.BranchRes = nil
+		for _, branch := range sc.Branches {
+			re, err := regexp.Compile(branch)
+			if err != nil {
+				return fmt.Errorf("failed to compile signed commits branch regexp: %q, error: %v", branch, err)
+			}
+			sc.BranchRes = append(sc.BranchRes, re)
+		}
+	}
+
+	for i := range pc.PathLabels {
+		for j := range pc.PathLabels[i].Labels {
+			if err := pc.PathLabels[i].Labels[j].Compile(); err != nil {
+				return fmt.Errorf("invalid path_labels config #%d: %v", i, err)
+			}
+		}
+	}
+
+	for i := range pc.StaleReviews {
+		sr := &pc.StaleReviews[i]
+		sr.IgnoredPathRes = nil
+		for _, path := range sr.IgnoredPaths {
+			re, err := regexp.Compile("^(?:" + path + ")$")
+			if err != nil {
+				return fmt.Errorf("failed to compile stale reviews ignored path: %q, error: %v", path, err)
+			}
+			sr.IgnoredPathRes = append(sr.IgnoredPathRes, re)
+		}
+	}
+
+	for i := range pc.JobOwners {
+		owners := pc.JobOwners[i].Owners
+		for j := range owners {
+			re, err := regexp.Compile("^(?:" + owners[j].Job + ")$")
+			if err != nil {
+				return fmt.Errorf("failed to compile job owners job: %q, error: %v", owners[j].Job, err)
+			}
+			owners[j].JobRe = re
+		}
+	}
+
+	for i := range pc.CommandRestrictions {
+		r := &pc.CommandRestrictions[i]
+		if r.Cooldown == "" {
+			continue
+		}
+		dur, err := time.ParseDuration(r.Cooldown)
+		if err != nil || dur <= 0 {
+			return fmt.Errorf("invalid cooldown of command_restrictions config #%d: %q", i, r.Cooldown)
+		}
+		r.CooldownDuration = dur
+	}
+
+	for i := range pc.CodeFreezes {
+		cf := &pc.CodeFreezes[i]
+		var err error
+		if cf.Start != "" {
+			if cf.StartTime, err = time.Parse(time.RFC3339, cf.Start); err != nil {
+				return fmt.Errorf("invalid start of code_freezes config #%d: %q", i, cf.Start)
+			}
+		}
+		if cf.End != "" {
+			if cf.EndTime, err = time.Parse(time.RFC3339, cf.End); err != nil {
+				return fmt.Errorf("invalid end of code_freezes config #%d: %q", i, cf.End)
+			}
+			if !cf.EndTime.After(cf.StartTime) {
+				return fmt.Errorf("the end of code_freezes config #%d must be after its start", i)
+			}
+		}
+	}
+
+	return compilePluginTimeouts(pc.PluginTimeouts)
+}
+
+func compilePluginTimeouts(pt *PluginTimeouts) error {
+	if pt == nil {
+		return nil
+	}
+	if pt.Default != "" {
+		dur, err := time.ParseDuration(pt.Default)
+		if err != nil || dur <= 0 {
+			return fmt.Errorf("invalid default plugin timeout: %q", pt.Default)
+		}
+		pt.DefaultDuration = dur
+	}
+	pt.PluginDurations = map[string]time.Duration{}
+	for name, timeout := range pt.Plugins {
+		dur, err := time.ParseDuration(timeout)
+		if err != nil || dur <= 0 {
+			return fmt.Errorf("invalid timeout of the plugin %s: %q", name, timeout)
+		}
+		pt.PluginDurations[name] = dur
+	}
 	return nil
 }
 
@@ -795,6 +1825,17 @@ func (c *Configuration) Validate() error {
 	if err := validateExternalPlugins(c.ExternalPlugins); err != nil {
 		return err
 	}
+	if err := validateBranchPlugins(c.BranchPlugins); err != nil {
+		return err
+	}
+	if err := validateCommandRestrictions(c.CommandRestrictions); err != nil {
+		return err
+	}
+	for key, provider := range c.Owners.Providers {
+		if err := provider.Validate(); err != nil {
+			return fmt.Errorf("invalid owners provider for %s: %v", key, err)
+		}
+	}
 	if err := validateConfigUpdater(&c.ConfigUpdater); err != nil {
 		return err
 	}
@@ -804,6 +1845,15 @@ func (c *Configuration) Validate() error {
 	if err := validateRequireMatchingLabel(c.RequireMatchingLabel); err != nil {
 		return err
 	}
+	if err := validateAssign(c.Assign); err != nil {
+		return err
+	}
+	if err := validateDuplicateIssues(c.DuplicateIssues); err != nil {
+		return err
+	}
+	if err := validateJobOwners(c.JobOwners); err != nil {
+		return err
+	}
 
 	return nil
 }
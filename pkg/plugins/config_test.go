@@ -19,8 +19,11 @@ package plugins
 import (
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/jenkins-x/lighthouse/pkg/labels"
 	"k8s.io/utils/diff"
 )
 
@@ -272,3 +275,311 @@ The list of patch release managers for each release can be found [here](https://
 		}
 	}
 }
+
+func TestPluginsFor(t *testing.T) {
+	c := &Configuration{
+		Plugins: map[string][]string{
+			"org":          {"approve", "lgtm", "size"},
+			"org/repo":     {"-lgtm", "cat"},
+			"org/disabled": {"-approve", "-lgtm", "-size"},
+		},
+	}
+	tests := map[string][]string{
+		"repo":     {"approve", "size", "cat"},
+		"other":    {"approve", "lgtm", "size"},
+		"disabled": nil,
+	}
+	for repo, expected := range tests {
+		if actual := c.PluginsFor("org", repo); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("plugins for org/%s: expected %v but got %v", repo, expected, actual)
+		}
+	}
+}
+
+func TestFunPluginsEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		fun      FunPlugins
+		expected map[string]bool
+	}{
+		{
+			name:     "enabled by default",
+			expected: map[string]bool{"org/repo": true, "other/repo": true},
+		},
+		{
+			name:     "org opting out",
+			fun:      FunPlugins{DisabledOrgs: []string{"org", "other/repo"}},
+			expected: map[string]bool{"org/repo": false, "other/repo": false, "other/fun": true},
+		},
+		{
+			name:     "disabled globally",
+			fun:      FunPlugins{Disabled: true, EnabledOrgs: []string{"org"}, DisabledOrgs: []string{"org"}},
+			expected: map[string]bool{"org/repo": true, "other/repo": false},
+		},
+	}
+	for _, tc := range tests {
+		c := &Configuration{FunPlugins: tc.fun}
+		for fullName, expected := range tc.expected {
+			parts := strings.Split(fullName, "/")
+			if actual := c.FunPluginsEnabled(parts[0], parts[1]); actual != expected {
+				t.Errorf("%s: fun plugins enabled for %s: expected %t but got %t", tc.name, fullName, expected, actual)
+			}
+		}
+	}
+}
+
+func TestSizeFor(t *testing.T) {
+	c := &Configuration{
+		Size: Size{
+			S: 10, M: 30, L: 100, Xl: 500, Xxl: 1000,
+			Exclude: []string{"vendor/**"},
+			Repos: map[string]Size{
+				"org":      {Xxl: 2000},
+				"org/repo": {S: 20, Exclude: []string{"*.pb.go"}},
+			},
+		},
+	}
+	tests := map[string]Size{
+		"other/repo": {S: 10, M: 30, L: 100, Xl: 500, Xxl: 1000, Exclude: []string{"vendor/**"}},
+		"org/other":  {S: 10, M: 30, L: 100, Xl: 500, Xxl: 2000, Exclude: []string{"vendor/**"}},
+		"org/repo":   {S: 20, M: 30, L: 100, Xl: 500, Xxl: 1000, Exclude: []string{"*.pb.go"}},
+	}
+	for fullName, expected := range tests {
+		parts := strings.Split(fullName, "/")
+		if actual := c.SizeFor(parts[0], parts[1]); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("size for %s: expected %v but got %v", fullName, expected, actual)
+		}
+	}
+
+	if err := validateSizes(c.Size); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	c.Size.Repos["org/invalid"] = Size{M: 5}
+	if err := validateSizes(c.Size); err == nil {
+		t.Error("expected an error for thresholds out of order")
+	}
+	delete(c.Size.Repos, "org/invalid")
+	c.Size.Exclude = []string{"!vendor/**"}
+	if err := validateSizes(c.Size); err == nil {
+		t.Error("expected an error for an invalid exclude pattern")
+	}
+}
+
+func TestValidateDisabledPlugins(t *testing.T) {
+	tests := []struct {
+		name    string
+		plugins map[string][]string
+		valid   bool
+	}{
+		{
+			name:    "disable org plugin",
+			plugins: map[string][]string{"org": {"lgtm"}, "org/repo": {"-lgtm"}},
+			valid:   true,
+		},
+		{
+			name:    "disable plugin not enabled for the org",
+			plugins: map[string][]string{"org": {"approve"}, "org/repo": {"-lgtm"}},
+		},
+		{
+			name:    "disable plugin for an org",
+			plugins: map[string][]string{"org": {"-lgtm"}},
+		},
+	}
+	for _, test := range tests {
+		err := validatePlugins(test.plugins)
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestBranchPlugins(t *testing.T) {
+	c := &Configuration{
+		BranchPlugins: []BranchPlugins{
+			{
+				Repos:        []string{"org"},
+				BranchRegexp: `^release-.*$`,
+				Plugins:      []string{"cherrypick"},
+				Commands:     []string{"hold", "override"},
+			},
+			{
+				Repos:        []string{"org/repo"},
+				BranchRegexp: `^main$`,
+				Commands:     []string{"hold"},
+			},
+		},
+	}
+	if err := compileRegexpsAndDurations(c); err != nil {
+		t.Fatalf("failed to compile the regexps: %v", err)
+	}
+	if err := validateBranchPlugins(c.BranchPlugins); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	tests := []struct {
+		repo, command, branch string
+		allowed               bool
+	}{
+		{repo: "repo", command: "hold", branch: "release-1.0", allowed: true},
+		{repo: "repo", command: "hold", branch: "main", allowed: true},
+		{repo: "repo", command: "hold", branch: "feature"},
+		{repo: "other", command: "hold", branch: "main"},
+		{repo: "other", command: "override", branch: "release-1.0", allowed: true},
+		{repo: "other", command: "lgtm", branch: "feature", allowed: true},
+		{repo: "repo", command: "hold", branch: UnknownBranch},
+		{repo: "other", command: "lgtm", branch: UnknownBranch, allowed: true},
+	}
+	for _, test := range tests {
+		if actual := c.CommandAllowedOnBranch("org", test.repo, test.command, test.branch); actual != test.allowed {
+			t.Errorf("command %s on org/%s branch %s: expected allowed %t", test.command, test.repo, test.branch, test.allowed)
+		}
+	}
+	if c.PluginAllowedOnBranch("org", "repo", "cherrypick", "main") {
+		t.Error("expected cherrypick not to be allowed on main")
+	}
+	if !c.PluginAllowedOnBranch("org", "repo", "lgtm", "main") {
+		t.Error("expected lgtm to be allowed on main")
+	}
+	if c.PluginAllowedOnBranch("org", "repo", "cherrypick", UnknownBranch) {
+		t.Error("expected cherrypick not to be allowed on an unknown branch")
+	}
+	if !c.HasBranchPlugins("org", "repo") || c.HasBranchPlugins("other", "repo") {
+		t.Error("unexpected repositories with branch plugins")
+	}
+
+	c.BranchPlugins = append(c.BranchPlugins, BranchPlugins{Repos: []string{"org"}, Plugins: []string{"cat"}})
+	if err := validateBranchPlugins(c.BranchPlugins); err == nil {
+		t.Error("expected an error for a missing branch_regexp")
+	}
+}
+
+func TestCommandRestrictions(t *testing.T) {
+	c := &Configuration{
+		CommandRestrictions: []CommandRestriction{
+			{Repos: []string{"org"}, Commands: []string{"retest"}, Cooldown: "10m"},
+			{Repos: []string{"org/repo"}, Commands: []string{"retest", "hold"}, Author: true},
+		},
+	}
+	if err := compileRegexpsAndDurations(c); err != nil {
+		t.Fatalf("failed to compile the durations: %v", err)
+	}
+	if err := validateCommandRestrictions(c.CommandRestrictions); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := c.CommandRestrictions[0].CooldownDuration; got != 10*time.Minute {
+		t.Errorf("expected a 10m cooldown, got %s", got)
+	}
+	if got := len(c.CommandRestrictionsFor("org", "repo", "retest")); got != 2 {
+		t.Errorf("expected 2 restrictions of retest on org/repo, got %d", got)
+	}
+	if got := len(c.CommandRestrictionsFor("org", "other", "hold")); got != 0 {
+		t.Errorf("expected no restriction of hold on org/other, got %d", got)
+	}
+
+	c.CommandRestrictions = append(c.CommandRestrictions, CommandRestriction{Repos: []string{"org"}, Commands: []string{"lgtm"}})
+	if err := validateCommandRestrictions(c.CommandRestrictions); err == nil {
+		t.Error("expected an error for a restriction restricting nothing")
+	}
+	c.CommandRestrictions = []CommandRestriction{{Repos: []string{"org"}, Commands: []string{"lgtm"}, Cooldown: "soon"}}
+	if err := compileRegexpsAndDurations(c); err == nil {
+		t.Error("expected an error for an invalid cooldown")
+	}
+}
+
+func TestCodeFreezes(t *testing.T) {
+	c := &Configuration{
+		CodeFreezes: []CodeFreeze{
+			{Repos: []string{"org"}, Branches: []string{"master"}, Start: "2021-03-01T00:00:00Z", End: "2021-03-15T00:00:00Z"},
+			{Repos: []string{"other/repo"}},
+		},
+	}
+	c.setDefaults()
+	if err := compileRegexpsAndDurations(c); err != nil {
+		t.Fatalf("failed to compile the times: %v", err)
+	}
+	cf := c.CodeFreezeFor("org", "repo")
+	if cf.ExceptionLabel != labels.CodeFreezeOK {
+		t.Errorf("expected the default exception label, got %q", cf.ExceptionLabel)
+	}
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	if cf.Frozen("master", start.Add(-time.Second)) || !cf.Frozen("master", start) || cf.Frozen("release", start) {
+		t.Error("expected master to be frozen from the start of the code freeze")
+	}
+	if cf.Frozen("master", time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected the code freeze to be lifted at its end")
+	}
+	if !c.CodeFreezeFor("other", "repo").Frozen("release", start) {
+		t.Error("expected all the branches of other/repo to be frozen")
+	}
+	if c.CodeFreezeFor("other", "repo2").Frozen("master", start) {
+		t.Error("expected other/repo2 not to be frozen")
+	}
+
+	c.CodeFreezes = []CodeFreeze{{Repos: []string{"org"}, Start: "tomorrow"}}
+	if err := compileRegexpsAndDurations(c); err == nil {
+		t.Error("expected an error for an invalid start")
+	}
+	c.CodeFreezes = []CodeFreeze{{Repos: []string{"org"}, Start: "2021-03-15T00:00:00Z", End: "2021-03-01T00:00:00Z"}}
+	if err := compileRegexpsAndDurations(c); err == nil {
+		t.Error("expected an error for an end before the start")
+	}
+}
+
+func TestPluginTimeout(t *testing.T) {
+	c := &Configuration{}
+	if got := c.PluginTimeout("trigger"); got != DefaultPluginTimeout {
+		t.Errorf("expected the default timeout, got %s", got)
+	}
+
+	c.PluginTimeouts = &PluginTimeouts{Default: "1m", Plugins: map[string]string{"trigger": "10m"}}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := c.PluginTimeout("trigger"); got != 10*time.Minute {
+		t.Errorf("expected the timeout of the plugin, got %s", got)
+	}
+	if got := c.PluginTimeout("size"); got != time.Minute {
+		t.Errorf("expected the configured default timeout, got %s", got)
+	}
+
+	c.PluginTimeouts.Plugins["size"] = "soon"
+	if err := c.Validate(); err == nil {
+		t.Error("expected an invalid timeout to fail the validation")
+	}
+}
+
+func TestValidateConfigUpdater(t *testing.T) {
+	tests := []struct {
+		name    string
+		updater ConfigUpdater
+		wantErr bool
+	}{
+		{
+			name: "partitioned config map",
+			updater: ConfigUpdater{Maps: map[string]ConfigMapSpec{
+				"jobs/a/*.yaml": {Name: "job-config", Partitioned: true},
+				"jobs/b/*.yaml": {Name: "job-config", Key: "b.yaml", Partitioned: true},
+			}},
+		},
+		{
+			name: "partitioned for some files only",
+			updater: ConfigUpdater{Maps: map[string]ConfigMapSpec{
+				"jobs/a/*.yaml": {Name: "job-config", Partitioned: true},
+				"jobs/b/*.yaml": {Name: "job-config", Key: "b.yaml"},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "negative partition size",
+			updater: ConfigUpdater{PartitionSize: -1},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		if err := validateConfigUpdater(&tc.updater); (err != nil) != tc.wantErr {
+			t.Errorf("%s: expected an error %t but got %v", tc.name, tc.wantErr, err)
+		}
+	}
+}
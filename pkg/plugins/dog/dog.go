@@ -47,6 +47,8 @@ const (
 func createPlugin(p pack) plugins.Plugin {
 	return plugins.Plugin{
 		Description: "The dog plugin adds a dog image to an issue or PR in response to the `/woof` command.",
+		BestEffort:  true,
+		Fun:         true,
 		Commands: []plugins.Command{{
 			Name:        "woof|bark",
 			Description: "Add a dog image to the issue or PR",
@@ -0,0 +1,193 @@
+// Package duplicateissues contains a plugin which compares the new issues to the recent issues of their repository
+// and comments with the likely duplicates, which can be labelled and closed with the `/duplicate` command.
+package duplicateissues
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pluginName = "duplicate-issues"
+
+	// pageSize is the maximum number of issues listed per page
+	pageSize = 100
+)
+
+var (
+	plugin = plugins.Plugin{
+		Description: "The duplicate-issues plugin compares the title and the body of the new issues to those of the recent issues of the repository, and comments with the most similar ones as likely duplicates. " +
+			"An issue can then be labelled as '" + labels.TriageDuplicate + "', and closed if the issue it duplicates is given, with the /duplicate command.",
+		ConfigHelpProvider: configHelp,
+		IssueHandler:       handleIssue,
+		Commands: []plugins.Command{{
+			Name: "duplicate",
+			Arg: &plugins.CommandArg{
+				Usage:    "[#issue]",
+				Pattern:  `#?\d+`,
+				Optional: true,
+			},
+			Description: "Labels the issue as `" + labels.TriageDuplicate + "` and, if the issue it duplicates is given, e.g. `/duplicate #12`, closes it.",
+			WhoCanUse:   "The author of the issue and the collaborators of the repository.",
+			Action: plugins.
+				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+					return handleCommand(pc.SCMProviderClient, pc.Logger, &e, match.Arg)
+				}).
+				When(plugins.Action(scm.ActionCreate), plugins.IsNotPR(), plugins.IssueState("open")),
+		}},
+	}
+)
+
+func init() {
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+type scmProviderClient interface {
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	CloseIssue(owner, repo string, number int) error
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	IsCollaborator(owner, repo, login string) (bool, error)
+	ListIssues(owner, repo string, opts scm.IssueListOptions) ([]*scm.Issue, error)
+	QuoteAuthorForComment(string) string
+}
+
+// match is a likely duplicate of an issue
+type match struct {
+	issue      *scm.Issue
+	similarity float64
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	duplicatesConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		parts := strings.Split(repo, "/")
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
+		}
+		name := ""
+		if len(parts) == 2 {
+			name = parts[1]
+		}
+		di := config.DuplicateIssuesFor(parts[0], name)
+		duplicatesConfig[repo] = fmt.Sprintf("The new issues are compared to the %d most recent issues, up to %d issues at least %d%% similar being reported as likely duplicates.",
+			di.RecentIssues, di.MaxSuggestions, int(di.Threshold*100))
+	}
+	return duplicatesConfig, nil
+}
+
+func handleIssue(pc plugins.Agent, ie scm.IssueHook) error {
+	di := pc.PluginConfig.DuplicateIssuesFor(ie.Repo.Namespace, ie.Repo.Name)
+	return handle(pc.SCMProviderClient, pc.Logger, di, &ie)
+}
+
+func handle(spc scmProviderClient, log *logrus.Entry, di *plugins.DuplicateIssues, ie *scm.IssueHook) error {
+	if ie.Action != scm.ActionOpen || ie.Issue.PullRequest {
+		return nil
+	}
+	org := ie.Repo.Namespace
+	repo := ie.Repo.Name
+	number := ie.Issue.Number
+
+	candidates, err := recentIssues(spc, org, repo, di.RecentIssues, number)
+	if err != nil {
+		return fmt.Errorf("failed to list the issues of %s/%s: %v", org, repo, err)
+	}
+	var matches []match
+	for i, s := range similarities(&ie.Issue, candidates) {
+		if s >= di.Threshold {
+			matches = append(matches, match{issue: candidates[i], similarity: s})
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].similarity > matches[j].similarity
+	})
+	if len(matches) > di.MaxSuggestions {
+		matches = matches[:di.MaxSuggestions]
+	}
+	log.Infof("Found %d likely duplicates of %s/%s#%d", len(matches), org, repo, number)
+	return spc.CreateComment(org, repo, number, false, formatMatches(matches))
+}
+
+// recentIssues returns up to max of the most recent issues of the repository other than the given one, the pull
+// requests being ignored
+func recentIssues(spc scmProviderClient, org, repo string, max, number int) ([]*scm.Issue, error) {
+	var answer []*scm.Issue
+	for page := 1; len(answer) < max; page++ {
+		issues, err := spc.ListIssues(org, repo, scm.IssueListOptions{Page: page, Size: pageSize, Open: true, Closed: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			if issue.Number != number && !issue.PullRequest && len(answer) < max {
+				answer = append(answer, issue)
+			}
+		}
+		if len(issues) < pageSize {
+			break
+		}
+	}
+	return answer, nil
+}
+
+func formatMatches(matches []match) string {
+	var sb strings.Builder
+	sb.WriteString("This issue may be a duplicate of:\n\n")
+	for _, m := range matches {
+		state := "open"
+		if m.issue.Closed {
+			state = "closed"
+		}
+		sb.WriteString(fmt.Sprintf("- #%d %s (%s, %d%% similar)\n", m.issue.Number, m.issue.Title, state, int(m.similarity*100)))
+	}
+	sb.WriteString(fmt.Sprintf("\nIf it is, comment `/duplicate #%d` to label it as `%s` and close it.", matches[0].issue.Number, labels.TriageDuplicate))
+	return sb.String()
+}
+
+func handleCommand(spc scmProviderClient, log *logrus.Entry, e *scmprovider.GenericCommentEvent, arg string) error {
+	org := e.Repo.Namespace
+	repo := e.Repo.Name
+	user := e.Author.Login
+	respond := func(msg string) error {
+		return spc.CreateComment(org, repo, e.Number, false, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(user), msg))
+	}
+
+	if e.IssueAuthor.Login != user {
+		ok, err := spc.IsCollaborator(org, repo, user)
+		if err != nil {
+			return fmt.Errorf("failed to check whether %s is a collaborator of %s/%s: %v", user, org, repo, err)
+		}
+		if !ok {
+			return respond("only the author of the issue and the collaborators of the repository can mark it as a duplicate.")
+		}
+	}
+	original := 0
+	if arg != "" {
+		original, _ = strconv.Atoi(strings.TrimPrefix(arg, "#"))
+		if original == e.Number {
+			return respond("an issue cannot duplicate itself.")
+		}
+	}
+
+	log.Infof("Adding the %q label", labels.TriageDuplicate)
+	if err := spc.AddLabel(org, repo, e.Number, labels.TriageDuplicate, false); err != nil {
+		return fmt.Errorf("failed to add the %s label to %s/%s#%d: %v", labels.TriageDuplicate, org, repo, e.Number, err)
+	}
+	if original == 0 {
+		return nil
+	}
+	if err := spc.CreateComment(org, repo, e.Number, false, fmt.Sprintf("Duplicate of #%d", original)); err != nil {
+		return fmt.Errorf("failed to link %s/%s#%d to the issue it duplicates: %v", org, repo, e.Number, err)
+	}
+	return spc.CloseIssue(org, repo, e.Number)
+}
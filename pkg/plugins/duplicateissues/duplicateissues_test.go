@@ -0,0 +1,175 @@
+package duplicateissues
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type fakeClient struct {
+	issues        []*scm.Issue
+	collaborators sets.String
+	labels        []string
+	comments      []string
+	closed        bool
+	pages         []int
+}
+
+func (f *fakeClient) AddLabel(_, _ string, _ int, label string, _ bool) error {
+	f.labels = append(f.labels, label)
+	return nil
+}
+
+func (f *fakeClient) CloseIssue(string, string, int) error {
+	f.closed = true
+	return nil
+}
+
+func (f *fakeClient) CreateComment(_, _ string, _ int, _ bool, comment string) error {
+	f.comments = append(f.comments, comment)
+	return nil
+}
+
+func (f *fakeClient) IsCollaborator(_, _, login string) (bool, error) {
+	return f.collaborators.Has(login), nil
+}
+
+func (f *fakeClient) ListIssues(_, _ string, opts scm.IssueListOptions) ([]*scm.Issue, error) {
+	f.pages = append(f.pages, opts.Page)
+	start := (opts.Page - 1) * opts.Size
+	if start >= len(f.issues) {
+		return nil, nil
+	}
+	end := start + opts.Size
+	if end > len(f.issues) {
+		end = len(f.issues)
+	}
+	return f.issues[start:end], nil
+}
+
+func (f *fakeClient) QuoteAuthorForComment(author string) string {
+	return "@" + author
+}
+
+func TestSimilarities(t *testing.T) {
+	issue := &scm.Issue{Title: "Webhook fails with a timeout", Body: "The webhook of GitLab times out when the repository is large."}
+	candidates := []*scm.Issue{
+		{Title: "Webhook fails with a timeout", Body: "The webhook of GitLab times out when the repository is large."},
+		{Title: "GitLab webhook timeout", Body: "Large repositories make the webhook time out."},
+		{Title: "Add a dark theme to the dashboard"},
+		{},
+	}
+	s := similarities(issue, candidates)
+	require.Len(t, s, 4)
+	assert.InDelta(t, 1, s[0], 1e-9)
+	assert.True(t, s[1] > 0.2 && s[1] < s[0], "similar issue: %v", s[1])
+	assert.Equal(t, 0.0, s[2])
+	assert.Equal(t, 0.0, s[3])
+}
+
+func TestHandle(t *testing.T) {
+	spc := &fakeClient{
+		issues: []*scm.Issue{
+			{Number: 10, Title: "Webhook fails with a timeout on GitLab", Body: "The webhook times out for large repositories."},
+			{Number: 9, Title: "Webhook fails with a timeout on GitLab", Body: "The webhook times out for large repositories.", PullRequest: true},
+			{Number: 8, Title: "GitLab webhook timeout", Body: "The webhook of large repositories times out.", Closed: true},
+			{Number: 7, Title: "Add a dark theme to the dashboard", Body: "The dashboard is too bright."},
+		},
+	}
+	ie := &scm.IssueHook{
+		Action: scm.ActionOpen,
+		Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+		Issue:  scm.Issue{Number: 10, Title: "Timeout of the GitLab webhook", Body: "The webhook times out for large repositories with many branches."},
+	}
+	di := &plugins.DuplicateIssues{RecentIssues: 100, Threshold: 0.3, MaxSuggestions: 3}
+	l := logrus.WithField("plugin", pluginName)
+
+	require.NoError(t, handle(spc, l, di, ie))
+	require.Len(t, spc.comments, 1)
+	assert.Contains(t, spc.comments[0], "- #8 GitLab webhook timeout (closed, ")
+	assert.NotContains(t, spc.comments[0], "#9 ")
+	assert.NotContains(t, spc.comments[0], "#10 ")
+	assert.NotContains(t, spc.comments[0], "#7 ")
+	assert.Contains(t, spc.comments[0], "comment `/duplicate #8` to label it as `"+labels.TriageDuplicate+"`")
+
+	// nothing is reported below the threshold
+	di.Threshold = 0.99
+	require.NoError(t, handle(spc, l, di, ie))
+	assert.Len(t, spc.comments, 1)
+
+	// the other actions and the pull requests are ignored
+	di.Threshold = 0.3
+	ie.Action = scm.ActionEdited
+	require.NoError(t, handle(spc, l, di, ie))
+	ie.Action = scm.ActionOpen
+	ie.Issue.PullRequest = true
+	require.NoError(t, handle(spc, l, di, ie))
+	assert.Len(t, spc.comments, 1)
+}
+
+func TestRecentIssues(t *testing.T) {
+	spc := &fakeClient{}
+	for i := 250; i > 0; i-- {
+		spc.issues = append(spc.issues, &scm.Issue{Number: i})
+	}
+	issues, err := recentIssues(spc, "org", "repo", 150, 250)
+	require.NoError(t, err)
+	assert.Len(t, issues, 150)
+	assert.Equal(t, 249, issues[0].Number)
+	assert.Equal(t, []int{1, 2}, spc.pages)
+
+	spc.pages = nil
+	issues, err = recentIssues(spc, "org", "repo", 1000, 250)
+	require.NoError(t, err)
+	assert.Len(t, issues, 249)
+	assert.Equal(t, []int{1, 2, 3}, spc.pages)
+}
+
+func TestCommand(t *testing.T) {
+	cases := []struct {
+		name     string
+		user     string
+		arg      string
+		labelled bool
+		closed   bool
+		comment  string
+	}{
+		{name: "author labels", user: "author", labelled: true},
+		{name: "collaborator closes", user: "collab", arg: "#12", labelled: true, closed: true, comment: "Duplicate of #12"},
+		{name: "number without hash", user: "author", arg: "12", labelled: true, closed: true, comment: "Duplicate of #12"},
+		{name: "other user refused", user: "other", arg: "#12", comment: "only the author of the issue and the collaborators"},
+		{name: "duplicate of itself", user: "author", arg: "#5", comment: "an issue cannot duplicate itself."},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			spc := &fakeClient{collaborators: sets.NewString("collab")}
+			e := &scmprovider.GenericCommentEvent{
+				Repo:        scm.Repository{Namespace: "org", Name: "repo"},
+				Number:      5,
+				Author:      scm.User{Login: tc.user},
+				IssueAuthor: scm.User{Login: "author"},
+				Body:        "/duplicate " + tc.arg,
+			}
+			require.NoError(t, handleCommand(spc, logrus.WithField("plugin", pluginName), e, tc.arg))
+			if tc.labelled {
+				assert.Equal(t, []string{labels.TriageDuplicate}, spc.labels)
+			} else {
+				assert.Empty(t, spc.labels)
+			}
+			assert.Equal(t, tc.closed, spc.closed)
+			if tc.comment == "" {
+				assert.Empty(t, spc.comments)
+			} else {
+				require.Len(t, spc.comments, 1)
+				assert.Contains(t, spc.comments[0], tc.comment)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package duplicateissues
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// titleWeight is how many times the words of the title of an issue count compared to those of its body
+const titleWeight = 2
+
+var (
+	wordRe = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+	// stopWords are the common english words which tell nothing about an issue
+	stopWords = sets.NewString(
+		"a", "about", "after", "all", "also", "am", "an", "and", "any", "are", "as", "at", "be", "been", "but", "by",
+		"can", "could", "did", "do", "does", "for", "from", "get", "got", "had", "has", "have", "how", "i", "if", "in",
+		"into", "is", "it", "its", "me", "my", "no", "not", "of", "on", "or", "our", "so", "some", "than", "that",
+		"the", "their", "then", "there", "these", "this", "to", "was", "we", "were", "what", "when", "which", "while",
+		"who", "will", "with", "would", "you", "your",
+	)
+)
+
+// terms returns the number of occurrences of the words of an issue which are not stop words
+func terms(issue *scm.Issue) map[string]float64 {
+	counts := map[string]float64{}
+	add := func(text string, weight float64) {
+		for _, w := range wordRe.FindAllString(strings.ToLower(text), -1) {
+			if len(w) > 1 && !stopWords.Has(w) {
+				counts[w] += weight
+			}
+		}
+	}
+	add(issue.Title, titleWeight)
+	add(issue.Body, 1)
+	return counts
+}
+
+// similarities returns the cosine similarity, between 0 and 1, of the TF-IDF vectors of an issue and of each of the
+// candidates, the inverse document frequencies being those of the issue and the candidates
+func similarities(issue *scm.Issue, candidates []*scm.Issue) []float64 {
+	docs := []map[string]float64{terms(issue)}
+	for _, c := range candidates {
+		docs = append(docs, terms(c))
+	}
+	df := map[string]int{}
+	for _, d := range docs {
+		for w := range d {
+			df[w]++
+		}
+	}
+	n := float64(len(docs))
+	vectors := make([]map[string]float64, len(docs))
+	for i, d := range docs {
+		v := map[string]float64{}
+		norm := 0.0
+		for w, tf := range d {
+			x := tf * math.Log(1+n/float64(df[w]))
+			v[w] = x
+			norm += x * x
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for w := range v {
+				v[w] /= norm
+			}
+		}
+		vectors[i] = v
+	}
+
+	answer := make([]float64, len(candidates))
+	for i := range candidates {
+		dot := 0.0
+		for w, x := range vectors[0] {
+			dot += x * vectors[i+1][w]
+		}
+		answer[i] = dot
+	}
+	return answer
+}
@@ -0,0 +1,203 @@
+// Package heart contains a plugin which celebrates the contributors added to the OWNERS and OWNERS_ALIASES files by
+// the merged pull requests: it reacts to the pull requests with a heart, congratulates the new owners and optionally
+// invites them to join the org.
+package heart
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	pluginName = "heart"
+
+	// reaction is the reaction added to the pull requests adding owners
+	reaction = "heart"
+)
+
+var (
+	ownersFiles = sets.NewString("OWNERS", "OWNERS_ALIASES")
+
+	// listItemRe matches the list items of the YAML OWNERS files, e.g. `- alice` or `  - "bob" # maintainer`
+	listItemRe = regexp.MustCompile(`^\s*-\s*["']?([A-Za-z0-9][-A-Za-z0-9_]*)["']?\s*(?:#.*)?$`)
+)
+
+// MessageInfo is given to the template of the comment congratulating the new owners
+type MessageInfo struct {
+	Org      string
+	Repo     string
+	Logins   []string
+	Mentions string
+}
+
+type scmProviderClient interface {
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	CreateReaction(org, repo string, number int, pr bool, reaction string) error
+	GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error)
+	InviteOrgMember(org, user string) error
+	IsMember(org, user string) (bool, error)
+	QuoteAuthorForComment(string) string
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		pluginName,
+		plugins.Plugin{
+			Description:        "The heart plugin celebrates the contributors added to the OWNERS and OWNERS_ALIASES files by the merged pull requests: it reacts to the pull requests with a heart, congratulates the new owners and, if configured, invites them to join the org.",
+			ConfigHelpProvider: configHelp,
+			PullRequestHandler: handlePullRequest,
+		},
+	)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	heartConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		parts := strings.Split(repo, "/")
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
+		}
+		name := ""
+		if len(parts) == 2 {
+			name = parts[1]
+		}
+		ho := config.HeartOwnersFor(parts[0], name)
+		help := fmt.Sprintf("The new owners are congratulated using the following template: %s.", ho.MessageTemplate)
+		if ho.InviteToOrg {
+			help += " The new owners who are not members of the org are invited to join it."
+		}
+		heartConfig[repo] = help
+	}
+	return heartConfig, nil
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	ho := pc.PluginConfig.HeartOwnersFor(pre.Repo.Namespace, pre.Repo.Name)
+	return handle(pc.SCMProviderClient, pc.Logger, ho, &pre)
+}
+
+func handle(spc scmProviderClient, log *logrus.Entry, ho *plugins.HeartOwners, pre *scm.PullRequestHook) error {
+	if pre.Action != scm.ActionClose || !pre.PullRequest.Merged {
+		return nil
+	}
+	org := pre.Repo.Namespace
+	repo := pre.Repo.Name
+	number := pre.PullRequest.Number
+
+	changes, err := spc.GetPullRequestChanges(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to get the changes of %s/%s PR #%d: %v", org, repo, number, err)
+	}
+	logins := addedOwners(changes)
+	if len(logins) == 0 {
+		return nil
+	}
+	log.Infof("Celebrating the new owners %v", logins)
+
+	if err := spc.CreateReaction(org, repo, number, true, reaction); err != nil && err != scm.ErrNotSupported {
+		log.WithError(err).Warnf("Failed to react to %s/%s PR #%d", org, repo, number)
+	}
+
+	var mentions []string
+	for _, login := range logins {
+		mentions = append(mentions, spc.QuoteAuthorForComment(login))
+	}
+	msg, err := formatMessage(ho.MessageTemplate, MessageInfo{Org: org, Repo: repo, Logins: logins, Mentions: strings.Join(mentions, ", ")})
+	if err != nil {
+		return err
+	}
+	if ho.InviteToOrg {
+		invited, err := invite(spc, log, org, logins)
+		if err != nil {
+			return err
+		}
+		if len(invited) > 0 {
+			msg += fmt.Sprintf("\n\nAn invitation to join the %s org was sent to %s.", org, strings.Join(invited, ", "))
+		}
+	}
+	return spc.CreateComment(org, repo, number, true, msg)
+}
+
+// addedOwners returns the logins added to the OWNERS and OWNERS_ALIASES files by the changes, the logins only moved
+// within or across those files being ignored
+func addedOwners(changes []*scm.Change) []string {
+	var added []string
+	seen := sets.NewString()
+	removed := sets.NewString()
+	for _, change := range changes {
+		if !ownersFiles.Has(path.Base(change.Path)) {
+			continue
+		}
+		for _, line := range strings.Split(change.Patch, "\n") {
+			if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") || line == "" {
+				continue
+			}
+			m := listItemRe.FindStringSubmatch(line[1:])
+			if m == nil {
+				continue
+			}
+			login := strings.ToLower(m[1])
+			switch line[0] {
+			case '+':
+				if !seen.Has(login) {
+					seen.Insert(login)
+					added = append(added, m[1])
+				}
+			case '-':
+				removed.Insert(login)
+			}
+		}
+	}
+	var answer []string
+	for _, login := range added {
+		if !removed.Has(strings.ToLower(login)) {
+			answer = append(answer, login)
+		}
+	}
+	return answer
+}
+
+// invite invites the logins which are not members of the org to join it, returning the mentions of those invited
+func invite(spc scmProviderClient, log *logrus.Entry, org string, logins []string) ([]string, error) {
+	var invited []string
+	for _, login := range logins {
+		member, err := spc.IsMember(org, login)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check whether %s is a member of %s: %v", login, org, err)
+		}
+		if member {
+			continue
+		}
+		err = spc.InviteOrgMember(org, login)
+		if err == scm.ErrNotSupported {
+			log.Warnf("Inviting the new owners to join %s is not supported by the provider", org)
+			return invited, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to invite %s to join %s: %v", login, org, err)
+		}
+		invited = append(invited, spc.QuoteAuthorForComment(login))
+	}
+	return invited, nil
+}
+
+func formatMessage(messageTemplate string, info MessageInfo) (string, error) {
+	t, err := template.New("heart").Parse(messageTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid heart message template: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, info); err != nil {
+		return "", fmt.Errorf("failed to format the heart message: %v", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,103 @@
+package heart
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type fakeClient struct {
+	changes   []*scm.Change
+	members   sets.String
+	comments  []string
+	reactions []string
+	invited   []string
+	noInvites bool
+}
+
+func (f *fakeClient) CreateComment(_, _ string, _ int, _ bool, comment string) error {
+	f.comments = append(f.comments, comment)
+	return nil
+}
+
+func (f *fakeClient) CreateReaction(_, _ string, _ int, _ bool, reaction string) error {
+	f.reactions = append(f.reactions, reaction)
+	return nil
+}
+
+func (f *fakeClient) GetPullRequestChanges(string, string, int) ([]*scm.Change, error) {
+	return f.changes, nil
+}
+
+func (f *fakeClient) InviteOrgMember(_, user string) error {
+	if f.noInvites {
+		return scm.ErrNotSupported
+	}
+	f.invited = append(f.invited, user)
+	return nil
+}
+
+func (f *fakeClient) IsMember(_, user string) (bool, error) {
+	return f.members.Has(user), nil
+}
+
+func (f *fakeClient) QuoteAuthorForComment(author string) string {
+	return "@" + author
+}
+
+const ownersPatch = `@@ -1,4 +1,6 @@
+ approvers:
+ - alice
++- bob
++- "carol" # docs
+ reviewers:
+-- dave
++- Dave
++- erin`
+
+func TestAddedOwners(t *testing.T) {
+	changes := []*scm.Change{
+		{Path: "OWNERS", Patch: ownersPatch},
+		{Path: "docs/OWNERS_ALIASES", Patch: "@@ -1,2 +1,3 @@\n aliases:\n   docs-reviewers:\n+  - frank\n+  - bob"},
+		{Path: "README.md", Patch: "+- grace"},
+	}
+	assert.Equal(t, []string{"bob", "carol", "erin", "frank"}, addedOwners(changes))
+}
+
+func TestHandle(t *testing.T) {
+	merged := &scm.PullRequestHook{
+		Action:      scm.ActionClose,
+		Repo:        scm.Repository{Namespace: "org", Name: "repo"},
+		PullRequest: scm.PullRequest{Number: 5, Merged: true},
+	}
+	l := logrus.WithField("plugin", pluginName)
+
+	spc := &fakeClient{changes: []*scm.Change{{Path: "OWNERS", Patch: ownersPatch}}, members: sets.NewString("carol")}
+	ho := &plugins.HeartOwners{MessageTemplate: plugins.DefaultHeartOwnersMessage, InviteToOrg: true}
+	require.NoError(t, handle(spc, l, ho, merged))
+	assert.Equal(t, []string{reaction}, spc.reactions)
+	assert.Equal(t, []string{"bob", "erin"}, spc.invited)
+	require.Len(t, spc.comments, 1)
+	assert.Equal(t, "Congratulations @bob, @carol, @erin on becoming an owner of org/repo! ❤️ Thank you for your contributions.\n\nAn invitation to join the org org was sent to @bob, @erin.", spc.comments[0])
+
+	// the invitations are optional and may not be supported
+	for _, ho := range []*plugins.HeartOwners{{MessageTemplate: "Thanks {{.Mentions}}!"}, {MessageTemplate: "Thanks {{.Mentions}}!", InviteToOrg: true}} {
+		spc = &fakeClient{changes: []*scm.Change{{Path: "OWNERS", Patch: ownersPatch}}, members: sets.NewString(), noInvites: true}
+		require.NoError(t, handle(spc, l, ho, merged))
+		assert.Empty(t, spc.invited)
+		assert.Equal(t, []string{"Thanks @bob, @carol, @erin!"}, spc.comments)
+	}
+
+	// the pull requests which are not merged or which add no owner are ignored
+	spc = &fakeClient{changes: []*scm.Change{{Path: "OWNERS", Patch: ownersPatch}}}
+	require.NoError(t, handle(spc, l, ho, &scm.PullRequestHook{Action: scm.ActionClose, PullRequest: scm.PullRequest{Number: 5}}))
+	spc.changes = []*scm.Change{{Path: "OWNERS", Patch: "-- bob\n+- Bob"}}
+	require.NoError(t, handle(spc, l, ho, merged))
+	assert.Empty(t, spc.comments)
+	assert.Empty(t, spc.reactions)
+}
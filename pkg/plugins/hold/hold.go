@@ -22,8 +22,12 @@ package hold
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/holds"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/sirupsen/logrus"
 
@@ -39,17 +43,19 @@ type hasLabelFunc func(label string, issueLabels []*scm.Label) bool
 
 var (
 	plugin = plugins.Plugin{
-		Description: "The hold plugin allows anyone to add or remove the '" + labels.Hold + "' Label from a pull request in order to temporarily prevent the PR from merging without withholding approval.",
+		Description: "The hold plugin allows anyone to add or remove the '" + labels.Hold + "' Label from a pull request in order to temporarily prevent the PR from merging without withholding approval. " +
+			"The hold may be given a reason, shown in the status of Keeper, and a duration after which Keeper removes it, e.g. '/hold 48h flaky infra'.",
 		Commands: []plugins.Command{{
 			Name:        "hold",
-			Description: "Adds or removes the `" + labels.Hold + "` Label which is used to indicate that the PR should not be automatically merged.",
+			Description: "Adds or removes the `" + labels.Hold + "` Label which is used to indicate that the PR should not be automatically merged, optionally recording the reason and the duration of the hold, made of weeks, days, hours and minutes.",
 			Arg: &plugins.CommandArg{
-				Pattern:  "cancel",
+				Usage:    "cancel|[duration] [reason]",
+				Pattern:  `[^\r\n]+`,
 				Optional: true,
 			},
 			Action: plugins.
 				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
-					return handleGenericComment(match.Arg == "cancel", pc, e)
+					return handleGenericComment(match.Arg, pc, e)
 				}).
 				When(plugins.Action(scm.ActionCreate)),
 		}},
@@ -61,36 +67,72 @@ func init() {
 }
 
 type scmProviderClient interface {
+	botcomment.ListingClient
 	AddLabel(owner, repo string, number int, label string, pr bool) error
 	RemoveLabel(owner, repo string, number int, label string, pr bool) error
 	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
 }
 
-func handleGenericComment(cancel bool, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+func handleGenericComment(arg string, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
 	hasLabel := func(label string, labels []*scm.Label) bool {
 		return scmprovider.HasLabel(label, labels)
 	}
-	return handle(cancel, pc.SCMProviderClient, pc.Logger, &e, hasLabel)
+	return handle(arg, time.Now(), pc.SCMProviderClient, pc.Logger, &e, hasLabel)
+}
+
+// parseArg returns the hold requested by the argument of the /hold command, made of an optional duration followed by
+// an optional reason
+func parseArg(arg, author string, now time.Time) *holds.Hold {
+	hold := &holds.Hold{By: author, Reason: strings.TrimSpace(arg)}
+	fields := strings.Fields(arg)
+	if len(fields) == 0 {
+		return hold
+	}
+	if d, err := holds.ParseDuration(fields[0]); err == nil {
+		expiry := now.Add(d).UTC()
+		hold.Expiry = &expiry
+		hold.Reason = strings.TrimSpace(strings.TrimPrefix(hold.Reason, fields[0]))
+	}
+	return hold
 }
 
 // handle drives the pull request to the desired state. If any user adds
 // a /hold directive, we want to add a label if one does not already exist.
 // If they add /hold cancel, we want to remove the label if it exists.
-func handle(cancel bool, spc scmProviderClient, log *logrus.Entry, e *scmprovider.GenericCommentEvent, f hasLabelFunc) error {
-	needsLabel := !cancel
-
-	issueLabels, err := spc.GetIssueLabels(e.Repo.Namespace, e.Repo.Name, e.Number, e.IsPR)
+// The reason and the expiry of the hold are recorded in a sticky comment,
+// which is removed along with the hold.
+func handle(arg string, now time.Time, spc scmProviderClient, log *logrus.Entry, e *scmprovider.GenericCommentEvent, f hasLabelFunc) error {
+	org := e.Repo.Namespace
+	repo := e.Repo.Name
+	needsLabel := strings.TrimSpace(arg) != "cancel"
+
+	issueLabels, err := spc.GetIssueLabels(org, repo, e.Number, e.IsPR)
 	if err != nil {
-		return fmt.Errorf("failed to get the labels on %s/%s#%d: %v", e.Repo.Namespace, e.Repo.Name, e.Number, err)
+		return fmt.Errorf("failed to get the labels on %s/%s#%d: %v", org, repo, e.Number, err)
 	}
 
 	hasLabel := f(labels.Hold, issueLabels)
 	if hasLabel && !needsLabel {
-		log.Infof("Removing %q Label for %s/%s#%d", labels.Hold, e.Repo.Namespace, e.Repo.Name, e.Number)
-		return spc.RemoveLabel(e.Repo.Namespace, e.Repo.Name, e.Number, labels.Hold, e.IsPR)
+		log.Infof("Removing %q Label for %s/%s#%d", labels.Hold, org, repo, e.Number)
+		if err := spc.RemoveLabel(org, repo, e.Number, labels.Hold, e.IsPR); err != nil {
+			return err
+		}
 	} else if !hasLabel && needsLabel {
-		log.Infof("Adding %q Label for %s/%s#%d", labels.Hold, e.Repo.Namespace, e.Repo.Name, e.Number)
-		return spc.AddLabel(e.Repo.Namespace, e.Repo.Name, e.Number, labels.Hold, e.IsPR)
+		log.Infof("Adding %q Label for %s/%s#%d", labels.Hold, org, repo, e.Number)
+		if err := spc.AddLabel(org, repo, e.Number, labels.Hold, e.IsPR); err != nil {
+			return err
+		}
+	}
+
+	// a hold with neither reason nor expiry replaces the one recorded before, if any
+	var body string
+	if needsLabel {
+		if hold := parseArg(arg, e.Author.Login, now); hold.Reason != "" || hold.Expiry != nil {
+			body = hold.Format()
+		}
+	}
+	if body == "" && !hasLabel && !needsLabel {
+		return nil
 	}
-	return nil
+	return botcomment.Upsert(spc, org, repo, e.Number, e.IsPR, holds.Marker(), body)
 }
@@ -19,11 +19,16 @@ package hold
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	lhfake "github.com/jenkins-x/lighthouse/pkg/fake"
+	"github.com/jenkins-x/lighthouse/pkg/holds"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jenkins-x/lighthouse/pkg/labels"
 )
@@ -106,7 +111,7 @@ func TestHandle(t *testing.T) {
 				t.Fatalf("(%s): Unexpected error from handle: %v.", tc.name, err)
 			}
 			for _, m := range matches {
-				if err := handle(m.Arg == "cancel", scmprovider.ToTestClient(client), logrus.WithField("plugin", pluginName), e, hasLabel); err != nil {
+				if err := handle(m.Arg, time.Now(), scmprovider.ToTestClient(client), logrus.WithField("plugin", pluginName), e, hasLabel); err != nil {
 					t.Fatalf("For case %s, didn't expect error from hold: %v", tc.name, err)
 				}
 			}
@@ -129,3 +134,64 @@ func TestHandle(t *testing.T) {
 		})
 	}
 }
+
+func TestHoldRecord(t *testing.T) {
+	server := lhfake.NewServer("bot")
+	defer server.Close()
+	server.AddRepository("org", "repo", "master", "base-sha")
+	number, err := server.OpenPullRequest("org/repo", "author", "a change", "master", "feature", "head-sha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spc := scmprovider.ToClient(server.Client(), "bot")
+	now := time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC)
+
+	hold := func(body string) *holds.Hold {
+		e := &scmprovider.GenericCommentEvent{
+			Action: scm.ActionCreate,
+			Body:   body,
+			Number: number,
+			IsPR:   true,
+			Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+			Author: scm.User{Login: "user"},
+		}
+		matches, err := plugin.Commands[0].FilterAndGetMatches(e)
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		require.NoError(t, handle(matches[0].Arg, now, spc, logrus.WithField("plugin", pluginName), e, scmprovider.HasLabel))
+		comments, err := spc.ListPullRequestComments("org", "repo", number)
+		require.NoError(t, err)
+		return holds.Find(comments, "bot")
+	}
+	hasLabel := func() bool {
+		return len(server.Repository("org/repo").Issues[number].Labels) == 1
+	}
+
+	h := hold("/hold 48h flaky infra")
+	require.NotNil(t, h)
+	assert.True(t, hasLabel())
+	assert.Equal(t, "user", h.By)
+	assert.Equal(t, "flaky infra", h.Reason)
+	require.NotNil(t, h.Expiry)
+	assert.Equal(t, now.Add(48*time.Hour), *h.Expiry)
+
+	h = hold("/hold waiting for the release")
+	require.NotNil(t, h)
+	assert.Equal(t, "waiting for the release", h.Reason)
+	assert.Nil(t, h.Expiry)
+	comments, err := spc.ListPullRequestComments("org", "repo", number)
+	require.NoError(t, err)
+	assert.Len(t, comments, 1, "the hold should be recorded in a single comment")
+
+	h = hold("/hold 2d")
+	require.NotNil(t, h)
+	assert.Equal(t, "", h.Reason)
+	assert.Equal(t, now.Add(48*time.Hour), *h.Expiry)
+
+	assert.Nil(t, hold("/hold"))
+	assert.True(t, hasLabel())
+
+	hold("/hold 1w")
+	assert.Nil(t, hold("/hold cancel"))
+	assert.False(t, hasLabel())
+}
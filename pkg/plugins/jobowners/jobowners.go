@@ -0,0 +1,150 @@
+// Package jobowners contains a plugin which cc's the teams owning the presubmits failing repeatedly on a pull
+// request, and tracks the failures of those likely flaky presubmits in an issue of the repository. The failures are
+// reported by foghorn, which observes the completed jobs.
+package jobowners
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PluginName is the name of the plugin, which must be enabled for the repositories whose failures are reported
+	PluginName = "job-owners"
+
+	// pageSize is the maximum number of issues listed per page
+	pageSize = 100
+	// maxPages is the maximum number of pages of open issues searched for the flake-tracking issue of a job
+	maxPages = 10
+)
+
+type scmProviderClient interface {
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	CreateIssue(owner, repo, title, body string) (*scm.Issue, error)
+	ListIssues(owner, repo string, opts scm.IssueListOptions) ([]*scm.Issue, error)
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		PluginName,
+		plugins.Plugin{
+			Description:        "The job-owners plugin cc's the teams owning a presubmit on a pull request once the presubmit failed repeatedly on it, and tracks the failures of such likely flaky presubmits in an issue of the repository.",
+			ConfigHelpProvider: configHelp,
+		},
+	)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	ownersConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		parts := strings.Split(repo, "/")
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
+		}
+		name := ""
+		if len(parts) == 2 {
+			name = parts[1]
+		}
+		jo := config.JobOwnersFor(parts[0], name)
+		var owners []string
+		for _, o := range jo.Owners {
+			owners = append(owners, fmt.Sprintf("<li>%s: %s</li>", o.Job, strings.Join(o.Teams, ", ")))
+		}
+		help := fmt.Sprintf("The presubmits failing %d times on a pull request are tracked in issues labelled %q.", jo.FlakeThreshold, jo.FlakeLabel)
+		if len(owners) > 0 {
+			help += fmt.Sprintf(" The following owners are cc'ed on the pull requests:<ul>%s</ul>", strings.Join(owners, ""))
+		}
+		ownersConfig[repo] = help
+	}
+	return ownersConfig, nil
+}
+
+// IssueTitle returns the title of the flake-tracking issue of a job
+func IssueTitle(job string) string {
+	return fmt.Sprintf("Flaky job: %s", job)
+}
+
+// ReportFailure handles a failed run of a presubmit, given the number of failed runs of the presubmit on the pull
+// request including this one. Once the presubmit failed FlakeThreshold times, its owners are cc'ed on the pull request
+// and this failure, like the next ones, is recorded in the flake-tracking issue of the presubmit.
+func ReportFailure(spc scmProviderClient, log *logrus.Entry, jo *plugins.JobOwners, j *v1alpha1.LighthouseJob, failures int) error {
+	refs := j.Spec.Refs
+	if refs == nil || len(refs.Pulls) == 0 || failures < jo.FlakeThreshold {
+		return nil
+	}
+	org := refs.Org
+	repo := refs.Repo
+	pull := refs.Pulls[0]
+	name := j.Spec.Job
+
+	if teams := jo.TeamsFor(name); failures == jo.FlakeThreshold && len(teams) > 0 {
+		var mentions []string
+		for _, t := range teams {
+			mentions = append(mentions, "@"+strings.TrimPrefix(t, "@"))
+		}
+		msg := fmt.Sprintf("The `%s` job failed %d times on this pull request, it may be flaky.\n\ncc %s", name, failures, strings.Join(mentions, " "))
+		log.Infof("Cc'ing %v on %s/%s#%d", teams, org, repo, pull.Number)
+		if err := spc.CreateComment(org, repo, pull.Number, true, msg); err != nil {
+			return fmt.Errorf("failed to cc the owners of %s on %s/%s#%d: %v", name, org, repo, pull.Number, err)
+		}
+	}
+
+	issue, err := findIssue(spc, org, repo, name)
+	if err != nil {
+		return fmt.Errorf("failed to find the flake-tracking issue of %s in %s/%s: %v", name, org, repo, err)
+	}
+	if issue == nil {
+		body := fmt.Sprintf("The `%s` job failed repeatedly on some pull requests, it may be flaky. Its failures are listed below.", name)
+		issue, err = spc.CreateIssue(org, repo, IssueTitle(name), body)
+		if err != nil {
+			return fmt.Errorf("failed to create the flake-tracking issue of %s in %s/%s: %v", name, org, repo, err)
+		}
+		log.Infof("Created the flake-tracking issue %s/%s#%d of %s", org, repo, issue.Number, name)
+		if err := spc.AddLabel(org, repo, issue.Number, jo.FlakeLabel, false); err != nil {
+			log.WithError(err).Warnf("Failed to label the flake-tracking issue %s/%s#%d", org, repo, issue.Number)
+		}
+	}
+	return spc.CreateComment(org, repo, issue.Number, false, failureComment(j, failures))
+}
+
+// findIssue returns the open flake-tracking issue of a job, nil if there is none
+func findIssue(spc scmProviderClient, org, repo, job string) (*scm.Issue, error) {
+	title := IssueTitle(job)
+	for page := 1; page <= maxPages; page++ {
+		issues, err := spc.ListIssues(org, repo, scm.IssueListOptions{Page: page, Size: pageSize, Open: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			if !issue.PullRequest && !issue.Closed && issue.Title == title {
+				return issue, nil
+			}
+		}
+		if len(issues) < pageSize {
+			break
+		}
+	}
+	return nil, nil
+}
+
+func failureComment(j *v1alpha1.LighthouseJob, failures int) string {
+	pull := j.Spec.Refs.Pulls[0]
+	link := pull.Link
+	if link == "" {
+		link = fmt.Sprintf("%s/%s#%d", j.Spec.Refs.Org, j.Spec.Refs.Repo, pull.Number)
+	}
+	msg := fmt.Sprintf("Failure %d of the job on %s at %s", failures, link, pull.SHA)
+	if j.Status.ReportURL != "" {
+		msg += fmt.Sprintf(" ([logs](%s))", j.Status.ReportURL)
+	}
+	if j.Status.Description != "" {
+		msg += fmt.Sprintf(": %s", j.Status.Description)
+	}
+	return msg
+}
@@ -0,0 +1,155 @@
+package jobowners
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	issues   []*scm.Issue
+	comments map[int][]string
+	labels   map[int][]string
+}
+
+func (f *fakeClient) AddLabel(_, _ string, number int, label string, _ bool) error {
+	f.labels[number] = append(f.labels[number], label)
+	return nil
+}
+
+func (f *fakeClient) CreateComment(_, _ string, number int, _ bool, comment string) error {
+	f.comments[number] = append(f.comments[number], comment)
+	return nil
+}
+
+func (f *fakeClient) CreateIssue(_, _, title, body string) (*scm.Issue, error) {
+	issue := &scm.Issue{Number: 100 + len(f.issues), Title: title, Body: body}
+	f.issues = append(f.issues, issue)
+	return issue, nil
+}
+
+func (f *fakeClient) ListIssues(_, _ string, opts scm.IssueListOptions) ([]*scm.Issue, error) {
+	start := (opts.Page - 1) * opts.Size
+	if start >= len(f.issues) {
+		return nil, nil
+	}
+	end := start + opts.Size
+	if end > len(f.issues) {
+		end = len(f.issues)
+	}
+	return f.issues[start:end], nil
+}
+
+func TestReportFailure(t *testing.T) {
+	jo := &plugins.JobOwners{
+		FlakeThreshold: 3,
+		FlakeLabel:     labels.Flake,
+		Owners: []plugins.JobOwner{
+			{Job: "integration-.*", JobRe: regexp.MustCompile("^(?:integration-.*)$"), Teams: []string{"org/infra", "@alice"}},
+		},
+	}
+	cases := []struct {
+		name             string
+		job              string
+		failures         int
+		issues           []*scm.Issue
+		expectedPRCc     bool
+		expectedIssue    int
+		expectedCreated  bool
+		expectedComments int
+	}{
+		{
+			name:     "below threshold",
+			job:      "integration-tests",
+			failures: 2,
+		},
+		{
+			name:             "threshold reached",
+			job:              "integration-tests",
+			failures:         3,
+			expectedPRCc:     true,
+			expectedIssue:    100,
+			expectedCreated:  true,
+			expectedComments: 1,
+		},
+		{
+			name:     "existing issue",
+			job:      "integration-tests",
+			failures: 4,
+			issues: []*scm.Issue{
+				{Number: 7, Title: "Flaky job: integration-tests", PullRequest: true},
+				{Number: 8, Title: "Flaky job: unit-tests"},
+				{Number: 9, Title: "Flaky job: integration-tests"},
+			},
+			expectedIssue:    9,
+			expectedComments: 1,
+		},
+		{
+			name:             "job without owners",
+			job:              "unit-tests",
+			failures:         3,
+			expectedIssue:    100,
+			expectedCreated:  true,
+			expectedComments: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			spc := &fakeClient{issues: tc.issues, comments: map[int][]string{}, labels: map[int][]string{}}
+			j := &v1alpha1.LighthouseJob{
+				Spec: v1alpha1.LighthouseJobSpec{
+					Job: tc.job,
+					Refs: &v1alpha1.Refs{
+						Org:   "org",
+						Repo:  "repo",
+						Pulls: []v1alpha1.Pull{{Number: 5, SHA: "abc", Link: "https://github.com/org/repo/pull/5"}},
+					},
+				},
+				Status: v1alpha1.LighthouseJobStatus{ReportURL: "https://dashboard/run/1"},
+			}
+			require.NoError(t, ReportFailure(spc, logrus.WithField("plugin", PluginName), jo, j, tc.failures))
+
+			if tc.expectedPRCc {
+				require.Len(t, spc.comments[5], 1)
+				assert.Equal(t, "The `integration-tests` job failed 3 times on this pull request, it may be flaky.\n\ncc @org/infra @alice", spc.comments[5][0])
+			} else {
+				assert.Empty(t, spc.comments[5])
+			}
+			if tc.expectedIssue == 0 {
+				assert.Len(t, spc.comments, 0)
+				return
+			}
+			if tc.expectedCreated {
+				assert.Equal(t, []string{labels.Flake}, spc.labels[tc.expectedIssue])
+			} else {
+				assert.Empty(t, spc.labels)
+			}
+			require.Len(t, spc.comments[tc.expectedIssue], tc.expectedComments)
+			assert.Contains(t, spc.comments[tc.expectedIssue][0], "https://github.com/org/repo/pull/5 at abc ([logs](https://dashboard/run/1))")
+		})
+	}
+}
+
+func TestFindIssuePages(t *testing.T) {
+	spc := &fakeClient{}
+	for i := 0; i < pageSize+1; i++ {
+		spc.issues = append(spc.issues, &scm.Issue{Number: i + 1, Title: "Some issue"})
+	}
+	spc.issues[pageSize].Title = IssueTitle("flaky")
+
+	issue, err := findIssue(spc, "org", "repo", "flaky")
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	assert.Equal(t, pageSize+1, issue.Number)
+
+	issue, err = findIssue(spc, "org", "repo", "other")
+	require.NoError(t, err)
+	assert.Nil(t, issue)
+}
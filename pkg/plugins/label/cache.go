@@ -0,0 +1,54 @@
+package label
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// labelsCacheTTL is how long the labels of a repository are cached for
+const labelsCacheTTL = 5 * time.Minute
+
+type labelsCacheEntry struct {
+	labels  []*scm.Label
+	expires time.Time
+}
+
+// labelsCache caches the labels of the repositories, which are listed for most of the label commands
+type labelsCache struct {
+	lock    sync.Mutex
+	entries map[string]labelsCacheEntry
+}
+
+var (
+	repoLabelsCache = newLabelsCache()
+
+	now = time.Now
+)
+
+func newLabelsCache() *labelsCache {
+	return &labelsCache{entries: map[string]labelsCacheEntry{}}
+}
+
+// get returns the labels of a repository, listing them if they are not cached yet, if they expired or if refresh
+// is true, e.g. when a label is not found among the cached ones as it may have been created since. The errors are
+// not cached.
+func (c *labelsCache) get(spc scmProviderClient, org, repo string, refresh bool) ([]*scm.Label, error) {
+	key := strings.ToLower(org + "/" + repo)
+	c.lock.Lock()
+	entry, ok := c.entries[key]
+	c.lock.Unlock()
+	if ok && !refresh && now().Before(entry.expires) {
+		return entry.labels, nil
+	}
+	labels, err := spc.GetRepoLabels(org, repo)
+	if err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	c.entries[key] = labelsCacheEntry{labels: labels, expires: now().Add(labelsCacheTTL)}
+	c.lock.Unlock()
+	return labels, nil
+}
@@ -32,6 +32,7 @@ const pluginName = "label"
 var (
 	defaultLabels           = []string{"kind", "priority", "area"}
 	nonExistentLabelOnIssue = "Those labels are not set on the issue: `%v`"
+	nonExistentLabelInRepo  = "The repository does not have those labels: `%v`"
 )
 
 var (
@@ -42,9 +43,10 @@ var (
 			Prefix: "remove-",
 			Name:   "area|committee|kind|language|priority|sig|triage|wg|label",
 			Arg: &plugins.CommandArg{
-				Pattern: ".*",
+				Pattern:  ".*",
+				Optional: true,
 			},
-			Description: "Applies or removes a label from one of the recognized types of labels.",
+			Description: "Applies or removes a label from one of the recognized types of labels. Without any label, e.g. `/area`, lists the labels of the type available in the repository.",
 			Action: plugins.
 				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
 					return handle(match.Prefix != "", match.Name, match.Arg, pc.SCMProviderClient, pc.Logger, pc.PluginConfig.Label.AdditionalLabels, &e)
@@ -111,32 +113,37 @@ func handle(remove bool, kind string, target string, spc scmProviderClient, log
 	org := e.Repo.Namespace
 	repo := e.Repo.Name
 
-	repoLabels, err := spc.GetRepoLabels(org, repo)
+	respond := func(msg string) error {
+		log.Info(msg)
+		return spc.CreateComment(org, repo, e.Number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(e.Author.Login), msg))
+	}
+
+	// Get labels to add and labels to remove from regexp matches
+	var lbls []string
+	if kind == "label" {
+		lbls = append(lbls, getLabelsFromGenericMatches(target, additionalLabels)...)
+	} else if strings.TrimSpace(target) != "" {
+		lbls = append(lbls, getLabelsFromREMatches(kind, target)...)
+	}
+
+	RepoLabelsExisting, err := getRepoLabels(spc, org, repo, lbls)
 	if err != nil {
 		return err
 	}
+	// A command without any label, e.g. /area, lists the labels of its kind
+	if kind != "label" && len(lbls) == 0 {
+		return respond(availableLabels(kind, RepoLabelsExisting))
+	}
 	labels, err := spc.GetIssueLabels(org, repo, e.Number, e.IsPR)
 	if err != nil {
 		return err
 	}
 
-	RepoLabelsExisting := map[string]string{}
-	for _, l := range repoLabels {
-		RepoLabelsExisting[strings.ToLower(l.Name)] = l.Name
-	}
 	var (
 		nonexistent         []string
 		noSuchLabelsOnIssue []string
 	)
 
-	// Get labels to add and labels to remove from regexp matches
-	var lbls []string
-	if kind == "label" {
-		lbls = append(lbls, getLabelsFromGenericMatches(target, additionalLabels)...)
-	} else {
-		lbls = append(lbls, getLabelsFromREMatches(kind, target)...)
-	}
-
 	for _, lbl := range lbls {
 		if remove {
 			if !scmprovider.HasLabel(lbl, labels) {
@@ -163,17 +170,56 @@ func handle(remove bool, kind string, target string, spc scmProviderClient, log
 		}
 	}
 
-	//TODO(grodrigues3): Once labels are standardized, make this reply with a comment.
+	var msgs []string
 	if len(nonexistent) > 0 {
 		log.Infof("Nonexistent labels: %v", nonexistent)
+		msgs = append(msgs, nonexistentLabelsMessage(nonexistent, RepoLabelsExisting))
 	}
-
 	// Tried to remove Labels that were not present on the Issue
 	if len(noSuchLabelsOnIssue) > 0 {
-		msg := fmt.Sprintf(nonExistentLabelOnIssue, strings.Join(noSuchLabelsOnIssue, ", "))
-		log.Info(msg)
-		return spc.CreateComment(org, repo, e.Number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(e.Author.Login), msg))
+		msgs = append(msgs, fmt.Sprintf(nonExistentLabelOnIssue, strings.Join(noSuchLabelsOnIssue, ", ")))
+	}
+	if len(msgs) > 0 {
+		return respond(strings.Join(msgs, "\n\n"))
 	}
-
 	return nil
 }
+
+// getRepoLabels returns the labels of the repository keyed by their lower case names, refreshing the cached labels
+// once if any of the wanted labels is missing as it may have been created since they were cached
+func getRepoLabels(spc scmProviderClient, org, repo string, wanted []string) (map[string]string, error) {
+	answer := map[string]string{}
+	for refresh := false; ; refresh = true {
+		repoLabels, err := repoLabelsCache.get(spc, org, repo, refresh)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range repoLabels {
+			answer[strings.ToLower(l.Name)] = l.Name
+		}
+		missing := false
+		for _, lbl := range wanted {
+			if _, ok := answer[lbl]; !ok {
+				missing = true
+			}
+		}
+		if !missing || refresh {
+			return answer, nil
+		}
+		answer = map[string]string{}
+	}
+}
+
+// nonexistentLabelsMessage returns the response to a command with labels the repository does not have, suggesting
+// the closest labels or listing those of the same kind
+func nonexistentLabelsMessage(nonexistent []string, repoLabels map[string]string) string {
+	msg := fmt.Sprintf(nonExistentLabelInRepo, strings.Join(nonexistent, ", "))
+	for _, lbl := range nonexistent {
+		if s := suggest(lbl, repoLabels); s != "" {
+			msg += fmt.Sprintf("\n- `%s`: did you mean `%s`?", lbl, s)
+		} else if i := strings.Index(lbl, "/"); i > 0 {
+			msg += fmt.Sprintf("\n- `%s`: %s", lbl, availableLabels(lbl[:i], repoLabels))
+		}
+	}
+	return msg
+}
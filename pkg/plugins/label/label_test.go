@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/driver/fake"
@@ -56,6 +57,7 @@ func TestLabel(t *testing.T) {
 		expectedNewLabels     []string
 		expectedRemovedLabels []string
 		expectedBotComment    bool
+		expectedComment       string
 		repoLabels            []string
 		issueLabels           []string
 	}
@@ -77,6 +79,50 @@ func TestLabel(t *testing.T) {
 			repoLabels:            []string{"area/infra"},
 			issueLabels:           []string{"area/infra"},
 			commenter:             orgMember,
+			expectedBotComment:    true,
+			expectedComment:       "The available `area` labels are: `area/infra`.",
+		},
+		{
+			name:                  "Suggest Closest Area Label",
+			body:                  "/area netwrking",
+			repoLabels:            []string{"area/infra", "area/networking", "area/network-policies"},
+			issueLabels:           []string{},
+			expectedNewLabels:     formatLabels(),
+			expectedRemovedLabels: []string{},
+			commenter:             orgMember,
+			expectedBotComment:    true,
+			expectedComment:       "- `area/netwrking`: did you mean `area/networking`?",
+		},
+		{
+			name:                  "List Available Kind Labels",
+			body:                  "/kind",
+			repoLabels:            []string{"area/infra", labels.Bug, "kind/feature"},
+			issueLabels:           []string{},
+			expectedNewLabels:     formatLabels(),
+			expectedRemovedLabels: []string{},
+			commenter:             orgMember,
+			expectedBotComment:    true,
+			expectedComment:       "The available `kind` labels are: `kind/bug`, `kind/feature`.",
+		},
+		{
+			name:                  "No Available Priority Labels",
+			body:                  "/remove-priority",
+			repoLabels:            []string{"area/infra"},
+			issueLabels:           []string{},
+			expectedNewLabels:     formatLabels(),
+			expectedRemovedLabels: []string{},
+			commenter:             orgMember,
+			expectedBotComment:    true,
+			expectedComment:       "This repository has no `priority` labels.",
+		},
+		{
+			name:                  "Empty Generic Label",
+			body:                  "/label",
+			repoLabels:            []string{"area/infra"},
+			issueLabels:           []string{},
+			expectedNewLabels:     formatLabels(),
+			expectedRemovedLabels: []string{},
+			commenter:             orgMember,
 		},
 		{
 			name:                  "Add Single Area Label",
@@ -158,6 +204,8 @@ func TestLabel(t *testing.T) {
 			expectedNewLabels:     formatLabels(),
 			expectedRemovedLabels: []string{},
 			commenter:             orgMember,
+			expectedBotComment:    true,
+			expectedComment:       "- `priority/critical`: This repository has no `priority` labels.",
 		},
 		{
 			name:                  "Non Org Member Can't Add",
@@ -185,6 +233,8 @@ func TestLabel(t *testing.T) {
 			expectedNewLabels:     formatLabels(),
 			expectedRemovedLabels: []string{},
 			commenter:             orgMember,
+			expectedBotComment:    true,
+			expectedComment:       "- `area/lgtm`: The available `area` labels are: `area/api`, `area/infra`.",
 		},
 		{
 			name:                  "Add Multiple Area Labels",
@@ -221,6 +271,8 @@ func TestLabel(t *testing.T) {
 			expectedNewLabels:     formatLabels(),
 			expectedRemovedLabels: []string{},
 			commenter:             orgMember,
+			expectedBotComment:    true,
+			expectedComment:       "- `area/urgent`: did you mean `priority/urgent`?",
 		},
 		{
 			name:                  "Label Prefix Must Match Command (Priority-Area Mismatch)",
@@ -230,6 +282,8 @@ func TestLabel(t *testing.T) {
 			expectedNewLabels:     formatLabels(),
 			expectedRemovedLabels: []string{},
 			commenter:             orgMember,
+			expectedBotComment:    true,
+			expectedComment:       "- `priority/infra`: did you mean `area/infra`?",
 		},
 		{
 			name:                  "Add Multiple Area Labels (Some Valid)",
@@ -239,6 +293,8 @@ func TestLabel(t *testing.T) {
 			expectedNewLabels:     formatLabels("area/infra"),
 			expectedRemovedLabels: []string{},
 			commenter:             orgMember,
+			expectedBotComment:    true,
+			expectedComment:       "The repository does not have those labels: `area/lgtm`",
 		},
 		{
 			name:                  "Add Multiple Committee Labels (Some Valid)",
@@ -248,6 +304,8 @@ func TestLabel(t *testing.T) {
 			expectedNewLabels:     formatLabels("committee/steering"),
 			expectedRemovedLabels: []string{},
 			commenter:             orgMember,
+			expectedBotComment:    true,
+			expectedComment:       "The repository does not have those labels: `committee/calamity`",
 		},
 		{
 			name:                  "Add Multiple Types of Labels Different Lines",
@@ -471,6 +529,7 @@ func TestLabel(t *testing.T) {
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
 			sort.Strings(tc.expectedNewLabels)
+			repoLabelsCache = newLabelsCache()
 			fakeScmClient, fakeData := fake.NewDefault()
 			fakeClient := scmprovider.ToTestClient(fakeScmClient)
 			fakeData.OrgMembers["org"] = []string{orgMember}
@@ -525,6 +584,9 @@ func TestLabel(t *testing.T) {
 			if len(fakeData.IssueCommentsAdded) == 0 && tc.expectedBotComment {
 				t.Error("expected a bot comment but got none")
 			}
+			if tc.expectedComment != "" && (len(fakeData.IssueCommentsAdded) != 1 || !strings.Contains(fakeData.IssueCommentsAdded[0], tc.expectedComment)) {
+				t.Errorf("expected a bot comment containing %q but got %#v", tc.expectedComment, fakeData.IssueCommentsAdded)
+			}
 		})
 	}
 }
@@ -581,3 +643,72 @@ func TestHelpProvider(t *testing.T) {
 		})
 	}
 }
+
+type countingClient struct {
+	scmProviderClient
+	labels []*scm.Label
+	calls  int
+}
+
+func (c *countingClient) GetRepoLabels(string, string) ([]*scm.Label, error) {
+	c.calls++
+	return c.labels, nil
+}
+
+func TestRepoLabelsCache(t *testing.T) {
+	defer func(n func() time.Time) { now = n }(now)
+	current := time.Now()
+	now = func() time.Time { return current }
+	repoLabelsCache = newLabelsCache()
+	spc := &countingClient{labels: []*scm.Label{{Name: "area/infra"}}}
+
+	for _, wanted := range [][]string{nil, {"area/infra"}} {
+		if _, err := getRepoLabels(spc, "Org", "repo", wanted); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if spc.calls != 1 {
+		t.Errorf("expected the labels to be listed once, but they were listed %d times", spc.calls)
+	}
+
+	// a missing label refreshes the cached labels
+	spc.labels = append(spc.labels, &scm.Label{Name: "Area/API"})
+	existing, err := getRepoLabels(spc, "org", "repo", []string{"area/api"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if existing["area/api"] != "Area/API" || spc.calls != 2 {
+		t.Errorf("expected the labels to be refreshed, but got %v after %d calls", existing, spc.calls)
+	}
+
+	// the cached labels expire
+	current = current.Add(labelsCacheTTL)
+	if _, err := getRepoLabels(spc, "org", "repo", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spc.calls != 3 {
+		t.Errorf("expected the expired labels to be listed again, but they were listed %d times", spc.calls)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	repoLabels := map[string]string{
+		"area/networking": "area/networking",
+		"area/infra":      "area/infra",
+		"priority/urgent": "priority/urgent",
+		"lgtm":            "lgtm",
+	}
+	cases := map[string]string{
+		"area/networkin":   "area/networking",
+		"area/infr":        "area/infra",
+		"area/urgent":      "priority/urgent",
+		"area/storage":     "",
+		"priority/critcal": "",
+		"lgtm":             "",
+	}
+	for label, expected := range cases {
+		if actual := suggest(label, repoLabels); actual != expected {
+			t.Errorf("expected %q to be suggested for %q, but got %q", expected, label, actual)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package label
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxListedLabels is the maximum number of labels listed in a comment
+const maxListedLabels = 20
+
+// labelsOfKind returns the sorted names of the labels of the repository of the given kind, e.g. area
+func labelsOfKind(kind string, repoLabels map[string]string) []string {
+	var answer []string
+	for lower, name := range repoLabels {
+		if strings.HasPrefix(lower, kind+"/") {
+			answer = append(answer, name)
+		}
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// listLabels formats the names of labels, up to maxListedLabels of them
+func listLabels(names []string) string {
+	var quoted []string
+	for i, name := range names {
+		if i == maxListedLabels {
+			return strings.Join(quoted, ", ") + fmt.Sprintf(" and %d more", len(names)-maxListedLabels)
+		}
+		quoted = append(quoted, "`"+name+"`")
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// availableLabels returns the response listing the labels of the repository of the given kind
+func availableLabels(kind string, repoLabels map[string]string) string {
+	names := labelsOfKind(kind, repoLabels)
+	if len(names) == 0 {
+		return fmt.Sprintf("This repository has no `%s` labels.", kind)
+	}
+	return fmt.Sprintf("The available `%s` labels are: %s.", kind, listLabels(names))
+}
+
+// suggest returns the label of the repository the user most likely meant instead of the given label which does not
+// exist, the closest label of the same kind or a label of another kind with the same value, if any
+func suggest(label string, repoLabels map[string]string) string {
+	parts := strings.SplitN(label, "/", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	kind, value := parts[0], parts[1]
+	best := ""
+	bestDistance := len(value)/3 + 1
+	if bestDistance < 2 {
+		bestDistance = 2
+	}
+	var sameValue []string
+	for lower, name := range repoLabels {
+		p := strings.SplitN(lower, "/", 2)
+		if len(p) != 2 {
+			continue
+		}
+		if p[0] != kind {
+			if p[1] == value {
+				sameValue = append(sameValue, name)
+			}
+			continue
+		}
+		if d := distance(value, p[1]); d < bestDistance || (d == bestDistance && best != "" && name < best) {
+			best = name
+			bestDistance = d
+		}
+	}
+	if best != "" {
+		return best
+	}
+	if len(sameValue) > 0 {
+		sort.Strings(sameValue)
+		return sameValue[0]
+	}
+	return ""
+}
+
+// distance returns the Levenshtein distance between two strings
+func distance(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(min(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
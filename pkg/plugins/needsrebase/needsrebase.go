@@ -0,0 +1,140 @@
+// Package needsrebase contains a plugin which labels the pull requests which can no longer be merged because they
+// conflict with their base branch. The pull requests are checked when they change and whenever their base branch is
+// pushed to, they are labelled and their author is told how to resolve the conflicts once, and the label and the
+// comment are removed once the conflicts are resolved.
+package needsrebase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pluginName = "needs-rebase"
+	// pageSize is the number of open pull requests listed per page
+	pageSize = 100
+)
+
+// marker identifies the comment telling the author to resolve the conflicts
+var marker = botcomment.Marker(pluginName)
+
+type scmProviderClient interface {
+	botcomment.ListingClient
+	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
+	ListAllPullRequestsForFullNameRepo(fullName string, opts scm.PullRequestListOptions) ([]*scm.PullRequest, error)
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		pluginName,
+		plugins.Plugin{
+			Description: "The needs-rebase plugin adds the '" + labels.NeedsRebase + "' label to the pull requests which conflict with their base branch, " +
+				"with a comment explaining how to resolve the conflicts, and removes them once the conflicts are resolved. " +
+				"The pull requests are checked when they are opened or updated and when their base branch is pushed to.",
+			PullRequestHandler: handlePullRequest,
+			PushEventHandler:   handlePush,
+		},
+	)
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	if pre.Action != scm.ActionOpen && pre.Action != scm.ActionReopen && pre.Action != scm.ActionSync {
+		return nil
+	}
+	org := pre.Repo.Namespace
+	repo := pre.Repo.Name
+	// the mergeability is not always computed yet when the webhook is sent
+	pr, err := pc.SCMProviderClient.GetPullRequest(org, repo, pre.PullRequest.Number)
+	if err != nil {
+		return fmt.Errorf("failed to get %s/%s PR #%d: %v", org, repo, pre.PullRequest.Number, err)
+	}
+	return check(pc.SCMProviderClient, pc.Logger, org, repo, pr)
+}
+
+func handlePush(pc plugins.Agent, pe scm.PushHook) error {
+	if pe.Deleted || !strings.HasPrefix(pe.Ref, "refs/heads/") {
+		return nil
+	}
+	return checkBranch(pc.SCMProviderClient, pc.Logger, pe.Repo.Namespace, pe.Repo.Name, scmprovider.PushHookBranch(&pe))
+}
+
+// checkBranch checks the open pull requests targeting the branch which was pushed to, carrying on with the next
+// pull requests on errors
+func checkBranch(spc scmProviderClient, log *logrus.Entry, org, repo, branch string) error {
+	prs, err := spc.ListAllPullRequestsForFullNameRepo(org+"/"+repo, scm.PullRequestListOptions{Page: 1, Size: pageSize, Open: true})
+	if err != nil {
+		return fmt.Errorf("failed to list the open pull requests of %s/%s: %v", org, repo, err)
+	}
+	var errs []string
+	for _, listed := range prs {
+		if listed.Closed || listed.Merged || listed.Base.Ref != branch {
+			continue
+		}
+		// the listed pull requests do not include their mergeability
+		pr, err := spc.GetPullRequest(org, repo, listed.Number)
+		if err == nil {
+			err = check(spc, log, org, repo, pr)
+		}
+		if err != nil {
+			log.WithError(err).Warnf("failed to check the conflicts of %s/%s PR #%d", org, repo, listed.Number)
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to check the conflicts of %d pull requests of %s/%s: %s", len(errs), org, repo, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// check labels the pull request and comments on it once if it conflicts with its base branch, and removes the label
+// and the comment once it is mergeable again. Nothing is done while the mergeability is unknown, the pull request is
+// checked again on the next event.
+func check(spc scmProviderClient, log *logrus.Entry, org, repo string, pr *scm.PullRequest) error {
+	if pr.Closed || pr.Merged || pr.MergeableState == scm.MergeableStateUnknown {
+		return nil
+	}
+	issueLabels, err := spc.GetIssueLabels(org, repo, pr.Number, true)
+	if err != nil {
+		return fmt.Errorf("failed to get the labels of %s/%s PR #%d: %v", org, repo, pr.Number, err)
+	}
+	hasLabel := false
+	for _, l := range issueLabels {
+		if l.Name == labels.NeedsRebase {
+			hasLabel = true
+			break
+		}
+	}
+	log = log.WithFields(logrus.Fields{"org": org, "repo": repo, "pr": pr.Number})
+	switch {
+	case pr.MergeableState == scm.MergeableStateConflicting && !hasLabel:
+		log.Infof("Adding the %s label", labels.NeedsRebase)
+		if err := spc.AddLabel(org, repo, pr.Number, labels.NeedsRebase, true); err != nil {
+			return fmt.Errorf("failed to add the %s label: %v", labels.NeedsRebase, err)
+		}
+		return botcomment.Upsert(spc, org, repo, pr.Number, true, marker, conflictComment(pr))
+	case pr.MergeableState == scm.MergeableStateMergeable && hasLabel:
+		log.Infof("Removing the %s label", labels.NeedsRebase)
+		if err := spc.RemoveLabel(org, repo, pr.Number, labels.NeedsRebase, true); err != nil {
+			return fmt.Errorf("failed to remove the %s label: %v", labels.NeedsRebase, err)
+		}
+		return botcomment.Remove(spc, org, repo, pr.Number, true, marker)
+	}
+	return nil
+}
+
+func conflictComment(pr *scm.PullRequest) string {
+	return fmt.Sprintf("@%s: this pull request has conflicts with the `%s` branch and cannot be merged.\n\n"+
+		"Please rebase it on the latest `%s` branch, resolve the conflicts and push it again. "+
+		"The `%s` label is removed once the conflicts are resolved.",
+		pr.Author.Login, pr.Base.Ref, pr.Base.Ref, labels.NeedsRebase)
+}
@@ -0,0 +1,151 @@
+package needsrebase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type fakeClient struct {
+	prs      map[int]*scm.PullRequest
+	comments map[int][]*scm.Comment
+	labels   map[int]sets.String
+	nextID   int
+}
+
+func newFakeClient(prs ...*scm.PullRequest) *fakeClient {
+	f := &fakeClient{prs: map[int]*scm.PullRequest{}, comments: map[int][]*scm.Comment{}, labels: map[int]sets.String{}}
+	for _, pr := range prs {
+		f.prs[pr.Number] = pr
+		f.labels[pr.Number] = sets.NewString()
+	}
+	return f
+}
+
+func (f *fakeClient) BotName() (string, error) {
+	return "bot", nil
+}
+
+func (f *fakeClient) CreateComment(_, _ string, number int, _ bool, comment string) error {
+	f.nextID++
+	f.comments[number] = append(f.comments[number], &scm.Comment{ID: f.nextID, Author: scm.User{Login: "bot"}, Body: comment})
+	return nil
+}
+
+func (f *fakeClient) EditComment(_, _ string, number, id int, comment string, _ bool) error {
+	for _, c := range f.comments[number] {
+		if c.ID == id {
+			c.Body = comment
+			return nil
+		}
+	}
+	return errors.New("no such comment")
+}
+
+func (f *fakeClient) DeleteComment(_, _ string, number, id int, _ bool) error {
+	for i, c := range f.comments[number] {
+		if c.ID == id {
+			f.comments[number] = append(f.comments[number][:i], f.comments[number][i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("no such comment")
+}
+
+func (f *fakeClient) ListIssueComments(_, _ string, number int) ([]*scm.Comment, error) {
+	return f.comments[number], nil
+}
+
+func (f *fakeClient) ListPullRequestComments(_, _ string, number int) ([]*scm.Comment, error) {
+	return f.comments[number], nil
+}
+
+func (f *fakeClient) GetPullRequest(_, _ string, number int) (*scm.PullRequest, error) {
+	pr, ok := f.prs[number]
+	if !ok {
+		return nil, errors.New("no such pull request")
+	}
+	return pr, nil
+}
+
+func (f *fakeClient) ListAllPullRequestsForFullNameRepo(string, scm.PullRequestListOptions) ([]*scm.PullRequest, error) {
+	var prs []*scm.PullRequest
+	for i := 1; i <= len(f.prs); i++ {
+		// the listed pull requests do not include their mergeability
+		listed := *f.prs[i]
+		listed.MergeableState = scm.MergeableStateUnknown
+		prs = append(prs, &listed)
+	}
+	return prs, nil
+}
+
+func (f *fakeClient) GetIssueLabels(_, _ string, number int, _ bool) ([]*scm.Label, error) {
+	var issueLabels []*scm.Label
+	for _, l := range f.labels[number].List() {
+		issueLabels = append(issueLabels, &scm.Label{Name: l})
+	}
+	return issueLabels, nil
+}
+
+func (f *fakeClient) AddLabel(_, _ string, number int, label string, _ bool) error {
+	f.labels[number].Insert(label)
+	return nil
+}
+
+func (f *fakeClient) RemoveLabel(_, _ string, number int, label string, _ bool) error {
+	f.labels[number].Delete(label)
+	return nil
+}
+
+func pr(number int, base string, state scm.MergeableState) *scm.PullRequest {
+	return &scm.PullRequest{Number: number, Author: scm.User{Login: "author"}, Base: scm.PullRequestBranch{Ref: base}, MergeableState: state}
+}
+
+func TestCheck(t *testing.T) {
+	log := logrus.WithField("plugin", pluginName)
+	conflicting := pr(1, "master", scm.MergeableStateConflicting)
+	spc := newFakeClient(conflicting)
+
+	require.NoError(t, check(spc, log, "org", "repo", conflicting))
+	assert.True(t, spc.labels[1].Has(labels.NeedsRebase))
+	require.Len(t, spc.comments[1], 1)
+	assert.Contains(t, spc.comments[1][0].Body, "@author: this pull request has conflicts with the `master` branch")
+
+	// the author is only told once
+	require.NoError(t, check(spc, log, "org", "repo", conflicting))
+	assert.Len(t, spc.comments[1], 1)
+
+	// nothing changes while the mergeability is unknown
+	conflicting.MergeableState = scm.MergeableStateUnknown
+	require.NoError(t, check(spc, log, "org", "repo", conflicting))
+	assert.True(t, spc.labels[1].Has(labels.NeedsRebase))
+	assert.Len(t, spc.comments[1], 1)
+
+	conflicting.MergeableState = scm.MergeableStateMergeable
+	require.NoError(t, check(spc, log, "org", "repo", conflicting))
+	assert.False(t, spc.labels[1].Has(labels.NeedsRebase))
+	assert.Empty(t, spc.comments[1])
+}
+
+func TestCheckBranch(t *testing.T) {
+	spc := newFakeClient(
+		pr(1, "master", scm.MergeableStateConflicting),
+		pr(2, "release", scm.MergeableStateConflicting),
+		pr(3, "master", scm.MergeableStateMergeable),
+		pr(4, "master", scm.MergeableStateMergeable),
+	)
+	spc.labels[4].Insert(labels.NeedsRebase)
+
+	require.NoError(t, checkBranch(spc, logrus.WithField("plugin", pluginName), "org", "repo", "master"))
+	assert.True(t, spc.labels[1].Has(labels.NeedsRebase))
+	assert.Len(t, spc.comments[1], 1)
+	assert.False(t, spc.labels[2].Has(labels.NeedsRebase), "the pull requests targeting other branches are not checked")
+	assert.False(t, spc.labels[3].Has(labels.NeedsRebase))
+	assert.False(t, spc.labels[4].Has(labels.NeedsRebase))
+}
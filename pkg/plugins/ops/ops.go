@@ -0,0 +1,180 @@
+// Package ops contains a plugin which runs the ops jobs of the repositories, e.g. release cuts or environment
+// promotions, when a user with write access to the repository comments their command on an issue or pull request,
+// e.g. `/release --version=1.2.0`.
+// The jobs run on the head of a branch rather than on the commits of a pull request, the arguments of the command
+// being passed to them as parameters.
+package ops
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+const pluginName = "ops"
+
+// commandRe matches a line of a comment holding a command, capturing its name and its arguments
+var commandRe = regexp.MustCompile(`(?i)^/(?:lh-)?([\w-]+)(?:[ \t]+(.*?))?\s*$`)
+
+// writeRoles are the permissions on the repository needed to run an ops job, as they act on production rather than
+// on a pull request
+var writeRoles = []string{scmprovider.RoleAdmin, "maintain", "write"}
+
+type scmProviderClient interface {
+	BotName() (string, error)
+	HasPermission(org, repo, user string, roles ...string) (bool, error)
+	GetRef(org, repo, ref string) (string, error)
+	GetRepositoryByFullName(fullName string) (*scm.Repository, error)
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	QuoteAuthorForComment(string) string
+}
+
+type launcher interface {
+	Launch(*v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error)
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		pluginName,
+		plugins.Plugin{
+			Description: "The ops plugin runs the ops jobs of the repository, e.g. release cuts or environment promotions, when a user with write access to the repository comments their command on an issue or pull request. " +
+				"The arguments of the command, e.g. `/release --version=1.2.0`, are passed to the job as parameters.",
+			ConfigHelpProvider:    configHelp,
+			GenericCommentHandler: handleGenericComment,
+		},
+	)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	return map[string]string{
+		"": "The commands are the ones of the `ops` jobs of the repository, only the users with write access to the repository can use them.",
+	}, nil
+}
+
+func handleGenericComment(pc plugins.Agent, ce scmprovider.GenericCommentEvent) error {
+	if ce.Action != scm.ActionCreate {
+		return nil
+	}
+	jobs := pc.Config.GetOps(ce.Repo)
+	if len(jobs) == 0 {
+		return nil
+	}
+	return handle(pc.SCMProviderClient, pc.LauncherClient, pc.Logger, jobs, &ce)
+}
+
+// opsCommand is a command of the comment running an ops job
+type opsCommand struct {
+	job  job.Ops
+	args string
+}
+
+func handle(spc scmProviderClient, lc launcher, log *logrus.Entry, jobs []job.Ops, ce *scmprovider.GenericCommentEvent) error {
+	commands := matchCommands(ce.Body, jobs)
+	if len(commands) == 0 {
+		return nil
+	}
+	org, repo := ce.Repo.Namespace, ce.Repo.Name
+	author := ce.Author.Login
+	botName, err := spc.BotName()
+	if err != nil {
+		return err
+	}
+	if author == botName {
+		return nil
+	}
+	respond := func(resp string) error {
+		log.Infof("Commenting \"%s\".", resp)
+		return spc.CreateComment(org, repo, ce.Number, ce.IsPR, plugins.FormatResponseRaw(ce.Body, ce.Link, spc.QuoteAuthorForComment(author), resp))
+	}
+
+	allowed, err := spc.HasPermission(org, repo, author, writeRoles...)
+	if err != nil {
+		return fmt.Errorf("error checking the permission of %s: %v", author, err)
+	}
+	if !allowed {
+		return respond(fmt.Sprintf("only the users with write access to the repository can run `/%s`.", commands[0].job.Command))
+	}
+
+	var lines []string
+	for _, c := range commands {
+		lj, err := launch(spc, lc, log, c, ce)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("- `/%s`: %v", c.job.Command, err))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("- `/%s`: started `%s` on `%s` (%s)", c.job.Command, lj.Spec.Job, lj.Spec.Refs.BaseRef, lj.Spec.Refs.BaseSHA))
+	}
+	return respond("\n\n" + strings.Join(lines, "\n"))
+}
+
+// matchCommands returns the commands of the comment running the ops jobs, in the order of the comment
+func matchCommands(body string, jobs []job.Ops) []opsCommand {
+	var commands []opsCommand
+	for _, line := range strings.Split(body, "\n") {
+		m := commandRe.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		for _, j := range jobs {
+			if strings.EqualFold(j.Command, m[1]) {
+				commands = append(commands, opsCommand{job: j, args: m[2]})
+				break
+			}
+		}
+	}
+	return commands
+}
+
+// launch creates the LighthouseJob of the command on the head of the branch of the job, the default branch of the
+// repository if it has none
+func launch(spc scmProviderClient, lc launcher, log *logrus.Entry, c opsCommand, ce *scmprovider.GenericCommentEvent) (*v1alpha1.LighthouseJob, error) {
+	org, repo := ce.Repo.Namespace, ce.Repo.Name
+	parameters, err := jobutil.OpsParameters(c.args, c.job)
+	if err != nil {
+		return nil, err
+	}
+	branch := c.job.Branch
+	if branch == "" {
+		branch = ce.Repo.Branch
+	}
+	if branch == "" {
+		r, err := spc.GetRepositoryByFullName(scm.Join(org, repo))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the default branch: %v", err)
+		}
+		branch = r.Branch
+	}
+	sha, err := spc.GetRef(org, repo, "heads/"+branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the head of branch %s: %v", branch, err)
+	}
+	refs := v1alpha1.Refs{
+		Org:      org,
+		Repo:     repo,
+		RepoLink: ce.Repo.Link,
+		BaseRef:  branch,
+		BaseSHA:  sha,
+		CloneURI: ce.Repo.Clone,
+	}
+	labels := make(map[string]string)
+	for k, v := range c.job.Labels {
+		labels[k] = v
+	}
+	labels[scmprovider.EventGUID] = ce.GUID
+	lj := jobutil.NewLighthouseJob(jobutil.OpsSpec(c.job, refs), labels, c.job.Annotations)
+	lj.Spec.Parameters = parameters
+	log.WithFields(jobutil.LighthouseJobFields(&lj)).Info("Creating a new LighthouseJob.")
+	if _, err := lc.Launch(&lj); err != nil {
+		log.WithError(err).Error("Failed to create LighthouseJob.")
+		return nil, fmt.Errorf("failed to create the job")
+	}
+	return &lj, nil
+}
@@ -0,0 +1,174 @@
+package ops
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	comments []string
+}
+
+func (f *fakeClient) BotName() (string, error) {
+	return "bot", nil
+}
+
+// permissions are the permissions of the users on the repository, the collaborators having write access
+var permissions = map[string]string{"collab": "write", "maintainer": "admin", "reader": "read"}
+
+func (f *fakeClient) HasPermission(_, _, user string, roles ...string) (bool, error) {
+	for _, role := range roles {
+		if permissions[user] == role {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeClient) GetRef(_, _, ref string) (string, error) {
+	return map[string]string{"heads/main": "abc", "heads/release": "def"}[ref], nil
+}
+
+func (f *fakeClient) GetRepositoryByFullName(string) (*scm.Repository, error) {
+	return &scm.Repository{Branch: "main"}, nil
+}
+
+func (f *fakeClient) CreateComment(_, _ string, _ int, _ bool, comment string) error {
+	f.comments = append(f.comments, comment)
+	return nil
+}
+
+func (f *fakeClient) QuoteAuthorForComment(author string) string {
+	return author
+}
+
+type fakeLauncher struct {
+	launched []v1alpha1.LighthouseJob
+}
+
+func (f *fakeLauncher) Launch(lj *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
+	f.launched = append(f.launched, *lj)
+	return lj, nil
+}
+
+func TestHandle(t *testing.T) {
+	jobs := []job.Ops{
+		{
+			Base:              job.Base{Name: "release", Agent: job.TektonPipelineAgent},
+			Command:           "release",
+			CommandParameters: []job.CommandParameter{{Name: "version", Pattern: `\d+\.\d+\.\d+`}},
+		},
+		{
+			Base:              job.Base{Name: "promote-env", Agent: job.TektonPipelineAgent},
+			Command:           "promote",
+			Branch:            "release",
+			CommandParameters: []job.CommandParameter{{Name: "environment"}},
+		},
+	}
+	tests := []struct {
+		name       string
+		body       string
+		author     string
+		expected   []string
+		branches   []string
+		parameters []map[string]string
+		comment    string
+	}{
+		{
+			name:   "other commands are ignored",
+			body:   "/test all\n/lgtm",
+			author: "collab",
+		},
+		{
+			name:       "release on the default branch",
+			body:       "/release --version=1.2.0",
+			author:     "collab",
+			expected:   []string{"release"},
+			branches:   []string{"main"},
+			parameters: []map[string]string{{"version": "1.2.0"}},
+			comment:    "started `release` on `main` (abc)",
+		},
+		{
+			name:       "several commands",
+			body:       "Shipping it\n/release --version=1.2.0\n/lh-promote --environment=production",
+			author:     "collab",
+			expected:   []string{"release", "promote-env"},
+			branches:   []string{"main", "release"},
+			parameters: []map[string]string{{"version": "1.2.0"}, {"environment": "production"}},
+			comment:    "started `promote-env` on `release` (def)",
+		},
+		{
+			name:    "invalid parameter",
+			body:    "/release --version=latest",
+			author:  "collab",
+			comment: "invalid value `latest` for parameter `version` of job release",
+		},
+		{
+			name:       "admin",
+			body:       "/release --version=1.2.0",
+			author:     "maintainer",
+			expected:   []string{"release"},
+			branches:   []string{"main"},
+			parameters: []map[string]string{{"version": "1.2.0"}},
+			comment:    "started `release` on `main` (abc)",
+		},
+		{
+			name:    "read-only collaborator",
+			body:    "/promote --environment=production",
+			author:  "reader",
+			comment: "only the users with write access to the repository can run `/promote`.",
+		},
+		{
+			name:    "untrusted user",
+			body:    "/release --version=1.2.0",
+			author:  "random",
+			comment: "only the users with write access to the repository can run `/release`.",
+		},
+		{
+			name:   "bot comments are ignored",
+			body:   "/release --version=1.2.0",
+			author: "bot",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spc := &fakeClient{}
+			lc := &fakeLauncher{}
+			ce := &scmprovider.GenericCommentEvent{
+				Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+				Number: 5,
+				Body:   tc.body,
+				Author: scm.User{Login: tc.author},
+				Action: scm.ActionCreate,
+			}
+			err := handle(spc, lc, logrus.WithField("plugin", pluginName), jobs, ce)
+			require.NoError(t, err)
+
+			var names, branches []string
+			var parameters []map[string]string
+			for _, lj := range lc.launched {
+				assert.Equal(t, job.OpsJob, lj.Spec.Type)
+				assert.Empty(t, lj.Spec.Refs.Pulls)
+				names = append(names, lj.Spec.Job)
+				branches = append(branches, lj.Spec.Refs.BaseRef)
+				parameters = append(parameters, lj.Spec.Parameters)
+			}
+			assert.Equal(t, tc.expected, names)
+			assert.Equal(t, tc.branches, branches)
+			assert.Equal(t, tc.parameters, parameters)
+			if tc.comment == "" {
+				assert.Empty(t, spc.comments)
+				return
+			}
+			require.Len(t, spc.comments, 1)
+			assert.Contains(t, spc.comments[0], tc.comment)
+		})
+	}
+}
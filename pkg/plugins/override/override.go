@@ -27,7 +27,9 @@ import (
 	lighthouseclient "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/typed/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
 	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/notification"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/jenkins-x/lighthouse/pkg/util"
@@ -86,7 +88,9 @@ var (
 			WhoCanUse:   "Repo administrators",
 			Action: plugins.
 				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
-					return handle(match.Arg, pc.SCMProviderClient, pc.LighthouseClient, pc.Config.JobConfig, pc.Logger, e)
+					return handle(match.Arg, pc.SCMProviderClient, pc.LighthouseClient, pc.Config.JobConfig, pc.Logger, e, func(contexts []string) {
+						notifyOverride(pc.Notifier, pc.Config.ChatNotifications, pc.Logger, e, contexts)
+					})
 				}).
 				When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
 		}},
@@ -125,7 +129,26 @@ func formatList(list []string) string {
 	return strings.Join(lines, "\n")
 }
 
-func handle(context string, spc scmProviderClient, lhClient lighthouseclient.LighthouseJobInterface, jc config.JobConfig, log *logrus.Entry, e scmprovider.GenericCommentEvent) error {
+// notifyOverride posts the overridden contexts to the chat notifications
+func notifyOverride(notifier *notification.Notifier, notifications []lighthouse.ChatNotification, log *logrus.Entry, e scmprovider.GenericCommentEvent, contexts []string) {
+	if len(notifications) == 0 {
+		return
+	}
+	event := &notification.Event{
+		Kind:     lighthouse.ChatEventOverride,
+		Org:      e.Repo.Namespace,
+		Repo:     e.Repo.Name,
+		Number:   e.Number,
+		Author:   e.Author.Login,
+		URL:      e.Link,
+		Contexts: contexts,
+	}
+	if err := notifier.Notify(notifications, event); err != nil {
+		log.WithError(err).Warn("Failed to post the override to the chat notifications")
+	}
+}
+
+func handle(context string, spc scmProviderClient, lhClient lighthouseclient.LighthouseJobInterface, jc config.JobConfig, log *logrus.Entry, e scmprovider.GenericCommentEvent, notify func(contexts []string)) error {
 	org := e.Repo.Namespace
 	repo := e.Repo.Name
 	number := e.Number
@@ -190,6 +213,9 @@ Only the following contexts were expected:
 		if err != nil {
 			log.WithError(err).Warn("Failed to create the comment")
 		}
+		if notify != nil {
+			notify(done.List())
+		}
 	}()
 
 	for _, status := range statuses {
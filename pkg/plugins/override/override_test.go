@@ -21,11 +21,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/driver/fake"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	lhfake "github.com/jenkins-x/lighthouse/pkg/fake"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/sirupsen/logrus"
@@ -52,17 +54,23 @@ func issueLabels(labels ...string) []string {
 func TestAuthorized(t *testing.T) {
 	cases := []struct {
 		name     string
+		repo     string
 		user     string
 		expected bool
 	}{
 		{
 			name: "fail closed",
-			user: "fail",
+			repo: "missing-repo",
+			user: adminUser,
 		},
 		{
 			name: "reject random",
 			user: "random",
 		},
+		{
+			name: "reject writer",
+			user: "writer",
+		},
 		{
 			name:     "accept admin",
 			user:     adminUser,
@@ -70,15 +78,21 @@ func TestAuthorized(t *testing.T) {
 		},
 	}
 
+	server := lhfake.NewServer("bot")
+	defer server.Close()
+	server.AddRepository(fakeOrg, fakeRepo, "master", fakeBaseSHA)
+	require.NoError(t, server.SetPermission(fakeOrg+"/"+fakeRepo, adminUser, "admin"))
+	require.NoError(t, server.SetPermission(fakeOrg+"/"+fakeRepo, "writer", "write"))
+	spc := scmprovider.ToClient(server.Client(), "bot")
+
 	log := logrus.WithField("plugin", pluginName)
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			fakeScmClient, fc := fake.NewDefault()
-			fakeClient := scmprovider.ToTestClient(fakeScmClient)
-			fc.UserPermissions[fakeOrg+"/"+fakeRepo] = map[string]string{
-				adminUser: "admin",
+			repo := tc.repo
+			if repo == "" {
+				repo = fakeRepo
 			}
-			if actual := authorized(&fakeClient.Client, log, fakeOrg, fakeRepo, tc.user); actual != tc.expected {
+			if actual := authorized(spc, log, fakeOrg, repo, tc.user); actual != tc.expected {
 				t.Errorf("actual %t != expected %t", actual, tc.expected)
 			}
 		})
@@ -0,0 +1,162 @@
+// Package pathlabels contains a plugin which labels the pull requests according to the paths of the files they
+// change, e.g. `area/docs` for the pull requests changing the files matching `docs/.*`. The labels map the regular
+// expressions of the paths to the labels, in the configuration of the plugin or in the `.lighthouse/labels.yaml`
+// file of the repository.
+package pathlabels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	pluginName = "path-labels"
+
+	// RepoFile is the file of a repository holding its path labels
+	RepoFile = ".lighthouse/labels.yaml"
+)
+
+// RepoConfig is the content of the RepoFile of a repository
+type RepoConfig struct {
+	// Labels map the files changed by the pull requests to the labels applied to them.
+	Labels []plugins.PathLabel `json:"labels,omitempty"`
+}
+
+type scmProviderClient interface {
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	GetFile(org, repo, filepath, commit string) ([]byte, error)
+	GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error)
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		pluginName,
+		plugins.Plugin{
+			Description:        "The path-labels plugin applies labels to the pull requests according to the paths of the files they change, e.g. 'area/docs' to those changing the files matching 'docs/.*', and removes them once the pull requests no longer change such files. The labels are configured for the plugin or in the '" + RepoFile + "' file of the repository.",
+			ConfigHelpProvider: configHelp,
+			PullRequestHandler: handlePullRequest,
+		},
+	)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	labelsConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		parts := strings.Split(repo, "/")
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
+		}
+		name := ""
+		if len(parts) == 2 {
+			name = parts[1]
+		}
+		pl := config.PathLabelsFor(parts[0], name)
+		var mappings []string
+		for _, l := range pl.Labels {
+			mappings = append(mappings, fmt.Sprintf("<li>%s: %s</li>", l.Path, l.Label))
+		}
+		help := "No path is labelled by the configuration."
+		if len(mappings) > 0 {
+			help = fmt.Sprintf("The pull requests changing the following paths are labelled:<ul>%s</ul>", strings.Join(mappings, ""))
+		}
+		if !pl.IgnoreRepoFile {
+			help += fmt.Sprintf(" The labels of the %s file of the repository are also applied.", RepoFile)
+		}
+		labelsConfig[repo] = help
+	}
+	return labelsConfig, nil
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	pl := pc.PluginConfig.PathLabelsFor(pre.Repo.Namespace, pre.Repo.Name)
+	return handle(pc.SCMProviderClient, pc.Logger, pl, &pre)
+}
+
+func handle(spc scmProviderClient, log *logrus.Entry, pl *plugins.PathLabels, pre *scm.PullRequestHook) error {
+	if pre.Action != scm.ActionSync &&
+		pre.Action != scm.ActionOpen &&
+		pre.Action != scm.ActionReopen &&
+		pre.Action != scm.ActionEdited {
+		return nil
+	}
+
+	org := pre.Repo.Namespace
+	repo := pre.Repo.Name
+	number := pre.PullRequest.Number
+	mappings := pl.Labels
+	if !pl.IgnoreRepoFile {
+		// the labels of the base branch are used, so that a pull request cannot change its own labels
+		repoLabels, err := loadRepoLabels(spc, org, repo, pre.PullRequest.Base.Ref)
+		if err != nil {
+			log.WithError(err).Warnf("Failed to load the %s file", RepoFile)
+		}
+		mappings = append(append([]plugins.PathLabel{}, mappings...), repoLabels...)
+	}
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	changes, err := spc.GetPullRequestChanges(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to get the changes of %s/%s PR #%d: %v", org, repo, number, err)
+	}
+	managed := sets.NewString()
+	wanted := sets.NewString()
+	for _, m := range mappings {
+		managed.Insert(m.Label)
+		for _, change := range changes {
+			if m.PathRe.MatchString(change.Path) || (change.PreviousPath != "" && m.PathRe.MatchString(change.PreviousPath)) {
+				wanted.Insert(m.Label)
+				break
+			}
+		}
+	}
+
+	issueLabels, err := spc.GetIssueLabels(org, repo, number, true)
+	if err != nil {
+		return fmt.Errorf("failed to get the labels of %s/%s PR #%d: %v", org, repo, number, err)
+	}
+	existing := sets.NewString()
+	for _, l := range issueLabels {
+		existing.Insert(l.Name)
+	}
+	for _, label := range wanted.Difference(existing).List() {
+		log.Infof("Adding the %q label", label)
+		if err := spc.AddLabel(org, repo, number, label, true); err != nil {
+			return fmt.Errorf("failed to add the %s label to %s/%s PR #%d: %v", label, org, repo, number, err)
+		}
+	}
+	for _, label := range managed.Intersection(existing).Difference(wanted).List() {
+		log.Infof("Removing the %q label", label)
+		if err := spc.RemoveLabel(org, repo, number, label, true); err != nil {
+			return fmt.Errorf("failed to remove the %s label from %s/%s PR #%d: %v", label, org, repo, number, err)
+		}
+	}
+	return nil
+}
+
+// loadRepoLabels returns the labels of the RepoFile of a repository, none if it has no such file
+func loadRepoLabels(spc scmProviderClient, org, repo, ref string) ([]plugins.PathLabel, error) {
+	data, err := spc.GetFile(org, repo, RepoFile, ref)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	config := &RepoConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", RepoFile, err)
+	}
+	for i := range config.Labels {
+		if err := config.Labels[i].Compile(); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", RepoFile, err)
+		}
+	}
+	return config.Labels, nil
+}
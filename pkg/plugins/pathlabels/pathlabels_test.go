@@ -0,0 +1,134 @@
+package pathlabels
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type fakeClient struct {
+	labels  sets.String
+	files   map[string][]byte
+	changes []*scm.Change
+}
+
+func (f *fakeClient) AddLabel(_, _ string, _ int, label string, _ bool) error {
+	f.labels.Insert(label)
+	return nil
+}
+
+func (f *fakeClient) RemoveLabel(_, _ string, _ int, label string, _ bool) error {
+	f.labels.Delete(label)
+	return nil
+}
+
+func (f *fakeClient) GetIssueLabels(string, string, int, bool) ([]*scm.Label, error) {
+	var labels []*scm.Label
+	for _, l := range f.labels.List() {
+		labels = append(labels, &scm.Label{Name: l})
+	}
+	return labels, nil
+}
+
+func (f *fakeClient) GetFile(_, _, path, ref string) ([]byte, error) {
+	return f.files[ref+":"+path], nil
+}
+
+func (f *fakeClient) GetPullRequestChanges(string, string, int) ([]*scm.Change, error) {
+	return f.changes, nil
+}
+
+func TestHandle(t *testing.T) {
+	pl := &plugins.PathLabels{
+		Labels: []plugins.PathLabel{
+			{Path: `docs/.*`, Label: "area/docs"},
+			{Path: `.*\.go`, Label: "area/code"},
+		},
+	}
+	for i := range pl.Labels {
+		require.NoError(t, pl.Labels[i].Compile())
+	}
+	spc := &fakeClient{
+		labels: sets.NewString("lgtm"),
+		files: map[string][]byte{
+			"master:" + RepoFile: []byte("labels:\n- path: charts/.*\n  label: area/charts\n"),
+		},
+		changes: []*scm.Change{
+			{Path: "docs/index.md"},
+			{Path: "pkg/main.go"},
+		},
+	}
+	pre := &scm.PullRequestHook{
+		Action: scm.ActionOpen,
+		Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+		PullRequest: scm.PullRequest{
+			Number: 1,
+			Base:   scm.PullRequestBranch{Ref: "master"},
+		},
+	}
+	l := logrus.WithField("plugin", pluginName)
+
+	require.NoError(t, handle(spc, l, pl, pre))
+	assert.Equal(t, []string{"area/code", "area/docs", "lgtm"}, spc.labels.List())
+
+	// the files are changed by a new commit
+	pre.Action = scm.ActionSync
+	spc.changes = []*scm.Change{
+		{Path: "docs/index.md"},
+		{Path: "charts/lighthouse/values.yaml"},
+	}
+	require.NoError(t, handle(spc, l, pl, pre))
+	assert.Equal(t, []string{"area/charts", "area/docs", "lgtm"}, spc.labels.List())
+
+	// the file of the repository is ignored
+	pl.IgnoreRepoFile = true
+	spc.changes = []*scm.Change{
+		{Path: "README.md", PreviousPath: "docs/README.md", Renamed: true},
+	}
+	require.NoError(t, handle(spc, l, pl, pre))
+	assert.Equal(t, []string{"area/charts", "area/docs", "lgtm"}, spc.labels.List())
+
+	// the paths are matched as a whole
+	spc.changes = []*scm.Change{
+		{Path: "mydocs/index.md"},
+	}
+	require.NoError(t, handle(spc, l, pl, pre))
+	assert.Equal(t, []string{"area/charts", "lgtm"}, spc.labels.List())
+
+	// other actions are ignored
+	pre.Action = scm.ActionClose
+	spc.changes = []*scm.Change{
+		{Path: "pkg/main.go"},
+	}
+	require.NoError(t, handle(spc, l, pl, pre))
+	assert.Equal(t, []string{"area/charts", "lgtm"}, spc.labels.List())
+}
+
+func TestLoadRepoLabels(t *testing.T) {
+	spc := &fakeClient{
+		files: map[string][]byte{
+			"valid:" + RepoFile:   []byte("labels:\n- path: docs/.*\n  label: area/docs\n"),
+			"invalid:" + RepoFile: []byte("labels:\n- path: docs/(\n  label: area/docs\n"),
+			"nolabel:" + RepoFile: []byte("labels:\n- path: docs/.*\n"),
+		},
+	}
+	labels, err := loadRepoLabels(spc, "org", "repo", "valid")
+	require.NoError(t, err)
+	require.Len(t, labels, 1)
+	assert.Equal(t, "area/docs", labels[0].Label)
+	assert.True(t, labels[0].PathRe.MatchString("docs/index.md"))
+
+	labels, err = loadRepoLabels(spc, "org", "repo", "missing")
+	assert.NoError(t, err)
+	assert.Empty(t, labels)
+
+	_, err = loadRepoLabels(spc, "org", "repo", "invalid")
+	assert.Error(t, err)
+	_, err = loadRepoLabels(spc, "org", "repo", "nolabel")
+	assert.Error(t, err)
+}
@@ -18,6 +18,11 @@ type Plugin struct {
 	StatusEventHandler    StatusEventHandler
 	GenericCommentHandler GenericCommentHandler
 	Commands              []Command
+	// BestEffort marks the plugins whose calls to the SCM provider are delayed then shed first once its rate limit
+	// is nearly exhausted
+	BestEffort bool
+	// Fun marks the non-functional plugins, such as cat or yuks, which can be disabled all at once with FunPlugins
+	Fun bool
 }
 
 // InvokeCommandHandler calls InvokeHandler on all commands
@@ -30,6 +30,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/git"
 	"github.com/jenkins-x/lighthouse/pkg/launcher"
+	"github.com/jenkins-x/lighthouse/pkg/notification"
 	"github.com/jenkins-x/lighthouse/pkg/pluginhelp"
 	"github.com/jenkins-x/lighthouse/pkg/repoowners"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
@@ -47,6 +48,16 @@ func RegisterPlugin(name string, plugin Plugin) {
 	plugins[name] = plugin
 }
 
+// IsBestEffort returns true if the registered plugin with the given name is best-effort
+func IsBestEffort(name string) bool {
+	return plugins[name].BestEffort
+}
+
+// IsFun returns true if the registered plugin with the given name is a non-functional plugin
+func IsFun(name string) bool {
+	return plugins[name].Fun
+}
+
 // HelpProvider defines the function type that construct a pluginhelp.PluginHelp for enabled
 // plugins. It takes into account the plugins configuration and enabled repositories.
 type HelpProvider func(config *Configuration, enabledRepos []string) (*pluginhelp.PluginHelp, error)
@@ -54,8 +65,8 @@ type HelpProvider func(config *Configuration, enabledRepos []string) (*pluginhel
 // ConfigHelpProvider defines the function type that constructs help about a plugin configuration.
 type ConfigHelpProvider func(config *Configuration, enabledRepos []string) (map[string]string, error)
 
-// IssueHandler defines the function contract for a scm.Issue handler.
-type IssueHandler func(Agent, scm.Issue) error
+// IssueHandler defines the function contract for a scm.IssueHook handler.
+type IssueHandler func(Agent, scm.IssueHook) error
 
 // PullRequestHandler defines the function contract for a scm.PullRequest handler.
 type PullRequestHandler func(Agent, scm.PullRequestHook) error
@@ -94,9 +105,8 @@ type Agent struct {
 	KubernetesClient  kubernetes.Interface
 	LighthouseClient  lighthouseclient.LighthouseJobInterface
 	ServerURL         *url.URL
-	/*
-		SlackClient      *slack.Client
-	*/
+	// Notifier posts events to the chat notifications, it may be nil
+	Notifier *notification.Notifier
 
 	OwnersClient *repoowners.Client
 
@@ -124,14 +134,13 @@ func NewAgent(configAgent *config.Agent, pluginConfigAgent *ConfigAgent, clientA
 		LauncherClient:    clientAgent.LauncherClient,
 		LighthouseClient:  clientAgent.LighthouseClient,
 		ServerURL:         serverURL,
+		Notifier:          clientAgent.Notifier,
 
-		/*
-			SlackClient:   clientAgent.SlackClient,
-		*/
 		OwnersClient: repoowners.NewClient(
 			clientAgent.GitClient, scmClient,
 			prowConfig, pluginConfig.MDYAMLEnabled,
 			pluginConfig.SkipCollaborators,
+			pluginConfig.OwnersProvider,
 		),
 		Config:       prowConfig,
 		PluginConfig: pluginConfig,
@@ -166,9 +175,7 @@ type ClientAgent struct {
 	GitClient        git.Client
 	LauncherClient   launcher.PipelineLauncher
 	LighthouseClient lighthouseclient.LighthouseJobInterface
-
-	/*	SlackClient      *slack.Client
-	 */
+	Notifier         *notification.Notifier
 }
 
 // ConfigAgent contains the agent mutex and the Agent configuration.
@@ -205,8 +212,14 @@ func (pa *ConfigAgent) Load(path string) error {
 }
 
 // LoadYAMLConfig loads the configuration from the given data
-func (pa *ConfigAgent) LoadYAMLConfig(data []byte) (*Configuration, error) {
-	c := &Configuration{}
+func (pa *ConfigAgent) LoadYAMLConfig(data []byte) (c *Configuration, err error) {
+	// we never want reloading an invalid config to take down the lighthouse components
+	defer func() {
+		if r := recover(); r != nil {
+			c, err = nil, fmt.Errorf("panic loading plugins config: %v", r)
+		}
+	}()
+	c = &Configuration{}
 	if err := yaml.Unmarshal(data, c); err != nil {
 		return c, err
 	}
@@ -253,9 +266,7 @@ func (pa *ConfigAgent) getPlugins(owner, repo string) []string {
 		owners = append(owners, lowerOwner)
 	}
 	for _, o := range owners {
-		fullName := fmt.Sprintf("%s/%s", o, repo)
-		plugins = append(plugins, pa.configuration.Plugins[o]...)
-		plugins = append(plugins, pa.configuration.Plugins[fullName]...)
+		plugins = append(plugins, pa.configuration.PluginsFor(o, repo)...)
 	}
 	logrus.Infof("found plugins %s\n", strings.Join(plugins, ", "))
 	return plugins
@@ -266,8 +277,18 @@ func (pa *ConfigAgent) GetPlugins(owner, repo, provider string) map[string]Plugi
 	pa.mut.Lock()
 	defer pa.mut.Unlock()
 
+	return pluginsNamed(pa.getPlugins(owner, repo), provider)
+}
+
+// GetPluginsNamed returns a map of the given plugin names to plugins, such as the plugins a repository declares
+// outside of the plugins configuration.
+func (pa *ConfigAgent) GetPluginsNamed(names []string, provider string) map[string]Plugin {
+	return pluginsNamed(names, provider)
+}
+
+func pluginsNamed(names []string, provider string) map[string]Plugin {
 	hs := map[string]Plugin{}
-	for _, p := range pa.getPlugins(owner, repo) {
+	for _, p := range names {
 		if h, ok := plugins[p]; ok && !plugins[p].IsProviderExcluded(provider) {
 			hs[p] = h
 		}
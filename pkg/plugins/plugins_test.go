@@ -42,6 +42,11 @@ func TestHasSelfApproval(t *testing.T) {
 			cfg:      `{"require_self_approval": false}`,
 			expected: true,
 		},
+		{
+			name:     "reject approval when forbid_self_approval set",
+			cfg:      `{"require_self_approval": false, "forbid_self_approval": true}`,
+			expected: false,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -52,6 +52,8 @@ const (
 func createPlugin(h herd) plugins.Plugin {
 	return plugins.Plugin{
 		Description: "The pony plugin adds a pony image to an issue or PR in response to the `/pony` command.",
+		BestEffort:  true,
+		Fun:         true,
 		Commands: []plugins.Command{{
 			Name: "pony",
 			Arg: &plugins.CommandArg{
@@ -0,0 +1,156 @@
+// Package rebase implements the `/rebase` command which rebases a pull request on the latest commit of its base
+// branch and pushes it back to its branch, or reports the files in conflict when it cannot be rebased cleanly.
+package rebase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/git"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+const pluginName = "rebase"
+
+type scmProviderClient interface {
+	BotName() (string, error)
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
+	IsCollaborator(org, repo, login string) (bool, error)
+	QuoteAuthorForComment(string) string
+}
+
+var (
+	plugin = plugins.Plugin{
+		Description: "The rebase plugin rebases a pull request on the latest commit of its base branch and pushes it back to its branch, " +
+			"or lists the files in conflict when it cannot be rebased cleanly.",
+		Commands: []plugins.Command{{
+			Name:        "rebase",
+			Description: "Rebases the pull request on its base branch.",
+			WhoCanUse:   "The author of the pull request and the collaborators of the repository",
+			Action: plugins.
+				Invoke(handleGenericComment).
+				When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
+		}},
+	}
+)
+
+func init() {
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+func handleGenericComment(_ plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+	return handle(pc.SCMProviderClient, pc.GitClient, pc.Logger, &e)
+}
+
+func handle(spc scmProviderClient, gc git.Client, log *logrus.Entry, e *scmprovider.GenericCommentEvent) error {
+	org := e.Repo.Namespace
+	repo := e.Repo.Name
+	number := e.Number
+	user := e.Author.Login
+	respond := func(resp string) error {
+		return spc.CreateComment(org, repo, number, true, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(user), resp))
+	}
+
+	pr, err := spc.GetPullRequest(org, repo, number)
+	if err != nil {
+		resp := fmt.Sprintf("Cannot get PR #%d in %s/%s: %v", number, org, repo, err)
+		log.Warn(resp)
+		return respond(resp)
+	}
+	if pr.Author.Login != user {
+		ok, err := spc.IsCollaborator(org, repo, user)
+		if err != nil {
+			log.WithError(err).Warnf("cannot determine whether %s is a collaborator of %s/%s", user, org, repo)
+		}
+		if !ok {
+			resp := fmt.Sprintf("%s unauthorized: /rebase is restricted to the author of the pull request and collaborators", user)
+			log.Debug(resp)
+			return respond(resp)
+		}
+	}
+	botName, err := spc.BotName()
+	if err != nil {
+		return fmt.Errorf("failed to get the bot name: %v", err)
+	}
+
+	resp, err := rebase(gc, log, org, repo, pr, botName)
+	if err != nil {
+		log.WithError(err).Warnf("failed to rebase %s/%s PR #%d", org, repo, number)
+		resp = fmt.Sprintf("Cannot rebase the pull request on `%s`: %v", pr.Base.Ref, err)
+	}
+	return respond(resp)
+}
+
+// rebase rebases the pull request on its base branch and pushes it, returning the response to the command
+func rebase(gc git.Client, log *logrus.Entry, org, repo string, pr *scm.PullRequest, botName string) (string, error) {
+	r, err := gc.Clone(org + "/" + repo)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := r.Clean(); err != nil {
+			log.WithError(err).Warn("failed to clean the clone")
+		}
+	}()
+	// the commits are rewritten by the bot, their authors are preserved
+	if err := r.Config("user.name", botName); err != nil {
+		return "", err
+	}
+	if err := r.Config("user.email", botName+"@localhost"); err != nil {
+		return "", err
+	}
+	if err := r.Config("commit.gpgsign", "false"); err != nil {
+		log.Warningf("Cannot set gpgsign=false in gitconfig: %v", err)
+	}
+
+	headRepo := pr.Head.Repo.FullName
+	if headRepo == "" {
+		headRepo = org + "/" + repo
+	}
+	if err := r.Fetch(headRepo, pr.Head.Ref); err != nil {
+		return "", err
+	}
+	if err := r.Checkout("FETCH_HEAD"); err != nil {
+		return "", err
+	}
+	head, err := r.RevParse("HEAD")
+	if err != nil {
+		return "", err
+	}
+	head = strings.TrimSpace(head)
+
+	ok, conflicts, err := r.Rebase("origin/" + pr.Base.Ref)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return conflictsResponse(pr.Base.Ref, conflicts), nil
+	}
+	rebased, err := r.RevParse("HEAD")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(rebased) == head {
+		return fmt.Sprintf("The pull request is already up to date with `%s`.", pr.Base.Ref), nil
+	}
+	if err := r.ForcePush(headRepo, pr.Head.Ref, head); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("The pull request was rebased on `%s`.", pr.Base.Ref), nil
+}
+
+func conflictsResponse(base string, conflicts []string) string {
+	resp := fmt.Sprintf("The pull request cannot be rebased on `%s` cleanly", base)
+	if len(conflicts) == 0 {
+		return resp + ", please rebase it manually."
+	}
+	resp += ", the following files are in conflict:\n"
+	for _, file := range conflicts {
+		resp += fmt.Sprintf("- `%s`\n", file)
+	}
+	return resp + "\nPlease rebase it manually and resolve the conflicts."
+}
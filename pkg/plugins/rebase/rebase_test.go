@@ -0,0 +1,117 @@
+package rebase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/git/localgit"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name         string
+		commenter    string
+		baseFiles    map[string][]byte
+		wantComment  string
+		wantRebased  bool
+		wantUpToDate bool
+	}{
+		{
+			name:        "author rebases cleanly",
+			commenter:   "author",
+			baseFiles:   map[string][]byte{"base": []byte("base")},
+			wantComment: "The pull request was rebased on `base`.",
+			wantRebased: true,
+		},
+		{
+			name:        "collaborator rebases cleanly",
+			commenter:   "collab",
+			baseFiles:   map[string][]byte{"base": []byte("base")},
+			wantComment: "The pull request was rebased on `base`.",
+			wantRebased: true,
+		},
+		{
+			name:         "unauthorized user",
+			commenter:    "random",
+			baseFiles:    map[string][]byte{"base": []byte("base")},
+			wantComment:  "random unauthorized: /rebase is restricted to the author of the pull request and collaborators",
+			wantUpToDate: true,
+		},
+		{
+			name:         "conflicts",
+			commenter:    "author",
+			baseFiles:    map[string][]byte{"shared": []byte("base")},
+			wantComment:  "The pull request cannot be rebased on `base` cleanly, the following files are in conflict:\n- `shared`\n",
+			wantUpToDate: true,
+		},
+		{
+			name:         "already up to date",
+			commenter:    "author",
+			wantComment:  "The pull request is already up to date with `base`.",
+			wantUpToDate: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			lg, gc, err := localgit.New()
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, lg.Clean())
+				require.NoError(t, gc.Clean())
+			}()
+			require.NoError(t, lg.MakeFakeRepo("org", "repo"))
+			require.NoError(t, lg.CheckoutNewBranch("org", "repo", "base"))
+			require.NoError(t, lg.CheckoutNewBranch("org", "repo", "feature"))
+			require.NoError(t, lg.AddCommit("org", "repo", map[string][]byte{"shared": []byte("feature")}))
+			require.NoError(t, lg.Checkout("org", "repo", "base"))
+			if tc.baseFiles != nil {
+				require.NoError(t, lg.AddCommit("org", "repo", tc.baseFiles))
+			}
+			base, err := lg.RevParse("org", "repo", "base")
+			require.NoError(t, err)
+			head, err := lg.RevParse("org", "repo", "feature")
+			require.NoError(t, err)
+
+			spc := &fake.SCMClient{
+				Collaborators: []string{"collab"},
+				PullRequests: map[int]*scm.PullRequest{
+					1: {
+						Number: 1,
+						Author: scm.User{Login: "author"},
+						Base:   scm.PullRequestBranch{Ref: "base"},
+						Head:   scm.PullRequestBranch{Ref: "feature", Repo: scm.Repository{FullName: "org/repo"}},
+					},
+				},
+				PullRequestComments: map[int][]*scm.Comment{},
+			}
+			e := &scmprovider.GenericCommentEvent{
+				Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+				Number: 1,
+				IsPR:   true,
+				Body:   "/rebase",
+				Author: scm.User{Login: tc.commenter},
+			}
+			require.NoError(t, handle(spc, gc, logrus.WithField("plugin", pluginName), e))
+
+			require.Len(t, spc.PullRequestComments[1], 1)
+			assert.Contains(t, spc.PullRequestComments[1][0].Body, tc.wantComment)
+			rebased, err := lg.RevParse("org", "repo", "feature")
+			require.NoError(t, err)
+			if tc.wantUpToDate {
+				assert.Equal(t, head, rebased)
+			}
+			if tc.wantRebased {
+				assert.NotEqual(t, head, rebased)
+				parent, err := lg.RevParse("org", "repo", "feature^")
+				require.NoError(t, err)
+				assert.Equal(t, strings.TrimSpace(base), strings.TrimSpace(parent))
+			}
+		})
+	}
+}
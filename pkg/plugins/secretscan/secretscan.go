@@ -0,0 +1,253 @@
+// Package secretscan contains a plugin which scans the lines added by a pull request for secrets, with the
+// patterns of the well known keys and tokens, the configured patterns and an entropy heuristic. It reports
+// the outcome with the `secrets-check` status, blocking the merge of the pull requests leaking secrets, and
+// lists the redacted findings in a sticky comment.
+package secretscan
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pluginName = "secret-scan"
+
+	// statusContext is the context of the status reporting the outcome of the scan
+	statusContext = "secrets-check"
+
+	// maxFindings is the number of findings listed in the comment
+	maxFindings = 20
+)
+
+// secretsMarker identifies the sticky comment listing the findings
+var secretsMarker = botcomment.Marker(pluginName)
+
+// rule is a pattern of secrets
+type rule struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// builtinRules match the formats of the well known keys and tokens
+var builtinRules = []rule{
+	{name: "AWS access key ID", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "GitHub token", re: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{name: "GitLab token", re: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{name: "Slack token", re: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{name: "Google API key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{name: "private key", re: regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----`)},
+}
+
+// assignmentRe matches a quoted value assigned to a key, token, password or secret, capturing the value
+var assignmentRe = regexp.MustCompile(`(?i)(?:key|token|secret|passw(?:or)?d|pwd|credentials?)[\w.-]*["']?\s*[:=]+\s*["']([A-Za-z0-9+/=_.-]{16,})["']`)
+
+// hunkRe matches the header of a hunk of a patch, capturing the first line of the new file
+var hunkRe = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+type scmProviderClient interface {
+	GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error)
+	CreateStatus(org, repo, ref string, s *scm.StatusInput) (*scm.Status, error)
+	QuoteAuthorForComment(string) string
+	botcomment.ListingClient
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		pluginName,
+		plugins.Plugin{
+			Description:        "The secret-scan plugin scans the lines added by pull requests for secrets such as cloud provider keys, tokens and private keys. It sets the '" + statusContext + "' status, failing it when secrets are found, and comments with the redacted findings.",
+			ConfigHelpProvider: configHelp,
+			PullRequestHandler: handlePullRequest,
+		},
+	)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	scanConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		parts := strings.Split(repo, "/")
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
+		}
+		name := ""
+		if len(parts) == 2 {
+			name = parts[1]
+		}
+		ss := config.SecretScanFor(parts[0], name)
+		var buf bytes.Buffer
+		fmt.Fprint(&buf, "The built-in patterns of the cloud provider keys, the SCM and chat tokens and the private keys are scanned for.")
+		if len(ss.Patterns) > 0 {
+			fmt.Fprintf(&buf, "<br>Additional patterns: %q", ss.Patterns)
+		}
+		if len(ss.ExcludedPaths) > 0 {
+			fmt.Fprintf(&buf, "<br>Excluded paths: %q", ss.ExcludedPaths)
+		}
+		if ss.EntropyThreshold > 0 {
+			fmt.Fprintf(&buf, "<br>The values assigned to keys, tokens, passwords and secrets with an entropy above %.1f bits per character are reported.", ss.EntropyThreshold)
+		}
+		scanConfig[repo] = buf.String()
+	}
+	return scanConfig, nil
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	ss := pc.PluginConfig.SecretScanFor(pre.Repo.Namespace, pre.Repo.Name)
+	return handle(pc.SCMProviderClient, pc.Logger, ss, &pre)
+}
+
+// finding is a secret found in the lines added by a pull request
+type finding struct {
+	path string
+	line int
+	rule string
+	// redacted is the secret with all but its first characters masked
+	redacted string
+}
+
+func handle(spc scmProviderClient, log *logrus.Entry, ss *plugins.SecretScan, pre *scm.PullRequestHook) error {
+	if pre.Action != scm.ActionSync &&
+		pre.Action != scm.ActionOpen &&
+		pre.Action != scm.ActionReopen {
+		return nil
+	}
+
+	org := pre.Repo.Namespace
+	repo := pre.Repo.Name
+	number := pre.PullRequest.Number
+	sha := pre.PullRequest.Head.Sha
+	changes, err := spc.GetPullRequestChanges(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("can not get PR changes for secret-scan plugin: %v", err)
+	}
+	findings := scan(changes, ss)
+
+	status := &scm.StatusInput{
+		Label: statusContext,
+		State: scm.StateSuccess,
+		Desc:  "No secrets found.",
+	}
+	body := ""
+	if len(findings) > 0 {
+		log.WithField("findings", len(findings)).Info("Found secrets in the pull request.")
+		status.State = scm.StateFailure
+		status.Desc = fmt.Sprintf("%d potential secrets found.", len(findings))
+		if len(findings) == 1 {
+			status.Desc = "1 potential secret found."
+		}
+		body = plugins.FormatResponse(spc.QuoteAuthorForComment(pre.PullRequest.Author.Login), "this pull request adds lines which look like secrets. Remove them from its commits and revoke them, as they may have been exposed already.", summary(findings))
+	}
+	if _, err := spc.CreateStatus(org, repo, sha, status); err != nil {
+		return fmt.Errorf("error setting the %s status of %s/%s PR #%d: %v", statusContext, org, repo, number, err)
+	}
+	return botcomment.Upsert(spc, org, repo, number, true, secretsMarker, body)
+}
+
+// scan returns the secrets found in the lines added by the changes
+func scan(changes []*scm.Change, ss *plugins.SecretScan) []finding {
+	rules := append([]rule(nil), builtinRules...)
+	for _, re := range ss.PatternRes {
+		rules = append(rules, rule{name: "pattern " + strconv.Quote(re.String()), re: re})
+	}
+	var findings []finding
+	for _, change := range changes {
+		if change.Deleted || matchesAny(change.Path, ss.ExcludedPathRes) {
+			continue
+		}
+		line := 0
+		for _, text := range strings.Split(change.Patch, "\n") {
+			if m := hunkRe.FindStringSubmatch(text); m != nil {
+				line, _ = strconv.Atoi(m[1])
+				continue
+			}
+			switch {
+			case strings.HasPrefix(text, "+"):
+				findings = append(findings, scanLine(change.Path, line, text[1:], rules, ss.EntropyThreshold)...)
+				line++
+			case strings.HasPrefix(text, "-"), strings.HasPrefix(text, `\`):
+			default:
+				line++
+			}
+		}
+	}
+	return findings
+}
+
+// scanLine returns the secrets found in an added line, at most one per rule
+func scanLine(path string, line int, text string, rules []rule, entropyThreshold float64) []finding {
+	var findings []finding
+	for _, r := range rules {
+		if match := r.re.FindString(text); match != "" {
+			findings = append(findings, finding{path: path, line: line, rule: r.name, redacted: redact(match)})
+		}
+	}
+	if len(findings) > 0 || entropyThreshold <= 0 {
+		return findings
+	}
+	for _, m := range assignmentRe.FindAllStringSubmatch(text, -1) {
+		if entropy(m[1]) > entropyThreshold {
+			return append(findings, finding{path: path, line: line, rule: "high entropy value", redacted: redact(m[1])})
+		}
+	}
+	return findings
+}
+
+// entropy returns the Shannon entropy of the string in bits per character
+func entropy(s string) float64 {
+	counts := map[rune]int{}
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	answer := 0.0
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		answer -= p * math.Log2(p)
+	}
+	return answer
+}
+
+// redact masks all but the first characters of the secret
+func redact(secret string) string {
+	shown := 4
+	if len(secret) < 3*shown {
+		shown = len(secret) / 4
+	}
+	masked := len(secret) - shown
+	if masked > 16 {
+		masked = 16
+	}
+	return secret[:shown] + strings.Repeat("*", masked)
+}
+
+// summary lists the findings in markdown
+func summary(findings []finding) string {
+	var buf bytes.Buffer
+	fmt.Fprint(&buf, "#### Potential secrets:\n")
+	for i, f := range findings {
+		if i == maxFindings {
+			fmt.Fprintf(&buf, "- and %d more\n", len(findings)-maxFindings)
+			break
+		}
+		fmt.Fprintf(&buf, "- `%s` line %d: %s `%s`\n", f.path, f.line, f.rule, f.redacted)
+	}
+	return buf.String()
+}
+
+func matchesAny(str string, regexps []*regexp.Regexp) bool {
+	for _, reg := range regexps {
+		if reg.MatchString(str) {
+			return true
+		}
+	}
+	return false
+}
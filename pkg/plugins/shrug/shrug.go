@@ -32,6 +32,8 @@ const pluginName = "shrug"
 var (
 	plugin = plugins.Plugin{
 		Description: labels.Shrug,
+		BestEffort:  true,
+		Fun:         true,
 		Commands: []plugins.Command{{
 			Name:        "shrug",
 			Description: "Adds the " + labels.Shrug + " label",
@@ -0,0 +1,171 @@
+// Package signedcommits contains a plugin which requires the commits of the pull requests to be signed with a key
+// the SCM provider verified. The pull requests with unsigned commits get the `do-not-merge/unsigned-commits` label
+// and a failed `signed-commits` status, with a sticky comment listing the unsigned commits.
+package signedcommits
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pluginName = "signed-commits"
+
+	// statusContext is the context of the status reporting whether the commits are signed
+	statusContext = "signed-commits"
+)
+
+// unsignedCommitsMarker identifies the sticky comment listing the unsigned commits
+var unsignedCommitsMarker = botcomment.Marker(pluginName)
+
+type scmProviderClient interface {
+	ListPullRequestCommitSignatures(org, repo string, number int) ([]*scmprovider.CommitSignature, error)
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+	CreateStatus(org, repo, ref string, s *scm.StatusInput) (*scm.Status, error)
+	QuoteAuthorForComment(string) string
+	botcomment.ListingClient
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		pluginName,
+		plugins.Plugin{
+			Description:        "The signed-commits plugin requires the commits of pull requests to be signed with a GPG, SSH or S/MIME key verified by the SCM provider. It applies the '" + labels.UnsignedCommits + "' label and fails the '" + statusContext + "' status when a pull request has unsigned commits. Only GitHub and GitLab are supported.",
+			ConfigHelpProvider: configHelp,
+			PullRequestHandler: handlePullRequest,
+		},
+	)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	signedConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		parts := strings.Split(repo, "/")
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
+		}
+		name := ""
+		if len(parts) == 2 {
+			name = parts[1]
+		}
+		sc := config.SignedCommitsFor(parts[0], name)
+		if len(sc.Branches) == 0 {
+			signedConfig[repo] = "The commits of the pull requests of all branches must be signed."
+		} else {
+			signedConfig[repo] = fmt.Sprintf("The commits of the pull requests of the branches matching %q must be signed.", sc.Branches)
+		}
+	}
+	return signedConfig, nil
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	sc := pc.PluginConfig.SignedCommitsFor(pre.Repo.Namespace, pre.Repo.Name)
+	return handle(pc.SCMProviderClient, pc.Logger, sc, &pre)
+}
+
+func handle(spc scmProviderClient, log *logrus.Entry, sc *plugins.SignedCommits, pre *scm.PullRequestHook) error {
+	// the base branch may have been changed by an edit
+	if pre.Action != scm.ActionSync &&
+		pre.Action != scm.ActionOpen &&
+		pre.Action != scm.ActionReopen &&
+		pre.Action != scm.ActionEdited {
+		return nil
+	}
+
+	org := pre.Repo.Namespace
+	repo := pre.Repo.Name
+	number := pre.PullRequest.Number
+	sha := pre.PullRequest.Head.Sha
+	issueLabels, err := spc.GetIssueLabels(org, repo, number, true)
+	if err != nil {
+		return err
+	}
+	labelPresent := hasUnsignedLabel(issueLabels)
+
+	var unsigned []*scmprovider.CommitSignature
+	required := sc.RequiredOn(pre.PullRequest.Base.Ref)
+	if required {
+		signatures, err := spc.ListPullRequestCommitSignatures(org, repo, number)
+		if err != nil {
+			return fmt.Errorf("failed to list the commit signatures of %s/%s PR #%d: %v", org, repo, number, err)
+		}
+		for _, s := range signatures {
+			if !s.Verified {
+				unsigned = append(unsigned, s)
+			}
+		}
+	} else if !labelPresent {
+		// no status was reported for the branches whose commits do not need to be signed
+		return nil
+	}
+
+	status := &scm.StatusInput{
+		Label: statusContext,
+		State: scm.StateSuccess,
+		Desc:  "All commits are signed.",
+	}
+	if !required {
+		status.Desc = "Signed commits are not required."
+	}
+	body := ""
+	if len(unsigned) > 0 {
+		log.WithField("unsigned", len(unsigned)).Info("Found unsigned commits in the pull request.")
+		status.State = scm.StateFailure
+		status.Desc = fmt.Sprintf("%d commits are not signed.", len(unsigned))
+		if len(unsigned) == 1 {
+			status.Desc = "1 commit is not signed."
+		}
+		body = plugins.FormatResponse(spc.QuoteAuthorForComment(pre.PullRequest.Author.Login), "the commits of this pull request must be signed with a GPG, SSH or S/MIME key verified by the SCM provider.", summary(unsigned))
+		if !labelPresent {
+			if err := spc.AddLabel(org, repo, number, labels.UnsignedCommits, true); err != nil {
+				return err
+			}
+		}
+	} else if labelPresent {
+		if err := spc.RemoveLabel(org, repo, number, labels.UnsignedCommits, true); err != nil {
+			return err
+		}
+	}
+	if _, err := spc.CreateStatus(org, repo, sha, status); err != nil {
+		return fmt.Errorf("error setting the %s status of %s/%s PR #%d: %v", statusContext, org, repo, number, err)
+	}
+	return botcomment.Upsert(spc, org, repo, number, true, unsignedCommitsMarker, body)
+}
+
+// summary lists the unsigned commits in markdown
+func summary(unsigned []*scmprovider.CommitSignature) string {
+	var buf bytes.Buffer
+	fmt.Fprint(&buf, "#### Unsigned commits:\n")
+	for _, s := range unsigned {
+		sha := s.Sha
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		reason := s.Reason
+		if reason == "" {
+			reason = "unverified"
+		}
+		fmt.Fprintf(&buf, "- %s: %s\n", sha, reason)
+	}
+	return buf.String()
+}
+
+func hasUnsignedLabel(issueLabels []*scm.Label) bool {
+	label := strings.ToLower(labels.UnsignedCommits)
+	for _, elem := range issueLabels {
+		if strings.ToLower(elem.Name) == label {
+			return true
+		}
+	}
+	return false
+}
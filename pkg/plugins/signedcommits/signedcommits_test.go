@@ -0,0 +1,73 @@
+package signedcommits
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	*scmprovider.TestClient
+	signatures []*scmprovider.CommitSignature
+}
+
+func (f *fakeClient) ListPullRequestCommitSignatures(string, string, int) ([]*scmprovider.CommitSignature, error) {
+	return f.signatures, nil
+}
+
+func TestHandle(t *testing.T) {
+	fakeScmClient, fakeData := fake.NewDefault()
+	fakeData.RepoLabelsExisting = []string{labels.UnsignedCommits}
+	spc := &fakeClient{TestClient: scmprovider.ToTestClient(fakeScmClient)}
+	spc.signatures = []*scmprovider.CommitSignature{
+		{Sha: "1111111111", Verified: true, Reason: "valid"},
+		{Sha: "2222222222", Reason: "unsigned"},
+	}
+	pre := &scm.PullRequestHook{
+		Action: scm.ActionOpen,
+		Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+		PullRequest: scm.PullRequest{
+			Number: 1,
+			Base:   scm.PullRequestBranch{Ref: "release-1.0"},
+			Head:   scm.PullRequestBranch{Sha: "sha"},
+			Author: scm.User{Login: "author"},
+		},
+	}
+	sc := &plugins.SignedCommits{BranchRes: []*regexp.Regexp{regexp.MustCompile(`^release-`)}}
+	l := logrus.WithField("plugin", pluginName)
+	label := "org/repo#1:" + labels.UnsignedCommits
+
+	require.NoError(t, handle(spc, l, sc, pre))
+	assert.Equal(t, []string{label}, fakeData.PullRequestLabelsAdded)
+	require.Len(t, fakeData.Statuses["sha"], 1)
+	assert.Equal(t, statusContext, fakeData.Statuses["sha"][0].Label)
+	assert.Equal(t, scm.StateFailure, fakeData.Statuses["sha"][0].State)
+	require.Len(t, fakeData.PullRequestComments[1], 1)
+	assert.Contains(t, fakeData.PullRequestComments[1][0].Body, "- 2222222: unsigned")
+
+	// the unsigned commit is replaced by a signed one
+	fakeData.PullRequestLabelsExisting = []string{label}
+	spc.signatures[1] = &scmprovider.CommitSignature{Sha: "3333333333", Verified: true, Reason: "valid"}
+	pre.Action = scm.ActionSync
+	require.NoError(t, handle(spc, l, sc, pre))
+	assert.Equal(t, []string{label}, fakeData.PullRequestLabelsRemoved)
+	assert.Equal(t, scm.StateSuccess, fakeData.Statuses["sha"][0].State)
+	assert.Empty(t, fakeData.PullRequestComments[1])
+
+	// the commits of the other branches do not need to be signed
+	fakeData.PullRequestLabelsExisting = nil
+	fakeData.Statuses["sha"] = nil
+	spc.signatures[1] = &scmprovider.CommitSignature{Sha: "2222222222", Reason: "unsigned"}
+	pre.PullRequest.Base.Ref = "master"
+	require.NoError(t, handle(spc, l, sc, pre))
+	assert.Empty(t, fakeData.Statuses["sha"])
+	assert.Empty(t, fakeData.PullRequestComments[1])
+}
@@ -47,7 +47,8 @@ func init() {
 	plugins.RegisterPlugin(
 		pluginName,
 		plugins.Plugin{
-			Description:        "The size plugin manages the 'size/*' labels, maintaining the appropriate label on each pull request as it is updated. Generated files identified by the config file '.generated_files' at the repo root are ignored. Labels are applied based on the total number of lines of changes (additions and deletions).",
+			Description:        "The size plugin manages the 'size/*' labels, maintaining the appropriate label on each pull request as it is updated. Generated files identified by the config file '.generated_files' at the repo root, linguist-generated files and the files matching the configured exclude patterns are ignored. Labels are applied based on the total number of lines of changes (additions and deletions).",
+			BestEffort:         true,
 			ConfigHelpProvider: configHelp,
 			PullRequestHandler: handlePullRequest,
 		},
@@ -55,22 +56,38 @@ func init() {
 }
 
 func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
-	sizes := sizesOrDefault(config.Size)
-	return map[string]string{
-			"": fmt.Sprintf(`The plugin has the following thresholds:<ul>
+	configInfo := map[string]string{
+		"": thresholdsHelp(sizesOrDefault(config.Size)),
+	}
+	for key := range config.Size.Repos {
+		parts := strings.SplitN(key, "/", 2)
+		org, repo := parts[0], ""
+		if len(parts) == 2 {
+			repo = parts[1]
+		}
+		configInfo[key] = thresholdsHelp(sizesOrDefault(config.SizeFor(org, repo)))
+	}
+	return configInfo, nil
+}
+
+func thresholdsHelp(sizes plugins.Size) string {
+	help := fmt.Sprintf(`The plugin has the following thresholds:<ul>
 <li>size/XS:  0-%d</li>
 <li>size/S:   %d-%d</li>
 <li>size/M:   %d-%d</li>
 <li>size/L:   %d-%d</li>
 <li>size/XL:  %d-%d</li>
 <li>size/XXL: %d+</li>
-</ul>`, sizes.S-1, sizes.S, sizes.M-1, sizes.M, sizes.L-1, sizes.L, sizes.Xl-1, sizes.Xl, sizes.Xxl-1, sizes.Xxl),
-		},
-		nil
+</ul>`, sizes.S-1, sizes.S, sizes.M-1, sizes.M, sizes.L-1, sizes.L, sizes.Xl-1, sizes.Xl, sizes.Xxl-1, sizes.Xxl)
+	if len(sizes.Exclude) > 0 {
+		help += fmt.Sprintf("The changes of the files matching %s are not counted.", strings.Join(sizes.Exclude, ", "))
+	}
+	return help
 }
 
 func handlePullRequest(pc plugins.Agent, pe scm.PullRequestHook) error {
-	return handlePR(pc.SCMProviderClient, sizesOrDefault(pc.PluginConfig.Size), pc.Logger, pe)
+	repo := pe.PullRequest.Base.Repo
+	return handlePR(pc.SCMProviderClient, sizesOrDefault(pc.PluginConfig.SizeFor(repo.Namespace, repo.Name)), pc.Logger, pe)
 }
 
 // Strict subset of gitprovider.Client methods.
@@ -106,6 +123,16 @@ func handlePR(spc scmProviderClient, sizes plugins.Size, le *logrus.Entry, pe sc
 		le.Warnf("error while loading .gitattributes: %v", err)
 	}
 
+	var excluded []gitattributes.Pattern
+	for _, p := range sizes.Exclude {
+		pattern, err := gitattributes.ParsePattern(p)
+		if err != nil {
+			le.Warnf("error while parsing the exclude pattern %q: %v", p, err)
+			continue
+		}
+		excluded = append(excluded, pattern)
+	}
+
 	changes, err := spc.GetPullRequestChanges(owner, repo, num)
 	if err != nil {
 		return fmt.Errorf("can not get PR changes for size plugin: %v", err)
@@ -113,8 +140,8 @@ func handlePR(spc scmProviderClient, sizes plugins.Size, le *logrus.Entry, pe sc
 
 	var count int
 	for _, change := range changes {
-		// Skip generated and linguist-generated files.
-		if gf != nil && ga != nil && (gf.Match(change.Path) || ga.IsLinguistGenerated(change.Path)) {
+		// Skip generated, linguist-generated and excluded files.
+		if (gf != nil && gf.Match(change.Path)) || (ga != nil && ga.IsLinguistGenerated(change.Path)) || isExcluded(excluded, change.Path) {
 			continue
 		}
 
@@ -153,6 +180,15 @@ func handlePR(spc scmProviderClient, sizes plugins.Size, le *logrus.Entry, pe sc
 	return nil
 }
 
+func isExcluded(patterns []gitattributes.Pattern, path string) bool {
+	for _, p := range patterns {
+		if p.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
 // One of a set of discrete buckets.
 type size int
 
@@ -17,6 +17,7 @@ limitations under the License.
 package size
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/jenkins-x/go-scm/scm"
@@ -106,7 +107,7 @@ func TestSizesOrDefault(t *testing.T) {
 			expected: defaultSizes,
 		},
 	} {
-		if c.expected != sizesOrDefault(c.input) {
+		if !reflect.DeepEqual(c.expected, sizesOrDefault(c.input)) {
 			t.Fatalf("Unexpected sizes from sizesOrDefault - expected %+v but got %+v", c.expected, sizesOrDefault(c.input))
 		}
 	}
@@ -281,6 +282,62 @@ func TestHandlePR(t *testing.T) {
 			},
 			sizes: defaultSizes,
 		},
+		{
+			name: "size/S on synchronize, with excluded files",
+			client: &spc{
+				labels: map[scm.Label]bool{
+					{Name: "size/XL"}: true,
+				},
+				files: map[string][]byte{},
+				prChanges: []*scm.Change{
+					{
+						Sha:       "abcd",
+						Path:      "main.go",
+						Additions: 10,
+						Deletions: 5,
+						Changes:   15,
+					},
+					{
+						Sha:       "abcd",
+						Path:      "vendor/github.com/foo/bar.go",
+						Additions: 500,
+						Deletions: 0,
+						Changes:   500,
+					},
+					{
+						Sha:       "abcd",
+						Path:      "api/v1/types.pb.go",
+						Additions: 300,
+						Deletions: 100,
+						Changes:   400,
+					},
+				},
+			},
+			event: scm.PullRequestHook{
+				Action: scm.ActionSync,
+				PullRequest: scm.PullRequest{
+					Number: 101,
+					Base: scm.PullRequestBranch{
+						Sha: "abcd",
+						Repo: scm.Repository{
+							Namespace: "kubernetes",
+							Name:      "kubernetes",
+						},
+					},
+				},
+			},
+			finalLabels: []*scm.Label{
+				{Name: "size/S"},
+			},
+			sizes: plugins.Size{
+				S:       10,
+				M:       30,
+				L:       100,
+				Xl:      500,
+				Xxl:     1000,
+				Exclude: []string{"vendor/**", "*.pb.go"},
+			},
+		},
 		{
 			name: "simple size/XS, with .generated_files and paths-from-repo",
 			client: &spc{
@@ -667,6 +724,20 @@ func TestHelpProvider(t *testing.T) {
 			},
 			enabledRepos: []string{"org1", "org2/repo"},
 		},
+		{
+			name: "Sizes and exclusions specified for a repo",
+			config: &plugins.Configuration{
+				Size: plugins.Size{
+					Repos: map[string]plugins.Size{
+						"org2/repo": {
+							Xxl:     2000,
+							Exclude: []string{"vendor/**"},
+						},
+					},
+				},
+			},
+			enabledRepos: []string{"org1", "org2/repo"},
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -14,18 +14,20 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package skip implements the `/skip` command which allows users
+// Package skip implements the `/skip` command which allows collaborators
 // to clean up commit statuses of non-blocking presubmits on PRs.
 package skip
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/plugins/trigger"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/sirupsen/logrus"
 )
 
@@ -37,15 +39,21 @@ type scmProviderClient interface {
 	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
 	GetCombinedStatus(org, repo, ref string) (*scm.CombinedStatus, error)
 	GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error)
+	IsCollaborator(org, repo, login string) (bool, error)
 	QuoteAuthorForComment(string) string
 }
 
 var (
 	plugin = plugins.Plugin{
-		Description: "The skip plugin allows users to clean up GitHub stale commit statuses for non-blocking jobs on a PR.",
+		Description: "The skip plugin allows collaborators to clean up GitHub stale commit statuses for non-blocking jobs on a PR. Unlike /override, it never touches the required contexts.",
 		Commands: []plugins.Command{{
-			Name:        "skip",
-			Description: "Cleans up GitHub stale commit statuses for non-blocking jobs on a PR.",
+			Name: "skip",
+			Arg: &plugins.CommandArg{
+				Usage:    "context",
+				Optional: true,
+			},
+			Description: "Cleans up GitHub stale commit statuses for non-blocking jobs on a PR, or only for the given optional context.",
+			WhoCanUse:   "Collaborators of the repository",
 			Action: plugins.
 				Invoke(handleGenericComment).
 				When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
@@ -57,15 +65,37 @@ func init() {
 	plugins.RegisterPlugin(pluginName, plugin)
 }
 
-func handleGenericComment(_ plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+func handleGenericComment(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
 	honorOkToTest := trigger.HonorOkToTest(pc.PluginConfig.TriggerFor(e.Repo.Namespace, e.Repo.Name))
-	return handle(pc.SCMProviderClient, pc.Logger, &e, pc.Config.GetPresubmits(e.Repo), honorOkToTest)
+	return handle(strings.TrimSpace(match.Arg), pc.SCMProviderClient, pc.Logger, &e, pc.Config.GetPresubmits(e.Repo), honorOkToTest)
 }
 
-func handle(spc scmProviderClient, log *logrus.Entry, e *scmprovider.GenericCommentEvent, presubmits []job.Presubmit, honorOkToTest bool) error {
+// description records the user skipping a context in the description of its status
+func description(user string) string {
+	return fmt.Sprintf("%s %s", util.SkippedByPrefix, user)
+}
+
+func handle(context string, spc scmProviderClient, log *logrus.Entry, e *scmprovider.GenericCommentEvent, presubmits []job.Presubmit, honorOkToTest bool) error {
 	org := e.Repo.Namespace
 	repo := e.Repo.Name
 	number := e.Number
+	user := e.Author.Login
+
+	ok, err := spc.IsCollaborator(org, repo, user)
+	if err != nil {
+		log.WithError(err).Warnf("cannot determine whether %s is a collaborator of %s/%s", user, org, repo)
+	}
+	if !ok {
+		resp := fmt.Sprintf("%s unauthorized: /skip is restricted to collaborators", user)
+		log.Debug(resp)
+		return spc.CreateComment(org, repo, number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(user), resp))
+	}
+	if context != "" {
+		if resp := checkContext(context, presubmits); resp != "" {
+			log.Debug(resp)
+			return spc.CreateComment(org, repo, number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(user), resp))
+		}
+	}
 
 	pr, err := spc.GetPullRequest(org, repo, number)
 	if err != nil {
@@ -115,17 +145,16 @@ func handle(spc scmProviderClient, log *logrus.Entry, e *scmprovider.GenericComm
 			continue
 		}
 		// Only skip jobs that are not required
-		if job.ContextRequired() {
+		if job.ContextRequired() || (context != "" && job.Context != context) {
 			continue
 		}
-		context := job.Context
 		status := &scm.StatusInput{
 			State: scm.StateSuccess,
-			Desc:  "Skipped",
-			Label: context,
+			Desc:  description(user),
+			Label: job.Context,
 		}
 		if _, err := spc.CreateStatus(org, repo, pr.Head.Sha, status); err != nil {
-			resp := fmt.Sprintf("Cannot update PR status for context %s: %v", context, err)
+			resp := fmt.Sprintf("Cannot update PR status for context %s: %v", job.Context, err)
 			log.Warn(resp)
 			return spc.CreateComment(org, repo, number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(e.Author.Login), resp))
 		}
@@ -133,6 +162,20 @@ func handle(spc scmProviderClient, log *logrus.Entry, e *scmprovider.GenericComm
 	return nil
 }
 
+// checkContext returns why the given context cannot be skipped, if it is not the context of an optional presubmit
+func checkContext(context string, presubmits []job.Presubmit) string {
+	for _, p := range presubmits {
+		if p.Context != context {
+			continue
+		}
+		if p.ContextRequired() {
+			return fmt.Sprintf("/skip only skips optional contexts, but `%s` is required. It can only be overridden by a repo administrator with /override.", context)
+		}
+		return ""
+	}
+	return fmt.Sprintf("/skip requires the context of an optional job, but `%s` is not the context of any job.", context)
+}
+
 func statusExists(job job.Presubmit, statuses []*scm.Status) bool {
 	for _, status := range statuses {
 		if status.Label == job.Context {
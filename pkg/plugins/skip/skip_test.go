@@ -18,6 +18,7 @@ package skip
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/jenkins-x/go-scm/scm"
@@ -37,7 +38,9 @@ func TestSkipStatus(t *testing.T) {
 		prChanges      map[int][]*scm.Change
 		existing       []*scm.StatusInput
 		combinedStatus scm.State
+		context        string
 		expected       []*scm.StatusInput
+		comment        string
 	}{
 		{
 			name: "required contexts should not be skipped regardless of their state",
@@ -64,6 +67,7 @@ func TestSkipStatus(t *testing.T) {
 				IsPR:       true,
 				IssueState: "open",
 				Action:     scm.ActionCreate,
+				Author:     scm.User{Login: "collab"},
 				Body:       "/skip",
 				Number:     1,
 				Repo:       scm.Repository{Namespace: "org", Name: "repo"},
@@ -120,6 +124,7 @@ func TestSkipStatus(t *testing.T) {
 				IsPR:       true,
 				IssueState: "open",
 				Action:     scm.ActionCreate,
+				Author:     scm.User{Login: "collab"},
 				Body:       "/skip",
 				Number:     1,
 				Repo:       scm.Repository{Namespace: "org", Name: "repo"},
@@ -138,12 +143,12 @@ func TestSkipStatus(t *testing.T) {
 			expected: []*scm.StatusInput{
 				{
 					State: scm.StateSuccess,
-					Desc:  "Skipped",
+					Desc:  "Skipped by collab",
 					Label: "failed-tests",
 				},
 				{
 					State: scm.StateSuccess,
-					Desc:  "Skipped",
+					Desc:  "Skipped by collab",
 					Label: "pending-tests",
 				},
 			},
@@ -170,6 +175,7 @@ func TestSkipStatus(t *testing.T) {
 				IsPR:       true,
 				IssueState: "open",
 				Action:     scm.ActionCreate,
+				Author:     scm.User{Login: "collab"},
 				Body:       "/lh-skip",
 				Number:     1,
 				Repo:       scm.Repository{Namespace: "org", Name: "repo"},
@@ -188,12 +194,12 @@ func TestSkipStatus(t *testing.T) {
 			expected: []*scm.StatusInput{
 				{
 					State: scm.StateSuccess,
-					Desc:  "Skipped",
+					Desc:  "Skipped by collab",
 					Label: "failed-tests",
 				},
 				{
 					State: scm.StateSuccess,
-					Desc:  "Skipped",
+					Desc:  "Skipped by collab",
 					Label: "pending-tests",
 				},
 			},
@@ -214,6 +220,7 @@ func TestSkipStatus(t *testing.T) {
 				IsPR:       true,
 				IssueState: "open",
 				Action:     scm.ActionCreate,
+				Author:     scm.User{Login: "collab"},
 				Body:       "/skip",
 				Number:     1,
 				Repo:       scm.Repository{Namespace: "org", Name: "repo"},
@@ -238,6 +245,7 @@ func TestSkipStatus(t *testing.T) {
 				IsPR:       true,
 				IssueState: "open",
 				Action:     scm.ActionCreate,
+				Author:     scm.User{Login: "collab"},
 				Body:       "/skip",
 				Number:     1,
 				Repo:       scm.Repository{Namespace: "org", Name: "repo"},
@@ -274,6 +282,7 @@ func TestSkipStatus(t *testing.T) {
 				IsPR:       true,
 				IssueState: "open",
 				Action:     scm.ActionCreate,
+				Author:     scm.User{Login: "collab"},
 				Body: `/skip
 /test job`,
 				Number: 1,
@@ -315,6 +324,7 @@ func TestSkipStatus(t *testing.T) {
 				IsPR:       true,
 				IssueState: "open",
 				Action:     scm.ActionCreate,
+				Author:     scm.User{Login: "collab"},
 				Body:       "/skip",
 				Number:     1,
 				Repo:       scm.Repository{Namespace: "org", Name: "repo"},
@@ -340,6 +350,126 @@ func TestSkipStatus(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "only the given optional context should be skipped",
+
+			presubmits: []job.Presubmit{
+				{
+					Optional: true,
+					Reporter: job.Reporter{
+						Context: "failed-tests",
+					},
+				},
+				{
+					Optional: true,
+					Reporter: job.Reporter{
+						Context: "pending-tests",
+					},
+				},
+			},
+			sha: "shalala",
+			event: &scmprovider.GenericCommentEvent{
+				IsPR:       true,
+				IssueState: "open",
+				Action:     scm.ActionCreate,
+				Author:     scm.User{Login: "collab"},
+				Body:       "/skip failed-tests",
+				Number:     1,
+				Repo:       scm.Repository{Namespace: "org", Name: "repo"},
+			},
+			context: "failed-tests",
+			existing: []*scm.StatusInput{
+				{
+					State: scm.StateFailure,
+					Label: "failed-tests",
+				},
+				{
+					State: scm.StatePending,
+					Label: "pending-tests",
+				},
+			},
+			expected: []*scm.StatusInput{
+				{
+					State: scm.StateSuccess,
+					Label: "failed-tests",
+					Desc:  "Skipped by collab",
+				},
+				{
+					State: scm.StatePending,
+					Label: "pending-tests",
+				},
+			},
+		},
+		{
+			name: "a given required context should be refused",
+
+			presubmits: []job.Presubmit{
+				{
+					Reporter: job.Reporter{
+						Context: "failed-tests",
+					},
+				},
+			},
+			sha: "shalala",
+			event: &scmprovider.GenericCommentEvent{
+				IsPR:       true,
+				IssueState: "open",
+				Action:     scm.ActionCreate,
+				Author:     scm.User{Login: "collab"},
+				Body:       "/skip failed-tests",
+				Number:     1,
+				Repo:       scm.Repository{Namespace: "org", Name: "repo"},
+			},
+			context: "failed-tests",
+			existing: []*scm.StatusInput{
+				{
+					State: scm.StateFailure,
+					Label: "failed-tests",
+				},
+			},
+			expected: []*scm.StatusInput{
+				{
+					State: scm.StateFailure,
+					Label: "failed-tests",
+				},
+			},
+			comment: "/skip only skips optional contexts, but `failed-tests` is required",
+		},
+		{
+			name: "users who are not collaborators should not skip contexts",
+
+			presubmits: []job.Presubmit{
+				{
+					Optional: true,
+					Reporter: job.Reporter{
+						Context: "failed-tests",
+					},
+				},
+			},
+			sha: "shalala",
+			event: &scmprovider.GenericCommentEvent{
+				IsPR:       true,
+				IssueState: "open",
+				Action:     scm.ActionCreate,
+				Author:     scm.User{Login: "stranger"},
+				Body:       "/skip",
+				Number:     1,
+				Repo:       scm.Repository{Namespace: "org", Name: "repo"},
+			},
+			existing: []*scm.StatusInput{
+				{
+					State: scm.StateFailure,
+					Label: "failed-tests",
+				},
+			},
+			expected: []*scm.StatusInput{
+				{
+					State: scm.StateFailure,
+					Label: "failed-tests",
+				},
+			},
+			comment: "stranger unauthorized: /skip is restricted to collaborators",
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -349,7 +479,8 @@ func TestSkipStatus(t *testing.T) {
 				}
 			}
 			fspc := &fake.SCMClient{
-				IssueComments: make(map[int][]*scm.Comment),
+				Collaborators:       []string{"collab"},
+				PullRequestComments: make(map[int][]*scm.Comment),
 				PullRequests: map[int]*scm.PullRequest{
 					test.event.Number: {
 						Head: scm.PullRequestBranch{
@@ -370,10 +501,18 @@ func TestSkipStatus(t *testing.T) {
 			}
 			l := logrus.WithField("plugin", pluginName)
 
-			if err := handle(fspc, l, test.event, test.presubmits, true); err != nil {
+			if err := handle(test.context, fspc, l, test.event, test.presubmits, true); err != nil {
 				t.Fatalf("%s: unexpected error: %v", test.name, err)
 			}
 
+			comments := fspc.PullRequestComments[test.event.Number]
+			if test.comment == "" && len(comments) > 0 {
+				t.Errorf("%s: unexpected comment: %s", test.name, comments[0].Body)
+			}
+			if test.comment != "" && (len(comments) != 1 || !strings.Contains(comments[0].Body, test.comment)) {
+				t.Errorf("%s: expected a comment containing %q, got: %v", test.name, test.comment, comments)
+			}
+
 			// Check that the correct statuses have been updated.
 			created := fspc.CreatedStatuses[test.sha]
 			if len(test.expected) != len(created) {
@@ -0,0 +1,204 @@
+// Package stalereview contains a plugin which dismisses the approvals of the pull requests once they change files
+// which were not approved, so that an approved pull request cannot be merged with changes nobody reviewed. The files
+// covered by the approval are recorded when the pull request is approved with a review or labelled as approved.
+package stalereview
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/stalereviews"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const pluginName = "stale-review"
+
+type scmProviderClient interface {
+	botcomment.ListingClient
+	GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error)
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+	ListReviews(owner, repo string, number int) ([]*scm.Review, error)
+	DismissReview(org, repo string, number, id int, message string) error
+}
+
+func init() {
+	plugins.RegisterPlugin(
+		pluginName,
+		plugins.Plugin{
+			Description: "The stale-review plugin records the files changed by the pull requests when they are approved, with a review or the '" + labels.Approved + "' label, " +
+				"and dismisses their approving reviews and removes their '" + labels.Approved + "' label once they change other files, so that they need to be approved again.",
+			ConfigHelpProvider: configHelp,
+			PullRequestHandler: handlePullRequest,
+			ReviewEventHandler: handleReview,
+		},
+	)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	staleConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		parts := strings.Split(repo, "/")
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
+		}
+		name := ""
+		if len(parts) == 2 {
+			name = parts[1]
+		}
+		sr := config.StaleReviewsFor(parts[0], name)
+		help := "The approving reviews are dismissed and the " + labels.Approved + " label is removed once the approvals are stale."
+		if sr.KeepReviews {
+			help = "The " + labels.Approved + " label is removed once the approvals are stale, the approving reviews are kept."
+		}
+		if len(sr.IgnoredPaths) > 0 {
+			help += fmt.Sprintf(" The changes of the files matching %s never make the approvals stale.", strings.Join(sr.IgnoredPaths, ", "))
+		}
+		staleConfig[repo] = help
+	}
+	return staleConfig, nil
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	sr := pc.PluginConfig.StaleReviewsFor(pre.Repo.Namespace, pre.Repo.Name)
+	switch {
+	case pre.Action == scm.ActionLabel && pre.Label.Name == labels.Approved:
+		return record(pc.SCMProviderClient, &pre.Repo, &pre.PullRequest)
+	case pre.Action == scm.ActionSync:
+		return dismissStale(pc.SCMProviderClient, pc.Logger, sr, &pre.Repo, &pre.PullRequest, time.Now())
+	}
+	return nil
+}
+
+func handleReview(pc plugins.Agent, re scm.ReviewHook) error {
+	// the review webhook returns the state as lowercase, while the review API returns it as uppercase
+	if re.Action != scm.ActionSubmitted || strings.ToUpper(re.Review.State) != scm.ReviewStateApproved {
+		return nil
+	}
+	return record(pc.SCMProviderClient, &re.Repo, &re.PullRequest)
+}
+
+// changedFiles returns the sorted paths of the files changed by the pull request, the renamed files being listed
+// with both their paths
+func changedFiles(spc scmProviderClient, org, repo string, number int) ([]string, error) {
+	changes, err := spc.GetPullRequestChanges(org, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the changes of %s/%s PR #%d: %v", org, repo, number, err)
+	}
+	files := sets.NewString()
+	for _, change := range changes {
+		files.Insert(change.Path)
+		if change.PreviousPath != "" {
+			files.Insert(change.PreviousPath)
+		}
+	}
+	return files.List(), nil
+}
+
+// record records the files changed by an approved pull request, which replace the ones of the previous approval
+func record(spc scmProviderClient, r *scm.Repository, pr *scm.PullRequest) error {
+	org := r.Namespace
+	repo := r.Name
+	botName, err := spc.BotName()
+	if err != nil {
+		return fmt.Errorf("failed to get the bot name: %v", err)
+	}
+	comments, err := spc.ListPullRequestComments(org, repo, pr.Number)
+	if err != nil {
+		return fmt.Errorf("failed to list the comments of %s/%s PR #%d: %v", org, repo, pr.Number, err)
+	}
+	files, err := changedFiles(spc, org, repo, pr.Number)
+	if err != nil {
+		return err
+	}
+	rec := &stalereviews.Record{SHA: pr.Sha, Files: files}
+	if previous := stalereviews.Find(comments, botName); previous != nil {
+		rec.Dismissed = previous.Dismissed
+	}
+	return botcomment.Sync(spc, org, repo, pr.Number, true, comments, botName, stalereviews.Marker(), rec.Format())
+}
+
+// dismissStale dismisses the approvals of a pull request which changes files outside the approved ones
+func dismissStale(spc scmProviderClient, log *logrus.Entry, sr *plugins.StaleReviews, r *scm.Repository, pr *scm.PullRequest, now time.Time) error {
+	org := r.Namespace
+	repo := r.Name
+	botName, err := spc.BotName()
+	if err != nil {
+		return fmt.Errorf("failed to get the bot name: %v", err)
+	}
+	comments, err := spc.ListPullRequestComments(org, repo, pr.Number)
+	if err != nil {
+		return fmt.Errorf("failed to list the comments of %s/%s PR #%d: %v", org, repo, pr.Number, err)
+	}
+	rec := stalereviews.Find(comments, botName)
+	if rec == nil || !rec.Approved() {
+		return nil
+	}
+	files, err := changedFiles(spc, org, repo, pr.Number)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for _, f := range files {
+		if !rec.Covers(f) && !sr.Ignored(f) {
+			stale = append(stale, f)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	sort.Strings(stale)
+
+	log.Infof("Dismissing the approvals as %d files were changed since they were given", len(stale))
+	if !sr.KeepReviews {
+		if err := dismissReviews(spc, org, repo, pr.Number, pr.Sha); err != nil {
+			return err
+		}
+	}
+	issueLabels, err := spc.GetIssueLabels(org, repo, pr.Number, true)
+	if err != nil {
+		return fmt.Errorf("failed to get the labels of %s/%s PR #%d: %v", org, repo, pr.Number, err)
+	}
+	for _, l := range issueLabels {
+		if l.Name == labels.Approved {
+			if err := spc.RemoveLabel(org, repo, pr.Number, labels.Approved, true); err != nil {
+				return fmt.Errorf("failed to remove the %s label from %s/%s PR #%d: %v", labels.Approved, org, repo, pr.Number, err)
+			}
+			break
+		}
+	}
+	dismissed := &stalereviews.Record{SHA: pr.Sha, Stale: stale, Dismissed: &now}
+	return botcomment.Sync(spc, org, repo, pr.Number, true, comments, botName, stalereviews.Marker(), dismissed.Format())
+}
+
+// dismissReviews dismisses the approving reviews of a pull request, if the provider supports it
+func dismissReviews(spc scmProviderClient, org, repo string, number int, sha string) error {
+	reviews, err := spc.ListReviews(org, repo, number)
+	if err == scm.ErrNotSupported {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list the reviews of %s/%s PR #%d: %v", org, repo, number, err)
+	}
+	msg := fmt.Sprintf("Dismissed as commit %s changes files which were not approved.", sha)
+	for _, review := range reviews {
+		if strings.ToUpper(review.State) != scm.ReviewStateApproved {
+			continue
+		}
+		err := spc.DismissReview(org, repo, number, review.ID, msg)
+		if err == scm.ErrNotSupported {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to dismiss the review %d of %s/%s PR #%d: %v", review.ID, org, repo, number, err)
+		}
+	}
+	return nil
+}
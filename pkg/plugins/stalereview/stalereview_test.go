@@ -0,0 +1,182 @@
+package stalereview
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/stalereviews"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type fakeClient struct {
+	comments  []*scm.Comment
+	changes   []*scm.Change
+	labels    sets.String
+	reviews   []*scm.Review
+	dismissed []int
+	noDismiss bool
+}
+
+func (f *fakeClient) BotName() (string, error) {
+	return "bot", nil
+}
+
+func (f *fakeClient) CreateComment(_, _ string, _ int, _ bool, comment string) error {
+	f.comments = append(f.comments, &scm.Comment{ID: len(f.comments) + 1, Author: scm.User{Login: "bot"}, Body: comment})
+	return nil
+}
+
+func (f *fakeClient) EditComment(_, _ string, _, id int, comment string, _ bool) error {
+	for _, c := range f.comments {
+		if c.ID == id {
+			c.Body = comment
+			return nil
+		}
+	}
+	return errors.New("no such comment")
+}
+
+func (f *fakeClient) DeleteComment(_, _ string, _, id int, _ bool) error {
+	for i, c := range f.comments {
+		if c.ID == id {
+			f.comments = append(f.comments[:i], f.comments[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("no such comment")
+}
+
+func (f *fakeClient) ListIssueComments(string, string, int) ([]*scm.Comment, error) {
+	return f.comments, nil
+}
+
+func (f *fakeClient) ListPullRequestComments(string, string, int) ([]*scm.Comment, error) {
+	return f.comments, nil
+}
+
+func (f *fakeClient) GetPullRequestChanges(string, string, int) ([]*scm.Change, error) {
+	return f.changes, nil
+}
+
+func (f *fakeClient) GetIssueLabels(string, string, int, bool) ([]*scm.Label, error) {
+	var issueLabels []*scm.Label
+	for _, l := range f.labels.List() {
+		issueLabels = append(issueLabels, &scm.Label{Name: l})
+	}
+	return issueLabels, nil
+}
+
+func (f *fakeClient) RemoveLabel(_, _ string, _ int, label string, _ bool) error {
+	f.labels.Delete(label)
+	return nil
+}
+
+func (f *fakeClient) ListReviews(string, string, int) ([]*scm.Review, error) {
+	return f.reviews, nil
+}
+
+func (f *fakeClient) DismissReview(_, _ string, _, id int, _ string) error {
+	if f.noDismiss {
+		return scm.ErrNotSupported
+	}
+	f.dismissed = append(f.dismissed, id)
+	return nil
+}
+
+func TestRecordAndDismiss(t *testing.T) {
+	spc := &fakeClient{
+		changes: []*scm.Change{
+			{Path: "pkg/main.go"},
+			{Path: "docs/index.md", PreviousPath: "docs/README.md", Renamed: true},
+		},
+		labels: sets.NewString(labels.Approved, labels.LGTM),
+		reviews: []*scm.Review{
+			{ID: 1, State: "APPROVED"},
+			{ID: 2, State: "COMMENTED"},
+			{ID: 3, State: "approved"},
+		},
+	}
+	sr := &plugins.StaleReviews{IgnoredPathRes: []*regexp.Regexp{regexp.MustCompile(`^(?:.*\.md)$`)}}
+	r := &scm.Repository{Namespace: "org", Name: "repo"}
+	pr := &scm.PullRequest{Number: 1, Sha: "abc"}
+	l := logrus.WithField("plugin", pluginName)
+	now := time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC)
+
+	require.NoError(t, record(spc, r, pr))
+	require.Len(t, spc.comments, 1)
+	rec := stalereviews.Find(spc.comments, "bot")
+	require.NotNil(t, rec)
+	assert.Equal(t, []string{"docs/README.md", "docs/index.md", "pkg/main.go"}, rec.Files)
+
+	// the approved files and the ignored ones are changed again
+	pr.Sha = "def"
+	spc.changes = append(spc.changes, &scm.Change{Path: "CHANGELOG.md"})
+	require.NoError(t, dismissStale(spc, l, sr, r, pr, now))
+	assert.Empty(t, spc.dismissed)
+	assert.True(t, spc.labels.Has(labels.Approved))
+	assert.True(t, stalereviews.Find(spc.comments, "bot").Approved())
+
+	// another file is changed
+	pr.Sha = "ghi"
+	spc.changes = append(spc.changes, &scm.Change{Path: "pkg/other.go"})
+	require.NoError(t, dismissStale(spc, l, sr, r, pr, now))
+	assert.Equal(t, []int{1, 3}, spc.dismissed)
+	assert.Equal(t, []string{labels.LGTM}, spc.labels.List())
+	require.Len(t, spc.comments, 1)
+	rec = stalereviews.Find(spc.comments, "bot")
+	require.NotNil(t, rec)
+	assert.False(t, rec.Approved())
+	assert.Equal(t, []string{"pkg/other.go"}, rec.Stale)
+	assert.Equal(t, "ghi", rec.SHA)
+
+	// the dismissed approvals are not dismissed again
+	spc.labels.Insert(labels.Approved)
+	require.NoError(t, dismissStale(spc, l, sr, r, pr, now.Add(time.Hour)))
+	assert.Equal(t, []int{1, 3}, spc.dismissed)
+	assert.True(t, spc.labels.Has(labels.Approved))
+
+	// the new approval covers all the files and keeps the time of the dismissal
+	require.NoError(t, record(spc, r, pr))
+	rec = stalereviews.Find(spc.comments, "bot")
+	require.NotNil(t, rec)
+	assert.True(t, rec.Covers("pkg/other.go"))
+	require.NotNil(t, rec.Dismissed)
+	assert.True(t, now.Equal(*rec.Dismissed))
+}
+
+func TestDismissKeepingReviews(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		sr        *plugins.StaleReviews
+		noDismiss bool
+	}{
+		{name: "keep reviews", sr: &plugins.StaleReviews{KeepReviews: true}},
+		{name: "unsupported dismissal", sr: &plugins.StaleReviews{}, noDismiss: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			spc := &fakeClient{
+				changes:   []*scm.Change{{Path: "main.go"}},
+				labels:    sets.NewString(labels.Approved),
+				reviews:   []*scm.Review{{ID: 1, State: "APPROVED"}},
+				noDismiss: tc.noDismiss,
+			}
+			r := &scm.Repository{Namespace: "org", Name: "repo"}
+			pr := &scm.PullRequest{Number: 1, Sha: "abc"}
+			require.NoError(t, record(spc, r, pr))
+
+			spc.changes = append(spc.changes, &scm.Change{Path: "other.go"})
+			require.NoError(t, dismissStale(spc, logrus.WithField("plugin", pluginName), tc.sr, r, pr, time.Now()))
+			assert.Empty(t, spc.dismissed)
+			assert.Empty(t, spc.labels.List())
+			assert.False(t, stalereviews.Find(spc.comments, "bot").Approved())
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Package transferissue contains a plugin which moves an issue to another repository of the same org with the
+// `/transfer-issue` or `/move` commands, with the transfer API of the SCM provider where it is supported, or by
+// copying the issue to the other repository and closing it otherwise.
+package transferissue
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const pluginName = "transfer-issue"
+
+// maintainerRoles are the permissions on both repositories needed to move an issue
+var maintainerRoles = []string{scmprovider.RoleAdmin, "maintain", "write"}
+
+var (
+	plugin = plugins.Plugin{
+		Description: "The transfer-issue plugin moves an issue to another repository of the same org, with the transfer API of the SCM provider where it is supported, or by copying the issue to the other repository and closing it otherwise. The labels of the issue are kept and the moved issue is linked to the original one.",
+		Commands: []plugins.Command{{
+			Name: "transfer-issue|move",
+			Arg: &plugins.CommandArg{
+				Usage:   "repo",
+				Pattern: `[-.\w]+(?:/[-.\w]+)?`,
+			},
+			Description: "Moves the issue to another repository of the same org, e.g. `/transfer-issue other-repo`.",
+			WhoCanUse:   "Users with write access to both repositories.",
+			Action: plugins.
+				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+					return handle(pc.SCMProviderClient, pc.Logger, &e, match.Arg)
+				}).
+				When(plugins.Action(scm.ActionCreate), plugins.IsNotPR(), plugins.IssueState("open")),
+		}},
+	}
+)
+
+func init() {
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+type scmProviderClient interface {
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	CloseIssue(owner, repo string, number int) error
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	CreateIssue(owner, repo, title, body string) (*scm.Issue, error)
+	GetIssue(owner, repo string, number int) (*scm.Issue, error)
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	GetRepositoryByFullName(fullName string) (*scm.Repository, error)
+	HasPermission(org, repo, user string, roles ...string) (bool, error)
+	QuoteAuthorForComment(string) string
+	TransferIssue(owner, repo string, number int, targetRepo string) (*scm.Issue, error)
+}
+
+func handle(spc scmProviderClient, log *logrus.Entry, e *scmprovider.GenericCommentEvent, target string) error {
+	org := e.Repo.Namespace
+	repo := e.Repo.Name
+	user := e.Author.Login
+	respond := func(msg string) error {
+		return spc.CreateComment(org, repo, e.Number, false, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(user), msg))
+	}
+
+	if parts := strings.SplitN(target, "/", 2); len(parts) == 2 {
+		if !strings.EqualFold(parts[0], org) {
+			return respond(fmt.Sprintf("issues can only be moved to the repositories of %s.", org))
+		}
+		target = parts[1]
+	}
+	if strings.EqualFold(target, repo) {
+		return respond("the issue is already in this repository.")
+	}
+	if _, err := spc.GetRepositoryByFullName(scm.Join(org, target)); err != nil {
+		log.WithError(err).Warnf("Failed to get the repository %s/%s", org, target)
+		return respond(fmt.Sprintf("the repository %s/%s cannot be found.", org, target))
+	}
+	for _, r := range []string{repo, target} {
+		ok, err := spc.HasPermission(org, r, user, maintainerRoles...)
+		if err != nil {
+			return fmt.Errorf("failed to check the permission of %s on %s/%s: %v", user, org, r, err)
+		}
+		if !ok {
+			return respond(fmt.Sprintf("only the users with write access to both %s/%s and %s/%s can move this issue.", org, repo, org, target))
+		}
+	}
+
+	issueLabels, err := spc.GetIssueLabels(org, repo, e.Number, false)
+	if err != nil {
+		return fmt.Errorf("failed to get the labels of %s/%s#%d: %v", org, repo, e.Number, err)
+	}
+
+	copied := false
+	moved, err := spc.TransferIssue(org, repo, e.Number, target)
+	if err == scm.ErrNotSupported {
+		log.Infof("Copying %s/%s#%d to %s/%s as the provider cannot transfer issues", org, repo, e.Number, org, target)
+		copied = true
+		moved, err = copyIssue(spc, org, repo, e.Number, target)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to move %s/%s#%d to %s/%s: %v", org, repo, e.Number, org, target, err)
+	}
+	log.Infof("Moved %s/%s#%d to %s/%s#%d", org, repo, e.Number, org, target, moved.Number)
+
+	restoreLabels(spc, log, org, target, moved.Number, issueLabels)
+	if err := spc.CreateComment(org, target, moved.Number, false, fmt.Sprintf("This issue was moved from %s/%s#%d by @%s.", org, repo, e.Number, user)); err != nil {
+		return fmt.Errorf("failed to link %s/%s#%d to the original issue: %v", org, target, moved.Number, err)
+	}
+	if !copied {
+		return nil
+	}
+	if err := spc.CreateComment(org, repo, e.Number, false, fmt.Sprintf("This issue was moved to %s/%s#%d.", org, target, moved.Number)); err != nil {
+		return fmt.Errorf("failed to link %s/%s#%d to the moved issue: %v", org, repo, e.Number, err)
+	}
+	return spc.CloseIssue(org, repo, e.Number)
+}
+
+// copyIssue creates a copy of the issue in the target repository, for the providers which cannot transfer issues
+func copyIssue(spc scmProviderClient, org, repo string, number int, target string) (*scm.Issue, error) {
+	issue, err := spc.GetIssue(org, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	body := fmt.Sprintf("%s\n\n---\n_Originally opened by @%s as %s/%s#%d._", issue.Body, issue.Author.Login, org, repo, number)
+	return spc.CreateIssue(org, target, issue.Title, body)
+}
+
+// restoreLabels applies the labels of the original issue the moved one does not have, which happens when they do not
+// exist in the target repository or the issue was copied
+func restoreLabels(spc scmProviderClient, log *logrus.Entry, org, repo string, number int, issueLabels []*scm.Label) {
+	existing := sets.NewString()
+	movedLabels, err := spc.GetIssueLabels(org, repo, number, false)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to get the labels of %s/%s#%d", org, repo, number)
+	}
+	for _, l := range movedLabels {
+		existing.Insert(l.Name)
+	}
+	for _, l := range issueLabels {
+		if existing.Has(l.Name) {
+			continue
+		}
+		if err := spc.AddLabel(org, repo, number, l.Name, false); err != nil {
+			log.WithError(err).Warnf("Failed to add the %s label to %s/%s#%d", l.Name, org, repo, number)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package transferissue
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	transfer    bool
+	repos       map[string]bool
+	permissions map[string]string
+	issues      map[string]*scm.Issue
+	labels      map[string][]string
+	comments    map[string][]string
+	closed      []string
+}
+
+func newFakeClient(transfer bool) *fakeClient {
+	return &fakeClient{
+		transfer:    transfer,
+		repos:       map[string]bool{"org/repo": true, "org/other": true},
+		permissions: map[string]string{"org/repo:maintainer": "write", "org/other:maintainer": "admin", "org/repo:writer": "write", "org/other:writer": "read"},
+		issues:      map[string]*scm.Issue{"org/repo#5": {Number: 5, Title: "Bug", Body: "It fails", Author: scm.User{Login: "author"}}},
+		labels:      map[string][]string{"org/repo#5": {"kind/bug", "priority/important"}},
+		comments:    map[string][]string{},
+	}
+}
+
+func key(org, repo string, number int) string {
+	return fmt.Sprintf("%s/%s#%d", org, repo, number)
+}
+
+func (f *fakeClient) AddLabel(org, repo string, number int, label string, _ bool) error {
+	f.labels[key(org, repo, number)] = append(f.labels[key(org, repo, number)], label)
+	return nil
+}
+
+func (f *fakeClient) CloseIssue(org, repo string, number int) error {
+	f.closed = append(f.closed, key(org, repo, number))
+	return nil
+}
+
+func (f *fakeClient) CreateComment(org, repo string, number int, _ bool, comment string) error {
+	f.comments[key(org, repo, number)] = append(f.comments[key(org, repo, number)], comment)
+	return nil
+}
+
+func (f *fakeClient) CreateIssue(org, repo, title, body string) (*scm.Issue, error) {
+	issue := &scm.Issue{Number: 1, Title: title, Body: body}
+	f.issues[key(org, repo, 1)] = issue
+	return issue, nil
+}
+
+func (f *fakeClient) GetIssue(org, repo string, number int) (*scm.Issue, error) {
+	return f.issues[key(org, repo, number)], nil
+}
+
+func (f *fakeClient) GetIssueLabels(org, repo string, number int, _ bool) ([]*scm.Label, error) {
+	var labels []*scm.Label
+	for _, l := range f.labels[key(org, repo, number)] {
+		labels = append(labels, &scm.Label{Name: l})
+	}
+	return labels, nil
+}
+
+func (f *fakeClient) GetRepositoryByFullName(fullName string) (*scm.Repository, error) {
+	if !f.repos[fullName] {
+		return nil, errors.New("not found")
+	}
+	return &scm.Repository{FullName: fullName}, nil
+}
+
+func (f *fakeClient) HasPermission(org, repo, user string, roles ...string) (bool, error) {
+	perm := f.permissions[org+"/"+repo+":"+user]
+	for _, r := range roles {
+		if r == perm {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeClient) QuoteAuthorForComment(author string) string {
+	return author
+}
+
+func (f *fakeClient) TransferIssue(org, repo string, number int, target string) (*scm.Issue, error) {
+	if !f.transfer {
+		return nil, scm.ErrNotSupported
+	}
+	issue := f.issues[key(org, repo, number)]
+	delete(f.issues, key(org, repo, number))
+	moved := *issue
+	moved.Number = 9
+	f.issues[key(org, target, 9)] = &moved
+	// the labels which do not exist in the target repository are dropped
+	f.labels[key(org, target, 9)] = []string{"kind/bug"}
+	return &moved, nil
+}
+
+func event(user, target string) *scmprovider.GenericCommentEvent {
+	return &scmprovider.GenericCommentEvent{
+		Action:     scm.ActionCreate,
+		Body:       "/transfer-issue " + target,
+		Author:     scm.User{Login: user},
+		Repo:       scm.Repository{Namespace: "org", Name: "repo"},
+		Number:     5,
+		IssueState: "open",
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	spc := newFakeClient(true)
+	require.NoError(t, handle(spc, logrus.WithField("plugin", pluginName), event("maintainer", "other"), "other"))
+
+	assert.Nil(t, spc.issues["org/repo#5"])
+	assert.Equal(t, "Bug", spc.issues["org/other#9"].Title)
+	assert.ElementsMatch(t, []string{"kind/bug", "priority/important"}, spc.labels["org/other#9"])
+	assert.Equal(t, []string{"This issue was moved from org/repo#5 by @maintainer."}, spc.comments["org/other#9"])
+	assert.Empty(t, spc.comments["org/repo#5"])
+	assert.Empty(t, spc.closed)
+}
+
+func TestCopy(t *testing.T) {
+	spc := newFakeClient(false)
+	require.NoError(t, handle(spc, logrus.WithField("plugin", pluginName), event("maintainer", "org/other"), "org/other"))
+
+	copied := spc.issues["org/other#1"]
+	require.NotNil(t, copied)
+	assert.Equal(t, "Bug", copied.Title)
+	assert.Contains(t, copied.Body, "It fails")
+	assert.Contains(t, copied.Body, "Originally opened by @author as org/repo#5")
+	assert.Equal(t, []string{"kind/bug", "priority/important"}, spc.labels["org/other#1"])
+	assert.Equal(t, []string{"This issue was moved from org/repo#5 by @maintainer."}, spc.comments["org/other#1"])
+	assert.Equal(t, []string{"This issue was moved to org/other#1."}, spc.comments["org/repo#5"])
+	assert.Equal(t, []string{"org/repo#5"}, spc.closed)
+}
+
+func TestRefused(t *testing.T) {
+	cases := []struct {
+		name     string
+		user     string
+		target   string
+		response string
+	}{
+		{name: "other org", user: "maintainer", target: "other-org/other", response: "issues can only be moved to the repositories of org."},
+		{name: "same repo", user: "maintainer", target: "repo", response: "the issue is already in this repository."},
+		{name: "missing repo", user: "maintainer", target: "missing", response: "the repository org/missing cannot be found."},
+		{name: "no write access to the target", user: "writer", target: "other", response: "only the users with write access to both org/repo and org/other can move this issue."},
+		{name: "no access", user: "rando", target: "other", response: "only the users with write access to both org/repo and org/other can move this issue."},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			spc := newFakeClient(true)
+			require.NoError(t, handle(spc, logrus.WithField("plugin", pluginName), event(tc.user, tc.target), tc.target))
+			require.Len(t, spc.comments["org/repo#5"], 1)
+			assert.Contains(t, spc.comments["org/repo#5"][0], tc.response)
+			assert.NotNil(t, spc.issues["org/repo#5"])
+			assert.Empty(t, spc.closed)
+		})
+	}
+}
+
+func TestCommand(t *testing.T) {
+	for body, expected := range map[string]string{
+		"/transfer-issue other":      "other",
+		"/move org/other":            "org/other",
+		"/lh-move other.repo":        "other.repo",
+		"/move":                      "",
+		"/transfer-issue other repo": "",
+	} {
+		e := event("maintainer", "")
+		e.Body = body
+		matches, err := plugin.Commands[0].FilterAndGetMatches(e)
+		require.NoError(t, err, body)
+		if expected == "" {
+			assert.Empty(t, matches, body)
+			continue
+		}
+		require.Len(t, matches, 1, body)
+		assert.Equal(t, expected, matches[0].Arg, body)
+	}
+}
@@ -0,0 +1,126 @@
+// Package triage implements the `/triage` commands, which let the collaborators of a repository triage its issues
+// and pull requests with the `triage/*` labels. The new issues are labelled as needing triage until they are
+// accepted.
+package triage
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+const pluginName = "triage"
+
+var (
+	triageLabels = []string{labels.TriageAccepted, labels.TriageDuplicate, labels.TriageNeedsInfo}
+)
+
+type scmProviderClient interface {
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	IsCollaborator(org, repo, login string) (bool, error)
+	QuoteAuthorForComment(string) string
+}
+
+var (
+	plugin = plugins.Plugin{
+		Description: "The triage plugin labels the new issues with '" + labels.NeedsTriage + "', and lets the collaborators triage the issues and PRs as accepted, duplicate or needing information. " +
+			"The '" + labels.NeedsTriage + "' label is removed once an issue or PR is accepted.",
+		IssueHandler: handleIssue,
+		Commands: []plugins.Command{{
+			Prefix: "remove-",
+			Name:   "triage",
+			Arg: &plugins.CommandArg{
+				Pattern: "accepted|duplicate|needs-information",
+			},
+			Description: "Flags an issue or PR as accepted/duplicate/needs-information, or removes the flag",
+			WhoCanUse:   "Collaborators of the repository",
+			Action: plugins.
+				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+					return handleOne(match.Prefix != "", "triage/"+match.Arg, pc.SCMProviderClient, pc.Logger, &e)
+				}).
+				When(plugins.Action(scm.ActionCreate)),
+		}},
+	}
+)
+
+func init() {
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+func handleIssue(pc plugins.Agent, ie scm.IssueHook) error {
+	if ie.Action != scm.ActionOpen || ie.Issue.PullRequest {
+		return nil
+	}
+	return labelNew(pc.SCMProviderClient, pc.Logger, ie.Repo.Namespace, ie.Repo.Name, &ie.Issue)
+}
+
+// labelNew labels a new issue as needing triage, unless it was already triaged
+func labelNew(spc scmProviderClient, log *logrus.Entry, org, repo string, issue *scm.Issue) error {
+	for _, l := range issue.Labels {
+		if l == labels.NeedsTriage || l == labels.TriageAccepted {
+			return nil
+		}
+	}
+	log.Infof("Adding the %s label to %s/%s#%d", labels.NeedsTriage, org, repo, issue.Number)
+	return spc.AddLabel(org, repo, issue.Number, labels.NeedsTriage, false)
+}
+
+func handleOne(remove bool, lbl string, spc scmProviderClient, log *logrus.Entry, e *scmprovider.GenericCommentEvent) error {
+	org := e.Repo.Namespace
+	repo := e.Repo.Name
+	number := e.Number
+	user := e.Author.Login
+
+	ok, err := spc.IsCollaborator(org, repo, user)
+	if err != nil {
+		log.WithError(err).Warnf("cannot determine whether %s is a collaborator of %s/%s", user, org, repo)
+	}
+	if !ok {
+		resp := fmt.Sprintf("%s unauthorized: /triage is restricted to collaborators", user)
+		log.Debug(resp)
+		return spc.CreateComment(org, repo, number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(user), resp))
+	}
+
+	issueLabels, err := spc.GetIssueLabels(org, repo, number, e.IsPR)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to get labels.")
+	}
+
+	if remove {
+		if !scmprovider.HasLabel(lbl, issueLabels) {
+			return nil
+		}
+		if err := spc.RemoveLabel(org, repo, number, lbl, e.IsPR); err != nil {
+			return err
+		}
+		// an issue which is no longer accepted needs to be triaged again
+		if lbl == labels.TriageAccepted && !scmprovider.HasLabel(labels.NeedsTriage, issueLabels) {
+			return spc.AddLabel(org, repo, number, labels.NeedsTriage, e.IsPR)
+		}
+		return nil
+	}
+
+	if !scmprovider.HasLabel(lbl, issueLabels) {
+		for _, label := range triageLabels {
+			if label != lbl && scmprovider.HasLabel(label, issueLabels) {
+				if err := spc.RemoveLabel(org, repo, number, label, e.IsPR); err != nil {
+					log.WithError(err).Errorf("failed to remove the following label: %s", label)
+				}
+			}
+		}
+		if err := spc.AddLabel(org, repo, number, lbl, e.IsPR); err != nil {
+			log.WithError(err).Errorf("failed to add the following label: %s", lbl)
+		}
+	}
+	if lbl == labels.TriageAccepted && scmprovider.HasLabel(labels.NeedsTriage, issueLabels) {
+		return spc.RemoveLabel(org, repo, number, labels.NeedsTriage, e.IsPR)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package triage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+type fakeClient struct {
+	// current labels
+	labels []string
+	// labels that are added
+	added []string
+	// labels that are removed
+	removed []string
+	// comments that are created
+	comments []string
+}
+
+func (c *fakeClient) AddLabel(owner, repo string, number int, label string, pr bool) error {
+	c.added = append(c.added, label)
+	c.labels = append(c.labels, label)
+	return nil
+}
+
+func (c *fakeClient) RemoveLabel(owner, repo string, number int, label string, pr bool) error {
+	c.removed = append(c.removed, label)
+	for k, v := range c.labels {
+		if label == v {
+			c.labels = append(c.labels[:k], c.labels[k+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (c *fakeClient) GetIssueLabels(owner, repo string, number int, pr bool) ([]*scm.Label, error) {
+	la := []*scm.Label{}
+	for _, l := range c.labels {
+		la = append(la, &scm.Label{Name: l})
+	}
+	return la, nil
+}
+
+func (c *fakeClient) CreateComment(owner, repo string, number int, pr bool, comment string) error {
+	c.comments = append(c.comments, comment)
+	return nil
+}
+
+func (c *fakeClient) IsCollaborator(org, repo, login string) (bool, error) {
+	return login == "collab", nil
+}
+
+func (c *fakeClient) QuoteAuthorForComment(author string) string {
+	return author
+}
+
+func TestTriage(t *testing.T) {
+	var testcases = []struct {
+		name     string
+		body     string
+		author   string
+		added    []string
+		removed  []string
+		labels   []string
+		comments int
+	}{
+		{
+			name:    "add triage but don't specify state -> no-op",
+			body:    "/triage",
+			added:   []string{},
+			removed: []string{},
+			labels:  []string{},
+		},
+		{
+			name:    "add triage random -> no-op",
+			body:    "/triage random",
+			added:   []string{},
+			removed: []string{},
+			labels:  []string{},
+		},
+		{
+			name:    "accept, needs triage -> accepted added, needs-triage removed",
+			body:    "/triage accepted",
+			added:   []string{labels.TriageAccepted},
+			removed: []string{labels.NeedsTriage},
+			labels:  []string{labels.NeedsTriage},
+		},
+		{
+			name:    "accept with prefix, needs triage -> accepted added, needs-triage removed",
+			body:    "/lh-triage accepted",
+			added:   []string{labels.TriageAccepted},
+			removed: []string{labels.NeedsTriage},
+			labels:  []string{labels.NeedsTriage},
+		},
+		{
+			name:    "duplicate, needs triage -> duplicate added",
+			body:    "/triage duplicate",
+			added:   []string{labels.TriageDuplicate},
+			removed: []string{},
+			labels:  []string{labels.NeedsTriage},
+		},
+		{
+			name:    "needs information, accepted -> needs information added, accepted removed",
+			body:    "/triage needs-information",
+			added:   []string{labels.TriageNeedsInfo},
+			removed: []string{labels.TriageAccepted},
+			labels:  []string{labels.TriageAccepted},
+		},
+		{
+			name:    "accept, have it -> no-op",
+			body:    "/triage accepted",
+			added:   []string{},
+			removed: []string{},
+			labels:  []string{labels.TriageAccepted},
+		},
+		{
+			name:    "remove accepted -> accepted removed, needs-triage added",
+			body:    "/remove-triage accepted",
+			added:   []string{labels.NeedsTriage},
+			removed: []string{labels.TriageAccepted},
+			labels:  []string{labels.TriageAccepted},
+		},
+		{
+			name:    "remove duplicate -> duplicate removed",
+			body:    "/remove-triage duplicate",
+			added:   []string{},
+			removed: []string{labels.TriageDuplicate},
+			labels:  []string{labels.NeedsTriage, labels.TriageDuplicate},
+		},
+		{
+			name:    "remove duplicate, don't have it -> no-op",
+			body:    "/remove-triage duplicate",
+			added:   []string{},
+			removed: []string{},
+			labels:  []string{labels.NeedsTriage},
+		},
+		{
+			name:     "not a collaborator -> comment",
+			body:     "/triage accepted",
+			author:   "random",
+			added:    []string{},
+			removed:  []string{},
+			labels:   []string{labels.NeedsTriage},
+			comments: 1,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fakeClient{
+				labels:  tc.labels,
+				added:   []string{},
+				removed: []string{},
+			}
+			author := tc.author
+			if author == "" {
+				author = "collab"
+			}
+			e := &scmprovider.GenericCommentEvent{
+				Body:   tc.body,
+				Action: scm.ActionCreate,
+				Author: scm.User{Login: author},
+			}
+			cmd := plugin.Commands[0]
+			matches, err := cmd.FilterAndGetMatches(e)
+			if err != nil {
+				t.Fatalf("(%s): Unexpected error from handle: %v.", tc.name, err)
+			}
+			for _, m := range matches {
+				if err := handleOne(m.Prefix != "", "triage/"+m.Arg, fc, logrus.WithField("plugin", pluginName), e); err != nil {
+					t.Fatalf("For case %s, didn't expect error from label test: %v", tc.name, err)
+				}
+			}
+			switch {
+			case !reflect.DeepEqual(tc.added, fc.added):
+				t.Errorf("%s: added %v != actual %v", tc.name, tc.added, fc.added)
+			case !reflect.DeepEqual(tc.removed, fc.removed):
+				t.Errorf("%s: removed %v != actual %v", tc.name, tc.removed, fc.removed)
+			case len(fc.comments) != tc.comments:
+				t.Errorf("%s: expected %d comments, got %v", tc.name, tc.comments, fc.comments)
+			}
+		})
+	}
+}
+
+func TestLabelNew(t *testing.T) {
+	var testcases = []struct {
+		name   string
+		labels []string
+		added  []string
+	}{
+		{
+			name:  "new issue -> needs-triage added",
+			added: []string{labels.NeedsTriage},
+		},
+		{
+			name:   "new accepted issue -> no-op",
+			labels: []string{labels.TriageAccepted},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fakeClient{}
+			issue := &scm.Issue{Number: 1, Labels: tc.labels}
+			if err := labelNew(fc, logrus.WithField("plugin", pluginName), "org", "repo", issue); err != nil {
+				t.Fatalf("For case %s, didn't expect error: %v", tc.name, err)
+			}
+			if !reflect.DeepEqual(tc.added, fc.added) {
+				t.Errorf("%s: added %v != actual %v", tc.name, tc.added, fc.added)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package trigger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+)
+
+type explainTrustClient interface {
+	trustedUserClient
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+}
+
+// ExplainTrust explains whether the jobs of the pull request are triggered automatically given its author, in the
+// same way as TrustedPullRequest
+func ExplainTrust(spc explainTrustClient, trigger *plugins.Trigger, author, org, repo string, num int) (bool, string, error) {
+	trusted, err := TrustedUser(spc, trigger, author, org, repo)
+	if err != nil {
+		return false, "", fmt.Errorf("error checking %s for trust: %v", author, err)
+	}
+	if trusted {
+		return true, fmt.Sprintf("the author %q is trusted: trusted users are the bot and %s", author, trustedUsers(trigger, org, repo)), nil
+	}
+	l, err := spc.GetIssueLabels(org, repo, num, true)
+	if err != nil {
+		return false, "", err
+	}
+	if scmprovider.HasLabel(labels.OkToTest, l) {
+		return true, fmt.Sprintf("the author %q is not trusted but the pull request has the %s label", author, labels.OkToTest), nil
+	}
+	return false, fmt.Sprintf("the author %q is not trusted and the pull request does not have the %s label: trusted users are the bot and %s, who can comment /ok-to-test to trust the pull request or /test to run its jobs once", author, labels.OkToTest, trustedUsers(trigger, org, repo)), nil
+}
+
+func trustedUsers(trigger *plugins.Trigger, org, repo string) string {
+	var answer []string
+	if !trigger.OnlyOrgMembers {
+		answer = append(answer, fmt.Sprintf("the collaborators of %s", scm.Join(org, repo)))
+	}
+	answer = append(answer, fmt.Sprintf("the members of the %s organization", org))
+	if trigger.TrustedOrg != "" && trigger.TrustedOrg != org {
+		answer = append(answer, fmt.Sprintf("the members of the %s organization", trigger.TrustedOrg))
+	}
+	if len(trigger.TrustedApps) > 0 {
+		answer = append(answer, fmt.Sprintf("the apps %s", strings.Join(trigger.TrustedApps, ", ")))
+	}
+	return strings.Join(answer, ", ")
+}
+
+// ExplainPresubmit explains whether the presubmit is triggered automatically for a pull request against the branch
+// with the given changes, in the same way as the presubmits are filtered when all the jobs of a pull request are
+// built
+func ExplainPresubmit(p job.Presubmit, branch string, changes job.ChangedFilesProvider) (bool, string, error) {
+	if !p.CouldRun(branch) {
+		return false, fmt.Sprintf("it does not run against the %s branch (branches: %v, skip_branches: %v)", branch, p.Branches, p.SkipBranches), nil
+	}
+	if p.AlwaysRun {
+		return true, "always_run is true", nil
+	}
+	if !p.RegexpChangeMatcher.CouldRun() {
+		return false, fmt.Sprintf("neither always_run nor run_if_changed nor skip_if_only_changed is set, it only runs when requested with %s", p.RerunCommand), nil
+	}
+	_, run, err := p.RegexpChangeMatcher.ShouldRun(changes)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to list the changed files: %v", err)
+	}
+	switch {
+	case p.RunIfChanged != "" && run:
+		return true, fmt.Sprintf("run_if_changed %q matches a changed file", p.RunIfChanged), nil
+	case p.RunIfChanged != "":
+		return false, fmt.Sprintf("run_if_changed %q matches none of the changed files", p.RunIfChanged), nil
+	case run:
+		return true, fmt.Sprintf("skip_if_only_changed %q does not match every changed file", p.SkipIfOnlyChanged), nil
+	default:
+		return false, fmt.Sprintf("skip_if_only_changed %q matches every changed file", p.SkipIfOnlyChanged), nil
+	}
+}
+
+// PresubmitsMessage returns the message listing the presubmits which could run for a pull request against the branch
+// with the given changes, whether they are required and whether they are triggered automatically, in the same way
+// as ExplainPresubmit
+func PresubmitsMessage(org, repo, branch string, presubmits []job.Presubmit, changes job.ChangedFilesProvider) (string, error) {
+	var rows []string
+	for _, p := range presubmits {
+		if !p.CouldRun(branch) {
+			continue
+		}
+		run, reason, err := ExplainPresubmit(p, branch, changes)
+		if err != nil {
+			return "", err
+		}
+		required := "optional"
+		if p.ContextRequired() {
+			required = "required"
+		}
+		status := "runs automatically"
+		switch {
+		case run:
+		case p.RegexpChangeMatcher.CouldRun():
+			status = "skipped"
+		default:
+			status = "on demand"
+		}
+		rows = append(rows, fmt.Sprintf("| `%s` | `%s` | %s | %s | %s |", p.Name, p.RerunCommand, required, status, strings.ReplaceAll(reason, "|", `\|`)))
+	}
+	if len(rows) == 0 {
+		return fmt.Sprintf("No presubmit jobs available for %s/%s@%s", org, repo, branch), nil
+	}
+	sort.Strings(rows)
+	return fmt.Sprintf("The following jobs are configured for this pull request against %s/%s@%s:\n\n| Job | Command | Required | Status | Reason |\n|---|---|---|---|---|\n%s",
+		org, repo, branch, strings.Join(rows, "\n")), nil
+}
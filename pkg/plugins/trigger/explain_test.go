@@ -0,0 +1,87 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	fake2 "github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainTrust(t *testing.T) {
+	g := &fake2.SCMClient{
+		OrgMembers:    map[string][]string{"org": {"member"}},
+		Collaborators: []string{"friend"},
+	}
+	trigger := &plugins.Trigger{}
+
+	trusted, message, err := ExplainTrust(g, trigger, "member", "org", "repo", 1)
+	require.NoError(t, err)
+	assert.True(t, trusted)
+	assert.Equal(t, `the author "member" is trusted: trusted users are the bot and the collaborators of org/repo, the members of the org organization`, message)
+
+	trusted, message, err = ExplainTrust(g, trigger, "stranger", "org", "repo", 1)
+	require.NoError(t, err)
+	assert.False(t, trusted)
+	assert.Contains(t, message, `the author "stranger" is not trusted and the pull request does not have the ok-to-test label`)
+
+	g.PullRequestLabelsExisting = []string{"org/repo#1:ok-to-test"}
+	trusted, message, err = ExplainTrust(g, trigger, "stranger", "org", "repo", 1)
+	require.NoError(t, err)
+	assert.True(t, trusted)
+	assert.Equal(t, `the author "stranger" is not trusted but the pull request has the ok-to-test label`, message)
+}
+
+func TestExplainPresubmit(t *testing.T) {
+	presubmits := []job.Presubmit{
+		{Base: job.Base{Name: "lint"}, AlwaysRun: true},
+		{Base: job.Base{Name: "docs"}, RegexpChangeMatcher: job.RegexpChangeMatcher{RunIfChanged: "^docs/"}},
+		{Base: job.Base{Name: "code"}, RegexpChangeMatcher: job.RegexpChangeMatcher{SkipIfOnlyChanged: "^docs/"}},
+		{Base: job.Base{Name: "e2e"}, Reporter: job.Reporter{Context: "e2e"}, RerunCommand: "/test e2e"},
+		{Base: job.Base{Name: "release"}, AlwaysRun: true, Brancher: job.Brancher{Branches: []string{"release"}}},
+	}
+	changes := func() ([]string, error) { return []string{"docs/README.md"}, nil }
+
+	expected := map[string]string{
+		"lint":    "always_run is true",
+		"docs":    `run_if_changed "^docs/" matches a changed file`,
+		"code":    `skip_if_only_changed "^docs/" matches every changed file`,
+		"e2e":     "neither always_run nor run_if_changed nor skip_if_only_changed is set, it only runs when requested with /test e2e",
+		"release": "it does not run against the master branch (branches: [release], skip_branches: [])",
+	}
+	for _, p := range presubmits {
+		require.NoError(t, p.SetRegexes())
+		_, reason, err := ExplainPresubmit(p, "master", changes)
+		require.NoError(t, err)
+		assert.Equal(t, expected[p.Name], reason, p.Name)
+	}
+}
+
+func TestPresubmitsMessage(t *testing.T) {
+	presubmits := []job.Presubmit{
+		{Base: job.Base{Name: "lint"}, AlwaysRun: true, RerunCommand: "/test lint"},
+		{Base: job.Base{Name: "docs"}, RegexpChangeMatcher: job.RegexpChangeMatcher{RunIfChanged: "^(docs|site)/"}, Reporter: job.Reporter{SkipReport: true}, RerunCommand: "/test docs"},
+		{Base: job.Base{Name: "e2e"}, Optional: true, RerunCommand: "/test e2e"},
+		{Base: job.Base{Name: "release"}, AlwaysRun: true, Brancher: job.Brancher{Branches: []string{"release"}}, RerunCommand: "/test release"},
+	}
+	for i := range presubmits {
+		require.NoError(t, presubmits[i].SetRegexes())
+	}
+	changes := func() ([]string, error) { return []string{"main.go"}, nil }
+
+	message, err := PresubmitsMessage("org", "repo", "master", presubmits, changes)
+	require.NoError(t, err)
+	expected := "The following jobs are configured for this pull request against org/repo@master:\n\n" +
+		"| Job | Command | Required | Status | Reason |\n" +
+		"|---|---|---|---|---|\n" +
+		"| `docs` | `/test docs` | optional | skipped | run_if_changed \"^(docs\\|site)/\" matches none of the changed files |\n" +
+		"| `e2e` | `/test e2e` | optional | on demand | neither always_run nor run_if_changed nor skip_if_only_changed is set, it only runs when requested with /test e2e |\n" +
+		"| `lint` | `/test lint` | required | runs automatically | always_run is true |"
+	assert.Equal(t, expected, message)
+
+	message, err = PresubmitsMessage("org", "repo", "master", presubmits[3:], changes)
+	require.NoError(t, err)
+	assert.Equal(t, "No presubmit jobs available for org/repo@master", message)
+}
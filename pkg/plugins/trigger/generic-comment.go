@@ -18,6 +18,7 @@ package trigger
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
@@ -51,6 +52,11 @@ func handleGenericComment(c Client, trigger *plugins.Trigger, gc scmprovider.Gen
 		return err
 	}
 
+	// Listing the jobs does not trigger anything, anyone can do it.
+	if jobutil.TestListRe.MatchString(gc.Body) {
+		return addListComment(c, gc, pr)
+	}
+
 	// Skip untrusted users comments.
 	trusted, err := TrustedUser(c.SCMProviderClient, trigger, commentAuthor, org, repo)
 	if err != nil {
@@ -91,11 +97,87 @@ func handleGenericComment(c Client, trigger *plugins.Trigger, gc scmprovider.Gen
 		}
 	}
 
-	toTest, toSkip, err := FilterPresubmits(HonorOkToTest(trigger), c.SCMProviderClient, gc.Body, pr, c.Config.GetPresubmits(gc.Repo), c.Logger)
+	presubmits := c.Config.GetPresubmits(gc.Repo)
+	toTest, toSkip, err := FilterPresubmits(HonorOkToTest(trigger), c.SCMProviderClient, gc.Body, pr, presubmits, c.Logger)
+	if err != nil {
+		return err
+	}
+	// Reply with the available jobs if the user didn't specify a /test target, or none of them exist.
+	if needsHelp, note := jobutil.ShouldRespondWithHelp(gc.Body, len(toTest)+len(toSkip)); needsHelp {
+		return addHelpComment(c, gc, pr, presubmits, note)
+	}
+	parameters, err := commandParameters(gc.Body, toTest)
+	if err != nil {
+		resp := fmt.Sprintf("Cannot trigger testing: %v", err)
+		c.Logger.Infof("Commenting \"%s\".", resp)
+		return c.SCMProviderClient.CreateComment(org, repo, number, true, plugins.FormatResponseRaw(gc.Body, gc.Link, c.SCMProviderClient.QuoteAuthorForComment(gc.Author.Login), resp))
+	}
+	// the jobs requested by a comment are run rather than reported from the cache, so that they can be forced to re-run
+	for i := range toTest {
+		toTest[i].CacheResults = false
+	}
+	if err := RunAndSkipJobs(c, pr, toTest, toSkip, gc.GUID, trigger.ElideSkippedContexts, parameters); err != nil {
+		return err
+	}
+	if jobutil.RetestRequiredRe.MatchString(gc.Body) {
+		return addRetestRequiredComment(c, gc, pr, toTest)
+	}
+	return nil
+}
+
+// commandParameters returns the parameters given to the jobs to run by the comment body, keyed by job name.
+func commandParameters(body string, toTest []job.Presubmit) (map[string]map[string]string, error) {
+	parameters := map[string]map[string]string{}
+	for _, presubmit := range toTest {
+		jobParameters, err := jobutil.CommandParameters(body, presubmit)
+		if err != nil {
+			return nil, err
+		}
+		if len(jobParameters) > 0 {
+			parameters[presubmit.Name] = jobParameters
+		}
+	}
+	return parameters, nil
+}
+
+func addRetestRequiredComment(c Client, gc scmprovider.GenericCommentEvent, pr *scm.PullRequest, toTest []job.Presubmit) error {
+	resp := "There are no failed required jobs to rerun."
+	if len(toTest) > 0 {
+		names := sets.NewString()
+		for _, presubmit := range toTest {
+			names.Insert(presubmit.Name)
+		}
+		var list strings.Builder
+		for _, name := range names.List() {
+			list.WriteString(fmt.Sprintf("\n* `%s`", name))
+		}
+		resp = fmt.Sprintf("Rerunning the failed required jobs:%s", list.String())
+	}
+	c.Logger.Infof("Commenting \"%s\".", resp)
+	return c.SCMProviderClient.CreateComment(gc.Repo.Namespace, gc.Repo.Name, pr.Number, true, plugins.FormatResponseRaw(gc.Body, gc.Link, c.SCMProviderClient.QuoteAuthorForComment(gc.Author.Login), resp))
+}
+
+func addHelpComment(c Client, gc scmprovider.GenericCommentEvent, pr *scm.PullRequest, presubmits []job.Presubmit, note string) error {
+	org, repo, number, branch := gc.Repo.Namespace, gc.Repo.Name, pr.Number, pr.Base.Ref
+	changes := job.NewGitHubDeferredChangedFilesProvider(c.SCMProviderClient, org, repo, number)
+	testAllNames, optionalCommands, requiredCommands, err := jobutil.AvailablePresubmits(changes, branch, presubmits, c.Logger)
+	if err != nil {
+		return err
+	}
+	resp := jobutil.HelpMessage(org, repo, branch, note, testAllNames, optionalCommands, requiredCommands)
+	c.Logger.Infof("Commenting \"%s\".", resp)
+	return c.SCMProviderClient.CreateComment(org, repo, number, true, plugins.FormatResponseRaw(gc.Body, gc.Link, c.SCMProviderClient.QuoteAuthorForComment(gc.Author.Login), resp))
+}
+
+func addListComment(c Client, gc scmprovider.GenericCommentEvent, pr *scm.PullRequest) error {
+	org, repo, number := gc.Repo.Namespace, gc.Repo.Name, pr.Number
+	changes := job.NewGitHubDeferredChangedFilesProvider(c.SCMProviderClient, org, repo, number)
+	resp, err := PresubmitsMessage(org, repo, pr.Base.Ref, c.Config.GetPresubmits(gc.Repo), changes)
 	if err != nil {
 		return err
 	}
-	return RunAndSkipJobs(c, pr, toTest, toSkip, gc.GUID, trigger.ElideSkippedContexts)
+	c.Logger.Infof("Commenting \"%s\".", resp)
+	return c.SCMProviderClient.CreateComment(org, repo, number, true, plugins.FormatResponseRaw(gc.Body, gc.Link, c.SCMProviderClient.QuoteAuthorForComment(gc.Author.Login), resp))
 }
 
 // HonorOkToTest checks if shoudn't ignore the ok test
@@ -121,6 +203,8 @@ type SCMProviderClient interface {
 //  - if we got a /test all or an /ok-to-test, we want to consider any job
 //    that doesn't explicitly require a human trigger comment; jobs will
 //    default to not run unless we can determine that they should
+//  - if we got a /retest-required, we only want to consider those required
+//    jobs that have already run and posted failing contexts to the PR
 // If a comment that we get matches more than one of the above patterns, we
 // consider the set of matching presubmits the union of the results from the
 // matching cases.
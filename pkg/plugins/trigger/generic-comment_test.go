@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/jenkins-x/go-scm/scm"
@@ -61,6 +62,8 @@ type testcase struct {
 	IssueLabels          []string
 	IgnoreOkToTest       bool
 	ElideSkippedContexts bool
+	CommentContains      []string
+	ExpectedParameters   map[string]string
 }
 
 func TestHandleGenericComment(t *testing.T) {
@@ -810,6 +813,216 @@ func TestHandleGenericComment(t *testing.T) {
 			ElideSkippedContexts: true,
 			ShouldReport:         false,
 		},
+		{
+			name:   "/test all of skip_if_only_changed job with only skipped changes",
+			Author: "trusted-member",
+			Body:   "/test all",
+			State:  "open",
+			IsPR:   true,
+			Presubmits: map[string][]job.Presubmit{
+				"org/repo": {
+					{
+						Base: job.Base{
+							Name: "jub",
+						},
+						RegexpChangeMatcher: job.RegexpChangeMatcher{
+							SkipIfOnlyChanged: "^CHANGED$",
+						},
+						Reporter: job.Reporter{
+							Context: "pull-jub",
+						},
+						Trigger:      `(?m)^/test (?:.*? )?jub(?: .*?)?$`,
+						RerunCommand: `/test jub`,
+					},
+				},
+			},
+			ShouldReport: true,
+		},
+		{
+			name:   "/test all of skip_if_only_changed job with other changes",
+			Author: "trusted-member",
+			Body:   "/test all",
+			State:  "open",
+			IsPR:   true,
+			Presubmits: map[string][]job.Presubmit{
+				"org/repo": {
+					{
+						Base: job.Base{
+							Name: "jub",
+						},
+						RegexpChangeMatcher: job.RegexpChangeMatcher{
+							SkipIfOnlyChanged: `^docs/`,
+						},
+						Reporter: job.Reporter{
+							Context: "pull-jub",
+						},
+						Trigger:      `(?m)^/test (?:.*? )?jub(?: .*?)?$`,
+						RerunCommand: `/test jub`,
+					},
+				},
+			},
+			ShouldBuild:   true,
+			StartsExactly: "pull-jub",
+		},
+		{
+			name:        "/test without a target lists the available jobs",
+			Author:      "trusted-member",
+			Body:        "/test",
+			State:       "open",
+			IsPR:        true,
+			ShouldBuild: false,
+			CommentContains: []string{
+				"The `/test` command needs one or more targets.",
+				"The following commands are available to trigger required jobs:\n* `/test jib`\n* `/test job`",
+				"Use `/test all` to run the following jobs that were automatically triggered:\n* `job`",
+			},
+		},
+		{
+			name:        "/test ? lists the jobs of the PR",
+			Author:      "untrusted-member",
+			PRAuthor:    "untrusted-member",
+			Body:        "/test ?",
+			State:       "open",
+			IsPR:        true,
+			ShouldBuild: false,
+			CommentContains: []string{
+				"| `jib` | `/test jib` | required | on demand |",
+				"| `job` | `/test job` | required | runs automatically | always_run is true |",
+			},
+		},
+		{
+			name:        "/test of an unknown job lists the available jobs",
+			Author:      "trusted-member",
+			Body:        "/test unknown",
+			State:       "open",
+			IsPR:        true,
+			ShouldBuild: false,
+			CommentContains: []string{
+				jobutil.TargetNotFoundNote,
+				"* `/test jib`",
+			},
+		},
+		{
+			name:        "/test of an optional job lists it as optional",
+			Author:      "trusted-member",
+			Body:        "/test unknown",
+			State:       "open",
+			IsPR:        true,
+			ShouldBuild: false,
+			Presubmits: map[string][]job.Presubmit{
+				"org/repo": {
+					{
+						Base: job.Base{
+							Name: "jab",
+						},
+						Optional: true,
+						Reporter: job.Reporter{
+							Context: "pull-jab",
+						},
+						Trigger:      `(?m)^/test (?:.*? )?jab(?: .*?)?$`,
+						RerunCommand: `/test jab`,
+					},
+				},
+			},
+			CommentContains: []string{
+				"The following commands are available to trigger optional jobs:\n* `/test jab`",
+			},
+		},
+		{
+			name:          "/retest-required of failed required job",
+			Author:        "trusted-member",
+			Body:          "/retest-required",
+			State:         "open",
+			IsPR:          true,
+			ShouldBuild:   true,
+			StartsExactly: "pull-jib",
+			CommentContains: []string{
+				"Rerunning the failed required jobs:\n* `jib`",
+			},
+		},
+		{
+			name:        "/retest-required ignores failed optional jobs",
+			Author:      "trusted-member",
+			Body:        "/retest-required",
+			State:       "open",
+			IsPR:        true,
+			ShouldBuild: false,
+			Presubmits: map[string][]job.Presubmit{
+				"org/repo": {
+					{
+						Base: job.Base{
+							Name: "jib",
+						},
+						Optional: true,
+						Reporter: job.Reporter{
+							Context: "pull-jib",
+						},
+						Trigger:      `(?m)^/test (?:.*? )?jib(?: .*?)?$`,
+						RerunCommand: `/test jib`,
+					},
+				},
+			},
+			CommentContains: []string{
+				"There are no failed required jobs to rerun.",
+			},
+		},
+		{
+			name:   "/test with parameters",
+			Author: "trusted-member",
+			Body:   "/test jib --provider=gke",
+			State:  "open",
+			IsPR:   true,
+			Presubmits: map[string][]job.Presubmit{
+				"org/repo": {
+					{
+						Base: job.Base{
+							Name: "jib",
+						},
+						Reporter: job.Reporter{
+							Context: "pull-jib",
+						},
+						Trigger:      `(?m)^/test (?:.*? )?jib(?: .*?)?$`,
+						RerunCommand: `/test jib`,
+						CommandParameters: []job.CommandParameter{
+							{Name: "provider", Pattern: "gke|eks"},
+						},
+					},
+				},
+			},
+			ShouldBuild:   true,
+			StartsExactly: "pull-jib",
+			ExpectedParameters: map[string]string{
+				"provider": "gke",
+			},
+		},
+		{
+			name:   "/test with an unknown parameter",
+			Author: "trusted-member",
+			Body:   "/test jib --cluster=foo",
+			State:  "open",
+			IsPR:   true,
+			Presubmits: map[string][]job.Presubmit{
+				"org/repo": {
+					{
+						Base: job.Base{
+							Name: "jib",
+						},
+						Reporter: job.Reporter{
+							Context: "pull-jib",
+						},
+						Trigger:      `(?m)^/test (?:.*? )?jib(?: .*?)?$`,
+						RerunCommand: `/test jib`,
+						CommandParameters: []job.CommandParameter{
+							{Name: "provider", Pattern: "gke|eks"},
+						},
+					},
+				},
+			},
+			ShouldBuild: false,
+			CommentContains: []string{
+				"Cannot trigger testing: unknown parameter `cluster` for job jib, the allowed parameters are `provider`",
+			},
+		},
 		{
 			name:        "accept /test all from trusted user",
 			Author:      "trusted-member",
@@ -830,6 +1043,26 @@ func TestHandleGenericComment(t *testing.T) {
 			IssueLabels: issueLabels(labels.LGTM, labels.Approved),
 		},
 	}
+	testcases = append(testcases, testcase{
+		name:          "Retest of a job caching its results runs it rather than reporting it from the cache",
+		Author:        "trusted-member",
+		Body:          "/retest",
+		State:         "open",
+		IsPR:          true,
+		ShouldBuild:   true,
+		StartsExactly: "pull-jib",
+		Presubmits: map[string][]job.Presubmit{
+			"org/repo": {
+				{
+					Base:         job.Base{Name: "jib"},
+					Reporter:     job.Reporter{Context: "pull-jib"},
+					Trigger:      `(?m)^/test (?:.*? )?jib(?: .*?)?$`,
+					RerunCommand: `/test jib`,
+					CacheResults: true,
+				},
+			},
+		},
+	})
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
 			if tc.Branch == "" {
@@ -857,6 +1090,7 @@ func TestHandleGenericComment(t *testing.T) {
 					},
 				},
 				PullRequestChanges: map[int][]*scm.Change{0: {{Path: "CHANGED"}}},
+				Commits:            map[string]*scm.Commit{"cafe": {Sha: "cafe", Tree: scm.CommitTree{Sha: "tree"}}},
 				CombinedStatuses: map[string]*scm.CombinedStatus{
 					"cafe": {
 						Statuses: []*scm.Status{
@@ -963,6 +1197,11 @@ func validate(name string, fakeLauncher *fake.Launcher, g *fake2.SCMClient, tc t
 	} else if len(startedContexts) == 0 && tc.ShouldBuild {
 		t.Errorf("Not built but should have: %+v", tc)
 	}
+	for _, pj := range fakeLauncher.Pipelines {
+		if tree := pj.Labels[job.TreeHashLabel]; tree != "" {
+			t.Errorf("%s: job %s requested by a comment may be reported from the cache of tree %s", name, pj.Spec.Job, tree)
+		}
+	}
 	if tc.StartsExactly != "" && (startedContexts.Len() != 1 || !startedContexts.Has(tc.StartsExactly)) {
 		t.Errorf("%s:Didn't build expected context %v, instead built %v", name, tc.StartsExactly, startedContexts)
 	}
@@ -986,6 +1225,19 @@ func validate(name string, fakeLauncher *fake.Launcher, g *fake2.SCMClient, tc t
 	if !reflect.DeepEqual(labelsRemoved, tc.RemovedLabels) {
 		t.Errorf("%s: expected %q to be removed, got %q", name, tc.RemovedLabels, labelsRemoved)
 	}
+	if tc.ExpectedParameters != nil {
+		for _, job := range fakeLauncher.Pipelines {
+			if !reflect.DeepEqual(job.Spec.Parameters, tc.ExpectedParameters) {
+				t.Errorf("%s: expected parameters %v for job %s, got %v", name, tc.ExpectedParameters, job.Spec.Job, job.Spec.Parameters)
+			}
+		}
+	}
+	comments := strings.Join(g.PullRequestCommentsAdded, "\n")
+	for _, expected := range tc.CommentContains {
+		if !strings.Contains(comments, expected) {
+			t.Errorf("%s: expected a comment containing %q, got %q", name, expected, g.PullRequestCommentsAdded)
+		}
+	}
 }
 
 func TestRetestFilter(t *testing.T) {
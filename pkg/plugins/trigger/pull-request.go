@@ -83,6 +83,9 @@ func handlePR(c Client, trigger *plugins.Trigger, pr scm.PullRequestHook) error
 	case scm.ActionSync:
 		return buildAllIfTrusted(c, trigger, pr)
 	case scm.ActionLabel:
+		if pr.Label.Name == labels.OkToTest {
+			return buildOkToTest(c, trigger, pr)
+		}
 		// When a PR is LGTMd, if it is untrusted then build it once.
 		if pr.Label.Name == labels.LGTM {
 			_, trusted, err := TrustedPullRequest(c.SCMProviderClient, trigger, author, org, repo, num, nil)
@@ -132,6 +135,47 @@ func buildAllIfTrusted(c Client, trigger *plugins.Trigger, pr scm.PullRequestHoo
 	return nil
 }
 
+// buildOkToTest builds a PR a trusted user added the ok-to-test label to rather than commenting /ok-to-test
+func buildOkToTest(c Client, trigger *plugins.Trigger, pr scm.PullRequestHook) error {
+	if trigger.IgnoreOkToTest {
+		return nil
+	}
+	org, repo, _ := orgRepoAuthor(pr.PullRequest)
+	num := pr.PullRequest.Number
+	sender := pr.Sender.Login
+	if sender == "" {
+		c.Logger.Infof("Unknown sender, ignoring the %s label.", labels.OkToTest)
+		return nil
+	}
+	// the jobs were already started by the /ok-to-test comment the bot added the label for
+	botName, err := c.SCMProviderClient.BotName()
+	if err != nil {
+		return err
+	}
+	if sender == botName {
+		return nil
+	}
+	trusted, err := TrustedUser(c.SCMProviderClient, trigger, sender, org, repo)
+	if err != nil {
+		return fmt.Errorf("could not check membership: %s", err)
+	}
+	if !trusted {
+		c.Logger.Infof("User %q is not trusted, ignoring the %s label they added.", sender, labels.OkToTest)
+		return nil
+	}
+	l, err := c.SCMProviderClient.GetIssueLabels(org, repo, num, true)
+	if err != nil {
+		return err
+	}
+	if scmprovider.HasLabel(labels.NeedsOkToTest, l) {
+		if err := c.SCMProviderClient.RemoveLabel(org, repo, num, labels.NeedsOkToTest, true); err != nil {
+			return err
+		}
+	}
+	c.Logger.Infof("Starting all jobs for PR labeled %s.", labels.OkToTest)
+	return buildAll(c, &pr.PullRequest, pr.GUID, trigger.ElideSkippedContexts)
+}
+
 func welcomeMsg(spc scmProviderClient, trigger *plugins.Trigger, pr scm.PullRequest) error {
 	var errors []error
 	org, repo, a := orgRepoAuthor(pr)
@@ -219,5 +263,5 @@ func buildAll(c Client, pr *scm.PullRequest, eventGUID string, elideSkippedConte
 	if err != nil {
 		return err
 	}
-	return RunAndSkipJobs(c, pr, toTest, toSkip, eventGUID, elideSkippedContexts)
+	return RunAndSkipJobs(c, pr, toTest, toSkip, eventGUID, elideSkippedContexts, nil)
 }
@@ -34,6 +34,7 @@ func TestTrusted(t *testing.T) {
 	const member = "org-member"
 	const sister = "trusted-org-member"
 	const friend = "repo-collaborator"
+	const app = "dependabot[bot]"
 
 	var testcases = []struct {
 		name     string
@@ -78,6 +79,18 @@ func TestTrusted(t *testing.T) {
 			labels:   []string{},
 			expected: false,
 		},
+		{
+			name:     "trust trusted app",
+			author:   app,
+			labels:   []string{},
+			expected: true,
+		},
+		{
+			name:     "reject user named after trusted app",
+			author:   "dependabot",
+			labels:   []string{},
+			expected: false,
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -89,6 +102,7 @@ func TestTrusted(t *testing.T) {
 			trigger := &plugins.Trigger{
 				TrustedOrg:     "kubernetes",
 				OnlyOrgMembers: tc.onlyOrg,
+				TrustedApps:    []string{app},
 			}
 			var labels []*scm.Label
 			for _, label := range tc.labels {
@@ -118,6 +132,7 @@ func TestHandlePullRequest(t *testing.T) {
 		prLabel       string
 		prChanges     bool
 		prAction      scm.Action
+		Sender        string
 	}{
 		{
 			name: "Trusted user open PR should build",
@@ -249,6 +264,41 @@ func TestHandlePullRequest(t *testing.T) {
 			prAction:    scm.ActionLabel,
 			prLabel:     "test",
 		},
+		{
+			name: "Untrusted user PR labeled with ok-to-test by a trusted user should build",
+
+			Author:      "u",
+			ShouldBuild: true,
+			prAction:    scm.ActionLabel,
+			prLabel:     labels.OkToTest,
+			Sender:      "t",
+		},
+		{
+			name: "Untrusted user PR labeled with ok-to-test by an untrusted user should not build",
+
+			Author:      "u",
+			ShouldBuild: false,
+			prAction:    scm.ActionLabel,
+			prLabel:     labels.OkToTest,
+			Sender:      "u",
+		},
+		{
+			name: "Untrusted user PR labeled with ok-to-test by the bot should not build",
+
+			Author:      "u",
+			ShouldBuild: false,
+			prAction:    scm.ActionLabel,
+			prLabel:     labels.OkToTest,
+			Sender:      fake2.Bot,
+		},
+		{
+			name: "Untrusted user PR labeled with ok-to-test by an unknown sender should not build",
+
+			Author:      "u",
+			ShouldBuild: false,
+			prAction:    scm.ActionLabel,
+			prLabel:     labels.OkToTest,
+		},
 		{
 			name: "Trusted user closed PR should not build",
 
@@ -305,6 +355,7 @@ func TestHandlePullRequest(t *testing.T) {
 		pr := scm.PullRequestHook{
 			Action: tc.prAction,
 			Label:  scm.Label{Name: tc.prLabel},
+			Sender: scm.User{Login: tc.Sender},
 			PullRequest: scm.PullRequest{
 				Number: 0,
 				Author: scm.User{Login: tc.Author},
@@ -17,6 +17,9 @@ limitations under the License.
 package trigger
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -39,9 +42,9 @@ const (
 
 var (
 	plugin = plugins.Plugin{
-		Description: `The trigger plugin starts tests in reaction to commands and pull request events. It is responsible for ensuring that test jobs are only run on trusted PRs. A PR is considered trusted if the author is a member of the 'trusted organization' for the repository or if such a member has left an '/ok-to-test' command on the PR.
+		Description: `The trigger plugin starts tests in reaction to commands and pull request events. It is responsible for ensuring that test jobs are only run on trusted PRs. A PR is considered trusted if the author is a member of the 'trusted organization' for the repository or a trusted app, or if such a member has left an '/ok-to-test' command on the PR or added the 'ok-to-test' label to it.
 <br>Trigger starts jobs automatically when a new trusted PR is created or when an untrusted PR becomes trusted, but it can also be used to start jobs manually via the '/test' command.
-<br>The '/retest' command can be used to rerun jobs that have reported failure.`,
+<br>The '/retest' command can be used to rerun jobs that have reported failure, and the '/retest-required' command to only rerun the failed jobs required for merging.`,
 		ConfigHelpProvider: configHelp,
 		PullRequestHandler: handlePullRequest,
 		PushEventHandler:   handlePush,
@@ -55,9 +58,10 @@ var (
 		}, {
 			Name: "test",
 			Arg: &plugins.CommandArg{
-				Pattern: `[-\w]+(?:,[-\w]+)*`,
+				Pattern:  `\?|[-\w]+(?:,[-\w]+)*(?:[ \t]+--[-\w]+=\S*)*`,
+				Optional: true,
 			},
-			Description: "Manually starts a/all test job(s).",
+			Description: "Manually starts a/all test job(s), or lists the jobs of the PR with `/test ?`.",
 			Featured:    true,
 			Action: plugins.
 				Invoke(handleGenericCommentEvent).
@@ -69,6 +73,12 @@ var (
 			Action: plugins.
 				Invoke(handleGenericCommentEvent).
 				When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
+		}, {
+			Name:        "retest-required",
+			Description: "Rerun only the required test jobs that have failed.",
+			Action: plugins.
+				Invoke(handleGenericCommentEvent).
+				When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
 		}},
 	}
 )
@@ -106,6 +116,7 @@ type scmProviderClient interface {
 	IsMember(org, user string) (bool, error)
 	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
 	GetRef(org, repo, ref string) (string, error)
+	GetSingleCommit(org, repo, SHA string) (*scm.Commit, error)
 	CreateComment(owner, repo string, number int, pr bool, comment string) error
 	ListIssueComments(owner, repo string, issue int) ([]*scm.Comment, error)
 	CreateStatus(org, repo, ref string, s *scm.StatusInput) (*scm.Status, error)
@@ -170,6 +181,12 @@ func TrustedUser(spc trustedUserClient, trigger *plugins.Trigger, user, org, rep
 		logrus.Infof("User %q is the bot user", user)
 		return true, nil
 	}
+	for _, app := range trigger.TrustedApps {
+		if isApp(user, app) {
+			logrus.Infof("User %q is the trusted app %q", user, app)
+			return true, nil
+		}
+	}
 	// First check if user is a collaborator, assuming this is allowed
 	if !trigger.OnlyOrgMembers {
 		if ok, err := spc.IsCollaborator(org, repo, user); err != nil {
@@ -204,6 +221,12 @@ func TrustedUser(spc trustedUserClient, trigger *plugins.Trigger, user, org, rep
 	return member, nil
 }
 
+// isApp returns true if the user is the given app. The login of the app must be given exactly, including the "[bot]"
+// suffix of the GitHub Apps, so that a user named after the app is not trusted as the app.
+func isApp(user, app string) bool {
+	return scmprovider.NormLogin(user) == scmprovider.NormLogin(app)
+}
+
 func skippedStatusFor(context string) *scm.StatusInput {
 	return &scm.StatusInput{
 		State: scm.StateSuccess,
@@ -221,13 +244,13 @@ func failedStatusForMetapipelineCreation(context string, err error) *scm.StatusI
 }
 
 // RunAndSkipJobs executes the config.Presubmits that are requested and posts skipped statuses
-// for the reporting jobs that are skipped
-func RunAndSkipJobs(c Client, pr *scm.PullRequest, requestedJobs []job.Presubmit, skippedJobs []job.Presubmit, eventGUID string, elideSkippedContexts bool) error {
+// for the reporting jobs that are skipped. The parameters given to the requested jobs are keyed by job name.
+func RunAndSkipJobs(c Client, pr *scm.PullRequest, requestedJobs []job.Presubmit, skippedJobs []job.Presubmit, eventGUID string, elideSkippedContexts bool, parameters map[string]map[string]string) error {
 	if err := validateContextOverlap(requestedJobs, skippedJobs); err != nil {
 		c.Logger.WithError(err).Warn("Could not run or skip requested jobs, overlapping contexts.")
 		return err
 	}
-	runErr := runRequested(c, pr, requestedJobs, eventGUID)
+	runErr := runRequested(c, pr, requestedJobs, eventGUID, parameters)
 	var skipErr error
 	if !elideSkippedContexts {
 		skipErr = skipRequested(c, pr, skippedJobs)
@@ -254,16 +277,29 @@ func validateContextOverlap(toRun, toSkip []job.Presubmit) error {
 }
 
 // runRequested executes the config.Presubmits that are requested
-func runRequested(c Client, pr *scm.PullRequest, requestedJobs []job.Presubmit, eventGUID string) error {
+func runRequested(c Client, pr *scm.PullRequest, requestedJobs []job.Presubmit, eventGUID string, parameters map[string]map[string]string) error {
 	baseSHA, err := c.SCMProviderClient.GetRef(pr.Base.Repo.Namespace, pr.Base.Repo.Name, "heads/"+pr.Base.Ref)
 	if err != nil {
 		return err
 	}
 
+	// the tree is only needed by the jobs which cache their results
+	tree := ""
+	for _, p := range requestedJobs {
+		if p.CacheResults {
+			tree = treeHash(c, pr)
+			break
+		}
+	}
+
 	var errors []error
 	for _, job := range requestedJobs {
 		c.Logger.Infof("Starting %s build.", job.Name)
 		pj := jobutil.NewPresubmit(pr, baseSHA, job, eventGUID, c.SCMProviderClient.PRRefFmt())
+		pj.Spec.Parameters = parameters[job.Name]
+		if job.CacheResults && tree != "" {
+			labelTreeHash(&pj, tree, specHash(job))
+		}
 		c.Logger.WithFields(jobutil.LighthouseJobFields(&pj)).Info("Creating a new LighthouseJob.")
 		if _, err := c.LauncherClient.Launch(&pj); err != nil {
 			c.Logger.WithError(err).Error("Failed to create LighthouseJob.")
@@ -276,6 +312,44 @@ func runRequested(c Client, pr *scm.PullRequest, requestedJobs []job.Presubmit,
 	return errorutil.NewAggregate(errors...)
 }
 
+// treeHash returns the hash of the tree of the head commit of the pull request, empty if it cannot be determined in
+// which case the job is run rather than reported from the cache
+func treeHash(c Client, pr *scm.PullRequest) string {
+	commit, err := c.SCMProviderClient.GetSingleCommit(pr.Base.Repo.Namespace, pr.Base.Repo.Name, pr.Head.Sha)
+	if err != nil {
+		c.Logger.WithError(err).Warnf("Failed to get the tree of commit %s, not using the cached results.", pr.Head.Sha)
+		return ""
+	}
+	if commit == nil {
+		return ""
+	}
+	return commit.Tree.Sha
+}
+
+// labelTreeHash labels the job with the tree it builds and the hash of its configuration, so that the launcher
+// reports it from the cache if the same job configuration already succeeded for the same tree
+func labelTreeHash(pj *v1alpha1.LighthouseJob, tree, spec string) {
+	if spec == "" {
+		return
+	}
+	if pj.Labels == nil {
+		pj.Labels = map[string]string{}
+	}
+	pj.Labels[job.TreeHashLabel] = tree
+	pj.Labels[job.JobSpecHashLabel] = spec
+}
+
+// specHash returns the hash of the configuration of the job, truncated to fit in a label value, empty if it cannot be
+// computed in which case the job is run rather than reported from the cache
+func specHash(p job.Presubmit) string {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:40]
+}
+
 // skipRequested posts skipped statuses for the config.Presubmits that are requested
 func skipRequested(c Client, pr *scm.PullRequest, skippedJobs []job.Presubmit) error {
 	var errors []error
@@ -26,6 +26,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	fake2 "github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
 	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/diff"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -338,7 +339,7 @@ func TestRunAndSkipJobs(t *testing.T) {
 				Logger:            logrus.WithField("testcase", testCase.name),
 			}
 
-			err := RunAndSkipJobs(client, pr, testCase.requestedJobs, testCase.skippedJobs, "event-guid", testCase.elideSkippedContexts)
+			err := RunAndSkipJobs(client, pr, testCase.requestedJobs, testCase.skippedJobs, "event-guid", testCase.elideSkippedContexts, nil)
 			if err == nil && testCase.expectedErr {
 				t.Errorf("%s: expected an error but got none", testCase.name)
 			}
@@ -438,7 +439,7 @@ func TestRunRequested(t *testing.T) {
 				Logger:            logrus.WithField("testcase", testCase.name),
 			}
 
-			err := runRequested(client, pr, testCase.requestedJobs, "event-guid")
+			err := runRequested(client, pr, testCase.requestedJobs, "event-guid", nil)
 			if err == nil && testCase.expectedErr {
 				t.Errorf("%s: expected an error but got none", testCase.name)
 			}
@@ -462,6 +463,71 @@ func TestRunRequested(t *testing.T) {
 	}
 }
 
+func TestRunRequestedLabelsTreeHash(t *testing.T) {
+	pr := &scm.PullRequest{
+		Base: scm.PullRequestBranch{
+			Repo: scm.Repository{Namespace: "org", Name: "repo"},
+			Ref:  "branch",
+		},
+		Head: scm.PullRequestBranch{Sha: "foobar1"},
+	}
+	fakeSCMClient := fake2.SCMClient{
+		Commits: map[string]*scm.Commit{"foobar1": {Sha: "foobar1", Tree: scm.CommitTree{Sha: "tree1"}}},
+	}
+	fakeLauncher := fake.NewLauncher()
+	client := Client{
+		SCMProviderClient: &fakeSCMClient,
+		LauncherClient:    fakeLauncher,
+		Logger:            logrus.WithField("plugin", pluginName),
+	}
+	requestedJobs := []job.Presubmit{{
+		Base:         job.Base{Name: "cached"},
+		Reporter:     job.Reporter{Context: "cached"},
+		CacheResults: true,
+	}, {
+		Base:     job.Base{Name: "uncached"},
+		Reporter: job.Reporter{Context: "uncached"},
+	}}
+
+	if err := runRequested(client, pr, requestedJobs, "event-guid", nil); err != nil {
+		t.Fatalf("expected no error but got one: %v", err)
+	}
+	trees := map[string]string{}
+	specs := map[string]string{}
+	for _, pj := range fakeLauncher.Pipelines {
+		trees[pj.Spec.Job] = pj.Labels[job.TreeHashLabel]
+		specs[pj.Spec.Job] = pj.Labels[job.JobSpecHashLabel]
+	}
+	if expected := map[string]string{"cached": "tree1", "uncached": ""}; !reflect.DeepEqual(expected, trees) {
+		t.Errorf("expected tree hash labels %v but got %v", expected, trees)
+	}
+	if expected := map[string]string{"cached": specHash(requestedJobs[0]), "uncached": ""}; !reflect.DeepEqual(expected, specs) {
+		t.Errorf("expected job spec hash labels %v but got %v", expected, specs)
+	}
+}
+
+func TestSpecHash(t *testing.T) {
+	presubmit := func(image string) job.Presubmit {
+		return job.Presubmit{
+			Base: job.Base{
+				Name: "cached",
+				Spec: &v1.PodSpec{Containers: []v1.Container{{Image: image, Command: []string{"make", "test"}}}},
+			},
+			CacheResults: true,
+		}
+	}
+	hash := specHash(presubmit("golang:1.13"))
+	if len(hash) != 40 {
+		t.Errorf("expected a hash of 40 characters fitting in a label value but got %q", hash)
+	}
+	if other := specHash(presubmit("golang:1.13")); other != hash {
+		t.Errorf("expected the same hash for the same job configuration but got %s and %s", hash, other)
+	}
+	if other := specHash(presubmit("golang:1.14")); other == hash {
+		t.Errorf("expected another hash once the image of the job changed but got %s", other)
+	}
+}
+
 func TestValidateContextOverlap(t *testing.T) {
 	var testCases = []struct {
 		name          string
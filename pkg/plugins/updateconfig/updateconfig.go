@@ -146,35 +146,11 @@ func Update(fg FileGetter, kc corev1.ConfigMapInterface, name, namespace string,
 			continue
 		}
 
-		content, err := fg.GetFile(upd.Filename)
+		value, err := fileValue(fg, upd, logger)
 		if err != nil {
-			return fmt.Errorf("get file err: %v", err)
-		}
-		logger.WithFields(logrus.Fields{"key": upd.Key, "cmName": upd.Filename}).Debug("Populating key.")
-		value := content
-		if upd.GZIP {
-			buff := bytes.NewBuffer([]byte{})
-			// TODO: this error is wildly unlikely for anything that
-			// would actually fit in a configmap, we could just as well return
-			// the error instead of falling back to the raw content
-			z := gzip.NewWriter(buff)
-			if _, err := z.Write(content); err != nil {
-				logger.WithError(err).Error("failed to gzip content, falling back to raw")
-			} else {
-				if err := z.Close(); err != nil {
-					logger.WithError(err).Error("failed to flush gzipped content (!?), falling back to raw")
-				} else {
-					value = buff.Bytes()
-				}
-			}
-		}
-		if utf8.ValidString(string(value)) {
-			delete(cm.BinaryData, upd.Key)
-			cm.Data[upd.Key] = string(value)
-		} else {
-			delete(cm.Data, upd.Key)
-			cm.BinaryData[upd.Key] = value
+			return err
 		}
+		setValue(cm, upd.Key, value)
 	}
 
 	var updateErr error
@@ -192,6 +168,159 @@ func Update(fg FileGetter, kc corev1.ConfigMapInterface, name, namespace string,
 	return nil
 }
 
+// fileValue returns the content of the file of an update, gzipped if needed
+func fileValue(fg FileGetter, upd ConfigMapUpdate, logger *logrus.Entry) ([]byte, error) {
+	content, err := fg.GetFile(upd.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("get file err: %v", err)
+	}
+	logger.WithFields(logrus.Fields{"key": upd.Key, "cmName": upd.Filename}).Debug("Populating key.")
+	value := content
+	if upd.GZIP {
+		buff := bytes.NewBuffer([]byte{})
+		// TODO: this error is wildly unlikely for anything that
+		// would actually fit in a configmap, we could just as well return
+		// the error instead of falling back to the raw content
+		z := gzip.NewWriter(buff)
+		if _, err := z.Write(content); err != nil {
+			logger.WithError(err).Error("failed to gzip content, falling back to raw")
+		} else {
+			if err := z.Close(); err != nil {
+				logger.WithError(err).Error("failed to flush gzipped content (!?), falling back to raw")
+			} else {
+				value = buff.Bytes()
+			}
+		}
+	}
+	return value, nil
+}
+
+// setValue sets the value of the key of the configmap, as binary data if it is not valid UTF-8
+func setValue(cm *coreapi.ConfigMap, key string, value []byte) {
+	if utf8.ValidString(string(value)) {
+		delete(cm.BinaryData, key)
+		cm.Data[key] = string(value)
+	} else {
+		delete(cm.Data, key)
+		cm.BinaryData[key] = value
+	}
+}
+
+// dataSize returns the size of the keys and values of the configmap
+func dataSize(cm *coreapi.ConfigMap) int {
+	size := 0
+	for k, v := range cm.Data {
+		size += len(k) + len(v)
+	}
+	for k, v := range cm.BinaryData {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// PartitionName returns the name of a partition of a partitioned configmap, the first one having the name of the
+// configmap itself
+func PartitionName(name string, index int) string {
+	if index == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s-%d", name, index)
+}
+
+// UpdatePartitioned updates the partitioned configmap with the data from the identified files. The keys are kept in
+// the partition holding them as long as it does not exceed the given size, otherwise they are moved to the first
+// partition with enough room, or to a new partition. It returns the names of the updated partitions.
+func UpdatePartitioned(fg FileGetter, kc corev1.ConfigMapInterface, name, namespace string, updates []ConfigMapUpdate, size int, logger *logrus.Entry) ([]string, error) {
+	if size <= 0 {
+		size = plugins.DefaultConfigMapPartitionSize
+	}
+	var partitions []*coreapi.ConfigMap
+	for {
+		cm, err := kc.Get(PartitionName(name, len(partitions)), metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch current state of configmap: %v", err)
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		if cm.BinaryData == nil {
+			cm.BinaryData = map[string][]byte{}
+		}
+		partitions = append(partitions, cm)
+	}
+	existing := len(partitions)
+
+	changed := map[int]bool{}
+	for _, upd := range updates {
+		current := -1
+		for i, cm := range partitions {
+			_, inData := cm.Data[upd.Key]
+			_, inBinaryData := cm.BinaryData[upd.Key]
+			if inData || inBinaryData {
+				current = i
+				delete(cm.Data, upd.Key)
+				delete(cm.BinaryData, upd.Key)
+				changed[i] = true
+			}
+		}
+		if upd.Filename == "" {
+			logger.WithField("key", upd.Key).Debug("Deleting key.")
+			continue
+		}
+
+		value, err := fileValue(fg, upd, logger)
+		if err != nil {
+			return nil, err
+		}
+		if len(upd.Key)+len(value) > size {
+			return nil, fmt.Errorf("file %s is larger than the partitions of configmap %s", upd.Filename, name)
+		}
+		target := -1
+		if current >= 0 && dataSize(partitions[current])+len(upd.Key)+len(value) <= size {
+			target = current
+		}
+		for i := 0; target < 0 && i < len(partitions); i++ {
+			if dataSize(partitions[i])+len(upd.Key)+len(value) <= size {
+				target = i
+			}
+		}
+		if target < 0 {
+			target = len(partitions)
+			partitions = append(partitions, &coreapi.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      PartitionName(name, target),
+					Namespace: namespace,
+				},
+				Data:       map[string]string{},
+				BinaryData: map[string][]byte{},
+			})
+		}
+		setValue(partitions[target], upd.Key, value)
+		changed[target] = true
+	}
+
+	var updated []string
+	for i, cm := range partitions {
+		if !changed[i] {
+			continue
+		}
+		var err error
+		if i < existing {
+			_, err = kc.Update(cm)
+		} else {
+			_, err = kc.Create(cm)
+		}
+		if err != nil {
+			return updated, fmt.Errorf("failed to save the partition %s of configmap %s: %v", cm.Name, name, err)
+		}
+		updated = append(updated, cm.Name)
+	}
+	return updated, nil
+}
+
 // ConfigMapID is a name/namespace combination that identifies a config map
 type ConfigMapID struct {
 	Name, Namespace string
@@ -202,6 +331,8 @@ type ConfigMapID struct {
 type ConfigMapUpdate struct {
 	Key, Filename string
 	GZIP          bool
+	// Partitioned is true when the keys of the config map are spread over several partitions
+	Partitioned bool
 }
 
 // FilterChanges determines which of the changes are relevant for config updating, returning mapping of
@@ -242,17 +373,17 @@ func FilterChanges(cfg plugins.ConfigUpdater, changes []*scm.Change, log *logrus
 					oldKey := path.Base(change.PreviousPath)
 					// not setting the cmName field will cause the key to be
 					// deleted
-					toUpdate[id] = append(toUpdate[id], ConfigMapUpdate{Key: oldKey})
+					toUpdate[id] = append(toUpdate[id], ConfigMapUpdate{Key: oldKey, Partitioned: cm.Partitioned})
 				}
 			}
 			if change.Deleted {
-				toUpdate[id] = append(toUpdate[id], ConfigMapUpdate{Key: key})
+				toUpdate[id] = append(toUpdate[id], ConfigMapUpdate{Key: key, Partitioned: cm.Partitioned})
 			} else {
 				shouldGZIP := cfg.GZIP
 				if cm.GZIP != nil {
 					shouldGZIP = *cm.GZIP
 				}
-				toUpdate[id] = append(toUpdate[id], ConfigMapUpdate{Key: key, Filename: change.Path, GZIP: shouldGZIP})
+				toUpdate[id] = append(toUpdate[id], ConfigMapUpdate{Key: key, Filename: change.Path, GZIP: shouldGZIP, Partitioned: cm.Partitioned})
 			}
 		}
 	}
@@ -328,7 +459,14 @@ func handle(spc scmProviderClient, kc corev1.ConfigMapsGetter, cp commentPruner,
 				cm.Namespace = defaultNamespace
 			}
 			logger := log.WithFields(logrus.Fields{"configmap": map[string]string{"name": cm.Name, "namespace": cm.Namespace}})
-			if err := Update(&scmFileGetter{org: org, repo: repo, commit: pr.MergeSha, client: spc}, kc.ConfigMaps(cm.Namespace), cm.Name, cm.Namespace, data, logger); err != nil {
+			fg := &scmFileGetter{org: org, repo: repo, commit: pr.MergeSha, client: spc}
+			if len(data) > 0 && data[0].Partitioned {
+				partitions, err := UpdatePartitioned(fg, kc.ConfigMaps(cm.Namespace), cm.Name, cm.Namespace, data, config.PartitionSize, logger)
+				if err != nil {
+					return err
+				}
+				logger.Infof("updated the partitions %s", strings.Join(partitions, ", "))
+			} else if err := Update(fg, kc.ConfigMaps(cm.Namespace), cm.Name, cm.Namespace, data, logger); err != nil {
 				return err
 			}
 			updated = append(updated, message(cm.Name, cm.Namespace, data, indent))
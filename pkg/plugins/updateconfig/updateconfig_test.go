@@ -1386,3 +1386,68 @@ func TestUpdateConfig(t *testing.T) {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+type fakeFileGetter map[string]string
+
+func (f fakeFileGetter) GetFile(filename string) ([]byte, error) {
+	content, ok := f[filename]
+	if !ok {
+		return nil, fmt.Errorf("file %s not found", filename)
+	}
+	return []byte(content), nil
+}
+
+func TestUpdatePartitioned(t *testing.T) {
+	kc := fake.NewSimpleClientset(&coreapi.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "job-config", Namespace: defaultNamespace},
+		Data:       map[string]string{"a.yaml": "aaaa", "old.yaml": "oooo"},
+	})
+	cms := kc.CoreV1().ConfigMaps(defaultNamespace)
+	fg := fakeFileGetter{
+		"jobs/a.yaml": "aaaaaaaaaa",
+		"jobs/b.yaml": "bbbbbbbbbb",
+		"jobs/c.yaml": "cc",
+		"jobs/d.yaml": strings.Repeat("d", 30),
+	}
+	logger := logrus.WithField("plugin", pluginName)
+
+	updated, err := UpdatePartitioned(fg, cms, "job-config", defaultNamespace, []ConfigMapUpdate{
+		{Key: "old.yaml"},
+		{Key: "a.yaml", Filename: "jobs/a.yaml"},
+		{Key: "b.yaml", Filename: "jobs/b.yaml"},
+		{Key: "c.yaml", Filename: "jobs/c.yaml"},
+	}, 25, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"job-config", "job-config-1"}; !equality.Semantic.DeepEqual(expected, updated) {
+		t.Errorf("expected the updated partitions %v, got %v", expected, updated)
+	}
+	expected := map[string]map[string]string{
+		"job-config":   {"a.yaml": "aaaaaaaaaa", "c.yaml": "cc"},
+		"job-config-1": {"b.yaml": "bbbbbbbbbb"},
+	}
+	for name, data := range expected {
+		cm, err := cms.Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error getting %s: %v", name, err)
+		}
+		if !equality.Semantic.DeepEqual(data, cm.Data) {
+			t.Errorf("expected the data of %s to be %v, got %v", name, data, cm.Data)
+		}
+	}
+
+	// keys growing too large for their partition are moved
+	fg["jobs/c.yaml"] = strings.Repeat("c", 12)
+	updated, err = UpdatePartitioned(fg, cms, "job-config", defaultNamespace, []ConfigMapUpdate{{Key: "c.yaml", Filename: "jobs/c.yaml"}}, 25, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"job-config", "job-config-2"}; !equality.Semantic.DeepEqual(expected, updated) {
+		t.Errorf("expected the updated partitions %v, got %v", expected, updated)
+	}
+
+	if _, err := UpdatePartitioned(fg, cms, "job-config", defaultNamespace, []ConfigMapUpdate{{Key: "d.yaml", Filename: "jobs/d.yaml"}}, 25, logger); err == nil {
+		t.Error("expected an error for a file larger than the partitions")
+	}
+}
@@ -42,6 +42,8 @@ const (
 func createPlugin(j joker) plugins.Plugin {
 	return plugins.Plugin{
 		Description: "The yuks plugin comments with jokes in response to the `/joke` command.",
+		BestEffort:  true,
+		Fun:         true,
 		Commands: []plugins.Command{{
 			Name:        "joke",
 			Description: "Tells a joke.",
@@ -0,0 +1,178 @@
+// Package pluginscaffold generates the skeleton of a new plugin: its package with a command handler, help metadata and
+// tests, its configuration and its registration in the binaries running the plugins.
+package pluginscaffold
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// pluginsImportPath is the import path of the packages of the plugins
+	pluginsImportPath = "github.com/jenkins-x/lighthouse/pkg/plugins/"
+
+	// configFile is the file of the configuration of the plugins, relative to the root of the repository
+	configFile = "pkg/plugins/config.go"
+
+	// configurationStart starts the declaration of the configuration of the plugins
+	configurationStart = "type Configuration struct {\n"
+)
+
+var (
+	nameRe = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+	// registrationFiles are the files importing the plugins linked into a binary, relative to the root of the
+	// repository
+	registrationFiles = []string{
+		"cmd/lighthouse/plugins.go",
+		"pkg/foghorn/plugins.go",
+		"pkg/webhook/plugins.go",
+	}
+)
+
+type data struct {
+	// Name is the name of the plugin, of its package and of its command
+	Name string
+	// Type is the name of the type of the configuration of the plugin
+	Type string
+}
+
+// Generate creates the package of the plugin of the given name in the repository at the given directory, adds its
+// configuration and registers it, returning the files created or changed
+func Generate(dir, name string) ([]string, error) {
+	if !nameRe.MatchString(name) {
+		return nil, errors.Errorf("invalid plugin name %q, it must be a valid package name made of lower case letters and digits", name)
+	}
+	d := data{Name: name, Type: strings.ToUpper(name[:1]) + name[1:]}
+
+	pkgDir := filepath.Join(dir, "pkg", "plugins", name)
+	if _, err := os.Stat(pkgDir); err == nil {
+		return nil, errors.Errorf("the plugin %s already exists in %s", name, pkgDir)
+	}
+	config, err := addConfig(filepath.Join(dir, configFile), d)
+	if err != nil {
+		return nil, err
+	}
+	registrations := map[string][]byte{}
+	for _, f := range registrationFiles {
+		path := filepath.Join(dir, f)
+		registrations[path], err = addImport(path, pluginsImportPath+name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	files := map[string][]byte{}
+	for f, t := range map[string]*template.Template{name + ".go": pluginTemplate, name + "_test.go": testTemplate} {
+		src, err := render(t, d)
+		if err == nil {
+			src, err = format.Source(src)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to generate %s", f)
+		}
+		files[filepath.Join(pkgDir, f)] = src
+	}
+
+	// the changes are only written once all of them could be made
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s", pkgDir)
+	}
+	files[filepath.Join(dir, configFile)] = config
+	for path, src := range registrations {
+		files[path] = src
+	}
+	var answer []string
+	for path, src := range files {
+		if err := ioutil.WriteFile(path, src, 0644); err != nil { //nolint:gosec
+			return answer, errors.Wrapf(err, "failed to write %s", path)
+		}
+		answer = append(answer, path)
+	}
+	sort.Strings(answer)
+	return answer, nil
+}
+
+// addConfig returns the configuration of the plugins with the configuration of the new plugin added to it
+func addConfig(path string, d data) ([]byte, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+	text := string(src)
+	if strings.Contains(text, fmt.Sprintf("\ntype %s ", d.Type)) {
+		return nil, errors.Errorf("the type %s is already declared in %s", d.Type, path)
+	}
+	start := strings.Index(text, configurationStart)
+	if start < 0 {
+		return nil, errors.Errorf("no Configuration struct found in %s", path)
+	}
+	end := strings.Index(text[start:], "\n}\n")
+	if end < 0 {
+		return nil, errors.Errorf("the Configuration struct is not terminated in %s", path)
+	}
+	end += start + 1
+
+	field, err := render(configFieldTemplate, d)
+	if err != nil {
+		return nil, err
+	}
+	decl, err := render(configTypeTemplate, d)
+	if err != nil {
+		return nil, err
+	}
+	text = text[:end] + string(field) + "}\n\n" + string(decl) + text[end+2:]
+	answer, err := format.Source([]byte(text))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to format %s", path)
+	}
+	return answer, nil
+}
+
+// addImport returns the file with the blank import of the package added to its sorted imports
+func addImport(path, pkg string) ([]byte, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+	lines := strings.SplitAfter(string(src), "\n")
+	line := fmt.Sprintf("\t_ %q\n", pkg)
+	insert := -1
+	for i, l := range lines {
+		if !strings.HasPrefix(l, "\t_ \""+pluginsImportPath) {
+			continue
+		}
+		imported := strings.SplitN(l, "\"", 3)[1]
+		if imported == pkg {
+			return nil, errors.Errorf("%s already imports %s", path, pkg)
+		}
+		if imported < pkg {
+			insert = i + 1
+		} else if insert < 0 {
+			insert = i
+		}
+	}
+	if insert < 0 {
+		return nil, errors.Errorf("no plugins imported in %s", path)
+	}
+	lines = append(lines[:insert], append([]string{line}, lines[insert:]...)...)
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// render renders the template with the names of the plugin
+func render(t *template.Template, d data) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, d); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
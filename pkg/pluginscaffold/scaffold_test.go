@@ -0,0 +1,80 @@
+package pluginscaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyRepo copies the files the generator changes from the repository into a temporary directory
+func copyRepo(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "pluginscaffold")
+	require.NoError(t, err)
+	for _, f := range append([]string{configFile}, registrationFiles...) {
+		src, err := ioutil.ReadFile(filepath.Join("..", "..", f))
+		require.NoError(t, err)
+		path := filepath.Join(dir, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, src, 0644))
+	}
+	return dir
+}
+
+func TestGenerate(t *testing.T) {
+	dir := copyRepo(t)
+	defer os.RemoveAll(dir)
+
+	files, err := Generate(dir, "greet")
+	require.NoError(t, err)
+	assert.Len(t, files, 6)
+
+	fset := token.NewFileSet()
+	for _, f := range files {
+		_, err := parser.ParseFile(fset, f, nil, parser.AllErrors)
+		assert.NoError(t, err, "%s should be valid Go", f)
+	}
+	for _, f := range []string{"greet.go", "greet_test.go"} {
+		assert.FileExists(t, filepath.Join(dir, "pkg", "plugins", "greet", f))
+	}
+	config, err := ioutil.ReadFile(filepath.Join(dir, configFile))
+	require.NoError(t, err)
+	assert.Regexp(t, "\tGreet +\\[\\]Greet +`json:\"greet,omitempty\"`\n}\n", string(config))
+	assert.Contains(t, string(config), "\ntype Greet struct {\n")
+	for _, f := range registrationFiles {
+		src, err := ioutil.ReadFile(filepath.Join(dir, f))
+		require.NoError(t, err)
+		text := string(src)
+		greet := strings.Index(text, `_ "github.com/jenkins-x/lighthouse/pkg/plugins/greet"`)
+		require.True(t, greet > 0, "%s should import the plugin", f)
+		assert.True(t, strings.Index(text, "/pkg/plugins/dog\"") < greet, "%s should keep the imports sorted", f)
+		assert.True(t, strings.Index(text, "/pkg/plugins/help\"") > greet, "%s should keep the imports sorted", f)
+	}
+
+	_, err = Generate(dir, "greet")
+	assert.Error(t, err, "the plugin already exists")
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	dir := copyRepo(t)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"", "Greet", "owners-label", "1greet"} {
+		_, err := Generate(dir, name)
+		assert.Error(t, err, "%q should be rejected", name)
+	}
+	_, err := Generate(dir, "approve")
+	assert.Error(t, err, "the type of the configuration of the approve plugin is already declared")
+
+	config, err := ioutil.ReadFile(filepath.Join(dir, configFile))
+	require.NoError(t, err)
+	original, err := ioutil.ReadFile(filepath.Join("..", "..", configFile))
+	require.NoError(t, err)
+	assert.Equal(t, string(original), string(config), "nothing should be written on errors")
+}
@@ -0,0 +1,198 @@
+package pluginscaffold
+
+import "text/template"
+
+// the templates use [[ and ]] as delimiters not to clash with the composite literals of the generated code
+
+var configFieldTemplate = parse("config field", `	[[.Type]] [][[.Type]] `+"`"+`json:"[[.Name]],omitempty"`+"`"+`
+`)
+
+var configTypeTemplate = parse("config type", `// [[.Type]] is config for the [[.Name]] plugin.
+type [[.Type]] struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `+"`"+`json:"repos,omitempty"`+"`"+`
+	// Message is the reply to the /[[.Name]] command, a default message being used if empty.
+	Message string `+"`"+`json:"message,omitempty"`+"`"+`
+}
+`)
+
+var pluginTemplate = parse("plugin", `// Package [[.Name]] contains a plugin which replies to the /[[.Name]] command with the message
+// configured for the repository.
+package [[.Name]]
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	pluginName = "[[.Name]]"
+
+	defaultMessage = "Hello from the [[.Name]] plugin!"
+)
+
+var (
+	plugin = plugins.Plugin{
+		Description:        "The [[.Name]] plugin replies to the /[[.Name]] command with the message configured for the repository.",
+		ConfigHelpProvider: configHelp,
+		Commands: []plugins.Command{{
+			Name:        "[[.Name]]",
+			Description: "Replies with the message configured for the repository.",
+			Action: plugins.
+				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+					return handle(pc.SCMProviderClient, pc.PluginConfig, pc.Logger, &e)
+				}).
+				When(plugins.Action(scm.ActionCreate)),
+		}},
+	}
+)
+
+func init() {
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	help := map[string]string{}
+	for _, repo := range enabledRepos {
+		parts := strings.Split(repo, "/")
+		var message string
+		switch len(parts) {
+		case 1:
+			message = messageForRepo(config, repo, "")
+		case 2:
+			message = messageForRepo(config, parts[0], parts[1])
+		default:
+			return nil, fmt.Errorf("invalid repo in enabledRepos: %q", repo)
+		}
+		help[repo] = fmt.Sprintf("The [[.Name]] plugin replies with: %s", message)
+	}
+	return help, nil
+}
+
+type scmProviderClient interface {
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	QuoteAuthorForComment(string) string
+}
+
+func handle(spc scmProviderClient, config *plugins.Configuration, log *logrus.Entry, e *scmprovider.GenericCommentEvent) error {
+	org := e.Repo.Namespace
+	repo := e.Repo.Name
+	message := messageForRepo(config, org, repo)
+	log.Infof("Replying to /[[.Name]] on %s/%s#%d", org, repo, e.Number)
+	return spc.CreateComment(org, repo, e.Number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(e.Author.Login), message))
+}
+
+// messageForRepo returns the message configured for the repository, else for its org, else the default message
+func messageForRepo(config *plugins.Configuration, org, repo string) string {
+	for _, name := range []string{scm.Join(org, repo), org} {
+		for _, c := range config.[[.Type]] {
+			if c.Message != "" && sets.NewString(c.Repos...).Has(name) {
+				return c.Message
+			}
+		}
+	}
+	return defaultMessage
+}
+`)
+
+var testTemplate = parse("test", `package [[.Name]]
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fakeOrg  = "fake-org"
+	fakeRepo = "fake-repo"
+	fakePR   = 33
+)
+
+func TestHandle(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   []plugins.[[.Type]]
+		issue    bool
+		expected string
+	}{
+		{
+			name:     "default message",
+			expected: defaultMessage,
+		},
+		{
+			name: "message of the repository",
+			config: []plugins.[[.Type]]{
+				{Repos: []string{fakeOrg}, Message: "org message"},
+				{Repos: []string{fakeOrg + "/" + fakeRepo}, Message: "repo message"},
+			},
+			expected: "repo message",
+		},
+		{
+			name: "message of the org",
+			config: []plugins.[[.Type]]{
+				{Repos: []string{fakeOrg}, Message: "org message"},
+			},
+			expected: "org message",
+		},
+		{
+			name:     "issue",
+			issue:    true,
+			expected: defaultMessage,
+		},
+	}
+
+	log := logrus.WithField("plugin", pluginName)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeScmClient, fc := fake.NewDefault()
+			fakeClient := scmprovider.ToTestClient(fakeScmClient)
+			e := &scmprovider.GenericCommentEvent{
+				Action: scm.ActionCreate,
+				Repo:   scm.Repository{Namespace: fakeOrg, Name: fakeRepo},
+				Number: fakePR,
+				IsPR:   !tc.issue,
+				Body:   "/[[.Name]]",
+				Author: scm.User{Login: "user"},
+			}
+			config := &plugins.Configuration{[[.Type]]: tc.config}
+
+			err := handle(&fakeClient.Client, config, log, e)
+			require.NoError(t, err)
+			comments := fc.PullRequestCommentsAdded
+			if tc.issue {
+				comments = fc.IssueCommentsAdded
+			}
+			require.Len(t, comments, 1)
+			assert.Contains(t, comments[0], tc.expected)
+		})
+	}
+}
+
+func TestHelp(t *testing.T) {
+	config := &plugins.Configuration{[[.Type]]: []plugins.[[.Type]]{{Repos: []string{fakeOrg}, Message: "org message"}}}
+	help, err := plugin.GetHelp(config, []string{fakeOrg, fakeOrg + "/" + fakeRepo, "other"})
+	require.NoError(t, err)
+	assert.Equal(t, "The [[.Name]] plugin replies with: org message", help.Config[fakeOrg])
+	assert.Equal(t, "The [[.Name]] plugin replies with: org message", help.Config[fakeOrg+"/"+fakeRepo])
+	assert.Equal(t, "The [[.Name]] plugin replies with: "+defaultMessage, help.Config["other"])
+	require.Len(t, help.Commands, 1)
+	assert.Equal(t, "/[lh-][[.Name]]", help.Commands[0].Usage)
+}
+`)
+
+func parse(name, text string) *template.Template {
+	return template.Must(template.New(name).Delims("[[", "]]").Parse(text))
+}
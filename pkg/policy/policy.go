@@ -0,0 +1,178 @@
+// Package policy authorizes the slash commands and the merges with the Rego policies of an Open Policy Agent, so
+// that who may run which command or merge what is decided by the policies of the organisation rather than by each
+// plugin.
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ActionCommand is the action of the slash commands
+	ActionCommand = "command"
+	// ActionMerge is the action of the merges of keeper
+	ActionMerge = "merge"
+)
+
+var decisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lighthouse_policy_decisions_total",
+	Help: "A counter of the decisions of the policy engine, by action and decision.",
+}, []string{"action", "decision"})
+
+func init() {
+	prometheus.MustRegister(decisions)
+}
+
+// Input is the input the policies are evaluated with
+type Input struct {
+	// Action is either "command" or "merge"
+	Action string `json:"action"`
+	// Actor is the login of the user commenting, or of the author of the pull request merged
+	Actor string `json:"actor"`
+	// Teams are the teams of the org the actor is a member of
+	Teams []string `json:"teams"`
+	Org   string   `json:"org"`
+	Repo  string   `json:"repo"`
+	// Number is the number of the pull request or issue
+	Number int  `json:"number"`
+	IsPR   bool `json:"is_pr"`
+	// Command is the name of the slash command without its /lh- prefix, e.g. "lgtm"
+	Command string `json:"command,omitempty"`
+	// Args are the arguments of the slash command
+	Args string `json:"args,omitempty"`
+	// Branch is the base branch of the pull request merged
+	Branch string `json:"branch,omitempty"`
+	// Labels are the labels of the pull request or issue
+	Labels []string `json:"labels"`
+}
+
+// Decision is the decision of the policies
+type Decision struct {
+	Allow bool `json:"allow"`
+	// Reason explains the decision, it is reported to the users denied
+	Reason string `json:"reason,omitempty"`
+}
+
+// TeamLister lists the teams of an org and their members
+type TeamLister interface {
+	ListTeams(string) ([]*scm.Team, error)
+	ListTeamMembers(int, string) ([]*scm.TeamMember, error)
+}
+
+// Teams returns the sorted names of the teams of the org the user is a member of
+func Teams(c TeamLister, org, user string) ([]string, error) {
+	teams, err := c.ListTeams(org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the teams of %s: %v", org, err)
+	}
+	answer := []string{}
+	for _, team := range teams {
+		members, err := c.ListTeamMembers(team.ID, scmprovider.RoleAll)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the members of the team %s of %s: %v", team.Name, org, err)
+		}
+		for _, member := range members {
+			if scmprovider.NormLogin(member.Login) == scmprovider.NormLogin(user) {
+				answer = append(answer, team.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(answer)
+	return answer, nil
+}
+
+// Evaluator evaluates the decisions of the policies with the data API of an Open Policy Agent
+type Evaluator struct {
+	client *http.Client
+	logger *logrus.Entry
+}
+
+// NewEvaluator creates a new evaluator
+func NewEvaluator(logger *logrus.Entry) *Evaluator {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	return &Evaluator{
+		client: &http.Client{},
+		logger: logger.WithField("component", "policy"),
+	}
+}
+
+// Evaluate returns the decision of the policies for the input. The decision follows the fail_open setting of the
+// policy configuration when the policy engine cannot be reached or has no decision.
+func (e *Evaluator) Evaluate(cfg *lighthouse.Policy, input *Input) Decision {
+	decision, err := e.query(cfg, input)
+	if err != nil {
+		e.logger.WithError(err).WithFields(logrus.Fields{"action": input.Action, "actor": input.Actor, "command": input.Command}).
+			Warn("failed to evaluate the policies")
+		decisions.WithLabelValues(input.Action, "error").Inc()
+		if cfg.FailOpen {
+			return Decision{Allow: true}
+		}
+		return Decision{Reason: "the policies could not be evaluated"}
+	}
+	if decision.Allow {
+		decisions.WithLabelValues(input.Action, "allow").Inc()
+	} else {
+		decisions.WithLabelValues(input.Action, "deny").Inc()
+	}
+	return *decision
+}
+
+// query posts the input to the data API, the result being either a boolean or a decision object
+func (e *Evaluator) query(cfg *lighthouse.Policy, input *Input) (*Decision, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the input: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the policy engine responded %s: %s", resp.Status, string(data))
+	}
+	result := struct {
+		Result json.RawMessage `json:"result"`
+	}{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse the response of the policy engine: %v", err)
+	}
+	if len(result.Result) == 0 {
+		return nil, fmt.Errorf("the policy engine has no decision for %s", cfg.URL)
+	}
+	var allow bool
+	if err := json.Unmarshal(result.Result, &allow); err == nil {
+		return &Decision{Allow: allow}, nil
+	}
+	decision := &Decision{}
+	if err := json.Unmarshal(result.Result, decision); err != nil {
+		return nil, fmt.Errorf("invalid decision %s: %v", string(result.Result), err)
+	}
+	return decision, nil
+}
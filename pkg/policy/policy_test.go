@@ -0,0 +1,97 @@
+package policy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	var inputs []Input
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := struct {
+			Input Input `json:"input"`
+		}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		inputs = append(inputs, body.Input)
+		switch body.Input.Command {
+		case "lgtm":
+			_, _ = w.Write([]byte(`{"result": true}`))
+		case "approve":
+			_, _ = w.Write([]byte(`{"result": {"allow": false, "reason": "only the maintainers may approve"}}`))
+		case "hold":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &lighthouse.Policy{URL: server.URL}
+	require.NoError(t, cfg.Parse())
+	e := NewEvaluator(nil)
+
+	input := &Input{Action: ActionCommand, Actor: "bob", Teams: []string{"devs"}, Org: "org", Repo: "repo", Number: 1, Command: "lgtm"}
+	assert.Equal(t, Decision{Allow: true}, e.Evaluate(cfg, input))
+	require.Len(t, inputs, 1)
+	assert.Equal(t, *input, inputs[0])
+
+	input.Command = "approve"
+	assert.Equal(t, Decision{Reason: "only the maintainers may approve"}, e.Evaluate(cfg, input))
+
+	for _, command := range []string{"hold", "retest"} {
+		input.Command = command
+		cfg.FailOpen = false
+		assert.False(t, e.Evaluate(cfg, input).Allow, "%s is denied without a decision", command)
+		cfg.FailOpen = true
+		assert.True(t, e.Evaluate(cfg, input).Allow, "%s is allowed without a decision when failing open", command)
+	}
+}
+
+func TestEvaluateTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+
+	cfg := &lighthouse.Policy{URL: server.URL, TimeoutString: "10ms"}
+	require.NoError(t, cfg.Parse())
+	assert.False(t, NewEvaluator(nil).Evaluate(cfg, &Input{Action: ActionMerge}).Allow)
+}
+
+type fakeTeams map[string][]string
+
+func (f fakeTeams) ListTeams(string) ([]*scm.Team, error) {
+	var teams []*scm.Team
+	for _, name := range []string{"devs", "admins", "docs"} {
+		teams = append(teams, &scm.Team{ID: len(teams), Name: name})
+	}
+	return teams, nil
+}
+
+func (f fakeTeams) ListTeamMembers(id int, _ string) ([]*scm.TeamMember, error) {
+	var members []*scm.TeamMember
+	for _, login := range f[[]string{"devs", "admins", "docs"}[id]] {
+		members = append(members, &scm.TeamMember{Login: login})
+	}
+	return members, nil
+}
+
+func TestTeams(t *testing.T) {
+	client := fakeTeams{
+		"devs":   {"Bob"},
+		"admins": {"alice", "bob"},
+		"docs":   {"alice"},
+	}
+	teams, err := Teams(client, "org", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admins", "devs"}, teams)
+}
@@ -0,0 +1,130 @@
+// Package quarantine tracks the flaky tests of the repositories. The failure rates of the tests are computed from
+// the junit results of the latest runs of the jobs, and the tests failing too often are quarantined: they are
+// listed, per repository, in a ConfigMap.
+package quarantine
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Test is a quarantined test
+type Test struct {
+	// Suite is the name of the test suite
+	Suite string `json:"suite,omitempty"`
+	// Name is the name of the test
+	Name string `json:"name"`
+	// Job is the job whose runs the test was found flaky in
+	Job string `json:"job"`
+	// Issue is the number of the issue tracking the flaky test
+	Issue int `json:"issue,omitempty"`
+	// FailureRate is the failure rate of the test when it was quarantined
+	FailureRate float64 `json:"failureRate"`
+	// Since is when the test was quarantined
+	Since metav1.Time `json:"since"`
+}
+
+// FullName returns the name of the test prefixed by the name of its suite, if any
+func FullName(suite, name string) string {
+	if suite == "" {
+		return name
+	}
+	return suite + " / " + name
+}
+
+// Key returns the key of the quarantine list of a repository in the ConfigMap
+func Key(org, repo string) string {
+	return strings.ToLower(org) + "_" + repo
+}
+
+// Load returns the quarantine list of a repository, stored in the ConfigMap
+func Load(cm *corev1.ConfigMap, org, repo string) ([]Test, error) {
+	data := cm.Data[Key(org, repo)]
+	if data == "" {
+		return nil, nil
+	}
+	var tests []Test
+	if err := yaml.Unmarshal([]byte(data), &tests); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the quarantine list of %s/%s", org, repo)
+	}
+	return tests, nil
+}
+
+// Save stores the quarantine list of a repository in the ConfigMap
+func Save(cm *corev1.ConfigMap, org, repo string, tests []Test) error {
+	data, err := yaml.Marshal(tests)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the quarantine list of %s/%s", org, repo)
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[Key(org, repo)] = string(data)
+	return nil
+}
+
+// Find returns the quarantined test of the list with the given suite and name, nil if it is not quarantined
+func Find(tests []Test, suite, name string) *Test {
+	for i := range tests {
+		if tests[i].Suite == suite && tests[i].Name == name {
+			return &tests[i]
+		}
+	}
+	return nil
+}
+
+// Rate is the failure rate of a test over the latest runs of a job
+type Rate struct {
+	Suite    string
+	Name     string
+	Failures int
+	Runs     int
+}
+
+// Value returns the failure rate, between 0 and 1
+func (r Rate) Value() float64 {
+	if r.Runs == 0 {
+		return 0
+	}
+	return float64(r.Failures) / float64(r.Runs)
+}
+
+// Flaky returns true if the test failed in at least the given rate of at least minRuns runs, but did not fail in
+// all of them, which would rather denote a broken test
+func (r Rate) Flaky(threshold float64, minRuns int) bool {
+	return r.Runs >= minRuns && r.Failures < r.Runs && r.Value() >= threshold
+}
+
+// FailureRates returns the failure rates of the tests which failed in the given completed runs of a job, sorted by
+// suite and name. Each test which did not fail in a run is assumed to have passed.
+func FailureRates(runs []v1alpha1.LighthouseJob) []Rate {
+	type key struct{ suite, name string }
+	failures := map[key]int{}
+	for i := range runs {
+		seen := map[key]bool{}
+		for _, f := range runs[i].Status.TestFailures {
+			k := key{f.Suite, f.Name}
+			if !seen[k] {
+				seen[k] = true
+				failures[k]++
+			}
+		}
+	}
+	var rates []Rate
+	for k, n := range failures {
+		rates = append(rates, Rate{Suite: k.suite, Name: k.name, Failures: n, Runs: len(runs)})
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].Suite != rates[j].Suite {
+			return rates[i].Suite < rates[j].Suite
+		}
+		return rates[i].Name < rates[j].Name
+	})
+	return rates
+}
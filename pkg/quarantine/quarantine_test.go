@@ -0,0 +1,62 @@
+package quarantine
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func run(failures ...v1alpha1.TestFailure) v1alpha1.LighthouseJob {
+	return v1alpha1.LighthouseJob{Status: v1alpha1.LighthouseJobStatus{TestFailures: failures}}
+}
+
+func TestFailureRates(t *testing.T) {
+	foo := v1alpha1.TestFailure{Suite: "pkg/foo", Name: "TestFoo"}
+	bar := v1alpha1.TestFailure{Name: "TestBar"}
+	runs := []v1alpha1.LighthouseJob{
+		run(foo, bar),
+		run(foo, foo),
+		run(),
+		run(bar),
+	}
+	rates := FailureRates(runs)
+	assert.Equal(t, []Rate{
+		{Name: "TestBar", Failures: 2, Runs: 4},
+		{Suite: "pkg/foo", Name: "TestFoo", Failures: 2, Runs: 4},
+	}, rates)
+	assert.Equal(t, 0.5, rates[0].Value())
+	assert.Empty(t, FailureRates(nil))
+}
+
+func TestFlaky(t *testing.T) {
+	assert.True(t, Rate{Failures: 1, Runs: 5}.Flaky(0.2, 5))
+	assert.False(t, Rate{Failures: 1, Runs: 5}.Flaky(0.25, 5), "below the threshold")
+	assert.False(t, Rate{Failures: 1, Runs: 4}.Flaky(0.2, 5), "not enough runs")
+	assert.False(t, Rate{Failures: 5, Runs: 5}.Flaky(0.2, 5), "always failing")
+}
+
+func TestLoadSave(t *testing.T) {
+	cm := &corev1.ConfigMap{}
+	tests, err := Load(cm, "Org", "repo")
+	require.NoError(t, err)
+	assert.Empty(t, tests)
+
+	tests = []Test{{Suite: "pkg/foo", Name: "TestFoo", Job: "unit", Issue: 3, FailureRate: 0.25}}
+	require.NoError(t, Save(cm, "Org", "repo", tests))
+	assert.Contains(t, cm.Data, "org_repo")
+
+	loaded, err := Load(cm, "org", "repo")
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, tests[0].Issue, loaded[0].Issue)
+	assert.Equal(t, tests[0].FailureRate, loaded[0].FailureRate)
+	assert.NotNil(t, Find(loaded, "pkg/foo", "TestFoo"))
+	assert.Nil(t, Find(loaded, "", "TestFoo"))
+
+	cm.Data["org_repo"] = "not: a list"
+	_, err = Load(cm, "org", "repo")
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package repoowners
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// codeOwnersFileNames are the locations of a CODEOWNERS file, in the order GitHub looks them up
+var codeOwnersFileNames = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeOwnersRule is a line of a CODEOWNERS file
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// parseCodeOwners parses the rules of a CODEOWNERS file, email owners are ignored as they cannot be mapped to logins
+func parseCodeOwners(b []byte, log *logrus.Entry) []codeOwnersRule {
+	var rules []codeOwnersRule
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		re, err := codeOwnersPatternToRegexp(fields[0])
+		if err != nil {
+			log.WithError(err).Warnf("Invalid CODEOWNERS pattern %q.", fields[0])
+			continue
+		}
+		rule := codeOwnersRule{pattern: re}
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "@") {
+				rule.owners = append(rule.owners, strings.TrimPrefix(owner, "@"))
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// codeOwnersPatternToRegexp converts a CODEOWNERS pattern, which follows the .gitignore rules, to a regexp
+// matching the paths relative to the root of the repository
+func codeOwnersPatternToRegexp(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	p := strings.TrimPrefix(pattern, "/")
+	p = strings.TrimSuffix(p, "/")
+
+	var sb strings.Builder
+	if anchored {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("^(.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '*':
+			if i+1 < len(p) && p[i+1] == '*' {
+				i++
+				if i+1 < len(p) && p[i+1] == '/' {
+					// "**/" matches zero or more directories
+					i++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if strings.HasSuffix(p, "/*") && !strings.HasSuffix(p, "**") {
+		// "docs/*" only matches the files directly within the directory
+		sb.WriteString("$")
+	} else {
+		// a pattern matching a directory also matches everything within it
+		sb.WriteString("(/.*)?$")
+	}
+	return regexp.Compile(sb.String())
+}
+
+// loadCodeOwnersFrom loads the owners of the repository from its CODEOWNERS file. As the last matching rule of a
+// CODEOWNERS file defines the owners of a file, the owners are set on each file of the repository, without inheriting
+// the owners of the parent directories. The owners are both approvers and reviewers of the files.
+func loadCodeOwnersFrom(baseDir string, aliases RepoAliases, dirExcludes sets.String, log *logrus.Entry) (*RepoOwners, error) {
+	o := &RepoOwners{
+		RepoAliases: aliases,
+		baseDir:     baseDir,
+		log:         log,
+
+		approvers:         make(map[string]map[*regexp.Regexp]sets.String),
+		reviewers:         make(map[string]map[*regexp.Regexp]sets.String),
+		requiredReviewers: make(map[string]map[*regexp.Regexp]sets.String),
+		labels:            make(map[string]map[*regexp.Regexp]sets.String),
+		options:           make(map[string]dirOptions),
+
+		dirExcludes: dirExcludes,
+	}
+
+	var rules []codeOwnersRule
+	for _, name := range codeOwnersFileNames {
+		b, err := ioutil.ReadFile(filepath.Join(baseDir, name)) // #nosec
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rules = parseCodeOwners(b, log)
+		break
+	}
+	if len(rules) == 0 {
+		log.Info("No CODEOWNERS rules found.")
+		return o, nil
+	}
+
+	return o, filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			log.WithError(err).WithField("path", path).Error("Error while walking the repository files.")
+			return nil
+		}
+		if info.Mode().IsDir() && (dirExcludes.Has(info.Name()) || defaultDirExcludes.Has(info.Name())) {
+			return filepath.SkipDir
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		for i := len(rules) - 1; i >= 0; i-- {
+			if rules[i].pattern.MatchString(relPath) {
+				o.applyConfigToPath(relPath, nil, &Config{Approvers: rules[i].owners, Reviewers: rules[i].owners})
+				o.applyOptionsToPath(relPath, dirOptions{NoParentOwners: true})
+				break
+			}
+		}
+		return nil
+	})
+}
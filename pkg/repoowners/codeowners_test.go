@@ -0,0 +1,118 @@
+package repoowners
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestCodeOwnersPatternToRegexp(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		matches    []string
+		notMatches []string
+	}{
+		{
+			pattern: "*",
+			matches: []string{"README.md", "pkg/foo.go"},
+		},
+		{
+			pattern:    "*.js",
+			matches:    []string{"app.js", "web/app.js"},
+			notMatches: []string{"app.go"},
+		},
+		{
+			pattern:    "/build/logs/",
+			matches:    []string{"build/logs/a.log", "build/logs/deep/b.log"},
+			notMatches: []string{"src/build/logs/a.log"},
+		},
+		{
+			pattern:    "docs/*",
+			matches:    []string{"docs/getting-started.md"},
+			notMatches: []string{"docs/build-app/troubleshooting.md"},
+		},
+		{
+			pattern:    "apps/",
+			matches:    []string{"apps/a.go", "src/apps/b.go"},
+			notMatches: []string{"myapps/a.go"},
+		},
+		{
+			pattern:    "**/logs",
+			matches:    []string{"logs/a.log", "build/logs/a.log"},
+			notMatches: []string{"build/logsx/a.log"},
+		},
+	}
+	for _, test := range tests {
+		re, err := codeOwnersPatternToRegexp(test.pattern)
+		if err != nil {
+			t.Fatalf("pattern %s: unexpected error %v", test.pattern, err)
+		}
+		for _, path := range test.matches {
+			if !re.MatchString(path) {
+				t.Errorf("pattern %s should match %s", test.pattern, path)
+			}
+		}
+		for _, path := range test.notMatches {
+			if re.MatchString(path) {
+				t.Errorf("pattern %s should not match %s", test.pattern, path)
+			}
+		}
+	}
+}
+
+func TestLoadCodeOwnersFrom(t *testing.T) {
+	dir, err := ioutil.TempDir("", "codeowners")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		".github/CODEOWNERS": `# the default owners
+*       @Alice
+*.md    @bob docs@example.com
+/pkg/   @myorg/backend
+/pkg/generated/
+`,
+		"main.go":              "",
+		"README.md":            "",
+		"pkg/server.go":        "",
+		"pkg/generated/zz.go":  "",
+		"pkg/docs/overview.md": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o, err := loadCodeOwnersFrom(dir, nil, sets.NewString(), logrus.WithField("test", t.Name()))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := map[string]sets.String{
+		"main.go":              sets.NewString("alice"),
+		"README.md":            sets.NewString("bob"),
+		"pkg/server.go":        sets.NewString("myorg/backend"),
+		"pkg/generated/zz.go":  sets.NewString(),
+		"pkg/docs/overview.md": sets.NewString("myorg/backend"),
+	}
+	for path, approvers := range expected {
+		if actual := o.Approvers(path); !actual.Equal(approvers) {
+			t.Errorf("approvers of %s: expected %v but got %v", path, approvers.List(), actual.List())
+		}
+		if actual := o.Reviewers(path); !actual.Equal(approvers) {
+			t.Errorf("reviewers of %s: expected %v but got %v", path, approvers.List(), actual.List())
+		}
+		if approvers.Len() > 0 && o.FindApproverOwnersForFile(path) != path {
+			t.Errorf("the owners of %s should be found on the file itself", path)
+		}
+	}
+}
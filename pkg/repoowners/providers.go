@@ -0,0 +1,118 @@
+package repoowners
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// OwnersProvider loads the owners from the OWNERS files of the repository, it is the default provider
+	OwnersProvider = "owners"
+	// CodeOwnersProvider loads the owners from the CODEOWNERS file of the repository
+	CodeOwnersProvider = "codeowners"
+	// APIProvider loads the owners from an external ownership service
+	APIProvider = "api"
+
+	defaultAPICacheTTL = 10 * time.Minute
+)
+
+// ProviderConfig configures where the owners of a repository are loaded from
+type ProviderConfig struct {
+	// Kind is the kind of provider, "owners" (the default) for OWNERS files, "codeowners" for
+	// CODEOWNERS files or "api" for an external ownership service.
+	Kind string `json:"kind,omitempty"`
+	// Endpoint is the URL of the ownership service used by the "api" provider. It is called with the
+	// org, repo, base and sha query parameters and returns the OWNERS configuration of each directory,
+	// e.g. {"owners": {"": {"approvers": ["alice"]}, "docs": {"reviewers": ["bob"]}}}
+	Endpoint string `json:"endpoint,omitempty"`
+	// CacheTTL is how long the owners returned by the ownership service are cached for a commit.
+	// Defaults to 10m.
+	CacheTTL string `json:"cache_ttl,omitempty"`
+}
+
+// Validate validates the provider configuration
+func (p ProviderConfig) Validate() error {
+	switch p.Kind {
+	case "", OwnersProvider, CodeOwnersProvider:
+	case APIProvider:
+		if p.Endpoint == "" {
+			return fmt.Errorf("the %s owners provider requires an endpoint", APIProvider)
+		}
+		if _, err := url.Parse(p.Endpoint); err != nil {
+			return fmt.Errorf("invalid owners provider endpoint %s: %v", p.Endpoint, err)
+		}
+	default:
+		return fmt.Errorf("unknown owners provider %q, must be one of %s, %s or %s", p.Kind, OwnersProvider, CodeOwnersProvider, APIProvider)
+	}
+	if p.CacheTTL != "" {
+		if _, err := time.ParseDuration(p.CacheTTL); err != nil {
+			return fmt.Errorf("invalid owners provider cache_ttl %s: %v", p.CacheTTL, err)
+		}
+	}
+	return nil
+}
+
+func (p ProviderConfig) cacheTTL() time.Duration {
+	if d, err := time.ParseDuration(p.CacheTTL); err == nil && d > 0 {
+		return d
+	}
+	return defaultAPICacheTTL
+}
+
+// apiOwnersResponse is the response of an ownership service
+type apiOwnersResponse struct {
+	// Owners the OWNERS configuration of each directory, the root directory being ""
+	Owners map[string]SimpleConfig `json:"owners"`
+}
+
+// loadOwnersFromAPI loads the owners of the repository at the given commit from the ownership service
+func loadOwnersFromAPI(client *http.Client, provider ProviderConfig, org, repo, base, sha string, log *logrus.Entry) (*RepoOwners, error) {
+	u, err := url.Parse(provider.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owners provider endpoint %s: %v", provider.Endpoint, err)
+	}
+	q := u.Query()
+	q.Set("org", org)
+	q.Set("repo", repo)
+	q.Set("base", base)
+	q.Set("sha", sha)
+	u.RawQuery = q.Encode()
+
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the ownership service: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the ownership service returned status %d", resp.StatusCode)
+	}
+	response := &apiOwnersResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return nil, fmt.Errorf("failed to decode the ownership service response: %v", err)
+	}
+
+	o := &RepoOwners{
+		log: log,
+
+		approvers:         make(map[string]map[*regexp.Regexp]sets.String),
+		reviewers:         make(map[string]map[*regexp.Regexp]sets.String),
+		requiredReviewers: make(map[string]map[*regexp.Regexp]sets.String),
+		labels:            make(map[string]map[*regexp.Regexp]sets.String),
+		options:           make(map[string]dirOptions),
+	}
+	for dir, cfg := range response.Owners {
+		config := cfg
+		path := canonicalize(strings.Trim(dir, "/"))
+		o.applyConfigToPath(path, nil, &config.Config)
+		o.applyOptionsToPath(path, config.Options)
+	}
+	return o, nil
+}
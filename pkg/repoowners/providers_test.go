@@ -0,0 +1,79 @@
+package repoowners
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	prowConf "github.com/jenkins-x/lighthouse/pkg/config"
+)
+
+func TestProviderConfigValidate(t *testing.T) {
+	tests := []struct {
+		provider ProviderConfig
+		valid    bool
+	}{
+		{provider: ProviderConfig{}, valid: true},
+		{provider: ProviderConfig{Kind: CodeOwnersProvider}, valid: true},
+		{provider: ProviderConfig{Kind: APIProvider, Endpoint: "http://owners", CacheTTL: "5m"}, valid: true},
+		{provider: ProviderConfig{Kind: APIProvider}},
+		{provider: ProviderConfig{Kind: APIProvider, Endpoint: "http://owners", CacheTTL: "soon"}},
+		{provider: ProviderConfig{Kind: "unknown"}},
+	}
+	for _, test := range tests {
+		err := test.provider.Validate()
+		if test.valid && err != nil {
+			t.Errorf("%+v: unexpected error %v", test.provider, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%+v: expected an error", test.provider)
+		}
+	}
+}
+
+func TestLoadRepoOwnersFromAPI(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("org") != "org" || r.URL.Query().Get("repo") != "repo" || r.URL.Query().Get("sha") == "" {
+			http.Error(w, "missing parameters", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"owners": {"": {"approvers": ["Alice"]}, "/docs/": {"approvers": ["bob"], "options": {"no_parent_owners": true}}}}`)
+	}))
+	defer server.Close()
+
+	c := &Client{
+		spc:        &fake.SCMClient{},
+		logger:     logrus.WithField("client", "repoowners"),
+		cache:      make(map[string]cacheEntry),
+		httpClient: server.Client(),
+		config:     &prowConf.Config{},
+
+		mdYAMLEnabled:     func(org, repo string) bool { return false },
+		skipCollaborators: func(org, repo string) bool { return true },
+		ownersProvider: func(org, repo string) ProviderConfig {
+			return ProviderConfig{Kind: APIProvider, Endpoint: server.URL}
+		},
+	}
+	for i := 0; i < 2; i++ {
+		ro, err := c.LoadRepoOwners("org", "repo", "master")
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if actual := ro.Approvers("main.go"); !actual.Equal(sets.NewString("alice")) {
+			t.Errorf("expected the approvers of main.go to be alice but got %v", actual.List())
+		}
+		if actual := ro.Approvers("docs/README.md"); !actual.Equal(sets.NewString("bob")) {
+			t.Errorf("expected the approvers of docs/README.md to be bob but got %v", actual.List())
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the ownership service to be called once but was called %d times", calls)
+	}
+}
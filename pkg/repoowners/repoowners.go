@@ -19,11 +19,13 @@ package repoowners
 import (
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/jenkins-x/go-scm/scm"
 	git2 "github.com/jenkins-x/lighthouse/pkg/git"
@@ -80,9 +82,12 @@ type scmProviderClient interface {
 }
 
 type cacheEntry struct {
-	sha     string
-	aliases RepoAliases
-	owners  *RepoOwners
+	sha      string
+	aliases  RepoAliases
+	owners   *RepoOwners
+	provider ProviderConfig
+	// expires is when the owners loaded from an ownership service must be reloaded, zero if never
+	expires time.Time
 }
 
 // Interface is an interface to work with OWNERS files.
@@ -104,6 +109,8 @@ type Client struct {
 
 	mdYAMLEnabled     func(org, repo string) bool
 	skipCollaborators func(org, repo string) bool
+	ownersProvider    func(org, repo string) ProviderConfig
+	httpClient        *http.Client
 
 	lock  sync.Mutex
 	cache map[string]cacheEntry
@@ -116,15 +123,18 @@ func NewClient(
 	config *prowConf.Config,
 	mdYAMLEnabled func(org, repo string) bool,
 	skipCollaborators func(org, repo string) bool,
+	ownersProvider func(org, repo string) ProviderConfig,
 ) *Client {
 	return &Client{
-		git:    gc,
-		spc:    spc,
-		logger: logrus.WithField("client", "repoowners"),
-		cache:  make(map[string]cacheEntry),
+		git:        gc,
+		spc:        spc,
+		logger:     logrus.WithField("client", "repoowners"),
+		cache:      make(map[string]cacheEntry),
+		httpClient: &http.Client{Timeout: time.Minute},
 
 		mdYAMLEnabled:     mdYAMLEnabled,
 		skipCollaborators: skipCollaborators,
+		ownersProvider:    ownersProvider,
 
 		config: config,
 	}
@@ -213,41 +223,62 @@ func (c *Client) LoadRepoOwners(org, repo, base string) (RepoOwner, error) {
 		return nil, fmt.Errorf("failed to get current SHA for %s: %v", fullName, err)
 	}
 
+	provider := c.provider(org, repo)
+
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	entry, ok := c.cache[fullName]
-	if !ok || entry.sha != sha || entry.owners == nil || entry.owners.enableMDYAML != mdYaml {
-		gitRepo, err := c.git.Clone(cloneRef)
-		if err != nil {
-			return nil, fmt.Errorf("failed to clone %s: %v", cloneRef, err)
-		}
-		defer gitRepo.Clean()
-		if err := gitRepo.Checkout(base); err != nil {
-			return nil, err
-		}
+	if !ok || entry.sha != sha || entry.owners == nil || entry.owners.enableMDYAML != mdYaml || entry.provider != provider ||
+		(!entry.expires.IsZero() && time.Now().After(entry.expires)) {
+		if provider.Kind == APIProvider {
+			entry.owners, err = loadOwnersFromAPI(c.httpClient, provider, org, repo, base, sha, log)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load RepoOwners for %s: %v", fullName, err)
+			}
+			entry.owners.enableMDYAML = mdYaml
+			entry.expires = time.Now().Add(provider.cacheTTL())
+		} else {
+			gitRepo, err := c.git.Clone(cloneRef)
+			if err != nil {
+				return nil, fmt.Errorf("failed to clone %s: %v", cloneRef, err)
+			}
+			defer gitRepo.Clean()
+			if err := gitRepo.Checkout(base); err != nil {
+				return nil, err
+			}
 
-		if entry.aliases == nil || entry.sha != sha {
-			// aliases must be loaded
-			entry.aliases = loadAliasesFrom(gitRepo.Dir, log)
-		}
+			if entry.aliases == nil || entry.sha != sha {
+				// aliases must be loaded
+				entry.aliases = loadAliasesFrom(gitRepo.Dir, log)
+			}
 
-		excludeConfig := c.config.OwnersDirExcludes
+			excludeConfig := c.config.OwnersDirExcludes
 
-		dirExcludes := sets.NewString()
-		if excludeConfig != nil {
-			dirExcludes = defaultDirExcludes.Union(sets.NewString(excludeConfig.Default...))
-			if bl, ok := excludeConfig.Repos[org]; ok {
-				dirExcludes.Insert(bl...)
+			dirExcludes := sets.NewString()
+			if excludeConfig != nil {
+				dirExcludes = defaultDirExcludes.Union(sets.NewString(excludeConfig.Default...))
+				if bl, ok := excludeConfig.Repos[org]; ok {
+					dirExcludes.Insert(bl...)
+				}
+				if bl, ok := excludeConfig.Repos[org+"/"+repo]; ok {
+					dirExcludes.Insert(bl...)
+				}
+			}
+			if provider.Kind == CodeOwnersProvider {
+				entry.owners, err = loadCodeOwnersFrom(gitRepo.Dir, entry.aliases, dirExcludes, log)
+				if err == nil {
+					entry.owners.enableMDYAML = mdYaml
+				}
+			} else {
+				entry.owners, err = loadOwnersFrom(gitRepo.Dir, mdYaml, entry.aliases, dirExcludes, log)
 			}
-			if bl, ok := excludeConfig.Repos[org+"/"+repo]; ok {
-				dirExcludes.Insert(bl...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load RepoOwners for %s: %v", fullName, err)
 			}
-		}
-		entry.owners, err = loadOwnersFrom(gitRepo.Dir, mdYaml, entry.aliases, dirExcludes, log)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load RepoOwners for %s: %v", fullName, err)
+			entry.expires = time.Time{}
 		}
 		entry.sha = sha
+		entry.provider = provider
 		c.cache[fullName] = entry
 	}
 
@@ -269,6 +300,14 @@ func (c *Client) LoadRepoOwners(org, repo, base string) (RepoOwner, error) {
 	return owners, nil
 }
 
+// provider returns the owners provider of the repository
+func (c *Client) provider(org, repo string) ProviderConfig {
+	if c.ownersProvider == nil {
+		return ProviderConfig{}
+	}
+	return c.ownersProvider(org, repo)
+}
+
 // ExpandAlias returns members of an alias
 func (a RepoAliases) ExpandAlias(alias string) sets.String {
 	if a == nil {
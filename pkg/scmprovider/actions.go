@@ -0,0 +1,355 @@
+package scmprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/envelope"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultActionMaxAttempts is the default number of attempts of a queued action before its batch is dropped
+	DefaultActionMaxAttempts = 5
+
+	// DefaultActionBackoff is the default duration waited for before retrying a queued action, doubled for each retry
+	DefaultActionBackoff = 2 * time.Second
+)
+
+// Action is a side-effecting call of a client to the SCM provider, e.g. posting a comment, adding a label or setting
+// a status, deferred to the action queue
+type Action struct {
+	Kind   audit.Action     `json:"kind"`
+	Owner  string           `json:"owner"`
+	Repo   string           `json:"repo"`
+	Number int              `json:"number,omitempty"`
+	PR     bool             `json:"pr,omitempty"`
+	ID     int              `json:"id,omitempty"`
+	Body   string           `json:"body,omitempty"`
+	Label  string           `json:"label,omitempty"`
+	Ref    string           `json:"ref,omitempty"`
+	Status *scm.StatusInput `json:"status,omitempty"`
+}
+
+// apply makes the call of the action with the client
+func (a *Action) apply(c *Client) error {
+	switch a.Kind {
+	case audit.CommentCreated:
+		return c.CreateComment(a.Owner, a.Repo, a.Number, a.PR, a.Body)
+	case audit.CommentEdited:
+		return c.EditComment(a.Owner, a.Repo, a.Number, a.ID, a.Body, a.PR)
+	case audit.CommentDeleted:
+		return c.DeleteComment(a.Owner, a.Repo, a.Number, a.ID, a.PR)
+	case audit.LabelAdded:
+		return c.AddLabel(a.Owner, a.Repo, a.Number, a.Label, a.PR)
+	case audit.LabelRemoved:
+		return c.RemoveLabel(a.Owner, a.Repo, a.Number, a.Label, a.PR)
+	case audit.StatusSet:
+		_, err := c.CreateStatus(a.Owner, a.Repo, a.Ref, a.Status)
+		return err
+	}
+	return errors.Errorf("unknown action %s", a.Kind)
+}
+
+// ActionBatch collects the side-effecting calls of a plugin handling a webhook, which are queued together once the
+// handler returned so that a crash while handling the webhook does not leave a pull request half updated. The batches
+// opened by a queue persist each call as it is made, so that the calls made before a crash are still applied.
+type ActionBatch struct {
+	// ID identifies the batch in the queue, the batches sorting by the time they were opened or committed at
+	ID string `json:"id"`
+	// EventID is the ID of the webhook the actions are made for
+	EventID string `json:"eventID,omitempty"`
+	// Source is the source of the actions recorded in the audit log
+	Source audit.Source `json:"source"`
+	// Actions are the actions in the order they were made by the handler
+	Actions []Action `json:"actions"`
+	// Next is the index of the next action to apply
+	Next int `json:"next"`
+	// Attempts is the number of failed attempts of the next action
+	Attempts int `json:"attempts,omitempty"`
+
+	lock      sync.Mutex
+	committed bool
+	queue     *ActionQueue
+}
+
+type actionBatchKey struct{}
+
+// ContextWithActionBatch returns a context whose clients add their side-effecting calls to a new batch, returned to
+// be committed to the queue once the plugin handler returned
+func ContextWithActionBatch(ctx context.Context) (context.Context, *ActionBatch) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	batch := &ActionBatch{
+		EventID: eventid.FromContext(ctx),
+		Source:  audit.SourceFromContext(ctx),
+	}
+	return context.WithValue(ctx, actionBatchKey{}, batch), batch
+}
+
+// add adds an action to the batch, persisting it if the batch was opened by a queue, returning false if the batch was
+// already committed
+func (b *ActionBatch) add(a Action) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.committed {
+		return false
+	}
+	b.Actions = append(b.Actions, a)
+	if b.queue != nil {
+		if err := b.queue.save(b); err != nil {
+			logrus.WithError(err).WithField("batch", b.ID).Warn("failed to persist the action, it is persisted once the batch is committed")
+		}
+	}
+	return true
+}
+
+// Close stops the batch from accepting actions, returning the actions added so far
+func (b *ActionBatch) Close() []Action {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.committed = true
+	return b.Actions
+}
+
+// queueAction adds the action to the batch of the context of the client, returning false if the action should be
+// made right away as there is no batch or it was already committed, e.g. as the handler timed out
+func (c *Client) queueAction(a Action) bool {
+	if c.ctx == nil {
+		return false
+	}
+	batch, _ := c.ctx.Value(actionBatchKey{}).(*ActionBatch)
+	return batch != nil && batch.add(a)
+}
+
+// ActionQueue is a durable queue of the batches of actions of the plugins, persisted as a file per batch in a
+// directory and applied in order by workers with retries. The batches left over by a crash, including the ones whose
+// handler had not returned yet, are applied once the queue is started again, so that the actions are applied at
+// least once.
+type ActionQueue struct {
+	// MaxAttempts is the number of attempts of an action before its batch is dropped
+	MaxAttempts int
+	// Backoff is the duration waited for before retrying an action, doubled for each retry
+	Backoff time.Duration
+	// Sealer encrypts the persisted batches, which are kept in clear if nil
+	Sealer *envelope.Sealer
+
+	dir     string
+	client  func(owner string) (*Client, error)
+	batches chan *ActionBatch
+	pending int64
+	ctx     context.Context
+	stop    context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewActionQueue creates a queue persisting the batches in the directory, the client of the owner of the repository
+// of an action being used to apply it
+func NewActionQueue(dir string, client func(owner string) (*Client, error)) (*ActionQueue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create the directory of the action queue %s", dir)
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	return &ActionQueue{
+		MaxAttempts: DefaultActionMaxAttempts,
+		Backoff:     DefaultActionBackoff,
+		dir:         dir,
+		client:      client,
+		batches:     make(chan *ActionBatch),
+		ctx:         ctx,
+		stop:        stop,
+	}, nil
+}
+
+// Start starts the workers applying the batches, the batches persisted by a previous run being applied first
+func (q *ActionQueue) Start(workers int) error {
+	files, err := filepath.Glob(filepath.Join(q.dir, "*.json"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the batches of the action queue in %s", q.dir)
+	}
+	sort.Strings(files)
+	var leftOver []*ActionBatch
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the batch of actions %s", f)
+		}
+		if data, err = q.Sealer.Open(data); err != nil {
+			return errors.Wrapf(err, "failed to decrypt the batch of actions %s", f)
+		}
+		b := &ActionBatch{}
+		if err := json.Unmarshal(data, b); err != nil {
+			logrus.WithError(err).WithField("file", f).Error("dropping the corrupted batch of actions")
+			_ = os.Remove(f)
+			continue
+		}
+		b.committed = true
+		leftOver = append(leftOver, b)
+	}
+	if len(leftOver) > 0 {
+		logrus.Infof("resuming %d batches of actions left over by the previous run", len(leftOver))
+	}
+	atomic.AddInt64(&q.pending, int64(len(leftOver)))
+	actionQueuePending.Set(float64(atomic.LoadInt64(&q.pending)))
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	go func() {
+		for _, b := range leftOver {
+			select {
+			case q.batches <- b:
+			case <-q.ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Open returns a context whose clients add their side-effecting calls to a new batch persisted in the queue as each
+// call is made, returned to be committed once the plugin handler returned
+func (q *ActionQueue) Open(ctx context.Context) (context.Context, *ActionBatch) {
+	ctx, b := ContextWithActionBatch(ctx)
+	b.ID = newBatchID()
+	b.queue = q
+	return ctx, b
+}
+
+// Commit persists the actions of the batch and queues them, the batch no longer accepting actions. A batch without
+// actions is ignored.
+func (q *ActionQueue) Commit(b *ActionBatch) error {
+	if len(b.Close()) == 0 {
+		return nil
+	}
+	if b.ID == "" {
+		b.ID = newBatchID()
+	}
+	if err := q.save(b); err != nil {
+		return err
+	}
+	atomic.AddInt64(&q.pending, 1)
+	actionQueuePending.Set(float64(atomic.LoadInt64(&q.pending)))
+	select {
+	case q.batches <- b:
+	case <-q.ctx.Done():
+		// applied once the queue is started again
+	}
+	return nil
+}
+
+// Stop waits up to timeout for the queued batches to be applied then stops the workers, returning the number of
+// batches left to be applied once the queue is started again
+func (q *ActionQueue) Stop(timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+	for atomic.LoadInt64(&q.pending) > 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	q.stop()
+	q.wg.Wait()
+	return int(atomic.LoadInt64(&q.pending))
+}
+
+func (q *ActionQueue) work() {
+	defer q.wg.Done()
+	for {
+		select {
+		case b := <-q.batches:
+			q.apply(b)
+		case <-q.ctx.Done():
+			return
+		}
+	}
+}
+
+// apply applies the actions of the batch from the next one, persisting the progress so that an action is applied
+// again only if the queue stopped while applying it
+func (q *ActionQueue) apply(b *ActionBatch) {
+	ctx := audit.ContextWithSource(q.ctx, b.Source)
+	ctx = eventid.ContextWithID(ctx, b.EventID)
+	log := logrus.WithField(eventid.Field, b.EventID).WithField("batch", b.ID)
+	for b.Next < len(b.Actions) {
+		a := &b.Actions[b.Next]
+		client, err := q.client(a.Owner)
+		if err == nil {
+			err = a.apply(client.WithContext(ctx))
+		}
+		if q.ctx.Err() != nil {
+			// the queue stopped, the action is applied again once it is started
+			return
+		}
+		if err == nil {
+			actionQueueActions.WithLabelValues(string(a.Kind), "applied").Inc()
+			b.Next++
+			b.Attempts = 0
+			if b.Next < len(b.Actions) {
+				if err := q.save(b); err != nil {
+					log.WithError(err).Warn("failed to persist the progress of the batch of actions")
+				}
+			}
+			continue
+		}
+		b.Attempts++
+		log := log.WithError(err).WithField("action", a.Kind).WithField("repo", a.Owner+"/"+a.Repo).WithField("attempts", b.Attempts)
+		if b.Attempts >= q.MaxAttempts {
+			actionQueueActions.WithLabelValues(string(a.Kind), "dropped").Inc()
+			log.Errorf("giving up on the %d remaining actions of the batch", len(b.Actions)-b.Next)
+			break
+		}
+		actionQueueActions.WithLabelValues(string(a.Kind), "retried").Inc()
+		log.Warn("failed to apply the action, retrying")
+		if err := q.save(b); err != nil {
+			log.WithError(err).Warn("failed to persist the progress of the batch of actions")
+		}
+		select {
+		case <-time.After(q.Backoff << uint(b.Attempts-1)):
+		case <-q.ctx.Done():
+			return
+		}
+	}
+	if err := os.Remove(q.path(b)); err != nil && !os.IsNotExist(err) {
+		log.WithError(err).Warn("failed to remove the batch of actions applied")
+	}
+	atomic.AddInt64(&q.pending, -1)
+	actionQueuePending.Set(float64(atomic.LoadInt64(&q.pending)))
+}
+
+// newBatchID returns the ID of a new batch, sorting by the time it is created at
+func newBatchID() string {
+	return fmt.Sprintf("%020d-%s", time.Now().UnixNano(), eventid.New())
+}
+
+func (q *ActionQueue) path(b *ActionBatch) string {
+	return filepath.Join(q.dir, b.ID+".json")
+}
+
+// save persists the batch atomically so that a crash does not leave a partially written batch
+func (q *ActionQueue) save(b *ActionBatch) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the batch of actions")
+	}
+	if data, err = q.Sealer.Seal(data); err != nil {
+		return errors.Wrap(err, "failed to encrypt the batch of actions")
+	}
+	path := q.path(b)
+	tmp := strings.TrimSuffix(path, ".json") + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write the batch of actions %s", tmp)
+	}
+	return errors.Wrapf(os.Rename(tmp, path), "failed to persist the batch of actions %s", path)
+}
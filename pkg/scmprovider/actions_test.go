@@ -0,0 +1,187 @@
+package scmprovider
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/envelope"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingPullRequestService fails to add the labels the first failures times
+type failingPullRequestService struct {
+	scm.PullRequestService
+	failures int
+}
+
+func (s *failingPullRequestService) AddLabel(ctx context.Context, repo string, number int, label string) (*scm.Response, error) {
+	if s.failures > 0 {
+		s.failures--
+		return nil, errors.New("boom")
+	}
+	return s.PullRequestService.AddLabel(ctx, repo, number, label)
+}
+
+func newTestActionQueue(t *testing.T, dir string, spc *Client) *ActionQueue {
+	q, err := NewActionQueue(dir, func(owner string) (*Client, error) {
+		return spc, nil
+	})
+	require.NoError(t, err)
+	q.Backoff = time.Millisecond
+	return q
+}
+
+func TestActionQueue(t *testing.T) {
+	client, data := fakescm.NewDefault()
+	prs := &failingPullRequestService{PullRequestService: client.PullRequests, failures: 1}
+	client.PullRequests = prs
+	spc := ToClient(client, "bot")
+	dir, err := ioutil.TempDir("", "actions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx, batch := ContextWithActionBatch(eventid.ContextWithID(nil, "abc"))
+	c := spc.WithContext(ctx)
+	require.NoError(t, c.CreateComment("org", "repo", 1, true, "hello"))
+	require.NoError(t, c.AddLabel("org", "repo", 1, "lgtm", true))
+	status, err := c.CreateStatus("org", "repo", "sha", &scm.StatusInput{Label: "ci", State: scm.StateSuccess})
+	require.NoError(t, err)
+	assert.Equal(t, "ci", status.Label)
+	assert.Empty(t, data.PullRequestComments[1], "the actions are deferred until the batch is committed")
+	assert.Len(t, batch.Actions, 3)
+
+	q := newTestActionQueue(t, dir, spc)
+	require.NoError(t, q.Start(1))
+	require.NoError(t, q.Commit(batch))
+	assert.Equal(t, 0, q.Stop(10*time.Second))
+
+	require.Len(t, data.PullRequestComments[1], 1)
+	assert.Equal(t, eventid.AppendToComment("hello", "abc"), data.PullRequestComments[1][0].Body)
+	assert.Equal(t, []string{"org/repo#1:lgtm"}, data.PullRequestLabelsAdded, "the failed action is retried")
+	require.Len(t, data.Statuses["sha"], 1)
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	assert.Empty(t, files, "the batches applied are removed")
+
+	// the actions made once the batch is committed, e.g. by a handler which timed out, are made right away
+	require.NoError(t, c.CreateComment("org", "repo", 1, true, "late"))
+	assert.Len(t, data.PullRequestComments[1], 2)
+}
+
+func TestActionQueueResumes(t *testing.T) {
+	client, data := fakescm.NewDefault()
+	spc := ToClient(client, "bot")
+	dir, err := ioutil.TempDir("", "actions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// a batch left over by a crash once its first action was applied
+	left := &ActionBatch{
+		ID:     "00000000000000000001-left",
+		Source: audit.Source{Actor: "someone"},
+		Actions: []Action{
+			{Kind: audit.LabelAdded, Owner: "org", Repo: "repo", Number: 1, Label: "applied", PR: true},
+			{Kind: audit.LabelAdded, Owner: "org", Repo: "repo", Number: 1, Label: "pending", PR: true},
+		},
+		Next: 1,
+	}
+	raw, err := json.Marshal(left)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, left.ID+".json"), raw, 0644))
+
+	q := newTestActionQueue(t, dir, spc)
+	require.NoError(t, q.Start(1))
+	assert.Equal(t, 0, q.Stop(10*time.Second))
+	assert.Equal(t, []string{"org/repo#1:pending"}, data.PullRequestLabelsAdded)
+}
+
+func TestActionQueueOpen(t *testing.T) {
+	client, data := fakescm.NewDefault()
+	spc := ToClient(client, "bot")
+	dir, err := ioutil.TempDir("", "actions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the handler crashes once it made its calls, before its batch is committed
+	q := newTestActionQueue(t, dir, spc)
+	ctx, batch := q.Open(eventid.ContextWithID(nil, "abc"))
+	c := spc.WithContext(ctx)
+	require.NoError(t, c.AddLabel("org", "repo", 1, "lgtm", true))
+	require.NoError(t, c.CreateComment("org", "repo", 1, true, "hello"))
+	assert.Empty(t, data.PullRequestLabelsAdded)
+	raw, err := ioutil.ReadFile(q.path(batch))
+	require.NoError(t, err, "the actions are persisted as they are made")
+	persisted := &ActionBatch{}
+	require.NoError(t, json.Unmarshal(raw, persisted))
+	assert.Len(t, persisted.Actions, 2)
+
+	q = newTestActionQueue(t, dir, spc)
+	require.NoError(t, q.Start(1))
+	assert.Equal(t, 0, q.Stop(10*time.Second))
+	assert.Equal(t, []string{"org/repo#1:lgtm"}, data.PullRequestLabelsAdded)
+	require.Len(t, data.PullRequestComments[1], 1)
+	assert.Equal(t, eventid.AppendToComment("hello", "abc"), data.PullRequestComments[1][0].Body)
+}
+
+func TestActionQueueEncrypted(t *testing.T) {
+	client, data := fakescm.NewDefault()
+	spc := ToClient(client, "bot")
+	dir, err := ioutil.TempDir("", "actions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "key")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte("0123456789abcdef0123456789abcdef"), 0600))
+	keys, err := envelope.NewLocalKey(keyFile)
+	require.NoError(t, err)
+	queueDir := filepath.Join(dir, "queue")
+
+	q := newTestActionQueue(t, queueDir, spc)
+	q.Sealer = envelope.NewSealer(keys)
+	batch := &ActionBatch{
+		ID:      "00000000000000000001-secret",
+		Actions: []Action{{Kind: audit.CommentCreated, Owner: "org", Repo: "repo", Number: 1, Body: "confidential", PR: true}},
+	}
+	require.NoError(t, q.save(batch))
+	raw, err := ioutil.ReadFile(q.path(batch))
+	require.NoError(t, err)
+	assert.True(t, envelope.IsSealed(raw))
+	assert.NotContains(t, string(raw), "confidential")
+
+	require.NoError(t, q.Start(1))
+	assert.Equal(t, 0, q.Stop(10*time.Second))
+	require.Len(t, data.PullRequestComments[1], 1)
+	assert.Equal(t, "confidential", data.PullRequestComments[1][0].Body)
+}
+
+func TestActionQueueDrops(t *testing.T) {
+	client, data := fakescm.NewDefault()
+	client.PullRequests = &failingPullRequestService{PullRequestService: client.PullRequests, failures: 10}
+	spc := ToClient(client, "bot")
+	dir, err := ioutil.TempDir("", "actions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx, batch := ContextWithActionBatch(nil)
+	c := spc.WithContext(ctx)
+	require.NoError(t, c.AddLabel("org", "repo", 1, "lgtm", true))
+	require.NoError(t, c.CreateComment("org", "repo", 1, true, "labelled"))
+
+	q := newTestActionQueue(t, dir, spc)
+	q.MaxAttempts = 3
+	require.NoError(t, q.Start(1))
+	require.NoError(t, q.Commit(batch))
+	assert.Equal(t, 0, q.Stop(10*time.Second))
+	assert.Empty(t, data.PullRequestLabelsAdded)
+	assert.Empty(t, data.PullRequestComments[1], "the batch is given up on once an action failed too many times")
+}
@@ -0,0 +1,70 @@
+package scmprovider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// changesCache memoizes the changes of the pull requests listed while processing a webhook, so that the plugins
+// handling it list the changes of a pull request at most once between them
+type changesCache struct {
+	lock    sync.Mutex
+	entries map[string]*changesEntry
+}
+
+type changesEntry struct {
+	lock    sync.Mutex
+	fetched bool
+	changes []*scm.Change
+}
+
+type changesCacheKey struct{}
+
+// ContextWithChangesCache returns a context whose clients share the changes of the pull requests they list, typically
+// the context of a webhook so that each webhook costs at most one listing of the changes of a pull request
+func ContextWithChangesCache(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, changesCacheKey{}, &changesCache{entries: map[string]*changesEntry{}})
+}
+
+// get returns the memoized changes of a pull request, calling fetch if they were not listed yet. The concurrent
+// callers wait for the first one to list them, the errors not being memoized.
+func (c *changesCache) get(fullName string, number int, fetch func() ([]*scm.Change, error)) ([]*scm.Change, error) {
+	key := fmt.Sprintf("%s#%d", fullName, number)
+	c.lock.Lock()
+	e := c.entries[key]
+	if e == nil {
+		e = &changesEntry{}
+		c.entries[key] = e
+	}
+	c.lock.Unlock()
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.fetched {
+		changesCacheLookups.WithLabelValues("hit").Inc()
+	} else {
+		changesCacheLookups.WithLabelValues("miss").Inc()
+		changes, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		e.changes = changes
+		e.fetched = true
+	}
+	return append([]*scm.Change(nil), e.changes...), nil
+}
+
+// changesCache returns the cache of the changes of the context of the client, if any
+func (c *Client) changesCache() *changesCache {
+	if c.ctx == nil {
+		return nil
+	}
+	cache, _ := c.ctx.Value(changesCacheKey{}).(*changesCache)
+	return cache
+}
@@ -0,0 +1,66 @@
+package scmprovider
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingPullRequestService counts the listings of the changes of the pull requests
+type countingPullRequestService struct {
+	scm.PullRequestService
+	listings int32
+}
+
+func (s *countingPullRequestService) ListChanges(ctx context.Context, repo string, number int, opts scm.ListOptions) ([]*scm.Change, *scm.Response, error) {
+	atomic.AddInt32(&s.listings, 1)
+	return s.PullRequestService.ListChanges(ctx, repo, number, opts)
+}
+
+func TestChangesCache(t *testing.T) {
+	client, data := fakescm.NewDefault()
+	prs := &countingPullRequestService{PullRequestService: client.PullRequests}
+	client.PullRequests = prs
+	data.PullRequestChanges[1] = []*scm.Change{{Path: "README.md"}, {Path: "main.go"}}
+	data.PullRequestChanges[2] = []*scm.Change{{Path: "docs/index.md"}}
+	spc := ToClient(client, "bot")
+
+	// the changes are listed every time without a cache
+	for i := 0; i < 2; i++ {
+		changes, err := spc.GetPullRequestChanges("myorg", "myrepo", 1)
+		require.NoError(t, err)
+		assert.Len(t, changes, 2)
+	}
+	assert.Equal(t, int32(2), prs.listings)
+
+	// the plugins handling a webhook share the changes listed once per pull request
+	ctx := ContextWithChangesCache(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			changes, err := spc.WithContext(ctx).GetPullRequestChanges("myorg", "myrepo", 1)
+			assert.NoError(t, err)
+			assert.Len(t, changes, 2)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(3), prs.listings)
+
+	changes, err := spc.WithContext(ctx).GetPullRequestChanges("myorg", "myrepo", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "docs/index.md", changes[0].Path)
+	assert.Equal(t, int32(4), prs.listings)
+
+	// the next webhook lists the changes again
+	_, err = spc.WithContext(ContextWithChangesCache(nil)).GetPullRequestChanges("myorg", "myrepo", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), prs.listings)
+}
@@ -0,0 +1,128 @@
+package scmprovider
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+)
+
+const (
+	// CheckRunQueued is the status of a check run which has not started yet
+	CheckRunQueued = "queued"
+	// CheckRunInProgress is the status of a running check run
+	CheckRunInProgress = "in_progress"
+	// CheckRunCompleted is the status of a finished check run, its conclusion tells the result
+	CheckRunCompleted = "completed"
+
+	// CheckRunSuccess is the conclusion of a successful check run
+	CheckRunSuccess = "success"
+	// CheckRunFailure is the conclusion of a failed check run
+	CheckRunFailure = "failure"
+	// CheckRunCancelled is the conclusion of an aborted check run
+	CheckRunCancelled = "cancelled"
+
+	// maxCheckRunSummary is the maximum length of the summary and text of a check run output accepted by GitHub
+	maxCheckRunSummary = 65535
+)
+
+// CheckRunOutput is the markdown output displayed for a check run
+type CheckRunOutput struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+	Text    string `json:"text,omitempty"`
+}
+
+// CheckRunInput is used to create or update a check run
+type CheckRunInput struct {
+	Name        string          `json:"name,omitempty"`
+	HeadSHA     string          `json:"head_sha,omitempty"`
+	DetailsURL  string          `json:"details_url,omitempty"`
+	ExternalID  string          `json:"external_id,omitempty"`
+	Status      string          `json:"status,omitempty"`
+	Conclusion  string          `json:"conclusion,omitempty"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	Output      *CheckRunOutput `json:"output,omitempty"`
+}
+
+// CheckRun is a check run reported on a commit
+type CheckRun struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	HeadSHA    string `json:"head_sha"`
+	ExternalID string `json:"external_id"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	DetailsURL string `json:"details_url"`
+}
+
+// SupportsChecks returns true if the underlying provider supports the checks API.
+// Currently, that means it has to be GitHub.
+func (c *Client) SupportsChecks() bool {
+	return c.client.Driver == scm.DriverGithub
+}
+
+// CreateCheckRun creates a check run on a commit of the repository
+func (c *Client) CreateCheckRun(owner, repo string, input *CheckRunInput) (_ *CheckRun, err error) {
+	defer c.audit(checkRunEvent(owner, repo, input), &err)
+	path := fmt.Sprintf("repos/%s/check-runs", c.repositoryName(owner, repo))
+	return c.doCheckRun(http.MethodPost, path, input)
+}
+
+// UpdateCheckRun updates the check run with the given ID
+func (c *Client) UpdateCheckRun(owner, repo string, id int64, input *CheckRunInput) (_ *CheckRun, err error) {
+	defer c.audit(checkRunEvent(owner, repo, input), &err)
+	path := fmt.Sprintf("repos/%s/check-runs/%d", c.repositoryName(owner, repo), id)
+	return c.doCheckRun(http.MethodPatch, path, input)
+}
+
+// ListCheckRuns lists the latest check run of each name reported on the commit of the given ref
+func (c *Client) ListCheckRuns(owner, repo, ref string) ([]*CheckRun, error) {
+	if !c.SupportsChecks() {
+		return nil, fmt.Errorf("the %s provider does not support check runs", c.ProviderType())
+	}
+	path := fmt.Sprintf("repos/%s/commits/%s/check-runs?filter=latest&per_page=100", c.repositoryName(owner, repo), ref)
+	out := struct {
+		CheckRuns []*CheckRun `json:"check_runs"`
+	}{}
+	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.CheckRuns, nil
+}
+
+func (c *Client) doCheckRun(method, path string, input *CheckRunInput) (*CheckRun, error) {
+	if !c.SupportsChecks() {
+		return nil, fmt.Errorf("the %s provider does not support check runs", c.ProviderType())
+	}
+	if input.Output != nil {
+		input.Output.Summary = truncateCheckRunText(eventid.AppendToComment(input.Output.Summary, c.eventID()))
+		input.Output.Text = truncateCheckRunText(input.Output.Text)
+	}
+	checkRun := &CheckRun{}
+	if err := c.doJSON(method, path, input, checkRun); err != nil {
+		return nil, err
+	}
+	return checkRun, nil
+}
+
+func checkRunEvent(owner, repo string, input *CheckRunInput) audit.Event {
+	return audit.Event{
+		Action:  audit.StatusSet,
+		Org:     owner,
+		Repo:    repo,
+		Target:  input.Name,
+		Details: map[string]string{"sha": input.HeadSHA, "state": input.Status, "conclusion": input.Conclusion},
+	}
+}
+
+func truncateCheckRunText(text string) string {
+	if len(text) <= maxCheckRunSummary {
+		return text
+	}
+	return text[:maxCheckRunSummary-3] + "..."
+}
@@ -1,12 +1,18 @@
 package scmprovider
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -27,6 +33,11 @@ type SCMClient interface {
 	ServerURL() *url.URL
 	QuoteAuthorForComment(string) string
 
+	// Functions implemented in checks.go
+	SupportsChecks() bool
+	CreateCheckRun(string, string, *CheckRunInput) (*CheckRun, error)
+	UpdateCheckRun(string, string, int64, *CheckRunInput) (*CheckRun, error)
+
 	// Functions implemented in content.go
 	GetFile(string, string, string, string) ([]byte, error)
 	ListFiles(string, string, string, string) ([]*scm.FileEntry, error)
@@ -36,6 +47,11 @@ type SCMClient interface {
 	DeleteRef(string, string, string) error
 	GetSingleCommit(string, string, string) (*scm.Commit, error)
 
+	// Functions implemented in gitlab.go
+	CreateGitLabStatus(string, string, string, *GitLabStatusInput) (*scm.Status, error)
+	FindGitLabPipeline(string, string, string, string) (int, error)
+	PipelinesMustSucceed(string, string) (bool, error)
+
 	// Functions implemented in issues.go
 	Query(context.Context, interface{}, map[string]interface{}) error
 	Search(scm.SearchOptions) ([]*scm.SearchIssue, *RateLimits, error)
@@ -53,23 +69,34 @@ type SCMClient interface {
 	FindIssues(string, string, bool) ([]scm.Issue, error)
 	CloseIssue(string, string, int) error
 	EditComment(owner, repo string, number int, id int, comment string, pr bool) error
+	GetIssue(string, string, int) (*scm.Issue, error)
+	ListIssues(string, string, scm.IssueListOptions) ([]*scm.Issue, error)
+	CreateIssue(string, string, string, string) (*scm.Issue, error)
+
+	// Functions implemented in issue_transfer.go
+	TransferIssue(string, string, int, string) (*scm.Issue, error)
 
 	// Functions implemented in organizations.go
 	ListTeams(string) ([]*scm.Team, error)
 	ListTeamMembers(int, string) ([]*scm.TeamMember, error)
 	ListOrgMembers(string) ([]*scm.TeamMember, error)
 	IsOrgAdmin(string, string) (bool, error)
+	InviteOrgMember(string, string) error
 
 	// Functions implemented in pull_requests.go
 	GetPullRequest(string, string, int) (*scm.PullRequest, error)
 	ListPullRequestComments(string, string, int) ([]*scm.Comment, error)
 	GetPullRequestChanges(string, string, int) ([]*scm.Change, error)
 	Merge(string, string, int, MergeDetails) error
+	CreatePullRequest(string, string, *scm.PullRequestInput) (*scm.PullRequest, error)
 	ReopenPR(string, string, int) error
 	ClosePR(string, string, int) error
 	ListAllPullRequestsForFullNameRepo(string, scm.PullRequestListOptions) ([]*scm.PullRequest, error)
 	FindPullRequestsByAuthor(string, string, string) ([]*scm.PullRequest, error)
 
+	// Functions implemented in reactions.go
+	CreateReaction(string, string, int, bool, string) error
+
 	// Functions implemented in repositories.go
 	GetRepoLabels(string, string) ([]*scm.Label, error)
 	IsCollaborator(string, string, string) (bool, error)
@@ -82,10 +109,19 @@ type SCMClient interface {
 	GetUserPermission(string, string, string) (string, error)
 	IsMember(string, string) (bool, error)
 	GetRepositoryByFullName(string) (*scm.Repository, error)
+	ListOrgRepos(string) ([]*scm.Repository, error)
+
+	// Functions implemented in repo_labels.go
+	CreateRepoLabel(string, string, *scm.Label) error
+	UpdateRepoLabel(string, string, string, *scm.Label) error
+
+	// Functions implemented in signatures.go
+	ListPullRequestCommitSignatures(string, string, int) ([]*CommitSignature, error)
 
 	// Functions implemented in reviews.go
 	ListReviews(string, string, int) ([]*scm.Review, error)
 	RequestReview(string, string, int, []string) error
+	DismissReview(string, string, int, int, string) error
 	UnrequestReview(string, string, int, []string) error
 
 	// Functions implemented in milestones.go
@@ -98,6 +134,35 @@ type SCMClient interface {
 type Client struct {
 	client  *scm.Client
 	botName string
+	// ctx carries the source of the actions of the client for the audit log, the priority of its calls and the ID of
+	// the webhook they are made for, it may be nil
+	ctx context.Context
+}
+
+// WithContext returns a copy of the client whose actions are recorded in the audit log with the source of the given
+// context, typically the user and the webhook which caused them, and whose calls have the priority of the context
+func (c *Client) WithContext(ctx context.Context) *Client {
+	answer := *c
+	answer.ctx = ctx
+	return &answer
+}
+
+// requestContext returns the context of the calls of the client to the SCM provider
+func (c *Client) requestContext() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// eventID returns the ID of the webhook the client acts for, if any, surfaced in its comments and statuses
+func (c *Client) eventID() string {
+	return eventid.FromContext(c.ctx)
+}
+
+// audit records an action of the client in the audit log, as failed if *err is not nil once the action is done
+func (c *Client) audit(event audit.Event, err *error) {
+	audit.Record(c.ctx, event, *err)
 }
 
 // ToScmClient gets the underlying SCM client
@@ -168,6 +233,60 @@ func (c *Client) repositoryName(owner string, repo string) string {
 	return fmt.Sprintf("%s/%s", owner, repo)
 }
 
+// doJSON sends a request to the REST API of the provider for the features go-scm does not support yet.
+// The input is encoded as the JSON body of the request if not nil, and the response decoded into out if not nil.
+func (c *Client) doJSON(method, path string, in, out interface{}) error {
+	req := &scm.Request{
+		Method: method,
+		Path:   path,
+		Header: http.Header{
+			"Accept": []string{"application/json"},
+		},
+	}
+	if c.client.Driver == scm.DriverGithub {
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+	}
+	if in != nil {
+		body, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Body = bytes.NewReader(body)
+	}
+	res, err := c.client.Do(c.requestContext(), req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.Status > 299 {
+		data, _ := ioutil.ReadAll(res.Body)
+		return &restError{method: method, path: path, status: res.Status, body: string(data)}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// restError is the error of a request to the REST API of the provider which failed with an error status
+type restError struct {
+	method string
+	path   string
+	status int
+	body   string
+}
+
+func (e *restError) Error() string {
+	return fmt.Sprintf("%s %s failed with status %d: %s", e.method, e.path, e.status, e.body)
+}
+
+// isNotFound returns true if the request to the REST API of the provider failed as the resource does not exist
+func isNotFound(err error) bool {
+	re, ok := err.(*restError)
+	return ok && re.status == http.StatusNotFound
+}
+
 func (c *Client) createListOptions() scm.ListOptions {
 	return scm.ListOptions{}
 }
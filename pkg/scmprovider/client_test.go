@@ -0,0 +1,31 @@
+package scmprovider
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventIDInCommentsAndStatuses(t *testing.T) {
+	client, data := fakescm.NewDefault()
+	spc := ToClient(client, "bot")
+
+	require.NoError(t, spc.CreateComment("myorg", "myrepo", 12, true, "/retest"))
+	input := &scm.StatusInput{State: scm.StatePending, Label: "lint", Desc: "Pipeline pending"}
+	_, err := spc.CreateStatus("myorg", "myrepo", "sha1", input)
+	require.NoError(t, err)
+	assert.Equal(t, "/retest", data.PullRequestComments[12][0].Body)
+	assert.Equal(t, "Pipeline pending", data.Statuses["sha1"][0].Desc)
+
+	spc = spc.WithContext(eventid.ContextWithID(nil, "0a1b2c3d4e"))
+	require.NoError(t, spc.CreateComment("myorg", "myrepo", 12, true, "/retest"))
+	_, err = spc.CreateStatus("myorg", "myrepo", "sha1", input)
+	require.NoError(t, err)
+	assert.Equal(t, "/retest\n\n<!-- lighthouse-event: 0a1b2c3d4e -->", data.PullRequestComments[12][1].Body)
+	assert.Equal(t, "Pipeline pending (event 0a1b2c3d4e)", data.Statuses["sha1"][0].Desc)
+	assert.Equal(t, "Pipeline pending", input.Desc, "the input of the caller is not modified")
+}
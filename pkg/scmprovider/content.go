@@ -1,14 +1,12 @@
 package scmprovider
 
 import (
-	"context"
-
 	"github.com/jenkins-x/go-scm/scm"
 )
 
 // GetFile returns the file from git
 func (c *Client) GetFile(owner, repo, filepath, commit string) ([]byte, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	answer, r, err := c.client.Contents.Find(ctx, fullName, filepath, commit)
 	// handle files not existing nicely
@@ -24,7 +22,7 @@ func (c *Client) GetFile(owner, repo, filepath, commit string) ([]byte, error) {
 
 // ListFiles returns the files from git
 func (c *Client) ListFiles(owner, repo, filepath, commit string) ([]*scm.FileEntry, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	answer, _, err := c.client.Contents.List(ctx, fullName, filepath, commit)
 	return answer, err
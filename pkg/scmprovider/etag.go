@@ -0,0 +1,154 @@
+package scmprovider
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+const (
+	// DefaultETagCacheSize is the default number of responses cached to be revalidated with their ETag once the
+	// cache is enabled
+	DefaultETagCacheSize = 5000
+
+	// maxETagBodySize is the size of the largest response body cached
+	maxETagBodySize = 1 << 20
+)
+
+// etagCacheEntry is a response cached with its ETag
+type etagCacheEntry struct {
+	key    string
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// etagCache caches the responses of the GET calls carrying an ETag, shared by all the clients, evicting the least
+// recently used ones
+type etagCache struct {
+	lock    sync.Mutex
+	maxSize int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// etags is disabled until SetETagCacheSize is called so that the tests mutating the responses of the fake
+// providers are not affected
+var etags = &etagCache{order: list.New(), entries: map[string]*list.Element{}}
+
+// SetETagCacheSize sets the number of responses cached to be revalidated with their ETag, the cache being disabled
+// if the size is not positive
+func SetETagCacheSize(size int) {
+	etags.lock.Lock()
+	defer etags.lock.Unlock()
+	etags.maxSize = size
+	etags.order = list.New()
+	etags.entries = map[string]*list.Element{}
+}
+
+func (c *etagCache) enabled() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.maxSize > 0
+}
+
+func (c *etagCache) get(key string) (*etagCacheEntry, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*etagCacheEntry), true
+}
+
+func (c *etagCache) put(entry *etagCacheEntry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.maxSize <= 0 {
+		return
+	}
+	if e, ok := c.entries[entry.key]; ok {
+		e.Value = entry
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*etagCacheEntry).key)
+	}
+}
+
+// etagTransport is a round tripper revalidating the cached responses of the GET calls with If-None-Match, the
+// provider answering 304 Not Modified without a body if they did not change, which GitHub does not count against
+// the rate limit
+type etagTransport struct {
+	base http.RoundTripper
+	// token identifies the token of the client, as the responses depend on what it can access
+	token string
+}
+
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || !etags.enabled() {
+		return t.base.RoundTrip(req)
+	}
+	key := t.token + " " + req.Header.Get("Accept") + " " + req.URL.String()
+	cached, ok := etags.get(key)
+	if ok {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if ok && resp.StatusCode == http.StatusNotModified {
+		etagCacheLookups.WithLabelValues("hit").Inc()
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		// the cached headers along with the fresh ones, e.g. the rate limit remaining
+		header := cached.header.Clone()
+		for k, v := range resp.Header {
+			header[k] = v
+		}
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(cached.body)),
+			ContentLength: int64(len(cached.body)),
+			Request:       resp.Request,
+		}, nil
+	}
+	etagCacheLookups.WithLabelValues("miss").Inc()
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" || resp.ContentLength > maxETagBodySize {
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxETagBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxETagBodySize {
+		// too large to be cached, the rest of the body is still read by the caller
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	etags.put(&etagCacheEntry{key: key, etag: etag, header: resp.Header.Clone(), body: body})
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
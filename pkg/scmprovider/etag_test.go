@@ -0,0 +1,71 @@
+package scmprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/jenkins-x/go-scm/scm/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestETagCache(t *testing.T) {
+	SetETagCacheSize(DefaultETagCacheSize)
+	defer SetETagCacheSize(0)
+
+	login := "bot"
+	var calls, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		etag := `"` + login + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"login": "` + login + `"}`))
+	}))
+	defer server.Close()
+
+	client, err := factory.NewClient("github", server.URL, "")
+	require.NoError(t, err)
+	InstrumentClient(client, t.Name())
+
+	for i := 0; i < 2; i++ {
+		user, _, err := client.Users.Find(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "bot", user.Login)
+	}
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 1, notModified, "the cached response is revalidated")
+
+	login = "renamed"
+	user, _, err := client.Users.Find(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", user.Login, "the modified response replaces the cached one")
+
+	other, err := factory.NewClient("github", server.URL, "")
+	require.NoError(t, err)
+	InstrumentClient(other, "other-token")
+	_, _, err = other.Users.Find(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, notModified, "the responses are cached per token")
+}
+
+func TestPooled(t *testing.T) {
+	assert.Equal(t, sharedTransport, pooled(nil))
+	assert.Equal(t, sharedTransport, pooled(http.DefaultTransport))
+
+	tr := &oauth2.Transport{}
+	assert.Equal(t, sharedTransport, pooled(tr).(*oauth2.Transport).Base)
+	assert.Nil(t, tr.Base, "the transport of the client is not modified")
+
+	custom := &transport.Custom{Base: &http.Transport{}}
+	assert.Equal(t, custom, pooled(custom), "a transport which does not use the default one keeps its own")
+}
@@ -50,6 +50,10 @@ type SCMClient struct {
 	Reviews             map[int][]*scm.Review
 	CombinedStatuses    map[string]*scm.CombinedStatus
 	CreatedStatuses     map[string][]*scm.StatusInput
+	CheckRuns           map[int64]*scmprovider.CheckRunInput
+	GitLabStatuses      map[string][]*scmprovider.GitLabStatusInput
+	GitLabPipelines     map[string]int
+	MustSucceed         bool
 	IssueEvents         map[int][]*scm.ListedIssueEvent
 	Commits             map[string]*scm.Commit
 
@@ -108,6 +112,52 @@ func (f *SCMClient) SupportsGraphQL() bool {
 	return false
 }
 
+// SupportsChecks returns whether the provider supports check runs
+func (f *SCMClient) SupportsChecks() bool {
+	return true
+}
+
+// CreateCheckRun records a new check run
+func (f *SCMClient) CreateCheckRun(owner, repo string, input *scmprovider.CheckRunInput) (*scmprovider.CheckRun, error) {
+	if f.CheckRuns == nil {
+		f.CheckRuns = map[int64]*scmprovider.CheckRunInput{}
+	}
+	id := int64(len(f.CheckRuns) + 1)
+	f.CheckRuns[id] = input
+	return &scmprovider.CheckRun{ID: id, Name: input.Name, HeadSHA: input.HeadSHA, ExternalID: input.ExternalID, Status: input.Status, Conclusion: input.Conclusion}, nil
+}
+
+// UpdateCheckRun updates a recorded check run
+func (f *SCMClient) UpdateCheckRun(owner, repo string, id int64, input *scmprovider.CheckRunInput) (*scmprovider.CheckRun, error) {
+	existing, ok := f.CheckRuns[id]
+	if !ok {
+		return nil, fmt.Errorf("check run %d not found", id)
+	}
+	input.Name = existing.Name
+	input.HeadSHA = existing.HeadSHA
+	f.CheckRuns[id] = input
+	return &scmprovider.CheckRun{ID: id, Name: input.Name, HeadSHA: input.HeadSHA, ExternalID: input.ExternalID, Status: input.Status, Conclusion: input.Conclusion}, nil
+}
+
+// CreateGitLabStatus records a GitLab status
+func (f *SCMClient) CreateGitLabStatus(owner, repo, SHA string, input *scmprovider.GitLabStatusInput) (*scm.Status, error) {
+	if f.GitLabStatuses == nil {
+		f.GitLabStatuses = map[string][]*scmprovider.GitLabStatusInput{}
+	}
+	f.GitLabStatuses[SHA] = append(f.GitLabStatuses[SHA], input)
+	return &scm.Status{State: input.State, Label: input.Name, Desc: input.Description, Target: input.TargetURL}, nil
+}
+
+// FindGitLabPipeline returns the ID of the pipeline registered for SHA:name
+func (f *SCMClient) FindGitLabPipeline(owner, repo, SHA, name string) (int, error) {
+	return f.GitLabPipelines[SHA+":"+name], nil
+}
+
+// PipelinesMustSucceed returns whether pipelines must succeed to merge
+func (f *SCMClient) PipelinesMustSucceed(owner, repo string) (bool, error) {
+	return f.MustSucceed, nil
+}
+
 // QuoteAuthorForComment adds quotes around the author for @ usage if needed
 func (f *SCMClient) QuoteAuthorForComment(author string) string {
 	return author
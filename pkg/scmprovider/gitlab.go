@@ -0,0 +1,132 @@
+package scmprovider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+)
+
+// GitLabStatusInput is used to report a commit status on GitLab, with the GitLab specific options go-scm does not expose
+type GitLabStatusInput struct {
+	State       scm.State
+	Name        string
+	TargetURL   string
+	Description string
+	// Ref is the branch or ref the status is reported for, GitLab groups the statuses of a ref in the same pipeline
+	Ref string
+	// PipelineID is the ID of the pipeline the status is added to, if any
+	PipelineID int
+}
+
+type gitlabStatusRequest struct {
+	State       string `json:"state"`
+	Name        string `json:"name,omitempty"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Ref         string `json:"ref,omitempty"`
+	PipelineID  int    `json:"pipeline_id,omitempty"`
+}
+
+type gitlabStatus struct {
+	ID          int    `json:"id"`
+	Status      string `json:"status"`
+	Name        string `json:"name"`
+	TargetURL   string `json:"target_url"`
+	Description string `json:"description"`
+}
+
+type gitlabPipeline struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type gitlabProject struct {
+	ID                               int  `json:"id"`
+	OnlyAllowMergeIfPipelineSucceeds bool `json:"only_allow_merge_if_pipeline_succeeds"`
+}
+
+// CreateGitLabStatus reports a commit status on GitLab, grouping it in the pipeline of the ref or in the given pipeline
+func (c *Client) CreateGitLabStatus(owner, repo, sha string, input *GitLabStatusInput) (_ *scm.Status, err error) {
+	defer c.audit(audit.Event{Action: audit.StatusSet, Org: owner, Repo: repo, Target: input.Name, Details: map[string]string{"sha": sha, "state": input.State.String()}}, &err)
+	if c.client.Driver != scm.DriverGitlab {
+		return nil, fmt.Errorf("cannot report a GitLab status with the %s provider", c.ProviderType())
+	}
+	req := &gitlabStatusRequest{
+		State:       gitlabState(input.State),
+		Name:        input.Name,
+		TargetURL:   input.TargetURL,
+		Description: eventid.AppendToDescription(input.Description, c.eventID()),
+		Ref:         input.Ref,
+		PipelineID:  input.PipelineID,
+	}
+	path := fmt.Sprintf("api/v4/projects/%s/statuses/%s", gitlabProjectID(owner, repo), sha)
+	out := &gitlabStatus{}
+	if err = c.doJSON(http.MethodPost, path, req, out); err != nil {
+		return nil, err
+	}
+	return &scm.Status{
+		State:  input.State,
+		Label:  out.Name,
+		Desc:   out.Description,
+		Target: out.TargetURL,
+	}, nil
+}
+
+// FindGitLabPipeline returns the ID of the latest pipeline of the commit with the given name, 0 if there is none
+func (c *Client) FindGitLabPipeline(owner, repo, sha, name string) (int, error) {
+	params := url.Values{}
+	params.Set("sha", sha)
+	params.Set("name", name)
+	params.Set("order_by", "id")
+	params.Set("sort", "desc")
+	path := fmt.Sprintf("api/v4/projects/%s/pipelines?%s", gitlabProjectID(owner, repo), params.Encode())
+	var pipelines []gitlabPipeline
+	if err := c.doJSON(http.MethodGet, path, nil, &pipelines); err != nil {
+		return 0, err
+	}
+	for _, p := range pipelines {
+		// older GitLab versions ignore the name filter
+		if p.Name == name {
+			return p.ID, nil
+		}
+	}
+	return 0, nil
+}
+
+// PipelinesMustSucceed returns true if the repository only allows merge requests to be merged once their pipeline
+// succeeded. It is always false for the other providers than GitLab.
+func (c *Client) PipelinesMustSucceed(owner, repo string) (bool, error) {
+	if c.client.Driver != scm.DriverGitlab {
+		return false, nil
+	}
+	path := fmt.Sprintf("api/v4/projects/%s", gitlabProjectID(owner, repo))
+	project := &gitlabProject{}
+	if err := c.doJSON(http.MethodGet, path, nil, project); err != nil {
+		return false, err
+	}
+	return project.OnlyAllowMergeIfPipelineSucceeds, nil
+}
+
+// gitlabProjectID returns the URL encoded full name of the project, which GitLab accepts in place of its ID
+func gitlabProjectID(owner, repo string) string {
+	return url.PathEscape(scm.Join(owner, repo))
+}
+
+func gitlabState(state scm.State) string {
+	switch state {
+	case scm.StatePending:
+		return "pending"
+	case scm.StateRunning:
+		return "running"
+	case scm.StateSuccess:
+		return "success"
+	case scm.StateCanceled:
+		return "canceled"
+	default:
+		return "failed"
+	}
+}
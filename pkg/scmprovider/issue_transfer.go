@@ -0,0 +1,145 @@
+package scmprovider
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+)
+
+const (
+	transferIssueIDsQuery = `query($owner: String!, $repo: String!, $target: String!, $number: Int!) {
+  source: repository(owner: $owner, name: $repo) { issue(number: $number) { id } }
+  target: repository(owner: $owner, name: $target) { id }
+}`
+
+	transferIssueMutation = `mutation($issueId: ID!, $repositoryId: ID!) {
+  transferIssue(input: {issueId: $issueId, repositoryId: $repositoryId}) { issue { number url title } }
+}`
+)
+
+type graphqlRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+type transferIssueIDs struct {
+	Source *struct {
+		Issue *struct {
+			ID string `json:"id"`
+		} `json:"issue"`
+	} `json:"source"`
+	Target *struct {
+		ID string `json:"id"`
+	} `json:"target"`
+}
+
+type transferredIssue struct {
+	TransferIssue struct {
+		Issue struct {
+			Number int    `json:"number"`
+			URL    string `json:"url"`
+			Title  string `json:"title"`
+		} `json:"issue"`
+	} `json:"transferIssue"`
+}
+
+type gitlabIssue struct {
+	IID    int    `json:"iid"`
+	Title  string `json:"title"`
+	WebURL string `json:"web_url"`
+}
+
+// TransferIssue moves an issue to another repository of the same owner with the transfer API of the provider and
+// returns the moved issue. scm.ErrNotSupported is returned for the providers which cannot transfer issues, which
+// are only GitHub and GitLab.
+func (c *Client) TransferIssue(owner, repo string, number int, targetRepo string) (issue *scm.Issue, err error) {
+	defer func() {
+		details := map[string]string{"target": scm.Join(owner, targetRepo)}
+		if issue != nil {
+			details["number"] = strconv.Itoa(issue.Number)
+		}
+		c.audit(audit.Event{Action: audit.Transferred, Org: owner, Repo: repo, Number: number, Details: details}, &err)
+	}()
+	switch c.client.Driver {
+	case scm.DriverGithub:
+		return c.transferGitHubIssue(owner, repo, number, targetRepo)
+	case scm.DriverGitlab:
+		return c.transferGitLabIssue(owner, repo, number, targetRepo)
+	default:
+		return nil, scm.ErrNotSupported
+	}
+}
+
+func (c *Client) transferGitHubIssue(owner, repo string, number int, targetRepo string) (*scm.Issue, error) {
+	ids := &transferIssueIDs{}
+	vars := map[string]interface{}{
+		"owner":  owner,
+		"repo":   repo,
+		"target": targetRepo,
+		"number": number,
+	}
+	if err := c.doGraphQL(transferIssueIDsQuery, vars, ids); err != nil {
+		return nil, err
+	}
+	if ids.Source == nil || ids.Source.Issue == nil {
+		return nil, fmt.Errorf("issue %s/%s#%d not found", owner, repo, number)
+	}
+	if ids.Target == nil {
+		return nil, fmt.Errorf("repository %s/%s not found", owner, targetRepo)
+	}
+
+	out := &transferredIssue{}
+	vars = map[string]interface{}{
+		"issueId":      ids.Source.Issue.ID,
+		"repositoryId": ids.Target.ID,
+	}
+	if err := c.doGraphQL(transferIssueMutation, vars, out); err != nil {
+		return nil, err
+	}
+	moved := out.TransferIssue.Issue
+	return &scm.Issue{Number: moved.Number, Title: moved.Title, Link: moved.URL}, nil
+}
+
+func (c *Client) transferGitLabIssue(owner, repo string, number int, targetRepo string) (*scm.Issue, error) {
+	target := &gitlabProject{}
+	if err := c.doJSON(http.MethodGet, fmt.Sprintf("api/v4/projects/%s", gitlabProjectID(owner, targetRepo)), nil, target); err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("api/v4/projects/%s/issues/%d/move", gitlabProjectID(owner, repo), number)
+	out := &gitlabIssue{}
+	if err := c.doJSON(http.MethodPost, path, map[string]int{"to_project_id": target.ID}, out); err != nil {
+		return nil, err
+	}
+	return &scm.Issue{Number: out.IID, Title: out.Title, Link: out.WebURL}, nil
+}
+
+// doGraphQL sends a GraphQL request to the provider for the mutations the GraphQL client of go-scm does not support,
+// decoding the data of the response into out
+func (c *Client) doGraphQL(query string, vars map[string]interface{}, out interface{}) error {
+	if c.client.GraphQLURL == nil {
+		return scm.ErrNotSupported
+	}
+	res := &struct {
+		Data   interface{}    `json:"data"`
+		Errors []graphqlError `json:"errors"`
+	}{Data: out}
+	if err := c.doJSON(http.MethodPost, c.client.GraphQLURL.String(), &graphqlRequest{Query: query, Variables: vars}, res); err != nil {
+		return err
+	}
+	if len(res.Errors) > 0 {
+		var messages []string
+		for _, e := range res.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("GraphQL request failed: %s", strings.Join(messages, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package scmprovider
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferIssue(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(string(body), "transferIssue"):
+			assert.Contains(t, string(body), `"variables":{"issueId":"I_1","repositoryId":"R_2"}`)
+			_, _ = w.Write([]byte(`{"data":{"transferIssue":{"issue":{"number":7,"url":"https://github.com/org/other/issues/7","title":"Bug"}}}}`))
+		case r.URL.Path == "/api/graphql":
+			_, _ = w.Write([]byte(`{"data":{"source":{"issue":{"id":"I_1"}},"target":{"id":"R_2"}}}`))
+		case r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"id":42}`))
+		default:
+			assert.Equal(t, `{"to_project_id":42}`, string(body))
+			_, _ = w.Write([]byte(`{"iid":8,"title":"Bug","web_url":"https://gitlab.com/org/other/-/issues/8"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := factory.NewClient("github", server.URL, "")
+	require.NoError(t, err)
+	issue, err := ToClient(client, "bot").TransferIssue("org", "repo", 5, "other")
+	require.NoError(t, err)
+	assert.Equal(t, &scm.Issue{Number: 7, Title: "Bug", Link: "https://github.com/org/other/issues/7"}, issue)
+
+	client, err = factory.NewClient("gitlab", server.URL, "")
+	require.NoError(t, err)
+	issue, err = ToClient(client, "bot").TransferIssue("org", "repo", 5, "other")
+	require.NoError(t, err)
+	assert.Equal(t, &scm.Issue{Number: 8, Title: "Bug", Link: "https://gitlab.com/org/other/-/issues/8"}, issue)
+
+	assert.Equal(t, []string{
+		"POST /api/graphql",
+		"POST /api/graphql",
+		"GET /api/v4/projects/org%2Fother",
+		"POST /api/v4/projects/org%2Frepo/issues/5/move",
+	}, requests)
+
+	client, err = factory.NewClient("stash", server.URL, "")
+	require.NoError(t, err)
+	_, err = ToClient(client, "bot").TransferIssue("org", "repo", 5, "other")
+	assert.Equal(t, scm.ErrNotSupported, err)
+}
+
+func TestTransferIssueGraphQLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"source":null,"target":null},"errors":[{"message":"Could not resolve to a Repository"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := factory.NewClient("github", server.URL, "")
+	require.NoError(t, err)
+	_, err = ToClient(client, "bot").TransferIssue("org", "repo", 5, "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Could not resolve to a Repository")
+}
@@ -8,6 +8,8 @@ import (
 	"strconv"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -53,7 +55,7 @@ func (c *Client) Query(ctx context.Context, q interface{}, vars map[string]inter
 
 // Search query issues/PRs using a query string
 func (c *Client) Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *RateLimits, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	results, res, err := c.client.Issues.Search(ctx, opts)
 
 	rates := &RateLimits{}
@@ -63,7 +65,7 @@ func (c *Client) Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *RateLimits
 
 // ListIssueEvents list issue events
 func (c *Client) ListIssueEvents(org, repo string, number int) ([]*scm.ListedIssueEvent, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(org, repo)
 	var allEvents []*scm.ListedIssueEvent
 	var resp *scm.Response
@@ -87,7 +89,7 @@ func (c *Client) ListIssueEvents(org, repo string, number int) ([]*scm.ListedIss
 
 // AssignIssue assigns issue
 func (c *Client) AssignIssue(owner, repo string, number int, logins []string) error {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	_, err := c.client.Issues.AssignIssue(ctx, fullName, number, logins)
 	return err
@@ -95,51 +97,63 @@ func (c *Client) AssignIssue(owner, repo string, number int, logins []string) er
 
 // UnassignIssue unassigns issue
 func (c *Client) UnassignIssue(owner, repo string, number int, logins []string) error {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	_, err := c.client.Issues.UnassignIssue(ctx, fullName, number, logins)
 	return err
 }
 
 // AddLabel adds a label
-func (c *Client) AddLabel(owner, repo string, number int, label string, pr bool) error {
-	ctx := context.Background()
+func (c *Client) AddLabel(owner, repo string, number int, label string, pr bool) (err error) {
+	if c.queueAction(Action{Kind: audit.LabelAdded, Owner: owner, Repo: repo, Number: number, Label: label, PR: pr}) {
+		return nil
+	}
+	defer c.audit(audit.Event{Action: audit.LabelAdded, Org: owner, Repo: repo, Number: number, Target: label}, &err)
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	if pr {
 		if !c.SupportsPRLabels() {
 			return AddLabelToComment(c, owner, repo, number, label)
 		}
-		_, err := c.client.PullRequests.AddLabel(ctx, fullName, number, label)
+		_, err = c.client.PullRequests.AddLabel(ctx, fullName, number, label)
 		return err
 	}
-	_, err := c.client.Issues.AddLabel(ctx, fullName, number, label)
+	_, err = c.client.Issues.AddLabel(ctx, fullName, number, label)
 	return err
 }
 
 // RemoveLabel removes labesl
-func (c *Client) RemoveLabel(owner, repo string, number int, label string, pr bool) error {
-	ctx := context.Background()
+func (c *Client) RemoveLabel(owner, repo string, number int, label string, pr bool) (err error) {
+	if c.queueAction(Action{Kind: audit.LabelRemoved, Owner: owner, Repo: repo, Number: number, Label: label, PR: pr}) {
+		return nil
+	}
+	defer c.audit(audit.Event{Action: audit.LabelRemoved, Org: owner, Repo: repo, Number: number, Target: label}, &err)
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	if pr {
 		if !c.SupportsPRLabels() {
 			return DeleteLabelFromComment(c, owner, repo, number, label)
 		}
-		_, err := c.client.PullRequests.DeleteLabel(ctx, fullName, number, label)
+		_, err = c.client.PullRequests.DeleteLabel(ctx, fullName, number, label)
 		return err
 	}
-	_, err := c.client.Issues.DeleteLabel(ctx, fullName, number, label)
+	_, err = c.client.Issues.DeleteLabel(ctx, fullName, number, label)
 	return err
 }
 
 // DeleteComment delete comments
-func (c *Client) DeleteComment(org, repo string, number, ID int, pr bool) error {
-	ctx := context.Background()
+func (c *Client) DeleteComment(org, repo string, number, ID int, pr bool) (err error) {
+	if c.queueAction(Action{Kind: audit.CommentDeleted, Owner: org, Repo: repo, Number: number, ID: ID, PR: pr}) {
+		return nil
+	}
+	defer c.audit(audit.Event{Action: audit.CommentDeleted, Org: org, Repo: repo, Number: number, Target: strconv.Itoa(ID)}, &err)
+	ctx := c.requestContext()
 	fullName := c.repositoryName(org, repo)
 	if pr {
-		_, err := c.client.PullRequests.DeleteComment(ctx, fullName, number, ID)
+		_, err = c.client.PullRequests.DeleteComment(ctx, fullName, number, ID)
 		return err
 	}
-	_, err := c.client.Issues.DeleteComment(ctx, fullName, number, ID)
+	_, err = c.client.Issues.DeleteComment(ctx, fullName, number, ID)
 	return err
 }
 
@@ -169,7 +183,7 @@ func (c *Client) DeleteStaleComments(org, repo string, number int, comments []*s
 
 // ListIssueComments list comments associated with an issue
 func (c *Client) ListIssueComments(org, repo string, number int) ([]*scm.Comment, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(org, repo)
 	var allComments []*scm.Comment
 	var resp *scm.Response
@@ -193,7 +207,7 @@ func (c *Client) ListIssueComments(org, repo string, number int) ([]*scm.Comment
 
 // GetIssueLabels returns the issue labels
 func (c *Client) GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(org, repo)
 	var allLabels []*scm.Label
 	var resp *scm.Response
@@ -230,12 +244,16 @@ func (c *Client) GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.L
 }
 
 // CreateComment create a comment
-func (c *Client) CreateComment(owner, repo string, number int, pr bool, comment string) error {
+func (c *Client) CreateComment(owner, repo string, number int, pr bool, comment string) (err error) {
+	if c.queueAction(Action{Kind: audit.CommentCreated, Owner: owner, Repo: repo, Number: number, Body: comment, PR: pr}) {
+		return nil
+	}
+	defer c.audit(audit.Event{Action: audit.CommentCreated, Org: owner, Repo: repo, Number: number}, &err)
 	fullName := c.repositoryName(owner, repo)
 	commentInput := scm.CommentInput{
-		Body: comment,
+		Body: eventid.AppendToComment(comment, c.eventID()),
 	}
-	ctx := context.Background()
+	ctx := c.requestContext()
 	if pr {
 		_, response, err := c.client.PullRequests.CreateComment(ctx, fullName, number, &commentInput)
 		if err != nil {
@@ -262,12 +280,16 @@ func (c *Client) CreateComment(owner, repo string, number int, pr bool, comment
 }
 
 // EditComment edit a comment
-func (c *Client) EditComment(owner, repo string, number int, id int, comment string, pr bool) error {
+func (c *Client) EditComment(owner, repo string, number int, id int, comment string, pr bool) (err error) {
+	if c.queueAction(Action{Kind: audit.CommentEdited, Owner: owner, Repo: repo, Number: number, ID: id, Body: comment, PR: pr}) {
+		return nil
+	}
+	defer c.audit(audit.Event{Action: audit.CommentEdited, Org: owner, Repo: repo, Number: number, Target: strconv.Itoa(id)}, &err)
 	fullName := c.repositoryName(owner, repo)
 	commentInput := scm.CommentInput{
-		Body: comment,
+		Body: eventid.AppendToComment(comment, c.eventID()),
 	}
-	ctx := context.Background()
+	ctx := c.requestContext()
 	if pr {
 		_, response, err := c.client.PullRequests.EditComment(ctx, fullName, number, id, &commentInput)
 		if err != nil {
@@ -294,22 +316,56 @@ func (c *Client) EditComment(owner, repo string, number int, id int, comment str
 }
 
 // ReopenIssue reopen an issue
-func (c *Client) ReopenIssue(owner, repo string, number int) error {
-	ctx := context.Background()
+func (c *Client) ReopenIssue(owner, repo string, number int) (err error) {
+	defer c.audit(audit.Event{Action: audit.Reopened, Org: owner, Repo: repo, Number: number}, &err)
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
-	_, err := c.client.Issues.Reopen(ctx, fullName, number)
+	_, err = c.client.Issues.Reopen(ctx, fullName, number)
 	return err
 }
 
+// GetIssue returns an issue
+func (c *Client) GetIssue(owner, repo string, number int) (*scm.Issue, error) {
+	ctx := c.requestContext()
+	fullName := c.repositoryName(owner, repo)
+	issue, _, err := c.client.Issues.Find(ctx, fullName, number)
+	return issue, err
+}
+
+// ListIssues lists a page of the issues of a repository, the most recent first. Some providers list the pull
+// requests among the issues.
+func (c *Client) ListIssues(owner, repo string, opts scm.IssueListOptions) ([]*scm.Issue, error) {
+	ctx := c.requestContext()
+	fullName := c.repositoryName(owner, repo)
+	issues, _, err := c.client.Issues.List(ctx, fullName, opts)
+	return issues, err
+}
+
+// CreateIssue creates an issue
+func (c *Client) CreateIssue(owner, repo, title, body string) (issue *scm.Issue, err error) {
+	defer func() {
+		event := audit.Event{Action: audit.IssueCreated, Org: owner, Repo: repo}
+		if issue != nil {
+			event.Number = issue.Number
+		}
+		c.audit(event, &err)
+	}()
+	ctx := c.requestContext()
+	fullName := c.repositoryName(owner, repo)
+	issue, _, err = c.client.Issues.Create(ctx, fullName, &scm.IssueInput{Title: title, Body: body})
+	return issue, err
+}
+
 // FindIssues find issues
 func (c *Client) FindIssues(query, sort string, asc bool) ([]scm.Issue, error) {
 	return nil, scm.ErrNotSupported
 }
 
 // CloseIssue close issue
-func (c *Client) CloseIssue(owner, repo string, number int) error {
-	ctx := context.Background()
+func (c *Client) CloseIssue(owner, repo string, number int) (err error) {
+	defer c.audit(audit.Event{Action: audit.Closed, Org: owner, Repo: repo, Number: number}, &err)
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
-	_, err := c.client.Issues.Close(ctx, fullName, number)
+	_, err = c.client.Issues.Close(ctx, fullName, number)
 	return err
 }
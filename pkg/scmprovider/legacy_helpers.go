@@ -149,6 +149,9 @@ type GenericCommentEvent struct {
 	IssueBody   string
 	IssueLink   string
 	GUID        string
+	// Sender is the user who triggered the event according to the payload, e.g. the editor of the comment.
+	// It is empty if the provider does not tell.
+	Sender scm.User
 }
 
 // ReviewAction is the action that a review can be made with.
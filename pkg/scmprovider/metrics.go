@@ -0,0 +1,128 @@
+package scmprovider
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	apiCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_scm_api_calls_total",
+		Help: "A counter of the calls made to the API of the SCM provider, by provider, method and response code.",
+	}, []string{"provider", "method", "code"})
+	rateLimitRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lighthouse_scm_rate_limit_remaining",
+		Help: "The number of calls to the API of the SCM provider remaining in the current rate limit window.",
+	}, []string{"provider"})
+	rateLimitDelayed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_scm_rate_limit_delayed_total",
+		Help: "A counter of the calls to the API of the SCM provider delayed as its rate limit is nearly exhausted, by provider and priority.",
+	}, []string{"provider", "priority"})
+	rateLimitShed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_scm_rate_limit_shed_total",
+		Help: "A counter of the calls to the API of the SCM provider shed as its rate limit is nearly exhausted, by provider and priority.",
+	}, []string{"provider", "priority"})
+	permissionCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_scm_permission_cache_lookups_total",
+		Help: "A counter of the lookups of the permissions, the memberships and the teams in their cache, by lookup and result (hit or miss).",
+	}, []string{"lookup", "result"})
+	changesCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_scm_changes_cache_lookups_total",
+		Help: "A counter of the lookups of the changes of the pull requests in the cache of their webhook, by result (hit or miss).",
+	}, []string{"result"})
+	actionQueueActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_scm_action_queue_actions_total",
+		Help: "A counter of the attempts of the actions of the plugins queued, by kind and result (applied, retried or dropped).",
+	}, []string{"kind", "result"})
+	actionQueuePending = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "lighthouse_scm_action_queue_pending_batches",
+		Help: "The number of batches of actions of the plugins queued and not applied yet.",
+	})
+	etagCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_scm_etag_cache_lookups_total",
+		Help: "A counter of the GET calls to the API of the SCM provider, by result (hit if the cached response was not modified, miss otherwise).",
+	}, []string{"result"})
+)
+
+// rateLimitHeaders are the headers the SCM providers return the remaining rate limit in
+var rateLimitHeaders = []string{"X-RateLimit-Remaining", "RateLimit-Remaining"}
+
+func init() {
+	prometheus.MustRegister(apiCalls)
+	prometheus.MustRegister(rateLimitRemaining)
+	prometheus.MustRegister(rateLimitDelayed)
+	prometheus.MustRegister(rateLimitShed)
+	prometheus.MustRegister(permissionCacheLookups)
+	prometheus.MustRegister(changesCacheLookups)
+	prometheus.MustRegister(actionQueueActions)
+	prometheus.MustRegister(actionQueuePending)
+	prometheus.MustRegister(etagCacheLookups)
+}
+
+// InstrumentClient records the calls made by the go-scm client, and the rate limit remaining as reported by the
+// provider, in the metrics. The calls share the rate limit budget of the token of the client for the resource they
+// are counted against, the low priority ones being delayed or shed once it is nearly exhausted. The GET calls are revalidated with the ETag of their cached
+// response once the cache is enabled, and the connections are shared with the other clients. It must be called once
+// the transport of the client is configured.
+func InstrumentClient(client *scm.Client, token string) {
+	var httpClient http.Client
+	if client.Client != nil {
+		if _, ok := client.Client.Transport.(*etagTransport); ok {
+			return
+		}
+		httpClient = *client.Client
+	}
+	provider := client.Driver.String()
+	httpClient.Transport = &etagTransport{
+		base:  &metricsTransport{base: pooled(httpClient.Transport), provider: provider, token: tokenKey(provider, token)},
+		token: tokenKey(provider, token),
+	}
+	client.Client = &httpClient
+}
+
+// metricsTransport is a round tripper recording the calls made to the SCM provider and enforcing its rate limit
+// budget
+type metricsTransport struct {
+	base     http.RoundTripper
+	provider string
+	token    string
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	priority := requestPriority(req)
+	resource := requestResource(req)
+	delay, ok := budgetFor(t.token, resource).admit(priority)
+	if !ok {
+		rateLimitShed.WithLabelValues(t.provider, priority.String()).Inc()
+		return nil, ErrRateLimitBudgetExhausted
+	}
+	if delay > 0 {
+		rateLimitDelayed.WithLabelValues(t.provider, priority.String()).Inc()
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		apiCalls.WithLabelValues(t.provider, req.Method, "error").Inc()
+		return resp, err
+	}
+	apiCalls.WithLabelValues(t.provider, req.Method, strconv.Itoa(resp.StatusCode)).Inc()
+	if remaining, ok := intHeader(resp.Header, rateLimitHeaders); ok {
+		rateLimitRemaining.WithLabelValues(t.provider).Set(float64(remaining))
+	}
+	if r := resp.Header.Get(rateLimitResourceHeader); r != "" {
+		resource = r
+	}
+	budgetFor(t.token, resource).update(resp.Header)
+	return resp, nil
+}
@@ -0,0 +1,34 @@
+package scmprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"login": "bot"}`))
+	}))
+	defer server.Close()
+
+	client, err := factory.NewClient("github", server.URL, "")
+	require.NoError(t, err)
+	InstrumentClient(client, "")
+	InstrumentClient(client, "")
+
+	before := testutil.ToFloat64(apiCalls.WithLabelValues("github", http.MethodGet, "200"))
+	_, _, err = client.Users.Find(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(apiCalls.WithLabelValues("github", http.MethodGet, "200")))
+	assert.Equal(t, 4321.0, testutil.ToFloat64(rateLimitRemaining.WithLabelValues("github")))
+}
@@ -1,14 +1,12 @@
 package scmprovider
 
 import (
-	"context"
-
 	"github.com/jenkins-x/go-scm/scm"
 )
 
 // ClearMilestone clears milestone
 func (c *Client) ClearMilestone(org, repo string, num int, isPR bool) error {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(org, repo)
 	var err error
 	if isPR {
@@ -21,7 +19,7 @@ func (c *Client) ClearMilestone(org, repo string, num int, isPR bool) error {
 
 // SetMilestone sets milestone
 func (c *Client) SetMilestone(org, repo string, issueNum, milestoneNum int, isPR bool) error {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(org, repo)
 	var err error
 	if isPR {
@@ -34,7 +32,7 @@ func (c *Client) SetMilestone(org, repo string, issueNum, milestoneNum int, isPR
 
 // ListMilestones list milestones
 func (c *Client) ListMilestones(org, repo string) ([]*scm.Milestone, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(org, repo)
 	var resp *scm.Response
 	var milestones []*scm.Milestone
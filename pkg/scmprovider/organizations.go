@@ -1,14 +1,28 @@
 package scmprovider
 
 import (
-	"context"
+	"fmt"
+	"net/http"
+	"strconv"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/pkg/errors"
 )
 
 // ListTeams list teams in the organisation
 func (c *Client) ListTeams(org string) ([]*scm.Team, error) {
-	ctx := context.Background()
+	answer, err := c.cachedLookup(org, "teams", "", func() (interface{}, error) {
+		return c.listTeams(org)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]*scm.Team(nil), answer.([]*scm.Team)...), nil
+}
+
+func (c *Client) listTeams(org string) ([]*scm.Team, error) {
+	ctx := c.requestContext()
 	var allTeams []*scm.Team
 	var resp *scm.Response
 	var teams []*scm.Team
@@ -31,7 +45,17 @@ func (c *Client) ListTeams(org string) ([]*scm.Team, error) {
 
 // ListTeamMembers list the team members
 func (c *Client) ListTeamMembers(id int, role string) ([]*scm.TeamMember, error) {
-	ctx := context.Background()
+	answer, err := c.cachedLookup("", "team_members", strconv.Itoa(id)+"/"+role, func() (interface{}, error) {
+		return c.listTeamMembers(id, role)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]*scm.TeamMember(nil), answer.([]*scm.TeamMember)...), nil
+}
+
+func (c *Client) listTeamMembers(id int, role string) ([]*scm.TeamMember, error) {
+	ctx := c.requestContext()
 	var allMembers []*scm.TeamMember
 	var resp *scm.Response
 	var members []*scm.TeamMember
@@ -54,7 +78,17 @@ func (c *Client) ListTeamMembers(id int, role string) ([]*scm.TeamMember, error)
 
 // ListOrgMembers list the org members
 func (c *Client) ListOrgMembers(org string) ([]*scm.TeamMember, error) {
-	ctx := context.Background()
+	answer, err := c.cachedLookup(org, "org_members", "", func() (interface{}, error) {
+		return c.listOrgMembers(org)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]*scm.TeamMember(nil), answer.([]*scm.TeamMember)...), nil
+}
+
+func (c *Client) listOrgMembers(org string) ([]*scm.TeamMember, error) {
+	ctx := c.requestContext()
 	var allMembers []*scm.TeamMember
 	var resp *scm.Response
 	var members []*scm.TeamMember
@@ -77,7 +111,23 @@ func (c *Client) ListOrgMembers(org string) ([]*scm.TeamMember, error) {
 
 // IsOrgAdmin returns whether this user is an admin of the org
 func (c *Client) IsOrgAdmin(org, user string) (bool, error) {
-	ctx := context.Background()
-	ok, _, err := c.client.Organizations.IsAdmin(ctx, org, user)
-	return ok, err
+	ok, err := c.cachedLookup(org, "admin", user, func() (interface{}, error) {
+		ok, _, err := c.client.Organizations.IsAdmin(c.requestContext(), org, user)
+		return ok, err
+	})
+	if err != nil {
+		return false, err
+	}
+	return ok.(bool), nil
+}
+
+// InviteOrgMember invites a user to join an org as a member, which is only supported by GitHub.
+// scm.ErrNotSupported is returned for the other providers.
+func (c *Client) InviteOrgMember(org, user string) (err error) {
+	defer c.audit(audit.Event{Action: audit.MemberInvited, Org: org, Target: user}, &err)
+	if c.client.Driver != scm.DriverGithub {
+		return scm.ErrNotSupported
+	}
+	path := fmt.Sprintf("orgs/%s/memberships/%s", org, user)
+	return errors.Wrapf(c.doJSON(http.MethodPut, path, map[string]string{"role": "member"}, nil), "inviting %s to %s", user, org)
 }
@@ -0,0 +1,112 @@
+package scmprovider
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// DefaultPermissionCacheTTL is the default duration the permissions, the memberships and the teams are cached for
+// once the cache is enabled
+const DefaultPermissionCacheTTL = 5 * time.Minute
+
+// permissionCacheKey identifies a cached lookup of the permissions, the memberships or the teams of an organisation
+type permissionCacheKey struct {
+	server string
+	// token identifies the token of the client, as the bot users or GitHub App installations of a server may not see
+	// the same permissions, memberships and teams
+	token string
+	// org is the organisation the lookup is about, empty for the members of a team which are looked up by the ID
+	// of the team
+	org    string
+	lookup string
+	key    string
+}
+
+type permissionCacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// permissionCache caches the lookups of the permissions, the memberships and the teams, shared by all the clients
+// of the same token as the plugins check them for most of the comments
+type permissionCache struct {
+	lock    sync.Mutex
+	ttl     time.Duration
+	entries map[permissionCacheKey]permissionCacheEntry
+}
+
+// permissions is disabled until SetPermissionCacheTTL is called so that the tests mutating the memberships of the
+// fake provider are not affected
+var permissions = &permissionCache{entries: map[permissionCacheKey]permissionCacheEntry{}}
+
+// SetPermissionCacheTTL sets how long the lookups of the permissions, the memberships and the teams are cached for,
+// the cache being disabled if the TTL is not positive
+func SetPermissionCacheTTL(ttl time.Duration) {
+	permissions.lock.Lock()
+	defer permissions.lock.Unlock()
+	permissions.ttl = ttl
+	permissions.entries = map[permissionCacheKey]permissionCacheEntry{}
+}
+
+// InvalidatePermissions forgets the cached permissions, memberships and teams of an organisation or user, along with
+// the cached members of all the teams, e.g. when a membership webhook is received for it
+func InvalidatePermissions(org string) {
+	org = strings.ToLower(org)
+	permissions.lock.Lock()
+	defer permissions.lock.Unlock()
+	for k := range permissions.entries {
+		if k.org == org || k.org == "" {
+			delete(permissions.entries, k)
+		}
+	}
+}
+
+// get returns the cached result of a lookup, calling fetch and caching its result if it is not cached or expired.
+// The errors are not cached.
+func (pc *permissionCache) get(key permissionCacheKey, fetch func() (interface{}, error)) (interface{}, error) {
+	key.org = strings.ToLower(key.org)
+	key.key = strings.ToLower(key.key)
+	pc.lock.Lock()
+	ttl := pc.ttl
+	entry, ok := pc.entries[key]
+	pc.lock.Unlock()
+	if ttl <= 0 {
+		return fetch()
+	}
+	if ok && now().Before(entry.expires) {
+		permissionCacheLookups.WithLabelValues(key.lookup, "hit").Inc()
+		return entry.value, nil
+	}
+	permissionCacheLookups.WithLabelValues(key.lookup, "miss").Inc()
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	pc.lock.Lock()
+	pc.entries[key] = permissionCacheEntry{value: value, expires: now().Add(ttl)}
+	pc.lock.Unlock()
+	return value, nil
+}
+
+// cachedLookup returns the cached result of a lookup about an organisation made with the client
+func (c *Client) cachedLookup(org, lookup, key string, fetch func() (interface{}, error)) (interface{}, error) {
+	server := ""
+	if u := c.client.BaseURL; u != nil {
+		server = u.Host
+	}
+	return permissions.get(permissionCacheKey{server: server, token: clientToken(c.client), org: org, lookup: lookup, key: key}, fetch)
+}
+
+// clientToken returns the tokenKey of the token the client was instrumented with, empty if it was not
+func clientToken(client *scm.Client) string {
+	if client.Client == nil {
+		return ""
+	}
+	if t, ok := client.Client.Transport.(*etagTransport); ok {
+		return t.token
+	}
+	return ""
+}
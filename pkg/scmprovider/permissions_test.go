@@ -0,0 +1,79 @@
+package scmprovider
+
+import (
+	"testing"
+	"time"
+
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionCache(t *testing.T) {
+	client, data := fakescm.NewDefault()
+	spc := ToClient(client, "bot")
+	data.Collaborators = []string{"alice"}
+	data.UserPermissions["myorg/myrepo"] = map[string]string{"alice": "write"}
+
+	// the cache is disabled by default
+	ok, err := spc.IsCollaborator("myorg", "myrepo", "bob")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	data.Collaborators = append(data.Collaborators, "bob")
+	ok, err = spc.IsCollaborator("myorg", "myrepo", "bob")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	SetPermissionCacheTTL(time.Minute)
+	defer SetPermissionCacheTTL(0)
+
+	ok, err = spc.HasPermission("myorg", "myrepo", "alice", "write")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	collabs, err := spc.ListCollaborators("myorg", "myrepo")
+	require.NoError(t, err)
+	assert.Len(t, collabs, 2)
+
+	data.Collaborators = []string{"bob"}
+	data.UserPermissions["myorg/myrepo"]["alice"] = "read"
+	ok, err = spc.HasPermission("myorg", "myrepo", "Alice", "write")
+	require.NoError(t, err)
+	assert.True(t, ok, "the permission is cached regardless of the case of the login")
+	collabs, err = spc.ListCollaborators("myorg", "myrepo")
+	require.NoError(t, err)
+	assert.Len(t, collabs, 2, "the collaborators are cached")
+
+	InvalidatePermissions("otherorg")
+	ok, err = spc.HasPermission("myorg", "myrepo", "alice", "write")
+	require.NoError(t, err)
+	assert.True(t, ok, "only the organisation of the membership webhook is invalidated")
+
+	InvalidatePermissions("MyOrg")
+	ok, err = spc.HasPermission("myorg", "myrepo", "alice", "write")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	collabs, err = spc.ListCollaborators("myorg", "myrepo")
+	require.NoError(t, err)
+	assert.Len(t, collabs, 1)
+
+	// the clients of other tokens do not share the entries
+	InstrumentClient(client, "bot-token")
+	data.UserPermissions["myorg/myrepo"]["alice"] = "write"
+	ok, err = spc.HasPermission("myorg", "myrepo", "alice", "write")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	other, otherData := fakescm.NewDefault()
+	InstrumentClient(other, "other-token")
+	otherData.UserPermissions["myorg/myrepo"] = map[string]string{"alice": "read"}
+	ok, err = ToClient(other, "bot").HasPermission("myorg", "myrepo", "alice", "write")
+	require.NoError(t, err)
+	assert.False(t, ok, "the permission cached for another token is not used")
+
+	// the entries expire after the TTL
+	defer func() { now = time.Now }()
+	data.UserPermissions["myorg/myrepo"]["alice"] = RoleAdmin
+	now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	ok, err = spc.HasPermission("myorg", "myrepo", "alice", RoleAdmin)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
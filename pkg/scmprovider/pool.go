@@ -0,0 +1,56 @@
+package scmprovider
+
+import (
+	"net/http"
+
+	"github.com/jenkins-x/go-scm/scm/transport"
+	"golang.org/x/oauth2"
+)
+
+// sharedTransport is the transport shared by the clients of the SCM providers so that they reuse their connections,
+// HTTP/2 ones if the provider supports them, instead of the concurrent calls of the plugins churning through the two
+// idle connections per host kept by the default transport
+var sharedTransport = newSharedTransport()
+
+func newSharedTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ForceAttemptHTTP2 = true
+	t.MaxIdleConns = 200
+	t.MaxIdleConnsPerHost = 100
+	return t
+}
+
+// pooled returns the round tripper making its calls with the shared transport if it makes them with the default
+// one, the authenticating round trippers being copied so that the ones of other clients are not modified
+func pooled(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil || rt == http.DefaultTransport {
+		return sharedTransport
+	}
+	switch t := rt.(type) {
+	case *oauth2.Transport:
+		c := *t
+		c.Base = pooled(t.Base)
+		return &c
+	case *transport.Custom:
+		c := *t
+		c.Base = pooled(t.Base)
+		return &c
+	case *transport.PrivateToken:
+		c := *t
+		c.Base = pooled(t.Base)
+		return &c
+	case *transport.BearerToken:
+		c := *t
+		c.Base = pooled(t.Base)
+		return &c
+	case *transport.BasicAuth:
+		c := *t
+		c.Base = pooled(t.Base)
+		return &c
+	case *transport.Authorization:
+		c := *t
+		c.Base = pooled(t.Base)
+		return &c
+	}
+	return rt
+}
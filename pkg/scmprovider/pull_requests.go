@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
 	"github.com/pkg/errors"
 )
 
@@ -18,7 +19,7 @@ type MergeDetails struct {
 
 // GetPullRequest returns the pull request
 func (c *Client) GetPullRequest(owner, repo string, number int) (*scm.PullRequest, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	pr, _, err := c.client.PullRequests.Find(ctx, fullName, number)
 	if err != nil {
@@ -59,7 +60,7 @@ func (c *Client) populateFields(ctx context.Context, pr *scm.PullRequest, owner,
 
 // ListAllPullRequestsForFullNameRepo lists all pull requests in a full-name repository
 func (c *Client) ListAllPullRequestsForFullNameRepo(fullName string, opts scm.PullRequestListOptions) ([]*scm.PullRequest, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	var allPRs []*scm.PullRequest
 	var resp *scm.Response
 	var pagePRs []*scm.PullRequest
@@ -90,7 +91,7 @@ func (c *Client) ListAllPullRequestsForFullNameRepo(fullName string, opts scm.Pu
 
 // ListPullRequestComments list pull request comments
 func (c *Client) ListPullRequestComments(owner, repo string, number int) ([]*scm.Comment, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	var allComments []*scm.Comment
 	var resp *scm.Response
@@ -112,10 +113,20 @@ func (c *Client) ListPullRequestComments(owner, repo string, number int) ([]*scm
 	return allComments, nil
 }
 
-// GetPullRequestChanges returns the changes in a pull request
+// GetPullRequestChanges returns the changes in a pull request, listed at most once per webhook if the context of
+// the client has a cache of the changes
 func (c *Client) GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error) {
-	ctx := context.Background()
 	fullName := c.repositoryName(org, repo)
+	if cache := c.changesCache(); cache != nil {
+		return cache.get(fullName, number, func() ([]*scm.Change, error) {
+			return c.listPullRequestChanges(fullName, number)
+		})
+	}
+	return c.listPullRequestChanges(fullName, number)
+}
+
+func (c *Client) listPullRequestChanges(fullName string, number int) ([]*scm.Change, error) {
+	ctx := c.requestContext()
 	var allChanges []*scm.Change
 	var resp *scm.Response
 	var changes []*scm.Change
@@ -137,15 +148,16 @@ func (c *Client) GetPullRequestChanges(org, repo string, number int) ([]*scm.Cha
 }
 
 // Merge reopens a pull request
-func (c *Client) Merge(owner, repo string, number int, details MergeDetails) error {
-	ctx := context.Background()
+func (c *Client) Merge(owner, repo string, number int, details MergeDetails) (err error) {
+	defer c.audit(audit.Event{Action: audit.Merged, Org: owner, Repo: repo, Number: number, Target: details.SHA, Details: map[string]string{"method": details.MergeMethod}}, &err)
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	mergeOptions := &scm.PullRequestMergeOptions{
 		CommitTitle: details.CommitTitle,
 		SHA:         details.SHA,
 		MergeMethod: details.MergeMethod,
 	}
-	_, err := c.client.PullRequests.Merge(ctx, fullName, number, mergeOptions)
+	_, err = c.client.PullRequests.Merge(ctx, fullName, number, mergeOptions)
 	return err
 }
 
@@ -174,25 +186,42 @@ type MergeCommitsForbiddenError string
 
 func (e MergeCommitsForbiddenError) Error() string { return string(e) }
 
+// CreatePullRequest creates a pull request
+func (c *Client) CreatePullRequest(owner, repo string, input *scm.PullRequestInput) (pr *scm.PullRequest, err error) {
+	defer func() {
+		event := audit.Event{Action: audit.PullRequestCreated, Org: owner, Repo: repo, Target: input.Base}
+		if pr != nil {
+			event.Number = pr.Number
+		}
+		c.audit(event, &err)
+	}()
+	ctx := c.requestContext()
+	fullName := c.repositoryName(owner, repo)
+	pr, _, err = c.client.PullRequests.Create(ctx, fullName, input)
+	return pr, err
+}
+
 // ReopenPR reopens a pull request
-func (c *Client) ReopenPR(owner, repo string, number int) error {
-	ctx := context.Background()
+func (c *Client) ReopenPR(owner, repo string, number int) (err error) {
+	defer c.audit(audit.Event{Action: audit.Reopened, Org: owner, Repo: repo, Number: number}, &err)
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
-	_, err := c.client.PullRequests.Reopen(ctx, fullName, number)
+	_, err = c.client.PullRequests.Reopen(ctx, fullName, number)
 	return err
 }
 
 // ClosePR closes a pull request
-func (c *Client) ClosePR(owner, repo string, number int) error {
-	ctx := context.Background()
+func (c *Client) ClosePR(owner, repo string, number int) (err error) {
+	defer c.audit(audit.Event{Action: audit.Closed, Org: owner, Repo: repo, Number: number}, &err)
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
-	_, err := c.client.PullRequests.Close(ctx, fullName, number)
+	_, err = c.client.PullRequests.Close(ctx, fullName, number)
 	return err
 }
 
 // FindPullRequestsByAuthor finds all pull requests for a given author
 func (c *Client) FindPullRequestsByAuthor(owner, repo string, author string) ([]*scm.PullRequest, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	var allPullRequests []*scm.PullRequest
 	var resp *scm.Response
@@ -0,0 +1,194 @@
+package scmprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Priority is the priority of a call to the SCM provider once its rate limit is nearly exhausted
+type Priority int
+
+const (
+	// PriorityLow is the priority of the best-effort calls, e.g. the ones of the fun plugins, which are delayed then
+	// shed first
+	PriorityLow Priority = iota + 1
+	// PriorityNormal is the priority of the calls which are neither best-effort nor critical, delayed once the rate
+	// limit is nearly exhausted
+	PriorityNormal
+	// PriorityCritical is the priority of the calls reporting the statuses and merging the pull requests, which are
+	// never delayed
+	PriorityCritical
+)
+
+const (
+	// lowPriorityReserve is the fraction of the rate limit below which the low priority calls are delayed until the
+	// rate limit is reset, or shed if it is not reset soon enough
+	lowPriorityReserve = 0.2
+	// normalPriorityReserve is the fraction of the rate limit below which the normal priority calls are delayed
+	// until the rate limit is reset
+	normalPriorityReserve = 0.05
+	// maxRateLimitDelay is the longest a call is delayed for
+	maxRateLimitDelay = 30 * time.Second
+)
+
+var (
+	// ErrRateLimitBudgetExhausted is the error of the low priority calls shed as the rate limit is nearly exhausted
+	ErrRateLimitBudgetExhausted = errors.New("the rate limit of the SCM provider is nearly exhausted, the low priority call was shed")
+
+	// rateLimitLimitHeaders and rateLimitResetHeaders are the headers the SCM providers return the rate limit and
+	// the time it is reset at, in seconds since the epoch, in
+	rateLimitLimitHeaders = []string{"X-RateLimit-Limit", "RateLimit-Limit"}
+	rateLimitResetHeaders = []string{"X-RateLimit-Reset", "RateLimit-Reset"}
+
+	// rateLimitResourceHeader is the header GitHub returns the rate limit resource the call was counted against in,
+	// e.g. core, search or graphql, each of them having its own rate limit
+	rateLimitResourceHeader = "X-RateLimit-Resource"
+
+	// now is overridden by the tests
+	now = time.Now
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityCritical:
+		return "critical"
+	default:
+		return "normal"
+	}
+}
+
+type priorityKey struct{}
+
+// ContextWithPriority returns a context whose calls to the SCM provider have the given priority
+func ContextWithPriority(ctx context.Context, priority Priority) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// requestPriority returns the priority of the context of the request, if any. Otherwise the calls reporting the
+// statuses and merging the pull requests are critical.
+func requestPriority(req *http.Request) Priority {
+	if priority, ok := req.Context().Value(priorityKey{}).(Priority); ok {
+		return priority
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		path := req.URL.Path
+		if strings.Contains(path, "/statuses/") || strings.Contains(path, "/check-runs") || strings.HasSuffix(path, "/merge") {
+			return PriorityCritical
+		}
+	}
+	return PriorityNormal
+}
+
+// requestResource returns the rate limit resource of GitHub the request is counted against, the other providers
+// having a single rate limit counted as the core one
+func requestResource(req *http.Request) string {
+	path := strings.TrimPrefix(req.URL.Path, "/api/v3")
+	switch {
+	case strings.HasSuffix(path, "/graphql"):
+		return "graphql"
+	case strings.HasPrefix(path, "/search/"):
+		return "search"
+	}
+	return "core"
+}
+
+// rateLimitBudget tracks the remaining calls of the rate limit of a resource of a token, shared by all the clients
+// using it
+type rateLimitBudget struct {
+	lock      sync.Mutex
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+var (
+	budgetsLock sync.Mutex
+	budgets     = map[string]*rateLimitBudget{}
+)
+
+// tokenKey identifies the token of the provider without keeping it
+func tokenKey(provider, token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return provider + "/" + hex.EncodeToString(hash[:8])
+}
+
+// budgetFor returns the budget of the rate limit of the given resource of the token, identified by its tokenKey
+func budgetFor(token, resource string) *rateLimitBudget {
+	key := token + "/" + resource
+	budgetsLock.Lock()
+	defer budgetsLock.Unlock()
+	b := budgets[key]
+	if b == nil {
+		b = &rateLimitBudget{}
+		budgets[key] = b
+	}
+	return b
+}
+
+// admit returns how long a call of the given priority must be delayed for, and false if it must be shed instead
+func (b *rateLimitBudget) admit(priority Priority) (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	t := now()
+	if b.limit == 0 || priority == PriorityCritical || (!b.reset.IsZero() && !t.Before(b.reset)) {
+		return 0, true
+	}
+	reserve := normalPriorityReserve
+	if priority == PriorityLow {
+		reserve = lowPriorityReserve
+	}
+	if float64(b.remaining) > reserve*float64(b.limit) {
+		b.remaining--
+		return 0, true
+	}
+	delay := maxRateLimitDelay
+	if !b.reset.IsZero() {
+		delay = b.reset.Sub(t)
+	}
+	if delay > maxRateLimitDelay {
+		if priority == PriorityLow {
+			return 0, false
+		}
+		delay = maxRateLimitDelay
+	}
+	return delay, true
+}
+
+// update records the rate limit returned by the provider
+func (b *rateLimitBudget) update(header http.Header) {
+	remaining, ok := intHeader(header, rateLimitHeaders)
+	if !ok {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.remaining = remaining
+	if limit, ok := intHeader(header, rateLimitLimitHeaders); ok {
+		b.limit = limit
+	}
+	if reset, ok := intHeader(header, rateLimitResetHeaders); ok {
+		b.reset = time.Unix(int64(reset), 0)
+	}
+}
+
+func intHeader(header http.Header, names []string) (int, bool) {
+	for _, name := range names {
+		if value, err := strconv.Atoi(header.Get(name)); err == nil {
+			return value, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,176 @@
+package scmprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestPriority(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		ctx      context.Context
+		expected Priority
+	}{
+		{method: http.MethodPost, path: "/repos/myorg/myrepo/statuses/abc", expected: PriorityCritical},
+		{method: http.MethodPatch, path: "/repos/myorg/myrepo/check-runs/12", expected: PriorityCritical},
+		{method: http.MethodPut, path: "/repos/myorg/myrepo/pulls/1/merge", expected: PriorityCritical},
+		{method: http.MethodGet, path: "/repos/myorg/myrepo/statuses/abc", expected: PriorityNormal},
+		{method: http.MethodPost, path: "/repos/myorg/myrepo/issues/1/comments", expected: PriorityNormal},
+		{method: http.MethodPost, path: "/repos/myorg/myrepo/issues/1/comments", ctx: ContextWithPriority(nil, PriorityLow), expected: PriorityLow},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.ctx != nil {
+			req = req.WithContext(tc.ctx)
+		}
+		assert.Equal(t, tc.expected, requestPriority(req), "%s %s", tc.method, tc.path)
+	}
+}
+
+func TestRateLimitBudget(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	now = func() time.Time { return start }
+	defer func() { now = time.Now }()
+
+	header := func(remaining int, reset time.Time) http.Header {
+		h := http.Header{}
+		h.Set("X-RateLimit-Limit", "5000")
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		h.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		return h
+	}
+
+	b := &rateLimitBudget{}
+	delay, ok := b.admit(PriorityLow)
+	assert.True(t, ok, "the calls are admitted until the rate limit is known")
+	assert.Zero(t, delay)
+
+	b.update(header(2000, start.Add(time.Hour)))
+	delay, ok = b.admit(PriorityLow)
+	assert.True(t, ok)
+	assert.Zero(t, delay)
+
+	// below the reserve of the low priority calls
+	b.update(header(900, start.Add(time.Hour)))
+	_, ok = b.admit(PriorityLow)
+	assert.False(t, ok, "the low priority calls are shed when the reset is far")
+	delay, ok = b.admit(PriorityNormal)
+	assert.True(t, ok)
+	assert.Zero(t, delay)
+
+	b.update(header(900, start.Add(10*time.Second)))
+	delay, ok = b.admit(PriorityLow)
+	assert.True(t, ok, "the low priority calls are delayed when the reset is close")
+	assert.Equal(t, 10*time.Second, delay)
+
+	// below the reserve of the normal priority calls
+	b.update(header(100, start.Add(time.Hour)))
+	delay, ok = b.admit(PriorityNormal)
+	assert.True(t, ok)
+	assert.Equal(t, maxRateLimitDelay, delay)
+	delay, ok = b.admit(PriorityCritical)
+	assert.True(t, ok)
+	assert.Zero(t, delay)
+
+	// the rate limit was reset since
+	now = func() time.Time { return start.Add(2 * time.Hour) }
+	delay, ok = b.admit(PriorityLow)
+	assert.True(t, ok)
+	assert.Zero(t, delay)
+}
+
+func TestRateLimitShedsLowPriorityCalls(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "10")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"login": "bot"}`))
+	}))
+	defer server.Close()
+
+	client, err := factory.NewClient("github", server.URL, "")
+	require.NoError(t, err)
+	InstrumentClient(client, t.Name())
+
+	_, _, err = client.Users.Find(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+
+	_, _, err = client.Users.Find(ContextWithPriority(nil, PriorityLow))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ErrRateLimitBudgetExhausted.Error())
+	assert.Equal(t, 1, calls)
+
+	_, _, _ = client.Repositories.CreateStatus(context.Background(), "myorg/myrepo", "abc", &scm.StatusInput{State: scm.StateSuccess, Label: "lint"})
+	assert.Equal(t, 2, calls, "the critical calls are never delayed")
+}
+
+func TestRateLimitBudgetPerResource(t *testing.T) {
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resource, remaining := "core", "4000"
+		if strings.Contains(r.URL.Path, "/search/") {
+			resource, remaining = "search", "1"
+		}
+		calls[resource]++
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", remaining)
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.Header().Set("X-RateLimit-Resource", resource)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"login": "bot", "items": []}`))
+	}))
+	defer server.Close()
+
+	client, err := factory.NewClient("github", server.URL, "")
+	require.NoError(t, err)
+	InstrumentClient(client, t.Name())
+
+	get := func(ctx context.Context, path string) error {
+		res, err := client.Do(ctx, &scm.Request{Method: http.MethodGet, Path: path})
+		if err == nil {
+			res.Body.Close()
+		}
+		return err
+	}
+
+	// the search rate limit is nearly exhausted
+	require.NoError(t, get(context.Background(), "search/issues?q=is:pr"))
+	assert.Equal(t, 1, calls["search"])
+
+	require.NoError(t, get(ContextWithPriority(nil, PriorityLow), "user"), "the core calls do not count against the search rate limit")
+	assert.Equal(t, 1, calls["core"])
+
+	err = get(ContextWithPriority(nil, PriorityLow), "search/issues?q=is:pr")
+	require.Error(t, err, "the low priority search calls are shed")
+	assert.Contains(t, err.Error(), ErrRateLimitBudgetExhausted.Error())
+	assert.Equal(t, 1, calls["search"])
+
+	// the core rate limit does not refill the search one
+	require.NoError(t, get(context.Background(), "user"))
+	err = get(ContextWithPriority(nil, PriorityLow), "search/issues?q=is:pr")
+	require.Error(t, err)
+	assert.Equal(t, 1, calls["search"])
+}
+
+func TestRequestResource(t *testing.T) {
+	assert.Equal(t, "core", requestResource(httptest.NewRequest(http.MethodGet, "/repos/myorg/myrepo", nil)))
+	assert.Equal(t, "search", requestResource(httptest.NewRequest(http.MethodGet, "/search/issues", nil)))
+	assert.Equal(t, "search", requestResource(httptest.NewRequest(http.MethodGet, "/api/v3/search/issues", nil)))
+	assert.Equal(t, "graphql", requestResource(httptest.NewRequest(http.MethodPost, "/graphql", nil)))
+	assert.Equal(t, "graphql", requestResource(httptest.NewRequest(http.MethodPost, "/api/graphql", nil)))
+}
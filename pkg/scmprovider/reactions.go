@@ -0,0 +1,46 @@
+package scmprovider
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/pkg/errors"
+)
+
+// CreateReaction adds a reaction, e.g. heart or +1, to an issue or a pull request, which is supported by GitHub and
+// GitLab. scm.ErrNotSupported is returned for the other providers.
+func (c *Client) CreateReaction(org, repo string, number int, pr bool, reaction string) (err error) {
+	defer c.audit(audit.Event{Action: audit.ReactionCreated, Org: org, Repo: repo, Number: number, Target: reaction}, &err)
+	switch c.client.Driver {
+	case scm.DriverGithub:
+		path := fmt.Sprintf("repos/%s/%s/issues/%d/reactions", org, repo, number)
+		err = c.doJSON(http.MethodPost, path, map[string]string{"content": reaction}, nil)
+	case scm.DriverGitlab:
+		kind := "issues"
+		if pr {
+			kind = "merge_requests"
+		}
+		path := fmt.Sprintf("api/v4/projects/%s/%s/%d/award_emoji", gitlabProjectID(org, repo), kind, number)
+		err = c.doJSON(http.MethodPost, path, map[string]string{"name": gitlabEmoji(reaction)}, nil)
+	default:
+		return scm.ErrNotSupported
+	}
+	return errors.Wrapf(err, "adding the %s reaction", reaction)
+}
+
+// gitlabEmoji returns the name of the GitLab award emoji of a GitHub reaction
+func gitlabEmoji(reaction string) string {
+	switch reaction {
+	case "+1":
+		return "thumbsup"
+	case "-1":
+		return "thumbsdown"
+	case "laugh":
+		return "laughing"
+	case "hooray":
+		return "tada"
+	}
+	return reaction
+}
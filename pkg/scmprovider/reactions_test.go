@@ -0,0 +1,42 @@
+package scmprovider
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReactionsAndInvitations(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath()+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := factory.NewClient("github", server.URL, "")
+	require.NoError(t, err)
+	require.NoError(t, ToClient(client, "bot").CreateReaction("org", "repo", 5, true, "heart"))
+	require.NoError(t, ToClient(client, "bot").InviteOrgMember("org", "alice"))
+
+	client, err = factory.NewClient("gitlab", server.URL, "")
+	require.NoError(t, err)
+	require.NoError(t, ToClient(client, "bot").CreateReaction("org", "repo", 5, true, "hooray"))
+	require.NoError(t, ToClient(client, "bot").CreateReaction("org", "repo", 6, false, "heart"))
+	assert.Equal(t, scm.ErrNotSupported, ToClient(client, "bot").InviteOrgMember("org", "alice"))
+
+	assert.Equal(t, []string{
+		`POST /api/v3/repos/org/repo/issues/5/reactions {"content":"heart"}`,
+		`PUT /api/v3/orgs/org/memberships/alice {"role":"member"}`,
+		`POST /api/v4/projects/org%2Frepo/merge_requests/5/award_emoji {"name":"tada"}`,
+		`POST /api/v4/projects/org%2Frepo/issues/6/award_emoji {"name":"heart"}`,
+	}, requests)
+}
@@ -0,0 +1,53 @@
+package scmprovider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// labelInput is the body of the requests creating and updating the labels, for both GitHub and GitLab
+type labelInput struct {
+	Name        string `json:"name,omitempty"`
+	NewName     string `json:"new_name,omitempty"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+// CreateRepoLabel creates a label of the repository, which is only supported by GitHub and GitLab
+func (c *Client) CreateRepoLabel(owner, repo string, label *scm.Label) error {
+	color := strings.TrimPrefix(label.Color, "#")
+	switch c.client.Driver {
+	case scm.DriverGithub:
+		path := fmt.Sprintf("repos/%s/%s/labels", owner, repo)
+		return c.doJSON(http.MethodPost, path, &labelInput{Name: label.Name, Color: color, Description: label.Description}, nil)
+	case scm.DriverGitlab:
+		path := fmt.Sprintf("api/v4/projects/%s/labels", gitlabProjectID(owner, repo))
+		return c.doJSON(http.MethodPost, path, &labelInput{Name: label.Name, Color: "#" + color, Description: label.Description}, nil)
+	default:
+		return fmt.Errorf("the %s provider does not support the creation of the repository labels", c.ProviderType())
+	}
+}
+
+// UpdateRepoLabel updates the name, the color and the description of the label of the repository with the given
+// name, which is only supported by GitHub and GitLab
+func (c *Client) UpdateRepoLabel(owner, repo, name string, label *scm.Label) error {
+	color := strings.TrimPrefix(label.Color, "#")
+	newName := ""
+	if label.Name != name {
+		newName = label.Name
+	}
+	switch c.client.Driver {
+	case scm.DriverGithub:
+		path := fmt.Sprintf("repos/%s/%s/labels/%s", owner, repo, url.PathEscape(name))
+		return c.doJSON(http.MethodPatch, path, &labelInput{NewName: newName, Color: color, Description: label.Description}, nil)
+	case scm.DriverGitlab:
+		path := fmt.Sprintf("api/v4/projects/%s/labels/%s", gitlabProjectID(owner, repo), url.PathEscape(name))
+		return c.doJSON(http.MethodPut, path, &labelInput{NewName: newName, Color: "#" + color, Description: label.Description}, nil)
+	default:
+		return fmt.Errorf("the %s provider does not support the update of the repository labels", c.ProviderType())
+	}
+}
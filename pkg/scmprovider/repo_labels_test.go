@@ -0,0 +1,39 @@
+package scmprovider
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAndUpdateRepoLabel(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath()+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	label := &scm.Label{Name: "kind/bug", Color: "ee0701", Description: "A bug"}
+	for _, kind := range []string{"github", "gitlab"} {
+		client, err := factory.NewClient(kind, server.URL, "")
+		require.NoError(t, err)
+		c := ToClient(client, "bot")
+		require.NoError(t, c.CreateRepoLabel("org", "repo", label))
+		require.NoError(t, c.UpdateRepoLabel("org", "repo", "bug", label))
+	}
+	assert.Equal(t, []string{
+		`POST /api/v3/repos/org/repo/labels {"name":"kind/bug","color":"ee0701","description":"A bug"}`,
+		`PATCH /api/v3/repos/org/repo/labels/bug {"new_name":"kind/bug","color":"ee0701","description":"A bug"}`,
+		`POST /api/v4/projects/org%2Frepo/labels {"name":"kind/bug","color":"#ee0701","description":"A bug"}`,
+		`PUT /api/v4/projects/org%2Frepo/labels/bug {"new_name":"kind/bug","color":"#ee0701","description":"A bug"}`,
+	}, requests)
+}
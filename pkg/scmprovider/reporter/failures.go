@@ -0,0 +1,86 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+)
+
+const (
+	failuresCommentTagPrefix = "<!-- test failures: "
+	failuresCommentTagSuffix = " -->"
+)
+
+// failuresCommentTag returns the tag identifying the test failures comment of a context
+func failuresCommentTag(context string) string {
+	return failuresCommentTagPrefix + context + failuresCommentTagSuffix
+}
+
+// reportTestFailures updates the test failures comment of the job context in place with the failed
+// tests, if the job failed and reported any, or deletes it otherwise.
+func reportTestFailures(spc SCMProviderClient, lhj *v1alpha1.LighthouseJob, botName string, prcs []*scm.Comment) error {
+	refs := lhj.Spec.Refs
+	comment := ""
+	if lhj.Status.State == v1alpha1.FailureState && len(lhj.Status.TestFailures) > 0 {
+		comment = createFailuresComment(lhj, spc.QuoteAuthorForComment(refs.Pulls[0].Author))
+	}
+	if err := botcomment.Sync(spc, refs.Org, refs.Repo, refs.Pulls[0].Number, true, prcs, botName, failuresCommentTag(lhj.Spec.Context), comment); err != nil {
+		return fmt.Errorf("error updating test failures comment: %v", err)
+	}
+	return nil
+}
+
+// createFailuresComment returns a comment listing the failed tests of a job in a collapsed section.
+func createFailuresComment(lhj *v1alpha1.LighthouseJob, author string) string {
+	failures := lhj.Status.TestFailures
+	plural := ""
+	if len(failures) > 1 {
+		plural = "s"
+	}
+	quarantined := 0
+	for _, f := range failures {
+		if f.QuarantineIssue > 0 {
+			quarantined++
+		}
+	}
+	summary := fmt.Sprintf("%d failed test%s", len(failures), plural)
+	switch {
+	case quarantined == len(failures) && quarantined > 1:
+		summary += ", all quarantined as flaky"
+	case quarantined == len(failures):
+		summary += ", quarantined as flaky"
+	case quarantined > 0:
+		summary += fmt.Sprintf(", %d of them quarantined as flaky", quarantined)
+	}
+	lines := []string{
+		fmt.Sprintf("@%s: `%s` failed on commit %s with %s, say `%s` to rerun it.", author, lhj.Spec.Context, lhj.Spec.Refs.Pulls[0].SHA, summary, lhj.Spec.RerunCommand),
+		"",
+		"<details>",
+		"<summary>Failed tests</summary>",
+		"",
+	}
+	for _, f := range failures {
+		name := f.Name
+		if f.Suite != "" {
+			name = f.Suite + " / " + f.Name
+		}
+		if f.QuarantineIssue > 0 {
+			lines = append(lines, fmt.Sprintf("**%s** (quarantined as flaky, see #%d)", name, f.QuarantineIssue))
+		} else {
+			lines = append(lines, fmt.Sprintf("**%s**", name))
+		}
+		if f.Message != "" {
+			lines = append(lines, "", "```", strings.ReplaceAll(f.Message, "```", "'''"), "```")
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, "</details>")
+	if lhj.Status.ArtifactsURL != "" {
+		lines = append(lines, "", fmt.Sprintf("See the [artifacts](%s) for the full test results.", lhj.Status.ArtifactsURL))
+	}
+	lines = append(lines, "", failuresCommentTag(lhj.Spec.Context))
+	return strings.Join(lines, "\n")
+}
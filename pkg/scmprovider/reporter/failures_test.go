@@ -0,0 +1,117 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSCMProviderClient struct {
+	comments []*scm.Comment
+	created  []string
+	edited   map[int]string
+	deleted  []int
+}
+
+func (f *fakeSCMProviderClient) BotName() (string, error) {
+	return "bot", nil
+}
+
+func (f *fakeSCMProviderClient) ListPullRequestComments(string, string, int) ([]*scm.Comment, error) {
+	return f.comments, nil
+}
+
+func (f *fakeSCMProviderClient) CreateComment(_, _ string, _ int, _ bool, comment string) error {
+	f.created = append(f.created, comment)
+	return nil
+}
+
+func (f *fakeSCMProviderClient) DeleteComment(_, _ string, _, id int, _ bool) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeSCMProviderClient) EditComment(_, _ string, _, id int, comment string, _ bool) error {
+	if f.edited == nil {
+		f.edited = map[int]string{}
+	}
+	f.edited[id] = comment
+	return nil
+}
+
+func (f *fakeSCMProviderClient) QuoteAuthorForComment(author string) string {
+	return author
+}
+
+func failedJob() *v1alpha1.LighthouseJob {
+	return &v1alpha1.LighthouseJob{
+		Spec: v1alpha1.LighthouseJobSpec{
+			Context:      "unit",
+			RerunCommand: "/test unit",
+			Refs: &v1alpha1.Refs{
+				Org:   "org",
+				Repo:  "repo",
+				Pulls: []v1alpha1.Pull{{Number: 1, Author: "author", SHA: "abcdef"}},
+			},
+		},
+		Status: v1alpha1.LighthouseJobStatus{
+			State:        v1alpha1.FailureState,
+			ArtifactsURL: "https://storage/unit",
+			TestFailures: []v1alpha1.TestFailure{
+				{Suite: "pkg/foo", Name: "TestFoo", Message: "expected 1, got 2"},
+				{Name: "TestBar"},
+			},
+		},
+	}
+}
+
+func TestReportTestFailures(t *testing.T) {
+	lhj := failedJob()
+	spc := &fakeSCMProviderClient{}
+	prcs := []*scm.Comment{
+		{ID: 1, Author: scm.User{Login: "bot"}, Body: "old\n" + failuresCommentTag("unit")},
+		{ID: 2, Author: scm.User{Login: "bot"}, Body: "other\n" + failuresCommentTag("lint")},
+		{ID: 3, Author: scm.User{Login: "someone"}, Body: failuresCommentTag("unit")},
+	}
+	require.NoError(t, reportTestFailures(spc, lhj, "bot", prcs))
+	assert.Empty(t, spc.deleted)
+	assert.Empty(t, spc.created)
+	require.Len(t, spc.edited, 1)
+
+	comment := spc.edited[1]
+	assert.True(t, strings.HasPrefix(comment, "@author: `unit` failed on commit abcdef with 2 failed tests, say `/test unit` to rerun it."))
+	assert.Contains(t, comment, "<details>\n<summary>Failed tests</summary>")
+	assert.Contains(t, comment, "**pkg/foo / TestFoo**\n\n```\nexpected 1, got 2\n```")
+	assert.Contains(t, comment, "**TestBar**\n")
+	assert.Contains(t, comment, "[artifacts](https://storage/unit)")
+	assert.True(t, strings.HasSuffix(comment, failuresCommentTag("unit")))
+}
+
+func TestReportTestFailuresSuccess(t *testing.T) {
+	lhj := failedJob()
+	lhj.Status.State = v1alpha1.SuccessState
+	spc := &fakeSCMProviderClient{}
+	prcs := []*scm.Comment{
+		{ID: 1, Author: scm.User{Login: "bot"}, Body: failuresCommentTag("unit")},
+	}
+	require.NoError(t, reportTestFailures(spc, lhj, "bot", prcs))
+	assert.Equal(t, []int{1}, spc.deleted)
+	assert.Empty(t, spc.created)
+}
+
+func TestReportTestFailuresQuarantined(t *testing.T) {
+	lhj := failedJob()
+	lhj.Status.TestFailures[1].QuarantineIssue = 12
+	spc := &fakeSCMProviderClient{}
+	require.NoError(t, reportTestFailures(spc, lhj, "bot", nil))
+	require.Len(t, spc.created, 1)
+
+	comment := spc.created[0]
+	assert.True(t, strings.HasPrefix(comment, "@author: `unit` failed on commit abcdef with 2 failed tests, 1 of them quarantined as flaky, say `/test unit` to rerun it."))
+	assert.Contains(t, comment, "**pkg/foo / TestFoo**\n")
+	assert.Contains(t, comment, "**TestBar** (quarantined as flaky, see #12)\n")
+}
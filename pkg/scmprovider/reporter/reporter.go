@@ -25,6 +25,7 @@ import (
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 )
@@ -93,38 +94,37 @@ func Report(spc SCMProviderClient, reportTemplate *template.Template, lhj *v1alp
 	if err != nil {
 		return fmt.Errorf("error getting bot name: %v", err)
 	}
-	deletes, entries, updateID := parsePRComments(lhj, botName, prcs)
+	deletes, entries := parsePRComments(lhj, botName, prcs)
+	var remaining []*scm.Comment
+	for _, c := range prcs {
+		if !containsID(deletes, c.ID) {
+			remaining = append(remaining, c)
+		}
+	}
 	for _, delete := range deletes {
 		if err := spc.DeleteComment(refs.Org, refs.Repo, refs.Pulls[0].Number, delete, true); err != nil {
 			return fmt.Errorf("error deleting comment: %v", err)
 		}
 	}
+	var comment string
 	if len(entries) > 0 {
-		comment, err := createComment(reportTemplate, lhj, spc.QuoteAuthorForComment(lhj.Spec.Refs.Pulls[0].Author), entries)
+		comment, err = createComment(reportTemplate, lhj, spc.QuoteAuthorForComment(lhj.Spec.Refs.Pulls[0].Author), entries)
 		if err != nil {
 			return fmt.Errorf("generating comment: %v", err)
 		}
-		if updateID == 0 {
-			if err := spc.CreateComment(refs.Org, refs.Repo, refs.Pulls[0].Number, true, comment); err != nil {
-				return fmt.Errorf("error creating comment: %v", err)
-			}
-		} else {
-			if err := spc.EditComment(refs.Org, refs.Repo, refs.Pulls[0].Number, updateID, comment, true); err != nil {
-				return fmt.Errorf("error updating comment: %v", err)
-			}
-		}
 	}
-	return nil
+	// the report comment is updated in place, or deleted once all the tests pass
+	if err := botcomment.Sync(spc, refs.Org, refs.Repo, refs.Pulls[0].Number, true, remaining, botName, "<"+commentTag+">", comment); err != nil {
+		return fmt.Errorf("error updating report comment: %v", err)
+	}
+	return reportTestFailures(spc, lhj, botName, remaining)
 }
 
-// parsePRComments returns a list of comments to delete, a list of table
-// entries, and the ID of the comment to update. If there are no table entries
-// then don't make a new comment. Otherwise, if the comment to update is 0,
-// create a new comment.
-func parsePRComments(lhj *v1alpha1.LighthouseJob, botName string, ics []*scm.Comment) ([]int, []string, int) {
+// parsePRComments returns a list of old style comments to delete and the table
+// entries of the report comment. If there are no table entries then the report
+// comment is deleted.
+func parsePRComments(lhj *v1alpha1.LighthouseJob, botName string, ics []*scm.Comment) ([]int, []string) {
 	var toDelete []int
-	var previousComments []int
-	var latestComment int
 	var entries []string
 	// First accumulate result entries and comment IDs
 	for _, ic := range ics {
@@ -139,10 +139,6 @@ func parsePRComments(lhj *v1alpha1.LighthouseJob, botName string, ics []*scm.Com
 		if !strings.Contains(ic.Body, commentTag) {
 			continue
 		}
-		if latestComment != 0 {
-			previousComments = append(previousComments, latestComment)
-		}
-		latestComment = ic.ID
 		var tracking bool
 		for _, line := range strings.Split(ic.Body, "\n") {
 			line = strings.TrimSpace(line)
@@ -178,24 +174,30 @@ func parsePRComments(lhj *v1alpha1.LighthouseJob, botName string, ics []*scm.Com
 			newEntries = append(newEntries, entries[i])
 		}
 	}
-	var createNewComment bool
 	if lhj.Status.State == v1alpha1.FailureState {
 		newEntries = append(newEntries, createEntry(lhj))
-		createNewComment = true
 	}
-	toDelete = append(toDelete, previousComments...)
-	if (createNewComment || len(newEntries) == 0) && latestComment != 0 {
-		toDelete = append(toDelete, latestComment)
-		latestComment = 0
+	return toDelete, newEntries
+}
+
+func containsID(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
 	}
-	return toDelete, newEntries, latestComment
+	return false
 }
 
 func createEntry(lhj *v1alpha1.LighthouseJob) string {
+	details := fmt.Sprintf("[link](%s)", lhj.Status.ReportURL)
+	if lhj.Status.ArtifactsURL != "" {
+		details += fmt.Sprintf(" [artifacts](%s)", lhj.Status.ArtifactsURL)
+	}
 	return strings.Join([]string{
 		lhj.Spec.Context,
 		lhj.Spec.Refs.Pulls[0].SHA,
-		fmt.Sprintf("[link](%s)", lhj.Status.ReportURL),
+		details,
 		fmt.Sprintf("`%s`", lhj.Spec.RerunCommand),
 	}, " | ")
 }
@@ -23,6 +23,9 @@ import (
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestParsePRComment(t *testing.T) {
@@ -33,7 +36,6 @@ func TestParsePRComment(t *testing.T) {
 		prcs             []*scm.Comment
 		expectedDeletes  []int
 		expectedContexts []string
-		expectedUpdate   int
 	}{
 		{
 			name:    "should delete old style comments",
@@ -91,7 +93,6 @@ func TestParsePRComment(t *testing.T) {
 					ID:     123,
 				},
 			},
-			expectedDeletes:  []int{123},
 			expectedContexts: []string{},
 		},
 		{
@@ -105,7 +106,6 @@ func TestParsePRComment(t *testing.T) {
 					ID:     123,
 				},
 			},
-			expectedDeletes:  []int{123},
 			expectedContexts: []string{},
 		},
 
@@ -120,7 +120,6 @@ func TestParsePRComment(t *testing.T) {
 					ID:     123,
 				},
 			},
-			expectedDeletes:  []int{123},
 			expectedContexts: []string{"bla test"},
 		},
 		{
@@ -134,7 +133,6 @@ func TestParsePRComment(t *testing.T) {
 					ID:     123,
 				},
 			},
-			expectedDeletes:  []int{123},
 			expectedContexts: []string{"bla test", "foo test"},
 		},
 		{
@@ -153,7 +151,6 @@ func TestParsePRComment(t *testing.T) {
 					ID:     124,
 				},
 			},
-			expectedDeletes:  []int{123, 124},
 			expectedContexts: []string{"bla test", "foo test"},
 		},
 		{
@@ -167,9 +164,7 @@ func TestParsePRComment(t *testing.T) {
 					ID:     123,
 				},
 			},
-			expectedDeletes:  []int{},
 			expectedContexts: []string{"foo test"},
-			expectedUpdate:   123,
 		},
 	}
 	for _, tc := range testcases {
@@ -183,7 +178,7 @@ func TestParsePRComment(t *testing.T) {
 					State: tc.state,
 				},
 			}
-			deletes, entries, update := parsePRComments(lhj, "k8s-ci-robot", tc.prcs)
+			deletes, entries := parsePRComments(lhj, "k8s-ci-robot", tc.prcs)
 			if len(deletes) != len(tc.expectedDeletes) {
 				t.Errorf("It %s: wrong number of deletes. Got %v, expected %v", tc.name, deletes, tc.expectedDeletes)
 			} else {
@@ -216,9 +211,6 @@ func TestParsePRComment(t *testing.T) {
 					}
 				}
 			}
-			if tc.expectedUpdate != update {
-				t.Errorf("It %s: expected update %d, got %d", tc.name, tc.expectedUpdate, update)
-			}
 		})
 	}
 }
@@ -270,3 +262,57 @@ func TestShouldReport(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateEntry(t *testing.T) {
+	lhj := &v1alpha1.LighthouseJob{
+		Spec: v1alpha1.LighthouseJobSpec{
+			Context:      "unit",
+			RerunCommand: "/test unit",
+			Refs: &v1alpha1.Refs{
+				Pulls: []v1alpha1.Pull{{SHA: "abcdef"}},
+			},
+		},
+		Status: v1alpha1.LighthouseJobStatus{
+			ReportURL: "https://dashboard/unit",
+		},
+	}
+	expected := "unit | abcdef | [link](https://dashboard/unit) | `/test unit`"
+	if entry := createEntry(lhj); entry != expected {
+		t.Errorf("Expected entry %q, got %q", expected, entry)
+	}
+
+	lhj.Status.ArtifactsURL = "https://storage/unit"
+	expected = "unit | abcdef | [link](https://dashboard/unit) [artifacts](https://storage/unit) | `/test unit`"
+	if entry := createEntry(lhj); entry != expected {
+		t.Errorf("Expected entry %q, got %q", expected, entry)
+	}
+}
+
+func TestReportUpdatesCommentInPlace(t *testing.T) {
+	lhj := failedJob()
+	lhj.Spec.Type = job.PresubmitJob
+	lhj.Status.TestFailures = nil
+	lhj.Status.CompletionTime = &metav1.Time{}
+	spc := &fakeSCMProviderClient{
+		comments: []*scm.Comment{
+			{ID: 1, Author: scm.User{Login: "bot"}, Body: "--- | --- | ---\nlint | abcdef | details\n\n<" + commentTag + ">"},
+			{ID: 2, Author: scm.User{Login: "bot"}, Body: "--- | --- | ---\nunit | 012345 | details\n\n<" + commentTag + ">"},
+		},
+	}
+	require.NoError(t, Report(spc, nil, lhj, []job.PipelineKind{job.PresubmitJob}))
+	assert.Equal(t, []int{1}, spc.deleted)
+	assert.Empty(t, spc.created)
+	require.Len(t, spc.edited, 1)
+	assert.Contains(t, spc.edited[2], "lint | abcdef | details")
+	assert.Contains(t, spc.edited[2], "unit | abcdef |")
+
+	lhj.Status.State = v1alpha1.SuccessState
+	spc = &fakeSCMProviderClient{
+		comments: []*scm.Comment{
+			{ID: 2, Author: scm.User{Login: "bot"}, Body: "--- | --- | ---\nunit | abcdef | details\n\n<" + commentTag + ">"},
+		},
+	}
+	require.NoError(t, Report(spc, nil, lhj, []job.PipelineKind{job.PresubmitJob}))
+	assert.Equal(t, []int{2}, spc.deleted)
+	assert.Empty(t, spc.edited)
+}
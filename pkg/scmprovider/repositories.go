@@ -1,21 +1,21 @@
 package scmprovider
 
 import (
-	"context"
-
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
 )
 
 // GetRepositoryByFullName returns the repository details
 func (c *Client) GetRepositoryByFullName(fullName string) (*scm.Repository, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	r, _, err := c.client.Repositories.Find(ctx, fullName)
 	return r, err
 }
 
 // GetRepoLabels returns the repository labels
 func (c *Client) GetRepoLabels(owner, repo string) ([]*scm.Label, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	var allLabels []*scm.Label
 	var resp *scm.Response
@@ -39,15 +39,31 @@ func (c *Client) GetRepoLabels(owner, repo string) ([]*scm.Label, error) {
 
 // IsCollaborator check if a user is collaborator to a repository
 func (c *Client) IsCollaborator(owner, repo, login string) (bool, error) {
-	ctx := context.Background()
 	fullName := c.repositoryName(owner, repo)
-	flag, _, err := c.client.Repositories.IsCollaborator(ctx, fullName, login)
-	return flag, err
+	flag, err := c.cachedLookup(owner, "collaborator", fullName+"/"+login, func() (interface{}, error) {
+		flag, _, err := c.client.Repositories.IsCollaborator(c.requestContext(), fullName, login)
+		return flag, err
+	})
+	if err != nil {
+		return false, err
+	}
+	return flag.(bool), nil
 }
 
 // ListCollaborators list the collaborators to a repository
 func (c *Client) ListCollaborators(owner, repo string) ([]scm.User, error) {
-	ctx := context.Background()
+	fullName := c.repositoryName(owner, repo)
+	collabs, err := c.cachedLookup(owner, "collaborators", fullName, func() (interface{}, error) {
+		return c.listCollaborators(owner, repo)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]scm.User(nil), collabs.([]scm.User)...), nil
+}
+
+func (c *Client) listCollaborators(owner, repo string) ([]scm.User, error) {
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	var allCollabs []scm.User
 	var resp *scm.Response
@@ -70,10 +86,20 @@ func (c *Client) ListCollaborators(owner, repo string) ([]scm.User, error) {
 }
 
 // CreateStatus create a status into a repository
-func (c *Client) CreateStatus(owner, repo, ref string, s *scm.StatusInput) (*scm.Status, error) {
-	ctx := context.Background()
+func (c *Client) CreateStatus(owner, repo, ref string, s *scm.StatusInput) (status *scm.Status, err error) {
+	queued := *s
+	if c.queueAction(Action{Kind: audit.StatusSet, Owner: owner, Repo: repo, Ref: ref, Status: &queued}) {
+		return scm.ConvertStatusInputToStatus(s), nil
+	}
+	defer c.audit(audit.Event{Action: audit.StatusSet, Org: owner, Repo: repo, Target: s.Label, Details: map[string]string{"sha": ref, "state": s.State.String()}}, &err)
+	if id := c.eventID(); id != "" {
+		input := *s
+		input.Desc = eventid.AppendToDescription(s.Desc, id)
+		s = &input
+	}
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
-	status, _, err := c.client.Repositories.CreateStatus(ctx, fullName, ref, s)
+	status, _, err = c.client.Repositories.CreateStatus(ctx, fullName, ref, s)
 	return status, err
 }
 
@@ -98,7 +124,7 @@ type Status struct {
 
 // ListStatuses list the statuses
 func (c *Client) ListStatuses(owner, repo, ref string) ([]*scm.Status, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	var allStatuses []*scm.Status
 	var resp *scm.Response
@@ -122,7 +148,7 @@ func (c *Client) ListStatuses(owner, repo, ref string) ([]*scm.Status, error) {
 
 // GetCombinedStatus returns the combined status
 func (c *Client) GetCombinedStatus(owner, repo, ref string) (*scm.CombinedStatus, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	resources, _, err := c.client.Repositories.FindCombinedStatus(ctx, fullName, ref)
 	return resources, err
@@ -151,15 +177,48 @@ func (c *Client) HasPermission(org, repo, user string, roles ...string) (bool, e
 
 // GetUserPermission returns the user's permission level for a repo
 func (c *Client) GetUserPermission(org, repo, user string) (string, error) {
-	ctx := context.Background()
 	fullName := c.repositoryName(org, repo)
-	perm, _, err := c.client.Repositories.FindUserPermission(ctx, fullName, user)
-	return perm, err
+	perm, err := c.cachedLookup(org, "permission", fullName+"/"+user, func() (interface{}, error) {
+		perm, _, err := c.client.Repositories.FindUserPermission(c.requestContext(), fullName, user)
+		return perm, err
+	})
+	if err != nil {
+		return "", err
+	}
+	return perm.(string), nil
 }
 
 // IsMember checks if a user is a member of the organisation
 func (c *Client) IsMember(org, user string) (bool, error) {
-	ctx := context.Background()
-	member, _, err := c.client.Organizations.IsMember(ctx, org, user)
-	return member, err
+	member, err := c.cachedLookup(org, "member", user, func() (interface{}, error) {
+		member, _, err := c.client.Organizations.IsMember(c.requestContext(), org, user)
+		return member, err
+	})
+	if err != nil {
+		return false, err
+	}
+	return member.(bool), nil
+}
+
+// ListOrgRepos returns the repositories of the organisation
+func (c *Client) ListOrgRepos(org string) ([]*scm.Repository, error) {
+	ctx := c.requestContext()
+	var allRepos []*scm.Repository
+	var resp *scm.Response
+	var repos []*scm.Repository
+	var err error
+	firstRun := false
+	opts := scm.ListOptions{
+		Page: 1,
+	}
+	for !firstRun || (resp != nil && opts.Page <= resp.Page.Last) {
+		repos, resp, err = c.client.Repositories.ListOrganisation(ctx, org, opts)
+		if err != nil {
+			return nil, err
+		}
+		firstRun = true
+		allRepos = append(allRepos, repos...)
+		opts.Page++
+	}
+	return allRepos, nil
 }
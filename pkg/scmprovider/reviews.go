@@ -1,15 +1,18 @@
 package scmprovider
 
 import (
-	"context"
+	"fmt"
+	"net/http"
+	"strconv"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
 	"github.com/pkg/errors"
 )
 
 // ListReviews list the reviews
 func (c *Client) ListReviews(owner, repo string, number int) ([]*scm.Review, error) {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(owner, repo)
 	var allReviews []*scm.Review
 	var resp *scm.Response
@@ -33,7 +36,7 @@ func (c *Client) ListReviews(owner, repo string, number int) ([]*scm.Review, err
 
 // RequestReview requests a review
 func (c *Client) RequestReview(org, repo string, number int, logins []string) error {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(org, repo)
 	_, err := c.client.PullRequests.RequestReview(ctx, fullName, number, logins)
 	return errors.Wrapf(err, "requesting review from %s", logins)
@@ -41,8 +44,20 @@ func (c *Client) RequestReview(org, repo string, number int, logins []string) er
 
 // UnrequestReview unrequest a review
 func (c *Client) UnrequestReview(org, repo string, number int, logins []string) error {
-	ctx := context.Background()
+	ctx := c.requestContext()
 	fullName := c.repositoryName(org, repo)
 	_, err := c.client.PullRequests.UnrequestReview(ctx, fullName, number, logins)
 	return errors.Wrapf(err, "unrequesting review from %s", logins)
 }
+
+// DismissReview dismisses a review of a pull request with the given message, which is only supported by GitHub.
+// scm.ErrNotSupported is returned for the other providers.
+func (c *Client) DismissReview(org, repo string, number, id int, message string) (err error) {
+	defer c.audit(audit.Event{Action: audit.ReviewDismissed, Org: org, Repo: repo, Number: number, Details: map[string]string{"review": strconv.Itoa(id)}}, &err)
+	if c.client.Driver != scm.DriverGithub {
+		return scm.ErrNotSupported
+	}
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d/reviews/%d/dismissals", org, repo, number, id)
+	in := map[string]string{"message": message, "event": "DISMISS"}
+	return errors.Wrapf(c.doJSON(http.MethodPut, path, in, nil), "dismissing review %d", id)
+}
@@ -0,0 +1,36 @@
+package scmprovider
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDismissReview(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := factory.NewClient("github", server.URL, "")
+	require.NoError(t, err)
+	require.NoError(t, ToClient(client, "bot").DismissReview("org", "repo", 5, 42, "stale"))
+	assert.Equal(t, []string{
+		`PUT /api/v3/repos/org/repo/pulls/5/reviews/42/dismissals {"event":"DISMISS","message":"stale"}`,
+	}, requests)
+
+	client, err = factory.NewClient("gitlab", server.URL, "")
+	require.NoError(t, err)
+	assert.Equal(t, scm.ErrNotSupported, ToClient(client, "bot").DismissReview("org", "repo", 5, 42, "stale"))
+	assert.Len(t, requests, 1)
+}
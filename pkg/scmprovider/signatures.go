@@ -0,0 +1,108 @@
+package scmprovider
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// signaturesPageSize is the number of commits of a pull request listed per page
+const signaturesPageSize = 100
+
+// CommitSignature is the verification of the signature of a commit by the provider
+type CommitSignature struct {
+	Sha string
+	// Verified is true if the commit is signed with a GPG, SSH or S/MIME key the provider verified
+	Verified bool
+	// Reason is the reason the signature is not verified as given by the provider, e.g. "unsigned"
+	Reason string
+}
+
+type githubPullRequestCommit struct {
+	Sha    string `json:"sha"`
+	Commit struct {
+		Verification struct {
+			Verified bool   `json:"verified"`
+			Reason   string `json:"reason"`
+		} `json:"verification"`
+	} `json:"commit"`
+}
+
+type gitlabMergeRequestCommit struct {
+	ID string `json:"id"`
+}
+
+type gitlabCommitSignature struct {
+	VerificationStatus string `json:"verification_status"`
+}
+
+// ListPullRequestCommitSignatures returns the verification of the signatures of the commits of the pull request by
+// the provider, which is only supported by GitHub and GitLab
+func (c *Client) ListPullRequestCommitSignatures(owner, repo string, number int) ([]*CommitSignature, error) {
+	switch c.client.Driver {
+	case scm.DriverGithub:
+		return c.listGitHubCommitSignatures(owner, repo, number)
+	case scm.DriverGitlab:
+		return c.listGitLabCommitSignatures(owner, repo, number)
+	default:
+		return nil, fmt.Errorf("the %s provider does not support the verification of the commit signatures", c.ProviderType())
+	}
+}
+
+func (c *Client) listGitHubCommitSignatures(owner, repo string, number int) ([]*CommitSignature, error) {
+	var answer []*CommitSignature
+	for page := 1; ; page++ {
+		path := fmt.Sprintf("repos/%s/%s/pulls/%d/commits?per_page=%d&page=%d", owner, repo, number, signaturesPageSize, page)
+		var commits []githubPullRequestCommit
+		if err := c.doJSON(http.MethodGet, path, nil, &commits); err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			answer = append(answer, &CommitSignature{
+				Sha:      commit.Sha,
+				Verified: commit.Commit.Verification.Verified,
+				Reason:   commit.Commit.Verification.Reason,
+			})
+		}
+		if len(commits) < signaturesPageSize {
+			return answer, nil
+		}
+	}
+}
+
+func (c *Client) listGitLabCommitSignatures(owner, repo string, number int) ([]*CommitSignature, error) {
+	project := gitlabProjectID(owner, repo)
+	var commits []gitlabMergeRequestCommit
+	for page := 1; ; page++ {
+		path := fmt.Sprintf("api/v4/projects/%s/merge_requests/%d/commits?per_page=%d&page=%d", project, number, signaturesPageSize, page)
+		var pageCommits []gitlabMergeRequestCommit
+		if err := c.doJSON(http.MethodGet, path, nil, &pageCommits); err != nil {
+			return nil, err
+		}
+		commits = append(commits, pageCommits...)
+		if len(pageCommits) < signaturesPageSize {
+			break
+		}
+	}
+	var answer []*CommitSignature
+	for _, commit := range commits {
+		path := fmt.Sprintf("api/v4/projects/%s/repository/commits/%s/signature", project, commit.ID)
+		signature := &gitlabCommitSignature{}
+		err := c.doJSON(http.MethodGet, path, nil, signature)
+		switch {
+		case isNotFound(err):
+			// GitLab has no signature for the unsigned commits
+			answer = append(answer, &CommitSignature{Sha: commit.ID, Reason: "unsigned"})
+		case err != nil:
+			return nil, err
+		default:
+			answer = append(answer, &CommitSignature{
+				Sha:      commit.ID,
+				Verified: signature.VerificationStatus == "verified",
+				Reason:   signature.VerificationStatus,
+			})
+		}
+	}
+	return answer, nil
+}
@@ -0,0 +1,54 @@
+package scmprovider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListPullRequestCommitSignatures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		// the GitHub client of a custom server uses the GitHub Enterprise API
+		case "/api/v3/repos/org/repo/pulls/1/commits":
+			_, _ = w.Write([]byte(`[
+				{"sha": "aaa", "commit": {"verification": {"verified": true, "reason": "valid"}}},
+				{"sha": "bbb", "commit": {"verification": {"verified": false, "reason": "unsigned"}}}
+			]`))
+		case "/api/v4/projects/org/repo/merge_requests/1/commits":
+			_, _ = w.Write([]byte(`[{"id": "ccc"}, {"id": "ddd"}, {"id": "eee"}]`))
+		case "/api/v4/projects/org/repo/repository/commits/ccc/signature":
+			_, _ = w.Write([]byte(`{"verification_status": "verified"}`))
+		case "/api/v4/projects/org/repo/repository/commits/ddd/signature":
+			_, _ = w.Write([]byte(`{"verification_status": "unverified"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "404 Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := factory.NewClient("github", server.URL, "")
+	require.NoError(t, err)
+	signatures, err := ToClient(client, "bot").ListPullRequestCommitSignatures("org", "repo", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []*CommitSignature{
+		{Sha: "aaa", Verified: true, Reason: "valid"},
+		{Sha: "bbb", Reason: "unsigned"},
+	}, signatures)
+
+	client, err = factory.NewClient("gitlab", server.URL, "")
+	require.NoError(t, err)
+	signatures, err = ToClient(client, "bot").ListPullRequestCommitSignatures("org", "repo", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []*CommitSignature{
+		{Sha: "ccc", Verified: true, Reason: "verified"},
+		{Sha: "ddd", Reason: "unverified"},
+		{Sha: "eee", Reason: "unsigned"},
+	}, signatures)
+}
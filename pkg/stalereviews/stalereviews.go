@@ -0,0 +1,111 @@
+// Package stalereviews records the files covered by the approval of a pull request, and when its approvals were
+// last dismissed, in the sticky comment of the stale-review plugin, so that the approvals can be dismissed once the
+// pull request changes other files and the approve plugin can ignore the approvals given before.
+package stalereviews
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+)
+
+const (
+	// markerID identifies the sticky comment recording the approval of a pull request
+	markerID = "stale-review"
+
+	// maxListedFiles is the maximum number of files listed in the comment, all of them being recorded anyway
+	maxListedFiles = 10
+)
+
+var dataRe = regexp.MustCompile(`<!-- stale-review-data: (.*) -->`)
+
+// Record is the record of the approval of a pull request
+type Record struct {
+	// SHA is the head commit of the pull request when it was approved or when its approvals were dismissed
+	SHA string `json:"sha,omitempty"`
+	// Files are the paths of the files covered by the approval, none once the approvals were dismissed
+	Files []string `json:"files,omitempty"`
+	// Stale are the paths of the files outside the approved ones which made the approvals stale
+	Stale []string `json:"stale,omitempty"`
+	// Dismissed is when the approvals were last dismissed, never if nil
+	Dismissed *time.Time `json:"dismissed,omitempty"`
+}
+
+// Marker returns the hidden marker of the sticky comment recording the approval of a pull request
+func Marker() string {
+	return botcomment.Marker(markerID)
+}
+
+// Approved tells whether the record covers an approval which was not dismissed since
+func (r *Record) Approved() bool {
+	return len(r.Files) > 0
+}
+
+// Covers tells whether the approval covers the file with the given path
+func (r *Record) Covers(path string) bool {
+	for _, f := range r.Files {
+		if f == path {
+			return true
+		}
+	}
+	return false
+}
+
+// DismissedBefore tells whether the approvals given at the given time were dismissed since
+func (r *Record) DismissedBefore(t time.Time) bool {
+	return r.Dismissed != nil && t.Before(*r.Dismissed)
+}
+
+// Format returns the body of the comment recording the approval
+func (r *Record) Format() string {
+	var sb strings.Builder
+	if r.Approved() {
+		sb.WriteString(fmt.Sprintf("The approval of this pull request covers the %d files it changes as of commit %s. ", len(r.Files), r.SHA))
+		sb.WriteString("Pushing changes to other files dismisses it.")
+	} else {
+		sb.WriteString(fmt.Sprintf("The approvals of this pull request were dismissed as commit %s changes files which were not approved: %s. ", r.SHA, listFiles(r.Stale)))
+		sb.WriteString("It needs to be approved again.")
+	}
+	data, _ := json.Marshal(r)
+	sb.WriteString(fmt.Sprintf("\n\n<!-- stale-review-data: %s -->\n%s", data, Marker()))
+	return sb.String()
+}
+
+// Parse returns the record of the approval in the body of a comment, nil if there is none
+func Parse(body string) *Record {
+	m := dataRe.FindStringSubmatch(body)
+	if m == nil {
+		return nil
+	}
+	r := &Record{}
+	if err := json.Unmarshal([]byte(m[1]), r); err != nil {
+		return nil
+	}
+	return r
+}
+
+// Find returns the record of the approval made by the bot among the comments of a pull request, nil if there is
+// none
+func Find(comments []*scm.Comment, botName string) *Record {
+	found := botcomment.Find(comments, botName, Marker())
+	if len(found) == 0 {
+		return nil
+	}
+	return Parse(found[len(found)-1].Body)
+}
+
+func listFiles(files []string) string {
+	quoted := make([]string, 0, maxListedFiles)
+	for i, f := range files {
+		if i == maxListedFiles {
+			return strings.Join(quoted, ", ") + fmt.Sprintf(" and %d more", len(files)-maxListedFiles)
+		}
+		quoted = append(quoted, "`"+f+"`")
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,52 @@
+package stalereviews
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAndFind(t *testing.T) {
+	approved := &Record{SHA: "abc", Files: []string{"a.go", "b.go"}}
+	body := approved.Format()
+	assert.Contains(t, body, "covers the 2 files it changes as of commit abc")
+	assert.Contains(t, body, Marker())
+
+	dismissed := time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC)
+	stale := &Record{SHA: "def", Stale: []string{"c.go"}, Dismissed: &dismissed}
+	assert.Contains(t, stale.Format(), "dismissed as commit def changes files which were not approved: `c.go`.")
+
+	comments := []*scm.Comment{
+		{Author: scm.User{Login: "bot"}, Body: stale.Format()},
+		{Author: scm.User{Login: "someone"}, Body: body},
+		{Author: scm.User{Login: "bot"}, Body: body},
+	}
+	found := Find(comments, "bot")
+	require.NotNil(t, found)
+	assert.Equal(t, approved, found)
+	assert.True(t, found.Approved())
+	assert.True(t, found.Covers("b.go"))
+	assert.False(t, found.Covers("c.go"))
+
+	found = Find(comments[:2], "bot")
+	require.NotNil(t, found)
+	assert.False(t, found.Approved())
+	assert.True(t, found.DismissedBefore(dismissed.Add(-time.Minute)))
+	assert.False(t, found.DismissedBefore(dismissed.Add(time.Minute)))
+
+	assert.Nil(t, Find(comments[1:2], "bot"))
+	assert.Nil(t, Parse("no record"))
+}
+
+func TestListFiles(t *testing.T) {
+	var files []string
+	for i := 0; i < 12; i++ {
+		files = append(files, fmt.Sprintf("f%d", i))
+	}
+	assert.Equal(t, "`f0`", listFiles(files[:1]))
+	assert.Equal(t, "`f0`, `f1`, `f2`, `f3`, `f4`, `f5`, `f6`, `f7`, `f8`, `f9` and 2 more", listFiles(files))
+}
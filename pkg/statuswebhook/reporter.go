@@ -0,0 +1,224 @@
+package statuswebhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// EventHeader is the header key carrying the event of the payload
+	EventHeader = "X-Lighthouse-Event"
+
+	maxRetries = 3
+)
+
+// Payload is the JSON body posted to the status webhooks.
+type Payload struct {
+	// Event is the state transition, one of "triggered", "running", "succeeded", "failed" and "aborted"
+	Event string `json:"event"`
+	// Name is the name of the LighthouseJob
+	Name string `json:"name"`
+	// Namespace is the namespace of the LighthouseJob
+	Namespace string `json:"namespace,omitempty"`
+	// Job is the name of the job
+	Job string `json:"job"`
+	// Type is the type of the job
+	Type job.PipelineKind `json:"type"`
+	// Context is the commit status context of the job
+	Context string `json:"context,omitempty"`
+	// State is the state of the LighthouseJob
+	State v1alpha1.PipelineState `json:"state"`
+	// Description is the description of the job state
+	Description string `json:"description,omitempty"`
+	// ReportURL is the link to the job
+	ReportURL string `json:"report_url,omitempty"`
+	// Refs is the code under test
+	Refs *v1alpha1.Refs `json:"refs,omitempty"`
+	// StartTime is when the job was created
+	StartTime metav1.Time `json:"start_time"`
+	// CompletionTime is when the job completed
+	CompletionTime *metav1.Time `json:"completion_time,omitempty"`
+}
+
+// EventForState returns the webhook event for the given job state, or an empty string
+// if the state is unknown.
+func EventForState(state v1alpha1.PipelineState) string {
+	switch state {
+	case v1alpha1.TriggeredState, v1alpha1.QueuedState:
+		return lighthouse.StatusWebhookTriggered
+	case v1alpha1.PendingState, v1alpha1.RunningState:
+		return lighthouse.StatusWebhookRunning
+	case v1alpha1.SuccessState:
+		return lighthouse.StatusWebhookSucceeded
+	case v1alpha1.FailureState, v1alpha1.ErrorState:
+		return lighthouse.StatusWebhookFailed
+	case v1alpha1.AbortedState:
+		return lighthouse.StatusWebhookAborted
+	}
+	return ""
+}
+
+// NewPayload returns the payload describing the given event of the LighthouseJob
+func NewPayload(event string, lhjob *v1alpha1.LighthouseJob) *Payload {
+	return &Payload{
+		Event:          event,
+		Name:           lhjob.Name,
+		Namespace:      lhjob.Namespace,
+		Job:            lhjob.Spec.Job,
+		Type:           lhjob.Spec.Type,
+		Context:        lhjob.Spec.Context,
+		State:          lhjob.Status.State,
+		Description:    lhjob.Status.Description,
+		ReportURL:      lhjob.Status.ReportURL,
+		Refs:           lhjob.Spec.Refs,
+		StartTime:      lhjob.Status.StartTime,
+		CompletionTime: lhjob.Status.CompletionTime,
+	}
+}
+
+// Sign returns the signature of the payload for the given token
+func Sign(payload []byte, token string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	_, _ = mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Reporter posts LighthouseJob state transitions to status webhooks.
+type Reporter struct {
+	client  *http.Client
+	logger  *logrus.Entry
+	secrets *secret.Resolver
+	backoff time.Duration
+}
+
+// NewReporter creates a new reporter, the secrets resolver resolving the HMAC token secrets of the webhooks
+func NewReporter(logger *logrus.Entry, secrets *secret.Resolver) *Reporter {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	return &Reporter{
+		client:  &http.Client{Timeout: 30 * time.Second},
+		logger:  logger.WithField("reporter", "status-webhook"),
+		secrets: secrets,
+		backoff: time.Second,
+	}
+}
+
+// Matching returns the webhooks which want the given event of the LighthouseJob
+func Matching(webhooks []lighthouse.StatusWebhook, event string, lhjob *v1alpha1.LighthouseJob) []lighthouse.StatusWebhook {
+	var org, repo string
+	if lhjob.Spec.Refs != nil {
+		org, repo = lhjob.Spec.Refs.Org, lhjob.Spec.Refs.Repo
+	}
+	var matching []lighthouse.StatusWebhook
+	for _, w := range webhooks {
+		if w.Matches(org, repo, event) {
+			matching = append(matching, w)
+		}
+	}
+	return matching
+}
+
+// Report posts the given payload to the webhooks, returning the first error encountered.
+func (r *Reporter) Report(webhooks []lighthouse.StatusWebhook, payload *Payload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status webhook payload: %v", err)
+	}
+	var firstErr error
+	for _, w := range webhooks {
+		logger := r.logger.WithField("webhook", w.Name).WithField("event", payload.Event).WithField("job", payload.Name)
+		if err := r.send(&w, payload.Event, data); err != nil {
+			logger.WithError(err).Warn("failed to post job status to webhook")
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to post job status to webhook %s: %v", w.Name, err)
+			}
+			continue
+		}
+		logger.Debug("posted job status to webhook")
+	}
+	return firstErr
+}
+
+// token returns the token used to sign the payloads of the webhook, resolving its secret reference if any
+func (r *Reporter) token(w *lighthouse.StatusWebhook) (string, error) {
+	if w.HMACTokenSecret != nil {
+		token, err := r.secrets.Resolve(*w.HMACTokenSecret)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve the HMAC token: %v", err)
+		}
+		return token, nil
+	}
+	tokenEnv := w.HMACTokenEnv
+	if tokenEnv == "" {
+		tokenEnv = lighthouse.DefaultStatusWebhookHMACTokenEnv
+	}
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		r.logger.WithField("webhook", w.Name).Warnf("no HMAC token in $%s, posting unsigned payload", tokenEnv)
+	}
+	return token, nil
+}
+
+func (r *Reporter) send(w *lighthouse.StatusWebhook, event string, data []byte) error {
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	headers.Set("User-Agent", util.LighthouseUserAgent)
+	headers.Set(EventHeader, event)
+	token, err := r.token(w)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		headers.Set(util.LighthouseSignatureHeader, Sign(data, token))
+	}
+
+	backoff := r.backoff
+	for retries := 0; retries < maxRetries; retries++ {
+		if retries > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		var retry bool
+		retry, err = r.post(w.URL, data, headers)
+		if err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+// post posts the data to the url, returning whether the request can be retried on error
+func (r *Reporter) post(url string, data []byte, headers http.Header) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	req.Header = headers
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, fmt.Errorf("response has status %q and body %q", resp.Status, string(body))
+	}
+	return false, nil
+}
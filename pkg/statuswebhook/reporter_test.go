@@ -0,0 +1,161 @@
+package statuswebhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEventForState(t *testing.T) {
+	tests := map[v1alpha1.PipelineState]string{
+		v1alpha1.TriggeredState: lighthouse.StatusWebhookTriggered,
+		v1alpha1.QueuedState:    lighthouse.StatusWebhookTriggered,
+		v1alpha1.PendingState:   lighthouse.StatusWebhookRunning,
+		v1alpha1.RunningState:   lighthouse.StatusWebhookRunning,
+		v1alpha1.SuccessState:   lighthouse.StatusWebhookSucceeded,
+		v1alpha1.FailureState:   lighthouse.StatusWebhookFailed,
+		v1alpha1.ErrorState:     lighthouse.StatusWebhookFailed,
+		v1alpha1.AbortedState:   lighthouse.StatusWebhookAborted,
+		"":                      "",
+	}
+	for state, expected := range tests {
+		assert.Equal(t, expected, EventForState(state), "state %q", state)
+	}
+}
+
+func TestMatching(t *testing.T) {
+	webhooks := []lighthouse.StatusWebhook{
+		{Name: "all"},
+		{Name: "org", Repos: []string{"org"}},
+		{Name: "repo", Repos: []string{"org/repo"}},
+		{Name: "other-repo", Repos: []string{"org/other"}},
+		{Name: "failures", Events: []string{lighthouse.StatusWebhookFailed}},
+	}
+	names := func(webhooks []lighthouse.StatusWebhook) []string {
+		var names []string
+		for _, w := range webhooks {
+			names = append(names, w.Name)
+		}
+		return names
+	}
+
+	lhjob := &v1alpha1.LighthouseJob{Spec: v1alpha1.LighthouseJobSpec{Refs: &v1alpha1.Refs{Org: "org", Repo: "repo"}}}
+	assert.Equal(t, []string{"all", "org", "repo"}, names(Matching(webhooks, lighthouse.StatusWebhookSucceeded, lhjob)))
+	assert.Equal(t, []string{"all", "org", "repo", "failures"}, names(Matching(webhooks, lighthouse.StatusWebhookFailed, lhjob)))
+
+	periodic := &v1alpha1.LighthouseJob{Spec: v1alpha1.LighthouseJobSpec{Type: job.PeriodicJob}}
+	assert.Equal(t, []string{"all", "failures"}, names(Matching(webhooks, lighthouse.StatusWebhookFailed, periodic)))
+}
+
+func TestReport(t *testing.T) {
+	const token = "abc123"
+	os.Setenv("TEST_STATUS_WEBHOOK_TOKEN", token)
+	defer os.Unsetenv("TEST_STATUS_WEBHOOK_TOKEN")
+
+	var received []Payload
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, Sign(body, token), r.Header.Get(util.LighthouseSignatureHeader))
+		assert.Equal(t, lighthouse.StatusWebhookSucceeded, r.Header.Get(EventHeader))
+		var payload Payload
+		require.NoError(t, json.Unmarshal(body, &payload))
+		received = append(received, payload)
+	}))
+	defer server.Close()
+
+	lhjob := &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: "jx"},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type:    job.PresubmitJob,
+			Job:     "unit",
+			Context: "unit",
+			Refs:    &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "master"},
+		},
+		Status: v1alpha1.LighthouseJobStatus{
+			State:       v1alpha1.SuccessState,
+			Description: "Pipeline successful",
+		},
+	}
+	r := NewReporter(nil, nil)
+	r.backoff = 0
+	webhooks := []lighthouse.StatusWebhook{{Name: "dashboard", URL: server.URL, HMACTokenEnv: "TEST_STATUS_WEBHOOK_TOKEN"}}
+	err := r.Report(webhooks, NewPayload(lighthouse.StatusWebhookSucceeded, lhjob))
+	require.NoError(t, err)
+
+	require.Len(t, received, 1)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, "succeeded", received[0].Event)
+	assert.Equal(t, "abc", received[0].Name)
+	assert.Equal(t, "unit", received[0].Job)
+	assert.Equal(t, v1alpha1.SuccessState, received[0].State)
+	assert.Equal(t, "org", received[0].Refs.Org)
+}
+
+func TestReportClientError(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	r := NewReporter(nil, nil)
+	r.backoff = 0
+	webhooks := []lighthouse.StatusWebhook{{Name: "dashboard", URL: server.URL}}
+	err := r.Report(webhooks, NewPayload(lighthouse.StatusWebhookFailed, &v1alpha1.LighthouseJob{}))
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts, "client errors should not be retried")
+}
+
+func TestReportSecretToken(t *testing.T) {
+	const token = "s3cr3t"
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, Sign(body, token), r.Header.Get(util.LighthouseSignatureHeader))
+		signatures = append(signatures, r.Header.Get(util.LighthouseSignatureHeader))
+	}))
+	defer server.Close()
+
+	gets := 0
+	secrets := secret.NewResolver(func(name string) (*corev1.Secret, error) {
+		gets++
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data:       map[string][]byte{"hmac": []byte(token + "\n")},
+		}, nil
+	}, time.Hour)
+	r := NewReporter(nil, secrets)
+	r.backoff = 0
+	webhooks := []lighthouse.StatusWebhook{{Name: "dashboard", URL: server.URL, HMACTokenSecret: &secret.Reference{Name: "webhooks", Key: "hmac"}}}
+	require.NoError(t, r.Report(webhooks, NewPayload(lighthouse.StatusWebhookRunning, &v1alpha1.LighthouseJob{})))
+	require.NoError(t, r.Report(webhooks, NewPayload(lighthouse.StatusWebhookSucceeded, &v1alpha1.LighthouseJob{})))
+	assert.Len(t, signatures, 2)
+	assert.Equal(t, 1, gets, "the secret should be cached")
+
+	webhooks[0].HMACTokenSecret.Key = "missing"
+	assert.Error(t, r.Report(webhooks, NewPayload(lighthouse.StatusWebhookFailed, &v1alpha1.LighthouseJob{})))
+	assert.Len(t, signatures, 2, "the payload should not be posted unsigned")
+}
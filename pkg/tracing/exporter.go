@@ -0,0 +1,270 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/version"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// EndpointEnv is the environment variable of the base URL of the OTLP/HTTP collector the spans are sent to
+	EndpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	// TracesEndpointEnv is the environment variable of the full URL the spans are sent to, overriding EndpointEnv
+	TracesEndpointEnv = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	// ServiceNameEnv is the environment variable overriding the service name of the spans of the component
+	ServiceNameEnv = "OTEL_SERVICE_NAME"
+
+	// batchSize is the number of spans triggering an export before the next flush
+	batchSize = 512
+	// flushInterval is the interval between the exports of the spans
+	flushInterval = 5 * time.Second
+	// maxBufferedSpans caps the number of spans waiting to be exported, the extra ones being dropped
+	maxBufferedSpans = 8 * batchSize
+)
+
+var (
+	exporterLock sync.RWMutex
+	exporter     *Exporter
+)
+
+// Init exports the spans of the component to the OTLP/HTTP collector configured in the environment, if any, and
+// returns the function flushing the remaining spans on shutdown
+func Init(service string) func() {
+	url := os.Getenv(TracesEndpointEnv)
+	if url == "" {
+		if endpoint := os.Getenv(EndpointEnv); endpoint != "" {
+			url = strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+		}
+	}
+	if url == "" {
+		return func() {}
+	}
+	if name := os.Getenv(ServiceNameEnv); name != "" {
+		service = name
+	}
+	logrus.WithFields(logrus.Fields{"url": url, "service": service}).Info("exporting the traces")
+	e := NewExporter(url, service)
+	SetExporter(e)
+	return e.Shutdown
+}
+
+// SetExporter sets the exporter of the ended spans, nil disabling the exports
+func SetExporter(e *Exporter) {
+	exporterLock.Lock()
+	defer exporterLock.Unlock()
+	exporter = e
+}
+
+func export(span *Span) {
+	exporterLock.RLock()
+	e := exporter
+	exporterLock.RUnlock()
+	if e != nil {
+		e.add(span)
+	}
+}
+
+// Exporter sends the spans in batches to an OTLP/HTTP collector, in the JSON encoding
+type Exporter struct {
+	url     string
+	service string
+	client  *http.Client
+
+	lock  sync.Mutex
+	spans []*Span
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewExporter creates an exporter sending the spans of the service to the given URL every few seconds
+func NewExporter(url, service string) *Exporter {
+	e := &Exporter{
+		url:     url,
+		service: service,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+func (e *Exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.flushAndLog()
+		case <-e.stop:
+			e.flushAndLog()
+			return
+		}
+	}
+}
+
+// Shutdown stops the exporter once the remaining spans are exported
+func (e *Exporter) Shutdown() {
+	close(e.stop)
+	<-e.done
+}
+
+func (e *Exporter) add(span *Span) {
+	e.lock.Lock()
+	if len(e.spans) >= maxBufferedSpans {
+		e.lock.Unlock()
+		logrus.WithField("span", span.name).Debug("dropping the span as the collector is lagging")
+		return
+	}
+	e.spans = append(e.spans, span)
+	full := len(e.spans) >= batchSize
+	e.lock.Unlock()
+	if full {
+		go e.flushAndLog()
+	}
+}
+
+func (e *Exporter) flushAndLog() {
+	if err := e.Flush(); err != nil {
+		logrus.WithError(err).Warn("failed to export the traces")
+	}
+}
+
+// Flush exports the spans ended since the last export
+func (e *Exporter) Flush() error {
+	e.lock.Lock()
+	spans := e.spans
+	e.spans = nil
+	e.lock.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the spans")
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "failed to send %d spans to %s", len(spans), e.url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send %d spans to %s: status %d", len(spans), e.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// The OTLP/HTTP JSON encoding of the spans, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []spanData `json:"spans"`
+}
+
+type scope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type spanData struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            *status    `json:"status,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// statusCodeError is the code of the status of the failed spans
+const statusCodeError = 2
+
+func (e *Exporter) request(spans []*Span) *exportRequest {
+	var data []spanData
+	for _, s := range spans {
+		data = append(data, s.data())
+	}
+	return &exportRequest{
+		ResourceSpans: []resourceSpans{{
+			Resource: resource{Attributes: []keyValue{
+				{Key: "service.name", Value: anyValue{StringValue: e.service}},
+				{Key: "service.version", Value: anyValue{StringValue: version.Version}},
+			}},
+			ScopeSpans: []scopeSpans{{
+				Scope: scope{Name: "github.com/jenkins-x/lighthouse/pkg/tracing"},
+				Spans: data,
+			}},
+		}},
+	}
+}
+
+func (s *Span) data() spanData {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	answer := spanData{
+		TraceID:           hex.EncodeToString(s.context.TraceID[:]),
+		SpanID:            hex.EncodeToString(s.context.SpanID[:]),
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+	}
+	if s.parentID != [8]byte{} {
+		answer.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	var keys []string
+	for k := range s.attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		answer.Attributes = append(answer.Attributes, keyValue{Key: k, Value: anyValue{StringValue: s.attributes[k]}})
+	}
+	if s.err != nil {
+		answer.Status = &status{Code: statusCodeError, Message: s.err.Error()}
+	}
+	return answer
+}
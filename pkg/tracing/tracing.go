@@ -0,0 +1,191 @@
+// Package tracing records the spans of the processing of the webhooks, from their receipt to the completion of the
+// jobs they trigger, and exports them to an OpenTelemetry collector. The span contexts are propagated between the
+// components in the W3C trace context format, in the traceparent annotation of the LighthouseJobs.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Header is the W3C trace context header of the incoming requests
+	Header = "traceparent"
+
+	// Annotation is the annotation of the LighthouseJobs carrying the context of the span which launched them
+	Annotation = "lighthouse.jenkins-x.io/traceparent"
+)
+
+// Kinds of the spans, as defined by OpenTelemetry
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// SpanContext identifies a span across the components
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// IsValid returns true if the trace and span IDs are set
+func (c SpanContext) IsValid() bool {
+	return c.TraceID != [16]byte{} && c.SpanID != [8]byte{}
+}
+
+// String returns the span context in the W3C traceparent format
+func (c SpanContext) String() string {
+	flags := "00"
+	if c.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", hex.EncodeToString(c.TraceID[:]), hex.EncodeToString(c.SpanID[:]), flags)
+}
+
+// Parse parses a span context in the W3C traceparent format
+func Parse(traceparent string) (SpanContext, bool) {
+	var answer SpanContext
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[3]) != 2 {
+		return answer, false
+	}
+	if n, err := hex.Decode(answer.TraceID[:], []byte(parts[1])); err != nil || n != len(answer.TraceID) || len(parts[1]) != 32 {
+		return answer, false
+	}
+	if n, err := hex.Decode(answer.SpanID[:], []byte(parts[2])); err != nil || n != len(answer.SpanID) || len(parts[2]) != 16 {
+		return answer, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return answer, false
+	}
+	answer.Sampled = flags[0]&1 == 1
+	return answer, answer.IsValid()
+}
+
+// FromAnnotations returns the span context of the annotations of a LighthouseJob, if any
+func FromAnnotations(annotations map[string]string) (SpanContext, bool) {
+	return Parse(annotations[Annotation])
+}
+
+// ContextWithAnnotations returns a context whose spans are children of the span context of the annotations of a
+// LighthouseJob, if any
+func ContextWithAnnotations(ctx context.Context, annotations map[string]string) context.Context {
+	if parent, ok := FromAnnotations(annotations); ok {
+		return ContextWithSpanContext(ctx, parent)
+	}
+	return ctx
+}
+
+// Span is an operation of the processing of a webhook
+type Span struct {
+	name     string
+	kind     int
+	context  SpanContext
+	parentID [8]byte
+	start    time.Time
+
+	lock       sync.Mutex
+	end        time.Time
+	attributes map[string]string
+	err        error
+	ended      bool
+}
+
+type spanKey struct{}
+
+// ContextWithSpanContext returns a context whose spans are children of the given span context, typically the one
+// of a span of another component
+func ContextWithSpanContext(ctx context.Context, parent SpanContext) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, spanKey{}, parent)
+}
+
+// SpanContextFromContext returns the context of the current span of the context, if any
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if ctx == nil {
+		return SpanContext{}, false
+	}
+	sc, ok := ctx.Value(spanKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// Start starts a span, child of the current span of the context if any, and returns a context carrying it. The
+// context may be nil.
+func Start(ctx context.Context, name string, kind int) (context.Context, *Span) {
+	span := &Span{
+		name:       name,
+		kind:       kind,
+		start:      time.Now(),
+		attributes: map[string]string{},
+	}
+	if parent, ok := SpanContextFromContext(ctx); ok {
+		span.context.TraceID = parent.TraceID
+		span.context.Sampled = parent.Sampled
+		span.parentID = parent.SpanID
+	} else {
+		_, _ = rand.Read(span.context.TraceID[:])
+		span.context.Sampled = true
+	}
+	_, _ = rand.Read(span.context.SpanID[:])
+	return ContextWithSpanContext(ctx, span.context), span
+}
+
+// Context returns the span context of the span
+func (s *Span) Context() SpanContext {
+	return s.context
+}
+
+// SetStartTime overrides the start time of the span, e.g. for the spans of past operations
+func (s *Span) SetStartTime(start time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.start = start
+}
+
+// SetAttribute sets an attribute of the span
+func (s *Span) SetAttribute(key, value string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.attributes[key] = value
+}
+
+// RecordError marks the span as failed with the given error, if not nil
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.err = err
+}
+
+// End ends the span now and exports it
+func (s *Span) End() {
+	s.EndAt(time.Now())
+}
+
+// EndAt ends the span at the given time and exports it. Ending a span more than once has no effect.
+func (s *Span) EndAt(end time.Time) {
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = end
+	s.lock.Unlock()
+
+	if s.context.Sampled {
+		export(s)
+	}
+}
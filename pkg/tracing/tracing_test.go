@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	sc, ok := Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.String())
+
+	sc, ok = Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.True(t, ok)
+	assert.False(t, sc.Sampled)
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bz-01",
+	} {
+		_, ok := Parse(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestStart(t *testing.T) {
+	ctx, root := Start(nil, "root", KindServer)
+	require.True(t, root.Context().IsValid())
+	assert.True(t, root.Context().Sampled)
+
+	_, child := Start(ctx, "child", KindInternal)
+	assert.Equal(t, root.Context().TraceID, child.Context().TraceID)
+	assert.NotEqual(t, root.Context().SpanID, child.Context().SpanID)
+	assert.Equal(t, root.Context().SpanID, child.parentID)
+
+	remote, _ := Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := Start(ContextWithSpanContext(context.Background(), remote), "remote", KindInternal)
+	assert.Equal(t, remote.TraceID, span.Context().TraceID)
+	assert.Equal(t, remote.SpanID, span.parentID)
+
+	sc, ok := FromAnnotations(map[string]string{Annotation: remote.String()})
+	require.True(t, ok)
+	assert.Equal(t, remote, sc)
+}
+
+func TestExporter(t *testing.T) {
+	requests := make(chan *exportRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		request := &exportRequest{}
+		require.NoError(t, json.Unmarshal(body, request))
+		requests <- request
+	}))
+	defer server.Close()
+
+	e := NewExporter(server.URL+"/v1/traces", "lighthouse-webhooks")
+	SetExporter(e)
+	defer SetExporter(nil)
+
+	ctx, root := Start(nil, "webhook", KindServer)
+	root.SetAttribute("event_type", "issue_comment")
+	_, child := Start(ctx, "plugin trigger", KindInternal)
+	child.RecordError(errors.New("boom"))
+	child.End()
+	root.End()
+	root.End()
+
+	_, unsampled := Start(ContextWithSpanContext(nil, SpanContext{TraceID: [16]byte{1}, SpanID: [8]byte{1}}), "unsampled", KindInternal)
+	unsampled.End()
+
+	e.Shutdown()
+	request := <-requests
+	require.Len(t, request.ResourceSpans, 1)
+	assert.Equal(t, keyValue{Key: "service.name", Value: anyValue{StringValue: "lighthouse-webhooks"}}, request.ResourceSpans[0].Resource.Attributes[0])
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "plugin trigger", spans[0].Name)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	assert.Equal(t, &status{Code: statusCodeError, Message: "boom"}, spans[0].Status)
+
+	assert.Equal(t, "webhook", spans[1].Name)
+	assert.Equal(t, KindServer, spans[1].Kind)
+	assert.Empty(t, spans[1].ParentSpanID)
+	assert.Equal(t, []keyValue{{Key: "event_type", Value: anyValue{StringValue: "issue_comment"}}}, spans[1].Attributes)
+	assert.Nil(t, spans[1].Status)
+}
@@ -0,0 +1,86 @@
+package crdconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// AdmissionPath the default path of the validating admission webhook
+const AdmissionPath = "/validate-config"
+
+// AdmissionHandler is a validating admission webhook rejecting invalid LighthouseConfig and LighthouseTrigger resources
+type AdmissionHandler struct {
+	// PodNamespace the namespace the jobs run in, used to default the jobs before validating them
+	PodNamespace string
+	// KnownPlugins the plugins which can be enabled, any plugin is accepted if empty
+	KnownPlugins []string
+}
+
+// ServeHTTP handles an AdmissionReview request
+func (h *AdmissionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request: %v", err), http.StatusBadRequest)
+		return
+	}
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		http.Error(w, "invalid AdmissionReview request", http.StatusBadRequest)
+		return
+	}
+	review.Response = h.Review(review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		logrus.WithError(err).Error("failed to write AdmissionReview response")
+	}
+}
+
+// Review validates the resource of the admission request
+func (h *AdmissionHandler) Review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation == admissionv1.Delete {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	var errs field.ErrorList
+	switch req.Kind.Kind {
+	case LighthouseTriggerKind:
+		t := &LighthouseTrigger{}
+		if err := json.Unmarshal(req.Object.Raw, t); err != nil {
+			return denied(fmt.Sprintf("failed to parse %s: %v", req.Kind.Kind, err))
+		}
+		errs = ValidateTrigger(t, h.PodNamespace)
+	case LighthouseConfigKind:
+		c := &LighthouseConfig{}
+		if err := json.Unmarshal(req.Object.Raw, c); err != nil {
+			return denied(fmt.Sprintf("failed to parse %s: %v", req.Kind.Kind, err))
+		}
+		errs = ValidateConfig(c, h.KnownPlugins)
+	default:
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	if len(errs) > 0 {
+		return denied(fmt.Sprintf("%s %s is invalid: %v", req.Kind.Kind, req.Name, errs.ToAggregate()))
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func denied(message string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: message,
+			Code:    http.StatusUnprocessableEntity,
+		},
+	}
+}
@@ -0,0 +1,172 @@
+package crdconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newTrigger(name, repository string, presubmits ...job.Presubmit) *LighthouseTrigger {
+	return &LighthouseTrigger{
+		TypeMeta:   metav1.TypeMeta{Kind: LighthouseTriggerKind},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: LighthouseTriggerSpec{
+			Repository: repository,
+			ConfigSpec: triggerconfig.ConfigSpec{Presubmits: presubmits},
+		},
+	}
+}
+
+func presubmit(name string) job.Presubmit {
+	return job.Presubmit{Base: job.Base{Name: name, Agent: job.TektonPipelineAgent}}
+}
+
+func TestValidateTrigger(t *testing.T) {
+	testCases := []struct {
+		name    string
+		trigger *LighthouseTrigger
+		errors  []string
+	}{
+		{
+			name:    "valid",
+			trigger: newTrigger("valid", "myorg/myrepo", presubmit("lint"), presubmit("unit")),
+		},
+		{
+			name:    "missing repository",
+			trigger: newTrigger("missing", "", presubmit("lint")),
+			errors:  []string{"spec.repository: Required value"},
+		},
+		{
+			name:    "org repository",
+			trigger: newTrigger("org", "myorg", presubmit("lint")),
+			errors:  []string{`spec.repository: Invalid value: "myorg"`},
+		},
+		{
+			name:    "duplicate job",
+			trigger: newTrigger("dupe", "myorg/myrepo", presubmit("lint"), presubmit("lint")),
+			errors:  []string{`spec.presubmits[1].name: Duplicate value: "lint"`},
+		},
+		{
+			name:    "invalid agent",
+			trigger: newTrigger("agent", "myorg/myrepo", job.Presubmit{Base: job.Base{Name: "lint", Agent: "unknown"}}),
+			errors:  []string{`spec.presubmits[0]: Invalid value: "lint": invalid presubmit job lint: agent must be one of`},
+		},
+	}
+	for _, tc := range testCases {
+		errs := ValidateTrigger(tc.trigger, "jx")
+		require.Len(t, errs, len(tc.errors), "%s: %v", tc.name, errs)
+		for i, e := range tc.errors {
+			assert.Contains(t, errs[i].Error(), e, tc.name)
+		}
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	c := &LighthouseConfig{
+		Spec: LighthouseConfigSpec{
+			Repository: "myorg/myrepo",
+			Plugins:    []string{"approve", "-lgtm", "approve", "unknown"},
+		},
+	}
+	errs := ValidateConfig(c, []string{"approve", "lgtm"})
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Error(), `spec.plugins[1]: Invalid value: "-lgtm"`)
+	assert.Contains(t, errs[1].Error(), `spec.plugins[2]: Duplicate value: "approve"`)
+	assert.Contains(t, errs[2].Error(), `spec.plugins[3]: Unsupported value: "unknown"`)
+}
+
+func TestAdmissionHandler(t *testing.T) {
+	h := &AdmissionHandler{PodNamespace: "jx"}
+	testCases := []struct {
+		name    string
+		trigger *LighthouseTrigger
+		allowed bool
+	}{
+		{
+			name:    "valid",
+			trigger: newTrigger("valid", "myorg/myrepo", presubmit("lint")),
+			allowed: true,
+		},
+		{
+			name:    "invalid",
+			trigger: newTrigger("invalid", "myorg", presubmit("lint")),
+		},
+	}
+	for _, tc := range testCases {
+		raw, err := json.Marshal(tc.trigger)
+		require.NoError(t, err)
+		review := admissionv1.AdmissionReview{
+			Request: &admissionv1.AdmissionRequest{
+				UID:       "1234",
+				Name:      tc.trigger.Name,
+				Kind:      metav1.GroupVersionKind{Kind: LighthouseTriggerKind},
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		}
+		body, err := json.Marshal(review)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, AdmissionPath, bytes.NewReader(body)))
+		require.Equal(t, http.StatusOK, w.Code, tc.name)
+
+		response := admissionv1.AdmissionReview{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), tc.name)
+		require.NotNil(t, response.Response, tc.name)
+		assert.Equal(t, "1234", string(response.Response.UID), tc.name)
+		assert.Equal(t, tc.allowed, response.Response.Allowed, tc.name)
+		if !tc.allowed {
+			assert.Contains(t, response.Response.Result.Message, "LighthouseTrigger invalid is invalid: spec.repository", tc.name)
+		}
+	}
+}
+
+func TestStoreGenerate(t *testing.T) {
+	s := &Store{podNamespace: "jx", logger: logrus.WithField("component", "crdconfig")}
+	s.update(
+		[]*LighthouseTrigger{
+			newTrigger("lint", "myorg/myrepo", presubmit("lint")),
+			newTrigger("unit", "myorg/myrepo", presubmit("unit")),
+			newTrigger("invalid", "myorg/other", job.Presubmit{Base: job.Base{Name: "lint", Agent: "unknown"}}),
+		},
+		[]*LighthouseConfig{
+			{Spec: LighthouseConfigSpec{Repository: "myorg/myrepo", Plugins: []string{"approve"}}},
+		},
+	)
+
+	sharedConfig := &config.Config{}
+	sharedPlugins := &plugins.Configuration{Plugins: map[string][]string{"myorg": {"lgtm"}}}
+	cfg, pluginCfg, err := s.Generate(sharedConfig, sharedPlugins, "myorg", "myrepo")
+	require.NoError(t, err)
+	presubmits := cfg.Presubmits["myorg/myrepo"]
+	require.Len(t, presubmits, 2)
+	assert.Equal(t, "lint", presubmits[0].Name)
+	assert.Equal(t, "lint", presubmits[0].Context, "the context should be defaulted")
+	assert.Equal(t, "unit", presubmits[1].Name)
+	assert.Equal(t, []string{"approve"}, pluginCfg.Plugins["myorg/myrepo"])
+	assert.Empty(t, sharedConfig.Presubmits, "the shared config should not be modified")
+	assert.NotContains(t, sharedPlugins.Plugins, "myorg/myrepo", "the shared plugins should not be modified")
+
+	names, ok := s.RepoPlugins("myorg", "myrepo")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"approve"}, names)
+
+	cfg, pluginCfg, err = s.Generate(sharedConfig, sharedPlugins, "myorg", "other")
+	require.NoError(t, err)
+	assert.Same(t, sharedConfig, cfg, "the invalid trigger should be ignored")
+	assert.Same(t, sharedPlugins, pluginCfg)
+}
@@ -0,0 +1,217 @@
+package crdconfig
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/merge"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+const resyncPeriod = 10 * time.Minute
+
+// Store reconciles the LighthouseConfig and LighthouseTrigger resources of a namespace into per repository
+// configurations which are merged into the central configuration
+type Store struct {
+	podNamespace string
+	logger       *logrus.Entry
+
+	factory  dynamicinformer.DynamicSharedInformerFactory
+	triggers cache.GenericLister
+	configs  cache.GenericLister
+
+	lock           sync.RWMutex
+	repoTriggers   map[string]*triggerconfig.Config
+	repoPluginSpec map[string]*LighthouseConfigSpec
+}
+
+// NewStore creates a store watching the resources of the namespace, jobs are defaulted using the pod namespace
+func NewStore(client dynamic.Interface, namespace, podNamespace string) *Store {
+	s := &Store{
+		podNamespace: podNamespace,
+		logger:       logrus.WithField("component", "crdconfig"),
+		factory:      dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, resyncPeriod, namespace, nil),
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { s.reconcile() },
+		UpdateFunc: func(interface{}, interface{}) { s.reconcile() },
+		DeleteFunc: func(interface{}) { s.reconcile() },
+	}
+	triggers := s.factory.ForResource(LighthouseTriggerResource)
+	triggers.Informer().AddEventHandler(handler)
+	s.triggers = triggers.Lister()
+	configs := s.factory.ForResource(LighthouseConfigResource)
+	configs.Informer().AddEventHandler(handler)
+	s.configs = configs.Lister()
+	return s
+}
+
+// Start starts watching the resources and waits for the initial list
+func (s *Store) Start(stop <-chan struct{}) {
+	s.factory.Start(stop)
+	s.factory.WaitForCacheSync(stop)
+	s.reconcile()
+}
+
+// reconcile rebuilds the per repository configurations from the cached resources
+func (s *Store) reconcile() {
+	var triggers []*LighthouseTrigger
+	var configs []*LighthouseConfig
+	objs, err := s.triggers.List(labels.Everything())
+	if err != nil {
+		s.logger.WithError(err).Error("failed to list LighthouseTriggers")
+		return
+	}
+	for _, obj := range objs {
+		t := &LighthouseTrigger{}
+		if err := fromUnstructured(obj, t); err != nil {
+			s.logger.WithError(err).Warn("ignoring invalid LighthouseTrigger")
+			continue
+		}
+		triggers = append(triggers, t)
+	}
+	objs, err = s.configs.List(labels.Everything())
+	if err != nil {
+		s.logger.WithError(err).Error("failed to list LighthouseConfigs")
+		return
+	}
+	for _, obj := range objs {
+		c := &LighthouseConfig{}
+		if err := fromUnstructured(obj, c); err != nil {
+			s.logger.WithError(err).Warn("ignoring invalid LighthouseConfig")
+			continue
+		}
+		configs = append(configs, c)
+	}
+	s.update(triggers, configs)
+}
+
+// update replaces the per repository configurations, resources failing validation are ignored
+func (s *Store) update(triggers []*LighthouseTrigger, configs []*LighthouseConfig) {
+	repoTriggers := map[string]*triggerconfig.Config{}
+	for _, t := range triggers {
+		if errs := ValidateTrigger(t, s.podNamespace); len(errs) > 0 {
+			s.logger.WithField("name", t.Name).WithError(errs.ToAggregate()).Warn("ignoring invalid LighthouseTrigger")
+			continue
+		}
+		spec := t.Spec.ConfigSpec
+		for i := range spec.Presubmits {
+			spec.Presubmits[i].SetDefaults(s.podNamespace)
+			if err := spec.Presubmits[i].SetRegexes(); err != nil {
+				s.logger.WithField("name", t.Name).WithError(err).Warn("ignoring invalid presubmit")
+			}
+		}
+		for i := range spec.Postsubmits {
+			spec.Postsubmits[i].SetDefaults(s.podNamespace)
+			if err := spec.Postsubmits[i].SetRegexes(); err != nil {
+				s.logger.WithField("name", t.Name).WithError(err).Warn("ignoring invalid postsubmit")
+			}
+		}
+		repoTriggers[t.Spec.Repository] = merge.CombineConfigs(repoTriggers[t.Spec.Repository], &triggerconfig.Config{Spec: spec})
+	}
+	repoPluginSpec := map[string]*LighthouseConfigSpec{}
+	for _, c := range configs {
+		if errs := ValidateConfig(c, nil); len(errs) > 0 {
+			s.logger.WithField("name", c.Name).WithError(errs.ToAggregate()).Warn("ignoring invalid LighthouseConfig")
+			continue
+		}
+		if _, ok := repoPluginSpec[c.Spec.Repository]; ok {
+			s.logger.WithField("name", c.Name).Warnf("ignoring duplicate LighthouseConfig for repository %s", c.Spec.Repository)
+			continue
+		}
+		spec := c.Spec
+		repoPluginSpec[c.Spec.Repository] = &spec
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.repoTriggers = repoTriggers
+	s.repoPluginSpec = repoPluginSpec
+}
+
+// Generate returns the configurations with the resources of the repository merged in, or the shared configurations
+// if the repository has no resources
+func (s *Store) Generate(sharedConfig *config.Config, sharedPlugins *plugins.Configuration, owner, repo string) (*config.Config, *plugins.Configuration, error) {
+	if s == nil {
+		return sharedConfig, sharedPlugins, nil
+	}
+	fullName := owner + "/" + repo
+	s.lock.RLock()
+	repoTriggers := s.repoTriggers[fullName]
+	pluginSpec := s.repoPluginSpec[fullName]
+	s.lock.RUnlock()
+	if repoTriggers == nil && pluginSpec == nil {
+		return sharedConfig, sharedPlugins, nil
+	}
+
+	cfg := *sharedConfig
+	pluginCfg := plugins.Configuration{}
+	if sharedPlugins != nil {
+		pluginCfg = *sharedPlugins
+		// lets avoid concurrent modification issues sharing the config updater
+		pluginCfg.ConfigUpdater = plugins.ConfigUpdater{
+			Maps: map[string]plugins.ConfigMapSpec{},
+			GZIP: false,
+		}
+	}
+	if pluginSpec != nil {
+		pluginCfg.Plugins = copyPlugins(pluginCfg.Plugins)
+		pluginCfg.Plugins[fullName] = pluginSpec.Plugins
+	}
+	if repoTriggers != nil {
+		// lets copy the triggers so that merging in the repository does not modify the store
+		pluginCfg.Triggers = append([]plugins.Trigger{}, pluginCfg.Triggers...)
+		if idx := len(pluginCfg.Triggers) - 1; idx >= 0 {
+			pluginCfg.Triggers[idx].Repos = append([]string{}, pluginCfg.Triggers[idx].Repos...)
+		}
+		if err := merge.ConfigMerge(&cfg, &pluginCfg, repoTriggers, owner, repo); err != nil {
+			return sharedConfig, sharedPlugins, errors.Wrapf(err, "failed to merge LighthouseTrigger config with repository %s", fullName)
+		}
+	}
+	return &cfg, &pluginCfg, nil
+}
+
+// RepoPlugins returns the plugins of the repository declared by a LighthouseConfig, if any
+func (s *Store) RepoPlugins(owner, repo string) ([]string, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	spec, ok := s.repoPluginSpec[owner+"/"+repo]
+	if !ok {
+		return nil, false
+	}
+	return spec.Plugins, true
+}
+
+func copyPlugins(m map[string][]string) map[string][]string {
+	answer := map[string][]string{}
+	for k, v := range m {
+		answer[k] = v
+	}
+	return answer
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return errors.Errorf("unexpected object %T", obj)
+	}
+	data, err := json.Marshal(u.Object)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s", u.GetName())
+	}
+	return errors.Wrapf(json.Unmarshal(data, into), "failed to unmarshal %s", u.GetName())
+}
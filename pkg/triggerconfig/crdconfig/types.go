@@ -0,0 +1,60 @@
+package crdconfig
+
+import (
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// LighthouseConfigKind the kind of the per repository plugin configuration resource
+	LighthouseConfigKind = "LighthouseConfig"
+	// LighthouseTriggerKind the kind of the per repository trigger configuration resource
+	LighthouseTriggerKind = "LighthouseTrigger"
+)
+
+var (
+	// LighthouseConfigResource the resource of the LighthouseConfig CRD
+	LighthouseConfigResource = schema.GroupVersionResource{Group: lighthouse.GroupName, Version: "v1alpha1", Resource: "lighthouseconfigs"}
+	// LighthouseTriggerResource the resource of the LighthouseTrigger CRD
+	LighthouseTriggerResource = schema.GroupVersionResource{Group: lighthouse.GroupName, Version: "v1alpha1", Resource: "lighthousetriggers"}
+)
+
+// LighthouseTrigger declares the presubmits, postsubmits and keeper settings of a repository, they are merged into
+// the central configuration the same way as the in repository `triggers.yaml` files
+type LighthouseTrigger struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec holds the trigger configuration of the repository
+	Spec LighthouseTriggerSpec `json:"spec"`
+}
+
+// LighthouseTriggerSpec the trigger configuration of a repository
+type LighthouseTriggerSpec struct {
+	// Repository the full name of the repository, e.g. "myorg/myrepo"
+	Repository string `json:"repository"`
+
+	triggerconfig.ConfigSpec `json:",inline"`
+}
+
+// LighthouseConfig declares the plugins of a repository
+type LighthouseConfig struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec holds the plugin configuration of the repository
+	Spec LighthouseConfigSpec `json:"spec"`
+}
+
+// LighthouseConfigSpec the plugin configuration of a repository
+type LighthouseConfigSpec struct {
+	// Repository the full name of the repository, e.g. "myorg/myrepo"
+	Repository string `json:"repository"`
+
+	// Plugins the plugins enabled for the repository, replacing the ones of the central configuration
+	Plugins []string `json:"plugins,omitempty"`
+}
@@ -0,0 +1,99 @@
+package crdconfig
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateTrigger validates the LighthouseTrigger, the jobs being validated with their defaults applied as they would be
+// once merged into the configuration
+func ValidateTrigger(t *LighthouseTrigger, podNamespace string) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+	errs = append(errs, validateRepository(specPath.Child("repository"), t.Spec.Repository)...)
+
+	names := sets.NewString()
+	for i := range t.Spec.Presubmits {
+		path := specPath.Child("presubmits").Index(i)
+		p := t.Spec.Presubmits[i]
+		if names.Has(p.Name) {
+			errs = append(errs, field.Duplicate(path.Child("name"), p.Name))
+			continue
+		}
+		names.Insert(p.Name)
+		p.SetDefaults(podNamespace)
+		if err := p.Validate(podNamespace); err != nil {
+			errs = append(errs, field.Invalid(path, p.Name, err.Error()))
+			continue
+		}
+		if err := p.SetRegexes(); err != nil {
+			errs = append(errs, field.Invalid(path, p.Name, err.Error()))
+		}
+	}
+
+	names = sets.NewString()
+	for i := range t.Spec.Postsubmits {
+		path := specPath.Child("postsubmits").Index(i)
+		p := t.Spec.Postsubmits[i]
+		if names.Has(p.Name) {
+			errs = append(errs, field.Duplicate(path.Child("name"), p.Name))
+			continue
+		}
+		names.Insert(p.Name)
+		p.SetDefaults(podNamespace)
+		if err := p.Base.Validate(job.PostsubmitJob, podNamespace); err != nil {
+			errs = append(errs, field.Invalid(path, p.Name, err.Error()))
+			continue
+		}
+		if err := p.SetRegexes(); err != nil {
+			errs = append(errs, field.Invalid(path, p.Name, err.Error()))
+		}
+	}
+
+	if k := t.Spec.Keeper; k != nil && k.MergeType != "" {
+		if !validMergeTypes.Has(string(k.MergeType)) {
+			errs = append(errs, field.NotSupported(specPath.Child("keeper", "merge_method"), k.MergeType, validMergeTypes.List()))
+		}
+	}
+	return errs
+}
+
+// ValidateConfig validates the LighthouseConfig, the known plugins are the names of the plugins the server can run
+func ValidateConfig(c *LighthouseConfig, knownPlugins []string) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+	errs = append(errs, validateRepository(specPath.Child("repository"), c.Spec.Repository)...)
+
+	known := sets.NewString(knownPlugins...)
+	names := sets.NewString()
+	for i, name := range c.Spec.Plugins {
+		path := specPath.Child("plugins").Index(i)
+		switch {
+		case strings.HasPrefix(name, plugins.DisabledPluginPrefix):
+			errs = append(errs, field.Invalid(path, name, "plugins of a repository cannot be disabled by a LighthouseConfig"))
+		case names.Has(name):
+			errs = append(errs, field.Duplicate(path, name))
+		case len(known) > 0 && !known.Has(name):
+			errs = append(errs, field.NotSupported(path, name, known.List()))
+		}
+		names.Insert(name)
+	}
+	return errs
+}
+
+var validMergeTypes = sets.NewString("merge", "rebase", "squash")
+
+func validateRepository(path *field.Path, repository string) field.ErrorList {
+	if repository == "" {
+		return field.ErrorList{field.Required(path, "the full name of the repository is required, e.g. myorg/myrepo")}
+	}
+	parts := strings.Split(repository, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return field.ErrorList{field.Invalid(path, repository, "must be the full name of a repository, e.g. myorg/myrepo")}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package inrepo
+
+import (
+	"container/list"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// DefaultContentCacheSize is the default maximum number of SCM responses kept in a ContentCache
+const DefaultContentCacheSize = 5000
+
+var shaRe = regexp.MustCompile(`^(?:[0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// ContentCache caches the files and directory listings of repositories at given commit SHAs so that
+// loading the in-repo configuration does not hit the git provider on every event.
+// Only contents at commit SHAs are cached, as those never change, contents at branches are always fetched.
+type ContentCache struct {
+	lock    sync.Mutex
+	maxSize int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type contentCacheEntry struct {
+	key   string
+	files []*scm.FileEntry
+	data  []byte
+}
+
+// NewContentCache creates a cache keeping at most the given number of responses, evicting the least recently used ones
+func NewContentCache(maxSize int) *ContentCache {
+	if maxSize <= 0 {
+		maxSize = DefaultContentCacheSize
+	}
+	return &ContentCache{
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// Len returns the number of cached responses
+func (c *ContentCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.order.Len()
+}
+
+func (c *ContentCache) get(key string) (*contentCacheEntry, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*contentCacheEntry), true
+}
+
+func (c *ContentCache) put(entry *contentCacheEntry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.entries[entry.key]; ok {
+		e.Value = entry
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*contentCacheEntry).key)
+	}
+}
+
+// cachingClient is a scmProviderClient caching the contents at commit SHAs.
+type cachingClient struct {
+	scmProviderClient
+	cache *ContentCache
+}
+
+// newCachingClient returns a client caching the contents at commit SHAs in the given cache, if any.
+func newCachingClient(client scmProviderClient, cache *ContentCache) scmProviderClient {
+	if cache == nil {
+		return client
+	}
+	return &cachingClient{scmProviderClient: client, cache: cache}
+}
+
+func cacheKey(kind, owner, repo, path, sha string) string {
+	return strings.Join([]string{kind, owner, repo, sha, path}, ":")
+}
+
+// GetFile returns the contents of the file, from the cache if the ref is a commit SHA
+func (c *cachingClient) GetFile(owner, repo, path, ref string) ([]byte, error) {
+	if !shaRe.MatchString(ref) {
+		return c.scmProviderClient.GetFile(owner, repo, path, ref)
+	}
+	key := cacheKey("file", owner, repo, path, ref)
+	if entry, ok := c.cache.get(key); ok {
+		return entry.data, nil
+	}
+	data, err := c.scmProviderClient.GetFile(owner, repo, path, ref)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.put(&contentCacheEntry{key: key, data: data})
+	return data, nil
+}
+
+// ListFiles returns the files of the directory, from the cache if the ref is a commit SHA
+func (c *cachingClient) ListFiles(owner, repo, path, ref string) ([]*scm.FileEntry, error) {
+	if !shaRe.MatchString(ref) {
+		return c.scmProviderClient.ListFiles(owner, repo, path, ref)
+	}
+	key := cacheKey("dir", owner, repo, path, ref)
+	if entry, ok := c.cache.get(key); ok {
+		return entry.files, nil
+	}
+	files, err := c.scmProviderClient.ListFiles(owner, repo, path, ref)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.put(&contentCacheEntry{key: key, files: files})
+	return files, nil
+}
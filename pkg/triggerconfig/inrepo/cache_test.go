@@ -0,0 +1,93 @@
+package inrepo
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSha = "0123456789abcdef0123456789abcdef01234567"
+
+type countingClient struct {
+	getFileCalls   int
+	listFilesCalls int
+}
+
+func (c *countingClient) GetRepositoryByFullName(string) (*scm.Repository, error) {
+	return &scm.Repository{}, nil
+}
+
+func (c *countingClient) GetRef(string, string, string) (string, error) {
+	return testSha, nil
+}
+
+func (c *countingClient) GetFile(owner, repo, path, ref string) ([]byte, error) {
+	c.getFileCalls++
+	return []byte(path + "@" + ref), nil
+}
+
+func (c *countingClient) ListFiles(owner, repo, path, ref string) ([]*scm.FileEntry, error) {
+	c.listFilesCalls++
+	return []*scm.FileEntry{{Name: "triggers.yaml", Path: path + "/triggers.yaml"}}, nil
+}
+
+func TestCachingClientCachesShaRefs(t *testing.T) {
+	fake := &countingClient{}
+	client := newCachingClient(fake, NewContentCache(10))
+
+	for i := 0; i < 3; i++ {
+		data, err := client.GetFile("myorg", "myrepo", ".lighthouse/jenkins-x/triggers.yaml", testSha)
+		require.NoError(t, err)
+		assert.Equal(t, ".lighthouse/jenkins-x/triggers.yaml@"+testSha, string(data))
+
+		files, err := client.ListFiles("myorg", "myrepo", ".lighthouse", testSha)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+	}
+	assert.Equal(t, 1, fake.getFileCalls, "GetFile calls")
+	assert.Equal(t, 1, fake.listFilesCalls, "ListFiles calls")
+
+	_, err := client.GetFile("myorg", "other", ".lighthouse/jenkins-x/triggers.yaml", testSha)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fake.getFileCalls, "GetFile calls for another repository")
+}
+
+func TestCachingClientDoesNotCacheBranches(t *testing.T) {
+	fake := &countingClient{}
+	cache := NewContentCache(10)
+	client := newCachingClient(fake, cache)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetFile("myorg", "myrepo", ".lighthouse/jenkins-x/triggers.yaml", "master")
+		require.NoError(t, err)
+		_, err = client.ListFiles("myorg", "myrepo", ".lighthouse", "master")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, fake.getFileCalls, "GetFile calls")
+	assert.Equal(t, 3, fake.listFilesCalls, "ListFiles calls")
+	assert.Equal(t, 0, cache.Len(), "cached responses")
+}
+
+func TestContentCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	fake := &countingClient{}
+	cache := NewContentCache(2)
+	client := newCachingClient(fake, cache)
+
+	get := func(path string) {
+		_, err := client.GetFile("myorg", "myrepo", path, testSha)
+		require.NoError(t, err)
+	}
+	get("a")
+	get("b")
+	get("a")
+	get("c")
+	assert.Equal(t, 2, cache.Len(), "cached responses")
+	assert.Equal(t, 3, fake.getFileCalls, "GetFile calls before eviction")
+
+	get("a")
+	assert.Equal(t, 3, fake.getFileCalls, "a should still be cached")
+	get("b")
+	assert.Equal(t, 4, fake.getFileCalls, "b should have been evicted")
+}
@@ -10,8 +10,11 @@ import (
 	"github.com/pkg/errors"
 )
 
-// Generate generates the in repository config if enabled for this repository otherwise return the shared config
-func Generate(scmClient scmProviderClient, sharedConfig *config.Config, sharedPlugins *plugins.Configuration, owner, repo, eventRef string) (*config.Config, *plugins.Configuration, error) {
+// Generate generates the in repository config if enabled for this repository otherwise return the shared config.
+//
+// The configuration of the main branch is loaded first, then the configuration at the event ref, typically the base SHA
+// of a pull request, is merged on top of it. The contents at commit SHAs are cached in the given cache, if any.
+func Generate(scmClient scmProviderClient, cache *ContentCache, sharedConfig *config.Config, sharedPlugins *plugins.Configuration, owner, repo, eventRef string) (*config.Config, *plugins.Configuration, error) {
 	fullName := scm.Join(owner, repo)
 	if !sharedConfig.InRepoConfigEnabled(fullName) {
 		return sharedConfig, sharedPlugins, nil
@@ -43,15 +46,21 @@ func Generate(scmClient scmProviderClient, sharedConfig *config.Config, sharedPl
 	}
 
 	// lets load the main branch first then merge in any changes from this PR/branch
-	refs := []string{mainBranch}
+	// resolving the main branch to its head SHA so its contents can be cached
+	mainRef := mainBranch
+	if sha, err := scmClient.GetRef(owner, repo, "heads/"+mainBranch); err == nil && shaRe.MatchString(sha) {
+		mainRef = sha
+	}
+	refs := []string{mainRef}
 
 	eventRef = strings.TrimPrefix(eventRef, "refs/heads/")
 	eventRef = strings.TrimPrefix(eventRef, "refs/tags/")
-	if eventRef != mainBranch && eventRef != "" {
+	if eventRef != mainBranch && eventRef != mainRef && eventRef != "" {
 		refs = append(refs, eventRef)
 	}
+	client := newCachingClient(scmClient, cache)
 	for _, ref := range refs {
-		repoConfig, err := LoadTriggerConfig(scmClient, owner, repo, ref)
+		repoConfig, err := LoadTriggerConfig(client, owner, repo, ref)
 		if err != nil {
 			return sharedConfig, sharedPlugins, errors.Wrapf(err, "failed to create trigger config from local source for repo %s/%s ref %s", owner, repo, ref)
 		}
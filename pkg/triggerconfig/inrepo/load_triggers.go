@@ -69,7 +69,14 @@ func mergeConfigs(m map[string]*triggerconfig.Config) (*triggerconfig.Config, er
 	// lets check for duplicates
 	presubmitNames := map[string]string{}
 	postsubmitNames := map[string]string{}
+	keeperFile := ""
 	for file, cfg := range m {
+		if cfg.Spec.Keeper != nil {
+			if keeperFile != "" {
+				return nil, errors.Errorf("duplicate keeper settings in file %s and %s", keeperFile, file)
+			}
+			keeperFile = file
+		}
 		for _, ps := range cfg.Spec.Presubmits {
 			name := ps.Name
 			otherFile := presubmitNames[name]
@@ -120,7 +127,7 @@ func loadConfigFile(client scmProviderClient, ownerName, repoName, path, sha str
 			}
 
 		}
-		if r.Agent == "" && r.PipelineRunSpec != nil {
+		if r.Agent == "" && (r.PipelineRunSpec != nil || r.PipelineRef != nil) {
 			r.Agent = job.TektonPipelineAgent
 		}
 	}
@@ -132,7 +139,7 @@ func loadConfigFile(client scmProviderClient, ownerName, repoName, path, sha str
 				return nil, errors.Wrapf(err, "failed to load Source for Presubmit %d", i)
 			}
 		}
-		if r.Agent == "" && r.PipelineRunSpec != nil {
+		if r.Agent == "" && (r.PipelineRunSpec != nil || r.PipelineRef != nil) {
 			r.Agent = job.TektonPipelineAgent
 		}
 	}
@@ -4,6 +4,7 @@ import "github.com/jenkins-x/go-scm/scm"
 
 type scmProviderClient interface {
 	GetRepositoryByFullName(string) (*scm.Repository, error)
+	GetRef(string, string, string) (string, error)
 	GetFile(string, string, string, string) ([]byte, error)
 	ListFiles(string, string, string, string) ([]*scm.FileEntry, error)
 }
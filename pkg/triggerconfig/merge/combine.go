@@ -18,5 +18,8 @@ func CombineConfigs(a, b *triggerconfig.Config) *triggerconfig.Config {
 	for _, r := range b.Spec.Postsubmits {
 		a.Spec.Postsubmits = append(a.Spec.Postsubmits, r)
 	}
+	if b.Spec.Keeper != nil {
+		a.Spec.Keeper = b.Spec.Keeper
+	}
 	return a
 }
@@ -3,6 +3,7 @@ package merge
 import (
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/triggerconfig"
 	"github.com/pkg/errors"
@@ -21,7 +22,7 @@ func ConfigMerge(cfg *config.Config, pluginsCfg *plugins.Configuration, repoConf
 		}
 		cfg.Presubmits = m
 
-		ps := cfg.Presubmits[repoKey]
+		ps := append([]job.Presubmit{}, cfg.RepoPresubmits(repoKey)...)
 		for _, p := range repoConfig.Spec.Presubmits {
 			found := false
 			for i := range ps {
@@ -36,7 +37,7 @@ func ConfigMerge(cfg *config.Config, pluginsCfg *plugins.Configuration, repoConf
 				ps = append(ps, p)
 			}
 		}
-		cfg.Presubmits[repoKey] = ps
+		cfg.Presubmits[repoKey] = enabledPresubmits(ps)
 	}
 	if len(repoConfig.Spec.Postsubmits) > 0 {
 		// lets make a new map to avoid concurrent modifications
@@ -48,7 +49,7 @@ func ConfigMerge(cfg *config.Config, pluginsCfg *plugins.Configuration, repoConf
 		}
 		cfg.Postsubmits = m
 
-		ps := cfg.Postsubmits[repoKey]
+		ps := append([]job.Postsubmit{}, cfg.RepoPostsubmits(repoKey)...)
 		for _, p := range repoConfig.Spec.Postsubmits {
 			found := false
 			for i := range ps {
@@ -62,7 +63,14 @@ func ConfigMerge(cfg *config.Config, pluginsCfg *plugins.Configuration, repoConf
 				ps = append(ps, p)
 			}
 		}
-		cfg.Postsubmits[repoKey] = ps
+		cfg.Postsubmits[repoKey] = enabledPostsubmits(ps)
+	}
+
+	if repoConfig.Spec.Keeper != nil {
+		err := mergeKeeper(&cfg.Keeper, repoConfig.Spec.Keeper, repoOwner, repoName)
+		if err != nil {
+			return errors.Wrapf(err, "invalid keeper settings for repository %s", repoKey)
+		}
 	}
 
 	// lets make sure we've got a trigger added
@@ -86,3 +94,61 @@ func ConfigMerge(cfg *config.Config, pluginsCfg *plugins.Configuration, repoConf
 	}
 	return nil
 }
+
+// enabledPresubmits removes the presubmits disabled by the repository
+func enabledPresubmits(jobs []job.Presubmit) []job.Presubmit {
+	var answer []job.Presubmit
+	for _, j := range jobs {
+		if !j.Disable {
+			answer = append(answer, j)
+		}
+	}
+	return answer
+}
+
+// enabledPostsubmits removes the postsubmits disabled by the repository
+func enabledPostsubmits(jobs []job.Postsubmit) []job.Postsubmit {
+	var answer []job.Postsubmit
+	for _, j := range jobs {
+		if !j.Disable {
+			answer = append(answer, j)
+		}
+	}
+	return answer
+}
+
+// mergeKeeper merges the keeper settings of a repository into the keeper configuration
+func mergeKeeper(cfg *keeper.Config, repoConfig *triggerconfig.KeeperConfig, repoOwner string, repoName string) error {
+	repoKey := repoOwner + "/" + repoName
+	if repoConfig.MergeType != "" {
+		if !repoConfig.MergeType.IsValid() {
+			return errors.Errorf("merge type %q is not a valid type", repoConfig.MergeType)
+		}
+		// lets make a new map to avoid concurrent modifications
+		m := map[string]keeper.PullRequestMergeType{}
+		for k, v := range cfg.MergeType {
+			m[k] = v
+		}
+		m[repoKey] = repoConfig.MergeType
+		cfg.MergeType = m
+	}
+	if repoConfig.ContextOptions != nil {
+		if err := repoConfig.ContextOptions.Validate(); err != nil {
+			return errors.Wrap(err, "invalid context options")
+		}
+		orgs := map[string]keeper.OrgContextPolicy{}
+		for k, v := range cfg.ContextOptions.Orgs {
+			orgs[k] = v
+		}
+		org := orgs[repoOwner]
+		repos := map[string]keeper.RepoContextPolicy{}
+		for k, v := range org.Repos {
+			repos[k] = v
+		}
+		repos[repoName] = *repoConfig.ContextOptions
+		org.Repos = repos
+		orgs[repoOwner] = org
+		cfg.ContextOptions.Orgs = orgs
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/triggerconfig"
 	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/merge"
@@ -149,3 +150,36 @@ func LoadYAMLFile(t *testing.T, fileName string, dest interface{}) {
 	err = yaml.Unmarshal(data, dest)
 	require.NoError(t, err, "failed to unmarshal YAML file %s", fileName)
 }
+
+func TestMergeKeeperConfig(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Keeper.MergeType = map[string]keeper.PullRequestMergeType{
+		"myorg": keeper.MergeMerge,
+	}
+	repoConfig := &triggerconfig.Config{
+		Spec: triggerconfig.ConfigSpec{
+			Keeper: &triggerconfig.KeeperConfig{
+				MergeType: keeper.MergeSquash,
+				ContextOptions: &keeper.RepoContextPolicy{
+					ContextPolicy: keeper.ContextPolicy{
+						RequiredContexts: []string{"lint"},
+					},
+				},
+			},
+		},
+	}
+
+	err := merge.ConfigMerge(cfg, &plugins.Configuration{}, repoConfig, "myorg", "myrepo")
+	require.NoError(t, err, "failed to merge repository config")
+
+	assert.Equal(t, keeper.MergeSquash, cfg.Keeper.MergeMethod("myorg", "myrepo"), "merge method of the repository")
+	assert.Equal(t, keeper.MergeMerge, cfg.Keeper.MergeMethod("myorg", "other"), "merge method of the other repositories")
+
+	policy, err := cfg.GetKeeperContextPolicy("myorg", "myrepo", "master")
+	require.NoError(t, err, "failed to get context policy")
+	assert.Equal(t, []string{"lint"}, policy.RequiredContexts, "required contexts of the repository")
+
+	repoConfig.Spec.Keeper.MergeType = "fast-forward"
+	err = merge.ConfigMerge(cfg, &plugins.Configuration{}, repoConfig, "myorg", "myrepo")
+	assert.Error(t, err, "invalid merge method should fail")
+}
@@ -2,6 +2,7 @@ package triggerconfig
 
 import (
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -23,6 +24,18 @@ type ConfigSpec struct {
 
 	// Postsubmit zero or more postsubmits
 	Postsubmits []job.Postsubmit `json:"postsubmits,omitempty"`
+
+	// Keeper the optional keeper settings of the repository
+	Keeper *KeeperConfig `json:"keeper,omitempty"`
+}
+
+// KeeperConfig specifies the keeper settings of a repository which override the central keeper configuration
+type KeeperConfig struct {
+	// MergeType the merge method used to merge the pull requests of the repository, one of "merge", "rebase" or "squash"
+	MergeType keeper.PullRequestMergeType `json:"merge_method,omitempty"`
+
+	// ContextOptions the context policy of the repository and its branches
+	ContextOptions *keeper.RepoContextPolicy `json:"context_options,omitempty"`
 }
 
 // ConfigList contains a list of Config
@@ -7,6 +7,9 @@ const (
 	// OverriddenByPrefix is the beginning of the description for commit statuses set by /override
 	OverriddenByPrefix = "Overridden by"
 
+	// SkippedByPrefix is the beginning of the description for commit statuses set by /skip
+	SkippedByPrefix = "Skipped by"
+
 	// GitHubAppGitRemoteUsername Username for git https URLs when using a GitHub App token.
 	// see https://developer.github.com/apps/building-github-apps/authenticating-with-github-apps/#http-based-git-access-by-an-installation
 	GitHubAppGitRemoteUsername = "x-access-token"
@@ -59,6 +62,10 @@ const (
 	// CloneURIAnnotation is added in resources created by Lighthouse and contains the clone URI for the git repo.
 	CloneURIAnnotation = "lighthouse.jenkins-x.io/cloneURI"
 
+	// WebhookReceivedAnnotation is added to the LighthouseJobs triggered by a webhook and contains the time the
+	// webhook was received, in RFC 3339 format.
+	WebhookReceivedAnnotation = "lighthouse.jenkins-x.io/webhookReceivedTime"
+
 	// GithubServer the default github server URL
 	GithubServer = "https://github.com"
 
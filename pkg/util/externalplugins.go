@@ -17,6 +17,7 @@ import (
 	goscmhmac "github.com/jenkins-x/go-scm/pkg/hmac"
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -164,33 +165,54 @@ func parseWebhook(l *logrus.Entry, req *http.Request, data []byte) (scm.Webhook,
 	return hook, nil
 }
 
-// callExternalPlugins dispatches the provided payload to the external plugins.
-func callExternalPlugins(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, payload []byte, headers http.Header, hmacToken string, wg *sync.WaitGroup) {
+// callExternalPlugins dispatches the provided payload to the external plugins. The payload sent to a plugin with
+// an HMAC token secret is signed with the resolved token instead of the given one.
+func callExternalPlugins(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, payload []byte, headers http.Header, hmacToken string, secrets *secret.Resolver, wg *sync.WaitGroup) {
 	headers.Set("User-Agent", LighthouseUserAgent)
-	mac := hmac.New(sha256.New, []byte(hmacToken))
-	_, err := mac.Write(payload)
+	signature, err := sign(payload, hmacToken)
 	if err != nil {
 		l.WithError(err).Error("Unable to generate signature for relayed payload")
 		return
 	}
-	sum := mac.Sum(nil)
-	signature := "sha256=" + hex.EncodeToString(sum)
 	headers.Set(LighthouseSignatureHeader, signature)
 	for _, p := range externalPlugins {
+		pluginHeaders := headers
+		if p.HMACTokenSecret != nil {
+			token, err := secrets.Resolve(*p.HMACTokenSecret)
+			if err != nil {
+				l.WithError(err).WithField("external-plugin", p.Name).Error("Unable to resolve the HMAC token of the external plugin.")
+				continue
+			}
+			pluginSignature, err := sign(payload, token)
+			if err != nil {
+				l.WithError(err).WithField("external-plugin", p.Name).Error("Unable to generate signature for relayed payload")
+				continue
+			}
+			pluginHeaders = headers.Clone()
+			pluginHeaders.Set(LighthouseSignatureHeader, pluginSignature)
+		}
 		wg.Add(1)
-		go func(p plugins.ExternalPlugin) {
+		go func(p plugins.ExternalPlugin, headers http.Header) {
 			defer wg.Done()
 			if err := dispatch(p.Endpoint, payload, headers); err != nil {
 				l.WithError(err).WithField("external-plugin", p.Name).Error("Error dispatching event to external plugin.")
 			} else {
 				l.WithField("external-plugin", p.Name).Info("Dispatched event to external plugin")
 			}
-		}(p)
+		}(p, pluginHeaders)
+	}
+}
+
+func sign(payload []byte, hmacToken string) (string, error) {
+	mac := hmac.New(sha256.New, []byte(hmacToken))
+	if _, err := mac.Write(payload); err != nil {
+		return "", err
 	}
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil)), nil
 }
 
 // CallExternalPluginsWithActivityRecord dispatches the provided activity record to the external plugins.
-func CallExternalPluginsWithActivityRecord(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, activity *v1alpha1.ActivityRecord, hmacToken string, wg *sync.WaitGroup) {
+func CallExternalPluginsWithActivityRecord(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, activity *v1alpha1.ActivityRecord, hmacToken string, secrets *secret.Resolver, wg *sync.WaitGroup) {
 	headers := http.Header{}
 	headers.Set(LighthousePayloadTypeHeader, LighthousePayloadTypeActivity)
 	payload, err := json.Marshal(activity)
@@ -198,11 +220,11 @@ func CallExternalPluginsWithActivityRecord(l *logrus.Entry, externalPlugins []pl
 		l.WithError(err).Errorf("Unable to marshal activity for relaying to external plugins. Activity is: %v", activity)
 		return
 	}
-	callExternalPlugins(l, externalPlugins, payload, headers, hmacToken, wg)
+	callExternalPlugins(l, externalPlugins, payload, headers, hmacToken, secrets, wg)
 }
 
 // CallExternalPluginsWithWebhook dispatches the provided webhook to the external plugins.
-func CallExternalPluginsWithWebhook(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, webhook scm.Webhook, hmacToken string, wg *sync.WaitGroup) {
+func CallExternalPluginsWithWebhook(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, webhook scm.Webhook, hmacToken string, secrets *secret.Resolver, wg *sync.WaitGroup) {
 	headers := http.Header{}
 	headers.Set(LighthouseWebhookKindHeader, string(webhook.Kind()))
 	headers.Set(LighthousePayloadTypeHeader, LighthousePayloadTypeWebhook)
@@ -211,7 +233,7 @@ func CallExternalPluginsWithWebhook(l *logrus.Entry, externalPlugins []plugins.E
 		l.WithError(err).Errorf("Unable to marshal webhook for relaying to external plugins. Webhook is: %v", webhook)
 		return
 	}
-	callExternalPlugins(l, externalPlugins, payload, headers, hmacToken, wg)
+	callExternalPlugins(l, externalPlugins, payload, headers, hmacToken, secrets, wg)
 }
 
 // dispatch creates a new request using the provided payload and headers
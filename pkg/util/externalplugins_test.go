@@ -1,11 +1,19 @@
 package util_test
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func Test_ExternalPluginsForEvent_returns_empty_slice_for_nil_configuration(t *testing.T) {
@@ -13,3 +21,35 @@ func Test_ExternalPluginsForEvent_returns_empty_slice_for_nil_configuration(t *t
 	plugins := util.ExternalPluginsForEvent(configAgent, util.LighthousePayloadTypeActivity, "myorg/myrepo")
 	require.Empty(t, plugins)
 }
+
+func Test_CallExternalPluginsWithActivityRecord_signs_with_the_plugin_secret(t *testing.T) {
+	var lock sync.Mutex
+	received := 0
+	newPlugin := func(token string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			received++
+			lock.Unlock()
+			_, activity, err := util.ParseExternalPluginEvent(r, token)
+			if assert.NoError(t, err, "token %s", token) {
+				assert.Equal(t, "my-pipeline", activity.Name)
+			}
+		}))
+	}
+	shared := newPlugin("shared")
+	defer shared.Close()
+	own := newPlugin("own")
+	defer own.Close()
+
+	secrets := secret.NewResolver(func(name string) (*corev1.Secret, error) {
+		return &corev1.Secret{Data: map[string][]byte{"hmac": []byte("own")}}, nil
+	}, 0)
+	external := []plugins.ExternalPlugin{
+		{Name: "shared", Endpoint: shared.URL},
+		{Name: "own", Endpoint: own.URL, HMACTokenSecret: &secret.Reference{Name: "plugin", Key: "hmac"}},
+	}
+	wg := &sync.WaitGroup{}
+	util.CallExternalPluginsWithActivityRecord(logrus.WithField("test", t.Name()), external, &v1alpha1.ActivityRecord{Name: "my-pipeline"}, "shared", secrets, wg)
+	wg.Wait()
+	assert.Equal(t, 2, received)
+}
@@ -21,6 +21,8 @@ import (
 // AddAuthToSCMClient configures an existing go-scm client with transport and authorization using the given token,
 // depending on whether the token is a GitHub App token
 func AddAuthToSCMClient(client *scm.Client, token string, isGitHubApp bool) {
+	// the transport is replaced below so record the calls made through the new one
+	defer scmprovider.InstrumentClient(client, token)
 	if isGitHubApp {
 		defaultScmTransport(client)
 		tr := &transport.Custom{
@@ -93,6 +95,9 @@ func GetSCMClient(owner string, cfg config.Getter) (scmprovider.SCMClient, *scm.
 	}
 
 	client, err := factory.NewClient(kind, serverURL, token)
+	if err == nil {
+		scmprovider.InstrumentClient(client, token)
+	}
 	scmClient := scmprovider.ToClient(client, GetBotName(cfg))
 	return scmClient, client, serverURL, token, err
 }
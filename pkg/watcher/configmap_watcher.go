@@ -26,6 +26,7 @@ type ConfigMapWatcher struct {
 	watch      watch.Interface
 	stopped    bool
 	stopCh     <-chan struct{}
+	reloads    *reloadStatuses
 }
 
 // ConfigMapCallback represents a callback
@@ -42,7 +43,30 @@ type ConfigMapEntryCallback struct {
 }
 
 // SetupConfigMapWatchers takes a config agent and plugin agent, each potentially nil, and sets up the appropriate watchers for them.
+// A changed configuration only replaces the current one if it is valid, otherwise the last good configuration is kept
+// and the failure is reported by the metrics and the ReloadStatuses of the watcher.
 func SetupConfigMapWatchers(ns string, configAgent *config.Agent, pluginAgent *plugins.ConfigAgent) (*ConfigMapWatcher, error) {
+	reloads := newReloadStatuses()
+	callbacks := reloadCallbacks(configAgent, pluginAgent, reloads)
+
+	// No watch to configure
+	if len(callbacks) == 0 {
+		return nil, nil
+	}
+	_, kubeClient, _, _, err := clients.GetAPIClients()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create Kube client")
+	}
+
+	w, err := NewConfigMapWatcher(kubeClient, ns, callbacks, util.Stopper())
+	if w != nil {
+		w.reloads = reloads
+	}
+	return w, err
+}
+
+// reloadCallbacks returns the callbacks loading and validating the changed configurations before setting them on the agents
+func reloadCallbacks(configAgent *config.Agent, pluginAgent *plugins.ConfigAgent, reloads *reloadStatuses) []ConfigMapCallback {
 	var callbacks []ConfigMapCallback
 
 	if configAgent != nil {
@@ -50,10 +74,12 @@ func SetupConfigMapWatchers(ns string, configAgent *config.Agent, pluginAgent *p
 			if text != "" {
 				loadedConfig, err := config.LoadYAMLConfig([]byte(text))
 				if err != nil {
-					logrus.WithError(err).Error("Error processing the Lighthouse Config YAML")
+					logrus.WithError(err).Error("Error processing the Lighthouse Config YAML, keeping the previous configuration")
+					reloads.failed(ConfigReloadName, err)
 				} else {
 					logrus.Info("updating the Lighthouse core configuration")
 					configAgent.Set(loadedConfig)
+					reloads.succeeded(ConfigReloadName)
 				}
 			}
 		}
@@ -69,10 +95,12 @@ func SetupConfigMapWatchers(ns string, configAgent *config.Agent, pluginAgent *p
 			if text != "" {
 				loadedConfig, err := pluginAgent.LoadYAMLConfig([]byte(text))
 				if err != nil {
-					logrus.WithError(err).Error("Error processing the Lighthouse Plugins YAML")
+					logrus.WithError(err).Error("Error processing the Lighthouse Plugins YAML, keeping the previous configuration")
+					reloads.failed(PluginsReloadName, err)
 				} else {
 					logrus.Info("updating the Lighthouse plugins configuration")
 					pluginAgent.Set(loadedConfig)
+					reloads.succeeded(PluginsReloadName)
 				}
 			}
 		}
@@ -82,17 +110,7 @@ func SetupConfigMapWatchers(ns string, configAgent *config.Agent, pluginAgent *p
 			Callback: onPluginsYamlChange,
 		})
 	}
-
-	// No watch to configure
-	if len(callbacks) == 0 {
-		return nil, nil
-	}
-	_, kubeClient, _, _, err := clients.GetAPIClients()
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to create Kube client")
-	}
-
-	return NewConfigMapWatcher(kubeClient, ns, callbacks, util.Stopper())
+	return callbacks
 }
 
 // OnChange invokes the callback function if the value is not empty and changes
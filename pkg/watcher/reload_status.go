@@ -0,0 +1,161 @@
+package watcher
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ConfigReloadName is the name of the reload status of the Lighthouse configuration
+	ConfigReloadName = "config"
+	// PluginsReloadName is the name of the reload status of the plugins configuration
+	PluginsReloadName = "plugins"
+
+	// StatusPath is the path the reload status endpoint is usually served on
+	StatusPath = "/config/status"
+)
+
+var (
+	reloadSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lighthouse_config_reload_success",
+		Help: "Whether the last reload of the configuration succeeded (1) or failed and the previous configuration is still in use (0).",
+	}, []string{"config"})
+	reloadFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_config_reload_failures_total",
+		Help: "A counter of the reloads of the configuration which failed validation.",
+	}, []string{"config"})
+	reloadSuccessTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lighthouse_config_last_reload_success_timestamp_seconds",
+		Help: "The time of the last successful reload of the configuration.",
+	}, []string{"config"})
+)
+
+func init() {
+	prometheus.MustRegister(reloadSuccess)
+	prometheus.MustRegister(reloadFailures)
+	prometheus.MustRegister(reloadSuccessTimestamp)
+}
+
+// ReloadStatus is the result of the reloads of a configuration
+type ReloadStatus struct {
+	// Name is the name of the configuration, either "config" or "plugins"
+	Name string `json:"name"`
+	// Healthy is false if the last reload failed and the last good configuration is still in use
+	Healthy bool `json:"healthy"`
+	// LastSuccessTime is when the configuration was last loaded successfully
+	LastSuccessTime *time.Time `json:"lastSuccessTime,omitempty"`
+	// LastFailureTime is when the configuration last failed to load
+	LastFailureTime *time.Time `json:"lastFailureTime,omitempty"`
+	// LastError is the error of the last failed reload, cleared by a successful reload
+	LastError string `json:"lastError,omitempty"`
+	// Failures is the number of failed reloads
+	Failures int `json:"failures"`
+}
+
+// reloadStatuses keeps track of the reload status of each configuration
+type reloadStatuses struct {
+	lock     sync.RWMutex
+	statuses map[string]*ReloadStatus
+	now      func() time.Time
+}
+
+func newReloadStatuses() *reloadStatuses {
+	return &reloadStatuses{
+		statuses: map[string]*ReloadStatus{},
+		now:      time.Now,
+	}
+}
+
+func (r *reloadStatuses) status(name string) *ReloadStatus {
+	s := r.statuses[name]
+	if s == nil {
+		s = &ReloadStatus{Name: name}
+		r.statuses[name] = s
+	}
+	return s
+}
+
+// succeeded records a successful reload of the named configuration
+func (r *reloadStatuses) succeeded(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := r.now()
+	s := r.status(name)
+	s.Healthy = true
+	s.LastSuccessTime = &now
+	s.LastError = ""
+	reloadSuccess.WithLabelValues(name).Set(1)
+	reloadSuccessTimestamp.WithLabelValues(name).Set(float64(now.Unix()))
+}
+
+// failed records a failed reload of the named configuration
+func (r *reloadStatuses) failed(name string, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := r.now()
+	s := r.status(name)
+	s.Healthy = false
+	s.LastFailureTime = &now
+	s.LastError = err.Error()
+	s.Failures++
+	reloadSuccess.WithLabelValues(name).Set(0)
+	reloadFailures.WithLabelValues(name).Inc()
+}
+
+// list returns a copy of the statuses sorted by name
+func (r *reloadStatuses) list() []ReloadStatus {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	answer := []ReloadStatus{}
+	for _, s := range r.statuses {
+		answer = append(answer, *s)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer
+}
+
+// ReloadStatuses returns the reload status of each watched configuration
+func (w *ConfigMapWatcher) ReloadStatuses() []ReloadStatus {
+	if w == nil || w.reloads == nil {
+		return []ReloadStatus{}
+	}
+	return w.reloads.list()
+}
+
+// Healthy returns false if the last reload of any watched configuration failed
+func (w *ConfigMapWatcher) Healthy() bool {
+	return allHealthy(w.ReloadStatuses())
+}
+
+func allHealthy(statuses []ReloadStatus) bool {
+	for _, s := range statuses {
+		if !s.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// StatusHandler returns a handler serving the reload statuses as JSON. It always responds with 200 OK
+// as the last good configuration is still served when a reload fails.
+func (w *ConfigMapWatcher) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		statuses := w.ReloadStatuses()
+		data, err := json.Marshal(statuses)
+		if err != nil {
+			logrus.WithError(err).Error("failed to marshal config reload statuses")
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write(data)
+	})
+}
@@ -0,0 +1,68 @@
+package watcher
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func configMap(name, key, value string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Data:       map[string]string{key: value},
+	}
+}
+
+func TestReloadKeepsLastGoodConfig(t *testing.T) {
+	configAgent := &config.Agent{}
+	pluginAgent := &plugins.ConfigAgent{}
+	w := &ConfigMapWatcher{reloads: newReloadStatuses()}
+	w.callbacks = reloadCallbacks(configAgent, pluginAgent, w.reloads)
+
+	w.invokeCallbacks(configMap(util.ProwConfigMapName, util.ProwConfigFilename, "pod_namespace: jx\n"))
+	w.invokeCallbacks(configMap(util.ProwPluginsConfigMapName, util.ProwPluginsFilename, "plugins: {}\n"))
+	require.NotNil(t, configAgent.Config(), "config should have been loaded")
+	require.NotNil(t, pluginAgent.Config(), "plugins should have been loaded")
+	assert.True(t, w.Healthy(), "watcher should be healthy")
+
+	good := configAgent.Config()
+	goodPlugins := pluginAgent.Config()
+	w.invokeCallbacks(configMap(util.ProwConfigMapName, util.ProwConfigFilename, "pod_namespace: jx\nlog_level: noisy\n"))
+	w.invokeCallbacks(configMap(util.ProwPluginsConfigMapName, util.ProwPluginsFilename, "plugins:\n  myorg: [approve\n"))
+	assert.Same(t, good, configAgent.Config(), "the last good config should be kept")
+	assert.Same(t, goodPlugins, pluginAgent.Config(), "the last good plugins should be kept")
+	assert.False(t, w.Healthy(), "watcher should not be healthy")
+
+	statuses := w.ReloadStatuses()
+	require.Len(t, statuses, 2)
+	for _, s := range statuses {
+		assert.False(t, s.Healthy, "status of %s", s.Name)
+		assert.Equal(t, 1, s.Failures, "failures of %s", s.Name)
+		assert.NotEmpty(t, s.LastError, "last error of %s", s.Name)
+		assert.NotNil(t, s.LastSuccessTime, "last success of %s", s.Name)
+	}
+
+	rw := httptest.NewRecorder()
+	w.StatusHandler().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, StatusPath, nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	var served []ReloadStatus
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &served))
+	require.Len(t, served, 2)
+	assert.Equal(t, ConfigReloadName, served[0].Name)
+	assert.Equal(t, PluginsReloadName, served[1].Name)
+
+	w.invokeCallbacks(configMap(util.ProwConfigMapName, util.ProwConfigFilename, "pod_namespace: lighthouse\n"))
+	assert.Equal(t, "lighthouse", configAgent.Config().PodNamespace, "valid config should be applied")
+	statuses = w.ReloadStatuses()
+	assert.True(t, statuses[0].Healthy, "config should be healthy again")
+	assert.Empty(t, statuses[0].LastError, "last error should be cleared")
+}
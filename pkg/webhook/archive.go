@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/envelope"
+	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ArchivedWebhook is a webhook persisted to the archive so that it can be replayed
+type ArchivedWebhook struct {
+	// ID is the ID of the webhook
+	ID string `json:"id"`
+	// Received is the time the webhook was received at
+	Received time.Time `json:"received"`
+	// Header are the headers of the webhook, whose tokens and signatures are redacted
+	Header http.Header `json:"header"`
+	// Body is the payload of the webhook
+	Body string `json:"body"`
+}
+
+// WebhookArchive persists the webhooks received in a directory for a while, encrypted if a Sealer is given
+type WebhookArchive struct {
+	dir    string
+	maxAge time.Duration
+	sealer *envelope.Sealer
+}
+
+// NewWebhookArchive creates the archive persisting the webhooks in the directory for the given duration. The webhooks
+// are encrypted with envelope encryption if the sealer is not nil.
+func NewWebhookArchive(dir string, maxAge time.Duration, sealer *envelope.Sealer) (*WebhookArchive, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create the webhook archive directory %s", dir)
+	}
+	return &WebhookArchive{dir: dir, maxAge: maxAge, sealer: sealer}, nil
+}
+
+// Save persists the webhook, redacting the tokens and signatures of its headers
+func (a *WebhookArchive) Save(id string, received time.Time, header http.Header, body []byte) error {
+	data, err := json.Marshal(&ArchivedWebhook{
+		ID:       id,
+		Received: received,
+		Header:   envelope.RedactHeaders(header),
+		Body:     string(body),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the webhook %s", id)
+	}
+	data, err = a.sealer.Seal(data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encrypt the webhook %s", id)
+	}
+	path := a.path(id)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write the webhook %s", id)
+	}
+	return errors.Wrapf(os.Rename(tmp, path), "failed to write the webhook %s", id)
+}
+
+// Load returns the archived webhook of the given ID, decrypted
+func (a *WebhookArchive) Load(id string) (*ArchivedWebhook, error) {
+	data, err := ioutil.ReadFile(a.path(id))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the webhook %s", id)
+	}
+	data, err = a.sealer.Open(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt the webhook %s", id)
+	}
+	answer := &ArchivedWebhook{}
+	return answer, errors.Wrapf(json.Unmarshal(data, answer), "failed to unmarshal the webhook %s", id)
+}
+
+// Prune removes the webhooks archived for longer than the maximum age
+func (a *WebhookArchive) Prune() {
+	files, err := ioutil.ReadDir(a.dir)
+	if err != nil {
+		logrus.WithError(err).Warnf("failed to list the webhook archive directory %s", a.dir)
+		return
+	}
+	cutoff := time.Now().Add(-a.maxAge)
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") || f.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(a.dir, f.Name())); err != nil && !os.IsNotExist(err) {
+			logrus.WithError(err).Warnf("failed to remove the archived webhook %s", f.Name())
+		}
+	}
+}
+
+func (a *WebhookArchive) path(id string) string {
+	return filepath.Join(a.dir, filepath.Base(id)+".json")
+}
+
+// StartWebhookArchive archives the webhooks received in the directory for the given duration, encrypted with envelope
+// encryption if the sealer is not nil
+func (o *WebhooksController) StartWebhookArchive(dir string, maxAge time.Duration, sealer *envelope.Sealer) error {
+	archive, err := NewWebhookArchive(dir, maxAge, sealer)
+	if err != nil {
+		return err
+	}
+	interrupts.Tick(archive.Prune, func() time.Duration { return time.Hour })
+	o.archive = archive
+	return nil
+}
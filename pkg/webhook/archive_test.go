@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/envelope"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook-archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "key")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte("0123456789abcdef0123456789abcdef"), 0600))
+	keys, err := envelope.NewLocalKey(keyFile)
+	require.NoError(t, err)
+
+	archive, err := NewWebhookArchive(filepath.Join(dir, "archive"), time.Hour, envelope.NewSealer(keys))
+	require.NoError(t, err)
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "pull_request")
+	header.Set("X-Hub-Signature", "sha1=abc")
+	received := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, archive.Save("0a1b2c3d", received, header, []byte(`{"action":"opened"}`)))
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "archive", "0a1b2c3d.json"))
+	require.NoError(t, err)
+	assert.True(t, envelope.IsSealed(data))
+	assert.NotContains(t, string(data), "opened")
+
+	webhook, err := archive.Load("0a1b2c3d")
+	require.NoError(t, err)
+	assert.Equal(t, "0a1b2c3d", webhook.ID)
+	assert.True(t, received.Equal(webhook.Received))
+	assert.Equal(t, `{"action":"opened"}`, webhook.Body)
+	assert.Equal(t, "pull_request", webhook.Header.Get("X-GitHub-Event"))
+	assert.Equal(t, envelope.Redacted, webhook.Header.Get("X-Hub-Signature"))
+
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "archive", "0a1b2c3d.json"), old, old))
+	archive.Prune()
+	_, err = archive.Load("0a1b2c3d")
+	assert.Error(t, err, "the webhooks older than the maximum age are pruned")
+}
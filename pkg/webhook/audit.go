@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+)
+
+// deliveryHeaders are the headers identifying the deliveries of the webhooks of the git providers
+var deliveryHeaders = []string{
+	"X-GitHub-Delivery",
+	"X-Gitea-Delivery",
+	"X-Gitlab-Event-UUID",
+	"X-Request-UUID",
+}
+
+// auditSource returns the source of the actions caused by a webhook: its sender and its delivery ID
+func auditSource(r *http.Request, webhook scm.Webhook) audit.Source {
+	source := audit.Source{}
+	for _, h := range deliveryHeaders {
+		if id := r.Header.Get(h); id != "" {
+			source.EventID = id
+			break
+		}
+	}
+	// all the hooks of go-scm have a sender, but do not expose it in the webhook interface
+	v := reflect.Indirect(reflect.ValueOf(webhook))
+	if v.Kind() == reflect.Struct {
+		if f := v.FieldByName("Sender"); f.IsValid() && f.CanInterface() {
+			if sender, ok := f.Interface().(scm.User); ok {
+				source.Actor = sender.Login
+			}
+		}
+	}
+	return source
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// checkRunRerequested is the action of a check_run event sent when a user clicks the re-run button of a check run
+const checkRunRerequested = "rerequested"
+
+// checkRunEvent is the part of a GitHub check_run event payload lighthouse needs, go-scm not parsing the check run
+type checkRunEvent struct {
+	Action   string `json:"action"`
+	CheckRun struct {
+		ID         int64  `json:"id"`
+		Name       string `json:"name"`
+		HeadSHA    string `json:"head_sha"`
+		ExternalID string `json:"external_id"`
+	} `json:"check_run"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// ProcessCheckRunHook handles a check_run event, triggering again the job of a check run a user asked to re-run
+func (o *WebhooksController) ProcessCheckRunHook(l *logrus.Entry, body []byte) (*logrus.Entry, string, error) {
+	event := &checkRunEvent{}
+	if err := json.Unmarshal(body, event); err != nil {
+		return l, "", errors.Wrap(err, "failed to parse check_run event")
+	}
+	l = l.WithFields(logrus.Fields{
+		"Action":     event.Action,
+		"Repository": event.Repository.FullName,
+		"CheckRun":   event.CheckRun.Name,
+		"Sender":     event.Sender.Login,
+	})
+	if event.Action != checkRunRerequested {
+		return l, fmt.Sprintf("ignored check run %s event", event.Action), nil
+	}
+	l.Info("invoking check run re-run handler")
+	if err := o.server.handleCheckRunRerequested(l, event); err != nil {
+		return l, "", err
+	}
+	return l, "processed check run hook", nil
+}
+
+// handleCheckRunRerequested launches a copy of the job a check run was created for, its name being the external
+// ID of the check run
+func (s *Server) handleCheckRunRerequested(l *logrus.Entry, event *checkRunEvent) error {
+	name := event.CheckRun.ExternalID
+	if name == "" {
+		l.Debug("ignoring re-run of a check run without external ID")
+		return nil
+	}
+	previous, err := s.ClientAgent.LighthouseClient.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get LighthouseJob %s of check run %s", name, event.CheckRun.Name)
+	}
+	refs := previous.Spec.Refs
+	if refs == nil || !strings.EqualFold(scm.Join(refs.Org, refs.Repo), event.Repository.FullName) {
+		return fmt.Errorf("LighthouseJob %s was not triggered for repository %s", name, event.Repository.FullName)
+	}
+	j := jobutil.NewLighthouseJob(previous.Spec, nil, nil)
+	launcherClient := &eventLauncher{PipelineLauncher: s.ClientAgent.LauncherClient, ctx: l.Context}
+	if _, err := launcherClient.Launch(&j); err != nil {
+		return errors.Wrapf(err, "failed to launch job %s again", previous.Spec.Job)
+	}
+	l.WithField("LighthouseJob", j.Name).Infof("triggered job %s again", previous.Spec.Job)
+	return nil
+}
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	fakelauncher "github.com/jenkins-x/lighthouse/pkg/launcher/fake"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProcessCheckRunHook(t *testing.T) {
+	previous := &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "previous-job"},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type:    job.PresubmitJob,
+			Job:     "unit",
+			Context: "unit",
+			Refs: &v1alpha1.Refs{
+				Org:     "myorg",
+				Repo:    "myrepo",
+				BaseRef: "master",
+				BaseSHA: "base",
+				Pulls:   []v1alpha1.Pull{{Number: 1, SHA: "head"}},
+			},
+		},
+	}
+	testCases := []struct {
+		name     string
+		body     string
+		output   string
+		err      string
+		launched bool
+	}{
+		{
+			name:     "rerequested",
+			body:     `{"action": "rerequested", "check_run": {"id": 1, "name": "unit", "head_sha": "head", "external_id": "previous-job"}, "repository": {"full_name": "myorg/myrepo"}}`,
+			output:   "processed check run hook",
+			launched: true,
+		},
+		{
+			name:   "completed",
+			body:   `{"action": "completed", "check_run": {"id": 1, "name": "unit", "external_id": "previous-job"}, "repository": {"full_name": "myorg/myrepo"}}`,
+			output: "ignored check run completed event",
+		},
+		{
+			name: "other repository",
+			body: `{"action": "rerequested", "check_run": {"id": 1, "name": "unit", "external_id": "previous-job"}, "repository": {"full_name": "myorg/other"}}`,
+			err:  "LighthouseJob previous-job was not triggered for repository myorg/other",
+		},
+		{
+			name: "unknown job",
+			body: `{"action": "rerequested", "check_run": {"id": 1, "name": "unit", "external_id": "unknown"}, "repository": {"full_name": "myorg/myrepo"}}`,
+			err:  "failed to get LighthouseJob unknown of check run unit",
+		},
+	}
+	for _, tc := range testCases {
+		launcher := fakelauncher.NewLauncher()
+		o := &WebhooksController{
+			server: &Server{
+				ClientAgent: &plugins.ClientAgent{
+					LighthouseClient: fake.NewSimpleClientset(previous).LighthouseV1alpha1().LighthouseJobs(""),
+					LauncherClient:   launcher,
+				},
+			},
+		}
+		_, output, err := o.ProcessCheckRunHook(logrus.WithField("test", tc.name), []byte(tc.body))
+		if tc.err != "" {
+			require.Error(t, err, tc.name)
+			assert.Contains(t, err.Error(), tc.err, tc.name)
+		} else {
+			require.NoError(t, err, tc.name)
+			assert.Equal(t, tc.output, output, tc.name)
+		}
+		if !tc.launched {
+			assert.Empty(t, launcher.Pipelines, tc.name)
+			continue
+		}
+		require.Len(t, launcher.Pipelines, 1, tc.name)
+		assert.NotEqual(t, previous.Name, launcher.Pipelines[0].Name, tc.name)
+		assert.Equal(t, previous.Spec, launcher.Pipelines[0].Spec, tc.name)
+	}
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/plugins/codefreeze"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+// StartCodeFreezeSync syncs the code freeze label of the open pull requests of the repositories with a code freeze
+// every period until the context is done, so that the label is added when a code freeze starts and removed once it
+// is lifted, even if the pull requests do not change
+func (o *WebhooksController) StartCodeFreezeSync(ctx context.Context, period time.Duration) {
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := o.server.syncCodeFreezes(time.Now()); err != nil {
+					logrus.WithError(err).Warn("failed to sync the code freezes")
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// syncCodeFreezes syncs the open pull requests of the repositories which enabled the code-freeze plugin and have a
+// code freeze configured, carrying on with the next repositories on errors. The repositories of the orgs enabling the
+// plugin are resolved like for their webhooks, so that the repositories disabling it are skipped.
+func (s *Server) syncCodeFreezes(now time.Time) error {
+	pc := s.Plugins.Config()
+	orgs, repos := pc.EnabledReposForPlugin(codefreeze.PluginName)
+	for _, org := range orgs {
+		scmClient, _, _, _, err := util.GetSCMClient(org, s.ConfigAgent.Config)
+		if err != nil {
+			return fmt.Errorf("failed to create the SCM client of %s: %v", org, err)
+		}
+		orgRepos, err := scmClient.ListOrgRepos(org)
+		if err != nil {
+			return fmt.Errorf("failed to list the repositories of %s: %v", org, err)
+		}
+		for _, r := range orgRepos {
+			repos = append(repos, org+"/"+r.Name)
+		}
+	}
+
+	var errs []string
+	synced := map[string]bool{}
+	for _, fullName := range repos {
+		parts := strings.Split(fullName, "/")
+		if len(parts) != 2 || synced[fullName] {
+			continue
+		}
+		synced[fullName] = true
+		org, repo := parts[0], parts[1]
+		cf := pc.CodeFreezeFor(org, repo)
+		if len(cf.Repos) == 0 {
+			continue
+		}
+		scmClient, client, _, _, err := util.GetSCMClient(org, s.ConfigAgent.Config)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to create the SCM client of %s: %v", org, err))
+			continue
+		}
+		if _, ok := s.pluginsFor(client.Driver.String(), org, repo, "")[codefreeze.PluginName]; !ok {
+			continue
+		}
+		l := logrus.WithFields(logrus.Fields{"plugin": codefreeze.PluginName, "org": org, "repo": repo})
+		if err := codefreeze.Sync(scmClient, l, cf, org, repo, now); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to sync the code freezes of %d repositories: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -17,22 +17,109 @@ limitations under the License.
 package webhook
 
 import (
+	"context"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+	"github.com/jenkins-x/lighthouse/pkg/launcher"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/tracing"
 	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/inrepo"
+	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
 // CreateAgent creates an agent for the given plugin and repository
 // if the repository is configured to use in repository configuration then we create the use the repository specific
-// configuration
+// configuration. The configuration declared by LighthouseConfig and LighthouseTrigger resources is merged in first.
 func (s *Server) CreateAgent(l *logrus.Entry, plugin, owner, repo, ref string) (plugins.Agent, error) {
 	pc := plugins.NewAgent(s.ConfigAgent, s.Plugins, s.ClientAgent, s.ServerURL, l.WithField("plugin", plugin))
+	ctx := l.Context
+	if plugins.IsBestEffort(plugin) {
+		ctx = scmprovider.ContextWithPriority(ctx, scmprovider.PriorityLow)
+	}
+	pc.SCMProviderClient = pc.SCMProviderClient.WithContext(ctx)
+	if s.simulation != nil {
+		pc.LauncherClient = s.simulation.launcher(plugin)
+	} else if pc.LauncherClient != nil {
+		pc.LauncherClient = &eventLauncher{PipelineLauncher: pc.LauncherClient, ctx: l.Context}
+	}
 
 	var err error
-	pc.Config, pc.PluginConfig, err = inrepo.Generate(pc.SCMProviderClient, pc.Config, pc.PluginConfig, owner, repo, ref)
+	pc.Config, pc.PluginConfig, err = s.CRDConfig.Generate(pc.Config, pc.PluginConfig, owner, repo)
+	if err != nil {
+		return pc, errors.Wrapf(err, "failed to calculate LighthouseTrigger config")
+	}
+	pc.Config, pc.PluginConfig, err = inrepo.Generate(pc.SCMProviderClient, s.InRepoCache, pc.Config, pc.PluginConfig, owner, repo, ref)
 	if err != nil {
 		return pc, errors.Wrapf(err, "failed to calculate in repo config")
 	}
 	return pc, nil
 }
+
+type receivedKey struct{}
+
+// contextWithReceivedTime returns a context carrying the time the webhook was received at, recorded on the jobs it
+// triggers to measure the time to their first status
+func contextWithReceivedTime(ctx context.Context, received time.Time) context.Context {
+	return context.WithValue(ctx, receivedKey{}, received)
+}
+
+func receivedTimeFromContext(ctx context.Context) (time.Time, bool) {
+	if ctx == nil {
+		return time.Time{}, false
+	}
+	received, ok := ctx.Value(receivedKey{}).(time.Time)
+	return received, ok
+}
+
+// eventLauncher records the launches of the jobs of a webhook in its trace, if any, and in the audit log. The
+// launched jobs carry the context of the span of their launch so that the engines and foghorn continue the trace, and
+// the ID of the webhook so that they can be looked up by it.
+type eventLauncher struct {
+	launcher.PipelineLauncher
+	ctx context.Context
+}
+
+// Launch launches the job in a span of the trace of the webhook
+func (t *eventLauncher) Launch(request *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
+	if request.Annotations == nil {
+		request.Annotations = map[string]string{}
+	}
+	if id := eventid.FromContext(t.ctx); id != "" {
+		if request.Labels == nil {
+			request.Labels = map[string]string{}
+		}
+		request.Labels[eventid.Label] = id
+	}
+	if received, ok := receivedTimeFromContext(t.ctx); ok {
+		request.Annotations[util.WebhookReceivedAnnotation] = received.UTC().Format(time.RFC3339Nano)
+	}
+	if _, ok := tracing.SpanContextFromContext(t.ctx); !ok {
+		return t.launch(request)
+	}
+	_, span := tracing.Start(t.ctx, "launch "+request.Spec.Job, tracing.KindInternal)
+	defer span.End()
+	span.SetAttribute("job", request.Spec.Job)
+	request.Annotations[tracing.Annotation] = span.Context().String()
+
+	answer, err := t.launch(request)
+	span.RecordError(err)
+	if answer != nil {
+		span.SetAttribute("lighthouse_job", answer.Name)
+	}
+	return answer, err
+}
+
+func (t *eventLauncher) launch(request *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
+	answer, err := t.PipelineLauncher.Launch(request)
+	// the launcher returns the active duplicate of the job instead of creating it, if any
+	if err != nil || answer == nil || answer.Name == request.Name {
+		audit.Record(t.ctx, audit.JobEvent(audit.JobCreated, request), err)
+	}
+	return answer, err
+}
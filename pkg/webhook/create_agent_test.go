@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+	"github.com/jenkins-x/lighthouse/pkg/tracing"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLauncher struct {
+	launched []*v1alpha1.LighthouseJob
+}
+
+func (f *fakeLauncher) Launch(request *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
+	f.launched = append(f.launched, request)
+	answer := request.DeepCopy()
+	answer.Name = "my-job-1"
+	return answer, nil
+}
+
+func TestEventLauncher(t *testing.T) {
+	received := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	ctx := eventid.ContextWithID(contextWithReceivedTime(context.Background(), received), "0a1b2c3d4e")
+	ctx, span := tracing.Start(ctx, "webhook", tracing.KindServer)
+	fake := &fakeLauncher{}
+	l := &eventLauncher{PipelineLauncher: fake, ctx: ctx}
+
+	_, err := l.Launch(&v1alpha1.LighthouseJob{Spec: v1alpha1.LighthouseJobSpec{Job: "my-job"}})
+	require.NoError(t, err)
+	require.Len(t, fake.launched, 1)
+
+	launch, ok := tracing.FromAnnotations(fake.launched[0].Annotations)
+	require.True(t, ok)
+	assert.Equal(t, span.Context().TraceID, launch.TraceID)
+	assert.NotEqual(t, span.Context().SpanID, launch.SpanID)
+	assert.Equal(t, "2020-10-01T12:00:00Z", fake.launched[0].Annotations[util.WebhookReceivedAnnotation])
+	assert.Equal(t, "0a1b2c3d4e", fake.launched[0].Labels[eventid.Label])
+}
@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Outcomes of the plugin handlers
+const (
+	outcomeSuccess = "success"
+	outcomeError   = "error"
+	outcomePanic   = "panic"
+	outcomeTimeout = "timeout"
+)
+
+// errPluginTimeout is the error of the plugin handlers given up on once their timeout expired
+var errPluginTimeout = errors.New("the plugin timed out")
+
+// pluginPanic is the error of the plugin handlers which panicked
+type pluginPanic struct {
+	value interface{}
+}
+
+func (p pluginPanic) Error() string {
+	return fmt.Sprintf("the plugin panicked: %v", p.value)
+}
+
+// pluginOutcome returns the outcome of a plugin handler given its error
+func pluginOutcome(err error) string {
+	switch err.(type) {
+	case nil:
+		return outcomeSuccess
+	case pluginPanic:
+		return outcomePanic
+	}
+	if err == errPluginTimeout {
+		return outcomeTimeout
+	}
+	return outcomeError
+}
+
+// dispatch runs the handler of a plugin for an event in its own goroutine so that all the plugins handle the event
+// concurrently. The panics of the handler are recovered and its calls to the SCM provider are cancelled once the
+// timeout of the plugin expired, so that a crashing or slow plugin neither kills nor delays the handling of the event
+// by the other plugins. The outcome of the handler is recorded in the metrics. The event is only reported as handled,
+// and the comments, labels and statuses of the handler only queued if the server has an action queue, once the
+// handler returned, even if it timed out. The action queue persists them as they are made so that they are applied
+// even if the process crashes before the handler returned.
+// If the server simulates a webhook, they are recorded instead.
+func (s *Server) dispatch(l *logrus.Entry, plugin, eventType, owner, repo, ref string, handle func(agent plugins.Agent) error) {
+	done := s.startHandling(l)
+	go func() {
+		defer done()
+		timeout := plugins.DefaultPluginTimeout
+		if cfg := s.Plugins.Config(); cfg != nil {
+			timeout = cfg.PluginTimeout(plugin)
+		}
+		ctx := l.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		var batch *scmprovider.ActionBatch
+		if s.simulation != nil {
+			ctx = contextWithSimulatedPlugin(ctx, plugin)
+			ctx, batch = scmprovider.ContextWithActionBatch(ctx)
+			defer s.simulation.addBatch(plugin, batch)
+		} else if s.ActionQueue != nil {
+			ctx, batch = s.ActionQueue.Open(ctx)
+			defer func() {
+				if err := s.ActionQueue.Commit(batch); err != nil {
+					l.WithError(err).WithField("plugin", plugin).Errorf("Error queuing the actions of the %s event.", eventType)
+				}
+			}()
+		}
+
+		l, span := startPluginSpan(l.WithContext(ctx), plugin, eventType)
+		defer span.End()
+		agent, err := s.CreateAgent(l, plugin, owner, repo, ref)
+		if err != nil {
+			agent.Logger.WithError(err).Errorf("Error creating agent for %s event.", eventType)
+			span.RecordError(err)
+			return
+		}
+		start := time.Now()
+		err = runHandler(ctx, agent, handle)
+		s.metrics().ObservePluginHandler(plugin, eventType, start, err)
+		span.RecordError(err)
+		if err != nil {
+			agent.Logger.WithError(err).WithField("outcome", pluginOutcome(err)).Errorf("Error handling %s event.", eventType)
+		}
+	}()
+}
+
+// runHandler runs a plugin handler and waits for it to return, returning a pluginPanic error if it panicked, or
+// errPluginTimeout if ctx was done before it returned
+func runHandler(ctx context.Context, agent plugins.Agent, handle func(agent plugins.Agent) error) error {
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				agent.Logger.WithField("stack", string(debug.Stack())).Errorf("Recovered from a panic of the plugin: %v", r)
+				result <- pluginPanic{value: r}
+			}
+		}()
+		result <- handle(agent)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		// the calls of the handler to the SCM provider are cancelled with ctx, it is waited for so that the actions it
+		// takes until it returns are still batched rather than applied alongside the batch being committed
+		agent.Logger.Warn("The plugin timed out, waiting for its handler to return.")
+		<-result
+		return errPluginTimeout
+	}
+}
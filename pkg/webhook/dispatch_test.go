@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatch(t *testing.T) {
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{})
+	pluginConfig := &plugins.Configuration{
+		PluginTimeouts: &plugins.PluginTimeouts{Plugins: map[string]string{"slow": "50ms"}},
+	}
+	require.NoError(t, pluginConfig.Validate())
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(pluginConfig)
+	scmClient, _ := fakescm.NewDefault()
+	outcomes := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "outcomes"}, []string{"plugin", "event_type", "outcome"})
+	metrics := NewMetrics()
+	metrics.PluginHandlerOutcomes = outcomes
+	s := &Server{
+		ConfigAgent: configAgent,
+		Plugins:     pluginAgent,
+		ClientAgent: &plugins.ClientAgent{SCMProviderClient: scmClient},
+		Metrics:     metrics,
+	}
+
+	handled := make(chan string, 4)
+	l := logrus.WithField("test", "dispatch")
+	s.dispatch(l, "panic", "push", "org", "repo", "", func(agent plugins.Agent) error {
+		panic("boom")
+	})
+	s.dispatch(l, "slow", "push", "org", "repo", "", func(agent plugins.Agent) error {
+		<-agent.Logger.Context.Done()
+		time.Sleep(100 * time.Millisecond)
+		handled <- "slow"
+		return nil
+	})
+	s.dispatch(l, "failing", "push", "org", "repo", "", func(agent plugins.Agent) error {
+		handled <- "failing"
+		return errors.New("failed")
+	})
+	s.dispatch(l, "ok", "push", "org", "repo", "", func(agent plugins.Agent) error {
+		handled <- "ok"
+		return nil
+	})
+	assert.Empty(t, s.Drain(10*time.Second), "the slow plugin is waited for once timed out")
+	require.Len(t, handled, 3, "the handlers returned before the event is reported as handled")
+	assert.ElementsMatch(t, []string{"failing", "ok", "slow"}, []string{<-handled, <-handled, <-handled})
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(outcomes.WithLabelValues("panic", "push", outcomePanic)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(outcomes.WithLabelValues("slow", "push", outcomeTimeout)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(outcomes.WithLabelValues("failing", "push", outcomeError)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(outcomes.WithLabelValues("ok", "push", outcomeSuccess)))
+}
+
+func TestDispatchQueuesActions(t *testing.T) {
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{})
+	scmClient, data := fakescm.NewDefault()
+	dir, err := ioutil.TempDir("", "actions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	queue, err := scmprovider.NewActionQueue(dir, func(owner string) (*scmprovider.Client, error) {
+		return scmprovider.ToClient(scmClient, "bot"), nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, queue.Start(1))
+	s := &Server{
+		ConfigAgent: configAgent,
+		Plugins:     pluginAgent,
+		ClientAgent: &plugins.ClientAgent{SCMProviderClient: scmClient},
+		ActionQueue: queue,
+	}
+
+	applied := make(chan int, 1)
+	s.dispatch(logrus.WithField("test", "dispatch"), "ok", "push", "org", "repo", "", func(agent plugins.Agent) error {
+		if err := agent.SCMProviderClient.CreateComment("org", "repo", 1, true, "hello"); err != nil {
+			return err
+		}
+		applied <- len(data.PullRequestComments[1])
+		return agent.SCMProviderClient.AddLabel("org", "repo", 1, "lgtm", true)
+	})
+	assert.Empty(t, s.Drain(10*time.Second))
+	assert.Equal(t, 0, <-applied, "the comment is queued until the handler returned")
+	assert.Equal(t, 0, queue.Stop(10*time.Second))
+	assert.Len(t, data.PullRequestComments[1], 1)
+	assert.Equal(t, []string{"org/repo#1:lgtm"}, data.PullRequestLabelsAdded)
+}
+
+func TestDispatchQueuesActionsOfTimedOutHandlers(t *testing.T) {
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{})
+	pluginConfig := &plugins.Configuration{
+		PluginTimeouts: &plugins.PluginTimeouts{Plugins: map[string]string{"slow": "50ms"}},
+	}
+	require.NoError(t, pluginConfig.Validate())
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(pluginConfig)
+	scmClient, data := fakescm.NewDefault()
+	dir, err := ioutil.TempDir("", "actions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	queue, err := scmprovider.NewActionQueue(dir, func(owner string) (*scmprovider.Client, error) {
+		return scmprovider.ToClient(scmClient, "bot"), nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, queue.Start(1))
+	s := &Server{
+		ConfigAgent: configAgent,
+		Plugins:     pluginAgent,
+		ClientAgent: &plugins.ClientAgent{SCMProviderClient: scmClient},
+		ActionQueue: queue,
+	}
+
+	applied := make(chan int, 1)
+	s.dispatch(logrus.WithField("test", "dispatch"), "slow", "push", "org", "repo", "", func(agent plugins.Agent) error {
+		if err := agent.SCMProviderClient.AddLabel("org", "repo", 1, "lgtm", true); err != nil {
+			return err
+		}
+		<-agent.Logger.Context.Done()
+		time.Sleep(100 * time.Millisecond)
+		applied <- len(data.PullRequestLabelsAdded)
+		return nil
+	})
+	assert.Empty(t, s.Drain(10*time.Second))
+	assert.Equal(t, 0, <-applied, "the batch is not committed while the timed out handler runs")
+	assert.Equal(t, 0, queue.Stop(10*time.Second))
+	assert.Equal(t, []string{"org/repo#1:lgtm"}, data.PullRequestLabelsAdded)
+}
+
+func TestHandleIssueEvent(t *testing.T) {
+	handled := make(chan scm.IssueHook, 1)
+	plugins.RegisterPlugin("test-issue-handler", plugins.Plugin{
+		IssueHandler: func(agent plugins.Agent, ie scm.IssueHook) error {
+			handled <- ie
+			return nil
+		},
+	})
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{Plugins: map[string][]string{"org/repo": {"test-issue-handler"}}})
+	scmClient, _ := fakescm.NewDefault()
+	s := &Server{
+		ConfigAgent: configAgent,
+		Plugins:     pluginAgent,
+		ClientAgent: &plugins.ClientAgent{SCMProviderClient: scmClient},
+		Metrics:     NewMetrics(),
+	}
+
+	s.handleIssueEvent(logrus.WithField("test", "issue"), scm.IssueHook{
+		Action: scm.ActionOpen,
+		Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+		Issue:  scm.Issue{Number: 3},
+	})
+	assert.Empty(t, s.Drain(10*time.Second))
+	ie := <-handled
+	assert.Equal(t, scm.ActionOpen, ie.Action)
+	assert.Equal(t, 3, ie.Issue.Number)
+}
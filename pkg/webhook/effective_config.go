@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/inrepo"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+// EffectiveConfigPath is the path the effective configuration of a repository is served on
+const EffectiveConfigPath = "/config"
+
+// EffectiveConfig is the configuration lighthouse uses for a repository, once the org jobs and plugins are
+// inherited, the LighthouseTrigger resources and in repository configuration merged and the defaults applied.
+type EffectiveConfig struct {
+	// Repository is the full name of the repository
+	Repository string `json:"repository"`
+	// Ref is the branch or commit the in repository configuration was loaded from, the default branch if empty
+	Ref string `json:"ref,omitempty"`
+	// InRepo is true if the in repository configuration is enabled for the repository
+	InRepo bool `json:"in_repo"`
+	// Presubmits are the presubmits of the repository
+	Presubmits []job.Presubmit `json:"presubmits,omitempty"`
+	// Postsubmits are the postsubmits of the repository
+	Postsubmits []job.Postsubmit `json:"postsubmits,omitempty"`
+	// Plugins are the plugins invoked for the events of the repository
+	Plugins []string `json:"plugins,omitempty"`
+	// ExternalPlugins are the external plugins the events of the repository are sent to
+	ExternalPlugins []plugins.ExternalPlugin `json:"external_plugins,omitempty"`
+	// BranchPlugins are the restrictions of the plugins and commands of the repository to some branches
+	BranchPlugins []plugins.BranchPlugins `json:"branch_plugins,omitempty"`
+	// CommandRestrictions are the restrictions of the users and cooldowns of the commands of the repository
+	CommandRestrictions []plugins.CommandRestriction `json:"command_restrictions,omitempty"`
+	// Trigger is the configuration of the trigger plugin for the repository
+	Trigger *plugins.Trigger `json:"trigger,omitempty"`
+	// KeeperQueries are the keeper queries matching the pull requests of the repository
+	KeeperQueries keeper.Queries `json:"keeper_queries,omitempty"`
+}
+
+// EffectiveConfig calculates the effective configuration of the repository, loading its in repository
+// configuration at the given ref, or at the default branch if ref is empty
+func (s *Server) EffectiveConfig(scmClient *scm.Client, owner, repo, ref string) (*EffectiveConfig, error) {
+	fullName := scm.Join(owner, repo)
+	cfg, pluginCfg, inRepo, err := s.repoConfig(scmprovider.ToClient(scmClient, util.GetBotName(s.ConfigAgent.Config)), owner, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	answer := &EffectiveConfig{
+		Repository:    fullName,
+		Ref:           ref,
+		InRepo:        inRepo,
+		Presubmits:    cfg.RepoPresubmits(fullName),
+		Postsubmits:   cfg.RepoPostsubmits(fullName),
+		Trigger:       pluginCfg.TriggerFor(owner, repo),
+		KeeperQueries: cfg.Keeper.Queries.QueryMap().ForRepo(owner, repo),
+	}
+	for name := range s.pluginsFor(scmClient.Driver.String(), owner, repo, "") {
+		answer.Plugins = append(answer.Plugins, name)
+	}
+	sort.Strings(answer.Plugins)
+	answer.ExternalPlugins = append(answer.ExternalPlugins, pluginCfg.ExternalPlugins[owner]...)
+	answer.ExternalPlugins = append(answer.ExternalPlugins, pluginCfg.ExternalPlugins[fullName]...)
+	for _, bp := range pluginCfg.BranchPlugins {
+		for _, r := range bp.Repos {
+			if r == owner || r == fullName {
+				answer.BranchPlugins = append(answer.BranchPlugins, bp)
+				break
+			}
+		}
+	}
+	for _, cr := range pluginCfg.CommandRestrictions {
+		for _, r := range cr.Repos {
+			if r == owner || r == fullName {
+				answer.CommandRestrictions = append(answer.CommandRestrictions, cr)
+				break
+			}
+		}
+	}
+	return answer, nil
+}
+
+// repoConfig returns the configuration and plugins configuration of the repository, once the LighthouseTrigger
+// resources and its in repository configuration at the given ref are merged, and whether the latter is enabled
+func (s *Server) repoConfig(spc *scmprovider.Client, owner, repo, ref string) (*config.Config, *plugins.Configuration, bool, error) {
+	cfg := s.ConfigAgent.Config()
+	pluginCfg := s.Plugins.Config()
+	if cfg == nil || pluginCfg == nil {
+		return nil, nil, false, errors.New("the configuration is not loaded yet")
+	}
+	var err error
+	cfg, pluginCfg, err = s.CRDConfig.Generate(cfg, pluginCfg, owner, repo)
+	if err != nil {
+		return nil, nil, false, errors.Wrapf(err, "failed to calculate LighthouseTrigger config")
+	}
+	inRepo := cfg.InRepoConfigEnabled(scm.Join(owner, repo))
+	if inRepo {
+		cfg, pluginCfg, err = inrepo.Generate(spc, s.InRepoCache, cfg, pluginCfg, owner, repo, ref)
+		if err != nil {
+			return nil, nil, false, errors.Wrapf(err, "failed to calculate in repo config")
+		}
+	}
+	return cfg, pluginCfg, inRepo, nil
+}
+
+// EffectiveConfigHandler serves the effective configuration of the repository given by the `repo` query parameter,
+// e.g. `/config?repo=myorg/myrepo`. The optional `ref` query parameter is the branch or commit the in repository
+// configuration is loaded from and `format=yaml` returns YAML rather than JSON. As it reads the private repositories
+// with the bot token, it must only be served on an internal port.
+func (o *WebhooksController) EffectiveConfigHandler(w http.ResponseWriter, r *http.Request) {
+	fullName := r.URL.Query().Get("repo")
+	parts := strings.Split(fullName, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.Error(w, fmt.Sprintf("the repo query parameter must be the full name of a repository, e.g. myorg/myrepo, not %q", fullName), http.StatusBadRequest)
+		return
+	}
+	owner, repo := parts[0], parts[1]
+
+	_, scmClient, _, _, err := util.GetSCMClient(owner, o.server.ConfigAgent.Config)
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: failed to create SCM client: %s", err.Error()))
+		return
+	}
+	effective, err := o.server.EffectiveConfig(scmClient, owner, repo, r.URL.Query().Get("ref"))
+	if err != nil {
+		logrus.WithError(err).WithField("repo", fullName).Warn("failed to calculate the effective configuration")
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+	writeEffectiveConfig(w, effective, r.URL.Query().Get("format"))
+}
+
+func writeEffectiveConfig(w http.ResponseWriter, effective *EffectiveConfig, format string) {
+	var data []byte
+	var err error
+	contentType := "application/json"
+	if format == "yaml" {
+		contentType = "application/yaml"
+		data, err = yaml.Marshal(effective)
+	} else {
+		data, err = json.MarshalIndent(effective, "", "  ")
+	}
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: failed to marshal the effective configuration: %s", err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(data)
+}
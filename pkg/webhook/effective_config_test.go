@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestEffectiveConfig(t *testing.T) {
+	cfg, err := config.LoadYAMLConfig([]byte(`
+presubmits:
+  myorg:
+  - name: lint
+    agent: tekton
+    always_run: true
+  myorg/myrepo:
+  - name: unit
+    agent: tekton
+    context: unit-tests
+tide:
+  queries:
+  - repos:
+    - myorg/myrepo
+    labels:
+    - approved
+  - repos:
+    - myorg/other
+    labels:
+    - lgtm
+`))
+	require.NoError(t, err)
+	configAgent := &config.Agent{}
+	configAgent.Set(cfg)
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{
+		Plugins: map[string][]string{
+			"myorg":        {"approve", "lgtm"},
+			"myorg/myrepo": {"-lgtm", "hold", "yuks"},
+		},
+		FunPlugins: plugins.FunPlugins{DisabledOrgs: []string{"myorg/myrepo"}},
+		ExternalPlugins: map[string][]plugins.ExternalPlugin{
+			"myorg/myrepo": {{Name: "chatops", Endpoint: "http://chatops"}},
+		},
+		BranchPlugins: []plugins.BranchPlugins{
+			{Repos: []string{"myorg"}, BranchRegexp: "^release-.*$", Commands: []string{"hold"}},
+			{Repos: []string{"otherorg"}, BranchRegexp: "^main$", Commands: []string{"hold"}},
+		},
+		CommandRestrictions: []plugins.CommandRestriction{
+			{Repos: []string{"myorg/myrepo"}, Commands: []string{"retest"}, Cooldown: "10m"},
+			{Repos: []string{"otherorg"}, Commands: []string{"retest"}, Author: true},
+		},
+	})
+	s := &Server{ConfigAgent: configAgent, Plugins: pluginAgent}
+	scmClient, _ := fakescm.NewDefault()
+
+	effective, err := s.EffectiveConfig(scmClient, "myorg", "myrepo", "")
+	require.NoError(t, err)
+	assert.Equal(t, "myorg/myrepo", effective.Repository)
+	assert.False(t, effective.InRepo)
+	require.Len(t, effective.Presubmits, 2)
+	assert.Equal(t, "unit", effective.Presubmits[0].Name)
+	assert.Equal(t, "unit-tests", effective.Presubmits[0].Context)
+	assert.Equal(t, "lint", effective.Presubmits[1].Name, "the org jobs should be inherited")
+	assert.Equal(t, "lint", effective.Presubmits[1].Context, "the defaults should be applied")
+	assert.Equal(t, []string{"approve", "hold"}, effective.Plugins)
+	require.Len(t, effective.ExternalPlugins, 1)
+	assert.Equal(t, "chatops", effective.ExternalPlugins[0].Name)
+	require.Len(t, effective.BranchPlugins, 1)
+	assert.Equal(t, "^release-.*$", effective.BranchPlugins[0].BranchRegexp)
+	require.Len(t, effective.CommandRestrictions, 1)
+	assert.Equal(t, "10m", effective.CommandRestrictions[0].Cooldown)
+	require.Len(t, effective.KeeperQueries, 1)
+	assert.Equal(t, []string{"approved"}, effective.KeeperQueries[0].Labels)
+
+	w := httptest.NewRecorder()
+	writeEffectiveConfig(w, effective, "yaml")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+	decoded := EffectiveConfig{}
+	require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &decoded))
+	assert.Equal(t, effective.Plugins, decoded.Plugins)
+}
+
+func TestEffectiveConfigHandlerInvalidRepo(t *testing.T) {
+	o := &WebhooksController{server: &Server{}}
+	w := httptest.NewRecorder()
+	o.EffectiveConfigHandler(w, httptest.NewRequest(http.MethodGet, EffectiveConfigPath+"?repo=myorg", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// EventDebugPath is the path the lookup of a webhook by the ID surfaced in the comments and statuses of the bot is
+	// served on
+	EventDebugPath = "/debug/event"
+
+	// EventIDHeader is the header of the responses to the webhooks carrying their ID
+	EventIDHeader = "X-Lighthouse-Event"
+)
+
+var eventIDRegex = regexp.MustCompile(`^[0-9a-f]+$`)
+
+// EventLookup correlates the ID of a webhook to its logs and the jobs it triggered
+type EventLookup struct {
+	// ID is the ID of the webhook
+	ID string `json:"id"`
+	// Event is the webhook and the lines logged while processing it, if it was received recently by this replica
+	Event *eventid.Event `json:"event,omitempty"`
+	// LogQuery is the query matching the log lines of the webhook in the logs of all the replicas
+	LogQuery string `json:"logQuery"`
+	// Jobs are the LighthouseJobs triggered by the webhook
+	Jobs []EventJob `json:"jobs"`
+}
+
+// EventJob is a LighthouseJob triggered by a webhook
+type EventJob struct {
+	// Name is the name of the LighthouseJob
+	Name string `json:"name"`
+	// Job is the name of the job
+	Job string `json:"job"`
+	// State is the state of the LighthouseJob
+	State string `json:"state"`
+	// ReportURL is the URL of the logs or report of the LighthouseJob, if any
+	ReportURL string `json:"reportURL,omitempty"`
+}
+
+// EventDebugHandler serves the lookup of the webhook whose ID is given by the `id` query parameter, e.g.
+// `/debug/event?id=0a1b2c3d4e`, as surfaced in the comments and statuses of the bot
+func (o *WebhooksController) EventDebugHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if !eventIDRegex.MatchString(id) {
+		http.Error(w, "the id query parameter is required, e.g. ?id=0a1b2c3d4e", http.StatusBadRequest)
+		return
+	}
+	answer := &EventLookup{
+		ID:       id,
+		LogQuery: fmt.Sprintf("%s=%q", eventid.Field, id),
+		Jobs:     []EventJob{},
+	}
+	if o.events != nil {
+		if event, ok := o.events.Get(id); ok {
+			answer.Event = &event
+		}
+	}
+	list, err := o.lhClient.LighthouseV1alpha1().LighthouseJobs(o.namespace).List(metav1.ListOptions{LabelSelector: eventid.Label + "=" + id})
+	if err != nil {
+		logrus.WithError(err).WithField(eventid.Field, id).Warn("failed to list the jobs of the webhook")
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: failed to list the jobs: %s", err.Error()))
+		return
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+	for _, j := range list.Items {
+		answer.Jobs = append(answer.Jobs, EventJob{
+			Name:      j.Name,
+			Job:       j.Spec.Job,
+			State:     string(j.Status.State),
+			ReportURL: j.Status.ReportURL,
+		})
+	}
+	if answer.Event == nil && len(answer.Jobs) == 0 {
+		http.Error(w, fmt.Sprintf("no recent webhook nor job found for the event %s", id), http.StatusNotFound)
+		return
+	}
+
+	data, err := json.MarshalIndent(answer, "", "  ")
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: failed to marshal the lookup: %s", err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestEventDebugHandler(t *testing.T) {
+	ns := "jx"
+	events := eventid.NewRecorder()
+	events.Add(eventid.Event{ID: "0a1b2c3d4e", Kind: "pull_request", Repository: "myorg/myrepo"})
+	logger := logrus.New()
+	logger.AddHook(events)
+	logger.WithField(eventid.Field, "0a1b2c3d4e").Info("invoking PR handler")
+
+	jobs := []runtime.Object{
+		&v1alpha1.LighthouseJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "myorg-myrepo-pr-12-lint", Namespace: ns, Labels: map[string]string{eventid.Label: "0a1b2c3d4e"}},
+			Spec:       v1alpha1.LighthouseJobSpec{Job: "lint"},
+			Status:     v1alpha1.LighthouseJobStatus{State: v1alpha1.PendingState},
+		},
+		&v1alpha1.LighthouseJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "myorg-myrepo-pr-11-lint", Namespace: ns, Labels: map[string]string{eventid.Label: "5f6a7b8c9d"}},
+			Spec:       v1alpha1.LighthouseJobSpec{Job: "lint"},
+		},
+	}
+	o := &WebhooksController{namespace: ns, events: events, lhClient: fake.NewSimpleClientset(jobs...)}
+
+	w := httptest.NewRecorder()
+	o.EventDebugHandler(w, httptest.NewRequest(http.MethodGet, EventDebugPath+"?id=0a1b2c3d4e", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	lookup := &EventLookup{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), lookup))
+	require.NotNil(t, lookup.Event)
+	assert.Equal(t, "myorg/myrepo", lookup.Event.Repository)
+	require.Len(t, lookup.Event.Logs, 1)
+	assert.Contains(t, lookup.Event.Logs[0], "invoking PR handler")
+	assert.Equal(t, `event_id="0a1b2c3d4e"`, lookup.LogQuery)
+	assert.Equal(t, []EventJob{{Name: "myorg-myrepo-pr-12-lint", Job: "lint", State: "pending"}}, lookup.Jobs)
+
+	// the jobs are found even once the webhook is forgotten
+	w = httptest.NewRecorder()
+	o.EventDebugHandler(w, httptest.NewRequest(http.MethodGet, EventDebugPath+"?id=5f6a7b8c9d", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	o.EventDebugHandler(w, httptest.NewRequest(http.MethodGet, EventDebugPath+"?id=ffffffffff", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	o.EventDebugHandler(w, httptest.NewRequest(http.MethodGet, EventDebugPath+"?id=a,b", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -17,14 +17,22 @@ limitations under the License.
 package webhook
 
 import (
+	"context"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/jenkins-x/lighthouse/pkg/notification"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/policy"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/tracing"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/crdconfig"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/inrepo"
 	"github.com/sirupsen/logrus"
 )
 
@@ -36,15 +44,138 @@ type Server struct {
 	ServerURL      *url.URL
 	TokenGenerator func() []byte
 	Metrics        *Metrics
+	// InRepoCache caches the in-repo configuration contents at commit SHAs, it may be nil
+	InRepoCache *inrepo.ContentCache
+	// CRDConfig holds the configuration declared by LighthouseConfig and LighthouseTrigger resources, it may be nil
+	CRDConfig *crdconfig.Store
+	// Secrets resolves the secret references of the configuration
+	Secrets *secret.Resolver
+	// Notifier posts events to the chat notifications
+	Notifier *notification.Notifier
+	// ActionQueue durably queues the comments, labels and statuses of the plugins, it may be nil
+	ActionQueue *scmprovider.ActionQueue
+	// Policies evaluates the policies authorizing the slash commands, it may be nil
+	Policies *policy.Evaluator
 
 	// Tracks running handlers for graceful shutdown
 	wg sync.WaitGroup
+	// inFlight counts the running handlers per webhook ID
+	inFlight     map[string]int
+	inFlightLock sync.Mutex
+	// cooldowns tracks the cooldowns of the restricted commands
+	cooldowns commandCooldowns
+	// simulation records the actions of the plugins instead of making them, if simulating a webhook
+	simulation *simulation
 }
 
 const failedCommentCoerceFmt = "Could not coerce %s event to a GenericCommentEvent. Unknown 'action': %q."
 
-func (s *Server) getPlugins(org, repo string) map[string]plugins.Plugin {
-	return s.Plugins.GetPlugins(org, repo, s.ClientAgent.SCMProviderClient.Driver.String())
+// genericCommentEventType is the event type of the metrics of the generic comment handlers, which handle the
+// comments of several kinds of webhooks
+const genericCommentEventType = "generic_comment"
+
+// startPluginSpan starts the span of a plugin handling an event, returning the logger carrying it
+func startPluginSpan(l *logrus.Entry, plugin, eventType string) (*logrus.Entry, *tracing.Span) {
+	ctx, span := tracing.Start(l.Context, "plugin "+plugin, tracing.KindInternal)
+	span.SetAttribute("plugin", plugin)
+	span.SetAttribute("event_type", eventType)
+	return l.WithContext(ctx), span
+}
+
+// metrics returns the metrics of the server, defaulting to the registered ones
+func (s *Server) metrics() *Metrics {
+	if s.Metrics == nil {
+		return NewMetrics()
+	}
+	return s.Metrics
+}
+
+// getPlugins returns the plugins of the repository, the branch of the event, if any, removing the plugins and
+// commands which are not allowed on it, and the fun plugins if they are disabled for the repository
+func (s *Server) getPlugins(org, repo, branch string) map[string]plugins.Plugin {
+	return s.pluginsFor(s.ClientAgent.SCMProviderClient.Driver.String(), org, repo, branch)
+}
+
+func (s *Server) pluginsFor(provider, org, repo, branch string) map[string]plugins.Plugin {
+	var answer map[string]plugins.Plugin
+	if names, ok := s.CRDConfig.RepoPlugins(org, repo); ok {
+		answer = s.Plugins.GetPluginsNamed(names, provider)
+	} else {
+		answer = s.Plugins.GetPlugins(org, repo, provider)
+	}
+	cfg := s.Plugins.Config()
+	if cfg == nil {
+		return answer
+	}
+	if !cfg.FunPluginsEnabled(org, repo) {
+		for name := range answer {
+			if plugins.IsFun(name) {
+				delete(answer, name)
+			}
+		}
+	}
+	if branch == "" || !cfg.HasBranchPlugins(org, repo) {
+		return answer
+	}
+	for name, p := range answer {
+		if !cfg.PluginAllowedOnBranch(org, repo, name, branch) {
+			delete(answer, name)
+			continue
+		}
+		var commands []plugins.Command
+		for _, cmd := range p.Commands {
+			if cfg.CommandAllowedOnBranch(org, repo, cmd.Name, branch) {
+				commands = append(commands, cmd)
+			}
+		}
+		p.Commands = commands
+		answer[name] = p
+	}
+	return answer
+}
+
+// pullRequestBranch returns the base branch of an issue comment event on a pull request when plugins or
+// commands of the repository are restricted to some branches, the issue comment events not including it. The
+// restricted plugins and commands are dropped if the pull request cannot be fetched.
+func (s *Server) pullRequestBranch(l *logrus.Entry, ic scm.IssueCommentHook) string {
+	cfg := s.Plugins.Config()
+	if !ic.Issue.PullRequest || cfg == nil || !cfg.HasBranchPlugins(ic.Repo.Namespace, ic.Repo.Name) {
+		return ""
+	}
+	pr, _, err := s.ClientAgent.SCMProviderClient.PullRequests.Find(context.Background(), scm.Join(ic.Repo.Namespace, ic.Repo.Name), ic.Issue.Number)
+	if err != nil {
+		l.WithError(err).Warn("Failed to get the pull request of the comment, skipping the plugins and commands restricted to some branches.")
+		return plugins.UnknownBranch
+	}
+	return pr.Base.Ref
+}
+
+// handleIssueEvent handles an issue event
+func (s *Server) handleIssueEvent(l *logrus.Entry, ie scm.IssueHook) {
+	l = l.WithFields(logrus.Fields{
+		scmprovider.OrgLogField:  ie.Repo.Namespace,
+		scmprovider.RepoLogField: ie.Repo.Name,
+		scmprovider.PrLogField:   ie.Issue.Number,
+		"author":                 ie.Issue.Author.Login,
+		"url":                    ie.Issue.Link,
+	})
+	l.Infof("Issue %s.", ie.Action)
+	c := 0
+	for p, h := range s.getPlugins(ie.Repo.Namespace, ie.Repo.Name, "") {
+		if h.IssueHandler != nil {
+			c++
+			handler := h.IssueHandler
+			s.dispatch(l, p, string(scm.WebhookKindIssue), ie.Repo.Namespace, ie.Repo.Name, "", func(agent plugins.Agent) error {
+				agent.InitializeCommentPruner(
+					ie.Repo.Namespace,
+					ie.Repo.Name,
+					ie.Issue.Number,
+				)
+				return handler(agent, ie)
+			})
+		}
+	}
+	l.WithField("count", strconv.Itoa(c)).Info("number of issue handlers")
 }
 
 // handleIssueCommentEvent handle comment events
@@ -60,6 +191,7 @@ func (s *Server) handleIssueCommentEvent(l *logrus.Entry, ic scm.IssueCommentHoo
 
 	s.handleGenericComment(
 		l,
+		s.pullRequestBranch(l, ic),
 		&scmprovider.GenericCommentEvent{
 			GUID:        strconv.Itoa(ic.Comment.ID),
 			IsPR:        ic.Issue.PullRequest,
@@ -69,6 +201,7 @@ func (s *Server) handleIssueCommentEvent(l *logrus.Entry, ic scm.IssueCommentHoo
 			Number:      ic.Issue.Number,
 			Repo:        ic.Repo,
 			Author:      ic.Comment.Author,
+			Sender:      ic.Sender,
 			IssueAuthor: ic.Issue.Author,
 			Assignees:   ic.Issue.Assignees,
 			IssueState:  ic.Issue.State,
@@ -91,6 +224,7 @@ func (s *Server) handlePullRequestCommentEvent(l *logrus.Entry, pc scm.PullReque
 
 	s.handleGenericComment(
 		l,
+		pc.PullRequest.Base.Ref,
 		&scmprovider.GenericCommentEvent{
 			GUID:        strconv.Itoa(pc.Comment.ID),
 			IsPR:        true,
@@ -100,6 +234,7 @@ func (s *Server) handlePullRequestCommentEvent(l *logrus.Entry, pc scm.PullReque
 			Number:      pc.PullRequest.Number,
 			Repo:        pc.Repo,
 			Author:      pc.Comment.Author,
+			Sender:      pc.Sender,
 			IssueAuthor: pc.PullRequest.Author,
 			Assignees:   pc.PullRequest.Assignees,
 			IssueState:  pc.PullRequest.State,
@@ -109,41 +244,32 @@ func (s *Server) handlePullRequestCommentEvent(l *logrus.Entry, pc scm.PullReque
 	)
 }
 
-func (s *Server) handleGenericComment(l *logrus.Entry, ce *scmprovider.GenericCommentEvent) {
-	for p, h := range s.getPlugins(ce.Repo.Namespace, ce.Repo.Name) {
+func (s *Server) handleGenericComment(l *logrus.Entry, branch string, ce *scmprovider.GenericCommentEvent) {
+	ce = s.checkProvenance(l, ce)
+	ce = s.authorizeCommands(l, ce)
+	s.dispatchGenericComment(l, branch, ce)
+}
+
+// dispatchGenericComment dispatches the comment whose commands were checked and authorized to the plugins
+func (s *Server) dispatchGenericComment(l *logrus.Entry, branch string, ce *scmprovider.GenericCommentEvent) {
+	ce = s.restrictCommands(l, ce)
+	for p, h := range s.getPlugins(ce.Repo.Namespace, ce.Repo.Name, branch) {
 		if h.GenericCommentHandler != nil {
-			s.wg.Add(1)
-			go func(p string, h plugins.GenericCommentHandler) {
-				defer s.wg.Done()
-				agent, err := s.CreateAgent(l, p, ce.Repo.Namespace, ce.Repo.Name, "")
-				if err != nil {
-					agent.Logger.WithError(err).Error("Error creating agent for GenericCommentEvent.")
-					return
-				}
-				if err := h(agent, *ce); err != nil {
-					agent.Logger.WithError(err).Error("Error handling GenericCommentEvent.")
-				}
-			}(p, h.GenericCommentHandler)
+			handler := h.GenericCommentHandler
+			s.dispatch(l, p, genericCommentEventType, ce.Repo.Namespace, ce.Repo.Name, "", func(agent plugins.Agent) error {
+				return handler(agent, *ce)
+			})
 		}
 		for _, cmd := range h.Commands {
 			err := cmd.InvokeCommandHandler(ce, func(handler plugins.CommandEventHandler, e *scmprovider.GenericCommentEvent, match plugins.CommandMatch) error {
-				s.wg.Add(1)
-				go func(p string, h plugins.CommandEventHandler, m plugins.CommandMatch) {
-					defer s.wg.Done()
-					agent, err := s.CreateAgent(l, p, ce.Repo.Namespace, ce.Repo.Name, "")
-					if err != nil {
-						agent.Logger.WithError(err).Error("Error creating agent for GenericCommentEvent.")
-						return
-					}
+				s.dispatch(l, p, genericCommentEventType, ce.Repo.Namespace, ce.Repo.Name, "", func(agent plugins.Agent) error {
 					agent.InitializeCommentPruner(
 						ce.Repo.Namespace,
 						ce.Repo.Name,
 						ce.Number,
 					)
-					if err := h(m, agent, *ce); err != nil {
-						agent.Logger.WithError(err).Error("Error handling GenericCommentEvent.")
-					}
-				}(p, handler, match)
+					return handler(match, agent, *ce)
+				})
 				return nil
 			})
 			if err != nil {
@@ -164,21 +290,13 @@ func (s *Server) handlePushEvent(l *logrus.Entry, pe *scm.PushHook) {
 	})
 	l.Info("Push event.")
 	c := 0
-	for p, h := range s.getPlugins(pe.Repo.Namespace, pe.Repo.Name) {
+	for p, h := range s.getPlugins(pe.Repo.Namespace, pe.Repo.Name, strings.TrimPrefix(pe.Ref, "refs/heads/")) {
 		if h.PushEventHandler != nil {
-			s.wg.Add(1)
 			c++
-			go func(p string, h plugins.PushEventHandler) {
-				defer s.wg.Done()
-				agent, err := s.CreateAgent(l, p, repo.Namespace, repo.Name, pe.Ref)
-				if err != nil {
-					agent.Logger.WithError(err).Error("Error creating agent for PushEvent.")
-					return
-				}
-				if err := h(agent, *pe); err != nil {
-					agent.Logger.WithError(err).Error("Error handling PushEvent.")
-				}
-			}(p, h.PushEventHandler)
+			handler := h.PushEventHandler
+			s.dispatch(l, p, string(scm.WebhookKindPush), repo.Namespace, repo.Name, pe.Ref, func(agent plugins.Agent) error {
+				return handler(agent, *pe)
+			})
 		}
 	}
 	l.WithField("count", strconv.Itoa(c)).Info("number of push handlers")
@@ -199,26 +317,18 @@ func (s *Server) handlePullRequestEvent(l *logrus.Entry, pr *scm.PullRequestHook
 	if repo.Name == "" {
 		repo = pr.Repo
 	}
-	for p, h := range s.getPlugins(repo.Namespace, repo.Name) {
+	for p, h := range s.getPlugins(repo.Namespace, repo.Name, pr.PullRequest.Base.Ref) {
 		if h.PullRequestHandler != nil {
-			s.wg.Add(1)
 			c++
-			go func(p string, h plugins.PullRequestHandler) {
-				defer s.wg.Done()
-				agent, err := s.CreateAgent(l, p, repo.Namespace, repo.Name, pr.PullRequest.Sha)
-				if err != nil {
-					agent.Logger.WithError(err).Error("Error creating agent for PullRequestEvent.")
-					return
-				}
+			handler := h.PullRequestHandler
+			s.dispatch(l, p, string(scm.WebhookKindPullRequest), repo.Namespace, repo.Name, pr.PullRequest.Base.Sha, func(agent plugins.Agent) error {
 				agent.InitializeCommentPruner(
 					pr.Repo.Namespace,
 					pr.Repo.Name,
 					pr.PullRequest.Number,
 				)
-				if err := h(agent, *pr); err != nil {
-					agent.Logger.WithError(err).Error("Error handling PullRequestEvent.")
-				}
-			}(p, h.PullRequestHandler)
+				return handler(agent, *pr)
+			})
 		}
 	}
 	l.WithField("count", strconv.Itoa(c)).Info("number of PR handlers")
@@ -228,6 +338,7 @@ func (s *Server) handlePullRequestEvent(l *logrus.Entry, pr *scm.PullRequestHook
 	}
 	s.handleGenericComment(
 		l,
+		pr.PullRequest.Base.Ref,
 		&scmprovider.GenericCommentEvent{
 			GUID:        pr.GUID,
 			IsPR:        true,
@@ -237,6 +348,7 @@ func (s *Server) handlePullRequestEvent(l *logrus.Entry, pr *scm.PullRequestHook
 			Number:      pr.PullRequest.Number,
 			Repo:        pr.Repo,
 			Author:      pr.PullRequest.Author,
+			Sender:      pr.Sender,
 			IssueAuthor: pr.PullRequest.Author,
 			Assignees:   pr.PullRequest.Assignees,
 			IssueState:  pr.PullRequest.State,
@@ -262,51 +374,44 @@ func (s *Server) handleReviewEvent(l *logrus.Entry, re scm.ReviewHook) {
 		"url":                    re.Review.Link,
 	})
 	l.Infof("Review %s.", re.Action)
-	for p, h := range s.getPlugins(re.PullRequest.Base.Repo.Namespace, re.PullRequest.Base.Repo.Name) {
+	ce := &scmprovider.GenericCommentEvent{
+		GUID:        re.GUID,
+		IsPR:        true,
+		Action:      re.Action,
+		Body:        re.Review.Body,
+		Link:        re.Review.Link,
+		Number:      re.PullRequest.Number,
+		Repo:        re.Repo,
+		Author:      re.Review.Author,
+		IssueAuthor: re.PullRequest.Author,
+		Assignees:   re.PullRequest.Assignees,
+		IssueState:  re.PullRequest.State,
+		IssueBody:   re.PullRequest.Body,
+		IssueLink:   re.PullRequest.Link,
+	}
+	// the review handlers read the commands of the body too, e.g. lgtm and approve, so they only see the
+	// trusted and authorized ones
+	ce = s.authorizeCommands(l, s.checkProvenance(l, ce))
+	re.Review.Body = ce.Body
+	for p, h := range s.getPlugins(re.PullRequest.Base.Repo.Namespace, re.PullRequest.Base.Repo.Name, re.PullRequest.Base.Ref) {
 		repo := re.PullRequest.Base.Repo
 		if h.ReviewEventHandler != nil {
-			s.wg.Add(1)
-			go func(p string, h plugins.ReviewEventHandler) {
-				defer s.wg.Done()
-				agent, err := s.CreateAgent(l, p, repo.Namespace, repo.Name, re.PullRequest.Sha)
-				if err != nil {
-					agent.Logger.WithError(err).Error("Error creating agent for ReviewEvent.")
-					return
-				}
+			handler := h.ReviewEventHandler
+			s.dispatch(l, p, string(scm.WebhookKindReview), repo.Namespace, repo.Name, re.PullRequest.Base.Sha, func(agent plugins.Agent) error {
 				agent.InitializeCommentPruner(
 					re.Repo.Namespace,
 					re.Repo.Name,
 					re.PullRequest.Number,
 				)
-				if err := h(agent, re); err != nil {
-					agent.Logger.WithError(err).Error("Error handling ReviewEvent.")
-				}
-			}(p, h.ReviewEventHandler)
+				return handler(agent, re)
+			})
 		}
 	}
 
-	action := re.Action
-	if !actionRelatesToPullRequestComment(action, l) {
+	if !actionRelatesToPullRequestComment(re.Action, l) {
 		return
 	}
-	s.handleGenericComment(
-		l,
-		&scmprovider.GenericCommentEvent{
-			GUID:        re.GUID,
-			IsPR:        true,
-			Action:      action,
-			Body:        re.Review.Body,
-			Link:        re.Review.Link,
-			Number:      re.PullRequest.Number,
-			Repo:        re.Repo,
-			Author:      re.Review.Author,
-			IssueAuthor: re.PullRequest.Author,
-			Assignees:   re.PullRequest.Assignees,
-			IssueState:  re.PullRequest.State,
-			IssueBody:   re.PullRequest.Body,
-			IssueLink:   re.PullRequest.Link,
-		},
-	)
+	s.dispatchGenericComment(l, re.PullRequest.Base.Ref, ce)
 }
 
 func actionRelatesToPullRequestComment(action scm.Action, l *logrus.Entry) bool {
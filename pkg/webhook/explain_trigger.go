@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/plugins/trigger"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// TriggerDebugPath is the path the explanation of the jobs triggered for a pull request is served on
+	TriggerDebugPath = "/debug/trigger"
+
+	triggerPluginName = "trigger"
+)
+
+// TriggerExplanation explains step by step whether the jobs of a pull request are triggered when it is opened or
+// updated, and which ones
+type TriggerExplanation struct {
+	// Repository is the full name of the repository
+	Repository string `json:"repository"`
+	// Number is the number of the pull request
+	Number int `json:"number"`
+	// Author is the login of the author of the pull request
+	Author string `json:"author,omitempty"`
+	// Branch is the base branch of the pull request
+	Branch string `json:"branch,omitempty"`
+	// SHA is the head commit of the pull request
+	SHA string `json:"sha,omitempty"`
+	// Steps are the checks evaluated in order, the jobs are only triggered if they all passed
+	Steps []TriggerStep `json:"steps"`
+	// Jobs explain whether each presubmit of the repository is triggered once the steps passed
+	Jobs []JobTrigger `json:"jobs,omitempty"`
+}
+
+// TriggerStep is a check evaluated to trigger the jobs of a pull request
+type TriggerStep struct {
+	// Name is the name of the check
+	Name string `json:"name"`
+	// Passed is true if the check passed
+	Passed bool `json:"passed"`
+	// Message explains the result of the check
+	Message string `json:"message"`
+}
+
+// JobTrigger explains whether a presubmit is triggered for a pull request
+type JobTrigger struct {
+	// Name is the name of the presubmit
+	Name string `json:"name"`
+	// Context is the status context of the presubmit
+	Context string `json:"context"`
+	// Triggered is true if the presubmit is triggered
+	Triggered bool `json:"triggered"`
+	// Reason explains why the presubmit is triggered or not
+	Reason string `json:"reason"`
+}
+
+func (e *TriggerExplanation) step(name string, passed bool, format string, args ...interface{}) bool {
+	e.Steps = append(e.Steps, TriggerStep{Name: name, Passed: passed, Message: fmt.Sprintf(format, args...)})
+	return passed
+}
+
+// ExplainTrigger re-evaluates the rules of the trigger plugin against the live pull request: its state, the
+// enablement of the plugin, the presubmits of the repository, the trust of its author and, for each presubmit, its
+// branches and run_if_changed. It explains the jobs triggered automatically, not the ones requested by a command.
+func (s *Server) ExplainTrigger(scmClient *scm.Client, owner, repo string, number int) (*TriggerExplanation, error) {
+	spc := scmprovider.ToClient(scmClient, util.GetBotName(s.ConfigAgent.Config))
+	pr, err := spc.GetPullRequest(owner, repo, number)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get pull request %s#%d", scm.Join(owner, repo), number)
+	}
+	answer := &TriggerExplanation{
+		Repository: scm.Join(owner, repo),
+		Number:     number,
+		Author:     pr.Author.Login,
+		Branch:     pr.Base.Ref,
+		SHA:        pr.Head.Sha,
+	}
+	branch := pr.Base.Ref
+	if pr.Closed || pr.Merged {
+		answer.step("pull request", false, "the pull request is %s, its jobs are not triggered", pr.State)
+		return answer, nil
+	}
+	answer.step("pull request", true, "the pull request is open against the %s branch", branch)
+
+	cfg, pluginCfg, inRepo, err := s.repoConfig(spc, owner, repo, pr.Base.Sha)
+	if err != nil {
+		answer.step("configuration", false, "failed to load the configuration: %v", err)
+		return answer, nil
+	}
+	if inRepo {
+		answer.step("configuration", true, "the in repository configuration is merged at the base commit %s", pr.Base.Sha)
+	} else {
+		answer.step("configuration", true, "the in repository configuration is not enabled")
+	}
+
+	if _, ok := s.pluginsFor(scmClient.Driver.String(), owner, repo, branch)[triggerPluginName]; !ok {
+		answer.step("plugin", false, "the %s plugin is not enabled for the repository on the %s branch", triggerPluginName, branch)
+		return answer, nil
+	}
+	answer.step("plugin", true, "the %s plugin is enabled for the repository", triggerPluginName)
+
+	repository := pr.Base.Repo
+	if repository.Name == "" {
+		repository = scm.Repository{Namespace: owner, Name: repo, FullName: scm.Join(owner, repo)}
+	}
+	presubmits := cfg.GetPresubmits(repository)
+	if len(presubmits) == 0 {
+		answer.step("presubmits", false, "no presubmits are configured for the repository")
+		return answer, nil
+	}
+	answer.step("presubmits", true, "%d presubmits are configured for the repository", len(presubmits))
+
+	trusted, message, err := trigger.ExplainTrust(spc, pluginCfg.TriggerFor(owner, repo), pr.Author.Login, owner, repo, number)
+	if err != nil {
+		answer.step("trust", false, "failed to check the trust of the pull request: %v", err)
+	} else {
+		answer.step("trust", trusted, "%s", message)
+	}
+
+	changes := job.NewGitHubDeferredChangedFilesProvider(spc, owner, repo, number)
+	for _, p := range presubmits {
+		jt := JobTrigger{Name: p.Name, Context: p.Context}
+		jt.Triggered, jt.Reason, err = trigger.ExplainPresubmit(p, branch, changes)
+		if err != nil {
+			jt.Reason = err.Error()
+		}
+		answer.Jobs = append(answer.Jobs, jt)
+	}
+	return answer, nil
+}
+
+// TriggerDebugHandler serves the explanation of the jobs triggered for the pull request given by the `org`, `repo`
+// and `pr` query parameters, e.g. `/debug/trigger?org=myorg&repo=myrepo&pr=12`. `format=text` returns plain text
+// rather than JSON. As it reads the private pull requests and configuration with the bot token, it must only be served
+// on an internal port.
+func (o *WebhooksController) TriggerDebugHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	owner, repo := query.Get("org"), query.Get("repo")
+	number, err := strconv.Atoi(query.Get("pr"))
+	if owner == "" || repo == "" || err != nil {
+		http.Error(w, "the org, repo and pr query parameters are required, e.g. ?org=myorg&repo=myrepo&pr=12", http.StatusBadRequest)
+		return
+	}
+
+	_, scmClient, _, _, err := util.GetSCMClient(owner, o.server.ConfigAgent.Config)
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: failed to create SCM client: %s", err.Error()))
+		return
+	}
+	explanation, err := o.server.ExplainTrigger(scmClient, owner, repo, number)
+	if err != nil {
+		logrus.WithError(err).WithField("repo", scm.Join(owner, repo)).WithField("pr", number).Warn("failed to explain the trigger of the jobs")
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+	writeTriggerExplanation(w, explanation, query.Get("format"))
+}
+
+func writeTriggerExplanation(w http.ResponseWriter, explanation *TriggerExplanation, format string) {
+	if format == "text" {
+		buf := &bytes.Buffer{}
+		fmt.Fprintf(buf, "%s#%d by %s against %s at %s\n", explanation.Repository, explanation.Number, explanation.Author, explanation.Branch, explanation.SHA)
+		for _, s := range explanation.Steps {
+			fmt.Fprintf(buf, "%s %s: %s\n", checkMark(s.Passed), s.Name, s.Message)
+		}
+		if len(explanation.Jobs) > 0 {
+			fmt.Fprintln(buf, "jobs:")
+		}
+		for _, j := range explanation.Jobs {
+			fmt.Fprintf(buf, "  %s %s: %s\n", checkMark(j.Triggered), j.Name, j.Reason)
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write(buf.Bytes())
+		return
+	}
+	data, err := json.MarshalIndent(explanation, "", "  ")
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: failed to marshal the explanation: %s", err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+func checkMark(passed bool) string {
+	if passed {
+		return "[+]"
+	}
+	return "[-]"
+}
@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainTrigger(t *testing.T) {
+	cfg, err := config.LoadYAMLConfig([]byte(`
+presubmits:
+  myorg/myrepo:
+  - name: lint
+    agent: tekton
+    always_run: true
+  - name: docs
+    agent: tekton
+    run_if_changed: ^docs/
+  - name: e2e
+    agent: tekton
+  - name: release
+    agent: tekton
+    always_run: true
+    branches:
+    - release
+`))
+	require.NoError(t, err)
+	configAgent := &config.Agent{}
+	configAgent.Set(cfg)
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{
+		Plugins: map[string][]string{"myorg": {"trigger"}},
+	})
+	s := &Server{ConfigAgent: configAgent, Plugins: pluginAgent}
+	scmClient, data := fakescm.NewDefault()
+	data.PullRequests[12] = &scm.PullRequest{
+		Number: 12,
+		State:  "open",
+		Author: scm.User{Login: "bob"},
+		Base:   scm.PullRequestBranch{Ref: "master", Sha: "base-sha", Repo: scm.Repository{Namespace: "myorg", Name: "myrepo", FullName: "myorg/myrepo"}},
+		Head:   scm.PullRequestBranch{Ref: "feature", Sha: "head-sha"},
+	}
+	data.PullRequestChanges[12] = []*scm.Change{{Path: "pkg/main.go"}}
+	data.Collaborators = []string{"bob"}
+
+	explanation, err := s.ExplainTrigger(scmClient, "myorg", "myrepo", 12)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", explanation.Author)
+	require.Len(t, explanation.Steps, 5)
+	for _, step := range explanation.Steps {
+		assert.True(t, step.Passed, "%s: %s", step.Name, step.Message)
+	}
+	assert.Equal(t, "trust", explanation.Steps[4].Name)
+	assert.Contains(t, explanation.Steps[4].Message, `the author "bob" is trusted`)
+
+	triggered := map[string]bool{}
+	for _, j := range explanation.Jobs {
+		triggered[j.Name] = j.Triggered
+	}
+	assert.Equal(t, map[string]bool{"lint": true, "docs": false, "e2e": false, "release": false}, triggered)
+	assert.Equal(t, `run_if_changed "^docs/" matches none of the changed files`, explanation.Jobs[1].Reason)
+
+	w := httptest.NewRecorder()
+	writeTriggerExplanation(w, explanation, "text")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "[+] lint: always_run is true\n")
+	assert.Contains(t, w.Body.String(), "[-] release: it does not run against the master branch")
+
+	// the trigger plugin is not enabled
+	pluginAgent.Set(&plugins.Configuration{Plugins: map[string][]string{"myorg": {"lgtm"}}})
+	explanation, err = s.ExplainTrigger(scmClient, "myorg", "myrepo", 12)
+	require.NoError(t, err)
+	require.Len(t, explanation.Steps, 3)
+	assert.False(t, explanation.Steps[2].Passed)
+	assert.Empty(t, explanation.Jobs)
+}
+
+func TestTriggerDebugHandlerInvalidQuery(t *testing.T) {
+	o := &WebhooksController{server: &Server{}}
+	w := httptest.NewRecorder()
+	o.TriggerDebugHandler(w, httptest.NewRequest(http.MethodGet, TriggerDebugPath+"?org=myorg&repo=myrepo", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
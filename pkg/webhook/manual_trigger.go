@@ -0,0 +1,203 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	goscmhmac "github.com/jenkins-x/go-scm/pkg/hmac"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ManualTriggerPath is the path the jobs are triggered by hand on
+	ManualTriggerPath = "/trigger"
+
+	// ManualTriggerSignatureHeader is the header of the requests to trigger a job holding the signature of their body
+	// with the HMAC token, of the form sha256=...
+	ManualTriggerSignatureHeader = "X-Lighthouse-Signature"
+)
+
+// ManualTrigger requests a job to be triggered by hand, e.g. to kick a periodic or to rerun a postsubmit
+type ManualTrigger struct {
+	// Job is the name of the presubmit, postsubmit or periodic
+	Job string `json:"job"`
+	// Repo is the full name of the repository of the presubmit or postsubmit, empty for a periodic
+	Repo string `json:"repo,omitempty"`
+	// Branch is the branch the postsubmit runs against, the default branch of the repository if empty
+	Branch string `json:"branch,omitempty"`
+	// PR is the number of the pull request the presubmit runs against, zero for a postsubmit
+	PR int `json:"pr,omitempty"`
+	// RequestedBy is the user triggering the job, recorded in the audit log
+	RequestedBy string `json:"requestedBy,omitempty"`
+}
+
+// Validate checks the request names a job and, if any, the full name of a repository
+func (t *ManualTrigger) Validate() error {
+	if t.Job == "" {
+		return errors.New("no job given")
+	}
+	if t.Repo == "" {
+		if t.Branch != "" || t.PR != 0 {
+			return errors.New("the branch and the pull request require the repository")
+		}
+		return nil
+	}
+	if parts := strings.Split(t.Repo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return errors.Errorf("the repository must be a full name, e.g. myorg/myrepo, not %q", t.Repo)
+	}
+	if t.Branch != "" && t.PR != 0 {
+		return errors.New("either the branch of a postsubmit or the pull request of a presubmit can be given, not both")
+	}
+	return nil
+}
+
+// ManualJob returns the LighthouseJob triggered by hand: the presubmit of the repository running against the pull
+// request if one is given, else its postsubmit running against the head of the branch, else the periodic
+func (s *Server) ManualJob(spc *scmprovider.Client, t *ManualTrigger) (*v1alpha1.LighthouseJob, error) {
+	if t.Repo == "" {
+		cfg := s.ConfigAgent.Config()
+		if cfg == nil {
+			return nil, errors.New("the configuration is not loaded yet")
+		}
+		for _, p := range cfg.Periodics {
+			if p.Name == t.Job {
+				answer := jobutil.NewLighthouseJob(jobutil.PeriodicSpec(p), p.Labels, p.Annotations)
+				return &answer, nil
+			}
+		}
+		return nil, errors.Errorf("no periodic named %s", t.Job)
+	}
+
+	owner, repo := scm.Split(t.Repo)
+	repository := scm.Repository{Namespace: owner, Name: repo}
+	if t.PR != 0 {
+		pr, err := spc.GetPullRequest(owner, repo, t.PR)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the pull request %s#%d", t.Repo, t.PR)
+		}
+		cfg, _, _, err := s.repoConfig(spc, owner, repo, pr.Base.Ref)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range cfg.GetPresubmits(repository) {
+			if p.Name != t.Job {
+				continue
+			}
+			baseSHA, err := spc.GetRef(owner, repo, "heads/"+pr.Base.Ref)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get the head of the branch %s", pr.Base.Ref)
+			}
+			answer := jobutil.NewPresubmit(pr, baseSHA, p, "", spc.PRRefFmt())
+			return &answer, nil
+		}
+		return nil, errors.Errorf("no presubmit named %s in %s", t.Job, t.Repo)
+	}
+
+	found, err := spc.GetRepositoryByFullName(t.Repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the repository %s", t.Repo)
+	}
+	branch := t.Branch
+	if branch == "" {
+		branch = found.Branch
+	}
+	cfg, _, _, err := s.repoConfig(spc, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range cfg.GetPostsubmits(repository) {
+		if p.Name != t.Job {
+			continue
+		}
+		if !p.Brancher.ShouldRun(branch) {
+			return nil, errors.Errorf("the postsubmit %s does not run against the branch %s", t.Job, branch)
+		}
+		sha, err := spc.GetRef(owner, repo, "heads/"+branch)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the head of the branch %s", branch)
+		}
+		refs := v1alpha1.Refs{
+			Org:      owner,
+			Repo:     repo,
+			RepoLink: found.Link,
+			BaseRef:  branch,
+			BaseSHA:  sha,
+			CloneURI: found.Clone,
+		}
+		answer := jobutil.NewLighthouseJob(jobutil.PostsubmitSpec(p, refs), p.Labels, p.Annotations)
+		return &answer, nil
+	}
+	return nil, errors.Errorf("no postsubmit named %s in %s", t.Job, t.Repo)
+}
+
+// ManualTriggerHandler triggers the job of a ManualTrigger posted as JSON, answering the created LighthouseJob. The
+// body must be signed with the HMAC token in the X-Lighthouse-Signature header.
+func (o *WebhooksController) ManualTriggerHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "405 Method Not Allowed: the job to trigger must be posted", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		responseHTTPError(w, http.StatusBadRequest, fmt.Sprintf("400 Bad Request: failed to read the body: %s", err.Error()))
+		return
+	}
+	token := util.HMACToken()
+	if token == "" || !goscmhmac.ValidatePrefix(body, []byte(token), r.Header.Get(ManualTriggerSignatureHeader)) {
+		responseHTTPError(w, http.StatusForbidden, "403 Forbidden: invalid signature")
+		return
+	}
+	t := &ManualTrigger{}
+	if err := json.Unmarshal(body, t); err != nil {
+		responseHTTPError(w, http.StatusBadRequest, fmt.Sprintf("400 Bad Request: failed to parse the body: %s", err.Error()))
+		return
+	}
+	if err := t.Validate(); err != nil {
+		responseHTTPError(w, http.StatusBadRequest, fmt.Sprintf("400 Bad Request: %s", err.Error()))
+		return
+	}
+
+	owner, _ := scm.Split(t.Repo)
+	_, scmClient, _, _, err := util.GetSCMClient(owner, o.server.ConfigAgent.Config)
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: failed to create SCM client: %s", err.Error()))
+		return
+	}
+	spc := scmprovider.ToClient(scmClient, util.GetBotName(o.server.ConfigAgent.Config))
+	request, err := o.server.ManualJob(spc, t)
+	if err != nil {
+		responseHTTPError(w, http.StatusBadRequest, fmt.Sprintf("400 Bad Request: %s", err.Error()))
+		return
+	}
+
+	ctx := audit.ContextWithSource(context.Background(), audit.Source{Actor: t.RequestedBy})
+	ctx = eventid.ContextWithID(ctx, eventid.New())
+	l := logrus.WithFields(jobutil.LighthouseJobFields(request)).WithField("requestedBy", t.RequestedBy)
+	l.Info("Triggering a LighthouseJob by hand.")
+	launcher := &eventLauncher{PipelineLauncher: o.launcher, ctx: ctx}
+	job, err := launcher.Launch(request)
+	if err != nil {
+		l.WithError(err).Error("Failed to trigger the LighthouseJob.")
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: failed to create the LighthouseJob: %s", err.Error()))
+		return
+	}
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: failed to marshal the LighthouseJob: %s", err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManualTriggerValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger ManualTrigger
+		valid   bool
+	}{
+		{name: "periodic", trigger: ManualTrigger{Job: "nightly"}, valid: true},
+		{name: "postsubmit", trigger: ManualTrigger{Job: "release", Repo: "myorg/myrepo", Branch: "main"}, valid: true},
+		{name: "presubmit", trigger: ManualTrigger{Job: "unit", Repo: "myorg/myrepo", PR: 1}, valid: true},
+		{name: "no job", trigger: ManualTrigger{Repo: "myorg/myrepo"}},
+		{name: "branch without repo", trigger: ManualTrigger{Job: "nightly", Branch: "main"}},
+		{name: "not a full name", trigger: ManualTrigger{Job: "unit", Repo: "myrepo"}},
+		{name: "branch and pull request", trigger: ManualTrigger{Job: "unit", Repo: "myorg/myrepo", Branch: "main", PR: 1}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.trigger.Validate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestManualJob(t *testing.T) {
+	cfg, err := config.LoadYAMLConfig([]byte(`
+presubmits:
+  myorg/myrepo:
+  - name: unit
+    agent: tekton
+    context: unit-tests
+postsubmits:
+  myorg/myrepo:
+  - name: release
+    agent: tekton
+    branches:
+    - main
+periodics:
+- name: nightly
+  agent: tekton
+  cron: "0 0 * * *"
+`))
+	require.NoError(t, err)
+	configAgent := &config.Agent{}
+	configAgent.Set(cfg)
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{})
+	s := &Server{ConfigAgent: configAgent, Plugins: pluginAgent}
+
+	scmClient, data := fakescm.NewDefault()
+	data.TestRef = "abcdef"
+	data.Repositories = append(data.Repositories, &scm.Repository{
+		Namespace: "myorg",
+		Name:      "myrepo",
+		FullName:  "myorg/myrepo",
+		Branch:    "main",
+		Clone:     "https://github.com/myorg/myrepo.git",
+	})
+	data.PullRequests[1] = &scm.PullRequest{
+		Number: 1,
+		Base:   scm.PullRequestBranch{Ref: "main", Repo: scm.Repository{Namespace: "myorg", Name: "myrepo"}},
+		Head:   scm.PullRequestBranch{Ref: "feature", Sha: "123456"},
+		Author: scm.User{Login: "alice"},
+	}
+	spc := scmprovider.ToClient(scmClient, "bot")
+
+	lhjob, err := s.ManualJob(spc, &ManualTrigger{Job: "nightly"})
+	require.NoError(t, err)
+	assert.Equal(t, job.PeriodicJob, lhjob.Spec.Type)
+	assert.Equal(t, "nightly", lhjob.Spec.Job)
+
+	lhjob, err = s.ManualJob(spc, &ManualTrigger{Job: "release", Repo: "myorg/myrepo"})
+	require.NoError(t, err)
+	assert.Equal(t, job.PostsubmitJob, lhjob.Spec.Type)
+	require.NotNil(t, lhjob.Spec.Refs)
+	assert.Equal(t, "main", lhjob.Spec.Refs.BaseRef, "the default branch should be used")
+	assert.Equal(t, "abcdef", lhjob.Spec.Refs.BaseSHA)
+
+	lhjob, err = s.ManualJob(spc, &ManualTrigger{Job: "unit", Repo: "myorg/myrepo", PR: 1})
+	require.NoError(t, err)
+	assert.Equal(t, job.PresubmitJob, lhjob.Spec.Type)
+	assert.Equal(t, "unit-tests", lhjob.Spec.Context)
+	require.NotNil(t, lhjob.Spec.Refs)
+	assert.Equal(t, "abcdef", lhjob.Spec.Refs.BaseSHA)
+	require.Len(t, lhjob.Spec.Refs.Pulls, 1)
+	assert.Equal(t, "123456", lhjob.Spec.Refs.Pulls[0].SHA)
+
+	_, err = s.ManualJob(spc, &ManualTrigger{Job: "release", Repo: "myorg/myrepo", Branch: "feature"})
+	assert.Error(t, err, "the postsubmit does not run against the branch")
+	_, err = s.ManualJob(spc, &ManualTrigger{Job: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestManualTriggerHandlerSignature(t *testing.T) {
+	old, hadToken := os.LookupEnv("HMAC_TOKEN")
+	require.NoError(t, os.Setenv("HMAC_TOKEN", "secret"))
+	defer func() {
+		if hadToken {
+			os.Setenv("HMAC_TOKEN", old) //nolint:errcheck
+		} else {
+			os.Unsetenv("HMAC_TOKEN") //nolint:errcheck
+		}
+	}()
+	o := &WebhooksController{launcher: &fakeLauncher{}}
+
+	w := httptest.NewRecorder()
+	o.ManualTriggerHandler(w, httptest.NewRequest(http.MethodGet, ManualTriggerPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, ManualTriggerPath, bytes.NewBufferString(`{"job": "nightly"}`))
+	r.Header.Set(ManualTriggerSignatureHeader, "sha256=0123456789abcdef")
+	o.ManualTriggerHandler(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
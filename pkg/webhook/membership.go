@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+
+	goscmhmac "github.com/jenkins-x/go-scm/pkg/hmac"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+// membershipEvents are the GitHub webhooks changing the permissions, the memberships or the teams of an
+// organisation, which the SCM client does not parse
+var membershipEvents = map[string]bool{
+	"member":       true,
+	"membership":   true,
+	"organization": true,
+	"team":         true,
+	"team_add":     true,
+}
+
+// membershipPayload holds the fields of the membership webhooks identifying the organisation they are about
+type membershipPayload struct {
+	Organization struct {
+		Login string `json:"login"`
+	} `json:"organization"`
+	Repository struct {
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+}
+
+// handleMembershipEvent invalidates the cached permissions, memberships and teams of the organisation of a
+// membership webhook, returning false if the request is not a membership webhook
+func (o *WebhooksController) handleMembershipEvent(w http.ResponseWriter, r *http.Request, body []byte) bool {
+	event := r.Header.Get("X-GitHub-Event")
+	if !membershipEvents[event] {
+		return false
+	}
+	if token := util.HMACToken(); token != "" && !goscmhmac.ValidatePrefix(body, []byte(token), r.Header.Get("X-Hub-Signature")) {
+		responseHTTPError(w, http.StatusForbidden, "403 Forbidden: invalid signature")
+		return true
+	}
+	payload := membershipPayload{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		responseHTTPError(w, http.StatusBadRequest, "400 Bad Request: failed to parse the membership webhook")
+		return true
+	}
+	org := payload.Organization.Login
+	if org == "" {
+		org = payload.Repository.Owner.Login
+	}
+	if org != "" {
+		scmprovider.InvalidatePermissions(org)
+	}
+	logrus.WithField("Webhook", event).WithField(scmprovider.OrgLogField, org).Info("invalidated the cached permissions")
+	_, _ = w.Write([]byte("invalidated the cached permissions of " + org))
+	return true
+}
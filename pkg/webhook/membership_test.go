@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleMembershipEvent(t *testing.T) {
+	scmprovider.SetPermissionCacheTTL(time.Minute)
+	defer scmprovider.SetPermissionCacheTTL(0)
+	client, data := fakescm.NewDefault()
+	spc := scmprovider.ToClient(client, "bot")
+	o := &WebhooksController{}
+
+	ok, err := spc.IsCollaborator("myorg", "myrepo", "bob")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	data.Collaborators = []string{"bob"}
+
+	r := httptest.NewRequest(http.MethodPost, "/hook", nil)
+	r.Header.Set("X-GitHub-Event", "push")
+	assert.False(t, o.handleMembershipEvent(httptest.NewRecorder(), r, []byte(`{}`)))
+	ok, err = spc.IsCollaborator("myorg", "myrepo", "bob")
+	require.NoError(t, err)
+	assert.False(t, ok, "the collaborator is cached")
+
+	body := `{"action":"added","member":{"login":"bob"},"repository":{"full_name":"myorg/myrepo","owner":{"login":"myorg"}}}`
+	r = httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+	r.Header.Set("X-GitHub-Event", "member")
+	w := httptest.NewRecorder()
+	assert.True(t, o.handleMembershipEvent(w, r, []byte(body)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	ok, err = spc.IsCollaborator("myorg", "myrepo", "bob")
+	require.NoError(t, err)
+	assert.True(t, ok, "the membership webhook invalidated the cache")
+}
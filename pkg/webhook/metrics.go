@@ -17,9 +17,17 @@ limitations under the License.
 package webhook
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// Results of the webhook events
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
 var (
 	// Define all metrics for webhooks here.
 	webhookCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -30,23 +38,72 @@ var (
 		Name: "prow_webhook_response_codes",
 		Help: "A counter of the different responses hook has responded to webhooks with.",
 	}, []string{"response_code"})
+	webhookEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_webhook_events_total",
+		Help: "A counter of the webhook events processed, by event type, organisation and result.",
+	}, []string{"event_type", "org", "result"})
+	pluginHandlerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "lighthouse_plugin_handler_duration_seconds",
+		Help:    "The duration of the plugin handlers, by plugin and event type.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"plugin", "event_type"})
+	pluginHandlerErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_plugin_handler_errors_total",
+		Help: "A counter of the errors returned by the plugin handlers, by plugin and event type.",
+	}, []string{"plugin", "event_type"})
+	pluginHandlerOutcomes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_plugin_handler_outcomes_total",
+		Help: "A counter of the outcomes of the plugin handlers, by plugin, event type and outcome (success, error, panic or timeout).",
+	}, []string{"plugin", "event_type", "outcome"})
 )
 
 func init() {
 	prometheus.MustRegister(webhookCounter)
 	prometheus.MustRegister(responseCounter)
+	prometheus.MustRegister(webhookEvents)
+	prometheus.MustRegister(pluginHandlerDuration)
+	prometheus.MustRegister(pluginHandlerErrors)
+	prometheus.MustRegister(pluginHandlerOutcomes)
 }
 
 // Metrics is a set of metrics gathered by hook.
 type Metrics struct {
-	WebhookCounter  *prometheus.CounterVec
-	ResponseCounter *prometheus.CounterVec
+	WebhookCounter        *prometheus.CounterVec
+	ResponseCounter       *prometheus.CounterVec
+	WebhookEvents         *prometheus.CounterVec
+	PluginHandlerDuration *prometheus.HistogramVec
+	PluginHandlerErrors   *prometheus.CounterVec
+	PluginHandlerOutcomes *prometheus.CounterVec
 }
 
 // NewMetrics creates a new set of metrics for the hook server.
 func NewMetrics() *Metrics {
 	return &Metrics{
-		WebhookCounter:  webhookCounter,
-		ResponseCounter: responseCounter,
+		WebhookCounter:        webhookCounter,
+		ResponseCounter:       responseCounter,
+		WebhookEvents:         webhookEvents,
+		PluginHandlerDuration: pluginHandlerDuration,
+		PluginHandlerErrors:   pluginHandlerErrors,
+		PluginHandlerOutcomes: pluginHandlerOutcomes,
+	}
+}
+
+// ObserveWebhookEvent records a webhook event of the organisation and whether it was processed successfully
+func (m *Metrics) ObserveWebhookEvent(eventType, org string, err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultError
+	}
+	m.WebhookCounter.WithLabelValues(eventType).Inc()
+	m.WebhookEvents.WithLabelValues(eventType, org, result).Inc()
+}
+
+// ObservePluginHandler records the duration of a plugin handler started at the given time, its error if any and its
+// outcome
+func (m *Metrics) ObservePluginHandler(plugin, eventType string, start time.Time, err error) {
+	m.PluginHandlerDuration.WithLabelValues(plugin, eventType).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.PluginHandlerErrors.WithLabelValues(plugin, eventType).Inc()
 	}
+	m.PluginHandlerOutcomes.WithLabelValues(plugin, eventType, pluginOutcome(err)).Inc()
 }
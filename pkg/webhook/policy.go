@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/policy"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+// slashCommandRe matches a line of a comment holding a slash command, capturing its name and its arguments
+var slashCommandRe = regexp.MustCompile(`(?i)^/(?:lh-)?([\w-]+)(?:[ \t]+(.*?))?\s*$`)
+
+// policyClient is the part of the SCM client used to authorize the slash commands
+type policyClient interface {
+	policy.TeamLister
+	GetIssueLabels(string, string, int, bool) ([]*scm.Label, error)
+	CreateComment(string, string, int, bool, string) error
+}
+
+// authorizeCommands evaluates the policies for each slash command of the comment if they are enabled for its
+// repository, returning the event whose denied commands are removed from the body so that no plugin handles them.
+// The author of a new comment or review is told why their commands were denied.
+func (s *Server) authorizeCommands(l *logrus.Entry, ce *scmprovider.GenericCommentEvent) *scmprovider.GenericCommentEvent {
+	if s.Policies == nil || s.ConfigAgent == nil || s.ClientAgent == nil {
+		return ce
+	}
+	cfg := s.ConfigAgent.Config()
+	if cfg == nil || !cfg.Policy.EnabledFor(ce.Repo.Namespace, ce.Repo.Name) {
+		return ce
+	}
+	spc := scmprovider.ToClient(s.ClientAgent.SCMProviderClient, s.ClientAgent.BotName)
+	return authorizeCommands(l, s.Policies, cfg.Policy, spc, ce)
+}
+
+func authorizeCommands(l *logrus.Entry, evaluator *policy.Evaluator, cfg *lighthouse.Policy, spc policyClient, ce *scmprovider.GenericCommentEvent) *scmprovider.GenericCommentEvent {
+	var input *policy.Input
+	var kept, denied []string
+	for _, line := range strings.Split(ce.Body, "\n") {
+		m := slashCommandRe.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			kept = append(kept, line)
+			continue
+		}
+		if input == nil {
+			input = commandInput(l, spc, ce)
+		}
+		in := *input
+		in.Command = strings.ToLower(m[1])
+		in.Args = m[2]
+		decision := evaluator.Evaluate(cfg, &in)
+		if decision.Allow {
+			kept = append(kept, line)
+			continue
+		}
+		l.WithFields(logrus.Fields{"command": in.Command, "reason": decision.Reason}).Info("The policies denied the command.")
+		reason := decision.Reason
+		if reason == "" {
+			reason = "denied by the policies"
+		}
+		denied = append(denied, fmt.Sprintf("- `/%s`: %s", in.Command, reason))
+	}
+	if len(denied) == 0 {
+		return ce
+	}
+	if ce.Action == scm.ActionCreate || ce.Action == scm.ActionSubmitted {
+		reply := "the following commands are not allowed:\n\n" + strings.Join(denied, "\n")
+		if err := spc.CreateComment(ce.Repo.Namespace, ce.Repo.Name, ce.Number, ce.IsPR, plugins.FormatResponseRaw(ce.Body, ce.Link, ce.Author.Login, reply)); err != nil {
+			l.WithError(err).Error("Failed to comment on the denied commands.")
+		}
+	}
+	authorized := *ce
+	authorized.Body = strings.Join(kept, "\n")
+	return &authorized
+}
+
+// commandInput returns the input of the policies common to the commands of the comment
+func commandInput(l *logrus.Entry, spc policyClient, ce *scmprovider.GenericCommentEvent) *policy.Input {
+	org, repo := ce.Repo.Namespace, ce.Repo.Name
+	input := &policy.Input{
+		Action: policy.ActionCommand,
+		Actor:  ce.Author.Login,
+		Org:    org,
+		Repo:   repo,
+		Number: ce.Number,
+		IsPR:   ce.IsPR,
+		Labels: []string{},
+	}
+	teams, err := policy.Teams(spc, org, ce.Author.Login)
+	if err != nil {
+		l.WithError(err).Warn("Failed to list the teams of the author of the commands.")
+		teams = []string{}
+	}
+	input.Teams = teams
+	labels, err := spc.GetIssueLabels(org, repo, ce.Number, ce.IsPR)
+	if err != nil {
+		l.WithError(err).Warn("Failed to list the labels of the commands.")
+	}
+	for _, label := range labels {
+		input.Labels = append(input.Labels, label.Name)
+	}
+	return input
+}
@@ -0,0 +1,158 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/policy"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePolicyClient struct {
+	comments []string
+}
+
+func (f *fakePolicyClient) ListTeams(string) ([]*scm.Team, error) {
+	return []*scm.Team{{ID: 1, Name: "devs"}}, nil
+}
+
+func (f *fakePolicyClient) ListTeamMembers(int, string) ([]*scm.TeamMember, error) {
+	return []*scm.TeamMember{{Login: "bob"}}, nil
+}
+
+func (f *fakePolicyClient) GetIssueLabels(string, string, int, bool) ([]*scm.Label, error) {
+	return []*scm.Label{{Name: "approved"}}, nil
+}
+
+func (f *fakePolicyClient) CreateComment(_, _ string, _ int, _ bool, comment string) error {
+	f.comments = append(f.comments, comment)
+	return nil
+}
+
+func TestAuthorizeCommands(t *testing.T) {
+	var inputs []policy.Input
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := struct {
+			Input policy.Input `json:"input"`
+		}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		inputs = append(inputs, body.Input)
+		if body.Input.Command == "approve" {
+			_, _ = w.Write([]byte(`{"result": {"allow": false, "reason": "only the maintainers may approve"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result": {"allow": true}}`))
+	}))
+	defer server.Close()
+	cfg := &lighthouse.Policy{URL: server.URL}
+	require.NoError(t, cfg.Parse())
+
+	l := logrus.NewEntry(logrus.StandardLogger())
+	spc := &fakePolicyClient{}
+	ce := &scmprovider.GenericCommentEvent{
+		IsPR:   true,
+		Action: scm.ActionCreate,
+		Body:   "looks good\n/lgtm\n/lh-approve no-issue\r\n",
+		Number: 3,
+		Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+		Author: scm.User{Login: "bob"},
+	}
+	authorized := authorizeCommands(l, policy.NewEvaluator(l), cfg, spc, ce)
+	assert.Equal(t, "looks good\n/lgtm\n", authorized.Body)
+	assert.Equal(t, "looks good\n/lgtm\n/lh-approve no-issue\r\n", ce.Body, "the event is not modified")
+	require.Len(t, spc.comments, 1)
+	assert.Contains(t, spc.comments[0], "`/approve`: only the maintainers may approve")
+
+	require.Len(t, inputs, 2)
+	assert.Equal(t, policy.Input{
+		Action:  policy.ActionCommand,
+		Actor:   "bob",
+		Teams:   []string{"devs"},
+		Org:     "org",
+		Repo:    "repo",
+		Number:  3,
+		IsPR:    true,
+		Command: "approve",
+		Args:    "no-issue",
+		Labels:  []string{"approved"},
+	}, inputs[1])
+
+	ce.Action = scm.ActionUpdate
+	ce.Body = "/lgtm"
+	assert.Equal(t, ce, authorizeCommands(l, policy.NewEvaluator(l), cfg, spc, ce), "the allowed commands are kept")
+	assert.Len(t, spc.comments, 1)
+}
+
+func TestHandleReviewEventAuthorizesCommands(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := struct {
+			Input policy.Input `json:"input"`
+		}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body.Input.Command == "approve" {
+			_, _ = w.Write([]byte(`{"result": {"allow": false, "reason": "only the maintainers may approve"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result": {"allow": true}}`))
+	}))
+	defer server.Close()
+	policyConfig := &lighthouse.Policy{URL: server.URL}
+	require.NoError(t, policyConfig.Parse())
+
+	reviews := make(chan string, 1)
+	comments := make(chan string, 1)
+	plugins.RegisterPlugin("test-review-policy", plugins.Plugin{
+		ReviewEventHandler: func(agent plugins.Agent, re scm.ReviewHook) error {
+			reviews <- re.Review.Body
+			return nil
+		},
+		GenericCommentHandler: func(agent plugins.Agent, ce scmprovider.GenericCommentEvent) error {
+			comments <- ce.Body
+			return nil
+		},
+	})
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{ProwConfig: lighthouse.Config{Policy: policyConfig}})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{Plugins: map[string][]string{"org/repo": {"test-review-policy"}}})
+	scmClient, data := fakescm.NewDefault()
+	l := logrus.WithField("test", "review")
+	s := &Server{
+		ConfigAgent: configAgent,
+		Plugins:     pluginAgent,
+		ClientAgent: &plugins.ClientAgent{SCMProviderClient: scmClient, BotName: "bot"},
+		Policies:    policy.NewEvaluator(l),
+		Metrics:     NewMetrics(),
+	}
+
+	repo := scm.Repository{Namespace: "org", Name: "repo"}
+	s.handleReviewEvent(l, scm.ReviewHook{
+		Action: scm.ActionSubmitted,
+		Repo:   repo,
+		PullRequest: scm.PullRequest{
+			Number: 3,
+			Base:   scm.PullRequestBranch{Ref: "master", Repo: repo},
+		},
+		Review: scm.Review{
+			ID:     1,
+			Body:   "looks good\n/lgtm\n/approve",
+			Author: scm.User{Login: "bob"},
+		},
+	})
+	assert.Empty(t, s.Drain(10*time.Second))
+	assert.Equal(t, "looks good\n/lgtm", <-reviews, "the review handlers do not see the denied commands")
+	assert.Equal(t, "looks good\n/lgtm", <-comments, "the comment handlers do not see the denied commands")
+	require.Len(t, data.PullRequestComments[3], 1)
+	assert.Contains(t, data.PullRequestComments[3][0].Body, "`/approve`: only the maintainers may approve")
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Reasons the slash commands of a comment are ignored
+const (
+	ignoredBotAuthor      = "bot_author"
+	ignoredSenderMismatch = "sender_mismatch"
+)
+
+var ignoredCommands = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lighthouse_webhook_ignored_commands_total",
+	Help: "A counter of the comments whose slash commands were ignored as they were authored by a bot or their sender did not match their author, by reason.",
+}, []string{"reason"})
+
+func init() {
+	prometheus.MustRegister(ignoredCommands)
+}
+
+// checkProvenance returns the event without its slash commands if the comment was authored by the bot itself or one
+// of the bot accounts of the plugins configuration, or if the user who sent the event is not the author of the
+// comment, so that the commands cannot be injected by the bots or spoofed by mirrored or forwarded events
+func (s *Server) checkProvenance(l *logrus.Entry, ce *scmprovider.GenericCommentEvent) *scmprovider.GenericCommentEvent {
+	var bots []string
+	if s.ClientAgent != nil && s.ClientAgent.BotName != "" {
+		bots = append(bots, s.ClientAgent.BotName)
+	}
+	if s.Plugins != nil {
+		if cfg := s.Plugins.Config(); cfg != nil {
+			bots = append(bots, cfg.BotAccounts...)
+		}
+	}
+	reason := untrustedCommandsReason(bots, ce)
+	if reason == "" {
+		return ce
+	}
+	body := stripCommands(ce.Body)
+	if body == ce.Body {
+		return ce
+	}
+	l.WithFields(logrus.Fields{"reason": reason, "sender": ce.Sender.Login}).Warn("Ignoring the slash commands of the comment.")
+	ignoredCommands.WithLabelValues(reason).Inc()
+	trusted := *ce
+	trusted.Body = body
+	return &trusted
+}
+
+// untrustedCommandsReason returns why the commands of the comment cannot be trusted, empty if they can
+func untrustedCommandsReason(bots []string, ce *scmprovider.GenericCommentEvent) string {
+	author := scmprovider.NormLogin(ce.Author.Login)
+	for _, bot := range bots {
+		if scmprovider.NormLogin(bot) == author {
+			return ignoredBotAuthor
+		}
+	}
+	sender := ce.Sender
+	if sender.Login == "" && sender.ID == 0 {
+		// the provider does not tell who sent the event
+		return ""
+	}
+	if sender.Login != "" && scmprovider.NormLogin(sender.Login) != author {
+		return ignoredSenderMismatch
+	}
+	if sender.ID != 0 && ce.Author.ID != 0 && sender.ID != ce.Author.ID {
+		return ignoredSenderMismatch
+	}
+	return ""
+}
+
+// stripCommands removes the lines holding a slash command from the body of a comment
+func stripCommands(body string) string {
+	var kept []string
+	for _, line := range strings.Split(body, "\n") {
+		if !slashCommandRe.MatchString(strings.TrimRight(line, "\r")) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckProvenance(t *testing.T) {
+	pa := &plugins.ConfigAgent{}
+	pa.Set(&plugins.Configuration{BotAccounts: []string{"renovate-bot"}})
+	s := &Server{
+		ClientAgent: &plugins.ClientAgent{BotName: "lighthouse-bot"},
+		Plugins:     pa,
+	}
+	body := "looks good\n/lgtm\n/lh-approve\nthanks"
+
+	tests := []struct {
+		name   string
+		author scm.User
+		sender scm.User
+		body   string
+	}{
+		{
+			name:   "trusted author",
+			author: scm.User{ID: 1, Login: "alice"},
+			sender: scm.User{ID: 1, Login: "Alice"},
+			body:   body,
+		},
+		{
+			name:   "no sender",
+			author: scm.User{ID: 1, Login: "alice"},
+			body:   body,
+		},
+		{
+			name:   "bot itself",
+			author: scm.User{Login: "Lighthouse-Bot"},
+			body:   "looks good\nthanks",
+		},
+		{
+			name:   "bot account",
+			author: scm.User{Login: "renovate-bot"},
+			sender: scm.User{Login: "renovate-bot"},
+			body:   "looks good\nthanks",
+		},
+		{
+			name:   "sender login mismatch",
+			author: scm.User{ID: 1, Login: "alice"},
+			sender: scm.User{ID: 2, Login: "mallory"},
+			body:   "looks good\nthanks",
+		},
+		{
+			name:   "sender ID mismatch",
+			author: scm.User{ID: 1, Login: "alice"},
+			sender: scm.User{ID: 2, Login: "alice"},
+			body:   "looks good\nthanks",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ce := &scmprovider.GenericCommentEvent{
+				Action: scm.ActionCreate,
+				Body:   body,
+				Author: tc.author,
+				Sender: tc.sender,
+			}
+			checked := s.checkProvenance(logrus.NewEntry(logrus.StandardLogger()), ce)
+			assert.Equal(t, tc.body, checked.Body)
+			assert.Equal(t, body, ce.Body, "the original event must not be modified")
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/policy"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// restrictionClient is the part of the SCM client used to enforce the restrictions of the commands
+type restrictionClient interface {
+	policy.TeamLister
+	CreateComment(string, string, int, bool, string) error
+}
+
+// commandCooldowns remembers when the commands with a cooldown can be used again on an issue or pull request
+type commandCooldowns struct {
+	lock  sync.Mutex
+	until map[string]time.Time
+}
+
+// use returns whether the command can be used at the time, starting its cooldown if so
+func (c *commandCooldowns) use(key string, cooldown time.Duration, now time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.until == nil {
+		c.until = map[string]time.Time{}
+	}
+	for k, until := range c.until {
+		if !now.Before(until) {
+			delete(c.until, k)
+		}
+	}
+	if _, ok := c.until[key]; ok {
+		return false
+	}
+	c.until[key] = now.Add(cooldown)
+	return true
+}
+
+// restrictCommands returns the event whose commands not satisfying the command restrictions of its repository are
+// removed from the body so that no plugin handles them. The author of a new comment is told why their commands
+// were ignored.
+func (s *Server) restrictCommands(l *logrus.Entry, ce *scmprovider.GenericCommentEvent) *scmprovider.GenericCommentEvent {
+	if s.Plugins == nil || s.ClientAgent == nil {
+		return ce
+	}
+	cfg := s.Plugins.Config()
+	if cfg == nil || len(cfg.CommandRestrictions) == 0 {
+		return ce
+	}
+	spc := scmprovider.ToClient(s.ClientAgent.SCMProviderClient, s.ClientAgent.BotName)
+	return restrictCommands(l, cfg, &s.cooldowns, spc, ce, time.Now())
+}
+
+func restrictCommands(l *logrus.Entry, cfg *plugins.Configuration, cooldowns *commandCooldowns, spc restrictionClient, ce *scmprovider.GenericCommentEvent, now time.Time) *scmprovider.GenericCommentEvent {
+	org, repo := ce.Repo.Namespace, ce.Repo.Name
+	var teams sets.String
+	var kept, ignored []string
+	for _, line := range strings.Split(ce.Body, "\n") {
+		m := slashCommandRe.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			kept = append(kept, line)
+			continue
+		}
+		command := strings.ToLower(m[1])
+		restrictions := cfg.CommandRestrictionsFor(org, repo, command)
+		reason := ""
+		for _, r := range restrictions {
+			if !r.RestrictsUsers() {
+				continue
+			}
+			if teams == nil && len(r.Teams) > 0 {
+				names, err := policy.Teams(spc, org, ce.Author.Login)
+				if err != nil {
+					l.WithError(err).Warn("Failed to list the teams of the author of the commands.")
+				}
+				teams = sets.NewString(names...)
+			}
+			if !allowedUser(&r, ce, teams) {
+				reason = "only " + allowedUsers(&r) + " can use it"
+				break
+			}
+		}
+		if reason == "" {
+			for _, r := range restrictions {
+				if r.CooldownDuration == 0 {
+					continue
+				}
+				key := fmt.Sprintf("%s/%s#%d/%s/%s", org, repo, ce.Number, command, r.Cooldown)
+				if !cooldowns.use(key, r.CooldownDuration, now) {
+					reason = fmt.Sprintf("it can only be used once every %s", r.Cooldown)
+					break
+				}
+			}
+		}
+		if reason == "" {
+			kept = append(kept, line)
+			continue
+		}
+		l.WithFields(logrus.Fields{"command": command, "reason": reason}).Info("The command restrictions ignored the command.")
+		ignored = append(ignored, fmt.Sprintf("- `/%s`: %s", command, reason))
+	}
+	if len(ignored) == 0 {
+		return ce
+	}
+	if ce.Action == scm.ActionCreate {
+		reply := "the following commands were ignored:\n\n" + strings.Join(ignored, "\n")
+		if err := spc.CreateComment(org, repo, ce.Number, ce.IsPR, plugins.FormatResponseRaw(ce.Body, ce.Link, ce.Author.Login, reply)); err != nil {
+			l.WithError(err).Error("Failed to comment on the ignored commands.")
+		}
+	}
+	restricted := *ce
+	restricted.Body = strings.Join(kept, "\n")
+	return &restricted
+}
+
+// allowedUser returns whether the author of the comment is one of the users allowed by the restriction
+func allowedUser(r *plugins.CommandRestriction, ce *scmprovider.GenericCommentEvent, teams sets.String) bool {
+	author := scmprovider.NormLogin(ce.Author.Login)
+	if r.Author && author == scmprovider.NormLogin(ce.IssueAuthor.Login) {
+		return true
+	}
+	if r.Assignees {
+		for _, assignee := range ce.Assignees {
+			if author == scmprovider.NormLogin(assignee.Login) {
+				return true
+			}
+		}
+	}
+	return teams.HasAny(r.Teams...)
+}
+
+// allowedUsers describes the users allowed by the restriction
+func allowedUsers(r *plugins.CommandRestriction) string {
+	var users []string
+	if r.Author {
+		users = append(users, "the author")
+	}
+	if r.Assignees {
+		users = append(users, "the assignees")
+	}
+	if len(r.Teams) > 0 {
+		users = append(users, "the members of "+strings.Join(r.Teams, ", "))
+	}
+	if len(users) == 1 {
+		return users[0]
+	}
+	return strings.Join(users[:len(users)-1], ", ") + " or " + users[len(users)-1]
+}
@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestrictCommands(t *testing.T) {
+	cfg := &plugins.Configuration{
+		CommandRestrictions: []plugins.CommandRestriction{
+			{Repos: []string{"org"}, Commands: []string{"retest"}, Cooldown: "10m", CooldownDuration: 10 * time.Minute},
+			{Repos: []string{"org/repo"}, Commands: []string{"hold"}, Author: true, Assignees: true},
+			{Repos: []string{"org/repo"}, Commands: []string{"override"}, Teams: []string{"devs"}},
+		},
+	}
+	l := logrus.NewEntry(logrus.StandardLogger())
+	cooldowns := &commandCooldowns{}
+	now := time.Now()
+	event := func(author, body string) *scmprovider.GenericCommentEvent {
+		return &scmprovider.GenericCommentEvent{
+			IsPR:        true,
+			Action:      scm.ActionCreate,
+			Body:        body,
+			Number:      1,
+			Repo:        scm.Repository{Namespace: "org", Name: "repo"},
+			Author:      scm.User{Login: author},
+			IssueAuthor: scm.User{Login: "alice"},
+			Assignees:   []scm.User{{Login: "carol"}},
+		}
+	}
+
+	spc := &fakePolicyClient{}
+	restricted := restrictCommands(l, cfg, cooldowns, spc, event("alice", "/retest\n/hold\n/override"), now)
+	assert.Equal(t, "/retest\n/hold", restricted.Body)
+	require.Len(t, spc.comments, 1)
+	assert.Contains(t, spc.comments[0], "- `/override`: only the members of devs can use it")
+
+	restricted = restrictCommands(l, cfg, cooldowns, spc, event("bob", "/retest\n/hold\n/override\nthanks"), now.Add(time.Minute))
+	assert.Equal(t, "/override\nthanks", restricted.Body)
+	require.Len(t, spc.comments, 2)
+	assert.Contains(t, spc.comments[1], "- `/retest`: it can only be used once every 10m")
+	assert.Contains(t, spc.comments[1], "- `/hold`: only the author or the assignees can use it")
+
+	ce := event("carol", "/retest\n/hold")
+	assert.Equal(t, ce, restrictCommands(l, cfg, cooldowns, spc, ce, now.Add(10*time.Minute)), "the allowed commands are kept")
+	assert.Len(t, spc.comments, 2)
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// errShuttingDown fails the readiness once the controller is shutting down so that no new webhook is routed to it
+var errShuttingDown = errors.New("shutting down")
+
+// startHandling tracks a handler of the webhook of the logger until the returned func is called, so that the
+// graceful shutdown waits for it
+func (s *Server) startHandling(l *logrus.Entry) func() {
+	id, _ := l.Data[eventid.Field].(string)
+	s.wg.Add(1)
+	s.inFlightLock.Lock()
+	if s.inFlight == nil {
+		s.inFlight = map[string]int{}
+	}
+	s.inFlight[id]++
+	s.inFlightLock.Unlock()
+	return func() {
+		s.inFlightLock.Lock()
+		s.inFlight[id]--
+		if s.inFlight[id] <= 0 {
+			delete(s.inFlight, id)
+		}
+		s.inFlightLock.Unlock()
+		s.wg.Done()
+	}
+}
+
+// Drain waits up to timeout for the handlers of the webhooks dispatched so far to return, returning the IDs of the
+// webhooks still being handled if it timed out
+func (s *Server) Drain(timeout time.Duration) []string {
+	finished := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-time.After(timeout):
+	}
+	s.inFlightLock.Lock()
+	defer s.inFlightLock.Unlock()
+	var ids []string
+	for id := range s.inFlight {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Shutdown shuts the controller down gracefully without losing the webhooks it received: the readiness fails first
+// so that no new webhook is routed to it, the webhooks received until then during delay are still handled, then the
+// server stops accepting connections and the handlers of the webhooks dispatched are waited for up to timeout. The
+// IDs of the webhooks which could not be fully handled are logged so that they can be redelivered. The actions of the
+// plugins queued are applied within the same timeout, the ones left being applied on restart.
+func (o *WebhooksController) Shutdown(server *http.Server, delay, timeout time.Duration) {
+	atomic.StoreInt32(&o.shuttingDown, 1)
+	logrus.WithField("delay", delay).Info("shutting down, no longer ready")
+	time.Sleep(delay)
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logrus.WithError(err).Warn("failed to wait for the webhooks being received")
+	}
+	remaining := timeout - time.Since(start)
+	ids := o.server.Drain(remaining)
+	if queue := o.server.ActionQueue; queue != nil {
+		if pending := queue.Stop(timeout - time.Since(start)); pending > 0 {
+			logrus.Warnf("timed out waiting for the actions of the plugins, %d batches of actions are applied on restart", pending)
+		}
+	}
+	if len(ids) > 0 {
+		logrus.WithField("events", ids).Warnf("timed out waiting for %d webhooks to be handled, they should be redelivered", len(ids))
+		return
+	}
+	logrus.Info("all the webhooks received were handled")
+}
+
+// shutdownCheck is the readiness check failing once the controller is shutting down
+func (o *WebhooksController) shutdownCheck(ctx context.Context) error {
+	if atomic.LoadInt32(&o.shuttingDown) == 1 {
+		return errShuttingDown
+	}
+	return nil
+}
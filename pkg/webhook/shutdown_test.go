@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	s := &Server{}
+	assert.Empty(t, s.Drain(time.Second))
+
+	doneA := s.startHandling(logrus.WithField(eventid.Field, "a"))
+	doneB := s.startHandling(logrus.WithField(eventid.Field, "b"))
+	doneB2 := s.startHandling(logrus.WithField(eventid.Field, "b"))
+	doneA()
+	doneB()
+	assert.Equal(t, []string{"b"}, s.Drain(10*time.Millisecond))
+
+	doneB2()
+	assert.Empty(t, s.Drain(time.Second))
+}
+
+func TestShutdown(t *testing.T) {
+	o := &WebhooksController{server: &Server{}}
+	require.NoError(t, o.shutdownCheck(nil))
+
+	handled := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		done := o.server.startHandling(logrus.WithField(eventid.Field, "a"))
+		go func() {
+			defer done()
+			<-handled
+		}()
+	}))
+	defer ts.Close()
+	resp, err := http.Post(ts.URL, "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	finished := make(chan struct{})
+	go func() {
+		o.Shutdown(ts.Config, 0, 10*time.Second)
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		t.Fatal("the shutdown did not wait for the webhook being handled")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, errShuttingDown, o.shutdownCheck(nil))
+
+	close(handled)
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the shutdown did not return once the webhook was handled")
+	}
+	_, err = http.Post(ts.URL, "application/json", nil)
+	assert.Error(t, err, "no webhook is received once shut down")
+}
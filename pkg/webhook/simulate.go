@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+// actionRequest is the action of the requests to the SCM provider changing something other than the comments,
+// labels and statuses, e.g. merging a pull request
+const actionRequest = "request"
+
+// SimulatedAction is an action a plugin would have taken handling a webhook
+type SimulatedAction struct {
+	// Plugin is the name of the plugin, empty if the action could not be tied to a plugin
+	Plugin string `json:"plugin,omitempty"`
+	// Action is the kind of action, e.g. comment.create, job.create or request
+	Action string `json:"action"`
+	// Target is what the action applies to, e.g. org/repo#1 for a pull request or org/repo@sha for a commit
+	Target string `json:"target,omitempty"`
+	// Details are the details of the action, e.g. the body of a comment or the name of a label
+	Details string `json:"details,omitempty"`
+}
+
+// simulation records the actions of the plugins handling a webhook instead of making them
+type simulation struct {
+	lock    sync.Mutex
+	actions []SimulatedAction
+}
+
+func (s *simulation) add(action SimulatedAction) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.actions = append(s.actions, action)
+}
+
+// addBatch records the comments, labels and statuses of a plugin
+func (s *simulation) addBatch(plugin string, batch *scmprovider.ActionBatch) {
+	for _, a := range batch.Close() {
+		action := SimulatedAction{
+			Plugin: plugin,
+			Action: string(a.Kind),
+			Target: fmt.Sprintf("%s/%s#%d", a.Owner, a.Repo, a.Number),
+		}
+		switch a.Kind {
+		case audit.CommentCreated, audit.CommentEdited:
+			action.Details = a.Body
+		case audit.CommentDeleted:
+			action.Details = fmt.Sprintf("comment %d", a.ID)
+		case audit.LabelAdded, audit.LabelRemoved:
+			action.Details = a.Label
+		case audit.StatusSet:
+			action.Target = fmt.Sprintf("%s/%s@%s", a.Owner, a.Repo, a.Ref)
+			if a.Status != nil {
+				action.Details = fmt.Sprintf("%s: %s %s", a.Status.Label, a.Status.State, a.Status.Desc)
+			}
+		}
+		s.add(action)
+	}
+}
+
+// launcher returns the launcher recording the jobs the plugin would launch
+func (s *simulation) launcher(plugin string) *simulatedLauncher {
+	return &simulatedLauncher{simulation: s, plugin: plugin}
+}
+
+// sorted returns the actions sorted by plugin, the actions of a plugin being in the order they were made
+func (s *simulation) sorted() []SimulatedAction {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	answer := append([]SimulatedAction{}, s.actions...)
+	sort.SliceStable(answer, func(i, j int) bool {
+		return answer[i].Plugin < answer[j].Plugin
+	})
+	return answer
+}
+
+// simulatedLauncher records the jobs a plugin would launch
+type simulatedLauncher struct {
+	simulation *simulation
+	plugin     string
+}
+
+// Launch records the launch of the job, returning it as launched
+func (l *simulatedLauncher) Launch(request *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
+	target := ""
+	if r := request.Spec.Refs; r != nil {
+		target = scm.Join(r.Org, r.Repo)
+		if len(r.Pulls) > 0 {
+			target = fmt.Sprintf("%s#%d", target, r.Pulls[0].Number)
+		}
+	}
+	l.simulation.add(SimulatedAction{
+		Plugin:  l.plugin,
+		Action:  string(audit.JobCreated),
+		Target:  target,
+		Details: fmt.Sprintf("%s %s (%s)", request.Spec.Type, request.Spec.Job, request.Spec.Context),
+	})
+	return request, nil
+}
+
+type simulatedPluginKey struct{}
+
+// readOnlyTransport lets the requests reading from the SCM provider through, recording the other requests of the
+// plugins instead of making them
+type readOnlyTransport struct {
+	base       http.RoundTripper
+	simulation *simulation
+}
+
+// RoundTrip makes the reading requests, answering the others with an empty JSON object once recorded
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the GraphQL API of GitHub is only queried, no mutation is made
+	if req.Method == http.MethodGet || req.Method == http.MethodHead || strings.HasSuffix(req.URL.Path, "/graphql") {
+		return t.base.RoundTrip(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		body, _ = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+	}
+	plugin, _ := req.Context().Value(simulatedPluginKey{}).(string)
+	t.simulation.add(SimulatedAction{
+		Plugin:  plugin,
+		Action:  actionRequest,
+		Target:  req.Method + " " + req.URL.Path,
+		Details: string(body),
+	})
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString("{}")),
+		Request:    req,
+	}, nil
+}
+
+// Simulate handles a recorded webhook with the plugins of the server, returning the actions they would have taken.
+// The comments, labels and statuses of the plugins and the jobs they launch are recorded rather than made, so are the
+// other requests of the SCM client of the server changing something, the client being made read-only. The server
+// must not be used to handle real webhooks afterwards.
+func (s *Server) Simulate(l *logrus.Entry, webhook scm.Webhook) ([]SimulatedAction, error) {
+	sim := &simulation{}
+	s.simulation = sim
+	scmClient := s.ClientAgent.SCMProviderClient
+	if scmClient.Client == nil {
+		scmClient.Client = &http.Client{}
+	}
+	base := scmClient.Client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	scmClient.Client.Transport = &readOnlyTransport{base: base, simulation: sim}
+	o := &WebhooksController{server: s}
+	_, _, err := o.ProcessWebHook(l, webhook)
+	s.wg.Wait()
+	return sim.sorted(), err
+}
+
+// contextWithSimulatedPlugin returns a context whose requests to the SCM provider are recorded for the plugin
+func contextWithSimulatedPlugin(ctx context.Context, plugin string) context.Context {
+	return context.WithValue(ctx, simulatedPluginKey{}, plugin)
+}
@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulate(t *testing.T) {
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{Plugins: map[string][]string{"org/repo": {"hold"}}})
+	scmClient, data := fakescm.NewDefault()
+	s := &Server{
+		ConfigAgent: configAgent,
+		Plugins:     pluginAgent,
+		ClientAgent: &plugins.ClientAgent{BotName: "bot", SCMProviderClient: scmClient},
+	}
+	pr := scm.PullRequest{Number: 1, Base: scm.PullRequestBranch{Ref: "master"}}
+	hook := &scm.PullRequestCommentHook{
+		Action:      scm.ActionCreate,
+		Repo:        scm.Repository{Namespace: "org", Name: "repo", FullName: "org/repo"},
+		PullRequest: pr,
+		Comment:     scm.Comment{ID: 1, Body: "/hold", Author: scm.User{Login: "alice"}},
+	}
+
+	actions, err := s.Simulate(logrus.WithField("test", "simulate"), hook)
+	require.NoError(t, err)
+	assert.Equal(t, []SimulatedAction{
+		{Plugin: "hold", Action: "label.add", Target: "org/repo#1", Details: "do-not-merge/hold"},
+	}, actions)
+	assert.Empty(t, data.PullRequestLabelsAdded, "the label is not added")
+}
+
+func TestReadOnlyTransport(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"number": 1}`))
+	}))
+	defer server.Close()
+	client, err := factory.NewClient("github", server.URL, "")
+	require.NoError(t, err)
+	sim := &simulation{}
+	client.Client = &http.Client{Transport: &readOnlyTransport{base: http.DefaultTransport, simulation: sim}}
+
+	ctx := contextWithSimulatedPlugin(context.Background(), "merger")
+	_, _, err = client.PullRequests.Find(ctx, "org/repo", 1)
+	require.NoError(t, err)
+	_, err = client.PullRequests.Merge(ctx, "org/repo", 1, &scm.PullRequestMergeOptions{MergeMethod: "squash"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"GET /api/v3/repos/org/repo/pulls/1"}, requests)
+	actions := sim.sorted()
+	require.Len(t, actions, 1)
+	assert.Equal(t, "merger", actions[0].Plugin)
+	assert.Equal(t, actionRequest, actions[0].Action)
+	assert.Equal(t, "PUT /api/v3/repos/org/repo/pulls/1/merge", actions[0].Target)
+	assert.Contains(t, actions[0].Details, `"merge_method":"squash"`)
+}
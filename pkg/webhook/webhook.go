@@ -2,24 +2,43 @@ package webhook
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	clientset "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/secret"
+	"github.com/jenkins-x/lighthouse/pkg/dashboard"
+	"github.com/jenkins-x/lighthouse/pkg/engines/jenkins"
+	"github.com/jenkins-x/lighthouse/pkg/envelope"
+	"github.com/jenkins-x/lighthouse/pkg/eventid"
 	"github.com/jenkins-x/lighthouse/pkg/git"
+	"github.com/jenkins-x/lighthouse/pkg/health"
+	"github.com/jenkins-x/lighthouse/pkg/joblogs"
 	"github.com/jenkins-x/lighthouse/pkg/launcher"
 	"github.com/jenkins-x/lighthouse/pkg/metrics"
+	"github.com/jenkins-x/lighthouse/pkg/notification"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/policy"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/tracing"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/crdconfig"
+	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/inrepo"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/jenkins-x/lighthouse/pkg/version"
 	"github.com/jenkins-x/lighthouse/pkg/watcher"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/dynamic"
 )
 
 // WebhooksController holds the command line arguments
@@ -35,6 +54,13 @@ type WebhooksController struct {
 	gitServerURL   string
 	gitClient      git.Client
 	launcher       launcher.PipelineLauncher
+	logs           *joblogs.Handler
+	dashboard      *dashboard.Handler
+	health         *health.Checker
+	events         *eventid.Recorder
+	lhClient       clientset.Interface
+	archive        *WebhookArchive
+	shuttingDown   int32
 }
 
 // NewWebhooksController creates and configures the controller
@@ -60,15 +86,104 @@ func NewWebhooksController(path, namespace, botName, pluginFilename, configFilen
 	}
 	o.gitClient = gitClient
 
-	_, _, lhClient, _, err := clients.GetAPIClients()
+	_, kubeClient, lhClient, _, err := clients.GetAPIClients()
 	if err != nil {
 		return nil, errors.Wrap(err, "Error creating kubernetes resource clients.")
 	}
-	o.launcher = launcher.NewLauncher(lhClient, o.namespace)
+	o.launcher = launcher.NewLauncherWithConfig(lhClient, o.namespace, cfg)
+	o.server.Secrets = secret.NewResolver(secret.KubeGetter(kubeClient, o.namespace), secret.DefaultResolverTTL)
+	o.server.Notifier = notification.NewNotifier(nil, o.server.Secrets)
+	o.logs = joblogs.NewHandler(kubeClient, lhClient, o.namespace, cfg, func() []byte {
+		return []byte(util.HMACToken())
+	})
+	o.dashboard = dashboard.NewHandler(lhClient, o.namespace)
+	o.lhClient = lhClient
+	o.events = eventid.NewRecorder()
+	logrus.AddHook(o.events)
+
+	o.health = health.NewChecker()
+	o.health.AddReadinessCheck("shutdown", o.shutdownCheck)
+	o.health.AddReadinessCheck("kubernetes", health.KubernetesCheck(kubeClient.Discovery()))
+	o.health.AddReadinessCheck("config", health.ConfigCheck(o.ConfigMapWatcher))
+	// the tokens of a GitHub App are per owner so there is no single token to check
+	if util.GetGitHubAppSecretDir() == "" {
+		_, scmClient, _, _, err := util.GetSCMClient("", cfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create the SCM client of the health checks")
+		}
+		o.health.AddReadinessCheck("scm", health.SCMCheck(scmClient))
+	}
 
 	return o, nil
 }
 
+// WatchConfigResources merges the configuration declared by the LighthouseConfig and LighthouseTrigger resources
+// of the namespace into the configuration of their repositories, keeping it up to date until stop is closed
+func (o *WebhooksController) WatchConfigResources(stop <-chan struct{}) error {
+	kubeCfg, err := clients.GetConfig("", "")
+	if err != nil {
+		return errors.Wrap(err, "unable to get kubeconfig")
+	}
+	dynamicClient, err := dynamic.NewForConfig(kubeCfg)
+	if err != nil {
+		return errors.Wrap(err, "unable to create dynamic client")
+	}
+	store := crdconfig.NewStore(dynamicClient, o.namespace, o.server.ConfigAgent.Config().PodNamespace)
+	store.Start(stop)
+	o.server.CRDConfig = store
+	return nil
+}
+
+// StartActionQueue queues the comments, labels and statuses of the plugins durably in the directory, applying them
+// with the given number of workers, so that a crash while handling a webhook does not leave a pull request half
+// updated. The actions left over by a previous run are applied first. The queued actions are encrypted with envelope
+// encryption if the sealer is not nil.
+func (o *WebhooksController) StartActionQueue(dir string, workers int, sealer *envelope.Sealer) error {
+	queue, err := scmprovider.NewActionQueue(dir, func(owner string) (*scmprovider.Client, error) {
+		client, _, _, _, err := util.GetSCMClient(owner, o.server.ConfigAgent.Config)
+		if err != nil {
+			return nil, err
+		}
+		return client.(*scmprovider.Client), nil
+	})
+	if err != nil {
+		return err
+	}
+	queue.Sealer = sealer
+	if err := queue.Start(workers); err != nil {
+		return err
+	}
+	o.server.ActionQueue = queue
+	return nil
+}
+
+// AdmissionHandler returns the validating admission webhook of the LighthouseConfig and LighthouseTrigger resources
+func (o *WebhooksController) AdmissionHandler() http.Handler {
+	var knownPlugins []string
+	for name := range plugins.HelpProviders() {
+		knownPlugins = append(knownPlugins, name)
+	}
+	return &crdconfig.AdmissionHandler{
+		PodNamespace: o.server.ConfigAgent.Config().PodNamespace,
+		KnownPlugins: knownPlugins,
+	}
+}
+
+// LogsHandler returns the handler streaming the live logs of the jobs
+func (o *WebhooksController) LogsHandler() http.Handler {
+	return o.logs
+}
+
+// SetJenkinsAuth configures the credentials the live logs of the Jenkins builds are read with
+func (o *WebhooksController) SetJenkinsAuth(auth *jenkins.AuthConfig) {
+	o.logs.SetJenkinsAuth(auth)
+}
+
+// DashboardHandler returns the handler of the read-only API of the jobs
+func (o *WebhooksController) DashboardHandler() http.Handler {
+	return o.dashboard
+}
+
 // CleanupGitClientDir cleans up the git client's working directory
 func (o *WebhooksController) CleanupGitClientDir() {
 	err := o.gitClient.Clean()
@@ -86,13 +201,19 @@ func (o *WebhooksController) Health(w http.ResponseWriter, r *http.Request) {
 // Ready returns either HTTP 204 if the service is Ready to serve requests, otherwise HTTP 503.
 func (o *WebhooksController) Ready(w http.ResponseWriter, r *http.Request) {
 	logrus.Debug("Ready check")
-	if o.isReady() {
-		w.WriteHeader(http.StatusNoContent)
-	} else {
+	if err := o.health.Ready(r.Context()); err != nil {
+		logrus.WithError(err).Warn("not ready")
 		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
+// HealthChecker returns the checker serving the liveness and readiness endpoints
+func (o *WebhooksController) HealthChecker() *health.Checker {
+	return o.health
+}
+
 // DefaultHandler responds to requests without a specific handler
 func (o *WebhooksController) DefaultHandler(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
@@ -107,11 +228,6 @@ func (o *WebhooksController) DefaultHandler(w http.ResponseWriter, r *http.Reque
 	http.Error(w, fmt.Sprintf("unknown path %s", path), 404)
 }
 
-func (o *WebhooksController) isReady() bool {
-	// TODO a better readiness check
-	return true
-}
-
 // HandleWebhookRequests handles incoming events
 func (o *WebhooksController) HandleWebhookRequests(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -119,6 +235,7 @@ func (o *WebhooksController) HandleWebhookRequests(w http.ResponseWriter, r *htt
 		logrus.WithField("method", r.Method).Debug("invalid http method so returning 200")
 		return
 	}
+	received := time.Now()
 	logrus.Debug("about to parse webhook")
 
 	cfg := o.server.ConfigAgent.Config
@@ -138,6 +255,9 @@ func (o *WebhooksController) HandleWebhookRequests(w http.ResponseWriter, r *htt
 	}
 
 	r.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
+	if o.handleMembershipEvent(w, r, bodyBytes) {
+		return
+	}
 	_, scmClient, serverURL, _, err := util.GetSCMClient("", cfg)
 	if err != nil {
 		logrus.Errorf("failed to create SCM scmClient: %s", err.Error())
@@ -159,6 +279,39 @@ func (o *WebhooksController) HandleWebhookRequests(w http.ResponseWriter, r *htt
 		return
 	}
 
+	// continue the trace of the sender of the webhook, if any
+	ctx := context.Background()
+	if parent, ok := tracing.Parse(r.Header.Get(tracing.Header)); ok {
+		ctx = tracing.ContextWithSpanContext(ctx, parent)
+	}
+	source := auditSource(r, webhook)
+	eventID := eventid.New()
+	ctx = audit.ContextWithSource(ctx, source)
+	ctx = contextWithReceivedTime(ctx, received)
+	ctx = eventid.ContextWithID(ctx, eventID)
+	ctx = scmprovider.ContextWithChangesCache(ctx)
+	ctx, span := tracing.Start(ctx, "webhook "+string(webhook.Kind()), tracing.KindServer)
+	defer span.End()
+	span.SetAttribute("event_type", string(webhook.Kind()))
+	span.SetAttribute("repo", webhook.Repository().FullName)
+	span.SetAttribute(eventid.Field, eventID)
+	if o.events != nil {
+		o.events.Add(eventid.Event{
+			ID:         eventID,
+			Kind:       string(webhook.Kind()),
+			Repository: webhook.Repository().FullName,
+			Delivery:   source.EventID,
+			TraceID:    span.Context().String(),
+			Received:   received,
+		})
+	}
+	w.Header().Set(EventIDHeader, eventID)
+	if o.archive != nil {
+		if err := o.archive.Save(eventID, received, r.Header, bodyBytes); err != nil {
+			logrus.WithError(err).WithField(eventid.Field, eventID).Warn("failed to archive the webhook")
+		}
+	}
+
 	ghaSecretDir := util.GetGitHubAppSecretDir()
 
 	var gitCloneUser string
@@ -198,14 +351,30 @@ func (o *WebhooksController) HandleWebhookRequests(w http.ResponseWriter, r *htt
 		GitClient:         o.gitClient,
 		LighthouseClient:  lhClient.LighthouseV1alpha1().LighthouseJobs(o.namespace),
 		LauncherClient:    o.launcher,
+		Notifier:          o.server.Notifier,
+	}
+	var l *logrus.Entry
+	var output string
+	entry := logrus.WithContext(ctx).WithField("Webhook", webhook.Kind()).WithField(eventid.Field, eventID)
+	if _, ok := webhook.(*scm.CheckRunHook); ok {
+		l, output, err = o.ProcessCheckRunHook(entry, bodyBytes)
+	} else {
+		l, output, err = o.ProcessWebHook(entry, webhook)
 	}
-	l, output, err := o.ProcessWebHook(logrus.WithField("Webhook", webhook.Kind()), webhook)
+	o.server.metrics().ObserveWebhookEvent(string(webhook.Kind()), webhook.Repository().Namespace, err)
+	span.RecordError(err)
 	if err != nil {
 		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+	} else {
+		responseCounter.WithLabelValues(strconv.Itoa(http.StatusOK)).Inc()
 	}
 	// Demux events only to external plugins that require this event.
 	if external := util.ExternalPluginsForEvent(o.server.Plugins, string(webhook.Kind()), webhook.Repository().FullName); len(external) > 0 {
-		go util.CallExternalPluginsWithWebhook(l, external, webhook, util.HMACToken(), &o.server.wg)
+		done := o.server.startHandling(l)
+		go func() {
+			defer done()
+			util.CallExternalPluginsWithWebhook(l, external, webhook, util.HMACToken(), o.server.Secrets, &o.server.wg)
+		}()
 	}
 
 	_, err = w.Write([]byte(output))
@@ -288,6 +457,20 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 		o.server.handleBranchEvent(l, branchHook)
 		return l, "processed branch hook", nil
 	}
+	issueHook, ok := webhook.(*scm.IssueHook)
+	if ok {
+		action := issueHook.Action
+		issue := issueHook.Issue
+		fields["Action"] = action.String()
+		fields["Issue.Number"] = issue.Number
+		fields["Issue.Title"] = issue.Title
+		fields["Issue.Body"] = issue.Body
+
+		l.Info("invoking Issue handler")
+
+		o.server.handleIssueEvent(l, *issueHook)
+		return l, "processed issue hook", nil
+	}
 	issueCommentHook, ok := webhook.(*scm.IssueCommentHook)
 	if ok {
 		action := issueCommentHook.Action
@@ -395,6 +578,8 @@ func (o *WebhooksController) createHookServer() (*Server, error) {
 		Plugins:     pluginAgent,
 		Metrics:     promMetrics,
 		ServerURL:   serverURL,
+		InRepoCache: inrepo.NewContentCache(inrepo.DefaultContentCacheSize),
+		Policies:    policy.NewEvaluator(nil),
 		//TokenGenerator: secretAgent.GetTokenGenerator(o.webhookSecretFile),
 	}
 	return server, nil
@@ -405,5 +590,6 @@ func responseHTTPError(w http.ResponseWriter, statusCode int, response string) {
 		"response":    response,
 		"status-code": statusCode,
 	}).Info(response)
+	responseCounter.WithLabelValues(strconv.Itoa(statusCode)).Inc()
 	http.Error(w, response, statusCode)
 }